@@ -0,0 +1,2 @@
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "logo_key" text NULL;
@@ -0,0 +1,4 @@
+-- Modify "users" table
+ALTER TABLE "public"."users" ADD COLUMN "search_vector" tsvector NULL GENERATED ALWAYS AS ((setweight(to_tsvector('simple'::regconfig, COALESCE(first_name, ''::text)), 'A'::"char") || setweight(to_tsvector('simple'::regconfig, COALESCE(last_name, ''::text)), 'A'::"char")) || setweight(to_tsvector('simple'::regconfig, translate(COALESCE(email, ''::text), '@.-_+'::text, '     '::text)), 'B'::"char")) STORED;
+-- Create index "idx_users_search_vector" to table: "users"
+CREATE INDEX "idx_users_search_vector" ON "public"."users" USING gin ("search_vector");
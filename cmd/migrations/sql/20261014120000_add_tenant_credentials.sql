@@ -0,0 +1,23 @@
+-- Create "tenant_credentials" table
+CREATE TABLE "public"."tenant_credentials" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "provider" text NOT NULL,
+  "name" text NOT NULL,
+  "key_id" text NOT NULL,
+  "encrypted_key" bytea NOT NULL,
+  "nonce" bytea NOT NULL,
+  "ciphertext" bytea NOT NULL,
+  "masked_secret" jsonb NOT NULL,
+  "version" bigint NOT NULL DEFAULT 1,
+  "rotated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_tenant_credentials_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_tenant_credentials_company_provider_name" to table: "tenant_credentials"
+CREATE UNIQUE INDEX "idx_tenant_credentials_company_provider_name" ON "public"."tenant_credentials" ("company_id", "provider", "name");
+-- Create index "idx_tenant_credentials_deleted_at" to table: "tenant_credentials"
+CREATE INDEX "idx_tenant_credentials_deleted_at" ON "public"."tenant_credentials" ("deleted_at");
@@ -0,0 +1,22 @@
+-- Create "jobs" table
+CREATE TABLE "public"."jobs" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "kind" text NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" text NOT NULL DEFAULT 'pending',
+  "attempt" bigint NOT NULL DEFAULT 0,
+  "max_attempts" bigint NOT NULL,
+  "run_at" timestamptz NOT NULL DEFAULT now(),
+  "locked_at" timestamptz NULL,
+  "locked_by" text NULL,
+  "last_error" text NULL,
+  "finished_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_jobs_deleted_at" to table: "jobs"
+CREATE INDEX "idx_jobs_deleted_at" ON "public"."jobs" ("deleted_at");
+-- Create index "idx_jobs_status_run_at" to table: "jobs"
+CREATE INDEX "idx_jobs_status_run_at" ON "public"."jobs" ("status", "run_at");
@@ -0,0 +1,40 @@
+-- Create "api_keys" table
+CREATE TABLE "public"."api_keys" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "prefix" text NOT NULL,
+  "key_hash" text NOT NULL,
+  "last_used_at" timestamptz NULL,
+  "expires_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  "unused_alerted_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_api_keys_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_api_keys_company_id" to table: "api_keys"
+CREATE INDEX "idx_api_keys_company_id" ON "public"."api_keys" ("company_id");
+-- Create index "idx_api_keys_deleted_at" to table: "api_keys"
+CREATE INDEX "idx_api_keys_deleted_at" ON "public"."api_keys" ("deleted_at");
+-- Create index "idx_api_keys_key_hash" to table: "api_keys"
+CREATE UNIQUE INDEX "idx_api_keys_key_hash" ON "public"."api_keys" ("key_hash");
+-- Create "api_key_usages" table
+CREATE TABLE "public"."api_key_usages" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "api_key_id" uuid NOT NULL,
+  "day" date NOT NULL,
+  "endpoint" text NOT NULL,
+  "calls" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_api_key_usages_api_key" FOREIGN KEY ("api_key_id") REFERENCES "public"."api_keys" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_api_key_usages_deleted_at" to table: "api_key_usages"
+CREATE INDEX "idx_api_key_usages_deleted_at" ON "public"."api_key_usages" ("deleted_at");
+-- Create index "idx_api_key_usages_key_day_endpoint" to table: "api_key_usages"
+CREATE UNIQUE INDEX "idx_api_key_usages_key_day_endpoint" ON "public"."api_key_usages" ("api_key_id", "day", "endpoint");
@@ -0,0 +1,17 @@
+-- Create "onboarding_overrides" table
+CREATE TABLE "public"."onboarding_overrides" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "step" text NOT NULL,
+  "completed" boolean NOT NULL,
+  "updated_by" text NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_onboarding_overrides_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_onboarding_overrides_company_step" to table: "onboarding_overrides"
+CREATE UNIQUE INDEX "idx_onboarding_overrides_company_step" ON "public"."onboarding_overrides" ("company_id", "step");
+-- Create index "idx_onboarding_overrides_deleted_at" to table: "onboarding_overrides"
+CREATE INDEX "idx_onboarding_overrides_deleted_at" ON "public"."onboarding_overrides" ("deleted_at");
@@ -0,0 +1,21 @@
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "legal_hold_at" timestamptz NULL, ADD COLUMN "legal_hold_by" text NULL, ADD COLUMN "legal_hold_reason" text NULL;
+-- Modify "api_keys" table
+ALTER TABLE "public"."api_keys" ADD COLUMN "legal_hold_at" timestamptz NULL, ADD COLUMN "legal_hold_by" text NULL, ADD COLUMN "legal_hold_reason" text NULL;
+-- Create "retention_policies" table
+CREATE TABLE "public"."retention_policies" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "resource" text NOT NULL,
+  "days" bigint NOT NULL,
+  "updated_by" text NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_retention_policies_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_retention_policies_company_resource" to table: "retention_policies"
+CREATE UNIQUE INDEX "idx_retention_policies_company_resource" ON "public"."retention_policies" ("company_id", "resource");
+-- Create index "idx_retention_policies_deleted_at" to table: "retention_policies"
+CREATE INDEX "idx_retention_policies_deleted_at" ON "public"."retention_policies" ("deleted_at");
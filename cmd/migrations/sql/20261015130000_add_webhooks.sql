@@ -0,0 +1,56 @@
+-- Create "webhook_endpoints" table
+CREATE TABLE "public"."webhook_endpoints" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "url" text NOT NULL,
+  "description" text NOT NULL DEFAULT '',
+  "event_types" jsonb NOT NULL,
+  "conditions" jsonb NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "rate_limit" bigint NOT NULL DEFAULT 0,
+  "key_id" text NOT NULL,
+  "encrypted_key" bytea NOT NULL,
+  "nonce" bytea NOT NULL,
+  "ciphertext" bytea NOT NULL,
+  "created_by" text NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_webhook_endpoints_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_webhook_endpoints_company_id" to table: "webhook_endpoints"
+CREATE INDEX "idx_webhook_endpoints_company_id" ON "public"."webhook_endpoints" ("company_id");
+-- Create index "idx_webhook_endpoints_deleted_at" to table: "webhook_endpoints"
+CREATE INDEX "idx_webhook_endpoints_deleted_at" ON "public"."webhook_endpoints" ("deleted_at");
+-- Create "webhook_deliveries" table
+CREATE TABLE "public"."webhook_deliveries" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "endpoint_id" uuid NOT NULL,
+  "event_id" uuid NOT NULL,
+  "event_type" text NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" text NOT NULL DEFAULT 'pending',
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "response_status" bigint NULL,
+  "response_body" text NULL,
+  "last_error" text NULL,
+  "duration_ms" bigint NULL,
+  "next_attempt_at" timestamptz NULL,
+  "delivered_at" timestamptz NULL,
+  "redelivery_of" uuid NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_webhook_deliveries_endpoint" FOREIGN KEY ("endpoint_id") REFERENCES "public"."webhook_endpoints" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_webhook_deliveries_company_id" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_company_id" ON "public"."webhook_deliveries" ("company_id");
+-- Create index "idx_webhook_deliveries_deleted_at" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_deleted_at" ON "public"."webhook_deliveries" ("deleted_at");
+-- Create index "idx_webhook_deliveries_endpoint_id" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_endpoint_id" ON "public"."webhook_deliveries" ("endpoint_id");
+-- Create index "idx_webhook_deliveries_event_id" to table: "webhook_deliveries"
+CREATE INDEX "idx_webhook_deliveries_event_id" ON "public"."webhook_deliveries" ("event_id");
@@ -0,0 +1,18 @@
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "logo_size" bigint NOT NULL DEFAULT 0;
+-- Modify "users" table
+ALTER TABLE "public"."users" ADD COLUMN "avatar_size" bigint NOT NULL DEFAULT 0;
+-- Create "company_summaries" table
+CREATE TABLE "public"."company_summaries" (
+  "company_id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "member_count" bigint NOT NULL DEFAULT 0,
+  "storage_bytes" bigint NOT NULL DEFAULT 0,
+  "last_activity_at" timestamptz NULL,
+  "refreshed_at" timestamptz NOT NULL,
+  PRIMARY KEY ("company_id")
+);
+-- Create index "idx_company_summaries_last_activity_at" to table: "company_summaries"
+CREATE INDEX "idx_company_summaries_last_activity_at" ON "public"."company_summaries" ("last_activity_at");
+-- Create index "idx_company_summaries_member_count" to table: "company_summaries"
+CREATE INDEX "idx_company_summaries_member_count" ON "public"."company_summaries" ("member_count");
@@ -0,0 +1,29 @@
+-- Modify "api_keys" table
+ALTER TABLE "public"."api_keys" ADD COLUMN "sandbox" boolean NOT NULL DEFAULT false;
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "sandbox_of_id" uuid NULL;
+-- Create index "idx_companies_sandbox_of_id" to table: "companies"
+CREATE UNIQUE INDEX "idx_companies_sandbox_of_id" ON "public"."companies" ("sandbox_of_id");
+-- Create "captured_emails" table
+CREATE TABLE "public"."captured_emails" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "to" jsonb NOT NULL,
+  "cc" jsonb NULL,
+  "bcc" jsonb NULL,
+  "subject" text NOT NULL DEFAULT '',
+  "text_body" text NOT NULL DEFAULT '',
+  "html_body" text NOT NULL DEFAULT '',
+  "template_id" text NOT NULL DEFAULT '',
+  "template_data" jsonb NULL,
+  "attachments" jsonb NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_captured_emails_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_captured_emails_company_id" to table: "captured_emails"
+CREATE INDEX "idx_captured_emails_company_id" ON "public"."captured_emails" ("company_id");
+-- Create index "idx_captured_emails_deleted_at" to table: "captured_emails"
+CREATE INDEX "idx_captured_emails_deleted_at" ON "public"."captured_emails" ("deleted_at");
@@ -0,0 +1,2 @@
+-- Modify "captured_emails" table
+ALTER TABLE "public"."captured_emails" ALTER COLUMN "company_id" DROP NOT NULL;
@@ -0,0 +1,27 @@
+-- Create "files" table
+CREATE TABLE "public"."files" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "key" text NOT NULL,
+  "content_type" text NOT NULL,
+  "size" bigint NOT NULL DEFAULT 0,
+  "status" text NOT NULL DEFAULT 'pending',
+  "status_reason" text NULL,
+  "status_changed_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_files_deleted_at" to table: "files"
+CREATE INDEX "idx_files_deleted_at" ON "public"."files" ("deleted_at");
+-- Create index "idx_files_key" to table: "files"
+CREATE UNIQUE INDEX "idx_files_key" ON "public"."files" ("key");
+-- Register the avatars and logos uploaded before the registry as available
+INSERT INTO "public"."files" ("id", "key", "content_type", "size", "status")
+SELECT gen_random_uuid(), "avatar_key", '', "avatar_size", 'available' FROM "public"."users"
+WHERE "avatar_key" IS NOT NULL AND "avatar_key" <> '' AND "deleted_at" IS NULL
+ON CONFLICT DO NOTHING;
+INSERT INTO "public"."files" ("id", "key", "content_type", "size", "status")
+SELECT gen_random_uuid(), "logo_key", '', "logo_size", 'available' FROM "public"."companies"
+WHERE "logo_key" IS NOT NULL AND "logo_key" <> '' AND "deleted_at" IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- Create "tenant_request_metrics" table
+CREATE TABLE "public"."tenant_request_metrics" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "tenant" text NOT NULL,
+  "hour" timestamptz NOT NULL,
+  "route" text NOT NULL,
+  "status_class" text NOT NULL,
+  "bucket_ms" bigint NOT NULL,
+  "requests" bigint NOT NULL DEFAULT 0,
+  "duration_ms" numeric NOT NULL DEFAULT 0,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_tenant_request_metrics_deleted_at" to table: "tenant_request_metrics"
+CREATE INDEX "idx_tenant_request_metrics_deleted_at" ON "public"."tenant_request_metrics" ("deleted_at");
+-- Create index "idx_tenant_request_metrics_hour" to table: "tenant_request_metrics"
+CREATE INDEX "idx_tenant_request_metrics_hour" ON "public"."tenant_request_metrics" ("hour");
+-- Create index "idx_tenant_request_metrics_key" to table: "tenant_request_metrics"
+CREATE UNIQUE INDEX "idx_tenant_request_metrics_key" ON "public"."tenant_request_metrics" ("tenant", "hour", "route", "status_class", "bucket_ms");
@@ -0,0 +1,28 @@
+-- Create "export_audits" table
+CREATE TABLE "public"."export_audits" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "entity" text NOT NULL,
+  "format" text NOT NULL,
+  "principal_type" text NOT NULL,
+  "principal_id" text NOT NULL,
+  "roles" jsonb NOT NULL,
+  "company_id" uuid NULL,
+  "fields" jsonb NOT NULL,
+  "filters" jsonb NOT NULL,
+  "status" text NOT NULL,
+  "rows" bigint NOT NULL DEFAULT 0,
+  "error" text NULL,
+  "completed_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_export_audits_company_id" to table: "export_audits"
+CREATE INDEX "idx_export_audits_company_id" ON "public"."export_audits" ("company_id");
+-- Create index "idx_export_audits_deleted_at" to table: "export_audits"
+CREATE INDEX "idx_export_audits_deleted_at" ON "public"."export_audits" ("deleted_at");
+-- Create index "idx_export_audits_entity" to table: "export_audits"
+CREATE INDEX "idx_export_audits_entity" ON "public"."export_audits" ("entity");
+-- Create index "idx_export_audits_principal_id" to table: "export_audits"
+CREATE INDEX "idx_export_audits_principal_id" ON "public"."export_audits" ("principal_id");
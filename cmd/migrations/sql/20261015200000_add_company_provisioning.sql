@@ -0,0 +1,4 @@
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "slug" text NULL, ADD COLUMN "storage_prefix" text NOT NULL DEFAULT '', ADD COLUMN "default_roles" jsonb NOT NULL DEFAULT '[]';
+-- Create index "idx_companies_slug" to table: "companies"
+CREATE UNIQUE INDEX "idx_companies_slug" ON "public"."companies" ("slug");
@@ -0,0 +1,25 @@
+-- Create "maintenance_tasks" table
+CREATE TABLE "public"."maintenance_tasks" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "operation" text NOT NULL,
+  "params" jsonb NULL,
+  "status" text NOT NULL,
+  "batch_size" bigint NOT NULL,
+  "throttle_ms" bigint NOT NULL DEFAULT 0,
+  "cursor" text NOT NULL DEFAULT '',
+  "processed" bigint NOT NULL DEFAULT 0,
+  "total" bigint NOT NULL DEFAULT 0,
+  "generation" bigint NOT NULL DEFAULT 1,
+  "last_error" text NULL,
+  "created_by" text NOT NULL,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_maintenance_tasks_deleted_at" to table: "maintenance_tasks"
+CREATE INDEX "idx_maintenance_tasks_deleted_at" ON "public"."maintenance_tasks" ("deleted_at");
+-- Create index "idx_maintenance_tasks_operation_status" to table: "maintenance_tasks"
+CREATE INDEX "idx_maintenance_tasks_operation_status" ON "public"."maintenance_tasks" ("operation", "status");
@@ -0,0 +1,9 @@
+-- Enable row level security on table: "tenant_credentials"
+ALTER TABLE "public"."tenant_credentials" ENABLE ROW LEVEL SECURITY;
+-- Apply the policies to the table owner too, which the application connects as
+ALTER TABLE "public"."tenant_credentials" FORCE ROW LEVEL SECURITY;
+-- Create policy "tenant_isolation" on table: "tenant_credentials". Sessions without a
+-- tenant, e.g. the workers or DATABASE_RLS_ENABLED unset, reach every row.
+CREATE POLICY "tenant_isolation" ON "public"."tenant_credentials"
+  USING (NULLIF(current_setting('app.tenant_id', true), '') IS NULL OR "company_id"::text = current_setting('app.tenant_id', true))
+  WITH CHECK (NULLIF(current_setting('app.tenant_id', true), '') IS NULL OR "company_id"::text = current_setting('app.tenant_id', true));
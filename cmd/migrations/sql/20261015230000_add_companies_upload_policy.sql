@@ -0,0 +1,2 @@
+-- Modify "companies" table
+ALTER TABLE "public"."companies" ADD COLUMN "upload_policy" jsonb NULL;
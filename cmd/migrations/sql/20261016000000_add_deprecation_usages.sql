@@ -0,0 +1,18 @@
+-- Create "deprecation_usages" table
+CREATE TABLE "public"."deprecation_usages" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "deprecation_id" text NOT NULL,
+  "consumer" text NOT NULL,
+  "tenant" text NOT NULL DEFAULT '',
+  "calls" bigint NOT NULL DEFAULT 0,
+  "first_seen_at" timestamptz NOT NULL,
+  "last_seen_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_deprecation_usages_deleted_at" to table: "deprecation_usages"
+CREATE INDEX "idx_deprecation_usages_deleted_at" ON "public"."deprecation_usages" ("deleted_at");
+-- Create index "idx_deprecation_usages_key" to table: "deprecation_usages"
+CREATE UNIQUE INDEX "idx_deprecation_usages_key" ON "public"."deprecation_usages" ("deprecation_id", "consumer");
@@ -0,0 +1,20 @@
+-- Create "integration_probes" table
+CREATE TABLE "public"."integration_probes" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "integration" text NOT NULL,
+  "status" text NOT NULL,
+  "critical" boolean NOT NULL DEFAULT false,
+  "latency_ms" bigint NOT NULL DEFAULT 0,
+  "error" text NOT NULL DEFAULT '',
+  "checked_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_integration_probes_checked_at" to table: "integration_probes"
+CREATE INDEX "idx_integration_probes_checked_at" ON "public"."integration_probes" ("checked_at");
+-- Create index "idx_integration_probes_deleted_at" to table: "integration_probes"
+CREATE INDEX "idx_integration_probes_deleted_at" ON "public"."integration_probes" ("deleted_at");
+-- Create index "idx_integration_probes_integration_checked_at" to table: "integration_probes"
+CREATE INDEX "idx_integration_probes_integration_checked_at" ON "public"."integration_probes" ("integration", "checked_at");
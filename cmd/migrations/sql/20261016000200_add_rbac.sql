@@ -0,0 +1,44 @@
+-- Create "permissions" table
+CREATE TABLE "public"."permissions" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "name" text NOT NULL,
+  "description" text NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_permissions_deleted_at" to table: "permissions"
+CREATE INDEX "idx_permissions_deleted_at" ON "public"."permissions" ("deleted_at");
+-- Create index "idx_permissions_name" to table: "permissions"
+CREATE UNIQUE INDEX "idx_permissions_name" ON "public"."permissions" ("name");
+-- Create "roles" table
+CREATE TABLE "public"."roles" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "name" text NOT NULL,
+  "description" text NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_roles_deleted_at" to table: "roles"
+CREATE INDEX "idx_roles_deleted_at" ON "public"."roles" ("deleted_at");
+-- Create index "idx_roles_name" to table: "roles"
+CREATE UNIQUE INDEX "idx_roles_name" ON "public"."roles" ("name");
+-- Create "role_permissions" table
+CREATE TABLE "public"."role_permissions" (
+  "role_id" uuid NOT NULL,
+  "permission_id" uuid NOT NULL,
+  PRIMARY KEY ("role_id", "permission_id"),
+  CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "public"."permissions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "public"."roles" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "user_roles" table
+CREATE TABLE "public"."user_roles" (
+  "user_id" uuid NOT NULL,
+  "role_id" uuid NOT NULL,
+  PRIMARY KEY ("user_id", "role_id"),
+  CONSTRAINT "fk_user_roles_role" FOREIGN KEY ("role_id") REFERENCES "public"."roles" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_user_roles_user" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,22 @@
+-- Create "invitations" table
+CREATE TABLE "public"."invitations" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "email" text NOT NULL,
+  "invited_by" text NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "user_id" uuid NULL,
+  "accepted_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_invitations_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_invitations_company_id" to table: "invitations"
+CREATE INDEX "idx_invitations_company_id" ON "public"."invitations" ("company_id");
+-- Create index "idx_invitations_deleted_at" to table: "invitations"
+CREATE INDEX "idx_invitations_deleted_at" ON "public"."invitations" ("deleted_at");
+-- Create index "idx_invitations_email" to table: "invitations"
+CREATE INDEX "idx_invitations_email" ON "public"."invitations" ("email");
@@ -0,0 +1,4 @@
+-- Modify "users" table
+ALTER TABLE "public"."users" ADD COLUMN "external_id" text NULL, ADD COLUMN "disabled_at" timestamptz NULL;
+-- Create index "idx_users_external_id" to table: "users"
+CREATE INDEX "idx_users_external_id" ON "public"."users" ("external_id");
@@ -0,0 +1,16 @@
+-- Create "email_suppressions" table
+CREATE TABLE "public"."email_suppressions" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "email" text NOT NULL,
+  "reason" text NOT NULL,
+  "detail" text NOT NULL DEFAULT '',
+  "message_id" text NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_email_suppressions_deleted_at" to table: "email_suppressions"
+CREATE INDEX "idx_email_suppressions_deleted_at" ON "public"."email_suppressions" ("deleted_at");
+-- Create index "idx_email_suppressions_email" to table: "email_suppressions"
+CREATE UNIQUE INDEX "idx_email_suppressions_email" ON "public"."email_suppressions" ("email");
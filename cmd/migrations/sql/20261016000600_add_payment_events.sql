@@ -0,0 +1,16 @@
+-- Create "payment_events" table
+CREATE TABLE "public"."payment_events" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "provider" text NOT NULL,
+  "event_id" text NOT NULL,
+  "type" text NOT NULL,
+  "payload" jsonb NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_payment_events_deleted_at" to table: "payment_events"
+CREATE INDEX "idx_payment_events_deleted_at" ON "public"."payment_events" ("deleted_at");
+-- Create index "idx_payment_events_provider_event_id" to table: "payment_events"
+CREATE UNIQUE INDEX "idx_payment_events_provider_event_id" ON "public"."payment_events" ("provider", "event_id");
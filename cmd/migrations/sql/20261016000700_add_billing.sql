@@ -0,0 +1,61 @@
+-- Create "plans" table
+CREATE TABLE "public"."plans" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "key" text NOT NULL,
+  "name" text NOT NULL,
+  "price_id" text NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_plans_deleted_at" to table: "plans"
+CREATE INDEX "idx_plans_deleted_at" ON "public"."plans" ("deleted_at");
+-- Create index "idx_plans_key" to table: "plans"
+CREATE UNIQUE INDEX "idx_plans_key" ON "public"."plans" ("key");
+-- Create index "idx_plans_price_id" to table: "plans"
+CREATE UNIQUE INDEX "idx_plans_price_id" ON "public"."plans" ("price_id");
+-- Create "entitlements" table
+CREATE TABLE "public"."entitlements" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "plan_id" uuid NOT NULL,
+  "key" text NOT NULL,
+  "usage_limit" bigint NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_plans_entitlements" FOREIGN KEY ("plan_id") REFERENCES "public"."plans" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_entitlements_deleted_at" to table: "entitlements"
+CREATE INDEX "idx_entitlements_deleted_at" ON "public"."entitlements" ("deleted_at");
+-- Create index "idx_entitlements_plan_id_key" to table: "entitlements"
+CREATE UNIQUE INDEX "idx_entitlements_plan_id_key" ON "public"."entitlements" ("plan_id", "key");
+-- Create "subscriptions" table
+CREATE TABLE "public"."subscriptions" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "plan_id" uuid NOT NULL,
+  "status" text NOT NULL,
+  "provider" text NOT NULL DEFAULT '',
+  "provider_customer_id" text NOT NULL DEFAULT '',
+  "provider_subscription_id" text NULL,
+  "current_period_end" timestamptz NULL,
+  "cancel_at_period_end" boolean NOT NULL DEFAULT false,
+  "last_event_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_subscriptions_company" FOREIGN KEY ("company_id") REFERENCES "public"."companies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_subscriptions_plan" FOREIGN KEY ("plan_id") REFERENCES "public"."plans" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_subscriptions_company_id" to table: "subscriptions"
+CREATE UNIQUE INDEX "idx_subscriptions_company_id" ON "public"."subscriptions" ("company_id");
+-- Create index "idx_subscriptions_deleted_at" to table: "subscriptions"
+CREATE INDEX "idx_subscriptions_deleted_at" ON "public"."subscriptions" ("deleted_at");
+-- Create index "idx_subscriptions_plan_id" to table: "subscriptions"
+CREATE INDEX "idx_subscriptions_plan_id" ON "public"."subscriptions" ("plan_id");
+-- Create index "idx_subscriptions_provider_subscription_id" to table: "subscriptions"
+CREATE UNIQUE INDEX "idx_subscriptions_provider_subscription_id" ON "public"."subscriptions" ("provider_subscription_id");
@@ -0,0 +1,28 @@
+-- Create "payment_operations" table
+CREATE TABLE "public"."payment_operations" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "operation" text NOT NULL,
+  "provider" text NOT NULL,
+  "payment_id" text NOT NULL,
+  "amount" bigint NULL,
+  "processed_amount" bigint NOT NULL DEFAULT 0,
+  "currency" text NOT NULL DEFAULT '',
+  "reason" text NOT NULL DEFAULT '',
+  "note" text NOT NULL DEFAULT '',
+  "status" text NOT NULL,
+  "provider_object_id" text NOT NULL DEFAULT '',
+  "provider_status" text NOT NULL DEFAULT '',
+  "error" text NULL,
+  "initiated_by" text NOT NULL,
+  "completed_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_payment_operations_deleted_at" to table: "payment_operations"
+CREATE INDEX "idx_payment_operations_deleted_at" ON "public"."payment_operations" ("deleted_at");
+-- Create index "idx_payment_operations_initiated_by" to table: "payment_operations"
+CREATE INDEX "idx_payment_operations_initiated_by" ON "public"."payment_operations" ("initiated_by");
+-- Create index "idx_payment_operations_payment_id" to table: "payment_operations"
+CREATE INDEX "idx_payment_operations_payment_id" ON "public"."payment_operations" ("payment_id");
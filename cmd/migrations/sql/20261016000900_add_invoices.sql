@@ -0,0 +1,32 @@
+-- Create "invoices" table
+CREATE TABLE "public"."invoices" (
+  "id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  "company_id" uuid NOT NULL,
+  "provider" text NOT NULL,
+  "provider_invoice_id" text NOT NULL,
+  "number" text NOT NULL DEFAULT '',
+  "status" text NOT NULL,
+  "currency" text NOT NULL,
+  "lines" jsonb NOT NULL,
+  "subtotal" bigint NOT NULL DEFAULT 0,
+  "tax" bigint NOT NULL DEFAULT 0,
+  "total" bigint NOT NULL DEFAULT 0,
+  "amount_paid" bigint NOT NULL DEFAULT 0,
+  "amount_due" bigint NOT NULL DEFAULT 0,
+  "period_start" timestamptz NULL,
+  "period_end" timestamptz NULL,
+  "issued_at" timestamptz NOT NULL,
+  "due_at" timestamptz NULL,
+  "pdf_key" text NOT NULL DEFAULT '',
+  "last_event_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_invoices_company_id" to table: "invoices"
+CREATE INDEX "idx_invoices_company_id" ON "public"."invoices" ("company_id");
+-- Create index "idx_invoices_deleted_at" to table: "invoices"
+CREATE INDEX "idx_invoices_deleted_at" ON "public"."invoices" ("deleted_at");
+-- Create index "idx_invoices_provider_invoice_id" to table: "invoices"
+CREATE UNIQUE INDEX "idx_invoices_provider_invoice_id" ON "public"."invoices" ("provider_invoice_id");
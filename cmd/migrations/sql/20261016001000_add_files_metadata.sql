@@ -0,0 +1,8 @@
+-- Modify "files" table
+ALTER TABLE "public"."files" ADD COLUMN "owner_id" uuid NULL, ADD COLUMN "bucket" text NOT NULL DEFAULT '', ADD COLUMN "checksum" text NOT NULL DEFAULT '';
+-- Create index "idx_files_owner_id" to table: "files"
+CREATE INDEX "idx_files_owner_id" ON "public"."files" ("owner_id");
+-- Record the users and companies owning the avatars, logos and invoices registered before
+UPDATE "public"."files" SET "owner_id" = split_part("key", '/', 2)::uuid
+WHERE split_part("key", '/', 1) IN ('avatars', 'logos', 'invoices')
+AND split_part("key", '/', 2) ~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
@@ -2,28 +2,57 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"golang-boilerplate/docs"
 	"golang-boilerplate/internal/cache"
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	apperrors "golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/generator"
+	"golang-boilerplate/internal/graph"
 	"golang-boilerplate/internal/handlers"
 	"golang-boilerplate/internal/httpclient"
+	"golang-boilerplate/internal/integration/antivirus"
 	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/integration/cdn"
 	"golang-boilerplate/internal/integration/email"
+	"golang-boilerplate/internal/integration/email/templates"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/integration/messaging"
 	"golang-boilerplate/internal/integration/payment"
 	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/invoicing"
+	"golang-boilerplate/internal/jobs"
 	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/maintenance"
 	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/openapi"
+	"golang-boilerplate/internal/projections"
+	"golang-boilerplate/internal/realtime"
 	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/routing"
+	"golang-boilerplate/internal/scheduler"
+	"golang-boilerplate/internal/servertls"
 	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/shutdown"
+	"golang-boilerplate/internal/webhooks"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"golang-boilerplate/cmd/server/routes"
 
 	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/deprecation"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -36,18 +65,69 @@ func NewHTTPServer(lc fx.Lifecycle,
 	healthHandler *handlers.HealthHandler,
 	userHandler *handlers.UserHandler,
 	companyHandler *handlers.CompanyHandler,
+	demoHandler *handlers.DemoHandler,
+	credentialHandler *handlers.TenantCredentialHandler,
+	graphqlHandler *handlers.GraphQLHandler,
+	realtimeHandler *handlers.RealtimeHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	apiKeyService services.APIKeyService,
+	apiKeyUsage *services.APIKeyUsageRecorder,
+	performanceRecorder *services.PerformanceRecorder,
+	onboardingHandler *handlers.OnboardingHandler,
+	retentionHandler *handlers.RetentionHandler,
+	uploadPolicyHandler *handlers.UploadPolicyHandler,
+	webhookHandler *handlers.WebhookHandler,
+	dashboardHandler *handlers.DashboardHandler,
+	sandboxHandler *handlers.SandboxHandler,
+	devInboxHandler *handlers.DevInboxHandler,
+	performanceHandler *handlers.PerformanceHandler,
+	exportHandler *handlers.ExportHandler,
+	routeHandler *handlers.RouteHandler,
+	provisioningHandler *handlers.ProvisioningHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	deprecationHandler *handlers.DeprecationHandler,
+	authHandler *handlers.AuthHandler,
+	rbacHandler *handlers.RBACHandler,
+	invitationHandler *handlers.InvitationHandler,
+	mfaHandler *handlers.MFAHandler,
+	scimHandler *handlers.SCIMHandler,
+	sessionHandler *handlers.SessionHandler,
+	keycloakSyncHandler *handlers.KeycloakSyncHandler,
+	emailHandler *handlers.EmailHandler,
+	paymentWebhookHandler *handlers.PaymentWebhookHandler,
+	billingHandler *handlers.BillingHandler,
+	billingService services.BillingService,
+	companyRepo repositories.CompanyRepository,
+	paymentHandler *handlers.PaymentHandler,
+	invoiceHandler *handlers.InvoiceHandler,
+	fileHandler *handlers.FileHandler,
+	uploadHandler *handlers.UploadHandler,
+	deprecations *deprecation.Registry,
+	deprecationUsage *services.DeprecationUsageRecorder,
+	realtimeHub *realtime.Hub,
 	authProvider auth.AuthService,
 	nrApp *newrelic.Application,
+	catalog *routing.Catalog,
+	sequence *shutdown.Sequence,
+	readiness *shutdown.Readiness,
+	serverTLS *servertls.TLS,
 	cfg *config.Config,
 	db *db.PostgresDB,
 ) *http.Server {
-	handler := routes.Router(userHandler, companyHandler, healthHandler, authProvider, nrApp, cfg).Server.Handler
+	handler := routes.Router(userHandler, companyHandler, healthHandler, demoHandler, credentialHandler, graphqlHandler, realtimeHandler, apiKeyHandler, apiKeyService, apiKeyUsage, performanceRecorder, onboardingHandler, retentionHandler, uploadPolicyHandler, webhookHandler, dashboardHandler, sandboxHandler, devInboxHandler, performanceHandler, exportHandler, routeHandler, provisioningHandler, maintenanceHandler, deprecationHandler, authHandler, rbacHandler, invitationHandler, mfaHandler, scimHandler, sessionHandler, keycloakSyncHandler, emailHandler, paymentWebhookHandler, billingHandler, billingService, companyRepo, paymentHandler, invoiceHandler, fileHandler, uploadHandler, deprecations, deprecationUsage, authProvider, nrApp, catalog, cfg).Server.Handler
 
+	conns := shutdown.NewConnTracker()
 	srv := &http.Server{
 		Addr:              cfg.AppHTTPServer,
 		Handler:           handler,
 		ReadHeaderTimeout: time.Duration(cfg.AppRequestTimeout) * time.Second,
+		ConnState:         conns.ConnState,
 	}
+	if serverTLS != nil {
+		srv.TLSConfig = serverTLS.Config()
+	}
+	// Event streams never complete on their own, end them as soon as draining starts
+	srv.RegisterOnShutdown(realtimeHub.CloseStreams)
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -55,41 +135,510 @@ func NewHTTPServer(lc fx.Lifecycle,
 			if err != nil {
 				return err
 			}
-			logger.Sugar.Infof("Starting HTTP server at %s", srv.Addr)
+			apiKeyUsage.Start()
+			performanceRecorder.Start()
+			deprecationUsage.Start()
+			serve := srv.Serve
+			if srv.TLSConfig != nil {
+				logger.Sugar.Infof("Starting HTTPS server at %s", srv.Addr)
+				// The certificates are in the TLS config, ServeTLS also enables HTTP/2
+				serve = func(ln net.Listener) error { return srv.ServeTLS(ln, "", "") }
+			} else {
+				logger.Sugar.Infof("Starting HTTP server at %s", srv.Addr)
+			}
 			go func() {
-				err := srv.Serve(ln)
-				if err != nil {
+				err := serve(ln)
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
 					logger.Sugar.Panicf("HTTP server error: %v", err)
 				}
 			}()
 
 			return nil
 		},
-		OnStop: func(ctx context.Context) error {
-			logger.Sugar.Info("Shutting down HTTP server...")
+	})
+
+	// Report not ready until the load balancers stop routing requests here, drain the
+	// in-flight requests and the realtime connections, flush the API key usage, the
+	// performance metrics and the deprecation usage they recorded, then close the database
+	sequence.Register(shutdown.PhaseReadiness, shutdown.ComponentReadiness, 0, func(ctx context.Context) error {
+		readiness.Drain(ctx, cfg.ShutdownReadinessDelay)
+		return nil
+	})
+	sequence.Register(shutdown.PhaseIngress, shutdown.ComponentHTTP, cfg.ShutdownHTTPDrainTimeout, func(ctx context.Context) error {
+		return shutdown.DrainHTTP(ctx, srv, conns, nrApp)
+	})
+	sequence.Register(shutdown.PhaseFlush, shutdown.ComponentAPIKeyUsage, constants.ShutdownFlushTimeout, apiKeyUsage.Stop)
+	sequence.Register(shutdown.PhaseFlush, shutdown.ComponentPerformance, constants.ShutdownFlushTimeout, performanceRecorder.Stop)
+	sequence.Register(shutdown.PhaseFlush, shutdown.ComponentDeprecationUsage, constants.ShutdownFlushTimeout, deprecationUsage.Stop)
+	sequence.Register(shutdown.PhaseDatabase, shutdown.ComponentDatabase, cfg.ShutdownDatabaseTimeout, func(context.Context) error {
+		return db.Close()
+	})
 
-			// Graceful shutdown with timeout
-			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
+	return srv
+}
 
-			// Shutdown HTTP server
-			if err := srv.Shutdown(shutdownCtx); err != nil {
-				logger.Sugar.Errorf("HTTP server shutdown error: %v", err)
+// StartInternalHTTPServer serves the cluster-only endpoints on INTERNAL_HTTP_SERVER, a
+// listener kept out of the public ingress. It stops briefly, alongside the public drain.
+func StartInternalHTTPServer(lc fx.Lifecycle,
+	healthHandler *handlers.HealthHandler,
+	jobHandler *handlers.JobHandler,
+	configHandler *handlers.ConfigHandler,
+	internalAdminHandler *handlers.InternalAdminHandler,
+	catalog *routing.Catalog,
+	sequence *shutdown.Sequence,
+	cfg *config.Config,
+) {
+	srv := &http.Server{
+		Addr:              cfg.InternalHTTPServer,
+		Handler:           routes.InternalRouter(healthHandler, jobHandler, configHandler, internalAdminHandler, catalog, cfg).Server.Handler,
+		ReadHeaderTimeout: time.Duration(cfg.AppRequestTimeout) * time.Second,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
 				return err
 			}
+			logger.Sugar.Infof("Starting internal HTTP server at %s", srv.Addr)
+			go func() {
+				err := srv.Serve(ln)
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Sugar.Panicf("Internal HTTP server error: %v", err)
+				}
+			}()
+
+			return nil
+		},
+	})
+
+	sequence.Register(shutdown.PhaseIngress, shutdown.ComponentInternalHTTP, constants.InternalShutdownTimeout, func(ctx context.Context) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			return errors.Join(err, srv.Close())
+		}
+		return nil
+	})
+}
+
+// StartRedirectHTTPServer redirects the plain HTTP requests of TLS_REDIRECT_HTTP_SERVER to
+// the HTTPS listener and answers the ACME HTTP challenges. It runs when the server
+// terminates TLS and the redirect listener is configured.
+func StartRedirectHTTPServer(lc fx.Lifecycle,
+	serverTLS *servertls.TLS,
+	sequence *shutdown.Sequence,
+	cfg *config.Config,
+) {
+	if serverTLS == nil || cfg.TLSRedirectHTTPServer == "" {
+		return
+	}
 
-			// Close database connections
-			if err := db.Close(); err != nil {
-				logger.Sugar.Errorf("Database shutdown error: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.TLSRedirectHTTPServer,
+		Handler:           serverTLS.RedirectHandler(),
+		ReadHeaderTimeout: time.Duration(cfg.AppRequestTimeout) * time.Second,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
 				return err
 			}
+			logger.Sugar.Infof("Starting HTTPS redirect server at %s", srv.Addr)
+			go func() {
+				err := srv.Serve(ln)
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Sugar.Panicf("HTTPS redirect server error: %v", err)
+				}
+			}()
 
-			logger.Sugar.Info("Server shutdown completed")
 			return nil
 		},
 	})
 
-	return srv
+	sequence.Register(shutdown.PhaseIngress, shutdown.ComponentHTTPRedirect, constants.TLSRedirectShutdownTimeout, func(ctx context.Context) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			return errors.Join(err, srv.Close())
+		}
+		return nil
+	})
+}
+
+// StartWorker runs the jobs of the task queue in the worker mode. On shutdown the workers
+// finish their running jobs, then the database connections are closed.
+func StartWorker(lc fx.Lifecycle,
+	pool *jobs.Pool,
+	sequence *shutdown.Sequence,
+	cfg *config.Config,
+	db *db.PostgresDB,
+) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			pool.Start()
+			return nil
+		},
+	})
+
+	sequence.Register(shutdown.PhaseWorkers, shutdown.ComponentWorker, cfg.ShutdownWorkerTimeout, pool.Stop)
+	sequence.Register(shutdown.PhaseDatabase, shutdown.ComponentDatabase, cfg.ShutdownDatabaseTimeout, func(context.Context) error {
+		return db.Close()
+	})
+}
+
+// StartBillingConsumer syncs the subscriptions from the payment events of the broker in
+// the worker mode. It is not started when messaging is disabled.
+func StartBillingConsumer(lc fx.Lifecycle,
+	consumer messaging.Consumer,
+	billingService services.BillingService,
+	cfg *config.Config,
+) {
+	if cfg.MessagingProvider == "" {
+		logger.Log.Warn("Messaging is disabled, subscriptions are not synced with the payment provider")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				sub := messaging.Subscription{
+					Queue:  constants.BillingQueue,
+					Topics: []string{constants.BillingTopicStripeSubscriptions},
+				}
+				if err := consumer.Consume(ctx, sub, billingService.HandleSubscriptionEvent); err != nil && ctx.Err() == nil {
+					logger.Log.Error("Failed to consume subscription events", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// StartInvoiceConsumer syncs the invoices from the payment events of the broker in the
+// worker mode. It is not started when messaging is disabled.
+func StartInvoiceConsumer(lc fx.Lifecycle,
+	consumer messaging.Consumer,
+	invoiceService services.InvoiceService,
+	cfg *config.Config,
+) {
+	if cfg.MessagingProvider == "" {
+		logger.Log.Warn("Messaging is disabled, invoices are not synced with the payment provider")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				sub := messaging.Subscription{
+					Queue:  constants.InvoiceQueue,
+					Topics: []string{constants.InvoiceTopicStripeInvoices},
+				}
+				if err := consumer.Consume(ctx, sub, invoiceService.HandleInvoiceEvent); err != nil && ctx.Err() == nil {
+					logger.Log.Error("Failed to consume invoice events", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// RebuildProjections rebuilds the read models named in the arguments, all of them when
+// none is named, then exits with a non-zero code when a rebuild failed
+func RebuildProjections(lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	projector *projections.Projector,
+	db *db.PostgresDB,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				code := 0
+				if err := projector.Rebuild(ctx, os.Args[2:]...); err != nil {
+					logger.Sugar.Errorf("Failed to rebuild projections: %v", err)
+					code = 1
+				}
+				_ = shutdowner.Shutdown(fx.ExitCode(code))
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return db.Close()
+		},
+	})
+}
+
+// ParseCreateAdminArgs parses the flags of the create-admin run mode
+func ParseCreateAdminArgs(args []string) (*dtos.CreateAdminRequest, error) {
+	req := &dtos.CreateAdminRequest{Password: os.Getenv(constants.CreateAdminPasswordEnv)}
+	for _, arg := range args {
+		if value, ok := strings.CutPrefix(arg, constants.CreateAdminFlagEmail); ok {
+			req.Email = value
+		} else if value, ok := strings.CutPrefix(arg, constants.CreateAdminFlagFirstName); ok {
+			req.FirstName = value
+		} else if value, ok := strings.CutPrefix(arg, constants.CreateAdminFlagLastName); ok {
+			req.LastName = value
+		} else if value, ok := strings.CutPrefix(arg, constants.CreateAdminFlagPassword); ok {
+			req.Password = value
+		} else if arg == constants.CreateAdminFlagTemporaryPassword {
+			req.TemporaryPassword = true
+		} else {
+			return nil, fmt.Errorf("unknown argument %q", arg)
+		}
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		fieldErrors := apperrors.ParseValidationErrors(err)
+		messages := make([]string, 0, len(fieldErrors))
+		for _, message := range fieldErrors {
+			messages = append(messages, message)
+		}
+		slices.Sort(messages)
+		return nil, fmt.Errorf("invalid admin user: %s", strings.Join(messages, ", "))
+	}
+	return req, nil
+}
+
+// CreateAdmin creates the admin user of the request in Keycloak and the database, prints
+// it and stops the application
+func CreateAdmin(lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	req *dtos.CreateAdminRequest,
+	bootstrapService services.BootstrapService,
+	db *db.PostgresDB,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				code := 0
+				admin, err := bootstrapService.CreateAdmin(ctx, req)
+				if err != nil {
+					logger.Sugar.Errorf("Failed to create the admin user: %v", err)
+					code = 1
+				} else {
+					fmt.Printf("Admin user %s ready: user %s, Keycloak user %s\n", admin.Email, admin.UserID, admin.KeycloakID)
+				}
+				_ = shutdowner.Shutdown(fx.ExitCode(code))
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return db.Close()
+		},
+	})
+}
+
+// RunConfigCommand runs a command of the config run mode and returns the exit code. The
+// check command validates the config loaded from the env files, e.g. in CI, and lists all
+// its problems.
+func RunConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != constants.ConfigCommandCheck {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [env-file...]\n", constants.RunModeConfig, constants.ConfigCommandCheck)
+		return 2
+	}
+
+	if err := config.Check(args[1:]...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Println("Configuration is valid")
+	return 0
+}
+
+// RunRoutesCommand prints the routes of the public and internal listeners built for the
+// config, with their middlewares, schemes and roles, as a table or as JSON with
+// RoutesFlagJSON, and returns the exit code. The routers are built with empty handlers and
+// services: no route is served, so nothing connects to the database or the providers.
+func RunRoutesCommand(args []string) int {
+	asJSON := len(args) == 1 && args[0] == constants.RoutesFlagJSON
+	if len(args) > 1 || len(args) == 1 && !asJSON {
+		fmt.Fprintf(os.Stderr, "Usage: %s [%s]\n", constants.RunModeRoutes, constants.RoutesFlagJSON)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	logger.Init(cfg.LogLevel, cfg.AppEnv.String())
+
+	catalog := routing.ProvideCatalog()
+	routes.Router(new(handlers.UserHandler), new(handlers.CompanyHandler), new(handlers.HealthHandler), new(handlers.DemoHandler),
+		new(handlers.TenantCredentialHandler), new(handlers.GraphQLHandler), new(handlers.RealtimeHandler), new(handlers.APIKeyHandler),
+		nil, nil, nil, new(handlers.OnboardingHandler), new(handlers.RetentionHandler), new(handlers.UploadPolicyHandler),
+		new(handlers.WebhookHandler), new(handlers.DashboardHandler), new(handlers.SandboxHandler), new(handlers.DevInboxHandler),
+		new(handlers.PerformanceHandler), new(handlers.ExportHandler), new(handlers.RouteHandler), new(handlers.ProvisioningHandler), new(handlers.MaintenanceHandler),
+		new(handlers.DeprecationHandler), new(handlers.AuthHandler), new(handlers.RBACHandler), new(handlers.InvitationHandler), new(handlers.MFAHandler), new(handlers.SCIMHandler), new(handlers.SessionHandler), new(handlers.KeycloakSyncHandler), new(handlers.EmailHandler), new(handlers.PaymentWebhookHandler), new(handlers.BillingHandler), nil, nil, new(handlers.PaymentHandler), new(handlers.InvoiceHandler), new(handlers.FileHandler), new(handlers.UploadHandler), deprecation.ProvideRegistry(), nil, nil, nil, catalog, cfg)
+	routes.InternalRouter(new(handlers.HealthHandler), new(handlers.JobHandler), new(handlers.ConfigHandler), new(handlers.InternalAdminHandler), catalog, cfg)
+
+	registered := make([]dtos.RouteResponse, 0)
+	for _, route := range catalog.Routes() {
+		registered = append(registered, dtos.NewRouteResponse(route))
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(registered); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LISTENER\tMETHOD\tPATH\tSCHEMES\tROLES\tMIDDLEWARES")
+	for _, route := range registered {
+		schemes := strings.Join(route.Schemes, ",")
+		if schemes == "" {
+			schemes = "public"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Listener, route.Method, route.Path, schemes,
+			strings.Join(route.Roles, ","), strings.Join(route.Middlewares, ","))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// RunGenerateCommand runs a command of the generate run mode and returns the exit code.
+// The resource command writes the model, DTOs, repository, service, handler with its
+// Swagger annotations and routes of a new entity into the module, then prints the wiring
+// left to do by hand. It refuses to overwrite an existing file.
+func RunGenerateCommand(args []string) int {
+	usage := func() int {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s <name> [%s<plural>]\n", constants.RunModeGenerate, constants.GenerateCommandResource, constants.GenerateFlagPlural)
+		return 2
+	}
+	if len(args) < 2 || len(args) > 3 || args[0] != constants.GenerateCommandResource {
+		return usage()
+	}
+	plural := ""
+	if len(args) == 3 {
+		var ok bool
+		if plural, ok = strings.CutPrefix(args[2], constants.GenerateFlagPlural); !ok {
+			return usage()
+		}
+	}
+
+	root, err := generator.FindModuleRoot(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	module, err := generator.ModulePath(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	resource, err := generator.NewResource(module, args[1], plural)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	paths, err := generator.Generate(root, resource)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate the %s resource: %v\n", resource.Human, err)
+		return 1
+	}
+	for _, path := range paths {
+		fmt.Printf("created %s\n", path)
+	}
+	fmt.Printf("\nNext steps:\n%s", generator.NextSteps(resource))
+	return 0
+}
+
+// RunOpenAPICommand runs the openapi command of args, e.g. diff, and returns the exit code
+func RunOpenAPICommand(args []string) int {
+	usage := func() int {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s [%s<file>] | %s %s\n", constants.RunModeOpenAPI, constants.OpenAPICommandExport, constants.OpenAPIFlagOutput, constants.RunModeOpenAPI, constants.OpenAPICommandDiff)
+		return 2
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	root, err := generator.FindModuleRoot(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch args[0] {
+	case constants.OpenAPICommandExport:
+		output := ""
+		if len(args) > 2 {
+			return usage()
+		}
+		if len(args) == 2 {
+			var ok bool
+			if output, ok = strings.CutPrefix(args[1], constants.OpenAPIFlagOutput); !ok || output == "" {
+				return usage()
+			}
+		}
+		spec, err := openapi.Generate(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate the OpenAPI specification: %v\n", err)
+			return 1
+		}
+		if output == "" {
+			fmt.Println(string(spec))
+			return 0
+		}
+		if err := os.WriteFile(output, append(spec, '\n'), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("wrote %s\n", output)
+		return 0
+	case constants.OpenAPICommandDiff:
+		if len(args) != 1 {
+			return usage()
+		}
+		committed, err := os.ReadFile(filepath.Join(root, openapi.CommittedFile))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		spec, err := openapi.Generate(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to generate the OpenAPI specification: %v\n", err)
+			return 1
+		}
+		differences, err := openapi.Diff(committed, spec)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(differences) == 0 {
+			fmt.Printf("%s is up to date\n", openapi.CommittedFile)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "%s differs from the annotations of the handlers and the DTOs, run make swagger-load:\n", openapi.CommittedFile)
+		for _, difference := range differences {
+			fmt.Fprintf(os.Stderr, "  %s\n", difference)
+		}
+		return 1
+	default:
+		return usage()
+	}
 }
 
 // @title Golang Boilerplate API
@@ -102,7 +651,61 @@ func NewHTTPServer(lc fx.Lifecycle,
 // @in header
 // @name Authorization
 // @description Bearer Token Authentication. Use "Bearer {token}" as the value.
+// @securityDefinitions.apiKey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of a company, created through /companies/{id}/api-keys.
 func main() {
+	// The first argument selects the run mode: the HTTP server by default, the workers of
+	// the task queue, or the rebuild of the read models
+	mode := constants.RunModeServer
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+	var modeOptions fx.Option
+	switch mode {
+	case constants.RunModeServer:
+		modeOptions = fx.Options(
+			fx.Invoke(SeedDemoData),
+			fx.Invoke(func(*http.Server) {}),
+			fx.Invoke(StartInternalHTTPServer),
+			fx.Invoke(StartRedirectHTTPServer),
+			fx.Invoke(func(*scheduler.Scheduler) {}),
+		)
+	case constants.RunModeWorker:
+		modeOptions = fx.Options(
+			fx.Invoke(StartWorker),
+			fx.Invoke(StartBillingConsumer),
+			fx.Invoke(StartInvoiceConsumer),
+			fx.Invoke(StartInternalHTTPServer),
+		)
+	case constants.RunModeRebuildProjections:
+		modeOptions = fx.Invoke(RebuildProjections)
+	case constants.RunModeCreateAdmin:
+		req, err := ParseCreateAdminArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\nUsage: %s %s<email> %s<password> [%s<name>] [%s<name>] [%s]\nThe password can be given by %s instead\n",
+				err, constants.RunModeCreateAdmin, constants.CreateAdminFlagEmail, constants.CreateAdminFlagPassword, constants.CreateAdminFlagFirstName,
+				constants.CreateAdminFlagLastName, constants.CreateAdminFlagTemporaryPassword, constants.CreateAdminPasswordEnv)
+			os.Exit(2)
+		}
+		modeOptions = fx.Options(
+			fx.Supply(req),
+			fx.Invoke(CreateAdmin),
+		)
+	case constants.RunModeConfig:
+		os.Exit(RunConfigCommand(os.Args[2:]))
+	case constants.RunModeRoutes:
+		os.Exit(RunRoutesCommand(os.Args[2:]))
+	case constants.RunModeGenerate:
+		os.Exit(RunGenerateCommand(os.Args[2:]))
+	case constants.RunModeOpenAPI:
+		os.Exit(RunOpenAPICommand(os.Args[2:]))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown run mode %q, expected %s, %s, %s, %s, %s, %s, %s or %s\n", mode, constants.RunModeServer, constants.RunModeWorker, constants.RunModeRebuildProjections, constants.RunModeCreateAdmin, constants.RunModeConfig, constants.RunModeRoutes, constants.RunModeGenerate, constants.RunModeOpenAPI)
+		os.Exit(2)
+	}
+
 	// Ensure Swagger spec is registered and optionally override fields at runtime
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	cfg, err := config.Load()
@@ -152,24 +755,191 @@ func main() {
 			ProvideValidator,
 			httpclient.ProvideRestClient,
 			auth.ProvideAuth,
+			auth.ProvideTokenProvider,
+			cache.ProvideMetrics,
 			cache.ProvideCache,
 			email.ProvideEmailSender,
+			email.ProvideSNSVerifier,
+			templates.ProvideRegistry,
 			payment.ProvidePaymentAdapter,
+			payment.ProvideWebhookVerifier,
+			invoicing.ProvideRenderer,
 			storage.ProvideStorageAdapter,
+			kms.ProvideKeyManager,
+			messaging.ProvideBroker,
+			messaging.ProvidePublisher,
+			messaging.ProvideConsumer,
+			shutdown.ProvideWatchdog,
+			shutdown.ProvideSequence,
+			shutdown.ProvideReadiness,
+			servertls.ProvideTLS,
+			monitoring.ProvideHealthRegistry,
+			fx.Annotate(db.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(cache.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(auth.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(storage.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(email.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(payment.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			fx.Annotate(antivirus.ProvideHealthChecker, fx.ResultTags(`group:"health_checkers"`)),
+			routing.ProvideCatalog,
+			deprecation.ProvideRegistry,
+			realtime.ProvideHub,
+			realtime.ProvidePublisher,
 			repositories.ProvideUserRepository,
 			repositories.ProvideCompanyRepository,
+			repositories.ProvideDemoRepository,
+			repositories.ProvideTenantCredentialRepository,
+			repositories.ProvideJobRepository,
+			repositories.ProvideAPIKeyRepository,
+			repositories.ProvideOnboardingRepository,
+			repositories.ProvideRetentionRepository,
+			repositories.ProvideDeprecationRepository,
+			repositories.ProvideWebhookRepository,
+			repositories.ProvideCompanySummaryRepository,
+			repositories.ProvideAdminStatsRepository,
+			repositories.ProvideFileRepository,
+			repositories.ProvidePerformanceRepository,
+			repositories.ProvideIntegrationProbeRepository,
+			repositories.ProvideExportRepository,
+			repositories.ProvideMaintenanceRepository,
+			repositories.ProvideRBACRepository,
+			repositories.ProvideInvitationRepository,
+			repositories.ProvideEmailSuppressionRepository,
+			repositories.ProvidePaymentEventRepository,
+			repositories.ProvideBillingRepository,
+			repositories.ProvidePaymentOperationRepository,
+			repositories.ProvideInvoiceRepository,
+			fx.Annotate(repositories.ProvideCapturedEmailRepository, fx.As(new(repositories.CapturedEmailRepository)), fx.As(new(email.Inbox))),
+			jobs.ProvideClient,
+			jobs.ProvideEnqueuer,
+			jobs.ProvideDeadLetters,
+			jobs.ProvideWorkers,
+			jobs.ProvidePool,
+			antivirus.ProvideScanner,
+			files.ProvideRegistry,
+			webhooks.ProvideDispatcher,
+			webhooks.ProvideSender,
+			webhooks.ProvideWebhookSender,
+			projections.ProvideProjector,
+			projections.ProvidePublisher,
+			projections.ProvideEventProjector,
+			fx.Annotate(projections.ProvideCompanySummaries, fx.ResultTags(`group:"projections"`)),
+			fx.Annotate(projections.ProvideCDNPurge, fx.ResultTags(`group:"projections"`)),
+			cdn.ProvidePurger,
+			maintenance.ProvideManager,
+			maintenance.ProvideRunner,
+			fx.Annotate(maintenance.ProvideReindexSearch, fx.ResultTags(`group:"maintenance_operations"`)),
+			fx.Annotate(maintenance.ProvideRebuildProjections, fx.ResultTags(`group:"maintenance_operations"`)),
+			fx.Annotate(maintenance.ProvideReencryptCredentials, fx.ResultTags(`group:"maintenance_operations"`)),
+			fx.Annotate(maintenance.ProvideReencryptWebhookSecrets, fx.ResultTags(`group:"maintenance_operations"`)),
 			services.ProvideCompanyService,
 			services.ProvideEmailService,
+			services.ProvidePaymentWebhookService,
+			services.ProvideBillingService,
+			services.ProvidePaymentService,
+			services.ProvideInvoiceService,
+			services.ProvideFileService,
+			services.ProvideUploadService,
 			services.ProvideUserService,
 			services.ProvideAuthService,
+			services.ProvideDemoService,
+			services.ProvideTenantCredentialService,
+			services.ProvideAPIKeyService,
+			services.ProvideAPIKeyUsageRecorder,
+			services.ProvideOnboardingService,
+			services.ProvideRetentionService,
+			services.ProvideUploadPolicyService,
+			services.ProvideDeprecationService,
+			services.ProvideWebhookService,
+			services.ProvideDashboardService,
+			services.ProvideAdminDashboardService,
+			services.ProvideSandboxService,
+			services.ProvideDevInboxService,
+			services.ProvidePerformanceService,
+			services.ProvideIntegrationHealthService,
+			services.ProvidePerformanceRecorder,
+			services.ProvideDeprecationUsageRecorder,
+			services.ProvideExportService,
+			services.ProvideProvisioningService,
+			services.ProvideBootstrapService,
+			services.ProvidePolicyService,
+			services.ProvideInvitationService,
+			services.ProvideMFAService,
+			services.ProvideSCIMService,
+			services.ProvideSessionService,
+			services.ProvideKeycloakSyncService,
+			graph.ProvideResolver,
 			handlers.ProvideHealthHandler,
 			handlers.ProvideUserHandler,
 			handlers.ProvideCompanyHandler,
+			handlers.ProvideDemoHandler,
+			handlers.ProvideTenantCredentialHandler,
+			handlers.ProvideGraphQLHandler,
+			handlers.ProvideRealtimeHandler,
+			handlers.ProvideJobHandler,
+			handlers.ProvideInternalAdminHandler,
+			handlers.ProvideAPIKeyHandler,
+			handlers.ProvideOnboardingHandler,
+			handlers.ProvideRetentionHandler,
+			handlers.ProvideUploadPolicyHandler,
+			handlers.ProvideDeprecationHandler,
+			handlers.ProvideAuthHandler,
+			handlers.ProvideRBACHandler,
+			handlers.ProvideInvitationHandler,
+			handlers.ProvideMFAHandler,
+			handlers.ProvideSCIMHandler,
+			handlers.ProvideSessionHandler,
+			handlers.ProvideKeycloakSyncHandler,
+			handlers.ProvideEmailHandler,
+			handlers.ProvidePaymentWebhookHandler,
+			handlers.ProvideBillingHandler,
+			handlers.ProvidePaymentHandler,
+			handlers.ProvideInvoiceHandler,
+			handlers.ProvideFileHandler,
+			handlers.ProvideUploadHandler,
+			handlers.ProvideWebhookHandler,
+			handlers.ProvideDashboardHandler,
+			handlers.ProvideSandboxHandler,
+			handlers.ProvideDevInboxHandler,
+			handlers.ProvideConfigHandler,
+			handlers.ProvidePerformanceHandler,
+			handlers.ProvideExportHandler,
+			handlers.ProvideRouteHandler,
+			handlers.ProvideProvisioningHandler,
+			handlers.ProvideMaintenanceHandler,
+			scheduler.ProvideScheduler,
+			fx.Annotate(scheduler.ProvideDatabaseMetricsJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvideDemoResetJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvideAPIKeyHygieneJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvideDataRetentionJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvidePerformancePurgeJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvideIntegrationProbeJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(scheduler.ProvideKeycloakSyncJob, fx.ResultTags(`group:"jobs"`)),
 		),
-		fx.Invoke(func(*http.Server) {}),
+		modeOptions,
+		// Leave the hard timeout to the shutdown watchdog, which reports what is stuck
+		fx.StopTimeout(cfg.ShutdownHardTimeout+5*time.Second),
 	).Run()
 }
 
+// SeedDemoData seeds the demo dataset on startup when demo mode is enabled and the
+// database is empty
+func SeedDemoData(lc fx.Lifecycle, cfg *config.Config, demoService services.DemoService) {
+	if !cfg.DemoMode {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Sugar.Warn("Demo mode is enabled, data can be reset through POST /api/v1/demo/reset")
+			if _, err := demoService.SeedIfEmpty(ctx); err != nil {
+				return fmt.Errorf("seed demo data: %w", err)
+			}
+			return nil
+		},
+	})
+}
+
 func ProvideValidator() *validator.Validate {
 	return validator.New()
 }
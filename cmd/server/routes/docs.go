@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"golang-boilerplate/docs"
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/routing"
+
+	echoSwagger "github.com/swaggo/echo-swagger"
+	"github.com/swaggo/swag"
+)
+
+// apiDocs are the Swagger specifications of the API versions, from the oldest to the
+// latest, each registered by swag under the instance name of its docs package
+var apiDocs = []struct {
+	Version string
+	Spec    *swag.Spec
+}{
+	{Version: "v1", Spec: docs.SwaggerInfo},
+}
+
+// docsAccess returns the middlewares protecting the docs routes as DOCS_ACCESS requires,
+// and false when the docs are disabled
+func docsAccess(cfg *config.Config, basicAuth routing.Middleware, token routing.Middleware, roles func(...string) routing.Middleware) ([]routing.Middleware, bool) {
+	switch cfg.DocsAccess {
+	case constants.DocsAccessPublic:
+		return nil, true
+	case constants.DocsAccessBasic:
+		return []routing.Middleware{basicAuth}, true
+	case constants.DocsAccessRole:
+		return []routing.Middleware{token, roles(cfg.DocsRoles...)}, true
+	default:
+		return nil, false
+	}
+}
+
+// registerDocs serves the Swagger UI and the specification of each API version under
+// /swagger/{version}/, e.g. /swagger/v1/doc.json, and those of the latest version under
+// /swagger/
+func registerDocs(root *routing.Group, access []routing.Middleware) {
+	for _, apiDoc := range apiDocs {
+		root.GET("/swagger/"+apiDoc.Version+"/*", echoSwagger.EchoWrapHandler(echoSwagger.InstanceName(apiDoc.Spec.InstanceName())), access...)
+	}
+	latest := apiDocs[len(apiDocs)-1]
+	root.GET("/swagger/*", echoSwagger.EchoWrapHandler(echoSwagger.InstanceName(latest.Spec.InstanceName())), access...)
+}
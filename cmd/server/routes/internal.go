@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/handlers"
+	middlewares "golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/routing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// InternalRouter serves the cluster-only endpoints on the internal listener: database
+// metrics, Prometheus metrics, pprof, the dead-letter queue, the configuration, log level and cache flush of the
+// instance and the internal APIs. They are not registered on
+// the public router, so they stay unreachable through the ingress whatever its middleware
+// configuration.
+func InternalRouter(
+	healthHandler *handlers.HealthHandler,
+	jobHandler *handlers.JobHandler,
+	configHandler *handlers.ConfigHandler,
+	internalAdminHandler *handlers.InternalAdminHandler,
+	catalog *routing.Catalog,
+	cfg *config.Config,
+) *echo.Echo {
+	r := echo.New()
+	root := catalog.Listener(constants.ListenerInternal, r)
+
+	root.Use(routing.Describe("request_id", middleware.RequestID()))
+	root.Use(routing.Describe("request_context", middlewares.RequestContext(cfg.AppName)))
+	root.Use(routing.Describe("recovery", errors.RecoveryMiddleware(cfg)))
+	root.Use(routing.Describe("errors", errors.ErrorMiddleware()))
+	root.Use(routing.Middleware{
+		Name:    "internal_network",
+		Schemes: []string{constants.RouteSchemeInternalNetwork},
+		Func:    middlewares.InternalNetwork(cfg.InternalAllowedCIDRs),
+	})
+
+	root.GET("/", healthHandler.HealthCheck)
+
+	// Prometheus metrics of the instance
+	root.GET(constants.InternalMetricsPath, internalAdminHandler.Metrics)
+
+	// Profiling, e.g. `go tool pprof http://<pod>:3001/debug/pprof/heap`
+	pprofGroup := root.Group(constants.InternalPprofPath)
+	pprofGroup.GET("/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	pprofGroup.GET("/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	pprofGroup.GET("/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	pprofGroup.GET("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	pprofGroup.POST("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	pprofGroup.GET("/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	pprofGroup.GET("/:profile", func(c echo.Context) error {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Response(), c.Request())
+		return nil
+	})
+
+	// Internal APIs, only reachable from inside the cluster
+	internalGroup := root.Group(constants.InternalAPIPrefix)
+	internalGroup.GET("/health/database", healthHandler.DatabaseHealthCheck)
+	internalGroup.GET("/health/auth", healthHandler.AuthHealthCheck)
+	internalGroup.GET("/health/dependencies", healthHandler.DependenciesHealthCheck)
+	internalGroup.GET("/health/metrics", healthHandler.DatabaseMetrics)
+
+	// Dead-letter queue of the task queue
+	internalGroup.GET("/jobs/dead", jobHandler.GetDeadJobs)
+	internalGroup.POST("/jobs/:id/requeue", jobHandler.RequeueJob)
+
+	// Operations of the running instance
+	internalGroup.GET("/config", configHandler.GetInternalConfig)
+	internalGroup.GET("/log-level", internalAdminHandler.GetLogLevel)
+	internalGroup.PUT("/log-level", internalAdminHandler.SetLogLevel)
+	internalGroup.POST("/cache/flush", internalAdminHandler.FlushCache)
+
+	return r
+}
@@ -5,36 +5,146 @@ import (
 
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/deprecation"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/handlers"
 	"golang-boilerplate/internal/integration/auth"
 	middlewares "golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/routing"
+	"golang-boilerplate/internal/services"
 
 	"github.com/getsentry/sentry-go"
 	sentryecho "github.com/getsentry/sentry-go/echo"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/newrelic/go-agent/v3/newrelic"
-	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 func Router(
 	userHandler *handlers.UserHandler,
 	companyHandler *handlers.CompanyHandler,
 	healthHandler *handlers.HealthHandler,
+	demoHandler *handlers.DemoHandler,
+	credentialHandler *handlers.TenantCredentialHandler,
+	graphqlHandler *handlers.GraphQLHandler,
+	realtimeHandler *handlers.RealtimeHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	apiKeyService services.APIKeyService,
+	apiKeyUsage *services.APIKeyUsageRecorder,
+	performanceRecorder *services.PerformanceRecorder,
+	onboardingHandler *handlers.OnboardingHandler,
+	retentionHandler *handlers.RetentionHandler,
+	uploadPolicyHandler *handlers.UploadPolicyHandler,
+	webhookHandler *handlers.WebhookHandler,
+	dashboardHandler *handlers.DashboardHandler,
+	sandboxHandler *handlers.SandboxHandler,
+	devInboxHandler *handlers.DevInboxHandler,
+	performanceHandler *handlers.PerformanceHandler,
+	exportHandler *handlers.ExportHandler,
+	routeHandler *handlers.RouteHandler,
+	provisioningHandler *handlers.ProvisioningHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	deprecationHandler *handlers.DeprecationHandler,
+	authHandler *handlers.AuthHandler,
+	rbacHandler *handlers.RBACHandler,
+	invitationHandler *handlers.InvitationHandler,
+	mfaHandler *handlers.MFAHandler,
+	scimHandler *handlers.SCIMHandler,
+	sessionHandler *handlers.SessionHandler,
+	keycloakSyncHandler *handlers.KeycloakSyncHandler,
+	emailHandler *handlers.EmailHandler,
+	paymentWebhookHandler *handlers.PaymentWebhookHandler,
+	billingHandler *handlers.BillingHandler,
+	billingService services.BillingService,
+	companyRepo repositories.CompanyRepository,
+	paymentHandler *handlers.PaymentHandler,
+	invoiceHandler *handlers.InvoiceHandler,
+	fileHandler *handlers.FileHandler,
+	uploadHandler *handlers.UploadHandler,
+	deprecations *deprecation.Registry,
+	deprecationUsage *services.DeprecationUsageRecorder,
 	authService auth.AuthService,
 	nrApp *newrelic.Application,
+	catalog *routing.Catalog,
 	cfg *config.Config,
 ) *echo.Echo {
 	r := echo.New()
+	// Requests setting a deprecated field get the headers of its deprecation
+	r.Binder = middlewares.NewDeprecationBinder(cfg, deprecations, deprecationUsage)
+	root := catalog.Listener(constants.ListenerPublic, r)
+
+	// Route middlewares, described for the route catalog
+	token := routing.Middleware{
+		Name:    "auth",
+		Schemes: []string{constants.RouteSchemeBearer},
+		Func:    middlewares.AuthMiddleware(cfg, authService),
+	}
+	// introspectedToken authenticates like token, with the token introspected by Keycloak
+	// so that a revoked token is rejected at once, for the revocation-sensitive routes
+	introspectedToken := routing.Middleware{
+		Name:    "auth_introspected",
+		Schemes: []string{constants.RouteSchemeBearer},
+		Func:    middlewares.IntrospectedAuthMiddleware(cfg, authService),
+	}
+	roles := func(roles ...string) routing.Middleware {
+		return routing.Middleware{Name: "require_role", Roles: roles, Func: middlewares.RequireRole(cfg, roles...)}
+	}
+	// requireMFA rejects the tokens of a login without a second factor on the sensitive
+	// routes, when MFA_ENFORCED is set
+	requireMFA := routing.Middleware{Name: "require_mfa", Func: middlewares.RequireMFA(cfg)}
+	// apiKeyOrToken accepts an API key of the company of the route, or a token granted
+	// any of the roles
+	apiKeyOrToken := func(roles ...string) routing.Middleware {
+		return routing.Middleware{
+			Name:    "api_key_or_token",
+			Schemes: []string{constants.RouteSchemeAPIKey, constants.RouteSchemeBearer},
+			Roles:   roles,
+			Func: middlewares.APIKeyOrToken(apiKeyService, apiKeyUsage,
+				middlewares.AuthMiddleware(cfg, authService),
+				middlewares.RequireRole(cfg, roles...),
+			),
+		}
+	}
+	basicAuth := routing.Middleware{
+		Name:    "basic_auth",
+		Schemes: []string{constants.RouteSchemeBasic},
+		Func:    middlewares.BasicAuthMiddleware(*cfg),
+	}
+	// scimToken authenticates the identity providers provisioning the users
+	scimToken := routing.Middleware{
+		Name:    "scim_auth",
+		Schemes: []string{constants.RouteSchemeBearer},
+		Func:    middlewares.SCIMAuth(cfg),
+	}
+	// keycloakEventSignature authenticates the events posted by the event listener of the
+	// realm
+	keycloakEventSignature := routing.Middleware{
+		Name:    "keycloak_event_signature",
+		Schemes: []string{constants.RouteSchemeSignature},
+		Func:    middlewares.KeycloakEventSignature(cfg),
+	}
+	// entitled requires the plan of the company of the route to grant a feature
+	entitled := func(key string) routing.Middleware {
+		return routing.Describe("require_entitlement", middlewares.RequireEntitlement(billingService, key))
+	}
+	// tenant runs the database sessions of a company route as the company of the
+	// principal, and forbids the other companies, when DATABASE_RLS_ENABLED is set
+	tenant := routing.Describe("database_tenant", middlewares.DatabaseTenant(cfg, companyRepo))
+	deprecated := func(id string) routing.Middleware {
+		return routing.Describe("deprecated", middlewares.DeprecatedRoute(cfg, deprecations, deprecationUsage, id))
+	}
 
 	// Once it's done, you can attach the handler as one of your middleware
-	r.Use(sentryecho.New(sentryecho.Options{
+	root.Use(routing.Describe("sentry", sentryecho.New(sentryecho.Options{
 		Repanic: true,
-	}))
+	})))
+
+	// Start a New Relic transaction per request; user attributes are added by AuthMiddleware
+	root.Use(routing.Describe("new_relic", middlewares.NewRelicTransaction(nrApp)))
 
 	// Custom error handler middleware to capture errors and report to Sentry
-	r.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+	root.Use(routing.Describe("sentry_errors", func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			err := next(c)
 			if err != nil {
@@ -58,11 +168,6 @@ func Router(
 						scope.SetTag("service", cfg.AppName)
 						scope.SetTag("handler", c.Path())
 
-						// Add organization context if available
-						if orgID := c.Get("organization_id"); orgID != nil {
-							scope.SetTag("organization_id", orgID.(string))
-						}
-
 						// Add error type tag
 						if echoErr, ok := err.(*echo.HTTPError); ok {
 							scope.SetTag("error_type", "http_error")
@@ -78,93 +183,742 @@ func Router(
 			}
 			return err
 		}
-	})
+	}))
+
+	root.Use(routing.Describe("log_body", middlewares.LogBodyMiddleware))
+	root.Use(routing.Describe("request_id", middleware.RequestID()))
+	root.Use(routing.Describe("request_context", middlewares.RequestContext(cfg.AppName)))
+	root.Use(routing.Describe("recovery", errors.RecoveryMiddleware(cfg))) // Add panic recovery
+	root.Use(routing.Describe("errors", errors.ErrorMiddleware()))         // Add centralized error handling
+	root.Use(routing.Describe("security_headers", middlewares.Security())) // Add secure headers (XSS, HSTS, etc.)
+	root.Use(routing.Describe("cors", middlewares.CORS()))
+	root.Use(routing.Describe("csrf", middlewares.CSRF(cfg)))
+	root.Use(routing.Describe("csrf_token", middlewares.ExposeCSRFToken()))
+	root.Use(routing.Describe("rate_limit", middlewares.DefaultRateLimit()))
+	root.Use(routing.Describe("request_logging", middlewares.RequestLogging(cfg)))
+	root.Use(routing.Describe("load_shedding", middlewares.LoadShedding(cfg, nrApp)))
+	root.Use(routing.Describe("demo_mode", middlewares.DemoMode(cfg)))
+	root.Use(routing.Describe("envelope", middlewares.Envelope(constants.EnvelopeV1)))
+	root.Use(routing.Describe("tenant_performance", middlewares.TenantPerformance(performanceRecorder)))
+	root.Use(routing.Describe("surrogate_keys", middlewares.SurrogateKeys(cfg)))
+
+	// Kubernetes probes
+	root.GET(constants.LivenessPath, healthHandler.Liveness)
+	root.GET(constants.ReadinessPath, healthHandler.Readiness)
+
+	if access, enabled := docsAccess(cfg, basicAuth, token, roles); enabled {
+		registerDocs(root, access)
+		root.GET("/graphql/playground", graphqlHandler.Playground, access...)
+	}
+
+	// SCIM 2.0 provisioning, at the root like the identity providers expect, only
+	// registered when the SCIM token is set
+	if cfg.SCIMToken != "" {
+		scimGroup := root.Group(constants.SCIMBasePath)
+
+		scimGroup.GET("/ServiceProviderConfig", scimHandler.GetServiceProviderConfig, scimToken)
+		scimGroup.GET("/ResourceTypes", scimHandler.GetResourceTypes, scimToken)
 
-	r.Use(middlewares.LogBodyMiddleware)
-	r.Use(middleware.RequestID())
-	r.Use(middlewares.RequestContext(cfg.AppName))
-	r.Use(errors.RecoveryMiddleware(cfg)) // Add panic recovery
-	r.Use(errors.ErrorMiddleware())       // Add centralized error handling
-	r.Use(middlewares.Security())         // Add secure headers (XSS, HSTS, etc.)
-	r.Use(middlewares.CORS())
-	r.Use(middlewares.CSRF(cfg))
-	r.Use(middlewares.ExposeCSRFToken())
-	r.Use(middlewares.DefaultRateLimit())
-	r.Use(middlewares.RequestLogging(cfg))
+		scimGroup.GET("/Users", scimHandler.ListUsers, scimToken)
+		scimGroup.POST("/Users", scimHandler.CreateUser, scimToken)
+		scimGroup.GET("/Users/:id", scimHandler.GetUser, scimToken)
+		scimGroup.PUT("/Users/:id", scimHandler.ReplaceUser, scimToken)
+		scimGroup.PATCH("/Users/:id", scimHandler.PatchUser, scimToken)
+		scimGroup.DELETE("/Users/:id", scimHandler.DeleteUser, scimToken)
 
-	if cfg.AppEnv != config.EnvironmentProduction {
-		r.GET("/swagger/*", echoSwagger.WrapHandler, middlewares.BasicAuthMiddleware(*cfg))
+		scimGroup.GET("/Groups", scimHandler.ListGroups, scimToken)
+		scimGroup.POST("/Groups", scimHandler.CreateGroup, scimToken)
+		scimGroup.GET("/Groups/:id", scimHandler.GetGroup, scimToken)
+		scimGroup.PUT("/Groups/:id", scimHandler.ReplaceGroup, scimToken)
+		scimGroup.PATCH("/Groups/:id", scimHandler.PatchGroup, scimToken)
+		scimGroup.DELETE("/Groups/:id", scimHandler.DeleteGroup, scimToken)
 	}
 
 	// Base API path
 	baseAPI := "api"
 
 	// Version 1 API group
-	v1 := r.Group(baseAPI + "/v1")
+	v1 := root.Group(baseAPI + "/v1")
 
 	// Public routes
 	publicGroup := v1.Group("")
 	publicGroup.GET("/", healthHandler.HealthCheck)
-	publicGroup.GET("/health/database", healthHandler.DatabaseHealthCheck)
-	publicGroup.GET("/health/metrics", healthHandler.DatabaseMetrics)
+	publicGroup.GET("/health/database", healthHandler.DatabaseHealthCheck, deprecated(constants.DeprecationHealthDatabase))
+	publicGroup.GET("/health/auth", healthHandler.AuthHealthCheck, deprecated(constants.DeprecationHealthAuth))
+	publicGroup.GET("/health/dependencies", healthHandler.DependenciesHealthCheck)
+
+	// Session of the authenticated user
+	v1.POST("/auth/switch-org", authHandler.SwitchOrganization, introspectedToken)
+	v1.POST("/auth/exchange", authHandler.ExchangeToken, introspectedToken)
+
+	// Login of the first-party clients; the bodies hold credentials and refresh tokens
+	redactBody := routing.Describe("redact_body", middlewares.RedactLogBody())
+	v1.POST("/auth/login", authHandler.Login, redactBody, routing.Describe("auth_rate_limit", middlewares.AuthRateLimit()))
+	v1.POST("/auth/refresh", authHandler.RefreshToken, redactBody)
+
+	// Acceptance of an invitation by its token; the body may hold the password of the
+	// new account
+	v1.POST("/invitations/accept", invitationHandler.AcceptInvitation,
+		redactBody,
+		routing.Describe("auth_rate_limit", middlewares.AuthRateLimit()),
+	)
 
 	// User routes
 	userGroup := v1.Group("/users")
 
 	userGroup.GET("", userHandler.GetUsers,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.UserViewRoles...),
+		token,
+		roles(constants.UserViewRoles...),
 	)
 
-	userGroup.GET("/test-rest-client", userHandler.TestRestClient, middlewares.AuthMiddleware(cfg, authService))
+	userGroup.GET("/search", userHandler.SearchUsers,
+		token,
+		roles(constants.UserViewRoles...),
+	)
+
+	userGroup.GET("/test-rest-client", userHandler.TestRestClient, token)
 
 	userGroup.POST("", userHandler.CreateUser,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin, constants.RoleUserManager),
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserManager),
+	)
+
+	userGroup.POST("/import", userHandler.ImportUsers,
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserManager),
 	)
 
 	userGroup.GET("/:id", userHandler.GetOneByID,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin, constants.RoleUserViewer),
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserViewer),
 	)
 
 	userGroup.PUT("/:id", userHandler.UpdateUser,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.UserManagementRoles...),
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.PATCH("/:id", userHandler.PatchUser,
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.GET("/:id/roles", rbacHandler.GetUserRoles,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	userGroup.PUT("/:id/roles", rbacHandler.SetUserRoles,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	// Session routes, for admins and for the user themselves
+	userGroup.GET("/:id/sessions", sessionHandler.GetSessions, token)
+
+	userGroup.DELETE("/:id/sessions/:sessionId", sessionHandler.RevokeSession, token)
+
+	// MFA routes
+	userGroup.GET("/:id/mfa", mfaHandler.GetMFAStatus,
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserViewer),
+	)
+
+	userGroup.POST("/:id/mfa/require", mfaHandler.RequireMFA,
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.POST("/:id/mfa/enroll", mfaHandler.EnrollMFA,
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.DELETE("/:id/mfa", mfaHandler.ResetMFA,
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserManager),
+		requireMFA,
+	)
+
+	userGroup.GET("/:id/avatar", userHandler.GetAvatar,
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserViewer),
+	)
+
+	userGroup.PUT("/:id/avatar", userHandler.UploadAvatar,
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.POST("/:id/companies/:companyId", userHandler.AddCompany,
+		token,
+		roles(constants.UserManagementRoles...),
+	)
+
+	userGroup.DELETE("/:id/companies/:companyId", userHandler.RemoveCompany,
+		token,
+		roles(constants.UserManagementRoles...),
 	)
 
 	userGroup.DELETE("/:id", userHandler.DeleteUser,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin, constants.RoleUserManager),
+		token,
+		roles(constants.RoleAdmin, constants.RoleUserManager),
+		requireMFA,
 	)
 
-	// Company routes
+	// Company routes; the reads of a company also accept its API keys
 	companyGroup := v1.Group("/companies")
 
 	companyGroup.POST("", companyHandler.CreateCompany,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyCreator),
+		token,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyCreator),
+	)
+
+	companyGroup.POST("/with-logo", companyHandler.CreateCompanyWithLogo,
+		token,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyCreator),
 	)
 
 	companyGroup.GET("/:id", companyHandler.GetOneByID,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.CompanyViewRoles...),
+		apiKeyOrToken(constants.CompanyViewRoles...),
+		tenant,
+	)
+
+	companyGroup.GET("/:id/members", companyHandler.GetMembers,
+		apiKeyOrToken(constants.CompanyViewRoles...),
+		tenant,
 	)
 
 	companyGroup.PUT("/:id", companyHandler.UpdateCompany,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyEditor),
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyEditor),
+	)
+
+	companyGroup.PATCH("/:id", companyHandler.PatchCompany,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager, constants.RoleCompanyEditor),
 	)
 
 	companyGroup.DELETE("/:id", companyHandler.DeleteCompany,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.RoleAdmin),
+		token,
+		tenant,
+		roles(constants.RoleAdmin),
 	)
 
 	companyGroup.GET("", companyHandler.GetCompanies,
-		middlewares.AuthMiddleware(cfg, authService),
-		middlewares.RequireRole(cfg, constants.CompanyViewRoles...),
+		token,
+		roles(constants.CompanyViewRoles...),
+	)
+
+	// Tenant credential routes
+	companyGroup.GET("/:id/credentials", credentialHandler.GetCredentials,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/credentials", credentialHandler.CreateCredential,
+		introspectedToken,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+		requireMFA,
+	)
+
+	companyGroup.GET("/:id/credentials/:credentialId", credentialHandler.GetCredential,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/credentials/:credentialId/rotate", credentialHandler.RotateCredential,
+		introspectedToken,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+		requireMFA,
+	)
+
+	companyGroup.DELETE("/:id/credentials/:credentialId", credentialHandler.DeleteCredential,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// API key routes
+	companyGroup.GET("/:id/api-keys", apiKeyHandler.GetAPIKeys,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/api-keys", apiKeyHandler.CreateAPIKey,
+		introspectedToken,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+		requireMFA,
+	)
+
+	companyGroup.GET("/:id/api-keys/:keyId", apiKeyHandler.GetAPIKey,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/api-keys/:keyId/usage", apiKeyHandler.GetAPIKeyUsage,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/api-keys/:keyId", apiKeyHandler.RevokeAPIKey,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// Invitation routes
+	companyGroup.GET("/:id/invitations", invitationHandler.GetInvitations,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/invitations", invitationHandler.CreateInvitation,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/invitations/:invitationId", invitationHandler.RevokeInvitation,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// Retention routes; placing a legal hold is open to the company managers, clearing it
+	// is reserved to the admins
+	companyGroup.GET("/:id/retention", retentionHandler.GetRetention,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.PUT("/:id/retention/:resource", retentionHandler.UpdateRetentionPolicy,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/retention/:resource", retentionHandler.DeleteRetentionPolicy,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.PUT("/:id/legal-hold", retentionHandler.PlaceCompanyLegalHold,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/legal-hold", retentionHandler.ClearCompanyLegalHold,
+		token,
+		tenant,
+		roles(constants.RoleAdmin),
+	)
+
+	companyGroup.PUT("/:id/api-keys/:keyId/legal-hold", retentionHandler.PlaceAPIKeyLegalHold,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/api-keys/:keyId/legal-hold", retentionHandler.ClearAPIKeyLegalHold,
+		token,
+		tenant,
+		roles(constants.RoleAdmin),
+	)
+
+	// Upload policy routes
+	companyGroup.GET("/:id/upload-policy", uploadPolicyHandler.GetUploadPolicy,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.PUT("/:id/upload-policy", uploadPolicyHandler.UpdateUploadPolicy,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.DELETE("/:id/upload-policy", uploadPolicyHandler.DeleteUploadPolicy,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// Webhook routes
+	companyGroup.GET("/:id/webhooks", webhookHandler.GetWebhooks,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/webhooks", webhookHandler.CreateWebhook,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/webhooks/:webhookId", webhookHandler.GetWebhook,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.PATCH("/:id/webhooks/:webhookId", webhookHandler.UpdateWebhook,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/webhooks/:webhookId/rotate-secret", webhookHandler.RotateWebhookSecret,
+		introspectedToken,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/webhooks/:webhookId/test", webhookHandler.TestWebhook,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+		entitled(constants.EntitlementWebhooks),
+	)
+
+	companyGroup.DELETE("/:id/webhooks/:webhookId", webhookHandler.DeleteWebhook,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/webhooks/:webhookId/deliveries", webhookHandler.GetWebhookDeliveries,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/webhooks/deliveries", webhookHandler.GetCompanyWebhookDeliveries,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/webhooks/deliveries/:deliveryId", webhookHandler.GetWebhookDelivery,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/webhooks/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhookDelivery,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+		entitled(constants.EntitlementWebhooks),
+	)
+
+	// Subscription routes; the plan gates the features of the company through the
+	// entitled middleware and the quotas checked by the services
+	companyGroup.GET("/:id/subscription", billingHandler.GetSubscription,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.POST("/:id/subscription", billingHandler.ChangeSubscription,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// Invoice routes, the PDF documents are downloaded from presigned URLs
+	companyGroup.GET("/:id/invoices", invoiceHandler.GetInvoices,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	companyGroup.GET("/:id/invoices/:invoiceId/pdf", invoiceHandler.GetInvoicePDF,
+		token,
+		tenant,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// Sandbox routes, the inbox is also readable with the sandbox API keys
+	companyGroup.GET("/:id/sandbox/emails", sandboxHandler.GetSandboxEmails,
+		apiKeyOrToken(constants.RoleAdmin, constants.RoleCompanyManager),
+		tenant,
+	)
+
+	companyGroup.GET("/:id/sandbox/emails/:emailId", sandboxHandler.GetSandboxEmail,
+		apiKeyOrToken(constants.RoleAdmin, constants.RoleCompanyManager),
+		tenant,
+	)
+
+	// Dashboard routes, served from the read models
+	companyGroup.GET("/:id/summary", dashboardHandler.GetCompanySummary,
+		token,
+		tenant,
+		roles(constants.CompanyViewRoles...),
+	)
+
+	v1.GET("/dashboard/companies", dashboardHandler.GetCompanySummaries,
+		token,
+		roles(constants.RoleAdmin),
 	)
 
+	v1.GET("/admin/dashboard", dashboardHandler.GetAdminDashboard,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Health of the integrations over time, from their scheduled probes
+	v1.GET("/admin/integrations/health", healthHandler.GetIntegrationsHealth,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Events of the realm, only registered when their signing secret is set
+	if cfg.KeycloakEventsSecret != "" {
+		root.POST(constants.KeycloakEventsPath, keycloakSyncHandler.ReceiveEvent, keycloakEventSignature)
+	}
+
+	// Reconciliation of the users and companies with Keycloak, on demand
+	v1.POST("/admin/keycloak-sync", keycloakSyncHandler.SyncKeycloak,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	// Bounces and complaints of SES, only registered when their SNS topic is set
+	if cfg.SESNotificationsTopicARN != "" {
+		root.POST(constants.SESNotificationsPath, emailHandler.ReceiveSESNotification)
+	}
+
+	// Events of the payment providers, only registered when a provider webhook is set
+	if cfg.StripeWebhookSecret != "" || cfg.PayPalWebhookID != "" {
+		root.POST(constants.PaymentWebhookPath, paymentWebhookHandler.ReceiveEvent)
+	}
+
+	// Refunds, captures and voids of the payments, recorded with the admin initiating them
+	paymentGroup := v1.Group("/admin/payments")
+
+	paymentGroup.GET("/:id/operations", paymentHandler.GetPaymentOperations,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	paymentGroup.POST("/:id/refund", paymentHandler.RefundPayment,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	paymentGroup.POST("/:id/capture", paymentHandler.CapturePayment,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	paymentGroup.POST("/:id/void", paymentHandler.VoidPayment,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	// Files of the registry, listed and deleted by the admins and streamed through the
+	// server, the roles of a download are checked by the prefix of the key of the file
+	fileGroup := v1.Group("/files")
+
+	fileGroup.GET("", fileHandler.GetFiles,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	fileGroup.DELETE("/:id", fileHandler.DeleteFile,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	fileGroup.GET("/:id/download", fileHandler.DownloadFile,
+		token,
+	)
+
+	// Presigned uploads, posted by the clients straight to the storage
+	uploadGroup := v1.Group("/uploads")
+
+	uploadGroup.POST("/presign", uploadHandler.PresignUpload,
+		token,
+	)
+
+	uploadGroup.POST("/:id/complete", uploadHandler.CompleteUpload,
+		token,
+	)
+
+	// Queued emails still failing after their attempts
+	emailGroup := v1.Group("/admin/emails")
+
+	emailGroup.GET("/failed", emailHandler.GetFailedEmails,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	emailGroup.POST("/failed/redrive", emailHandler.RedriveFailedEmails,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Usage routes, for the company of the token organization
+	v1.GET("/usage/performance", performanceHandler.GetPerformance, token)
+
+	// Onboarding routes, for the company of the token organization
+	onboardingGroup := v1.Group("/onboarding")
+
+	onboardingGroup.GET("", onboardingHandler.GetOnboarding, token)
+
+	onboardingGroup.PUT("/steps/:step", onboardingHandler.UpdateOnboardingStep,
+		token,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	onboardingGroup.DELETE("/steps/:step", onboardingHandler.ResetOnboardingStep,
+		token,
+		roles(constants.RoleAdmin, constants.RoleCompanyManager),
+	)
+
+	// GraphQL routes, roles are checked per query by the resolvers
+	v1.GET("/graphql", graphqlHandler.Query, token)
+	v1.POST("/graphql", graphqlHandler.Query, token)
+
+	// Realtime routes, events are pushed to the connections and streams of the token subject
+	v1.GET("/realtime/ws", realtimeHandler.Connect,
+		routing.Describe("websocket_token", middlewares.WebSocketToken()),
+		token,
+	)
+	v1.GET("/events/stream", realtimeHandler.Stream, token)
+
+	// Audit records of the exports of the listings
+	v1.GET("/admin/exports", exportHandler.GetExports,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Registered routes with their middlewares, schemes and roles
+	v1.GET("/admin/routes", routeHandler.GetRoutes,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Deprecated routes and fields with the consumers still using them
+	v1.GET("/admin/deprecations", deprecationHandler.GetDeprecations,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Idempotent provisioning of the tenants, for the provisioning pipeline
+	v1.PUT("/provisioning/tenants/:slug", provisioningHandler.ProvisionTenant,
+		token,
+		roles(constants.RoleAdmin, constants.RoleTenantProvisioner),
+	)
+
+	// Maintenance tasks, run in batches on the workers
+	maintenanceGroup := v1.Group("/admin/maintenance")
+
+	maintenanceGroup.GET("/operations", maintenanceHandler.GetOperations,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.POST("/tasks", maintenanceHandler.CreateTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.GET("/tasks", maintenanceHandler.GetTasks,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.GET("/tasks/:id", maintenanceHandler.GetTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.PATCH("/tasks/:id", maintenanceHandler.UpdateTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.POST("/tasks/:id/pause", maintenanceHandler.PauseTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.POST("/tasks/:id/resume", maintenanceHandler.ResumeTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	maintenanceGroup.POST("/tasks/:id/cancel", maintenanceHandler.CancelTask,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	// Roles and permissions of the database RBAC, checked by RequireDBPermission
+	rbacGroup := v1.Group("/rbac")
+
+	rbacGroup.GET("/roles", rbacHandler.ListRoles,
+		token,
+		roles(constants.RoleAdmin),
+	)
+
+	rbacGroup.PUT("/roles/:name", rbacHandler.SaveRole,
+		token,
+		roles(constants.RoleAdmin),
+		requireMFA,
+	)
+
+	// Dev inbox routes, only registered when the emails are captured
+	if cfg.EmailCapture {
+		devInboxGroup := v1.Group("/admin/dev-inbox")
+
+		devInboxGroup.GET("", devInboxHandler.GetDevInboxEmails,
+			token,
+			roles(constants.RoleAdmin),
+		)
+
+		devInboxGroup.GET("/:emailId", devInboxHandler.GetDevInboxEmail,
+			token,
+			roles(constants.RoleAdmin),
+		)
+
+		devInboxGroup.GET("/:emailId/preview", devInboxHandler.PreviewDevInboxEmail,
+			token,
+			roles(constants.RoleAdmin),
+		)
+	}
+
+	// Demo routes, only registered in demo mode
+	if cfg.DemoMode {
+		demoGroup := v1.Group("/demo")
+
+		demoGroup.POST("/reset", demoHandler.ResetDemo,
+			token,
+			roles(constants.RoleAdmin),
+		)
+	}
+
 	return r
 }
@@ -46,14 +46,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/companies": {
+        "/admin/dashboard": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get companies",
+                "description": "Get the key stats of the admin frontends in one call: the user counts and recent signups, the tenants, the failing webhook endpoints, the job queue depth and the health of the dependencies. The dashboard is cached for 30 seconds; generated_at tells when it was computed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -61,59 +61,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
-                ],
-                "summary": "Get companies",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Page size",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"2025-09-11T02:17:24.290538Z\"",
-                        "description": "Start date",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"2025-09-11T02:17:24.290538Z\"",
-                        "description": "End date",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "example": "\"A\"",
-                        "description": "Query",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "created_at",
-                            "-created_at",
-                            "name",
-                            "-name"
-                        ],
-                        "type": "string",
-                        "example": "\"[-created_at,name]\"",
-                        "description": "Sort",
-                        "name": "sort",
-                        "in": "query"
-                    }
+                    "Dashboard"
                 ],
+                "summary": "Get admin dashboard",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -121,10 +71,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/dtos.CompanyResponse"
-                                    }
+                                    "$ref": "#/definitions/dtos.AdminDashboardResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -133,14 +80,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/deprecations": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create company",
+                "description": "Get the deprecated routes and request fields, the closest sunset first, with the consumers that still use them: API keys, OAuth clients or users, their tenant, number of calls and when they were first and last seen. Recent calls can take up to DEPRECATION_USAGE_FLUSH_INTERVAL to be counted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -148,20 +97,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
-                ],
-                "summary": "Create company",
-                "parameters": [
-                    {
-                        "description": "Company",
-                        "name": "company",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.CreateCompanyRequest"
-                        }
-                    }
+                    "Admin"
                 ],
+                "summary": "Get deprecation report",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -169,7 +107,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                    "$ref": "#/definitions/dtos.DeprecationReportResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -180,14 +118,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/companies/{id}": {
+        "/admin/dev-inbox": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get company by ID",
+                "description": "Search the emails captured instead of being sent, most recent first. Only available when EMAIL_CAPTURE is enabled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -195,16 +133,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "DevInbox"
                 ],
-                "summary": "Get company by ID",
+                "summary": "Get dev inbox emails",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "example": "\"Welcome\"",
+                        "description": "Search in the subject, recipients and bodies",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"jane@example.com\"",
+                        "description": "To, Cc or Bcc address",
+                        "name": "recipient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -214,7 +173,10 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.CapturedEmailResponse"
+                                    }
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -223,14 +185,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/dev-inbox/{emailId}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update company",
+                "description": "Get an email captured instead of being sent, with its bodies. Only available when EMAIL_CAPTURE is enabled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -238,25 +202,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "DevInbox"
                 ],
-                "summary": "Update company",
+                "summary": "Get dev inbox email by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Company ID",
-                        "name": "id",
+                        "description": "Email ID",
+                        "name": "emailId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Company",
-                        "name": "company",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.UpdateCompanyRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -266,7 +221,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                    "$ref": "#/definitions/dtos.CapturedEmailResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -275,54 +230,50 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/dev-inbox/{emailId}/preview": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete company",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Render the HTML body of a captured email, or its text body when it has none, as a sandboxed page without scripts. Only available when EMAIL_CAPTURE is enabled.",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "Company"
+                    "DevInbox"
                 ],
-                "summary": "Delete company",
+                "summary": "Preview dev inbox email",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Company ID",
-                        "name": "id",
+                        "description": "Email ID",
+                        "name": "emailId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "HTML body",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "data": {
-                                    "$ref": "#/definitions/dtos.CompanyResponse"
-                                },
-                                "meta": {
-                                    "$ref": "#/definitions/dtos.Meta"
-                                }
-                            }
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/health/database": {
+        "/admin/emails/failed": {
             "get": {
-                "description": "Check if the database connection is healthy",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the queued emails still failing after their attempts, kept in the dead-letter queue, most recently failed first",
                 "consumes": [
                     "application/json"
                 ],
@@ -330,9 +281,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Emails"
+                ],
+                "summary": "Get failed emails",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Database Health Check",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -340,7 +307,10 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "type": "object"
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.FailedEmailResponse"
+                                    }
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -351,9 +321,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/health/metrics": {
-            "get": {
-                "description": "Get detailed database connection metrics",
+        "/admin/emails/failed/redrive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue the failed emails again with all their attempts, those of ids or all of them when no ids are given",
                 "consumes": [
                     "application/json"
                 ],
@@ -361,9 +336,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Emails"
+                ],
+                "summary": "Re-drive failed emails",
+                "parameters": [
+                    {
+                        "description": "Failed emails",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RedriveFailedEmailsRequest"
+                        }
+                    }
                 ],
-                "summary": "Database Connection Metrics",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -371,7 +356,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "type": "object"
+                                    "$ref": "#/definitions/dtos.RedriveFailedEmailsResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -382,14 +367,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/admin/exports": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get users",
+                "description": "Get the audit records of the CSV and NDJSON exports of the listings, most recent first: who exported which entity, with which filters and fields, and how many rows. Exports denied by the export policies are recorded too.",
                 "consumes": [
                     "application/json"
                 ],
@@ -397,22 +382,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Get users",
+                "summary": "Get export audit records",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page",
-                        "name": "page",
+                        "enum": [
+                            "users",
+                            "companies",
+                            "company_members"
+                        ],
+                        "type": "string",
+                        "description": "Exported entity",
+                        "name": "entity",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Page size",
-                        "name": "page_size",
+                        "type": "string",
+                        "example": "\"123\"",
+                        "description": "Subject of the user or ID of the API key",
+                        "name": "principal_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"123\"",
+                        "description": "Company the export is scoped to",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "started",
+                            "completed",
+                            "failed",
+                            "denied"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
                         "in": "query"
                     },
                     {
@@ -430,23 +438,17 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "example": "\"A\"",
-                        "description": "Query",
-                        "name": "q",
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "created_at",
-                            "-created_at",
-                            "name",
-                            "-name"
-                        ],
-                        "type": "string",
-                        "example": "\"[-created_at,name]\"",
-                        "description": "Sort",
-                        "name": "sort",
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
                         "in": "query"
                     }
                 ],
@@ -459,7 +461,7 @@ const docTemplate = `{
                                 "data": {
                                     "type": "array",
                                     "items": {
-                                        "$ref": "#/definitions/dtos.UserResponse"
+                                        "$ref": "#/definitions/dtos.ExportAuditResponse"
                                     }
                                 },
                                 "meta": {
@@ -467,37 +469,114 @@ const docTemplate = `{
                                 }
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/integrations/health": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create user",
-                "consumes": [
+                "description": "Get the health of the integrations from their scheduled probes (INTEGRATION_PROBE_SCHEDULE): the Keycloak token endpoint, the SES send quota, the storage bucket, the payment provider, the database and the cache, with the result and latency of their last probe and their uptime, failures and average latency over the last 24 hours. The probes are recorded by the scheduler, so the integrations are not called by the request.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Integrations health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.IntegrationHealthResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keycloak-sync": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reconcile the users and companies with the users, organizations and client roles of Keycloak, like the scheduled job of KEYCLOAK_SYNC_SCHEDULE: create the users and companies missing locally, link the users to the Keycloak user of their email, fix the drift of their names, emails, memberships and roles, and disable the users disabled in or deleted from Keycloak. With dry_run, the default, the changes are only reported.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Create user",
+                "summary": "Sync with Keycloak",
                 "parameters": [
                     {
-                        "description": "User",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Only report the changes",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.CreateUserRequest"
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.KeycloakSyncReport"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/admin/maintenance/operations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the operations maintenance tasks can run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
                 ],
+                "summary": "Get maintenance operations",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -505,7 +584,10 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.UserResponse"
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.MaintenanceOperationResponse"
+                                    }
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -516,14 +598,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/test-rest-client": {
+        "/admin/maintenance/tasks": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Test rest client",
+                "description": "Get the maintenance tasks and their progress, most recently started first",
                 "consumes": [
                     "application/json"
                 ],
@@ -531,9 +613,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Maintenance"
+                ],
+                "summary": "Get maintenance tasks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Test rest client",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -541,8 +639,55 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "type": "object",
-                                    "additionalProperties": true
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a task of a maintenance operation, run in batches on the workers. An operation has at most one running, paused or failed task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
+                ],
+                "summary": "Create maintenance task",
+                "parameters": [
+                    {
+                        "description": "Task",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateMaintenanceTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -553,14 +698,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/admin/maintenance/tasks/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get user by ID",
+                "description": "Get a maintenance task and its progress",
                 "consumes": [
                     "application/json"
                 ],
@@ -568,13 +713,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Maintenance"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Get maintenance task by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Task ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -587,7 +732,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.UserResponse"
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -597,13 +742,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update user",
+                "description": "Change the batch size and throttling of a running, paused or failed task; a running task applies them from its next batch",
                 "consumes": [
                     "application/json"
                 ],
@@ -611,24 +756,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Maintenance"
                 ],
-                "summary": "Update user",
+                "summary": "Update maintenance task",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Task ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User",
-                        "name": "user",
+                        "description": "Settings",
+                        "name": "task",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.UpdateUserRequest"
+                            "$ref": "#/definitions/dtos.UpdateMaintenanceTaskRequest"
                         }
                     }
                 ],
@@ -639,7 +784,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.UserResponse"
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -648,14 +793,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/maintenance/tasks/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete user",
+                "description": "End a running, paused or failed task; a running task stops after its current batch",
                 "consumes": [
                     "application/json"
                 ],
@@ -663,13 +810,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Maintenance"
                 ],
-                "summary": "Delete user",
+                "summary": "Cancel maintenance task",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Task ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -682,7 +829,7 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "$ref": "#/definitions/dtos.UserResponse"
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
                                 },
                                 "meta": {
                                     "$ref": "#/definitions/dtos.Meta"
@@ -692,71 +839,8679 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "constants.UserStatus": {
-            "type": "string",
-            "enum": [
-                "active",
-                "inactive"
-            ],
-            "x-enum-varnames": [
-                "UserStatusActive",
-                "UserStatusInactive"
-            ]
-        },
-        "dtos.CompanyResponse": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "123"
-                },
-                "keycloak_id": {
-                    "type": "string",
-                    "example": "123"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
-                }
-            }
         },
-        "dtos.CreateCompanyRequest": {
-            "type": "object",
-            "properties": {
-                "keycloak_id": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "123"
-                },
-                "name": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "John Doe"
+        "/admin/maintenance/tasks/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop a running task after its current batch; it keeps its progress until resumed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
+                ],
+                "summary": "Pause maintenance task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
                 }
             }
         },
-        "dtos.CreateUserRequest": {
-            "type": "object",
-            "properties": {
-                "companies": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dtos.UpdateCompanyRequest"
-                    }
-                },
+        "/admin/maintenance/tasks/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Run a paused or failed task again from its last saved batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Maintenance"
+                ],
+                "summary": "Resume maintenance task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MaintenanceTaskResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Capture a payment intent authorized with a manual capture, in part when an amount is given, releasing the rest of the authorization, or in full otherwise. The capture is recorded with the user who initiated it, whether it succeeds or not.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Capture payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Capture",
+                        "name": "capture",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CapturePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.PaymentOperationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the refunds, captures and voids of a payment, most recent first, with the user who initiated each of them and its outcome",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get payment operations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.PaymentOperationResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Refund a payment intent of the payment provider, in part when an amount is given, in the smallest unit of its currency, or for what was not refunded yet otherwise. The refund is recorded with the user who initiated it, whether it succeeds or not.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Refund payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Refund",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefundPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.PaymentOperationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payments/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a payment intent that was authorized but not captured, releasing the authorization. The void is recorded with the user who initiated it, whether it succeeds or not.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Void payment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment intent ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Void",
+                        "name": "void",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.VoidPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.PaymentOperationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/routes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every route registered on the listeners of the running instance, with its middleware chain, the authentication schemes it accepts and the roles it requires, e.g. for audits or to generate gateway configs. A route without schemes is public.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get registered routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "public",
+                            "internal"
+                        ],
+                        "type": "string",
+                        "description": "Only the routes of this listener",
+                        "name": "listener",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.RouteResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/exchange": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exchange the access token for an access token of another client of the realm, the audience, through a Keycloak token exchange, e.g. to call another service on behalf of the user. The client must be allowed to exchange the tokens for the audience, otherwise the exchange gets a 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Exchange token",
+                "parameters": [
+                    {
+                        "description": "Audience",
+                        "name": "exchange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ExchangeTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TokenResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Log a user in with their username and password through the password grant of the client, so that first-party clients get their tokens without talking to Keycloak. The client must have the direct access grants enabled. Invalid credentials, a disabled user or one with pending required actions get a 401.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TokenResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Refresh the tokens of a session with its refresh token. The response replaces both tokens; an expired or revoked refresh token gets a 401 and the user logs in again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TokenResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/switch-org": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exchange the access token for tokens bound to another organization of the user, through a Keycloak token exchange, so that a frontend switches the tenant without a new login. The organization claim of the new access token only holds the selected organization; the frontend replaces its tokens with them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Switch organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SwitchOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.SwitchOrganizationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get companies\nWith Accept text/csv or application/x-ndjson every matching company is streamed, newest first, and page, page_size and sort are ignored.\nExports only carry the fields the export policies allow to the caller, and are audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Get companies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2025-09-11T02:17:24.290538Z\"",
+                        "description": "Start date",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2025-09-11T02:17:24.290538Z\"",
+                        "description": "End date",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"A\"",
+                        "description": "Query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "name",
+                            "-name"
+                        ],
+                        "type": "string",
+                        "example": "\"[-created_at,name]\"",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.CompanyResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Create company",
+                "parameters": [
+                    {
+                        "description": "Company",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/with-logo": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a company and upload its logo in one multipart request. The \"payload\" part holds the company JSON. The logo is removed from storage again when the company cannot be saved.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Create company with logo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company JSON matching dtos.CreateCompanyRequest",
+                        "name": "payload",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Logo image (jpeg, png or webp, max 1 MiB)",
+                        "name": "logo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get company by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Get company by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Update company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Company",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Delete company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply an RFC 7386 JSON merge patch to a company. Set a field to null to clear it.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Patch company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch",
+                        "name": "company",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PatchCompanyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the API keys of a company with their last use, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key"
+                ],
+                "summary": "Get API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.APIKeyResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an API key of a company, sent in the X-API-Key header by machine clients. The key is only returned by this response. A sandbox key belongs to the sandbox tenant of the company, created with its first sandbox key, whose ID is the company_id of the key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key",
+                        "name": "apiKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CreatedAPIKeyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/api-keys/{keyId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an API key of a company with its status and last use",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key"
+                ],
+                "summary": "Get API key by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.APIKeyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an API key of a company. The key is kept with its usage but no longer authenticates requests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.APIKeyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/api-keys/{keyId}/legal-hold": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exempt an API key and its usage from the retention purges until an admin clears the hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Place API key on legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Legal hold",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PlaceLegalHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.LegalHoldResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clear the legal hold of an API key, which is purged by the retention policies of its company again. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Clear API key legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.LegalHoldResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/api-keys/{keyId}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the calls made with an API key per day and per endpoint over the last days. Recent calls can take up to API_KEY_USAGE_FLUSH_INTERVAL to be counted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API Key"
+                ],
+                "summary": "Get API key usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 90,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Number of days, today included",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.APIKeyUsageResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/credentials": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the provider credentials of a company with their secrets masked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant Credential"
+                ],
+                "summary": "Get tenant credentials",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.TenantCredentialResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Store a provider credential of a company. The secret is encrypted at rest and only returned masked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant Credential"
+                ],
+                "summary": "Create tenant credential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Credential",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateTenantCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TenantCredentialResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/credentials/{credentialId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a provider credential of a company with its secret masked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant Credential"
+                ],
+                "summary": "Get tenant credential by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential ID",
+                        "name": "credentialId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TenantCredentialResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently delete a provider credential of a company",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant Credential"
+                ],
+                "summary": "Delete tenant credential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential ID",
+                        "name": "credentialId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/credentials/{credentialId}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the secret of a provider credential. The new secret is encrypted under a new data key and the version is incremented.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant Credential"
+                ],
+                "summary": "Rotate tenant credential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential ID",
+                        "name": "credentialId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New secret",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RotateTenantCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TenantCredentialResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the invitations of a company with their status, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invitation"
+                ],
+                "summary": "Get invitations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.InvitationResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invite a person by email to join a company. The email holds a link with a signed token, valid for INVITATION_TTL, that accepts the invitation. The invitation is deleted again when the email fails to be sent; an email with a pending invitation to the company gets a 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invitation"
+                ],
+                "summary": "Invite to company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.InvitationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/invitations/{invitationId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke a pending invitation of a company, whose token then no longer accepts it. An accepted invitation gets a 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invitation"
+                ],
+                "summary": "Revoke invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.InvitationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/invoices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the invoices of a company synced from the payment provider, most recently issued first. Amounts are in the smallest unit of the currency. Company managers only get the invoices of the company of the organization of their token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get invoices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.InvoiceResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/invoices/{invoiceId}/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a presigned URL of the branded PDF document of an invoice of a company. The document is rendered and stored the first time it is requested, and again once the invoice changed. Company managers only get the invoices of the company of the organization of their token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get invoice PDF",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "invoiceId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.InvoicePDFResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/legal-hold": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exempt all the data of a company from the retention purges until an admin clears the hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Place company on legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Legal hold",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PlaceLegalHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.LegalHoldResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clear the legal hold of a company, whose data is purged by its retention policies again. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Clear company legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.LegalHoldResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the users that are members of the company\nWith Accept text/csv or application/x-ndjson every member is streamed, newest first, and page and page_size are ignored.\nExports only carry the fields the export policies allow to the caller, and are audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Company"
+                ],
+                "summary": "Get company members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.UserResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/retention": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the retention period of each resource of a company and its legal hold. Resources without a policy are kept forever.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Get retention policies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.RetentionResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/retention/{resource}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the number of days the data of a resource of a company is kept before the retention job purges it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Set retention policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "api_key_usage",
+                            "revoked_api_keys"
+                        ],
+                        "type": "string",
+                        "description": "Resource",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Retention policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateRetentionPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.RetentionResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the retention policy of a resource of a company, whose data is kept forever again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Retention"
+                ],
+                "summary": "Delete retention policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "api_key_usage",
+                            "revoked_api_keys"
+                        ],
+                        "type": "string",
+                        "description": "Resource",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.RetentionResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/sandbox/emails": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the emails captured in the sandbox tenant instead of being sent, most recent first. The id is the sandbox company, or the company of the sandbox.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sandbox"
+                ],
+                "summary": "Get sandbox emails",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.CapturedEmailResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/sandbox/emails/{emailId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get an email captured in the sandbox tenant with its bodies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sandbox"
+                ],
+                "summary": "Get sandbox email by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email ID",
+                        "name": "emailId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CapturedEmailResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/subscription": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the plan in effect for a company and the entitlements it grants. A company without a subscription in effect gets the default plan, or no restriction when no default plan is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Billing"
+                ],
+                "summary": "Get subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.SubscriptionResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribe a company to a plan. A free plan, which cancels the paid subscription of the company, and a change between paid plans, prorated by the payment provider, take effect at once. The first paid plan returns a checkout_url to pay it; the current plan stays in effect until the payment provider reports the subscription paid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Billing"
+                ],
+                "summary": "Change subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Plan",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangeSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.SubscriptionResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the member count, storage usage and last activity of a company. The summary is updated shortly after the changes of the company; refreshed_at is omitted until it is first computed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Get company summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CompanySummaryResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/upload-policy": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the content types, size limit and banned extensions of the files a company accepts. A company without a policy accepts every upload within the built-in limits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Upload Policy"
+                ],
+                "summary": "Get upload policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UploadPolicyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the upload policy of a company. It applies to every upload of the company and to the presigned uploads issued for it, and only tightens the limits of each upload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Upload Policy"
+                ],
+                "summary": "Set upload policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upload policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateUploadPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UploadPolicyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the upload policy of a company, which accepts every upload within the built-in limits again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Upload Policy"
+                ],
+                "summary": "Delete upload policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UploadPolicyResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the webhook endpoints of a company, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.WebhookEndpointResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register an endpoint receiving the events of a company, signed with HMAC-SHA256. The signing secret is only returned by this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CreatedWebhookEndpointResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the delivery logs of the webhook endpoints of a company with the outcome of their last attempt, most recent first. Without a webhook ID, the logs of the deleted endpoints are included.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get company webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"user.updated\"",
+                        "description": "Event type",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.WebhookDeliveryResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/deliveries/{deliveryId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a delivery log of a company with its payload and the outcome of its last attempt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get webhook delivery by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.WebhookDeliveryResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send the payload of a delivery again, as a new delivery of the same event with its own attempts. The event keeps its ID, so that receivers can deduplicate it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Redeliver webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.WebhookDeliveryResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/{webhookId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a webhook endpoint of a company, without its signing secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get webhook by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.WebhookEndpointResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a webhook endpoint of a company. Its pending deliveries fail and its delivery logs are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the URL, description, event types, conditions, rate limit or active flag of a webhook endpoint. The omitted fields are kept; an inactive endpoint receives no deliveries.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.WebhookEndpointResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the delivery logs of a webhook endpoint with the outcome of their last attempt, most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"user.updated\"",
+                        "description": "Event type",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.WebhookDeliveryResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/{webhookId}/rotate-secret": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the signing secret of a webhook endpoint. The previous secret stops signing at once, including the retries of pending deliveries. The new secret is only returned by this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Rotate webhook secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.CreatedWebhookEndpointResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/companies/{id}/webhooks/{webhookId}/test": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deliver a webhook.test event to an active webhook endpoint, whatever its event types. The delivery is logged and retried like the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Send test webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.WebhookDeliveryResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/companies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the summaries of all the companies, most recently active first by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashboard"
+                ],
+                "summary": "Get company summaries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "member_count",
+                            "-member_count",
+                            "storage_bytes",
+                            "-storage_bytes",
+                            "last_activity_at",
+                            "-last_activity_at"
+                        ],
+                        "type": "string",
+                        "example": "\"[-member_count,name]\"",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.CompanySummaryResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete every company and user and seed the demo dataset again. Only available when DEMO_MODE is enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Demo"
+                ],
+                "summary": "Reset demo data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.DemoSeedResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/email/ses-notifications": {
+            "post": {
+                "description": "Receive a message of the SNS topic of the SES bounce and complaint notifications, SES_NOTIFICATIONS_TOPIC_ARN, signed by SNS. The subscription of the endpoint is confirmed; the recipients of the hard bounces and complaints are suppressed, so that no email is sent to them anymore. A failure answers an error so that SNS delivers the message again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Receive SES notification",
+                "parameters": [
+                    {
+                        "description": "SNS message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SNSMessage"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/events/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-sent events stream of the events of the authenticated user, for clients that cannot use WebSockets. Each event has the event ID and type, and the message sent over the WebSocket as data. After a reconnection, send the ID of the last event received as Last-Event-ID to replay the events missed in the last 5 minutes. Idle streams receive a heartbeat comment every 15 seconds, and streams of clients too slow to read are closed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Stream events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the files of the file registry with the metadata of their object, most recent first, to find the objects of an owner or the failed and quarantined files to clean up",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Get files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user or company owning the files",
+                        "name": "owner_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "scanning",
+                            "available",
+                            "failed",
+                            "quarantined"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"avatars/\"",
+                        "description": "Prefix of the keys",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.FileResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a file of the file registry and its object. An available avatar, logo or invoice is in use by its user or company and cannot be deleted: it is replaced through them instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Delete file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream an available file of the file registry through the server, for the buckets that stay private without presigned URLs. A single byte range of the Range header is served as 206 Partial Content, unless the If-Range header does not match the ETag; an unsatisfiable range is 416. The Repr-Digest header holds the SHA-256 of the whole file for the client to verify it. Avatars are downloaded with the user view roles, logos with the company view roles, invoices by the admins and the company managers of the company of the invoice, by the organization of their token, presigned uploads by the admins and the user who uploaded them, and the other files by the admins.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Download file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the range applies to",
+                        "name": "If-Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/auth": {
+            "get": {
+                "description": "Check that the realm of the auth provider is reachable and its endpoints resolve. The endpoints are discovered again at most once a minute. Deprecated, use the auth entry of /health/dependencies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Auth Provider Health Check",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "object"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health/database": {
+            "get": {
+                "description": "Check if the database connection is healthy. Deprecated, use the database entry of /health/dependencies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Database Health Check",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "object"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health/dependencies": {
+            "get": {
+                "description": "Check every dependency registered in the health registry: the database, the cache, the auth provider, the storage, the email and the payment providers, with their status and latency. The rollup is unhealthy when a critical dependency fails, with a 503, and degraded when another one does.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Dependencies Health Check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/monitoring.HealthReport"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "object",
+                                    "properties": {
+                                        "dependencies": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/monitoring.DependencyHealth"
+                                            }
+                                        },
+                                        "status": {
+                                            "type": "string"
+                                        }
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/accept": {
+            "post": {
+                "description": "Accept an invitation with the token of its email. A person without an account gets a Keycloak user with the password of the request; then they are added to the organization of the company and to its members. The steps done are undone when a later one fails, so that a failed acceptance can be retried with the same token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invitation"
+                ],
+                "summary": "Accept invitation",
+                "parameters": [
+                    {
+                        "description": "Acceptance",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.AcceptInvitationResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/keycloak/events": {
+            "post": {
+                "description": "Receive an admin or user event of the realm, posted by an event listener provider of Keycloak and signed with KEYCLOAK_EVENTS_SECRET: the hex HMAC-SHA256 of the body in the X-Keycloak-Signature header. The updates, disabling and deletions of the users (admin events on users/{id}, UPDATE_EMAIL, UPDATE_PROFILE and DELETE_ACCOUNT user events) bring the user to its current state in Keycloak; the other events are acknowledged and ignored. A failure answers an error so that the event is posted again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Receive Keycloak event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 of the body",
+                        "name": "X-Keycloak-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Keycloak event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.KeycloakEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/onboarding": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the setup steps of the tenant with their state, computed from its data unless overridden, and its progress",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onboarding"
+                ],
+                "summary": "Get onboarding checklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company ID, admins only; defaults to the company of the token organization",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.OnboardingResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/onboarding/steps/{step}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark a setup step of the tenant as completed or not, overriding the state computed from its data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onboarding"
+                ],
+                "summary": "Override onboarding step",
+                "parameters": [
+                    {
+                        "enum": [
+                            "invite_users",
+                            "configure_webhooks",
+                            "add_payment_method"
+                        ],
+                        "type": "string",
+                        "description": "Step",
+                        "name": "step",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID, admins only; defaults to the company of the token organization",
+                        "name": "company_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Step state",
+                        "name": "state",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateOnboardingStepRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.OnboardingResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the override of a setup step of the tenant, whose state is computed from its data again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onboarding"
+                ],
+                "summary": "Reset onboarding step",
+                "parameters": [
+                    {
+                        "enum": [
+                            "invite_users",
+                            "configure_webhooks",
+                            "add_payment_method"
+                        ],
+                        "type": "string",
+                        "description": "Step",
+                        "name": "step",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID, admins only; defaults to the company of the token organization",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.OnboardingResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/provisioning/tenants/{slug}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or update a tenant end to end from its declared state: the Keycloak organization, whose alias is the slug, the client roles of its members, the company, its storage prefix and its default webhook endpoints, matched by URL. The call is idempotent and can be repeated, e.g. after a failure half way. The signing secrets of the webhook endpoints it creates are only returned by that response. The storage prefix cannot change once the tenant is created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Provisioning"
+                ],
+                "summary": "Provision tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant slug, 2 to 63 lowercase letters, digits or dashes",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProvisionTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.ProvisionTenantResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rbac/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the roles of the database RBAC with their permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.RoleResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rbac/roles/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a role of the database RBAC, or replace the description and the permissions of the role. Permissions are named \u003cresource\u003e:\u003caction\u003e, e.g. users:write; \u003cresource\u003e:* grants every action of the resource and * every permission. The users of the role get its new permissions at once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Save role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SaveRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.RoleResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/realtime/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upgrade to a WebSocket receiving the events of the authenticated user as JSON messages ({id, type, data, timestamp}). Browsers, which cannot set the Authorization header, send the token as the \"bearer, \u003ctoken\u003e\" subprotocols.",
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Open the realtime WebSocket",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/uploads/presign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue an upload of a file straight to the storage, so that large files do not go through the server, and register its file, pending until the upload is completed. The declared file is checked against the upload policy of the tenant; the client then posts the fields, then the file in a last \"file\" field, as multipart/form-data to the URL before it expires, and completes the upload. The storage refuses another content type or a file larger than declared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Uploads"
+                ],
+                "summary": "Presign upload",
+                "parameters": [
+                    {
+                        "description": "Declared file",
+                        "name": "upload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PresignUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.PresignedUploadResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Complete the file of a presigned upload of the caller once its object is uploaded: the object is read from the storage and scanned by the virus scanner, its size and checksum are recorded, and a clean file becomes available; no URL or stream of the file is issued before. An infected file is quarantined, a 422, and a file that cannot be read or scanned failed. An object not uploaded yet is a 409 and leaves the file pending, to complete again later.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Uploads"
+                ],
+                "summary": "Complete upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.FileResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/usage/performance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the response times and errors of the requests of the tenant over the last hours, overall with a response time histogram, per route and per hour. Percentiles are estimated from the histogram. Recent requests can take up to PERFORMANCE_FLUSH_INTERVAL to be counted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Get tenant performance",
+                "parameters": [
+                    {
+                        "maximum": 720,
+                        "type": "integer",
+                        "default": 24,
+                        "description": "Number of hours, the current one included",
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID, admins only; defaults to the company of the token organization",
+                        "name": "company_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.TenantPerformanceResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get users\nWith Accept text/csv or application/x-ndjson every matching user is streamed, newest first, and page, page_size and sort are ignored.\nExports only carry the fields the export policies allow to the caller, and are audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2025-09-11T02:17:24.290538Z\"",
+                        "description": "Start date",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2025-09-11T02:17:24.290538Z\"",
+                        "description": "End date",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"A\"",
+                        "description": "Query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "name",
+                            "-name"
+                        ],
+                        "type": "string",
+                        "example": "\"[-created_at,name]\"",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.UserResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Import users from a CSV file with the columns email, first_name, last_name and keycloak_id.\nRows are validated first; with dry_run=true only the validation report is returned.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Import users from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Validate only, do not import",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.ImportUsersResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search of users by first name, last name and email. Every word of q is matched as a prefix.\nResults are ordered by relevance and the matched text is returned with the matching words wrapped in mark tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"john do\"",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.UserSearchResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/test-rest-client": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Test rest client",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Test rest client",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "object",
+                                    "additionalProperties": true
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get user by ID, with the MFA status of their Keycloak user, omitted when Keycloak cannot be reached",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply an RFC 7386 JSON merge patch to a user. Set a field to null to clear it.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Patch user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PatchUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/avatar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the avatar of the user with a new presigned URL. An avatar that is not available yet, or was quarantined, returns 409.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserAvatarResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a JPEG, PNG, GIF or WebP avatar (max 2 MiB) and return a presigned URL once it is available. The previous avatar is deleted.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Upload user avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserAvatarResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/companies/{companyId}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a single company membership to the user. Adding an existing membership is a no-op.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Add user to company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "companyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a single company membership from the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Remove user from company",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Company ID",
+                        "name": "companyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/mfa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether the user has an OTP authenticator and whether Keycloak asks them to configure one at their next login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Get MFA status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MFAStatusResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the OTP authenticators of the user, e.g. after the loss of their device, and the request to configure one. Require MFA again to have them configure a new one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Reset MFA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MFAStatusResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Email the user a Keycloak link to configure an OTP authenticator now, valid for the action token lifespan of the realm. A user who already has one gets a 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Enroll MFA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MFAStatusResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/mfa/require": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ask the user to configure an OTP authenticator at their next login, with the CONFIGURE_TOTP required action of Keycloak. The login cannot complete until they do.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Require MFA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.MFAStatusResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the roles of the database RBAC of a user and the permissions they grant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Get user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserRolesResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the roles of the database RBAC of a user. An empty list removes their roles; an unknown role gets a 404.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Set user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Roles",
+                        "name": "roles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SetUserRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/dtos.UserRolesResponse"
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the active sessions of the user, one per login on a device, the most recently used first. The session of the token of the request is flagged as current. Admins get the sessions of any user, other users only their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "Get user sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/dtos.SessionResponse"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End a session of the user, logging them out of that device: its refresh tokens stop working at once, its access tokens when they expire or are introspected. Admins revoke the sessions of any user, other users only their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Session"
+                ],
+                "summary": "Revoke user session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/payments": {
+            "post": {
+                "description": "Receive an event of Stripe, signed with STRIPE_WEBHOOK_SECRET in the Stripe-Signature header, or of PayPal, signed with the certificate of its Paypal-Cert-Url header for the webhook PAYPAL_WEBHOOK_ID. The event is recorded and published to the payment.\u003cprovider\u003e.\u003ctype\u003e topic of the message broker, once: the deliveries of an event already published are acknowledged. A failure answers an error so that the provider delivers the event again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Receive payment event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signature of a Stripe event",
+                        "name": "Stripe-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of a PayPal event",
+                        "name": "Paypal-Transmission-Sig",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Event of the provider",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "meta": {
+                                    "$ref": "#/definitions/dtos.Meta"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "constants.UserStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "inactive"
+            ],
+            "x-enum-varnames": [
+                "UserStatusActive",
+                "UserStatusInactive"
+            ]
+        },
+        "dtos.APIKeyDailyUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2021-01-01"
+                }
+            }
+        },
+        "dtos.APIKeyEndpointUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "endpoint": {
+                    "type": "string",
+                    "example": "GET /api/v1/companies/:id"
+                }
+            }
+        },
+        "dtos.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "legal_hold": {
+                    "description": "LegalHold is only set while the key is on legal hold",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.LegalHoldResponse"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CRM sync"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "gbk_Xq3vPz8a"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "sandbox": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.APIKeyUsageResponse": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.APIKeyDailyUsage"
+                    }
+                },
+                "days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.APIKeyEndpointUsage"
+                    }
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "total_calls": {
+                    "type": "integer",
+                    "example": 3600
+                }
+            }
+        },
+        "dtos.AcceptInvitationRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Doe"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 12
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AcceptInvitationResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "invitation_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "keycloak_user_created": {
+                    "description": "KeycloakUserCreated is false when the invited person already had an account",
+                    "type": "boolean",
+                    "example": true
+                },
+                "user_created": {
+                    "description": "UserCreated is false when the user already existed in the database",
+                    "type": "boolean",
+                    "example": true
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.AdminDashboardResponse": {
+            "type": "object",
+            "properties": {
+                "failing_webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AdminFailingWebhook"
+                    }
+                },
+                "generated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "health": {
+                    "$ref": "#/definitions/dtos.AdminHealthStats"
+                },
+                "jobs": {
+                    "$ref": "#/definitions/dtos.AdminJobQueueStats"
+                },
+                "recent_signups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AdminRecentSignup"
+                    }
+                },
+                "tenants": {
+                    "$ref": "#/definitions/dtos.AdminTenantStats"
+                },
+                "users": {
+                    "$ref": "#/definitions/dtos.AdminUserStats"
+                }
+            }
+        },
+        "dtos.AdminDependencyHealth": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Failed to ping Redis"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                }
+            }
+        },
+        "dtos.AdminFailingWebhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "endpoint_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "failures": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "last_failure_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "dtos.AdminHealthStats": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AdminDependencyHealth"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "healthy",
+                        "degraded",
+                        "unhealthy"
+                    ],
+                    "example": "healthy"
+                }
+            }
+        },
+        "dtos.AdminJobQueueStats": {
+            "type": "object",
+            "properties": {
+                "dead": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "oldest_pending_at": {
+                    "description": "OldestPendingAt is the run time of the job waiting the longest for a worker",
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "running": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "scheduled": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "dtos.AdminRecentSignup": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                }
+            }
+        },
+        "dtos.AdminTenantStats": {
+            "type": "object",
+            "properties": {
+                "companies": {
+                    "type": "integer",
+                    "example": 35
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "refreshed_at": {
+                    "description": "RefreshedAt is when a summary was last projected, omitted while there is none",
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "example": 104857600
+                }
+            }
+        },
+        "dtos.AdminUserStats": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "deleted": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "signups_last_day": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "signups_last_week": {
+                    "type": "integer",
+                    "example": 87
+                }
+            }
+        },
+        "dtos.CapturePaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Order 1042 shipped"
+                }
+            }
+        },
+        "dtos.CapturedEmailResponse": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "invoice.pdf"
+                    ]
+                },
+                "bcc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "audit@example.com"
+                    ]
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "john@example.com"
+                    ]
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "html_body": {
+                    "type": "string",
+                    "example": "\u003cp\u003eHello Jane\u003c/p\u003e"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Welcome to Acme"
+                },
+                "template_data": {
+                    "type": "object"
+                },
+                "template_id": {
+                    "type": "string",
+                    "example": "welcome"
+                },
+                "text_body": {
+                    "type": "string",
+                    "example": "Hello Jane"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "jane@example.com"
+                    ]
+                }
+            }
+        },
+        "dtos.ChangeSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "plan"
+            ],
+            "properties": {
+                "plan": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "pro"
+                }
+            }
+        },
+        "dtos.CompanyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "logo_key": {
+                    "type": "string",
+                    "example": "logos/123/0190b7f5.png"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.CompanySummaryResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "last_activity_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "member_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "refreshed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                }
+            }
+        },
+        "dtos.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 730,
+                    "minimum": 1,
+                    "example": 365
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "CRM sync"
+                },
+                "sandbox": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dtos.CreateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "keycloak_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
+                }
+            }
+        },
+        "dtos.CreateInvitationRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "dtos.CreateMaintenanceTaskRequest": {
+            "type": "object",
+            "required": [
+                "operation"
+            ],
+            "properties": {
+                "batch_size": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 100
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "rebuild_projections"
+                },
+                "params": {
+                    "type": "object"
+                },
+                "throttle_ms": {
+                    "type": "integer",
+                    "maximum": 60000,
+                    "minimum": 0,
+                    "example": 500
+                }
+            }
+        },
+        "dtos.CreateTenantCredentialRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "provider",
+                "secret"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "default"
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "smtp",
+                        "s3"
+                    ],
+                    "example": "smtp"
+                },
+                "secret": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "companies": {
+                    "description": "Companies of the user. On update, omitting them keeps the memberships and an empty\nlist removes them all.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.UpdateCompanyRequest"
+                    }
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Doe"
+                }
+            }
+        },
+        "dtos.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/dtos.WebhookCondition"
+                    }
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "CRM sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user.*",
+                        "company.updated"
+                    ]
+                },
+                "rate_limit": {
+                    "description": "RateLimit caps the deliveries per minute to the endpoint, 0 is unlimited",
+                    "type": "integer",
+                    "maximum": 6000,
+                    "minimum": 0,
+                    "example": 60
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "dtos.CreatedAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "gbk_Xq3vPz8a0bN4yR7tLw2sKd9fHj5mC1eGuVoYiA6pTz"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "legal_hold": {
+                    "description": "LegalHold is only set while the key is on legal hold",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.LegalHoldResponse"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CRM sync"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "gbk_Xq3vPz8a"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "sandbox": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.CreatedWebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.WebhookCondition"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CRM sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user.*",
+                        "company.updated"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "rate_limit": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_Xq3vPz8a0bN4yR7tLw2sKd9fHj5mC1eGuVoYiA6pTz"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "dtos.DemoSeedResponse": {
+            "type": "object",
+            "properties": {
+                "avatars": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "companies": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "seeded_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 16
+                }
+            }
+        },
+        "dtos.DeprecationConsumerResponse": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "consumer": {
+                    "type": "string",
+                    "example": "api_key:123"
+                },
+                "first_seen_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2021-01-02T00:00:00Z"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.DeprecationReportResponse": {
+            "type": "object",
+            "properties": {
+                "deprecations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DeprecationResponse"
+                    }
+                }
+            }
+        },
+        "dtos.DeprecationResponse": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "consumers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DeprecationConsumerResponse"
+                    }
+                },
+                "days_to_sunset": {
+                    "type": "integer",
+                    "example": 182
+                },
+                "deprecated_at": {
+                    "type": "string",
+                    "example": "2026-10-15T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "health-database"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "route",
+                        "field"
+                    ],
+                    "example": "route"
+                },
+                "link": {
+                    "type": "string",
+                    "example": "https://docs.example.com/migrations/health"
+                },
+                "replacement": {
+                    "type": "string",
+                    "example": "The database entry of GET /api/v1/health/dependencies"
+                },
+                "sunset": {
+                    "type": "string",
+                    "example": "2027-04-15T00:00:00Z"
+                },
+                "surface": {
+                    "type": "string",
+                    "example": "GET /api/v1/health/database"
+                }
+            }
+        },
+        "dtos.EntitlementResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "webhooks"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "dtos.ExchangeTokenRequest": {
+            "type": "object",
+            "required": [
+                "audience"
+            ],
+            "properties": {
+                "audience": {
+                    "description": "Audience is the client ID of the target client",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "reporting-api"
+                },
+                "scope": {
+                    "description": "Scope restricts the scopes of the new token, space separated",
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "openid profile"
+                }
+            }
+        },
+        "dtos.ExportAuditResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "entity": {
+                    "type": "string",
+                    "enum": [
+                        "users",
+                        "companies",
+                        "company_members"
+                    ],
+                    "example": "users"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "broken pipe"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id",
+                        "email"
+                    ]
+                },
+                "filters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string",
+                    "example": "text/csv"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "principal_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "principal_type": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "api_key"
+                    ],
+                    "example": "user"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user-manager"
+                    ]
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "started",
+                        "completed",
+                        "failed",
+                        "denied"
+                    ],
+                    "example": "completed"
+                }
+            }
+        },
+        "dtos.FailedEmailResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "failed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "Failed to send email via SES"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Welcome to My Echo App!"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "jane@example.com"
+                    ]
+                }
+            }
+        },
+        "dtos.FileResponse": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string",
+                    "example": "my-bucket"
+                },
+                "checksum": {
+                    "type": "string",
+                    "example": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "avatars/123/0190b7f5.png"
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 2048
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "scanning",
+                        "available",
+                        "failed",
+                        "quarantined"
+                    ],
+                    "example": "available"
+                },
+                "status_changed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "status_reason": {
+                    "type": "string",
+                    "example": "upload failed"
+                }
+            }
+        },
+        "dtos.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "service": {
+                    "type": "string",
+                    "example": "golang-boilerplate"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "dtos.HourlyPerformance": {
+            "type": "object",
+            "properties": {
+                "average_ms": {
+                    "type": "number",
+                    "example": 84.5
+                },
+                "client_errors": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "error_rate": {
+                    "description": "ErrorRate is the share of the requests that failed with a server error, from 0 to 1",
+                    "type": "number",
+                    "example": 0.0008
+                },
+                "hour": {
+                    "type": "string",
+                    "example": "2021-01-01T10:00:00Z"
+                },
+                "p50_ms": {
+                    "type": "number",
+                    "example": 62.5
+                },
+                "p95_ms": {
+                    "type": "number",
+                    "example": 240
+                },
+                "p99_ms": {
+                    "type": "number",
+                    "example": 480
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "server_errors": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.ImportUserRowError": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dtos.ImportUsersResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ImportUserRowError"
+                    }
+                },
+                "imported_rows": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "invalid_rows": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total_rows": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "valid_rows": {
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "dtos.IntegrationHealth": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number",
+                    "example": 98.4
+                },
+                "checked_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "critical": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Failed to ping Stripe"
+                },
+                "failures": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "last_failure_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "name": {
+                    "type": "string",
+                    "example": "payment"
+                },
+                "probes": {
+                    "type": "integer",
+                    "example": 1440
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "healthy",
+                        "unhealthy"
+                    ],
+                    "example": "healthy"
+                },
+                "uptime": {
+                    "description": "Uptime is the percentage of the probes of the window that succeeded",
+                    "type": "number",
+                    "example": 99.65
+                }
+            }
+        },
+        "dtos.IntegrationHealthResponse": {
+            "type": "object",
+            "properties": {
+                "integrations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.IntegrationHealth"
+                    }
+                },
+                "status": {
+                    "description": "Status is the rollup of the last probes: unhealthy when a critical integration\nis, degraded when another one is",
+                    "type": "string",
+                    "enum": [
+                        "healthy",
+                        "degraded",
+                        "unhealthy"
+                    ],
+                    "example": "healthy"
+                },
+                "window_hours": {
+                    "type": "integer",
+                    "example": 24
+                }
+            }
+        },
+        "dtos.InvitationResponse": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string",
+                    "example": "2021-01-02T00:00:00Z"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-01-08T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "invited_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2021-01-02T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "user_id": {
+                    "description": "UserID is the user who accepted the invitation",
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.InvoiceLineResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 4900
+                },
+                "description": {
+                    "type": "string",
+                    "example": "1 × Pro (at $49.00 / month)"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "unit_amount": {
+                    "type": "integer",
+                    "example": 4900
+                }
+            }
+        },
+        "dtos.InvoicePDFResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:15:00Z"
+                },
+                "invoice_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "pdf_key": {
+                    "type": "string",
+                    "example": "invoices/123/0190b7f5.pdf"
+                },
+                "pdf_url": {
+                    "type": "string",
+                    "example": "https://storage.googleapis.com/bucket/invoices/123/0190b7f5.pdf?X-Goog-Signature=..."
+                }
+            }
+        },
+        "dtos.InvoiceResponse": {
+            "type": "object",
+            "properties": {
+                "amount_due": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "amount_paid": {
+                    "type": "integer",
+                    "example": 5880
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "due_at": {
+                    "type": "string",
+                    "example": "2021-01-31T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "issued_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.InvoiceLineResponse"
+                    }
+                },
+                "number": {
+                    "type": "string",
+                    "example": "A1B2C3D4-0001"
+                },
+                "period_end": {
+                    "type": "string",
+                    "example": "2021-02-01T00:00:00Z"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "stripe"
+                },
+                "provider_invoice_id": {
+                    "type": "string",
+                    "example": "in_1MtHbELkdIwHu7ixl4OzzPMv"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "open",
+                        "paid",
+                        "uncollectible",
+                        "void"
+                    ],
+                    "example": "paid"
+                },
+                "subtotal": {
+                    "type": "integer",
+                    "example": 4900
+                },
+                "tax": {
+                    "type": "integer",
+                    "example": 980
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 5880
+                }
+            }
+        },
+        "dtos.KeycloakEvent": {
+            "type": "object",
+            "properties": {
+                "operationType": {
+                    "type": "string",
+                    "enum": [
+                        "CREATE",
+                        "UPDATE",
+                        "DELETE",
+                        "ACTION"
+                    ],
+                    "example": "DELETE"
+                },
+                "realmId": {
+                    "type": "string",
+                    "example": "4b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"
+                },
+                "resourcePath": {
+                    "type": "string",
+                    "example": "users/0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"
+                },
+                "resourceType": {
+                    "type": "string",
+                    "example": "USER"
+                },
+                "time": {
+                    "description": "Time is the time of the event, in milliseconds since the epoch",
+                    "type": "integer",
+                    "example": 1760000000000
+                },
+                "type": {
+                    "description": "Type is the type of a user event, e.g. UPDATE_EMAIL, optionally prefixed with\naccess.",
+                    "type": "string",
+                    "example": "UPDATE_EMAIL"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"
+                }
+            }
+        },
+        "dtos.KeycloakSyncChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "user_created"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "456"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "email: john@example.com -\u003e john.doe@example.com"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "example": "0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.KeycloakSyncReport": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes lists the first changes, up to 500",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.KeycloakSyncChange"
+                    }
+                },
+                "counts": {
+                    "description": "Counts are the changes per action, e.g. user_created or membership_removed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "failed": {
+                    "description": "Failed counts the changes that failed, left for the next run",
+                    "type": "integer",
+                    "example": 0
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2021-01-01T05:00:12Z"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2021-01-01T05:00:00Z"
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dtos.LegalHoldResponse": {
+            "type": "object",
+            "properties": {
+                "on_hold": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "placed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "placed_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Litigation 2026-042"
+                }
+            }
+        },
+        "dtos.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "S3cure!Passw0rd"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "jane@example.com"
+                }
+            }
+        },
+        "dtos.MFAStatusResponse": {
+            "type": "object",
+            "properties": {
+                "configured": {
+                    "description": "Configured is set when the user has an OTP authenticator",
+                    "type": "boolean",
+                    "example": true
+                },
+                "required": {
+                    "description": "Required is set when the user is asked to configure one at their next login",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dtos.MaintenanceOperationResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Rebuild the read models from the source tables, one after the other"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "rebuild_projections"
+                }
+            }
+        },
+        "dtos.MaintenanceTaskResponse": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0190b7f5-8f4a-7d3e-9c1b-2a6f4e8d1c3b"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "Credentials vault is not configured"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "reencrypt_tenant_credentials"
+                },
+                "params": {
+                    "type": "object"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 25
+                },
+                "processed": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "paused",
+                        "failed",
+                        "completed",
+                        "cancelled"
+                    ],
+                    "example": "running"
+                },
+                "throttle_ms": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 4800
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.Meta": {
+            "description": "Metadata for pagination",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "demo": {
+                    "description": "Demo is set when the response is served by an instance running in demo mode",
+                    "type": "boolean",
+                    "example": false
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "BAD_REQUEST"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Request is invalid"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 18
+                }
+            }
+        },
+        "dtos.OnboardingResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "completed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "done": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "progress": {
+                    "type": "integer",
+                    "example": 66
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.OnboardingStepResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.OnboardingStepResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "key": {
+                    "type": "string",
+                    "example": "invite_users"
+                },
+                "overridden_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "overridden_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "computed"
+                }
+            }
+        },
+        "dtos.OrganizationResponse": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                }
+            }
+        },
+        "dtos.PatchCompanyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "keycloak_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
+                }
+            }
+        },
+        "dtos.PatchUserRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Doe"
+                }
+            }
+        },
+        "dtos.PaymentOperationResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "completed_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Charge has already been refunded."
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "initiated_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Order 1042 returned"
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "refund",
+                        "capture",
+                        "void"
+                    ],
+                    "example": "refund"
+                },
+                "payment_id": {
+                    "type": "string",
+                    "example": "pi_3MtwBwLkdIwHu7ix28a3tqPa"
+                },
+                "processed_amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "stripe"
+                },
+                "provider_object_id": {
+                    "type": "string",
+                    "example": "re_1Nispe2eZvKYlo2Cd31jOCgZ"
+                },
+                "provider_status": {
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "requested_by_customer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "started",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "succeeded"
+                }
+            }
+        },
+        "dtos.PerformanceBucket": {
+            "type": "object",
+            "properties": {
+                "requests": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "upper_bound_ms": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dtos.PerformanceStats": {
+            "type": "object",
+            "properties": {
+                "average_ms": {
+                    "type": "number",
+                    "example": 84.5
+                },
+                "client_errors": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "error_rate": {
+                    "description": "ErrorRate is the share of the requests that failed with a server error, from 0 to 1",
+                    "type": "number",
+                    "example": 0.0008
+                },
+                "p50_ms": {
+                    "type": "number",
+                    "example": 62.5
+                },
+                "p95_ms": {
+                    "type": "number",
+                    "example": 240
+                },
+                "p99_ms": {
+                    "type": "number",
+                    "example": 480
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "server_errors": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.PlaceLegalHoldRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 1,
+                    "example": "Litigation 2026-042"
+                }
+            }
+        },
+        "dtos.PresignUploadRequest": {
+            "type": "object",
+            "required": [
+                "content_type",
+                "filename"
+            ],
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "application/pdf"
+                },
+                "filename": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "report.pdf"
+                },
+                "size": {
+                    "type": "integer",
+                    "maximum": 5368709120,
+                    "example": 10485760
+                }
+            }
+        },
+        "dtos.PresignedUploadResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:15:00Z"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "file_id": {
+                    "type": "string",
+                    "example": "0199e3a4-5b6c-7d8e-9f00-112233445566"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "uploads/123/0199e3a4-5b6c-7d8e-9f00-112233445566.pdf"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bucket.s3.us-east-1.amazonaws.com"
+                }
+            }
+        },
+        "dtos.ProvisionTenantRequest": {
+            "type": "object",
+            "required": [
+                "default_roles",
+                "domains",
+                "name"
+            ],
+            "properties": {
+                "default_roles": {
+                    "description": "DefaultRoles are the client roles of the members of the tenant, created in Keycloak\nwhen missing",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "company-viewer",
+                        "user-viewer"
+                    ]
+                },
+                "domains": {
+                    "description": "Domains are the domains of the Keycloak organization of the tenant",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "acme.com"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "Acme"
+                },
+                "storage_prefix": {
+                    "description": "StoragePrefix prefixes the storage keys of the tenant, tenants/{slug} by default. It\ncannot change once the tenant is created.",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "tenants/acme"
+                },
+                "webhooks": {
+                    "description": "Webhooks are the default webhook endpoints of the tenant, matched by URL",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/dtos.CreateWebhookRequest"
+                    }
+                }
+            }
+        },
+        "dtos.ProvisionTenantResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created": {
+                    "description": "Created is true when the call created the tenant",
+                    "type": "boolean",
+                    "example": true
+                },
+                "default_roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "company-viewer",
+                        "user-viewer"
+                    ]
+                },
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "acme.com"
+                    ]
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "storage_prefix": {
+                    "type": "string",
+                    "example": "tenants/acme"
+                },
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ProvisionedWebhookResponse"
+                    }
+                }
+            }
+        },
+        "dtos.ProvisionedWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is true when the call created the endpoint",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "secret": {
+                    "description": "Secret is the signing secret of an endpoint the call created, only returned once",
+                    "type": "string",
+                    "example": "whsec_3q2+7w=="
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "dtos.RedriveFailedEmailsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.RedriveFailedEmailsResponse": {
+            "type": "object",
+            "properties": {
+                "requeued": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzUxMiIs..."
+                }
+            }
+        },
+        "dtos.RefundPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1500
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Order 1042 returned"
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "duplicate",
+                        "fraudulent",
+                        "requested_by_customer"
+                    ],
+                    "example": "requested_by_customer"
+                }
+            }
+        },
+        "dtos.RetentionPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "example": 365
+                },
+                "resource": {
+                    "type": "string",
+                    "example": "api_key_usage"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.RetentionResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "legal_hold": {
+                    "$ref": "#/definitions/dtos.LegalHoldResponse"
+                },
+                "policies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.RetentionPolicyResponse"
+                    }
+                }
+            }
+        },
+        "dtos.RoleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Manages the users of the tenants"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "user-manager"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read",
+                        "users:write"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.RotateTenantCredentialRequest": {
+            "type": "object",
+            "required": [
+                "secret"
+            ],
+            "properties": {
+                "secret": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.RoutePerformance": {
+            "type": "object",
+            "properties": {
+                "average_ms": {
+                    "type": "number",
+                    "example": 84.5
+                },
+                "client_errors": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "error_rate": {
+                    "description": "ErrorRate is the share of the requests that failed with a server error, from 0 to 1",
+                    "type": "number",
+                    "example": 0.0008
+                },
+                "p50_ms": {
+                    "type": "number",
+                    "example": 62.5
+                },
+                "p95_ms": {
+                    "type": "number",
+                    "example": 240
+                },
+                "p99_ms": {
+                    "type": "number",
+                    "example": 480
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "route": {
+                    "type": "string",
+                    "example": "GET /api/v1/companies/:id"
+                },
+                "server_errors": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.RouteResponse": {
+            "type": "object",
+            "properties": {
+                "handler": {
+                    "type": "string",
+                    "example": "handlers.(*CompanyHandler).GetCompanyByID"
+                },
+                "listener": {
+                    "type": "string",
+                    "enum": [
+                        "public",
+                        "internal"
+                    ],
+                    "example": "public"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "GET"
+                },
+                "middlewares": {
+                    "description": "Middlewares are the middlewares the route runs, in order, the global ones first",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "request_id",
+                        "auth",
+                        "api_key_or_token"
+                    ]
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/companies/:id"
+                },
+                "roles": {
+                    "description": "Roles are the roles the route requires, any of them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin",
+                        "company-manager"
+                    ]
+                },
+                "schemes": {
+                    "description": "Schemes are the authentication schemes the route accepts, empty for a public route",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "api_key",
+                        "bearer"
+                    ]
+                }
+            }
+        },
+        "dtos.SNSMessage": {
+            "type": "object",
+            "properties": {
+                "Message": {
+                    "type": "string"
+                },
+                "MessageId": {
+                    "type": "string",
+                    "example": "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324"
+                },
+                "Signature": {
+                    "type": "string"
+                },
+                "SignatureVersion": {
+                    "type": "string",
+                    "enum": [
+                        "1",
+                        "2"
+                    ],
+                    "example": "1"
+                },
+                "SigningCertURL": {
+                    "type": "string",
+                    "example": "https://sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-0000.pem"
+                },
+                "Subject": {
+                    "type": "string"
+                },
+                "SubscribeURL": {
+                    "type": "string"
+                },
+                "Timestamp": {
+                    "type": "string",
+                    "example": "2026-01-01T00:00:00.000Z"
+                },
+                "Token": {
+                    "type": "string"
+                },
+                "TopicArn": {
+                    "type": "string",
+                    "example": "arn:aws:sns:ap-southeast-1:123456789012:ses-notifications"
+                },
+                "Type": {
+                    "type": "string",
+                    "enum": [
+                        "Notification",
+                        "SubscriptionConfirmation",
+                        "UnsubscribeConfirmation"
+                    ],
+                    "example": "Notification"
+                }
+            }
+        },
+        "dtos.SaveRoleRequest": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Manages the users of the tenants"
+                },
+                "permissions": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read",
+                        "users:write"
+                    ]
+                }
+            }
+        },
+        "dtos.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "description": "Clients are the client IDs the session holds tokens of",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "web-app"
+                    ]
+                },
+                "current": {
+                    "description": "Current is set on the session of the token of the request",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0f6c9e4a-7d1b-4a38-9b25-3c0e8f2d6a11"
+                },
+                "ip_address": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_access_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "started_at": {
+                    "description": "StartedAt is when the user logged in, LastAccessAt when the session was last refreshed",
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                }
+            }
+        },
+        "dtos.SetUserRolesRequest": {
+            "type": "object",
+            "required": [
+                "roles"
+            ],
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user-manager"
+                    ]
+                }
+            }
+        },
+        "dtos.SubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "cancel_at_period_end": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "checkout_url": {
+                    "type": "string",
+                    "example": "https://checkout.stripe.com/c/pay/cs_test_123"
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "current_period_end": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "entitlements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.EntitlementResponse"
+                    }
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "pro"
+                },
+                "plan_name": {
+                    "type": "string",
+                    "example": "Pro"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "dtos.SwitchOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "organization_id"
+            ],
+            "properties": {
+                "organization_id": {
+                    "type": "string",
+                    "example": "3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f"
+                }
+            }
+        },
+        "dtos.SwitchOrganizationResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIs..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "id_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIs..."
+                },
+                "organization": {
+                    "$ref": "#/definitions/dtos.OrganizationResponse"
+                },
+                "refresh_expires_in": {
+                    "type": "integer",
+                    "example": 1800
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzUxMiIs..."
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "openid organization:acme"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "dtos.TenantCredentialResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "smtp"
+                },
+                "rotated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "secret": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dtos.TenantPerformanceResponse": {
+            "type": "object",
+            "properties": {
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "histogram": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PerformanceBucket"
+                    }
+                },
+                "hourly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.HourlyPerformance"
+                    }
+                },
+                "hours": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "overall": {
+                    "$ref": "#/definitions/dtos.PerformanceStats"
+                },
+                "routes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.RoutePerformance"
+                    }
+                }
+            }
+        },
+        "dtos.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIs..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "id_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIs..."
+                },
+                "refresh_expires_in": {
+                    "type": "integer",
+                    "example": 1800
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzUxMiIs..."
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "openid profile email"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "dtos.UpdateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "keycloak_id": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "123"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2,
+                    "example": "John Doe"
+                }
+            }
+        },
+        "dtos.UpdateMaintenanceTaskRequest": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 50
+                },
+                "throttle_ms": {
+                    "type": "integer",
+                    "maximum": 60000,
+                    "minimum": 0,
+                    "example": 1000
+                }
+            }
+        },
+        "dtos.UpdateOnboardingStepRequest": {
+            "type": "object",
+            "required": [
+                "completed"
+            ],
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dtos.UpdateRetentionPolicyRequest": {
+            "type": "object",
+            "required": [
+                "days"
+            ],
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 1,
+                    "example": 365
+                }
+            }
+        },
+        "dtos.UpdateUploadPolicyRequest": {
+            "type": "object",
+            "required": [
+                "allowed_content_types",
+                "banned_extensions"
+            ],
+            "properties": {
+                "allowed_content_types": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "image/png",
+                        "application/pdf"
+                    ]
+                },
+                "banned_extensions": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        ".exe",
+                        ".js"
+                    ]
+                },
+                "max_size_bytes": {
+                    "type": "integer",
+                    "maximum": 5368709120,
+                    "minimum": 0,
+                    "example": 10485760
+                }
+            }
+        },
+        "dtos.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "companies": {
+                    "description": "Companies of the user. On update, omitting them keeps the memberships and an empty\nlist removes them all.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.UpdateCompanyRequest"
+                    }
+                },
                 "email": {
                     "type": "string",
                     "example": "john.doe@example.com"
@@ -778,136 +9533,227 @@ const docTemplate = `{
                     "maxLength": 100,
                     "minLength": 2,
                     "example": "Doe"
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserStatus"
+                        }
+                    ],
+                    "example": "active"
                 }
             }
         },
-        "dtos.HealthResponse": {
+        "dtos.UpdateWebhookRequest": {
             "type": "object",
+            "required": [
+                "event_types"
+            ],
             "properties": {
-                "service": {
-                    "type": "string",
-                    "example": "golang-boilerplate"
+                "active": {
+                    "description": "Active pauses the deliveries to the endpoint when false",
+                    "type": "boolean",
+                    "example": true
                 },
-                "status": {
-                    "type": "string",
-                    "example": "healthy"
+                "conditions": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/dtos.WebhookCondition"
+                    }
                 },
-                "timestamp": {
+                "description": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "maxLength": 255,
+                    "example": "CRM sync"
                 },
-                "version": {
+                "event_types": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user.*",
+                        "company.updated"
+                    ]
+                },
+                "rate_limit": {
+                    "type": "integer",
+                    "maximum": 6000,
+                    "minimum": 0,
+                    "example": 60
+                },
+                "url": {
                     "type": "string",
-                    "example": "1.0.0"
+                    "maxLength": 2048,
+                    "example": "https://example.com/webhooks"
                 }
             }
         },
-        "dtos.Meta": {
-            "description": "Metadata for pagination",
+        "dtos.UploadPolicyResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 400
+                "allowed_content_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "image/png",
+                        "application/pdf"
+                    ]
                 },
-                "error_code": {
-                    "type": "string",
-                    "example": "BAD_REQUEST"
+                "banned_extensions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        ".exe",
+                        ".js"
+                    ]
                 },
-                "message": {
+                "company_id": {
                     "type": "string",
-                    "example": "Request is invalid"
+                    "example": "123"
                 },
-                "page": {
-                    "type": "integer",
-                    "example": 1
+                "configured": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "page_size": {
+                "max_size_bytes": {
                     "type": "integer",
-                    "example": 20
+                    "example": 10485760
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 18
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "123"
                 }
             }
         },
-        "dtos.UpdateCompanyRequest": {
+        "dtos.UserAvatarResponse": {
             "type": "object",
             "properties": {
-                "id": {
+                "avatar_key": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "123"
+                    "example": "avatars/123/0190b7f5.png"
                 },
-                "keycloak_id": {
+                "avatar_url": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "123"
+                    "example": "https://storage.googleapis.com/bucket/avatars/123/0190b7f5.png?X-Goog-Signature=..."
                 },
-                "name": {
+                "expires_at": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
-                    "example": "John Doe"
+                    "example": "2021-01-01T01:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "scanning",
+                        "available",
+                        "failed",
+                        "quarantined"
+                    ],
+                    "example": "available"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123"
                 }
             }
         },
-        "dtos.UpdateUserRequest": {
+        "dtos.UserResponse": {
             "type": "object",
             "properties": {
+                "avatar_key": {
+                    "type": "string",
+                    "example": "avatars/123/0190b7f5.png"
+                },
                 "companies": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dtos.UpdateCompanyRequest"
+                        "$ref": "#/definitions/dtos.CompanyResponse"
                     }
                 },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
                 "email": {
                     "type": "string",
                     "example": "john.doe@example.com"
                 },
                 "first_name": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
                     "example": "John"
                 },
-                "keycloak_id": {
+                "id": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
                     "example": "123"
                 },
                 "last_name": {
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 2,
                     "example": "Doe"
                 },
-                "status": {
-                    "enum": [
-                        "active",
-                        "inactive"
-                    ],
+                "mfa": {
+                    "description": "MFA is only returned by the lookup of a user by ID, and omitted when Keycloak cannot\nbe reached",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/constants.UserStatus"
+                            "$ref": "#/definitions/dtos.MFAStatusResponse"
                         }
-                    ],
-                    "example": "active"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
                 }
             }
         },
-        "dtos.UserResponse": {
+        "dtos.UserRolesResponse": {
             "type": "object",
             "properties": {
-                "companies": {
+                "permissions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dtos.CompanyResponse"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read",
+                        "users:write"
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user-manager"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.UserSearchResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_key": {
+                    "type": "string",
+                    "example": "avatars/123/0190b7f5.png"
                 },
                 "created_at": {
                     "type": "string",
@@ -921,6 +9767,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "John"
                 },
+                "highlight": {
+                    "description": "Highlight is the matched text with the matching words wrapped in \u003cmark\u003e tags",
+                    "type": "string",
+                    "example": "\u003cmark\u003eJohn\u003c/mark\u003e \u003cmark\u003eDoe\u003c/mark\u003e john.doe@example.com"
+                },
                 "id": {
                     "type": "string",
                     "example": "123"
@@ -929,14 +9780,229 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Doe"
                 },
+                "rank": {
+                    "description": "Rank is the relevance of the match, higher is better",
+                    "type": "number",
+                    "example": 0.6079271
+                }
+            }
+        },
+        "dtos.VoidPaymentRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Order 1042 canceled"
+                }
+            }
+        },
+        "dtos.WebhookCondition": {
+            "type": "object",
+            "required": [
+                "operator",
+                "path"
+            ],
+            "properties": {
+                "operator": {
+                    "type": "string",
+                    "enum": [
+                        "eq",
+                        "ne",
+                        "in",
+                        "exists"
+                    ],
+                    "example": "eq"
+                },
+                "path": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "$.status"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "dtos.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "user.updated"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery_of": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "response_body": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "response_status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "succeeded"
+                },
+                "webhook_id": {
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "dtos.WebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "company_id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.WebhookCondition"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CRM sync"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user.*",
+                        "company.updated"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "rate_limit": {
+                    "type": "integer",
+                    "example": 60
+                },
                 "updated_at": {
                     "type": "string",
                     "example": "2021-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "monitoring.DependencyHealth": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Failed to ping Redis"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
+                }
+            }
+        },
+        "monitoring.HealthReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/monitoring.DependencyHealth"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "healthy"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of a company, created through /companies/{id}/api-keys.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BasicAuth": {
             "type": "basic"
         },
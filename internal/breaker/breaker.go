@@ -0,0 +1,142 @@
+// Package breaker stops calling a failing dependency for a while, so that an outage costs
+// the callers a fast error instead of a timeout per call, and probes the dependency with a
+// single call before letting the traffic back.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned instead of calling the dependency while the breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker
+type State int
+
+const (
+	// Closed lets the calls through and counts their consecutive failures
+	Closed State = iota
+	// Open rejects the calls until the cooldown elapses
+	Open
+	// HalfOpen lets a single trial call through, whose outcome closes or reopens the breaker
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker opens after maxFailures consecutive failures and rejects the calls for cooldown,
+// then lets a trial call through: the breaker closes when it succeeds and opens for another
+// cooldown when it fails.
+type Breaker struct {
+	maxFailures int
+	cooldown    time.Duration
+	onChange    func(from, to State)
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// New creates a closed breaker, maxFailures must be positive. onChange, which may be nil,
+// is called on every change of state, outside of the lock of the breaker.
+func New(maxFailures int, cooldown time.Duration, onChange func(from, to State)) *Breaker {
+	return &Breaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		onChange:    onChange,
+		now:         time.Now,
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may go through. Every allowed call must be followed by
+// Done with its outcome.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	from := b.state
+	allowed := true
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			allowed = false
+			break
+		}
+		b.state = HalfOpen
+		b.trial = true
+	case HalfOpen:
+		if b.trial {
+			allowed = false
+			break
+		}
+		b.trial = true
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+	return allowed
+}
+
+// Done records the outcome of an allowed call. The calls allowed before the breaker opened
+// and completing after it do not change its state.
+func (b *Breaker) Done(success bool) {
+	b.mu.Lock()
+	from := b.state
+	switch {
+	case b.state == Open:
+	case success:
+		b.failures = 0
+		b.trial = false
+		b.state = Closed
+	case b.state == HalfOpen:
+		b.trial = false
+		b.open()
+	case b.state == Closed:
+		b.failures++
+		if b.failures >= b.maxFailures {
+			b.open()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+// open opens the breaker for a cooldown, b.mu held
+func (b *Breaker) open() {
+	b.state = Open
+	b.failures = 0
+	b.openedAt = b.now()
+}
+
+func (b *Breaker) changed(from, to State) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,83 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreaker creates a breaker on a clock moved by the test, recording its changes
+func newTestBreaker(maxFailures int, cooldown time.Duration) (*Breaker, *time.Time, *[]State) {
+	now := time.Now()
+	changes := []State{}
+	b := New(maxFailures, cooldown, func(from, to State) { changes = append(changes, to) })
+	b.now = func() time.Time { return now }
+	return b, &now, &changes
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b, _, changes := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		require.True(t, b.Allow())
+		b.Done(false)
+	}
+	// A success resets the count of consecutive failures
+	require.True(t, b.Allow())
+	b.Done(true)
+	for i := 0; i < 2; i++ {
+		require.True(t, b.Allow())
+		b.Done(false)
+	}
+	assert.Equal(t, Closed, b.State())
+
+	require.True(t, b.Allow())
+	b.Done(false)
+	assert.Equal(t, Open, b.State())
+	assert.False(t, b.Allow())
+	assert.Equal(t, []State{Open}, *changes)
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		trialSuccess  bool
+		expectedState State
+	}{
+		{name: "trial succeeds", trialSuccess: true, expectedState: Closed},
+		{name: "trial fails", trialSuccess: false, expectedState: Open},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, now, changes := newTestBreaker(1, time.Minute)
+			require.True(t, b.Allow())
+			b.Done(false)
+			require.False(t, b.Allow())
+
+			*now = now.Add(time.Minute)
+			assert.Equal(t, HalfOpen, b.State())
+			require.True(t, b.Allow())
+			// A single trial call goes through at once
+			assert.False(t, b.Allow())
+
+			b.Done(tt.trialSuccess)
+			assert.Equal(t, tt.expectedState, b.State())
+			assert.Equal(t, []State{Open, HalfOpen, tt.expectedState}, *changes)
+			assert.Equal(t, tt.trialSuccess, b.Allow())
+		})
+	}
+}
+
+func TestBreaker_LateOutcomeWhileOpen(t *testing.T) {
+	b, _, _ := newTestBreaker(1, time.Minute)
+	require.True(t, b.Allow())
+	require.True(t, b.Allow())
+	b.Done(false)
+
+	// The call allowed before the breaker opened does not close it
+	b.Done(true)
+	assert.Equal(t, Open, b.State())
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"math/rand/v2"
+	"time"
+
+	"golang-boilerplate/internal/breaker"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// loads shares the loads of the keys missing from the cache between the callers of
+// GetOrLoad of this instance
+var loads singleflight.Group
+
+// GetOrLoad returns the value of key in the cache, or loads it and caches it for ttl as
+// JSON. The cache only saves work: a failing cache or an undecodable value is logged and
+// the value loaded, and the errors of the loader are returned without being cached. The
+// reads rejected by the open circuit breaker are not logged, the breaker reported the outage.
+//
+// The concurrent misses of a key share a single load, so that an expired hot key costs one
+// database or Keycloak call per instance rather than one per request. The shared load is
+// not canceled with the context of the caller that started it, each caller only stops
+// waiting for it. The TTL is lengthened by up to constants.CacheTTLJitter, so that the
+// keys cached together do not expire together.
+func GetOrLoad[T any](ctx context.Context, c Cache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	return GetOrLoadTTL(ctx, c, key, func(ctx context.Context) (T, time.Duration, error) {
+		value, err := loader(ctx)
+		return value, jitteredTTL(ttl), err
+	})
+}
+
+// GetOrLoadTTL is GetOrLoad for the values whose TTL depends on the value, e.g. bounded by
+// its own expiry: loader returns the TTL of the value it loads, used as is, and a value
+// with a TTL of 0 or less is not cached.
+func GetOrLoadTTL[T any](ctx context.Context, c Cache, key string, loader func(ctx context.Context) (T, time.Duration, error)) (T, error) {
+	data, err := c.Get(ctx, key)
+	if err == nil {
+		var value T
+		if err := json.Unmarshal([]byte(data), &value); err == nil {
+			return value, nil
+		}
+		logger.Log.Warn("Failed to decode cached value", zap.String("key", key), zap.Error(err))
+	} else if appErr := errors.GetAppError(err); (appErr == nil || appErr.Type != errors.ErrorTypeNotFound) && !stderrors.Is(err, breaker.ErrOpen) {
+		logger.Log.Warn("Failed to read cached value", zap.String("key", key), zap.Error(err))
+	}
+
+	loadCtx := context.WithoutCancel(ctx)
+	results := loads.DoChan(key, func() (any, error) {
+		value, ttl, err := loader(loadCtx)
+		if err != nil || ttl <= 0 {
+			return value, err
+		}
+
+		if data, err := json.Marshal(value); err != nil {
+			logger.Log.Warn("Failed to encode value to cache", zap.String("key", key), zap.Error(err))
+		} else if err := c.Set(loadCtx, key, string(data), ttl); err != nil {
+			logger.Log.Warn("Failed to cache value", zap.String("key", key), zap.Error(err))
+		}
+		return value, nil
+	})
+
+	select {
+	case result := <-results:
+		value, _ := result.Val.(T)
+		return value, result.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// jitteredTTL lengthens ttl by a random fraction up to constants.CacheTTLJitter
+func jitteredTTL(ttl time.Duration) time.Duration {
+	return ttl + time.Duration(rand.Float64()*constants.CacheTTLJitter*float64(ttl))
+}
+
+// Invalidate removes key after a change of its value. A failure is logged, the stale
+// value then expires with its TTL.
+func Invalidate(ctx context.Context, c Cache, key string) {
+	if err := c.Delete(ctx, key); err != nil {
+		logger.Log.Warn("Failed to invalidate cached value", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"golang-boilerplate/internal/breaker"
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// BreakerCache stops calling the shared cache after CACHE_BREAKER_FAILURES consecutive
+// failures, so that a Redis outage fails the cache operations at once instead of after a
+// connection timeout each. The callers then bypass the cache: GetOrLoad loads from the
+// database, the exclusive jobs skip their tick. After CACHE_BREAKER_COOLDOWN a single
+// operation probes Redis, closing the breaker when it succeeds.
+type BreakerCache struct {
+	cache   Cache
+	breaker *breaker.Breaker
+}
+
+// NewBreakerCache guards cache with a circuit breaker reporting its changes to Sentry, New
+// Relic and metrics; nrApp may be nil
+func NewBreakerCache(cfg *config.Config, cache Cache, metrics *Metrics, nrApp *newrelic.Application) *BreakerCache {
+	onChange := func(from, to breaker.State) {
+		metrics.breakerOpen.Store(to != breaker.Closed)
+		switch to {
+		case breaker.Open:
+			if from == breaker.Closed {
+				metrics.breakerOpens.Add(1)
+				alertBreakerOpen(cfg)
+			}
+			if nrApp != nil {
+				nrApp.RecordCustomMetric("Custom/Cache/Breaker/Open", 1)
+			}
+		case breaker.Closed:
+			logger.Log.Info("Cache circuit breaker closed, Redis is reachable again")
+			if nrApp != nil {
+				nrApp.RecordCustomMetric("Custom/Cache/Breaker/Close", 1)
+			}
+		}
+	}
+
+	return &BreakerCache{
+		cache:   cache,
+		breaker: breaker.New(cfg.CacheBreakerFailures, cfg.CacheBreakerCooldown, onChange),
+	}
+}
+
+// alertBreakerOpen reports a Redis outage, the breaker reopening after a failed probe is
+// part of the same outage and not reported again
+func alertBreakerOpen(cfg *config.Config) {
+	logger.Log.Error("Cache circuit breaker opened, bypassing the cache",
+		zap.Int("failures", cfg.CacheBreakerFailures),
+		zap.Duration("cooldown", cfg.CacheBreakerCooldown))
+
+	hub := sentry.CurrentHub().Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("component", "cache")
+		scope.SetTag("cache_breaker", "open")
+		scope.SetLevel(sentry.LevelError)
+		scope.SetExtra("failures", cfg.CacheBreakerFailures)
+		scope.SetExtra("cooldown", cfg.CacheBreakerCooldown.String())
+		hub.CaptureMessage("Cache circuit breaker opened, Redis is unreachable")
+	})
+}
+
+// guard runs the operation of the cache unless the breaker is open. A miss is a success,
+// any other error a failure of the cache.
+func (c *BreakerCache) guard(operation string, key string, run func() error) error {
+	if !c.breaker.Allow() {
+		return errors.CacheError("Cache circuit breaker is open", breaker.ErrOpen).
+			WithOperation(operation).
+			WithResource("cache").
+			WithContext("key", key)
+	}
+
+	err := run()
+	appErr := errors.GetAppError(err)
+	c.breaker.Done(err == nil || (appErr != nil && appErr.Type == errors.ErrorTypeNotFound))
+	return err
+}
+
+func (c *BreakerCache) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := c.guard("get_cache", key, func() (err error) {
+		value, err = c.cache.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (c *BreakerCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	return c.guard("set_cache", key, func() error {
+		return c.cache.Set(ctx, key, value, expiration)
+	})
+}
+
+func (c *BreakerCache) Delete(ctx context.Context, key string) error {
+	return c.guard("delete_cache", key, func() error {
+		return c.cache.Delete(ctx, key)
+	})
+}
+
+func (c *BreakerCache) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := c.guard("exists_cache", key, func() (err error) {
+		exists, err = c.cache.Exists(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+func (c *BreakerCache) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	var stored bool
+	err := c.guard("setnx_cache", key, func() (err error) {
+		stored, err = c.cache.SetNX(ctx, key, value, expiration)
+		return err
+	})
+	return stored, err
+}
+
+func (c *BreakerCache) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	var deleted bool
+	err := c.guard("compare_and_delete_cache", key, func() (err error) {
+		deleted, err = c.cache.CompareAndDelete(ctx, key, value)
+		return err
+	})
+	return deleted, err
+}
+
+func (c *BreakerCache) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	var expired bool
+	err := c.guard("compare_and_expire_cache", key, func() (err error) {
+		expired, err = c.cache.CompareAndExpire(ctx, key, value, expiration)
+		return err
+	})
+	return expired, err
+}
+
+func (c *BreakerCache) Flush(ctx context.Context, pattern string) (int64, error) {
+	var removed int64
+	err := c.guard("flush_cache", pattern, func() (err error) {
+		removed, err = c.cache.Flush(ctx, pattern)
+		return err
+	})
+	return removed, err
+}
+
+// Ping bypasses the breaker, so that the health check reports the state of Redis itself
+func (c *BreakerCache) Ping(ctx context.Context) error {
+	return c.cache.Ping(ctx)
+}
+
+func (c *BreakerCache) Close() error {
+	return c.cache.Close()
+}
@@ -6,7 +6,10 @@ import (
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/monitoring"
 	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 // Cache defines the interface for cache operations
@@ -23,11 +26,50 @@ type Cache interface {
 	// Exists checks if a key exists in cache
 	Exists(ctx context.Context, key string) (bool, error)
 
+	// SetNX stores a value with expiration unless the key exists, and reports whether it
+	// was stored
+	SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error)
+
+	// CompareAndDelete removes the key if it holds value, and reports whether it did
+	CompareAndDelete(ctx context.Context, key string, value string) (bool, error)
+
+	// CompareAndExpire sets the expiration of the key if it holds value, and reports
+	// whether it did
+	CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error)
+
+	// Flush removes the keys matching a glob pattern, * for all, and returns how many were
+	// removed
+	Flush(ctx context.Context, pattern string) (int64, error)
+
+	// Ping checks that the cache is reachable
+	Ping(ctx context.Context) error
+
 	// Close closes the cache connection
 	Close() error
 }
 
-func ProvideCache(cfg *config.Config) (Cache, error) {
+// ProvideHealthChecker registers the cache in the health registry
+func ProvideHealthChecker(cache Cache) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:     constants.DependencyCache,
+		Critical: true,
+		Check:    cache.Ping,
+	}
+}
+
+// ProvideCache creates the cache of CACHE_PROVIDER, guarded by a circuit breaker unless
+// CACHE_BREAKER_FAILURES is 0, behind an in-process cache when CACHE_LOCAL_SIZE is set, and
+// records the metrics of its operations
+func ProvideCache(cfg *config.Config, metrics *Metrics, nrApp *newrelic.Application) (Cache, error) {
+	cache, err := newCache(cfg, metrics, nrApp)
+	if err != nil {
+		return nil, err
+	}
+	return NewInstrumentedCache(cache, metrics, nrApp), nil
+}
+
+// newCache creates the cache of CACHE_PROVIDER
+func newCache(cfg *config.Config, metrics *Metrics, nrApp *newrelic.Application) (Cache, error) {
 	switch cfg.CacheProvider {
 	case constants.CacheProviderRedis:
 		redisCache, err := NewRedisCache(cfg)
@@ -36,7 +78,22 @@ func ProvideCache(cfg *config.Config) (Cache, error) {
 				WithOperation("initialize_cache").
 				WithResource("cache")
 		}
-		return redisCache, nil
+		var remote Cache = redisCache
+		if cfg.CacheBreakerFailures > 0 {
+			remote = NewBreakerCache(cfg, redisCache, metrics, nrApp)
+		}
+		if cfg.CacheLocalSize == 0 {
+			return remote, nil
+		}
+		tieredCache, err := NewTieredCache(cfg, remote, redisCache)
+		if err != nil {
+			redisCache.Close()
+			return nil, errors.CacheError("Failed to initialize in-process cache", err).
+				WithOperation("initialize_cache").
+				WithResource("cache").
+				WithContext("cache_local_size", cfg.CacheLocalSize)
+		}
+		return tieredCache, nil
 	default:
 		return nil, errors.InternalError("Invalid cache provider", fmt.Errorf("invalid cache provider: %s", cfg.CacheProvider)).
 			WithOperation("initialize_cache").
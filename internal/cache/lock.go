@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrLockHeld is returned by Lock when another owner holds the lock
+var ErrLockHeld = stderrors.New("lock is held by another owner")
+
+// ErrLockLost is the cause of the context of a lease whose lock expired or was taken over
+// before its release
+var ErrLockLost = stderrors.New("lock lost")
+
+// Lease is a lock held in the cache, renewed in the background until its release
+type Lease struct {
+	cache Cache
+	key   string
+	token string
+	ttl   time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	stop    chan struct{}
+	done    chan struct{}
+	release sync.Once
+}
+
+// Lock acquires the lock named key across the instances sharing the cache, for ttl renewed
+// every third of it until the release, so that a crashed owner frees the lock within ttl.
+// It returns ErrLockHeld without waiting when another owner holds it. Only the owner
+// releases or renews the lock, identified by a random token.
+func Lock(ctx context.Context, c Cache, key string, ttl time.Duration) (*Lease, error) {
+	l := &Lease{
+		cache: c,
+		key:   constants.LockKeyPrefix + key,
+		token: uuid.NewString(),
+		ttl:   ttl,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	acquired, err := c.SetNX(ctx, l.key, l.token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockHeld
+	}
+
+	l.ctx, l.cancel = context.WithCancelCause(ctx)
+	go l.renew()
+	return l, nil
+}
+
+// Context is canceled when the lease is released or its lock lost, with ErrLockLost as
+// cause; the work done under the lock runs in it so that it stops once it is not alone
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Release stops the renewal and frees the lock unless another owner took it over
+func (l *Lease) Release(ctx context.Context) error {
+	var err error
+	l.release.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.cancel(context.Canceled)
+		_, err = l.cache.CompareAndDelete(ctx, l.key, l.token)
+	})
+	return err
+}
+
+// renew extends the lock every third of its TTL. A failed renewal is retried on the next
+// tick, and the lock considered lost once it may have expired.
+func (l *Lease) renew() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, l.ttl/3)
+		renewed, err := l.cache.CompareAndExpire(ctx, l.key, l.token, l.ttl)
+		cancel()
+		switch {
+		case err == nil && !renewed:
+			logger.Log.Warn("Lock taken over", zap.String("key", l.key))
+			l.cancel(ErrLockLost)
+			return
+		case err != nil && time.Since(renewedAt) >= l.ttl:
+			logger.Log.Warn("Lock expired before its renewal", zap.String("key", l.key), zap.Error(err))
+			l.cancel(ErrLockLost)
+			return
+		case err != nil:
+			logger.Log.Warn("Failed to renew lock", zap.String("key", l.key), zap.Error(err))
+		default:
+			renewedAt = time.Now()
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Operations of the cache, the operation label of its metrics
+const (
+	opGet              = "get"
+	opSet              = "set"
+	opDelete           = "delete"
+	opExists           = "exists"
+	opSetNX            = "setnx"
+	opCompareAndDelete = "compare_and_delete"
+	opCompareAndExpire = "compare_and_expire"
+	opFlush            = "flush"
+	opPing             = "ping"
+)
+
+// metricOperations lists the operations in the order of the exposition
+var metricOperations = []string{opGet, opSet, opDelete, opExists, opSetNX, opCompareAndDelete, opCompareAndExpire, opFlush, opPing}
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram buckets
+var latencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// operationMetrics counts the calls, errors and latencies of an operation
+type operationMetrics struct {
+	calls      atomic.Uint64
+	errors     atomic.Uint64
+	buckets    []atomic.Uint64
+	sumSeconds atomic.Uint64 // float64 bits
+}
+
+// Metrics counts the operations of the cache of the instance: calls, errors, latencies
+// and the hits and misses of Get
+type Metrics struct {
+	operations map[string]*operationMetrics
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+
+	// breakerOpen is set while the circuit breaker of the cache is not closed
+	breakerOpen  atomic.Bool
+	breakerOpens atomic.Uint64
+}
+
+// ProvideMetrics creates the metrics of the cache, served to Prometheus on the internal
+// listener
+func ProvideMetrics() *Metrics {
+	m := &Metrics{operations: make(map[string]*operationMetrics, len(metricOperations))}
+	for _, operation := range metricOperations {
+		m.operations[operation] = &operationMetrics{buckets: make([]atomic.Uint64, len(latencyBuckets))}
+	}
+	return m
+}
+
+// observe records a call of operation that took elapsed and failed with err
+func (m *Metrics) observe(operation string, elapsed time.Duration, err error) {
+	metrics := m.operations[operation]
+	metrics.calls.Add(1)
+	if err != nil {
+		metrics.errors.Add(1)
+	}
+
+	seconds := elapsed.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			metrics.buckets[i].Add(1)
+			break
+		}
+	}
+	for {
+		old := metrics.sumSeconds.Load()
+		sum := math.Float64frombits(old) + seconds
+		if metrics.sumSeconds.CompareAndSwap(old, math.Float64bits(sum)) {
+			break
+		}
+	}
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var err error
+	write := func(format string, args ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP cache_operations_total Cache operations by operation.\n# TYPE cache_operations_total counter\n")
+	for _, operation := range metricOperations {
+		write("cache_operations_total{operation=%q} %d\n", operation, m.operations[operation].calls.Load())
+	}
+	write("# HELP cache_errors_total Failed cache operations by operation; a miss is not a failure.\n# TYPE cache_errors_total counter\n")
+	for _, operation := range metricOperations {
+		write("cache_errors_total{operation=%q} %d\n", operation, m.operations[operation].errors.Load())
+	}
+	write("# HELP cache_hits_total Cache reads that found their key.\n# TYPE cache_hits_total counter\ncache_hits_total %d\n", m.hits.Load())
+	write("# HELP cache_misses_total Cache reads that did not find their key.\n# TYPE cache_misses_total counter\ncache_misses_total %d\n", m.misses.Load())
+
+	breakerOpen := 0
+	if m.breakerOpen.Load() {
+		breakerOpen = 1
+	}
+	write("# HELP cache_breaker_open Whether the circuit breaker of the cache bypasses it.\n# TYPE cache_breaker_open gauge\ncache_breaker_open %d\n", breakerOpen)
+	write("# HELP cache_breaker_opens_total Openings of the circuit breaker of the cache.\n# TYPE cache_breaker_opens_total counter\ncache_breaker_opens_total %d\n", m.breakerOpens.Load())
+
+	write("# HELP cache_operation_duration_seconds Latency of the cache operations.\n# TYPE cache_operation_duration_seconds histogram\n")
+	for _, operation := range metricOperations {
+		metrics := m.operations[operation]
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += metrics.buckets[i].Load()
+			write("cache_operation_duration_seconds_bucket{operation=%q,le=%q} %d\n", operation, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		calls := metrics.calls.Load()
+		write("cache_operation_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", operation, calls)
+		write("cache_operation_duration_seconds_sum{operation=%q} %s\n", operation, strconv.FormatFloat(math.Float64frombits(metrics.sumSeconds.Load()), 'g', -1, 64))
+		write("cache_operation_duration_seconds_count{operation=%q} %d\n", operation, calls)
+	}
+
+	return err
+}
+
+// InstrumentedCache records the metrics of the operations of a cache, in Metrics for
+// Prometheus and as Custom/Cache/* metrics in New Relic
+type InstrumentedCache struct {
+	cache   Cache
+	metrics *Metrics
+	nrApp   *newrelic.Application
+}
+
+// NewInstrumentedCache instruments cache; nrApp may be nil
+func NewInstrumentedCache(cache Cache, metrics *Metrics, nrApp *newrelic.Application) *InstrumentedCache {
+	return &InstrumentedCache{cache: cache, metrics: metrics, nrApp: nrApp}
+}
+
+// record records a call of operation started at startedAt that failed with err
+func (c *InstrumentedCache) record(operation string, startedAt time.Time, err error) {
+	elapsed := time.Since(startedAt)
+	c.metrics.observe(operation, elapsed, err)
+	if c.nrApp == nil {
+		return
+	}
+	c.nrApp.RecordCustomMetric("Custom/Cache/"+operation+"/Duration", elapsed.Seconds())
+	if err != nil {
+		c.nrApp.RecordCustomMetric("Custom/Cache/"+operation+"/Error", 1)
+	}
+}
+
+// Get records a hit, a miss or an error
+func (c *InstrumentedCache) Get(ctx context.Context, key string) (string, error) {
+	startedAt := time.Now()
+	value, err := c.cache.Get(ctx, key)
+
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		c.record(opGet, startedAt, nil)
+		c.metrics.misses.Add(1)
+		if c.nrApp != nil {
+			c.nrApp.RecordCustomMetric("Custom/Cache/Miss", 1)
+		}
+		return value, err
+	}
+	c.record(opGet, startedAt, err)
+	if err == nil {
+		c.metrics.hits.Add(1)
+		if c.nrApp != nil {
+			c.nrApp.RecordCustomMetric("Custom/Cache/Hit", 1)
+		}
+	}
+	return value, err
+}
+
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	startedAt := time.Now()
+	err := c.cache.Set(ctx, key, value, expiration)
+	c.record(opSet, startedAt, err)
+	return err
+}
+
+func (c *InstrumentedCache) Delete(ctx context.Context, key string) error {
+	startedAt := time.Now()
+	err := c.cache.Delete(ctx, key)
+	c.record(opDelete, startedAt, err)
+	return err
+}
+
+func (c *InstrumentedCache) Exists(ctx context.Context, key string) (bool, error) {
+	startedAt := time.Now()
+	exists, err := c.cache.Exists(ctx, key)
+	c.record(opExists, startedAt, err)
+	return exists, err
+}
+
+func (c *InstrumentedCache) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	startedAt := time.Now()
+	stored, err := c.cache.SetNX(ctx, key, value, expiration)
+	c.record(opSetNX, startedAt, err)
+	return stored, err
+}
+
+func (c *InstrumentedCache) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	startedAt := time.Now()
+	deleted, err := c.cache.CompareAndDelete(ctx, key, value)
+	c.record(opCompareAndDelete, startedAt, err)
+	return deleted, err
+}
+
+func (c *InstrumentedCache) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	startedAt := time.Now()
+	expired, err := c.cache.CompareAndExpire(ctx, key, value, expiration)
+	c.record(opCompareAndExpire, startedAt, err)
+	return expired, err
+}
+
+func (c *InstrumentedCache) Flush(ctx context.Context, pattern string) (int64, error) {
+	startedAt := time.Now()
+	removed, err := c.cache.Flush(ctx, pattern)
+	c.record(opFlush, startedAt, err)
+	return removed, err
+}
+
+func (c *InstrumentedCache) Ping(ctx context.Context) error {
+	startedAt := time.Now()
+	err := c.cache.Ping(ctx)
+	c.record(opPing, startedAt, err)
+	return err
+}
+
+func (c *InstrumentedCache) Close() error {
+	return c.cache.Close()
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedCache_Metrics(t *testing.T) {
+	remote := newFakeRemote()
+	metrics := ProvideMetrics()
+	c := NewInstrumentedCache(remote, metrics, nil)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "user:1", "alice", time.Minute))
+
+	// A hit, a miss and a failed read
+	_, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "user:2")
+	assert.Equal(t, errors.ErrorTypeNotFound, errors.GetAppError(err).Type)
+	remote.getErr = errors.CacheError("Failed to get from cache", assert.AnError)
+	_, err = c.Get(ctx, "user:1")
+	require.Error(t, err)
+
+	var out strings.Builder
+	require.NoError(t, metrics.WritePrometheus(&out))
+	exposition := out.String()
+
+	for _, line := range []string{
+		`cache_operations_total{operation="get"} 3`,
+		`cache_operations_total{operation="set"} 1`,
+		`cache_operations_total{operation="delete"} 0`,
+		`cache_errors_total{operation="get"} 1`,
+		`cache_errors_total{operation="set"} 0`,
+		"cache_hits_total 1",
+		"cache_misses_total 1",
+		"cache_breaker_open 0",
+		`cache_operation_duration_seconds_bucket{operation="get",le="+Inf"} 3`,
+		`cache_operation_duration_seconds_count{operation="get"} 3`,
+		`cache_operation_duration_seconds_count{operation="set"} 1`,
+	} {
+		assert.Contains(t, exposition, line+"\n")
+	}
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	metrics := ProvideMetrics()
+
+	metrics.observe(opGet, 300*time.Microsecond, nil)
+	metrics.observe(opGet, 20*time.Millisecond, nil)
+	metrics.observe(opGet, 2*time.Second, assert.AnError)
+
+	var out strings.Builder
+	require.NoError(t, metrics.WritePrometheus(&out))
+	exposition := out.String()
+
+	// The buckets are cumulative, and a latency above the last bound is only in +Inf
+	for _, line := range []string{
+		`cache_operation_duration_seconds_bucket{operation="get",le="0.0005"} 1`,
+		`cache_operation_duration_seconds_bucket{operation="get",le="0.01"} 1`,
+		`cache_operation_duration_seconds_bucket{operation="get",le="0.025"} 2`,
+		`cache_operation_duration_seconds_bucket{operation="get",le="1"} 2`,
+		`cache_operation_duration_seconds_bucket{operation="get",le="+Inf"} 3`,
+		`cache_operation_duration_seconds_sum{operation="get"} 2.0203`,
+		`cache_errors_total{operation="get"} 1`,
+	} {
+		assert.Contains(t, exposition, line+"\n")
+	}
+}
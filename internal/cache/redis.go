@@ -2,43 +2,72 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/retry"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// compareAndDeleteScript deletes KEYS[1] if it holds ARGV[1], atomically
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// compareAndExpireScript sets the expiration of KEYS[1] to ARGV[2] milliseconds if it
+// holds ARGV[1], atomically
+var compareAndExpireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 // RedisCache implements Cache interface using Redis
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-// NewRedisCache is the Fx provider for RedisCache
+// NewRedisCache is the Fx provider for RedisCache. It connects to the topology of
+// REDIS_MODE: a standalone node, a master found by the sentinels or a cluster.
 func NewRedisCache(cfg *config.Config) (*RedisCache, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:            fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
-		Password:        cfg.RedisPassword,
-		DB:              cfg.RedisDB,
-		PoolSize:        cfg.PoolSize,
-		DialTimeout:     cfg.DialTimeout,
-		ReadTimeout:     cfg.ReadTimeout,
-		WriteTimeout:    cfg.WriteTimeout,
-		PoolTimeout:     cfg.PoolTimeout,
-		MaxRetries:      cfg.MaxRetries,
-		MinRetryBackoff: cfg.MinRetryBackoff,
-		MaxRetryBackoff: cfg.MaxRetryBackoff,
-	})
-
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, errors.CacheError("Failed to configure Redis", err).
+			WithOperation("connect_redis").
+			WithResource("cache").
+			WithContext("redis_mode", cfg.RedisMode)
+	}
 
-	if err := client.Ping(ctx).Err(); err != nil {
+	// Test connection, retrying while Redis is still starting up
+	backoff := retry.Backoff{
+		InitialInterval: cfg.StartupRetryDelay,
+		MaxInterval:     cfg.StartupRetryMaxDelay,
+		Jitter:          retry.DefaultJitter,
+		MaxAttempts:     cfg.StartupRetryAttempts,
+		MaxElapsedTime:  cfg.StartupRetryMaxElapsed,
+	}
+	err = retry.Do(context.Background(), "connect_redis", backoff, func(ctx context.Context, attempt int) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		client.Close()
 		return nil, errors.CacheError("Failed to connect to Redis", err).
 			WithOperation("connect_redis").
-			WithResource("cache")
+			WithResource("cache").
+			WithContext("redis_mode", cfg.RedisMode)
 	}
 
 	return &RedisCache{
@@ -46,6 +75,100 @@ func NewRedisCache(cfg *config.Config) (*RedisCache, error) {
 	}, nil
 }
 
+// newRedisClient creates the client of the topology of REDIS_MODE. With
+// REDIS_READ_FROM_REPLICA, the sentinel topology uses a cluster client over the master
+// and its replicas, which sends the writes to the master and the reads to a replica.
+func newRedisClient(cfg *config.Config) (redis.UniversalClient, error) {
+	tlsConfig, err := redisTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.RedisMode {
+	case constants.RedisModeSentinel:
+		options := &redis.FailoverOptions{
+			MasterName:       cfg.RedisSentinelMaster,
+			SentinelAddrs:    cfg.RedisAddrs,
+			SentinelUsername: cfg.RedisUsername,
+			SentinelPassword: cfg.RedisSentinelPassword,
+			ReplicaOnly:      cfg.RedisReadFromReplica,
+			Username:         cfg.RedisUsername,
+			Password:         cfg.RedisPassword,
+			DB:               cfg.RedisDB,
+			PoolSize:         cfg.PoolSize,
+			DialTimeout:      cfg.DialTimeout,
+			ReadTimeout:      cfg.ReadTimeout,
+			WriteTimeout:     cfg.WriteTimeout,
+			PoolTimeout:      cfg.PoolTimeout,
+			MaxRetries:       cfg.MaxRetries,
+			MinRetryBackoff:  cfg.MinRetryBackoff,
+			MaxRetryBackoff:  cfg.MaxRetryBackoff,
+			TLSConfig:        tlsConfig,
+		}
+		if cfg.RedisReadFromReplica {
+			return redis.NewFailoverClusterClient(options), nil
+		}
+		return redis.NewFailoverClient(options), nil
+	case constants.RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           cfg.RedisAddrs,
+			ReadOnly:        cfg.RedisReadFromReplica,
+			Username:        cfg.RedisUsername,
+			Password:        cfg.RedisPassword,
+			PoolSize:        cfg.PoolSize,
+			DialTimeout:     cfg.DialTimeout,
+			ReadTimeout:     cfg.ReadTimeout,
+			WriteTimeout:    cfg.WriteTimeout,
+			PoolTimeout:     cfg.PoolTimeout,
+			MaxRetries:      cfg.MaxRetries,
+			MinRetryBackoff: cfg.MinRetryBackoff,
+			MaxRetryBackoff: cfg.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:            fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+			Username:        cfg.RedisUsername,
+			Password:        cfg.RedisPassword,
+			DB:              cfg.RedisDB,
+			PoolSize:        cfg.PoolSize,
+			DialTimeout:     cfg.DialTimeout,
+			ReadTimeout:     cfg.ReadTimeout,
+			WriteTimeout:    cfg.WriteTimeout,
+			PoolTimeout:     cfg.PoolTimeout,
+			MaxRetries:      cfg.MaxRetries,
+			MinRetryBackoff: cfg.MinRetryBackoff,
+			MaxRetryBackoff: cfg.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		}), nil
+	}
+}
+
+// redisTLSConfig returns the TLS configuration of the connections to Redis, nil without
+// REDIS_TLS. The server certificate is verified against REDIS_TLS_CA_FILE when set, for
+// the managed services signing them with a private CA.
+func redisTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if !cfg.RedisTLS {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.RedisTLSServerName,
+	}
+	if cfg.RedisTLSCAFile != "" {
+		ca, err := os.ReadFile(cfg.RedisTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read REDIS_TLS_CA_FILE: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("REDIS_TLS_CA_FILE %s has no PEM certificate", cfg.RedisTLSCAFile)
+		}
+	}
+	return tlsConfig, nil
+}
+
 // Get retrieves a value from Redis
 func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	result := r.client.Get(ctx, key)
@@ -100,6 +223,147 @@ func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	return result.Val() > 0, nil
 }
 
+// SetNX stores a value in Redis with expiration unless the key exists
+func (r *RedisCache) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	stored, err := r.client.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, errors.CacheError("Failed to set cache if absent", err).
+			WithOperation("setnx_cache").
+			WithResource("cache").
+			WithContext("key", key)
+	}
+	return stored, nil
+}
+
+// CompareAndDelete removes the key from Redis if it holds value
+func (r *RedisCache) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	deleted, err := compareAndDeleteScript.Run(ctx, r.client, []string{key}, value).Int64()
+	if err != nil {
+		return false, errors.CacheError("Failed to compare and delete from cache", err).
+			WithOperation("compare_and_delete_cache").
+			WithResource("cache").
+			WithContext("key", key)
+	}
+	return deleted > 0, nil
+}
+
+// CompareAndExpire sets the expiration of the key in Redis if it holds value
+func (r *RedisCache) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	expired, err := compareAndExpireScript.Run(ctx, r.client, []string{key}, value, expiration.Milliseconds()).Int64()
+	if err != nil {
+		return false, errors.CacheError("Failed to compare and expire cache", err).
+			WithOperation("compare_and_expire_cache").
+			WithResource("cache").
+			WithContext("key", key)
+	}
+	return expired > 0, nil
+}
+
+// Flush removes the keys matching pattern in batches, scanning the keyspace rather than
+// blocking Redis with KEYS or FLUSHDB. A cluster is scanned master by master, as each
+// node only scans its own keys.
+func (r *RedisCache) Flush(ctx context.Context, pattern string) (int64, error) {
+	var removed int64
+	var err error
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		var clusterRemoved atomic.Int64
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := flushNode(ctx, node, pattern)
+			clusterRemoved.Add(n)
+			return err
+		})
+		removed = clusterRemoved.Load()
+	} else {
+		removed, err = flushNode(ctx, r.client, pattern)
+	}
+	if err != nil {
+		return removed, errors.CacheError("Failed to flush cache", err).
+			WithOperation("flush_cache").
+			WithResource("cache").
+			WithContext("pattern", pattern)
+	}
+
+	return removed, nil
+}
+
+// flushNode removes the keys of a node matching pattern. The keys of a batch are removed
+// one by one in a pipeline, as a cluster rejects the commands on keys of several slots.
+func flushNode(ctx context.Context, client redis.UniversalClient, pattern string) (int64, error) {
+	var removed int64
+	unlink := func(keys []string) error {
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if unlinked, ok := cmd.(*redis.IntCmd); ok {
+				removed += unlinked.Val()
+			}
+		}
+		return err
+	}
+
+	iter := client.Scan(ctx, 0, pattern, constants.CacheFlushBatchSize).Iterator()
+	keys := make([]string, 0, constants.CacheFlushBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) < constants.CacheFlushBatchSize {
+			continue
+		}
+		if err := unlink(keys); err != nil {
+			return removed, err
+		}
+		keys = keys[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return removed, fmt.Errorf("scan: %w", err)
+	}
+	if len(keys) > 0 {
+		if err := unlink(keys); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// Ping checks that Redis is reachable
+func (r *RedisCache) Ping(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return errors.CacheError("Failed to ping Redis", err).
+			WithOperation("ping_cache").
+			WithResource("cache")
+	}
+	return nil
+}
+
+// publishInvalidation publishes key on the invalidation channel of the in-process caches
+func (r *RedisCache) publishInvalidation(ctx context.Context, key string) error {
+	return r.client.Publish(ctx, constants.CacheInvalidationChannel, key).Err()
+}
+
+// listenInvalidations subscribes to the invalidation channel of the in-process caches
+// until ctx is done. The subscription reconnects by itself when Redis is unreachable.
+func (r *RedisCache) listenInvalidations(ctx context.Context, onKey func(key string)) {
+	pubsub := r.client.Subscribe(ctx, constants.CacheInvalidationChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			onKey(message.Payload)
+		}
+	}
+}
+
 // Close closes the Redis connection
 func (r *RedisCache) Close() error {
 	err := r.client.Close()
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.uber.org/zap"
+)
+
+// invalidationBus broadcasts the changed keys to the in-process caches of all instances
+type invalidationBus interface {
+	// publishInvalidation tells the instances to drop key, CacheInvalidateAll for all keys
+	publishInvalidation(ctx context.Context, key string) error
+	// listenInvalidations calls onKey with the keys published until ctx is done
+	listenInvalidations(ctx context.Context, onKey func(key string))
+}
+
+// localEntry is a value of the in-process cache
+type localEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// TieredCache serves the hot keys from an in-process LRU cache in front of a shared cache,
+// saving a Redis round trip per read. The keys changed on an instance are dropped from the
+// local caches of all of them through the invalidation bus; an invalidation missed while
+// the bus reconnects is bounded by the short TTL of the local entries.
+type TieredCache struct {
+	remote   Cache
+	local    *lru.Cache[string, localEntry]
+	ttl      time.Duration
+	prefixes []string
+	bus      invalidationBus
+
+	// generation changes on every invalidation, so that a value read from the shared cache
+	// before an invalidation is not kept locally after it
+	generation atomic.Uint64
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewTieredCache creates the in-process cache of CACHE_LOCAL_SIZE entries in front of
+// remote and starts listening to the invalidations of the other instances
+func NewTieredCache(cfg *config.Config, remote Cache, bus invalidationBus) (*TieredCache, error) {
+	local, err := lru.New[string, localEntry](cfg.CacheLocalSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &TieredCache{
+		remote:   remote,
+		local:    local,
+		ttl:      cfg.CacheLocalTTL,
+		prefixes: cfg.CacheLocalPrefixes,
+		bus:      bus,
+		cancel:   cancel,
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		bus.listenInvalidations(ctx, c.drop)
+	}()
+	return c, nil
+}
+
+// Get serves key from the local cache, or reads it from the shared cache and keeps it
+// locally for the local TTL
+func (c *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if !c.cacheable(key) {
+		return c.remote.Get(ctx, key)
+	}
+	if entry, ok := c.local.Get(key); ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	generation := c.generation.Load()
+	value, err := c.remote.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if c.generation.Load() == generation {
+		c.local.Add(key, localEntry{value: value, expiresAt: time.Now().Add(c.ttl)})
+	}
+	return value, nil
+}
+
+// Set stores key in the shared cache and drops it from the local caches
+func (c *TieredCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+	c.invalidate(ctx, key)
+	return nil
+}
+
+// Delete removes key from the shared cache and from the local caches
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	c.invalidate(ctx, key)
+	return nil
+}
+
+// Exists checks the local cache before the shared one
+func (c *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if c.cacheable(key) {
+		if entry, ok := c.local.Peek(key); ok && time.Now().Before(entry.expiresAt) {
+			return true, nil
+		}
+	}
+	return c.remote.Exists(ctx, key)
+}
+
+// SetNX stores key in the shared cache unless it exists, and drops it from the local
+// caches when stored
+func (c *TieredCache) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	stored, err := c.remote.SetNX(ctx, key, value, expiration)
+	if stored {
+		c.invalidate(ctx, key)
+	}
+	return stored, err
+}
+
+// CompareAndDelete removes key from the shared cache if it holds value, and from the
+// local caches when removed
+func (c *TieredCache) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	deleted, err := c.remote.CompareAndDelete(ctx, key, value)
+	if deleted {
+		c.invalidate(ctx, key)
+	}
+	return deleted, err
+}
+
+// CompareAndExpire sets the expiration of key in the shared cache if it holds value; the
+// local entries keep their own TTL
+func (c *TieredCache) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	return c.remote.CompareAndExpire(ctx, key, value, expiration)
+}
+
+// Flush removes the keys matching pattern from the shared cache and clears the local
+// caches, whatever the pattern
+func (c *TieredCache) Flush(ctx context.Context, pattern string) (int64, error) {
+	removed, err := c.remote.Flush(ctx, pattern)
+	c.invalidate(ctx, constants.CacheInvalidateAll)
+	return removed, err
+}
+
+// Ping checks that the shared cache is reachable
+func (c *TieredCache) Ping(ctx context.Context) error {
+	return c.remote.Ping(ctx)
+}
+
+// Close stops listening to the invalidations and closes the shared cache
+func (c *TieredCache) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return c.remote.Close()
+}
+
+// cacheable reports whether key is kept in the local cache
+func (c *TieredCache) cacheable(key string) bool {
+	if len(c.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate drops key locally and publishes it to the other instances. A failed publish
+// is logged, the other instances keep the key until their local TTL.
+func (c *TieredCache) invalidate(ctx context.Context, key string) {
+	c.drop(key)
+	if err := c.bus.publishInvalidation(ctx, key); err != nil {
+		logger.Log.Warn("Failed to publish cache invalidation", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// drop removes key from the local cache, or all keys for CacheInvalidateAll
+func (c *TieredCache) drop(key string) {
+	c.generation.Add(1)
+	if key == constants.CacheInvalidateAll {
+		c.local.Purge()
+		return
+	}
+	c.local.Remove(key)
+}
@@ -0,0 +1,374 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// fakeRemote is a shared cache in memory counting its reads. onGet runs after a value is
+// read and before it is returned, and getErr fails the reads.
+type fakeRemote struct {
+	mu     sync.Mutex
+	values map[string]string
+	gets   int
+	getErr error
+	onGet  func(key string)
+}
+
+func newFakeRemote() *fakeRemote {
+	return &fakeRemote{values: map[string]string{}}
+}
+
+func (r *fakeRemote) Get(ctx context.Context, key string) (string, error) {
+	r.mu.Lock()
+	r.gets++
+	value, ok := r.values[key]
+	getErr, onGet := r.getErr, r.onGet
+	r.mu.Unlock()
+
+	if getErr != nil {
+		return "", getErr
+	}
+	if !ok {
+		return "", errors.NotFoundError("Cache key", nil)
+	}
+	if onGet != nil {
+		onGet(key)
+	}
+	return value, nil
+}
+
+func (r *fakeRemote) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+	return nil
+}
+
+func (r *fakeRemote) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.values, key)
+	return nil
+}
+
+func (r *fakeRemote) Exists(ctx context.Context, key string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.values[key]
+	return ok, nil
+}
+
+func (r *fakeRemote) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.values[key]; ok {
+		return false, nil
+	}
+	r.values[key] = value
+	return true, nil
+}
+
+func (r *fakeRemote) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.values[key] != value {
+		return false, nil
+	}
+	delete(r.values, key)
+	return true, nil
+}
+
+func (r *fakeRemote) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[key] == value, nil
+}
+
+func (r *fakeRemote) Flush(ctx context.Context, pattern string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	removed := int64(len(r.values))
+	r.values = map[string]string{}
+	return removed, nil
+}
+
+func (r *fakeRemote) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeRemote) Close() error {
+	return nil
+}
+
+// set changes key in the shared cache only, as another instance would
+func (r *fakeRemote) set(key string, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+}
+
+func (r *fakeRemote) getCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gets
+}
+
+// fakeBus records the published invalidations and delivers the ones of the other
+// instances with deliver
+type fakeBus struct {
+	mu         sync.Mutex
+	published  []string
+	publishErr error
+	onKey      func(key string)
+	listening  chan struct{}
+	stopped    chan struct{}
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{listening: make(chan struct{}), stopped: make(chan struct{})}
+}
+
+func (b *fakeBus) publishInvalidation(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, key)
+	return b.publishErr
+}
+
+func (b *fakeBus) listenInvalidations(ctx context.Context, onKey func(key string)) {
+	b.mu.Lock()
+	b.onKey = onKey
+	b.mu.Unlock()
+	close(b.listening)
+	<-ctx.Done()
+	close(b.stopped)
+}
+
+// deliver delivers an invalidation published by another instance
+func (b *fakeBus) deliver(key string) {
+	b.mu.Lock()
+	onKey := b.onKey
+	b.mu.Unlock()
+	onKey(key)
+}
+
+func (b *fakeBus) publishedKeys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.published...)
+}
+
+func newTestTieredCache(t *testing.T, remote *fakeRemote, bus *fakeBus, prefixes ...string) *TieredCache {
+	t.Helper()
+	c, err := NewTieredCache(&config.Config{CacheLocalSize: 16, CacheLocalTTL: time.Minute, CacheLocalPrefixes: prefixes}, remote, bus)
+	require.NoError(t, err)
+	<-bus.listening
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestTieredCache_Get(t *testing.T) {
+	t.Run("hot keys are served locally", func(t *testing.T) {
+		remote := newFakeRemote()
+		remote.set("user:1", "alice")
+		c := newTestTieredCache(t, remote, newFakeBus())
+
+		for range 3 {
+			value, err := c.Get(context.Background(), "user:1")
+			require.NoError(t, err)
+			assert.Equal(t, "alice", value)
+		}
+		assert.Equal(t, 1, remote.getCount())
+	})
+
+	t.Run("keys outside the prefixes are always read from the shared cache", func(t *testing.T) {
+		remote := newFakeRemote()
+		remote.set("session:1", "token")
+		c := newTestTieredCache(t, remote, newFakeBus(), "user:")
+
+		for range 2 {
+			_, err := c.Get(context.Background(), "session:1")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, remote.getCount())
+	})
+
+	t.Run("misses are not kept locally", func(t *testing.T) {
+		remote := newFakeRemote()
+		c := newTestTieredCache(t, remote, newFakeBus())
+
+		_, err := c.Get(context.Background(), "user:1")
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorTypeNotFound, errors.GetAppError(err).Type)
+
+		remote.set("user:1", "alice")
+		value, err := c.Get(context.Background(), "user:1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", value)
+	})
+}
+
+func TestTieredCache_StaleReadAfterInvalidation(t *testing.T) {
+	remote := newFakeRemote()
+	remote.set("user:1", "alice")
+	bus := newFakeBus()
+	c := newTestTieredCache(t, remote, bus)
+
+	// Another instance changes the key while this read is in flight: the value read before
+	// the invalidation is returned but not kept
+	remote.onGet = func(key string) {
+		remote.onGet = nil
+		remote.set(key, "bob")
+		bus.deliver(key)
+	}
+	value, err := c.Get(context.Background(), "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", value)
+
+	value, err = c.Get(context.Background(), "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", value)
+	assert.Equal(t, 2, remote.getCount())
+}
+
+func TestTieredCache_InvalidationsOfOtherInstances(t *testing.T) {
+	remote := newFakeRemote()
+	remote.set("user:1", "alice")
+	remote.set("user:2", "bob")
+	bus := newFakeBus()
+	c := newTestTieredCache(t, remote, bus)
+	ctx := context.Background()
+
+	for _, key := range []string{"user:1", "user:2"} {
+		_, err := c.Get(ctx, key)
+		require.NoError(t, err)
+	}
+
+	remote.set("user:1", "carol")
+	bus.deliver("user:1")
+	value, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "carol", value)
+	value, err = c.Get(ctx, "user:2")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", value, "the other keys stay local")
+	assert.Equal(t, 3, remote.getCount())
+
+	remote.set("user:2", "dave")
+	bus.deliver(constants.CacheInvalidateAll)
+	value, err = c.Get(ctx, "user:2")
+	require.NoError(t, err)
+	assert.Equal(t, "dave", value)
+	exists, err := c.Exists(ctx, "user:1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 4, remote.getCount())
+	assert.Equal(t, 1, c.local.Len(), "only the key read again is local")
+}
+
+func TestTieredCache_Writes(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(ctx context.Context, c *TieredCache) error
+		expected []string
+	}{
+		{
+			name:     "set",
+			write:    func(ctx context.Context, c *TieredCache) error { return c.Set(ctx, "user:1", "bob", time.Minute) },
+			expected: []string{"user:1"},
+		},
+		{
+			name:     "delete",
+			write:    func(ctx context.Context, c *TieredCache) error { return c.Delete(ctx, "user:1") },
+			expected: []string{"user:1"},
+		},
+		{
+			name: "compare and delete",
+			write: func(ctx context.Context, c *TieredCache) error {
+				_, err := c.CompareAndDelete(ctx, "user:1", "alice")
+				return err
+			},
+			expected: []string{"user:1"},
+		},
+		{
+			name: "flush",
+			write: func(ctx context.Context, c *TieredCache) error {
+				_, err := c.Flush(ctx, "user:*")
+				return err
+			},
+			expected: []string{constants.CacheInvalidateAll},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newFakeRemote()
+			remote.set("user:1", "alice")
+			bus := newFakeBus()
+			c := newTestTieredCache(t, remote, bus)
+			ctx := context.Background()
+			_, err := c.Get(ctx, "user:1")
+			require.NoError(t, err)
+
+			require.NoError(t, tt.write(ctx, c))
+
+			assert.Equal(t, tt.expected, bus.publishedKeys())
+			_, ok := c.local.Peek("user:1")
+			assert.False(t, ok, "the key is dropped locally")
+		})
+	}
+}
+
+func TestTieredCache_FailedPublish(t *testing.T) {
+	remote := newFakeRemote()
+	remote.set("user:1", "alice")
+	bus := newFakeBus()
+	bus.publishErr = assert.AnError
+	c := newTestTieredCache(t, remote, bus)
+	ctx := context.Background()
+	_, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+
+	// The write is in the shared cache, so it succeeds; the other instances keep the key
+	// until their local TTL
+	require.NoError(t, c.Set(ctx, "user:1", "bob", time.Minute))
+
+	assert.Equal(t, []string{"user:1"}, bus.publishedKeys())
+	value, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", value)
+}
+
+func TestTieredCache_Close(t *testing.T) {
+	bus := newFakeBus()
+	c, err := NewTieredCache(&config.Config{CacheLocalSize: 16, CacheLocalTTL: time.Minute}, newFakeRemote(), bus)
+	require.NoError(t, err)
+	<-bus.listening
+
+	require.NoError(t, c.Close())
+
+	select {
+	case <-bus.stopped:
+	default:
+		t.Fatal("the invalidations are still listened to")
+	}
+}
@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSProvider reads secrets from AWS Secrets Manager. The path is the name or the ARN of
+// the secret, whose current version is read.
+type AWSProvider struct {
+	client *secretsmanager.Client
+}
+
+// NewAWSProvider creates a provider authenticated by the default AWS credential chain, in
+// the region of AWS_REGION
+func NewAWSProvider(ctx context.Context) (Provider, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load AWS config: %w", err)
+	}
+
+	return &AWSProvider{client: secretsmanager.NewFromConfig(awsCfg)}, nil
+}
+
+func (p *AWSProvider) Fetch(ctx context.Context, path string) (string, error) {
+	output, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(path),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if output.SecretString != nil {
+		return aws.ToString(output.SecretString), nil
+	}
+	return string(output.SecretBinary), nil
+}
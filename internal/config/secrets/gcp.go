@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	secretmanager "google.golang.org/api/secretmanager/v1"
+)
+
+// GCPProvider reads secrets from GCP Secret Manager. The path is the resource name of the
+// secret, projects/{project}/secrets/{secret}, whose latest version is read, or of one of
+// its versions, projects/{project}/secrets/{secret}/versions/{version}.
+type GCPProvider struct {
+	service *secretmanager.Service
+}
+
+// NewGCPProvider creates a provider authenticated by the application default credentials,
+// e.g. the service account of GOOGLE_APPLICATION_CREDENTIALS
+func NewGCPProvider(ctx context.Context) (Provider, error) {
+	service, err := secretmanager.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create secret manager client: %w", err)
+	}
+
+	return &GCPProvider{service: service}, nil
+}
+
+func (p *GCPProvider) Fetch(ctx context.Context, path string) (string, error) {
+	name := path
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	response, err := p.service.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	if response.Payload == nil {
+		return "", fmt.Errorf("secret version %s has no payload", name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decode secret version %s: %w", name, err)
+	}
+	return string(data), nil
+}
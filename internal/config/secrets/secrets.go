@@ -0,0 +1,148 @@
+// Package secrets resolves the config values that reference a secret in a secrets manager
+// instead of holding it, so that Keycloak secrets and database passwords do not have to
+// live in .env files. A reference is scheme://path#key: the secret at path is fetched from
+// the manager of the scheme and, when key is set, parsed as a JSON object to read the key.
+//
+//	vault://secret/data/app#db_password       HashiCorp Vault, KV v1 or v2
+//	awssm://prod/app#keycloak_secret          AWS Secrets Manager, by name or ARN
+//	gcpsm://projects/acme/secrets/db-password GCP Secret Manager, latest version
+//
+// The package does not depend on the config, which resolves the references before reading
+// its values; the managers are configured by their usual environment variables.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Reference points to a secret, or to a key of a JSON secret, in a secrets manager
+type Reference struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+// secret returns the reference to the whole secret, without its key
+func (r Reference) secret() string {
+	return r.Scheme + "://" + r.Path
+}
+
+// Provider fetches secrets from a secrets manager
+type Provider interface {
+	// Fetch returns the secret at path; a secret with several keys is returned as a JSON
+	// object
+	Fetch(ctx context.Context, path string) (string, error)
+}
+
+// ProviderFactory creates the provider of a scheme; it is only called once a value
+// references the scheme, so that unused managers need no credentials
+type ProviderFactory func(ctx context.Context) (Provider, error)
+
+// DefaultProviders returns the factories of the supported secrets managers
+func DefaultProviders() map[string]ProviderFactory {
+	return map[string]ProviderFactory{
+		constants.SecretSchemeVault: NewVaultProviderFromEnv,
+		constants.SecretSchemeAWS:   NewAWSProvider,
+		constants.SecretSchemeGCP:   NewGCPProvider,
+	}
+}
+
+// ParseReference parses value as a secret reference of one of the schemes, and reports
+// whether it is one. A value with another or no scheme, such as a URL, is not a reference.
+func ParseReference(value string, schemes map[string]ProviderFactory) (Reference, bool, error) {
+	scheme, rest, found := strings.Cut(value, "://")
+	if !found {
+		return Reference{}, false, nil
+	}
+	if _, ok := schemes[scheme]; !ok {
+		return Reference{}, false, nil
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return Reference{}, true, fmt.Errorf("secret reference %q has no path", value)
+	}
+
+	return Reference{Scheme: scheme, Path: path, Key: key}, true, nil
+}
+
+// Resolver resolves secret references, fetching each secret once however many of its keys
+// are referenced
+type Resolver struct {
+	factories map[string]ProviderFactory
+	providers map[string]Provider
+	secrets   map[string]string
+}
+
+// NewResolver creates a resolver of the references of the schemes of factories
+func NewResolver(factories map[string]ProviderFactory) *Resolver {
+	return &Resolver{
+		factories: factories,
+		providers: make(map[string]Provider),
+		secrets:   make(map[string]string),
+	}
+}
+
+// Resolve returns the secret value referenced by value, or value itself when it is not a
+// reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	ref, isReference, err := ParseReference(value, r.factories)
+	if err != nil || !isReference {
+		return value, err
+	}
+
+	secret, err := r.fetch(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if ref.Key == "" {
+		return secret, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, it has no key %q", ref.secret(), ref.Key)
+	}
+	field, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", ref.secret(), ref.Key)
+	}
+
+	// String fields are unquoted, numbers and booleans are kept as written
+	var text string
+	if err := json.Unmarshal(field, &text); err == nil {
+		return text, nil
+	}
+	return string(field), nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, ref Reference) (string, error) {
+	cacheKey := ref.secret()
+	if secret, ok := r.secrets[cacheKey]; ok {
+		return secret, nil
+	}
+
+	provider, ok := r.providers[ref.Scheme]
+	if !ok {
+		var err error
+		provider, err = r.factories[ref.Scheme](ctx)
+		if err != nil {
+			return "", fmt.Errorf("initialize %s secrets provider: %w", ref.Scheme, err)
+		}
+		r.providers[ref.Scheme] = provider
+	}
+
+	secret, err := provider.Fetch(ctx, ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("fetch secret %s: %w", cacheKey, err)
+	}
+	r.secrets[cacheKey] = secret
+
+	return secret, nil
+}
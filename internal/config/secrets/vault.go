@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from HashiCorp Vault over its HTTP API. The path is the API
+// path of the secret, e.g. secret/data/app for the app secret of a KV v2 engine mounted at
+// secret.
+type VaultProvider struct {
+	client    *http.Client
+	address   string
+	token     string
+	namespace string
+}
+
+// NewVaultProvider creates a provider of the Vault server at address authenticated by token
+func NewVaultProvider(address string, token string, namespace string) *VaultProvider {
+	return &VaultProvider{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		address:   strings.TrimRight(address, "/"),
+		token:     token,
+		namespace: namespace,
+	}
+}
+
+// NewVaultProviderFromEnv creates a provider configured by the VAULT_ADDR, VAULT_TOKEN and
+// VAULT_NAMESPACE variables of the Vault CLI
+func NewVaultProviderFromEnv(ctx context.Context) (Provider, error) {
+	address := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if address == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required")
+	}
+
+	return NewVaultProvider(address, token, os.Getenv("VAULT_NAMESPACE")), nil
+}
+
+func (p *VaultProvider) Fetch(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// The body only holds error messages, never the secret
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+
+	// A KV v2 engine nests the secret under data, next to its metadata
+	data, nested := payload.Data["data"]
+	if _, versioned := payload.Data["metadata"]; nested && versioned {
+		return string(data), nil
+	}
+
+	secret, err := json.Marshal(payload.Data)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Source is where the value of a config variable was loaded from
+type Source struct {
+	// Origin is one of the constants.ConfigSource values
+	Origin string
+	// Reference is the secret reference the value was resolved from, when it comes from a
+	// secrets manager
+	Reference string
+}
+
+// Setting is the effective value of a config variable. Secret settings hold the plain
+// value, callers exposing them must mask it.
+type Setting struct {
+	Name   string
+	Value  string
+	Secret bool
+	Source Source
+}
+
+// Settings returns the effective value of every config variable, in the order of the
+// Config fields, with where it was loaded from
+func (c *Config) Settings() []Setting {
+	value := reflect.ValueOf(c).Elem()
+	configType := value.Type()
+
+	var settings []Setting
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		source, ok := c.sources[name]
+		if !ok {
+			source = Source{Origin: constants.ConfigSourceDefault}
+		}
+		settings = append(settings, Setting{
+			Name:   name,
+			Value:  formatValue(value.Field(i)),
+			Secret: field.Tag.Get("secret") == "true",
+			Source: source,
+		})
+	}
+	return settings
+}
+
+func formatValue(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// environmentKeys returns the variables of the process environment
+func environmentKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, variable := range os.Environ() {
+		key, _, _ := strings.Cut(variable, "=")
+		keys[key] = true
+	}
+	return keys
+}
+
+// loadSources records the source of each config variable: a secret reference resolved
+// from a secrets manager, the process environment, an env file when it was not in the
+// process environment, or the default when it is unset
+func loadSources(processEnv map[string]bool, references map[string]string) map[string]Source {
+	sources := make(map[string]Source)
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		name := configType.Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		switch {
+		case os.Getenv(name) == "":
+			sources[name] = Source{Origin: constants.ConfigSourceDefault}
+		case references[name] != "":
+			sources[name] = Source{Origin: constants.ConfigSourceSecretsManager, Reference: references[name]}
+		case processEnv[name]:
+			sources[name] = Source{Origin: constants.ConfigSourceEnv}
+		default:
+			sources[name] = Source{Origin: constants.ConfigSourceDotenv}
+		}
+	}
+	return sources
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Settings(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("JOBS_TIMEOUT", "90s")
+	processEnv := environmentKeys()
+	// Set after the snapshot, as if read from the .env file
+	t.Setenv("APP_NAME", "dotenv-app")
+
+	cfg, err := load(processEnv)
+	require.NoError(t, err)
+
+	settings := make(map[string]Setting)
+	for _, setting := range cfg.Settings() {
+		settings[setting.Name] = setting
+	}
+
+	tests := []struct {
+		name           string
+		expectedValue  string
+		expectedSource string
+		expectedSecret bool
+	}{
+		{name: "POSTGRES_DB", expectedValue: "app", expectedSource: constants.ConfigSourceEnv},
+		{name: "JOBS_TIMEOUT", expectedValue: "1m30s", expectedSource: constants.ConfigSourceEnv},
+		{name: "APP_NAME", expectedValue: "dotenv-app", expectedSource: constants.ConfigSourceDotenv},
+		{name: "KEYCLOAK_CLIENT_SECRET", expectedValue: "secret", expectedSource: constants.ConfigSourceEnv, expectedSecret: true},
+		{name: "POSTGRES_PASSWORD", expectedSource: constants.ConfigSourceDefault, expectedSecret: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setting, ok := settings[tt.name]
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedValue, setting.Value)
+			assert.Equal(t, tt.expectedSource, setting.Source.Origin)
+			assert.Equal(t, tt.expectedSecret, setting.Secret)
+		})
+	}
+}
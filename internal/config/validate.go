@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError lists every problem of the configuration, so that a deployment is fixed
+// in one go rather than one variable per restart
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the config against the validate tags of its fields and the rules across
+// fields, and returns a ValidationError listing all the problems. Problems name the
+// environment variables of the fields.
+func (c *Config) Validate() error {
+	problems := parseProblems()
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(envName)
+	if err := validate.Struct(c); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return err
+		}
+		for _, fieldErr := range validationErrors {
+			problems = append(problems, describe(fieldErr))
+		}
+	}
+
+	// The readiness delay and the drain run one after the other under the hard cap
+	if c.ShutdownReadinessDelay+c.ShutdownHTTPDrainTimeout >= c.ShutdownHardTimeout {
+		problems = append(problems, "SHUTDOWN_READINESS_DELAY plus SHUTDOWN_HTTP_DRAIN_TIMEOUT must be less than SHUTDOWN_HARD_TIMEOUT")
+	}
+
+	// The public listener serves one certificate source
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSCertFile != "" && len(c.TLSAutocertDomains) > 0 {
+		problems = append(problems, "TLS_CERT_FILE cannot be used with TLS_AUTOCERT_DOMAINS")
+	}
+	if c.TLSRedirectHTTPServer != "" && !c.TLSEnabled() {
+		problems = append(problems, "TLS_REDIRECT_HTTP_SERVER requires TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS")
+	}
+	if c.TLSRedirectHTTPServer != "" && c.TLSRedirectHTTPServer == c.InternalHTTPServer {
+		problems = append(problems, "TLS_REDIRECT_HTTP_SERVER must differ from INTERNAL_HTTP_SERVER")
+	}
+
+	// A cluster only has the database 0
+	if c.RedisDB != 0 && c.RedisMode == constants.RedisModeCluster {
+		problems = append(problems, fmt.Sprintf("REDIS_DB must be 0 when REDIS_MODE is %s", c.RedisMode))
+	}
+	if c.RedisReadFromReplica && c.RedisMode == constants.RedisModeStandalone {
+		problems = append(problems, "REDIS_READ_FROM_REPLICA requires REDIS_MODE sentinel or cluster")
+	}
+	if !c.RedisTLS && (c.RedisTLSCAFile != "" || c.RedisTLSServerName != "") {
+		problems = append(problems, "REDIS_TLS_CA_FILE and REDIS_TLS_SERVER_NAME require REDIS_TLS")
+	}
+
+	// Demo mode wipes and reseeds the database, never allow it against production data
+	if c.DemoMode && c.AppEnv.IsProduction() {
+		problems = append(problems, fmt.Sprintf("DEMO_MODE cannot be enabled when APP_ENV is %s", c.AppEnv))
+	}
+
+	// Capturing the emails would silently stop the production ones
+	if c.EmailCapture && c.AppEnv.IsProduction() {
+		problems = append(problems, fmt.Sprintf("EMAIL_CAPTURE cannot be enabled when APP_ENV is %s", c.AppEnv))
+	}
+	if c.EmailRedirectTo != "" && c.AppEnv.IsProduction() {
+		problems = append(problems, fmt.Sprintf("EMAIL_REDIRECT_TO cannot be set when APP_ENV is %s", c.AppEnv))
+	}
+
+	// The local master key lives next to the data it protects, production needs a real KMS
+	if c.KMSProvider == constants.KMSProviderLocal && c.AppEnv.IsProduction() {
+		problems = append(problems, fmt.Sprintf("KMS_PROVIDER %s cannot be used when APP_ENV is %s", c.KMSProvider, c.AppEnv))
+	}
+
+	// The docs describe every route, production ones are never left open
+	if c.DocsAccess == constants.DocsAccessPublic && c.AppEnv.IsProduction() {
+		problems = append(problems, fmt.Sprintf("DOCS_ACCESS %s cannot be used when APP_ENV is %s", c.DocsAccess, c.AppEnv))
+	}
+	if c.DocsAccess == constants.DocsAccessBasic && (c.AppEnv == EnvironmentStaging || c.AppEnv.IsProduction()) && (c.BasicAuthUsername == "" || c.BasicAuthPassword == "") {
+		problems = append(problems, fmt.Sprintf("DOCS_ACCESS %s requires BASIC_AUTH_USER and BASIC_AUTH_SECRET", c.DocsAccess))
+	}
+
+	// The senders are single addresses, optionally named
+	for _, sender := range []struct{ name, address string }{{"EMAIL_FROM", c.EmailFrom}, {"SMTP_FROM", c.SMTPFrom}} {
+		if sender.address == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(sender.address); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be an email address, e.g. App <no-reply@example.com>, got %q", sender.name, sender.address))
+		}
+	}
+
+	// Every client names its service, once
+	clientIDs := make(map[string]bool, len(c.ServiceClients))
+	for i, entry := range c.ServiceClients {
+		clientID, service, found := strings.Cut(entry, constants.ServiceClientSeparator)
+		clientID, service = strings.TrimSpace(clientID), strings.TrimSpace(service)
+		switch {
+		case !found || clientID == "" || service == "":
+			problems = append(problems, fmt.Sprintf("SERVICE_CLIENTS[%d] must be client_id=service, got %q", i, entry))
+		case clientIDs[clientID]:
+			problems = append(problems, fmt.Sprintf("SERVICE_CLIENTS[%d] repeats the client %s", i, clientID))
+		}
+		clientIDs[clientID] = true
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// envName returns the environment variable of a config field
+func envName(field reflect.StructField) string {
+	if name := field.Tag.Get("env"); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// parseProblems reports the variables whose value is not of the type of their field; the
+// getEnvAs helpers fall back to the default on such values, which would hide the typo
+func parseProblems() []string {
+	var problems []string
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		name := field.Tag.Get("env")
+		value := os.Getenv(name)
+		if name == "" || value == "" {
+			continue
+		}
+
+		var err error
+		switch {
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			_, err = time.ParseDuration(value)
+		case field.Type.Kind() == reflect.Int:
+			_, err = strconv.Atoi(value)
+		case field.Type.Kind() == reflect.Bool:
+			_, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a valid %s", name, value, typeName(field.Type)))
+		}
+	}
+	return problems
+}
+
+func typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration, e.g. 30s or 5m"
+	}
+	if t.Kind() == reflect.Int {
+		return "integer"
+	}
+	return t.Kind().String()
+}
+
+// describe turns a validation error into a sentence naming the environment variable
+func describe(fieldErr validator.FieldError) string {
+	name := fieldErr.Field()
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return name + " is required"
+	case "required_if":
+		field, value, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("%s is required when %s is %s", name, fieldEnvName(field), value)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s, got %q", name, strings.ReplaceAll(param, " ", ", "), fieldErr.Value())
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", name, param)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s", name, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", name, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", name, param)
+	case "gtfield":
+		return fmt.Sprintf("%s must exceed %s", name, fieldEnvName(param))
+	case "gtefield":
+		return fmt.Sprintf("%s must be at least %s", name, fieldEnvName(param))
+	case "ltfield":
+		return fmt.Sprintf("%s must be less than %s", name, fieldEnvName(param))
+	case "ltefield":
+		return fmt.Sprintf("%s must be at most %s", name, fieldEnvName(param))
+	case "nefield":
+		return fmt.Sprintf("%s must differ from %s", name, fieldEnvName(param))
+	case "url":
+		return name + " must be an absolute URL"
+	case "hostname_port":
+		return name + " must be a listen address, e.g. :3000 or 0.0.0.0:3000"
+	case "fqdn":
+		return fmt.Sprintf("%s must be a domain name, got %q", name, fieldErr.Value())
+	case "email":
+		return name + " must be an email address"
+	case "cidr":
+		return fmt.Sprintf("%s must be a CIDR, got %q", name, fieldErr.Value())
+	case "timezone":
+		return fmt.Sprintf("%s must be an IANA time zone, e.g. Europe/Paris, got %q", name, fieldErr.Value())
+	case "numeric":
+		return name + " must be a number"
+	case "file":
+		return fmt.Sprintf("%s must be an existing file, got %q", name, fieldErr.Value())
+	case "base64":
+		return name + " must be base64 encoded"
+	default:
+		return fmt.Sprintf("%s failed the %s check", name, fieldErr.Tag())
+	}
+}
+
+// fieldEnvName returns the environment variable of the config field named in a validate tag
+func fieldEnvName(fieldName string) string {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(fieldName)
+	if !ok {
+		return fieldName
+	}
+	return envName(field)
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setRequiredEnv sets the variables without a default
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("POSTGRES_DB", "app")
+	t.Setenv("KEYCLOAK_URL", "https://sso.example.com")
+	t.Setenv("KEYCLOAK_REALM", "app")
+	t.Setenv("KEYCLOAK_CLIENT_ID", "api")
+	t.Setenv("KEYCLOAK_CLIENT_SECRET", "secret")
+	t.Setenv("KEY_CLAIMS", "user")
+	t.Setenv("GCS_BUCKET", "app-files")
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, EnvironmentDevelopment, cfg.AppEnv)
+	assert.Equal(t, "basic", cfg.DocsAccess)
+}
+
+func TestLoad_Validation(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		expectedProblems []string
+	}{
+		{
+			name: "required fields",
+			env:  map[string]string{"POSTGRES_DB": "", "KEYCLOAK_REALM": "", "GCS_BUCKET": ""},
+			expectedProblems: []string{
+				"POSTGRES_DB is required",
+				"KEYCLOAK_REALM is required when AUTH_PROVIDER is keycloak",
+				"GCS_BUCKET is required when STORAGE_PROVIDER is gcs",
+			},
+		},
+		{
+			name: "ranges and formats",
+			env: map[string]string{
+				"APP_ENV":                      "prod",
+				"KEYCLOAK_URL":                 "sso.example.com",
+				"EMAIL_BULK_RATE_PERCENT":      "150",
+				"EMAIL_FROM":                   "support",
+				"INTERNAL_ALLOWED_CIDRS":       "10.0.0.0/8,10.0.0.1",
+				"HEALTH_CRITICAL_DEPENDENCIES": "database,queue",
+			},
+			expectedProblems: []string{
+				`APP_ENV must be one of development, staging, production, test, got "prod"`,
+				"KEYCLOAK_URL must be an absolute URL",
+				"EMAIL_BULK_RATE_PERCENT must be at most 100",
+				`EMAIL_FROM must be an email address, e.g. App <no-reply@example.com>, got "support"`,
+				`INTERNAL_ALLOWED_CIDRS[1] must be a CIDR, got "10.0.0.1"`,
+				`HEALTH_CRITICAL_DEPENDENCIES[1] must be one of database, cache, auth, storage, email, payment, got "queue"`,
+			},
+		},
+		{
+			name: "rules across fields",
+			env: map[string]string{
+				"INTERNAL_HTTP_SERVER":     ":3000",
+				"JOBS_TIMEOUT":             "1h",
+				"WEBHOOK_TIMEOUT":          "2h",
+				"SHUTDOWN_READINESS_DELAY": "15s",
+			},
+			expectedProblems: []string{
+				"INTERNAL_HTTP_SERVER must differ from APP_HTTP_SERVER",
+				"JOBS_RESCUE_AFTER must exceed JOBS_TIMEOUT",
+				"WEBHOOK_TIMEOUT must be less than JOBS_TIMEOUT",
+				"SHUTDOWN_READINESS_DELAY plus SHUTDOWN_HTTP_DRAIN_TIMEOUT must be less than SHUTDOWN_HARD_TIMEOUT",
+			},
+		},
+		{
+			name: "values of the wrong type",
+			env:  map[string]string{"JOBS_CONCURRENCY": "ten", "JOBS_TIMEOUT": "5", "DEMO_MODE": "yes"},
+			expectedProblems: []string{
+				`JOBS_CONCURRENCY: "ten" is not a valid integer`,
+				`JOBS_TIMEOUT: "5" is not a valid duration, e.g. 30s or 5m`,
+				`DEMO_MODE: "yes" is not a valid bool`,
+			},
+		},
+		{
+			name:             "production only rules",
+			env:              map[string]string{"APP_ENV": "production", "DEMO_MODE": "true", "EMAIL_CAPTURE": "true", "EMAIL_REDIRECT_TO": "qa@example.com"},
+			expectedProblems: []string{"DEMO_MODE cannot be enabled when APP_ENV is production", "EMAIL_CAPTURE cannot be enabled when APP_ENV is production", "EMAIL_REDIRECT_TO cannot be set when APP_ENV is production"},
+		},
+		{
+			name:             "public docs in production",
+			env:              map[string]string{"APP_ENV": "production", "DOCS_ACCESS": "public"},
+			expectedProblems: []string{"DOCS_ACCESS public cannot be used when APP_ENV is production"},
+		},
+		{
+			name:             "docs basic auth without credentials",
+			env:              map[string]string{"APP_ENV": "staging", "DOCS_ACCESS": "basic"},
+			expectedProblems: []string{"DOCS_ACCESS basic requires BASIC_AUTH_USER and BASIC_AUTH_SECRET"},
+		},
+		{
+			name: "tls certificate sources",
+			env: map[string]string{
+				"TLS_KEY_FILE":         "validate.go",
+				"TLS_AUTOCERT_DOMAINS": "api.example.com,localhost:3000",
+			},
+			expectedProblems: []string{
+				"TLS_CERT_FILE and TLS_KEY_FILE must be set together",
+				`TLS_AUTOCERT_DOMAINS[1] must be a domain name, got "localhost:3000"`,
+			},
+		},
+		{
+			name:             "tls redirect without tls",
+			env:              map[string]string{"TLS_REDIRECT_HTTP_SERVER": ":80"},
+			expectedProblems: []string{"TLS_REDIRECT_HTTP_SERVER requires TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS"},
+		},
+		{
+			name:             "redis sentinel without sentinels",
+			env:              map[string]string{"REDIS_MODE": "sentinel", "REDIS_TLS_SERVER_NAME": "redis.example.com"},
+			expectedProblems: []string{"REDIS_ADDRS is required when REDIS_MODE is sentinel", "REDIS_SENTINEL_MASTER is required when REDIS_MODE is sentinel", "REDIS_TLS_CA_FILE and REDIS_TLS_SERVER_NAME require REDIS_TLS"},
+		},
+		{
+			name:             "redis cluster database",
+			env:              map[string]string{"REDIS_MODE": "cluster", "REDIS_ADDRS": "redis-0:6379,redis-1:6379", "REDIS_DB": "2"},
+			expectedProblems: []string{"REDIS_DB must be 0 when REDIS_MODE is cluster"},
+		},
+		{
+			name:             "redis replica reads of a standalone node",
+			env:              map[string]string{"REDIS_READ_FROM_REPLICA": "true"},
+			expectedProblems: []string{"REDIS_READ_FROM_REPLICA requires REDIS_MODE sentinel or cluster"},
+		},
+		{
+			name:             "docs access mode",
+			env:              map[string]string{"DOCS_ACCESS": "open", "DOCS_ROLES": ""},
+			expectedProblems: []string{`DOCS_ACCESS must be one of disabled, public, basic, role, got "open"`},
+		},
+		{
+			name: "service clients",
+			env:  map[string]string{"SERVICE_CLIENTS": "billing-worker=billing,reporting-job,billing-worker=invoicing,=audit"},
+			expectedProblems: []string{
+				`SERVICE_CLIENTS[1] must be client_id=service, got "reporting-job"`,
+				"SERVICE_CLIENTS[2] repeats the client billing-worker",
+				`SERVICE_CLIENTS[3] must be client_id=service, got "=audit"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+
+			assert.Nil(t, cfg)
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.ElementsMatch(t, tt.expectedProblems, validationErr.Problems)
+		})
+	}
+}
+
+func TestConfig_ServiceIdentities(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("SERVICE_CLIENTS", "billing-worker=billing, reporting-job = reporting")
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"billing-worker": "billing", "reporting-job": "reporting"}, cfg.ServiceIdentities())
+}
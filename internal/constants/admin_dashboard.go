@@ -0,0 +1,18 @@
+package constants
+
+import "time"
+
+// Admin dashboard settings
+const (
+	// AdminDashboardCacheKey is the cache key of the computed dashboard
+	AdminDashboardCacheKey = "admin:dashboard"
+	// AdminDashboardCacheTTL is how long the dashboard is served from the cache, so that
+	// admin frontends polling it do not run its queries and health checks every time
+	AdminDashboardCacheTTL = 30 * time.Second
+	// AdminDashboardRecentSignups is the number of recent signups listed
+	AdminDashboardRecentSignups = 10
+	// AdminDashboardFailingWebhooks is the number of failing webhook endpoints listed
+	AdminDashboardFailingWebhooks = 10
+	// AdminDashboardFailureWindow is how far back webhook delivery failures are counted
+	AdminDashboardFailureWindow = 24 * time.Hour
+)
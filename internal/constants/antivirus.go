@@ -0,0 +1,10 @@
+package constants
+
+// Protocols of the virus scanners
+const (
+	// VirusScanChunkSize is the size of the chunks of the contents streamed to the virus
+	// scanners
+	VirusScanChunkSize = 64 << 10
+	// ICAPPort is the port of an ICAP URL without one
+	ICAPPort = "1344"
+)
@@ -0,0 +1,33 @@
+package constants
+
+// API key settings
+const (
+	// HeaderAPIKey carries the API key of a machine client, instead of a bearer token
+	HeaderAPIKey = "X-API-Key"
+	// ContextKeyAPIKey stores the authenticated API key in the echo context
+	ContextKeyAPIKey = "api_key"
+	// APIKeyPrefix starts every key, so that leaked keys can be found by secret scanners
+	APIKeyPrefix = "gbk_"
+	// APIKeySandboxPrefix starts the keys of the sandbox tenants, so that clients can tell
+	// them apart from production keys
+	APIKeySandboxPrefix = APIKeyPrefix + "test_"
+	// APIKeySecretSize is the number of random bytes of a key
+	APIKeySecretSize = 32
+	// APIKeyDisplayLength is the number of leading characters of a key kept to identify it
+	APIKeyDisplayLength = 12
+	// APIKeyMaxExpiryDays bounds the expiry requested when creating a key
+	APIKeyMaxExpiryDays = 730
+)
+
+// API key usage reports
+const (
+	APIKeyUsageDefaultDays = 30
+	APIKeyUsageMaxDays     = 90
+)
+
+// API key statuses, derived from the revocation and expiry dates
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusExpired = "expired"
+	APIKeyStatusRevoked = "revoked"
+)
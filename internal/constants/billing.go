@@ -0,0 +1,44 @@
+package constants
+
+// Statuses of a subscription, those of the Stripe subscriptions. The subscriptions to a
+// free plan are always active.
+const (
+	SubscriptionStatusActive            = "active"
+	SubscriptionStatusTrialing          = "trialing"
+	SubscriptionStatusPastDue           = "past_due"
+	SubscriptionStatusIncomplete        = "incomplete"
+	SubscriptionStatusIncompleteExpired = "incomplete_expired"
+	SubscriptionStatusUnpaid            = "unpaid"
+	SubscriptionStatusPaused            = "paused"
+	SubscriptionStatusCanceled          = "canceled"
+)
+
+// SubscriptionEntitledStatuses are the statuses granting the entitlements of the plan;
+// the companies whose subscription is in another one get those of the default plan. A
+// past due subscription keeps them while the provider retries the payment.
+var SubscriptionEntitledStatuses = []string{
+	SubscriptionStatusActive,
+	SubscriptionStatusTrialing,
+	SubscriptionStatusPastDue,
+}
+
+// Entitlements granted by the plans
+const (
+	// EntitlementWebhooks grants the webhook endpoints, its limit caps their number
+	EntitlementWebhooks = "webhooks"
+)
+
+// Metadata of the provider subscriptions, linking them to their company and plan
+const (
+	BillingMetadataCompanyID = "company_id"
+	BillingMetadataPlan      = "plan"
+)
+
+// Consumer of the payment events syncing the subscriptions
+const (
+	// BillingQueue is the queue of the subscription events, shared by the workers
+	BillingQueue = "billing.subscriptions"
+	// BillingTopicStripeSubscriptions binds the created, updated and deleted Stripe
+	// subscriptions
+	BillingTopicStripeSubscriptions = MessagingTopicPaymentPrefix + PaymentProviderStripe + ".customer.subscription.*"
+)
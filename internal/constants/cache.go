@@ -0,0 +1,46 @@
+package constants
+
+import "time"
+
+// CacheFlushBatchSize is the number of keys scanned and removed per round trip when
+// flushing the cache
+const CacheFlushBatchSize = 500
+
+// Topologies of Redis, REDIS_MODE
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// CacheInvalidationChannel is the Redis channel on which the instances publish the keys
+// they change, so that the others drop them from their in-process cache
+const CacheInvalidationChannel = "cache:invalidations"
+
+// CacheInvalidateAll is published on CacheInvalidationChannel by a flush, which clears
+// the in-process caches
+const CacheInvalidateAll = "*"
+
+// Keys and TTL of the entities cached by the services, e.g. user:<id>. The services
+// invalidate them on their changes, the TTL bounds the staleness after the changes made
+// elsewhere.
+const (
+	UserCacheKeyPrefix    = "user:"
+	CompanyCacheKeyPrefix = "company:"
+	EntityCacheTTL        = time.Minute
+)
+
+// PermissionsCacheKeyPrefix namespaces the permissions of the database RBAC granted to a
+// user, cached by the Keycloak ID of the user for EntityCacheTTL
+const PermissionsCacheKeyPrefix = "permissions:"
+
+// IntrospectionCacheKeyPrefix namespaces the introspection results of the access tokens,
+// cached by the SHA-256 of the token
+const IntrospectionCacheKeyPrefix = "introspection:"
+
+// CacheTTLJitter lengthens the TTL of the values cached by cache.GetOrLoad by a random
+// fraction up to it, so that the keys cached together do not expire together
+const CacheTTLJitter = 0.1
+
+// LockKeyPrefix namespaces the keys of the locks of cache.Lock
+const LockKeyPrefix = "lock:"
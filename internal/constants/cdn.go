@@ -0,0 +1,34 @@
+package constants
+
+// Headers of the CDN surrogate keys, stripped by the CDN before the response reaches the
+// client: Fastly reads Surrogate-Key, space separated, and Cloudflare Cache-Tag, comma
+// separated
+const (
+	HeaderSurrogateKey = "Surrogate-Key"
+	HeaderCacheTag     = "Cache-Tag"
+	HeaderFastlyKey    = "Fastly-Key"
+)
+
+// CDNRoutePrefix prefixes the routes whose responses carry surrogate keys
+const CDNRoutePrefix = "/api/v1"
+
+// APIs of the CDN providers
+const (
+	FastlyAPIURL     = "https://api.fastly.com"
+	CloudflareAPIURL = "https://api.cloudflare.com/client/v4"
+)
+
+// Number of keys purged by a request, the limits of the provider APIs
+const (
+	FastlyPurgeBatchSize     = 256
+	CloudflarePurgeBatchSize = 30
+)
+
+// CDNEventResources maps the entity of a domain event, the prefix of its type, to the
+// route segment of its resource, e.g. the users of user.updated. The members of the
+// member events are users.
+var CDNEventResources = map[string]string{
+	"company": "companies",
+	"user":    "users",
+	"member":  "users",
+}
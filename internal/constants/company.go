@@ -0,0 +1,16 @@
+package constants
+
+// Company logo upload limits
+const (
+	// CompanyLogoMaxFileSize is the maximum accepted logo size in bytes (1 MiB)
+	CompanyLogoMaxFileSize = 1 << 20
+	// CompanyLogoKeyPrefix is the storage key prefix for logo objects
+	CompanyLogoKeyPrefix = "logos"
+)
+
+// CompanyLogoContentTypes lists the accepted logo content types and their file extensions
+var CompanyLogoContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
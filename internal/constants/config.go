@@ -0,0 +1,13 @@
+package constants
+
+// Sources of the config values
+const (
+	// ConfigSourceEnv is a variable of the process environment
+	ConfigSourceEnv = "env"
+	// ConfigSourceDotenv is a variable loaded from the .env file
+	ConfigSourceDotenv = "dotenv"
+	// ConfigSourceSecretsManager is a secret reference resolved from a secrets manager
+	ConfigSourceSecretsManager = "secrets_manager"
+	// ConfigSourceDefault is an unset variable, whose field has its default value
+	ConfigSourceDefault = "default"
+)
@@ -0,0 +1,11 @@
+package constants
+
+// Row level security of the tenants
+const (
+	// DatabaseTenantSetting is the Postgres session variable holding the company the
+	// database session runs as, read by the row level security policies
+	DatabaseTenantSetting = "app.tenant_id"
+	// CompanyRoutePrefix starts the routes of a company, whose :id parameter is the tenant
+	// of their database sessions
+	CompanyRoutePrefix = "/api/v1/companies/:id"
+)
@@ -0,0 +1,11 @@
+package constants
+
+// Demo mode
+const (
+	// DemoModeContextKey is the echo context key flagging a request served in demo mode
+	DemoModeContextKey = "demo_mode"
+	// DemoModeHeader is the response header announcing demo mode to clients
+	DemoModeHeader = "X-Demo-Mode"
+	// DemoAvatarSize is the width and height in pixels of generated demo avatars
+	DemoAvatarSize = 128
+)
@@ -0,0 +1,36 @@
+package constants
+
+// Deprecations declared in deprecation.Declared
+const (
+	// DeprecationHealthDatabase is GET /api/v1/health/database, replaced by the database
+	// entry of GET /api/v1/health/dependencies
+	DeprecationHealthDatabase = "health-database"
+	// DeprecationHealthAuth is GET /api/v1/health/auth, replaced by the auth entry of
+	// GET /api/v1/health/dependencies
+	DeprecationHealthAuth = "health-auth"
+)
+
+// Kinds of the deprecated surfaces
+const (
+	DeprecationKindRoute = "route"
+	DeprecationKindField = "field"
+)
+
+// DeprecationTag is the struct tag marking a deprecated request DTO field with the ID of
+// its deprecation, e.g. `deprecated:"user-phone"`
+const DeprecationTag = "deprecated"
+
+// Kinds of the consumers of the deprecated surfaces, prefixing their ID
+const (
+	DeprecationConsumerAPIKey    = "api_key"
+	DeprecationConsumerClient    = "client"
+	DeprecationConsumerUser      = "user"
+	DeprecationConsumerAnonymous = "anonymous"
+)
+
+// Headers of the responses of the deprecated surfaces
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
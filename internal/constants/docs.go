@@ -0,0 +1,10 @@
+package constants
+
+// Access to the Swagger UI, the specifications and the GraphQL playground, DOCS_ACCESS
+const (
+	DocsAccessDisabled = "disabled"
+	DocsAccessPublic   = "public"
+	DocsAccessBasic    = "basic"
+	// DocsAccessRole requires a token granted any of DOCS_ROLES
+	DocsAccessRole = "role"
+)
@@ -0,0 +1,88 @@
+package constants
+
+import "time"
+
+// Outbound email send limits
+const (
+	// SESSandboxSendRate is the SES sandbox send rate in recipients per second,
+	// used when the account quota cannot be discovered
+	SESSandboxSendRate = 1
+	// SESMaxBulkDestinations is the SES limit of destinations per SendBulkTemplatedEmail call
+	SESMaxBulkDestinations = 50
+	// EmailQuotaLookupTimeout bounds the provider quota lookup done at startup
+	EmailQuotaLookupTimeout = 5 * time.Second
+)
+
+// TLS of the SMTP connections, SMTP_TLS
+const (
+	// SMTPTLSStartTLS upgrades the connection with STARTTLS, refusing servers without it
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects over TLS, usually on port 465
+	SMTPTLSImplicit = "implicit"
+	// SMTPTLSNone sends in clear text, for a local MailHog
+	SMTPTLSNone = "none"
+)
+
+// Dev inbox of the captured emails
+const (
+	// DevInboxPreviewCSP is the Content-Security-Policy of the HTML previews of the dev
+	// inbox: the email is rendered sandboxed, without scripts, forms or remote content
+	// other than images
+	DevInboxPreviewCSP = "default-src 'none'; img-src data: https:; style-src 'unsafe-inline'; sandbox"
+)
+
+// Attachments of the outgoing emails
+const (
+	// SESMaxMessageSize is the SES limit of a raw message, its headers, bodies and
+	// base64 encoded attachments included
+	SESMaxMessageSize = 10 << 20
+	// EmailMaxAttachmentsSize bounds the total size of the attachments of an email, so that
+	// the message stays under the SES limit once base64 encoded
+	EmailMaxAttachmentsSize = 7 << 20
+	// EmailDefaultAttachmentType is the content type of the attachments without one whose
+	// extension is unknown
+	EmailDefaultAttachmentType = "application/octet-stream"
+)
+
+// EmailBlockedAttachmentExtensions are the executable and script attachments refused by
+// the mailbox providers, rejected before sending
+var EmailBlockedAttachmentExtensions = []string{
+	".bat", ".cmd", ".com", ".cpl", ".exe", ".hta", ".jar", ".js", ".jse", ".lnk", ".msi",
+	".msp", ".ps1", ".scr", ".sh", ".vbe", ".vbs", ".wsf",
+}
+
+// SES bounce and complaint notifications, published to an SNS topic subscribed to the
+// notifications endpoint
+const (
+	// SESNotificationsPath receives the messages of the SNS topic of the SES notifications
+	SESNotificationsPath = "/api/v1/email/ses-notifications"
+	// SESNotificationMaxBodySize bounds the body of an SNS message, in bytes
+	SESNotificationMaxBodySize = 256 << 10
+	// SNSCertificateTimeout bounds the download of the signing certificate of an SNS
+	// message and the confirmation of a subscription
+	SNSCertificateTimeout = 10 * time.Second
+	// SNSCertificateCacheSize bounds the signing certificates of SNS kept in memory, by
+	// normalized URL
+	SNSCertificateCacheSize = 16
+	// SNSMessageTypeNotification is the type of the SNS messages carrying a notification
+	SNSMessageTypeNotification = "Notification"
+	// SNSMessageTypeSubscriptionConfirmation is the type of the SNS message sent when the
+	// endpoint is subscribed to the topic, confirmed by visiting its SubscribeURL
+	SNSMessageTypeSubscriptionConfirmation = "SubscriptionConfirmation"
+	// SNSMessageTypeUnsubscribeConfirmation is the type of the SNS message sent when the
+	// endpoint is unsubscribed from the topic
+	SNSMessageTypeUnsubscribeConfirmation = "UnsubscribeConfirmation"
+	// SESNotificationTypeBounce and SESNotificationTypeComplaint are the SES notifications
+	// suppressing their recipients
+	SESNotificationTypeBounce    = "Bounce"
+	SESNotificationTypeComplaint = "Complaint"
+	// SESBounceTypePermanent is the type of the hard bounces; transient bounces, such as a
+	// full mailbox, do not suppress the recipient
+	SESBounceTypePermanent = "Permanent"
+)
+
+// Reasons of the suppressed email addresses
+const (
+	EmailSuppressionReasonBounce    = "bounce"
+	EmailSuppressionReasonComplaint = "complaint"
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Response envelopes. The v1 envelope wraps the data of the responses in {"meta", "data"};
+// the raw envelope returns the data alone, for integrations consuming the resources as is.
+const (
+	EnvelopeV1  = "v1"
+	EnvelopeRaw = "raw"
+	// HeaderEnvelope selects the envelope of a request, and tags the envelope used by its
+	// response
+	HeaderEnvelope = "X-Envelope"
+	// EnvelopeContextKey is the echo context key of the envelope of a request
+	EnvelopeContextKey = "envelope"
+)
+
+// Response headers carrying the pagination of the raw responses, which have no meta
+const (
+	HeaderTotalCount = "X-Total-Count"
+	HeaderPage       = "X-Page"
+	HeaderPageSize   = "X-Page-Size"
+)
@@ -23,4 +23,15 @@ const (
 	InternalError        = "INTERNAL_ERROR"
 	DatabaseError        = "DATABASE_ERROR"
 	ExternalServiceError = "EXTERNAL_SERVICE_ERROR"
+	ServiceUnavailable   = "SERVICE_UNAVAILABLE"
+
+	// FileInfected is returned for the uploads the virus scanner finds infected
+	FileInfected = "FILE_INFECTED"
+	// ChecksumMismatch is returned for the files whose content does not match their
+	// checksum, corrupted while they were transferred
+	ChecksumMismatch = "CHECKSUM_MISMATCH"
+
+	// External service errors classified from the upstream response
+	ExternalServiceUnavailable = "EXTERNAL_SERVICE_UNAVAILABLE"
+	ExternalServiceRateLimited = "EXTERNAL_SERVICE_RATE_LIMITED"
 )
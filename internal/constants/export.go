@@ -0,0 +1,48 @@
+package constants
+
+// Entities exported by the CSV and NDJSON streams of the listings
+const (
+	ExportEntityUsers          = "users"
+	ExportEntityCompanies      = "companies"
+	ExportEntityCompanyMembers = "company_members"
+)
+
+// Principals of an export
+const (
+	ExportPrincipalUser   = "user"
+	ExportPrincipalAPIKey = "api_key"
+)
+
+// ExportRoleAPIKey is the role of the API keys in the export policies
+const ExportRoleAPIKey = "api-key"
+
+// Statuses of an export audit record
+const (
+	// ExportStatusStarted is an export still streaming, or interrupted by a crash
+	ExportStatusStarted   = "started"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusDenied    = "denied"
+)
+
+// ExportPolicies declares the fields of each entity each role may export, named like the
+// JSON fields of the entity. Exports are stricter than reads: a role missing from the
+// policy of an entity cannot export it even if it can list it, and a principal with
+// several roles exports the fields of all of them. Every change here must be reviewed by
+// the compliance team.
+var ExportPolicies = map[string]map[string][]string{
+	ExportEntityUsers: {
+		RoleAdmin:       {"id", "email", "first_name", "last_name", "avatar_key", "companies", "created_at", "updated_at"},
+		RoleUserManager: {"id", "email", "first_name", "last_name", "companies", "created_at"},
+		RoleUserViewer:  {"id", "first_name", "last_name", "created_at"},
+	},
+	ExportEntityCompanies: {
+		RoleAdmin:          {"id", "name", "keycloak_id", "logo_key", "created_at", "updated_at"},
+		RoleCompanyManager: {"id", "name", "created_at"},
+	},
+	ExportEntityCompanyMembers: {
+		RoleAdmin:          {"id", "email", "first_name", "last_name", "avatar_key", "companies", "created_at", "updated_at"},
+		RoleCompanyManager: {"id", "email", "first_name", "last_name", "created_at"},
+		ExportRoleAPIKey:   {"id", "first_name", "last_name", "created_at"},
+	},
+}
@@ -0,0 +1,46 @@
+package constants
+
+// Statuses of the stored files. A file is registered pending before its object is
+// uploaded, scanned once uploaded, then available; only available files are linked to.
+const (
+	FileStatusPending     = "pending"
+	FileStatusScanning    = "scanning"
+	FileStatusAvailable   = "available"
+	FileStatusFailed      = "failed"
+	FileStatusQuarantined = "quarantined"
+)
+
+// FileStatusTransitions lists the statuses a file can move to from each status. Failed
+// and quarantined files stay so; an available file is quarantined when a later scan
+// finds it infected.
+var FileStatusTransitions = map[string][]string{
+	FileStatusPending:     {FileStatusScanning, FileStatusFailed},
+	FileStatusScanning:    {FileStatusAvailable, FileStatusFailed, FileStatusQuarantined},
+	FileStatusAvailable:   {FileStatusQuarantined},
+	FileStatusFailed:      {},
+	FileStatusQuarantined: {},
+}
+
+// FileDownloadRoles lists the roles that can download the files through the API, by the
+// first segment of their key. The files under FileOwnerPrefixes are downloaded by their
+// owner whatever its roles, and the files under the other prefixes by the admins only.
+var FileDownloadRoles = map[string][]string{
+	UserAvatarKeyPrefix:  UserViewRoles,
+	CompanyLogoKeyPrefix: CompanyViewRoles,
+	InvoiceKeyPrefix:     {RoleAdmin, RoleCompanyManager},
+}
+
+// FileCompanyPrefixes are the first segments of the keys of the files private to the
+// company owning them: besides the roles of FileDownloadRoles, the caller downloading one
+// must belong to that company, by the organization of its token, unless an admin
+var FileCompanyPrefixes = []string{InvoiceKeyPrefix}
+
+// FileOwnerPrefixes are the first segments of the keys of the files private to the user
+// owning them, e.g. the presigned uploads: only their owner, by the subject of its token,
+// and the admins download them
+var FileOwnerPrefixes = []string{UploadKeyPrefix}
+
+// FileReferencedPrefixes are the first segments of the keys of the files referenced by
+// the users, companies and invoices they belong to. An available file under them is in
+// use, and is deleted by replacing it rather than through the files API.
+var FileReferencedPrefixes = []string{UserAvatarKeyPrefix, CompanyLogoKeyPrefix, InvoiceKeyPrefix}
@@ -0,0 +1,14 @@
+package constants
+
+import "time"
+
+// GraphQL endpoint settings
+const (
+	// GraphQLComplexityLimit rejects queries whose complexity, one per selected field,
+	// exceeds this value
+	GraphQLComplexityLimit = 500
+	// GraphQLLoaderWait is how long a dataloader collects keys before fetching them
+	GraphQLLoaderWait = 2 * time.Millisecond
+	// GraphQLLoaderMaxBatch is the maximum number of keys fetched by one dataloader query
+	GraphQLLoaderMaxBatch = 100
+)
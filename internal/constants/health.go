@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// HealthCheckTimeout bounds each dependency check of the health registry, keep it below the
+// readiness probe timeout
+const HealthCheckTimeout = 2 * time.Second
+
+// Dependencies registered in the health registry
+const (
+	DependencyDatabase  = "database"
+	DependencyCache     = "cache"
+	DependencyAuth      = "auth"
+	DependencyStorage   = "storage"
+	DependencyEmail     = "email"
+	DependencyPayment   = "payment"
+	DependencyAntivirus = "antivirus"
+)
+
+// Probe paths, served at the root of the public listener
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// IntegrationHealthWindow is the window of the availability of the integrations reported
+// by the integration health dashboard
+const IntegrationHealthWindow = 24 * time.Hour
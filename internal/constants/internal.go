@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// InternalDefaultAllowedCIDRs are the loopback and private networks the internal listener
+// accepts by default, i.e. other pods and nodes of the cluster
+var InternalDefaultAllowedCIDRs = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// Internal listener routes
+const (
+	InternalAPIPrefix   = "/internal/v1"
+	InternalPprofPath   = "/debug/pprof"
+	InternalMetricsPath = "/metrics"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// InternalShutdownTimeout is how long the internal listener waits for its requests on
+// shutdown; a running CPU profile is cut short rather than delaying the public drain
+const InternalShutdownTimeout = 2 * time.Second
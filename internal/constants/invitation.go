@@ -0,0 +1,26 @@
+package constants
+
+// Invitation settings
+const (
+	// InvitationTokenAudience is the audience of the invitation tokens, so that no other
+	// token signed with the same key is accepted as an invitation
+	InvitationTokenAudience = "invitation"
+	// InvitationTokenParam is the query parameter of the accept URL carrying the token
+	InvitationTokenParam = "token"
+	// InvitationAcceptPath is appended to APP_BASE_URL when INVITATION_ACCEPT_URL is not set
+	InvitationAcceptPath = "/invitations/accept"
+)
+
+// Invitation statuses, derived from the acceptance, revocation and expiry dates
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Saga names of the invitation workflow
+const (
+	SagaCreateInvitation = "create_invitation"
+	SagaAcceptInvitation = "accept_invitation"
+)
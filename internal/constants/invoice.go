@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// Statuses of an invoice, those of the Stripe invoices. Only the invoices that are no
+// longer drafts have a PDF document.
+const (
+	InvoiceStatusDraft         = "draft"
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusUncollectible = "uncollectible"
+	InvoiceStatusVoid          = "void"
+)
+
+const (
+	// InvoiceKeyPrefix is the storage key prefix for the PDF documents of the invoices
+	InvoiceKeyPrefix = "invoices"
+	// InvoicePDFURLDuration is how long the presigned URL of a PDF document stays valid
+	InvoicePDFURLDuration = 15 * time.Minute
+)
+
+// Consumer of the payment events syncing the invoices
+const (
+	// InvoiceQueue is the queue of the invoice events, shared by the workers
+	InvoiceQueue = "invoicing.invoices"
+	// InvoiceTopicStripeInvoices binds the events of the Stripe invoices
+	InvoiceTopicStripeInvoices = MessagingTopicPaymentPrefix + PaymentProviderStripe + ".invoice.*"
+)
@@ -0,0 +1,100 @@
+package constants
+
+import "time"
+
+// Statuses of the jobs of the task queue
+const (
+	// JobStatusPending jobs wait for a worker, from their run_at time on
+	JobStatusPending = "pending"
+	// JobStatusRunning jobs are claimed by a worker
+	JobStatusRunning = "running"
+	// JobStatusCompleted jobs ran successfully
+	JobStatusCompleted = "completed"
+	// JobStatusDead jobs failed permanently or exhausted their attempts; they form the
+	// dead-letter queue and only run again when requeued
+	JobStatusDead = "dead"
+)
+
+// Task queue settings
+const (
+	// JobsRescueInterval is how often workers look for jobs left running by a crashed worker
+	JobsRescueInterval = time.Minute
+	// JobsMaxErrorLength truncates the error kept on a failed job
+	JobsMaxErrorLength = 2048
+)
+
+// SchedulerLockTTL is the TTL of the lock of an exclusive scheduler job, renewed while it
+// runs, so that the lock of a crashed instance frees within it
+const SchedulerLockTTL = 30 * time.Second
+
+// SchedulerLockKeyPrefix prefixes the job name in the lock of an exclusive scheduler job
+const SchedulerLockKeyPrefix = "scheduler:"
+
+// Run modes of the server binary, selected by its first argument
+const (
+	RunModeServer = "server"
+	RunModeWorker = "worker"
+	// RunModeRebuildProjections rebuilds the read models named by the next arguments, or
+	// all of them, and exits
+	RunModeRebuildProjections = "rebuild-projections"
+	// RunModeConfig runs the config command of the next argument, e.g. config check
+	RunModeConfig = "config"
+	// RunModeRoutes prints the routes of the listeners and exits, as JSON with
+	// RoutesFlagJSON
+	RunModeRoutes = "routes"
+	// RunModeGenerate runs the generate command of the next argument, e.g. generate
+	// resource invoice
+	RunModeGenerate = "generate"
+	// RunModeOpenAPI runs the openapi command of the next argument, e.g. openapi diff
+	RunModeOpenAPI = "openapi"
+	// RunModeCreateAdmin creates the initial admin user in Keycloak and the database and
+	// exits
+	RunModeCreateAdmin = "create-admin"
+)
+
+// Flags of the create-admin run mode
+const (
+	CreateAdminFlagEmail     = "--email="
+	CreateAdminFlagFirstName = "--first-name="
+	CreateAdminFlagLastName  = "--last-name="
+	// CreateAdminFlagPassword gives the password, read from CreateAdminPasswordEnv when
+	// omitted so that it stays out of the shell history
+	CreateAdminFlagPassword = "--password="
+	// CreateAdminFlagTemporaryPassword makes Keycloak ask for a new password at the first
+	// login
+	CreateAdminFlagTemporaryPassword = "--temporary-password"
+	CreateAdminPasswordEnv           = "ADMIN_PASSWORD"
+)
+
+// RoutesFlagJSON prints the routes as JSON, e.g. to generate gateway configs
+const RoutesFlagJSON = "--json"
+
+// Commands of the generate run mode
+const (
+	// GenerateCommandResource scaffolds the model, DTOs, repository, service, handler and
+	// routes of the entity named by the next argument
+	GenerateCommandResource = "resource"
+	// GenerateFlagPlural gives the plural of the entity when it is irregular, e.g.
+	// --plural=people
+	GenerateFlagPlural = "--plural="
+)
+
+// Commands of the openapi run mode
+const (
+	// OpenAPICommandExport writes the specification generated from the annotations to
+	// the file of OpenAPIFlagOutput, or to the standard output
+	OpenAPICommandExport = "export"
+	// OpenAPICommandDiff compares the generated specification with the committed one,
+	// lists their differences and fails when there are any
+	OpenAPICommandDiff = "diff"
+	// OpenAPIFlagOutput gives the file written by the export command, e.g.
+	// --output=openapi.json
+	OpenAPIFlagOutput = "--output="
+)
+
+// Commands of the config run mode
+const (
+	// ConfigCommandCheck validates the config loaded from the env files of the next
+	// arguments, or from .env, lists its problems and exits
+	ConfigCommandCheck = "check"
+)
@@ -0,0 +1,118 @@
+package constants
+
+import "time"
+
+// Keycloak endpoints discovery
+const (
+	// KeycloakDiscoveryPath is the OpenID discovery document, relative to the realm URL
+	KeycloakDiscoveryPath = ".well-known/openid-configuration"
+	// KeycloakRediscoveryInterval is the minimum time between two discoveries, so that a
+	// burst of 404s or health checks does not flood Keycloak
+	KeycloakRediscoveryInterval = time.Minute
+)
+
+// KeycloakContextPaths are the context paths the realms are looked up under, in order:
+// Keycloak 17+ serves them at the root, the legacy WildFly distribution under /auth
+var KeycloakContextPaths = []string{"", "/auth"}
+
+// KeycloakTenantSlugAttribute is the attribute of the Keycloak organizations of the
+// provisioned tenants holding their slug, by which they are found again
+const KeycloakTenantSlugAttribute = "tenant_slug"
+
+// Organization switch token exchange
+const (
+	// KeycloakTokenExchangeGrantType is the grant of the OAuth 2.0 token exchange (RFC 8693)
+	KeycloakTokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// KeycloakRefreshTokenType requests a refresh token along the access token, so that the
+	// session of the exchanged tokens can be refreshed
+	KeycloakRefreshTokenType = "urn:ietf:params:oauth:token-type:refresh_token"
+	// KeycloakOrganizationScope is the scope of the organization claim; organization:<alias>
+	// binds the token to that organization of the user
+	KeycloakOrganizationScope = "organization"
+)
+
+// Grants of the login and refresh endpoints
+const (
+	// KeycloakPasswordGrantType logs a user in with their credentials, through the direct
+	// access grants of the client
+	KeycloakPasswordGrantType = "password"
+	// KeycloakRefreshTokenGrantType refreshes the tokens of a session
+	KeycloakRefreshTokenGrantType = "refresh_token"
+)
+
+// KeycloakAdminTokenExpiryMargin is how long before its expiry a cached admin token is no
+// longer served, so that it does not expire on its way to Keycloak
+const KeycloakAdminTokenExpiryMargin = 5 * time.Second
+
+// Access token validation, KEYCLOAK_TOKEN_VALIDATION
+const (
+	// TokenValidationJWKS verifies the access tokens offline against the keys of the realm
+	TokenValidationJWKS = "jwks"
+	// TokenValidationIntrospection introspects every access token with Keycloak
+	TokenValidationIntrospection = "introspection"
+	// KeycloakJWKSMinRefreshInterval is the minimum time between two fetches of the keys of
+	// the realm, so that tokens signed with unknown keys do not flood Keycloak
+	KeycloakJWKSMinRefreshInterval = 10 * time.Second
+	// KeycloakTokenLeeway is the clock skew tolerated on the expiry of the access tokens
+	KeycloakTokenLeeway = 10 * time.Second
+	// KeycloakAccessTokenType is the typ claim of the Keycloak access tokens, which tells
+	// them from the ID tokens of the same client
+	KeycloakAccessTokenType = "Bearer"
+)
+
+// KeycloakTokenSigningMethods are the algorithms accepted on the access tokens
+var KeycloakTokenSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Multi-factor authentication with a one-time password
+const (
+	// KeycloakConfigureOTPAction is the required action asking the user to configure an
+	// OTP authenticator at their next login
+	KeycloakConfigureOTPAction = "CONFIGURE_TOTP"
+	// KeycloakOTPCredentialType is the type of the OTP credentials of the users
+	KeycloakOTPCredentialType = "otp"
+	// MFAMethodOTP is the amr claim of the tokens of a login with an OTP, added by the
+	// authentication method reference mapper of Keycloak
+	MFAMethodOTP = "otp"
+)
+
+// Actions of the Keycloak sync, counted in its report
+const (
+	KeycloakSyncUserCreated       = "user_created"
+	KeycloakSyncUserLinked        = "user_linked"
+	KeycloakSyncUserUpdated       = "user_updated"
+	KeycloakSyncUserDisabled      = "user_disabled"
+	KeycloakSyncUserEnabled       = "user_enabled"
+	KeycloakSyncCompanyCreated    = "company_created"
+	KeycloakSyncCompanyRenamed    = "company_renamed"
+	KeycloakSyncCompanyOrphaned   = "company_orphaned"
+	KeycloakSyncMembershipAdded   = "membership_added"
+	KeycloakSyncMembershipRemoved = "membership_removed"
+	KeycloakSyncRolesUpdated      = "roles_updated"
+	// KeycloakSyncUserSkipped is a Keycloak user without email, which has no local user
+	KeycloakSyncUserSkipped = "user_skipped"
+)
+
+// KeycloakSyncMaxReportedChanges bounds the changes listed in the report of a Keycloak
+// sync; the counts cover all of them
+const KeycloakSyncMaxReportedChanges = 500
+
+// Event webhook of the realm
+const (
+	// KeycloakEventsPath receives the admin and user events of the realm, posted as JSON by
+	// an event listener provider of Keycloak
+	KeycloakEventsPath = "/api/v1/keycloak/events"
+	// KeycloakEventSignatureHeader holds the hex HMAC-SHA256 of the body of an event, keyed
+	// with KEYCLOAK_EVENTS_SECRET, optionally prefixed with sha256=
+	KeycloakEventSignatureHeader = "X-Keycloak-Signature"
+	// KeycloakEventMaxBodySize bounds the body of an event, in bytes
+	KeycloakEventMaxBodySize = 1 << 20
+	// KeycloakEventResourceUser is the resource type of the admin events on the users
+	KeycloakEventResourceUser = "USER"
+	// KeycloakEventOperationCreate is the operation type of the admin events creating a
+	// resource
+	KeycloakEventOperationCreate = "CREATE"
+)
+
+// KeycloakUserEventTypes are the user events changing the email, names or existence of
+// the user, the ones the event webhook acts on
+var KeycloakUserEventTypes = []string{"UPDATE_EMAIL", "UPDATE_PROFILE", "DELETE_ACCOUNT"}
@@ -0,0 +1,4 @@
+package constants
+
+// LoadShedRetryAfter is the Retry-After, in seconds, of the requests shed under load
+const LoadShedRetryAfter = "1"
@@ -0,0 +1,54 @@
+package constants
+
+import "time"
+
+// Statuses of the maintenance tasks
+const (
+	// MaintenanceStatusRunning tasks have their batches run by the workers of the task queue
+	MaintenanceStatusRunning = "running"
+	// MaintenanceStatusPaused tasks stop after their current batch and keep their cursor
+	// until resumed
+	MaintenanceStatusPaused = "paused"
+	// MaintenanceStatusFailed tasks stopped on the error of a batch; resuming them runs the
+	// batch again
+	MaintenanceStatusFailed    = "failed"
+	MaintenanceStatusCompleted = "completed"
+	MaintenanceStatusCancelled = "cancelled"
+)
+
+// MaintenanceActiveStatuses are the statuses of the tasks that can still run; an operation
+// has at most one active task
+var MaintenanceActiveStatuses = []string{
+	MaintenanceStatusRunning,
+	MaintenanceStatusPaused,
+	MaintenanceStatusFailed,
+}
+
+// Maintenance operations
+const (
+	// MaintenanceOperationReindexSearch rebuilds the full-text search indexes
+	MaintenanceOperationReindexSearch = "reindex_search"
+	// MaintenanceOperationRebuildProjections rebuilds the read models
+	MaintenanceOperationRebuildProjections = "rebuild_projections"
+	// MaintenanceOperationReencryptCredentials seals the tenant credentials again under new
+	// data keys
+	MaintenanceOperationReencryptCredentials = "reencrypt_tenant_credentials"
+	// MaintenanceOperationReencryptWebhookSecrets seals the signing secrets of the webhook
+	// endpoints again under new data keys
+	MaintenanceOperationReencryptWebhookSecrets = "reencrypt_webhook_secrets"
+)
+
+// MaintenanceSearchIndexes are the full-text search indexes rebuilt by reindex_search
+var MaintenanceSearchIndexes = []string{"idx_users_search_vector"}
+
+// Maintenance task settings
+const (
+	MaintenanceDefaultBatchSize = 100
+	MaintenanceMaxBatchSize     = 1000
+	// MaintenanceMaxThrottle bounds the pause between two batches of a task
+	MaintenanceMaxThrottle = time.Minute
+	// MaintenanceSliceDuration is how long a job runs the batches of a task before handing
+	// over to a new job, so that a task never runs into JOBS_TIMEOUT and the workers stay
+	// available to the other jobs
+	MaintenanceSliceDuration = time.Minute
+)
@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// Message broker settings
+const (
+	// MessagingHeartbeat is the heartbeat interval negotiated with the broker
+	MessagingHeartbeat = 10 * time.Second
+	// MessagingCloseTimeout is how long the shutdown waits for the consumers to finish the
+	// messages they handle
+	MessagingCloseTimeout = 10 * time.Second
+	// MessagingReconnectDelay is the wait before a consumer subscribes again after losing
+	// its connection
+	MessagingReconnectDelay = 5 * time.Second
+)
+
+// Message topics published by the services
+const (
+	// MessagingTopicUserMembershipChanged is published for each company added to or
+	// removed from a user
+	MessagingTopicUserMembershipChanged = "user.membership.changed"
+	// MessagingTopicFileStatusChanged is published for each status change of a stored file
+	MessagingTopicFileStatusChanged = "file.status.changed"
+	// MessagingTopicPaymentPrefix prefixes the provider and lower case type of the verified
+	// events of the payment providers, e.g. payment.stripe.invoice.paid, so that services
+	// bind payment.# or the events they react to
+	MessagingTopicPaymentPrefix = "payment."
+)
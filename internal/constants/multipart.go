@@ -0,0 +1,5 @@
+package constants
+
+// MultipartPayloadField is the form field carrying the JSON document of a hybrid
+// multipart request that combines a JSON payload with file parts
+const MultipartPayloadField = "payload"
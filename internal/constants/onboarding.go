@@ -0,0 +1,25 @@
+package constants
+
+// Onboarding checklist steps of a tenant, in display order
+const (
+	OnboardingStepInviteUsers       = "invite_users"
+	OnboardingStepConfigureWebhooks = "configure_webhooks"
+	OnboardingStepAddPaymentMethod  = "add_payment_method"
+)
+
+// OnboardingSteps lists the onboarding steps in display order
+var OnboardingSteps = []string{
+	OnboardingStepInviteUsers,
+	OnboardingStepConfigureWebhooks,
+	OnboardingStepAddPaymentMethod,
+}
+
+// OnboardingMinMembers is the number of members from which a company has invited users,
+// its creator included
+const OnboardingMinMembers = 2
+
+// Sources of the state of an onboarding step
+const (
+	OnboardingSourceComputed = "computed"
+	OnboardingSourceManual   = "manual"
+)
@@ -8,4 +8,13 @@ const (
 	DefaultPage = 1
 	// DefaultPageSize is the default page size if not specified
 	DefaultPageSize = 10
+	// StreamBatchSize is the number of entities read per page when a listing is streamed
+	// as CSV or NDJSON
+	StreamBatchSize = 500
+)
+
+// External API pagination constants
+const (
+	// ExternalPageLimit is the default page size requested from paginated third-party APIs
+	ExternalPageLimit = 100
 )
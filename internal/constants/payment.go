@@ -0,0 +1,51 @@
+package constants
+
+import "time"
+
+// Webhook of the payment providers
+const (
+	// PaymentWebhookPath receives the events of Stripe and PayPal, told apart by their
+	// signature headers
+	PaymentWebhookPath = "/api/v1/webhooks/payments"
+	// PaymentWebhookMaxBodySize bounds the body of an event, in bytes
+	PaymentWebhookMaxBodySize = 1 << 20
+	// StripeSignatureHeader holds the timestamp and HMAC-SHA256 signatures of a Stripe
+	// event
+	StripeSignatureHeader = "Stripe-Signature"
+	// PayPal transmission headers: the signature of an event is verified with the
+	// certificate of PayPalCertURLHeader over its transmission ID and time, the webhook ID
+	// and the CRC32 of the body
+	PayPalTransmissionIDHeader   = "Paypal-Transmission-Id"
+	PayPalTransmissionTimeHeader = "Paypal-Transmission-Time"
+	PayPalTransmissionSigHeader  = "Paypal-Transmission-Sig"
+	PayPalCertURLHeader          = "Paypal-Cert-Url"
+	PayPalAuthAlgoHeader         = "Paypal-Auth-Algo"
+	// PayPalAuthAlgo is the only signature algorithm of the PayPal events
+	PayPalAuthAlgo = "SHA256withRSA"
+	// PayPalCertificateTimeout bounds the download of a PayPal signing certificate
+	PayPalCertificateTimeout = 10 * time.Second
+	// PayPalCertificateCacheSize bounds the PayPal signing certificates kept in memory, by
+	// normalized URL
+	PayPalCertificateCacheSize = 16
+	// PayPalTransmissionTolerance bounds the distance between the transmission time of a
+	// PayPal event and now, the tolerance Stripe applies to its signatures
+	PayPalTransmissionTolerance = 5 * time.Minute
+)
+
+// PayPalCertHosts are the hosts the PayPal signing certificates are downloaded from
+var PayPalCertHosts = []string{"api.paypal.com", "api-m.paypal.com", "api.sandbox.paypal.com", "api-m.sandbox.paypal.com"}
+
+// Operations on the payments, recorded with the user who initiated them
+const (
+	PaymentOperationRefund  = "refund"
+	PaymentOperationCapture = "capture"
+	PaymentOperationVoid    = "void"
+)
+
+// Statuses of the payment operations: started until the provider answers, then succeeded
+// or failed
+const (
+	PaymentOperationStatusStarted   = "started"
+	PaymentOperationStatusSucceeded = "succeeded"
+	PaymentOperationStatusFailed    = "failed"
+)
@@ -0,0 +1,24 @@
+package constants
+
+// Tenant performance reports
+const (
+	PerformanceDefaultHours = 24
+	PerformanceMaxHours     = 720
+	// PerformanceOtherRoute counts the requests of the routes of a tenant past
+	// PERFORMANCE_MAX_ROUTES, so that a scan of many routes cannot grow the metrics
+	PerformanceOtherRoute = "other"
+	// PerformanceOverflowBucket is the bucket of the requests slower than the last bound
+	// of PerformanceBucketsMs
+	PerformanceOverflowBucket = 0
+)
+
+// PerformanceBucketsMs are the upper bounds, in milliseconds, of the buckets of the
+// response time histograms
+var PerformanceBucketsMs = []int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Status classes of the requests in the performance metrics
+const (
+	PerformanceStatusSuccess     = "success"
+	PerformanceStatusClientError = "client_error"
+	PerformanceStatusServerError = "server_error"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Read models maintained by the projections, named after their table
+const (
+	ProjectionCompanySummaries = "company_summaries"
+	// ProjectionCDN purges the CDN cached responses of the changed entities; its rebuild
+	// purges the whole cache
+	ProjectionCDN = "cdn"
+)
+
+// Domain events applied to the read models that are not webhook events
+const (
+	EventCompanyCreated    = "company.created"
+	EventUserAvatarUpdated = "user.avatar_updated"
+)
@@ -0,0 +1,11 @@
+package constants
+
+// Tenant provisioning
+const (
+	// TenantSlugPattern is the format of the slugs of the provisioned tenants, which are
+	// also the aliases of their Keycloak organizations
+	TenantSlugPattern = `^[a-z0-9][a-z0-9-]{1,62}$`
+	// TenantStoragePrefix is the parent of the default storage prefixes of the tenants,
+	// tenants/{slug}
+	TenantStoragePrefix = "tenants"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// Names of the permissions of the database RBAC, <resource>:<action>. A permission whose
+// action is PermissionWildcard grants every action of its resource, PermissionWildcard
+// alone grants every permission.
+const (
+	PermissionSeparator = ":"
+	PermissionWildcard  = "*"
+)
@@ -0,0 +1,49 @@
+package constants
+
+import "time"
+
+// Realtime event types pushed to the connected clients
+const (
+	RealtimeEventUserUpdated = "user.updated"
+)
+
+// Realtime routes, whose connections stay open
+const (
+	RealtimeWebSocketPath = "/api/v1/realtime/ws"
+	RealtimeStreamPath    = "/api/v1/events/stream"
+)
+
+// WebSocket hub settings
+const (
+	// RealtimeTokenProtocol is the WebSocket subprotocol browsers send before their access
+	// token, as they cannot set the Authorization header: `bearer, <token>`
+	RealtimeTokenProtocol = "bearer"
+	// RealtimeSendBuffer is the number of events queued per connection; a connection
+	// falling further behind is closed
+	RealtimeSendBuffer = 64
+	// RealtimeWriteWait is the time allowed to write a message to a connection
+	RealtimeWriteWait = 10 * time.Second
+	// RealtimePongWait is the time allowed to read the pong answering a ping
+	RealtimePongWait = 60 * time.Second
+	// RealtimePingPeriod is how often connections are pinged, shorter than RealtimePongWait
+	RealtimePingPeriod = RealtimePongWait * 9 / 10
+	// RealtimeMaxMessageSize is the largest message accepted from a client
+	RealtimeMaxMessageSize = 4096
+	// RealtimeCloseTimeout is how long the shutdown waits for connections to close
+	RealtimeCloseTimeout = 5 * time.Second
+)
+
+// Server-sent events settings
+const (
+	// RealtimeHistorySize is the number of recent events kept per user to resume streams
+	RealtimeHistorySize = 100
+	// RealtimeHistoryTTL is how long events are kept to resume streams
+	RealtimeHistoryTTL = 5 * time.Minute
+	// RealtimeHeartbeatInterval is how often a comment is sent on idle streams, so that
+	// proxies do not close them
+	RealtimeHeartbeatInterval = 15 * time.Second
+	// HeaderLastEventID is sent by reconnecting clients with the ID of the last event received
+	HeaderLastEventID = "Last-Event-ID"
+	// RealtimeStreamRetry is the reconnection delay suggested to clients, in milliseconds
+	RealtimeStreamRetry = 3000
+)
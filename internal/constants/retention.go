@@ -0,0 +1,22 @@
+package constants
+
+// Resources purged by the retention policies of the tenants
+const (
+	// RetentionResourceAPIKeyUsage is the daily usage of the API keys, purged by day
+	RetentionResourceAPIKeyUsage = "api_key_usage"
+	// RetentionResourceRevokedAPIKeys are the API keys revoked or expired, purged with
+	// their usage from their revocation or expiry
+	RetentionResourceRevokedAPIKeys = "revoked_api_keys"
+)
+
+// RetentionResources lists the resources a tenant can set a retention policy for
+var RetentionResources = []string{
+	RetentionResourceAPIKeyUsage,
+	RetentionResourceRevokedAPIKeys,
+}
+
+// Bounds of the retention period of a policy, in days
+const (
+	RetentionMinDays = 1
+	RetentionMaxDays = 3650
+)
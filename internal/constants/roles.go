@@ -25,6 +25,9 @@ const (
 	RoleCompanyCreator = "company-creator" // Can create companies
 	RoleCompanyEditor  = "company-editor"  // Can edit companies
 	RoleCompanyDeleter = "company-deleter" // Can delete companies
+
+	// Provisioning roles
+	RoleTenantProvisioner = "tenant-provisioner" // Can provision tenants, e.g. the provisioning pipeline
 )
 
 // RoleGroups groups related roles for easier middleware usage
@@ -0,0 +1,18 @@
+package constants
+
+// Listeners of the route catalog
+const (
+	ListenerPublic   = "public"
+	ListenerInternal = "internal"
+)
+
+// Authentication schemes of the route catalog
+const (
+	RouteSchemeBearer = "bearer"
+	RouteSchemeAPIKey = "api_key"
+	RouteSchemeBasic  = "basic"
+	// RouteSchemeSignature admits the requests whose body is signed with a shared secret
+	RouteSchemeSignature = "signature"
+	// RouteSchemeInternalNetwork admits the requests from INTERNAL_ALLOWED_CIDRS
+	RouteSchemeInternalNetwork = "internal_network"
+)
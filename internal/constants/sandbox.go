@@ -0,0 +1,16 @@
+package constants
+
+// Sandbox tenants of the API consumers
+const (
+	// SandboxCompanyNameSuffix is appended to the name of a company to name its sandbox
+	SandboxCompanyNameSuffix = " (Sandbox)"
+	// SandboxHeader flags the responses of the requests made with a sandbox API key
+	SandboxHeader = "X-Sandbox"
+	// SandboxProvider is the provider reported by the fake adapters of the sandbox
+	SandboxProvider = "sandbox"
+	// SandboxEmailStatusCaptured is the status of an email captured instead of being sent
+	SandboxEmailStatusCaptured = "captured"
+	// SandboxStripeIDPrefix starts the IDs of the fake Stripe objects of the sandbox, like
+	// the test mode objects of Stripe
+	SandboxStripeIDPrefix = "sandbox_"
+)
@@ -0,0 +1,68 @@
+package constants
+
+// SCIM 2.0 provisioning (RFC 7643, RFC 7644)
+const (
+	// SCIMBasePath is the base URL of the SCIM endpoints, served at the root of the public
+	// listener like the identity providers expect
+	SCIMBasePath = "/scim/v2"
+	// SCIMContentType is the media type of the SCIM requests and responses
+	SCIMContentType = "application/scim+json"
+	// SCIMDefaultCount is the page size of a listing without count
+	SCIMDefaultCount = 100
+	// SCIMMaxCount bounds the page size of a listing
+	SCIMMaxCount = 1000
+	// SCIMTypeContextKey holds the SCIM error type in the context of the errors, so that
+	// the handler renders it in the scimType of the response
+	SCIMTypeContextKey = "scim_type"
+	// SagaSCIMCreateUser creates the Keycloak user and the user of a SCIM request
+	SagaSCIMCreateUser = "scim_create_user"
+)
+
+// SCIM schemas
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// SCIM resource types
+const (
+	SCIMResourceUser  = "User"
+	SCIMResourceGroup = "Group"
+)
+
+// SCIM error types of the 400 responses (RFC 7644 section 3.12)
+const (
+	SCIMErrorInvalidFilter = "invalidFilter"
+	SCIMErrorInvalidSyntax = "invalidSyntax"
+	SCIMErrorInvalidPath   = "invalidPath"
+	SCIMErrorInvalidValue  = "invalidValue"
+	SCIMErrorUniqueness    = "uniqueness"
+	SCIMErrorMutability    = "mutability"
+	SCIMErrorNoTarget      = "noTarget"
+)
+
+// SCIM PATCH operations
+const (
+	SCIMPatchAdd     = "add"
+	SCIMPatchRemove  = "remove"
+	SCIMPatchReplace = "replace"
+)
+
+// SCIMUserFilterColumns are the columns of the user attributes a listing can be filtered
+// on, by their lowercase SCIM name; userName is the email
+var SCIMUserFilterColumns = map[string]string{
+	"username":     "email",
+	"emails.value": "email",
+	"externalid":   "external_id",
+}
+
+// SCIMGroupFilterColumns are the columns of the group attributes a listing can be filtered
+// on, by their lowercase SCIM name
+var SCIMGroupFilterColumns = map[string]string{
+	"displayname": "name",
+}
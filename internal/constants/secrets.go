@@ -0,0 +1,14 @@
+package constants
+
+import "time"
+
+// Secret reference schemes of the config values resolved from a secrets manager, e.g.
+// vault://secret/data/app#db_password
+const (
+	SecretSchemeVault = "vault"
+	SecretSchemeAWS   = "awssm"
+	SecretSchemeGCP   = "gcpsm"
+)
+
+// SecretsDefaultTimeout bounds the resolution of all the secret references at startup
+const SecretsDefaultTimeout = 30 * time.Second
@@ -0,0 +1,13 @@
+package constants
+
+// Service-to-service authentication, by the client credentials grant
+const (
+	// ContextKeyService stores the authenticated service identity in the echo context
+	ContextKeyService = "service"
+	// KeycloakServiceAccountPrefix starts the username of the service account of a client,
+	// the user the client credentials grant issues its tokens for
+	KeycloakServiceAccountPrefix = "service-account-"
+	// ServiceClientSeparator separates a client ID from its service in SERVICE_CLIENTS,
+	// e.g. billing-worker=billing
+	ServiceClientSeparator = "="
+)
@@ -0,0 +1,12 @@
+package constants
+
+import "time"
+
+const (
+	// ShutdownFlushTimeout is how long the shutdown waits for each buffered recorder to
+	// write its pending records
+	ShutdownFlushTimeout = 5 * time.Second
+	// ShutdownTelemetryTimeout is how long the shutdown waits for Sentry and New Relic to
+	// send their pending data
+	ShutdownTelemetryTimeout = 3 * time.Second
+)
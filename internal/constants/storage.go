@@ -0,0 +1,27 @@
+package constants
+
+// Batch deletes of the storage adapters
+const (
+	// StorageDeleteBatchSize is the number of keys of an S3 DeleteObjects request, the
+	// most S3 accepts
+	StorageDeleteBatchSize = 1000
+	// StorageDeleteConcurrency bounds the objects deleted at once from GCS, which has no
+	// batch delete
+	StorageDeleteConcurrency = 16
+)
+
+// StorageChecksumMetadataKey is the metadata of the stored objects holding the hex SHA-256
+// of their content, recorded when they are uploaded
+const StorageChecksumMetadataKey = "sha256"
+
+// StorageUploadConcurrency is the default bound of the files uploaded at once by
+// UploadFiles
+const StorageUploadConcurrency = 8
+
+// Pages of the stored objects listed by the storage adapters
+const (
+	// StorageListDefaultLimit is the size of a page of objects when none is given
+	StorageListDefaultLimit = 100
+	// StorageListMaxLimit is the largest page of objects, the most S3 returns at once
+	StorageListMaxLimit = 1000
+)
@@ -1,10 +1,20 @@
 package constants
 
 const (
-	CacheProviderRedis    = "redis"
-	AuthProviderKeycloak  = "keycloak"
-	EmailProviderSES      = "ses"
-	StorageProviderGCS    = "gcs"
-	StorageProviderS3     = "s3"
-	PaymentProviderStripe = "stripe"
+	CacheProviderRedis         = "redis"
+	AuthProviderKeycloak       = "keycloak"
+	EmailProviderSES           = "ses"
+	EmailProviderSMTP          = "smtp"
+	EmailProviderCapture       = "capture"
+	StorageProviderGCS         = "gcs"
+	StorageProviderS3          = "s3"
+	PaymentProviderStripe      = "stripe"
+	PaymentProviderPayPal      = "paypal"
+	KMSProviderAWS             = "aws"
+	KMSProviderLocal           = "local"
+	MessagingProviderRabbitMQ  = "rabbitmq"
+	CDNProviderFastly          = "fastly"
+	CDNProviderCloudflare      = "cloudflare"
+	VirusScannerProviderClamAV = "clamav"
+	VirusScannerProviderICAP   = "icap"
 )
@@ -0,0 +1,25 @@
+package constants
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// TLSRedirectShutdownTimeout is how long the HTTP to HTTPS redirect listener waits for its
+// requests on shutdown
+const TLSRedirectShutdownTimeout = 2 * time.Second
+
+// TLSCipherSuites are the TLS 1.2 cipher suites accepted by the public listener: forward
+// secret AEAD suites only. The TLS 1.3 suites are all modern and not configurable.
+var TLSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// TLSCurvePreferences are the key exchanges offered by the public listener, the post-quantum
+// hybrid first
+var TLSCurvePreferences = []tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256}
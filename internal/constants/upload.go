@@ -0,0 +1,23 @@
+package constants
+
+import "time"
+
+// Bounds of the upload policy of a tenant
+const (
+	// UploadPolicyMaxEntries is the maximum number of content types or extensions listed
+	UploadPolicyMaxEntries = 100
+	// UploadPolicyMaxSizeBytes is the largest size limit a policy can set (5 GiB); the
+	// limits of each upload still apply
+	UploadPolicyMaxSizeBytes = 5 << 30
+)
+
+// Presigned uploads, posted by the clients straight to the storage
+const (
+	// UploadKeyPrefix is the storage key prefix for the objects uploaded by the clients
+	UploadKeyPrefix = "uploads"
+	// PresignedUploadDuration is how long a presigned upload can be posted
+	PresignedUploadDuration = 15 * time.Minute
+	// PresignedUploadMaxSizeBytes is the largest presigned upload (5 GiB), the largest
+	// object S3 accepts in a single request
+	PresignedUploadMaxSizeBytes = 5 << 30
+)
@@ -1,8 +1,54 @@
 package constants
 
+import "time"
+
 type UserStatus string
 
 const (
 	UserStatusActive   UserStatus = "active"
 	UserStatusInactive UserStatus = "inactive"
 )
+
+// Changes of the membership of a user in a company
+const (
+	MembershipChangeAdded   = "added"
+	MembershipChangeRemoved = "removed"
+)
+
+// User CSV import limits
+const (
+	// UserImportBatchSize is the number of rows inserted per transaction
+	UserImportBatchSize = 100
+	// UserImportMaxRows is the maximum number of data rows accepted in one file
+	UserImportMaxRows = 10000
+	// UserImportMaxFileSize is the maximum accepted upload size in bytes (5 MiB)
+	UserImportMaxFileSize = 5 << 20
+)
+
+// User avatar upload limits
+const (
+	// UserAvatarMaxFileSize is the maximum accepted avatar size in bytes (2 MiB)
+	UserAvatarMaxFileSize = 2 << 20
+	// UserAvatarKeyPrefix is the storage key prefix for avatar objects
+	UserAvatarKeyPrefix = "avatars"
+	// UserAvatarURLDuration is how long the presigned avatar URL stays valid
+	UserAvatarURLDuration = time.Hour
+)
+
+// UserAvatarContentTypes lists the accepted avatar content types and their file extensions
+var UserAvatarContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// User full-text search settings
+const (
+	// UserSearchConfig is the Postgres text search configuration of the users search vector
+	UserSearchConfig = "simple"
+	// UserSearchMaxTerms is the maximum number of words of a search query
+	UserSearchMaxTerms = 8
+	// UserSearchHeadlineOptions are the ts_headline options used to highlight matches
+	UserSearchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+)
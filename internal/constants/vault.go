@@ -0,0 +1,28 @@
+package constants
+
+// Credentials vault settings
+const (
+	// VaultDataKeySize is the size in bytes of the AES-256 data and master keys
+	VaultDataKeySize = 32
+	// VaultMaskVisibleChars is the number of trailing characters left visible by masked reads
+	VaultMaskVisibleChars = 4
+	// VaultMaskMinLength is the shortest secret value that keeps its trailing characters visible
+	VaultMaskMinLength = 12
+	// VaultMask replaces the hidden part of a masked secret value
+	VaultMask = "****"
+)
+
+// Tenant credential providers
+const (
+	TenantCredentialProviderSMTP = "smtp"
+	TenantCredentialProviderS3   = "s3"
+
+	// TenantCredentialDefaultName is the credential name used by the tenant integration instances
+	TenantCredentialDefaultName = "default"
+)
+
+// TenantCredentialFields lists the secret fields each tenant credential provider requires
+var TenantCredentialFields = map[string][]string{
+	TenantCredentialProviderSMTP: {"host", "port", "username", "password"},
+	TenantCredentialProviderS3:   {"region", "bucket", "access_key", "secret_key"},
+}
@@ -0,0 +1,72 @@
+package constants
+
+import "time"
+
+// Webhook event types delivered to the endpoints of the companies
+const (
+	WebhookEventCompanyUpdated = "company.updated"
+	WebhookEventCompanyDeleted = "company.deleted"
+	WebhookEventUserCreated    = "user.created"
+	WebhookEventUserUpdated    = "user.updated"
+	WebhookEventUserDeleted    = "user.deleted"
+	WebhookEventMemberAdded    = "member.added"
+	WebhookEventMemberRemoved  = "member.removed"
+)
+
+// WebhookEventTest is the type of the test events, only sent on request to a single
+// endpoint whatever its event types, so it cannot be subscribed to
+const WebhookEventTest = "webhook.test"
+
+// WebhookEventTypes lists the event types an endpoint can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventCompanyUpdated,
+	WebhookEventCompanyDeleted,
+	WebhookEventUserCreated,
+	WebhookEventUserUpdated,
+	WebhookEventUserDeleted,
+	WebhookEventMemberAdded,
+	WebhookEventMemberRemoved,
+}
+
+// Statuses of the webhook deliveries
+const (
+	// WebhookDeliveryPending deliveries wait for their first or next attempt
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliverySucceeded deliveries were answered with a 2xx status
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryFailed deliveries exhausted their attempts or lost their endpoint;
+	// they are only sent again when redelivered
+	WebhookDeliveryFailed = "failed"
+)
+
+// Webhook request headers
+const (
+	// HeaderWebhookID is the ID of the delivery, new for every redelivery
+	HeaderWebhookID = "X-Webhook-ID"
+	// HeaderWebhookEvent is the type of the delivered event
+	HeaderWebhookEvent = "X-Webhook-Event"
+	// HeaderWebhookTimestamp is the Unix time the request was signed at
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	// HeaderWebhookSignature is `v1=<hex HMAC-SHA256 of "<timestamp>.<body>">`
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// Webhook settings
+const (
+	// WebhookSecretPrefix starts every signing secret, so that leaked secrets can be found
+	// by secret scanners
+	WebhookSecretPrefix = "whsec_"
+	// WebhookSecretSize is the number of random bytes of a signing secret
+	WebhookSecretSize = 32
+	// WebhookSignatureVersion prefixes the signatures, so that the scheme can evolve
+	WebhookSignatureVersion = "v1"
+	// WebhookUserAgent identifies the webhook requests
+	WebhookUserAgent = "golang-boilerplate-webhooks/1.0"
+	// WebhookMaxResponseLength truncates the response body kept on a delivery
+	WebhookMaxResponseLength = 2048
+	// WebhookMaxRateLimit bounds the deliveries per minute an endpoint can be limited to
+	WebhookMaxRateLimit = 6000
+	// WebhookRateLimitDelay is the wait before a delivery over the rate limit of its
+	// endpoint is tried again, without counting an attempt
+	WebhookRateLimitDelay = 5 * time.Second
+)
@@ -9,6 +9,7 @@ import (
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/retry"
 
 	"github.com/getsentry/sentry-go"
 	"go.uber.org/zap"
@@ -58,7 +59,7 @@ func NewDatabaseManager(cfg *config.Config) (*DatabaseManager, error) {
 	}
 
 	// Initialize database connection with retry logic
-	if err := manager.connectWithRetry(); err != nil {
+	if err := manager.connectWithRetry(context.Background()); err != nil {
 		return nil, errors.DatabaseError("Failed to initialize database manager", err).
 			WithOperation("initialize_database_manager").
 			WithResource("database")
@@ -73,21 +74,27 @@ func NewDatabaseManager(cfg *config.Config) (*DatabaseManager, error) {
 	return manager, nil
 }
 
-// connectWithRetry attempts to connect to the database with retry logic
-func (dm *DatabaseManager) connectWithRetry() error {
-	var lastErr error
+// connectWithRetry attempts to connect to the database, backing off exponentially with
+// jitter between attempts until the retry attempts or elapsed time are exhausted
+func (dm *DatabaseManager) connectWithRetry(ctx context.Context) error {
+	backoff := retry.Backoff{
+		InitialInterval: dm.config.DatabaseRetryDelay,
+		MaxInterval:     dm.config.DatabaseRetryMaxDelay,
+		Jitter:          retry.DefaultJitter,
+		MaxAttempts:     dm.config.DatabaseRetryAttempts,
+		MaxElapsedTime:  dm.config.DatabaseRetryMaxElapsed,
+	}
 
-	for attempt := 1; attempt <= dm.config.DatabaseRetryAttempts; attempt++ {
+	err := retry.Do(ctx, "connect_database", backoff, func(ctx context.Context, attempt int) error {
 		logger.Log.Info("Attempting to connect to database",
 			zap.Int("attempt", attempt),
 			zap.Int("max_attempts", dm.config.DatabaseRetryAttempts),
 		)
 
 		if err := dm.connect(); err != nil {
-			lastErr = err
 			dm.healthStatus.RetryCount = attempt
 
-			if hub := sentry.GetHubFromContext(context.Background()); hub != nil {
+			if hub := sentry.GetHubFromContext(ctx); hub != nil {
 				hub.WithScope(func(scope *sentry.Scope) {
 					scope.SetTag("operation", "database_connection")
 					scope.SetTag("attempt", fmt.Sprintf("%d", attempt))
@@ -100,22 +107,22 @@ func (dm *DatabaseManager) connectWithRetry() error {
 				zap.Int("attempt", attempt),
 				zap.Error(err),
 			)
-
-			if attempt < dm.config.DatabaseRetryAttempts {
-				time.Sleep(dm.config.DatabaseRetryDelay)
-			}
-		} else {
-			dm.healthStatus.IsHealthy = true
-			dm.healthStatus.RetryCount = 0
-			logger.Log.Info("Database connection established successfully")
-			return nil
+			return err
 		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to connect to database after retries", err).
+			WithOperation("connect_database").
+			WithResource("database").
+			WithContext("retry_attempts", dm.healthStatus.RetryCount)
 	}
 
-	return errors.DatabaseError("Failed to connect to database after retries", lastErr).
-		WithOperation("connect_database").
-		WithResource("database").
-		WithContext("retry_attempts", dm.config.DatabaseRetryAttempts)
+	dm.healthStatus.IsHealthy = true
+	dm.healthStatus.RetryCount = 0
+	logger.Log.Info("Database connection established successfully")
+	return nil
 }
 
 // connect establishes the actual database connection
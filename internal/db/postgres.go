@@ -1,7 +1,13 @@
 package db
 
 import (
+	"context"
+	stderrors "errors"
+
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/monitoring"
 
 	_ "github.com/lib/pq" // PostgreSQL driver for database/sql
 	"gorm.io/gorm"
@@ -49,6 +55,21 @@ func (r *PostgresDB) FastHealthCheck() HealthStatus {
 	return HealthStatus{IsHealthy: false}
 }
 
+// ProvideHealthChecker registers the database in the health registry, reading the status
+// of the periodic health checks rather than pinging on every call
+func ProvideHealthChecker(db *PostgresDB) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:     constants.DependencyDatabase,
+		Critical: true,
+		Check: func(context.Context) error {
+			if status := db.FastHealthCheck(); !status.IsHealthy {
+				return errors.DatabaseError("Database is unhealthy", stderrors.New(status.LastError))
+			}
+			return nil
+		},
+	}
+}
+
 // GetMetrics returns the current connection metrics
 func (r *PostgresDB) GetMetrics() ConnectionMetrics {
 	if r.manager != nil {
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	"gorm.io/gorm"
+)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose database sessions run as the company tenantID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantID returns the tenant set by WithTenant, if any
+func TenantID(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// TenantTransaction runs fn in a transaction whose constants.DatabaseTenantSetting
+// variable is the tenant of ctx, so that the row level security policies only let it
+// reach the rows of that company. The variable is local to the transaction: it never
+// outlives it on the pooled connection. When DATABASE_RLS_ENABLED is not set or ctx has
+// no tenant, e.g. in the workers, fn runs on the connection pool without a transaction.
+func (r *PostgresDB) TenantTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tenantID, ok := TenantID(ctx)
+	if !ok || r.manager == nil || !r.manager.config.DatabaseRLSEnabled {
+		return fn(r.DB.WithContext(ctx))
+	}
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config(?, ?, true)", constants.DatabaseTenantSetting, tenantID).Error; err != nil {
+			return errors.DatabaseError("Failed to set database tenant", err).
+				WithOperation("set_database_tenant").
+				WithResource("database").
+				WithContext("tenant_id", tenantID)
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,111 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"gorm.io/gorm"
+)
+
+// TestPostgresDB_TenantTransaction checks that a row level security policy reading the
+// tenant of TenantTransaction only lets a session reach the rows of its tenant, and that
+// the tenant does not outlive the transaction on the pooled connection.
+func TestPostgresDB_TenantTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	container, err := tcpostgres.RunContainer(ctx,
+		tcpostgres.WithDatabase("testdb"),
+		tcpostgres.WithUsername("testuser"),
+		tcpostgres.WithPassword("testpassword"),
+	)
+	if err != nil {
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+	defer func() {
+		_ = container.Terminate(ctx)
+	}()
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		t.Fatalf("failed to get mapped port: %v", err)
+	}
+
+	manager, err := NewDatabaseManager(&config.Config{
+		DatabaseHost:            host,
+		DatabasePort:            port.Port(),
+		DatabaseUsername:        "testuser",
+		DatabasePassword:        "testpassword",
+		DatabaseName:            "testdb",
+		DatabaseMaxOpenConns:    1,
+		DatabaseMaxIdleConns:    1,
+		DatabaseConnMaxLifetime: 5 * time.Minute,
+		DatabaseConnMaxIdleTime: 1 * time.Minute,
+		DatabaseConnectTimeout:  10 * time.Second,
+		DatabaseQueryTimeout:    5 * time.Second,
+		DatabaseHealthTimeout:   3 * time.Second,
+		DatabaseRetryAttempts:   1,
+		DatabaseRetryDelay:      1 * time.Second,
+		DatabaseSSLMode:         "disable",
+		DatabaseTimezone:        "UTC",
+		DatabaseRLSEnabled:      true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create database manager: %v", err)
+	}
+	db := &PostgresDB{DB: manager.GetDB(), manager: manager}
+
+	// The container user is a superuser, which bypasses the policies: the queries run as
+	// an application role instead
+	for _, statement := range []string{
+		`CREATE TABLE secrets (company_id text NOT NULL, name text NOT NULL)`,
+		`ALTER TABLE secrets ENABLE ROW LEVEL SECURITY`,
+		`CREATE POLICY tenant_isolation ON secrets
+			USING (NULLIF(current_setting('app.tenant_id', true), '') IS NULL OR company_id = current_setting('app.tenant_id', true))`,
+		`INSERT INTO secrets VALUES ('company-1', 'smtp'), ('company-2', 'smtp'), ('company-2', 's3')`,
+		`CREATE ROLE application NOLOGIN`,
+		`GRANT SELECT ON secrets TO application`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to set up the table: %v", err)
+		}
+	}
+
+	count := func(ctx context.Context) int64 {
+		var count int64
+		err := db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+			return tx.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec("SET LOCAL ROLE application").Error; err != nil {
+					return err
+				}
+				return tx.Table("secrets").Count(&count).Error
+			})
+		})
+		if err != nil {
+			t.Fatalf("failed to count the rows: %v", err)
+		}
+		return count
+	}
+
+	if got := count(WithTenant(ctx, "company-2")); got != 2 {
+		t.Fatalf("expected the 2 rows of the tenant, got %d", got)
+	}
+	if got := count(WithTenant(ctx, "company-3")); got != 0 {
+		t.Fatalf("expected no row for a tenant without rows, got %d", got)
+	}
+	// The single pooled connection ran the transactions above
+	if got := count(ctx); got != 3 {
+		t.Fatalf("expected every row without a tenant, got %d", got)
+	}
+}
@@ -0,0 +1,47 @@
+package demo
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// Avatar renders a square PNG avatar filled with the given #RRGGBB color
+func Avatar(hexColor string, size int) ([]byte, error) {
+	fill, err := parseHexColor(hexColor)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode avatar: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func parseHexColor(hexColor string) (color.RGBA, error) {
+	value := strings.TrimPrefix(hexColor, "#")
+	if len(value) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid avatar color %q", hexColor)
+	}
+
+	rgb, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid avatar color %q: %w", hexColor, err)
+	}
+
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
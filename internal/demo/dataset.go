@@ -0,0 +1,153 @@
+// Package demo builds the seed dataset used when the service runs in demo mode.
+package demo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/utils"
+)
+
+//go:embed dataset.json
+var defaultDataset []byte
+
+// Persona is a template for a kind of demo user. Email is a text/template rendered with
+// the member first name, last name and company domain.
+type Persona struct {
+	Key         string `json:"key"`
+	Email       string `json:"email"`
+	AvatarColor string `json:"avatar_color"`
+}
+
+// Member is a demo user of a company, built from a persona
+type Member struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Persona   string `json:"persona"`
+}
+
+// Company is a demo company and its members
+type Company struct {
+	Name    string   `json:"name"`
+	Domain  string   `json:"domain"`
+	Members []Member `json:"members"`
+}
+
+// Dataset is the demo seed definition
+type Dataset struct {
+	Personas  []Persona `json:"personas"`
+	Companies []Company `json:"companies"`
+}
+
+// SeedUser is a demo user ready to be persisted, with the avatar color of its persona
+type SeedUser struct {
+	User        *models.User
+	AvatarColor string
+}
+
+// Seed is the rendered demo dataset
+type Seed struct {
+	Companies []models.Company
+	// Users points into the Users of Companies, in seed order
+	Users []SeedUser
+}
+
+// emailTemplateData is the data an email template of a persona is rendered with
+type emailTemplateData struct {
+	FirstName string
+	LastName  string
+	Domain    string
+}
+
+// Load reads the dataset at path, or the embedded default dataset when path is empty
+func Load(path string) (*Dataset, error) {
+	data := defaultDataset
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read demo dataset: %w", err)
+		}
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return nil, fmt.Errorf("parse demo dataset: %w", err)
+	}
+
+	return &dataset, nil
+}
+
+// Build renders the personas of the dataset into companies and users
+func (d *Dataset) Build() (*Seed, error) {
+	personas := make(map[string]Persona, len(d.Personas))
+	templates := make(map[string]*template.Template, len(d.Personas))
+	for _, persona := range d.Personas {
+		tmpl, err := template.New(persona.Key).Option("missingkey=error").Parse(persona.Email)
+		if err != nil {
+			return nil, fmt.Errorf("persona %q: invalid email template: %w", persona.Key, err)
+		}
+		personas[persona.Key] = persona
+		templates[persona.Key] = tmpl
+	}
+
+	seed := &Seed{Companies: make([]models.Company, len(d.Companies))}
+	emails := make(map[string]bool)
+
+	for i, company := range d.Companies {
+		seed.Companies[i] = models.Company{
+			BaseModel: models.NewBaseModel(),
+			Name:      company.Name,
+			Users:     make([]models.User, len(company.Members)),
+		}
+
+		for j, member := range company.Members {
+			tmpl, ok := templates[member.Persona]
+			if !ok {
+				return nil, fmt.Errorf("company %q: unknown persona %q", company.Name, member.Persona)
+			}
+
+			var email bytes.Buffer
+			err := tmpl.Execute(&email, emailTemplateData{
+				FirstName: member.FirstName,
+				LastName:  member.LastName,
+				Domain:    company.Domain,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("company %q: render email of %s %s: %w", company.Name, member.FirstName, member.LastName, err)
+			}
+
+			address := strings.ReplaceAll(utils.ConvertAccented(email.String()), " ", "")
+			if emails[address] {
+				return nil, fmt.Errorf("company %q: duplicated email %s", company.Name, address)
+			}
+			emails[address] = true
+
+			seed.Companies[i].Users[j] = models.User{
+				BaseModel: models.NewBaseModel(),
+				FirstName: member.FirstName,
+				LastName:  member.LastName,
+				Email:     address,
+			}
+		}
+	}
+
+	// Companies is not resized past this point, so the pointers stay valid
+	for i := range seed.Companies {
+		for j := range seed.Companies[i].Users {
+			member := d.Companies[i].Members[j]
+			seed.Users = append(seed.Users, SeedUser{
+				User:        &seed.Companies[i].Users[j],
+				AvatarColor: personas[member.Persona].AvatarColor,
+			})
+		}
+	}
+
+	return seed, nil
+}
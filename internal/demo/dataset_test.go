@@ -0,0 +1,133 @@
+package demo
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_DefaultDatasetBuilds(t *testing.T) {
+	dataset, err := Load("")
+	require.NoError(t, err)
+
+	seed, err := dataset.Build()
+	require.NoError(t, err)
+
+	require.NotEmpty(t, seed.Companies)
+	users := 0
+	for _, company := range seed.Companies {
+		assert.NotEmpty(t, company.ID)
+		users += len(company.Users)
+	}
+	assert.Len(t, seed.Users, users)
+
+	for _, seedUser := range seed.Users {
+		assert.NotEmpty(t, seedUser.User.ID)
+		assert.Regexp(t, `^[a-z.]+@[a-z.-]+$`, seedUser.User.Email)
+		assert.NotEmpty(t, seedUser.AvatarColor)
+	}
+}
+
+func TestDataset_Build(t *testing.T) {
+	persona := Persona{Key: "engineer", Email: "{{.FirstName}}.{{.LastName}}@{{.Domain}}", AvatarColor: "#10B981"}
+
+	tests := []struct {
+		name          string
+		dataset       Dataset
+		expected      []string
+		errorContains string
+	}{
+		{
+			name: "renders normalized emails",
+			dataset: Dataset{
+				Personas: []Persona{persona},
+				Companies: []Company{{Name: "Acme", Domain: "acme.example.com", Members: []Member{
+					{FirstName: "Tomás", LastName: "De Luca", Persona: "engineer"},
+				}}},
+			},
+			expected: []string{"tomas.deluca@acme.example.com"},
+		},
+		{
+			name: "unknown persona",
+			dataset: Dataset{
+				Personas: []Persona{persona},
+				Companies: []Company{{Name: "Acme", Domain: "acme.example.com", Members: []Member{
+					{FirstName: "Ava", LastName: "Stone", Persona: "designer"},
+				}}},
+			},
+			errorContains: "unknown persona",
+		},
+		{
+			name: "duplicated email",
+			dataset: Dataset{
+				Personas: []Persona{persona},
+				Companies: []Company{{Name: "Acme", Domain: "acme.example.com", Members: []Member{
+					{FirstName: "Ava", LastName: "Stone", Persona: "engineer"},
+					{FirstName: "Ava", LastName: "Stone", Persona: "engineer"},
+				}}},
+			},
+			errorContains: "duplicated email",
+		},
+		{
+			name: "unknown template field",
+			dataset: Dataset{
+				Personas: []Persona{{Key: "engineer", Email: "{{.Nickname}}@{{.Domain}}"}},
+				Companies: []Company{{Name: "Acme", Domain: "acme.example.com", Members: []Member{
+					{FirstName: "Ava", LastName: "Stone", Persona: "engineer"},
+				}}},
+			},
+			errorContains: "render email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, err := tt.dataset.Build()
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			emails := make([]string, 0, len(seed.Users))
+			for _, seedUser := range seed.Users {
+				emails = append(emails, seedUser.User.Email)
+			}
+			assert.Equal(t, tt.expected, emails)
+		})
+	}
+}
+
+func TestLoad_FromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"personas":[],"companies":[{"name":"Empty Co","domain":"empty.example.com"}]}`), 0o600))
+
+	dataset, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, dataset.Companies, 1)
+	assert.Equal(t, "Empty Co", dataset.Companies[0].Name)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestAvatar(t *testing.T) {
+	content, err := Avatar("#4F46E5", 8)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, 8, img.Bounds().Dx())
+
+	r, g, b, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{0x4F, 0x46, 0xE5}, []uint32{r >> 8, g >> 8, b >> 8})
+
+	_, err = Avatar("blue", 8)
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package deprecation
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Declared are the deprecations of the API. Mark the deprecated routes with the deprecated
+// route middleware and the deprecated request fields with a deprecated tag naming their ID.
+var Declared = []Deprecation{
+	{
+		ID:           constants.DeprecationHealthDatabase,
+		Kind:         constants.DeprecationKindRoute,
+		Surface:      "GET /api/v1/health/database",
+		DeprecatedAt: time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, time.April, 15, 0, 0, 0, 0, time.UTC),
+		Replacement:  "The database entry of GET /api/v1/health/dependencies",
+	},
+	{
+		ID:           constants.DeprecationHealthAuth,
+		Kind:         constants.DeprecationKindRoute,
+		Surface:      "GET /api/v1/health/auth",
+		DeprecatedAt: time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, time.April, 15, 0, 0, 0, 0, time.UTC),
+		Replacement:  "The auth entry of GET /api/v1/health/dependencies",
+	},
+}
@@ -0,0 +1,145 @@
+// Package deprecation declares the deprecated routes and request DTO fields of the API with
+// their sunset dates. The deprecation middleware and binder send the Deprecation (RFC 9745)
+// and Sunset (RFC 8594) headers on the requests that use them and count their consumers.
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Deprecation is a deprecated route or request field
+type Deprecation struct {
+	ID string
+	// Kind is constants.DeprecationKindRoute or constants.DeprecationKindField
+	Kind string
+	// Surface names the route, e.g. "GET /api/v1/health/database", or the field, e.g.
+	// "CreateUserRequest.phone"
+	Surface      string
+	DeprecatedAt time.Time
+	// Sunset is the date the surface is removed after
+	Sunset time.Time
+	// Replacement tells the consumers what to use instead
+	Replacement string
+	// Link is the migration guide, sent with the deprecation relation
+	Link string
+}
+
+// Registry holds the deprecations of the API by ID
+type Registry struct {
+	deprecations map[string]Deprecation
+}
+
+// NewRegistry creates a registry of the deprecations. A deprecation replaces an earlier one
+// with the same ID.
+func NewRegistry(deprecations ...Deprecation) *Registry {
+	registry := &Registry{deprecations: make(map[string]Deprecation, len(deprecations))}
+	for _, deprecation := range deprecations {
+		registry.deprecations[deprecation.ID] = deprecation
+	}
+	return registry
+}
+
+// ProvideRegistry creates the registry of the declared deprecations
+func ProvideRegistry() *Registry {
+	return NewRegistry(Declared...)
+}
+
+// Get returns the deprecation with the ID
+func (r *Registry) Get(id string) (Deprecation, bool) {
+	deprecation, ok := r.deprecations[id]
+	return deprecation, ok
+}
+
+// MustGet returns the deprecation with the ID, and panics when it is not declared so that
+// a route marked with an unknown deprecation fails at startup
+func (r *Registry) MustGet(id string) Deprecation {
+	deprecation, ok := r.deprecations[id]
+	if !ok {
+		panic(fmt.Sprintf("deprecation: %s is not declared", id))
+	}
+	return deprecation
+}
+
+// All returns the deprecations, the closest sunset first
+func (r *Registry) All() []Deprecation {
+	deprecations := make([]Deprecation, 0, len(r.deprecations))
+	for _, deprecation := range r.deprecations {
+		deprecations = append(deprecations, deprecation)
+	}
+	sort.Slice(deprecations, func(i, j int) bool {
+		if !deprecations[i].Sunset.Equal(deprecations[j].Sunset) {
+			return deprecations[i].Sunset.Before(deprecations[j].Sunset)
+		}
+		return deprecations[i].ID < deprecations[j].ID
+	})
+	return deprecations
+}
+
+// SetHeaders adds the headers of a deprecation to a response. When a response uses
+// several deprecated surfaces, the earliest deprecation and sunset dates are sent.
+func SetHeaders(header http.Header, deprecation Deprecation) {
+	if current, err := parseDeprecationDate(header.Get(constants.HeaderDeprecation)); err != nil || deprecation.DeprecatedAt.Before(current) {
+		header.Set(constants.HeaderDeprecation, "@"+strconv.FormatInt(deprecation.DeprecatedAt.Unix(), 10))
+	}
+	if current, err := http.ParseTime(header.Get(constants.HeaderSunset)); err != nil || deprecation.Sunset.Before(current) {
+		header.Set(constants.HeaderSunset, deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		header.Add(constants.HeaderLink, fmt.Sprintf(`<%s>; rel="deprecation"`, deprecation.Link))
+	}
+}
+
+// parseDeprecationDate parses the structured date of a Deprecation header, e.g. @1688169599
+func parseDeprecationDate(value string) (time.Time, error) {
+	if len(value) < 2 || value[0] != '@' {
+		return time.Time{}, fmt.Errorf("invalid deprecation date %q", value)
+	}
+	seconds, err := strconv.ParseInt(value[1:], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// Fields returns the IDs of the deprecations of the fields of a bound request that are
+// set, i.e. not zero, walking the embedded and nested structs
+func Fields(request any) []string {
+	var ids []string
+	collectFields(reflect.ValueOf(request), &ids)
+	return ids
+}
+
+func collectFields(value reflect.Value, ids *[]string) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		// The fields of unexported embedded structs are promoted, walk them too
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if id := field.Tag.Get(constants.DeprecationTag); id != "" && !fieldValue.IsZero() {
+			*ids = append(*ids, id)
+		}
+		if fieldValue.Kind() == reflect.Struct || fieldValue.Kind() == reflect.Pointer {
+			collectFields(fieldValue, ids)
+		}
+	}
+}
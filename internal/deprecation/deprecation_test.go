@@ -0,0 +1,105 @@
+package deprecation
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_All(t *testing.T) {
+	registry := NewRegistry(
+		Deprecation{ID: "late", Sunset: time.Date(2028, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		Deprecation{ID: "b-early", Sunset: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		Deprecation{ID: "a-early", Sunset: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	)
+
+	ids := make([]string, 0, 3)
+	for _, deprecation := range registry.All() {
+		ids = append(ids, deprecation.ID)
+	}
+	assert.Equal(t, []string{"a-early", "b-early", "late"}, ids, "closest sunset first, then by ID")
+
+	assert.Panics(t, func() { registry.MustGet("unknown") })
+}
+
+func TestDeclared(t *testing.T) {
+	seen := make(map[string]bool, len(Declared))
+	for _, deprecation := range Declared {
+		assert.False(t, seen[deprecation.ID], "duplicate deprecation %s", deprecation.ID)
+		seen[deprecation.ID] = true
+		assert.Contains(t, []string{constants.DeprecationKindRoute, constants.DeprecationKindField}, deprecation.Kind, deprecation.ID)
+		assert.NotEmpty(t, deprecation.Surface, deprecation.ID)
+		assert.True(t, deprecation.Sunset.After(deprecation.DeprecatedAt), "%s is sunset before it is deprecated", deprecation.ID)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	header := http.Header{}
+	later := Deprecation{
+		DeprecatedAt: time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, time.April, 15, 0, 0, 0, 0, time.UTC),
+		Link:         "https://docs.example.com/migrations/health",
+	}
+	earlier := Deprecation{
+		DeprecatedAt: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	SetHeaders(header, later)
+	assert.Equal(t, "@1792022400", header.Get(constants.HeaderDeprecation))
+	assert.Equal(t, "Thu, 15 Apr 2027 00:00:00 GMT", header.Get(constants.HeaderSunset))
+	assert.Equal(t, `<https://docs.example.com/migrations/health>; rel="deprecation"`, header.Get(constants.HeaderLink))
+
+	// A response using several deprecated surfaces announces the earliest dates
+	SetHeaders(header, earlier)
+	assert.Equal(t, "@1780272000", header.Get(constants.HeaderDeprecation))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", header.Get(constants.HeaderSunset))
+	SetHeaders(header, later)
+	assert.Equal(t, "@1780272000", header.Get(constants.HeaderDeprecation))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", header.Get(constants.HeaderSunset))
+}
+
+func TestFields(t *testing.T) {
+	type address struct {
+		Line2 string `deprecated:"address-line2"`
+	}
+	type base struct {
+		Phone string `deprecated:"user-phone"`
+	}
+	type request struct {
+		base
+		Email   string
+		Legacy  *bool `deprecated:"user-legacy"`
+		Address *address
+		Billing address
+	}
+
+	legacy := false
+	tests := []struct {
+		name     string
+		request  any
+		expected []string
+	}{
+		{name: "no deprecated field set", request: &request{Email: "user@example.com"}},
+		{name: "embedded field", request: &request{base: base{Phone: "+33600000000"}}, expected: []string{"user-phone"}},
+		{name: "pointer set to a zero value", request: &request{Legacy: &legacy}, expected: []string{"user-legacy"}},
+		{name: "nested structs", request: &request{Address: &address{Line2: "Floor 2"}, Billing: address{Line2: "Floor 3"}}, expected: []string{"address-line2", "address-line2"}},
+		{name: "not a struct", request: &[]string{"a"}},
+		{name: "nil", request: (*request)(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := Fields(tt.request)
+			require.Len(t, ids, len(tt.expected))
+			if len(tt.expected) > 0 {
+				assert.Equal(t, tt.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package dtos
+
+import "time"
+
+// AdminUserStats counts the users by status and their recent signups. Enabling and
+// disabling users is done in Keycloak, so active counts the users not deleted.
+type AdminUserStats struct {
+	Active          int64 `json:"active" example:"1250"`
+	Deleted         int64 `json:"deleted" example:"42"`
+	SignupsLastDay  int64 `json:"signups_last_day" example:"12"`
+	SignupsLastWeek int64 `json:"signups_last_week" example:"87"`
+}
+
+// AdminRecentSignup is a user who signed up recently
+type AdminRecentSignup struct {
+	ID        string    `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Email     string    `json:"email" example:"john.doe@example.com"`
+	FirstName string    `json:"first_name" example:"John"`
+	LastName  string    `json:"last_name" example:"Doe"`
+	CreatedAt time.Time `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// AdminTenantStats sums the company summaries read model
+type AdminTenantStats struct {
+	Companies    int64 `json:"companies" example:"35"`
+	Members      int64 `json:"members" example:"1250"`
+	StorageBytes int64 `json:"storage_bytes" example:"104857600"`
+	// RefreshedAt is when a summary was last projected, omitted while there is none
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// AdminFailingWebhook is an endpoint whose deliveries failed recently
+type AdminFailingWebhook struct {
+	EndpointID    string    `json:"endpoint_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	CompanyID     string    `json:"company_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	URL           string    `json:"url" example:"https://example.com/webhooks"`
+	Active        bool      `json:"active" example:"true"`
+	Failures      int64     `json:"failures" example:"14"`
+	LastFailureAt time.Time `json:"last_failure_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// AdminJobQueueStats is the depth of the task queue. Pending jobs are due, scheduled ones
+// wait for their run time.
+type AdminJobQueueStats struct {
+	Pending   int64 `json:"pending" example:"3"`
+	Scheduled int64 `json:"scheduled" example:"12"`
+	Running   int64 `json:"running" example:"2"`
+	Dead      int64 `json:"dead" example:"1"`
+	// OldestPendingAt is the run time of the job waiting the longest for a worker
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// AdminDependencyHealth is the result of the check of a dependency
+type AdminDependencyHealth struct {
+	Name      string `json:"name" example:"database"`
+	Status    string `json:"status" example:"healthy"`
+	Critical  bool   `json:"critical" example:"true"`
+	LatencyMs int64  `json:"latency_ms" example:"12"`
+	Error     string `json:"error,omitempty" example:"Failed to ping Redis"`
+}
+
+// AdminHealthStats is the health of the dependencies and their rollup
+type AdminHealthStats struct {
+	Status       string                  `json:"status" example:"healthy" enums:"healthy,degraded,unhealthy"`
+	Dependencies []AdminDependencyHealth `json:"dependencies"`
+}
+
+// AdminDashboardResponse aggregates the stats of the admin frontends in one response.
+// It is computed at most once per constants.AdminDashboardCacheTTL, GeneratedAt tells
+// when.
+type AdminDashboardResponse struct {
+	Users           AdminUserStats        `json:"users"`
+	RecentSignups   []AdminRecentSignup   `json:"recent_signups"`
+	Tenants         AdminTenantStats      `json:"tenants"`
+	FailingWebhooks []AdminFailingWebhook `json:"failing_webhooks"`
+	Jobs            AdminJobQueueStats    `json:"jobs"`
+	Health          AdminHealthStats      `json:"health"`
+	GeneratedAt     time.Time             `json:"generated_at" example:"2021-01-01T00:00:00Z"`
+}
@@ -0,0 +1,104 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+)
+
+// CreateAPIKeyRequest represents the request to create an API key of a company. A
+// sandbox key is created for the sandbox tenant of the company.
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" example:"CRM sync" validate:"required,min=1,max=100"`
+	ExpiresInDays *int   `json:"expires_in_days,omitempty" example:"365" validate:"omitempty,min=1,max=730"`
+	Sandbox       bool   `json:"sandbox" example:"false"`
+}
+
+// APIKeyResponse represents an API key without its secret
+type APIKeyResponse struct {
+	ID         string     `json:"id" example:"123"`
+	CompanyID  string     `json:"company_id" example:"123"`
+	Name       string     `json:"name" example:"CRM sync"`
+	Prefix     string     `json:"prefix" example:"gbk_Xq3vPz8a"`
+	Status     string     `json:"status" example:"active"`
+	Sandbox    bool       `json:"sandbox" example:"false"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	// LegalHold is only set while the key is on legal hold
+	LegalHold *LegalHoldResponse `json:"legal_hold,omitempty"`
+	CreatedAt time.Time          `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt time.Time          `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// CreatedAPIKeyResponse represents a new API key with its secret, only returned once
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"gbk_Xq3vPz8a0bN4yR7tLw2sKd9fHj5mC1eGuVoYiA6pTz"`
+}
+
+// APIKeyDailyUsage is the number of calls made with an API key during a day
+type APIKeyDailyUsage struct {
+	Day   string `json:"day" example:"2021-01-01"`
+	Calls int64  `json:"calls" example:"120"`
+}
+
+// APIKeyEndpointUsage is the number of calls made with an API key to an endpoint
+type APIKeyEndpointUsage struct {
+	Endpoint string `json:"endpoint" example:"GET /api/v1/companies/:id"`
+	Calls    int64  `json:"calls" example:"120"`
+}
+
+// APIKeyUsageResponse represents the usage of an API key over the last days. Recent
+// calls can take up to API_KEY_USAGE_FLUSH_INTERVAL to be counted.
+type APIKeyUsageResponse struct {
+	APIKeyID   string                `json:"api_key_id" example:"123"`
+	Days       int                   `json:"days" example:"30"`
+	TotalCalls int64                 `json:"total_calls" example:"3600"`
+	LastUsedAt *time.Time            `json:"last_used_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	Daily      []APIKeyDailyUsage    `json:"daily"`
+	Endpoints  []APIKeyEndpointUsage `json:"endpoints"`
+}
+
+// APIKeyHygieneResult reports the API keys found unused by a hygiene run
+type APIKeyHygieneResult struct {
+	Alerted int
+	Revoked int
+}
+
+func NewAPIKeyResponse(key *models.APIKey) *APIKeyResponse {
+	status := constants.APIKeyStatusActive
+	switch {
+	case key.RevokedAt != nil:
+		status = constants.APIKeyStatusRevoked
+	case !key.Active(time.Now()):
+		status = constants.APIKeyStatusExpired
+	}
+
+	response := &APIKeyResponse{
+		ID:         key.ID,
+		CompanyID:  key.CompanyID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Status:     status,
+		Sandbox:    key.Sandbox,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+	}
+	if key.OnLegalHold() {
+		response.LegalHold = NewLegalHoldResponse(key.LegalHold)
+	}
+
+	return response
+}
+
+func NewCreatedAPIKeyResponse(key *models.APIKey, rawKey string) *CreatedAPIKeyResponse {
+	return &CreatedAPIKeyResponse{
+		APIKeyResponse: *NewAPIKeyResponse(key),
+		Key:            rawKey,
+	}
+}
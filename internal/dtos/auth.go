@@ -0,0 +1,56 @@
+package dtos
+
+// SwitchOrganizationRequest selects the organization of the user the new tokens are bound to
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id" example:"3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f" validate:"required,uuid"`
+}
+
+// OrganizationResponse is a Keycloak organization of the user
+type OrganizationResponse struct {
+	ID    string `json:"id" example:"3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f"`
+	Name  string `json:"name" example:"Acme"`
+	Alias string `json:"alias" example:"acme"`
+}
+
+// SwitchOrganizationResponse holds the tokens bound to the selected organization, which
+// replace the tokens of the frontend
+type SwitchOrganizationResponse struct {
+	AccessToken      string               `json:"access_token" example:"eyJhbGciOiJSUzI1NiIs..."`
+	RefreshToken     string               `json:"refresh_token" example:"eyJhbGciOiJIUzUxMiIs..."`
+	IDToken          string               `json:"id_token,omitempty" example:"eyJhbGciOiJSUzI1NiIs..."`
+	TokenType        string               `json:"token_type" example:"Bearer"`
+	ExpiresIn        int                  `json:"expires_in" example:"300"`
+	RefreshExpiresIn int                  `json:"refresh_expires_in" example:"1800"`
+	Scope            string               `json:"scope" example:"openid organization:acme"`
+	Organization     OrganizationResponse `json:"organization"`
+}
+
+// LoginRequest holds the credentials of a user of the realm
+type LoginRequest struct {
+	Username string `json:"username" example:"jane@example.com" validate:"required,max=255"`
+	Password string `json:"password" example:"S3cure!Passw0rd" validate:"required,max=255"`
+}
+
+// RefreshTokenRequest holds the refresh token of the session to refresh
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzUxMiIs..." validate:"required"`
+}
+
+// ExchangeTokenRequest selects the client of the realm the access token is exchanged for
+type ExchangeTokenRequest struct {
+	// Audience is the client ID of the target client
+	Audience string `json:"audience" example:"reporting-api" validate:"required,max=255"`
+	// Scope restricts the scopes of the new token, space separated
+	Scope string `json:"scope,omitempty" example:"openid profile" validate:"omitempty,max=1024"`
+}
+
+// TokenResponse holds the tokens issued by Keycloak
+type TokenResponse struct {
+	AccessToken      string `json:"access_token" example:"eyJhbGciOiJSUzI1NiIs..."`
+	RefreshToken     string `json:"refresh_token,omitempty" example:"eyJhbGciOiJIUzUxMiIs..."`
+	IDToken          string `json:"id_token,omitempty" example:"eyJhbGciOiJSUzI1NiIs..."`
+	TokenType        string `json:"token_type" example:"Bearer"`
+	ExpiresIn        int    `json:"expires_in" example:"300"`
+	RefreshExpiresIn int    `json:"refresh_expires_in,omitempty" example:"1800"`
+	Scope            string `json:"scope,omitempty" example:"openid profile email"`
+}
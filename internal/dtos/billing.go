@@ -0,0 +1,62 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// ChangeSubscriptionRequest represents the request to subscribe a company to a plan
+type ChangeSubscriptionRequest struct {
+	Plan string `json:"plan" example:"pro" validate:"required,max=64"`
+}
+
+// EntitlementResponse represents a feature granted by a plan. Limit is omitted for the
+// unlimited features.
+type EntitlementResponse struct {
+	Key   string `json:"key" example:"webhooks"`
+	Limit *int64 `json:"limit,omitempty" example:"5"`
+}
+
+// SubscriptionResponse represents the plan of a company and the entitlements it grants.
+// Plan is empty while the company has no subscription in effect and no default plan is
+// configured, which leaves its features unrestricted. CheckoutURL is only returned by a
+// subscription to a paid plan that waits for the payment of the company; the current plan
+// stays in effect until it is paid.
+type SubscriptionResponse struct {
+	CompanyID         string                `json:"company_id" example:"123"`
+	Plan              string                `json:"plan,omitempty" example:"pro"`
+	PlanName          string                `json:"plan_name,omitempty" example:"Pro"`
+	Status            string                `json:"status,omitempty" example:"active"`
+	Entitlements      []EntitlementResponse `json:"entitlements"`
+	CurrentPeriodEnd  *time.Time            `json:"current_period_end,omitempty" example:"2021-01-01T00:00:00Z"`
+	CancelAtPeriodEnd bool                  `json:"cancel_at_period_end" example:"false"`
+	CheckoutURL       string                `json:"checkout_url,omitempty" example:"https://checkout.stripe.com/c/pay/cs_test_123"`
+}
+
+// NewSubscriptionResponse describes the plan of a company, from its subscription when it
+// is in effect; plan is nil for the companies without restrictions
+func NewSubscriptionResponse(companyID string, plan *models.Plan, subscription *models.Subscription) *SubscriptionResponse {
+	response := &SubscriptionResponse{
+		CompanyID:    companyID,
+		Entitlements: []EntitlementResponse{},
+	}
+	if plan == nil {
+		return response
+	}
+
+	response.Plan = plan.Key
+	response.PlanName = plan.Name
+	for _, entitlement := range plan.Entitlements {
+		response.Entitlements = append(response.Entitlements, EntitlementResponse{
+			Key:   entitlement.Key,
+			Limit: entitlement.Limit,
+		})
+	}
+	if subscription != nil {
+		response.Status = subscription.Status
+		response.CurrentPeriodEnd = subscription.CurrentPeriodEnd
+		response.CancelAtPeriodEnd = subscription.CancelAtPeriodEnd
+	}
+	return response
+}
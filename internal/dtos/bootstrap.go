@@ -0,0 +1,22 @@
+package dtos
+
+// CreateAdminRequest describes the initial admin user of a fresh environment
+type CreateAdminRequest struct {
+	Email     string `json:"email" example:"admin@example.com" validate:"required,email"`
+	FirstName string `json:"first_name,omitempty" example:"Jane" validate:"omitempty,min=2,max=100"`
+	LastName  string `json:"last_name,omitempty" example:"Doe" validate:"omitempty,min=2,max=100"`
+	Password  string `json:"-" validate:"required,min=12,max=128"`
+	// TemporaryPassword makes Keycloak ask for a new password at the first login
+	TemporaryPassword bool `json:"temporary_password"`
+}
+
+// CreateAdminResponse is the admin user provisioned in Keycloak and the database
+type CreateAdminResponse struct {
+	UserID     string `json:"user_id" example:"123"`
+	KeycloakID string `json:"keycloak_id" example:"123"`
+	Email      string `json:"email" example:"admin@example.com"`
+	// KeycloakUserCreated is false when the Keycloak user already existed
+	KeycloakUserCreated bool `json:"keycloak_user_created" example:"true"`
+	// UserCreated is false when the user already existed in the database
+	UserCreated bool `json:"user_created" example:"true"`
+}
@@ -5,7 +5,9 @@ import (
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/utils/i18n"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -15,8 +17,34 @@ type BaseResponse[T any] struct {
 	Data T    `json:"data"`
 }
 
+// JSON writes the response in the envelope of the request, tagged in the X-Envelope
+// header. The raw envelope writes the data of the successful responses alone, with their
+// pagination in headers; errors keep the v1 envelope so that clients read them the same
+// way whatever the envelope.
 func (b *BaseResponse[T]) JSON(ctx echo.Context) error {
-	return ctx.JSON(b.Meta.HttpCode(), b)
+	status := b.Meta.HttpCode()
+	header := ctx.Response().Header()
+	if Envelope(ctx) != constants.EnvelopeRaw || status >= http.StatusBadRequest {
+		header.Set(constants.HeaderEnvelope, constants.EnvelopeV1)
+		return ctx.JSON(status, b)
+	}
+
+	header.Set(constants.HeaderEnvelope, constants.EnvelopeRaw)
+	if b.Meta.Page > 0 {
+		header.Set(constants.HeaderPage, strconv.Itoa(b.Meta.Page))
+		header.Set(constants.HeaderPageSize, strconv.Itoa(b.Meta.PageSize))
+		header.Set(constants.HeaderTotalCount, strconv.FormatInt(b.Meta.Total, 10))
+	}
+	return ctx.JSON(status, b.Data)
+}
+
+// Envelope returns the envelope of the request, v1 unless the envelope middleware
+// selected another
+func Envelope(ctx echo.Context) string {
+	if envelope, ok := ctx.Get(constants.EnvelopeContextKey).(string); ok {
+		return envelope
+	}
+	return constants.EnvelopeV1
 }
 
 // Meta represents metadata for paginated responses
@@ -28,6 +56,8 @@ type Meta struct {
 	Page      int    `json:"page,omitempty"  example:"1"`
 	PageSize  int    `json:"page_size,omitempty" example:"20"`
 	Total     int64  `json:"total,omitempty" example:"18"`
+	// Demo is set when the response is served by an instance running in demo mode
+	Demo bool `json:"demo,omitempty" example:"false"`
 }
 
 // PageableRequest is a struct for pagination request. It contains the page number and the page size. Page number starts from 1.
@@ -42,6 +72,13 @@ type Pageable struct {
 	Total    int64 `json:"total"`
 }
 
+// Cursor is the position of the last entity read from a listing streamed newest first,
+// the next page starts after it
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
 type DataResponse[T any] struct {
 	Data     []T       `json:"data"`
 	Pageable *Pageable `json:"pageable,omitempty"`
@@ -121,6 +158,7 @@ func GetMeta(c echo.Context, code string, httpStatus int) Meta {
 		ErrorCode: code,
 		Message:   i18n.T(c, fmt.Sprintf("Code_%s", code), nil),
 		Code:      httpStatus,
+		Demo:      isDemoMode(c),
 	}
 }
 
@@ -132,5 +170,12 @@ func GetMetaPaging(c echo.Context, code string, pageable *Pageable, httpStatus i
 		PageSize:  pageable.PageSize,
 		Page:      pageable.Page,
 		Total:     pageable.Total,
+		Demo:      isDemoMode(c),
 	}
 }
+
+// isDemoMode reports whether the request was flagged by the demo mode middleware
+func isDemoMode(c echo.Context) bool {
+	demo, _ := c.Get(constants.DemoModeContextKey).(bool)
+	return demo
+}
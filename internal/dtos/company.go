@@ -10,6 +10,7 @@ type CompanyResponse struct {
 	ID         string    `json:"id" example:"123"`
 	Name       string    `json:"name" example:"John Doe"`
 	KeycloakID string    `json:"keycloak_id" example:"123"`
+	LogoKey    string    `json:"logo_key,omitempty" example:"logos/123/0190b7f5.png"`
 	CreatedAt  time.Time `json:"created_at" example:"2021-01-01T00:00:00Z"`
 	UpdatedAt  time.Time `json:"updated_at" example:"2021-01-01T00:00:00Z"`
 }
@@ -40,11 +41,27 @@ type UpdateCompanyRequest struct {
 	ID string `json:"id,omitempty" example:"123" validate:"omitempty,min=2,max=100"`
 }
 
+// PatchCompanyRequest is the company document an RFC 7386 merge patch is applied to.
+// A member set to null in the patch clears the field; the name cannot be cleared.
+type PatchCompanyRequest struct {
+	Name       string `json:"name,omitempty" example:"John Doe" validate:"required,min=2,max=100"`
+	KeycloakID string `json:"keycloak_id,omitempty" example:"123" validate:"omitempty,min=2,max=100"`
+}
+
+// NewPatchCompanyRequest returns the current patchable document of a company
+func NewPatchCompanyRequest(company *models.Company) *PatchCompanyRequest {
+	return &PatchCompanyRequest{
+		Name:       company.Name,
+		KeycloakID: company.KeycloakID,
+	}
+}
+
 func NewCompanyResponse(company *models.Company) *CompanyResponse {
 	return &CompanyResponse{
 		ID:         company.ID,
 		Name:       company.Name,
 		KeycloakID: company.KeycloakID,
+		LogoKey:    company.LogoKey,
 		CreatedAt:  company.CreatedAt,
 		UpdatedAt:  company.UpdatedAt,
 	}
@@ -0,0 +1,13 @@
+package dtos
+
+// ConfigSettingResponse is the effective value of a config variable of the running
+// instance, masked when it is a secret
+type ConfigSettingResponse struct {
+	Name   string `json:"name" example:"POSTGRES_PASSWORD"`
+	Value  string `json:"value" example:"****2024"`
+	Secret bool   `json:"secret" example:"true"`
+	// Source is where the value was loaded from
+	Source string `json:"source" example:"secrets_manager" enums:"env,dotenv,secrets_manager,default"`
+	// Reference is the secret reference of a value from a secrets manager
+	Reference string `json:"reference,omitempty" example:"vault://secret/data/app#db_password"`
+}
@@ -0,0 +1,40 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// CompanySummaryPageableRequest represents the request to list the company summaries of
+// the dashboard
+type CompanySummaryPageableRequest struct {
+	PageableRequest
+	Sort []string `json:"sort" example:"[-member_count,name]" enums:"name,-name,member_count,-member_count,storage_bytes,-storage_bytes,last_activity_at,-last_activity_at"`
+}
+
+// CompanySummaryResponse represents the dashboard figures of a company. RefreshedAt is
+// omitted until the summary is first projected, e.g. right after the company is created.
+type CompanySummaryResponse struct {
+	CompanyID      string     `json:"company_id" example:"123"`
+	Name           string     `json:"name" example:"Acme"`
+	MemberCount    int64      `json:"member_count" example:"12"`
+	StorageBytes   int64      `json:"storage_bytes" example:"1048576"`
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	RefreshedAt    *time.Time `json:"refreshed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewCompanySummaryResponse(summary *models.CompanySummary) *CompanySummaryResponse {
+	response := &CompanySummaryResponse{
+		CompanyID:      summary.CompanyID,
+		Name:           summary.Name,
+		MemberCount:    summary.MemberCount,
+		StorageBytes:   summary.StorageBytes,
+		LastActivityAt: summary.LastActivityAt,
+	}
+	if !summary.RefreshedAt.IsZero() {
+		response.RefreshedAt = &summary.RefreshedAt
+	}
+
+	return response
+}
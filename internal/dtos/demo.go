@@ -0,0 +1,11 @@
+package dtos
+
+import "time"
+
+// DemoSeedResponse represents the result of seeding the demo dataset
+type DemoSeedResponse struct {
+	Companies int       `json:"companies" example:"3"`
+	Users     int       `json:"users" example:"16"`
+	Avatars   int       `json:"avatars" example:"16"`
+	SeededAt  time.Time `json:"seeded_at" example:"2021-01-01T00:00:00Z"`
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+// DeprecationConsumerResponse is a consumer of a deprecated surface: an API key
+// (api_key:<id>), an OAuth client (client:<id>), a user (user:<id>) or the anonymous
+// callers. Tenant is the company of the API key or the Keycloak organization of the token.
+type DeprecationConsumerResponse struct {
+	Consumer    string    `json:"consumer" example:"api_key:123"`
+	Tenant      string    `json:"tenant,omitempty" example:"123"`
+	Calls       int64     `json:"calls" example:"42"`
+	FirstSeenAt time.Time `json:"first_seen_at" example:"2021-01-01T00:00:00Z"`
+	LastSeenAt  time.Time `json:"last_seen_at" example:"2021-01-02T00:00:00Z"`
+}
+
+// DeprecationResponse is a deprecated route or request field with its consumers, the most
+// recent first
+type DeprecationResponse struct {
+	ID           string                        `json:"id" example:"health-database"`
+	Kind         string                        `json:"kind" example:"route" enums:"route,field"`
+	Surface      string                        `json:"surface" example:"GET /api/v1/health/database"`
+	DeprecatedAt time.Time                     `json:"deprecated_at" example:"2026-10-15T00:00:00Z"`
+	Sunset       time.Time                     `json:"sunset" example:"2027-04-15T00:00:00Z"`
+	DaysToSunset int                           `json:"days_to_sunset" example:"182"`
+	Replacement  string                        `json:"replacement,omitempty" example:"The database entry of GET /api/v1/health/dependencies"`
+	Link         string                        `json:"link,omitempty" example:"https://docs.example.com/migrations/health"`
+	Calls        int64                         `json:"calls" example:"42"`
+	Consumers    []DeprecationConsumerResponse `json:"consumers"`
+}
+
+// DeprecationReportResponse lists the deprecations, the closest sunset first, with the
+// consumers that still use them. Recent calls can take up to
+// DEPRECATION_USAGE_FLUSH_INTERVAL to be counted.
+type DeprecationReportResponse struct {
+	Deprecations []DeprecationResponse `json:"deprecations"`
+}
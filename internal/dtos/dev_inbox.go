@@ -0,0 +1,10 @@
+package dtos
+
+// DevInboxPageableRequest represents the search of the emails of the dev inbox
+type DevInboxPageableRequest struct {
+	PageableRequest
+	// Q matches the subject, the recipients and the bodies
+	Q string `json:"q" example:"Welcome"`
+	// Recipient matches a To, Cc or Bcc address, ignoring case
+	Recipient string `json:"recipient" example:"jane@example.com"`
+}
@@ -58,3 +58,77 @@ type EmailListResponse struct {
 	Emails     []EmailResponse `json:"emails"`
 	Pagination Pageable        `json:"pagination"`
 }
+
+// FailedEmailResponse represents an email still failing after its attempts, kept in the
+// dead-letter queue
+type FailedEmailResponse struct {
+	ID        string     `json:"id" example:"123"`
+	To        []string   `json:"to" example:"jane@example.com"`
+	Cc        []string   `json:"cc,omitempty"`
+	Subject   string     `json:"subject" example:"Welcome to My Echo App!"`
+	Attempts  int        `json:"attempts" example:"10"`
+	LastError *string    `json:"last_error,omitempty" example:"Failed to send email via SES"`
+	FailedAt  *time.Time `json:"failed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	CreatedAt time.Time  `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// RedriveFailedEmailsRequest selects the failed emails to queue again, all of them when
+// IDs is empty
+type RedriveFailedEmailsRequest struct {
+	IDs []string `json:"ids,omitempty" validate:"omitempty,max=100,dive,uuid"`
+}
+
+// RedriveFailedEmailsResponse is the number of failed emails queued again
+type RedriveFailedEmailsResponse struct {
+	Requeued int64 `json:"requeued" example:"3"`
+}
+
+// SNSMessage is a message of an SNS topic posted to an HTTPS subscription: a
+// notification, whose Message is the JSON of the notification, or the confirmation of the
+// subscription or of its removal
+type SNSMessage struct {
+	Type             string `json:"Type" example:"Notification" enums:"Notification,SubscriptionConfirmation,UnsubscribeConfirmation"`
+	MessageID        string `json:"MessageId" example:"22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324"`
+	Token            string `json:"Token,omitempty"`
+	TopicArn         string `json:"TopicArn" example:"arn:aws:sns:ap-southeast-1:123456789012:ses-notifications"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	Timestamp        string `json:"Timestamp" example:"2026-01-01T00:00:00.000Z"`
+	SignatureVersion string `json:"SignatureVersion" example:"1" enums:"1,2"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL" example:"https://sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-0000.pem"`
+}
+
+// SESNotification is a bounce or complaint notification of SES, the Message of an SNS
+// notification
+type SESNotification struct {
+	NotificationType string        `json:"notificationType"`
+	Bounce           *SESBounce    `json:"bounce,omitempty"`
+	Complaint        *SESComplaint `json:"complaint,omitempty"`
+	Mail             SESMail       `json:"mail"`
+}
+
+// SESBounce is the bounce of an email, Permanent for the addresses that do not exist
+type SESBounce struct {
+	BounceType        string         `json:"bounceType"`
+	BounceSubType     string         `json:"bounceSubType"`
+	BouncedRecipients []SESRecipient `json:"bouncedRecipients"`
+}
+
+// SESComplaint is the complaint of recipients marking an email as spam
+type SESComplaint struct {
+	ComplaintFeedbackType string         `json:"complaintFeedbackType,omitempty"`
+	ComplainedRecipients  []SESRecipient `json:"complainedRecipients"`
+}
+
+// SESRecipient is a recipient of a bounce or complaint
+type SESRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode,omitempty"`
+}
+
+// SESMail is the email a notification is about
+type SESMail struct {
+	MessageID string `json:"messageId"`
+}
@@ -0,0 +1,56 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// ExportAuditPageableRequest represents the filters of the export audit records
+type ExportAuditPageableRequest struct {
+	PageableRequest
+	Entity      string     `json:"entity" example:"users" enums:"users,companies,company_members" validate:"omitempty,oneof=users companies company_members"`
+	PrincipalID string     `json:"principal_id" example:"123" validate:"omitempty,max=255"`
+	CompanyID   string     `json:"company_id" example:"123" validate:"omitempty,uuid"`
+	Status      string     `json:"status" example:"completed" enums:"started,completed,failed,denied" validate:"omitempty,oneof=started completed failed denied"`
+	StartDate   *time.Time `json:"start_date" example:"2025-02-26 08:36:23.886089+00"`
+	EndDate     *time.Time `json:"end_date" example:"2025-02-26 08:36:23.886089+00"`
+}
+
+// ExportAuditResponse represents an export audit record
+type ExportAuditResponse struct {
+	ID            string            `json:"id" example:"123"`
+	Entity        string            `json:"entity" example:"users" enums:"users,companies,company_members"`
+	Format        string            `json:"format" example:"text/csv"`
+	PrincipalType string            `json:"principal_type" example:"user" enums:"user,api_key"`
+	PrincipalID   string            `json:"principal_id" example:"123"`
+	Roles         []string          `json:"roles" example:"user-manager"`
+	CompanyID     *string           `json:"company_id,omitempty" example:"123"`
+	Fields        []string          `json:"fields" example:"id,email"`
+	Filters       map[string]string `json:"filters"`
+	Status        string            `json:"status" example:"completed" enums:"started,completed,failed,denied"`
+	Rows          int64             `json:"rows" example:"1200"`
+	Error         *string           `json:"error,omitempty" example:"broken pipe"`
+	CreatedAt     time.Time         `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	CompletedAt   *time.Time        `json:"completed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// NewExportAuditResponse creates an export audit record response from its model
+func NewExportAuditResponse(audit *models.ExportAudit) *ExportAuditResponse {
+	return &ExportAuditResponse{
+		ID:            audit.ID,
+		Entity:        audit.Entity,
+		Format:        audit.Format,
+		PrincipalType: audit.PrincipalType,
+		PrincipalID:   audit.PrincipalID,
+		Roles:         audit.Roles,
+		CompanyID:     audit.CompanyID,
+		Fields:        audit.Fields,
+		Filters:       audit.Filters,
+		Status:        audit.Status,
+		Rows:          audit.Rows,
+		Error:         audit.Error,
+		CreatedAt:     audit.CreatedAt,
+		CompletedAt:   audit.CompletedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// FileStatusChangedEvent is the body of the messages published when a stored file
+// changes status
+type FileStatusChangedEvent struct {
+	FileID    string    `json:"file_id" example:"123"`
+	Key       string    `json:"key" example:"avatars/123/0190b7f5.png"`
+	From      string    `json:"from" example:"scanning" enums:"pending,scanning,available,failed,quarantined"`
+	To        string    `json:"to" example:"available" enums:"pending,scanning,available,failed,quarantined"`
+	Reason    string    `json:"reason,omitempty" example:"upload failed"`
+	ChangedAt time.Time `json:"changed_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// FilePageableRequest represents the filters of the files of the registry
+type FilePageableRequest struct {
+	PageableRequest
+	OwnerID string `json:"owner_id" example:"123" validate:"omitempty,uuid"`
+	Status  string `json:"status" example:"failed" enums:"pending,scanning,available,failed,quarantined" validate:"omitempty,oneof=pending scanning available failed quarantined"`
+	// Prefix filters the files whose key starts with it, e.g. avatars/
+	Prefix string `json:"prefix" example:"avatars/" validate:"omitempty,max=1024"`
+}
+
+// FileResponse represents a file of the registry and the metadata of its object
+type FileResponse struct {
+	ID              string    `json:"id" example:"123"`
+	OwnerID         *string   `json:"owner_id,omitempty" example:"123"`
+	Key             string    `json:"key" example:"avatars/123/0190b7f5.png"`
+	Bucket          string    `json:"bucket" example:"my-bucket"`
+	ContentType     string    `json:"content_type" example:"image/png"`
+	Size            int64     `json:"size" example:"2048"`
+	Checksum        string    `json:"checksum" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
+	Status          string    `json:"status" example:"available" enums:"pending,scanning,available,failed,quarantined"`
+	StatusReason    *string   `json:"status_reason,omitempty" example:"upload failed"`
+	StatusChangedAt time.Time `json:"status_changed_at" example:"2021-01-01T00:00:00Z"`
+	CreatedAt       time.Time `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// NewFileResponse creates a file response from its model
+func NewFileResponse(file *models.File) *FileResponse {
+	return &FileResponse{
+		ID:              file.ID,
+		OwnerID:         file.OwnerID,
+		Key:             file.Key,
+		Bucket:          file.Bucket,
+		ContentType:     file.ContentType,
+		Size:            file.Size,
+		Checksum:        file.Checksum,
+		Status:          file.Status,
+		StatusReason:    file.StatusReason,
+		StatusChangedAt: file.StatusChangedAt,
+		CreatedAt:       file.CreatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package dtos
+
+import "time"
+
+// IntegrationProbeStats summarizes the probes of an integration since a time
+type IntegrationProbeStats struct {
+	Integration   string     `gorm:"column:integration"`
+	Probes        int64      `gorm:"column:probes"`
+	Failures      int64      `gorm:"column:failures"`
+	AvgLatencyMs  float64    `gorm:"column:avg_latency_ms"`
+	LastFailureAt *time.Time `gorm:"column:last_failure_at"`
+}
+
+// IntegrationHealthResponse is the health of the integrations, from their last probe,
+// with their availability over the window
+type IntegrationHealthResponse struct {
+	// Status is the rollup of the last probes: unhealthy when a critical integration
+	// is, degraded when another one is
+	Status       string              `json:"status" example:"healthy" enums:"healthy,degraded,unhealthy"`
+	WindowHours  int                 `json:"window_hours" example:"24"`
+	Integrations []IntegrationHealth `json:"integrations"`
+}
+
+// IntegrationHealth is the last probe of an integration and its availability over the window
+type IntegrationHealth struct {
+	Name      string    `json:"name" example:"payment"`
+	Status    string    `json:"status" example:"healthy" enums:"healthy,unhealthy"`
+	Critical  bool      `json:"critical" example:"false"`
+	LatencyMs int64     `json:"latency_ms" example:"120"`
+	Error     string    `json:"error,omitempty" example:"Failed to ping Stripe"`
+	CheckedAt time.Time `json:"checked_at" example:"2021-01-01T00:00:00Z"`
+	// Uptime is the percentage of the probes of the window that succeeded
+	Uptime        float64    `json:"uptime" example:"99.65"`
+	Probes        int64      `json:"probes" example:"1440"`
+	Failures      int64      `json:"failures" example:"5"`
+	AvgLatencyMs  float64    `json:"avg_latency_ms" example:"98.4"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
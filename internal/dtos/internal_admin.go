@@ -0,0 +1,22 @@
+package dtos
+
+// LogLevelRequest changes the level of the logs of the instance
+type LogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error" example:"debug"`
+}
+
+// LogLevelResponse is the level of the logs of the instance
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+}
+
+// CacheFlushRequest removes the cache keys matching a glob pattern
+type CacheFlushRequest struct {
+	Pattern string `json:"pattern" validate:"required" example:"admin:*"`
+}
+
+// CacheFlushResponse is the number of cache keys removed by a flush
+type CacheFlushResponse struct {
+	Pattern string `json:"pattern" example:"admin:*"`
+	Removed int64  `json:"removed" example:"12"`
+}
@@ -0,0 +1,78 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+)
+
+// CreateInvitationRequest represents the request to invite a person to a company by email
+type CreateInvitationRequest struct {
+	Email string `json:"email" example:"john.doe@example.com" validate:"required,email"`
+}
+
+// InvitationResponse represents an invitation to a company, without its token
+type InvitationResponse struct {
+	ID        string `json:"id" example:"123"`
+	CompanyID string `json:"company_id" example:"123"`
+	Email     string `json:"email" example:"john.doe@example.com"`
+	Status    string `json:"status" example:"pending"`
+	InvitedBy string `json:"invited_by" example:"123"`
+	// UserID is the user who accepted the invitation
+	UserID     *string    `json:"user_id,omitempty" example:"123"`
+	ExpiresAt  time.Time  `json:"expires_at" example:"2021-01-08T00:00:00Z"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" example:"2021-01-02T00:00:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2021-01-02T00:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewInvitationResponse(invitation *models.Invitation) *InvitationResponse {
+	status := constants.InvitationStatusPending
+	switch {
+	case invitation.AcceptedAt != nil:
+		status = constants.InvitationStatusAccepted
+	case invitation.RevokedAt != nil:
+		status = constants.InvitationStatusRevoked
+	case !invitation.Pending(time.Now()):
+		status = constants.InvitationStatusExpired
+	}
+
+	return &InvitationResponse{
+		ID:         invitation.ID,
+		CompanyID:  invitation.CompanyID,
+		Email:      invitation.Email,
+		Status:     status,
+		InvitedBy:  invitation.InvitedBy,
+		UserID:     invitation.UserID,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedAt:  invitation.CreatedAt,
+		UpdatedAt:  invitation.UpdatedAt,
+	}
+}
+
+// AcceptInvitationRequest represents the acceptance of an invitation with its token. The
+// password is only required when the invited person has no account yet, and is ignored
+// otherwise.
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" validate:"required"`
+	FirstName string `json:"first_name,omitempty" example:"John" validate:"omitempty,min=2,max=100"`
+	LastName  string `json:"last_name,omitempty" example:"Doe" validate:"omitempty,min=2,max=100"`
+	Password  string `json:"password,omitempty" validate:"omitempty,min=12,max=128"`
+}
+
+// AcceptInvitationResponse is the member created by the acceptance of an invitation
+type AcceptInvitationResponse struct {
+	InvitationID string `json:"invitation_id" example:"123"`
+	CompanyID    string `json:"company_id" example:"123"`
+	UserID       string `json:"user_id" example:"123"`
+	KeycloakID   string `json:"keycloak_id" example:"123"`
+	Email        string `json:"email" example:"john.doe@example.com"`
+	// KeycloakUserCreated is false when the invited person already had an account
+	KeycloakUserCreated bool `json:"keycloak_user_created" example:"true"`
+	// UserCreated is false when the user already existed in the database
+	UserCreated bool `json:"user_created" example:"true"`
+}
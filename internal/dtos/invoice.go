@@ -0,0 +1,74 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// InvoiceResponse represents an invoice of a company. Amounts are in the smallest unit of
+// the currency.
+type InvoiceResponse struct {
+	ID                string                `json:"id" example:"123"`
+	CompanyID         string                `json:"company_id" example:"123"`
+	Provider          string                `json:"provider" example:"stripe"`
+	ProviderInvoiceID string                `json:"provider_invoice_id" example:"in_1MtHbELkdIwHu7ixl4OzzPMv"`
+	Number            string                `json:"number" example:"A1B2C3D4-0001"`
+	Status            string                `json:"status" example:"paid" enums:"draft,open,paid,uncollectible,void"`
+	Currency          string                `json:"currency" example:"usd"`
+	Lines             []InvoiceLineResponse `json:"lines"`
+	Subtotal          int64                 `json:"subtotal" example:"4900"`
+	Tax               int64                 `json:"tax" example:"980"`
+	Total             int64                 `json:"total" example:"5880"`
+	AmountPaid        int64                 `json:"amount_paid" example:"5880"`
+	AmountDue         int64                 `json:"amount_due" example:"0"`
+	PeriodStart       *time.Time            `json:"period_start,omitempty" example:"2021-01-01T00:00:00Z"`
+	PeriodEnd         *time.Time            `json:"period_end,omitempty" example:"2021-02-01T00:00:00Z"`
+	IssuedAt          time.Time             `json:"issued_at" example:"2021-01-01T00:00:00Z"`
+	DueAt             *time.Time            `json:"due_at,omitempty" example:"2021-01-31T00:00:00Z"`
+}
+
+// InvoiceLineResponse represents a line of an invoice
+type InvoiceLineResponse struct {
+	Description string `json:"description" example:"1 × Pro (at $49.00 / month)"`
+	Quantity    int64  `json:"quantity" example:"1"`
+	UnitAmount  int64  `json:"unit_amount" example:"4900"`
+	Amount      int64  `json:"amount" example:"4900"`
+}
+
+// InvoicePDFResponse represents the PDF document of an invoice, downloaded from a
+// presigned URL
+type InvoicePDFResponse struct {
+	InvoiceID string    `json:"invoice_id" example:"123"`
+	PDFKey    string    `json:"pdf_key" example:"invoices/123/0190b7f5.pdf"`
+	PDFURL    string    `json:"pdf_url" example:"https://storage.googleapis.com/bucket/invoices/123/0190b7f5.pdf?X-Goog-Signature=..."`
+	ExpiresAt time.Time `json:"expires_at" example:"2021-01-01T00:15:00Z"`
+}
+
+// NewInvoiceResponse creates an invoice response from its model
+func NewInvoiceResponse(invoice *models.Invoice) *InvoiceResponse {
+	lines := make([]InvoiceLineResponse, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = InvoiceLineResponse(line)
+	}
+
+	return &InvoiceResponse{
+		ID:                invoice.ID,
+		CompanyID:         invoice.CompanyID,
+		Provider:          invoice.Provider,
+		ProviderInvoiceID: invoice.ProviderInvoiceID,
+		Number:            invoice.Number,
+		Status:            invoice.Status,
+		Currency:          invoice.Currency,
+		Lines:             lines,
+		Subtotal:          invoice.Subtotal,
+		Tax:               invoice.Tax,
+		Total:             invoice.Total,
+		AmountPaid:        invoice.AmountPaid,
+		AmountDue:         invoice.AmountDue,
+		PeriodStart:       invoice.PeriodStart,
+		PeriodEnd:         invoice.PeriodEnd,
+		IssuedAt:          invoice.IssuedAt,
+		DueAt:             invoice.DueAt,
+	}
+}
@@ -0,0 +1,37 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// JobResponse represents a job of the task queue
+type JobResponse struct {
+	ID          string          `json:"id" example:"123"`
+	Kind        string          `json:"kind" example:"send_verification_email"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status" example:"dead"`
+	Attempt     int             `json:"attempt" example:"10"`
+	MaxAttempts int             `json:"max_attempts" example:"10"`
+	RunAt       time.Time       `json:"run_at" example:"2021-01-01T00:00:00Z"`
+	LastError   *string         `json:"last_error,omitempty" example:"connection refused"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	CreatedAt   time.Time       `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewJobResponse(job *models.Job) *JobResponse {
+	return &JobResponse{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempt:     job.Attempt,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LastError:   job.LastError,
+		FinishedAt:  job.FinishedAt,
+		CreatedAt:   job.CreatedAt,
+	}
+}
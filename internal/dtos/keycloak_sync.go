@@ -0,0 +1,43 @@
+package dtos
+
+import "time"
+
+// KeycloakSyncReport reports a reconciliation of the users and companies with Keycloak.
+// In dry run the changes are the ones the sync would have made, none of them applied.
+type KeycloakSyncReport struct {
+	DryRun     bool      `json:"dry_run" example:"true"`
+	StartedAt  time.Time `json:"started_at" example:"2021-01-01T05:00:00Z"`
+	FinishedAt time.Time `json:"finished_at" example:"2021-01-01T05:00:12Z"`
+	// Counts are the changes per action, e.g. user_created or membership_removed
+	Counts map[string]int `json:"counts"`
+	// Changes lists the first changes, up to 500
+	Changes   []KeycloakSyncChange `json:"changes"`
+	Truncated bool                 `json:"truncated" example:"false"`
+	// Failed counts the changes that failed, left for the next run
+	Failed int `json:"failed" example:"0"`
+}
+
+// KeycloakSyncChange is one change of a Keycloak sync
+type KeycloakSyncChange struct {
+	Action     string `json:"action" example:"user_created"`
+	UserID     string `json:"user_id,omitempty" example:"123"`
+	CompanyID  string `json:"company_id,omitempty" example:"456"`
+	KeycloakID string `json:"keycloak_id,omitempty" example:"0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"`
+	Detail     string `json:"detail,omitempty" example:"email: john@example.com -> john.doe@example.com"`
+}
+
+// KeycloakEvent is an admin or user event of Keycloak, as posted by the event listener
+// providers: an admin event has a resource type and path, e.g. USER and users/<id>, a
+// user event the ID of its user
+type KeycloakEvent struct {
+	// Type is the type of a user event, e.g. UPDATE_EMAIL, optionally prefixed with
+	// access.
+	Type          string `json:"type,omitempty" example:"UPDATE_EMAIL"`
+	RealmID       string `json:"realmId,omitempty" example:"4b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"`
+	UserID        string `json:"userId,omitempty" example:"0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"`
+	OperationType string `json:"operationType,omitempty" example:"DELETE" enums:"CREATE,UPDATE,DELETE,ACTION"`
+	ResourceType  string `json:"resourceType,omitempty" example:"USER"`
+	ResourcePath  string `json:"resourcePath,omitempty" example:"users/0b1f6c2e-7a4d-4c1e-9d3b-2f8e5a6c7d90"`
+	// Time is the time of the event, in milliseconds since the epoch
+	Time int64 `json:"time,omitempty" example:"1760000000000"`
+}
@@ -0,0 +1,77 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+)
+
+// MaintenanceOperationResponse is an operation maintenance tasks can run
+type MaintenanceOperationResponse struct {
+	Name        string `json:"name" example:"rebuild_projections"`
+	Description string `json:"description" example:"Rebuild the read models from the source tables, one after the other"`
+}
+
+// CreateMaintenanceTaskRequest starts a task of an operation. ThrottleMs pauses the task
+// between two batches to spare the database.
+type CreateMaintenanceTaskRequest struct {
+	Operation  string          `json:"operation" example:"rebuild_projections" validate:"required"`
+	Params     json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+	BatchSize  int             `json:"batch_size,omitempty" example:"100" validate:"omitempty,min=1,max=1000"`
+	ThrottleMs int64           `json:"throttle_ms,omitempty" example:"500" validate:"omitempty,min=0,max=60000"`
+}
+
+// UpdateMaintenanceTaskRequest changes the throttling of a task from its next batch on
+type UpdateMaintenanceTaskRequest struct {
+	BatchSize  *int   `json:"batch_size,omitempty" example:"50" validate:"omitempty,min=1,max=1000"`
+	ThrottleMs *int64 `json:"throttle_ms,omitempty" example:"1000" validate:"omitempty,min=0,max=60000"`
+}
+
+// MaintenanceTaskResponse represents a maintenance task and its progress
+type MaintenanceTaskResponse struct {
+	ID         string          `json:"id" example:"0190b7f5-8f4a-7d3e-9c1b-2a6f4e8d1c3b"`
+	Operation  string          `json:"operation" example:"reencrypt_tenant_credentials"`
+	Params     json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+	Status     string          `json:"status" example:"running" enums:"running,paused,failed,completed,cancelled"`
+	BatchSize  int             `json:"batch_size" example:"100"`
+	ThrottleMs int64           `json:"throttle_ms" example:"500"`
+	Processed  int64           `json:"processed" example:"1200"`
+	Total      int64           `json:"total" example:"4800"`
+	Percent    float64         `json:"percent" example:"25"`
+	LastError  *string         `json:"last_error,omitempty" example:"Credentials vault is not configured"`
+	CreatedBy  string          `json:"created_by" example:"f47ac10b-58cc-4372-a567-0e02b2c3d479"`
+	StartedAt  time.Time       `json:"started_at" example:"2021-01-01T00:00:00Z"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt  time.Time       `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// NewMaintenanceTaskResponse reports the progress of a task as a percentage of its total,
+// which is counted when it starts: items added since can take it past 100, so it is capped
+func NewMaintenanceTaskResponse(task *models.MaintenanceTask) *MaintenanceTaskResponse {
+	percent := 0.0
+	switch {
+	case task.Status == constants.MaintenanceStatusCompleted:
+		percent = 100
+	case task.Total > 0:
+		percent = min(100, float64(task.Processed*10000/task.Total)/100)
+	}
+
+	return &MaintenanceTaskResponse{
+		ID:         task.ID,
+		Operation:  task.Operation,
+		Params:     task.Params,
+		Status:     task.Status,
+		BatchSize:  task.BatchSize,
+		ThrottleMs: task.ThrottleMs,
+		Processed:  task.Processed,
+		Total:      task.Total,
+		Percent:    percent,
+		LastError:  task.LastError,
+		CreatedBy:  task.CreatedBy,
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+		UpdatedAt:  task.UpdatedAt,
+	}
+}
@@ -0,0 +1,29 @@
+package dtos
+
+import "time"
+
+// UpdateOnboardingStepRequest represents the request to override the state of an
+// onboarding step
+type UpdateOnboardingStepRequest struct {
+	Completed *bool `json:"completed" example:"true" validate:"required"`
+}
+
+// OnboardingStepResponse represents the state of an onboarding step. Source is `manual`
+// when the state was set through the API, `computed` when it comes from the tenant data.
+type OnboardingStepResponse struct {
+	Key          string     `json:"key" example:"invite_users"`
+	Completed    bool       `json:"completed" example:"true"`
+	Source       string     `json:"source" example:"computed"`
+	OverriddenBy string     `json:"overridden_by,omitempty" example:"123"`
+	OverriddenAt *time.Time `json:"overridden_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// OnboardingResponse represents the onboarding checklist of a tenant
+type OnboardingResponse struct {
+	CompanyID string                   `json:"company_id" example:"123"`
+	Completed int                      `json:"completed" example:"2"`
+	Total     int                      `json:"total" example:"3"`
+	Progress  int                      `json:"progress" example:"66"`
+	Done      bool                     `json:"done" example:"false"`
+	Steps     []OnboardingStepResponse `json:"steps"`
+}
@@ -0,0 +1,82 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// PaymentEvent is the body of the messages published for the verified events of the
+// payment providers
+type PaymentEvent struct {
+	ID        string          `json:"id" example:"evt_1NG8Du2eZvKYlo2CUI79vXWy"`
+	Provider  string          `json:"provider" example:"stripe" enums:"stripe,paypal"`
+	Type      string          `json:"type" example:"invoice.paid"`
+	CreatedAt time.Time       `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// RefundPaymentRequest represents a refund of a payment. Amount is in the smallest unit of
+// the currency of the payment; without it, what was not refunded yet is refunded.
+type RefundPaymentRequest struct {
+	Amount int64  `json:"amount,omitempty" example:"1500" validate:"omitempty,gt=0"`
+	Reason string `json:"reason,omitempty" example:"requested_by_customer" enums:"duplicate,fraudulent,requested_by_customer" validate:"omitempty,oneof=duplicate fraudulent requested_by_customer"`
+	Note   string `json:"note,omitempty" example:"Order 1042 returned" validate:"omitempty,max=500"`
+}
+
+// CapturePaymentRequest represents a capture of an authorized payment. Amount is in the
+// smallest unit of the currency of the payment; without it, the whole authorization is
+// captured.
+type CapturePaymentRequest struct {
+	Amount int64  `json:"amount,omitempty" example:"1500" validate:"omitempty,gt=0"`
+	Note   string `json:"note,omitempty" example:"Order 1042 shipped" validate:"omitempty,max=500"`
+}
+
+// VoidPaymentRequest represents the cancellation of an authorized payment
+type VoidPaymentRequest struct {
+	Note string `json:"note,omitempty" example:"Order 1042 canceled" validate:"omitempty,max=500"`
+}
+
+// PaymentOperationResponse represents a refund, capture or void of a payment and who
+// initiated it
+type PaymentOperationResponse struct {
+	ID               string     `json:"id" example:"123"`
+	Operation        string     `json:"operation" example:"refund" enums:"refund,capture,void"`
+	Provider         string     `json:"provider" example:"stripe"`
+	PaymentID        string     `json:"payment_id" example:"pi_3MtwBwLkdIwHu7ix28a3tqPa"`
+	Amount           *int64     `json:"amount,omitempty" example:"1500"`
+	ProcessedAmount  int64      `json:"processed_amount" example:"1500"`
+	Currency         string     `json:"currency,omitempty" example:"usd"`
+	Reason           string     `json:"reason,omitempty" example:"requested_by_customer"`
+	Note             string     `json:"note,omitempty" example:"Order 1042 returned"`
+	Status           string     `json:"status" example:"succeeded" enums:"started,succeeded,failed"`
+	ProviderObjectID string     `json:"provider_object_id,omitempty" example:"re_1Nispe2eZvKYlo2Cd31jOCgZ"`
+	ProviderStatus   string     `json:"provider_status,omitempty" example:"succeeded"`
+	Error            *string    `json:"error,omitempty" example:"Charge has already been refunded."`
+	InitiatedBy      string     `json:"initiated_by" example:"123"`
+	CreatedAt        time.Time  `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// NewPaymentOperationResponse creates a payment operation response from its model
+func NewPaymentOperationResponse(operation *models.PaymentOperation) *PaymentOperationResponse {
+	return &PaymentOperationResponse{
+		ID:               operation.ID,
+		Operation:        operation.Operation,
+		Provider:         operation.Provider,
+		PaymentID:        operation.PaymentID,
+		Amount:           operation.Amount,
+		ProcessedAmount:  operation.ProcessedAmount,
+		Currency:         operation.Currency,
+		Reason:           operation.Reason,
+		Note:             operation.Note,
+		Status:           operation.Status,
+		ProviderObjectID: operation.ProviderObjectID,
+		ProviderStatus:   operation.ProviderStatus,
+		Error:            operation.Error,
+		InitiatedBy:      operation.InitiatedBy,
+		CreatedAt:        operation.CreatedAt,
+		CompletedAt:      operation.CompletedAt,
+	}
+}
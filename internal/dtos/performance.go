@@ -0,0 +1,48 @@
+package dtos
+
+import "time"
+
+// PerformanceStats aggregates the requests of a tenant: their number by outcome and their
+// response times. Percentiles are estimated from the histogram buckets.
+type PerformanceStats struct {
+	Requests     int64 `json:"requests" example:"1200"`
+	ClientErrors int64 `json:"client_errors" example:"12"`
+	ServerErrors int64 `json:"server_errors" example:"1"`
+	// ErrorRate is the share of the requests that failed with a server error, from 0 to 1
+	ErrorRate float64 `json:"error_rate" example:"0.0008"`
+	AverageMs float64 `json:"average_ms" example:"84.5"`
+	P50Ms     float64 `json:"p50_ms" example:"62.5"`
+	P95Ms     float64 `json:"p95_ms" example:"240"`
+	P99Ms     float64 `json:"p99_ms" example:"480"`
+}
+
+// PerformanceBucket is the number of requests whose response time is within a bucket of
+// the histogram. UpperBoundMs is null for the requests slower than the last bucket.
+type PerformanceBucket struct {
+	UpperBoundMs *int64 `json:"upper_bound_ms" example:"100"`
+	Requests     int64  `json:"requests" example:"300"`
+}
+
+// RoutePerformance is the performance of the requests of a tenant to a route
+type RoutePerformance struct {
+	Route string `json:"route" example:"GET /api/v1/companies/:id"`
+	PerformanceStats
+}
+
+// HourlyPerformance is the performance of the requests of a tenant during an hour
+type HourlyPerformance struct {
+	Hour time.Time `json:"hour" example:"2021-01-01T10:00:00Z"`
+	PerformanceStats
+}
+
+// TenantPerformanceResponse represents the performance of the requests of a tenant over
+// the last hours, overall, per route and per hour. Recent requests can take up to
+// PERFORMANCE_FLUSH_INTERVAL to be counted.
+type TenantPerformanceResponse struct {
+	CompanyID string              `json:"company_id" example:"123"`
+	Hours     int                 `json:"hours" example:"24"`
+	Overall   PerformanceStats    `json:"overall"`
+	Histogram []PerformanceBucket `json:"histogram"`
+	Routes    []RoutePerformance  `json:"routes"`
+	Hourly    []HourlyPerformance `json:"hourly"`
+}
@@ -0,0 +1,42 @@
+package dtos
+
+// ProvisionTenantRequest declares the state of a provisioned tenant. The omitted domains
+// and default roles are removed from the tenant; webhook endpoints are only created or
+// updated, never deleted.
+type ProvisionTenantRequest struct {
+	Name string `json:"name" example:"Acme" validate:"required,min=2,max=100"`
+	// Domains are the domains of the Keycloak organization of the tenant
+	Domains []string `json:"domains,omitempty" example:"acme.com" validate:"max=20,dive,required,fqdn"`
+	// DefaultRoles are the client roles of the members of the tenant, created in Keycloak
+	// when missing
+	DefaultRoles []string `json:"default_roles,omitempty" example:"company-viewer,user-viewer" validate:"max=20,dive,required,max=100"`
+	// StoragePrefix prefixes the storage keys of the tenant, tenants/{slug} by default. It
+	// cannot change once the tenant is created.
+	StoragePrefix string `json:"storage_prefix,omitempty" example:"tenants/acme" validate:"omitempty,max=255"`
+	// Webhooks are the default webhook endpoints of the tenant, matched by URL
+	Webhooks []CreateWebhookRequest `json:"webhooks,omitempty" validate:"max=20,dive"`
+}
+
+// ProvisionTenantResponse is the state of a provisioned tenant
+type ProvisionTenantResponse struct {
+	Slug          string                       `json:"slug" example:"acme"`
+	CompanyID     string                       `json:"company_id" example:"123"`
+	Name          string                       `json:"name" example:"Acme"`
+	KeycloakID    string                       `json:"keycloak_id" example:"123"`
+	Domains       []string                     `json:"domains" example:"acme.com"`
+	DefaultRoles  []string                     `json:"default_roles" example:"company-viewer,user-viewer"`
+	StoragePrefix string                       `json:"storage_prefix" example:"tenants/acme"`
+	Webhooks      []ProvisionedWebhookResponse `json:"webhooks"`
+	// Created is true when the call created the tenant
+	Created bool `json:"created" example:"true"`
+}
+
+// ProvisionedWebhookResponse is a default webhook endpoint of a provisioned tenant
+type ProvisionedWebhookResponse struct {
+	ID  string `json:"id" example:"123"`
+	URL string `json:"url" example:"https://example.com/webhooks"`
+	// Secret is the signing secret of an endpoint the call created, only returned once
+	Secret string `json:"secret,omitempty" example:"whsec_3q2+7w=="`
+	// Created is true when the call created the endpoint
+	Created bool `json:"created" example:"true"`
+}
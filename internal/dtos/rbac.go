@@ -0,0 +1,50 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// SaveRoleRequest represents the description and the permissions of a role of the
+// database RBAC, which replace the ones of the role when it exists
+type SaveRoleRequest struct {
+	Description string   `json:"description" example:"Manages the users of the tenants" validate:"max=255"`
+	Permissions []string `json:"permissions" example:"users:read,users:write" validate:"max=100,dive,required,max=100"`
+}
+
+// RoleResponse represents a role of the database RBAC with its permissions
+type RoleResponse struct {
+	ID          string    `json:"id" example:"123"`
+	Name        string    `json:"name" example:"user-manager"`
+	Description string    `json:"description" example:"Manages the users of the tenants"`
+	Permissions []string  `json:"permissions" example:"users:read,users:write"`
+	CreatedAt   time.Time `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// NewRoleResponse creates a RoleResponse from the role and its permissions
+func NewRoleResponse(role *models.Role) *RoleResponse {
+	return &RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.PermissionNames(),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// SetUserRolesRequest represents the roles of the database RBAC assigned to a user, which
+// replace their roles
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" example:"user-manager" validate:"max=50,dive,required,max=100"`
+}
+
+// UserRolesResponse represents the roles of the database RBAC of a user and the
+// permissions they grant
+type UserRolesResponse struct {
+	UserID      string   `json:"user_id" example:"123"`
+	Roles       []string `json:"roles" example:"user-manager"`
+	Permissions []string `json:"permissions" example:"users:read,users:write"`
+}
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// UpdateRetentionPolicyRequest represents the request to set the retention period of a
+// resource of a company
+type UpdateRetentionPolicyRequest struct {
+	Days int `json:"days" example:"365" validate:"required,min=1,max=3650"`
+}
+
+// PlaceLegalHoldRequest represents the request to place an entity on legal hold
+type PlaceLegalHoldRequest struct {
+	Reason string `json:"reason" example:"Litigation 2026-042" validate:"required,min=1,max=500"`
+}
+
+// RetentionPolicyResponse represents the retention period of a resource of a company.
+// Days is omitted when the resource has no policy and is kept forever.
+type RetentionPolicyResponse struct {
+	Resource  string     `json:"resource" example:"api_key_usage"`
+	Days      *int       `json:"days,omitempty" example:"365"`
+	UpdatedBy string     `json:"updated_by,omitempty" example:"123"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// LegalHoldResponse represents the legal hold of an entity
+type LegalHoldResponse struct {
+	OnHold   bool       `json:"on_hold" example:"true"`
+	Reason   string     `json:"reason,omitempty" example:"Litigation 2026-042"`
+	PlacedBy string     `json:"placed_by,omitempty" example:"123"`
+	PlacedAt *time.Time `json:"placed_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+// RetentionResponse represents the retention policies of a company. While the company is
+// on legal hold none of its data is purged.
+type RetentionResponse struct {
+	CompanyID string                    `json:"company_id" example:"123"`
+	LegalHold LegalHoldResponse         `json:"legal_hold"`
+	Policies  []RetentionPolicyResponse `json:"policies"`
+}
+
+// RetentionResult reports the rows purged by a retention run, per resource
+type RetentionResult struct {
+	Policies int
+	Purged   map[string]int64
+	Failed   int
+}
+
+func NewLegalHoldResponse(hold models.LegalHold) *LegalHoldResponse {
+	return &LegalHoldResponse{
+		OnHold:   hold.OnLegalHold(),
+		Reason:   hold.LegalHoldReason,
+		PlacedBy: hold.LegalHoldBy,
+		PlacedAt: hold.LegalHoldAt,
+	}
+}
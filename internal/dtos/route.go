@@ -0,0 +1,41 @@
+package dtos
+
+import "golang-boilerplate/internal/routing"
+
+// RouteResponse is a route registered on a listener of the instance
+type RouteResponse struct {
+	Listener string `json:"listener" example:"public" enums:"public,internal"`
+	Method   string `json:"method" example:"GET"`
+	Path     string `json:"path" example:"/api/v1/companies/:id"`
+	Handler  string `json:"handler" example:"handlers.(*CompanyHandler).GetCompanyByID"`
+	// Middlewares are the middlewares the route runs, in order, the global ones first
+	Middlewares []string `json:"middlewares" example:"request_id,auth,api_key_or_token"`
+	// Schemes are the authentication schemes the route accepts, empty for a public route
+	Schemes []string `json:"schemes" example:"api_key,bearer"`
+	// Roles are the roles the route requires, any of them
+	Roles []string `json:"roles" example:"admin,company-manager"`
+}
+
+// NewRouteResponse creates the response of a route of the catalog
+func NewRouteResponse(route routing.Route) RouteResponse {
+	response := RouteResponse{
+		Listener:    route.Listener,
+		Method:      route.Method,
+		Path:        route.Path,
+		Handler:     route.Handler,
+		Middlewares: route.Middlewares,
+		Schemes:     route.Schemes,
+		Roles:       route.Roles,
+	}
+	// The lists are never null
+	if response.Middlewares == nil {
+		response.Middlewares = []string{}
+	}
+	if response.Schemes == nil {
+		response.Schemes = []string{}
+	}
+	if response.Roles == nil {
+		response.Roles = []string{}
+	}
+	return response
+}
@@ -0,0 +1,42 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// CapturedEmailResponse represents an email captured instead of being sent, in the inbox
+// of a sandbox tenant or in the dev inbox
+type CapturedEmailResponse struct {
+	ID           string          `json:"id" example:"123"`
+	CompanyID    *string         `json:"company_id,omitempty" example:"123"`
+	To           []string        `json:"to" example:"jane@example.com"`
+	Cc           []string        `json:"cc,omitempty" example:"john@example.com"`
+	Bcc          []string        `json:"bcc,omitempty" example:"audit@example.com"`
+	Subject      string          `json:"subject" example:"Welcome to Acme"`
+	TextBody     string          `json:"text_body,omitempty" example:"Hello Jane"`
+	HTMLBody     string          `json:"html_body,omitempty" example:"<p>Hello Jane</p>"`
+	TemplateID   string          `json:"template_id,omitempty" example:"welcome"`
+	TemplateData json.RawMessage `json:"template_data,omitempty" swaggertype:"object"`
+	Attachments  []string        `json:"attachments,omitempty" example:"invoice.pdf"`
+	CreatedAt    time.Time       `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewCapturedEmailResponse(email *models.CapturedEmail) *CapturedEmailResponse {
+	return &CapturedEmailResponse{
+		ID:           email.ID,
+		CompanyID:    email.CompanyID,
+		To:           email.To,
+		Cc:           email.Cc,
+		Bcc:          email.Bcc,
+		Subject:      email.Subject,
+		TextBody:     email.TextBody,
+		HTMLBody:     email.HTMLBody,
+		TemplateID:   email.TemplateID,
+		TemplateData: email.TemplateData,
+		Attachments:  email.Attachments,
+		CreatedAt:    email.CreatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SCIMUser is a user of the SCIM API (RFC 7643 section 4.1). The userName is the email
+// of the user; an identity provider whose userName is not an email sends it as the
+// primary email.
+type SCIMUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty" validate:"max=255"`
+	UserName    string      `json:"userName" validate:"required,max=255"`
+	Name        *SCIMName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []SCIMEmail `json:"emails,omitempty" validate:"max=10,dive"`
+	// Active is true when omitted from a request creating the user, and left as is when
+	// omitted from a request replacing it
+	Active *bool        `json:"active,omitempty"`
+	Groups []SCIMMember `json:"groups,omitempty"`
+	Meta   *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMName is the name of a SCIM user
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty" validate:"max=100"`
+	FamilyName string `json:"familyName,omitempty" validate:"max=100"`
+}
+
+// SCIMEmail is an email of a SCIM user
+type SCIMEmail struct {
+	Value   string `json:"value" validate:"required,email"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMGroup is a group of the SCIM API (RFC 7643 section 4.2), a company whose members
+// are its users
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName" validate:"required,min=2,max=100"`
+	Members     []SCIMMember `json:"members,omitempty" validate:"dive"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMMember references a user from a group, or a group from a user
+type SCIMMember struct {
+	Value   string `json:"value" validate:"required"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMMeta is the metadata of a SCIM resource
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// SCIMListRequest is a listing of SCIM resources. Filter only supports the equality of one
+// attribute, e.g. userName eq "john.doe@example.com", which is what the identity providers
+// send to find a resource before creating it.
+type SCIMListRequest struct {
+	// StartIndex is the 1-based index of the first resource
+	StartIndex int
+	// Count is the number of resources of the page; 0 only counts them
+	Count  int
+	Filter string
+	// ExcludedAttributes are the attributes left out of the resources; excluding the
+	// members of the groups spares loading them
+	ExcludedAttributes []string
+}
+
+// SCIMListResponse is a page of SCIM resources
+type SCIMListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// SCIMPatchRequest is a SCIM PATCH request (RFC 7644 section 3.5.2)
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" validate:"required,min=1,max=100,dive"`
+}
+
+// SCIMPatchOperation is an operation of a SCIM PATCH request. Op is add, remove or
+// replace, whatever its case; Value is decoded according to the path.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op" validate:"required"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SCIMError is the body of the SCIM error responses (RFC 7644 section 3.12)
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMServiceProviderConfig describes the SCIM features of the service (RFC 7643 section 5)
+type SCIMServiceProviderConfig struct {
+	Schemas               []string                   `json:"schemas"`
+	Patch                 SCIMSupported              `json:"patch"`
+	Bulk                  SCIMBulk                   `json:"bulk"`
+	Filter                SCIMFilterSupport          `json:"filter"`
+	ChangePassword        SCIMSupported              `json:"changePassword"`
+	Sort                  SCIMSupported              `json:"sort"`
+	ETag                  SCIMSupported              `json:"etag"`
+	AuthenticationSchemes []SCIMAuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  *SCIMMeta                  `json:"meta,omitempty"`
+}
+
+// SCIMSupported tells whether a SCIM feature is supported
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+// SCIMBulk describes the support of the bulk operations
+type SCIMBulk struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// SCIMFilterSupport describes the support of the filters
+type SCIMFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// SCIMAuthenticationScheme is an authentication scheme of the SCIM API
+type SCIMAuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary,omitempty"`
+}
+
+// SCIMResourceType describes a resource type of the SCIM API
+type SCIMResourceType struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Endpoint    string    `json:"endpoint"`
+	Description string    `json:"description"`
+	Schema      string    `json:"schema"`
+	Meta        *SCIMMeta `json:"meta,omitempty"`
+}
@@ -0,0 +1,45 @@
+package dtos
+
+import (
+	"golang-boilerplate/internal/models"
+	"time"
+)
+
+// CreateTenantCredentialRequest represents the request to store a provider credential of a company
+type CreateTenantCredentialRequest struct {
+	Provider string            `json:"provider" example:"smtp" validate:"required,oneof=smtp s3"`
+	Name     string            `json:"name" example:"default" validate:"required,min=1,max=100"`
+	Secret   map[string]string `json:"secret" validate:"required,min=1,max=20,dive,keys,min=1,max=64,endkeys,max=4096"`
+}
+
+// RotateTenantCredentialRequest represents the request to replace the secret of a credential
+type RotateTenantCredentialRequest struct {
+	Secret map[string]string `json:"secret" validate:"required,min=1,max=20,dive,keys,min=1,max=64,endkeys,max=4096"`
+}
+
+// TenantCredentialResponse represents a tenant credential with its secret values masked
+type TenantCredentialResponse struct {
+	ID        string            `json:"id" example:"123"`
+	CompanyID string            `json:"company_id" example:"123"`
+	Provider  string            `json:"provider" example:"smtp"`
+	Name      string            `json:"name" example:"default"`
+	Secret    map[string]string `json:"secret"`
+	Version   int               `json:"version" example:"2"`
+	RotatedAt *time.Time        `json:"rotated_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	CreatedAt time.Time         `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt time.Time         `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewTenantCredentialResponse(credential *models.TenantCredential) *TenantCredentialResponse {
+	return &TenantCredentialResponse{
+		ID:        credential.ID,
+		CompanyID: credential.CompanyID,
+		Provider:  credential.Provider,
+		Name:      credential.Name,
+		Secret:    credential.MaskedSecret,
+		Version:   credential.Version,
+		RotatedAt: credential.RotatedAt,
+		CreatedAt: credential.CreatedAt,
+		UpdatedAt: credential.UpdatedAt,
+	}
+}
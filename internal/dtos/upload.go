@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+// PresignUploadRequest represents the request of a presigned upload, declaring the file
+// the client is about to upload
+type PresignUploadRequest struct {
+	Filename    string `json:"filename" example:"report.pdf" validate:"required,max=255"`
+	ContentType string `json:"content_type" example:"application/pdf" validate:"required,max=255"`
+	Size        int64  `json:"size" example:"10485760" validate:"gt=0,lte=5368709120"`
+}
+
+// PresignedUploadResponse represents a presigned upload: the client posts the fields then
+// the file, in a last "file" field, as multipart/form-data to the URL before it expires,
+// then completes the file. The storage refuses another content type or a file larger
+// than declared.
+type PresignedUploadResponse struct {
+	FileID    string            `json:"file_id" example:"0199e3a4-5b6c-7d8e-9f00-112233445566"`
+	Key       string            `json:"key" example:"uploads/123/0199e3a4-5b6c-7d8e-9f00-112233445566.pdf"`
+	URL       string            `json:"url" example:"https://bucket.s3.us-east-1.amazonaws.com"`
+	Method    string            `json:"method" example:"POST"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt time.Time         `json:"expires_at" example:"2021-01-01T00:15:00Z"`
+}
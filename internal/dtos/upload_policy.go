@@ -0,0 +1,44 @@
+package dtos
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// UpdateUploadPolicyRequest represents the request to set the upload policy of a company.
+// Content types and extensions are matched case insensitively; extensions may omit the
+// leading dot.
+type UpdateUploadPolicyRequest struct {
+	AllowedContentTypes []string `json:"allowed_content_types" example:"image/png,application/pdf" validate:"max=100,dive,required,max=255"`
+	MaxSizeBytes        int64    `json:"max_size_bytes" example:"10485760" validate:"gte=0,lte=5368709120"`
+	BannedExtensions    []string `json:"banned_extensions" example:".exe,.js" validate:"max=100,dive,required,max=32"`
+}
+
+// UploadPolicyResponse represents the upload policy of a company. Configured is false, and
+// the other fields are omitted, while the company accepts every upload within the
+// built-in limits.
+type UploadPolicyResponse struct {
+	CompanyID           string     `json:"company_id" example:"123"`
+	Configured          bool       `json:"configured" example:"true"`
+	AllowedContentTypes []string   `json:"allowed_content_types,omitempty" example:"image/png,application/pdf"`
+	MaxSizeBytes        int64      `json:"max_size_bytes,omitempty" example:"10485760"`
+	BannedExtensions    []string   `json:"banned_extensions,omitempty" example:".exe,.js"`
+	UpdatedBy           string     `json:"updated_by,omitempty" example:"123"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty" example:"2021-01-01T00:00:00Z"`
+}
+
+func NewUploadPolicyResponse(companyID string, policy *models.UploadPolicy) *UploadPolicyResponse {
+	response := &UploadPolicyResponse{CompanyID: companyID}
+	if policy == nil {
+		return response
+	}
+
+	response.Configured = true
+	response.AllowedContentTypes = policy.AllowedContentTypes
+	response.MaxSizeBytes = policy.MaxSizeBytes
+	response.BannedExtensions = policy.BannedExtensions
+	response.UpdatedBy = policy.UpdatedBy
+	response.UpdatedAt = &policy.UpdatedAt
+	return response
+}
@@ -12,9 +12,34 @@ type UserResponse struct {
 	Email     string            `json:"email" example:"john.doe@example.com"`
 	FirstName string            `json:"first_name" example:"John"`
 	LastName  string            `json:"last_name" example:"Doe"`
+	AvatarKey string            `json:"avatar_key,omitempty" example:"avatars/123/0190b7f5.png"`
 	CreatedAt time.Time         `json:"created_at" example:"2021-01-01T00:00:00Z"`
 	UpdatedAt time.Time         `json:"updated_at" example:"2021-01-01T00:00:00Z"`
 	Companies []CompanyResponse `json:"companies"`
+	// MFA is only returned by the lookup of a user by ID, and omitted when Keycloak cannot
+	// be reached
+	MFA *MFAStatusResponse `json:"mfa,omitempty"`
+}
+
+// MFAStatusResponse is the multi-factor authentication state of a user
+type MFAStatusResponse struct {
+	// Configured is set when the user has an OTP authenticator
+	Configured bool `json:"configured" example:"true"`
+	// Required is set when the user is asked to configure one at their next login
+	Required bool `json:"required" example:"false"`
+}
+
+// SessionResponse is an active session of a user, one per login on a device
+type SessionResponse struct {
+	ID        string `json:"id" example:"0f6c9e4a-7d1b-4a38-9b25-3c0e8f2d6a11"`
+	IPAddress string `json:"ip_address" example:"203.0.113.7"`
+	// StartedAt is when the user logged in, LastAccessAt when the session was last refreshed
+	StartedAt    time.Time `json:"started_at" example:"2021-01-01T00:00:00Z"`
+	LastAccessAt time.Time `json:"last_access_at" example:"2021-01-01T00:00:00Z"`
+	// Clients are the client IDs the session holds tokens of
+	Clients []string `json:"clients" example:"web-app"`
+	// Current is set on the session of the token of the request
+	Current bool `json:"current" example:"true"`
 }
 
 // UserPageableRequest represents the request structure for a user
@@ -26,13 +51,35 @@ type UserPageableRequest struct {
 	Sort      []string   `json:"sort" example:"[-created_at,name]" enums:"created_at,-created_at,name,-name"`
 }
 
+// UserSearchRequest represents a user full-text search request
+type UserSearchRequest struct {
+	PageableRequest
+	Q string `json:"q" example:"john do" validate:"required,max=200"`
+}
+
+// UserSearchResponse represents a user matched by a full-text search
+type UserSearchResponse struct {
+	ID        string    `json:"id" example:"123"`
+	Email     string    `json:"email" example:"john.doe@example.com"`
+	FirstName string    `json:"first_name" example:"John"`
+	LastName  string    `json:"last_name" example:"Doe"`
+	AvatarKey string    `json:"avatar_key,omitempty" example:"avatars/123/0190b7f5.png"`
+	CreatedAt time.Time `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	// Rank is the relevance of the match, higher is better
+	Rank float64 `json:"rank" example:"0.6079271"`
+	// Highlight is the matched text with the matching words wrapped in <mark> tags
+	Highlight string `json:"highlight" example:"<mark>John</mark> <mark>Doe</mark> john.doe@example.com"`
+}
+
 // UserRequest represents a user request DTO
 type UserRequest struct {
-	Email      string                 `json:"email,omitempty" example:"john.doe@example.com" validate:"omitempty,email"`
-	FirstName  string                 `json:"first_name,omitempty" example:"John" validate:"omitempty,min=2,max=100"`
-	LastName   string                 `json:"last_name,omitempty" example:"Doe" validate:"omitempty,min=2,max=100"`
-	KeycloakID string                 `json:"keycloak_id,omitempty" example:"123" validate:"omitempty,min=2,max=100"`
-	Companies  []UpdateCompanyRequest `json:"companies,omitempty"`
+	Email      string `json:"email,omitempty" example:"john.doe@example.com" validate:"omitempty,email"`
+	FirstName  string `json:"first_name,omitempty" example:"John" validate:"omitempty,min=2,max=100"`
+	LastName   string `json:"last_name,omitempty" example:"Doe" validate:"omitempty,min=2,max=100"`
+	KeycloakID string `json:"keycloak_id,omitempty" example:"123" validate:"omitempty,min=2,max=100"`
+	// Companies of the user. On update, omitting them keeps the memberships and an empty
+	// list removes them all.
+	Companies []UpdateCompanyRequest `json:"companies,omitempty"`
 }
 
 // CreateCompanyRequest represents the request structure for a company
@@ -46,12 +93,32 @@ type UpdateUserRequest struct {
 	Status constants.UserStatus `json:"status" example:"active" enums:"active,inactive"`
 }
 
+// PatchUserRequest is the user document an RFC 7386 merge patch is applied to.
+// A member set to null in the patch clears the field; the email cannot be cleared.
+type PatchUserRequest struct {
+	Email      string `json:"email,omitempty" example:"john.doe@example.com" validate:"required,email"`
+	FirstName  string `json:"first_name,omitempty" example:"John" validate:"omitempty,min=2,max=100"`
+	LastName   string `json:"last_name,omitempty" example:"Doe" validate:"omitempty,min=2,max=100"`
+	KeycloakID string `json:"keycloak_id,omitempty" example:"123" validate:"omitempty,min=2,max=100"`
+}
+
+// NewPatchUserRequest returns the current patchable document of a user
+func NewPatchUserRequest(user *models.User) *PatchUserRequest {
+	return &PatchUserRequest{
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		KeycloakID: user.KeycloakID,
+	}
+}
+
 func NewUserResponse(user *models.User) *UserResponse {
 	result := &UserResponse{
 		ID:        user.ID,
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
+		AvatarKey: user.AvatarKey,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -63,3 +130,61 @@ func NewUserResponse(user *models.User) *UserResponse {
 
 	return result
 }
+
+// NewUserSearchResponse creates a UserSearchResponse from a search result
+func NewUserSearchResponse(result *models.UserSearchResult) *UserSearchResponse {
+	return &UserSearchResponse{
+		ID:        result.ID,
+		Email:     result.Email,
+		FirstName: result.FirstName,
+		LastName:  result.LastName,
+		AvatarKey: result.AvatarKey,
+		CreatedAt: result.CreatedAt,
+		Rank:      result.Rank,
+		Highlight: result.Highlight,
+	}
+}
+
+// ImportUserRow represents a single parsed CSV row of a user import.
+// Errors holds the request validation failures found while parsing the row.
+type ImportUserRow struct {
+	Row    int               `json:"row"`
+	User   CreateUserRequest `json:"user"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ImportUserRowError represents the validation errors of a single CSV row
+type ImportUserRowError struct {
+	Row    int               `json:"row" example:"2"`
+	Email  string            `json:"email,omitempty" example:"john.doe@example.com"`
+	Errors map[string]string `json:"errors"`
+}
+
+// ImportUsersResponse represents the result of a CSV user import
+type ImportUsersResponse struct {
+	DryRun       bool                 `json:"dry_run" example:"true"`
+	TotalRows    int                  `json:"total_rows" example:"10"`
+	ValidRows    int                  `json:"valid_rows" example:"9"`
+	InvalidRows  int                  `json:"invalid_rows" example:"1"`
+	ImportedRows int                  `json:"imported_rows" example:"0"`
+	Errors       []ImportUserRowError `json:"errors"`
+}
+
+// UserAvatarResponse represents the avatar of a user with a presigned URL
+type UserAvatarResponse struct {
+	UserID    string    `json:"user_id" example:"123"`
+	AvatarKey string    `json:"avatar_key" example:"avatars/123/0190b7f5.png"`
+	Status    string    `json:"status" example:"available" enums:"pending,scanning,available,failed,quarantined"`
+	AvatarURL string    `json:"avatar_url" example:"https://storage.googleapis.com/bucket/avatars/123/0190b7f5.png?X-Goog-Signature=..."`
+	ExpiresAt time.Time `json:"expires_at" example:"2021-01-01T01:00:00Z"`
+}
+
+// UserMembershipChangedEvent is the body of the messages published when a company is
+// added to or removed from a user
+type UserMembershipChangedEvent struct {
+	UserID     string    `json:"user_id" example:"123"`
+	KeycloakID string    `json:"keycloak_id,omitempty" example:"123"`
+	CompanyID  string    `json:"company_id" example:"456"`
+	Change     string    `json:"change" example:"added" enums:"added,removed"`
+	ChangedAt  time.Time `json:"changed_at" example:"2021-01-01T00:00:00Z"`
+}
@@ -0,0 +1,146 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/models"
+)
+
+// WebhookCondition narrows down the events delivered to an endpoint by testing the
+// values a JSONPath selects in the event data
+type WebhookCondition struct {
+	Path     string `json:"path" example:"$.status" validate:"required,max=255"`
+	Operator string `json:"operator" example:"eq" enums:"eq,ne,in,exists" validate:"required,oneof=eq ne in exists"`
+	Value    any    `json:"value,omitempty" swaggertype:"string" example:"active"`
+}
+
+// CreateWebhookRequest represents the request to register a webhook endpoint of a company.
+// An event type ending with `.*` subscribes to the types under that prefix, `*` to all.
+type CreateWebhookRequest struct {
+	URL         string             `json:"url" example:"https://example.com/webhooks" validate:"required,url,max=2048"`
+	Description string             `json:"description,omitempty" example:"CRM sync" validate:"max=255"`
+	EventTypes  []string           `json:"event_types" example:"user.*,company.updated" validate:"required,min=1,max=50,dive,required,max=100"`
+	Conditions  []WebhookCondition `json:"conditions,omitempty" validate:"max=20,dive"`
+	// RateLimit caps the deliveries per minute to the endpoint, 0 is unlimited
+	RateLimit int `json:"rate_limit,omitempty" example:"60" validate:"min=0,max=6000"`
+}
+
+// UpdateWebhookRequest represents the changes to a webhook endpoint, the omitted fields
+// are kept. An empty conditions array removes the conditions.
+type UpdateWebhookRequest struct {
+	URL         *string            `json:"url,omitempty" example:"https://example.com/webhooks" validate:"omitempty,url,max=2048"`
+	Description *string            `json:"description,omitempty" example:"CRM sync" validate:"omitempty,max=255"`
+	EventTypes  []string           `json:"event_types,omitempty" example:"user.*,company.updated" validate:"omitempty,min=1,max=50,dive,required,max=100"`
+	Conditions  []WebhookCondition `json:"conditions,omitempty" validate:"omitempty,max=20,dive"`
+	// Active pauses the deliveries to the endpoint when false
+	Active    *bool `json:"active,omitempty" example:"true"`
+	RateLimit *int  `json:"rate_limit,omitempty" example:"60" validate:"omitempty,min=0,max=6000"`
+}
+
+// WebhookDeliveryPageableRequest represents the filters of the delivery logs of a company
+type WebhookDeliveryPageableRequest struct {
+	PageableRequest
+	WebhookID string `json:"webhook_id" example:"123"`
+	Status    string `json:"status" example:"failed" enums:"pending,succeeded,failed" validate:"omitempty,oneof=pending succeeded failed"`
+	EventType string `json:"event_type" example:"user.updated" validate:"omitempty,max=100"`
+}
+
+// WebhookEndpointResponse represents a webhook endpoint without its signing secret
+type WebhookEndpointResponse struct {
+	ID          string             `json:"id" example:"123"`
+	CompanyID   string             `json:"company_id" example:"123"`
+	URL         string             `json:"url" example:"https://example.com/webhooks"`
+	Description string             `json:"description" example:"CRM sync"`
+	EventTypes  []string           `json:"event_types" example:"user.*,company.updated"`
+	Conditions  []WebhookCondition `json:"conditions"`
+	Active      bool               `json:"active" example:"true"`
+	RateLimit   int                `json:"rate_limit" example:"60"`
+	CreatedBy   string             `json:"created_by" example:"123"`
+	CreatedAt   time.Time          `json:"created_at" example:"2021-01-01T00:00:00Z"`
+	UpdatedAt   time.Time          `json:"updated_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// CreatedWebhookEndpointResponse represents a new webhook endpoint, or one whose secret
+// was rotated, with its signing secret, only returned once
+type CreatedWebhookEndpointResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret" example:"whsec_Xq3vPz8a0bN4yR7tLw2sKd9fHj5mC1eGuVoYiA6pTz"`
+}
+
+// WebhookDeliveryResponse represents the log of the delivery of an event to an endpoint
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id" example:"123"`
+	WebhookID      string          `json:"webhook_id" example:"123"`
+	EventID        string          `json:"event_id" example:"123"`
+	EventType      string          `json:"event_type" example:"user.updated"`
+	Status         string          `json:"status" example:"succeeded" enums:"pending,succeeded,failed"`
+	Attempts       int             `json:"attempts" example:"1"`
+	ResponseStatus *int            `json:"response_status,omitempty" example:"200"`
+	ResponseBody   *string         `json:"response_body,omitempty" example:"ok"`
+	LastError      *string         `json:"last_error,omitempty" example:"context deadline exceeded"`
+	DurationMs     *int64          `json:"duration_ms,omitempty" example:"120"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" example:"2021-01-01T00:00:00Z"`
+	RedeliveryOf   *string         `json:"redelivery_of,omitempty" example:"123"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// WebhookEvent is the JSON body of the webhook requests
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CompanyID string          `json:"company_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func NewWebhookEndpointResponse(endpoint *models.WebhookEndpoint) *WebhookEndpointResponse {
+	conditions := []WebhookCondition{}
+	if len(endpoint.Conditions) > 0 {
+		// The conditions were validated when stored
+		_ = json.Unmarshal(endpoint.Conditions, &conditions)
+	}
+
+	return &WebhookEndpointResponse{
+		ID:          endpoint.ID,
+		CompanyID:   endpoint.CompanyID,
+		URL:         endpoint.URL,
+		Description: endpoint.Description,
+		EventTypes:  endpoint.EventTypes,
+		Conditions:  conditions,
+		Active:      endpoint.Active,
+		RateLimit:   endpoint.RateLimit,
+		CreatedBy:   endpoint.CreatedBy,
+		CreatedAt:   endpoint.CreatedAt,
+		UpdatedAt:   endpoint.UpdatedAt,
+	}
+}
+
+func NewCreatedWebhookEndpointResponse(endpoint *models.WebhookEndpoint, secret string) *CreatedWebhookEndpointResponse {
+	return &CreatedWebhookEndpointResponse{
+		WebhookEndpointResponse: *NewWebhookEndpointResponse(endpoint),
+		Secret:                  secret,
+	}
+}
+
+func NewWebhookDeliveryResponse(delivery *models.WebhookDelivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		DurationMs:     delivery.DurationMs,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		RedeliveryOf:   delivery.RedeliveryOf,
+		Payload:        delivery.Payload,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
@@ -60,6 +60,14 @@ type AppError struct {
 	Operation string `json:"operation,omitempty"`
 	// Resource that was being accessed
 	Resource string `json:"resource,omitempty"`
+	// Provider is the third-party integration that failed (external errors only)
+	Provider string `json:"provider,omitempty"`
+	// ProviderCode is the provider specific error code, e.g. "SlowDown" or "invalid_grant"
+	ProviderCode string `json:"provider_code,omitempty"`
+	// UpstreamStatus is the HTTP status returned by the provider, 0 when no response was received
+	UpstreamStatus int `json:"upstream_status,omitempty"`
+	// Retryable reports whether the failed call is safe to retry
+	Retryable bool `json:"retryable,omitempty"`
 }
 
 // Error implements the error interface
@@ -222,6 +230,17 @@ func ConflictError(message string, cause error) *AppError {
 	return WrapError(cause, constants.BadRequest, message, ErrorTypeConflict, http.StatusConflict)
 }
 
+// InfectedFileError creates the error of an upload the virus scanner found infected
+func InfectedFileError(message string, cause error) *AppError {
+	return WrapError(cause, constants.FileInfected, message, ErrorTypeValidation, http.StatusUnprocessableEntity)
+}
+
+// ChecksumMismatchError creates the error of a file whose content does not match its
+// checksum
+func ChecksumMismatchError(message string, cause error) *AppError {
+	return WrapError(cause, constants.ChecksumMismatch, message, ErrorTypeExternal, http.StatusBadGateway)
+}
+
 // InternalError creates an internal server error
 func InternalError(message string, cause error) *AppError {
 	return WrapError(cause, constants.InternalError, message, ErrorTypeInternal, http.StatusInternalServerError)
@@ -232,9 +251,21 @@ func DatabaseError(message string, cause error) *AppError {
 	return WrapError(cause, constants.DatabaseError, message, ErrorTypeDatabase, http.StatusInternalServerError)
 }
 
-// ExternalServiceError creates an external service error
+// ExternalServiceError creates an external service error,
+// carrying over the provider classification when the cause is an already classified provider error.
 func ExternalServiceError(message string, cause error) *AppError {
-	return WrapError(cause, constants.ExternalServiceError, message, ErrorTypeExternal, http.StatusBadGateway)
+	appErr := WrapError(cause, constants.ExternalServiceError, message, ErrorTypeExternal, http.StatusBadGateway)
+
+	if upstream := GetAppError(cause); upstream != nil && upstream.Provider != "" {
+		appErr.Code = upstream.Code
+		appErr.HTTPStatus = upstream.HTTPStatus
+		appErr.Provider = upstream.Provider
+		appErr.ProviderCode = upstream.ProviderCode
+		appErr.UpstreamStatus = upstream.UpstreamStatus
+		appErr.Retryable = upstream.Retryable
+	}
+
+	return appErr
 }
 
 // CacheError creates a cache error
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler_Envelope(t *testing.T) {
+	tests := []struct {
+		name             string
+		envelope         string
+		respond          func(h *ErrorHandler, c echo.Context) error
+		expectedStatus   int
+		expectedEnvelope string
+		expectedBody     string
+		expectedTotal    string
+	}{
+		{
+			name:     "v1 by default",
+			envelope: "",
+			respond: func(h *ErrorHandler, c echo.Context) error {
+				return h.SuccessResponse(c, "Users retrieved", []string{"john"}, &dtos.Pageable{Page: 1, PageSize: 10, Total: 1})
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEnvelope: constants.EnvelopeV1,
+			expectedBody:     `{"meta":{"error_code":"SUCCESS","message":"Users retrieved","code":200,"page":1,"page_size":10,"total":1},"data":["john"]}`,
+		},
+		{
+			name:     "raw data with the pagination in headers",
+			envelope: constants.EnvelopeRaw,
+			respond: func(h *ErrorHandler, c echo.Context) error {
+				return h.SuccessResponse(c, "Users retrieved", []string{"john"}, &dtos.Pageable{Page: 1, PageSize: 10, Total: 1})
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEnvelope: constants.EnvelopeRaw,
+			expectedBody:     `["john"]`,
+			expectedTotal:    "1",
+		},
+		{
+			name:     "errors keep the v1 envelope",
+			envelope: constants.EnvelopeRaw,
+			respond: func(h *ErrorHandler, c echo.Context) error {
+				return h.NotFoundErrorResponse(c, "User")
+			},
+			expectedStatus:   http.StatusNotFound,
+			expectedEnvelope: constants.EnvelopeV1,
+			expectedBody:     `{"meta":{"error_code":"USER_NOT_FOUND","message":"User not found","code":404},"data":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/users", nil), rec)
+			if tt.envelope != "" {
+				c.Set(constants.EnvelopeContextKey, tt.envelope)
+			}
+
+			require.NoError(t, tt.respond(NewErrorHandler(), c))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedEnvelope, rec.Header().Get(constants.HeaderEnvelope))
+			assert.Equal(t, tt.expectedTotal, rec.Header().Get(constants.HeaderTotalCount))
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
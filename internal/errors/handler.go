@@ -26,6 +26,16 @@ func NewErrorHandler() *ErrorHandler {
 
 // HandleError processes an error and returns appropriate HTTP response
 func (h *ErrorHandler) HandleError(c echo.Context, err error) error {
+	appErr := h.ReportError(c, err)
+
+	// Return structured error response
+	return h.errorResponse(c, appErr)
+}
+
+// ReportError converts an error to an AppError, logs it and reports it to Sentry without
+// writing a response. Transports that render errors in their own format, like GraphQL,
+// use it to share the REST error handling.
+func (h *ErrorHandler) ReportError(c echo.Context, err error) *AppError {
 	appErr := h.processError(err)
 
 	// Log the error with context
@@ -34,8 +44,7 @@ func (h *ErrorHandler) HandleError(c echo.Context, err error) error {
 	// Report to Sentry if available
 	h.reportToSentry(c, appErr)
 
-	// Return structured error response
-	return h.errorResponse(c, appErr)
+	return appErr
 }
 
 // processError converts various error types to AppError
@@ -193,6 +202,16 @@ func (h *ErrorHandler) logError(c echo.Context, appErr *AppError) {
 		zap.Time("timestamp", appErr.Timestamp),
 	}
 
+	// Add upstream provider context for external errors
+	if appErr.Provider != "" {
+		fields = append(fields,
+			zap.String("provider", appErr.Provider),
+			zap.String("provider_code", appErr.ProviderCode),
+			zap.Int("upstream_status", appErr.UpstreamStatus),
+			zap.Bool("retryable", appErr.Retryable),
+		)
+	}
+
 	// Add request context
 	if c != nil {
 		fields = append(fields,
@@ -235,6 +254,12 @@ func (h *ErrorHandler) reportToSentry(c echo.Context, appErr *AppError) {
 			scope.SetTag("http_status", fmt.Sprintf("%d", appErr.HTTPStatus))
 			scope.SetTag("operation", appErr.Operation)
 			scope.SetTag("resource", appErr.Resource)
+			if appErr.Provider != "" {
+				scope.SetTag("provider", appErr.Provider)
+				scope.SetTag("provider_code", appErr.ProviderCode)
+				scope.SetTag("upstream_status", fmt.Sprintf("%d", appErr.UpstreamStatus))
+				scope.SetTag("retryable", fmt.Sprintf("%t", appErr.Retryable))
+			}
 
 			// Set request context
 			if c != nil {
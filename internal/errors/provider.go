@@ -0,0 +1,231 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"google.golang.org/api/googleapi"
+)
+
+// upstreamClass is the coarse classification of an upstream failure used to pick
+// the HTTP status returned to our own clients
+type upstreamClass int
+
+const (
+	// upstreamPermanent is a non-retryable upstream rejection (502)
+	upstreamPermanent upstreamClass = iota
+	// upstreamUnavailable is a transient upstream outage or timeout (503)
+	upstreamUnavailable
+	// upstreamRateLimited means the upstream throttled us (429)
+	upstreamRateLimited
+)
+
+// awsErrorCodes maps AWS SDK (S3, SES, STS) error codes to a class.
+// Codes not listed fall back to the upstream HTTP status.
+var awsErrorCodes = map[string]upstreamClass{
+	"Throttling":                             upstreamRateLimited,
+	"ThrottlingException":                    upstreamRateLimited,
+	"ThrottledException":                     upstreamRateLimited,
+	"TooManyRequestsException":               upstreamRateLimited,
+	"RequestLimitExceeded":                   upstreamRateLimited,
+	"RequestThrottled":                       upstreamRateLimited,
+	"SlowDown":                               upstreamRateLimited,
+	"LimitExceededException":                 upstreamRateLimited,
+	"ProvisionedThroughputExceededException": upstreamRateLimited,
+	"ServiceUnavailable":                     upstreamUnavailable,
+	"ServiceUnavailableException":            upstreamUnavailable,
+	"InternalError":                          upstreamUnavailable,
+	"InternalFailure":                        upstreamUnavailable,
+	"RequestTimeout":                         upstreamUnavailable,
+	"RequestTimeoutException":                upstreamUnavailable,
+	"AccessDenied":                           upstreamPermanent,
+	"AccessDeniedException":                  upstreamPermanent,
+	"InvalidAccessKeyId":                     upstreamPermanent,
+	"SignatureDoesNotMatch":                  upstreamPermanent,
+	"ExpiredToken":                           upstreamPermanent,
+	"NoSuchBucket":                           upstreamPermanent,
+	"NoSuchKey":                              upstreamPermanent,
+	"MessageRejected":                        upstreamPermanent,
+	"MailFromDomainNotVerifiedException":     upstreamPermanent,
+	"AccountSendingPausedException":          upstreamPermanent,
+}
+
+// gcsErrorReasons maps Google Cloud Storage JSON API error reasons to a class
+var gcsErrorReasons = map[string]upstreamClass{
+	"rateLimitExceeded":     upstreamRateLimited,
+	"userRateLimitExceeded": upstreamRateLimited,
+	"quotaExceeded":         upstreamRateLimited,
+	"backendError":          upstreamUnavailable,
+	"internalError":         upstreamUnavailable,
+	"serviceUnavailable":    upstreamUnavailable,
+	"forbidden":             upstreamPermanent,
+	"notFound":              upstreamPermanent,
+	"invalid":               upstreamPermanent,
+	"required":              upstreamPermanent,
+	"authError":             upstreamPermanent,
+}
+
+// keycloakErrorCodes maps Keycloak OAuth/admin API error codes to a class
+var keycloakErrorCodes = map[string]upstreamClass{
+	"invalid_grant":           upstreamPermanent,
+	"invalid_client":          upstreamPermanent,
+	"unauthorized_client":     upstreamPermanent,
+	"invalid_token":           upstreamPermanent,
+	"access_denied":           upstreamPermanent,
+	"temporarily_unavailable": upstreamUnavailable,
+	"server_error":            upstreamUnavailable,
+}
+
+// httpStatusError is implemented by errors that carry the upstream HTTP response status,
+// such as smithy response errors and httpclient status errors
+type httpStatusError interface {
+	error
+	HTTPStatusCode() int
+}
+
+// WithProvider records which third-party provider failed and, for external service errors,
+// derives the provider error code, upstream HTTP status and retryability from the cause.
+// The response status becomes 429 when the upstream throttled us, 503 when it is unavailable
+// or timed out, and 502 for any other upstream failure.
+func (e *AppError) WithProvider(provider string) *AppError {
+	e.Provider = provider
+	if e.Type != ErrorTypeExternal {
+		return e
+	}
+
+	code, status, class := classifyUpstream(e.Cause)
+	e.ProviderCode = code
+	e.UpstreamStatus = status
+
+	switch class {
+	case upstreamRateLimited:
+		e.Code = constants.ExternalServiceRateLimited
+		e.HTTPStatus = http.StatusTooManyRequests
+		e.Retryable = true
+	case upstreamUnavailable:
+		e.Code = constants.ExternalServiceUnavailable
+		e.HTTPStatus = http.StatusServiceUnavailable
+		e.Retryable = true
+	default:
+		e.Code = constants.ExternalServiceError
+		e.HTTPStatus = http.StatusBadGateway
+		e.Retryable = false
+	}
+
+	return e
+}
+
+// IsRetryable reports whether err is an AppError flagged as safe to retry
+func IsRetryable(err error) bool {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Retryable
+	}
+	return false
+}
+
+// classifyUpstream extracts the provider error code and upstream HTTP status from a
+// gocloak, AWS SDK or Google API error and classifies the failure
+func classifyUpstream(err error) (code string, status int, class upstreamClass) {
+	if err == nil {
+		return "", 0, upstreamPermanent
+	}
+
+	var keycloakErr *gocloak.APIError
+	if stderrors.As(err, &keycloakErr) {
+		code = keycloakErrorCode(keycloakErr)
+		// gocloak reports transport failures (no response) with a zero code
+		if keycloakErr.Code == 0 && code == "" {
+			return "", 0, upstreamUnavailable
+		}
+		if c, ok := keycloakErrorCodes[code]; ok {
+			return code, keycloakErr.Code, c
+		}
+		return code, keycloakErr.Code, classifyStatus(keycloakErr.Code)
+	}
+
+	var awsErr smithy.APIError
+	if stderrors.As(err, &awsErr) {
+		code = awsErr.ErrorCode()
+		var respErr *smithyhttp.ResponseError
+		if stderrors.As(err, &respErr) {
+			status = respErr.HTTPStatusCode()
+		}
+		if c, ok := awsErrorCodes[code]; ok {
+			return code, status, c
+		}
+		return code, status, classifyStatus(status)
+	}
+
+	var gcsErr *googleapi.Error
+	if stderrors.As(err, &gcsErr) {
+		if len(gcsErr.Errors) > 0 {
+			code = gcsErr.Errors[0].Reason
+		}
+		if c, ok := gcsErrorReasons[code]; ok {
+			return code, gcsErr.Code, c
+		}
+		return code, gcsErr.Code, classifyStatus(gcsErr.Code)
+	}
+
+	var statusErr httpStatusError
+	if stderrors.As(err, &statusErr) {
+		return "", statusErr.HTTPStatusCode(), classifyStatus(statusErr.HTTPStatusCode())
+	}
+
+	if isTransientTransportError(err) {
+		return "", 0, upstreamUnavailable
+	}
+
+	return "", 0, upstreamPermanent
+}
+
+// keycloakErrorCode pulls the OAuth error code (e.g. invalid_grant) out of a gocloak error
+func keycloakErrorCode(err *gocloak.APIError) string {
+	if err.Type == gocloak.APIErrTypeInvalidGrant {
+		return "invalid_grant"
+	}
+	// Messages look like "401 Unauthorized: invalid_grant: Invalid user credentials"
+	parts := strings.Split(err.Message, ": ")
+	if len(parts) >= 2 {
+		candidate := strings.TrimSpace(parts[1])
+		if candidate != "" && !strings.Contains(candidate, " ") {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// classifyStatus classifies an upstream HTTP status code
+func classifyStatus(status int) upstreamClass {
+	switch status {
+	case http.StatusTooManyRequests:
+		return upstreamRateLimited
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return upstreamUnavailable
+	default:
+		return upstreamPermanent
+	}
+}
+
+// isTransientTransportError reports timeouts and connection failures that never produced a response
+func isTransientTransportError(err error) bool {
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return stderrors.As(err, &opErr)
+}
@@ -0,0 +1,179 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+)
+
+func awsResponseError(status int, code string) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      &smithy.GenericAPIError{Code: code, Message: "aws error"},
+	}
+}
+
+// statusError is an error carrying an upstream HTTP status, like httpclient.StatusError
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("unexpected status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestAppError_WithProvider(t *testing.T) {
+	tests := []struct {
+		name              string
+		provider          string
+		cause             error
+		expectedStatus    int
+		expectedCode      string
+		expectedProvider  string
+		expectedUpstream  int
+		expectedRetryable bool
+	}{
+		{
+			name:              "keycloak invalid grant is a bad gateway",
+			provider:          constants.AuthProviderKeycloak,
+			cause:             &gocloak.APIError{Code: http.StatusUnauthorized, Message: "401 Unauthorized: invalid_grant: Invalid user credentials"},
+			expectedStatus:    http.StatusBadGateway,
+			expectedCode:      constants.ExternalServiceError,
+			expectedProvider:  "invalid_grant",
+			expectedUpstream:  http.StatusUnauthorized,
+			expectedRetryable: false,
+		},
+		{
+			name:              "keycloak transport failure is unavailable",
+			provider:          constants.AuthProviderKeycloak,
+			cause:             &gocloak.APIError{Code: 0, Message: "could not get token: dial tcp 127.0.0.1:8080: connect: connection refused"},
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedCode:      constants.ExternalServiceUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:              "keycloak 503 is unavailable",
+			provider:          constants.AuthProviderKeycloak,
+			cause:             &gocloak.APIError{Code: http.StatusServiceUnavailable, Message: "503 Service Unavailable"},
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedCode:      constants.ExternalServiceUnavailable,
+			expectedUpstream:  http.StatusServiceUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:              "aws throttling is rate limited",
+			provider:          constants.StorageProviderS3,
+			cause:             fmt.Errorf("operation error S3: PutObject: %w", awsResponseError(http.StatusServiceUnavailable, "SlowDown")),
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedCode:      constants.ExternalServiceRateLimited,
+			expectedProvider:  "SlowDown",
+			expectedUpstream:  http.StatusServiceUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:              "aws message rejected is a bad gateway",
+			provider:          constants.EmailProviderSES,
+			cause:             awsResponseError(http.StatusBadRequest, "MessageRejected"),
+			expectedStatus:    http.StatusBadGateway,
+			expectedCode:      constants.ExternalServiceError,
+			expectedProvider:  "MessageRejected",
+			expectedUpstream:  http.StatusBadRequest,
+			expectedRetryable: false,
+		},
+		{
+			name:              "aws unknown code falls back to upstream status",
+			provider:          constants.StorageProviderS3,
+			cause:             awsResponseError(http.StatusGatewayTimeout, "SomethingNew"),
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedCode:      constants.ExternalServiceUnavailable,
+			expectedProvider:  "SomethingNew",
+			expectedUpstream:  http.StatusGatewayTimeout,
+			expectedRetryable: true,
+		},
+		{
+			name:              "gcs rate limit reason is rate limited",
+			provider:          constants.StorageProviderGCS,
+			cause:             &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}},
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedCode:      constants.ExternalServiceRateLimited,
+			expectedProvider:  "rateLimitExceeded",
+			expectedUpstream:  http.StatusForbidden,
+			expectedRetryable: true,
+		},
+		{
+			name:              "status error 429 is rate limited",
+			provider:          constants.AuthProviderKeycloak,
+			cause:             fmt.Errorf("fetch page 2: %w", statusError(http.StatusTooManyRequests)),
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedCode:      constants.ExternalServiceRateLimited,
+			expectedUpstream:  http.StatusTooManyRequests,
+			expectedRetryable: true,
+		},
+		{
+			name:              "status error 404 is a bad gateway",
+			provider:          constants.AuthProviderKeycloak,
+			cause:             statusError(http.StatusNotFound),
+			expectedStatus:    http.StatusBadGateway,
+			expectedCode:      constants.ExternalServiceError,
+			expectedUpstream:  http.StatusNotFound,
+			expectedRetryable: false,
+		},
+		{
+			name:              "context deadline is unavailable",
+			provider:          constants.StorageProviderGCS,
+			cause:             fmt.Errorf("write object: %w", context.DeadlineExceeded),
+			expectedStatus:    http.StatusServiceUnavailable,
+			expectedCode:      constants.ExternalServiceUnavailable,
+			expectedRetryable: true,
+		},
+		{
+			name:              "unknown error is a bad gateway",
+			provider:          constants.StorageProviderGCS,
+			cause:             fmt.Errorf("boom"),
+			expectedStatus:    http.StatusBadGateway,
+			expectedCode:      constants.ExternalServiceError,
+			expectedRetryable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr := ExternalServiceError("upstream call failed", tt.cause).WithProvider(tt.provider)
+
+			assert.Equal(t, tt.provider, appErr.Provider)
+			assert.Equal(t, tt.expectedStatus, appErr.HTTPStatus)
+			assert.Equal(t, tt.expectedCode, appErr.Code)
+			assert.Equal(t, tt.expectedProvider, appErr.ProviderCode)
+			assert.Equal(t, tt.expectedUpstream, appErr.UpstreamStatus)
+			assert.Equal(t, tt.expectedRetryable, appErr.Retryable)
+			assert.Equal(t, tt.expectedRetryable, IsRetryable(appErr))
+		})
+	}
+}
+
+func TestExternalServiceError_KeepsProviderClassification(t *testing.T) {
+	upstream := ExternalServiceError("failed to send email via SES", awsResponseError(http.StatusBadRequest, "Throttling")).
+		WithProvider(constants.EmailProviderSES)
+
+	wrapped := ExternalServiceError("Failed to send welcome email", upstream)
+
+	assert.Equal(t, http.StatusTooManyRequests, wrapped.HTTPStatus)
+	assert.Equal(t, constants.ExternalServiceRateLimited, wrapped.Code)
+	assert.Equal(t, constants.EmailProviderSES, wrapped.Provider)
+	assert.Equal(t, "Throttling", wrapped.ProviderCode)
+	assert.True(t, wrapped.Retryable)
+}
+
+func TestAppError_WithProviderIgnoresNonExternalErrors(t *testing.T) {
+	appErr := ValidationError("bad input", fmt.Errorf("boom")).WithProvider(constants.StorageProviderS3)
+
+	assert.Equal(t, constants.StorageProviderS3, appErr.Provider)
+	assert.Equal(t, http.StatusBadRequest, appErr.HTTPStatus)
+	assert.False(t, appErr.Retryable)
+}
@@ -0,0 +1,100 @@
+package factory
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyFactory builds API keys along with their raw key
+type APIKeyFactory struct {
+	key    models.APIKey
+	rawKey string
+}
+
+// APIKey starts an active key of company with a random secret
+func APIKey(company *models.Company) *APIKeyFactory {
+	f := &APIKeyFactory{key: models.APIKey{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: company.ID,
+		Name:      fmt.Sprintf("Key %d", next()),
+	}}
+	return f.withSecret(constants.APIKeyPrefix)
+}
+
+// withSecret generates the raw key with prefix and sets its stored form
+func (f *APIKeyFactory) withSecret(prefix string) *APIKeyFactory {
+	secret := make([]byte, constants.APIKeySecretSize)
+	_, _ = rand.Read(secret)
+	f.rawKey = prefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	sum := sha256.Sum256([]byte(f.rawKey))
+	f.key.KeyHash = hex.EncodeToString(sum[:])
+	f.key.Prefix = f.rawKey[:constants.APIKeyDisplayLength+len(prefix)-len(constants.APIKeyPrefix)]
+	return f
+}
+
+func (f *APIKeyFactory) WithName(name string) *APIKeyFactory {
+	f.key.Name = name
+	return f
+}
+
+// Sandbox makes it a sandbox key, of the sandbox tenant the key was started with
+func (f *APIKeyFactory) Sandbox() *APIKeyFactory {
+	f.key.Sandbox = true
+	return f.withSecret(constants.APIKeySandboxPrefix)
+}
+
+// CreatedAt sets the creation time of the key, from which an unused key is reported
+func (f *APIKeyFactory) CreatedAt(createdAt time.Time) *APIKeyFactory {
+	f.key.CreatedAt = createdAt
+	return f
+}
+
+func (f *APIKeyFactory) ExpiresAt(expiresAt time.Time) *APIKeyFactory {
+	f.key.ExpiresAt = &expiresAt
+	return f
+}
+
+func (f *APIKeyFactory) RevokedAt(revokedAt time.Time) *APIKeyFactory {
+	f.key.RevokedAt = &revokedAt
+	return f
+}
+
+func (f *APIKeyFactory) LastUsedAt(lastUsedAt time.Time) *APIKeyFactory {
+	f.key.LastUsedAt = &lastUsedAt
+	return f
+}
+
+func (f *APIKeyFactory) UnusedAlertedAt(alertedAt time.Time) *APIKeyFactory {
+	f.key.UnusedAlertedAt = &alertedAt
+	return f
+}
+
+// RawKey returns the key clients authenticate with, whose hash is stored
+func (f *APIKeyFactory) RawKey() string {
+	return f.rawKey
+}
+
+// Build returns the key without saving it
+func (f *APIKeyFactory) Build() *models.APIKey {
+	key := f.key
+	return &key
+}
+
+// Create saves the key; its company must be saved
+func (f *APIKeyFactory) Create(db *gorm.DB) (*models.APIKey, error) {
+	key := f.Build()
+	if err := create(db, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,98 @@
+package factory
+
+import (
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CompanyFactory builds companies
+type CompanyFactory struct {
+	company models.Company
+}
+
+// Company starts a company with a unique name and Keycloak organization
+func Company() *CompanyFactory {
+	return &CompanyFactory{company: models.Company{
+		BaseModel:  models.NewBaseModel(),
+		Name:       fmt.Sprintf("%s %s %d", pick(adjectives), pick(nouns), next()),
+		KeycloakID: newUUID(),
+	}}
+}
+
+// WithID sets the ID of the company, for the tests that look it up by a fixed ID
+func (f *CompanyFactory) WithID(id string) *CompanyFactory {
+	f.company.ID = id
+	return f
+}
+
+func (f *CompanyFactory) WithName(name string) *CompanyFactory {
+	f.company.Name = name
+	return f
+}
+
+func (f *CompanyFactory) WithKeycloakID(keycloakID string) *CompanyFactory {
+	f.company.KeycloakID = keycloakID
+	return f
+}
+
+// WithLogo sets the key and the size of the stored logo
+func (f *CompanyFactory) WithLogo(key string, size int64) *CompanyFactory {
+	f.company.LogoKey = key
+	f.company.LogoSize = size
+	return f
+}
+
+// AsTenant sets the slug, the storage prefix and the default roles of a tenant created
+// by the provisioning API
+func (f *CompanyFactory) AsTenant(slug string, storagePrefix string, defaultRoles ...string) *CompanyFactory {
+	f.company.Slug = &slug
+	f.company.StoragePrefix = storagePrefix
+	f.company.DefaultRoles = defaultRoles
+	return f
+}
+
+func (f *CompanyFactory) WithUploadPolicy(policy *models.UploadPolicy) *CompanyFactory {
+	f.company.UploadPolicy = policy
+	return f
+}
+
+// SandboxOf makes the company the sandbox tenant of parent
+func (f *CompanyFactory) SandboxOf(parent *models.Company) *CompanyFactory {
+	f.company.SandboxOfID = &parent.ID
+	return f
+}
+
+// OnLegalHold places the company on legal hold by the Keycloak user heldBy
+func (f *CompanyFactory) OnLegalHold(reason string, heldBy string) *CompanyFactory {
+	now := time.Now().UTC()
+	f.company.LegalHold = models.LegalHold{LegalHoldAt: &now, LegalHoldBy: heldBy, LegalHoldReason: reason}
+	return f
+}
+
+// Build returns the company without saving it
+func (f *CompanyFactory) Build() *models.Company {
+	company := f.company
+	return &company
+}
+
+// Create saves the company
+func (f *CompanyFactory) Create(db *gorm.DB) (*models.Company, error) {
+	company := f.Build()
+	if err := create(db, company); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// CreateRequest returns the request creating the company
+func (f *CompanyFactory) CreateRequest() *dtos.CreateCompanyRequest {
+	return &dtos.CreateCompanyRequest{CompanyRequest: dtos.CompanyRequest{
+		Name:       f.company.Name,
+		KeycloakID: f.company.KeycloakID,
+	}}
+}
@@ -0,0 +1,49 @@
+// Package factory builds models and request DTOs for tests, with randomized defaults that
+// satisfy the constraints of the schema and of the request validation, so that tests only
+// set the fields they are about:
+//
+//	company := factory.Company().Build()
+//	user, err := factory.User().WithCompany(company).Create(db)
+//
+// Build returns the model without touching the database, Create also inserts it. Unique
+// columns, such as emails and Keycloak IDs, get a value of their own on every build.
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	firstNames = []string{"Alice", "Bruno", "Chloe", "David", "Emma", "Farid", "Grace", "Hugo", "Ines", "Jonas"}
+	lastNames  = []string{"Martin", "Nguyen", "Smith", "Garcia", "Muller", "Rossi", "Kowalski", "Silva", "Dubois", "Tanaka"}
+	adjectives = []string{"Blue", "Bright", "Northern", "Rapid", "Silent", "Golden", "Green", "Swift"}
+	nouns      = []string{"Labs", "Systems", "Works", "Logistics", "Analytics", "Foods", "Studio", "Partners"}
+)
+
+// sequence numbers the unique values built by the factories
+var sequence atomic.Int64
+
+func next() int64 {
+	return sequence.Add(1)
+}
+
+func pick(values []string) string {
+	return values[rand.IntN(len(values))]
+}
+
+func newUUID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// create inserts value, whose type is named in the error
+func create(db *gorm.DB, value any) error {
+	if err := db.Create(value).Error; err != nil {
+		return fmt.Errorf("create %T: %w", value, err)
+	}
+	return nil
+}
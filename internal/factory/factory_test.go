@@ -0,0 +1,96 @@
+package factory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_UniqueValues(t *testing.T) {
+	first, second := User().Build(), User().Build()
+
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.NotEqual(t, first.Email, second.Email)
+	assert.NotEqual(t, first.KeycloakID, second.KeycloakID)
+}
+
+func TestUser_WithCompany(t *testing.T) {
+	company := Company().Build()
+	factory := User().WithCompany(company)
+
+	user := factory.Build()
+	require.Len(t, user.Companies, 1)
+	assert.Equal(t, company.ID, user.Companies[0].ID)
+
+	// Built users do not share their companies
+	user.Companies[0].Name = "Changed"
+	assert.Equal(t, company.Name, factory.Build().Companies[0].Name)
+
+	req := factory.CreateRequest()
+	require.Len(t, req.Companies, 1)
+	assert.Equal(t, company.ID, req.Companies[0].ID)
+}
+
+func TestRequests_PassValidation(t *testing.T) {
+	validate := validator.New()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, validate.Struct(User().WithCompany(Company().Build()).CreateRequest()))
+		require.NoError(t, validate.Struct(Company().CreateRequest()))
+	}
+}
+
+func TestCompany_Defaults(t *testing.T) {
+	parent := Company().Build()
+	sandbox := Company().SandboxOf(parent).OnLegalHold("audit", "admin-1").Build()
+
+	_, err := uuid.Parse(sandbox.KeycloakID)
+	assert.NoError(t, err)
+	require.NotNil(t, sandbox.SandboxOfID)
+	assert.Equal(t, parent.ID, *sandbox.SandboxOfID)
+	assert.True(t, sandbox.OnLegalHold())
+	assert.False(t, parent.OnLegalHold())
+}
+
+func TestAPIKey_RawKeyMatchesHash(t *testing.T) {
+	company := Company().Build()
+
+	tests := []struct {
+		name           string
+		factory        *APIKeyFactory
+		expectedPrefix string
+	}{
+		{name: "production key", factory: APIKey(company), expectedPrefix: constants.APIKeyPrefix},
+		{name: "sandbox key", factory: APIKey(company).Sandbox(), expectedPrefix: constants.APIKeySandboxPrefix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.factory.Build()
+			rawKey := tt.factory.RawKey()
+
+			sum := sha256.Sum256([]byte(rawKey))
+			assert.Equal(t, hex.EncodeToString(sum[:]), key.KeyHash)
+			assert.True(t, strings.HasPrefix(rawKey, tt.expectedPrefix))
+			assert.True(t, strings.HasPrefix(rawKey, key.Prefix))
+			assert.Equal(t, company.ID, key.CompanyID)
+		})
+	}
+}
+
+func TestFile_WithStatus(t *testing.T) {
+	file := File().WithStatus(constants.FileStatusQuarantined, "infected").Build()
+
+	assert.Equal(t, constants.FileStatusQuarantined, file.Status)
+	require.NotNil(t, file.StatusReason)
+	assert.Equal(t, "infected", *file.StatusReason)
+	assert.Equal(t, constants.FileStatusAvailable, File().Build().Status)
+}
@@ -0,0 +1,76 @@
+package factory
+
+import (
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FileFactory builds registered files
+type FileFactory struct {
+	file models.File
+}
+
+// File starts an available PNG file under a unique key
+func File() *FileFactory {
+	return &FileFactory{file: models.File{
+		BaseModel:       models.NewBaseModel(),
+		Key:             fmt.Sprintf("files/%d/%s.png", next(), newUUID()),
+		ContentType:     "image/png",
+		Size:            1024,
+		Status:          constants.FileStatusAvailable,
+		StatusChangedAt: time.Now().UTC(),
+	}}
+}
+
+func (f *FileFactory) WithKey(key string) *FileFactory {
+	f.file.Key = key
+	return f
+}
+
+// WithOwner sets the user or company the file belongs to
+func (f *FileFactory) WithOwner(ownerID string) *FileFactory {
+	f.file.OwnerID = &ownerID
+	return f
+}
+
+// WithChecksum sets the hex SHA-256 of the content of the file
+func (f *FileFactory) WithChecksum(checksum string) *FileFactory {
+	f.file.Checksum = checksum
+	return f
+}
+
+func (f *FileFactory) WithContent(contentType string, size int64) *FileFactory {
+	f.file.ContentType = contentType
+	f.file.Size = size
+	return f
+}
+
+// WithStatus sets the status of the file, with the reason of a failed or quarantined file
+func (f *FileFactory) WithStatus(status string, reason string) *FileFactory {
+	f.file.Status = status
+	f.file.StatusReason = nil
+	if reason != "" {
+		f.file.StatusReason = &reason
+	}
+	return f
+}
+
+// Build returns the file without saving it
+func (f *FileFactory) Build() *models.File {
+	file := f.file
+	return &file
+}
+
+// Create saves the file
+func (f *FileFactory) Create(db *gorm.DB) (*models.File, error) {
+	file := f.Build()
+	if err := create(db, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
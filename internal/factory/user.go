@@ -0,0 +1,115 @@
+package factory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UserFactory builds users
+type UserFactory struct {
+	user models.User
+}
+
+// User starts a user with a random name, and a unique email and Keycloak user
+func User() *UserFactory {
+	firstName, lastName := pick(firstNames), pick(lastNames)
+	return &UserFactory{user: models.User{
+		BaseModel:  models.NewBaseModel(),
+		FirstName:  firstName,
+		LastName:   lastName,
+		Email:      fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(firstName), strings.ToLower(lastName), next()),
+		KeycloakID: newUUID(),
+	}}
+}
+
+// WithID sets the ID of the user, for the tests that look it up by a fixed ID
+func (f *UserFactory) WithID(id string) *UserFactory {
+	f.user.ID = id
+	return f
+}
+
+func (f *UserFactory) WithName(firstName string, lastName string) *UserFactory {
+	f.user.FirstName = firstName
+	f.user.LastName = lastName
+	return f
+}
+
+func (f *UserFactory) WithEmail(email string) *UserFactory {
+	f.user.Email = email
+	return f
+}
+
+func (f *UserFactory) WithKeycloakID(keycloakID string) *UserFactory {
+	f.user.KeycloakID = keycloakID
+	return f
+}
+
+func (f *UserFactory) WithStripeCustomerID(customerID string) *UserFactory {
+	f.user.StripeCustomerID = customerID
+	return f
+}
+
+// WithAvatar sets the key and the size of the stored avatar
+func (f *UserFactory) WithAvatar(key string, size int64) *UserFactory {
+	f.user.AvatarKey = key
+	f.user.AvatarSize = size
+	return f
+}
+
+// WithCreatedAt sets the creation time of the user, which orders the members of a company
+func (f *UserFactory) WithCreatedAt(createdAt time.Time) *UserFactory {
+	f.user.CreatedAt = createdAt
+	return f
+}
+
+// WithRoles grants roles to the user
+func (f *UserFactory) WithRoles(roles ...models.Role) *UserFactory {
+	f.user.Roles = append(f.user.Roles, roles...)
+	return f
+}
+
+// WithCompany adds the user to company; Create saves the company too when it is not saved
+// yet
+func (f *UserFactory) WithCompany(company *models.Company) *UserFactory {
+	f.user.Companies = append(f.user.Companies, *company)
+	return f
+}
+
+// Build returns the user without saving it
+func (f *UserFactory) Build() *models.User {
+	user := f.user
+	user.Companies = append([]models.Company(nil), f.user.Companies...)
+	user.Roles = append([]models.Role(nil), f.user.Roles...)
+	return &user
+}
+
+// Create saves the user with its companies
+func (f *UserFactory) Create(db *gorm.DB) (*models.User, error) {
+	user := f.Build()
+	if err := create(db, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateRequest returns the request creating the user in its companies
+func (f *UserFactory) CreateRequest() *dtos.CreateUserRequest {
+	companies := make([]dtos.UpdateCompanyRequest, len(f.user.Companies))
+	for i, company := range f.user.Companies {
+		companies[i] = dtos.UpdateCompanyRequest{ID: company.ID}
+	}
+
+	return &dtos.CreateUserRequest{UserRequest: dtos.UserRequest{
+		Email:      f.user.Email,
+		FirstName:  f.user.FirstName,
+		LastName:   f.user.LastName,
+		KeycloakID: f.user.KeycloakID,
+		Companies:  companies,
+	}}
+}
@@ -0,0 +1,450 @@
+// Package files tracks the objects stored through the storage adapter in the file
+// registry. A file goes through pending, while its object is uploaded, and scanning by
+// the virus scanner before it is available; a failed upload or scan is failed and an
+// infected file quarantined.
+// URLs are only issued for available files, so that consumers never link to a half
+// uploaded or infected object.
+package files
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"hash"
+	"io"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/antivirus"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// Registry stores files and tracks their status
+type Registry interface {
+	// Upload registers the file of the user or company ownerID under key, with its bucket
+	// and checksum, uploads its object, scans it and makes it available. The file is
+	// failed when the upload or the scan fails or the uploaded content does not match its
+	// checksum, and quarantined with an errors.InfectedFileError when it is infected.
+	Upload(ctx context.Context, file *multipart.FileHeader, key string, contentType string, ownerID string) (*models.File, error)
+	// Register registers the file of ownerID under key, of its declared size, pending
+	// while the client uploads its object straight to the storage, until Complete
+	Register(ctx context.Context, key string, contentType string, size int64, ownerID string) (*models.File, error)
+	// Complete completes a registered file once its object is uploaded: it reads the
+	// object, scanning it and recording its size and checksum, and makes the file available
+	// as Upload does, quarantined with an errors.InfectedFileError when it is infected. An
+	// object not uploaded yet is a conflict and leaves the file pending; a file that cannot
+	// be read or scanned is failed.
+	Complete(ctx context.Context, file *models.File) error
+	Get(ctx context.Context, key string) (*models.File, error)
+	GetByID(ctx context.Context, id string) (*models.File, error)
+	// List returns the files matching the filters, most recent first
+	List(ctx context.Context, pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error)
+	// Transition moves the file to status, with the reason of a failed or quarantined
+	// file. A transition constants.FileStatusTransitions does not allow is a conflict.
+	Transition(ctx context.Context, file *models.File, status string, reason string) error
+	// PresignedURL returns a URL of the object of an available file, a conflict otherwise
+	PresignedURL(ctx context.Context, file *models.File, duration time.Duration) (string, error)
+	// Open streams length bytes of the object of an available file from offset, up to its
+	// end when length is negative; a file that is not available is a conflict
+	Open(ctx context.Context, file *models.File, offset int64, length int64) (io.ReadCloser, error)
+	// Delete deletes the object of key and its file. An object without a file is still
+	// deleted.
+	Delete(ctx context.Context, key string) error
+}
+
+// registry implements Registry
+type registry struct {
+	repo    repositories.FileRepository
+	storage storage.StorageAdapter
+	broker  messaging.Publisher
+	scanner antivirus.Scanner
+}
+
+// ProvideRegistry creates the file registry
+func ProvideRegistry(repo repositories.FileRepository, storage storage.StorageAdapter, broker messaging.Publisher, scanner antivirus.Scanner) Registry {
+	return &registry{
+		repo:    repo,
+		storage: storage,
+		broker:  broker,
+		scanner: scanner,
+	}
+}
+
+func (r *registry) Upload(ctx context.Context, fileHeader *multipart.FileHeader, key string, contentType string, ownerID string) (*models.File, error) {
+	checksum, err := fileChecksum(fileHeader)
+	if err != nil {
+		return nil, errors.InternalError("Failed to read file", err).
+			WithOperation("upload_file").
+			WithResource("file").
+			WithContext("key", key)
+	}
+
+	file := &models.File{
+		BaseModel:       models.NewBaseModel(),
+		Key:             key,
+		Bucket:          r.storage.Bucket(),
+		ContentType:     contentType,
+		Size:            fileHeader.Size,
+		Checksum:        checksum,
+		Status:          constants.FileStatusPending,
+		StatusChangedAt: time.Now().UTC(),
+	}
+	if ownerID != "" {
+		file.OwnerID = &ownerID
+	}
+	if err := r.repo.Create(file); err != nil {
+		return nil, err
+	}
+
+	result, err := r.storage.UploadFile(ctx, fileHeader, key)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == constants.ChecksumMismatch {
+		r.settle(ctx, file, constants.FileStatusFailed, "checksum mismatch")
+		return nil, err
+	}
+	if err != nil {
+		r.settle(ctx, file, constants.FileStatusFailed, "upload failed")
+
+		return nil, errors.ExternalServiceError("Failed to upload file", err).
+			WithOperation("upload_file").
+			WithResource("file").
+			WithContext("key", key)
+	}
+	// The content changed since the file was registered
+	if result.Checksum != file.Checksum {
+		r.settle(ctx, file, constants.FileStatusFailed, "checksum mismatch")
+
+		return nil, errors.ChecksumMismatchError("Uploaded file does not match its checksum", nil).
+			WithOperation("upload_file").
+			WithResource("file").
+			WithContext("key", key).
+			WithContext("checksum", file.Checksum).
+			WithContext("uploaded_checksum", result.Checksum)
+	}
+
+	if err := r.Transition(ctx, file, constants.FileStatusScanning, ""); err != nil {
+		return nil, err
+	}
+	if err := r.scan(ctx, file, fileHeader); err != nil {
+		return nil, err
+	}
+	if err := r.Transition(ctx, file, constants.FileStatusAvailable, ""); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (r *registry) Register(ctx context.Context, key string, contentType string, size int64, ownerID string) (*models.File, error) {
+	file := &models.File{
+		BaseModel:       models.NewBaseModel(),
+		Key:             key,
+		Bucket:          r.storage.Bucket(),
+		ContentType:     contentType,
+		Size:            size,
+		Status:          constants.FileStatusPending,
+		StatusChangedAt: time.Now().UTC(),
+	}
+	if ownerID != "" {
+		file.OwnerID = &ownerID
+	}
+	if err := r.repo.Create(file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (r *registry) Complete(ctx context.Context, file *models.File) error {
+	if file.Status != constants.FileStatusPending {
+		return errors.ConflictError("File is already completed", nil).
+			WithOperation("complete_file").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("status", file.Status)
+	}
+
+	content, err := r.storage.OpenFile(ctx, file.Key)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		return errors.ConflictError("File is not uploaded yet", err).
+			WithOperation("complete_file").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+	if err != nil {
+		return errors.ExternalServiceError("Failed to read file", err).
+			WithOperation("complete_file").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+	defer content.Close()
+
+	// Moving the file out of pending first keeps a concurrent completion from scanning it
+	// again
+	if err := r.Transition(ctx, file, constants.FileStatusScanning, ""); err != nil {
+		return err
+	}
+
+	// The object is hashed as the scanner reads it, then up to its end, as the scanner may
+	// not read all of it
+	object := &objectReader{Reader: content, hash: sha256.New()}
+	result, err := r.scanner.Scan(ctx, object)
+	if err == nil {
+		_, err = io.Copy(io.Discard, object)
+	}
+	if object.err != nil {
+		r.settle(ctx, file, constants.FileStatusFailed, "read failed")
+
+		return errors.ExternalServiceError("Failed to read file", object.err).
+			WithOperation("complete_file").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+	if err != nil {
+		return r.scanFailed(ctx, file, err)
+	}
+
+	// The content of an infected file is recorded too, for its review
+	file.Size = object.size
+	file.Checksum = hex.EncodeToString(object.hash.Sum(nil))
+	if err := r.repo.UpdateContent(file); err != nil {
+		r.settle(ctx, file, constants.FileStatusFailed, "read failed")
+		return err
+	}
+	if result.Infected {
+		return r.quarantine(ctx, file, result.Signature)
+	}
+
+	return r.Transition(ctx, file, constants.FileStatusAvailable, "")
+}
+
+// objectReader hashes and counts the content of an object as it is read, and keeps the
+// error of a failed read apart from the errors of its consumer
+type objectReader struct {
+	io.Reader
+	hash hash.Hash
+	size int64
+	err  error
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.hash.Write(p[:n])
+	r.size += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// fileChecksum returns the hex SHA-256 of the content of an uploaded file
+func fileChecksum(fileHeader *multipart.FileHeader) (string, error) {
+	content, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// scan scans the content of a file being uploaded, from the upload rather than from the
+// storage. A file that could not be scanned is failed, as it cannot be made available
+// unscanned, and an infected one quarantined; its object is kept for review.
+func (r *registry) scan(ctx context.Context, file *models.File, fileHeader *multipart.FileHeader) error {
+	content, err := fileHeader.Open()
+	var result *antivirus.Result
+	if err == nil {
+		result, err = r.scanner.Scan(ctx, content)
+		content.Close()
+	}
+	if err != nil {
+		return r.scanFailed(ctx, file, err)
+	}
+
+	if result.Infected {
+		return r.quarantine(ctx, file, result.Signature)
+	}
+
+	return nil
+}
+
+// scanFailed fails a file that could not be scanned
+func (r *registry) scanFailed(ctx context.Context, file *models.File, err error) error {
+	r.settle(ctx, file, constants.FileStatusFailed, "scan failed")
+
+	return errors.ExternalServiceError("Failed to scan file", err).
+		WithOperation("scan_file").
+		WithResource("file").
+		WithContext("key", file.Key)
+}
+
+// quarantine quarantines an infected file, returning its errors.InfectedFileError
+func (r *registry) quarantine(ctx context.Context, file *models.File, signature string) error {
+	r.settle(ctx, file, constants.FileStatusQuarantined, "infected: "+signature)
+
+	logger.Log.Warn("Infected file quarantined",
+		zap.String("file_id", file.ID),
+		zap.String("key", file.Key),
+		zap.String("signature", signature),
+	)
+	return errors.InfectedFileError("File is infected", nil).
+		WithOperation("scan_file").
+		WithResource("file").
+		WithContext("file_id", file.ID).
+		WithContext("signature", signature)
+}
+
+// settle moves a file whose upload did not succeed to its final status. The upload fails
+// either way, so a failed transition is only logged.
+func (r *registry) settle(ctx context.Context, file *models.File, status string, reason string) {
+	if err := r.Transition(ctx, file, status, reason); err != nil {
+		logger.Log.Warn("Failed to settle file status",
+			zap.String("key", file.Key),
+			zap.String("status", status),
+			zap.Error(err),
+		)
+	}
+}
+
+func (r *registry) Get(ctx context.Context, key string) (*models.File, error) {
+	return r.repo.GetByKey(key)
+}
+
+func (r *registry) GetByID(ctx context.Context, id string) (*models.File, error) {
+	return r.repo.GetByID(id)
+}
+
+func (r *registry) List(ctx context.Context, pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error) {
+	return r.repo.List(pr)
+}
+
+func (r *registry) Transition(ctx context.Context, file *models.File, status string, reason string) error {
+	from := file.Status
+	if !slices.Contains(constants.FileStatusTransitions[from], status) {
+		return errors.ConflictError("File status cannot change", nil).
+			WithOperation("transition_file").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("from", from).
+			WithContext("to", status)
+	}
+
+	next := *file
+	next.Status = status
+	next.StatusReason = nil
+	if reason != "" {
+		next.StatusReason = &reason
+	}
+	next.StatusChangedAt = time.Now().UTC()
+
+	updated, err := r.repo.UpdateStatus(&next, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.ConflictError("File status changed concurrently", nil).
+			WithOperation("transition_file").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("from", from).
+			WithContext("to", status)
+	}
+	*file = next
+
+	r.publishStatusChanged(ctx, file, from)
+	return nil
+}
+
+// publishStatusChanged publishes the status change of a file to the message broker. The
+// change is already saved, so a failure is only logged.
+func (r *registry) publishStatusChanged(ctx context.Context, file *models.File, from string) {
+	event := dtos.FileStatusChangedEvent{
+		FileID:    file.ID,
+		Key:       file.Key,
+		From:      from,
+		To:        file.Status,
+		ChangedAt: file.StatusChangedAt,
+	}
+	if file.StatusReason != nil {
+		event.Reason = *file.StatusReason
+	}
+
+	body, err := json.Marshal(event)
+	if err == nil {
+		err = r.broker.Publish(ctx, constants.MessagingTopicFileStatusChanged, messaging.Message{Body: body})
+	}
+	if err != nil && !stderrors.Is(err, messaging.ErrDisabled) {
+		logger.Log.Warn("Failed to publish file status change",
+			zap.String("file_id", file.ID),
+			zap.String("status", file.Status),
+			zap.Error(err),
+		)
+	}
+}
+
+func (r *registry) PresignedURL(ctx context.Context, file *models.File, duration time.Duration) (string, error) {
+	if file.Status != constants.FileStatusAvailable {
+		return "", errors.ConflictError("File is not available", nil).
+			WithOperation("presign_file").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("status", file.Status)
+	}
+
+	url, err := r.storage.GetPresignedURL(ctx, file.Key, duration)
+	if err != nil {
+		return "", errors.ExternalServiceError("Failed to generate file URL", err).
+			WithOperation("presign_file").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+
+	return url, nil
+}
+
+func (r *registry) Open(ctx context.Context, file *models.File, offset int64, length int64) (io.ReadCloser, error) {
+	if file.Status != constants.FileStatusAvailable {
+		return nil, errors.ConflictError("File is not available", nil).
+			WithOperation("open_file").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("status", file.Status)
+	}
+
+	return r.storage.OpenFileRange(ctx, file.Key, offset, length)
+}
+
+func (r *registry) Delete(ctx context.Context, key string) error {
+	if err := r.storage.DeleteFile(ctx, key); err != nil {
+		return errors.ExternalServiceError("Failed to delete file", err).
+			WithOperation("delete_file").
+			WithResource("file").
+			WithContext("key", key)
+	}
+
+	file, err := r.repo.GetByKey(key)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return r.repo.Delete(file)
+}
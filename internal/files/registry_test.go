@@ -0,0 +1,563 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/antivirus"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// MockFileRepository is a mock implementation of repositories.FileRepository
+type MockFileRepository struct {
+	mock.Mock
+}
+
+func (m *MockFileRepository) Create(file *models.File) error {
+	args := m.Called(file)
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) GetByID(id string) (*models.File, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRepository) GetByKey(key string) (*models.File, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRepository) List(pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error) {
+	args := m.Called(pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.File]), args.Error(1)
+}
+
+func (m *MockFileRepository) UpdateStatus(file *models.File, from string) (bool, error) {
+	args := m.Called(file.Status, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFileRepository) UpdateContent(file *models.File) error {
+	return m.Called(file.Size, file.Checksum).Error(0)
+}
+
+func (m *MockFileRepository) Delete(file *models.File) error {
+	args := m.Called(file)
+	return args.Error(0)
+}
+
+// MockStorageAdapter is a mock implementation of storage.StorageAdapter
+type MockStorageAdapter struct {
+	mock.Mock
+}
+
+func (m *MockStorageAdapter) UploadFile(ctx context.Context, file *multipart.FileHeader, key string) (*storage.UploadResult, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.UploadResult), args.Error(1)
+}
+
+func (m *MockStorageAdapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*storage.BatchUploadResult, error) {
+	return nil, m.Called(files).Error(0)
+}
+
+func (m *MockStorageAdapter) Bucket() string {
+	return m.Called().String(0)
+}
+
+func (m *MockStorageAdapter) GetObjectURL(key string) string {
+	return m.Called(key).String(0)
+}
+
+func (m *MockStorageAdapter) GetPresignedURL(ctx context.Context, key string, duration ...time.Duration) (string, error) {
+	args := m.Called(key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageAdapter) PresignUpload(ctx context.Context, key string, contentType string, maxSize int64) (*storage.PresignedUpload, error) {
+	args := m.Called(key, contentType, maxSize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.PresignedUpload), args.Error(1)
+}
+
+func (m *MockStorageAdapter) DeleteFile(ctx context.Context, key string) error {
+	return m.Called(key).Error(0)
+}
+
+func (m *MockStorageAdapter) DeleteFiles(ctx context.Context, keys []string) error {
+	return m.Called(keys).Error(0)
+}
+
+func (m *MockStorageAdapter) ListObjects(ctx context.Context, prefix string, cursor string, limit int) (*storage.ObjectPage, error) {
+	args := m.Called(prefix, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.ObjectPage), args.Error(1)
+}
+
+func (m *MockStorageAdapter) HeadObject(ctx context.Context, key string) (*storage.ObjectMetadata, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.ObjectMetadata), args.Error(1)
+}
+
+func (m *MockStorageAdapter) CopyObject(ctx context.Context, srcKey string, dstKey string) error {
+	return m.Called(srcKey, dstKey).Error(0)
+}
+
+func (m *MockStorageAdapter) MoveObject(ctx context.Context, srcKey string, dstKey string) error {
+	return m.Called(srcKey, dstKey).Error(0)
+}
+
+func (m *MockStorageAdapter) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorageAdapter) OpenFileRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	args := m.Called(key, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorageAdapter) Ping(ctx context.Context) error {
+	return m.Called().Error(0)
+}
+
+// recordingPublisher records the status changes published to the broker
+type recordingPublisher struct {
+	events []dtos.FileStatusChangedEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, msg messaging.Message) error {
+	var event dtos.FileStatusChangedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) transitions() []string {
+	transitions := make([]string, len(p.events))
+	for i, event := range p.events {
+		transitions[i] = event.From + " -> " + event.To
+	}
+	return transitions
+}
+
+// MockScanner is a mock implementation of antivirus.Scanner
+type MockScanner struct {
+	mock.Mock
+}
+
+func (m *MockScanner) Scan(ctx context.Context, content io.Reader) (*antivirus.Result, error) {
+	data, _ := io.ReadAll(content)
+	args := m.Called(string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*antivirus.Result), args.Error(1)
+}
+
+func (m *MockScanner) Ping(ctx context.Context) error {
+	return m.Called().Error(0)
+}
+
+// newFileHeader returns the header of an uploaded file holding content
+func newFileHeader(t *testing.T, content string) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "a.png")
+	require.NoError(t, err)
+	part.Write([]byte(content))
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+// pngContentChecksum is the SHA-256 of the content of the uploaded files
+const pngContentChecksum = "47c5980ff911e17a2e60e068e79fbfc52c7f36e518a38a37e4dfc69650138bd7"
+
+func TestRegistry_Upload(t *testing.T) {
+	tests := []struct {
+		name                string
+		uploadErr           error
+		uploadChecksum      string
+		scanResult          *antivirus.Result
+		scanErr             error
+		expectedError       errors.ErrorType
+		expectedTransitions []string
+		expectedReason      string
+	}{
+		{
+			name:                "success - clean file is available",
+			scanResult:          &antivirus.Result{},
+			expectedTransitions: []string{"pending -> scanning", "scanning -> available"},
+		},
+		{
+			name:                "error - failed upload fails the file",
+			uploadErr:           assert.AnError,
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> failed"},
+			expectedReason:      "upload failed",
+		},
+		{
+			name:                "error - corrupted upload fails the file",
+			uploadErr:           errors.ChecksumMismatchError("uploaded content does not match its checksum", assert.AnError),
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> failed"},
+			expectedReason:      "checksum mismatch",
+		},
+		{
+			name:                "error - content changed since its registration fails the file",
+			uploadChecksum:      "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> failed"},
+			expectedReason:      "checksum mismatch",
+		},
+		{
+			name:                "error - infected file is quarantined",
+			scanResult:          &antivirus.Result{Infected: true, Signature: "Win.Test.EICAR_HDB-1"},
+			expectedError:       errors.ErrorTypeValidation,
+			expectedTransitions: []string{"pending -> scanning", "scanning -> quarantined"},
+		},
+		{
+			name:                "error - failed scan fails the file",
+			scanErr:             assert.AnError,
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> scanning", "scanning -> failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFileRepository)
+			storageAdapter := new(MockStorageAdapter)
+			scanner := new(MockScanner)
+			publisher := &recordingPublisher{}
+			registry := ProvideRegistry(repo, storageAdapter, publisher, scanner)
+			fileHeader := newFileHeader(t, "png content")
+
+			repo.On("Create", mock.MatchedBy(func(file *models.File) bool {
+				return file.Key == "avatars/1/a.png" && file.Status == constants.FileStatusPending && file.Size == fileHeader.Size &&
+					file.Bucket == "my-bucket" && *file.OwnerID == "user-1" && file.Checksum == pngContentChecksum
+			})).Return(nil)
+			storageAdapter.On("Bucket").Return("my-bucket")
+			repo.On("UpdateStatus", mock.Anything, mock.Anything).Return(true, nil)
+			if tt.uploadErr != nil {
+				storageAdapter.On("UploadFile", "avatars/1/a.png").Return(nil, tt.uploadErr)
+			} else {
+				uploadChecksum := pngContentChecksum
+				if tt.uploadChecksum != "" {
+					uploadChecksum = tt.uploadChecksum
+				}
+				storageAdapter.On("UploadFile", "avatars/1/a.png").Return(&storage.UploadResult{Checksum: uploadChecksum}, nil)
+			}
+			scanner.On("Scan", "png content").Return(tt.scanResult, tt.scanErr).Maybe()
+
+			file, err := registry.Upload(context.Background(), fileHeader, "avatars/1/a.png", "image/png", "user-1")
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				assert.Nil(t, file)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, constants.FileStatusAvailable, file.Status)
+			}
+			assert.Equal(t, tt.expectedTransitions, publisher.transitions())
+			if tt.expectedReason != "" {
+				assert.Equal(t, tt.expectedReason, publisher.events[len(publisher.events)-1].Reason)
+			}
+			if tt.scanResult != nil && tt.scanResult.Infected {
+				appErr := errors.GetAppError(err)
+				assert.Equal(t, http.StatusUnprocessableEntity, appErr.HTTPStatus)
+				assert.Equal(t, "Win.Test.EICAR_HDB-1", appErr.Context["signature"])
+				assert.Equal(t, "infected: Win.Test.EICAR_HDB-1", publisher.events[1].Reason)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	repo := new(MockFileRepository)
+	storageAdapter := new(MockStorageAdapter)
+	registry := ProvideRegistry(repo, storageAdapter, &recordingPublisher{}, new(MockScanner))
+	storageAdapter.On("Bucket").Return("my-bucket")
+	repo.On("Create", mock.Anything).Return(nil)
+
+	file, err := registry.Register(context.Background(), "uploads/user-1/a.pdf", "application/pdf", 1<<20, "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "uploads/user-1/a.pdf", file.Key)
+	assert.Equal(t, "my-bucket", file.Bucket)
+	assert.Equal(t, "user-1", *file.OwnerID)
+	assert.Equal(t, int64(1<<20), file.Size, "the declared size until the upload is completed")
+	assert.Empty(t, file.Checksum)
+	assert.Equal(t, constants.FileStatusPending, file.Status)
+	repo.AssertCalled(t, "Create", file)
+}
+
+// unreadScanner finds every content clean without reading it, as the disabled scanner
+type unreadScanner struct{}
+
+func (unreadScanner) Scan(ctx context.Context, content io.Reader) (*antivirus.Result, error) {
+	return &antivirus.Result{}, nil
+}
+
+func (unreadScanner) Ping(ctx context.Context) error {
+	return nil
+}
+
+func TestRegistry_Complete(t *testing.T) {
+	tests := []struct {
+		name                string
+		status              string
+		object              io.Reader
+		openErr             error
+		scanner             antivirus.Scanner
+		scanResult          *antivirus.Result
+		scanErr             error
+		expectedError       errors.ErrorType
+		expectedTransitions []string
+		expectedReason      string
+		expectedContent     bool
+	}{
+		{
+			name:                "success - clean file is available",
+			status:              constants.FileStatusPending,
+			object:              strings.NewReader("png content"),
+			scanResult:          &antivirus.Result{},
+			expectedTransitions: []string{"pending -> scanning", "scanning -> available"},
+			expectedContent:     true,
+		},
+		{
+			name:                "success - content the scanner did not read is hashed",
+			status:              constants.FileStatusPending,
+			object:              strings.NewReader("png content"),
+			scanner:             unreadScanner{},
+			expectedTransitions: []string{"pending -> scanning", "scanning -> available"},
+			expectedContent:     true,
+		},
+		{
+			name:                "error - infected file is quarantined",
+			status:              constants.FileStatusPending,
+			object:              strings.NewReader("png content"),
+			scanResult:          &antivirus.Result{Infected: true, Signature: "Win.Test.EICAR_HDB-1"},
+			expectedError:       errors.ErrorTypeValidation,
+			expectedTransitions: []string{"pending -> scanning", "scanning -> quarantined"},
+			expectedReason:      "infected: Win.Test.EICAR_HDB-1",
+			expectedContent:     true,
+		},
+		{
+			name:                "error - failed scan fails the file",
+			status:              constants.FileStatusPending,
+			object:              strings.NewReader("png content"),
+			scanErr:             assert.AnError,
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> scanning", "scanning -> failed"},
+			expectedReason:      "scan failed",
+		},
+		{
+			name:                "error - failed read fails the file",
+			status:              constants.FileStatusPending,
+			object:              iotest.ErrReader(assert.AnError),
+			scanErr:             assert.AnError,
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{"pending -> scanning", "scanning -> failed"},
+			expectedReason:      "read failed",
+		},
+		{
+			name:                "error - object not uploaded yet leaves the file pending",
+			status:              constants.FileStatusPending,
+			openErr:             errors.NotFoundError("File", nil),
+			expectedError:       errors.ErrorTypeConflict,
+			expectedTransitions: []string{},
+		},
+		{
+			name:                "error - unreachable storage leaves the file pending",
+			status:              constants.FileStatusPending,
+			openErr:             errors.ExternalServiceError("failed to get S3 object", assert.AnError),
+			expectedError:       errors.ErrorTypeExternal,
+			expectedTransitions: []string{},
+		},
+		{
+			name:                "error - completed file",
+			status:              constants.FileStatusAvailable,
+			expectedError:       errors.ErrorTypeConflict,
+			expectedTransitions: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFileRepository)
+			storageAdapter := new(MockStorageAdapter)
+			scanner := tt.scanner
+			if scanner == nil {
+				mockScanner := new(MockScanner)
+				mockScanner.On("Scan", mock.Anything).Return(tt.scanResult, tt.scanErr).Maybe()
+				scanner = mockScanner
+			}
+			publisher := &recordingPublisher{}
+			registry := ProvideRegistry(repo, storageAdapter, publisher, scanner)
+			file := factory.File().WithKey("uploads/user-1/a.png").WithContent("image/png", 1<<20).WithStatus(tt.status, "").Build()
+
+			if tt.openErr != nil {
+				storageAdapter.On("OpenFile", file.Key).Return(nil, tt.openErr)
+			} else {
+				storageAdapter.On("OpenFile", file.Key).Return(io.NopCloser(tt.object), nil).Maybe()
+			}
+			repo.On("UpdateStatus", mock.Anything, mock.Anything).Return(true, nil)
+			repo.On("UpdateContent", int64(len("png content")), pngContentChecksum).Return(nil).Maybe()
+
+			err := registry.Complete(context.Background(), file)
+
+			assert.Equal(t, tt.expectedTransitions, publisher.transitions())
+			if tt.expectedContent {
+				assert.Equal(t, int64(len("png content")), file.Size)
+				assert.Equal(t, pngContentChecksum, file.Checksum)
+				repo.AssertCalled(t, "UpdateContent", file.Size, file.Checksum)
+			} else {
+				repo.AssertNotCalled(t, "UpdateContent", mock.Anything, mock.Anything)
+			}
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				if tt.expectedReason != "" {
+					assert.Equal(t, tt.expectedReason, publisher.events[len(publisher.events)-1].Reason)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, constants.FileStatusAvailable, file.Status)
+		})
+	}
+}
+
+func TestRegistry_Transition(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          string
+		to            string
+		updated       bool
+		expectedError bool
+	}{
+		{name: "success - scanned file is quarantined", from: constants.FileStatusScanning, to: constants.FileStatusQuarantined, updated: true},
+		{name: "success - available file is quarantined", from: constants.FileStatusAvailable, to: constants.FileStatusQuarantined, updated: true},
+		{name: "error - pending file cannot become available unscanned", from: constants.FileStatusPending, to: constants.FileStatusAvailable, expectedError: true},
+		{name: "error - quarantined file stays quarantined", from: constants.FileStatusQuarantined, to: constants.FileStatusAvailable, expectedError: true},
+		{name: "error - concurrent transition", from: constants.FileStatusScanning, to: constants.FileStatusAvailable, updated: false, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockFileRepository)
+			publisher := &recordingPublisher{}
+			registry := ProvideRegistry(repo, new(MockStorageAdapter), publisher, new(MockScanner))
+			repo.On("UpdateStatus", tt.to, tt.from).Return(tt.updated, nil).Maybe()
+
+			file := factory.File().WithKey("avatars/1/a.png").WithStatus(tt.from, "").Build()
+			err := registry.Transition(context.Background(), file, tt.to, "infected")
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Equal(t, errors.ErrorTypeConflict, errors.GetAppError(err).Type)
+				assert.Equal(t, tt.from, file.Status, "a refused transition leaves the file unchanged")
+				assert.Empty(t, publisher.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, file.Status)
+			require.NotNil(t, file.StatusReason)
+			assert.Equal(t, "infected", *file.StatusReason)
+			require.Len(t, publisher.events, 1)
+			assert.Equal(t, "infected", publisher.events[0].Reason)
+		})
+	}
+}
+
+func TestRegistry_PresignedURL(t *testing.T) {
+	storageAdapter := new(MockStorageAdapter)
+	registry := ProvideRegistry(new(MockFileRepository), storageAdapter, &recordingPublisher{}, new(MockScanner))
+	storageAdapter.On("GetPresignedURL", "avatars/1/a.png").Return("https://example.com/a.png?signature=abc", nil)
+
+	url, err := registry.PresignedURL(context.Background(), factory.File().WithKey("avatars/1/a.png").Build(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a.png?signature=abc", url)
+
+	for _, status := range []string{constants.FileStatusPending, constants.FileStatusScanning, constants.FileStatusFailed, constants.FileStatusQuarantined} {
+		_, err := registry.PresignedURL(context.Background(), factory.File().WithKey("avatars/1/a.png").WithStatus(status, "").Build(), time.Hour)
+		require.Error(t, err, status)
+		assert.Equal(t, errors.ErrorTypeConflict, errors.GetAppError(err).Type, status)
+	}
+	storageAdapter.AssertNumberOfCalls(t, "GetPresignedURL", 1)
+}
+
+func TestRegistry_Open(t *testing.T) {
+	storageAdapter := new(MockStorageAdapter)
+	registry := ProvideRegistry(new(MockFileRepository), storageAdapter, &recordingPublisher{}, new(MockScanner))
+	storageAdapter.On("OpenFileRange", "invoices/1/a.pdf", int64(100), int64(50)).Return(io.NopCloser(strings.NewReader("content")), nil)
+
+	reader, err := registry.Open(context.Background(), factory.File().WithKey("invoices/1/a.pdf").Build(), 100, 50)
+	require.NoError(t, err)
+	assert.NoError(t, reader.Close())
+
+	for _, status := range []string{constants.FileStatusPending, constants.FileStatusScanning, constants.FileStatusFailed, constants.FileStatusQuarantined} {
+		_, err := registry.Open(context.Background(), factory.File().WithKey("invoices/1/a.pdf").WithStatus(status, "").Build(), 0, -1)
+		require.Error(t, err, status)
+		assert.Equal(t, errors.ErrorTypeConflict, errors.GetAppError(err).Type, status)
+	}
+	storageAdapter.AssertNumberOfCalls(t, "OpenFileRange", 1)
+}
@@ -0,0 +1,299 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// resourceFiles are the files of a resource, by template, relative to the module root.
+// The %s is the snake case name of the resource.
+var resourceFiles = []struct {
+	template string
+	path     string
+}{
+	{template: "model.go.tmpl", path: "internal/models/%s.go"},
+	{template: "dto.go.tmpl", path: "internal/dtos/%s.go"},
+	{template: "repository.go.tmpl", path: "internal/repositories/%s.go"},
+	{template: "service.go.tmpl", path: "internal/services/%s.go"},
+	{template: "handler.go.tmpl", path: "internal/handlers/%s.go"},
+	{template: "routes.go.tmpl", path: "cmd/server/routes/%s.go"},
+}
+
+// Resource holds the names of a generated entity in the cases the templates use them
+type Resource struct {
+	// Module is the module path of the go.mod, e.g. golang-boilerplate
+	Module string
+	// Name is the Go type name, e.g. LineItem, and Plural its plural, e.g. LineItems
+	Name   string
+	Plural string
+	// Var is the name of a variable holding one entity, e.g. lineItem, and VarPlural of
+	// a slice of them
+	Var       string
+	VarPlural string
+	// Snake is the file name and the name in the operations, e.g. line_item, and Table
+	// the plural, e.g. line_items
+	Snake string
+	Table string
+	// Path is the route of the collection, e.g. /line-items
+	Path string
+	// Human is the name in messages, e.g. line item, and HumanPlural the plural
+	Human       string
+	HumanPlural string
+	// Title is the name starting messages and the Swagger tag, e.g. Line item
+	Title       string
+	TitlePlural string
+}
+
+// NewResource derives the names of a resource from its name, in any of the cases
+// line-item, line_item, lineItem or LineItem. An empty plural is derived from the name by
+// the English rules; irregular plurals are given explicitly.
+func NewResource(module string, name string, plural string) (*Resource, error) {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid resource name %q: expected letters and digits, e.g. invoice or line-item", name)
+	}
+	if unicode.IsDigit(rune(words[0][0])) {
+		return nil, fmt.Errorf("invalid resource name %q: it cannot start with a digit", name)
+	}
+	// A one letter name would shadow the receivers and the echo context
+	if len(words) == 1 && len(words[0]) == 1 {
+		return nil, fmt.Errorf("invalid resource name %q: expected at least two letters", name)
+	}
+
+	var pluralWords []string
+	if plural != "" {
+		pluralWords = splitWords(plural)
+		if len(pluralWords) == 0 || unicode.IsDigit(rune(pluralWords[0][0])) {
+			return nil, fmt.Errorf("invalid plural %q: expected letters and digits", plural)
+		}
+	} else {
+		pluralWords = append(slices.Clone(words[:len(words)-1]), pluralize(words[len(words)-1]))
+	}
+
+	resource := &Resource{
+		Module:      module,
+		Name:        pascalCase(words),
+		Plural:      pascalCase(pluralWords),
+		Snake:       strings.Join(words, "_"),
+		Table:       strings.Join(pluralWords, "_"),
+		Path:        "/" + strings.Join(pluralWords, "-"),
+		Human:       humanCase(words),
+		HumanPlural: humanCase(pluralWords),
+	}
+	resource.Var = camelCase(words)
+	resource.VarPlural = camelCase(pluralWords)
+	if token.IsKeyword(resource.Var) || token.IsKeyword(resource.VarPlural) {
+		return nil, fmt.Errorf("invalid resource name %q: %s or %s is a Go keyword", name, resource.Var, resource.VarPlural)
+	}
+	resource.Title = capitalize(resource.Human)
+	resource.TitlePlural = capitalize(resource.HumanPlural)
+	if resource.Name == resource.Plural {
+		return nil, fmt.Errorf("the plural of %q is the same as its name, give a distinct plural", name)
+	}
+	return resource, nil
+}
+
+// Generate writes the files of the resource under the module root and returns their
+// paths, relative to the root. Nothing is written when any of the files already exists,
+// so an entity cannot be overwritten.
+func Generate(root string, resource *Resource) ([]string, error) {
+	rendered := make(map[string][]byte, len(resourceFiles))
+	paths := make([]string, 0, len(resourceFiles))
+	for _, file := range resourceFiles {
+		path := fmt.Sprintf(file.path, resource.Snake)
+		if _, err := os.Stat(filepath.Join(root, path)); err == nil {
+			return nil, fmt.Errorf("%s already exists", path)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		source, err := render(file.template, resource)
+		if err != nil {
+			return nil, err
+		}
+		rendered[path] = source
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		if err := os.WriteFile(filepath.Join(root, path), rendered[path], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// NextSteps returns the wiring left to do by hand once the files of the resource are
+// generated: the positional parameters of the router and the fx providers cannot be
+// edited safely by a generator.
+func NextSteps(resource *Resource) string {
+	handler := resource.Var + "Handler"
+	var b strings.Builder
+	fmt.Fprintf(&b, "1. cmd/server/routes/router.go: add the parameter\n\t%s *handlers.%sHandler,\n", handler, resource.Name)
+	fmt.Fprintf(&b, "   to Router and register the routes after the other groups\n\tregister%sRoutes(v1, %s, token, roles)\n", resource.Plural, handler)
+	fmt.Fprintf(&b, "2. cmd/server/main.go: add the same parameter to NewHTTPServer and pass it to routes.Router,\n")
+	fmt.Fprintf(&b, "   pass new(handlers.%sHandler) to routes.Router in RunRoutesCommand, and provide\n", resource.Name)
+	fmt.Fprintf(&b, "\trepositories.Provide%sRepository,\n\tservices.Provide%sService,\n\thandlers.Provide%sHandler,\n", resource.Name, resource.Name, resource.Name)
+	fmt.Fprintf(&b, "3. Generate the migration of the %s table: make migrate-generate name=add_%s\n", resource.Table, resource.Table)
+	fmt.Fprintf(&b, "4. Regenerate the Swagger docs: make swagger-load\n")
+	return b.String()
+}
+
+// ModulePath returns the module path declared by the go.mod of the root
+func ModulePath(root string) (string, error) {
+	file, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module directive in %s", filepath.Join(root, "go.mod"))
+}
+
+// FindModuleRoot returns the closest directory from dir up holding a go.mod
+func FindModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found from %s up", dir)
+		}
+		dir = parent
+	}
+}
+
+// render executes a template for the resource and formats the result, which fails on
+// a template producing invalid Go
+func render(name string, resource *Resource) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, resource); err != nil {
+		return nil, fmt.Errorf("render %s: %w", name, err)
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", name, err)
+	}
+	return source, nil
+}
+
+// splitWords splits a name into lowercase words at dashes, underscores, spaces and the
+// upper case letters starting a word. Any other character makes the name invalid.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(strings.TrimSpace(name))
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '_' || r == ' ':
+			flush()
+		case r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)):
+			return nil
+		case unicode.IsUpper(r):
+			// An upper case letter starts a word, unless it continues an acronym:
+			// APIKey is api key
+			previousUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !previousUpper || nextLower {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// pluralize returns the plural of an English word by the regular rules
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// commonInitialisms are the words kept upper case in Go names, as golint does
+var commonInitialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "sms": true, "sql": true,
+	"url": true, "uri": true, "uuid": true, "http": true, "html": true, "csv": true,
+}
+
+func pascalCase(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if commonInitialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// humanCase joins the words with spaces, keeping the initialisms upper case
+func humanCase(words []string) string {
+	human := make([]string, len(words))
+	for i, word := range words {
+		if commonInitialisms[word] {
+			word = strings.ToUpper(word)
+		}
+		human[i] = word
+	}
+	return strings.Join(human, " ")
+}
+
+func camelCase(words []string) string {
+	first := words[0]
+	return first + pascalCase(words[1:])
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResource(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		plural        string
+		expected      *Resource
+		expectedError bool
+	}{
+		{
+			name:  "kebab case",
+			input: "line-item",
+			expected: &Resource{
+				Module: "golang-boilerplate", Name: "LineItem", Plural: "LineItems", Var: "lineItem", VarPlural: "lineItems",
+				Snake: "line_item", Table: "line_items", Path: "/line-items",
+				Human: "line item", HumanPlural: "line items", Title: "Line item", TitlePlural: "Line items",
+			},
+		},
+		{
+			name:  "pascal case with an initialism",
+			input: "APIQuota",
+			expected: &Resource{
+				Module: "golang-boilerplate", Name: "APIQuota", Plural: "APIQuotas", Var: "apiQuota", VarPlural: "apiQuotas",
+				Snake: "api_quota", Table: "api_quotas", Path: "/api-quotas",
+				Human: "API quota", HumanPlural: "API quotas", Title: "API quota", TitlePlural: "API quotas",
+			},
+		},
+		{
+			name:  "plural in ies",
+			input: "category",
+			expected: &Resource{
+				Module: "golang-boilerplate", Name: "Category", Plural: "Categories", Var: "category", VarPlural: "categories",
+				Snake: "category", Table: "categories", Path: "/categories",
+				Human: "category", HumanPlural: "categories", Title: "Category", TitlePlural: "Categories",
+			},
+		},
+		{
+			name:   "irregular plural",
+			input:  "person",
+			plural: "people",
+			expected: &Resource{
+				Module: "golang-boilerplate", Name: "Person", Plural: "People", Var: "person", VarPlural: "people",
+				Snake: "person", Table: "people", Path: "/people",
+				Human: "person", HumanPlural: "people", Title: "Person", TitlePlural: "People",
+			},
+		},
+		{name: "invalid character", input: "invoice.item", expectedError: true},
+		{name: "leading digit", input: "2fa-device", expectedError: true},
+		{name: "keyword", input: "type", expectedError: true},
+		{name: "plural as the name", input: "series", plural: "series", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := NewResource("golang-boilerplate", tt.input, tt.plural)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resource)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.25\n"), 0o644))
+	for _, dir := range []string{"internal/models", "internal/dtos", "internal/repositories", "internal/services", "internal/handlers", "cmd/server/routes"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	}
+
+	module, err := ModulePath(root)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/app", module)
+	found, err := FindModuleRoot(filepath.Join(root, "cmd/server"))
+	require.NoError(t, err)
+	assert.Equal(t, root, found)
+
+	resource, err := NewResource(module, "line-item", "")
+	require.NoError(t, err)
+	paths, err := Generate(root, resource)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"internal/models/line_item.go",
+		"internal/dtos/line_item.go",
+		"internal/repositories/line_item.go",
+		"internal/services/line_item.go",
+		"internal/handlers/line_item.go",
+		"cmd/server/routes/line_item.go",
+	}, paths)
+
+	handler, err := os.ReadFile(filepath.Join(root, "internal/handlers/line_item.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(handler), `"example.com/app/internal/services"`)
+	assert.Contains(t, string(handler), "// @Router /line-items/{id} [put]")
+	assert.Contains(t, string(handler), "func (h *LineItemHandler) GetLineItems(c echo.Context) error {")
+
+	// An existing entity is left untouched
+	for _, path := range paths {
+		if path != "internal/services/line_item.go" {
+			require.NoError(t, os.Remove(filepath.Join(root, path)))
+		}
+	}
+	_, err = Generate(root, resource)
+	require.EqualError(t, err, "internal/services/line_item.go already exists")
+	_, err = os.Stat(filepath.Join(root, "internal/handlers/line_item.go"))
+	assert.True(t, os.IsNotExist(err))
+}
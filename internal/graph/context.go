@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Loaders holds the dataloaders of one GraphQL request
+type Loaders struct {
+	// UserCompanies loads the companies of a user by user ID
+	UserCompanies *Loader[string, []models.Company]
+	// CompanyMembers loads the members of a company by company ID
+	CompanyMembers *Loader[string, []models.User]
+}
+
+// NewLoaders creates the dataloaders of a request, backed by the repositories
+func NewLoaders(userRepo repositories.UserRepository, companyRepo repositories.CompanyRepository) *Loaders {
+	return &Loaders{
+		UserCompanies: NewLoader(constants.GraphQLLoaderWait, constants.GraphQLLoaderMaxBatch,
+			func(ctx context.Context, userIDs []string) (map[string][]models.Company, error) {
+				return companyRepo.GetByUserIDs(userIDs)
+			}),
+		CompanyMembers: NewLoader(constants.GraphQLLoaderWait, constants.GraphQLLoaderMaxBatch,
+			func(ctx context.Context, companyIDs []string) (map[string][]models.User, error) {
+				return userRepo.GetByCompanyIDs(companyIDs)
+			}),
+	}
+}
+
+type ctxKey struct{}
+
+// requestState is the per request state the resolvers read from the context
+type requestState struct {
+	echo    echo.Context
+	loaders *Loaders
+}
+
+// NewContext returns a context carrying the Echo context of the request, which holds the
+// token claims set by the auth middleware, and its dataloaders
+func NewContext(ctx context.Context, c echo.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestState{echo: c, loaders: loaders})
+}
+
+func stateFromContext(ctx context.Context) *requestState {
+	state, _ := ctx.Value(ctxKey{}).(*requestState)
+	return state
+}
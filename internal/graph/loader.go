@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Loader batches the loads of one request: keys loaded within wait of each other are
+// fetched with one call of the batch function, and every result is kept for the rest of
+// the request. It turns the N+1 queries of nested list fields into one query per level.
+type Loader[K comparable, V any] struct {
+	fetch    func(ctx context.Context, keys []K) (map[K]V, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*loadResult[V]
+	pending *loadBatch[K, V]
+}
+
+// loadResult is the value of one key, available once done is closed
+type loadResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// loadBatch collects the keys waiting to be fetched together
+type loadBatch[K comparable, V any] struct {
+	ctx   context.Context
+	keys  []K
+	timer *time.Timer
+}
+
+// NewLoader creates a loader calling fetch for batches of at most maxBatch keys. Keys
+// missing from the map returned by fetch load the zero value.
+func NewLoader[K comparable, V any](wait time.Duration, maxBatch int, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  make(map[K]*loadResult[V]),
+	}
+}
+
+// Load returns the value of key, waiting for the batch it joins to be fetched
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loadResult[V]{done: make(chan struct{})}
+		l.results[key] = result
+		l.enqueue(ctx, key)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch, starting a new one when there is none. It must
+// be called with the lock held.
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) {
+	if l.pending == nil {
+		batch := &loadBatch[K, V]{ctx: ctx}
+		batch.timer = time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+		l.pending = batch
+	}
+
+	l.pending.keys = append(l.pending.keys, key)
+	if l.maxBatch > 0 && len(l.pending.keys) >= l.maxBatch {
+		batch := l.pending
+		l.pending = nil
+		// A timer that already fired is dispatching the batch, waiting for the lock
+		if batch.timer.Stop() {
+			go l.dispatch(batch)
+		}
+	}
+}
+
+// dispatch fetches a batch and publishes the value of each of its keys
+func (l *Loader[K, V]) dispatch(batch *loadBatch[K, V]) {
+	l.mu.Lock()
+	if l.pending == batch {
+		l.pending = nil
+	}
+	keys := batch.keys
+	l.mu.Unlock()
+
+	values, err := l.safeFetch(batch.ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		result := l.results[key]
+		result.value = values[key]
+		result.err = err
+		close(result.done)
+	}
+}
+
+// safeFetch runs the batch function, turning a panic into an error of every key so that
+// no caller waits forever
+func (l *Loader[K, V]) safeFetch(ctx context.Context, keys []K) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			values, err = nil, fmt.Errorf("loader panic: %v", r)
+		}
+	}()
+
+	return l.fetch(ctx, keys)
+}
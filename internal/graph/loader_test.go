@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetch returns the fetch function of a loader doubling its keys, and records the
+// batches it was called with
+func countingFetch(batches *[][]int, mu *sync.Mutex) func(ctx context.Context, keys []int) (map[int]int, error) {
+	return func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		*batches = append(*batches, append([]int(nil), keys...))
+		mu.Unlock()
+
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			values[key] = key * 2
+		}
+		return values, nil
+	}
+}
+
+func loadAll(t *testing.T, loader *Loader[int, int], keys []int) []int {
+	t.Helper()
+	values := make([]int, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), key)
+			assert.NoError(t, err)
+			values[i] = value
+		}()
+	}
+	wg.Wait()
+	return values
+}
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	loader := NewLoader(10*time.Millisecond, 100, countingFetch(&batches, &mu))
+
+	values := loadAll(t, loader, []int{1, 2, 3, 4})
+
+	assert.Equal(t, []int{2, 4, 6, 8}, values)
+	require.Len(t, batches, 1)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, batches[0])
+}
+
+func TestLoader_CachesLoadedKeys(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	loader := NewLoader(time.Millisecond, 100, countingFetch(&batches, &mu))
+
+	first, err := loader.Load(context.Background(), 7)
+	require.NoError(t, err)
+	second, err := loader.Load(context.Background(), 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, 14, first)
+	assert.Equal(t, 14, second)
+	assert.Len(t, batches, 1)
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	loader := NewLoader(20*time.Millisecond, 2, countingFetch(&batches, &mu))
+
+	values := loadAll(t, loader, []int{1, 2, 3, 4, 5})
+
+	assert.Equal(t, []int{2, 4, 6, 8, 10}, values)
+	assert.Len(t, batches, 3)
+	for _, batch := range batches {
+		assert.LessOrEqual(t, len(batch), 2)
+	}
+}
+
+func TestLoader_MissingKeyLoadsZeroValue(t *testing.T) {
+	loader := NewLoader(time.Millisecond, 10, func(ctx context.Context, keys []string) (map[string][]string, error) {
+		return map[string][]string{"a": {"x"}}, nil
+	})
+
+	value, err := loader.Load(context.Background(), "b")
+
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestLoader_ErrorsAndPanics(t *testing.T) {
+	errFetch := stderrors.New("database unavailable")
+
+	tests := []struct {
+		name  string
+		fetch func(ctx context.Context, keys []int) (map[int]int, error)
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "fetch error",
+			fetch: func(ctx context.Context, keys []int) (map[int]int, error) {
+				return nil, errFetch
+			},
+			check: func(t *testing.T, err error) { assert.ErrorIs(t, err, errFetch) },
+		},
+		{
+			name: "fetch panic",
+			fetch: func(ctx context.Context, keys []int) (map[int]int, error) {
+				panic("boom")
+			},
+			check: func(t *testing.T, err error) { assert.ErrorContains(t, err, "boom") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewLoader(time.Millisecond, 10, tt.fetch)
+
+			_, err := loader.Load(context.Background(), 1)
+
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	loader := NewLoader(50*time.Millisecond, 10, func(ctx context.Context, keys []int) (map[int]int, error) {
+		return map[int]int{}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err := loader.Load(ctx, 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
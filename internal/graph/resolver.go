@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/utils"
+)
+
+// Resolver resolves the GraphQL queries through the same services as the REST handlers
+type Resolver struct {
+	userService    services.UserService
+	companyService services.CompanyService
+	cfg            *config.Config
+}
+
+// ProvideResolver creates the root GraphQL resolver
+func ProvideResolver(
+	userService services.UserService,
+	companyService services.CompanyService,
+	cfg *config.Config,
+) *Resolver {
+	return &Resolver{
+		userService:    userService,
+		companyService: companyService,
+		cfg:            cfg,
+	}
+}
+
+// Sort fields accepted by the list queries
+var (
+	userSortFields    = map[string]struct{}{"created_at": {}, "first_name": {}, "last_name": {}, "email": {}}
+	companySortFields = map[string]struct{}{"created_at": {}, "name": {}}
+)
+
+// requireRole fails unless the token claims of the request grant any of the roles, the
+// GraphQL counterpart of the RequireRole middleware of the REST routes
+func (r *Resolver) requireRole(ctx context.Context, roles ...string) error {
+	state := stateFromContext(ctx)
+	if state == nil {
+		return errors.UnauthorizedError("User not authenticated", nil)
+	}
+	claims, ok := state.echo.Get(r.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return errors.UnauthorizedError("User not authenticated", nil)
+	}
+
+	if !middlewares.HasAnyRole(claims, r.cfg.KeycloakClientID, roles...) {
+		return errors.ForbiddenError("Insufficient permissions", nil)
+	}
+
+	return nil
+}
+
+// loaders returns the dataloaders of the request
+func (r *Resolver) loaders(ctx context.Context) (*Loaders, error) {
+	state := stateFromContext(ctx)
+	if state == nil || state.loaders == nil {
+		return nil, errors.InternalError("Dataloaders are not configured", nil)
+	}
+	return state.loaders, nil
+}
+
+// pageableRequest builds the pagination of a list query, with the defaults of the REST
+// handlers for missing or invalid values
+func pageableRequest(page *int, pageSize *int) dtos.PageableRequest {
+	pr := dtos.PageableRequest{Page: 1, PageSize: 10}
+	if page != nil && *page > 0 {
+		pr.Page = *page
+	}
+	if pageSize != nil && *pageSize >= 0 {
+		pr.PageSize = *pageSize
+	}
+	return pr
+}
+
+// validateSort rejects sort fields that are not allowed
+func validateSort(sort []string, allowed map[string]struct{}) ([]string, error) {
+	valid, invalid := utils.NormalizeAndValidateSort(sort, allowed)
+	if len(invalid) > 0 {
+		return nil, errors.ValidationErrorWithDetails("Validation failed", nil, map[string]string{
+			"sort": "invalid sort field(s): " + strings.Join(invalid, ", "),
+		})
+	}
+	return valid, nil
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,127 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.85
+
+import (
+	"context"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/graph/model"
+	"golang-boilerplate/internal/models"
+)
+
+// Members is the resolver for the members field.
+func (r *companyResolver) Members(ctx context.Context, obj *models.Company) ([]models.User, error) {
+	loaders, err := r.loaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := loaders.CompanyMembers.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []models.User{}
+	}
+
+	return members, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*models.User, error) {
+	if err := r.requireRole(ctx, constants.RoleAdmin, constants.RoleUserViewer); err != nil {
+		return nil, err
+	}
+
+	return r.userService.GetOneByID(ctx, id)
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, page *int, pageSize *int, q *string, sort []string) (*model.UserPage, error) {
+	if err := r.requireRole(ctx, constants.UserViewRoles...); err != nil {
+		return nil, err
+	}
+
+	validSort, err := validateSort(sort, userSortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := r.userService.List(ctx, &dtos.UserPageableRequest{
+		PageableRequest: pageableRequest(page, pageSize),
+		Q:               valueOrEmpty(q),
+		Sort:            validSort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UserPage{Items: users.Data, PageInfo: users.Pageable}, nil
+}
+
+// Company is the resolver for the company field.
+func (r *queryResolver) Company(ctx context.Context, id string) (*models.Company, error) {
+	if err := r.requireRole(ctx, constants.CompanyViewRoles...); err != nil {
+		return nil, err
+	}
+
+	return r.companyService.GetOneByID(ctx, id)
+}
+
+// Companies is the resolver for the companies field.
+func (r *queryResolver) Companies(ctx context.Context, page *int, pageSize *int, q *string, sort []string) (*model.CompanyPage, error) {
+	if err := r.requireRole(ctx, constants.CompanyViewRoles...); err != nil {
+		return nil, err
+	}
+
+	validSort, err := validateSort(sort, companySortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	companies, err := r.companyService.List(ctx, &dtos.CompanyPageableRequest{
+		PageableRequest: pageableRequest(page, pageSize),
+		Q:               valueOrEmpty(q),
+		Sort:            validSort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CompanyPage{Items: companies.Data, PageInfo: companies.Pageable}, nil
+}
+
+// Companies is the resolver for the companies field.
+func (r *userResolver) Companies(ctx context.Context, obj *models.User) ([]models.Company, error) {
+	loaders, err := r.loaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	companies, err := loaders.UserCompanies.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if companies == nil {
+		companies = []models.Company{}
+	}
+
+	return companies, nil
+}
+
+// Company returns CompanyResolver implementation.
+func (r *Resolver) Company() CompanyResolver { return &companyResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type companyResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// NewServer creates the GraphQL server. Queries are accepted over GET and POST, their
+// complexity is limited and introspection is disabled in production. Resolver errors go
+// through the REST error handler, so they are logged and reported to Sentry the same way,
+// and carry the AppError code and HTTP status in their extensions.
+func NewServer(resolver *Resolver, cfg *config.Config) *handler.Server {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+
+	if cfg.AppEnv != config.EnvironmentProduction {
+		srv.Use(extension.Introspection{})
+	}
+	srv.Use(extension.FixedComplexityLimit(constants.GraphQLComplexityLimit))
+
+	errorHandler := errors.NewErrorHandler()
+	srv.SetErrorPresenter(func(ctx context.Context, err error) *gqlerror.Error {
+		return presentError(ctx, errorHandler, err)
+	})
+	srv.SetRecoverFunc(func(ctx context.Context, p any) error {
+		return errors.InternalError("An unexpected error occurred", fmt.Errorf("panic: %v", p)).
+			WithOperation("graphql_resolve").
+			WithResource("graphql")
+	})
+
+	return srv
+}
+
+// presentError converts a resolver error to a GraphQL error. Errors raised by gqlgen
+// itself, such as parse and validation errors, are returned unchanged.
+func presentError(ctx context.Context, errorHandler *errors.ErrorHandler, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if gqlErr.Err == nil {
+		return gqlErr
+	}
+
+	cause := gqlErr.Err
+	var appErr *errors.AppError
+	if stderrors.As(cause, &appErr) {
+		cause = appErr
+	}
+
+	state := stateFromContext(ctx)
+	if state == nil {
+		return gqlErr
+	}
+	appErr = errorHandler.ReportError(state.echo, cause)
+
+	gqlErr.Message = appErr.Message
+	gqlErr.Extensions = map[string]any{
+		"code":   appErr.Code,
+		"status": appErr.HTTPStatus,
+	}
+	if len(appErr.Context) > 0 {
+		gqlErr.Extensions["details"] = appErr.Context
+	}
+
+	return gqlErr
+}
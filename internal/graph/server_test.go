@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+const testClaimsKey = "claims"
+
+// fakeCompanyService lists a fixed set of companies; its other methods are not used
+type fakeCompanyService struct {
+	services.CompanyService
+	companies []models.Company
+}
+
+func (s *fakeCompanyService) List(ctx context.Context, pr *dtos.CompanyPageableRequest) (*dtos.DataResponse[models.Company], error) {
+	return &dtos.DataResponse[models.Company]{
+		Data:     s.companies,
+		Pageable: &dtos.Pageable{Page: pr.Page, PageSize: pr.PageSize, Total: int64(len(s.companies))},
+	}, nil
+}
+
+func (s *fakeCompanyService) GetOneByID(ctx context.Context, id string) (*models.Company, error) {
+	return nil, errors.NotFoundError("Company", nil).WithContext("company_id", id)
+}
+
+// fakeUserRepository serves company members and counts the batch queries
+type fakeUserRepository struct {
+	repositories.UserRepository
+	members map[string][]models.User
+	calls   atomic.Int32
+}
+
+func (r *fakeUserRepository) GetByCompanyIDs(companyIDs []string) (map[string][]models.User, error) {
+	r.calls.Add(1)
+	return r.members, nil
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func executeQuery(t *testing.T, resolver *Resolver, userRepo repositories.UserRepository, roles []string, query string) graphqlResponse {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if roles != nil {
+		claims := &auth.TokenClaims{}
+		claims.RealmAccess.Roles = roles
+		c.Set(testClaimsKey, claims)
+	}
+
+	ctx := NewContext(req.Context(), c, NewLoaders(userRepo, nil))
+	NewServer(resolver, resolver.cfg).ServeHTTP(rec, req.WithContext(ctx))
+
+	var response graphqlResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
+
+func newTestResolver(companyService services.CompanyService) *Resolver {
+	return ProvideResolver(nil, companyService, &config.Config{KeycloakKeyClaim: testClaimsKey, KeycloakClientID: "api"})
+}
+
+func TestServer_BatchesNestedMembers(t *testing.T) {
+	companyService := &fakeCompanyService{companies: []models.Company{
+		*factory.Company().WithID("c1").WithName("Acme").Build(),
+		*factory.Company().WithID("c2").WithName("Globex").Build(),
+		*factory.Company().WithID("c3").WithName("Initech").Build(),
+	}}
+	userRepo := &fakeUserRepository{members: map[string][]models.User{
+		"c1": {*factory.User().WithID("u1").WithEmail("ada@acme.test").Build()},
+		"c2": {*factory.User().WithID("u2").WithEmail("bob@globex.test").Build()},
+	}}
+
+	response := executeQuery(t, newTestResolver(companyService), userRepo, []string{constants.RoleCompanyViewer},
+		`{ companies { items { id members { email } } pageInfo { total } } }`)
+
+	require.Empty(t, response.Errors)
+	assert.JSONEq(t, `{"companies":{"items":[
+		{"id":"c1","members":[{"email":"ada@acme.test"}]},
+		{"id":"c2","members":[{"email":"bob@globex.test"}]},
+		{"id":"c3","members":[]}
+	],"pageInfo":{"total":3}}}`, string(response.Data))
+	assert.Equal(t, int32(1), userRepo.calls.Load())
+}
+
+func TestServer_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		roles          []string
+		query          string
+		expectedCode   string
+		expectedStatus float64
+		expectDetails  bool
+	}{
+		{
+			name:           "not authenticated",
+			query:          `{ companies { items { id } } }`,
+			expectedCode:   constants.Unauthorized,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing role",
+			roles:          []string{constants.RoleUserViewer},
+			query:          `{ companies { items { id } } }`,
+			expectedCode:   constants.Forbidden,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "invalid sort",
+			roles:          []string{constants.RoleCompanyViewer},
+			query:          `{ companies(sort: ["-password"]) { items { id } } }`,
+			expectedCode:   constants.ValidationError,
+			expectedStatus: http.StatusBadRequest,
+			expectDetails:  true,
+		},
+		{
+			name:           "not found",
+			roles:          []string{constants.RoleCompanyViewer},
+			query:          `{ company(id: "missing") { id } }`,
+			expectedCode:   constants.UserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectDetails:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := executeQuery(t, newTestResolver(&fakeCompanyService{}), &fakeUserRepository{}, tt.roles, tt.query)
+
+			require.Len(t, response.Errors, 1)
+			extensions := response.Errors[0].Extensions
+			assert.Equal(t, tt.expectedCode, extensions["code"])
+			assert.Equal(t, tt.expectedStatus, extensions["status"])
+			if tt.expectDetails {
+				assert.NotEmpty(t, extensions["details"])
+			}
+		})
+	}
+}
+
+func TestServer_ParseErrorsAreUnchanged(t *testing.T) {
+	response := executeQuery(t, newTestResolver(&fakeCompanyService{}), &fakeUserRepository{}, []string{constants.RoleAdmin},
+		`{ companies { unknownField } }`)
+
+	require.NotEmpty(t, response.Errors)
+	assert.Contains(t, response.Errors[0].Message, "unknownField")
+	assert.NotContains(t, response.Errors[0].Extensions, "status")
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler handles the HTTP requests of the API keys of the companies. A key is
+// only returned in full by its creation.
+type APIKeyHandler struct {
+	BaseHandler
+	apiKeyService services.APIKeyService
+	cfg           *config.Config
+	validator     *validator.Validate
+}
+
+// ProvideAPIKeyHandler creates a new API key handler
+func ProvideAPIKeyHandler(
+	apiKeyService services.APIKeyService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		BaseHandler:   *NewBaseHandler(),
+		apiKeyService: apiKeyService,
+		cfg:           cfg,
+		validator:     validator,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create an API key of a company, sent in the X-API-Key header by machine clients. The key is only returned by this response. A sandbox key belongs to the sandbox tenant of the company, created with its first sandbox key, whose ID is the company_id of the key.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param apiKey body dtos.CreateAPIKeyRequest true "API key"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CreatedAPIKeyResponse}
+// @Router /companies/{id}/api-keys [post]
+// @Security BearerAuth
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateAPIKeyRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	key, rawKey, err := h.apiKeyService.Create(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "API key created successfully", dtos.NewCreatedAPIKeyResponse(key, rawKey), nil)
+}
+
+// GetAPIKeys godoc
+// @Summary Get API keys
+// @Description Get the API keys of a company with their last use, most recent first
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.APIKeyResponse}
+// @Router /companies/{id}/api-keys [get]
+// @Security BearerAuth
+func (h *APIKeyHandler) GetAPIKeys(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	keys, err := h.apiKeyService.List(c.Request().Context(), c.Param("id"), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.APIKeyResponse, len(keys.Data))
+	for i, key := range keys.Data {
+		responseDto[i] = *dtos.NewAPIKeyResponse(&key)
+	}
+
+	return h.SuccessResponse(c, "API keys retrieved successfully", responseDto, keys.Pageable)
+}
+
+// GetAPIKey godoc
+// @Summary Get API key by ID
+// @Description Get an API key of a company with its status and last use
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.APIKeyResponse}
+// @Router /companies/{id}/api-keys/{keyId} [get]
+// @Security BearerAuth
+func (h *APIKeyHandler) GetAPIKey(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	key, err := h.apiKeyService.GetOneByID(c.Request().Context(), c.Param("id"), c.Param("keyId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "API key retrieved successfully", dtos.NewAPIKeyResponse(key), nil)
+}
+
+// GetAPIKeyUsage godoc
+// @Summary Get API key usage
+// @Description Get the calls made with an API key per day and per endpoint over the last days. Recent calls can take up to API_KEY_USAGE_FLUSH_INTERVAL to be counted.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param keyId path string true "API key ID"
+// @Param days query int false "Number of days, today included" default(30) maximum(90) example("30")
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.APIKeyUsageResponse}
+// @Router /companies/{id}/api-keys/{keyId}/usage [get]
+// @Security BearerAuth
+func (h *APIKeyHandler) GetAPIKeyUsage(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	days, err := strconv.Atoi(c.QueryParam("days"))
+	if err != nil {
+		days = 0
+	}
+
+	usage, err := h.apiKeyService.Usage(c.Request().Context(), c.Param("id"), c.Param("keyId"), days)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "API key usage retrieved successfully", usage, nil)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key of a company. The key is kept with its usage but no longer authenticates requests.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.APIKeyResponse}
+// @Router /companies/{id}/api-keys/{keyId} [delete]
+// @Security BearerAuth
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	key, err := h.apiKeyService.Revoke(c.Request().Context(), c.Param("id"), c.Param("keyId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "API key revoked successfully", dtos.NewAPIKeyResponse(key), nil)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// AuthHandler handles the session requests of the authenticated users
+type AuthHandler struct {
+	BaseHandler
+	authService services.AuthService
+	cfg         *config.Config
+	validator   *validator.Validate
+}
+
+// ProvideAuthHandler creates a new auth handler
+func ProvideAuthHandler(authService services.AuthService, cfg *config.Config, validator *validator.Validate) *AuthHandler {
+	return &AuthHandler{
+		BaseHandler: *NewBaseHandler(),
+		authService: authService,
+		cfg:         cfg,
+		validator:   validator,
+	}
+}
+
+// SwitchOrganization godoc
+// @Summary Switch organization
+// @Description Exchange the access token for tokens bound to another organization of the user, through a Keycloak token exchange, so that a frontend switches the tenant without a new login. The organization claim of the new access token only holds the selected organization; the frontend replaces its tokens with them.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param organization body dtos.SwitchOrganizationRequest true "Organization"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.SwitchOrganizationResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 403 {object} object{meta=dtos.Meta}
+// @Router /auth/switch-org [post]
+// @Security BearerAuth
+func (h *AuthHandler) SwitchOrganization(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.SwitchOrganizationRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	accessToken := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	result, err := h.authService.SwitchOrganization(c.Request().Context(), claims.Sub, accessToken, requestDto.OrganizationID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// The rest of the request is attributed to the selected organization, as the requests
+	// made with the new tokens will be
+	c.Set(middlewares.OrganizationIDContextKey, result.Organization.ID)
+	monitoring.SetPrincipal(c.Request().Context(), monitoring.NewPrincipal(claims.Sub, claims.Email, result.Organization.ID))
+
+	return h.SuccessResponse(c, "Organization switched successfully", result, nil)
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Log a user in with their username and password through the password grant of the client, so that first-party clients get their tokens without talking to Keycloak. The client must have the direct access grants enabled. Invalid credentials, a disabled user or one with pending required actions get a 401.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body dtos.LoginRequest true "Credentials"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TokenResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 401 {object} object{meta=dtos.Meta}
+// @Failure 429 {object} object{meta=dtos.Meta}
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c echo.Context) error {
+	var requestDto dtos.LoginRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	result, err := h.authService.Login(c.Request().Context(), requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Logged in successfully", result, nil)
+}
+
+// RefreshToken godoc
+// @Summary Refresh tokens
+// @Description Refresh the tokens of a session with its refresh token. The response replaces both tokens; an expired or revoked refresh token gets a 401 and the user logs in again.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token body dtos.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TokenResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 401 {object} object{meta=dtos.Meta}
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c echo.Context) error {
+	var requestDto dtos.RefreshTokenRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	result, err := h.authService.RefreshToken(c.Request().Context(), requestDto.RefreshToken)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Token refreshed successfully", result, nil)
+}
+
+// ExchangeToken godoc
+// @Summary Exchange token
+// @Description Exchange the access token for an access token of another client of the realm, the audience, through a Keycloak token exchange, e.g. to call another service on behalf of the user. The client must be allowed to exchange the tokens for the audience, otherwise the exchange gets a 403.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param exchange body dtos.ExchangeTokenRequest true "Audience"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TokenResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 401 {object} object{meta=dtos.Meta}
+// @Failure 403 {object} object{meta=dtos.Meta}
+// @Router /auth/exchange [post]
+// @Security BearerAuth
+func (h *AuthHandler) ExchangeToken(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.ExchangeTokenRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	accessToken := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	result, err := h.authService.ExchangeToken(c.Request().Context(), claims.Sub, accessToken, requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Token exchanged successfully", result, nil)
+}
+
+// bind binds and validates the body of the request into requestDto
+func (h *AuthHandler) bind(c echo.Context, requestDto any) error {
+	if err := c.Bind(requestDto); err != nil {
+		return errors.ValidationError("Invalid request body", err)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors)
+		}
+		return errors.ValidationError("Validation failed", err)
+	}
+
+	return nil
+}
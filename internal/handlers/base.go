@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"io"
+	"mime"
+	"net/http"
+
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
@@ -52,3 +60,30 @@ func (b *BaseHandler) ForbiddenErrorResponse(c echo.Context, message string) err
 func (b *BaseHandler) InternalErrorResponse(c echo.Context, message string, cause error) error {
 	return b.errorHandler.InternalErrorResponse(c, message, cause)
 }
+
+// IsAuthenticated reports whether the request was authenticated by a token, whose claims
+// are stored under claimsKey, or by an API key of a company
+func (b *BaseHandler) IsAuthenticated(c echo.Context, claimsKey string) bool {
+	if _, ok := c.Get(claimsKey).(*auth.TokenClaims); ok {
+		return true
+	}
+	_, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey)
+	return ok
+}
+
+// ReadMergePatch reads an RFC 7386 JSON merge patch body. Both application/merge-patch+json
+// and application/json are accepted.
+func (b *BaseHandler) ReadMergePatch(c echo.Context) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != utils.MergePatchContentType && mediaType != echo.MIMEApplicationJSON) {
+		return nil, errors.NewAppError(constants.BadRequest, "Unsupported content type, expected "+utils.MergePatchContentType,
+			errors.ErrorTypeValidation, http.StatusUnsupportedMediaType)
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, errors.ValidationError("Invalid request body", err)
+	}
+
+	return patch, nil
+}
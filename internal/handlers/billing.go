@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// BillingHandler handles the HTTP requests of the subscriptions of the companies
+type BillingHandler struct {
+	BaseHandler
+	billingService services.BillingService
+	cfg            *config.Config
+	validator      *validator.Validate
+}
+
+// ProvideBillingHandler creates a new billing handler
+func ProvideBillingHandler(
+	billingService services.BillingService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *BillingHandler {
+	return &BillingHandler{
+		BaseHandler:    *NewBaseHandler(),
+		billingService: billingService,
+		cfg:            cfg,
+		validator:      validator,
+	}
+}
+
+// GetSubscription godoc
+// @Summary Get subscription
+// @Description Get the plan in effect for a company and the entitlements it grants. A company without a subscription in effect gets the default plan, or no restriction when no default plan is configured.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.SubscriptionResponse}
+// @Router /companies/{id}/subscription [get]
+// @Security BearerAuth
+func (h *BillingHandler) GetSubscription(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	subscription, err := h.billingService.GetSubscription(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Subscription retrieved successfully", subscription, nil)
+}
+
+// ChangeSubscription godoc
+// @Summary Change subscription
+// @Description Subscribe a company to a plan. A free plan, which cancels the paid subscription of the company, and a change between paid plans, prorated by the payment provider, take effect at once. The first paid plan returns a checkout_url to pay it; the current plan stays in effect until the payment provider reports the subscription paid.
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param subscription body dtos.ChangeSubscriptionRequest true "Plan"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.SubscriptionResponse}
+// @Router /companies/{id}/subscription [post]
+// @Security BearerAuth
+func (h *BillingHandler) ChangeSubscription(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.ChangeSubscriptionRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	subscription, err := h.billingService.ChangeSubscription(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Subscription changed successfully", subscription, nil)
+}
@@ -4,9 +4,11 @@ import (
 	"strconv"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
 	"golang-boilerplate/internal/services"
 	"golang-boilerplate/internal/utils"
 
@@ -18,6 +20,8 @@ import (
 type CompanyHandler struct {
 	BaseHandler
 	companyService services.CompanyService
+	exportService  services.ExportService
+	policies       services.UploadPolicyService
 	cfg            *config.Config
 	validator      *validator.Validate
 }
@@ -25,12 +29,16 @@ type CompanyHandler struct {
 // ProvideCompanyHandler creates a new company handler
 func ProvideCompanyHandler(
 	companyService services.CompanyService,
+	exportService services.ExportService,
+	policies services.UploadPolicyService,
 	cfg *config.Config,
 	validator *validator.Validate,
 ) *CompanyHandler {
 	return &CompanyHandler{
 		BaseHandler:    *NewBaseHandler(),
 		companyService: companyService,
+		exportService:  exportService,
+		policies:       policies,
 		cfg:            cfg,
 		validator:      validator,
 	}
@@ -74,6 +82,52 @@ func (h *CompanyHandler) CreateCompany(c echo.Context) error {
 	return h.SuccessResponse(c, "Company created successfully", dtos.NewCompanyResponse(company), nil)
 }
 
+// CreateCompanyWithLogo godoc
+// @Summary Create company with logo
+// @Description Create a company and upload its logo in one multipart request. The "payload" part holds the company JSON. The logo is removed from storage again when the company cannot be saved.
+// @Tags Company
+// @Accept multipart/form-data
+// @Produce json
+// @Param payload formData string true "Company JSON matching dtos.CreateCompanyRequest"
+// @Param logo formData file true "Logo image (jpeg, png or webp, max 1 MiB)"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CompanyResponse}
+// @Router /companies/with-logo [post]
+// @Security BearerAuth
+func (h *CompanyHandler) CreateCompanyWithLogo(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateCompanyRequest
+	files, err := h.BindMultipartJSON(c, h.validator, constants.MultipartPayloadField, &requestDto, FilePart{
+		Field:        "logo",
+		Required:     true,
+		MaxSize:      constants.CompanyLogoMaxFileSize,
+		ContentTypes: constants.CompanyLogoContentTypes,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	logo := files["logo"]
+	if err := h.EnforceUploadPolicy(c, h.policies, services.UploadFile{
+		Field:       "logo",
+		Filename:    logo.Filename,
+		ContentType: logo.Header.Get(echo.HeaderContentType),
+		Size:        logo.Size,
+	}); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	company, err := h.companyService.CreateWithLogo(c.Request().Context(), &requestDto, logo, logo.Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Company created successfully", dtos.NewCompanyResponse(company), nil)
+}
+
 // GetOneByID godoc
 // @Summary Get company by ID
 // @Description Get company by ID
@@ -84,9 +138,9 @@ func (h *CompanyHandler) CreateCompany(c echo.Context) error {
 // @Success 200 {object} object{meta=dtos.Meta,data=dtos.CompanyResponse}
 // @Router /companies/{id} [get]
 // @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *CompanyHandler) GetOneByID(c echo.Context) error {
-	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
-	if !ok {
+	if !h.IsAuthenticated(c, h.cfg.KeycloakKeyClaim) {
 		return h.UnauthorizedErrorResponse(c, "User not authenticated")
 	}
 
@@ -138,6 +192,56 @@ func (h *CompanyHandler) UpdateCompany(c echo.Context) error {
 	return h.SuccessResponse(c, "Company updated successfully", dtos.NewCompanyResponse(company), nil)
 }
 
+// PatchCompany godoc
+// @Summary Patch company
+// @Description Apply an RFC 7386 JSON merge patch to a company. Set a field to null to clear it.
+// @Tags Company
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param company body dtos.PatchCompanyRequest true "Merge patch"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CompanyResponse}
+// @Router /companies/{id} [patch]
+// @Security BearerAuth
+func (h *CompanyHandler) PatchCompany(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	patch, err := h.ReadMergePatch(c)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	companyID := c.Param("id")
+	company, err := h.companyService.GetOneByID(c.Request().Context(), companyID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	requestDto := dtos.NewPatchCompanyRequest(company)
+	if err := utils.MergePatch(requestDto, patch); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid merge patch", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	company, err = h.companyService.Patch(c.Request().Context(), companyID, requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Company updated successfully", dtos.NewCompanyResponse(company), nil)
+}
+
 // DeleteCompany godoc
 // @Summary Delete company
 // @Description Delete company
@@ -166,9 +270,11 @@ func (h *CompanyHandler) DeleteCompany(c echo.Context) error {
 // GetCompanies godoc
 // @Summary Get companies
 // @Description Get companies
+// @Description With Accept text/csv or application/x-ndjson every matching company is streamed, newest first, and page, page_size and sort are ignored.
+// @Description Exports only carry the fields the export policies allow to the caller, and are audited.
 // @Tags Company
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/x-ndjson
 // @Param page query int false "Page" default(1) example("1")
 // @Param page_size query int false "Page size" default(10) example("10")
 // @Param start_date query string false "Start date" example("2025-09-11T02:17:24.290538Z")
@@ -216,6 +322,19 @@ func (h *CompanyHandler) GetCompanies(c echo.Context) error {
 		Sort:      sort,
 	}
 
+	if format := NegotiateListFormat(c); format != echo.MIMEApplicationJSON {
+		export := ListExport[dtos.CompanyResponse]{Entity: constants.ExportEntityCompanies, Filename: "companies", Columns: companyStreamColumns}
+		return StreamList(c, &h.BaseHandler, h.exportService, NewExportPrincipal(c, h.cfg), format, export, func(yield func([]dtos.CompanyResponse) error) error {
+			return h.companyService.Stream(c.Request().Context(), pr, func(companies []models.Company) error {
+				responses := make([]dtos.CompanyResponse, len(companies))
+				for i, company := range companies {
+					responses[i] = *dtos.NewCompanyResponse(&company)
+				}
+				return yield(responses)
+			})
+		})
+	}
+
 	companies, err := h.companyService.List(c.Request().Context(), pr)
 	if err != nil {
 		return h.HandleError(c, err)
@@ -229,3 +348,76 @@ func (h *CompanyHandler) GetCompanies(c echo.Context) error {
 
 	return h.SuccessResponse(c, "Companies retrieved successfully", responseDto, companies.Pageable)
 }
+
+// GetMembers godoc
+// @Summary Get company members
+// @Description Get the users that are members of the company
+// @Description With Accept text/csv or application/x-ndjson every member is streamed, newest first, and page and page_size are ignored.
+// @Description Exports only carry the fields the export policies allow to the caller, and are audited.
+// @Tags Company
+// @Accept json
+// @Produce json,text/csv,application/x-ndjson
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.UserResponse}
+// @Router /companies/{id}/members [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CompanyHandler) GetMembers(c echo.Context) error {
+	if !h.IsAuthenticated(c, h.cfg.KeycloakKeyClaim) {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	companyID := c.Param("id")
+	if format := NegotiateListFormat(c); format != echo.MIMEApplicationJSON {
+		export := ListExport[dtos.UserResponse]{
+			Entity:    constants.ExportEntityCompanyMembers,
+			Filename:  "company-members",
+			Columns:   userStreamColumns,
+			CompanyID: companyID,
+		}
+		return StreamList(c, &h.BaseHandler, h.exportService, NewExportPrincipal(c, h.cfg), format, export, func(yield func([]dtos.UserResponse) error) error {
+			return h.companyService.StreamMembers(c.Request().Context(), companyID, func(members []models.User) error {
+				return yield(newUserResponses(members))
+			})
+		})
+	}
+
+	members, err := h.companyService.ListMembers(c.Request().Context(), companyID, &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.UserResponse, len(members.Data))
+	for i, member := range members.Data {
+		responseDto[i] = *dtos.NewUserResponse(&member)
+	}
+
+	return h.SuccessResponse(c, "Company members retrieved successfully", responseDto, members.Pageable)
+}
+
+// companyStreamColumns are the fields of the streamed company listing
+var companyStreamColumns = []StreamColumn[dtos.CompanyResponse]{
+	{Name: "id", Value: func(c dtos.CompanyResponse) string { return c.ID }},
+	{Name: "name", Value: func(c dtos.CompanyResponse) string { return c.Name }},
+	{Name: "keycloak_id", Value: func(c dtos.CompanyResponse) string { return c.KeycloakID }},
+	{Name: "logo_key", Value: func(c dtos.CompanyResponse) string { return c.LogoKey }},
+	{Name: "created_at", Value: func(c dtos.CompanyResponse) string { return formatStreamTime(c.CreatedAt) }},
+	{Name: "updated_at", Value: func(c dtos.CompanyResponse) string { return formatStreamTime(c.UpdatedAt) }},
+}
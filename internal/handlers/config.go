@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"slices"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// configSources are the sources the settings can be filtered by
+var configSources = []string{
+	constants.ConfigSourceEnv,
+	constants.ConfigSourceDotenv,
+	constants.ConfigSourceSecretsManager,
+	constants.ConfigSourceDefault,
+}
+
+// ConfigHandler handles the HTTP requests about the configuration of the running instance
+type ConfigHandler struct {
+	BaseHandler
+	cfg *config.Config
+}
+
+// ProvideConfigHandler creates a new config handler
+func ProvideConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		BaseHandler: *NewBaseHandler(),
+		cfg:         cfg,
+	}
+}
+
+// GetInternalConfig returns the value of every config variable the running instance
+// loaded, with its source, filtered by the source in the query. It is only served on the
+// internal listener, where the requests come from the cluster, and masks the secrets.
+func (h *ConfigHandler) GetInternalConfig(c echo.Context) error {
+	source := c.QueryParam("source")
+	if source != "" && !slices.Contains(configSources, source) {
+		return h.HandleError(c, errors.ValidationError("Invalid source", nil).
+			WithOperation("get_config").
+			WithContext("source", source))
+	}
+
+	settings := make([]dtos.ConfigSettingResponse, 0)
+	for _, setting := range h.cfg.Settings() {
+		if source != "" && setting.Source.Origin != source {
+			continue
+		}
+
+		value := setting.Value
+		if setting.Secret && value != "" {
+			value = utils.MaskSecret(value)
+		}
+		settings = append(settings, dtos.ConfigSettingResponse{
+			Name:      setting.Name,
+			Value:     value,
+			Secret:    setting.Secret,
+			Source:    setting.Source.Origin,
+			Reference: setting.Source.Reference,
+		})
+	}
+
+	return h.SuccessResponse(c, "Configuration retrieved successfully", settings, nil)
+}
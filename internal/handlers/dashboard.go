@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DashboardHandler handles the HTTP requests of the dashboards, served from the read
+// models maintained by the projections
+type DashboardHandler struct {
+	BaseHandler
+	dashboardService      services.DashboardService
+	adminDashboardService services.AdminDashboardService
+	cfg                   *config.Config
+}
+
+// ProvideDashboardHandler creates a new dashboard handler
+func ProvideDashboardHandler(
+	dashboardService services.DashboardService,
+	adminDashboardService services.AdminDashboardService,
+	cfg *config.Config,
+) *DashboardHandler {
+	return &DashboardHandler{
+		BaseHandler:           *NewBaseHandler(),
+		dashboardService:      dashboardService,
+		adminDashboardService: adminDashboardService,
+		cfg:                   cfg,
+	}
+}
+
+// GetCompanySummary godoc
+// @Summary Get company summary
+// @Description Get the member count, storage usage and last activity of a company. The summary is updated shortly after the changes of the company; refreshed_at is omitted until it is first computed.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CompanySummaryResponse}
+// @Router /companies/{id}/summary [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetCompanySummary(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	summary, err := h.dashboardService.GetCompanySummary(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Company summary retrieved successfully", dtos.NewCompanySummaryResponse(summary), nil)
+}
+
+// GetCompanySummaries godoc
+// @Summary Get company summaries
+// @Description Get the summaries of all the companies, most recently active first by default
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Param sort query string false "Sort" example("[-member_count,name]") Enums(name,-name,member_count,-member_count,storage_bytes,-storage_bytes,last_activity_at,-last_activity_at)
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.CompanySummaryResponse}
+// @Router /dashboard/companies [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetCompanySummaries(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	// Validate sort fields against allowed set
+	allowedSort := map[string]struct{}{
+		"name":             {},
+		"member_count":     {},
+		"storage_bytes":    {},
+		"last_activity_at": {},
+	}
+	validSort, invalidSort := utils.NormalizeAndValidateSort(c.QueryParams()["sort"], allowedSort)
+	if len(invalidSort) > 0 {
+		invalids := map[string]string{
+			"sort": "invalid sort field(s): " + strings.Join(invalidSort, ", "),
+		}
+		return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", nil, invalids))
+	}
+
+	summaries, err := h.dashboardService.ListCompanySummaries(c.Request().Context(), &dtos.CompanySummaryPageableRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		Sort: validSort,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.CompanySummaryResponse, len(summaries.Data))
+	for i, summary := range summaries.Data {
+		responseDto[i] = *dtos.NewCompanySummaryResponse(&summary)
+	}
+
+	return h.SuccessResponse(c, "Company summaries retrieved successfully", responseDto, summaries.Pageable)
+}
+
+// GetAdminDashboard godoc
+// @Summary Get admin dashboard
+// @Description Get the key stats of the admin frontends in one call: the user counts and recent signups, the tenants, the failing webhook endpoints, the job queue depth and the health of the dependencies. The dashboard is cached for 30 seconds; generated_at tells when it was computed.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.AdminDashboardResponse}
+// @Router /admin/dashboard [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetAdminDashboard(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	dashboard, err := h.adminDashboardService.Get(c.Request().Context())
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Admin dashboard retrieved successfully", dashboard, nil)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DemoHandler handles demo mode HTTP requests
+type DemoHandler struct {
+	BaseHandler
+	demoService services.DemoService
+	cfg         *config.Config
+}
+
+// ProvideDemoHandler creates a new demo handler
+func ProvideDemoHandler(
+	demoService services.DemoService,
+	cfg *config.Config,
+) *DemoHandler {
+	return &DemoHandler{
+		BaseHandler: *NewBaseHandler(),
+		demoService: demoService,
+		cfg:         cfg,
+	}
+}
+
+// ResetDemo godoc
+// @Summary Reset demo data
+// @Description Delete every company and user and seed the demo dataset again. Only available when DEMO_MODE is enabled.
+// @Tags Demo
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.DemoSeedResponse}
+// @Router /demo/reset [post]
+// @Security BearerAuth
+func (h *DemoHandler) ResetDemo(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	result, err := h.demoService.Reset(c.Request().Context())
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Demo data reset successfully", result, nil)
+}
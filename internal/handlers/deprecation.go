@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeprecationHandler handles the HTTP requests about the deprecated surfaces of the API
+type DeprecationHandler struct {
+	BaseHandler
+	deprecationService services.DeprecationService
+	cfg                *config.Config
+}
+
+// ProvideDeprecationHandler creates a new deprecation handler
+func ProvideDeprecationHandler(deprecationService services.DeprecationService, cfg *config.Config) *DeprecationHandler {
+	return &DeprecationHandler{
+		BaseHandler:        *NewBaseHandler(),
+		deprecationService: deprecationService,
+		cfg:                cfg,
+	}
+}
+
+// GetDeprecations godoc
+// @Summary Get deprecation report
+// @Description Get the deprecated routes and request fields, the closest sunset first, with the consumers that still use them: API keys, OAuth clients or users, their tenant, number of calls and when they were first and last seen. Recent calls can take up to DEPRECATION_USAGE_FLUSH_INTERVAL to be counted.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.DeprecationReportResponse}
+// @Router /admin/deprecations [get]
+// @Security BearerAuth
+func (h *DeprecationHandler) GetDeprecations(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	report, err := h.deprecationService.Report(c.Request().Context())
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Deprecations retrieved successfully", report, nil)
+}
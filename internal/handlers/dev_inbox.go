@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DevInboxHandler handles the HTTP requests of the dev inbox, the emails captured instead
+// of being sent when EMAIL_CAPTURE is enabled
+type DevInboxHandler struct {
+	BaseHandler
+	devInboxService services.DevInboxService
+	cfg             *config.Config
+}
+
+// ProvideDevInboxHandler creates a new dev inbox handler
+func ProvideDevInboxHandler(
+	devInboxService services.DevInboxService,
+	cfg *config.Config,
+) *DevInboxHandler {
+	return &DevInboxHandler{
+		BaseHandler:     *NewBaseHandler(),
+		devInboxService: devInboxService,
+		cfg:             cfg,
+	}
+}
+
+// GetDevInboxEmails godoc
+// @Summary Get dev inbox emails
+// @Description Search the emails captured instead of being sent, most recent first. Only available when EMAIL_CAPTURE is enabled.
+// @Tags DevInbox
+// @Accept json
+// @Produce json
+// @Param q query string false "Search in the subject, recipients and bodies" example("Welcome")
+// @Param recipient query string false "To, Cc or Bcc address" example("jane@example.com")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.CapturedEmailResponse}
+// @Router /admin/dev-inbox [get]
+// @Security BearerAuth
+func (h *DevInboxHandler) GetDevInboxEmails(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	emails, err := h.devInboxService.List(c.Request().Context(), &dtos.DevInboxPageableRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		Q:         c.QueryParam("q"),
+		Recipient: c.QueryParam("recipient"),
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.CapturedEmailResponse, len(emails.Data))
+	for i, email := range emails.Data {
+		responseDto[i] = *dtos.NewCapturedEmailResponse(&email)
+	}
+
+	return h.SuccessResponse(c, "Dev inbox emails retrieved successfully", responseDto, emails.Pageable)
+}
+
+// GetDevInboxEmail godoc
+// @Summary Get dev inbox email by ID
+// @Description Get an email captured instead of being sent, with its bodies. Only available when EMAIL_CAPTURE is enabled.
+// @Tags DevInbox
+// @Accept json
+// @Produce json
+// @Param emailId path string true "Email ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CapturedEmailResponse}
+// @Router /admin/dev-inbox/{emailId} [get]
+// @Security BearerAuth
+func (h *DevInboxHandler) GetDevInboxEmail(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	email, err := h.devInboxService.Get(c.Request().Context(), c.Param("emailId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Dev inbox email retrieved successfully", dtos.NewCapturedEmailResponse(email), nil)
+}
+
+// PreviewDevInboxEmail godoc
+// @Summary Preview dev inbox email
+// @Description Render the HTML body of a captured email, or its text body when it has none, as a sandboxed page without scripts. Only available when EMAIL_CAPTURE is enabled.
+// @Tags DevInbox
+// @Produce html
+// @Param emailId path string true "Email ID"
+// @Success 200 {string} string "HTML body"
+// @Router /admin/dev-inbox/{emailId}/preview [get]
+// @Security BearerAuth
+func (h *DevInboxHandler) PreviewDevInboxEmail(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	email, err := h.devInboxService.Get(c.Request().Context(), c.Param("emailId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	body := email.HTMLBody
+	if body == "" {
+		body = "<pre>" + html.EscapeString(email.TextBody) + "</pre>"
+	}
+
+	// The body comes from the application templates, and their data from the users
+	c.Response().Header().Set("Content-Security-Policy", constants.DevInboxPreviewCSP)
+	return c.HTML(http.StatusOK, body)
+}
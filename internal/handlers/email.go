@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// EmailHandler handles the HTTP requests of the emails queued by the services, those
+// failing after their attempts, and the bounce and complaint notifications of SES
+type EmailHandler struct {
+	BaseHandler
+	emailService services.EmailService
+	cfg          *config.Config
+	validator    *validator.Validate
+}
+
+// ProvideEmailHandler creates a new email handler
+func ProvideEmailHandler(
+	emailService services.EmailService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *EmailHandler {
+	return &EmailHandler{
+		BaseHandler:  *NewBaseHandler(),
+		emailService: emailService,
+		cfg:          cfg,
+		validator:    validator,
+	}
+}
+
+// GetFailedEmails godoc
+// @Summary Get failed emails
+// @Description Get the queued emails still failing after their attempts, kept in the dead-letter queue, most recently failed first
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.FailedEmailResponse}
+// @Router /admin/emails/failed [get]
+// @Security BearerAuth
+func (h *EmailHandler) GetFailedEmails(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	emails, err := h.emailService.ListFailedEmails(c.Request().Context(), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Failed emails retrieved successfully", emails.Data, emails.Pageable)
+}
+
+// RedriveFailedEmails godoc
+// @Summary Re-drive failed emails
+// @Description Queue the failed emails again with all their attempts, those of ids or all of them when no ids are given
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Param request body dtos.RedriveFailedEmailsRequest false "Failed emails"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.RedriveFailedEmailsResponse}
+// @Router /admin/emails/failed/redrive [post]
+// @Security BearerAuth
+func (h *EmailHandler) RedriveFailedEmails(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.RedriveFailedEmailsRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	requeued, err := h.emailService.RedriveFailedEmails(c.Request().Context(), requestDto.IDs)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Failed emails requeued successfully", dtos.RedriveFailedEmailsResponse{Requeued: requeued}, nil)
+}
+
+// ReceiveSESNotification godoc
+// @Summary Receive SES notification
+// @Description Receive a message of the SNS topic of the SES bounce and complaint notifications, SES_NOTIFICATIONS_TOPIC_ARN, signed by SNS. The subscription of the endpoint is confirmed; the recipients of the hard bounces and complaints are suppressed, so that no email is sent to them anymore. A failure answers an error so that SNS delivers the message again.
+// @Tags Emails
+// @Accept json
+// @Produce json
+// @Param message body dtos.SNSMessage true "SNS message"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /email/ses-notifications [post]
+func (h *EmailHandler) ReceiveSESNotification(c echo.Context) error {
+	// SNS posts its messages as text/plain, so the body is decoded whatever its type
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, constants.SESNotificationMaxBodySize))
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid SNS message", err))
+	}
+
+	var message dtos.SNSMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid SNS message", err))
+	}
+
+	if err := h.emailService.HandleSESNotification(c.Request().Context(), &message); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Notification received successfully", nil, nil)
+}
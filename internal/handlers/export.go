@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// ExportHandler handles the HTTP requests about the audit records of the exports
+type ExportHandler struct {
+	BaseHandler
+	exportService services.ExportService
+	cfg           *config.Config
+	validator     *validator.Validate
+}
+
+// ProvideExportHandler creates a new export handler
+func ProvideExportHandler(
+	exportService services.ExportService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *ExportHandler {
+	return &ExportHandler{
+		BaseHandler:   *NewBaseHandler(),
+		exportService: exportService,
+		cfg:           cfg,
+		validator:     validator,
+	}
+}
+
+// GetExports godoc
+// @Summary Get export audit records
+// @Description Get the audit records of the CSV and NDJSON exports of the listings, most recent first: who exported which entity, with which filters and fields, and how many rows. Exports denied by the export policies are recorded too.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param entity query string false "Exported entity" Enums(users, companies, company_members)
+// @Param principal_id query string false "Subject of the user or ID of the API key" example("123")
+// @Param company_id query string false "Company the export is scoped to" example("123")
+// @Param status query string false "Status" Enums(started, completed, failed, denied)
+// @Param start_date query string false "Start date" example("2025-09-11T02:17:24.290538Z")
+// @Param end_date query string false "End date" example("2025-09-11T02:17:24.290538Z")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.ExportAuditResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Router /admin/exports [get]
+// @Security BearerAuth
+func (h *ExportHandler) GetExports(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	// Parse and validate dates
+	dateRange, err := utils.ParseDateRange(c.QueryParam("start_date"), c.QueryParam("end_date"))
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid date range", err))
+	}
+
+	pageableRequest := dtos.ExportAuditPageableRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		Entity:      c.QueryParam("entity"),
+		PrincipalID: c.QueryParam("principal_id"),
+		CompanyID:   c.QueryParam("company_id"),
+		Status:      c.QueryParam("status"),
+		StartDate:   dateRange.StartDate,
+		EndDate:     dateRange.EndDate,
+	}
+	if err := h.validator.Struct(pageableRequest); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	audits, err := h.exportService.List(c.Request().Context(), &pageableRequest)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.ExportAuditResponse, len(audits.Data))
+	for i, audit := range audits.Data {
+		responseDto[i] = *dtos.NewExportAuditResponse(&audit)
+	}
+
+	return h.SuccessResponse(c, "Export audit records retrieved successfully", responseDto, audits.Pageable)
+}
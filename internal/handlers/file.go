@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// FileHandler handles the HTTP requests of the files of the file registry
+type FileHandler struct {
+	BaseHandler
+	fileService services.FileService
+	cfg         *config.Config
+	validator   *validator.Validate
+}
+
+// ProvideFileHandler creates a new file handler
+func ProvideFileHandler(
+	fileService services.FileService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *FileHandler {
+	return &FileHandler{
+		BaseHandler: *NewBaseHandler(),
+		fileService: fileService,
+		cfg:         cfg,
+		validator:   validator,
+	}
+}
+
+// GetFiles godoc
+// @Summary Get files
+// @Description Get the files of the file registry with the metadata of their object, most recent first, to find the objects of an owner or the failed and quarantined files to clean up
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param owner_id query string false "ID of the user or company owning the files"
+// @Param status query string false "Status" Enums(pending, scanning, available, failed, quarantined)
+// @Param prefix query string false "Prefix of the keys" example("avatars/")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.FileResponse}
+// @Router /files [get]
+// @Security BearerAuth
+func (h *FileHandler) GetFiles(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	pageableRequest := dtos.FilePageableRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		OwnerID: c.QueryParam("owner_id"),
+		Status:  c.QueryParam("status"),
+		Prefix:  c.QueryParam("prefix"),
+	}
+	if err := h.validator.Struct(pageableRequest); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	files, err := h.fileService.List(c.Request().Context(), &pageableRequest)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	responseDto := make([]dtos.FileResponse, len(files.Data))
+	for i, file := range files.Data {
+		responseDto[i] = *dtos.NewFileResponse(&file)
+	}
+
+	return h.SuccessResponse(c, "Files retrieved successfully", responseDto, files.Pageable)
+}
+
+// DeleteFile godoc
+// @Summary Delete file
+// @Description Delete a file of the file registry and its object. An available avatar, logo or invoice is in use by its user or company and cannot be deleted: it is replaced through them instead.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /files/{id} [delete]
+// @Security BearerAuth
+func (h *FileHandler) DeleteFile(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	if err := h.fileService.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "File deleted successfully", nil, nil)
+}
+
+// DownloadFile godoc
+// @Summary Download file
+// @Description Stream an available file of the file registry through the server, for the buckets that stay private without presigned URLs. A single byte range of the Range header is served as 206 Partial Content, unless the If-Range header does not match the ETag; an unsatisfiable range is 416. The Repr-Digest header holds the SHA-256 of the whole file for the client to verify it. Avatars are downloaded with the user view roles, logos with the company view roles, invoices by the admins and the company managers of the company of the invoice, by the organization of their token, presigned uploads by the admins and the user who uploaded them, and the other files by the admins.
+// @Tags Files
+// @Produce octet-stream
+// @Param id path string true "File ID"
+// @Param Range header string false "Byte range, e.g. bytes=0-1023"
+// @Param If-Range header string false "ETag the range applies to"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Router /files/{id}/download [get]
+// @Security BearerAuth
+func (h *FileHandler) DownloadFile(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	ctx := c.Request().Context()
+	organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string)
+	file, err := h.fileService.Get(ctx, c.Param("id"), middlewares.ExtractRoles(claims, h.cfg.KeycloakClientID), organizationID, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// The object of a file never changes, so its ID identifies the content
+	etag := strconv.Quote(file.ID)
+	req := c.Request()
+	res := c.Response()
+
+	// A range is ignored when the client holds another version of the content than If-Range
+	var byteRange *utils.ByteRange
+	if ifRange := req.Header.Get("If-Range"); ifRange == "" || ifRange == etag {
+		byteRange, err = utils.ParseByteRange(req.Header.Get("Range"), file.Size)
+		if stderrors.Is(err, utils.ErrRangeNotSatisfiable) {
+			res.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(file.Size, 10))
+			return c.NoContent(http.StatusRequestedRangeNotSatisfiable)
+		}
+	}
+
+	reader, err := h.fileService.Open(ctx, file, byteRange)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+	defer reader.Close()
+
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(file.Key)}))
+	res.Header().Set("Accept-Ranges", "bytes")
+	res.Header().Set("ETag", etag)
+	if digest, err := hex.DecodeString(file.Checksum); err == nil && len(digest) == sha256.Size {
+		// Digest of the whole file, also of a range of it, for the client to verify
+		res.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(digest)+":")
+	}
+	res.Header().Set("Cache-Control", "private")
+	// Keep nginx from buffering the stream
+	res.Header().Set("X-Accel-Buffering", "no")
+
+	status := http.StatusOK
+	length := file.Size
+	if byteRange != nil {
+		status = http.StatusPartialContent
+		length = byteRange.Length()
+		res.Header().Set("Content-Range", byteRange.ContentRange(file.Size))
+	}
+	res.Header().Set(echo.HeaderContentLength, strconv.FormatInt(length, 10))
+	res.WriteHeader(status)
+
+	// Once the response has started its status can no longer change, so an error is
+	// logged and the response ends short of its Content-Length: the server closes the
+	// connection and the client sees a failed transfer. The reader of a corrupted file
+	// fails before its last byte, so such a file is never served whole.
+	_, err = io.Copy(res, reader)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == constants.ChecksumMismatch {
+		logger.Log.Error("File download does not match its checksum",
+			zap.String("file_id", file.ID),
+			zap.Error(err),
+		)
+	} else if err != nil {
+		logger.Log.Warn("File download ended early",
+			zap.String("file_id", file.ID),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
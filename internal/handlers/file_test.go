@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeFileRegistry serves a single file with its content
+type fakeFileRegistry struct {
+	files.Registry
+	file    *models.File
+	content string
+}
+
+func (r *fakeFileRegistry) GetByID(ctx context.Context, id string) (*models.File, error) {
+	return r.file, nil
+}
+
+func (r *fakeFileRegistry) Open(ctx context.Context, file *models.File, offset int64, length int64) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(r.content)), nil
+}
+
+func TestFileHandler_DownloadFile_Upload(t *testing.T) {
+	logger.Log = zap.NewNop()
+	const fileID = "0199e3a4-5b6c-7d8e-9f00-112233445566"
+	cfg := &config.Config{KeycloakKeyClaim: "claims", KeycloakClientID: "api"}
+	userRepo := &fakeUserRepository{users: map[string]string{"kc-1": "user-1", "kc-2": "user-2"}}
+
+	tests := []struct {
+		name           string
+		subject        string
+		roles          []string
+		expectedStatus int
+	}{
+		{name: "downloaded by its owner", subject: "kc-1", roles: []string{constants.RoleUser}, expectedStatus: http.StatusOK},
+		{name: "downloaded by another user", subject: "kc-2", roles: []string{constants.RoleUser}, expectedStatus: http.StatusForbidden},
+		{name: "downloaded by an unknown user", subject: "kc-3", roles: []string{constants.RoleUser}, expectedStatus: http.StatusForbidden},
+		{name: "downloaded by an admin", subject: "kc-2", roles: []string{constants.RoleAdmin}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := factory.File().WithKey("uploads/user-1/report.pdf").WithOwner("user-1").WithContent("application/pdf", 6).Build()
+			file.ID = fileID
+			registry := &fakeFileRegistry{file: file, content: "report"}
+			handler := ProvideFileHandler(services.ProvideFileService(registry, nil, userRepo), cfg, nil)
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			c.SetParamNames("id")
+			c.SetParamValues(fileID)
+			claims := &auth.TokenClaims{Sub: tt.subject}
+			claims.RealmAccess.Roles = tt.roles
+			c.Set(cfg.KeycloakKeyClaim, claims)
+
+			assert.NoError(t, handler.DownloadFile(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "report", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/graph"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQLHandler serves the GraphQL endpoint next to the REST API
+type GraphQLHandler struct {
+	BaseHandler
+	server      *handler.Server
+	userRepo    repositories.UserRepository
+	companyRepo repositories.CompanyRepository
+	cfg         *config.Config
+}
+
+// ProvideGraphQLHandler creates a new GraphQL handler
+func ProvideGraphQLHandler(
+	resolver *graph.Resolver,
+	userRepo repositories.UserRepository,
+	companyRepo repositories.CompanyRepository,
+	cfg *config.Config,
+) *GraphQLHandler {
+	return &GraphQLHandler{
+		BaseHandler: *NewBaseHandler(),
+		server:      graph.NewServer(resolver, cfg),
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		cfg:         cfg,
+	}
+}
+
+// Query executes a GraphQL request with fresh dataloaders. The route must run behind the
+// auth middleware; roles are checked by each resolver.
+func (h *GraphQLHandler) Query(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	ctx := graph.NewContext(c.Request().Context(), c, graph.NewLoaders(h.userRepo, h.companyRepo))
+	h.server.ServeHTTP(c.Response(), c.Request().WithContext(ctx))
+	return nil
+}
+
+// Playground serves the GraphiQL playground for the GraphQL endpoint
+func (h *GraphQLHandler) Playground(c echo.Context) error {
+	playground.Handler("GraphQL", "/api/v1/graphql").ServeHTTP(c.Response(), c.Request())
+	return nil
+}
@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"net/http"
+	"time"
+
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/db"
 	"golang-boilerplate/internal/dtos"
-	"time"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/shutdown"
 
 	"github.com/labstack/echo/v4"
 )
@@ -12,16 +20,31 @@ import (
 // HealthHandler handles health check requests
 type HealthHandler struct {
 	BaseHandler
-	cfg *config.Config
-	db  *db.PostgresDB
+	cfg         *config.Config
+	db          *db.PostgresDB
+	authService auth.AuthService
+	registry    *monitoring.HealthRegistry
+	readiness   *shutdown.Readiness
+	integration services.IntegrationHealthService
 }
 
 // NewHealthHandler creates a new health handler
-func ProvideHealthHandler(cfg *config.Config, db *db.PostgresDB) *HealthHandler {
+func ProvideHealthHandler(
+	cfg *config.Config,
+	db *db.PostgresDB,
+	authService auth.AuthService,
+	registry *monitoring.HealthRegistry,
+	readiness *shutdown.Readiness,
+	integration services.IntegrationHealthService,
+) *HealthHandler {
 	return &HealthHandler{
 		BaseHandler: *NewBaseHandler(),
 		cfg:         cfg,
 		db:          db,
+		authService: authService,
+		registry:    registry,
+		readiness:   readiness,
+		integration: integration,
 	}
 }
 
@@ -45,13 +68,87 @@ func (h *HealthHandler) HealthCheck(c echo.Context) error {
 	return response
 }
 
+// Liveness reports that the process is alive, without checking its dependencies; a failing
+// liveness probe restarts the instance. Probes are served at the root, outside the API base
+// path, so they are not part of the API documentation.
+func (h *HealthHandler) Liveness(c echo.Context) error {
+	healthResponse := dtos.HealthResponse{
+		Status:    "alive",
+		Timestamp: time.Now().UTC(),
+		Version:   h.cfg.AppVersion,
+		Service:   h.cfg.AppName,
+	}
+
+	return h.SuccessResponse(c, "Service is alive", healthResponse, nil)
+}
+
+// Readiness reports whether the critical dependencies of the health registry are
+// reachable. It reports not ready as soon as the graceful shutdown starts, so that the load
+// balancers stop routing requests to the instance.
+func (h *HealthHandler) Readiness(c echo.Context) error {
+	if !h.readiness.Ready() {
+		return h.HandleError(c, errors.NewAppError(constants.ServiceUnavailable, "Server is shutting down",
+			errors.ErrorTypeInternal, http.StatusServiceUnavailable))
+	}
+
+	report := h.registry.Check(c.Request().Context())
+	if report.Status == monitoring.HealthStatusUnhealthy {
+		return h.HandleError(c, unhealthyError("Service is not ready", report))
+	}
+	return h.SuccessResponse(c, "Service is ready", report, nil)
+}
+
+// DependenciesHealthCheck godoc
+// @Summary Dependencies Health Check
+// @Description Check every dependency registered in the health registry: the database, the cache, the auth provider, the storage, the email and the payment providers, with their status and latency. The rollup is unhealthy when a critical dependency fails, with a 503, and degraded when another one does.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=monitoring.HealthReport}
+// @Failure 503 {object} object{meta=dtos.Meta,data=object{status=string,dependencies=[]monitoring.DependencyHealth}}
+// @Router /health/dependencies [get]
+func (h *HealthHandler) DependenciesHealthCheck(c echo.Context) error {
+	report := h.registry.Check(c.Request().Context())
+	if report.Status == monitoring.HealthStatusUnhealthy {
+		return h.HandleError(c, unhealthyError("Service is unhealthy", report))
+	}
+	if report.Status == monitoring.HealthStatusDegraded {
+		return h.SuccessResponse(c, "Service is degraded", report, nil)
+	}
+	return h.SuccessResponse(c, "Service is healthy", report, nil)
+}
+
+// GetIntegrationsHealth godoc
+// @Summary Integrations health
+// @Description Get the health of the integrations from their scheduled probes (INTEGRATION_PROBE_SCHEDULE): the Keycloak token endpoint, the SES send quota, the storage bucket, the payment provider, the database and the cache, with the result and latency of their last probe and their uptime, failures and average latency over the last 24 hours. The probes are recorded by the scheduler, so the integrations are not called by the request.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.IntegrationHealthResponse}
+// @Router /admin/integrations/health [get]
+// @Security BearerAuth
+func (h *HealthHandler) GetIntegrationsHealth(c echo.Context) error {
+	health, err := h.integration.Get(c.Request().Context())
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Integrations health retrieved successfully", health, nil)
+}
+
+// unhealthyError carries the report of an unhealthy rollup in a 503
+func unhealthyError(message string, report monitoring.HealthReport) *errors.AppError {
+	return errors.NewAppError(constants.ServiceUnavailable, message, errors.ErrorTypeExternal, http.StatusServiceUnavailable).
+		WithContext("status", report.Status).
+		WithContext("dependencies", report.Dependencies)
+}
+
 // DatabaseHealthCheck godoc
 // @Summary Database Health Check
-// @Description Check if the database connection is healthy
+// @Description Check if the database connection is healthy. Deprecated, use the database entry of /health/dependencies.
 // @Tags Health
 // @Accept json
 // @Produce json
 // @Success 200 {object} object{meta=dtos.Meta,data=object}
+// @Deprecated
 // @Router /health/database [get]
 func (h *HealthHandler) DatabaseHealthCheck(c echo.Context) error {
 	if h.db == nil {
@@ -74,14 +171,32 @@ func (h *HealthHandler) DatabaseHealthCheck(c echo.Context) error {
 	return h.InternalErrorResponse(c, "Database is unhealthy", nil)
 }
 
-// DatabaseMetrics godoc
-// @Summary Database Connection Metrics
-// @Description Get detailed database connection metrics
+// AuthHealthCheck godoc
+// @Summary Auth Provider Health Check
+// @Description Check that the realm of the auth provider is reachable and its endpoints resolve. The endpoints are discovered again at most once a minute. Deprecated, use the auth entry of /health/dependencies.
 // @Tags Health
 // @Accept json
 // @Produce json
 // @Success 200 {object} object{meta=dtos.Meta,data=object}
-// @Router /health/metrics [get]
+// @Failure 500 {object} object{meta=dtos.Meta}
+// @Deprecated
+// @Router /health/auth [get]
+func (h *HealthHandler) AuthHealthCheck(c echo.Context) error {
+	if err := h.authService.HealthCheck(c.Request().Context()); err != nil {
+		return h.InternalErrorResponse(c, "Auth provider is unhealthy", nil)
+	}
+
+	response := map[string]interface{}{
+		"auth_provider": h.cfg.AuthProvider,
+		"realm":         h.authService.GetRealm(),
+		"timestamp":     time.Now().UTC(),
+	}
+
+	return h.SuccessResponse(c, "Auth provider is healthy", response, nil)
+}
+
+// DatabaseMetrics returns detailed database connection metrics. It is served on the
+// internal listener only, so it is not part of the public API documentation.
 func (h *HealthHandler) DatabaseMetrics(c echo.Context) error {
 	if h.db == nil {
 		return h.InternalErrorResponse(c, "Database not initialized", nil)
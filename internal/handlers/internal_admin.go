@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// InternalAdminHandler operates the running instance: its log level, its cache and its
+// metrics. It is served on the internal listener only, so it is not part of the public API
+// documentation.
+type InternalAdminHandler struct {
+	BaseHandler
+	cache        cache.Cache
+	cacheMetrics *cache.Metrics
+	validator    *validator.Validate
+}
+
+// ProvideInternalAdminHandler creates a new internal admin handler
+func ProvideInternalAdminHandler(cache cache.Cache, cacheMetrics *cache.Metrics, validator *validator.Validate) *InternalAdminHandler {
+	return &InternalAdminHandler{
+		BaseHandler:  *NewBaseHandler(),
+		cache:        cache,
+		cacheMetrics: cacheMetrics,
+		validator:    validator,
+	}
+}
+
+// Metrics serves the metrics of the instance in the Prometheus text format, for a scrape
+// of each pod
+func (h *InternalAdminHandler) Metrics(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentType, constants.PrometheusContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return h.cacheMetrics.WritePrometheus(c.Response())
+}
+
+// GetLogLevel returns the level of the logs of the instance
+func (h *InternalAdminHandler) GetLogLevel(c echo.Context) error {
+	return h.SuccessResponse(c, "Log level retrieved successfully", dtos.LogLevelResponse{Level: logger.Level.String()}, nil)
+}
+
+// SetLogLevel changes the level of the logs of the instance until it restarts, e.g. to
+// debug an issue without a redeploy. Other instances keep their level.
+func (h *InternalAdminHandler) SetLogLevel(c echo.Context) error {
+	var requestDto dtos.LogLevelRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	level, err := zapcore.ParseLevel(requestDto.Level)
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid log level", err).
+			WithContext("level", requestDto.Level))
+	}
+	previous := logger.Level.Level()
+	logger.Level.SetLevel(level)
+	logger.Log.Warn("Log level changed",
+		zap.String("previous_level", previous.String()),
+		zap.String("level", level.String()),
+		zap.String("ip", c.RealIP()),
+	)
+
+	return h.SuccessResponse(c, "Log level changed successfully", dtos.LogLevelResponse{Level: level.String()}, nil)
+}
+
+// FlushCache removes the cache keys matching a pattern, e.g. admin:* or * for all of them.
+// The cache is shared by the instances, so the keys are removed for all of them.
+func (h *InternalAdminHandler) FlushCache(c echo.Context) error {
+	var requestDto dtos.CacheFlushRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	removed, err := h.cache.Flush(c.Request().Context(), requestDto.Pattern)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+	logger.Log.Warn("Cache flushed",
+		zap.String("pattern", requestDto.Pattern),
+		zap.Int64("removed", removed),
+		zap.String("ip", c.RealIP()),
+	)
+
+	return h.SuccessResponse(c, "Cache flushed successfully", dtos.CacheFlushResponse{Pattern: requestDto.Pattern, Removed: removed}, nil)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// InvitationHandler handles the HTTP requests of the invitations to the companies
+type InvitationHandler struct {
+	BaseHandler
+	invitationService services.InvitationService
+	cfg               *config.Config
+	validator         *validator.Validate
+}
+
+// ProvideInvitationHandler creates a new invitation handler
+func ProvideInvitationHandler(
+	invitationService services.InvitationService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *InvitationHandler {
+	return &InvitationHandler{
+		BaseHandler:       *NewBaseHandler(),
+		invitationService: invitationService,
+		cfg:               cfg,
+		validator:         validator,
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Invite to company
+// @Description Invite a person by email to join a company. The email holds a link with a signed token, valid for INVITATION_TTL, that accepts the invitation. The invitation is deleted again when the email fails to be sent; an email with a pending invitation to the company gets a 409.
+// @Tags Invitation
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param invitation body dtos.CreateInvitationRequest true "Invitation"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.InvitationResponse}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Router /companies/{id}/invitations [post]
+// @Security BearerAuth
+func (h *InvitationHandler) CreateInvitation(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateInvitationRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	invitation, err := h.invitationService.Create(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Invitation sent successfully", dtos.NewInvitationResponse(invitation), nil)
+}
+
+// GetInvitations godoc
+// @Summary Get invitations
+// @Description Get the invitations of a company with their status, most recent first
+// @Tags Invitation
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.InvitationResponse}
+// @Router /companies/{id}/invitations [get]
+// @Security BearerAuth
+func (h *InvitationHandler) GetInvitations(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	invitations, err := h.invitationService.List(c.Request().Context(), c.Param("id"), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.InvitationResponse, len(invitations.Data))
+	for i, invitation := range invitations.Data {
+		responseDto[i] = *dtos.NewInvitationResponse(&invitation)
+	}
+
+	return h.SuccessResponse(c, "Invitations retrieved successfully", responseDto, invitations.Pageable)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revoke a pending invitation of a company, whose token then no longer accepts it. An accepted invitation gets a 409.
+// @Tags Invitation
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.InvitationResponse}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Router /companies/{id}/invitations/{invitationId} [delete]
+// @Security BearerAuth
+func (h *InvitationHandler) RevokeInvitation(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	invitation, err := h.invitationService.Revoke(c.Request().Context(), c.Param("id"), c.Param("invitationId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Invitation revoked successfully", dtos.NewInvitationResponse(invitation), nil)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Accept an invitation with the token of its email. A person without an account gets a Keycloak user with the password of the request; then they are added to the organization of the company and to its members. The steps done are undone when a later one fails, so that a failed acceptance can be retried with the same token.
+// @Tags Invitation
+// @Accept json
+// @Produce json
+// @Param invitation body dtos.AcceptInvitationRequest true "Acceptance"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.AcceptInvitationResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 401 {object} object{meta=dtos.Meta}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Failure 429 {object} object{meta=dtos.Meta}
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c echo.Context) error {
+	var requestDto dtos.AcceptInvitationRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	result, err := h.invitationService.Accept(c.Request().Context(), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Invitation accepted successfully", result, nil)
+}
+
+func (h *InvitationHandler) bind(c echo.Context, requestDto any) error {
+	if err := c.Bind(requestDto); err != nil {
+		return errors.ValidationError("Invalid request body", err)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors)
+		}
+		return errors.ValidationError("Validation failed", err)
+	}
+
+	return nil
+}
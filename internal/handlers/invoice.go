@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// InvoiceHandler handles the HTTP requests of the invoices of the companies
+type InvoiceHandler struct {
+	BaseHandler
+	invoiceService services.InvoiceService
+	cfg            *config.Config
+}
+
+// ProvideInvoiceHandler creates a new invoice handler
+func ProvideInvoiceHandler(
+	invoiceService services.InvoiceService,
+	cfg *config.Config,
+) *InvoiceHandler {
+	return &InvoiceHandler{
+		BaseHandler:    *NewBaseHandler(),
+		invoiceService: invoiceService,
+		cfg:            cfg,
+	}
+}
+
+// GetInvoices godoc
+// @Summary Get invoices
+// @Description Get the invoices of a company synced from the payment provider, most recently issued first. Amounts are in the smallest unit of the currency. Company managers only get the invoices of the company of the organization of their token.
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.InvoiceResponse}
+// @Router /companies/{id}/invoices [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) GetInvoices(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string)
+	invoices, err := h.invoiceService.List(c.Request().Context(), c.Param("id"), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	}, middlewares.ExtractRoles(claims, h.cfg.KeycloakClientID), organizationID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	responseDto := make([]dtos.InvoiceResponse, len(invoices.Data))
+	for i, invoice := range invoices.Data {
+		responseDto[i] = *dtos.NewInvoiceResponse(&invoice)
+	}
+
+	return h.SuccessResponse(c, "Invoices retrieved successfully", responseDto, invoices.Pageable)
+}
+
+// GetInvoicePDF godoc
+// @Summary Get invoice PDF
+// @Description Get a presigned URL of the branded PDF document of an invoice of a company. The document is rendered and stored the first time it is requested, and again once the invoice changed. Company managers only get the invoices of the company of the organization of their token.
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param invoiceId path string true "Invoice ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.InvoicePDFResponse}
+// @Router /companies/{id}/invoices/{invoiceId}/pdf [get]
+// @Security BearerAuth
+func (h *InvoiceHandler) GetInvoicePDF(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string)
+	pdf, err := h.invoiceService.GetInvoicePDF(c.Request().Context(), c.Param("id"), c.Param("invoiceId"),
+		middlewares.ExtractRoles(claims, h.cfg.KeycloakClientID), organizationID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Invoice PDF retrieved successfully", pdf, nil)
+}
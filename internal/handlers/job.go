@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/jobs"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JobHandler manages the dead-letter queue of the task queue. It is served on the
+// internal listener only, so it is not part of the public API documentation.
+type JobHandler struct {
+	BaseHandler
+	client *jobs.Client
+}
+
+// ProvideJobHandler creates a new job handler
+func ProvideJobHandler(client *jobs.Client) *JobHandler {
+	return &JobHandler{
+		BaseHandler: *NewBaseHandler(),
+		client:      client,
+	}
+}
+
+// GetDeadJobs returns the jobs of the dead-letter queue, most recently failed first
+func (h *JobHandler) GetDeadJobs(c echo.Context) error {
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	deadJobs, err := h.client.ListDead(c.Request().Context(), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	responseDto := make([]dtos.JobResponse, len(deadJobs.Data))
+	for i, job := range deadJobs.Data {
+		responseDto[i] = *dtos.NewJobResponse(&job)
+	}
+
+	return h.SuccessResponse(c, "Dead jobs retrieved successfully", responseDto, deadJobs.Pageable)
+}
+
+// RequeueJob runs a job of the dead-letter queue again, with all its attempts
+func (h *JobHandler) RequeueJob(c echo.Context) error {
+	job, err := h.client.Requeue(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Job requeued successfully", dtos.NewJobResponse(job), nil)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// KeycloakSyncHandler handles the HTTP requests reconciling the users and companies with
+// Keycloak, and the events of the realm
+type KeycloakSyncHandler struct {
+	BaseHandler
+	keycloakSyncService services.KeycloakSyncService
+}
+
+// ProvideKeycloakSyncHandler creates a new Keycloak sync handler
+func ProvideKeycloakSyncHandler(keycloakSyncService services.KeycloakSyncService) *KeycloakSyncHandler {
+	return &KeycloakSyncHandler{
+		BaseHandler:         *NewBaseHandler(),
+		keycloakSyncService: keycloakSyncService,
+	}
+}
+
+// SyncKeycloak godoc
+// @Summary Sync with Keycloak
+// @Description Reconcile the users and companies with the users, organizations and client roles of Keycloak, like the scheduled job of KEYCLOAK_SYNC_SCHEDULE: create the users and companies missing locally, link the users to the Keycloak user of their email, fix the drift of their names, emails, memberships and roles, and disable the users disabled in or deleted from Keycloak. With dry_run, the default, the changes are only reported.
+// @Tags Admin
+// @Produce json
+// @Param dry_run query bool false "Only report the changes" default(true)
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.KeycloakSyncReport}
+// @Router /admin/keycloak-sync [post]
+// @Security BearerAuth
+func (h *KeycloakSyncHandler) SyncKeycloak(c echo.Context) error {
+	dryRun := true
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, map[string]string{
+				"dry_run": "dry_run must be a boolean",
+			}))
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.keycloakSyncService.Sync(c.Request().Context(), dryRun)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Keycloak synced successfully", report, nil)
+}
+
+// ReceiveEvent godoc
+// @Summary Receive Keycloak event
+// @Description Receive an admin or user event of the realm, posted by an event listener provider of Keycloak and signed with KEYCLOAK_EVENTS_SECRET: the hex HMAC-SHA256 of the body in the X-Keycloak-Signature header. The updates, disabling and deletions of the users (admin events on users/{id}, UPDATE_EMAIL, UPDATE_PROFILE and DELETE_ACCOUNT user events) bring the user to its current state in Keycloak; the other events are acknowledged and ignored. A failure answers an error so that the event is posted again.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Keycloak-Signature header string true "HMAC-SHA256 of the body"
+// @Param event body dtos.KeycloakEvent true "Keycloak event"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /keycloak/events [post]
+func (h *KeycloakSyncHandler) ReceiveEvent(c echo.Context) error {
+	var event dtos.KeycloakEvent
+	if err := c.Bind(&event); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid event", err))
+	}
+
+	if err := h.keycloakSyncService.HandleEvent(c.Request().Context(), &event); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Event received successfully", nil, nil)
+}
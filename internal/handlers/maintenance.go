@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/maintenance"
+	"golang-boilerplate/internal/models"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// MaintenanceHandler handles the HTTP requests of the maintenance tasks, which run the
+// long maintenance operations in batches on the workers
+type MaintenanceHandler struct {
+	BaseHandler
+	manager   *maintenance.Manager
+	cfg       *config.Config
+	validator *validator.Validate
+}
+
+// ProvideMaintenanceHandler creates a new maintenance handler
+func ProvideMaintenanceHandler(
+	manager *maintenance.Manager,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		BaseHandler: *NewBaseHandler(),
+		manager:     manager,
+		cfg:         cfg,
+		validator:   validator,
+	}
+}
+
+// GetOperations godoc
+// @Summary Get maintenance operations
+// @Description Get the operations maintenance tasks can run
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.MaintenanceOperationResponse}
+// @Router /admin/maintenance/operations [get]
+// @Security BearerAuth
+func (h *MaintenanceHandler) GetOperations(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	return h.SuccessResponse(c, "Maintenance operations retrieved successfully", h.manager.Operations(), nil)
+}
+
+// CreateTask godoc
+// @Summary Create maintenance task
+// @Description Start a task of a maintenance operation, run in batches on the workers. An operation has at most one running, paused or failed task.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param task body dtos.CreateMaintenanceTaskRequest true "Task"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks [post]
+// @Security BearerAuth
+func (h *MaintenanceHandler) CreateTask(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateMaintenanceTaskRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	task, err := h.manager.Create(c.Request().Context(), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Maintenance task created successfully", dtos.NewMaintenanceTaskResponse(task), nil)
+}
+
+// GetTasks godoc
+// @Summary Get maintenance tasks
+// @Description Get the maintenance tasks and their progress, most recently started first
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks [get]
+// @Security BearerAuth
+func (h *MaintenanceHandler) GetTasks(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	tasks, err := h.manager.List(c.Request().Context(), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.MaintenanceTaskResponse, len(tasks.Data))
+	for i, task := range tasks.Data {
+		responseDto[i] = *dtos.NewMaintenanceTaskResponse(&task)
+	}
+
+	return h.SuccessResponse(c, "Maintenance tasks retrieved successfully", responseDto, tasks.Pageable)
+}
+
+// GetTask godoc
+// @Summary Get maintenance task by ID
+// @Description Get a maintenance task and its progress
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks/{id} [get]
+// @Security BearerAuth
+func (h *MaintenanceHandler) GetTask(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	task, err := h.manager.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Maintenance task retrieved successfully", dtos.NewMaintenanceTaskResponse(task), nil)
+}
+
+// UpdateTask godoc
+// @Summary Update maintenance task
+// @Description Change the batch size and throttling of a running, paused or failed task; a running task applies them from its next batch
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param task body dtos.UpdateMaintenanceTaskRequest true "Settings"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks/{id} [patch]
+// @Security BearerAuth
+func (h *MaintenanceHandler) UpdateTask(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.UpdateMaintenanceTaskRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	task, err := h.manager.Update(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Maintenance task updated successfully", dtos.NewMaintenanceTaskResponse(task), nil)
+}
+
+// PauseTask godoc
+// @Summary Pause maintenance task
+// @Description Stop a running task after its current batch; it keeps its progress until resumed
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks/{id}/pause [post]
+// @Security BearerAuth
+func (h *MaintenanceHandler) PauseTask(c echo.Context) error {
+	return h.control(c, h.manager.Pause, "Maintenance task paused successfully")
+}
+
+// ResumeTask godoc
+// @Summary Resume maintenance task
+// @Description Run a paused or failed task again from its last saved batch
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks/{id}/resume [post]
+// @Security BearerAuth
+func (h *MaintenanceHandler) ResumeTask(c echo.Context) error {
+	return h.control(c, h.manager.Resume, "Maintenance task resumed successfully")
+}
+
+// CancelTask godoc
+// @Summary Cancel maintenance task
+// @Description End a running, paused or failed task; a running task stops after its current batch
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MaintenanceTaskResponse}
+// @Router /admin/maintenance/tasks/{id}/cancel [post]
+// @Security BearerAuth
+func (h *MaintenanceHandler) CancelTask(c echo.Context) error {
+	return h.control(c, h.manager.Cancel, "Maintenance task cancelled successfully")
+}
+
+// control applies a change of status to the task of the request
+func (h *MaintenanceHandler) control(
+	c echo.Context,
+	apply func(ctx context.Context, id string) (*models.MaintenanceTask, error),
+	message string,
+) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	task, err := apply(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, message, dtos.NewMaintenanceTaskResponse(task), nil)
+}
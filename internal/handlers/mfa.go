@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MFAHandler handles the HTTP requests of the OTP authenticators of the users
+type MFAHandler struct {
+	BaseHandler
+	mfaService services.MFAService
+	cfg        *config.Config
+}
+
+// ProvideMFAHandler creates a new MFA handler
+func ProvideMFAHandler(mfaService services.MFAService, cfg *config.Config) *MFAHandler {
+	return &MFAHandler{
+		BaseHandler: *NewBaseHandler(),
+		mfaService:  mfaService,
+		cfg:         cfg,
+	}
+}
+
+// GetMFAStatus godoc
+// @Summary Get MFA status
+// @Description Get whether the user has an OTP authenticator and whether Keycloak asks them to configure one at their next login
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MFAStatusResponse}
+// @Router /users/{id}/mfa [get]
+// @Security BearerAuth
+func (h *MFAHandler) GetMFAStatus(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	status, err := h.mfaService.Status(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "MFA status retrieved successfully", status, nil)
+}
+
+// RequireMFA godoc
+// @Summary Require MFA
+// @Description Ask the user to configure an OTP authenticator at their next login, with the CONFIGURE_TOTP required action of Keycloak. The login cannot complete until they do.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MFAStatusResponse}
+// @Router /users/{id}/mfa/require [post]
+// @Security BearerAuth
+func (h *MFAHandler) RequireMFA(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	status, err := h.mfaService.Require(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "MFA required successfully", status, nil)
+}
+
+// EnrollMFA godoc
+// @Summary Enroll MFA
+// @Description Email the user a Keycloak link to configure an OTP authenticator now, valid for the action token lifespan of the realm. A user who already has one gets a 409.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MFAStatusResponse}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Router /users/{id}/mfa/enroll [post]
+// @Security BearerAuth
+func (h *MFAHandler) EnrollMFA(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	status, err := h.mfaService.Enroll(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "MFA enrollment email sent successfully", status, nil)
+}
+
+// ResetMFA godoc
+// @Summary Reset MFA
+// @Description Remove the OTP authenticators of the user, e.g. after the loss of their device, and the request to configure one. Require MFA again to have them configure a new one.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.MFAStatusResponse}
+// @Router /users/{id}/mfa [delete]
+// @Security BearerAuth
+func (h *MFAHandler) ResetMFA(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	status, err := h.mfaService.Reset(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "MFA reset successfully", status, nil)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// FilePart describes a file part accepted by a hybrid multipart request
+type FilePart struct {
+	// Field is the form field name of the file
+	Field    string
+	Required bool
+	// MaxSize is the maximum accepted size in bytes
+	MaxSize int64
+	// ContentTypes maps the accepted content types, detected from the file content, to
+	// their file extensions
+	ContentTypes map[string]string
+}
+
+// BindMultipartJSON binds a multipart request made of a JSON document in the payloadField
+// form value plus file parts. The document is decoded into payload, rejecting unknown
+// fields, and validated; each file is checked against its FilePart. Failures of both parts
+// are reported together in one validation error, keyed "<payloadField>.<Field>" for the
+// document and by form field for files.
+//
+// The returned files are keyed by form field, with the detected content type set in their
+// Content-Type header. Optional files that were not sent are absent.
+func (b *BaseHandler) BindMultipartJSON(c echo.Context, validate *validator.Validate, payloadField string, payload any, parts ...FilePart) (map[string]*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, errors.ValidationError("Invalid multipart request", err)
+	}
+
+	fieldErrors := make(map[string]string)
+
+	if values := form.Value[payloadField]; len(values) == 0 {
+		fieldErrors[payloadField] = "is required"
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(values[0])))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(payload); err != nil {
+			fieldErrors[payloadField] = fmt.Sprintf("invalid JSON: %v", err)
+		} else if err := validate.Struct(payload); err != nil {
+			for field, message := range errors.ParseValidationErrors(err) {
+				fieldErrors[payloadField+"."+field] = message
+			}
+		}
+	}
+
+	files := make(map[string]*multipart.FileHeader, len(parts))
+	for _, part := range parts {
+		headers := form.File[part.Field]
+		switch {
+		case len(headers) == 0:
+			if part.Required {
+				fieldErrors[part.Field] = "is required"
+			}
+			continue
+		case len(headers) > 1:
+			fieldErrors[part.Field] = "only one file is accepted"
+			continue
+		}
+
+		fileHeader := headers[0]
+		if fileHeader.Size == 0 {
+			fieldErrors[part.Field] = "file is empty"
+			continue
+		}
+		if part.MaxSize > 0 && fileHeader.Size > part.MaxSize {
+			fieldErrors[part.Field] = fmt.Sprintf("file exceeds %d bytes", part.MaxSize)
+			continue
+		}
+
+		contentType, err := detectContentType(fileHeader)
+		if err != nil {
+			fieldErrors[part.Field] = "file could not be read"
+			continue
+		}
+		if _, allowed := part.ContentTypes[contentType]; !allowed {
+			fieldErrors[part.Field] = fmt.Sprintf("unsupported content type: %s", contentType)
+			continue
+		}
+		fileHeader.Header.Set(echo.HeaderContentType, contentType)
+
+		files[part.Field] = fileHeader
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, errors.ValidationErrorWithDetails("Validation failed", nil, fieldErrors)
+	}
+
+	return files, nil
+}
+
+// EnforceUploadPolicy checks the files of a request against the upload policy of its
+// tenant: the company of the API key, or the company of the token organization. Requests
+// without a tenant, and tenants without a policy, only get the limits of the upload.
+func (b *BaseHandler) EnforceUploadPolicy(c echo.Context, policies services.UploadPolicyService, files ...services.UploadFile) error {
+	ctx := c.Request().Context()
+	if key, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey); ok {
+		return policies.Check(ctx, key.CompanyID, files...)
+	}
+	if organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string); organizationID != "" {
+		return policies.CheckOrganization(ctx, organizationID, files...)
+	}
+	return nil
+}
+
+// detectContentType sniffs the content type of an uploaded file from its first bytes
+// instead of trusting the client header
+func detectContentType(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:n]), nil
+}
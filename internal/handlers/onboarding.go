@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// OnboardingHandler handles the HTTP requests of the onboarding checklist. The tenant is
+// the company of the Keycloak organization of the token; admins can pass company_id.
+type OnboardingHandler struct {
+	BaseHandler
+	onboardingService services.OnboardingService
+	cfg               *config.Config
+	validator         *validator.Validate
+}
+
+// ProvideOnboardingHandler creates a new onboarding handler
+func ProvideOnboardingHandler(
+	onboardingService services.OnboardingService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *OnboardingHandler {
+	return &OnboardingHandler{
+		BaseHandler:       *NewBaseHandler(),
+		onboardingService: onboardingService,
+		cfg:               cfg,
+		validator:         validator,
+	}
+}
+
+// GetOnboarding godoc
+// @Summary Get onboarding checklist
+// @Description Get the setup steps of the tenant with their state, computed from its data unless overridden, and its progress
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param company_id query string false "Company ID, admins only; defaults to the company of the token organization"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.OnboardingResponse}
+// @Router /onboarding [get]
+// @Security BearerAuth
+func (h *OnboardingHandler) GetOnboarding(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	companyID, err := h.tenant(c, claims)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	onboarding, err := h.onboardingService.Get(c.Request().Context(), companyID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Onboarding retrieved successfully", onboarding, nil)
+}
+
+// UpdateOnboardingStep godoc
+// @Summary Override onboarding step
+// @Description Mark a setup step of the tenant as completed or not, overriding the state computed from its data
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param step path string true "Step" Enums(invite_users, configure_webhooks, add_payment_method)
+// @Param company_id query string false "Company ID, admins only; defaults to the company of the token organization"
+// @Param state body dtos.UpdateOnboardingStepRequest true "Step state"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.OnboardingResponse}
+// @Router /onboarding/steps/{step} [put]
+// @Security BearerAuth
+func (h *OnboardingHandler) UpdateOnboardingStep(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.UpdateOnboardingStepRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	companyID, err := h.tenant(c, claims)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	onboarding, err := h.onboardingService.SetStep(c.Request().Context(), companyID, c.Param("step"), *requestDto.Completed, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Onboarding step updated successfully", onboarding, nil)
+}
+
+// ResetOnboardingStep godoc
+// @Summary Reset onboarding step
+// @Description Remove the override of a setup step of the tenant, whose state is computed from its data again
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param step path string true "Step" Enums(invite_users, configure_webhooks, add_payment_method)
+// @Param company_id query string false "Company ID, admins only; defaults to the company of the token organization"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.OnboardingResponse}
+// @Router /onboarding/steps/{step} [delete]
+// @Security BearerAuth
+func (h *OnboardingHandler) ResetOnboardingStep(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	companyID, err := h.tenant(c, claims)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	onboarding, err := h.onboardingService.ResetStep(c.Request().Context(), companyID, c.Param("step"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Onboarding step reset successfully", onboarding, nil)
+}
+
+// tenant returns the company of the request: company_id for admins, the company of the
+// token organization otherwise
+func (h *OnboardingHandler) tenant(c echo.Context, claims *auth.TokenClaims) (string, error) {
+	if companyID := c.QueryParam("company_id"); companyID != "" {
+		if !middlewares.HasAnyRole(claims, h.cfg.KeycloakClientID, constants.RoleAdmin) {
+			return "", errors.ForbiddenError("Only admins can access the onboarding of another company", nil).
+				WithOperation("resolve_onboarding_tenant").
+				WithResource("onboarding")
+		}
+		return companyID, nil
+	}
+
+	organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string)
+	if organizationID == "" {
+		return "", errors.ValidationError("The token has no organization", nil).
+			WithOperation("resolve_onboarding_tenant").
+			WithResource("onboarding")
+	}
+
+	company, err := h.onboardingService.ResolveCompany(c.Request().Context(), organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	return company.ID, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// PaymentHandler handles the HTTP requests of the refunds, captures and voids of the
+// payments
+type PaymentHandler struct {
+	BaseHandler
+	paymentService services.PaymentService
+	cfg            *config.Config
+	validator      *validator.Validate
+}
+
+// ProvidePaymentHandler creates a new payment handler
+func ProvidePaymentHandler(
+	paymentService services.PaymentService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *PaymentHandler {
+	return &PaymentHandler{
+		BaseHandler:    *NewBaseHandler(),
+		paymentService: paymentService,
+		cfg:            cfg,
+		validator:      validator,
+	}
+}
+
+// RefundPayment godoc
+// @Summary Refund payment
+// @Description Refund a payment intent of the payment provider, in part when an amount is given, in the smallest unit of its currency, or for what was not refunded yet otherwise. The refund is recorded with the user who initiated it, whether it succeeds or not.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Param refund body dtos.RefundPaymentRequest true "Refund"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.PaymentOperationResponse}
+// @Router /admin/payments/{id}/refund [post]
+// @Security BearerAuth
+func (h *PaymentHandler) RefundPayment(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.RefundPaymentRequest
+	if err := h.bindAndValidate(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	operation, err := h.paymentService.Refund(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Payment refunded successfully", dtos.NewPaymentOperationResponse(operation), nil)
+}
+
+// CapturePayment godoc
+// @Summary Capture payment
+// @Description Capture a payment intent authorized with a manual capture, in part when an amount is given, releasing the rest of the authorization, or in full otherwise. The capture is recorded with the user who initiated it, whether it succeeds or not.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Param capture body dtos.CapturePaymentRequest true "Capture"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.PaymentOperationResponse}
+// @Router /admin/payments/{id}/capture [post]
+// @Security BearerAuth
+func (h *PaymentHandler) CapturePayment(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CapturePaymentRequest
+	if err := h.bindAndValidate(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	operation, err := h.paymentService.Capture(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Payment captured successfully", dtos.NewPaymentOperationResponse(operation), nil)
+}
+
+// VoidPayment godoc
+// @Summary Void payment
+// @Description Cancel a payment intent that was authorized but not captured, releasing the authorization. The void is recorded with the user who initiated it, whether it succeeds or not.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Param void body dtos.VoidPaymentRequest false "Void"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.PaymentOperationResponse}
+// @Router /admin/payments/{id}/void [post]
+// @Security BearerAuth
+func (h *PaymentHandler) VoidPayment(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.VoidPaymentRequest
+	if err := h.bindAndValidate(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	operation, err := h.paymentService.Void(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Payment voided successfully", dtos.NewPaymentOperationResponse(operation), nil)
+}
+
+// GetPaymentOperations godoc
+// @Summary Get payment operations
+// @Description Get the refunds, captures and voids of a payment, most recent first, with the user who initiated each of them and its outcome
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.PaymentOperationResponse}
+// @Router /admin/payments/{id}/operations [get]
+// @Security BearerAuth
+func (h *PaymentHandler) GetPaymentOperations(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	operations, err := h.paymentService.ListOperations(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	responseDto := make([]dtos.PaymentOperationResponse, len(operations))
+	for i, operation := range operations {
+		responseDto[i] = *dtos.NewPaymentOperationResponse(&operation)
+	}
+
+	return h.SuccessResponse(c, "Payment operations retrieved successfully", responseDto, nil)
+}
+
+// bindAndValidate binds the body of an operation and validates it
+func (h *PaymentHandler) bindAndValidate(c echo.Context, requestDto any) error {
+	if err := c.Bind(requestDto); err != nil {
+		return errors.ValidationError("Invalid request body", err)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors)
+		}
+		return errors.ValidationError("Validation failed", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PaymentWebhookHandler handles the events posted by the payment providers
+type PaymentWebhookHandler struct {
+	BaseHandler
+	paymentWebhookService services.PaymentWebhookService
+}
+
+// ProvidePaymentWebhookHandler creates a new payment webhook handler
+func ProvidePaymentWebhookHandler(paymentWebhookService services.PaymentWebhookService) *PaymentWebhookHandler {
+	return &PaymentWebhookHandler{
+		BaseHandler:           *NewBaseHandler(),
+		paymentWebhookService: paymentWebhookService,
+	}
+}
+
+// ReceiveEvent godoc
+// @Summary Receive payment event
+// @Description Receive an event of Stripe, signed with STRIPE_WEBHOOK_SECRET in the Stripe-Signature header, or of PayPal, signed with the certificate of its Paypal-Cert-Url header for the webhook PAYPAL_WEBHOOK_ID. The event is recorded and published to the payment.<provider>.<type> topic of the message broker, once: the deliveries of an event already published are acknowledged. A failure answers an error so that the provider delivers the event again.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string false "Signature of a Stripe event"
+// @Param Paypal-Transmission-Sig header string false "Signature of a PayPal event"
+// @Param event body object true "Event of the provider"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /webhooks/payments [post]
+func (h *PaymentWebhookHandler) ReceiveEvent(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, constants.PaymentWebhookMaxBodySize+1))
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid payment event", err))
+	}
+	if len(body) > constants.PaymentWebhookMaxBodySize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": "Event too large",
+		})
+	}
+
+	if err := h.paymentWebhookService.HandleEvent(c.Request().Context(), body, c.Request().Header); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Event received successfully", nil, nil)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PerformanceHandler handles the HTTP requests of the tenant performance reports. The
+// tenant is the company of the Keycloak organization of the token; admins can pass
+// company_id.
+type PerformanceHandler struct {
+	BaseHandler
+	performanceService services.PerformanceService
+	cfg                *config.Config
+}
+
+// ProvidePerformanceHandler creates a new performance handler
+func ProvidePerformanceHandler(performanceService services.PerformanceService, cfg *config.Config) *PerformanceHandler {
+	return &PerformanceHandler{
+		BaseHandler:        *NewBaseHandler(),
+		performanceService: performanceService,
+		cfg:                cfg,
+	}
+}
+
+// GetPerformance godoc
+// @Summary Get tenant performance
+// @Description Get the response times and errors of the requests of the tenant over the last hours, overall with a response time histogram, per route and per hour. Percentiles are estimated from the histogram. Recent requests can take up to PERFORMANCE_FLUSH_INTERVAL to be counted.
+// @Tags Usage
+// @Accept json
+// @Produce json
+// @Param hours query int false "Number of hours, the current one included" default(24) maximum(720) example("24")
+// @Param company_id query string false "Company ID, admins only; defaults to the company of the token organization"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TenantPerformanceResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 403 {object} object{meta=dtos.Meta}
+// @Router /usage/performance [get]
+// @Security BearerAuth
+func (h *PerformanceHandler) GetPerformance(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	companyID, err := h.tenant(c, claims)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	hours, err := strconv.Atoi(c.QueryParam("hours"))
+	if err != nil {
+		hours = 0
+	}
+
+	performance, err := h.performanceService.Get(c.Request().Context(), companyID, hours)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Performance retrieved successfully", performance, nil)
+}
+
+// tenant returns the company of the request: company_id for admins, the company of the
+// token organization otherwise
+func (h *PerformanceHandler) tenant(c echo.Context, claims *auth.TokenClaims) (string, error) {
+	if companyID := c.QueryParam("company_id"); companyID != "" {
+		if !middlewares.HasAnyRole(claims, h.cfg.KeycloakClientID, constants.RoleAdmin) {
+			return "", errors.ForbiddenError("Only admins can access the performance of another company", nil).
+				WithOperation("resolve_performance_tenant").
+				WithResource("performance")
+		}
+		return companyID, nil
+	}
+
+	organizationID, _ := c.Get(middlewares.OrganizationIDContextKey).(string)
+	if organizationID == "" {
+		return "", errors.ValidationError("The token has no organization", nil).
+			WithOperation("resolve_performance_tenant").
+			WithResource("performance")
+	}
+
+	company, err := h.performanceService.ResolveCompany(c.Request().Context(), organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	return company.ID, nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// ProvisioningHandler handles the HTTP requests of the provisioning pipeline
+type ProvisioningHandler struct {
+	BaseHandler
+	provisioningService services.ProvisioningService
+	cfg                 *config.Config
+	validator           *validator.Validate
+}
+
+// ProvideProvisioningHandler creates a new provisioning handler
+func ProvideProvisioningHandler(
+	provisioningService services.ProvisioningService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *ProvisioningHandler {
+	return &ProvisioningHandler{
+		BaseHandler:         *NewBaseHandler(),
+		provisioningService: provisioningService,
+		cfg:                 cfg,
+		validator:           validator,
+	}
+}
+
+// ProvisionTenant godoc
+// @Summary Provision tenant
+// @Description Create or update a tenant end to end from its declared state: the Keycloak organization, whose alias is the slug, the client roles of its members, the company, its storage prefix and its default webhook endpoints, matched by URL. The call is idempotent and can be repeated, e.g. after a failure half way. The signing secrets of the webhook endpoints it creates are only returned by that response. The storage prefix cannot change once the tenant is created.
+// @Tags Provisioning
+// @Accept json
+// @Produce json
+// @Param slug path string true "Tenant slug, 2 to 63 lowercase letters, digits or dashes"
+// @Param tenant body dtos.ProvisionTenantRequest true "Tenant"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.ProvisionTenantResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Router /provisioning/tenants/{slug} [put]
+// @Security BearerAuth
+func (h *ProvisioningHandler) ProvisionTenant(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.ProvisionTenantRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	tenant, err := h.provisioningService.Provision(c.Request().Context(), c.Param("slug"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Tenant provisioned successfully", tenant, nil)
+}
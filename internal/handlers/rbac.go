@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// RBACHandler handles the HTTP requests managing the roles and permissions of the
+// database RBAC
+type RBACHandler struct {
+	BaseHandler
+	policyService services.PolicyService
+	validator     *validator.Validate
+}
+
+// ProvideRBACHandler creates a new RBAC handler
+func ProvideRBACHandler(policyService services.PolicyService, validator *validator.Validate) *RBACHandler {
+	return &RBACHandler{
+		BaseHandler:   *NewBaseHandler(),
+		policyService: policyService,
+		validator:     validator,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles of the database RBAC with their permissions
+// @Tags RBAC
+// @Produce json
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.RoleResponse}
+// @Router /rbac/roles [get]
+// @Security BearerAuth
+func (h *RBACHandler) ListRoles(c echo.Context) error {
+	roles, err := h.policyService.ListRoles(c.Request().Context())
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Roles retrieved successfully", roles, nil)
+}
+
+// SaveRole godoc
+// @Summary Save role
+// @Description Create a role of the database RBAC, or replace the description and the permissions of the role. Permissions are named <resource>:<action>, e.g. users:write; <resource>:* grants every action of the resource and * every permission. The users of the role get its new permissions at once.
+// @Tags RBAC
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param role body dtos.SaveRoleRequest true "Role"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.RoleResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Router /rbac/roles/{name} [put]
+// @Security BearerAuth
+func (h *RBACHandler) SaveRole(c echo.Context) error {
+	var requestDto dtos.SaveRoleRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	role, err := h.policyService.SaveRole(c.Request().Context(), c.Param("name"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Role saved successfully", role, nil)
+}
+
+// GetUserRoles godoc
+// @Summary Get user roles
+// @Description Get the roles of the database RBAC of a user and the permissions they grant
+// @Tags RBAC
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserRolesResponse}
+// @Failure 404 {object} object{meta=dtos.Meta}
+// @Router /users/{id}/roles [get]
+// @Security BearerAuth
+func (h *RBACHandler) GetUserRoles(c echo.Context) error {
+	roles, err := h.policyService.GetUserRoles(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User roles retrieved successfully", roles, nil)
+}
+
+// SetUserRoles godoc
+// @Summary Set user roles
+// @Description Replace the roles of the database RBAC of a user. An empty list removes their roles; an unknown role gets a 404.
+// @Tags RBAC
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param roles body dtos.SetUserRolesRequest true "Roles"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserRolesResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Failure 404 {object} object{meta=dtos.Meta}
+// @Router /users/{id}/roles [put]
+// @Security BearerAuth
+func (h *RBACHandler) SetUserRoles(c echo.Context) error {
+	var requestDto dtos.SetUserRolesRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	roles, err := h.policyService.SetUserRoles(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User roles set successfully", roles, nil)
+}
+
+// bind binds and validates the body of the request into requestDto
+func (h *RBACHandler) bind(c echo.Context, requestDto any) error {
+	if err := c.Bind(requestDto); err != nil {
+		return errors.ValidationError("Invalid request body", err)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors)
+		}
+		return errors.ValidationError("Validation failed", err)
+	}
+
+	return nil
+}
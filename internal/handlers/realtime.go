@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/realtime"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// RealtimeHandler upgrades authenticated requests to WebSocket connections of the hub, or
+// serves them the events of the hub as server-sent events
+type RealtimeHandler struct {
+	BaseHandler
+	hub      *realtime.Hub
+	upgrader websocket.Upgrader
+	cfg      *config.Config
+}
+
+// ProvideRealtimeHandler creates a new realtime handler
+func ProvideRealtimeHandler(
+	hub *realtime.Hub,
+	cfg *config.Config,
+) *RealtimeHandler {
+	return &RealtimeHandler{
+		BaseHandler: *NewBaseHandler(),
+		hub:         hub,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{constants.RealtimeTokenProtocol},
+			// Connections authenticate with a bearer token rather than cookies, so any origin
+			// is accepted like by the CORS policy
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		cfg: cfg,
+	}
+}
+
+// Connect godoc
+// @Summary Open the realtime WebSocket
+// @Description Upgrade to a WebSocket receiving the events of the authenticated user as JSON messages ({id, type, data, timestamp}). Browsers, which cannot set the Authorization header, send the token as the "bearer, <token>" subprotocols.
+// @Tags Realtime
+// @Success 101 "Switching Protocols"
+// @Router /realtime/ws [get]
+// @Security BearerAuth
+func (h *RealtimeHandler) Connect(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok || claims.Sub == "" {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// The upgrader has already answered with the HTTP error
+		return nil
+	}
+
+	h.hub.Serve(conn, claims.Sub)
+	return nil
+}
+
+// Stream godoc
+// @Summary Stream events
+// @Description Server-sent events stream of the events of the authenticated user, for clients that cannot use WebSockets. Each event has the event ID and type, and the message sent over the WebSocket as data. After a reconnection, send the ID of the last event received as Last-Event-ID to replay the events missed in the last 5 minutes. Idle streams receive a heartbeat comment every 15 seconds, and streams of clients too slow to read are closed.
+// @Tags Realtime
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} object{meta=dtos.Meta}
+// @Failure 503 {object} object{meta=dtos.Meta}
+// @Router /events/stream [get]
+// @Security BearerAuth
+func (h *RealtimeHandler) Stream(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok || claims.Sub == "" {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	stream, err := h.hub.Subscribe(claims.Sub, c.Request().Header.Get(constants.HeaderLastEventID))
+	if err != nil {
+		return h.HandleError(c, errors.NewAppError(constants.ServiceUnavailable, "Server is shutting down",
+			errors.ErrorTypeInternal, http.StatusServiceUnavailable))
+	}
+	defer stream.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Keep nginx from buffering the stream
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// A client that stops reading fails the write at the deadline instead of blocking the
+	// stream, which the hub drops once its buffer is full
+	rc := http.NewResponseController(res.Writer)
+	_ = rc.SetWriteDeadline(time.Now().Add(constants.RealtimeWriteWait))
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", constants.RealtimeStreamRetry); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(constants.RealtimeHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-stream.Events():
+			_ = rc.SetWriteDeadline(time.Now().Add(constants.RealtimeWriteWait))
+			if err := writeStreamEvent(res, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(constants.RealtimeWriteWait))
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case <-stream.Done():
+			return nil
+		case <-c.Request().Context().Done():
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+// writeStreamEvent writes an event in the server-sent events format, with the message sent
+// over the WebSocket as data so that clients handle both transports alike
+func writeStreamEvent(res *echo.Response, event realtime.Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, message)
+	return err
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// RetentionHandler handles the HTTP requests of the retention policies and legal holds of
+// the companies
+type RetentionHandler struct {
+	BaseHandler
+	retentionService services.RetentionService
+	cfg              *config.Config
+	validator        *validator.Validate
+}
+
+// ProvideRetentionHandler creates a new retention handler
+func ProvideRetentionHandler(
+	retentionService services.RetentionService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *RetentionHandler {
+	return &RetentionHandler{
+		BaseHandler:      *NewBaseHandler(),
+		retentionService: retentionService,
+		cfg:              cfg,
+		validator:        validator,
+	}
+}
+
+// GetRetention godoc
+// @Summary Get retention policies
+// @Description Get the retention period of each resource of a company and its legal hold. Resources without a policy are kept forever.
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.RetentionResponse}
+// @Router /companies/{id}/retention [get]
+// @Security BearerAuth
+func (h *RetentionHandler) GetRetention(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	retention, err := h.retentionService.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Retention policies retrieved successfully", retention, nil)
+}
+
+// UpdateRetentionPolicy godoc
+// @Summary Set retention policy
+// @Description Set the number of days the data of a resource of a company is kept before the retention job purges it
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param resource path string true "Resource" Enums(api_key_usage, revoked_api_keys)
+// @Param policy body dtos.UpdateRetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.RetentionResponse}
+// @Router /companies/{id}/retention/{resource} [put]
+// @Security BearerAuth
+func (h *RetentionHandler) UpdateRetentionPolicy(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.UpdateRetentionPolicyRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	retention, err := h.retentionService.SetPolicy(c.Request().Context(), c.Param("id"), c.Param("resource"), requestDto.Days, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Retention policy updated successfully", retention, nil)
+}
+
+// DeleteRetentionPolicy godoc
+// @Summary Delete retention policy
+// @Description Remove the retention policy of a resource of a company, whose data is kept forever again
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param resource path string true "Resource" Enums(api_key_usage, revoked_api_keys)
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.RetentionResponse}
+// @Router /companies/{id}/retention/{resource} [delete]
+// @Security BearerAuth
+func (h *RetentionHandler) DeleteRetentionPolicy(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	retention, err := h.retentionService.DeletePolicy(c.Request().Context(), c.Param("id"), c.Param("resource"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Retention policy deleted successfully", retention, nil)
+}
+
+// PlaceCompanyLegalHold godoc
+// @Summary Place company on legal hold
+// @Description Exempt all the data of a company from the retention purges until an admin clears the hold
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param hold body dtos.PlaceLegalHoldRequest true "Legal hold"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.LegalHoldResponse}
+// @Router /companies/{id}/legal-hold [put]
+// @Security BearerAuth
+func (h *RetentionHandler) PlaceCompanyLegalHold(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	requestDto, err := h.bindLegalHold(c)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	hold, err := h.retentionService.PlaceCompanyLegalHold(c.Request().Context(), c.Param("id"), requestDto.Reason, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Legal hold placed successfully", hold, nil)
+}
+
+// ClearCompanyLegalHold godoc
+// @Summary Clear company legal hold
+// @Description Clear the legal hold of a company, whose data is purged by its retention policies again. Admins only.
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.LegalHoldResponse}
+// @Router /companies/{id}/legal-hold [delete]
+// @Security BearerAuth
+func (h *RetentionHandler) ClearCompanyLegalHold(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	hold, err := h.retentionService.ClearCompanyLegalHold(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Legal hold cleared successfully", hold, nil)
+}
+
+// PlaceAPIKeyLegalHold godoc
+// @Summary Place API key on legal hold
+// @Description Exempt an API key and its usage from the retention purges until an admin clears the hold
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param keyId path string true "API key ID"
+// @Param hold body dtos.PlaceLegalHoldRequest true "Legal hold"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.LegalHoldResponse}
+// @Router /companies/{id}/api-keys/{keyId}/legal-hold [put]
+// @Security BearerAuth
+func (h *RetentionHandler) PlaceAPIKeyLegalHold(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	requestDto, err := h.bindLegalHold(c)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	hold, err := h.retentionService.PlaceAPIKeyLegalHold(c.Request().Context(), c.Param("id"), c.Param("keyId"), requestDto.Reason, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Legal hold placed successfully", hold, nil)
+}
+
+// ClearAPIKeyLegalHold godoc
+// @Summary Clear API key legal hold
+// @Description Clear the legal hold of an API key, which is purged by the retention policies of its company again. Admins only.
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.LegalHoldResponse}
+// @Router /companies/{id}/api-keys/{keyId}/legal-hold [delete]
+// @Security BearerAuth
+func (h *RetentionHandler) ClearAPIKeyLegalHold(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	hold, err := h.retentionService.ClearAPIKeyLegalHold(c.Request().Context(), c.Param("id"), c.Param("keyId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Legal hold cleared successfully", hold, nil)
+}
+
+// bindLegalHold binds and validates the body of the requests placing a legal hold
+func (h *RetentionHandler) bindLegalHold(c echo.Context) (*dtos.PlaceLegalHoldRequest, error) {
+	var requestDto dtos.PlaceLegalHoldRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return nil, errors.ValidationError("Invalid request body", err)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return nil, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors)
+		}
+		return nil, errors.ValidationError("Validation failed", err)
+	}
+
+	return &requestDto, nil
+}
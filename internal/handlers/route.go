@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/routing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RouteHandler handles the HTTP requests about the routes of the running instance
+type RouteHandler struct {
+	BaseHandler
+	cfg     *config.Config
+	catalog *routing.Catalog
+}
+
+// ProvideRouteHandler creates a new route handler
+func ProvideRouteHandler(cfg *config.Config, catalog *routing.Catalog) *RouteHandler {
+	return &RouteHandler{
+		BaseHandler: *NewBaseHandler(),
+		cfg:         cfg,
+		catalog:     catalog,
+	}
+}
+
+// GetRoutes godoc
+// @Summary Get registered routes
+// @Description Get every route registered on the listeners of the running instance, with its middleware chain, the authentication schemes it accepts and the roles it requires, e.g. for audits or to generate gateway configs. A route without schemes is public.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param listener query string false "Only the routes of this listener" Enums(public,internal)
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.RouteResponse}
+// @Failure 400 {object} object{meta=dtos.Meta}
+// @Router /admin/routes [get]
+// @Security BearerAuth
+func (h *RouteHandler) GetRoutes(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	listener := c.QueryParam("listener")
+	if listener != "" && listener != constants.ListenerPublic && listener != constants.ListenerInternal {
+		return h.HandleError(c, errors.ValidationError("Invalid listener", nil).
+			WithOperation("get_routes").
+			WithContext("listener", listener))
+	}
+
+	routes := make([]dtos.RouteResponse, 0)
+	for _, route := range h.catalog.Routes() {
+		if listener != "" && route.Listener != listener {
+			continue
+		}
+		routes = append(routes, dtos.NewRouteResponse(route))
+	}
+
+	return h.SuccessResponse(c, "Routes retrieved successfully", routes, nil)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SandboxHandler handles the HTTP requests of the sandbox tenants
+type SandboxHandler struct {
+	BaseHandler
+	sandboxService services.SandboxService
+	cfg            *config.Config
+}
+
+// ProvideSandboxHandler creates a new sandbox handler
+func ProvideSandboxHandler(
+	sandboxService services.SandboxService,
+	cfg *config.Config,
+) *SandboxHandler {
+	return &SandboxHandler{
+		BaseHandler:    *NewBaseHandler(),
+		sandboxService: sandboxService,
+		cfg:            cfg,
+	}
+}
+
+// GetSandboxEmails godoc
+// @Summary Get sandbox emails
+// @Description Get the emails captured in the sandbox tenant instead of being sent, most recent first. The id is the sandbox company, or the company of the sandbox.
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.CapturedEmailResponse}
+// @Router /companies/{id}/sandbox/emails [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SandboxHandler) GetSandboxEmails(c echo.Context) error {
+	if !h.IsAuthenticated(c, h.cfg.KeycloakKeyClaim) {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	emails, err := h.sandboxService.ListEmails(c.Request().Context(), c.Param("id"), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.CapturedEmailResponse, len(emails.Data))
+	for i, email := range emails.Data {
+		responseDto[i] = *dtos.NewCapturedEmailResponse(&email)
+	}
+
+	return h.SuccessResponse(c, "Sandbox emails retrieved successfully", responseDto, emails.Pageable)
+}
+
+// GetSandboxEmail godoc
+// @Summary Get sandbox email by ID
+// @Description Get an email captured in the sandbox tenant with its bodies
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param emailId path string true "Email ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CapturedEmailResponse}
+// @Router /companies/{id}/sandbox/emails/{emailId} [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SandboxHandler) GetSandboxEmail(c echo.Context) error {
+	if !h.IsAuthenticated(c, h.cfg.KeycloakKeyClaim) {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	email, err := h.sandboxService.GetEmail(c.Request().Context(), c.Param("id"), c.Param("emailId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Sandbox email retrieved successfully", dtos.NewCapturedEmailResponse(email), nil)
+}
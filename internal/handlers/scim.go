@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMHandler handles the SCIM 2.0 requests of the identity providers provisioning the
+// users. Its responses are SCIM resources and errors rather than the envelope of the API,
+// so its routes are not part of the OpenAPI document.
+type SCIMHandler struct {
+	BaseHandler
+	scimService services.SCIMService
+	validator   *validator.Validate
+}
+
+// ProvideSCIMHandler creates a new SCIM handler
+func ProvideSCIMHandler(scimService services.SCIMService, validator *validator.Validate) *SCIMHandler {
+	return &SCIMHandler{
+		BaseHandler: *NewBaseHandler(),
+		scimService: scimService,
+		validator:   validator,
+	}
+}
+
+// GetServiceProviderConfig describes the SCIM features the service supports
+func (h *SCIMHandler) GetServiceProviderConfig(c echo.Context) error {
+	return h.respond(c, http.StatusOK, dtos.SCIMServiceProviderConfig{
+		Schemas:        []string{constants.SCIMSchemaServiceProviderConfig},
+		Patch:          dtos.SCIMSupported{Supported: true},
+		Filter:         dtos.SCIMFilterSupport{Supported: true, MaxResults: constants.SCIMMaxCount},
+		ChangePassword: dtos.SCIMSupported{Supported: false},
+		AuthenticationSchemes: []dtos.SCIMAuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer token",
+			Description: "The SCIM_TOKEN of the service",
+			Primary:     true,
+		}},
+		Meta: &dtos.SCIMMeta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     h.location(c, "ServiceProviderConfig"),
+		},
+	})
+}
+
+// GetResourceTypes lists the users and groups resource types
+func (h *SCIMHandler) GetResourceTypes(c echo.Context) error {
+	resourceTypes := []dtos.SCIMResourceType{
+		{
+			Schemas:     []string{constants.SCIMSchemaResourceType},
+			ID:          constants.SCIMResourceUser,
+			Name:        constants.SCIMResourceUser,
+			Endpoint:    "/Users",
+			Description: "The users of the service",
+			Schema:      constants.SCIMSchemaUser,
+			Meta:        &dtos.SCIMMeta{ResourceType: "ResourceType", Location: h.location(c, "ResourceTypes/"+constants.SCIMResourceUser)},
+		},
+		{
+			Schemas:     []string{constants.SCIMSchemaResourceType},
+			ID:          constants.SCIMResourceGroup,
+			Name:        constants.SCIMResourceGroup,
+			Endpoint:    "/Groups",
+			Description: "The companies, whose members are their users",
+			Schema:      constants.SCIMSchemaGroup,
+			Meta:        &dtos.SCIMMeta{ResourceType: "ResourceType", Location: h.location(c, "ResourceTypes/"+constants.SCIMResourceGroup)},
+		},
+	}
+
+	return h.respond(c, http.StatusOK, &dtos.SCIMListResponse[dtos.SCIMResourceType]{
+		Schemas:      []string{constants.SCIMSchemaListResponse},
+		TotalResults: int64(len(resourceTypes)),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	})
+}
+
+// ListUsers lists the users matching the filter
+func (h *SCIMHandler) ListUsers(c echo.Context) error {
+	users, err := h.scimService.ListUsers(c.Request().Context(), h.listRequest(c))
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	for i := range users.Resources {
+		h.setUserLocation(c, &users.Resources[i])
+	}
+	return h.respond(c, http.StatusOK, users)
+}
+
+// GetUser gets a user
+func (h *SCIMHandler) GetUser(c echo.Context) error {
+	user, err := h.scimService.GetUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setUserLocation(c, user)
+	return h.respond(c, http.StatusOK, user)
+}
+
+// CreateUser provisions a user
+func (h *SCIMHandler) CreateUser(c echo.Context) error {
+	var requestDto dtos.SCIMUser
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	user, err := h.scimService.CreateUser(c.Request().Context(), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setUserLocation(c, user)
+	c.Response().Header().Set(echo.HeaderLocation, user.Meta.Location)
+	return h.respond(c, http.StatusCreated, user)
+}
+
+// ReplaceUser replaces a user
+func (h *SCIMHandler) ReplaceUser(c echo.Context) error {
+	var requestDto dtos.SCIMUser
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	user, err := h.scimService.ReplaceUser(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setUserLocation(c, user)
+	return h.respond(c, http.StatusOK, user)
+}
+
+// PatchUser patches a user, e.g. deactivates them
+func (h *SCIMHandler) PatchUser(c echo.Context) error {
+	var requestDto dtos.SCIMPatchRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	user, err := h.scimService.PatchUser(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setUserLocation(c, user)
+	return h.respond(c, http.StatusOK, user)
+}
+
+// DeleteUser deprovisions a user
+func (h *SCIMHandler) DeleteUser(c echo.Context) error {
+	if err := h.scimService.DeleteUser(c.Request().Context(), c.Param("id")); err != nil {
+		return h.scimError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListGroups lists the groups matching the filter
+func (h *SCIMHandler) ListGroups(c echo.Context) error {
+	groups, err := h.scimService.ListGroups(c.Request().Context(), h.listRequest(c))
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	for i := range groups.Resources {
+		h.setGroupLocation(c, &groups.Resources[i])
+	}
+	return h.respond(c, http.StatusOK, groups)
+}
+
+// GetGroup gets a group
+func (h *SCIMHandler) GetGroup(c echo.Context) error {
+	withMembers := !slices.Contains(splitSCIMAttributes(c.QueryParam("excludedAttributes")), "members")
+	group, err := h.scimService.GetGroup(c.Request().Context(), c.Param("id"), withMembers)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setGroupLocation(c, group)
+	return h.respond(c, http.StatusOK, group)
+}
+
+// CreateGroup provisions a group
+func (h *SCIMHandler) CreateGroup(c echo.Context) error {
+	var requestDto dtos.SCIMGroup
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	group, err := h.scimService.CreateGroup(c.Request().Context(), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setGroupLocation(c, group)
+	c.Response().Header().Set(echo.HeaderLocation, group.Meta.Location)
+	return h.respond(c, http.StatusCreated, group)
+}
+
+// ReplaceGroup replaces a group and its members
+func (h *SCIMHandler) ReplaceGroup(c echo.Context) error {
+	var requestDto dtos.SCIMGroup
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	group, err := h.scimService.ReplaceGroup(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setGroupLocation(c, group)
+	return h.respond(c, http.StatusOK, group)
+}
+
+// PatchGroup patches a group, e.g. adds or removes members
+func (h *SCIMHandler) PatchGroup(c echo.Context) error {
+	var requestDto dtos.SCIMPatchRequest
+	if err := h.bind(c, &requestDto); err != nil {
+		return h.scimError(c, err)
+	}
+
+	group, err := h.scimService.PatchGroup(c.Request().Context(), c.Param("id"), &requestDto)
+	if err != nil {
+		return h.scimError(c, err)
+	}
+
+	h.setGroupLocation(c, group)
+	return h.respond(c, http.StatusOK, group)
+}
+
+// DeleteGroup deprovisions a group
+func (h *SCIMHandler) DeleteGroup(c echo.Context) error {
+	if err := h.scimService.DeleteGroup(c.Request().Context(), c.Param("id")); err != nil {
+		return h.scimError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// bind decodes and validates a SCIM body. The default binder of Echo only decodes
+// application/json, while the identity providers send application/scim+json.
+func (h *SCIMHandler) bind(c echo.Context, requestDto any) error {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != constants.SCIMContentType && mediaType != echo.MIMEApplicationJSON) {
+		return errors.NewAppError(constants.BadRequest, "Unsupported content type, expected "+constants.SCIMContentType,
+			errors.ErrorTypeValidation, http.StatusUnsupportedMediaType)
+	}
+
+	if err := json.NewDecoder(c.Request().Body).Decode(requestDto); err != nil {
+		return errors.ValidationError("Invalid request body", err).
+			WithContext(constants.SCIMTypeContextKey, constants.SCIMErrorInvalidSyntax)
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		fields := make([]string, 0, len(fieldErrors))
+		for field, message := range fieldErrors {
+			fields = append(fields, field+": "+message)
+		}
+		slices.Sort(fields)
+		message := "Validation failed"
+		if len(fields) > 0 {
+			message += ": " + strings.Join(fields, "; ")
+		}
+		return errors.ValidationErrorWithDetails(message, err, fieldErrors).
+			WithContext(constants.SCIMTypeContextKey, constants.SCIMErrorInvalidValue)
+	}
+
+	return nil
+}
+
+// listRequest reads the pagination, filter and excluded attributes of a listing
+func (h *SCIMHandler) listRequest(c echo.Context) *dtos.SCIMListRequest {
+	startIndex, err := strconv.Atoi(c.QueryParam("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err := strconv.Atoi(c.QueryParam("count"))
+	if err != nil || count < 0 {
+		count = constants.SCIMDefaultCount
+	}
+	count = min(count, constants.SCIMMaxCount)
+
+	return &dtos.SCIMListRequest{
+		StartIndex:         startIndex,
+		Count:              count,
+		Filter:             c.QueryParam("filter"),
+		ExcludedAttributes: splitSCIMAttributes(c.QueryParam("excludedAttributes")),
+	}
+}
+
+// respond writes a SCIM resource
+func (h *SCIMHandler) respond(c echo.Context, status int, body any) error {
+	c.Response().Header().Set(echo.HeaderContentType, constants.SCIMContentType)
+	return c.JSON(status, body)
+}
+
+// scimError logs and reports the error like the other handlers, and writes it as a SCIM
+// error whose scimType is the one of its context
+func (h *SCIMHandler) scimError(c echo.Context, err error) error {
+	appErr := h.errorHandler.ReportError(c, err)
+
+	scimType, _ := appErr.Context[constants.SCIMTypeContextKey].(string)
+	return h.respond(c, appErr.HTTPStatus, dtos.SCIMError{
+		Schemas:  []string{constants.SCIMSchemaError},
+		Status:   strconv.Itoa(appErr.HTTPStatus),
+		SCIMType: scimType,
+		Detail:   appErr.Message,
+	})
+}
+
+// location returns the URL of a path under the SCIM base path
+func (h *SCIMHandler) location(c echo.Context, path string) string {
+	return c.Scheme() + "://" + c.Request().Host + constants.SCIMBasePath + "/" + path
+}
+
+func (h *SCIMHandler) setUserLocation(c echo.Context, user *dtos.SCIMUser) {
+	user.Meta.Location = h.location(c, "Users/"+user.ID)
+	for i := range user.Groups {
+		user.Groups[i].Ref = h.location(c, "Groups/"+user.Groups[i].Value)
+	}
+}
+
+func (h *SCIMHandler) setGroupLocation(c echo.Context, group *dtos.SCIMGroup) {
+	group.Meta.Location = h.location(c, "Groups/"+group.ID)
+	for i := range group.Members {
+		group.Members[i].Ref = h.location(c, "Users/"+group.Members[i].Value)
+	}
+}
+
+// splitSCIMAttributes splits a comma-separated list of attributes
+func splitSCIMAttributes(attributes string) []string {
+	var result []string
+	for _, attribute := range strings.Split(attributes, ",") {
+		if attribute = strings.TrimSpace(attribute); attribute != "" {
+			result = append(result, attribute)
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SessionHandler handles the HTTP requests of the sessions of the users
+type SessionHandler struct {
+	BaseHandler
+	sessionService services.SessionService
+	cfg            *config.Config
+}
+
+// ProvideSessionHandler creates a new session handler
+func ProvideSessionHandler(sessionService services.SessionService, cfg *config.Config) *SessionHandler {
+	return &SessionHandler{
+		BaseHandler:    *NewBaseHandler(),
+		sessionService: sessionService,
+		cfg:            cfg,
+	}
+}
+
+// GetSessions godoc
+// @Summary Get user sessions
+// @Description Get the active sessions of the user, one per login on a device, the most recently used first. The session of the token of the request is flagged as current. Admins get the sessions of any user, other users only their own.
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.SessionResponse}
+// @Router /users/{id}/sessions [get]
+// @Security BearerAuth
+func (h *SessionHandler) GetSessions(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	sessions, err := h.sessionService.List(c.Request().Context(), c.Param("id"), h.requester(claims))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Sessions retrieved successfully", sessions, nil)
+}
+
+// RevokeSession godoc
+// @Summary Revoke user session
+// @Description End a session of the user, logging them out of that device: its refresh tokens stop working at once, its access tokens when they expire or are introspected. Admins revoke the sessions of any user, other users only their own.
+// @Tags Session
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /users/{id}/sessions/{sessionId} [delete]
+// @Security BearerAuth
+func (h *SessionHandler) RevokeSession(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	err := h.sessionService.Revoke(c.Request().Context(), c.Param("id"), c.Param("sessionId"), h.requester(claims))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Session revoked successfully", nil, nil)
+}
+
+// requester returns the user of the token, with the session it belongs to: the sid claim,
+// or the session_state claim of the Keycloak versions before 25
+func (h *SessionHandler) requester(claims *auth.TokenClaims) services.SessionRequester {
+	sessionID, _ := claims.MapClaims["sid"].(string)
+	if sessionID == "" {
+		sessionID, _ = claims.MapClaims["session_state"].(string)
+	}
+
+	return services.SessionRequester{
+		KeycloakID: claims.Sub,
+		SessionID:  sessionID,
+		Admin:      middlewares.HasAnyRole(claims, h.cfg.KeycloakClientID, constants.RoleAdmin),
+	}
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/middlewares"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// StreamColumn is a field of a streamed listing, named like its JSON field
+type StreamColumn[T any] struct {
+	Name string
+	// Value formats the field for CSV
+	Value func(T) string
+}
+
+// ListExport describes a listing exported by StreamList
+type ListExport[T any] struct {
+	// Entity is the key of the policy of the listing in constants.ExportPolicies
+	Entity string
+	// Filename is the name of the attachment, without extension
+	Filename string
+	// Columns are all the fields of the listing, in CSV order
+	Columns []StreamColumn[T]
+	// CompanyID is the company the listing is scoped to, if any
+	CompanyID string
+}
+
+// NegotiateListFormat returns the format of a listing the client accepts: JSON, CSV or
+// NDJSON. JSON is returned when the Accept header is missing or names none of them.
+func NegotiateListFormat(c echo.Context) string {
+	return utils.NegotiateContentType(c.Request().Header.Get(echo.HeaderAccept),
+		echo.MIMEApplicationJSON, utils.CSVContentType, utils.NDJSONContentType)
+}
+
+// NewExportPrincipal returns the API key or the user of the request as the principal of
+// an export, with the roles of the token of a user
+func NewExportPrincipal(c echo.Context, cfg *config.Config) services.ExportPrincipal {
+	if key, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey); ok {
+		return services.ExportPrincipal{
+			Type:  constants.ExportPrincipalAPIKey,
+			ID:    key.ID,
+			Roles: []string{constants.ExportRoleAPIKey},
+		}
+	}
+
+	principal := services.ExportPrincipal{Type: constants.ExportPrincipalUser}
+	if claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims); ok {
+		principal.ID = claims.Sub
+		principal.Roles = middlewares.ExtractRoles(claims, cfg.KeycloakClientID)
+	}
+	return principal
+}
+
+// StreamList exports a listing as CSV, with a header row, or as NDJSON, with one JSON
+// object per entity, as the batches are passed by stream. Only the fields the export
+// policy of the listing allows to the principal are written, and the export is audited
+// by exports. Each batch is flushed to the client before the next one is read.
+//
+// An error before the first batch is handled as usual. Once the response has started its
+// status can no longer change, so a later error is logged and the response ends early.
+func StreamList[T any](c echo.Context, b *BaseHandler, exports services.ExportService, principal services.ExportPrincipal, format string, export ListExport[T], stream func(yield func([]T) error) error) error {
+	ctx := c.Request().Context()
+	audit, err := exports.Begin(ctx, principal, services.ExportRequest{
+		Entity:    export.Entity,
+		Format:    format,
+		CompanyID: export.CompanyID,
+		Filters:   exportFilters(c),
+	})
+	if err != nil {
+		return b.HandleError(c, err)
+	}
+
+	columns := make([]StreamColumn[T], 0, len(audit.Fields))
+	for _, column := range export.Columns {
+		if slices.Contains(audit.Fields, column.Name) {
+			columns = append(columns, column)
+		}
+	}
+
+	res := c.Response()
+	var csvWriter *csv.Writer
+	var rows int64
+
+	started := false
+	start := func() error {
+		started = true
+		res.Header().Set(echo.HeaderContentType, format+"; charset=utf-8")
+		extension := ".ndjson"
+		if format == utils.CSVContentType {
+			extension = ".csv"
+		}
+		res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+export.Filename+extension+`"`)
+		// Keep nginx from buffering the stream
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+
+		if format != utils.CSVContentType {
+			return nil
+		}
+		csvWriter = csv.NewWriter(res)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		return csvWriter.Write(header)
+	}
+
+	err = stream(func(batch []T) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		for _, entity := range batch {
+			if csvWriter == nil {
+				if err := writeNDJSONLine(res, entity, columns); err != nil {
+					return err
+				}
+				rows++
+				continue
+			}
+
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = column.Value(entity)
+			}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+			rows++
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		res.Flush()
+		return nil
+	})
+	if err == nil && !started {
+		// An empty listing is still a valid document, with the CSV header alone
+		err = start()
+		if csvWriter != nil {
+			csvWriter.Flush()
+			err = csvWriter.Error()
+		}
+	}
+	exports.Complete(ctx, audit, rows, err)
+	if err != nil {
+		if !started {
+			return b.HandleError(c, err)
+		}
+		logger.Log.Error("Failed to stream listing",
+			zap.String("path", c.Path()),
+			zap.String("format", format),
+			zap.Int64("rows", rows),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
+
+// writeNDJSONLine writes the columns of the JSON document of the entity as one line, in
+// the order of the columns. Fields omitted from the document stay omitted.
+func writeNDJSONLine[T any](res *echo.Response, entity T, columns []StreamColumn[T]) error {
+	document, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(document, &fields); err != nil {
+		return err
+	}
+
+	var line bytes.Buffer
+	line.WriteByte('{')
+	for _, column := range columns {
+		value, ok := fields[column.Name]
+		if !ok {
+			continue
+		}
+		if line.Len() > 1 {
+			line.WriteByte(',')
+		}
+		name, _ := json.Marshal(column.Name)
+		line.Write(name)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteString("}\n")
+
+	_, err = res.Write(line.Bytes())
+	return err
+}
+
+// exportFilters returns the query parameters of the request for the audit record
+func exportFilters(c echo.Context) map[string]string {
+	filters := make(map[string]string)
+	for name, values := range c.QueryParams() {
+		filters[name] = strings.Join(values, ",")
+	}
+	return filters
+}
+
+// formatStreamTime formats a time of a CSV column
+func formatStreamTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// TenantCredentialHandler handles the HTTP requests of the tenant credentials vault.
+// Secret values are write only: every response carries their masked copy.
+type TenantCredentialHandler struct {
+	BaseHandler
+	credentialService services.TenantCredentialService
+	cfg               *config.Config
+	validator         *validator.Validate
+}
+
+// ProvideTenantCredentialHandler creates a new tenant credential handler
+func ProvideTenantCredentialHandler(
+	credentialService services.TenantCredentialService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *TenantCredentialHandler {
+	return &TenantCredentialHandler{
+		BaseHandler:       *NewBaseHandler(),
+		credentialService: credentialService,
+		cfg:               cfg,
+		validator:         validator,
+	}
+}
+
+// CreateCredential godoc
+// @Summary Create tenant credential
+// @Description Store a provider credential of a company. The secret is encrypted at rest and only returned masked.
+// @Tags Tenant Credential
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param credential body dtos.CreateTenantCredentialRequest true "Credential"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TenantCredentialResponse}
+// @Router /companies/{id}/credentials [post]
+// @Security BearerAuth
+func (h *TenantCredentialHandler) CreateCredential(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateTenantCredentialRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	companyID := c.Param("id")
+	credential, err := h.credentialService.Create(c.Request().Context(), companyID, &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Tenant credential created successfully", dtos.NewTenantCredentialResponse(credential), nil)
+}
+
+// GetCredentials godoc
+// @Summary Get tenant credentials
+// @Description Get the provider credentials of a company with their secrets masked
+// @Tags Tenant Credential
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.TenantCredentialResponse}
+// @Router /companies/{id}/credentials [get]
+// @Security BearerAuth
+func (h *TenantCredentialHandler) GetCredentials(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	companyID := c.Param("id")
+	credentials, err := h.credentialService.List(c.Request().Context(), companyID, &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.TenantCredentialResponse, len(credentials.Data))
+	for i, credential := range credentials.Data {
+		responseDto[i] = *dtos.NewTenantCredentialResponse(&credential)
+	}
+
+	return h.SuccessResponse(c, "Tenant credentials retrieved successfully", responseDto, credentials.Pageable)
+}
+
+// GetCredential godoc
+// @Summary Get tenant credential by ID
+// @Description Get a provider credential of a company with its secret masked
+// @Tags Tenant Credential
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param credentialId path string true "Credential ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TenantCredentialResponse}
+// @Router /companies/{id}/credentials/{credentialId} [get]
+// @Security BearerAuth
+func (h *TenantCredentialHandler) GetCredential(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	credential, err := h.credentialService.GetOneByID(c.Request().Context(), c.Param("id"), c.Param("credentialId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Tenant credential retrieved successfully", dtos.NewTenantCredentialResponse(credential), nil)
+}
+
+// RotateCredential godoc
+// @Summary Rotate tenant credential
+// @Description Replace the secret of a provider credential. The new secret is encrypted under a new data key and the version is incremented.
+// @Tags Tenant Credential
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param credentialId path string true "Credential ID"
+// @Param credential body dtos.RotateTenantCredentialRequest true "New secret"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.TenantCredentialResponse}
+// @Router /companies/{id}/credentials/{credentialId}/rotate [post]
+// @Security BearerAuth
+func (h *TenantCredentialHandler) RotateCredential(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.RotateTenantCredentialRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	credential, err := h.credentialService.Rotate(c.Request().Context(), c.Param("id"), c.Param("credentialId"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Tenant credential rotated successfully", dtos.NewTenantCredentialResponse(credential), nil)
+}
+
+// DeleteCredential godoc
+// @Summary Delete tenant credential
+// @Description Permanently delete a provider credential of a company
+// @Tags Tenant Credential
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param credentialId path string true "Credential ID"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /companies/{id}/credentials/{credentialId} [delete]
+// @Security BearerAuth
+func (h *TenantCredentialHandler) DeleteCredential(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	if err := h.credentialService.Delete(c.Request().Context(), c.Param("id"), c.Param("credentialId")); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Tenant credential deleted successfully", nil, nil)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// UploadHandler handles the HTTP requests of the presigned uploads
+type UploadHandler struct {
+	BaseHandler
+	uploadService services.UploadService
+	policies      services.UploadPolicyService
+	userRepo      repositories.UserRepository
+	cfg           *config.Config
+	validator     *validator.Validate
+}
+
+// ProvideUploadHandler creates a new upload handler
+func ProvideUploadHandler(
+	uploadService services.UploadService,
+	policies services.UploadPolicyService,
+	userRepo repositories.UserRepository,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *UploadHandler {
+	return &UploadHandler{
+		BaseHandler:   *NewBaseHandler(),
+		uploadService: uploadService,
+		policies:      policies,
+		userRepo:      userRepo,
+		cfg:           cfg,
+		validator:     validator,
+	}
+}
+
+// PresignUpload godoc
+// @Summary Presign upload
+// @Description Issue an upload of a file straight to the storage, so that large files do not go through the server, and register its file, pending until the upload is completed. The declared file is checked against the upload policy of the tenant; the client then posts the fields, then the file in a last "file" field, as multipart/form-data to the URL before it expires, and completes the upload. The storage refuses another content type or a file larger than declared.
+// @Tags Uploads
+// @Accept json
+// @Produce json
+// @Param upload body dtos.PresignUploadRequest true "Declared file"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.PresignedUploadResponse}
+// @Router /uploads/presign [post]
+// @Security BearerAuth
+func (h *UploadHandler) PresignUpload(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.PresignUploadRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	// The storage cannot check the content, so the policy applies to the declared file
+	if err := h.EnforceUploadPolicy(c, h.policies, services.UploadFile{
+		Field:       "file",
+		Filename:    requestDto.Filename,
+		ContentType: requestDto.ContentType,
+		Size:        requestDto.Size,
+	}); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// The uploads are owned by the user of the token, as the other files of the registry
+	user, err := h.userRepo.GetByKeycloakID(claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	upload, err := h.uploadService.Presign(c.Request().Context(), user.ID, &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Upload presigned successfully", upload, nil)
+}
+
+// CompleteUpload godoc
+// @Summary Complete upload
+// @Description Complete the file of a presigned upload of the caller once its object is uploaded: the object is read from the storage and scanned by the virus scanner, its size and checksum are recorded, and a clean file becomes available; no URL or stream of the file is issued before. An infected file is quarantined, a 422, and a file that cannot be read or scanned failed. An object not uploaded yet is a 409 and leaves the file pending, to complete again later.
+// @Tags Uploads
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.FileResponse}
+// @Router /uploads/{id}/complete [post]
+// @Security BearerAuth
+func (h *UploadHandler) CompleteUpload(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	user, err := h.userRepo.GetByKeycloakID(claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	file, err := h.uploadService.Complete(c.Request().Context(), user.ID, c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Upload completed successfully", dtos.NewFileResponse(file), nil)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// UploadPolicyHandler handles the HTTP requests of the upload policies of the companies
+type UploadPolicyHandler struct {
+	BaseHandler
+	uploadPolicyService services.UploadPolicyService
+	cfg                 *config.Config
+	validator           *validator.Validate
+}
+
+// ProvideUploadPolicyHandler creates a new upload policy handler
+func ProvideUploadPolicyHandler(
+	uploadPolicyService services.UploadPolicyService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *UploadPolicyHandler {
+	return &UploadPolicyHandler{
+		BaseHandler:         *NewBaseHandler(),
+		uploadPolicyService: uploadPolicyService,
+		cfg:                 cfg,
+		validator:           validator,
+	}
+}
+
+// GetUploadPolicy godoc
+// @Summary Get upload policy
+// @Description Get the content types, size limit and banned extensions of the files a company accepts. A company without a policy accepts every upload within the built-in limits.
+// @Tags Upload Policy
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UploadPolicyResponse}
+// @Router /companies/{id}/upload-policy [get]
+// @Security BearerAuth
+func (h *UploadPolicyHandler) GetUploadPolicy(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	policy, err := h.uploadPolicyService.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Upload policy retrieved successfully", policy, nil)
+}
+
+// UpdateUploadPolicy godoc
+// @Summary Set upload policy
+// @Description Replace the upload policy of a company. It applies to every upload of the company and to the presigned uploads issued for it, and only tightens the limits of each upload.
+// @Tags Upload Policy
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param policy body dtos.UpdateUploadPolicyRequest true "Upload policy"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UploadPolicyResponse}
+// @Router /companies/{id}/upload-policy [put]
+// @Security BearerAuth
+func (h *UploadPolicyHandler) UpdateUploadPolicy(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.UpdateUploadPolicyRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	policy, err := h.uploadPolicyService.Set(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Upload policy updated successfully", policy, nil)
+}
+
+// DeleteUploadPolicy godoc
+// @Summary Delete upload policy
+// @Description Remove the upload policy of a company, which accepts every upload within the built-in limits again
+// @Tags Upload Policy
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UploadPolicyResponse}
+// @Router /companies/{id}/upload-policy [delete]
+// @Security BearerAuth
+func (h *UploadPolicyHandler) DeleteUploadPolicy(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	policy, err := h.uploadPolicyService.Delete(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Upload policy deleted successfully", policy, nil)
+}
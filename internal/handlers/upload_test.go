@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockUploadService is a mock implementation of services.UploadService
+type MockUploadService struct {
+	mock.Mock
+}
+
+func (m *MockUploadService) Presign(ctx context.Context, ownerID string, request *dtos.PresignUploadRequest) (*dtos.PresignedUploadResponse, error) {
+	args := m.Called(ctx, ownerID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.PresignedUploadResponse), args.Error(1)
+}
+
+func (m *MockUploadService) Complete(ctx context.Context, ownerID string, id string) (*models.File, error) {
+	args := m.Called(ctx, ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+// fakeUserRepository resolves the users of the Keycloak users
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[string]string
+}
+
+func (r *fakeUserRepository) GetByKeycloakID(keycloakID string) (*models.User, error) {
+	userID, ok := r.users[keycloakID]
+	if !ok {
+		return nil, errors.NotFoundError("User", nil)
+	}
+	user := &models.User{KeycloakID: keycloakID}
+	user.ID = userID
+	return user, nil
+}
+
+func TestUploadHandler_CompleteUpload(t *testing.T) {
+	logger.Log = zap.NewNop()
+	cfg := &config.Config{KeycloakKeyClaim: "claims"}
+	userRepo := &fakeUserRepository{users: map[string]string{"kc-1": "user-1"}}
+
+	tests := []struct {
+		name           string
+		subject        string
+		expectedStatus int
+		expectComplete bool
+	}{
+		{name: "completed as the user of the token", subject: "kc-1", expectedStatus: http.StatusOK, expectComplete: true},
+		{name: "token of an unknown user", subject: "kc-2", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploadService := new(MockUploadService)
+			uploadService.On("Complete", mock.Anything, "user-1", "file-1").Return(&models.File{Key: "uploads/user-1/a.pdf"}, nil).Maybe()
+			handler := ProvideUploadHandler(uploadService, nil, userRepo, cfg, nil)
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			c.SetParamNames("id")
+			c.SetParamValues("file-1")
+			c.Set(cfg.KeycloakKeyClaim, &auth.TokenClaims{Sub: tt.subject})
+
+			assert.NoError(t, handler.CompleteUpload(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectComplete {
+				uploadService.AssertExpectations(t)
+			} else {
+				uploadService.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/httpclient"
 	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
 	"golang-boilerplate/internal/services"
 	"golang-boilerplate/internal/utils"
 
@@ -19,25 +22,34 @@ import (
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
 	BaseHandler
-	userService services.UserService
-	cfg         *config.Config
-	validator   *validator.Validate
-	restClient  httpclient.RestClient
+	userService   services.UserService
+	exportService services.ExportService
+	policies      services.UploadPolicyService
+	mfaService    services.MFAService
+	cfg           *config.Config
+	validator     *validator.Validate
+	restClient    httpclient.RestClient
 }
 
 // NewUserHandler creates a new user handler
 func ProvideUserHandler(
 	userService services.UserService,
+	exportService services.ExportService,
+	policies services.UploadPolicyService,
+	mfaService services.MFAService,
 	cfg *config.Config,
 	validator *validator.Validate,
 	restClient httpclient.RestClient,
 ) *UserHandler {
 	return &UserHandler{
-		BaseHandler: *NewBaseHandler(),
-		userService: userService,
-		cfg:         cfg,
-		validator:   validator,
-		restClient:  restClient,
+		BaseHandler:   *NewBaseHandler(),
+		userService:   userService,
+		exportService: exportService,
+		policies:      policies,
+		mfaService:    mfaService,
+		cfg:           cfg,
+		validator:     validator,
+		restClient:    restClient,
 	}
 }
 
@@ -81,7 +93,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 
 // GetOneByID godoc
 // @Summary Get user by ID
-// @Description Get user by ID
+// @Description Get user by ID, with the MFA status of their Keycloak user, omitted when Keycloak cannot be reached
 // @Tags User
 // @Accept json
 // @Produce json
@@ -101,7 +113,10 @@ func (h *UserHandler) GetOneByID(c echo.Context) error {
 		return h.HandleError(c, err)
 	}
 
-	return h.SuccessResponse(c, "User retrieved successfully", dtos.NewUserResponse(user), nil)
+	response := dtos.NewUserResponse(user)
+	response.MFA = h.mfaService.UserStatus(c.Request().Context(), user)
+
+	return h.SuccessResponse(c, "User retrieved successfully", response, nil)
 }
 
 // UpdateUser godoc
@@ -143,6 +158,56 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	return h.SuccessResponse(c, "User updated successfully", dtos.NewUserResponse(user), nil)
 }
 
+// PatchUser godoc
+// @Summary Patch user
+// @Description Apply an RFC 7386 JSON merge patch to a user. Set a field to null to clear it.
+// @Tags User
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body dtos.PatchUserRequest true "Merge patch"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserResponse}
+// @Router /users/{id} [patch]
+// @Security BearerAuth
+func (h *UserHandler) PatchUser(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	patch, err := h.ReadMergePatch(c)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	userID := c.Param("id")
+	user, err := h.userService.GetOneByID(c.Request().Context(), userID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	requestDto := dtos.NewPatchUserRequest(user)
+	if err := utils.MergePatch(requestDto, patch); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid merge patch", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	user, err = h.userService.Patch(c.Request().Context(), userID, requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User updated successfully", dtos.NewUserResponse(user), nil)
+}
+
 // DeleteUser godoc
 // @Summary Delete user
 // @Description Delete user
@@ -171,9 +236,11 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 // GetUsers godoc
 // @Summary Get users
 // @Description Get users
+// @Description With Accept text/csv or application/x-ndjson every matching user is streamed, newest first, and page, page_size and sort are ignored.
+// @Description Exports only carry the fields the export policies allow to the caller, and are audited.
 // @Tags User
 // @Accept json
-// @Produce json
+// @Produce json,text/csv,application/x-ndjson
 // @Param page query int false "Page" default(1) example("1")
 // @Param page_size query int false "Page size" default(10) example("10")
 // @Param start_date query string false "Start date" example("2025-09-11T02:17:24.290538Z")
@@ -234,6 +301,15 @@ func (h *UserHandler) GetUsers(c echo.Context) error {
 		Sort:      validSort,
 	}
 
+	if format := NegotiateListFormat(c); format != echo.MIMEApplicationJSON {
+		export := ListExport[dtos.UserResponse]{Entity: constants.ExportEntityUsers, Filename: "users", Columns: userStreamColumns}
+		return StreamList(c, &h.BaseHandler, h.exportService, NewExportPrincipal(c, h.cfg), format, export, func(yield func([]dtos.UserResponse) error) error {
+			return h.userService.Stream(c.Request().Context(), pr, func(users []models.User) error {
+				return yield(newUserResponses(users))
+			})
+		})
+	}
+
 	users, err := h.userService.List(c.Request().Context(), pr)
 	if err != nil {
 		return h.HandleError(c, err)
@@ -248,6 +324,319 @@ func (h *UserHandler) GetUsers(c echo.Context) error {
 	return h.SuccessResponse(c, "Users retrieved successfully", responseDto, users.Pageable)
 }
 
+// SearchUsers godoc
+// @Summary Search users
+// @Description Full-text search of users by first name, last name and email. Every word of q is matched as a prefix.
+// @Description Results are ordered by relevance and the matched text is returned with the matching words wrapped in mark tags.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query" example("john do")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.UserSearchResponse}
+// @Router /users/search [get]
+// @Security BearerAuth
+func (h *UserHandler) SearchUsers(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	requestDto := &dtos.UserSearchRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		Q: strings.TrimSpace(c.QueryParam("q")),
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	results, err := h.userService.Search(c.Request().Context(), requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	responseDto := make([]dtos.UserSearchResponse, len(results.Data))
+	for i, result := range results.Data {
+		responseDto[i] = *dtos.NewUserSearchResponse(&result)
+	}
+
+	return h.SuccessResponse(c, "Users retrieved successfully", responseDto, results.Pageable)
+}
+
+// ImportUsers godoc
+// @Summary Import users from CSV
+// @Description Import users from a CSV file with the columns email, first_name, last_name and keycloak_id.
+// @Description Rows are validated first; with dry_run=true only the validation report is returned.
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param dry_run query bool false "Validate only, do not import" default(false)
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.ImportUsersResponse}
+// @Router /users/import [post]
+// @Security BearerAuth
+func (h *UserHandler) ImportUsers(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, map[string]string{
+				"dry_run": "dry_run must be a boolean",
+			}))
+		}
+		dryRun = parsed
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("CSV file is required", err))
+	}
+	if fileHeader.Size > constants.UserImportMaxFileSize {
+		return h.HandleError(c, errors.ValidationError("CSV file is too large", nil).
+			WithContext("max_size_bytes", constants.UserImportMaxFileSize))
+	}
+
+	contentType, err := detectContentType(fileHeader)
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Failed to read CSV file", err))
+	}
+	if err := h.EnforceUploadPolicy(c, h.policies, services.UploadFile{
+		Field:       "file",
+		Filename:    fileHeader.Filename,
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+	}); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Failed to read CSV file", err))
+	}
+	defer file.Close()
+
+	records, err := utils.ReadCSV(file)
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid CSV file", err))
+	}
+	if len(records) == 0 {
+		return h.HandleError(c, errors.ValidationError("CSV file has no rows", nil))
+	}
+	if len(records) > constants.UserImportMaxRows {
+		return h.HandleError(c, errors.ValidationError("CSV file has too many rows", nil).
+			WithContext("max_rows", constants.UserImportMaxRows))
+	}
+
+	rows := make([]dtos.ImportUserRow, len(records))
+	seenEmails := make(map[string]int, len(records))
+	for i, record := range records {
+		row := dtos.ImportUserRow{
+			Row: record.Line,
+			User: dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					Email:      record.Values["email"],
+					FirstName:  record.Values["first_name"],
+					LastName:   record.Values["last_name"],
+					KeycloakID: record.Values["keycloak_id"],
+				},
+			},
+		}
+
+		rowErrors := map[string]string{}
+		if err := h.validator.Struct(row.User); err != nil {
+			for field, message := range errors.ParseValidationErrors(err) {
+				rowErrors[field] = message
+			}
+		}
+
+		email := strings.ToLower(row.User.Email)
+		if email == "" {
+			rowErrors["Email"] = "Email is required"
+		} else if firstRow, ok := seenEmails[email]; ok {
+			rowErrors["Email"] = fmt.Sprintf("Email is duplicated in the file (first seen on row %d)", firstRow)
+		} else {
+			seenEmails[email] = record.Line
+		}
+
+		if len(rowErrors) > 0 {
+			row.Errors = rowErrors
+		}
+		rows[i] = row
+	}
+
+	result, err := h.userService.Import(c.Request().Context(), rows, dryRun)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	if dryRun {
+		return h.SuccessResponse(c, "Users import validated successfully", result, nil)
+	}
+	return h.SuccessResponse(c, "Users imported successfully", result, nil)
+}
+
+// UploadAvatar godoc
+// @Summary Upload user avatar
+// @Description Upload a JPEG, PNG, GIF or WebP avatar (max 2 MiB) and return a presigned URL once it is available. The previous avatar is deleted.
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User ID"
+// @Param file formData file true "Avatar image"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserAvatarResponse}
+// @Router /users/{id}/avatar [put]
+// @Security BearerAuth
+func (h *UserHandler) UploadAvatar(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	userID := c.Param("id")
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Avatar file is required", err))
+	}
+	if fileHeader.Size == 0 {
+		return h.HandleError(c, errors.ValidationError("Avatar file is empty", nil))
+	}
+	if fileHeader.Size > constants.UserAvatarMaxFileSize {
+		return h.HandleError(c, errors.ValidationError("Avatar file is too large", nil).
+			WithContext("max_size_bytes", constants.UserAvatarMaxFileSize))
+	}
+
+	contentType, err := detectContentType(fileHeader)
+	if err != nil {
+		return h.HandleError(c, errors.ValidationError("Failed to read avatar file", err))
+	}
+	if _, allowed := constants.UserAvatarContentTypes[contentType]; !allowed {
+		return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", nil, map[string]string{
+			"file": fmt.Sprintf("unsupported avatar content type: %s", contentType),
+		}))
+	}
+	fileHeader.Header.Set("Content-Type", contentType)
+
+	if err := h.EnforceUploadPolicy(c, h.policies, services.UploadFile{
+		Field:       "file",
+		Filename:    fileHeader.Filename,
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+	}); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	avatar, err := h.userService.UploadAvatar(c.Request().Context(), userID, fileHeader, contentType)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User avatar uploaded successfully", avatar, nil)
+}
+
+// GetAvatar godoc
+// @Summary Get user avatar
+// @Description Return the avatar of the user with a new presigned URL. An avatar that is not available yet, or was quarantined, returns 409.
+// @Tags User
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserAvatarResponse}
+// @Failure 404 {object} object{meta=dtos.Meta}
+// @Failure 409 {object} object{meta=dtos.Meta}
+// @Router /users/{id}/avatar [get]
+// @Security BearerAuth
+func (h *UserHandler) GetAvatar(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	avatar, err := h.userService.GetAvatar(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User avatar retrieved successfully", avatar, nil)
+}
+
+// AddCompany godoc
+// @Summary Add user to company
+// @Description Add a single company membership to the user. Adding an existing membership is a no-op.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param companyId path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserResponse}
+// @Router /users/{id}/companies/{companyId} [post]
+// @Security BearerAuth
+func (h *UserHandler) AddCompany(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	userID := c.Param("id")
+	companyID := c.Param("companyId")
+	user, err := h.userService.AddCompany(c.Request().Context(), userID, companyID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User added to company successfully", dtos.NewUserResponse(user), nil)
+}
+
+// RemoveCompany godoc
+// @Summary Remove user from company
+// @Description Remove a single company membership from the user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param companyId path string true "Company ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.UserResponse}
+// @Router /users/{id}/companies/{companyId} [delete]
+// @Security BearerAuth
+func (h *UserHandler) RemoveCompany(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	userID := c.Param("id")
+	companyID := c.Param("companyId")
+	user, err := h.userService.RemoveCompany(c.Request().Context(), userID, companyID)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "User removed from company successfully", dtos.NewUserResponse(user), nil)
+}
+
 // TestRestClient godoc
 // @Summary Test rest client
 // @Description Test rest client
@@ -272,3 +661,31 @@ func (h *UserHandler) TestRestClient(c echo.Context) error {
 
 	return h.SuccessResponse(c, "User profile retrieved successfully", result, nil)
 }
+
+// userStreamColumns are the fields of the streamed user listings; in CSV the companies are
+// listed by ID, separated by semicolons
+var userStreamColumns = []StreamColumn[dtos.UserResponse]{
+	{Name: "id", Value: func(u dtos.UserResponse) string { return u.ID }},
+	{Name: "email", Value: func(u dtos.UserResponse) string { return u.Email }},
+	{Name: "first_name", Value: func(u dtos.UserResponse) string { return u.FirstName }},
+	{Name: "last_name", Value: func(u dtos.UserResponse) string { return u.LastName }},
+	{Name: "avatar_key", Value: func(u dtos.UserResponse) string { return u.AvatarKey }},
+	{Name: "companies", Value: func(u dtos.UserResponse) string {
+		ids := make([]string, len(u.Companies))
+		for i, company := range u.Companies {
+			ids[i] = company.ID
+		}
+		return strings.Join(ids, ";")
+	}},
+	{Name: "created_at", Value: func(u dtos.UserResponse) string { return formatStreamTime(u.CreatedAt) }},
+	{Name: "updated_at", Value: func(u dtos.UserResponse) string { return formatStreamTime(u.UpdatedAt) }},
+}
+
+// newUserResponses transforms users to response DTOs
+func newUserResponses(users []models.User) []dtos.UserResponse {
+	responses := make([]dtos.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = *dtos.NewUserResponse(&user)
+	}
+	return responses
+}
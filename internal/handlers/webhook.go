@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"strconv"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler handles the HTTP requests of the webhook endpoints of the companies and
+// their delivery logs. The signing secret of an endpoint is only returned by its creation
+// and its rotations.
+type WebhookHandler struct {
+	BaseHandler
+	webhookService services.WebhookService
+	cfg            *config.Config
+	validator      *validator.Validate
+}
+
+// ProvideWebhookHandler creates a new webhook handler
+func ProvideWebhookHandler(
+	webhookService services.WebhookService,
+	cfg *config.Config,
+	validator *validator.Validate,
+) *WebhookHandler {
+	return &WebhookHandler{
+		BaseHandler:    *NewBaseHandler(),
+		webhookService: webhookService,
+		cfg:            cfg,
+		validator:      validator,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook
+// @Description Register an endpoint receiving the events of a company, signed with HMAC-SHA256. The signing secret is only returned by this response.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhook body dtos.CreateWebhookRequest true "Webhook"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CreatedWebhookEndpointResponse}
+// @Router /companies/{id}/webhooks [post]
+// @Security BearerAuth
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	claims, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.CreateWebhookRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	endpoint, secret, err := h.webhookService.Create(c.Request().Context(), c.Param("id"), &requestDto, claims.Sub)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook created successfully", dtos.NewCreatedWebhookEndpointResponse(endpoint, secret), nil)
+}
+
+// GetWebhooks godoc
+// @Summary Get webhooks
+// @Description Get the webhook endpoints of a company, most recent first
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.WebhookEndpointResponse}
+// @Router /companies/{id}/webhooks [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	endpoints, err := h.webhookService.List(c.Request().Context(), c.Param("id"), &dtos.PageableRequest{
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.WebhookEndpointResponse, len(endpoints.Data))
+	for i, endpoint := range endpoints.Data {
+		responseDto[i] = *dtos.NewWebhookEndpointResponse(&endpoint)
+	}
+
+	return h.SuccessResponse(c, "Webhooks retrieved successfully", responseDto, endpoints.Pageable)
+}
+
+// GetWebhook godoc
+// @Summary Get webhook by ID
+// @Description Get a webhook endpoint of a company, without its signing secret
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.WebhookEndpointResponse}
+// @Router /companies/{id}/webhooks/{webhookId} [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	endpoint, err := h.webhookService.Get(c.Request().Context(), c.Param("id"), c.Param("webhookId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook retrieved successfully", dtos.NewWebhookEndpointResponse(endpoint), nil)
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook
+// @Description Update the URL, description, event types, conditions, rate limit or active flag of a webhook endpoint. The omitted fields are kept; an inactive endpoint receives no deliveries.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Param webhook body dtos.UpdateWebhookRequest true "Webhook"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.WebhookEndpointResponse}
+// @Router /companies/{id}/webhooks/{webhookId} [patch]
+// @Security BearerAuth
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	var requestDto dtos.UpdateWebhookRequest
+	if err := c.Bind(&requestDto); err != nil {
+		return h.HandleError(c, errors.ValidationError("Invalid request body", err))
+	}
+
+	if err := h.validator.Struct(requestDto); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	endpoint, err := h.webhookService.Update(c.Request().Context(), c.Param("id"), c.Param("webhookId"), &requestDto)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook updated successfully", dtos.NewWebhookEndpointResponse(endpoint), nil)
+}
+
+// RotateWebhookSecret godoc
+// @Summary Rotate webhook secret
+// @Description Replace the signing secret of a webhook endpoint. The previous secret stops signing at once, including the retries of pending deliveries. The new secret is only returned by this response.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.CreatedWebhookEndpointResponse}
+// @Router /companies/{id}/webhooks/{webhookId}/rotate-secret [post]
+// @Security BearerAuth
+func (h *WebhookHandler) RotateWebhookSecret(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	endpoint, secret, err := h.webhookService.RotateSecret(c.Request().Context(), c.Param("id"), c.Param("webhookId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook secret rotated successfully", dtos.NewCreatedWebhookEndpointResponse(endpoint, secret), nil)
+}
+
+// TestWebhook godoc
+// @Summary Send test webhook event
+// @Description Deliver a webhook.test event to an active webhook endpoint, whatever its event types. The delivery is logged and retried like the others.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.WebhookDeliveryResponse}
+// @Router /companies/{id}/webhooks/{webhookId}/test [post]
+// @Security BearerAuth
+func (h *WebhookHandler) TestWebhook(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	delivery, err := h.webhookService.SendTest(c.Request().Context(), c.Param("id"), c.Param("webhookId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook test event sent successfully", dtos.NewWebhookDeliveryResponse(delivery), nil)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Description Delete a webhook endpoint of a company. Its pending deliveries fail and its delivery logs are kept.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} object{meta=dtos.Meta}
+// @Router /companies/{id}/webhooks/{webhookId} [delete]
+// @Security BearerAuth
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	if err := h.webhookService.Delete(c.Request().Context(), c.Param("id"), c.Param("webhookId")); err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook deleted successfully", nil, nil)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get webhook deliveries
+// @Description Get the delivery logs of a webhook endpoint with the outcome of their last attempt, most recent first
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhookId path string true "Webhook ID"
+// @Param status query string false "Status" Enums(pending, succeeded, failed)
+// @Param event_type query string false "Event type" example("user.updated")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.WebhookDeliveryResponse}
+// @Router /companies/{id}/webhooks/{webhookId}/deliveries [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetWebhookDeliveries(c echo.Context) error {
+	return h.getDeliveries(c, c.Param("webhookId"))
+}
+
+// GetCompanyWebhookDeliveries godoc
+// @Summary Get company webhook deliveries
+// @Description Get the delivery logs of the webhook endpoints of a company with the outcome of their last attempt, most recent first. Without a webhook ID, the logs of the deleted endpoints are included.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param webhook_id query string false "Webhook ID"
+// @Param status query string false "Status" Enums(pending, succeeded, failed)
+// @Param event_type query string false "Event type" example("user.updated")
+// @Param page query int false "Page" default(1) example("1")
+// @Param page_size query int false "Page size" default(10) example("10")
+// @Success 200 {object} object{meta=dtos.Meta,data=[]dtos.WebhookDeliveryResponse}
+// @Router /companies/{id}/webhooks/deliveries [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetCompanyWebhookDeliveries(c echo.Context) error {
+	return h.getDeliveries(c, c.QueryParam("webhook_id"))
+}
+
+// getDeliveries responds with the delivery logs of the company matching the query
+// filters, of the webhook endpoint when webhookID is not empty
+func (h *WebhookHandler) getDeliveries(c echo.Context, webhookID string) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	// Parse pagination parameters
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("page_size"))
+	if err != nil || pageSize < 0 {
+		pageSize = 10
+	}
+
+	pageableRequest := dtos.WebhookDeliveryPageableRequest{
+		PageableRequest: dtos.PageableRequest{
+			Page:     page,
+			PageSize: pageSize,
+		},
+		WebhookID: webhookID,
+		Status:    c.QueryParam("status"),
+		EventType: c.QueryParam("event_type"),
+	}
+	if err := h.validator.Struct(pageableRequest); err != nil {
+		fieldErrors := errors.ParseValidationErrors(err)
+		if len(fieldErrors) > 0 {
+			return h.HandleError(c, errors.ValidationErrorWithDetails("Validation failed", err, fieldErrors))
+		}
+		return h.HandleError(c, errors.ValidationError("Validation failed", err))
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request().Context(), c.Param("id"), &pageableRequest)
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	// Transform to response DTOs
+	responseDto := make([]dtos.WebhookDeliveryResponse, len(deliveries.Data))
+	for i, delivery := range deliveries.Data {
+		responseDto[i] = *dtos.NewWebhookDeliveryResponse(&delivery)
+	}
+
+	return h.SuccessResponse(c, "Webhook deliveries retrieved successfully", responseDto, deliveries.Pageable)
+}
+
+// GetWebhookDelivery godoc
+// @Summary Get webhook delivery by ID
+// @Description Get a delivery log of a company with its payload and the outcome of its last attempt
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.WebhookDeliveryResponse}
+// @Router /companies/{id}/webhooks/deliveries/{deliveryId} [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetWebhookDelivery(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.Request().Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook delivery retrieved successfully", dtos.NewWebhookDeliveryResponse(delivery), nil)
+}
+
+// RedeliverWebhookDelivery godoc
+// @Summary Redeliver webhook delivery
+// @Description Send the payload of a delivery again, as a new delivery of the same event with its own attempts. The event keeps its ID, so that receivers can deduplicate it.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Company ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} object{meta=dtos.Meta,data=dtos.WebhookDeliveryResponse}
+// @Router /companies/{id}/webhooks/deliveries/{deliveryId}/redeliver [post]
+// @Security BearerAuth
+func (h *WebhookHandler) RedeliverWebhookDelivery(c echo.Context) error {
+	_, ok := c.Get(h.cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return h.UnauthorizedErrorResponse(c, "User not authenticated")
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request().Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		return h.HandleError(c, err)
+	}
+
+	return h.SuccessResponse(c, "Webhook delivery redelivered successfully", dtos.NewWebhookDeliveryResponse(delivery), nil)
+}
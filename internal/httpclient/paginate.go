@@ -0,0 +1,444 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/time/rate"
+)
+
+// StatusError is returned by a PageIterator when a page request gets a non 2xx response
+type StatusError struct {
+	// Method is the method of the request, GET when empty
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	method := e.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return fmt.Sprintf("%s %s: unexpected status %d", method, e.Endpoint, e.StatusCode)
+}
+
+// HTTPStatusCode returns the upstream response status, so that errors.WithProvider can
+// classify the failure
+func (e *StatusError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// PageRequest is the request of one page
+type PageRequest struct {
+	Endpoint string
+	Query    url.Values
+}
+
+// Page is one decoded page of a paginated API
+type Page[T any] struct {
+	Items []T
+	// NextCursor is the cursor of the following page for cursor pagination, empty on the
+	// last page
+	NextCursor string
+}
+
+// PageDecoder decodes the response of a page request
+type PageDecoder[T any] func(resp *resty.Response) (*Page[T], error)
+
+// Pagination is the way a paginated API exposes its pages
+type Pagination interface {
+	// First adds the pagination parameters of the first page to query
+	First(query url.Values)
+	// Next returns the request of the page following current, given its response, number of
+	// items and next cursor, or false when current was the last page
+	Next(current PageRequest, resp *resty.Response, count int, cursor string) (PageRequest, bool)
+}
+
+// PageNumberPagination requests pages by number, e.g. ?page=2&limit=100, until a page
+// comes back short
+type PageNumberPagination struct {
+	// PageParam defaults to "page"
+	PageParam string
+	// LimitParam defaults to "limit"
+	LimitParam string
+	// Limit defaults to constants.ExternalPageLimit
+	Limit int
+	// ZeroBased numbers the first page 0 instead of 1
+	ZeroBased bool
+}
+
+func (p PageNumberPagination) First(query url.Values) {
+	first := 1
+	if p.ZeroBased {
+		first = 0
+	}
+	query.Set(paramOrDefault(p.PageParam, "page"), strconv.Itoa(first))
+	query.Set(paramOrDefault(p.LimitParam, "limit"), strconv.Itoa(limitOrDefault(p.Limit)))
+}
+
+func (p PageNumberPagination) Next(current PageRequest, _ *resty.Response, count int, _ string) (PageRequest, bool) {
+	if count == 0 || count < limitOrDefault(p.Limit) {
+		return PageRequest{}, false
+	}
+
+	param := paramOrDefault(p.PageParam, "page")
+	page, err := strconv.Atoi(current.Query.Get(param))
+	if err != nil {
+		return PageRequest{}, false
+	}
+
+	query := cloneValues(current.Query)
+	query.Set(param, strconv.Itoa(page+1))
+	return PageRequest{Endpoint: current.Endpoint, Query: query}, true
+}
+
+// OffsetPagination requests pages by item offset, e.g. ?offset=200&limit=100, until a page
+// comes back short. Keycloak admin APIs use it with the first and max parameters.
+type OffsetPagination struct {
+	// OffsetParam defaults to "offset"
+	OffsetParam string
+	// LimitParam defaults to "limit"
+	LimitParam string
+	// Limit defaults to constants.ExternalPageLimit
+	Limit int
+}
+
+func (p OffsetPagination) First(query url.Values) {
+	query.Set(paramOrDefault(p.OffsetParam, "offset"), "0")
+	query.Set(paramOrDefault(p.LimitParam, "limit"), strconv.Itoa(limitOrDefault(p.Limit)))
+}
+
+func (p OffsetPagination) Next(current PageRequest, _ *resty.Response, count int, _ string) (PageRequest, bool) {
+	if count == 0 || count < limitOrDefault(p.Limit) {
+		return PageRequest{}, false
+	}
+
+	param := paramOrDefault(p.OffsetParam, "offset")
+	offset, err := strconv.Atoi(current.Query.Get(param))
+	if err != nil {
+		return PageRequest{}, false
+	}
+
+	query := cloneValues(current.Query)
+	query.Set(param, strconv.Itoa(offset+count))
+	return PageRequest{Endpoint: current.Endpoint, Query: query}, true
+}
+
+// CursorPagination requests pages with the opaque cursor returned by the previous page,
+// e.g. ?cursor=abc&limit=100, until a page comes back without a cursor
+type CursorPagination struct {
+	// CursorParam defaults to "cursor"
+	CursorParam string
+	// LimitParam defaults to "limit"
+	LimitParam string
+	// Limit defaults to constants.ExternalPageLimit
+	Limit int
+}
+
+func (p CursorPagination) First(query url.Values) {
+	query.Set(paramOrDefault(p.LimitParam, "limit"), strconv.Itoa(limitOrDefault(p.Limit)))
+}
+
+func (p CursorPagination) Next(current PageRequest, _ *resty.Response, _ int, cursor string) (PageRequest, bool) {
+	if cursor == "" || cursor == current.Query.Get(paramOrDefault(p.CursorParam, "cursor")) {
+		return PageRequest{}, false
+	}
+
+	query := cloneValues(current.Query)
+	query.Set(paramOrDefault(p.CursorParam, "cursor"), cursor)
+	return PageRequest{Endpoint: current.Endpoint, Query: query}, true
+}
+
+// LinkHeaderPagination follows the rel="next" URL of the RFC 8288 Link response header,
+// as GitHub style APIs do, until a response has no next link
+type LinkHeaderPagination struct {
+	// LimitParam is the page size parameter of the first request; none is sent when empty
+	LimitParam string
+	// Limit defaults to constants.ExternalPageLimit
+	Limit int
+}
+
+func (p LinkHeaderPagination) First(query url.Values) {
+	if p.LimitParam != "" {
+		query.Set(p.LimitParam, strconv.Itoa(limitOrDefault(p.Limit)))
+	}
+}
+
+func (p LinkHeaderPagination) Next(current PageRequest, resp *resty.Response, _ int, _ string) (PageRequest, bool) {
+	target := nextLink(resp.Header())
+	if target == "" {
+		return PageRequest{}, false
+	}
+
+	next, err := url.Parse(target)
+	if err != nil {
+		return PageRequest{}, false
+	}
+	// Relative links are resolved against the URL that was actually requested
+	if resp.Request != nil && resp.Request.RawRequest != nil {
+		requested := resp.Request.RawRequest.URL
+		next = requested.ResolveReference(next)
+		// Guard against an API linking a page to itself
+		if next.String() == requested.String() {
+			return PageRequest{}, false
+		}
+	}
+
+	// The next link already carries every query parameter
+	return PageRequest{Endpoint: next.String(), Query: url.Values{}}, true
+}
+
+// PageIteratorConfig configures a PageIterator
+type PageIteratorConfig[T any] struct {
+	Endpoint string
+	Headers  map[string]string
+	// Query holds the filters sent with every page request
+	Query      url.Values
+	Pagination Pagination
+	// Decode decodes a page, DecodeJSONArray when nil
+	Decode PageDecoder[T]
+	// Limiter paces the page requests when set
+	Limiter *rate.Limiter
+	// MaxPages stops the iteration after that many pages when positive
+	MaxPages int
+}
+
+// PageIterator walks the pages of a paginated third-party API one request at a time.
+// It is not safe for concurrent use.
+type PageIterator[T any] struct {
+	client RestClient
+	config PageIteratorConfig[T]
+	next   *PageRequest
+	pages  int
+}
+
+// NewPageIterator creates an iterator positioned before the first page
+func NewPageIterator[T any](client RestClient, cfg PageIteratorConfig[T]) *PageIterator[T] {
+	if cfg.Decode == nil {
+		cfg.Decode = DecodeJSONArray[T]
+	}
+
+	query := cloneValues(cfg.Query)
+	cfg.Pagination.First(query)
+
+	return &PageIterator[T]{
+		client: client,
+		config: cfg,
+		next:   &PageRequest{Endpoint: cfg.Endpoint, Query: query},
+	}
+}
+
+// HasNext reports whether another page is left to fetch
+func (it *PageIterator[T]) HasNext() bool {
+	return it.next != nil
+}
+
+// Pages returns the number of pages fetched so far
+func (it *PageIterator[T]) Pages() int {
+	return it.pages
+}
+
+// Next fetches the next page and returns its items, or nil once every page was fetched.
+// After an error the same page is requested again by the following call.
+func (it *PageIterator[T]) Next(ctx context.Context) ([]T, error) {
+	if it.next == nil {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if it.config.Limiter != nil {
+		if err := it.config.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	current := *it.next
+	resp, err := it.client.GetWithContext(ctx, current.Endpoint, nil, cloneHeaders(it.config.Headers), current.Query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("fetch page %d of %s: %w", it.pages+1, current.Endpoint, err)
+	}
+	if resp.IsError() {
+		return nil, &StatusError{
+			Endpoint:   current.Endpoint,
+			StatusCode: resp.StatusCode(),
+			Body:       resp.String(),
+		}
+	}
+
+	page, err := it.config.Decode(resp)
+	if err != nil {
+		return nil, fmt.Errorf("decode page %d of %s: %w", it.pages+1, current.Endpoint, err)
+	}
+
+	it.pages++
+	it.next = nil
+	if it.config.MaxPages <= 0 || it.pages < it.config.MaxPages {
+		if next, ok := it.config.Pagination.Next(current, resp, len(page.Items), page.NextCursor); ok {
+			it.next = &next
+		}
+	}
+
+	return page.Items, nil
+}
+
+// All yields the items of every remaining page. The iteration stops after the first
+// error, which is yielded with a zero item.
+func (it *PageIterator[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for it.HasNext() {
+			items, err := it.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Collect returns the items of every remaining page
+func (it *PageIterator[T]) Collect(ctx context.Context) ([]T, error) {
+	var all []T
+	for item, err := range it.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
+
+// DecodeJSONArray decodes a page whose body is a JSON array of items
+func DecodeJSONArray[T any](resp *resty.Response) (*Page[T], error) {
+	var items []T
+	if err := json.Unmarshal(resp.Body(), &items); err != nil {
+		return nil, err
+	}
+	return &Page[T]{Items: items}, nil
+}
+
+// DecodeJSONEnvelope returns a decoder for pages whose body is a JSON object holding the
+// items in itemsField and, for cursor pagination, the next cursor in cursorField. A null,
+// missing or empty cursor ends the iteration.
+func DecodeJSONEnvelope[T any](itemsField, cursorField string) PageDecoder[T] {
+	return func(resp *resty.Response) (*Page[T], error) {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(resp.Body(), &envelope); err != nil {
+			return nil, err
+		}
+
+		page := &Page[T]{}
+		if raw, ok := envelope[itemsField]; ok {
+			if err := json.Unmarshal(raw, &page.Items); err != nil {
+				return nil, fmt.Errorf("decode %s: %w", itemsField, err)
+			}
+		}
+
+		if raw, ok := envelope[cursorField]; ok && cursorField != "" {
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.UseNumber()
+			var cursor interface{}
+			if err := decoder.Decode(&cursor); err != nil {
+				return nil, fmt.Errorf("decode %s: %w", cursorField, err)
+			}
+			switch value := cursor.(type) {
+			case string:
+				page.NextCursor = value
+			case json.Number:
+				page.NextCursor = value.String()
+			case nil:
+			default:
+				return nil, fmt.Errorf("decode %s: cursor must be a string or a number", cursorField)
+			}
+		}
+
+		return page, nil
+	}
+}
+
+// nextLink returns the target of the rel="next" link of the Link headers, or an empty string
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for {
+			start := strings.IndexByte(value, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(value[start:], '>')
+			if end < 0 {
+				break
+			}
+			end += start
+
+			target := value[start+1 : end]
+			value = value[end+1:]
+
+			params := value
+			if next := strings.IndexByte(value, '<'); next >= 0 {
+				params = value[:next]
+			}
+			value = value[len(params):]
+
+			for _, param := range strings.Split(params, ";") {
+				key, rel, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, name := range strings.Fields(strings.Trim(strings.TrimSpace(rel), `",`)) {
+					if strings.EqualFold(name, "next") {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func paramOrDefault(param, fallback string) string {
+	if param == "" {
+		return fallback
+	}
+	return param
+}
+
+func limitOrDefault(limit int) int {
+	if limit <= 0 {
+		return constants.ExternalPageLimit
+	}
+	return limit
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
+
+func cloneHeaders(headers map[string]string) map[string]string {
+	clone := make(map[string]string, len(headers))
+	for key, value := range headers {
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,311 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type pageItem struct {
+	ID int `json:"id"`
+}
+
+func newTestRestClient() RestClient {
+	return ProvideRestClient(&config.Config{
+		HTTPClientTimeout: 5 * time.Second,
+		AppName:           "test-app",
+		AppVersion:        "1.0.0",
+	})
+}
+
+// pageItems returns the items [from, to) of a dataset of total items
+func pageItems(from, to, total int) []pageItem {
+	items := []pageItem{}
+	for i := from; i < to && i < total; i++ {
+		items = append(items, pageItem{ID: i})
+	}
+	return items
+}
+
+func itemIDs(items []pageItem) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestPageIterator_Strategies(t *testing.T) {
+	const total = 5
+
+	tests := []struct {
+		name          string
+		pagination    Pagination
+		decode        PageDecoder[pageItem]
+		handler       func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		expectedPages int
+	}{
+		{
+			name:       "page number",
+			pagination: PageNumberPagination{Limit: 2},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "2", r.URL.Query().Get("limit"))
+				assert.Equal(t, "active", r.URL.Query().Get("status"))
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				json.NewEncoder(w).Encode(pageItems((page-1)*2, page*2, total))
+			},
+			expectedPages: 3,
+		},
+		{
+			name:       "offset with custom parameters",
+			pagination: OffsetPagination{OffsetParam: "first", LimitParam: "max", Limit: 2},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "2", r.URL.Query().Get("max"))
+				first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+				json.NewEncoder(w).Encode(pageItems(first, first+2, total))
+			},
+			expectedPages: 3,
+		},
+		{
+			name:       "cursor",
+			pagination: CursorPagination{Limit: 2},
+			decode:     DecodeJSONEnvelope[pageItem]("data", "next_cursor"),
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				from, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+				body := map[string]interface{}{"data": pageItems(from, from+2, total), "next_cursor": nil}
+				if from+2 < total {
+					body["next_cursor"] = strconv.Itoa(from + 2)
+				}
+				json.NewEncoder(w).Encode(body)
+			},
+			expectedPages: 3,
+		},
+		{
+			name:       "link header",
+			pagination: LinkHeaderPagination{LimitParam: "per_page", Limit: 2},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if page == 0 {
+					page = 1
+				}
+				if page*2 < total {
+					next := fmt.Sprintf("/items?page=%d&per_page=2&status=active", page+1)
+					w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", </items?page=3&per_page=2>; rel="last"`, next))
+				}
+				json.NewEncoder(w).Encode(pageItems((page-1)*2, page*2, total))
+			},
+			expectedPages: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(t, w, r)
+			}))
+			defer server.Close()
+
+			it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+				Endpoint:   server.URL + "/items",
+				Query:      url.Values{"status": {"active"}},
+				Pagination: tt.pagination,
+				Decode:     tt.decode,
+			})
+
+			items, err := it.Collect(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, []int{0, 1, 2, 3, 4}, itemIDs(items))
+			assert.Equal(t, tt.expectedPages, it.Pages())
+			assert.False(t, it.HasNext())
+		})
+	}
+}
+
+func TestPageIterator_StopsOnEmptyPage(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		json.NewEncoder(w).Encode(pageItems((page-1)*2, page*2, 4))
+	}))
+	defer server.Close()
+
+	it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+		Endpoint:   server.URL,
+		Pagination: PageNumberPagination{Limit: 2},
+	})
+
+	items, err := it.Collect(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+	assert.Equal(t, 3, requests)
+}
+
+func TestPageIterator_MaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		json.NewEncoder(w).Encode(pageItems(offset, offset+2, 100))
+	}))
+	defer server.Close()
+
+	it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+		Endpoint:   server.URL,
+		Pagination: OffsetPagination{Limit: 2},
+		MaxPages:   2,
+	})
+
+	items, err := it.Collect(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, itemIDs(items))
+	assert.False(t, it.HasNext())
+}
+
+func TestPageIterator_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"forbidden"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(pageItems(0, 2, 10))
+	}))
+	defer server.Close()
+
+	it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+		Endpoint:   server.URL,
+		Pagination: PageNumberPagination{Limit: 2},
+	})
+
+	first, err := it.Next(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, first, 2)
+
+	_, err = it.Next(context.Background())
+	var statusErr *StatusError
+	require.True(t, stderrors.As(err, &statusErr))
+	assert.Equal(t, http.StatusForbidden, statusErr.HTTPStatusCode())
+	assert.Contains(t, statusErr.Body, "forbidden")
+	// The failed page is requested again by the next call
+	assert.True(t, it.HasNext())
+}
+
+func TestPageIterator_RateLimit(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		json.NewEncoder(w).Encode(pageItems(page-1, page, 3))
+	}))
+	defer server.Close()
+
+	it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+		Endpoint:   server.URL,
+		Pagination: PageNumberPagination{Limit: 1},
+		Limiter:    rate.NewLimiter(rate.Every(50*time.Millisecond), 1),
+	})
+
+	items, err := it.Collect(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+	require.Len(t, times, 4)
+	assert.GreaterOrEqual(t, times[3].Sub(times[0]), 140*time.Millisecond)
+}
+
+func TestPageIterator_ContextCancellation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(pageItems(0, 2, 10))
+	}))
+	defer server.Close()
+
+	it := NewPageIterator(newTestRestClient(), PageIteratorConfig[pageItem]{
+		Endpoint:   server.URL,
+		Pagination: PageNumberPagination{Limit: 2},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	var iterErr error
+	for _, err := range it.All(ctx) {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		count++
+		if count == 2 {
+			cancel()
+		}
+	}
+
+	assert.ErrorIs(t, iterErr, context.Canceled)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1, requests)
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{
+			name:     "github style",
+			values:   []string{`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel="last"`},
+			expected: "https://api.example.com/items?page=2",
+		},
+		{
+			name:     "next not first",
+			values:   []string{`</items?page=1>; rel="prev", </items?page=3>; rel=next`},
+			expected: "/items?page=3",
+		},
+		{
+			name:     "multiple relations",
+			values:   []string{`</items?page=3>; title="more"; rel="next last"`},
+			expected: "/items?page=3",
+		},
+		{
+			name:     "separate header values",
+			values:   []string{`</items?page=1>; rel="first"`, `</items?page=2>; rel="next"`},
+			expected: "/items?page=2",
+		},
+		{
+			name:     "no next",
+			values:   []string{`</items?page=1>; rel="prev"`},
+			expected: "",
+		},
+		{
+			name:     "no header",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, value := range tt.values {
+				header.Add("Link", value)
+			}
+			assert.Equal(t, tt.expected, nextLink(header))
+		})
+	}
+}
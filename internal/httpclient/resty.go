@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 
@@ -14,7 +15,9 @@ type RestClient interface {
 	Post(endpoint string, body, okResult, failedResult interface{}, headers map[string]string) (*resty.Response, error)
 	Put(endpoint string, body, okResult, failedResult interface{}, headers map[string]string) (*resty.Response, error)
 	Get(endpoint string, result interface{}, headers map[string]string, queryParams string) (*resty.Response, error)
+	GetWithContext(ctx context.Context, endpoint string, result interface{}, headers map[string]string, queryParams string) (*resty.Response, error)
 	Patch(endpoint string, body, okResult, failedResult interface{}, headers map[string]string) (*resty.Response, error)
+	Delete(endpoint string, failedResult interface{}, headers map[string]string) (*resty.Response, error)
 }
 
 type restClient struct {
@@ -111,6 +114,17 @@ func (r *restClient) Get(
 	result interface{},
 	headers map[string]string,
 	queryParams string,
+) (*resty.Response, error) {
+	return r.GetWithContext(context.Background(), endpoint, result, headers, queryParams)
+}
+
+// GetWithContext is Get bound to ctx, so that canceling ctx aborts the request and its retries
+func (r *restClient) GetWithContext(
+	ctx context.Context,
+	endpoint string,
+	result interface{},
+	headers map[string]string,
+	queryParams string,
 ) (*resty.Response, error) {
 	if headers == nil {
 		headers = make(map[string]string)
@@ -118,6 +132,7 @@ func (r *restClient) Get(
 	headers[echo.HeaderContentType] = echo.MIMEApplicationJSON
 
 	request := r.client.R().
+		SetContext(ctx).
 		SetHeaders(headers).
 		SetQueryString(queryParams)
 
@@ -147,3 +162,19 @@ func (r *restClient) Patch(
 		SetError(failedResult).
 		Patch(endpoint)
 }
+
+func (r *restClient) Delete(
+	endpoint string,
+	failedResult interface{},
+	headers map[string]string,
+) (*resty.Response, error) {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers[echo.HeaderContentType] = echo.MIMEApplicationJSON
+
+	return r.client.R().
+		SetHeaders(headers).
+		SetError(failedResult).
+		Delete(endpoint)
+}
@@ -369,6 +369,65 @@ func TestRestClient_Patch(t *testing.T) {
 	}
 }
 
+func TestRestClient_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		serverHandler  http.HandlerFunc
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:    "success - delete request",
+			headers: map[string]string{"Authorization": "Bearer token-123"},
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "Bearer token-123", r.Header.Get("Authorization"))
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   map[string]interface{}{},
+		},
+		{
+			name:    "error response - decoded in the failed result",
+			headers: nil,
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"error": "not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.serverHandler)
+			defer server.Close()
+
+			cfg := &config.Config{
+				HTTPClientTimeout:      60 * time.Second,
+				HTTPClientRetryCount:   0,
+				HTTPClientRetryWaitMin: 1 * time.Second,
+				HTTPClientRetryWaitMax: 5 * time.Second,
+				AppName:                "test-app",
+				AppVersion:             "1.0.0",
+			}
+
+			client := ProvideRestClient(cfg)
+			failedResult := &map[string]interface{}{}
+
+			response, err := client.Delete(server.URL, failedResult, tt.headers)
+
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			assert.Equal(t, tt.expectedStatus, response.StatusCode())
+			assert.Equal(t, tt.expectedBody, *failedResult)
+		})
+	}
+}
+
 func TestProvideRestClient(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,102 @@
+// Package antivirus scans the uploaded files before they become available. The scanner is
+// selected with VIRUS_SCANNER_PROVIDER: a clamd daemon of ClamAV, or an ICAP server.
+package antivirus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/monitoring"
+)
+
+// Result is the verdict of a scan
+type Result struct {
+	Infected bool
+	// Signature names the threat found in an infected content, e.g. Win.Test.EICAR_HDB-1
+	Signature string
+}
+
+// Scanner scans contents for viruses
+type Scanner interface {
+	// Scan reads content to its end and reports whether it is infected. An error means
+	// the content could not be scanned, not that it is clean.
+	Scan(ctx context.Context, content io.Reader) (*Result, error)
+	// Ping checks that the scanner is reachable
+	Ping(ctx context.Context) error
+}
+
+// ProvideScanner creates the scanner of VIRUS_SCANNER_PROVIDER, one finding every content
+// clean when it is empty
+func ProvideScanner(cfg *config.Config) (Scanner, error) {
+	switch cfg.VirusScannerProvider {
+	case "":
+		return disabledScanner{}, nil
+	case constants.VirusScannerProviderClamAV:
+		return NewClamAV(cfg.ClamAVAddress, cfg.VirusScanTimeout), nil
+	case constants.VirusScannerProviderICAP:
+		icap, err := NewICAP(cfg.ICAPURL, cfg.VirusScanTimeout)
+		if err != nil {
+			return nil, errors.InternalError("Invalid ICAP URL", err).
+				WithOperation("initialize_virus_scanner").
+				WithResource("antivirus")
+		}
+		return icap, nil
+	default:
+		return nil, errors.InternalError("Invalid virus scanner provider", fmt.Errorf("invalid virus scanner provider: %s", cfg.VirusScannerProvider)).
+			WithOperation("initialize_virus_scanner").
+			WithResource("antivirus").
+			WithContext("virus_scanner_provider", cfg.VirusScannerProvider)
+	}
+}
+
+// ProvideHealthChecker registers the virus scanner in the health registry. The uploads
+// fail while it is unreachable, but the rest of the API does not.
+func ProvideHealthChecker(scanner Scanner) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:  constants.DependencyAntivirus,
+		Check: scanner.Ping,
+	}
+}
+
+// dial connects to address, with a deadline of timeout or of ctx if it is sooner
+func dial(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func scanError(provider string, operation string, err error) *errors.AppError {
+	return errors.ExternalServiceError("Failed to scan file", err).
+		WithProvider(provider).
+		WithOperation(operation).
+		WithResource("antivirus")
+}
+
+// disabledScanner is used when no virus scanner is configured
+type disabledScanner struct{}
+
+func (disabledScanner) Scan(ctx context.Context, content io.Reader) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (disabledScanner) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,195 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve accepts the connections of a fake scanner on a local port and handles each one
+func serve(t *testing.T, handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			handle(conn)
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// fakeClamd answers INSTREAM with reply and records the streamed content
+func fakeClamd(t *testing.T, reply string, received *strings.Builder) string {
+	return serve(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		command, err := reader.ReadString(0)
+		if err != nil {
+			return
+		}
+		if command == "zPING\x00" {
+			conn.Write([]byte("PONG\x00"))
+			return
+		}
+		assert.Equal(t, "zINSTREAM\x00", command)
+		for {
+			var size uint32
+			if err := binary.Read(reader, binary.BigEndian, &size); err != nil || size == 0 {
+				break
+			}
+			chunk := make([]byte, size)
+			io.ReadFull(reader, chunk)
+			received.Write(chunk)
+		}
+		conn.Write([]byte(reply + "\x00"))
+	})
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	tests := []struct {
+		name          string
+		reply         string
+		expected      *Result
+		expectedError bool
+	}{
+		{name: "clean", reply: "stream: OK", expected: &Result{}},
+		{name: "infected", reply: "stream: Win.Test.EICAR_HDB-1 FOUND", expected: &Result{Infected: true, Signature: "Win.Test.EICAR_HDB-1"}},
+		{name: "content too large", reply: "INSTREAM size limit exceeded. ERROR", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received strings.Builder
+			scanner := NewClamAV(fakeClamd(t, tt.reply, &received), time.Second)
+			content := strings.Repeat("x", 100<<10)
+
+			result, err := scanner.Scan(context.Background(), strings.NewReader(content))
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, content, received.String())
+		})
+	}
+}
+
+func TestClamAV_Ping(t *testing.T) {
+	var received strings.Builder
+	assert.NoError(t, NewClamAV(fakeClamd(t, "", &received), time.Second).Ping(context.Background()))
+
+	// Nothing listens on the port of a closed listener
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+	assert.Error(t, NewClamAV(address, time.Second).Ping(context.Background()))
+}
+
+// fakeICAP answers RESPMOD with answer and records the encapsulated body
+func fakeICAP(t *testing.T, answer string, received *strings.Builder) string {
+	return serve(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		tp := textproto.NewReader(reader)
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		header, _ := tp.ReadMIMEHeader()
+		if strings.HasPrefix(line, "OPTIONS ") {
+			conn.Write([]byte("ICAP/1.0 200 OK\r\nMethods: RESPMOD\r\n\r\n"))
+			return
+		}
+		assert.Equal(t, "RESPMOD icap://"+header.Get("Host")+"/avscan ICAP/1.0", line)
+		assert.Equal(t, "204", header.Get("Allow"))
+
+		// The encapsulated response status line and headers, then its chunked body
+		status, _ := tp.ReadLine()
+		assert.Equal(t, "HTTP/1.1 200 OK", status)
+		_, err = tp.ReadMIMEHeader()
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(httputil.NewChunkedReader(reader))
+		received.Write(body)
+		conn.Write([]byte(answer))
+	})
+}
+
+func TestICAP_Scan(t *testing.T) {
+	tests := []struct {
+		name          string
+		answer        string
+		expected      *Result
+		expectedError bool
+	}{
+		{name: "clean", answer: "ICAP/1.0 204 No Content\r\n\r\n", expected: &Result{}},
+		{
+			name:     "infected with X-Infection-Found",
+			answer:   "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\nEncapsulated: res-hdr=0, null-body=19\r\n\r\nHTTP/1.1 403 Forbidden\r\n\r\n",
+			expected: &Result{Infected: true, Signature: "Eicar-Test-Signature"},
+		},
+		{
+			name:     "infected with X-Virus-ID",
+			answer:   "ICAP/1.0 200 OK\r\nX-Virus-ID: Win.Test.EICAR_HDB-1\r\n\r\n",
+			expected: &Result{Infected: true, Signature: "Win.Test.EICAR_HDB-1"},
+		},
+		{name: "service failure", answer: "ICAP/1.0 500 Server Error\r\n\r\n", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received strings.Builder
+			scanner, err := NewICAP("icap://"+fakeICAP(t, tt.answer, &received)+"/avscan", time.Second)
+			require.NoError(t, err)
+			content := strings.Repeat("x", 100<<10)
+
+			result, err := scanner.Scan(context.Background(), strings.NewReader(content))
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, content, received.String())
+		})
+	}
+}
+
+func TestICAP_Ping(t *testing.T) {
+	var received strings.Builder
+	scanner, err := NewICAP("icap://"+fakeICAP(t, "", &received)+"/avscan", time.Second)
+	require.NoError(t, err)
+
+	assert.NoError(t, scanner.Ping(context.Background()))
+}
+
+func TestNewICAP(t *testing.T) {
+	scanner, err := NewICAP("icap://scanner.internal/avscan", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "scanner.internal:1344", scanner.url.Host)
+
+	_, err = NewICAP("http://scanner.internal/avscan", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// ClamAV scans the contents with a clamd daemon, streaming them with its INSTREAM command
+type ClamAV struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV creates the scanner of the clamd daemon listening on address, e.g.
+// localhost:3310
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	return &ClamAV{address: address, timeout: timeout}
+}
+
+// Scan implements Scanner, sending content in chunks of constants.VirusScanChunkSize bytes,
+// each prefixed by its length, and a chunk of zero bytes to end it
+func (c *ClamAV) Scan(ctx context.Context, content io.Reader) (*Result, error) {
+	reply, err := c.command(ctx, "INSTREAM", func(w *bufio.Writer) error {
+		chunk := make([]byte, constants.VirusScanChunkSize)
+		for {
+			n, readErr := content.Read(chunk)
+			if n > 0 {
+				if err := binary.Write(w, binary.BigEndian, uint32(n)); err != nil {
+					return err
+				}
+				if _, err := w.Write(chunk[:n]); err != nil {
+					return err
+				}
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+		return binary.Write(w, binary.BigEndian, uint32(0))
+	})
+	if err != nil {
+		return nil, scanError(constants.VirusScannerProviderClamAV, "scan_file", err)
+	}
+
+	// The reply is "stream: OK", "stream: <signature> FOUND" or "<message> ERROR", e.g.
+	// when the content exceeds the StreamMaxLength of clamd
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, scanError(constants.VirusScannerProviderClamAV, "scan_file", fmt.Errorf("clamd: %s", reply))
+	}
+}
+
+// Ping implements Scanner with the PING command of clamd
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err == nil && reply != "PONG" {
+		err = fmt.Errorf("clamd: unexpected reply to PING: %s", reply)
+	}
+	if err != nil {
+		return scanError(constants.VirusScannerProviderClamAV, "ping_virus_scanner", err)
+	}
+	return nil
+}
+
+// command sends a null terminated command to clamd, then its payload if any, and returns
+// the reply of clamd
+func (c *ClamAV) command(ctx context.Context, name string, payload func(w *bufio.Writer) error) (string, error) {
+	conn, err := dial(ctx, c.address, c.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriterSize(conn, constants.VirusScanChunkSize+4)
+	if _, err := w.WriteString("z" + name + "\x00"); err != nil {
+		return "", err
+	}
+	if payload != nil {
+		if err := payload(w); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(err == io.EOF && reply != "") {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
@@ -0,0 +1,154 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// ICAP scans the contents with the RESPMOD service of an ICAP server (RFC 3507), such as
+// c-icap with its ClamAV module or the ICAP interface of a commercial antivirus. Each
+// content is sent as the body of an HTTP response: the server answers 204 for a clean
+// content and 200, with the response it would serve instead, for an infected one.
+type ICAP struct {
+	url     *url.URL
+	timeout time.Duration
+}
+
+// NewICAP creates the scanner of the ICAP service at rawURL, e.g.
+// icap://localhost:1344/avscan
+func NewICAP(rawURL string, timeout time.Duration) (*ICAP, error) {
+	serviceURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if serviceURL.Scheme != "icap" || serviceURL.Hostname() == "" {
+		return nil, fmt.Errorf("invalid ICAP URL: %s", rawURL)
+	}
+	if serviceURL.Port() == "" {
+		serviceURL.Host = net.JoinHostPort(serviceURL.Hostname(), constants.ICAPPort)
+	}
+	return &ICAP{url: serviceURL, timeout: timeout}, nil
+}
+
+// Scan implements Scanner, sending content chunked without a preview and allowing the
+// 204 answer
+func (i *ICAP) Scan(ctx context.Context, content io.Reader) (*Result, error) {
+	// The encapsulated HTTP response headers, whose length locates the body
+	responseHeaders := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nTransfer-Encoding: chunked\r\n\r\n"
+	headers := fmt.Sprintf("Allow: 204\r\nEncapsulated: res-hdr=0, res-body=%d\r\n", len(responseHeaders))
+
+	status, header, err := i.request(ctx, "RESPMOD", headers, func(w *bufio.Writer) error {
+		if _, err := w.WriteString(responseHeaders); err != nil {
+			return err
+		}
+		chunk := make([]byte, constants.VirusScanChunkSize)
+		for {
+			n, readErr := content.Read(chunk)
+			if n > 0 {
+				if _, err := fmt.Fprintf(w, "%x\r\n", n); err != nil {
+					return err
+				}
+				if _, err := w.Write(chunk[:n]); err != nil {
+					return err
+				}
+				if _, err := w.WriteString("\r\n"); err != nil {
+					return err
+				}
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				return readErr
+			}
+		}
+		_, err := w.WriteString("0\r\n\r\n")
+		return err
+	})
+	if err != nil {
+		return nil, scanError(constants.VirusScannerProviderICAP, "scan_file", err)
+	}
+
+	switch status {
+	case 204:
+		return &Result{}, nil
+	case 200:
+		return &Result{Infected: true, Signature: icapSignature(header)}, nil
+	default:
+		return nil, scanError(constants.VirusScannerProviderICAP, "scan_file", fmt.Errorf("icap: RESPMOD answered %d", status))
+	}
+}
+
+// Ping implements Scanner with an OPTIONS request of the service
+func (i *ICAP) Ping(ctx context.Context) error {
+	status, _, err := i.request(ctx, "OPTIONS", "Encapsulated: null-body=0\r\n", nil)
+	if err == nil && status != 200 {
+		err = fmt.Errorf("icap: OPTIONS answered %d", status)
+	}
+	if err != nil {
+		return scanError(constants.VirusScannerProviderICAP, "ping_virus_scanner", err)
+	}
+	return nil
+}
+
+// request sends an ICAP request with headers, then its body if any, and returns the
+// status and the headers of the answer
+func (i *ICAP) request(ctx context.Context, method string, headers string, body func(w *bufio.Writer) error) (int, textproto.MIMEHeader, error) {
+	conn, err := dial(ctx, i.url.Host, i.timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	if _, err := fmt.Fprintf(w, "%s %s ICAP/1.0\r\nHost: %s\r\n%s\r\n", method, i.url.String(), i.url.Host, headers); err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		if err := body(w); err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return 0, nil, err
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	line, err := reader.ReadLine()
+	if err != nil {
+		return 0, nil, err
+	}
+	var status int
+	if _, err := fmt.Sscanf(line, "ICAP/1.0 %d", &status); err != nil {
+		return 0, nil, fmt.Errorf("icap: invalid status line: %q", line)
+	}
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return 0, nil, err
+	}
+	return status, header, nil
+}
+
+// icapSignature returns the threat named by the headers of the answer about an infected
+// content: the Threat of X-Infection-Found (draft-stecher-icap-subid), or X-Virus-ID, as
+// sent by c-icap
+func icapSignature(header textproto.MIMEHeader) string {
+	for _, field := range strings.Split(header.Get("X-Infection-Found"), ";") {
+		if threat, ok := strings.CutPrefix(strings.TrimSpace(field), "Threat="); ok && threat != "" {
+			return threat
+		}
+	}
+	if virusID := strings.TrimSpace(header.Get("X-Virus-ID")); virusID != "" {
+		return virusID
+	}
+	return "unknown"
+}
@@ -3,15 +3,18 @@ package auth
 import (
 	"context"
 	"fmt"
+	"golang-boilerplate/internal/cache"
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/httpclient"
+	"golang-boilerplate/internal/monitoring"
 	"time"
 
 	"github.com/Nerzal/gocloak/v13"
 	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
 // User represents an authenticated user
@@ -65,6 +68,11 @@ type TokenClaims struct {
 	FamilyName           string                            `json:"family_name"`
 	Scope                string                            `json:"scope"`
 	AllowedOrigins       []string                          `json:"allowed-origins"`
+	// ACR is the authentication context class of the login, e.g. its level of assurance
+	ACR string `json:"acr"`
+	// AMR are the authentication methods of the login, e.g. pwd and otp, when the
+	// authentication method reference mapper is added to the client
+	AMR []string `json:"amr"`
 
 	// Present in RPT tokens when Authorization Services are enabled
 	Authorization AuthorizationPermissions `json:"authorization"`
@@ -110,11 +118,44 @@ type Organization struct {
 	ID   string `json:"id"`
 }
 
+// TenantOrganization is the organization of a provisioned tenant. Its alias is the slug
+// of the tenant, which Keycloak does not let change.
+type TenantOrganization struct {
+	ID      string
+	Name    string
+	Alias   string
+	Domains []string
+	// Attributes are kept when the organization is saved, the slug attribute included
+	Attributes map[string][]string
+}
+
+// MFAStatus is the multi-factor authentication state of a user of the realm
+type MFAStatus struct {
+	// Configured is set when the user has an OTP authenticator
+	Configured bool
+	// Required is set when Keycloak asks the user to configure one at their next login
+	Required bool
+}
+
+// Session is an active session of a user of the realm, one per login on a device
+type Session struct {
+	ID        string
+	IPAddress string
+	// Start is when the user logged in, LastAccess when the session was last refreshed
+	Start      time.Time
+	LastAccess time.Time
+	// Clients are the client IDs the session holds tokens of
+	Clients []string
+}
+
 // AuthService defines the interface for authentication operations
 type AuthService interface {
 	GetRealm() string
 	DecodeAccessToken(ctx context.Context, token string, realm string, claims *TokenClaims) (*TokenClaims, error)
 	ValidateToken(token string) (*gocloak.IntroSpectTokenResult, error)
+	// VerifyAccessToken verifies an access token offline against the keys of the realm and
+	// decodes its claims, without calling Keycloak once the keys are cached
+	VerifyAccessToken(ctx context.Context, token string, claims *TokenClaims) error
 	ClientLogin() (*TokenInfo, error)
 	GetUserInfo(token string) (*User, error)
 	GetClaimsKey() string
@@ -122,23 +163,87 @@ type AuthService interface {
 	// Obtain an RPT (Requesting Party Token) using UMA to evaluate permissions
 	GetRequestingPartyToken(ctx context.Context, accessToken string, opts RequestingPartyTokenOptions) (*JWT, error)
 	CreateUser(ctx context.Context, adminToken string, userDto *dtos.CreateUserRequest) (*User, error)
+	// FindUserByEmail returns the user of the realm with the email, nil when there is none
+	FindUserByEmail(ctx context.Context, adminToken string, email string) (*User, error)
 	SetPassword(ctx context.Context, adminToken string, userID string, password string, temporary bool) error
 	SendVerificationMail(ctx context.Context, adminToken string, userID string, params SendVerificationMailParams) error
 	GetClientID() string
 	GetRedirectURI() string
 	GetOrganization(userClaims *TokenClaims) (Organization, error)
 	AddUserToOrganization(ctx context.Context, adminToken string, userID string, organizationID string) error
+	// RemoveUserFromOrganization ends the membership of the user in the organization
+	RemoveUserFromOrganization(ctx context.Context, adminToken string, userID string, organizationID string) error
+	ListOrganizationMembers(ctx context.Context, adminToken string, organizationID string) ([]User, error)
+	// ListUsers returns every user of the realm, without the service accounts
+	ListUsers(ctx context.Context, adminToken string) ([]User, error)
+	// ListOrganizations returns every organization of the realm
+	ListOrganizations(ctx context.Context, adminToken string) ([]TenantOrganization, error)
+	// ListClientRoleMembers returns the IDs of the users granted each of the client roles,
+	// leaving out the roles the client does not define
+	ListClientRoleMembers(ctx context.Context, adminToken string, roles []string) (map[string][]string, error)
+	// GetUser returns the user of the realm with the ID, nil when there is none
+	GetUser(ctx context.Context, adminToken string, userID string) (*User, error)
 	AddClientRolesToUser(ctx context.Context, adminToken string, userID string, clientID string, role string) error
+	// UpdateUser enables or disables the user from the status of the request and replaces
+	// the names and email it sets
 	UpdateUser(ctx context.Context, adminToken string, userID string, userDto *dtos.UpdateUserRequest) error
+	// DeleteUser deletes the user from the realm
+	DeleteUser(ctx context.Context, adminToken string, userID string) error
+	// GetMFAStatus returns whether the user has an OTP authenticator and whether they are
+	// asked to configure one
+	GetMFAStatus(ctx context.Context, adminToken string, userID string) (*MFAStatus, error)
+	// RequireOTP asks the user to configure an OTP authenticator at their next login
+	RequireOTP(ctx context.Context, adminToken string, userID string) error
+	// SendOTPEnrollmentEmail emails the user a link to configure an OTP authenticator
+	SendOTPEnrollmentEmail(ctx context.Context, adminToken string, userID string) error
+	// ResetOTP removes the OTP authenticators of the user and the request to configure one
+	ResetOTP(ctx context.Context, adminToken string, userID string) error
+	// GetUserSessions returns the active sessions of the user, the most recent first
+	GetUserSessions(ctx context.Context, adminToken string, userID string) ([]Session, error)
+	// LogoutUserSession ends the session; its refresh tokens are revoked at once
+	LogoutUserSession(ctx context.Context, adminToken string, sessionID string) error
+	// FindTenantOrganization returns the organization of a provisioned tenant, nil when
+	// there is none
+	FindTenantOrganization(ctx context.Context, adminToken string, slug string) (*TenantOrganization, error)
+	// SaveTenantOrganization creates the organization when it has no ID, or replaces its
+	// name and domains, and returns it with its ID
+	SaveTenantOrganization(ctx context.Context, adminToken string, organization *TenantOrganization) (*TenantOrganization, error)
+	// EnsureClientRoles creates the roles of the client missing from Keycloak
+	EnsureClientRoles(ctx context.Context, adminToken string, roles []string) error
+	// ListUserOrganizations returns the organizations the user is a member of
+	ListUserOrganizations(ctx context.Context, adminToken string, userID string) ([]TenantOrganization, error)
+	// ExchangeOrganizationToken exchanges the access token of a user for tokens bound to one
+	// of their organizations, identified by its alias
+	ExchangeOrganizationToken(ctx context.Context, subjectToken string, organizationAlias string) (*JWT, error)
+	// Login logs a user in with their username and password
+	Login(ctx context.Context, username string, password string) (*JWT, error)
+	// RefreshToken refreshes the tokens of a session with its refresh token
+	RefreshToken(ctx context.Context, refreshToken string) (*JWT, error)
+	// ExchangeToken exchanges the access token of a user for an access token of another
+	// client of the realm
+	ExchangeToken(ctx context.Context, subjectToken string, audience string, scope string) (*JWT, error)
+	// HealthCheck checks that the realm is reachable and its endpoints resolve
+	HealthCheck(ctx context.Context) error
+}
+
+// ProvideHealthChecker registers the auth provider in the health registry
+func ProvideHealthChecker(authService AuthService) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:     constants.DependencyAuth,
+		Critical: true,
+		Check:    authService.HealthCheck,
+	}
 }
 
 func ProvideAuth(
 	cfg *config.Config,
 	restClient httpclient.RestClient,
+	cache cache.Cache,
+	nrApp *newrelic.Application,
 ) (AuthService, error) {
 	switch cfg.AuthProvider {
 	case constants.AuthProviderKeycloak:
-		keycloakAuth, err := NewKeycloakAuth(cfg, restClient)
+		keycloakAuth, err := NewKeycloakAuth(cfg, restClient, cache, nrApp)
 		if err != nil {
 			return nil, errors.ExternalServiceError("Failed to initialize Keycloak auth", err).
 				WithOperation("initialize_auth_provider").
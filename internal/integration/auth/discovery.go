@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/httpclient"
+)
+
+// KeycloakEndpoints are the endpoints of the realm, read from its OpenID discovery document.
+// The admin REST API is not part of the document, its base is resolved from the context
+// path the document was found under.
+type KeycloakEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+	// ServerURL is the Keycloak URL with its context path, e.g. https://sso.example.com/auth
+	ServerURL string `json:"-"`
+	// AdminURL is the base of the admin REST API of the realm
+	AdminURL string `json:"-"`
+}
+
+// validate checks that the document is the one of the realm and has the endpoints the
+// adapter uses
+func (e *KeycloakEndpoints) validate(realm string) error {
+	if !strings.HasSuffix(strings.TrimRight(e.Issuer, "/"), "/realms/"+realm) {
+		return fmt.Errorf("issuer %q is not the one of realm %q", e.Issuer, realm)
+	}
+
+	required := []struct {
+		name     string
+		endpoint string
+	}{
+		{"token_endpoint", e.TokenEndpoint},
+		{"introspection_endpoint", e.IntrospectionEndpoint},
+		{"userinfo_endpoint", e.UserinfoEndpoint},
+		{"jwks_uri", e.JwksURI},
+	}
+	for _, r := range required {
+		if r.endpoint == "" {
+			return fmt.Errorf("discovery document of realm %q has no %s", realm, r.name)
+		}
+	}
+
+	return nil
+}
+
+// keycloakDiscovery resolves the endpoints of the realm and caches them, so that the
+// adapter does not depend on the URL layout of a Keycloak version
+type keycloakDiscovery struct {
+	restClient httpclient.RestClient
+	baseURL    string
+	realm      string
+	// interval is the minimum time between two rediscoveries
+	interval time.Duration
+
+	mu           sync.RWMutex
+	endpoints    *KeycloakEndpoints
+	discoveredAt time.Time
+}
+
+func newKeycloakDiscovery(restClient httpclient.RestClient, baseURL string, realm string) *keycloakDiscovery {
+	return &keycloakDiscovery{
+		restClient: restClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		realm:      realm,
+		interval:   constants.KeycloakRediscoveryInterval,
+	}
+}
+
+// Endpoints returns the endpoints discovered last, nil before the first discovery
+func (d *keycloakDiscovery) Endpoints() *KeycloakEndpoints {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.endpoints
+}
+
+// Discover fetches the discovery document of the realm under each context path until one
+// is found, validates it and caches its endpoints
+func (d *keycloakDiscovery) Discover(ctx context.Context) (*KeycloakEndpoints, error) {
+	var notFound error
+	for _, contextPath := range constants.KeycloakContextPaths {
+		serverURL := d.baseURL + contextPath
+		realmURL := fmt.Sprintf("%s/realms/%s", serverURL, url.PathEscape(d.realm))
+		documentURL := realmURL + "/" + constants.KeycloakDiscoveryPath
+
+		var endpoints KeycloakEndpoints
+		resp, err := d.restClient.GetWithContext(ctx, documentURL, &endpoints, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", documentURL, err)
+		}
+		if resp.IsError() {
+			statusErr := &httpclient.StatusError{
+				Endpoint:   documentURL,
+				StatusCode: resp.StatusCode(),
+				Body:       resp.String(),
+			}
+			// Try the next context path, the realm may be served elsewhere
+			if resp.StatusCode() == http.StatusNotFound {
+				notFound = statusErr
+				continue
+			}
+			return nil, statusErr
+		}
+
+		endpoints.ServerURL = serverURL
+		endpoints.AdminURL = fmt.Sprintf("%s/admin/realms/%s", serverURL, url.PathEscape(d.realm))
+		if err := endpoints.validate(d.realm); err != nil {
+			return nil, err
+		}
+
+		d.mu.Lock()
+		d.endpoints = &endpoints
+		d.discoveredAt = time.Now()
+		d.mu.Unlock()
+
+		return &endpoints, nil
+	}
+
+	return nil, notFound
+}
+
+// Rediscover discovers the endpoints again, unless the last discovery is more recent than
+// the interval, and reports whether they changed
+func (d *keycloakDiscovery) Rediscover(ctx context.Context) (bool, error) {
+	d.mu.RLock()
+	previous := d.endpoints
+	recent := previous != nil && time.Since(d.discoveredAt) < d.interval
+	d.mu.RUnlock()
+	if recent {
+		return false, nil
+	}
+
+	endpoints, err := d.Discover(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return previous == nil || *previous != *endpoints, nil
+}
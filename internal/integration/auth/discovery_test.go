@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/httpclient"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// fakeKeycloak serves the discovery document of a realm and its organization members under
+// a context path, which a test can change to simulate an upgrade
+type fakeKeycloak struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	contextPath string
+	issuer      string
+	documents   int
+	// introspections counts the calls of the introspection endpoint
+	introspections int
+}
+
+func newFakeKeycloak(t *testing.T, contextPath string) *fakeKeycloak {
+	t.Helper()
+	kc := &fakeKeycloak{contextPath: contextPath}
+	kc.Server = httptest.NewServer(http.HandlerFunc(kc.serve))
+	t.Cleanup(kc.Close)
+	return kc
+}
+
+func (kc *fakeKeycloak) setContextPath(contextPath string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.contextPath = contextPath
+}
+
+func (kc *fakeKeycloak) serve(w http.ResponseWriter, r *http.Request) {
+	kc.mu.Lock()
+	contextPath := kc.contextPath
+	issuer := kc.issuer
+	kc.mu.Unlock()
+	serverURL := kc.URL + contextPath
+	if issuer == "" {
+		issuer = serverURL + "/realms/test"
+	}
+
+	path, found := strings.CutPrefix(r.URL.Path, contextPath)
+	switch {
+	case !found:
+		w.WriteHeader(http.StatusNotFound)
+	case path == "/realms/test/.well-known/openid-configuration":
+		kc.mu.Lock()
+		kc.documents++
+		kc.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"token_endpoint":         serverURL + "/realms/test/protocol/openid-connect/token",
+			"introspection_endpoint": serverURL + "/realms/test/protocol/openid-connect/token/introspect",
+			"userinfo_endpoint":      serverURL + "/realms/test/protocol/openid-connect/userinfo",
+			"jwks_uri":               serverURL + "/realms/test/protocol/openid-connect/certs",
+		})
+	case path == "/realms/test/protocol/openid-connect/token":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":300,"token_type":"Bearer"}`))
+	case path == "/realms/test/protocol/openid-connect/token/introspect":
+		kc.mu.Lock()
+		kc.introspections++
+		kc.mu.Unlock()
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("token") != "active-token" {
+			_, _ = w.Write([]byte(`{"active":false}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"active": true,
+			"sub":    "user-1",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		})
+	case path == "/admin/realms/test/organizations/org-1/members":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"user-1","username":"jane","email":"jane@example.com"}]`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestRestClient() httpclient.RestClient {
+	return httpclient.ProvideRestClient(&config.Config{HTTPClientTimeout: time.Second})
+}
+
+func TestKeycloakDiscovery_Discover(t *testing.T) {
+	tests := []struct {
+		name          string
+		contextPath   string
+		realm         string
+		issuer        string
+		expectedError string
+	}{
+		{name: "keycloak 17+", realm: "test"},
+		{name: "legacy context path", contextPath: "/auth", realm: "test"},
+		{name: "unknown realm", realm: "unknown", expectedError: "unexpected status 404"},
+		{name: "issuer of another realm", realm: "test", issuer: "https://sso.example.com/realms/other", expectedError: "is not the one of realm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc := newFakeKeycloak(t, tt.contextPath)
+			kc.issuer = tt.issuer
+			discovery := newKeycloakDiscovery(newTestRestClient(), kc.URL+"/", tt.realm)
+
+			endpoints, err := discovery.Discover(context.Background())
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, discovery.Endpoints())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, kc.URL+tt.contextPath, endpoints.ServerURL)
+			assert.Equal(t, kc.URL+tt.contextPath+"/admin/realms/test", endpoints.AdminURL)
+			assert.Equal(t, kc.URL+tt.contextPath+"/realms/test/protocol/openid-connect/certs", endpoints.JwksURI)
+			assert.Equal(t, endpoints, discovery.Endpoints())
+		})
+	}
+}
+
+func TestKeycloakDiscovery_Rediscover(t *testing.T) {
+	kc := newFakeKeycloak(t, "")
+	discovery := newKeycloakDiscovery(newTestRestClient(), kc.URL, "test")
+	_, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+
+	// A recent discovery is not repeated
+	kc.setContextPath("/auth")
+	changed, err := discovery.Rediscover(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, kc.documents)
+
+	discovery.interval = 0
+	changed, err = discovery.Rediscover(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, kc.URL+"/auth/admin/realms/test", discovery.Endpoints().AdminURL)
+
+	changed, err = discovery.Rediscover(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed, "the endpoints are the same")
+}
+
+func TestKeycloakAuth_ListOrganizationMembers_Rediscovers(t *testing.T) {
+	kc := newFakeKeycloak(t, "")
+	keycloakAuth, err := NewKeycloakAuth(&config.Config{
+		KeycloakURL:          kc.URL,
+		KeycloakRealm:        "test",
+		HTTPClientTimeout:    time.Second,
+		StartupRetryAttempts: 1,
+	}, newTestRestClient(), nil, nil)
+	require.NoError(t, err)
+
+	// Keycloak moves to another context path on an upgrade
+	kc.setContextPath("/auth")
+	keycloakAuth.discovery.interval = 0
+
+	members, err := keycloakAuth.ListOrganizationMembers(context.Background(), "token", "org-1")
+	require.NoError(t, err)
+
+	require.Len(t, members, 1)
+	assert.Equal(t, "user-1", members[0].ID)
+	assert.Equal(t, kc.URL+"/auth/admin/realms/test", keycloakAuth.discovery.Endpoints().AdminURL)
+	require.NoError(t, keycloakAuth.HealthCheck(context.Background()))
+}
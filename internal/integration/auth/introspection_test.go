@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache holds the values in memory with their TTL; the other methods of the cache
+// are not used by the introspection
+type memoryCache struct {
+	cache.Cache
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+	data map[string]string
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{ttls: make(map[string]time.Duration), data: make(map[string]string)}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.data[key]
+	if !ok {
+		return "", errors.NotFoundError("Cache key", fmt.Errorf("key not found"))
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	c.ttls[key] = expiration
+	return nil
+}
+
+func TestKeycloakAuth_ValidateToken_Cache(t *testing.T) {
+	kc := newFakeKeycloak(t, "")
+	memory := newMemoryCache()
+	keycloakAuth, err := NewKeycloakAuth(&config.Config{
+		KeycloakURL:              kc.URL,
+		KeycloakRealm:            "test",
+		HTTPClientTimeout:        time.Second,
+		StartupRetryAttempts:     1,
+		KeycloakIntrospectionTTL: 10 * time.Second,
+		KeycloakInactiveTokenTTL: time.Minute,
+	}, newTestRestClient(), memory, nil)
+	require.NoError(t, err)
+
+	// The results are introspected once per token, the inactive ones included
+	for i := 0; i < 3; i++ {
+		result, err := keycloakAuth.ValidateToken("active-token")
+		require.NoError(t, err)
+		assert.True(t, *result.Active)
+		require.NotNil(t, result.Exp)
+
+		result, err = keycloakAuth.ValidateToken("revoked-token")
+		require.NoError(t, err)
+		assert.False(t, *result.Active)
+	}
+	assert.Equal(t, 2, kc.introspections)
+
+	// The tokens are not kept in the cache, only their hashes
+	require.Len(t, memory.ttls, 2)
+	var ttls []time.Duration
+	for key, ttl := range memory.ttls {
+		assert.NotContains(t, key, "token")
+		ttls = append(ttls, ttl)
+	}
+	assert.ElementsMatch(t, []time.Duration{10 * time.Second, time.Minute}, ttls)
+}
+
+func TestKeycloakAuth_IntrospectionTTL(t *testing.T) {
+	active, inactive := true, false
+	expiresIn := func(d time.Duration) *int {
+		exp := int(time.Now().Add(d).Unix())
+		return &exp
+	}
+
+	tests := []struct {
+		name     string
+		result   gocloak.IntroSpectTokenResult
+		expected time.Duration
+	}{
+		{name: "active token", result: gocloak.IntroSpectTokenResult{Active: &active, Exp: expiresIn(time.Hour)}, expected: 10 * time.Second},
+		{name: "active token without expiry", result: gocloak.IntroSpectTokenResult{Active: &active}, expected: 10 * time.Second},
+		{name: "inactive token", result: gocloak.IntroSpectTokenResult{Active: &inactive}, expected: time.Minute},
+		{name: "result without activity", result: gocloak.IntroSpectTokenResult{}, expected: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &KeycloakAuth{config: &config.Config{KeycloakIntrospectionTTL: 10 * time.Second, KeycloakInactiveTokenTTL: time.Minute}}
+			assert.Equal(t, tt.expected, a.introspectionTTL(&tt.result))
+		})
+	}
+
+	// A token about to expire is cached at most until it expires
+	a := &KeycloakAuth{config: &config.Config{KeycloakIntrospectionTTL: 10 * time.Second}}
+	ttl := a.introspectionTTL(&gocloak.IntroSpectTokenResult{Active: &active, Exp: expiresIn(3 * time.Second)})
+	assert.Greater(t, ttl, time.Second)
+	assert.LessOrEqual(t, ttl, 3*time.Second)
+	ttl = a.introspectionTTL(&gocloak.IntroSpectTokenResult{Active: &active, Exp: expiresIn(-time.Second)})
+	assert.LessOrEqual(t, ttl, time.Duration(0), "an expired token is not cached")
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/httpclient"
+	"golang-boilerplate/internal/logger"
+)
+
+// jsonWebKey is a key of a JWK set (RFC 7517), RSA or EC
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes the public key of the JWK
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// keycloakJWKS caches the signing keys of the realm by key ID, so that the access tokens
+// are verified without calling Keycloak. The keys are fetched again once they are older
+// than refreshInterval, and when a token is signed with an unknown key, e.g. after a
+// rotation, at most once per minInterval so that forged key IDs do not flood Keycloak.
+type keycloakJWKS struct {
+	restClient      httpclient.RestClient
+	refreshInterval time.Duration
+	minInterval     time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+
+	// fetching serializes the fetches, the callers waiting for one use its outcome
+	fetching    sync.Mutex
+	attemptedAt time.Time
+	fetchErr    error
+}
+
+func newKeycloakJWKS(restClient httpclient.RestClient, refreshInterval time.Duration, minInterval time.Duration) *keycloakJWKS {
+	return &keycloakJWKS{
+		restClient:      restClient,
+		refreshInterval: refreshInterval,
+		minInterval:     minInterval,
+	}
+}
+
+// key returns the key of kid, fetching the keys of jwksURI when they are stale or do not
+// have it. Stale keys are still used while Keycloak cannot be reached.
+func (j *keycloakJWKS) key(ctx context.Context, jwksURI string, kid string) (any, error) {
+	j.mu.RLock()
+	key, found := j.keys[kid]
+	fresh := time.Since(j.fetchedAt) < j.refreshInterval
+	j.mu.RUnlock()
+	if found && fresh {
+		return key, nil
+	}
+
+	if err := j.fetch(ctx, jwksURI); err != nil {
+		if found {
+			logger.Sugar.Warnw("Failed to refresh the keys of the realm, using the cached ones", "error", err)
+			return key, nil
+		}
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, found := j.keys[kid]; found {
+		return key, nil
+	}
+	return nil, errUnknownKey{kid: kid}
+}
+
+// fetch replaces the keys with the ones of jwksURI, unless they were attempted less than
+// minInterval ago, in which case it returns the outcome of that attempt
+func (j *keycloakJWKS) fetch(ctx context.Context, jwksURI string) error {
+	j.fetching.Lock()
+	defer j.fetching.Unlock()
+
+	if !j.attemptedAt.IsZero() && time.Since(j.attemptedAt) < j.minInterval {
+		return j.fetchErr
+	}
+	j.attemptedAt = time.Now()
+	j.fetchErr = j.fetchKeys(ctx, jwksURI)
+	return j.fetchErr
+}
+
+// fetchKeys fetches the keys of jwksURI and replaces the cached ones
+func (j *keycloakJWKS) fetchKeys(ctx context.Context, jwksURI string) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	resp, err := j.restClient.GetWithContext(ctx, jwksURI, &set, nil, "")
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", jwksURI, err)
+	}
+	if resp.IsError() {
+		return &httpclient.StatusError{Endpoint: jwksURI, StatusCode: resp.StatusCode(), Body: resp.String()}
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.Sugar.Warnw("Skipping an invalid key of the realm", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+// errUnknownKey is returned for a token signed with a key the realm does not have
+type errUnknownKey struct {
+	kid string
+}
+
+func (e errUnknownKey) Error() string {
+	return fmt.Sprintf("unknown signing key %q", e.kid)
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJWKS serves the public keys of its signing keys as the JWK set of a realm
+type fakeJWKS struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	status  int
+	fetches int
+}
+
+func newFakeJWKS(t *testing.T) *fakeJWKS {
+	t.Helper()
+	j := &fakeJWKS{keys: map[string]*rsa.PrivateKey{}, status: http.StatusOK}
+	j.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.fetches++
+		if j.status != http.StatusOK {
+			w.WriteHeader(j.status)
+			return
+		}
+		keys := []map[string]string{}
+		for kid, key := range j.keys {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	t.Cleanup(j.Close)
+	return j
+}
+
+// addKey generates the signing key kid
+func (j *fakeJWKS) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys[kid] = key
+	return key
+}
+
+func (j *fakeJWKS) setStatus(status int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+}
+
+const testIssuer = "https://sso.example.com/realms/test"
+
+// newJWKSKeycloakAuth creates a KeycloakAuth verifying the tokens of testIssuer against
+// the keys of jwks
+func newJWKSKeycloakAuth(jwks *fakeJWKS, audiences ...string) *KeycloakAuth {
+	return &KeycloakAuth{
+		config: &config.Config{KeycloakRealm: "test", KeycloakClientID: "backend", KeycloakAudiences: audiences},
+		discovery: &keycloakDiscovery{
+			endpoints: &KeycloakEndpoints{Issuer: testIssuer, JwksURI: jwks.URL},
+		},
+		jwks: newKeycloakJWKS(newTestRestClient(), time.Minute, 0),
+	}
+}
+
+// signToken signs claims, completed with a valid issuer, expiry, type and audience, with
+// key as kid
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	all := jwt.MapClaims{
+		"iss":   testIssuer,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"typ":   "Bearer",
+		"azp":   "backend",
+		"sub":   "user-1",
+		"email": "jane@example.com",
+	}
+	for name, value := range claims {
+		all[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestKeycloakAuth_VerifyAccessToken(t *testing.T) {
+	jwks := newFakeJWKS(t)
+	key := jwks.addKey(t, "key-1")
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		audiences    []string
+		token        func() string
+		expectedType errors.ErrorType
+	}{
+		{
+			name:  "valid token of the client",
+			token: func() string { return signToken(t, key, "key-1", nil) },
+		},
+		{
+			name:      "audience of the config",
+			audiences: []string{"api"},
+			token: func() string {
+				return signToken(t, key, "key-1", jwt.MapClaims{"azp": "frontend", "aud": []string{"api", "account"}})
+			},
+		},
+		{
+			name: "expired token",
+			token: func() string {
+				return signToken(t, key, "key-1", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})
+			},
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name: "token of another issuer",
+			token: func() string {
+				return signToken(t, key, "key-1", jwt.MapClaims{"iss": "https://evil.example.com/realms/test"})
+			},
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name:         "token of another client",
+			token:        func() string { return signToken(t, key, "key-1", jwt.MapClaims{"azp": "frontend", "aud": "account"}) },
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name:         "ID token",
+			token:        func() string { return signToken(t, key, "key-1", jwt.MapClaims{"typ": "ID"}) },
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name:         "token signed with another key",
+			token:        func() string { return signToken(t, otherKey, "key-1", nil) },
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name:         "token signed with an unknown key",
+			token:        func() string { return signToken(t, otherKey, "key-2", nil) },
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+		{
+			name: "token signed with a shared secret",
+			token: func() string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": testIssuer}).SignedString([]byte("secret"))
+				require.NoError(t, err)
+				return signed
+			},
+			expectedType: errors.ErrorTypeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newJWKSKeycloakAuth(jwks, tt.audiences...)
+
+			var claims TokenClaims
+			err := a.VerifyAccessToken(context.Background(), tt.token(), &claims)
+
+			if tt.expectedType != "" {
+				appErr := errors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.expectedType, appErr.Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Sub)
+			assert.Equal(t, "jane@example.com", claims.Email)
+			assert.Equal(t, claims.Sub, claims.MapClaims["sub"])
+		})
+	}
+}
+
+func TestKeycloakAuth_VerifyAccessToken_Keys(t *testing.T) {
+	jwks := newFakeJWKS(t)
+	key := jwks.addKey(t, "key-1")
+	a := newJWKSKeycloakAuth(jwks)
+
+	// The keys are fetched once and cached
+	for i := 0; i < 3; i++ {
+		require.NoError(t, a.VerifyAccessToken(context.Background(), signToken(t, key, "key-1", nil), &TokenClaims{}))
+	}
+	assert.Equal(t, 1, jwks.fetches)
+
+	// A rotated key is fetched when a token is signed with it
+	rotated := jwks.addKey(t, "key-2")
+	require.NoError(t, a.VerifyAccessToken(context.Background(), signToken(t, rotated, "key-2", nil), &TokenClaims{}))
+	assert.Equal(t, 2, jwks.fetches)
+
+	// Keycloak being unreachable fails the tokens of the keys not cached as an external error
+	jwks.setStatus(http.StatusServiceUnavailable)
+	err := a.VerifyAccessToken(context.Background(), signToken(t, rotated, "key-3", nil), &TokenClaims{})
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrorTypeExternal, appErr.Type)
+	require.NoError(t, a.VerifyAccessToken(context.Background(), signToken(t, key, "key-1", nil), &TokenClaims{}))
+}
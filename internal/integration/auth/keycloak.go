@@ -2,54 +2,213 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"golang-boilerplate/internal/cache"
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/httpclient"
-	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/retry"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"golang-boilerplate/internal/logger"
 
 	"github.com/Nerzal/gocloak/v13"
-	"github.com/getsentry/sentry-go"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/time/rate"
 )
 
+// keycloakMember is a member of a Keycloak organization as returned by the admin API
+type keycloakMember struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Enabled       *bool  `json:"enabled"`
+}
+
 // KeycloakAuth implements AuthService using Keycloak
 type KeycloakAuth struct {
-	client     *gocloak.GoCloak
 	restClient httpclient.RestClient
 	config     *config.Config
+	// adminLimiter paces paginated admin API listings, nil when unlimited
+	adminLimiter *rate.Limiter
+	discovery    *keycloakDiscovery
+	jwks         *keycloakJWKS
+	// cache holds the introspection results of the tokens
+	cache cache.Cache
+	nrApp *newrelic.Application
+
+	// mu guards client, which is rebuilt when the endpoints are discovered anew
+	mu     sync.RWMutex
+	client *gocloak.GoCloak
 }
 
 // NewKeycloakAuth creates a new Keycloak authentication service
-func NewKeycloakAuth(cfg *config.Config, restClient httpclient.RestClient) (*KeycloakAuth, error) {
-	return &KeycloakAuth{
-		client:     gocloak.NewClient(cfg.KeycloakURL),
-		restClient: restClient,
-		config:     cfg,
-	}, nil
+func NewKeycloakAuth(cfg *config.Config, restClient httpclient.RestClient, cache cache.Cache, nrApp *newrelic.Application) (*KeycloakAuth, error) {
+	var adminLimiter *rate.Limiter
+	if cfg.KeycloakAdminRateLimit > 0 {
+		adminLimiter = rate.NewLimiter(rate.Limit(cfg.KeycloakAdminRateLimit), 1)
+	}
+
+	discovery := newKeycloakDiscovery(restClient, cfg.KeycloakURL, cfg.KeycloakRealm)
+	if err := waitForRealm(discovery, cfg); err != nil {
+		return nil, err
+	}
+
+	a := &KeycloakAuth{
+		restClient:   restClient,
+		config:       cfg,
+		adminLimiter: adminLimiter,
+		discovery:    discovery,
+		jwks:         newKeycloakJWKS(restClient, cfg.KeycloakJWKSRefresh, constants.KeycloakJWKSMinRefreshInterval),
+		cache:        cache,
+		nrApp:        nrApp,
+	}
+	a.useEndpoints(discovery.Endpoints())
+
+	return a, nil
+}
+
+// waitForRealm discovers the endpoints of the realm on startup, retrying while Keycloak is
+// unavailable. Failures Keycloak will not recover from, such as an unknown realm or an
+// invalid discovery document, are not retried.
+func waitForRealm(discovery *keycloakDiscovery, cfg *config.Config) error {
+	backoff := retry.Backoff{
+		InitialInterval: cfg.StartupRetryDelay,
+		MaxInterval:     cfg.StartupRetryMaxDelay,
+		Jitter:          retry.DefaultJitter,
+		MaxAttempts:     cfg.StartupRetryAttempts,
+		MaxElapsedTime:  cfg.StartupRetryMaxElapsed,
+	}
+
+	return retry.Do(context.Background(), "connect_keycloak", backoff, func(ctx context.Context, attempt int) error {
+		ctx, cancel := context.WithTimeout(ctx, cfg.HTTPClientTimeout)
+		defer cancel()
+
+		if _, err := discovery.Discover(ctx); err != nil {
+			appErr := errors.ExternalServiceError("Failed to reach Keycloak realm", err).
+				WithOperation("connect_keycloak").
+				WithResource("auth").
+				WithProvider(constants.AuthProviderKeycloak).
+				WithContext("realm", cfg.KeycloakRealm)
+			if !appErr.Retryable {
+				return retry.Permanent(appErr)
+			}
+			return appErr
+		}
+
+		return nil
+	})
+}
+
+// useEndpoints points the gocloak client at the server URL of the endpoints
+func (a *KeycloakAuth) useEndpoints(endpoints *KeycloakEndpoints) {
+	client := gocloak.NewClient(endpoints.ServerURL)
+
+	a.mu.Lock()
+	a.client = client
+	a.mu.Unlock()
+
+	logger.Sugar.Infow("Using discovered Keycloak endpoints",
+		"realm", a.config.KeycloakRealm,
+		"issuer", endpoints.Issuer,
+		"admin_url", endpoints.AdminURL,
+	)
+}
+
+// gocloak returns the client of the endpoints discovered last
+func (a *KeycloakAuth) gocloak() *gocloak.GoCloak {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.client
+}
+
+// organizationMembersURL returns the admin API URL of the members of an organization
+func organizationMembersURL(endpoints *KeycloakEndpoints, organizationID string) string {
+	return fmt.Sprintf("%s/organizations/%s/members", endpoints.AdminURL, url.PathEscape(organizationID))
+}
+
+// withRediscovery runs call with the endpoints discovered last and, when it gets a 404
+// and a new discovery resolves other endpoints, e.g. after a Keycloak upgrade moved the
+// context path, runs it once more with the new ones
+func (a *KeycloakAuth) withRediscovery(ctx context.Context, call func(endpoints *KeycloakEndpoints) error) error {
+	err := call(a.discovery.Endpoints())
+
+	var statusErr interface{ HTTPStatusCode() int }
+	if !stderrors.As(err, &statusErr) || statusErr.HTTPStatusCode() != http.StatusNotFound {
+		return err
+	}
+
+	changed, discoverErr := a.discovery.Rediscover(ctx)
+	if discoverErr != nil {
+		logger.Sugar.Warnw("Failed to rediscover Keycloak endpoints",
+			"realm", a.config.KeycloakRealm,
+			"error", discoverErr,
+		)
+		return err
+	}
+	if !changed {
+		return err
+	}
+
+	a.useEndpoints(a.discovery.Endpoints())
+	return call(a.discovery.Endpoints())
+}
+
+// HealthCheck discovers the endpoints of the realm again, at most once per
+// KeycloakRediscoveryInterval, and switches to them when they changed. The token endpoint
+// is then probed with a client login, which also checks the client credentials.
+func (a *KeycloakAuth) HealthCheck(ctx context.Context) error {
+	changed, err := a.discovery.Rediscover(ctx)
+	if err != nil {
+		return errors.ExternalServiceError("Keycloak realm is unhealthy", err).
+			WithProvider(constants.AuthProviderKeycloak).
+			WithOperation("health_check").
+			WithResource("keycloak").
+			WithContext("realm", a.config.KeycloakRealm)
+	}
+	if changed {
+		a.useEndpoints(a.discovery.Endpoints())
+	}
+
+	return a.call(ctx, keycloakCall{
+		operation: "health_check_token",
+		message:   "Keycloak token endpoint is unhealthy",
+		fields:    map[string]any{"client_id": a.config.KeycloakClientID},
+	}, func(ctx context.Context) error {
+		_, err := a.gocloak().LoginClient(ctx, a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+		return err
+	})
 }
 
 // Login performs client login and returns an access token
 func (a *KeycloakAuth) ClientLogin() (*TokenInfo, error) {
-	token, err := a.client.LoginClient(context.Background(), a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+	var token *gocloak.JWT
+	err := a.call(context.Background(), keycloakCall{
+		operation: "login",
+		message:   "Failed to login to keycloak",
+		fields:    map[string]any{"client_id": a.config.KeycloakClientID},
+	}, func(ctx context.Context) error {
+		var err error
+		token, err = a.gocloak().LoginClient(ctx, a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+		return err
+	})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(context.Background()); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "login")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("client_id", a.config.KeycloakClientID)
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorf("Failed to login to keycloak: %v", err)
-		return nil, errors.ExternalServiceError("Failed to login to keycloak", err).
-			WithOperation("login").
-			WithResource("keycloak")
+		return nil, err
 	}
 	return &TokenInfo{
 		AccessToken:  token.AccessToken,
@@ -61,21 +220,17 @@ func (a *KeycloakAuth) ClientLogin() (*TokenInfo, error) {
 
 // GetUserInfo retrieves user information from Keycloak
 func (a *KeycloakAuth) GetUserInfo(token string) (*User, error) {
-	userInfo, err := a.client.GetUserInfo(context.Background(), token, a.config.KeycloakRealm)
+	var userInfo *gocloak.UserInfo
+	err := a.call(context.Background(), keycloakCall{
+		operation: "get_user_info",
+		message:   "Failed to get user info",
+	}, func(ctx context.Context) error {
+		var err error
+		userInfo, err = a.gocloak().GetUserInfo(ctx, token, a.config.KeycloakRealm)
+		return err
+	})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(context.Background()); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_user_info")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorf("Failed to get user info: %v", err)
-		return nil, errors.ExternalServiceError("Failed to get user info", err).
-			WithOperation("get_user_info").
-			WithResource("keycloak")
+		return nil, err
 	}
 
 	return &User{
@@ -89,46 +244,143 @@ func (a *KeycloakAuth) GetUserInfo(token string) (*User, error) {
 	}, nil
 }
 
-// ValidateToken validates a Keycloak token
+// ValidateToken introspects a token with Keycloak. The results are cached by the hash of
+// the token, the active ones for KEYCLOAK_INTROSPECTION_CACHE_TTL at most until the token
+// expires and the inactive ones for KEYCLOAK_INTROSPECTION_INACTIVE_TTL, so that the
+// requests of a token cost one introspection per TTL; the failures are not cached.
 func (a *KeycloakAuth) ValidateToken(token string) (*gocloak.IntroSpectTokenResult, error) {
-	result, err := a.client.RetrospectToken(context.Background(), token, a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+	ctx := context.Background()
+	if a.cache == nil {
+		return a.introspect(ctx, token)
+	}
 
-	if err != nil {
-		if hub := monitoring.GetSentryHub(context.Background()); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "validate_token")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
+	hash := sha256.Sum256([]byte(token))
+	key := constants.IntrospectionCacheKeyPrefix + hex.EncodeToString(hash[:])
+	return cache.GetOrLoadTTL(ctx, a.cache, key, func(ctx context.Context) (*gocloak.IntroSpectTokenResult, time.Duration, error) {
+		result, err := a.introspect(ctx, token)
+		if err != nil {
+			return nil, 0, err
 		}
-		logger.Sugar.Errorf("Failed to validate token: %v", err)
-		return nil, errors.ExternalServiceError("Failed to validate token", err).
-			WithOperation("validate_token").
-			WithResource("keycloak")
+		return result, a.introspectionTTL(result), nil
+	})
+}
+
+// introspectionTTL returns how long the introspection result is cached
+func (a *KeycloakAuth) introspectionTTL(result *gocloak.IntroSpectTokenResult) time.Duration {
+	if result.Active == nil || !*result.Active {
+		return a.config.KeycloakInactiveTokenTTL
+	}
+	ttl := a.config.KeycloakIntrospectionTTL
+	if result.Exp != nil {
+		ttl = min(ttl, time.Until(time.Unix(int64(*result.Exp), 0)))
+	}
+	return ttl
+}
+
+// introspect calls the introspection endpoint of the realm
+func (a *KeycloakAuth) introspect(ctx context.Context, token string) (*gocloak.IntroSpectTokenResult, error) {
+	var result *gocloak.IntroSpectTokenResult
+	err := a.call(ctx, keycloakCall{
+		operation: "validate_token",
+		message:   "Failed to validate token",
+	}, func(ctx context.Context) error {
+		var err error
+		result, err = a.gocloak().RetrospectToken(ctx, token, a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
-func (a *KeycloakAuth) DecodeAccessToken(ctx context.Context, token string, realm string, claims *TokenClaims) (*TokenClaims, error) {
-	_, err := a.client.DecodeAccessTokenCustomClaims(ctx, token, realm, claims)
+// VerifyAccessToken verifies an access token offline against the keys of the realm: its
+// signature, expiry, issuer, type and audience, then decodes its claims. An invalid token
+// fails with an unauthorized error, and the keys failing to be fetched with an external
+// service error.
+func (a *KeycloakAuth) VerifyAccessToken(ctx context.Context, token string, claims *TokenClaims) error {
+	endpoints := a.discovery.Endpoints()
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(constants.KeycloakTokenSigningMethods),
+		jwt.WithIssuer(endpoints.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(constants.KeycloakTokenLeeway),
+	)
+
+	var keysErr error
+	mapClaims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, mapClaims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := a.jwks.key(ctx, endpoints.JwksURI, kid)
+		if err != nil && !stderrors.As(err, &errUnknownKey{}) {
+			keysErr = err
+		}
+		return key, err
+	})
+	if keysErr != nil {
+		return errors.ExternalServiceError("Failed to fetch the keys of the realm", keysErr).
+			WithProvider(constants.AuthProviderKeycloak).
+			WithOperation("verify_access_token").
+			WithResource("keycloak").
+			WithContext("realm", a.config.KeycloakRealm)
+	}
+	if err == nil {
+		err = a.verifyAudience(mapClaims)
+	}
 	if err != nil {
-		// Capture invalid claims error in Sentry
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("auth_error", "invalid_claims")
-				scope.SetTag("service", "fast-ai")
-				scope.SetTag("environment", a.config.AppEnv.String())
-				scope.SetExtra("error_details", err.Error())
-				hub.CaptureException(err)
-			})
+		return errors.UnauthorizedError("Invalid access token", err).
+			WithOperation("verify_access_token").
+			WithResource("token")
+	}
+
+	data, err := json.Marshal(mapClaims)
+	if err == nil {
+		err = json.Unmarshal(data, claims)
+	}
+	if err != nil {
+		return errors.UnauthorizedError("Invalid access token claims", err).
+			WithOperation("verify_access_token").
+			WithResource("token")
+	}
+	claims.MapClaims = mapClaims
+	return nil
+}
+
+// verifyAudience checks that the claims are the ones of an access token issued to, or
+// for, one of the audiences of the config
+func (a *KeycloakAuth) verifyAudience(claims jwt.MapClaims) error {
+	if typ, ok := claims["typ"].(string); ok && typ != constants.KeycloakAccessTokenType {
+		return fmt.Errorf("token type %q is not an access token", typ)
+	}
+
+	audiences := a.config.KeycloakAudiences
+	if len(audiences) == 0 {
+		audiences = []string{a.config.KeycloakClientID}
+	}
+	tokenAudiences, err := claims.GetAudience()
+	if err != nil {
+		return err
+	}
+	authorizedParty, _ := claims["azp"].(string)
+	for _, audience := range audiences {
+		if audience == authorizedParty || slices.Contains(tokenAudiences, audience) {
+			return nil
 		}
-		logger.Sugar.Errorf("Failed to decode access token custom claims: %v", err)
-		return nil, errors.ExternalServiceError("Failed to decode access token custom claims", err).
-			WithOperation("decode_access_token_custom_claims").
-			WithResource("keycloak")
+	}
+	return fmt.Errorf("token audience %v is not accepted", []string(tokenAudiences))
+}
+
+func (a *KeycloakAuth) DecodeAccessToken(ctx context.Context, token string, realm string, claims *TokenClaims) (*TokenClaims, error) {
+	err := a.call(ctx, keycloakCall{
+		operation: "decode_access_token_custom_claims",
+		message:   "Failed to decode access token custom claims",
+	}, func(ctx context.Context) error {
+		_, err := a.gocloak().DecodeAccessTokenCustomClaims(ctx, token, realm, claims)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return claims, nil
@@ -144,60 +396,167 @@ func (a *KeycloakAuth) GetClaimsKey() string {
 
 // GetRequestingPartyToken exchanges an access token for an RPT with evaluated permissions
 func (a *KeycloakAuth) GetRequestingPartyToken(ctx context.Context, accessToken string, opts RequestingPartyTokenOptions) (*JWT, error) {
-	rpt, err := a.client.GetRequestingPartyToken(ctx, accessToken, a.config.KeycloakRealm, gocloak.RequestingPartyTokenOptions(opts))
+	var rpt *gocloak.JWT
+	err := a.call(ctx, keycloakCall{
+		operation: "get_rpt",
+		message:   "Failed to get RPT",
+	}, func(ctx context.Context) error {
+		var err error
+		rpt, err = a.gocloak().GetRequestingPartyToken(ctx, accessToken, a.config.KeycloakRealm, gocloak.RequestingPartyTokenOptions(opts))
+		return err
+	})
 	if err != nil {
-		// Capture error in Sentry
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_rpt")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorf("Failed to get RPT: %v", err)
-		return nil, errors.ExternalServiceError("Failed to get RPT", err).
-			WithOperation("get_rpt").
-			WithResource("keycloak")
+		return nil, err
+	}
+	return newJWT(rpt), nil
+}
+
+// ExchangeOrganizationToken exchanges the access token of a user for an access and a
+// refresh token requested with the organization:<alias> scope, so that their organization
+// claim only holds that organization. The client must be allowed to exchange the tokens of
+// the realm and have the organization client scope.
+func (a *KeycloakAuth) ExchangeOrganizationToken(ctx context.Context, subjectToken string, organizationAlias string) (*JWT, error) {
+	var token *gocloak.JWT
+	err := a.call(ctx, keycloakCall{
+		operation: "exchange_organization_token",
+		message:   "Failed to exchange token for the organization",
+		fields:    map[string]any{"organization_alias": organizationAlias},
+	}, func(ctx context.Context) error {
+		var err error
+		token, err = a.gocloak().GetToken(ctx, a.config.KeycloakRealm, gocloak.TokenOptions{
+			ClientID:           &a.config.KeycloakClientID,
+			ClientSecret:       &a.config.KeycloakSecret,
+			GrantType:          gocloak.StringP(constants.KeycloakTokenExchangeGrantType),
+			SubjectToken:       &subjectToken,
+			RequestedTokenType: gocloak.StringP(constants.KeycloakRefreshTokenType),
+			Scope:              gocloak.StringP("openid " + constants.KeycloakOrganizationScope + ":" + organizationAlias),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newJWT(token), nil
+}
+
+// Login logs a user in with the password grant, which needs the direct access grants of
+// the client. Invalid credentials, as well as a disabled user or one with required
+// actions, fail with an unauthorized error.
+func (a *KeycloakAuth) Login(ctx context.Context, username string, password string) (*JWT, error) {
+	return a.token(ctx, keycloakCall{
+		operation: "login_user",
+		message:   "Failed to log in",
+		rejected: func(cause error) *errors.AppError {
+			return errors.UnauthorizedError("Invalid username or password", cause)
+		},
+	}, gocloak.TokenOptions{
+		GrantType: gocloak.StringP(constants.KeycloakPasswordGrantType),
+		Username:  &username,
+		Password:  &password,
+		Scope:     gocloak.StringP("openid"),
+	})
+}
+
+// RefreshToken refreshes the tokens of a session with its refresh token. An expired or
+// revoked refresh token fails with an unauthorized error.
+func (a *KeycloakAuth) RefreshToken(ctx context.Context, refreshToken string) (*JWT, error) {
+	return a.token(ctx, keycloakCall{
+		operation: "refresh_token",
+		message:   "Failed to refresh token",
+		rejected: func(cause error) *errors.AppError {
+			return errors.UnauthorizedError("Invalid or expired refresh token", cause)
+		},
+	}, gocloak.TokenOptions{
+		GrantType:    gocloak.StringP(constants.KeycloakRefreshTokenGrantType),
+		RefreshToken: &refreshToken,
+	})
+}
+
+// ExchangeToken exchanges the access token of a user for an access token of another client
+// of the realm, the audience, restricted to scope when it is set. Keycloak refusing the
+// exchange fails with a forbidden error; the client must be allowed to exchange the tokens
+// for the audience.
+func (a *KeycloakAuth) ExchangeToken(ctx context.Context, subjectToken string, audience string, scope string) (*JWT, error) {
+	options := gocloak.TokenOptions{
+		GrantType:    gocloak.StringP(constants.KeycloakTokenExchangeGrantType),
+		SubjectToken: &subjectToken,
+		Audience:     &audience,
+	}
+	if scope != "" {
+		options.Scope = &scope
 	}
+	return a.token(ctx, keycloakCall{
+		operation: "exchange_token",
+		message:   "Failed to exchange token",
+		fields:    map[string]any{"audience": audience},
+		rejected: func(cause error) *errors.AppError {
+			return errors.ForbiddenError("Token exchange not allowed for the audience", cause)
+		},
+	}, options)
+}
+
+// token requests tokens from the token endpoint of the realm as the client
+func (a *KeycloakAuth) token(ctx context.Context, call keycloakCall, options gocloak.TokenOptions) (*JWT, error) {
+	options.ClientID = &a.config.KeycloakClientID
+	options.ClientSecret = &a.config.KeycloakSecret
+
+	var token *gocloak.JWT
+	err := a.call(ctx, call, func(ctx context.Context) error {
+		var err error
+		token, err = a.gocloak().GetToken(ctx, a.config.KeycloakRealm, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newJWT(token), nil
+}
+
+// newJWT converts the tokens returned by gocloak
+func newJWT(token *gocloak.JWT) *JWT {
 	return &JWT{
-		AccessToken:      rpt.AccessToken,
-		IDToken:          rpt.IDToken,
-		ExpiresIn:        rpt.ExpiresIn,
-		RefreshExpiresIn: rpt.RefreshExpiresIn,
-		RefreshToken:     rpt.RefreshToken,
-		TokenType:        rpt.TokenType,
-		NotBeforePolicy:  rpt.NotBeforePolicy,
-		SessionState:     rpt.SessionState,
-		Scope:            rpt.Scope,
-	}, nil
+		AccessToken:      token.AccessToken,
+		IDToken:          token.IDToken,
+		ExpiresIn:        token.ExpiresIn,
+		RefreshExpiresIn: token.RefreshExpiresIn,
+		RefreshToken:     token.RefreshToken,
+		TokenType:        token.TokenType,
+		NotBeforePolicy:  token.NotBeforePolicy,
+		SessionState:     token.SessionState,
+		Scope:            token.Scope,
+	}
 }
 
 func (a *KeycloakAuth) CreateUser(ctx context.Context, adminToken string, userDto *dtos.CreateUserRequest) (*User, error) {
 	emailVerified := true
 	enabled := true
-	userID, err := a.client.CreateUser(ctx, adminToken, a.config.KeycloakRealm, gocloak.User{
+	user := gocloak.User{
 		Email:           &userDto.Email,
 		Username:        &userDto.Email,
 		EmailVerified:   &emailVerified,
 		Enabled:         &enabled,
 		RequiredActions: &[]string{"VERIFY_EMAIL", "UPDATE_PASSWORD"},
-	})
+	}
+	if userDto.FirstName != "" {
+		user.FirstName = &userDto.FirstName
+	}
+	if userDto.LastName != "" {
+		user.LastName = &userDto.LastName
+	}
 
+	var userID string
+	err := a.admin(ctx, keycloakCall{
+		operation: "create_user",
+		message:   "Failed to create user",
+		conflict:  "A user with this email already exists in Keycloak",
+		create:    true,
+	}, adminToken, func(ctx context.Context, token string) error {
+		var err error
+		userID, err = a.gocloak().CreateUser(ctx, token, a.config.KeycloakRealm, user)
+		return err
+	})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "create_user")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		return nil, errors.ExternalServiceError("Failed to create user", err).
-			WithOperation("create_user").
-			WithResource("keycloak")
+		return nil, err
 	}
 
 	return &User{
@@ -205,180 +564,94 @@ func (a *KeycloakAuth) CreateUser(ctx context.Context, adminToken string, userDt
 	}, nil
 }
 
-func (a *KeycloakAuth) getClients(ctx context.Context, adminToken string) ([]*gocloak.Client, error) {
-	kcClients, err := a.client.GetClients(ctx, adminToken, a.config.KeycloakRealm, gocloak.GetClientsParams{ClientID: gocloak.StringP(a.config.KeycloakClientID)})
+// FindUserByEmail returns the user of the realm with the email, nil when there is none
+func (a *KeycloakAuth) FindUserByEmail(ctx context.Context, adminToken string, email string) (*User, error) {
+	exact := true
+	var users []*gocloak.User
+	err := a.admin(ctx, keycloakCall{
+		operation: "find_user_by_email",
+		message:   "Failed to find user",
+	}, adminToken, func(ctx context.Context, token string) error {
+		var err error
+		users, err = a.gocloak().GetUsers(ctx, token, a.config.KeycloakRealm, gocloak.GetUsersParams{
+			Email: &email,
+			Exact: &exact,
+		})
+		return err
+	})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_clients")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorw("Failed to get clients",
-			"realm", a.config.KeycloakRealm,
-			"client_id", a.config.KeycloakClientID,
-			"error", err,
-		)
-		return nil, errors.ExternalServiceError("Failed to get clients", err).
-			WithOperation("get_clients").
-			WithResource("keycloak")
+		return nil, err
 	}
-	return kcClients, nil
-}
 
-func (a *KeycloakAuth) getClientRole(ctx context.Context, adminToken string, clientID string, roleName string) (*gocloak.Role, error) {
-	kcRole, err := a.client.GetClientRole(ctx, adminToken, a.config.KeycloakRealm, clientID, roleName)
-	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_client_role")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
+	for _, user := range users {
+		if user.ID == nil || user.Email == nil || !strings.EqualFold(*user.Email, email) {
+			continue
 		}
-		logger.Sugar.Errorw("Failed to get client role",
-			"client_id", clientID,
-			"role", roleName,
-			"realm", a.config.KeycloakRealm,
-			"error", err,
-		)
-
-		return nil, errors.ExternalServiceError("Failed to get client role", err).
-			WithOperation("get_client_role").
-			WithResource("keycloak")
+		return &User{
+			ID:                *user.ID,
+			Sub:               *user.ID,
+			Email:             *user.Email,
+			PreferredUsername: gocloak.PString(user.Username),
+			GivenName:         gocloak.PString(user.FirstName),
+			FamilyName:        gocloak.PString(user.LastName),
+			EmailVerified:     gocloak.PBool(user.EmailVerified),
+			Enabled:           user.Enabled,
+		}, nil
 	}
-
-	return kcRole, nil
+	return nil, nil
 }
 
-func (a *KeycloakAuth) AddClientRolesToUser(ctx context.Context, adminToken string, userID string, clientID string, role string) error {
-	kcClients, err := a.getClients(ctx, adminToken)
+// realmClient returns the client of the server in the realm
+func (a *KeycloakAuth) realmClient(ctx context.Context, token string) (*gocloak.Client, error) {
+	kcClients, err := a.gocloak().GetClients(ctx, token, a.config.KeycloakRealm, gocloak.GetClientsParams{ClientID: gocloak.StringP(a.config.KeycloakClientID)})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_clients")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				scope.SetExtra("client_id", clientID)
-				scope.SetExtra("user_id", userID)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorw("Failed to get clients",
-			"realm", a.config.KeycloakRealm,
-			"client_id", clientID,
-			"user_id", userID,
-			"error", err,
-		)
-
-		return errors.ExternalServiceError("Failed to get client", err).
-			WithOperation("get_client").
-			WithResource("keycloak").
-			WithContext("client_id", clientID)
+		return nil, err
 	}
-
 	if len(kcClients) == 0 {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "get_client")
-				scope.SetExtra("error_details", "Client not found")
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				scope.SetExtra("client_id", clientID)
-				scope.SetExtra("user_id", userID)
-				hub.CaptureException(errors.NotFoundError("Client not found", nil))
-			})
-		}
-		return errors.ExternalServiceError("Client not found", nil).
+		return nil, errors.ExternalServiceError("Client not found", nil).
 			WithOperation("get_client").
 			WithResource("keycloak").
-			WithContext("client_id", clientID)
-	}
-	clientID = *kcClients[0].ID
-
-	kcRole, err := a.getClientRole(ctx, adminToken, clientID, role)
-	if err != nil {
-		return errors.ExternalServiceError("Failed to get client role", err).
-			WithOperation("get_client_role").
-			WithResource("keycloak").
-			WithContext("client_id", clientID).
-			WithContext("role", role)
+			WithContext("client_id", a.config.KeycloakClientID)
 	}
+	return kcClients[0], nil
+}
 
-	err = a.client.AddClientRolesToUser(ctx, adminToken, a.config.KeycloakRealm, clientID, userID, []gocloak.Role{*kcRole})
-	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "add_client_roles_to_user")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				scope.SetExtra("user_id", userID)
-				scope.SetExtra("client_id", clientID)
-				scope.SetExtra("role", *kcRole.ID)
-				hub.CaptureException(err)
-			})
+func (a *KeycloakAuth) AddClientRolesToUser(ctx context.Context, adminToken string, userID string, clientID string, role string) error {
+	return a.admin(ctx, keycloakCall{
+		operation: "add_client_roles_to_user",
+		message:   "Failed to add client roles to user",
+		fields:    map[string]any{"user_id": userID, "client_id": clientID, "role": role},
+	}, adminToken, func(ctx context.Context, token string) error {
+		kcClient, err := a.realmClient(ctx, token)
+		if err != nil {
+			return err
 		}
-		logger.Sugar.Errorw("Failed to add client roles to user",
-			"user_id", userID,
-			"client_id", clientID,
-			"role", *kcRole.ID,
-			"realm", a.config.KeycloakRealm,
-			"error", err,
-		)
-
-		return errors.ExternalServiceError("Failed to add client roles to user", err).
-			WithOperation("add_client_roles_to_user").
-			WithResource("keycloak").
-			WithContext("client_id", clientID).
-			WithContext("role", role)
-	}
-	return nil
+		kcRole, err := a.gocloak().GetClientRole(ctx, token, a.config.KeycloakRealm, *kcClient.ID, role)
+		if err != nil {
+			return err
+		}
+		return a.gocloak().AddClientRolesToUser(ctx, token, a.config.KeycloakRealm, *kcClient.ID, userID, []gocloak.Role{*kcRole})
+	})
 }
 
 func (a *KeycloakAuth) SetPassword(ctx context.Context, adminToken string, userID string, password string, temporary bool) error {
-	err := a.client.SetPassword(ctx, adminToken, userID, a.config.KeycloakRealm, password, temporary)
-	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "set_password")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		return errors.ExternalServiceError("Failed to set password", err).
-			WithOperation("set_password").
-			WithResource("keycloak")
-	}
-	return nil
+	return a.admin(ctx, keycloakCall{
+		operation: "set_password",
+		message:   "Failed to set password",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.gocloak().SetPassword(ctx, token, userID, a.config.KeycloakRealm, password, temporary)
+	})
 }
 
 func (a *KeycloakAuth) SendVerificationMail(ctx context.Context, adminToken string, userID string, params SendVerificationMailParams) error {
-	err := a.client.SendVerifyEmail(ctx, adminToken, userID, a.config.KeycloakRealm, gocloak.SendVerificationMailParams(params))
-	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "send_verification_email")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorf("Failed to send verification email: %v", err)
-		return errors.ExternalServiceError("Failed to send verification email", err).
-			WithOperation("send_verification_email").
-			WithResource("keycloak")
-	}
-	return nil
+	return a.admin(ctx, keycloakCall{
+		operation: "send_verification_email",
+		message:   "Failed to send verification email",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.gocloak().SendVerifyEmail(ctx, token, userID, a.config.KeycloakRealm, gocloak.SendVerificationMailParams(params))
+	})
 }
 
 func (a *KeycloakAuth) GetClientID() string {
@@ -445,44 +718,77 @@ func (a *KeycloakAuth) getHeaders(token string) map[string]string {
 }
 
 func (a *KeycloakAuth) AddUserToOrganization(ctx context.Context, adminToken string, userID string, organizationID string) error {
-	// Use the correct Keycloak Organizations API endpoint
-	url := fmt.Sprintf("%s/admin/realms/%s/organizations/%s/members",
-		a.config.KeycloakURL, a.config.KeycloakRealm, organizationID)
+	err := a.admin(ctx, keycloakCall{
+		operation: "add_user_to_organization",
+		message:   "Failed to add user to organization",
+		fields:    map[string]any{"user_id": userID, "organization_id": organizationID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			url := organizationMembersURL(endpoints, organizationID)
+
+			// The body is just the user ID, as a plain string
+			var response map[string]interface{}
+			var errorResponse map[string]interface{}
+			resp, err := a.restClient.Post(url, userID, &response, &errorResponse, a.getHeaders(token))
+			if err != nil {
+				return err
+			}
+			// Keycloak answers 409 when the user is already a member
+			if resp.IsError() && resp.StatusCode() != http.StatusConflict {
+				return &httpclient.StatusError{
+					Method:     http.MethodPost,
+					Endpoint:   url,
+					StatusCode: resp.StatusCode(),
+					Body:       resp.String(),
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
 
-	// Request body for inviting existing user to organization
-	// The body should be just the user ID as a plain string
-	requestBody := userID
+	logger.Sugar.Infow("Successfully added user to organization via Keycloak API",
+		"user_id", userID,
+		"organization_id", organizationID,
+	)
 
-	var response map[string]interface{}
-	var errorResponse map[string]interface{}
+	return nil
+}
 
-	_, err := a.restClient.Post(url, requestBody, &response, &errorResponse, a.getHeaders(adminToken))
+// RemoveUserFromOrganization ends the membership of the user in the organization; removing
+// a user who is not a member is a no-op
+func (a *KeycloakAuth) RemoveUserFromOrganization(ctx context.Context, adminToken string, userID string, organizationID string) error {
+	err := a.admin(ctx, keycloakCall{
+		operation: "remove_user_from_organization",
+		message:   "Failed to remove user from organization",
+		fields:    map[string]any{"user_id": userID, "organization_id": organizationID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			endpoint := fmt.Sprintf("%s/%s", organizationMembersURL(endpoints, organizationID), url.PathEscape(userID))
+
+			var errorResponse map[string]interface{}
+			resp, err := a.restClient.Delete(endpoint, &errorResponse, a.getHeaders(token))
+			if err != nil {
+				return err
+			}
+			if resp.IsError() && resp.StatusCode() != http.StatusNotFound {
+				return &httpclient.StatusError{
+					Method:     http.MethodDelete,
+					Endpoint:   endpoint,
+					StatusCode: resp.StatusCode(),
+					Body:       resp.String(),
+				}
+			}
+			return nil
+		})
+	})
 	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "keycloak_adapter")
-				scope.SetTag("operation", "add_user_to_organization")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("user_id", userID)
-				scope.SetExtra("organization_id", organizationID)
-				hub.CaptureException(err)
-			})
-		}
-		logger.Sugar.Errorw("Failed to add user to organization via Keycloak API",
-			"error", err.Error(),
-			"user_id", userID,
-			"organization_id", organizationID,
-			"url", url,
-		)
-
-		return errors.ExternalServiceError("Failed to add user to organization", err).
-			WithOperation("add_user_to_organization").
-			WithResource("keycloak").
-			WithContext("user_id", userID).
-			WithContext("organization_id", organizationID)
+		return err
 	}
 
-	logger.Sugar.Infow("Successfully added user to organization via Keycloak API",
+	logger.Sugar.Infow("Successfully removed user from organization via Keycloak API",
 		"user_id", userID,
 		"organization_id", organizationID,
 	)
@@ -490,37 +796,89 @@ func (a *KeycloakAuth) AddUserToOrganization(ctx context.Context, adminToken str
 	return nil
 }
 
+// ListOrganizationMembers returns every member of the organization, walking the admin API
+// pages with the first and max parameters
+func (a *KeycloakAuth) ListOrganizationMembers(ctx context.Context, adminToken string, organizationID string) ([]User, error) {
+	var members []keycloakMember
+	err := a.admin(ctx, keycloakCall{
+		operation: "list_organization_members",
+		message:   "Failed to list organization members",
+		fields:    map[string]any{"organization_id": organizationID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			pages := httpclient.NewPageIterator(a.restClient, httpclient.PageIteratorConfig[keycloakMember]{
+				Endpoint:   organizationMembersURL(endpoints, organizationID),
+				Headers:    a.getHeaders(token),
+				Pagination: httpclient.OffsetPagination{OffsetParam: "first", LimitParam: "max"},
+				Limiter:    a.adminLimiter,
+			})
+
+			var err error
+			members, err = pages.Collect(ctx)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, len(members))
+	for i, member := range members {
+		users[i] = User{
+			ID:                member.ID,
+			Sub:               member.ID,
+			PreferredUsername: member.Username,
+			GivenName:         member.FirstName,
+			FamilyName:        member.LastName,
+			Name:              strings.TrimSpace(member.FirstName + " " + member.LastName),
+			Email:             member.Email,
+			EmailVerified:     member.EmailVerified,
+			Enabled:           member.Enabled,
+		}
+	}
+
+	return users, nil
+}
+
+// UpdateUser enables or disables the user from the status of the request, and replaces the
+// names and email it sets; Keycloak keeps the attributes left out of the representation
 func (a *KeycloakAuth) UpdateUser(ctx context.Context, adminToken string, userID string, userDto *dtos.UpdateUserRequest) error {
 	enabled := userDto.Status != constants.UserStatusInactive
-
-	err := a.client.UpdateUser(ctx, adminToken, a.config.KeycloakRealm, gocloak.User{
+	user := gocloak.User{
 		ID:      &userID,
 		Enabled: &enabled,
+	}
+	if userDto.FirstName != "" {
+		user.FirstName = &userDto.FirstName
+	}
+	if userDto.LastName != "" {
+		user.LastName = &userDto.LastName
+	}
+	if userDto.Email != "" {
+		user.Email = &userDto.Email
+	}
+
+	return a.admin(ctx, keycloakCall{
+		operation: "update_user",
+		message:   "Failed to update user",
+		conflict:  "A user with this email already exists in Keycloak",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.gocloak().UpdateUser(ctx, token, a.config.KeycloakRealm, user)
 	})
+}
 
-	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("adapter", "keycloak")
-				scope.SetTag("operation", "update_user")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("realm", a.config.KeycloakRealm)
-				scope.SetExtra("user_id", userID)
-				scope.SetExtra("body_request", userDto)
-				hub.CaptureException(err)
-			})
+// DeleteUser deletes the user from the realm; deleting a user that does not exist is a no-op
+func (a *KeycloakAuth) DeleteUser(ctx context.Context, adminToken string, userID string) error {
+	return a.admin(ctx, keycloakCall{
+		operation: "delete_user",
+		message:   "Failed to delete user",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		err := a.gocloak().DeleteUser(ctx, token, a.config.KeycloakRealm, userID)
+		if responseStatus(err) == http.StatusNotFound {
+			return nil
 		}
-		logger.Sugar.Errorw("Failed to update user",
-			"user_id", userID,
-			"body_request", userDto,
-			"realm", a.config.KeycloakRealm,
-			"error", err,
-		)
-
-		return errors.ExternalServiceError("Failed to update user", err).
-			WithOperation("update_user").
-			WithResource("keycloak").
-			WithContext("user_id", userID)
-	}
-	return nil
+		return err
+	})
 }
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// keycloakCall describes a call to Keycloak run by the executor
+type keycloakCall struct {
+	// operation names the call in the logs, the metrics and the errors, e.g. create_user
+	operation string
+	// message is the message of the error returned when the call fails
+	message string
+	// conflict, when set, is the message of the conflict error returned on a 409
+	conflict string
+	// create marks calls creating a resource. A timeout or a 5xx may come after Keycloak
+	// created it, so they are only retried when Keycloak throttled the call.
+	create bool
+	// rejected, when set, builds the error returned when Keycloak rejects the grant of a
+	// token request with a 400, 401 or 403, such as invalid credentials or an expired refresh
+	// token. Rejections are failures of the client: they are not reported to Sentry.
+	rejected func(cause error) *errors.AppError
+	// fields are added to the logs, the Sentry scope and the context of the error
+	fields map[string]any
+}
+
+// call runs fn, a call to Keycloak, and retries it with backoff while it fails
+// transiently: 5xx, 429, timeouts and connection failures, up to KEYCLOAK_ADMIN_RETRY_ATTEMPTS
+// attempts. A failure is logged, reported to Sentry and returned as an AppError classified
+// from the Keycloak response; fn returns an AppError as is to fail with its own. Each call is
+// timed as Custom/Keycloak/<operation>/Duration, failures, rejections and retries are
+// counted as Custom/Keycloak/<operation>/Failure, /Rejected and /Retry.
+func (a *KeycloakAuth) call(ctx context.Context, call keycloakCall, fn func(ctx context.Context) error) error {
+	startedAt := time.Now()
+	backoff := retry.Backoff{
+		InitialInterval: a.config.KeycloakAdminRetryDelay,
+		Jitter:          retry.DefaultJitter,
+		MaxAttempts:     max(a.config.KeycloakAdminAttempts, 1),
+	}
+
+	err := retry.Do(ctx, "keycloak_"+call.operation, backoff, func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			a.nrApp.RecordCustomMetric("Custom/Keycloak/"+call.operation+"/Retry", 1)
+		}
+		err := fn(ctx)
+		if err != nil && !a.retryable(call, err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+
+	a.nrApp.RecordCustomMetric("Custom/Keycloak/"+call.operation+"/Duration", time.Since(startedAt).Seconds())
+	if err == nil {
+		return nil
+	}
+	if call.rejected != nil && !errors.IsAppError(err) {
+		switch responseStatus(err) {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+			a.nrApp.RecordCustomMetric("Custom/Keycloak/"+call.operation+"/Rejected", 1)
+			return a.withCall(call, call.rejected(err))
+		}
+	}
+
+	a.nrApp.RecordCustomMetric("Custom/Keycloak/"+call.operation+"/Failure", 1)
+	a.logFailure(call, err)
+	return a.callError(call, err)
+}
+
+// admin runs fn, a call to the admin API, with the admin token through call. Admin tokens
+// are short-lived; when Keycloak answers 401 the token expired or was revoked, and fn runs
+// once more with the token of a new client login, counted as
+// Custom/Keycloak/<operation>/TokenRefresh.
+func (a *KeycloakAuth) admin(ctx context.Context, call keycloakCall, adminToken string, fn func(ctx context.Context, token string) error) error {
+	token := adminToken
+	refreshed := false
+
+	return a.call(ctx, call, func(ctx context.Context) error {
+		err := fn(ctx, token)
+		if refreshed || responseStatus(err) != http.StatusUnauthorized {
+			return err
+		}
+
+		login, loginErr := a.gocloak().LoginClient(ctx, a.config.KeycloakClientID, a.config.KeycloakSecret, a.config.KeycloakRealm)
+		if loginErr != nil {
+			return stderrors.Join(err, loginErr)
+		}
+		token, refreshed = login.AccessToken, true
+		a.nrApp.RecordCustomMetric("Custom/Keycloak/"+call.operation+"/TokenRefresh", 1)
+		logger.Sugar.Infow("Refreshed the Keycloak admin token",
+			"operation", call.operation,
+			"realm", a.config.KeycloakRealm,
+		)
+
+		return fn(ctx, token)
+	})
+}
+
+// retryable reports whether a failed attempt of call may succeed when attempted again
+func (a *KeycloakAuth) retryable(call keycloakCall, err error) bool {
+	if errors.IsAppError(err) {
+		return false
+	}
+
+	appErr := errors.ExternalServiceError(call.message, err).WithProvider(constants.AuthProviderKeycloak)
+	if !appErr.Retryable {
+		return false
+	}
+	return !call.create || appErr.UpstreamStatus == http.StatusTooManyRequests
+}
+
+// callError maps the failure of call to the AppError returned to the services
+func (a *KeycloakAuth) callError(call keycloakCall, err error) error {
+	if errors.IsAppError(err) {
+		return err
+	}
+
+	var appErr *errors.AppError
+	if call.conflict != "" && responseStatus(err) == http.StatusConflict {
+		appErr = errors.ConflictError(call.conflict, err)
+	} else {
+		appErr = errors.ExternalServiceError(call.message, err).WithProvider(constants.AuthProviderKeycloak)
+	}
+	return a.withCall(call, appErr)
+}
+
+// withCall adds the operation and the fields of call to appErr
+func (a *KeycloakAuth) withCall(call keycloakCall, appErr *errors.AppError) *errors.AppError {
+	appErr = appErr.
+		WithOperation(call.operation).
+		WithResource("keycloak")
+	for key, value := range call.fields {
+		appErr = appErr.WithContext(key, value)
+	}
+	return appErr
+}
+
+// logFailure logs the failure of call; the error returned for it is reported to Sentry
+// by the error handler
+func (a *KeycloakAuth) logFailure(call keycloakCall, err error) {
+	fields := make([]any, 0, 2*len(call.fields)+6)
+	fields = append(fields, "operation", call.operation, "realm", a.config.KeycloakRealm, "error", err)
+	for key, value := range call.fields {
+		fields = append(fields, key, value)
+	}
+	logger.Sugar.Errorw(call.message, fields...)
+}
+
+// responseStatus returns the HTTP status of the Keycloak response err failed with, 0 when
+// there was none
+func responseStatus(err error) int {
+	var apiErr *gocloak.APIError
+	if stderrors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if stderrors.As(err, &statusErr) {
+		return statusErr.HTTPStatusCode()
+	}
+	return 0
+}
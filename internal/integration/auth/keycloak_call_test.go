@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/httpclient"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKeycloakAuth creates a KeycloakAuth whose gocloak client logs in against a token
+// endpoint issuing the fresh token
+func newTestKeycloakAuth(t *testing.T) *KeycloakAuth {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/realms/test/protocol/openid-connect/token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "fresh", "expires_in": 60})
+	}))
+	t.Cleanup(server.Close)
+
+	return &KeycloakAuth{
+		config: &config.Config{
+			KeycloakRealm:           "test",
+			KeycloakClientID:        "server",
+			KeycloakAdminAttempts:   3,
+			KeycloakAdminRetryDelay: time.Millisecond,
+		},
+		client: gocloak.NewClient(server.URL),
+	}
+}
+
+// failing returns a call failing with the errors in order, then succeeding, and the number
+// of attempts it got
+func failing(errs ...error) (func(ctx context.Context) error, *int) {
+	attempts := 0
+	return func(context.Context) error {
+		attempts++
+		if attempts <= len(errs) {
+			return errs[attempts-1]
+		}
+		return nil
+	}, &attempts
+}
+
+func statusError(status int) error {
+	return &httpclient.StatusError{Endpoint: "http://keycloak/admin", StatusCode: status}
+}
+
+func TestKeycloakAuth_Call(t *testing.T) {
+	tests := []struct {
+		name             string
+		call             keycloakCall
+		errs             []error
+		expectedAttempts int
+		expectedType     errors.ErrorType
+	}{
+		{
+			name:             "retries a transient failure",
+			call:             keycloakCall{operation: "update_user", message: "Failed to update user"},
+			errs:             []error{statusError(http.StatusServiceUnavailable), &gocloak.APIError{Code: http.StatusBadGateway}},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "gives up after the attempts",
+			call:             keycloakCall{operation: "update_user", message: "Failed to update user"},
+			errs:             []error{statusError(500), statusError(500), statusError(500)},
+			expectedAttempts: 3,
+			expectedType:     errors.ErrorTypeExternal,
+		},
+		{
+			name:             "does not retry a rejection",
+			call:             keycloakCall{operation: "update_user", message: "Failed to update user"},
+			errs:             []error{&gocloak.APIError{Code: http.StatusBadRequest}},
+			expectedAttempts: 1,
+			expectedType:     errors.ErrorTypeExternal,
+		},
+		{
+			name:             "does not retry a create after a 5xx",
+			call:             keycloakCall{operation: "create_user", message: "Failed to create user", create: true},
+			errs:             []error{statusError(http.StatusServiceUnavailable)},
+			expectedAttempts: 1,
+			expectedType:     errors.ErrorTypeExternal,
+		},
+		{
+			name:             "retries a throttled create",
+			call:             keycloakCall{operation: "create_user", message: "Failed to create user", create: true},
+			errs:             []error{statusError(http.StatusTooManyRequests)},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "maps a conflict",
+			call:             keycloakCall{operation: "create_user", message: "Failed to create user", conflict: "User exists", create: true},
+			errs:             []error{&gocloak.APIError{Code: http.StatusConflict}},
+			expectedAttempts: 1,
+			expectedType:     errors.ErrorTypeConflict,
+		},
+		{
+			name: "maps a rejected grant",
+			call: keycloakCall{operation: "login_user", message: "Failed to log in", rejected: func(cause error) *errors.AppError {
+				return errors.UnauthorizedError("Invalid username or password", cause)
+			}},
+			errs:             []error{&gocloak.APIError{Code: http.StatusUnauthorized}},
+			expectedAttempts: 1,
+			expectedType:     errors.ErrorTypeUnauthorized,
+		},
+		{
+			name: "does not map the failures of Keycloak as rejections",
+			call: keycloakCall{operation: "login_user", message: "Failed to log in", rejected: func(cause error) *errors.AppError {
+				return errors.UnauthorizedError("Invalid username or password", cause)
+			}},
+			errs:             []error{statusError(500), statusError(500), statusError(500)},
+			expectedAttempts: 3,
+			expectedType:     errors.ErrorTypeExternal,
+		},
+		{
+			name:             "returns the app errors of the call as is",
+			call:             keycloakCall{operation: "add_client_roles_to_user", message: "Failed to add client roles to user"},
+			errs:             []error{errors.NotFoundError("Client", nil)},
+			expectedAttempts: 1,
+			expectedType:     errors.ErrorTypeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestKeycloakAuth(t)
+			fn, attempts := failing(tt.errs...)
+
+			err := a.call(context.Background(), tt.call, fn)
+
+			assert.Equal(t, tt.expectedAttempts, *attempts)
+			if tt.expectedType == "" {
+				require.NoError(t, err)
+				return
+			}
+			appErr := errors.GetAppError(err)
+			require.NotNil(t, appErr)
+			assert.Equal(t, tt.expectedType, appErr.Type)
+			if tt.expectedType != errors.ErrorTypeNotFound {
+				assert.Equal(t, tt.call.operation, appErr.Operation)
+				assert.Equal(t, "keycloak", appErr.Resource)
+			}
+		})
+	}
+}
+
+func TestKeycloakAuth_Admin_RefreshesExpiredToken(t *testing.T) {
+	a := newTestKeycloakAuth(t)
+	var tokens []string
+
+	err := a.admin(context.Background(), keycloakCall{operation: "update_user", message: "Failed to update user"}, "expired",
+		func(ctx context.Context, token string) error {
+			tokens = append(tokens, token)
+			if token != "fresh" {
+				return &gocloak.APIError{Code: http.StatusUnauthorized}
+			}
+			return nil
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"expired", "fresh"}, tokens)
+}
+
+func TestKeycloakAuth_Admin_RefreshesOnce(t *testing.T) {
+	a := newTestKeycloakAuth(t)
+	var tokens []string
+
+	err := a.admin(context.Background(), keycloakCall{operation: "update_user", message: "Failed to update user"}, "expired",
+		func(ctx context.Context, token string) error {
+			tokens = append(tokens, token)
+			return statusError(http.StatusUnauthorized)
+		})
+
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusUnauthorized, appErr.UpstreamStatus)
+	assert.Equal(t, []string{"expired", "fresh"}, tokens)
+}
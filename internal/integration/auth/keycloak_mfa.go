@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// GetMFAStatus reads the OTP credentials and the required actions of the user
+func (a *KeycloakAuth) GetMFAStatus(ctx context.Context, adminToken string, userID string) (*MFAStatus, error) {
+	status := &MFAStatus{}
+	err := a.admin(ctx, keycloakCall{
+		operation: "get_mfa_status",
+		message:   "Failed to get MFA status",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		user, err := a.gocloak().GetUserByID(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+		credentials, err := a.gocloak().GetCredentials(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+
+		status.Required = user.RequiredActions != nil && slices.Contains(*user.RequiredActions, constants.KeycloakConfigureOTPAction)
+		status.Configured = len(otpCredentialIDs(credentials)) > 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// RequireOTP adds the configure OTP required action to the user, keeping their other
+// required actions
+func (a *KeycloakAuth) RequireOTP(ctx context.Context, adminToken string, userID string) error {
+	return a.admin(ctx, keycloakCall{
+		operation: "require_otp",
+		message:   "Failed to require OTP",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		user, err := a.gocloak().GetUserByID(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+		actions := []string{}
+		if user.RequiredActions != nil {
+			actions = *user.RequiredActions
+		}
+		if slices.Contains(actions, constants.KeycloakConfigureOTPAction) {
+			return nil
+		}
+
+		actions = append(actions, constants.KeycloakConfigureOTPAction)
+		return a.gocloak().UpdateUser(ctx, token, a.config.KeycloakRealm, gocloak.User{
+			ID:              &userID,
+			RequiredActions: &actions,
+		})
+	})
+}
+
+// SendOTPEnrollmentEmail sends the execute actions email of Keycloak with the configure
+// OTP action, whose link is valid for the action token lifespan of the realm
+func (a *KeycloakAuth) SendOTPEnrollmentEmail(ctx context.Context, adminToken string, userID string) error {
+	params := gocloak.ExecuteActionsEmail{
+		UserID:   &userID,
+		ClientID: &a.config.KeycloakClientID,
+		Actions:  &[]string{constants.KeycloakConfigureOTPAction},
+	}
+	if a.config.KeycloakRedirectURI != "" {
+		params.RedirectURI = &a.config.KeycloakRedirectURI
+	}
+
+	return a.admin(ctx, keycloakCall{
+		operation: "send_otp_enrollment_email",
+		message:   "Failed to send OTP enrollment email",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.gocloak().ExecuteActionsEmail(ctx, token, a.config.KeycloakRealm, params)
+	})
+}
+
+// ResetOTP deletes the OTP credentials of the user and removes the configure OTP required
+// action, e.g. after the loss of their device
+func (a *KeycloakAuth) ResetOTP(ctx context.Context, adminToken string, userID string) error {
+	return a.admin(ctx, keycloakCall{
+		operation: "reset_otp",
+		message:   "Failed to reset OTP",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		credentials, err := a.gocloak().GetCredentials(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+		for _, credentialID := range otpCredentialIDs(credentials) {
+			if err := a.gocloak().DeleteCredentials(ctx, token, a.config.KeycloakRealm, userID, credentialID); err != nil {
+				return err
+			}
+		}
+
+		user, err := a.gocloak().GetUserByID(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+		if user.RequiredActions == nil || !slices.Contains(*user.RequiredActions, constants.KeycloakConfigureOTPAction) {
+			return nil
+		}
+		actions := slices.DeleteFunc(slices.Clone(*user.RequiredActions), func(action string) bool {
+			return action == constants.KeycloakConfigureOTPAction
+		})
+		return a.gocloak().UpdateUser(ctx, token, a.config.KeycloakRealm, gocloak.User{
+			ID:              &userID,
+			RequiredActions: &actions,
+		})
+	})
+}
+
+// otpCredentialIDs returns the IDs of the OTP credentials among those of a user
+func otpCredentialIDs(credentials []*gocloak.CredentialRepresentation) []string {
+	var ids []string
+	for _, credential := range credentials {
+		if credential.Type != nil && *credential.Type == constants.KeycloakOTPCredentialType && credential.ID != nil {
+			ids = append(ids, *credential.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeycloakUser serves the user, credentials and required actions of one user of the
+// admin API
+type fakeKeycloakUser struct {
+	mu              sync.Mutex
+	requiredActions []string
+	credentials     []map[string]string
+}
+
+func (u *fakeKeycloakUser) serve(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	const userPath = "/admin/realms/test/users/kc-1"
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == userPath:
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "kc-1", "requiredActions": u.requiredActions})
+	case r.Method == http.MethodPut && r.URL.Path == userPath:
+		var user gocloak.User
+		_ = json.NewDecoder(r.Body).Decode(&user)
+		if user.RequiredActions != nil {
+			u.requiredActions = *user.RequiredActions
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == userPath+"/credentials":
+		_ = json.NewEncoder(w).Encode(u.credentials)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, userPath+"/credentials/"):
+		id := strings.TrimPrefix(r.URL.Path, userPath+"/credentials/")
+		kept := u.credentials[:0]
+		for _, credential := range u.credentials {
+			if credential["id"] != id {
+				kept = append(kept, credential)
+			}
+		}
+		u.credentials = kept
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newMFAKeycloakAuth(t *testing.T, user *fakeKeycloakUser) *KeycloakAuth {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(user.serve))
+	t.Cleanup(server.Close)
+
+	return &KeycloakAuth{
+		config: &config.Config{
+			KeycloakRealm:           "test",
+			KeycloakClientID:        "server",
+			KeycloakAdminAttempts:   1,
+			KeycloakAdminRetryDelay: time.Millisecond,
+		},
+		client: gocloak.NewClient(server.URL),
+	}
+}
+
+func TestKeycloakAuth_RequireOTP(t *testing.T) {
+	user := &fakeKeycloakUser{requiredActions: []string{"VERIFY_EMAIL"}}
+	a := newMFAKeycloakAuth(t, user)
+
+	require.NoError(t, a.RequireOTP(context.Background(), "admin-token", "kc-1"))
+	// Requiring it again keeps a single action
+	require.NoError(t, a.RequireOTP(context.Background(), "admin-token", "kc-1"))
+
+	assert.Equal(t, []string{"VERIFY_EMAIL", constants.KeycloakConfigureOTPAction}, user.requiredActions)
+	status, err := a.GetMFAStatus(context.Background(), "admin-token", "kc-1")
+	require.NoError(t, err)
+	assert.Equal(t, &MFAStatus{Required: true}, status)
+}
+
+func TestKeycloakAuth_ResetOTP(t *testing.T) {
+	user := &fakeKeycloakUser{
+		requiredActions: []string{constants.KeycloakConfigureOTPAction, "UPDATE_PASSWORD"},
+		credentials: []map[string]string{
+			{"id": "password-1", "type": "password"},
+			{"id": "otp-1", "type": constants.KeycloakOTPCredentialType},
+			{"id": "otp-2", "type": constants.KeycloakOTPCredentialType},
+		},
+	}
+	a := newMFAKeycloakAuth(t, user)
+
+	status, err := a.GetMFAStatus(context.Background(), "admin-token", "kc-1")
+	require.NoError(t, err)
+	assert.Equal(t, &MFAStatus{Configured: true, Required: true}, status)
+
+	require.NoError(t, a.ResetOTP(context.Background(), "admin-token", "kc-1"))
+
+	assert.Equal(t, []map[string]string{{"id": "password-1", "type": "password"}}, user.credentials)
+	assert.Equal(t, []string{"UPDATE_PASSWORD"}, user.requiredActions)
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/httpclient"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// keycloakOrganization is an organization as represented by the admin API
+type keycloakOrganization struct {
+	ID         string                       `json:"id,omitempty"`
+	Name       string                       `json:"name"`
+	Alias      string                       `json:"alias,omitempty"`
+	Enabled    bool                         `json:"enabled"`
+	Attributes map[string][]string          `json:"attributes,omitempty"`
+	Domains    []keycloakOrganizationDomain `json:"domains"`
+}
+
+// keycloakOrganizationDomain is a domain of an organization
+type keycloakOrganizationDomain struct {
+	Name     string `json:"name"`
+	Verified bool   `json:"verified"`
+}
+
+// organizationsURL returns the admin API URL of the organizations
+func organizationsURL(endpoints *KeycloakEndpoints) string {
+	return endpoints.AdminURL + "/organizations"
+}
+
+func newTenantOrganization(organization keycloakOrganization) *TenantOrganization {
+	domains := make([]string, len(organization.Domains))
+	for i, domain := range organization.Domains {
+		domains[i] = domain.Name
+	}
+	return &TenantOrganization{
+		ID:         organization.ID,
+		Name:       organization.Name,
+		Alias:      organization.Alias,
+		Domains:    domains,
+		Attributes: organization.Attributes,
+	}
+}
+
+// FindTenantOrganization looks the organization up by its slug attribute, as the admin API
+// cannot search the aliases
+func (a *KeycloakAuth) FindTenantOrganization(ctx context.Context, adminToken string, slug string) (*TenantOrganization, error) {
+	query := url.Values{
+		"q":                   {constants.KeycloakTenantSlugAttribute + ":" + slug},
+		"briefRepresentation": {"false"},
+	}.Encode()
+
+	var organizations []keycloakOrganization
+	err := a.admin(ctx, keycloakCall{
+		operation: "find_tenant_organization",
+		message:   "Failed to find tenant organization",
+		fields:    map[string]any{"slug": slug},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			endpoint := organizationsURL(endpoints)
+			resp, err := a.restClient.GetWithContext(ctx, endpoint, &organizations, a.getHeaders(token), query)
+			if err != nil {
+				return err
+			}
+			if resp.IsError() {
+				return &httpclient.StatusError{Endpoint: endpoint, StatusCode: resp.StatusCode(), Body: resp.String()}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, organization := range organizations {
+		// The attribute query is a prefix match on some versions
+		for _, value := range organization.Attributes[constants.KeycloakTenantSlugAttribute] {
+			if value == slug {
+				return newTenantOrganization(organization), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ListUserOrganizations returns the organizations the user is a member of, with the
+// members endpoint of the organizations admin API (Keycloak 26+)
+func (a *KeycloakAuth) ListUserOrganizations(ctx context.Context, adminToken string, userID string) ([]TenantOrganization, error) {
+	var organizations []keycloakOrganization
+	err := a.admin(ctx, keycloakCall{
+		operation: "list_user_organizations",
+		message:   "Failed to list user organizations",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			endpoint := fmt.Sprintf("%s/members/%s/organizations", organizationsURL(endpoints), url.PathEscape(userID))
+			resp, err := a.restClient.GetWithContext(ctx, endpoint, &organizations, a.getHeaders(token), "")
+			if err != nil {
+				return err
+			}
+			if resp.IsError() {
+				return &httpclient.StatusError{Endpoint: endpoint, StatusCode: resp.StatusCode(), Body: resp.String()}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TenantOrganization, len(organizations))
+	for i, organization := range organizations {
+		result[i] = *newTenantOrganization(organization)
+	}
+	return result, nil
+}
+
+func (a *KeycloakAuth) SaveTenantOrganization(ctx context.Context, adminToken string, organization *TenantOrganization) (*TenantOrganization, error) {
+	representation := keycloakOrganization{
+		ID:         organization.ID,
+		Name:       organization.Name,
+		Alias:      organization.Alias,
+		Enabled:    true,
+		Attributes: organization.Attributes,
+		Domains:    make([]keycloakOrganizationDomain, len(organization.Domains)),
+	}
+	for i, domain := range organization.Domains {
+		representation.Domains[i] = keycloakOrganizationDomain{Name: domain}
+	}
+
+	operation := "update_tenant_organization"
+	if organization.ID == "" {
+		operation = "create_tenant_organization"
+	}
+
+	saved := *organization
+	err := a.admin(ctx, keycloakCall{
+		operation: operation,
+		message:   "Failed to save tenant organization",
+		// Keycloak answers 409 when the name, alias or a domain belongs to another organization
+		conflict: "The name, alias or a domain of the organization is already used in Keycloak",
+		create:   organization.ID == "",
+		fields:   map[string]any{"organization_id": organization.ID, "alias": organization.Alias},
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			var errorResponse map[string]interface{}
+			if organization.ID != "" {
+				endpoint := fmt.Sprintf("%s/%s", organizationsURL(endpoints), url.PathEscape(organization.ID))
+				resp, err := a.restClient.Put(endpoint, representation, nil, &errorResponse, a.getHeaders(token))
+				if err != nil {
+					return err
+				}
+				if resp.IsError() {
+					return &httpclient.StatusError{Method: http.MethodPut, Endpoint: endpoint, StatusCode: resp.StatusCode(), Body: resp.String()}
+				}
+				return nil
+			}
+
+			endpoint := organizationsURL(endpoints)
+			resp, err := a.restClient.Post(endpoint, representation, nil, &errorResponse, a.getHeaders(token))
+			if err != nil {
+				return err
+			}
+			if resp.IsError() {
+				return &httpclient.StatusError{Method: http.MethodPost, Endpoint: endpoint, StatusCode: resp.StatusCode(), Body: resp.String()}
+			}
+			// The ID of the new organization is the last segment of its location
+			location := resp.Header().Get("Location")
+			if location == "" {
+				return fmt.Errorf("POST %s: no location of the created organization", endpoint)
+			}
+			saved.ID = path.Base(location)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+func (a *KeycloakAuth) EnsureClientRoles(ctx context.Context, adminToken string, roles []string) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	return a.admin(ctx, keycloakCall{
+		operation: "ensure_client_roles",
+		message:   "Failed to create client role",
+		fields:    map[string]any{"client_id": a.config.KeycloakClientID, "roles": roles},
+	}, adminToken, func(ctx context.Context, token string) error {
+		kcClient, err := a.realmClient(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			_, err := a.gocloak().GetClientRole(ctx, token, a.config.KeycloakRealm, *kcClient.ID, role)
+			var apiErr *gocloak.APIError
+			if err == nil {
+				continue
+			}
+			if stderrors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				_, err = a.gocloak().CreateClientRole(ctx, token, a.config.KeycloakRealm, *kcClient.ID, gocloak.Role{Name: gocloak.StringP(role)})
+				// Another provisioning created it meanwhile
+				if stderrors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+					err = nil
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("role %s: %w", role, err)
+			}
+		}
+		return nil
+	})
+}
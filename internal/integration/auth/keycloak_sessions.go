@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// GetUserSessions lists the sessions of the user, the most recently refreshed first
+func (a *KeycloakAuth) GetUserSessions(ctx context.Context, adminToken string, userID string) ([]Session, error) {
+	var sessions []Session
+	err := a.admin(ctx, keycloakCall{
+		operation: "get_user_sessions",
+		message:   "Failed to get user sessions",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		representations, err := a.gocloak().GetUserSessions(ctx, token, a.config.KeycloakRealm, userID)
+		if err != nil {
+			return err
+		}
+
+		sessions = make([]Session, 0, len(representations))
+		for _, representation := range representations {
+			sessions = append(sessions, newSession(representation))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(sessions, func(a, b Session) int {
+		return b.LastAccess.Compare(a.LastAccess)
+	})
+	return sessions, nil
+}
+
+// LogoutUserSession ends the session; a session that already ended is ignored
+func (a *KeycloakAuth) LogoutUserSession(ctx context.Context, adminToken string, sessionID string) error {
+	return a.admin(ctx, keycloakCall{
+		operation: "logout_user_session",
+		message:   "Failed to log out user session",
+		fields:    map[string]any{"session_id": sessionID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		err := a.gocloak().LogoutUserSession(ctx, token, a.config.KeycloakRealm, sessionID)
+		if responseStatus(err) == http.StatusNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// newSession converts a session of the admin API, whose times are in milliseconds
+func newSession(representation *gocloak.UserSessionRepresentation) Session {
+	session := Session{
+		ID:        gocloak.PString(representation.ID),
+		IPAddress: gocloak.PString(representation.IPAddress),
+	}
+	if representation.Start != nil {
+		session.Start = time.UnixMilli(*representation.Start).UTC()
+	}
+	if representation.LastAccess != nil {
+		session.LastAccess = time.UnixMilli(*representation.LastAccess).UTC()
+	}
+	if representation.Clients != nil {
+		for _, clientID := range *representation.Clients {
+			session.Clients = append(session.Clients, clientID)
+		}
+		slices.Sort(session.Clients)
+	}
+
+	return session
+}
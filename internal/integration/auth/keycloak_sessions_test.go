@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionsKeycloakAuth(t *testing.T, handler http.HandlerFunc) *KeycloakAuth {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &KeycloakAuth{
+		config: &config.Config{
+			KeycloakRealm:           "test",
+			KeycloakClientID:        "server",
+			KeycloakAdminAttempts:   1,
+			KeycloakAdminRetryDelay: time.Millisecond,
+		},
+		client: gocloak.NewClient(server.URL),
+	}
+}
+
+func TestKeycloakAuth_GetUserSessions(t *testing.T) {
+	a := newSessionsKeycloakAuth(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/admin/realms/test/users/kc-1/sessions", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": "session-1", "ipAddress": "198.51.100.4", "start": 1760000000000, "lastAccess": 1760000100000, "clients": map[string]string{"uuid-2": "web-app", "uuid-1": "admin-console"}},
+			{"id": "session-2", "ipAddress": "203.0.113.7", "start": 1760000200000, "lastAccess": 1760000300000},
+		})
+	})
+
+	sessions, err := a.GetUserSessions(context.Background(), "admin-token", "kc-1")
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "session-2", sessions[0].ID)
+	assert.Equal(t, Session{
+		ID:         "session-1",
+		IPAddress:  "198.51.100.4",
+		Start:      time.UnixMilli(1760000000000).UTC(),
+		LastAccess: time.UnixMilli(1760000100000).UTC(),
+		Clients:    []string{"admin-console", "web-app"},
+	}, sessions[1])
+}
+
+func TestKeycloakAuth_LogoutUserSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "session ended", status: http.StatusNoContent},
+		{name: "session already ended", status: http.StatusNotFound},
+		{name: "Keycloak failure", status: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSessionsKeycloakAuth(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodDelete, r.Method)
+				require.Equal(t, "/admin/realms/test/sessions/session-1", r.URL.Path)
+				w.WriteHeader(tt.status)
+			})
+
+			err := a.LogoutUserSession(context.Background(), "admin-token", "session-1")
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
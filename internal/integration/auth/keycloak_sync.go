@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang-boilerplate/internal/httpclient"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// ListUsers returns every user of the realm, walking the admin API pages with the first and
+// max parameters. Keycloak leaves the service accounts of the clients out.
+func (a *KeycloakAuth) ListUsers(ctx context.Context, adminToken string) ([]User, error) {
+	var members []keycloakMember
+	err := a.admin(ctx, keycloakCall{
+		operation: "list_users",
+		message:   "Failed to list users",
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			pages := httpclient.NewPageIterator(a.restClient, httpclient.PageIteratorConfig[keycloakMember]{
+				Endpoint:   endpoints.AdminURL + "/users",
+				Headers:    a.getHeaders(token),
+				Query:      url.Values{"briefRepresentation": {"true"}},
+				Pagination: httpclient.OffsetPagination{OffsetParam: "first", LimitParam: "max"},
+				Limiter:    a.adminLimiter,
+			})
+
+			var err error
+			members, err = pages.Collect(ctx)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, len(members))
+	for i, member := range members {
+		users[i] = User{
+			ID:                member.ID,
+			Sub:               member.ID,
+			PreferredUsername: member.Username,
+			GivenName:         member.FirstName,
+			FamilyName:        member.LastName,
+			Name:              strings.TrimSpace(member.FirstName + " " + member.LastName),
+			Email:             member.Email,
+			EmailVerified:     member.EmailVerified,
+			Enabled:           member.Enabled,
+		}
+	}
+
+	return users, nil
+}
+
+// ListOrganizations returns every organization of the realm, walking the admin API pages
+// with the first and max parameters
+func (a *KeycloakAuth) ListOrganizations(ctx context.Context, adminToken string) ([]TenantOrganization, error) {
+	var organizations []keycloakOrganization
+	err := a.admin(ctx, keycloakCall{
+		operation: "list_organizations",
+		message:   "Failed to list organizations",
+	}, adminToken, func(ctx context.Context, token string) error {
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			pages := httpclient.NewPageIterator(a.restClient, httpclient.PageIteratorConfig[keycloakOrganization]{
+				Endpoint:   organizationsURL(endpoints),
+				Headers:    a.getHeaders(token),
+				Query:      url.Values{"briefRepresentation": {"false"}},
+				Pagination: httpclient.OffsetPagination{OffsetParam: "first", LimitParam: "max"},
+				Limiter:    a.adminLimiter,
+			})
+
+			var err error
+			organizations, err = pages.Collect(ctx)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TenantOrganization, len(organizations))
+	for i, organization := range organizations {
+		result[i] = *newTenantOrganization(organization)
+	}
+	return result, nil
+}
+
+// ListClientRoleMembers returns the IDs of the users granted each of the roles of the
+// client; the roles the client does not define are left out
+func (a *KeycloakAuth) ListClientRoleMembers(ctx context.Context, adminToken string, roles []string) (map[string][]string, error) {
+	result := make(map[string][]string, len(roles))
+	if len(roles) == 0 {
+		return result, nil
+	}
+
+	err := a.admin(ctx, keycloakCall{
+		operation: "list_client_role_members",
+		message:   "Failed to list client role members",
+		fields:    map[string]any{"client_id": a.config.KeycloakClientID, "roles": roles},
+	}, adminToken, func(ctx context.Context, token string) error {
+		kcClient, err := a.realmClient(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		return a.withRediscovery(ctx, func(endpoints *KeycloakEndpoints) error {
+			clear(result)
+			for _, role := range roles {
+				_, err := a.gocloak().GetClientRole(ctx, token, a.config.KeycloakRealm, *kcClient.ID, role)
+				var apiErr *gocloak.APIError
+				if stderrors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("role %s: %w", role, err)
+				}
+
+				pages := httpclient.NewPageIterator(a.restClient, httpclient.PageIteratorConfig[keycloakMember]{
+					Endpoint:   fmt.Sprintf("%s/clients/%s/roles/%s/users", endpoints.AdminURL, url.PathEscape(*kcClient.ID), url.PathEscape(role)),
+					Headers:    a.getHeaders(token),
+					Query:      url.Values{"briefRepresentation": {"true"}},
+					Pagination: httpclient.OffsetPagination{OffsetParam: "first", LimitParam: "max"},
+					Limiter:    a.adminLimiter,
+				})
+				members, err := pages.Collect(ctx)
+				if err != nil {
+					return fmt.Errorf("role %s: %w", role, err)
+				}
+
+				ids := make([]string, len(members))
+				for i, member := range members {
+					ids[i] = member.ID
+				}
+				result[role] = ids
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetUser returns the user of the realm with the ID, nil when there is none
+func (a *KeycloakAuth) GetUser(ctx context.Context, adminToken string, userID string) (*User, error) {
+	var user *gocloak.User
+	err := a.admin(ctx, keycloakCall{
+		operation: "get_user",
+		message:   "Failed to get user",
+		fields:    map[string]any{"user_id": userID},
+	}, adminToken, func(ctx context.Context, token string) error {
+		var err error
+		user, err = a.gocloak().GetUserByID(ctx, token, a.config.KeycloakRealm, userID)
+		if responseStatus(err) == http.StatusNotFound {
+			user = nil
+			return nil
+		}
+		return err
+	})
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:                userID,
+		Sub:               userID,
+		Email:             gocloak.PString(user.Email),
+		PreferredUsername: gocloak.PString(user.Username),
+		GivenName:         gocloak.PString(user.FirstName),
+		FamilyName:        gocloak.PString(user.LastName),
+		Name:              strings.TrimSpace(gocloak.PString(user.FirstName) + " " + gocloak.PString(user.LastName)),
+		EmailVerified:     gocloak.PBool(user.EmailVerified),
+		Enabled:           user.Enabled,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeycloakAuth_GetUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantUser bool
+		wantErr  bool
+	}{
+		{name: "user", status: http.StatusOK, wantUser: true},
+		{name: "user deleted", status: http.StatusNotFound},
+		{name: "Keycloak failure", status: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newSessionsKeycloakAuth(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/admin/realms/test/users/kc-1", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					_ = json.NewEncoder(w).Encode(map[string]any{
+						"id": "kc-1", "username": "john", "email": "john@example.com", "firstName": "John", "lastName": "Doe", "enabled": false,
+					})
+				}
+			})
+
+			user, err := a.GetUser(context.Background(), "admin-token", "kc-1")
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.wantUser {
+				assert.Nil(t, user)
+				return
+			}
+			require.NotNil(t, user)
+			assert.Equal(t, "john@example.com", user.Email)
+			assert.Equal(t, "John Doe", user.Name)
+			require.NotNil(t, user.Enabled)
+			assert.False(t, *user.Enabled)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// ServiceIdentity is the internal service a machine token was issued to, mapped from its
+// client by SERVICE_CLIENTS
+type ServiceIdentity struct {
+	Name     string
+	ClientID string
+	// Scopes are the scopes of the token, the client scopes of the client in Keycloak
+	Scopes []string
+}
+
+// HasScopes reports whether the service was granted all the scopes
+func (s *ServiceIdentity) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(s.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceClientID returns the client a token of the client credentials grant was issued
+// to, or "" for the token of a user. Keycloak issues these tokens for the service account
+// of the client, and names its client in the client_id claim, clientId before Keycloak 25.
+func (c *TokenClaims) ServiceClientID() string {
+	if !strings.HasPrefix(c.PreferredUsername, constants.KeycloakServiceAccountPrefix) {
+		return ""
+	}
+	for _, claim := range []string{"client_id", "clientId", "azp"} {
+		if clientID, ok := c.MapClaims[claim].(string); ok && clientID != "" {
+			return clientID
+		}
+	}
+	return ""
+}
+
+// ServiceScopes returns the scopes of the token
+func (c *TokenClaims) ServiceScopes() []string {
+	return strings.Fields(c.Scope)
+}
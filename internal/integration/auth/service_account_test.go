@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenClaims_ServiceClientID(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   TokenClaims
+		expected string
+	}{
+		{
+			name: "client_id claim",
+			claims: TokenClaims{
+				MapClaims:         jwt.MapClaims{"client_id": "billing-worker", "azp": "billing-worker"},
+				PreferredUsername: "service-account-billing-worker",
+			},
+			expected: "billing-worker",
+		},
+		{
+			name: "legacy clientId claim",
+			claims: TokenClaims{
+				MapClaims:         jwt.MapClaims{"clientId": "billing-worker"},
+				PreferredUsername: "service-account-billing-worker",
+			},
+			expected: "billing-worker",
+		},
+		{
+			name: "authorized party only",
+			claims: TokenClaims{
+				MapClaims:         jwt.MapClaims{"azp": "billing-worker"},
+				PreferredUsername: "service-account-billing-worker",
+			},
+			expected: "billing-worker",
+		},
+		{
+			name: "user token",
+			claims: TokenClaims{
+				MapClaims:         jwt.MapClaims{"azp": "frontend"},
+				PreferredUsername: "john",
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.claims.ServiceClientID())
+		})
+	}
+}
+
+func TestServiceIdentity_HasScopes(t *testing.T) {
+	claims := TokenClaims{Scope: "profile invoices:read invoices:write"}
+	service := ServiceIdentity{Name: "billing", Scopes: claims.ServiceScopes()}
+
+	assert.True(t, service.HasScopes())
+	assert.True(t, service.HasScopes("invoices:read", "invoices:write"))
+	assert.False(t, service.HasScopes("invoices:read", "users:read"))
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TokenProvider provides the admin token of the client to the calls of the admin API
+type TokenProvider interface {
+	// AdminToken returns the access token of a client login, cached until shortly before
+	// it expires
+	AdminToken(ctx context.Context) (string, error)
+}
+
+// CachedTokenProvider caches the token of a client login instead of logging in for every
+// admin call. Once the token enters the last KEYCLOAK_ADMIN_TOKEN_REFRESH_BEFORE of its
+// lifetime, it is still served while a login refreshes it in the background, so the
+// callers do not wait for Keycloak; a token about to expire is refreshed before it is
+// served. Concurrent refreshes share a single login.
+type CachedTokenProvider struct {
+	login         func() (*TokenInfo, error)
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu        sync.RWMutex
+	token     string
+	refreshAt time.Time
+	expiresAt time.Time
+
+	logins     singleflight.Group
+	refreshing atomic.Bool
+}
+
+// ProvideTokenProvider is the Fx provider of the admin token of the client
+func ProvideTokenProvider(cfg *config.Config, authService AuthService) TokenProvider {
+	return NewCachedTokenProvider(authService.ClientLogin, cfg.KeycloakTokenRefreshBefore)
+}
+
+// NewCachedTokenProvider caches the tokens of login, refreshing them refreshBefore their
+// expiry
+func NewCachedTokenProvider(login func() (*TokenInfo, error), refreshBefore time.Duration) *CachedTokenProvider {
+	return &CachedTokenProvider{
+		login:         login,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+	}
+}
+
+// AdminToken returns the cached token, refreshing it when it is about to expire
+func (p *CachedTokenProvider) AdminToken(ctx context.Context) (string, error) {
+	p.mu.RLock()
+	token, refreshAt, expiresAt := p.token, p.refreshAt, p.expiresAt
+	p.mu.RUnlock()
+
+	now := p.now()
+	if token != "" && now.Before(expiresAt) {
+		if !now.Before(refreshAt) && p.refreshing.CompareAndSwap(false, true) {
+			go func() {
+				defer p.refreshing.Store(false)
+				if _, err, _ := p.logins.Do("login", p.refresh); err != nil {
+					logger.Sugar.Warnw("Failed to refresh the Keycloak admin token", "error", err)
+				}
+			}()
+		}
+		return token, nil
+	}
+
+	select {
+	case result := <-p.logins.DoChan("login", p.refresh):
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// refresh logs in and caches the new token
+func (p *CachedTokenProvider) refresh() (any, error) {
+	loggedInAt := p.now()
+	info, err := p.login()
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := time.Duration(info.ExpiresIn) * time.Second
+	p.mu.Lock()
+	p.token = info.AccessToken
+	p.refreshAt = loggedInAt.Add(max(lifetime-p.refreshBefore, lifetime/2))
+	p.expiresAt = loggedInAt.Add(lifetime - min(constants.KeycloakAdminTokenExpiryMargin, lifetime/2))
+	p.mu.Unlock()
+
+	return info.AccessToken, nil
+}
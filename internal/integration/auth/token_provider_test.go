@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLogin returns a login issuing the tokens token-1, token-2... of 60 seconds, and
+// the number of logins done
+func countingLogin() (func() (*TokenInfo, error), *atomic.Int32) {
+	logins := &atomic.Int32{}
+	return func() (*TokenInfo, error) {
+		n := logins.Add(1)
+		return &TokenInfo{AccessToken: fmt.Sprintf("token-%d", n), ExpiresIn: 60}, nil
+	}, logins
+}
+
+func TestCachedTokenProvider_AdminToken(t *testing.T) {
+	login, logins := countingLogin()
+	provider := NewCachedTokenProvider(login, 20*time.Second)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	// The concurrent first calls share one login
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := provider.AdminToken(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "token-1", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), logins.Load())
+
+	// The token is served from the cache until its refresh
+	now = now.Add(30 * time.Second)
+	token, err := provider.AdminToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), logins.Load())
+
+	// Within the refresh window the token is served while it is refreshed in the background
+	now = now.Add(15 * time.Second)
+	token, err = provider.AdminToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	require.Eventually(t, func() bool {
+		token, err := provider.AdminToken(context.Background())
+		return err == nil && token == "token-2"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), logins.Load())
+}
+
+func TestCachedTokenProvider_AdminToken_Expired(t *testing.T) {
+	login, logins := countingLogin()
+	provider := NewCachedTokenProvider(login, 20*time.Second)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	_, err := provider.AdminToken(context.Background())
+	require.NoError(t, err)
+
+	// A token about to expire is not served, the caller waits for a new one
+	now = now.Add(56 * time.Second)
+	token, err := provider.AdminToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, int32(2), logins.Load())
+}
+
+func TestCachedTokenProvider_AdminToken_LoginFails(t *testing.T) {
+	provider := NewCachedTokenProvider(func() (*TokenInfo, error) {
+		return nil, assert.AnError
+	}, 20*time.Second)
+
+	_, err := provider.AdminToken(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,98 @@
+// Package cdn tags the cacheable responses with surrogate keys and purges the keys of the
+// changed entities from the CDN. The CDN is selected with CDN_PROVIDER.
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/httpclient"
+)
+
+// Purger invalidates the responses cached by the CDN
+type Purger interface {
+	// Purge invalidates the responses tagged with any of the keys
+	Purge(ctx context.Context, keys []string) error
+	// PurgeAll invalidates every cached response
+	PurgeAll(ctx context.Context) error
+}
+
+// ProvidePurger creates the purger of CDN_PROVIDER, one purging nothing when it is empty
+func ProvidePurger(cfg *config.Config, restClient httpclient.RestClient) (Purger, error) {
+	switch cfg.CDNProvider {
+	case "":
+		return disabledPurger{}, nil
+	case constants.CDNProviderFastly:
+		return NewFastly(restClient, constants.FastlyAPIURL, cfg.FastlyServiceID, cfg.FastlyAPIToken), nil
+	case constants.CDNProviderCloudflare:
+		return NewCloudflare(restClient, constants.CloudflareAPIURL, cfg.CloudflareZoneID, cfg.CloudflareAPIToken), nil
+	default:
+		return nil, errors.InternalError("Invalid CDN provider", fmt.Errorf("invalid CDN provider: %s", cfg.CDNProvider)).
+			WithOperation("initialize_cdn").
+			WithResource("cdn").
+			WithContext("cdn_provider", cfg.CDNProvider)
+	}
+}
+
+// RouteKeys returns the surrogate keys of a response of the route path, e.g.
+// /api/v1/companies/:id/users, with the values of its parameters: a key per resource
+// followed by a parameter, such as companies:42, and the resource listed after them, such
+// as users, whatever follows it, e.g. /users/export. The routes outside
+// constants.CDNRoutePrefix have none.
+func RouteKeys(path string, param func(name string) string) []string {
+	rest, ok := strings.CutPrefix(path, constants.CDNRoutePrefix+"/")
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	segments := strings.Split(rest, "/")
+	listed := 0
+	for i := 0; i+1 < len(segments); i++ {
+		name, isParam := strings.CutPrefix(segments[i+1], ":")
+		if !isParam || strings.HasPrefix(segments[i], ":") {
+			continue
+		}
+		if value := param(name); value != "" {
+			keys = append(keys, EntityKey(segments[i], value))
+		}
+		listed = i + 2
+	}
+	if listed < len(segments) && segments[listed] != "" && !strings.HasPrefix(segments[listed], ":") && !strings.HasPrefix(segments[listed], "*") {
+		keys = append(keys, segments[listed])
+	}
+	return keys
+}
+
+// EntityKey returns the surrogate key of an entity of a resource, e.g. users:42
+func EntityKey(resource string, id string) string {
+	return resource + ":" + id
+}
+
+// batches splits keys in slices of at most size keys
+func batches(keys []string, size int) [][]string {
+	var result [][]string
+	for len(keys) > size {
+		result = append(result, keys[:size])
+		keys = keys[size:]
+	}
+	if len(keys) > 0 {
+		result = append(result, keys)
+	}
+	return result
+}
+
+// disabledPurger is used when no CDN provider is configured
+type disabledPurger struct{}
+
+func (disabledPurger) Purge(ctx context.Context, keys []string) error {
+	return nil
+}
+
+func (disabledPurger) PurgeAll(ctx context.Context) error {
+	return nil
+}
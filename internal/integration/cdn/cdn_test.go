@@ -0,0 +1,106 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteKeys(t *testing.T) {
+	params := map[string]string{"id": "42", "userId": "7"}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{name: "list", path: "/api/v1/users", expected: []string{"users"}},
+		{name: "entity", path: "/api/v1/users/:id", expected: []string{"users:42"}},
+		{name: "sub-resource list", path: "/api/v1/companies/:id/users", expected: []string{"companies:42", "users"}},
+		{name: "nested entities", path: "/api/v1/companies/:id/users/:userId", expected: []string{"companies:42", "users:7"}},
+		{name: "action of a list", path: "/api/v1/users/export", expected: []string{"users"}},
+		{name: "outside the API", path: "/health", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := RouteKeys(tt.path, func(name string) string { return params[name] })
+
+			assert.Equal(t, tt.expected, keys)
+		})
+	}
+}
+
+func TestBatches(t *testing.T) {
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, batches([]string{"a", "b", "c"}, 2))
+	assert.Nil(t, batches(nil, 2))
+}
+
+func newRestClient() httpclient.RestClient {
+	return httpclient.ProvideRestClient(&config.Config{HTTPClientTimeout: 5 * time.Second})
+}
+
+func TestFastly_Purge(t *testing.T) {
+	var surrogateKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/service/svc-1/purge", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("Fastly-Key"))
+		surrogateKeys = append(surrogateKeys, r.Header.Get("Surrogate-Key"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	keys := make([]string, 300)
+	for i := range keys {
+		keys[i] = "users"
+	}
+	err := NewFastly(newRestClient(), server.URL, "svc-1", "token").Purge(context.Background(), keys)
+
+	require.NoError(t, err)
+	require.Len(t, surrogateKeys, 2)
+	assert.Len(t, strings.Fields(surrogateKeys[0]), 256)
+	assert.Len(t, strings.Fields(surrogateKeys[1]), 44)
+}
+
+func TestCloudflare_Purge(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectedError bool
+	}{
+		{name: "purged", status: http.StatusOK},
+		{name: "rejected", status: http.StatusForbidden, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body cloudflarePurgeRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/zones/zone-1/purge_cache", r.URL.Path)
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewCloudflare(newRestClient(), server.URL, "zone-1", "token").Purge(context.Background(), []string{"users", "users:42"})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"users", "users:42"}, body.Tags)
+		})
+	}
+}
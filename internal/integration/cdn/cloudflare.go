@@ -0,0 +1,54 @@
+package cdn
+
+import (
+	"context"
+	"fmt"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/httpclient"
+)
+
+// Cloudflare purges the cache tags of a Cloudflare zone with its purge cache API
+type Cloudflare struct {
+	restClient httpclient.RestClient
+	baseURL    string
+	zoneID     string
+	token      string
+}
+
+// cloudflarePurgeRequest is the body of the purge cache API
+type cloudflarePurgeRequest struct {
+	Tags            []string `json:"tags,omitempty"`
+	PurgeEverything bool     `json:"purge_everything,omitempty"`
+}
+
+// NewCloudflare creates the purger of the Cloudflare zone
+func NewCloudflare(restClient httpclient.RestClient, baseURL string, zoneID string, token string) *Cloudflare {
+	return &Cloudflare{restClient: restClient, baseURL: baseURL, zoneID: zoneID, token: token}
+}
+
+// Purge implements Purger, in batches of constants.CloudflarePurgeBatchSize tags
+func (c *Cloudflare) Purge(ctx context.Context, keys []string) error {
+	for _, batch := range batches(keys, constants.CloudflarePurgeBatchSize) {
+		if err := post(c.restClient, c.purgeURL(), cloudflarePurgeRequest{Tags: batch}, c.headers()); err != nil {
+			return purgeError(constants.CDNProviderCloudflare, "purge_cdn_keys", err).WithContext("keys", batch)
+		}
+	}
+	return nil
+}
+
+// PurgeAll implements Purger
+func (c *Cloudflare) PurgeAll(ctx context.Context) error {
+	if err := post(c.restClient, c.purgeURL(), cloudflarePurgeRequest{PurgeEverything: true}, c.headers()); err != nil {
+		return purgeError(constants.CDNProviderCloudflare, "purge_cdn", err)
+	}
+	return nil
+}
+
+func (c *Cloudflare) purgeURL() string {
+	return fmt.Sprintf("%s/zones/%s/purge_cache", c.baseURL, c.zoneID)
+}
+
+func (c *Cloudflare) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + c.token}
+}
@@ -0,0 +1,75 @@
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/httpclient"
+)
+
+// Fastly purges the keys of a Fastly service with its purge API
+type Fastly struct {
+	restClient httpclient.RestClient
+	baseURL    string
+	serviceID  string
+	token      string
+}
+
+// NewFastly creates the purger of the Fastly service
+func NewFastly(restClient httpclient.RestClient, baseURL string, serviceID string, token string) *Fastly {
+	return &Fastly{restClient: restClient, baseURL: baseURL, serviceID: serviceID, token: token}
+}
+
+// Purge implements Purger, in batches of constants.FastlyPurgeBatchSize keys
+func (f *Fastly) Purge(ctx context.Context, keys []string) error {
+	for _, batch := range batches(keys, constants.FastlyPurgeBatchSize) {
+		headers := f.headers()
+		headers[constants.HeaderSurrogateKey] = strings.Join(batch, " ")
+		if err := post(f.restClient, fmt.Sprintf("%s/service/%s/purge", f.baseURL, f.serviceID), nil, headers); err != nil {
+			return purgeError(constants.CDNProviderFastly, "purge_cdn_keys", err).WithContext("keys", batch)
+		}
+	}
+	return nil
+}
+
+// PurgeAll implements Purger
+func (f *Fastly) PurgeAll(ctx context.Context) error {
+	if err := post(f.restClient, fmt.Sprintf("%s/service/%s/purge_all", f.baseURL, f.serviceID), nil, f.headers()); err != nil {
+		return purgeError(constants.CDNProviderFastly, "purge_cdn", err)
+	}
+	return nil
+}
+
+func (f *Fastly) headers() map[string]string {
+	return map[string]string{constants.HeaderFastlyKey: f.token}
+}
+
+// post sends a purge request and fails on an error status
+func post(restClient httpclient.RestClient, url string, body any, headers map[string]string) error {
+	var response map[string]any
+	var errorResponse map[string]any
+	resp, err := restClient.Post(url, body, &response, &errorResponse, headers)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return &httpclient.StatusError{
+			Method:     http.MethodPost,
+			Endpoint:   url,
+			StatusCode: resp.StatusCode(),
+			Body:       resp.String(),
+		}
+	}
+	return nil
+}
+
+func purgeError(provider string, operation string, err error) *errors.AppError {
+	return errors.ExternalServiceError("Failed to purge the CDN", err).
+		WithProvider(provider).
+		WithOperation(operation).
+		WithResource("cdn")
+}
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/sandbox"
+)
+
+// Inbox stores the captured emails, of the sandbox tenants and of the dev inbox
+type Inbox interface {
+	Create(email *models.CapturedEmail) error
+}
+
+// CaptureSender stores every email in the inbox instead of sending it, so that the
+// non-production environments can be tested without real mailboxes. The emails of a
+// sandbox tenant keep their company, so they also show in its inbox.
+type CaptureSender struct {
+	inbox Inbox
+}
+
+// NewCaptureSender creates a sender capturing every email into inbox
+func NewCaptureSender(inbox Inbox) *CaptureSender {
+	return &CaptureSender{inbox: inbox}
+}
+
+func (s *CaptureSender) SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error) {
+	return captureEmail(s.inbox, constants.EmailProviderCapture, sandboxCompany(ctx), message)
+}
+
+// SendRawEmail captures the raw MIME message as the text body of the email
+func (s *CaptureSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	return captureEmail(s.inbox, constants.EmailProviderCapture, sandboxCompany(ctx), EmailRequest{TextBody: string(rawData)})
+}
+
+// Ping succeeds, the inbox lives in the database checked on its own
+func (s *CaptureSender) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *CaptureSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	responses := make([]EmailResponse, len(messages))
+	for i, message := range messages {
+		response, err := captureEmail(s.inbox, constants.EmailProviderCapture, sandboxCompany(ctx), message)
+		if err != nil {
+			return responses, err
+		}
+		responses[i] = *response
+	}
+	return responses, nil
+}
+
+// sandboxCompany returns the sandbox tenant of the context, nil outside a sandbox
+func sandboxCompany(ctx context.Context) *string {
+	if companyID, ok := sandbox.CompanyID(ctx); ok {
+		return &companyID
+	}
+	return nil
+}
+
+// captureEmail stores the message in the inbox, for the company when not nil, and returns
+// the response of its capture by provider
+func captureEmail(inbox Inbox, provider string, companyID *string, message EmailRequest) (*EmailResponse, error) {
+	email := &models.CapturedEmail{
+		BaseModel:  models.NewBaseModel(),
+		CompanyID:  companyID,
+		To:         message.To,
+		Cc:         message.Cc,
+		Bcc:        message.Bcc,
+		Subject:    message.Subject,
+		TextBody:   message.TextBody,
+		HTMLBody:   message.HTMLBody,
+		TemplateID: message.TemplateID,
+	}
+	if len(message.TemplateData) > 0 {
+		templateData, err := json.Marshal(message.TemplateData)
+		if err != nil {
+			return nil, errors.ValidationError("Invalid email template data", err).
+				WithOperation("capture_email").
+				WithResource("email")
+		}
+		email.TemplateData = templateData
+	}
+	for _, attachment := range message.Attachments {
+		email.Attachments = append(email.Attachments, attachment.Filename)
+	}
+
+	if err := inbox.Create(email); err != nil {
+		return nil, err
+	}
+
+	return &EmailResponse{
+		MessageID: email.ID,
+		Provider:  provider,
+		Status:    constants.SandboxEmailStatusCaptured,
+	}, nil
+}
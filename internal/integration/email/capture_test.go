@@ -0,0 +1,61 @@
+package email
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/sandbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureSender_SendEmail(t *testing.T) {
+	message := EmailRequest{
+		To:       []string{"jane@example.com"},
+		Subject:  "Reset your password",
+		HTMLBody: "<p>Hello Jane</p>",
+	}
+
+	tests := []struct {
+		name              string
+		ctx               context.Context
+		expectedCompanyID *string
+	}{
+		{name: "emails are captured without company", ctx: context.Background()},
+		{name: "sandbox emails keep their company", ctx: sandbox.WithCompany(context.Background(), "sandbox-1"), expectedCompanyID: ptr("sandbox-1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbox := &memoryInbox{}
+			sender := NewCaptureSender(inbox)
+
+			response, err := sender.SendEmail(tt.ctx, message)
+
+			require.NoError(t, err)
+			require.Len(t, inbox.emails, 1)
+			captured := inbox.emails[0]
+			assert.Equal(t, tt.expectedCompanyID, captured.CompanyID)
+			assert.Equal(t, message.Subject, captured.Subject)
+			assert.Equal(t, message.HTMLBody, captured.HTMLBody)
+			assert.Equal(t, captured.ID, response.MessageID)
+			assert.Equal(t, constants.EmailProviderCapture, response.Provider)
+			assert.Equal(t, constants.SandboxEmailStatusCaptured, response.Status)
+		})
+	}
+}
+
+func TestProvideEmailSender_Capture(t *testing.T) {
+	// The provider is not initialized, so the capture works without its credentials
+	sender, err := ProvideEmailSender(config.Config{EmailProvider: constants.EmailProviderSES, EmailCapture: true}, &memoryInbox{}, nil)
+
+	require.NoError(t, err)
+	assert.IsType(t, &CaptureSender{}, sender)
+}
+
+func ptr(value string) *string {
+	return &value
+}
@@ -6,6 +6,8 @@ import (
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/monitoring"
 )
 
 // EmailMessage represents an email message
@@ -31,11 +33,16 @@ type EmailRequest struct {
 	Attachments  []Attachment           `json:"attachments,omitempty"`
 }
 
-// Attachment represents an email attachment
+// Attachment represents an email attachment, given with its content or stored under a
+// key of the storage adapter and streamed into the message. The content type defaults to
+// the one of the file extension.
 type Attachment struct {
 	Filename    string `json:"filename"`
-	Content     []byte `json:"content"`
-	ContentType string `json:"content_type"`
+	Content     []byte `json:"content,omitempty"`
+	StorageKey  string `json:"storage_key,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// ContentID shows an image inline, referenced from the HTML body as cid:<ContentID>
+	ContentID string `json:"content_id,omitempty"`
 }
 
 // EmailResponse represents the response from email providers
@@ -60,18 +67,81 @@ type TemplateData struct {
 type EmailSender interface {
 	SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error)
 	SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error)
+	// SendBulkEmail sends independent messages, using the provider batch API where supported.
+	// Responses are returned in the order of messages; on a call-level failure the
+	// messages that were not attempted are left with an empty Status.
+	SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error)
+	// Ping checks that the provider is reachable with the configured credentials
+	Ping(ctx context.Context) error
 }
 
-func ProvideEmailSender(config config.Config) (EmailSender, error) {
+// ProvideHealthChecker registers the email provider in the health registry. Emails are
+// queued and retried, so an unreachable provider only degrades the server.
+func ProvideHealthChecker(sender EmailSender) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:  constants.DependencyEmail,
+		Check: sender.Ping,
+	}
+}
+
+// ProvideEmailSender creates the sender of the configured provider, reading the stored
+// attachments from the storage adapter. The emails of the sandbox tenants are captured
+// into inbox instead, and all of them when the capture is enabled, without initializing
+// the provider. With EMAIL_REDIRECT_TO the other emails are sent to that address only.
+func ProvideEmailSender(config config.Config, inbox Inbox, store storage.StorageAdapter) (EmailSender, error) {
+	if config.EmailCapture {
+		return NewCaptureSender(inbox), nil
+	}
+
+	sender, err := newProviderSender(config, store)
+	if err != nil {
+		return nil, err
+	}
+	if config.EmailRedirectTo != "" {
+		sender = NewRedirectSender(sender, config.EmailRedirectTo)
+	}
+
+	return NewSandboxSender(sender, inbox), nil
+}
+
+// newProviderSender creates the sender of the configured provider, paced at its send
+// rate
+func newProviderSender(config config.Config, store storage.StorageAdapter) (EmailSender, error) {
 	switch config.EmailProvider {
 	case constants.EmailProviderSES:
-		sesSender, err := NewSESSender(config)
+		sesSender, err := NewSESSender(config, store)
 		if err != nil {
 			return nil, errors.ExternalServiceError("Failed to initialize SES email sender", err).
 				WithOperation("initialize_email_sender").
 				WithResource("email")
 		}
-		return sesSender, nil
+		sendRate := config.EmailSendRate
+		if sendRate <= 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), constants.EmailQuotaLookupTimeout)
+			sendRate = sesSender.MaxSendRate(ctx)
+			cancel()
+		}
+		return NewThrottledSender(sesSender, ThrottleConfig{
+			SendRate:    sendRate,
+			Burst:       config.EmailSendBurst,
+			BulkPercent: config.EmailBulkRatePercent,
+			BatchSize:   config.EmailBatchSize,
+		}), nil
+	case constants.EmailProviderSMTP:
+		smtpSender, err := NewSMTPSender(config, store)
+		if err != nil {
+			return nil, err
+		}
+		// A relay has no quota to discover, it is only paced when EMAIL_SEND_RATE is set
+		if config.EmailSendRate <= 0 {
+			return smtpSender, nil
+		}
+		return NewThrottledSender(smtpSender, ThrottleConfig{
+			SendRate:    config.EmailSendRate,
+			Burst:       config.EmailSendBurst,
+			BulkPercent: config.EmailBulkRatePercent,
+			BatchSize:   config.EmailBatchSize,
+		}), nil
 	default:
 		return nil, errors.InternalError("Invalid email provider", fmt.Errorf("invalid email provider: %s", config.EmailProvider)).
 			WithOperation("initialize_email_sender").
@@ -0,0 +1,390 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+)
+
+// AttachmentStore opens the stored objects of the attachments with a StorageKey, the
+// storage adapter of the application
+type AttachmentStore interface {
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// mimeMessage is the MIME message of an email request, built by the SMTP and SES senders.
+// Its attachments are only read when the message is written, the stored ones streamed
+// from the attachment store.
+type mimeMessage struct {
+	provider string
+	from     *mail.Address
+	// headers are the To and Cc recipients, recipients the envelope ones, Bcc included
+	headers    map[string][]*mail.Address
+	recipients []string
+	subject    string
+	messageID  string
+	root       *mimePart
+	store      AttachmentStore
+	// remaining is the size left to the attachments while the message is written
+	remaining int64
+}
+
+// mimePart is a part of a MIME message: a multipart of its parts, or a leaf writing its
+// encoded content
+type mimePart struct {
+	header   map[string]string
+	boundary string
+	parts    []*mimePart
+	content  func(ctx context.Context, w io.Writer, m *mimeMessage) error
+}
+
+// newMIMEMessage validates the request and builds its message: a text or HTML body, both
+// as alternatives, the HTML one related to its inline images, and the attachments after
+// them
+func newMIMEMessage(provider string, from *mail.Address, store AttachmentStore, request EmailRequest) (*mimeMessage, error) {
+	if request.TextBody == "" && request.HTMLBody == "" {
+		return nil, errors.ValidationError("Either HTML or text content must be provided", nil).
+			WithProvider(provider).
+			WithOperation("send_email").
+			WithResource(provider)
+	}
+
+	message := &mimeMessage{
+		provider:  provider,
+		from:      from,
+		headers:   map[string][]*mail.Address{},
+		subject:   request.Subject,
+		store:     store,
+		remaining: constants.EmailMaxAttachmentsSize,
+	}
+	for _, field := range []struct {
+		header    string
+		addresses []string
+	}{{"To", request.To}, {"Cc", request.Cc}, {"Bcc", request.Bcc}} {
+		for _, address := range field.addresses {
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				return nil, errors.ValidationError("Invalid email recipient", err).
+					WithProvider(provider).
+					WithOperation("send_email").
+					WithResource(provider).
+					WithContext("recipient", address)
+			}
+			message.recipients = append(message.recipients, parsed.Address)
+			if field.header != "Bcc" {
+				message.headers[field.header] = append(message.headers[field.header], parsed)
+			}
+		}
+	}
+
+	var inline, attachments []*mimePart
+	contentIDs := map[string]bool{}
+	for _, attachment := range request.Attachments {
+		contentType, err := message.validateAttachment(attachment, request.HTMLBody != "", contentIDs)
+		if err != nil {
+			return nil, err
+		}
+		if attachment.ContentID != "" {
+			inline = append(inline, attachmentPart(attachment, contentType))
+		} else {
+			attachments = append(attachments, attachmentPart(attachment, contentType))
+		}
+	}
+
+	var body *mimePart
+	if request.HTMLBody != "" {
+		body = textPart("text/html", request.HTMLBody)
+		if len(inline) > 0 {
+			body = multipartPart("multipart/related", append([]*mimePart{body}, inline...))
+		}
+	}
+	if request.TextBody != "" {
+		text := textPart("text/plain", request.TextBody)
+		if body == nil {
+			body = text
+		} else {
+			body = multipartPart("multipart/alternative", []*mimePart{text, body})
+		}
+	}
+	if len(attachments) > 0 {
+		body = multipartPart("multipart/mixed", append([]*mimePart{body}, attachments...))
+	}
+	message.root = body
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.InternalError("Failed to generate email message ID", err).
+			WithOperation("send_email").
+			WithResource(provider)
+	}
+	_, domain, _ := strings.Cut(from.Address, "@")
+	message.messageID = hex.EncodeToString(random) + "@" + domain
+
+	return message, nil
+}
+
+// validateAttachment checks an attachment and returns its content type: a file with
+// either its content or a storage key, not executable, and an image when it is inline in
+// the HTML body. The content given with the request counts against the size limit here,
+// the stored one while it is streamed.
+func (m *mimeMessage) validateAttachment(attachment Attachment, hasHTML bool, contentIDs map[string]bool) (string, error) {
+	invalid := func(reason string) error {
+		return errors.ValidationError("Invalid email attachment", fmt.Errorf("attachment %q: %s", attachment.Filename, reason)).
+			WithProvider(m.provider).
+			WithOperation("send_email").
+			WithResource(m.provider).
+			WithContext("filename", attachment.Filename)
+	}
+
+	extension := strings.ToLower(filepath.Ext(attachment.Filename))
+	switch {
+	case attachment.Filename == "":
+		return "", invalid("the filename is required")
+	case (len(attachment.Content) == 0) == (attachment.StorageKey == ""):
+		return "", invalid("either the content or the storage key is required")
+	case attachment.StorageKey != "" && m.store == nil:
+		return "", invalid("no attachment store to read the storage key from")
+	case slices.Contains(constants.EmailBlockedAttachmentExtensions, extension):
+		return "", invalid(fmt.Sprintf("%s files are not allowed", extension))
+	}
+
+	contentType := attachment.ContentType
+	if contentType == "" {
+		if contentType = mime.TypeByExtension(extension); contentType == "" {
+			contentType = constants.EmailDefaultAttachmentType
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return "", invalid(fmt.Sprintf("invalid content type %q", contentType))
+	}
+
+	if attachment.ContentID != "" {
+		switch {
+		case !hasHTML:
+			return "", invalid("inline attachments need an HTML body")
+		case !strings.HasPrefix(mediaType, "image/"):
+			return "", invalid("inline attachments must be images")
+		case strings.ContainsAny(attachment.ContentID, "<>\"\\ \t\r\n"):
+			return "", invalid(fmt.Sprintf("invalid content ID %q", attachment.ContentID))
+		case contentIDs[attachment.ContentID]:
+			return "", invalid(fmt.Sprintf("the content ID %q is used twice", attachment.ContentID))
+		}
+		contentIDs[attachment.ContentID] = true
+	}
+
+	if m.remaining -= int64(len(attachment.Content)); m.remaining < 0 {
+		return "", m.tooLarge()
+	}
+	return contentType, nil
+}
+
+func (m *mimeMessage) tooLarge() error {
+	return errors.ValidationError("Email attachments are too large", fmt.Errorf("the attachments exceed %d bytes", constants.EmailMaxAttachmentsSize)).
+		WithProvider(m.provider).
+		WithOperation("send_email").
+		WithResource(m.provider)
+}
+
+// write writes the message, streaming its stored attachments. It fails without
+// completing the message when an attachment cannot be read or exceeds the size limit.
+func (m *mimeMessage) write(ctx context.Context, w io.Writer) error {
+	var headers bytes.Buffer
+	writeHeader := func(key, value string) {
+		fmt.Fprintf(&headers, "%s: %s\r\n", key, value)
+	}
+	writeHeader("From", m.from.String())
+	for _, header := range []string{"To", "Cc"} {
+		if len(m.headers[header]) > 0 {
+			writeHeader(header, joinAddresses(m.headers[header]))
+		}
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", m.subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", "<"+m.messageID+">")
+	writeHeader("MIME-Version", "1.0")
+	for _, key := range sortedKeys(m.root.header) {
+		writeHeader(key, m.root.header[key])
+	}
+	headers.WriteString("\r\n")
+	if _, err := w.Write(headers.Bytes()); err != nil {
+		return err
+	}
+
+	return m.root.write(ctx, w, m)
+}
+
+// write writes the body of the part
+func (p *mimePart) write(ctx context.Context, w io.Writer, m *mimeMessage) error {
+	if p.content != nil {
+		return p.content(ctx, w, m)
+	}
+
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(p.boundary); err != nil {
+		return err
+	}
+	for _, part := range p.parts {
+		header := make(map[string][]string, len(part.header))
+		for key, value := range part.header {
+			header[key] = []string{value}
+		}
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if err := part.write(ctx, partWriter, m); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// textPart is a UTF-8 text part, quoted-printable encoded
+func textPart(mediaType, content string) *mimePart {
+	return &mimePart{
+		header: map[string]string{
+			"Content-Type":              mime.FormatMediaType(mediaType, map[string]string{"charset": "utf-8"}),
+			"Content-Transfer-Encoding": "quoted-printable",
+		},
+		content: func(_ context.Context, w io.Writer, _ *mimeMessage) error {
+			writer := quotedprintable.NewWriter(w)
+			if _, err := writer.Write([]byte(content)); err != nil {
+				return err
+			}
+			return writer.Close()
+		},
+	}
+}
+
+// attachmentPart is an attachment, base64 encoded in lines of 76 characters: inline with
+// its Content-ID when it has one, so that the HTML body shows it as cid:<ContentID>
+func attachmentPart(attachment Attachment, contentType string) *mimePart {
+	header := map[string]string{
+		"Content-Type":              contentType,
+		"Content-Disposition":       mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"Content-Transfer-Encoding": "base64",
+	}
+	if attachment.ContentID != "" {
+		header["Content-Disposition"] = mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename})
+		header["Content-ID"] = "<" + attachment.ContentID + ">"
+	}
+
+	return &mimePart{
+		header: header,
+		content: func(ctx context.Context, w io.Writer, m *mimeMessage) error {
+			lines := &lineWriter{w: w}
+			encoder := base64.NewEncoder(base64.StdEncoding, lines)
+			if len(attachment.Content) > 0 {
+				if _, err := encoder.Write(attachment.Content); err != nil {
+					return err
+				}
+			} else if err := m.stream(ctx, encoder, attachment); err != nil {
+				return err
+			}
+			if err := encoder.Close(); err != nil {
+				return err
+			}
+			return lines.close()
+		},
+	}
+}
+
+// stream copies the stored content of an attachment, within the size left to the
+// attachments
+func (m *mimeMessage) stream(ctx context.Context, w io.Writer, attachment Attachment) error {
+	reader, err := m.store.OpenFile(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	copied, err := io.Copy(w, io.LimitReader(reader, m.remaining+1))
+	if err != nil {
+		return errors.ExternalServiceError("Failed to read email attachment", err).
+			WithProvider(m.provider).
+			WithOperation("send_email").
+			WithResource(m.provider).
+			WithContext("storage_key", attachment.StorageKey)
+	}
+	if m.remaining -= copied; m.remaining < 0 {
+		return m.tooLarge()
+	}
+	return nil
+}
+
+// multipartPart is a multipart part of the media type holding the parts
+func multipartPart(mediaType string, parts []*mimePart) *mimePart {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	return &mimePart{
+		header: map[string]string{
+			"Content-Type": mime.FormatMediaType(mediaType, map[string]string{"boundary": boundary}),
+		},
+		boundary: boundary,
+		parts:    parts,
+	}
+}
+
+// lineWriter breaks the base64 content in lines of 76 characters
+type lineWriter struct {
+	w    io.Writer
+	line int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if l.line == 76 {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				return written, err
+			}
+			l.line = 0
+		}
+		n := min(len(p), 76-l.line)
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		l.line += n
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// close ends the last line
+func (l *lineWriter) close() error {
+	_, err := io.WriteString(l.w, "\r\n")
+	return err
+}
+
+func joinAddresses(addresses []*mail.Address) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		formatted[i] = address.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func sortedKeys(header map[string]string) []string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an attachment store of in-memory objects
+type memoryStore map[string][]byte
+
+func (s memoryStore) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	content, ok := s[key]
+	if !ok {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// readParts returns the media type of a body, and its parts with their content when it
+// is a multipart
+func readParts(t *testing.T, contentType string, body io.Reader) (string, []*multipart.Part, [][]byte) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return mediaType, nil, nil
+	}
+
+	var parts []*multipart.Part
+	var contents [][]byte
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return mediaType, parts, contents
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, part)
+		contents = append(contents, content)
+	}
+}
+
+func TestMIMEMessage_InlineAndStoredAttachments(t *testing.T) {
+	from := &mail.Address{Name: "App", Address: "no-reply@example.com"}
+	store := memoryStore{"reports/q1.pdf": []byte(strings.Repeat("a,b\n", 100))}
+	message, err := newMIMEMessage(constants.EmailProviderSES, from, store, EmailRequest{
+		To:       []string{"john@example.com"},
+		Bcc:      []string{"audit@example.com"},
+		Subject:  "Report",
+		TextBody: "Your report",
+		HTMLBody: `<img src="cid:logo"><p>Your report</p>`,
+		Attachments: []Attachment{
+			{Filename: "q1.pdf", StorageKey: "reports/q1.pdf"},
+			{Filename: "logo.png", Content: []byte("\x89PNG"), ContentType: "image/png", ContentID: "logo"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"john@example.com", "audit@example.com"}, message.recipients)
+
+	var data bytes.Buffer
+	require.NoError(t, message.write(context.Background(), &data))
+	parsed, err := mail.ReadMessage(&data)
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+
+	// mixed: alternative (text, related (html, logo)), report
+	mediaType, mixed, contents := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body)
+	assert.Equal(t, "multipart/mixed", mediaType)
+	require.Len(t, mixed, 2)
+	assert.Equal(t, "q1.pdf", mixed[1].FileName())
+	assert.Equal(t, "application/pdf", mixed[1].Header.Get("Content-Type"))
+	assert.Equal(t, strings.Repeat("a,b\n", 100), string(mustDecodeBase64(t, contents[1])))
+	for _, line := range strings.Split(strings.TrimSpace(string(contents[1])), "\r\n") {
+		assert.LessOrEqual(t, len(line), 76)
+	}
+
+	mediaType, alternatives, contents := readParts(t, mixed[0].Header.Get("Content-Type"), bytes.NewReader(contents[0]))
+	assert.Equal(t, "multipart/alternative", mediaType)
+	require.Len(t, alternatives, 2)
+	assert.Equal(t, "Your report", string(contents[0]))
+
+	mediaType, related, contents := readParts(t, alternatives[1].Header.Get("Content-Type"), bytes.NewReader(contents[1]))
+	assert.Equal(t, "multipart/related", mediaType)
+	require.Len(t, related, 2)
+	assert.Equal(t, `<img src="cid:logo"><p>Your report</p>`, string(contents[0]))
+	assert.Equal(t, "<logo>", related[1].Header.Get("Content-Id"))
+	assert.True(t, strings.HasPrefix(related[1].Header.Get("Content-Disposition"), "inline"))
+	assert.Equal(t, "\x89PNG", string(mustDecodeBase64(t, contents[1])))
+}
+
+func TestMIMEMessage_InvalidAttachments(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@example.com"}
+	request := func(attachment Attachment) EmailRequest {
+		return EmailRequest{To: []string{"a@example.com"}, HTMLBody: "<p>A</p>", Attachments: []Attachment{attachment}}
+	}
+
+	tests := []struct {
+		name    string
+		request EmailRequest
+		store   AttachmentStore
+	}{
+		{name: "no content", request: request(Attachment{Filename: "a.pdf"})},
+		{name: "content and storage key", request: request(Attachment{Filename: "a.pdf", Content: []byte("A"), StorageKey: "a.pdf"}), store: memoryStore{}},
+		{name: "storage key without store", request: request(Attachment{Filename: "a.pdf", StorageKey: "a.pdf"})},
+		{name: "executable", request: request(Attachment{Filename: "setup.EXE", Content: []byte("MZ")})},
+		{name: "invalid content type", request: request(Attachment{Filename: "a.pdf", Content: []byte("A"), ContentType: "pdf;"})},
+		{name: "inline document", request: request(Attachment{Filename: "a.pdf", Content: []byte("A"), ContentID: "a"})},
+		{name: "inline without HTML", request: EmailRequest{To: []string{"a@example.com"}, TextBody: "A", Attachments: []Attachment{{Filename: "a.png", Content: []byte("A"), ContentID: "a"}}}},
+		{name: "too large", request: request(Attachment{Filename: "a.pdf", Content: make([]byte, constants.EmailMaxAttachmentsSize+1)})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newMIMEMessage(constants.EmailProviderSES, from, tt.store, tt.request)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMIMEMessage_StoredAttachmentTooLarge(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@example.com"}
+	store := memoryStore{"a.pdf": make([]byte, constants.EmailMaxAttachmentsSize)}
+	message, err := newMIMEMessage(constants.EmailProviderSES, from, store, EmailRequest{
+		To:       []string{"a@example.com"},
+		TextBody: "A",
+		Attachments: []Attachment{
+			{Filename: "b.pdf", Content: []byte("B")},
+			{Filename: "a.pdf", StorageKey: "a.pdf"},
+		},
+	})
+	require.NoError(t, err)
+
+	err = message.write(context.Background(), io.Discard)
+
+	assert.Error(t, err)
+}
+
+func mustDecodeBase64(t *testing.T, encoded []byte) []byte {
+	t.Helper()
+	decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(encoded)))
+	require.NoError(t, err)
+	return decoded
+}
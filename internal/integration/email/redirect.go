@@ -0,0 +1,59 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang-boilerplate/internal/errors"
+)
+
+// RedirectSender sends every email to a catch-all address instead of its recipients, so
+// that a staging environment sends real emails without reaching the users. The original
+// recipients are named in the subject.
+type RedirectSender struct {
+	sender     EmailSender
+	redirectTo string
+}
+
+// NewRedirectSender wraps sender with the redirection of the emails to redirectTo
+func NewRedirectSender(sender EmailSender, redirectTo string) *RedirectSender {
+	return &RedirectSender{sender: sender, redirectTo: redirectTo}
+}
+
+func (s *RedirectSender) SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error) {
+	return s.sender.SendEmail(ctx, s.redirect(message))
+}
+
+// SendRawEmail refuses the raw messages, whose recipients are in headers it cannot
+// rewrite
+func (s *RedirectSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	return nil, errors.ValidationError("Raw emails cannot be redirected", nil).
+		WithOperation("send_raw_email").
+		WithResource("email").
+		WithContext("redirect_to", s.redirectTo)
+}
+
+func (s *RedirectSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	redirected := make([]EmailRequest, len(messages))
+	for i, message := range messages {
+		redirected[i] = s.redirect(message)
+	}
+	return s.sender.SendBulkEmail(ctx, redirected)
+}
+
+// Ping checks the wrapped sender
+func (s *RedirectSender) Ping(ctx context.Context) error {
+	return s.sender.Ping(ctx)
+}
+
+// redirect returns the message sent to the catch-all address only, its subject prefixed
+// with the original recipients
+func (s *RedirectSender) redirect(message EmailRequest) EmailRequest {
+	recipients := append(append(append([]string{}, message.To...), message.Cc...), message.Bcc...)
+	message.Subject = fmt.Sprintf("[%s] %s", strings.Join(recipients, ", "), message.Subject)
+	message.To = []string{s.redirectTo}
+	message.Cc = nil
+	message.Bcc = nil
+	return message
+}
@@ -0,0 +1,40 @@
+package email
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectSender(t *testing.T) {
+	next := &recordingSender{}
+	sender := NewRedirectSender(next, "qa@example.com")
+	message := EmailRequest{
+		To:       []string{"jane@example.com"},
+		Cc:       []string{"john@example.com"},
+		Bcc:      []string{"audit@example.com"},
+		Subject:  "Welcome",
+		HTMLBody: "<p>Hello Jane</p>",
+	}
+
+	_, err := sender.SendEmail(context.Background(), message)
+	require.NoError(t, err)
+	_, err = sender.SendBulkEmail(context.Background(), []EmailRequest{message})
+	require.NoError(t, err)
+
+	require.Len(t, next.batches, 2)
+	for _, batch := range next.batches {
+		require.Len(t, batch, 1)
+		assert.Equal(t, []string{"qa@example.com"}, batch[0].To)
+		assert.Empty(t, batch[0].Cc)
+		assert.Empty(t, batch[0].Bcc)
+		assert.Equal(t, "[jane@example.com, john@example.com, audit@example.com] Welcome", batch[0].Subject)
+		assert.Equal(t, message.HTMLBody, batch[0].HTMLBody)
+	}
+	assert.Equal(t, []string{"jane@example.com"}, message.To, "the message of the caller is left as is")
+
+	_, err = sender.SendRawEmail(context.Background(), []byte("To: jane@example.com\r\n\r\nHello"))
+	assert.Error(t, err)
+}
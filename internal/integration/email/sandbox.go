@@ -0,0 +1,65 @@
+package email
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/sandbox"
+)
+
+// SandboxSender captures the emails sent in a sandbox tenant into its inbox instead of
+// sending them, and sends the other emails with the wrapped sender
+type SandboxSender struct {
+	sender EmailSender
+	inbox  Inbox
+}
+
+// NewSandboxSender wraps sender with the capture of the sandbox emails
+func NewSandboxSender(sender EmailSender, inbox Inbox) *SandboxSender {
+	return &SandboxSender{sender: sender, inbox: inbox}
+}
+
+func (s *SandboxSender) SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error) {
+	companyID, ok := sandbox.CompanyID(ctx)
+	if !ok {
+		return s.sender.SendEmail(ctx, message)
+	}
+
+	return s.capture(companyID, message)
+}
+
+// SendRawEmail captures the raw MIME message as the text body of the email
+func (s *SandboxSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	companyID, ok := sandbox.CompanyID(ctx)
+	if !ok {
+		return s.sender.SendRawEmail(ctx, rawData)
+	}
+
+	return s.capture(companyID, EmailRequest{TextBody: string(rawData)})
+}
+
+func (s *SandboxSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	companyID, ok := sandbox.CompanyID(ctx)
+	if !ok {
+		return s.sender.SendBulkEmail(ctx, messages)
+	}
+
+	responses := make([]EmailResponse, len(messages))
+	for i, message := range messages {
+		response, err := s.capture(companyID, message)
+		if err != nil {
+			return responses, err
+		}
+		responses[i] = *response
+	}
+	return responses, nil
+}
+
+// Ping checks the wrapped sender
+func (s *SandboxSender) Ping(ctx context.Context) error {
+	return s.sender.Ping(ctx)
+}
+
+func (s *SandboxSender) capture(companyID string, message EmailRequest) (*EmailResponse, error) {
+	return captureEmail(s.inbox, constants.SandboxProvider, &companyID, message)
+}
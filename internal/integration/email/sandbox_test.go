@@ -0,0 +1,88 @@
+package email
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/sandbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryInbox stores the captured emails in memory
+type memoryInbox struct {
+	emails []*models.CapturedEmail
+}
+
+func (i *memoryInbox) Create(email *models.CapturedEmail) error {
+	i.emails = append(i.emails, email)
+	return nil
+}
+
+func TestSandboxSender_SendEmail(t *testing.T) {
+	message := EmailRequest{
+		To:           []string{"jane@example.com"},
+		Subject:      "Welcome",
+		HTMLBody:     "<p>Hello Jane</p>",
+		TemplateData: map[string]interface{}{"name": "Jane"},
+		Attachments:  []Attachment{{Filename: "invoice.pdf", Content: []byte("%PDF")}},
+	}
+
+	t.Run("sandbox emails are captured", func(t *testing.T) {
+		next := &recordingSender{}
+		inbox := &memoryInbox{}
+		sender := NewSandboxSender(next, inbox)
+
+		response, err := sender.SendEmail(sandbox.WithCompany(context.Background(), "sandbox-1"), message)
+
+		require.NoError(t, err)
+		assert.Empty(t, next.batches, "nothing is sent")
+		require.Len(t, inbox.emails, 1)
+		captured := inbox.emails[0]
+		require.NotNil(t, captured.CompanyID)
+		assert.Equal(t, "sandbox-1", *captured.CompanyID)
+		assert.Equal(t, message.To, captured.To)
+		assert.Equal(t, message.HTMLBody, captured.HTMLBody)
+		assert.JSONEq(t, `{"name":"Jane"}`, string(captured.TemplateData))
+		assert.Equal(t, []string{"invoice.pdf"}, captured.Attachments)
+		assert.Equal(t, captured.ID, response.MessageID)
+		assert.Equal(t, constants.SandboxProvider, response.Provider)
+		assert.Equal(t, constants.SandboxEmailStatusCaptured, response.Status)
+	})
+
+	t.Run("other emails are sent", func(t *testing.T) {
+		next := &recordingSender{}
+		inbox := &memoryInbox{}
+		sender := NewSandboxSender(next, inbox)
+
+		response, err := sender.SendEmail(context.Background(), message)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sent", response.Status)
+		assert.Len(t, next.batches, 1)
+		assert.Empty(t, inbox.emails)
+	})
+}
+
+func TestSandboxSender_SendBulkEmail(t *testing.T) {
+	next := &recordingSender{}
+	inbox := &memoryInbox{}
+	sender := NewSandboxSender(next, inbox)
+
+	responses, err := sender.SendBulkEmail(sandbox.WithCompany(context.Background(), "sandbox-1"), []EmailRequest{
+		{To: []string{"a@example.com"}, Subject: "Digest"},
+		{To: []string{"b@example.com"}, Subject: "Digest"},
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, next.batches)
+	require.Len(t, inbox.emails, 2)
+	require.Len(t, responses, 2)
+	for i, response := range responses {
+		assert.Equal(t, inbox.emails[i].ID, response.MessageID)
+		assert.Equal(t, constants.SandboxEmailStatusCaptured, response.Status)
+	}
+}
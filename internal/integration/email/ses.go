@@ -1,10 +1,16 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
-	"golang-boilerplate/internal/monitoring"
 
 	"golang-boilerplate/internal/logger"
 
@@ -13,16 +19,28 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/aws/aws-sdk-go-v2/service/ses/types"
-
-	"github.com/getsentry/sentry-go"
 )
 
+// SESSender sends the emails through SES as raw MIME messages, so that they can carry
+// attachments and inline images, from the EMAIL_FROM sender
 type SESSender struct {
 	client *ses.Client
 	config config.Config
+	from   *mail.Address
+	store  AttachmentStore
 }
 
-func NewSESSender(config config.Config) (*SESSender, error) {
+// NewSESSender creates a sender of the AWS_SES_* settings, streaming the stored
+// attachments from the store
+func NewSESSender(config config.Config, store AttachmentStore) (*SESSender, error) {
+	from, err := mail.ParseAddress(config.EmailFrom)
+	if err != nil {
+		return nil, errors.ValidationError("Invalid SES sender", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("initialize_email_sender").
+			WithResource("ses")
+	}
+
 	// Load AWS config
 	cfg, err := awsconfig.LoadDefaultConfig(
 		context.TODO(),
@@ -30,17 +48,6 @@ func NewSESSender(config config.Config) (*SESSender, error) {
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(config.AWSSESAccessKey, config.AWSSESSecretKey, "")),
 	)
 	if err != nil {
-		if hub := monitoring.GetSentryHub(context.TODO()); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "ses")
-				scope.SetTag("operation", "send_email")
-				scope.SetExtra("step", "error")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("config", config)
-				hub.CaptureException(errors.ExternalServiceError("failed to load AWS config", err))
-			})
-		}
-
 		logger.Sugar.Errorw("Failed to load AWS config",
 			"service", "ses",
 			"operation", "send_email",
@@ -49,6 +56,7 @@ func NewSESSender(config config.Config) (*SESSender, error) {
 		)
 
 		return nil, errors.ExternalServiceError("Failed to load AWS config", err).
+			WithProvider(constants.EmailProviderSES).
 			WithOperation("load_aws_config").
 			WithResource("ses")
 	}
@@ -59,86 +67,44 @@ func NewSESSender(config config.Config) (*SESSender, error) {
 	return &SESSender{
 		client: client,
 		config: config,
+		from:   from,
+		store:  store,
 	}, nil
 }
 
+// SendEmail builds the MIME message of the request and sends it with SendRawEmail, to the
+// To, Cc and Bcc recipients. The stored attachments are streamed into the message, which
+// must stay under the SES message size.
 func (s *SESSender) SendEmail(ctx context.Context, request EmailRequest) (*EmailResponse, error) {
-	// Build the email content
-	var body *types.Body
-
-	switch {
-	case request.HTMLBody != "":
-		body = &types.Body{
-			Html: &types.Content{
-				Data:    aws.String(request.HTMLBody),
-				Charset: aws.String("UTF-8"),
-			},
-		}
-		if request.TextBody != "" {
-			body.Text = &types.Content{
-				Data:    aws.String(request.TextBody),
-				Charset: aws.String("UTF-8"),
-			}
-		}
-	case request.TextBody != "":
-		body = &types.Body{
-			Text: &types.Content{
-				Data:    aws.String(request.TextBody),
-				Charset: aws.String("UTF-8"),
-			},
-		}
-	default:
-		return nil, errors.ExternalServiceError("either HTML or text content must be provided", nil).
-			WithOperation("send_email").
-			WithResource("ses")
-	}
-
-	// Build the message
-	message := &types.Message{
-		Subject: &types.Content{
-			Data:    aws.String(request.Subject),
-			Charset: aws.String("UTF-8"),
-		},
-		Body: body,
-	}
-
-	// Build the destination
-	destination := &types.Destination{
-		ToAddresses: request.To,
+	message, err := newMIMEMessage(constants.EmailProviderSES, s.from, s.store, request)
+	if err != nil {
+		return nil, err
 	}
-	if len(request.Cc) > 0 {
-		destination.CcAddresses = request.Cc
+	var data bytes.Buffer
+	if err := message.write(ctx, &data); err != nil {
+		return nil, err
 	}
-	if len(request.Bcc) > 0 {
-		destination.BccAddresses = request.Bcc
+	if data.Len() > constants.SESMaxMessageSize {
+		return nil, errors.ValidationError("Email is too large", fmt.Errorf("the message is %d bytes, over the SES limit of %d", data.Len(), constants.SESMaxMessageSize)).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("send_email").
+			WithResource("ses")
 	}
 
-	// Build the input
-	input := &ses.SendEmailInput{
-		Source:      aws.String(s.config.AWSSESAccessKey),
-		Destination: destination,
-		Message:     message,
+	input := &ses.SendRawEmailInput{
+		Source:       aws.String(s.from.String()),
+		Destinations: message.recipients,
+		RawMessage:   &types.RawMessage{Data: data.Bytes()},
 	}
 
 	// Send the email
-	result, err := s.client.SendEmail(ctx, input)
+	result, err := s.client.SendRawEmail(ctx, input)
 	if err != nil {
-		if hub := monitoring.GetSentryHub(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "ses")
-				scope.SetTag("operation", "send_email")
-				scope.SetExtra("step", "error")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("recipients", request.To)
-				scope.SetExtra("subject", request.Subject)
-				hub.CaptureException(errors.ExternalServiceError("failed to send email via SES", err))
-			})
-		}
-
 		logger.Sugar.Errorw("Failed to send email via SES",
 			"service", "ses",
 			"operation", "send_email",
-			"request", request,
+			"recipients", len(message.recipients),
+			"subject", request.Subject,
 			"error", err.Error(),
 		)
 
@@ -147,6 +113,7 @@ func (s *SESSender) SendEmail(ctx context.Context, request EmailRequest) (*Email
 				Status:   "failed",
 				Error:    err.Error(),
 			}, errors.ExternalServiceError("Failed to send email via SES", err).
+				WithProvider(constants.EmailProviderSES).
 				WithOperation("send_email").
 				WithResource("ses")
 	}
@@ -173,6 +140,7 @@ func (s *SESSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailRes
 				Status:   "failed",
 				Error:    err.Error(),
 			}, errors.ExternalServiceError("Failed to send raw email via SES", err).
+				WithProvider(constants.EmailProviderSES).
 				WithOperation("send_raw_email").
 				WithResource("ses")
 	}
@@ -183,3 +151,128 @@ func (s *SESSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailRes
 		Status:    "sent",
 	}, nil
 }
+
+// Ping reads the SES send quota, which needs valid credentials
+func (s *SESSender) Ping(ctx context.Context) error {
+	if _, err := s.client.GetSendQuota(ctx, &ses.GetSendQuotaInput{}); err != nil {
+		return errors.ExternalServiceError("Failed to reach SES", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("ping_email").
+			WithResource("email")
+	}
+	return nil
+}
+
+// MaxSendRate returns the account send rate in recipients per second from the SES quota.
+// It falls back to the sandbox rate when the quota cannot be read.
+func (s *SESSender) MaxSendRate(ctx context.Context) int {
+	quota, err := s.client.GetSendQuota(ctx, &ses.GetSendQuotaInput{})
+	if err != nil || quota.MaxSendRate < 1 {
+		logger.Sugar.Warnw("Failed to read SES send quota, using sandbox send rate",
+			"service", "ses",
+			"operation", "get_send_quota",
+			"send_rate", constants.SESSandboxSendRate,
+			"error", err,
+		)
+		return constants.SESSandboxSendRate
+	}
+	return int(math.Floor(quota.MaxSendRate))
+}
+
+// SendBulkEmail sends templated messages that share a template and have no Cc/Bcc or
+// attachments through SendBulkTemplatedEmail, up to 50 destinations per call. Other
+// messages are sent one by one.
+func (s *SESSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	responses := make([]EmailResponse, len(messages))
+
+	templates := make(map[string][]int)
+	var templateOrder []string
+	for i, message := range messages {
+		if message.TemplateID == "" || len(message.Cc) > 0 || len(message.Bcc) > 0 || len(message.Attachments) > 0 {
+			response, err := s.SendEmail(ctx, message)
+			if response != nil {
+				responses[i] = *response
+			}
+			if err != nil {
+				return responses, err
+			}
+			continue
+		}
+		if _, ok := templates[message.TemplateID]; !ok {
+			templateOrder = append(templateOrder, message.TemplateID)
+		}
+		templates[message.TemplateID] = append(templates[message.TemplateID], i)
+	}
+
+	for _, templateID := range templateOrder {
+		indexes := templates[templateID]
+		for start := 0; start < len(indexes); start += constants.SESMaxBulkDestinations {
+			batch := indexes[start:min(start+constants.SESMaxBulkDestinations, len(indexes))]
+			if err := s.sendBulkTemplated(ctx, templateID, messages, batch, responses); err != nil {
+				return responses, err
+			}
+		}
+	}
+
+	return responses, nil
+}
+
+// sendBulkTemplated sends one SendBulkTemplatedEmail call for the messages at indexes
+// and records the per-destination status in responses
+func (s *SESSender) sendBulkTemplated(ctx context.Context, templateID string, messages []EmailRequest, indexes []int, responses []EmailResponse) error {
+	destinations := make([]types.BulkEmailDestination, 0, len(indexes))
+	for _, i := range indexes {
+		templateData, err := json.Marshal(messages[i].TemplateData)
+		if err != nil {
+			return errors.ValidationError("Invalid email template data", err).
+				WithOperation("send_bulk_email").
+				WithResource("ses").
+				WithContext("template_id", templateID)
+		}
+		destinations = append(destinations, types.BulkEmailDestination{
+			Destination:             &types.Destination{ToAddresses: messages[i].To},
+			ReplacementTemplateData: aws.String(string(templateData)),
+		})
+	}
+
+	result, err := s.client.SendBulkTemplatedEmail(ctx, &ses.SendBulkTemplatedEmailInput{
+		Source:              aws.String(s.config.AWSSESAccessKey),
+		Template:            aws.String(templateID),
+		DefaultTemplateData: aws.String("{}"),
+		Destinations:        destinations,
+	})
+	if err != nil {
+		logger.Sugar.Errorw("Failed to send bulk email via SES",
+			"service", "ses",
+			"operation", "send_bulk_email",
+			"template_id", templateID,
+			"destinations", len(destinations),
+			"error", err.Error(),
+		)
+
+		return errors.ExternalServiceError("Failed to send bulk email via SES", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("send_bulk_email").
+			WithResource("ses").
+			WithContext("template_id", templateID)
+	}
+
+	for j, i := range indexes {
+		response := EmailResponse{Provider: "ses", Status: "failed"}
+		if j < len(result.Status) {
+			status := result.Status[j]
+			response.MessageID = aws.ToString(status.MessageId)
+			if status.Status == types.BulkEmailStatusSuccess {
+				response.Status = "sent"
+			} else {
+				response.Error = string(status.Status)
+				if status.Error != nil {
+					response.Error = *status.Error
+				}
+			}
+		}
+		responses[i] = response
+	}
+
+	return nil
+}
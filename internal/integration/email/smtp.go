@@ -0,0 +1,340 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+)
+
+// SMTPSender sends the emails through an SMTP server, e.g. MailHog in development or the
+// relay of a self-hosted deployment. Every send opens its own connection; a bulk send
+// delivers all its messages on one.
+type SMTPSender struct {
+	store    AttachmentStore
+	addr     string
+	host     string
+	from     *mail.Address
+	username string
+	password string
+	tlsMode  string
+	timeout  time.Duration
+}
+
+// smtpConnection is an open and authenticated connection to the SMTP server
+type smtpConnection struct {
+	conn    net.Conn
+	client  *smtp.Client
+	timeout time.Duration
+}
+
+// NewSMTPSender creates a sender of the SMTP_* settings, streaming the stored attachments
+// from the store
+func NewSMTPSender(config config.Config, store AttachmentStore) (*SMTPSender, error) {
+	from, err := mail.ParseAddress(config.SMTPFrom)
+	if err != nil {
+		return nil, errors.ValidationError("Invalid SMTP sender", err).
+			WithProvider(constants.EmailProviderSMTP).
+			WithOperation("initialize_email_sender").
+			WithResource("smtp")
+	}
+
+	return &SMTPSender{
+		store:    store,
+		addr:     net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort)),
+		host:     config.SMTPHost,
+		from:     from,
+		username: config.SMTPUsername,
+		password: config.SMTPPassword,
+		tlsMode:  config.SMTPTLS,
+		timeout:  config.SMTPTimeout,
+	}, nil
+}
+
+func (s *SMTPSender) SendEmail(ctx context.Context, request EmailRequest) (*EmailResponse, error) {
+	connection, err := s.connect(ctx)
+	if err != nil {
+		return s.failed(err, "send_email", request)
+	}
+	defer connection.close()
+
+	return s.send(ctx, connection, request)
+}
+
+// SendRawEmail sends a MIME message, without its Bcc header, to the recipients of its To,
+// Cc and Bcc headers and from its From header, the configured sender by default
+func (s *SMTPSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	message, err := mail.ReadMessage(bytes.NewReader(rawData))
+	if err != nil {
+		return nil, errors.ValidationError("Invalid raw email", err).
+			WithProvider(constants.EmailProviderSMTP).
+			WithOperation("send_raw_email").
+			WithResource("smtp")
+	}
+
+	from := s.from
+	if message.Header.Get("From") != "" {
+		if from, err = mail.ParseAddress(message.Header.Get("From")); err != nil {
+			return nil, errors.ValidationError("Invalid raw email sender", err).
+				WithProvider(constants.EmailProviderSMTP).
+				WithOperation("send_raw_email").
+				WithResource("smtp")
+		}
+	}
+	var recipients []string
+	for _, header := range []string{"To", "Cc", "Bcc"} {
+		addresses, err := message.Header.AddressList(header)
+		if err != nil && err != mail.ErrHeaderNotPresent {
+			return nil, errors.ValidationError("Invalid raw email recipients", err).
+				WithProvider(constants.EmailProviderSMTP).
+				WithOperation("send_raw_email").
+				WithResource("smtp").
+				WithContext("header", header)
+		}
+		for _, address := range addresses {
+			recipients = append(recipients, address.Address)
+		}
+	}
+
+	connection, err := s.connect(ctx)
+	if err == nil {
+		defer connection.close()
+		err = connection.deliver(ctx, from.Address, recipients, func(w io.Writer) error {
+			_, err := w.Write(withoutBcc(rawData))
+			return err
+		})
+	}
+	if err != nil {
+		return &EmailResponse{
+			Provider: constants.EmailProviderSMTP,
+			Status:   "failed",
+			Error:    err.Error(),
+		}, errors.ExternalServiceError("Failed to send raw email via SMTP", err).
+			WithProvider(constants.EmailProviderSMTP).
+			WithOperation("send_raw_email").
+			WithResource("smtp")
+	}
+
+	return &EmailResponse{
+		MessageID: strings.Trim(message.Header.Get("Message-Id"), "<>"),
+		Provider:  constants.EmailProviderSMTP,
+		Status:    "sent",
+	}, nil
+}
+
+// SendBulkEmail sends the messages one by one on a single connection
+func (s *SMTPSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	responses := make([]EmailResponse, len(messages))
+	if len(messages) == 0 {
+		return responses, nil
+	}
+
+	connection, err := s.connect(ctx)
+	if err != nil {
+		response, err := s.failed(err, "send_bulk_email", messages[0])
+		responses[0] = *response
+		return responses, err
+	}
+	defer connection.close()
+
+	for i, message := range messages {
+		response, err := s.send(ctx, connection, message)
+		if response != nil {
+			responses[i] = *response
+		}
+		if err != nil {
+			return responses, err
+		}
+	}
+
+	return responses, nil
+}
+
+// Ping opens an authenticated connection, which needs a reachable server and valid
+// credentials
+func (s *SMTPSender) Ping(ctx context.Context) error {
+	connection, err := s.connect(ctx)
+	if err != nil {
+		return errors.ExternalServiceError("Failed to reach SMTP server", err).
+			WithProvider(constants.EmailProviderSMTP).
+			WithOperation("ping_email").
+			WithResource("email")
+	}
+	connection.close()
+	return nil
+}
+
+// send builds the message of the request and delivers it on the connection, its stored
+// attachments streamed into the DATA command
+func (s *SMTPSender) send(ctx context.Context, connection *smtpConnection, request EmailRequest) (*EmailResponse, error) {
+	message, err := newMIMEMessage(constants.EmailProviderSMTP, s.from, s.store, request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = connection.deliver(ctx, s.from.Address, message.recipients, func(w io.Writer) error {
+		return message.write(ctx, w)
+	})
+	if err != nil {
+		return s.failed(err, "send_email", request)
+	}
+
+	return &EmailResponse{
+		MessageID: message.messageID,
+		Provider:  constants.EmailProviderSMTP,
+		Status:    "sent",
+	}, nil
+}
+
+// failed logs a failed send and returns its response and error
+func (s *SMTPSender) failed(err error, operation string, request EmailRequest) (*EmailResponse, error) {
+	logger.Sugar.Errorw("Failed to send email via SMTP",
+		"service", "smtp",
+		"operation", operation,
+		"server", s.addr,
+		"recipients", len(request.To)+len(request.Cc)+len(request.Bcc),
+		"subject", request.Subject,
+		"error", err.Error(),
+	)
+
+	return &EmailResponse{
+		Provider: constants.EmailProviderSMTP,
+		Status:   "failed",
+		Error:    err.Error(),
+	}, errors.ExternalServiceError("Failed to send email via SMTP", err).
+		WithProvider(constants.EmailProviderSMTP).
+		WithOperation(operation).
+		WithResource("smtp")
+}
+
+// connect dials the server, upgrades the connection to TLS and authenticates, as
+// configured. Every exchange must complete within the timeout, or the context deadline.
+func (s *SMTPSender) connect(ctx context.Context) (*smtpConnection, error) {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	tlsConfig := &tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if s.tlsMode == constants.SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	connection := &smtpConnection{conn: conn, timeout: s.timeout}
+	connection.extendDeadline(ctx)
+	if connection.client, err = smtp.NewClient(conn, s.host); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.tlsMode == constants.SMTPTLSStartTLS {
+		if ok, _ := connection.client.Extension("STARTTLS"); !ok {
+			connection.close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", s.addr)
+		}
+		if err := connection.client.StartTLS(tlsConfig); err != nil {
+			connection.close()
+			return nil, err
+		}
+	}
+
+	if s.username != "" {
+		if err := connection.client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			connection.close()
+			return nil, err
+		}
+	}
+
+	return connection, nil
+}
+
+// extendDeadline gives the next exchange the timeout, bounded by the context deadline
+func (c *smtpConnection) extendDeadline(ctx context.Context) {
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	c.conn.SetDeadline(deadline)
+}
+
+// deliver runs one mail transaction, its message written by write, reset on failure so
+// that the connection can carry the next one
+func (c *smtpConnection) deliver(ctx context.Context, from string, recipients []string, write func(io.Writer) error) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	c.extendDeadline(ctx)
+
+	err := c.transaction(from, recipients, write)
+	if err != nil {
+		c.client.Reset()
+	}
+	return err
+}
+
+func (c *smtpConnection) transaction(from string, recipients []string, write func(io.Writer) error) error {
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := c.client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if err := write(writer); err != nil {
+		// Ending the DATA command would send the partial message, drop the connection
+		c.conn.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// close quits the session, or drops the connection when the server does not answer
+func (c *smtpConnection) close() {
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+// withoutBcc removes the Bcc header, and its folded lines, from the headers of a raw
+// message, so that the recipients do not see each other
+func withoutBcc(rawData []byte) []byte {
+	var message bytes.Buffer
+	inHeaders, inBcc := true, false
+	for _, line := range bytes.SplitAfter(rawData, []byte("\n")) {
+		if inHeaders {
+			if len(bytes.TrimRight(line, "\r\n")) == 0 {
+				inHeaders = false
+			} else if line[0] == ' ' || line[0] == '\t' {
+				if inBcc {
+					continue
+				}
+			} else if inBcc = len(line) >= 4 && strings.EqualFold(string(line[:4]), "bcc:"); inBcc {
+				continue
+			}
+		}
+		message.Write(line)
+	}
+	return message.Bytes()
+}
@@ -0,0 +1,265 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// smtpTransaction is a mail transaction received by the fake SMTP server
+type smtpTransaction struct {
+	from       string
+	recipients []string
+	data       []byte
+}
+
+// fakeSMTPServer accepts the mail transactions of plain SMTP sessions, like MailHog
+type fakeSMTPServer struct {
+	listener     net.Listener
+	mu           sync.Mutex
+	connections  int
+	transactions []smtpTransaction
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP")
+	var current smtpTransaction
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case command == "EHLO" || command == "HELO":
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			current = smtpTransaction{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			current.recipients = append(current.recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			current.data = data
+			s.mu.Lock()
+			s.transactions = append(s.transactions, current)
+			s.mu.Unlock()
+			text.PrintfLine("250 Queued")
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) config(tlsMode string) config.Config {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return config.Config{
+		SMTPHost:    host,
+		SMTPPort:    portNumber,
+		SMTPFrom:    "App <no-reply@example.com>",
+		SMTPTLS:     tlsMode,
+		SMTPTimeout: 5 * time.Second,
+	}
+}
+
+func (s *fakeSMTPServer) received() ([]smtpTransaction, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.transactions, s.connections
+}
+
+func TestSMTPSender_SendEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSNone), nil)
+	require.NoError(t, err)
+
+	response, err := sender.SendEmail(context.Background(), EmailRequest{
+		To:       []string{"John Doe <john@example.com>"},
+		Cc:       []string{"jane@example.com"},
+		Bcc:      []string{"audit@example.com"},
+		Subject:  "Welcome é",
+		TextBody: "Hello John",
+		HTMLBody: "<p>Hello John</p>",
+		Attachments: []Attachment{
+			{Filename: "terms.pdf", Content: []byte("%PDF-1.4"), ContentType: "application/pdf"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, constants.EmailProviderSMTP, response.Provider)
+	assert.Equal(t, "sent", response.Status)
+	assert.True(t, strings.HasSuffix(response.MessageID, "@example.com"))
+
+	transactions, _ := server.received()
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "no-reply@example.com", transactions[0].from)
+	assert.Equal(t, []string{"john@example.com", "jane@example.com", "audit@example.com"}, transactions[0].recipients)
+
+	message, err := mail.ReadMessage(bytes.NewReader(transactions[0].data))
+	require.NoError(t, err)
+	assert.Equal(t, `"App" <no-reply@example.com>`, message.Header.Get("From"))
+	assert.Equal(t, `"John Doe" <john@example.com>`, message.Header.Get("To"))
+	assert.Equal(t, "<jane@example.com>", message.Header.Get("Cc"))
+	assert.Empty(t, message.Header.Get("Bcc"))
+	assert.Equal(t, "<"+response.MessageID+">", message.Header.Get("Message-Id"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome é", subject)
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+	mixed := multipart.NewReader(message.Body, params["boundary"])
+
+	body, err := mixed.NextPart()
+	require.NoError(t, err)
+	mediaType, params, err = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	alternatives := multipart.NewReader(body, params["boundary"])
+	for _, expected := range []string{"Hello John", "<p>Hello John</p>"} {
+		part, err := alternatives.NextPart()
+		require.NoError(t, err)
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+
+	attachment, err := mixed.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "terms.pdf", attachment.FileName())
+	assert.Equal(t, "application/pdf", attachment.Header.Get("Content-Type"))
+}
+
+func TestSMTPSender_SendBulkEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSNone), nil)
+	require.NoError(t, err)
+
+	responses, err := sender.SendBulkEmail(context.Background(), []EmailRequest{
+		{To: []string{"a@example.com"}, Subject: "Digest", TextBody: "A"},
+		{To: []string{"b@example.com"}, Subject: "Digest", TextBody: "B"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+	assert.Equal(t, "sent", responses[1].Status)
+	transactions, connections := server.received()
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, 1, connections)
+}
+
+func TestSMTPSender_SendRawEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSNone), nil)
+	require.NoError(t, err)
+	raw := []byte("From: billing@example.com\r\nTo: a@example.com, b@example.com\r\nBcc: c@example.com\r\nMessage-ID: <42@example.com>\r\nSubject: Invoice\r\n\r\nPaid\r\n")
+
+	response, err := sender.SendRawEmail(context.Background(), raw)
+
+	require.NoError(t, err)
+	assert.Equal(t, "42@example.com", response.MessageID)
+	transactions, _ := server.received()
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "billing@example.com", transactions[0].from)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, transactions[0].recipients)
+	assert.Equal(t, "From: billing@example.com\nTo: a@example.com, b@example.com\nMessage-ID: <42@example.com>\nSubject: Invoice\n\nPaid\n", string(transactions[0].data))
+}
+
+func TestSMTPSender_StartTLSRequired(t *testing.T) {
+	logger.Sugar = zap.NewNop().Sugar()
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSStartTLS), nil)
+	require.NoError(t, err)
+
+	response, err := sender.SendEmail(context.Background(), EmailRequest{To: []string{"a@example.com"}, TextBody: "A"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support STARTTLS")
+	assert.Equal(t, "failed", response.Status)
+	transactions, _ := server.received()
+	assert.Empty(t, transactions)
+	assert.Error(t, sender.Ping(context.Background()))
+}
+
+func TestSMTPSender_EmptyBody(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSNone), nil)
+	require.NoError(t, err)
+
+	_, err = sender.SendEmail(context.Background(), EmailRequest{To: []string{"a@example.com"}, Subject: "Empty"})
+
+	assert.Error(t, err)
+	transactions, _ := server.received()
+	assert.Empty(t, transactions)
+}
+
+func TestSMTPSender_StoredAttachmentUnreadable(t *testing.T) {
+	logger.Sugar = zap.NewNop().Sugar()
+	server := newFakeSMTPServer(t)
+	sender, err := NewSMTPSender(server.config(constants.SMTPTLSNone), memoryStore{})
+	require.NoError(t, err)
+
+	_, err = sender.SendEmail(context.Background(), EmailRequest{
+		To:          []string{"a@example.com"},
+		TextBody:    "Your report",
+		Attachments: []Attachment{{Filename: "report.pdf", StorageKey: "reports/missing.pdf"}},
+	})
+
+	// The connection is dropped rather than completing a message without its attachment
+	assert.Error(t, err)
+	transactions, _ := server.received()
+	assert.Empty(t, transactions)
+}
@@ -0,0 +1,248 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// snsHostPattern matches the hosts of SNS, the only ones the signing certificates are
+// downloaded from and the subscriptions confirmed on
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSVerifier authenticates the messages of the SNS topic of the SES notifications
+type SNSVerifier interface {
+	// Verify checks that the message was published to the configured topic and signed by
+	// SNS
+	Verify(ctx context.Context, message *dtos.SNSMessage) error
+	// ConfirmSubscription confirms the subscription of a verified SubscriptionConfirmation
+	// message by visiting its SubscribeURL
+	ConfirmSubscription(ctx context.Context, message *dtos.SNSMessage) error
+}
+
+// snsVerifier verifies the signatures with the certificates of SNS, downloaded once per
+// normalized URL and kept in a bounded LRU
+type snsVerifier struct {
+	topicARN     string
+	client       *http.Client
+	certificates *lru.Cache[string, *x509.Certificate]
+}
+
+// ProvideSNSVerifier creates the verifier of the messages of SES_NOTIFICATIONS_TOPIC_ARN
+func ProvideSNSVerifier(config config.Config) SNSVerifier {
+	// lru.New only fails on a non-positive size
+	certificates, _ := lru.New[string, *x509.Certificate](constants.SNSCertificateCacheSize)
+	return &snsVerifier{
+		topicARN:     config.SESNotificationsTopicARN,
+		client:       &http.Client{Timeout: constants.SNSCertificateTimeout},
+		certificates: certificates,
+	}
+}
+
+func (v *snsVerifier) Verify(ctx context.Context, message *dtos.SNSMessage) error {
+	if v.topicARN == "" || message.TopicArn != v.topicARN {
+		return errors.UnauthorizedError("Unexpected SNS topic", nil).
+			WithOperation("verify_sns_message").
+			WithResource("email").
+			WithContext("topic_arn", message.TopicArn)
+	}
+
+	var hash crypto.Hash
+	switch message.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return errors.UnauthorizedError("Unsupported SNS signature version", nil).
+			WithOperation("verify_sns_message").
+			WithResource("email").
+			WithContext("signature_version", message.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return errors.UnauthorizedError("Invalid SNS message signature", err).
+			WithOperation("verify_sns_message").
+			WithResource("email")
+	}
+
+	certificate, err := v.certificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	now := time.Now()
+	if !ok || now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
+		return errors.UnauthorizedError("Invalid SNS signing certificate", nil).
+			WithOperation("verify_sns_message").
+			WithResource("email").
+			WithContext("signing_cert_url", message.SigningCertURL)
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(snsStringToSign(message)))
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest.Sum(nil), signature); err != nil {
+		return errors.UnauthorizedError("Invalid SNS message signature", err).
+			WithOperation("verify_sns_message").
+			WithResource("email").
+			WithContext("message_id", message.MessageID)
+	}
+
+	return nil
+}
+
+func (v *snsVerifier) ConfirmSubscription(ctx context.Context, message *dtos.SNSMessage) error {
+	subscribeURL, err := snsURL(message.SubscribeURL)
+	if err != nil {
+		return errors.ValidationError("Invalid SNS subscribe URL", err).
+			WithOperation("confirm_sns_subscription").
+			WithResource("email")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return errors.InternalError("Failed to create SNS subscription confirmation", err).
+			WithOperation("confirm_sns_subscription").
+			WithResource("email")
+	}
+	response, err := v.client.Do(request)
+	if err == nil {
+		_ = response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status %d", response.StatusCode)
+		}
+	}
+	if err != nil {
+		return errors.ExternalServiceError("Failed to confirm SNS subscription", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("confirm_sns_subscription").
+			WithResource("email").
+			WithContext("topic_arn", message.TopicArn)
+	}
+
+	return nil
+}
+
+// certificate returns the signing certificate of rawURL, downloaded from SNS the first
+// time its normalized URL is seen
+func (v *snsVerifier) certificate(ctx context.Context, rawURL string) (*x509.Certificate, error) {
+	certificateURL, err := snsURL(rawURL)
+	if err == nil && !strings.HasSuffix(certificateURL, ".pem") {
+		err = fmt.Errorf("not a certificate")
+	}
+	if err != nil {
+		return nil, errors.UnauthorizedError("Invalid SNS signing certificate URL", err).
+			WithOperation("get_sns_certificate").
+			WithResource("email").
+			WithContext("signing_cert_url", rawURL)
+	}
+
+	if certificate, ok := v.certificates.Get(certificateURL); ok {
+		return certificate, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, certificateURL, nil)
+	if err != nil {
+		return nil, errors.InternalError("Failed to create SNS signing certificate request", err).
+			WithOperation("get_sns_certificate").
+			WithResource("email")
+	}
+	response, err := v.client.Do(request)
+	if err != nil {
+		return nil, errors.ExternalServiceError("Failed to get SNS signing certificate", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("get_sns_certificate").
+			WithResource("email").
+			WithContext("signing_cert_url", rawURL)
+	}
+	defer response.Body.Close()
+
+	var certificate *x509.Certificate
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if err == nil && response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	if err == nil {
+		certificate, err = parseCertificate(body)
+	}
+	if err != nil {
+		return nil, errors.ExternalServiceError("Failed to get SNS signing certificate", err).
+			WithProvider(constants.EmailProviderSES).
+			WithOperation("get_sns_certificate").
+			WithResource("email").
+			WithContext("signing_cert_url", rawURL)
+	}
+
+	v.certificates.Add(certificateURL, certificate)
+
+	return certificate, nil
+}
+
+// parseCertificate parses a PEM encoded certificate
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// snsURL returns the normalized form of rawURL, with a lower case host, without the
+// default port and the fragment, when it is an HTTPS URL of SNS
+func snsURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if parsed.Scheme != "https" || parsed.User != nil || (parsed.Port() != "" && parsed.Port() != "443") || !snsHostPattern.MatchString(host) {
+		return "", fmt.Errorf("not an SNS URL: %s", parsed.Redacted())
+	}
+	normalized := url.URL{Scheme: "https", Host: host, Path: parsed.Path, RawPath: parsed.RawPath, RawQuery: parsed.RawQuery}
+	return normalized.String(), nil
+}
+
+// snsStringToSign returns the string SNS signs for a message: the names and values of its
+// fields, in that order, each on its own line
+func snsStringToSign(message *dtos.SNSMessage) string {
+	fields := [][2]string{{"Message", message.Message}, {"MessageId", message.MessageID}}
+	if message.Type == constants.SNSMessageTypeNotification {
+		if message.Subject != "" {
+			fields = append(fields, [2]string{"Subject", message.Subject})
+		}
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", message.SubscribeURL})
+	}
+	fields = append(fields, [2]string{"Timestamp", message.Timestamp})
+	if message.Type != constants.SNSMessageTypeNotification {
+		fields = append(fields, [2]string{"Token", message.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", message.TopicArn}, [2]string{"Type", message.Type})
+
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(field[0])
+		builder.WriteString("\n")
+		builder.WriteString(field[1])
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
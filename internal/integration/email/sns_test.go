@@ -0,0 +1,201 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopicARN = "arn:aws:sns:ap-southeast-1:123456789012:ses-notifications"
+	testCertURL  = "https://sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-test.pem"
+)
+
+// newTestSNSVerifier returns a verifier holding the certificate of testCertURL, and the
+// key signing the messages
+func newTestSNSVerifier(t *testing.T) (*snsVerifier, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	verifier := ProvideSNSVerifier(config.Config{SESNotificationsTopicARN: testTopicARN}).(*snsVerifier)
+	verifier.certificates.Add(testCertURL, certificate)
+	return verifier, key
+}
+
+// roundTripFunc serves the requests of an HTTP client with a function
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// signSNSMessage signs the message as SNS does
+func signSNSMessage(t *testing.T, key *rsa.PrivateKey, message *dtos.SNSMessage) {
+	t.Helper()
+	hash := crypto.SHA1
+	if message.SignatureVersion == "2" {
+		hash = crypto.SHA256
+	}
+	digest := hash.New()
+	digest.Write([]byte(snsStringToSign(message)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(nil))
+	require.NoError(t, err)
+	message.Signature = base64.StdEncoding.EncodeToString(signature)
+}
+
+func TestSNSVerifier_Verify(t *testing.T) {
+	verifier, key := newTestSNSVerifier(t)
+	notification := func() *dtos.SNSMessage {
+		return &dtos.SNSMessage{
+			Type:             constants.SNSMessageTypeNotification,
+			MessageID:        "message-1",
+			TopicArn:         testTopicARN,
+			Message:          `{"notificationType":"Bounce"}`,
+			Timestamp:        "2026-01-01T00:00:00.000Z",
+			SignatureVersion: "1",
+			SigningCertURL:   testCertURL,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		message       func() *dtos.SNSMessage
+		tamper        func(*dtos.SNSMessage)
+		expectedError bool
+	}{
+		{name: "notification signed with SHA1", message: notification},
+		{
+			name: "subscription confirmation signed with SHA256",
+			message: func() *dtos.SNSMessage {
+				message := notification()
+				message.Type = constants.SNSMessageTypeSubscriptionConfirmation
+				message.Token = "token"
+				message.SubscribeURL = "https://sns.ap-southeast-1.amazonaws.com/?Action=ConfirmSubscription&Token=token"
+				message.SignatureVersion = "2"
+				return message
+			},
+		},
+		{
+			name:          "tampered message",
+			message:       notification,
+			tamper:        func(message *dtos.SNSMessage) { message.Message = `{"notificationType":"Complaint"}` },
+			expectedError: true,
+		},
+		{
+			name:          "other topic",
+			message:       notification,
+			tamper:        func(message *dtos.SNSMessage) { message.TopicArn = "arn:aws:sns:ap-southeast-1:999999999999:other" },
+			expectedError: true,
+		},
+		{
+			name:          "certificate outside SNS",
+			message:       notification,
+			tamper:        func(message *dtos.SNSMessage) { message.SigningCertURL = "https://sns.example.com/cert.pem" },
+			expectedError: true,
+		},
+		{
+			name:          "unknown signature version",
+			message:       notification,
+			tamper:        func(message *dtos.SNSMessage) { message.SignatureVersion = "3" },
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.message()
+			signSNSMessage(t, key, message)
+			if tt.tamper != nil {
+				tt.tamper(message)
+			}
+
+			err := verifier.Verify(context.Background(), message)
+
+			assert.Equal(t, tt.expectedError, err != nil, err)
+		})
+	}
+}
+
+func TestSNSVerifier_ConfirmSubscriptionOutsideSNS(t *testing.T) {
+	verifier, _ := newTestSNSVerifier(t)
+
+	err := verifier.ConfirmSubscription(context.Background(), &dtos.SNSMessage{
+		SubscribeURL: "https://attacker.example.com/?Action=ConfirmSubscription",
+	})
+
+	assert.Error(t, err)
+}
+
+func TestSNSVerifier_Certificate(t *testing.T) {
+	verifier, _ := newTestSNSVerifier(t)
+	certificate, _ := verifier.certificates.Get(testCertURL)
+	body := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+	var downloaded []string
+	verifier.client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		downloaded = append(downloaded, request.URL.String())
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Request: request}, nil
+	})}
+	ctx := context.Background()
+
+	// The spellings of the URL of a known certificate share its entry
+	for _, rawURL := range []string{
+		"https://SNS.ap-southeast-1.amazonaws.com/SimpleNotificationService-test.pem",
+		"https://sns.ap-southeast-1.amazonaws.com:443/SimpleNotificationService-test.pem",
+		"https://sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-test.pem#other",
+	} {
+		_, err := verifier.certificate(ctx, rawURL)
+		require.NoError(t, err)
+	}
+	assert.Empty(t, downloaded)
+
+	for _, rawURL := range []string{
+		"https://user@sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-test.pem",
+		"https://sns.ap-southeast-1.amazonaws.com:8443/SimpleNotificationService-test.pem",
+	} {
+		_, err := verifier.certificate(ctx, rawURL)
+		assert.Error(t, err, rawURL)
+	}
+
+	// The certificates of other URLs are downloaded once, and the oldest are evicted past
+	// the bound
+	for i := range constants.SNSCertificateCacheSize + 1 {
+		rawURL := fmt.Sprintf("https://sns.ap-southeast-1.amazonaws.com/SimpleNotificationService-%d.pem", i)
+		for range 2 {
+			_, err := verifier.certificate(ctx, rawURL)
+			require.NoError(t, err)
+		}
+	}
+	assert.Len(t, downloaded, constants.SNSCertificateCacheSize+1)
+	assert.Equal(t, constants.SNSCertificateCacheSize, verifier.certificates.Len())
+	assert.False(t, verifier.certificates.Contains(testCertURL), "the least recently used certificate is evicted")
+}
@@ -0,0 +1,34 @@
+package templates
+
+import "time"
+
+// Welcome is the email welcoming a new user
+type Welcome struct {
+	UserName string
+}
+
+func (Welcome) Template() string { return "welcome" }
+
+// PasswordReset is the email with the link resetting the password of a user
+type PasswordReset struct {
+	ResetURL string
+}
+
+func (PasswordReset) Template() string { return "password_reset" }
+
+// Invitation is the email inviting a person to join a company
+type Invitation struct {
+	CompanyName string
+	AcceptURL   string
+	ExpiresAt   time.Time
+}
+
+func (Invitation) Template() string { return "invitation" }
+
+// Notification is a free-form notification, its message shown as one paragraph
+type Notification struct {
+	Subject string
+	Message string
+}
+
+func (Notification) Template() string { return "notification" }
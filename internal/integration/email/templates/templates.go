@@ -0,0 +1,147 @@
+// Package templates renders the emails from the templates embedded in the binary. Every
+// email has a text and an HTML template, rendered inside the shared layouts with its
+// typed data: html/template escapes the data of the HTML body, text/template leaves the
+// text body and the subject as is.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+)
+
+//go:embed *.tmpl
+var files embed.FS
+
+// Layouts of the emails, rendering the content template of each email
+const (
+	htmlLayout = "layout.html.tmpl"
+	textLayout = "layout.txt.tmpl"
+)
+
+// Brand is the product the emails are sent for, shown by the layouts
+type Brand struct {
+	ProductName string
+	// BaseURL links the footer to the application, when set
+	BaseURL string
+}
+
+// Email is the typed data of an email, naming its template
+type Email interface {
+	Template() string
+}
+
+// Rendered is a rendered email
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// view is what the templates render: the brand as .App and the email data as .Data
+type view struct {
+	App  Brand
+	Data Email
+}
+
+// template is an email template: the subject and content of its text template, and the
+// content of its HTML template, each set with its layout
+type template struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Registry holds the email templates, parsed once at startup
+type Registry struct {
+	brand     Brand
+	templates map[string]template
+}
+
+// funcs are the functions of the templates
+var funcs = map[string]any{
+	// date formats a time as a day, e.g. January 2, 2006, in UTC
+	"date": func(t time.Time) string {
+		return t.UTC().Format("January 2, 2006")
+	},
+}
+
+// ProvideRegistry parses the email templates, branded with EMAIL_PRODUCT_NAME and
+// APP_BASE_URL
+func ProvideRegistry(config config.Config) (*Registry, error) {
+	return NewRegistry(Brand{ProductName: config.EmailProductName, BaseURL: config.AppBaseURL})
+}
+
+// NewRegistry parses the embedded templates: every <name>.txt.tmpl, defining the subject
+// and content templates, and its <name>.html.tmpl, defining the content template
+func NewRegistry(brand Brand) (*Registry, error) {
+	textFiles, err := fs.Glob(files, "*.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{brand: brand, templates: make(map[string]template)}
+	for _, textFile := range textFiles {
+		if textFile == textLayout {
+			continue
+		}
+		name := strings.TrimSuffix(textFile, ".txt.tmpl")
+
+		text, err := texttemplate.New(textLayout).Funcs(funcs).ParseFS(files, textLayout, textFile)
+		if err != nil {
+			return nil, fmt.Errorf("parse the text template of the %s email: %w", name, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("the text template of the %s email does not define its subject", name)
+		}
+		html, err := htmltemplate.New(htmlLayout).Funcs(funcs).ParseFS(files, htmlLayout, name+".html.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("parse the HTML template of the %s email: %w", name, err)
+		}
+
+		registry.templates[name] = template{html: html, text: text}
+	}
+
+	return registry, nil
+}
+
+// Render renders the subject and the bodies of an email
+func (r *Registry) Render(email Email) (*Rendered, error) {
+	tmpl, ok := r.templates[email.Template()]
+	if !ok {
+		return nil, errors.InternalError("Unknown email template", fmt.Errorf("no email template %q", email.Template())).
+			WithOperation("render_email").
+			WithResource("email").
+			WithContext("template", email.Template())
+	}
+
+	data := view{App: r.brand, Data: email}
+	var subject, text, html bytes.Buffer
+	err := tmpl.text.ExecuteTemplate(&subject, "subject", data)
+	if err == nil {
+		err = tmpl.text.Execute(&text, data)
+	}
+	if err == nil {
+		err = tmpl.html.Execute(&html, data)
+	}
+	if err != nil {
+		return nil, errors.InternalError("Failed to render email", err).
+			WithOperation("render_email").
+			WithResource("email").
+			WithContext("template", email.Template())
+	}
+
+	return &Rendered{
+		// The subject is a single header line
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
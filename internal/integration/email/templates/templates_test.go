@@ -0,0 +1,87 @@
+package templates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unknown is an email without template
+type unknown struct{}
+
+func (unknown) Template() string { return "unknown" }
+
+func TestRegistry_Render(t *testing.T) {
+	registry, err := NewRegistry(Brand{ProductName: "Acme", BaseURL: "https://app.example.com"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		email           Email
+		expectedSubject string
+		expectedText    []string
+		expectedHTML    []string
+	}{
+		{
+			name:            "welcome",
+			email:           Welcome{UserName: "John Doe"},
+			expectedSubject: "Welcome to Acme!",
+			expectedText:    []string{"Hello John Doe,", "Welcome to Acme!"},
+			expectedHTML:    []string{"<p>Hello John Doe,</p>"},
+		},
+		{
+			name:            "password reset",
+			email:           PasswordReset{ResetURL: "https://app.example.com/reset-password?token=abc&x=1"},
+			expectedSubject: "Password Reset Request",
+			expectedText:    []string{"https://app.example.com/reset-password?token=abc&x=1"},
+			expectedHTML:    []string{`<a href="https://app.example.com/reset-password?token=abc&amp;x=1">`},
+		},
+		{
+			name: "invitation, data escaped in the HTML only",
+			email: Invitation{
+				CompanyName: "Acme <Labs>",
+				AcceptURL:   "https://app.example.com/invitations/accept?token=abc",
+				ExpiresAt:   time.Date(2026, 3, 9, 23, 30, 0, 0, time.FixedZone("", -2*3600)),
+			},
+			expectedSubject: "You are invited to join Acme <Labs>",
+			expectedText:    []string{"You are invited to join Acme <Labs>.", "expires on March 10, 2026."},
+			expectedHTML:    []string{"<h2>You are invited to join Acme &lt;Labs&gt;</h2>", "expires on March 10, 2026."},
+		},
+		{
+			name:            "notification, subject on one line",
+			email:           Notification{Subject: "Scheduled\r\nBcc: x@example.com", Message: "<script>alert(1)</script>"},
+			expectedSubject: "Scheduled Bcc: x@example.com",
+			expectedText:    []string{"<script>alert(1)</script>"},
+			expectedHTML:    []string{"<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := registry.Render(tt.email)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, rendered.Subject)
+			for _, expected := range tt.expectedText {
+				assert.Contains(t, rendered.Text, expected)
+			}
+			for _, expected := range tt.expectedHTML {
+				assert.Contains(t, rendered.HTML, expected)
+			}
+			// The layouts wrap every email
+			assert.Contains(t, rendered.Text, "--\nAcme\nhttps://app.example.com")
+			assert.Contains(t, rendered.HTML, `<a href="https://app.example.com" style="color: #71717a;">Acme</a>`)
+		})
+	}
+}
+
+func TestRegistry_RenderUnknownTemplate(t *testing.T) {
+	registry, err := NewRegistry(Brand{ProductName: "Acme"})
+	require.NoError(t, err)
+
+	_, err = registry.Render(unknown{})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package email
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+
+	"golang.org/x/time/rate"
+)
+
+// Priority selects the pacing lane of an outbound email
+type Priority int
+
+const (
+	// PriorityTransactional is for emails triggered by a user action (welcome, password reset)
+	PriorityTransactional Priority = iota
+	// PriorityBulk is for campaigns and digests; it only uses the share of the send rate
+	// left over by transactional traffic
+	PriorityBulk
+)
+
+type priorityContextKey struct{}
+
+// WithPriority returns a context whose emails are paced in the given lane
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, priority)
+}
+
+// PriorityFromContext returns the lane set by WithPriority, defaulting to transactional
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityContextKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityTransactional
+}
+
+// ThrottleConfig configures a ThrottledSender
+type ThrottleConfig struct {
+	// SendRate is the provider send rate in recipients per second
+	SendRate int
+	// Burst is the number of recipients that may be sent at once; defaults to SendRate
+	Burst int
+	// BulkPercent is the share of SendRate bulk traffic may use (1-100)
+	BulkPercent int
+	// BatchSize is the number of messages handed to the provider per bulk call
+	BatchSize int
+}
+
+// ThrottledSender paces an EmailSender to the provider send rate so that bulk campaigns
+// don't trigger provider throttling. Every recipient consumes one token of a shared
+// limiter. Bulk traffic is additionally capped by its own limiter and yields to
+// transactional sends that are waiting for a token, so that welcome and password reset
+// emails are not queued behind a campaign.
+type ThrottledSender struct {
+	sender    EmailSender
+	limiter   *rate.Limiter
+	bulk      *rate.Limiter
+	batchSize int
+	yield     time.Duration
+	// pending counts transactional sends waiting on the shared limiter
+	pending atomic.Int64
+}
+
+// NewThrottledSender wraps sender with send-rate throttling
+func NewThrottledSender(sender EmailSender, cfg ThrottleConfig) *ThrottledSender {
+	if cfg.SendRate <= 0 {
+		cfg.SendRate = 1
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = cfg.SendRate
+	}
+	if cfg.BulkPercent <= 0 || cfg.BulkPercent > 100 {
+		cfg.BulkPercent = 100
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+
+	bulkRate := rate.Limit(float64(cfg.SendRate) * float64(cfg.BulkPercent) / 100)
+	bulkBurst := max(cfg.Burst*cfg.BulkPercent/100, 1)
+
+	return &ThrottledSender{
+		sender:    sender,
+		limiter:   rate.NewLimiter(rate.Limit(cfg.SendRate), cfg.Burst),
+		bulk:      rate.NewLimiter(bulkRate, bulkBurst),
+		batchSize: cfg.BatchSize,
+		yield:     time.Second / time.Duration(cfg.SendRate),
+	}
+}
+
+// SendEmail sends a single message in the lane carried by ctx
+func (s *ThrottledSender) SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error) {
+	if err := s.wait(ctx, PriorityFromContext(ctx), recipientCount(message)); err != nil {
+		return nil, err
+	}
+	return s.sender.SendEmail(ctx, message)
+}
+
+// SendRawEmail sends a raw message in the lane carried by ctx. The recipients of a raw
+// message are not known, so it is paced as a single recipient.
+func (s *ThrottledSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	if err := s.wait(ctx, PriorityFromContext(ctx), 1); err != nil {
+		return nil, err
+	}
+	return s.sender.SendRawEmail(ctx, rawData)
+}
+
+// Ping checks the wrapped sender without waiting for the send rate
+func (s *ThrottledSender) Ping(ctx context.Context) error {
+	return s.sender.Ping(ctx)
+}
+
+// SendBulkEmail sends messages in the bulk lane, BatchSize messages per provider call
+func (s *ThrottledSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	responses := make([]EmailResponse, len(messages))
+
+	for start := 0; start < len(messages); start += s.batchSize {
+		end := min(start+s.batchSize, len(messages))
+		batch := messages[start:end]
+
+		recipients := 0
+		for _, message := range batch {
+			recipients += recipientCount(message)
+		}
+		if err := s.wait(ctx, PriorityBulk, recipients); err != nil {
+			return responses, err
+		}
+
+		batchResponses, err := s.sender.SendBulkEmail(ctx, batch)
+		copy(responses[start:end], batchResponses)
+		if err != nil {
+			return responses, err
+		}
+	}
+
+	return responses, nil
+}
+
+// wait blocks until recipients tokens are available in the given lane
+func (s *ThrottledSender) wait(ctx context.Context, priority Priority, recipients int) error {
+	if priority == PriorityBulk {
+		if err := waitN(ctx, s.bulk, recipients); err != nil {
+			return throttleError(err)
+		}
+		for s.pending.Load() > 0 {
+			select {
+			case <-ctx.Done():
+				return throttleError(ctx.Err())
+			case <-time.After(s.yield):
+			}
+		}
+	} else {
+		s.pending.Add(1)
+		defer s.pending.Add(-1)
+	}
+
+	if err := waitN(ctx, s.limiter, recipients); err != nil {
+		return throttleError(err)
+	}
+	return nil
+}
+
+// waitN waits for n tokens, in steps of at most the limiter burst
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	for n > 0 {
+		step := min(n, limiter.Burst())
+		if err := limiter.WaitN(ctx, step); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
+func throttleError(err error) *errors.AppError {
+	return errors.TimeoutError("Timed out waiting for the email send rate limit", err).
+		WithOperation("throttle_email").
+		WithResource("email")
+}
+
+// recipientCount returns the number of recipients a provider bills a message for
+func recipientCount(message EmailRequest) int {
+	return max(len(message.To)+len(message.Cc)+len(message.Bcc), 1)
+}
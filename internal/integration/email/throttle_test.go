@@ -0,0 +1,97 @@
+package email
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSender records the messages it is asked to send
+type recordingSender struct {
+	mu      sync.Mutex
+	batches [][]EmailRequest
+}
+
+func (r *recordingSender) SendEmail(ctx context.Context, message EmailRequest) (*EmailResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, []EmailRequest{message})
+	return &EmailResponse{Provider: "test", Status: "sent"}, nil
+}
+
+func (r *recordingSender) SendRawEmail(ctx context.Context, rawData []byte) (*EmailResponse, error) {
+	return &EmailResponse{Provider: "test", Status: "sent"}, nil
+}
+
+func (r *recordingSender) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *recordingSender) SendBulkEmail(ctx context.Context, messages []EmailRequest) ([]EmailResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, messages)
+	responses := make([]EmailResponse, len(messages))
+	for i := range responses {
+		responses[i] = EmailResponse{Provider: "test", Status: "sent"}
+	}
+	return responses, nil
+}
+
+func TestThrottledSender_SendBulkEmailBatches(t *testing.T) {
+	recorder := &recordingSender{}
+	sender := NewThrottledSender(recorder, ThrottleConfig{SendRate: 1000, BatchSize: 2})
+
+	messages := []EmailRequest{
+		{To: []string{"a@example.com"}},
+		{To: []string{"b@example.com"}},
+		{To: []string{"c@example.com"}},
+	}
+
+	responses, err := sender.SendBulkEmail(context.Background(), messages)
+
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+	require.Len(t, recorder.batches, 2)
+	assert.Len(t, recorder.batches[0], 2)
+	assert.Len(t, recorder.batches[1], 1)
+	for _, response := range responses {
+		assert.Equal(t, "sent", response.Status)
+	}
+}
+
+func TestThrottledSender_PacesRecipients(t *testing.T) {
+	sender := NewThrottledSender(&recordingSender{}, ThrottleConfig{SendRate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := sender.SendEmail(context.Background(), EmailRequest{To: []string{"a@example.com"}})
+		require.NoError(t, err)
+	}
+
+	// The first send uses the burst token, the next two wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestThrottledSender_WaitHonoursContext(t *testing.T) {
+	sender := NewThrottledSender(&recordingSender{}, ThrottleConfig{SendRate: 1, Burst: 1})
+
+	_, err := sender.SendEmail(context.Background(), EmailRequest{To: []string{"a@example.com"}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = sender.SendBulkEmail(ctx, []EmailRequest{{To: []string{"b@example.com"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "email send rate limit")
+}
+
+func TestPriorityFromContext(t *testing.T) {
+	assert.Equal(t, PriorityTransactional, PriorityFromContext(context.Background()))
+	assert.Equal(t, PriorityBulk, PriorityFromContext(WithPriority(context.Background(), PriorityBulk)))
+}
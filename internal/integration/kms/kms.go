@@ -0,0 +1,72 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+)
+
+// DataKey is a fresh data encryption key. Plaintext encrypts the data and must never be
+// stored; Encrypted is the same key wrapped by the master key, stored next to the data.
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Encrypted []byte
+}
+
+// KeyManager issues and unwraps data keys for envelope encryption
+type KeyManager interface {
+	// GenerateDataKey returns a new 256-bit data key
+	GenerateDataKey(ctx context.Context) (*DataKey, error)
+	// Decrypt unwraps an encrypted data key returned by GenerateDataKey
+	Decrypt(ctx context.Context, encryptedKey []byte) ([]byte, error)
+}
+
+func ProvideKeyManager(cfg *config.Config) (KeyManager, error) {
+	switch cfg.KMSProvider {
+	case "":
+		return disabledKeyManager{}, nil
+	case constants.KMSProviderAWS:
+		awsKMS, err := NewAWSKeyManager(cfg)
+		if err != nil {
+			return nil, errors.ExternalServiceError("Failed to initialize AWS KMS key manager", err).
+				WithOperation("initialize_key_manager").
+				WithResource("kms")
+		}
+		return awsKMS, nil
+	case constants.KMSProviderLocal:
+		localKMS, err := NewLocalKeyManager(cfg.KMSLocalMasterKey)
+		if err != nil {
+			return nil, errors.InternalError("Failed to initialize local key manager", err).
+				WithOperation("initialize_key_manager").
+				WithResource("kms")
+		}
+		return localKMS, nil
+	default:
+		return nil, errors.InternalError("Invalid KMS provider", fmt.Errorf("invalid KMS provider: %s", cfg.KMSProvider)).
+			WithOperation("initialize_key_manager").
+			WithResource("kms").
+			WithContext("kms_provider", cfg.KMSProvider)
+	}
+}
+
+// disabledKeyManager is used when no KMS provider is configured, so that the server still
+// starts and only the credentials vault reports that it is unavailable
+type disabledKeyManager struct{}
+
+func (disabledKeyManager) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	return nil, errVaultDisabled("generate_data_key")
+}
+
+func (disabledKeyManager) Decrypt(ctx context.Context, encryptedKey []byte) ([]byte, error) {
+	return nil, errVaultDisabled("decrypt_data_key")
+}
+
+func errVaultDisabled(operation string) error {
+	return errors.InternalError("Credentials vault is not configured", fmt.Errorf("KMS_PROVIDER is not set")).
+		WithOperation(operation).
+		WithResource("kms")
+}
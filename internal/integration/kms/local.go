@@ -0,0 +1,86 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+)
+
+// LocalKeyManager wraps data keys with a static master key held in configuration.
+// It is meant for development and tests, config.Load rejects it in production.
+type LocalKeyManager struct {
+	master cipher.AEAD
+}
+
+// NewLocalKeyManager creates a key manager from a base64 encoded 32 byte master key
+func NewLocalKeyManager(masterKey string) (*LocalKeyManager, error) {
+	key, err := base64.StdEncoding.DecodeString(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode local master key: %w", err)
+	}
+	if len(key) != constants.VaultDataKeySize {
+		return nil, fmt.Errorf("local master key must be %d bytes, got %d", constants.VaultDataKeySize, len(key))
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalKeyManager{master: aead}, nil
+}
+
+func (m *LocalKeyManager) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	plaintext := make([]byte, constants.VaultDataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, errors.InternalError("Failed to generate data key", err).
+			WithOperation("generate_data_key").
+			WithResource("kms")
+	}
+
+	nonce := make([]byte, m.master.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.InternalError("Failed to generate data key", err).
+			WithOperation("generate_data_key").
+			WithResource("kms")
+	}
+
+	return &DataKey{
+		KeyID:     constants.KMSProviderLocal,
+		Plaintext: plaintext,
+		// The wrapped key is the nonce followed by the sealed key
+		Encrypted: m.master.Seal(nonce, nonce, plaintext, nil),
+	}, nil
+}
+
+func (m *LocalKeyManager) Decrypt(ctx context.Context, encryptedKey []byte) ([]byte, error) {
+	nonceSize := m.master.NonceSize()
+	if len(encryptedKey) < nonceSize {
+		return nil, errors.InternalError("Failed to decrypt data key", fmt.Errorf("encrypted key is too short")).
+			WithOperation("decrypt_data_key").
+			WithResource("kms")
+	}
+
+	plaintext, err := m.master.Open(nil, encryptedKey[:nonceSize], encryptedKey[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.InternalError("Failed to decrypt data key", err).
+			WithOperation("decrypt_data_key").
+			WithResource("kms")
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,124 @@
+// Package messaging publishes and consumes the messages of a broker. Services depend on
+// the Publisher and Consumer interfaces; the broker is selected with MESSAGING_PROVIDER.
+package messaging
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/shutdown"
+)
+
+// Message is a message published to a topic. ID and Timestamp are set on publish when
+// empty.
+type Message struct {
+	ID        string
+	Topic     string
+	Body      []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// Handler handles a consumed message. The message is acknowledged when it returns nil.
+// An error is retried by redelivering the message once, an error wrapped by
+// retry.Permanent rejects it at once; rejected messages go to the dead-letter exchange.
+type Handler func(ctx context.Context, msg Message) error
+
+// Subscription selects the messages of a consumer
+type Subscription struct {
+	// Queue is the durable queue shared by the consumers of the subscription, each
+	// message is handled by one of them
+	Queue string
+	// Topics are the topics bound to the queue
+	Topics []string
+}
+
+// Publisher publishes messages to the broker
+type Publisher interface {
+	// Publish sends msg to topic and returns once the broker has confirmed it
+	Publish(ctx context.Context, topic string, msg Message) error
+}
+
+// Consumer consumes messages from the broker
+type Consumer interface {
+	// Consume declares the queue of sub and passes its messages to handler until ctx is
+	// done or the consumer is closed, subscribing again when the connection is lost
+	Consume(ctx context.Context, sub Subscription, handler Handler) error
+}
+
+// Broker is the connection to the message broker
+type Broker interface {
+	Publisher
+	Consumer
+	// Close stops the consumers, waits for the messages they handle until ctx is done,
+	// then closes the connection
+	Close(ctx context.Context) error
+}
+
+// ProvideBroker connects to the broker of MESSAGING_PROVIDER and closes the connection on
+// shutdown
+func ProvideBroker(cfg *config.Config, sequence *shutdown.Sequence) (Broker, error) {
+	var broker Broker
+	switch cfg.MessagingProvider {
+	case "":
+		return disabledBroker{}, nil
+	case constants.MessagingProviderRabbitMQ:
+		rabbitMQ, err := NewRabbitMQ(cfg)
+		if err != nil {
+			return nil, errors.ExternalServiceError("Failed to initialize RabbitMQ broker", err).
+				WithOperation("initialize_broker").
+				WithResource("messaging")
+		}
+		broker = rabbitMQ
+	default:
+		return nil, errors.InternalError("Invalid messaging provider", fmt.Errorf("invalid messaging provider: %s", cfg.MessagingProvider)).
+			WithOperation("initialize_broker").
+			WithResource("messaging").
+			WithContext("messaging_provider", cfg.MessagingProvider)
+	}
+
+	sequence.Register(shutdown.PhaseConsumers, shutdown.ComponentMessaging, constants.MessagingCloseTimeout, broker.Close)
+
+	return broker, nil
+}
+
+// ProvidePublisher exposes the broker to the services publishing messages
+func ProvidePublisher(broker Broker) Publisher {
+	return broker
+}
+
+// ProvideConsumer exposes the broker to the services consuming messages
+func ProvideConsumer(broker Broker) Consumer {
+	return broker
+}
+
+// ErrDisabled is wrapped by the errors of the broker used when no messaging provider is
+// configured, so that publishers can tell it from a failure
+var ErrDisabled = stderrors.New("MESSAGING_PROVIDER is not set")
+
+// disabledBroker is used when no messaging provider is configured, so that the server
+// still starts and only the features relying on the broker report that it is unavailable
+type disabledBroker struct{}
+
+func (disabledBroker) Publish(ctx context.Context, topic string, msg Message) error {
+	return errMessagingDisabled("publish_message").WithContext("topic", topic)
+}
+
+func (disabledBroker) Consume(ctx context.Context, sub Subscription, handler Handler) error {
+	return errMessagingDisabled("consume_messages").WithContext("queue", sub.Queue)
+}
+
+func (disabledBroker) Close(ctx context.Context) error {
+	return nil
+}
+
+func errMessagingDisabled(operation string) *errors.AppError {
+	return errors.InternalError("Message broker is not configured", ErrDisabled).
+		WithOperation(operation).
+		WithResource("messaging")
+}
@@ -0,0 +1,388 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// RabbitMQ publishes messages to an exchange, with the topic as routing key, and consumes
+// them from durable queues bound to the exchange. Messages are persistent, publishes wait
+// for the broker confirmation and consumers acknowledge each message once handled.
+type RabbitMQ struct {
+	url                string
+	exchange           string
+	deadLetterExchange string
+	prefetch           int
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	publish *amqp.Channel
+	closed  bool
+
+	// closing stops the consumers, handleCtx cancels the messages they handle
+	closing       context.Context
+	stopConsumers context.CancelFunc
+	handleCtx     context.Context
+	cancelHandle  context.CancelFunc
+	consumers     sync.WaitGroup
+}
+
+// NewRabbitMQ connects to RABBITMQ_URL, retrying while the broker is still starting up,
+// and declares the exchange and the dead-letter exchange
+func NewRabbitMQ(cfg *config.Config) (*RabbitMQ, error) {
+	closing, stopConsumers := context.WithCancel(context.Background())
+	handleCtx, cancelHandle := context.WithCancel(context.Background())
+	r := &RabbitMQ{
+		url:                cfg.RabbitMQURL,
+		exchange:           cfg.RabbitMQExchange,
+		deadLetterExchange: cfg.RabbitMQDeadLetterExchange,
+		prefetch:           cfg.RabbitMQPrefetch,
+		closing:            closing,
+		stopConsumers:      stopConsumers,
+		handleCtx:          handleCtx,
+		cancelHandle:       cancelHandle,
+	}
+
+	backoff := retry.Backoff{
+		InitialInterval: cfg.StartupRetryDelay,
+		MaxInterval:     cfg.StartupRetryMaxDelay,
+		Jitter:          retry.DefaultJitter,
+		MaxAttempts:     cfg.StartupRetryAttempts,
+		MaxElapsedTime:  cfg.StartupRetryMaxElapsed,
+	}
+	var conn *amqp.Connection
+	err := retry.Do(context.Background(), "connect_rabbitmq", backoff, func(ctx context.Context, attempt int) error {
+		var err error
+		conn, err = r.connection()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open RabbitMQ channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(r.exchange, cfg.RabbitMQExchangeType, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("declare exchange %s: %w", r.exchange, err)
+	}
+	if r.deadLetterExchange != "" {
+		if err := ch.ExchangeDeclare(r.deadLetterExchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("declare dead-letter exchange %s: %w", r.deadLetterExchange, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Publish implements Publisher, routing msg to the queues bound to topic
+func (r *RabbitMQ) Publish(ctx context.Context, topic string, msg Message) error {
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now().UTC()
+	}
+	headers := make(amqp.Table, len(msg.Headers))
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+
+	err := r.publishConfirmed(ctx, topic, amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID,
+		Timestamp:    msg.Timestamp,
+		Body:         msg.Body,
+	})
+	if err != nil {
+		return errors.ExternalServiceError("Failed to publish message", err).
+			WithProvider(constants.MessagingProviderRabbitMQ).
+			WithOperation("publish_message").
+			WithResource("messaging").
+			WithContext("topic", topic).
+			WithContext("message_id", msg.ID)
+	}
+
+	return nil
+}
+
+func (r *RabbitMQ) publishConfirmed(ctx context.Context, topic string, publishing amqp.Publishing) error {
+	ch, err := r.publishChannel()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, r.exchange, topic, false, false, publishing)
+	if err != nil {
+		return err
+	}
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("message not confirmed by the broker")
+	}
+
+	return nil
+}
+
+// Consume implements Consumer. The messages are handled concurrently, up to
+// RABBITMQ_PREFETCH at a time.
+func (r *RabbitMQ) Consume(ctx context.Context, sub Subscription, handler Handler) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return errors.InternalError("Message broker is closed", fmt.Errorf("consume after close")).
+			WithOperation("consume_messages").
+			WithResource("messaging").
+			WithContext("queue", sub.Queue)
+	}
+	r.consumers.Add(1)
+	r.mu.Unlock()
+	defer r.consumers.Done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(r.closing, cancel)()
+
+	ch, deliveries, err := r.subscribe(ctx, sub)
+	if err != nil {
+		return errors.ExternalServiceError("Failed to consume messages", err).
+			WithProvider(constants.MessagingProviderRabbitMQ).
+			WithOperation("consume_messages").
+			WithResource("messaging").
+			WithContext("queue", sub.Queue)
+	}
+
+	for {
+		var workers sync.WaitGroup
+		for range r.prefetch {
+			workers.Go(func() {
+				for delivery := range deliveries {
+					handleDelivery(r.handleCtx, delivery, handler)
+				}
+			})
+		}
+		workers.Wait()
+		ch.Close()
+
+		// The deliveries end when ctx is done or the connection is lost
+		for ctx.Err() == nil {
+			logger.Log.Warn("RabbitMQ consumer disconnected, subscribing again",
+				zap.String("queue", sub.Queue),
+				zap.Duration("retry_in", constants.MessagingReconnectDelay),
+			)
+			select {
+			case <-ctx.Done():
+			case <-time.After(constants.MessagingReconnectDelay):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			ch, deliveries, err = r.subscribe(ctx, sub)
+			if err == nil {
+				break
+			}
+			logger.Log.Warn("Failed to subscribe RabbitMQ consumer", zap.String("queue", sub.Queue), zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// subscribe declares the queue of sub, binds its topics and starts consuming it on a new
+// channel
+func (r *RabbitMQ) subscribe(ctx context.Context, sub Subscription) (*amqp.Channel, <-chan amqp.Delivery, error) {
+	conn, err := r.connection()
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("open channel: %w", err)
+	}
+
+	var args amqp.Table
+	if r.deadLetterExchange != "" {
+		args = amqp.Table{"x-dead-letter-exchange": r.deadLetterExchange}
+	}
+	if _, err := ch.QueueDeclare(sub.Queue, true, false, false, false, args); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("declare queue %s: %w", sub.Queue, err)
+	}
+	for _, topic := range sub.Topics {
+		if err := ch.QueueBind(sub.Queue, topic, r.exchange, false, nil); err != nil {
+			ch.Close()
+			return nil, nil, fmt.Errorf("bind queue %s to %s: %w", sub.Queue, topic, err)
+		}
+	}
+	if err := ch.Qos(r.prefetch, 0, false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("set prefetch: %w", err)
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, sub.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("consume queue %s: %w", sub.Queue, err)
+	}
+
+	return ch, deliveries, nil
+}
+
+// Close implements Broker. Messages still handled when ctx is done are canceled and
+// redelivered by the broker once the connection is closed.
+func (r *RabbitMQ) Close(ctx context.Context) error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.stopConsumers()
+
+	done := make(chan struct{})
+	go func() {
+		r.consumers.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	r.cancelHandle()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil && !r.conn.IsClosed() {
+		if closeErr := r.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// connection returns the connection to the broker, dialing it again when it was lost
+func (r *RabbitMQ) connection() (*amqp.Connection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.connectionLocked()
+}
+
+func (r *RabbitMQ) connectionLocked() (*amqp.Connection, error) {
+	if r.conn != nil && !r.conn.IsClosed() {
+		return r.conn, nil
+	}
+
+	conn, err := amqp.DialConfig(r.url, amqp.Config{Heartbeat: constants.MessagingHeartbeat})
+	if err != nil {
+		return nil, err
+	}
+	r.conn = conn
+
+	return conn, nil
+}
+
+// publishChannel returns the channel in confirm mode used by the publishes, opening it
+// again when it was closed by the broker
+func (r *RabbitMQ) publishChannel() (*amqp.Channel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.publish != nil && !r.publish.IsClosed() {
+		return r.publish, nil
+	}
+
+	conn, err := r.connectionLocked()
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("open channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("enable publisher confirms: %w", err)
+	}
+	r.publish = ch
+
+	return ch, nil
+}
+
+// handleDelivery passes a delivery to handler and acknowledges it once handled. A failed
+// message is requeued for a second attempt, then rejected, as are messages failing with
+// a permanent error or a panic.
+func handleDelivery(ctx context.Context, delivery amqp.Delivery, handler Handler) {
+	fields := []zap.Field{
+		zap.String("topic", delivery.RoutingKey),
+		zap.String("message_id", delivery.MessageId),
+		zap.Bool("redelivered", delivery.Redelivered),
+	}
+
+	err := callHandler(ctx, handler, newMessage(delivery))
+
+	var ackErr error
+	switch {
+	case err == nil:
+		ackErr = delivery.Ack(false)
+	case retry.IsPermanent(err) || delivery.Redelivered:
+		logger.Log.Warn("Message rejected", append(fields, zap.Error(err))...)
+		ackErr = delivery.Reject(false)
+	default:
+		logger.Log.Warn("Message failed, requeuing", append(fields, zap.Error(err))...)
+		ackErr = delivery.Nack(false, true)
+	}
+	if ackErr != nil {
+		logger.Log.Warn("Failed to acknowledge message", append(fields, zap.Error(ackErr))...)
+	}
+}
+
+func callHandler(ctx context.Context, handler Handler, msg Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = retry.Permanent(fmt.Errorf("message handler panicked: %v", r))
+		}
+	}()
+
+	return handler(ctx, msg)
+}
+
+func newMessage(delivery amqp.Delivery) Message {
+	headers := make(map[string]string, len(delivery.Headers))
+	for key, value := range delivery.Headers {
+		headers[key] = fmt.Sprint(value)
+	}
+
+	return Message{
+		ID:        delivery.MessageId,
+		Topic:     delivery.RoutingKey,
+		Body:      delivery.Body,
+		Headers:   headers,
+		Timestamp: delivery.Timestamp,
+	}
+}
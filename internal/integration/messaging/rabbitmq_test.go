@@ -0,0 +1,119 @@
+package messaging
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/retry"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// recordingAcknowledger records how a delivery was acknowledged
+type recordingAcknowledger struct {
+	outcome string
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.outcome = "ack"
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.outcome = "nack"
+	if requeue {
+		a.outcome = "requeue"
+	}
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.outcome = "reject"
+	return nil
+}
+
+func TestHandleDelivery(t *testing.T) {
+	errUnavailable := stderrors.New("connection refused")
+
+	tests := []struct {
+		name            string
+		redelivered     bool
+		handler         Handler
+		expectedOutcome string
+	}{
+		{
+			name:            "handled",
+			handler:         func(context.Context, Message) error { return nil },
+			expectedOutcome: "ack",
+		},
+		{
+			name:            "failure is requeued",
+			handler:         func(context.Context, Message) error { return errUnavailable },
+			expectedOutcome: "requeue",
+		},
+		{
+			name:            "second failure is rejected",
+			redelivered:     true,
+			handler:         func(context.Context, Message) error { return errUnavailable },
+			expectedOutcome: "reject",
+		},
+		{
+			name: "permanent error is rejected",
+			handler: func(context.Context, Message) error {
+				return retry.Permanent(stderrors.New("malformed payload"))
+			},
+			expectedOutcome: "reject",
+		},
+		{
+			name:            "panic is rejected",
+			handler:         func(context.Context, Message) error { panic("nil map") },
+			expectedOutcome: "reject",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acknowledger := &recordingAcknowledger{}
+			delivery := amqp.Delivery{
+				Acknowledger: acknowledger,
+				RoutingKey:   "user.created",
+				MessageId:    "message-1",
+				Redelivered:  tt.redelivered,
+			}
+
+			handleDelivery(context.Background(), delivery, tt.handler)
+
+			assert.Equal(t, tt.expectedOutcome, acknowledger.outcome)
+		})
+	}
+}
+
+func TestNewMessage(t *testing.T) {
+	timestamp := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	delivery := amqp.Delivery{
+		MessageId:  "message-1",
+		RoutingKey: "user.created",
+		Body:       []byte(`{"id":"1"}`),
+		Headers:    amqp.Table{"tenant": "acme", "attempt": int32(2)},
+		Timestamp:  timestamp,
+	}
+
+	assert.Equal(t, Message{
+		ID:        "message-1",
+		Topic:     "user.created",
+		Body:      []byte(`{"id":"1"}`),
+		Headers:   map[string]string{"tenant": "acme", "attempt": "2"},
+		Timestamp: timestamp,
+	}, newMessage(delivery))
+}
@@ -7,6 +7,7 @@ import (
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/monitoring"
 
 	"github.com/stripe/stripe-go/v82"
 )
@@ -18,8 +19,40 @@ type PaymentAdapter interface {
 	CreateCustomerPortalSession(ctx context.Context, customerID string) (*stripe.BillingPortalSession, error)
 	HandleWebhook(ctx context.Context, payload []byte, signature string) (stripe.Event, error)
 	CreateCustomer(ctx context.Context, email string, userID string) (*stripe.Customer, error)
+	// CreateSubscriptionCheckoutSession creates a checkout session subscribing a customer,
+	// or a new one when customerID is empty, to a price. The metadata is set on the
+	// subscription it creates.
+	CreateSubscriptionCheckoutSession(ctx context.Context, priceID string, customerID string, metadata map[string]string) (*stripe.CheckoutSession, error)
+	// UpdateSubscriptionPrice moves a subscription to another price, prorating the change,
+	// and replaces its metadata
+	UpdateSubscriptionPrice(ctx context.Context, subscriptionID string, priceID string, metadata map[string]string) (*stripe.Subscription, error)
+	// CancelSubscription cancels a subscription at once
+	CancelSubscription(ctx context.Context, subscriptionID string) (*stripe.Subscription, error)
+	// Refund refunds a payment intent: amount, in the smallest unit of its currency, when
+	// it is positive, or what was not refunded yet otherwise. Reason is empty or one of the
+	// refund reasons of Stripe.
+	Refund(ctx context.Context, paymentID string, amount int64, reason string) (*stripe.Refund, error)
+	// Capture captures an authorized payment intent: amount when it is positive, releasing
+	// the rest of the authorization, or all of it otherwise
+	Capture(ctx context.Context, paymentID string, amount int64) (*stripe.PaymentIntent, error)
+	// Void cancels an authorized payment intent that was not captured, releasing the
+	// authorization
+	Void(ctx context.Context, paymentID string) (*stripe.PaymentIntent, error)
+	// Ping checks that the provider is reachable with the configured credentials
+	Ping(ctx context.Context) error
 }
 
+// ProvideHealthChecker registers the payment provider in the health registry. Only the
+// checkouts depend on it, so an unreachable provider only degrades the server.
+func ProvideHealthChecker(adapter PaymentAdapter) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:  constants.DependencyPayment,
+		Check: adapter.Ping,
+	}
+}
+
+// ProvidePaymentAdapter creates the adapter of the configured provider. The payments of
+// the sandbox tenants are approved without calling it.
 func ProvidePaymentAdapter(config *config.Config) (PaymentAdapter, error) {
 	switch config.PaymentProvider {
 	case constants.PaymentProviderStripe:
@@ -29,7 +62,7 @@ func ProvidePaymentAdapter(config *config.Config) (PaymentAdapter, error) {
 				WithOperation("initialize_payment_adapter").
 				WithResource("payment")
 		}
-		return stripeAdapter, nil
+		return NewSandboxAdapter(stripeAdapter, config), nil
 	default:
 		return nil, errors.InternalError("Invalid payment provider", fmt.Errorf("invalid payment provider: %s", config.PaymentProvider)).
 			WithOperation("initialize_payment_adapter").
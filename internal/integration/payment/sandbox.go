@@ -0,0 +1,233 @@
+package payment
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/sandbox"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v82"
+)
+
+// SandboxAdapter approves the payments of the sandbox tenants without calling the
+// provider: checkout sessions are created complete and paid, refunds, captures and voids
+// succeed, and customers and portal sessions are fakes. Other payments go to the wrapped adapter. The IDs of the fakes
+// start with constants.SandboxStripeIDPrefix.
+type SandboxAdapter struct {
+	adapter PaymentAdapter
+	config  *config.Config
+}
+
+// NewSandboxAdapter wraps adapter with the fake payments of the sandbox tenants
+func NewSandboxAdapter(adapter PaymentAdapter, config *config.Config) *SandboxAdapter {
+	return &SandboxAdapter{adapter: adapter, config: config}
+}
+
+func (a *SandboxAdapter) CreateCheckoutSession(ctx context.Context, priceID string, user models.User, mode stripe.CheckoutSessionMode) (*stripe.CheckoutSession, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok {
+		return a.adapter.CreateCheckoutSession(ctx, priceID, user, mode)
+	}
+
+	checkoutSession := a.checkoutSession(sandboxID("cs"))
+	checkoutSession.Mode = mode
+	checkoutSession.CustomerEmail = user.Email
+	checkoutSession.Metadata = map[string]string{
+		"user_id":    user.ID,
+		"user_email": user.Email,
+		"price_id":   priceID,
+	}
+	return checkoutSession, nil
+}
+
+// GetCheckoutSession returns the sandbox sessions as complete and paid, whatever the
+// context, so that a session created in the sandbox can be read back
+func (a *SandboxAdapter) GetCheckoutSession(ctx context.Context, sessionID string) (*stripe.CheckoutSession, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(sessionID) {
+		return a.adapter.GetCheckoutSession(ctx, sessionID)
+	}
+
+	return a.checkoutSession(sessionID), nil
+}
+
+func (a *SandboxAdapter) CreateCustomerPortalSession(ctx context.Context, customerID string) (*stripe.BillingPortalSession, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(customerID) {
+		return a.adapter.CreateCustomerPortalSession(ctx, customerID)
+	}
+
+	return &stripe.BillingPortalSession{
+		ID:        sandboxID("bps"),
+		Object:    "billing_portal.session",
+		Created:   time.Now().Unix(),
+		Customer:  customerID,
+		Livemode:  false,
+		ReturnURL: a.config.StripeCustomerPortalURL,
+		URL:       a.config.StripeCustomerPortalURL,
+	}, nil
+}
+
+// HandleWebhook always verifies the webhooks with the provider, which never sends events
+// of the sandbox
+func (a *SandboxAdapter) HandleWebhook(ctx context.Context, payload []byte, signature string) (stripe.Event, error) {
+	return a.adapter.HandleWebhook(ctx, payload, signature)
+}
+
+func (a *SandboxAdapter) CreateCustomer(ctx context.Context, email string, userID string) (*stripe.Customer, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok {
+		return a.adapter.CreateCustomer(ctx, email, userID)
+	}
+
+	return &stripe.Customer{
+		ID:       sandboxID("cus"),
+		Object:   "customer",
+		Created:  time.Now().Unix(),
+		Email:    email,
+		Livemode: false,
+		Metadata: map[string]string{
+			"user_id": userID,
+		},
+	}, nil
+}
+
+// CreateSubscriptionCheckoutSession returns a complete session of the sandbox tenants
+// holding the active subscription it created, since no event of the provider follows it
+func (a *SandboxAdapter) CreateSubscriptionCheckoutSession(ctx context.Context, priceID string, customerID string, metadata map[string]string) (*stripe.CheckoutSession, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok {
+		return a.adapter.CreateSubscriptionCheckoutSession(ctx, priceID, customerID, metadata)
+	}
+
+	if customerID == "" {
+		customerID = sandboxID("cus")
+	}
+	checkoutSession := a.checkoutSession(sandboxID("cs"))
+	checkoutSession.Mode = stripe.CheckoutSessionModeSubscription
+	checkoutSession.Customer = &stripe.Customer{ID: customerID}
+	checkoutSession.Metadata = metadata
+	checkoutSession.Subscription = sandboxSubscription(sandboxID("sub"), customerID, priceID, metadata, stripe.SubscriptionStatusActive)
+	return checkoutSession, nil
+}
+
+func (a *SandboxAdapter) UpdateSubscriptionPrice(ctx context.Context, subscriptionID string, priceID string, metadata map[string]string) (*stripe.Subscription, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(subscriptionID) {
+		return a.adapter.UpdateSubscriptionPrice(ctx, subscriptionID, priceID, metadata)
+	}
+
+	return sandboxSubscription(subscriptionID, "", priceID, metadata, stripe.SubscriptionStatusActive), nil
+}
+
+func (a *SandboxAdapter) CancelSubscription(ctx context.Context, subscriptionID string) (*stripe.Subscription, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(subscriptionID) {
+		return a.adapter.CancelSubscription(ctx, subscriptionID)
+	}
+
+	return sandboxSubscription(subscriptionID, "", "", nil, stripe.SubscriptionStatusCanceled), nil
+}
+
+// Refund refunds the sandbox payments in full when amount is not positive, as the sandbox
+// does not know their amount
+func (a *SandboxAdapter) Refund(ctx context.Context, paymentID string, amount int64, reason string) (*stripe.Refund, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(paymentID) {
+		return a.adapter.Refund(ctx, paymentID, amount, reason)
+	}
+
+	return &stripe.Refund{
+		ID:            sandboxID("re"),
+		Object:        "refund",
+		Amount:        amount,
+		Created:       time.Now().Unix(),
+		Currency:      stripe.CurrencyUSD,
+		PaymentIntent: &stripe.PaymentIntent{ID: paymentID},
+		Reason:        stripe.RefundReason(reason),
+		Status:        stripe.RefundStatusSucceeded,
+	}, nil
+}
+
+func (a *SandboxAdapter) Capture(ctx context.Context, paymentID string, amount int64) (*stripe.PaymentIntent, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(paymentID) {
+		return a.adapter.Capture(ctx, paymentID, amount)
+	}
+
+	paymentIntent := sandboxPaymentIntent(paymentID, stripe.PaymentIntentStatusSucceeded)
+	paymentIntent.AmountReceived = amount
+	return paymentIntent, nil
+}
+
+func (a *SandboxAdapter) Void(ctx context.Context, paymentID string) (*stripe.PaymentIntent, error) {
+	if _, ok := sandbox.CompanyID(ctx); !ok && !isSandboxID(paymentID) {
+		return a.adapter.Void(ctx, paymentID)
+	}
+
+	return sandboxPaymentIntent(paymentID, stripe.PaymentIntentStatusCanceled), nil
+}
+
+// Ping checks the wrapped adapter
+func (a *SandboxAdapter) Ping(ctx context.Context) error {
+	return a.adapter.Ping(ctx)
+}
+
+func (a *SandboxAdapter) checkoutSession(id string) *stripe.CheckoutSession {
+	return &stripe.CheckoutSession{
+		ID:            id,
+		Object:        "checkout.session",
+		Created:       time.Now().Unix(),
+		Currency:      stripe.CurrencyUSD,
+		Livemode:      false,
+		Status:        stripe.CheckoutSessionStatusComplete,
+		PaymentStatus: stripe.CheckoutSessionPaymentStatusPaid,
+		SuccessURL:    a.config.StripeSuccessURL + "?session_id=" + id,
+		CancelURL:     a.config.StripeCancelURL,
+		URL:           a.config.StripeSuccessURL + "?session_id=" + id,
+	}
+}
+
+// sandboxSubscription returns a fake subscription to a price, renewed in 30 days
+func sandboxSubscription(id string, customerID string, priceID string, metadata map[string]string, status stripe.SubscriptionStatus) *stripe.Subscription {
+	now := time.Now()
+	subscription := &stripe.Subscription{
+		ID:       id,
+		Object:   "subscription",
+		Created:  now.Unix(),
+		Livemode: false,
+		Metadata: metadata,
+		Status:   status,
+	}
+	if customerID != "" {
+		subscription.Customer = &stripe.Customer{ID: customerID}
+	}
+	if priceID != "" {
+		subscription.Items = &stripe.SubscriptionItemList{
+			Data: []*stripe.SubscriptionItem{{
+				ID:               sandboxID("si"),
+				Price:            &stripe.Price{ID: priceID},
+				CurrentPeriodEnd: now.AddDate(0, 0, 30).Unix(),
+			}},
+		}
+	}
+	return subscription
+}
+
+// sandboxPaymentIntent returns a fake payment intent
+func sandboxPaymentIntent(id string, status stripe.PaymentIntentStatus) *stripe.PaymentIntent {
+	return &stripe.PaymentIntent{
+		ID:       id,
+		Object:   "payment_intent",
+		Created:  time.Now().Unix(),
+		Currency: stripe.CurrencyUSD,
+		Livemode: false,
+		Status:   status,
+	}
+}
+
+// sandboxID returns a new ID of a fake Stripe object, e.g. cus_sandbox_<uuid>
+func sandboxID(objectPrefix string) string {
+	return objectPrefix + "_" + constants.SandboxStripeIDPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func isSandboxID(id string) bool {
+	_, rest, ok := strings.Cut(id, "_")
+	return ok && strings.HasPrefix(rest, constants.SandboxStripeIDPrefix)
+}
@@ -0,0 +1,93 @@
+package payment
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/sandbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v82"
+)
+
+func TestSandboxAdapter_CheckoutSession(t *testing.T) {
+	// The sandbox never calls the wrapped adapter
+	adapter := NewSandboxAdapter(nil, &config.Config{StripeSuccessURL: "https://example.com/success"})
+	ctx := sandbox.WithCompany(context.Background(), "sandbox-1")
+
+	created, err := adapter.CreateCheckoutSession(ctx, "price_1", *factory.User().WithID("user-1").WithEmail("jane@example.com").Build(), stripe.CheckoutSessionModeSubscription)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.ID, "cs_sandbox_"))
+	assert.Equal(t, stripe.CheckoutSessionStatusComplete, created.Status)
+	assert.Equal(t, stripe.CheckoutSessionPaymentStatusPaid, created.PaymentStatus)
+	assert.Equal(t, "price_1", created.Metadata["price_id"])
+	assert.False(t, created.Livemode)
+
+	// A sandbox session reads back as paid, e.g. from the success page without the key
+	read, err := adapter.GetCheckoutSession(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, read.ID)
+	assert.Equal(t, stripe.CheckoutSessionPaymentStatusPaid, read.PaymentStatus)
+}
+
+func TestSandboxAdapter_CreateCustomer(t *testing.T) {
+	adapter := NewSandboxAdapter(nil, &config.Config{})
+
+	customer, err := adapter.CreateCustomer(sandbox.WithCompany(context.Background(), "sandbox-1"), "jane@example.com", "user-1")
+
+	require.NoError(t, err)
+	assert.True(t, isSandboxID(customer.ID))
+	assert.Equal(t, "jane@example.com", customer.Email)
+	assert.False(t, isSandboxID("cus_NffrFeUfNV2Hib"))
+}
+
+func TestSandboxAdapter_Subscriptions(t *testing.T) {
+	adapter := NewSandboxAdapter(nil, &config.Config{StripeSuccessURL: "https://example.com/success"})
+	ctx := sandbox.WithCompany(context.Background(), "sandbox-1")
+	metadata := map[string]string{"company_id": "sandbox-1", "plan": "pro"}
+
+	// The checkout completes at once with the subscription it created
+	checkout, err := adapter.CreateSubscriptionCheckoutSession(ctx, "price_pro", "", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, stripe.CheckoutSessionStatusComplete, checkout.Status)
+	require.NotNil(t, checkout.Subscription)
+	assert.True(t, isSandboxID(checkout.Subscription.ID))
+	assert.Equal(t, stripe.SubscriptionStatusActive, checkout.Subscription.Status)
+	assert.Equal(t, "price_pro", checkout.Subscription.Items.Data[0].Price.ID)
+	assert.Equal(t, metadata, checkout.Subscription.Metadata)
+
+	// A sandbox subscription changes without the key
+	updated, err := adapter.UpdateSubscriptionPrice(context.Background(), checkout.Subscription.ID, "price_team", metadata)
+	require.NoError(t, err)
+	assert.Equal(t, "price_team", updated.Items.Data[0].Price.ID)
+
+	canceled, err := adapter.CancelSubscription(context.Background(), checkout.Subscription.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stripe.SubscriptionStatusCanceled, canceled.Status)
+}
+
+func TestSandboxAdapter_PaymentOperations(t *testing.T) {
+	adapter := NewSandboxAdapter(nil, &config.Config{})
+	paymentID := sandboxID("pi")
+
+	// Sandbox payments are refunded, captured and voided without the key
+	refund, err := adapter.Refund(context.Background(), paymentID, 1500, "requested_by_customer")
+	require.NoError(t, err)
+	assert.True(t, isSandboxID(refund.ID))
+	assert.Equal(t, int64(1500), refund.Amount)
+	assert.Equal(t, paymentID, refund.PaymentIntent.ID)
+	assert.Equal(t, stripe.RefundStatusSucceeded, refund.Status)
+
+	captured, err := adapter.Capture(context.Background(), paymentID, 3000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3000), captured.AmountReceived)
+	assert.Equal(t, stripe.PaymentIntentStatusSucceeded, captured.Status)
+
+	voided, err := adapter.Void(sandbox.WithCompany(context.Background(), "sandbox-1"), "pi_3MtwBwLkdIwHu7ix28a3tqPa")
+	require.NoError(t, err)
+	assert.Equal(t, stripe.PaymentIntentStatusCanceled, voided.Status)
+}
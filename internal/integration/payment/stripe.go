@@ -2,13 +2,20 @@ package payment
 
 import (
 	"context"
+	"fmt"
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/models"
 
 	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/balance"
 	portalsession "github.com/stripe/stripe-go/v82/billingportal/session"
 	"github.com/stripe/stripe-go/v82/checkout/session"
 	"github.com/stripe/stripe-go/v82/customer"
+	"github.com/stripe/stripe-go/v82/paymentintent"
+	"github.com/stripe/stripe-go/v82/refund"
+	"github.com/stripe/stripe-go/v82/subscription"
 	"github.com/stripe/stripe-go/v82/webhook"
 )
 
@@ -80,3 +87,111 @@ func (a *StripeAdapter) CreateCustomer(ctx context.Context, email string, userID
 
 	return customer.New(params)
 }
+
+// CreateSubscriptionCheckoutSession creates a Stripe Checkout session in subscription mode
+func (a *StripeAdapter) CreateSubscriptionCheckoutSession(ctx context.Context, priceID string, customerID string, metadata map[string]string) (*stripe.CheckoutSession, error) {
+	params := &stripe.CheckoutSessionParams{
+		SuccessURL: stripe.String(a.config.StripeSuccessURL + "?session_id={CHECKOUT_SESSION_ID}"),
+		CancelURL:  stripe.String(a.config.StripeCancelURL),
+		Mode:       stripe.String(string(stripe.CheckoutSessionModeSubscription)),
+		LineItems: []*stripe.CheckoutSessionLineItemParams{
+			{
+				Price:    stripe.String(priceID),
+				Quantity: stripe.Int64(1),
+			},
+		},
+		Metadata: metadata,
+		SubscriptionData: &stripe.CheckoutSessionSubscriptionDataParams{
+			Metadata: metadata,
+		},
+	}
+	if customerID != "" {
+		params.Customer = stripe.String(customerID)
+	}
+	params.Context = ctx
+
+	return session.New(params)
+}
+
+// UpdateSubscriptionPrice replaces the price of the single item of a Stripe subscription
+func (a *StripeAdapter) UpdateSubscriptionPrice(ctx context.Context, subscriptionID string, priceID string, metadata map[string]string) (*stripe.Subscription, error) {
+	getParams := &stripe.SubscriptionParams{}
+	getParams.Context = ctx
+	current, err := subscription.Get(subscriptionID, getParams)
+	if err != nil {
+		return nil, err
+	}
+	if current.Items == nil || len(current.Items.Data) != 1 {
+		return nil, fmt.Errorf("subscription %s does not have a single item", subscriptionID)
+	}
+
+	params := &stripe.SubscriptionParams{
+		Items: []*stripe.SubscriptionItemsParams{
+			{
+				ID:    stripe.String(current.Items.Data[0].ID),
+				Price: stripe.String(priceID),
+			},
+		},
+		ProrationBehavior: stripe.String("create_prorations"),
+		Metadata:          metadata,
+	}
+	params.Context = ctx
+
+	return subscription.Update(subscriptionID, params)
+}
+
+// CancelSubscription cancels a Stripe subscription without waiting for the end of its period
+func (a *StripeAdapter) CancelSubscription(ctx context.Context, subscriptionID string) (*stripe.Subscription, error) {
+	params := &stripe.SubscriptionCancelParams{}
+	params.Context = ctx
+
+	return subscription.Cancel(subscriptionID, params)
+}
+
+// Refund creates a Stripe refund of a payment intent
+func (a *StripeAdapter) Refund(ctx context.Context, paymentID string, amount int64, reason string) (*stripe.Refund, error) {
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(paymentID),
+	}
+	if amount > 0 {
+		params.Amount = stripe.Int64(amount)
+	}
+	if reason != "" {
+		params.Reason = stripe.String(reason)
+	}
+	params.Context = ctx
+
+	return refund.New(params)
+}
+
+// Capture captures a Stripe payment intent created with the manual capture method
+func (a *StripeAdapter) Capture(ctx context.Context, paymentID string, amount int64) (*stripe.PaymentIntent, error) {
+	params := &stripe.PaymentIntentCaptureParams{}
+	if amount > 0 {
+		params.AmountToCapture = stripe.Int64(amount)
+	}
+	params.Context = ctx
+
+	return paymentintent.Capture(paymentID, params)
+}
+
+// Void cancels a Stripe payment intent
+func (a *StripeAdapter) Void(ctx context.Context, paymentID string) (*stripe.PaymentIntent, error) {
+	params := &stripe.PaymentIntentCancelParams{}
+	params.Context = ctx
+
+	return paymentintent.Cancel(paymentID, params)
+}
+
+// Ping reads the account balance, which needs a valid secret key
+func (a *StripeAdapter) Ping(ctx context.Context) error {
+	params := &stripe.BalanceParams{}
+	params.Context = ctx
+	if _, err := balance.Get(params); err != nil {
+		return errors.ExternalServiceError("Failed to reach Stripe", err).
+			WithProvider(constants.PaymentProviderStripe).
+			WithOperation("ping_payment").
+			WithResource("payment")
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package payment
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// WebhookEvent is a verified event of a payment provider
+type WebhookEvent struct {
+	Provider  string
+	ID        string
+	Type      string
+	CreatedAt time.Time
+	// Data is the object of the event: the data object of a Stripe event, the resource of a
+	// PayPal one
+	Data json.RawMessage
+}
+
+// WebhookVerifier verifies the events posted by the payment providers
+type WebhookVerifier interface {
+	// Verify checks the signature of an event, Stripe or PayPal depending on its headers,
+	// and returns it
+	Verify(ctx context.Context, payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+// webhookVerifier verifies the Stripe events with the payment adapter and the PayPal
+// events with their certificates, downloaded once per normalized URL and kept in a
+// bounded LRU
+type webhookVerifier struct {
+	adapter         PaymentAdapter
+	stripeSecret    string
+	payPalWebhookID string
+	client          *http.Client
+	certificates    *lru.Cache[string, *x509.Certificate]
+}
+
+// ProvideWebhookVerifier creates the verifier of the events of Stripe, signed with
+// STRIPE_WEBHOOK_SECRET, and of PayPal, posted by the webhook PAYPAL_WEBHOOK_ID
+func ProvideWebhookVerifier(config *config.Config, adapter PaymentAdapter) WebhookVerifier {
+	// lru.New only fails on a non-positive size
+	certificates, _ := lru.New[string, *x509.Certificate](constants.PayPalCertificateCacheSize)
+	return &webhookVerifier{
+		adapter:         adapter,
+		stripeSecret:    config.StripeWebhookSecret,
+		payPalWebhookID: config.PayPalWebhookID,
+		client:          &http.Client{Timeout: constants.PayPalCertificateTimeout},
+		certificates:    certificates,
+	}
+}
+
+func (v *webhookVerifier) Verify(ctx context.Context, payload []byte, header http.Header) (*WebhookEvent, error) {
+	switch {
+	case header.Get(constants.StripeSignatureHeader) != "" && v.stripeSecret != "":
+		return v.verifyStripe(ctx, payload, header.Get(constants.StripeSignatureHeader))
+	case header.Get(constants.PayPalTransmissionSigHeader) != "" && v.payPalWebhookID != "":
+		return v.verifyPayPal(ctx, payload, header)
+	default:
+		return nil, errors.UnauthorizedError("Missing payment event signature", nil).
+			WithOperation("verify_payment_event").
+			WithResource("payment")
+	}
+}
+
+func (v *webhookVerifier) verifyStripe(ctx context.Context, payload []byte, signature string) (*WebhookEvent, error) {
+	event, err := v.adapter.HandleWebhook(ctx, payload, signature)
+	if err != nil {
+		return nil, errors.UnauthorizedError("Invalid Stripe event signature", err).
+			WithProvider(constants.PaymentProviderStripe).
+			WithOperation("verify_payment_event").
+			WithResource("payment")
+	}
+
+	webhookEvent := &WebhookEvent{
+		Provider:  constants.PaymentProviderStripe,
+		ID:        event.ID,
+		Type:      string(event.Type),
+		CreatedAt: time.Unix(event.Created, 0).UTC(),
+	}
+	if event.Data != nil {
+		webhookEvent.Data = event.Data.Raw
+	}
+	return webhookEvent, nil
+}
+
+// payPalEvent is the part of a PayPal event the webhook reads
+type payPalEvent struct {
+	ID         string          `json:"id"`
+	EventType  string          `json:"event_type"`
+	CreateTime time.Time       `json:"create_time"`
+	Resource   json.RawMessage `json:"resource"`
+}
+
+func (v *webhookVerifier) verifyPayPal(ctx context.Context, payload []byte, header http.Header) (*WebhookEvent, error) {
+	if header.Get(constants.PayPalAuthAlgoHeader) != constants.PayPalAuthAlgo {
+		return nil, errors.UnauthorizedError("Unsupported PayPal signature algorithm", nil).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment").
+			WithContext("auth_algo", header.Get(constants.PayPalAuthAlgoHeader))
+	}
+
+	// As Stripe does for its signatures, the transmissions too far from now are refused so
+	// that a captured event cannot be replayed later
+	transmissionTime, err := time.Parse(time.RFC3339, header.Get(constants.PayPalTransmissionTimeHeader))
+	if err != nil || time.Since(transmissionTime).Abs() > constants.PayPalTransmissionTolerance {
+		return nil, errors.UnauthorizedError("PayPal event transmission time outside the tolerance", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment").
+			WithContext("transmission_time", header.Get(constants.PayPalTransmissionTimeHeader))
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(header.Get(constants.PayPalTransmissionSigHeader))
+	if err != nil {
+		return nil, errors.UnauthorizedError("Invalid PayPal event signature", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment")
+	}
+
+	certificate, err := v.certificate(ctx, header.Get(constants.PayPalCertURLHeader))
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	now := time.Now()
+	if !ok || now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
+		return nil, errors.UnauthorizedError("Invalid PayPal signing certificate", nil).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment")
+	}
+
+	signed := fmt.Sprintf("%s|%s|%s|%d",
+		header.Get(constants.PayPalTransmissionIDHeader),
+		header.Get(constants.PayPalTransmissionTimeHeader),
+		v.payPalWebhookID,
+		crc32.ChecksumIEEE(payload),
+	)
+	digest := sha256.Sum256([]byte(signed))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, errors.UnauthorizedError("Invalid PayPal event signature", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment").
+			WithContext("transmission_id", header.Get(constants.PayPalTransmissionIDHeader))
+	}
+
+	var event payPalEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return nil, errors.ValidationError("Invalid PayPal event", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("verify_payment_event").
+			WithResource("payment")
+	}
+
+	return &WebhookEvent{
+		Provider:  constants.PaymentProviderPayPal,
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreateTime,
+		Data:      event.Resource,
+	}, nil
+}
+
+// certificate returns the PayPal signing certificate of rawURL, downloaded the first time
+// its normalized URL is seen
+func (v *webhookVerifier) certificate(ctx context.Context, rawURL string) (*x509.Certificate, error) {
+	certificateURL, err := payPalCertificateURL(rawURL)
+	if err != nil {
+		return nil, errors.UnauthorizedError("Invalid PayPal certificate URL", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("get_paypal_certificate").
+			WithResource("payment").
+			WithContext("cert_url", rawURL)
+	}
+
+	if certificate, ok := v.certificates.Get(certificateURL); ok {
+		return certificate, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, certificateURL, nil)
+	if err != nil {
+		return nil, errors.InternalError("Failed to create PayPal certificate request", err).
+			WithOperation("get_paypal_certificate").
+			WithResource("payment")
+	}
+	response, err := v.client.Do(request)
+	if err != nil {
+		return nil, errors.ExternalServiceError("Failed to get PayPal certificate", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("get_paypal_certificate").
+			WithResource("payment").
+			WithContext("cert_url", rawURL)
+	}
+	defer response.Body.Close()
+
+	var certificate *x509.Certificate
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if err == nil && response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	if err == nil {
+		certificate, err = parseCertificate(body)
+	}
+	if err != nil {
+		return nil, errors.ExternalServiceError("Failed to get PayPal certificate", err).
+			WithProvider(constants.PaymentProviderPayPal).
+			WithOperation("get_paypal_certificate").
+			WithResource("payment").
+			WithContext("cert_url", rawURL)
+	}
+
+	v.certificates.Add(certificateURL, certificate)
+
+	return certificate, nil
+}
+
+// payPalCertificateURL returns the normalized form of rawURL, with a lower case host,
+// without the default port and the fragment, when it is an HTTPS URL of a PayPal
+// certificate host
+func payPalCertificateURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if parsed.Scheme != "https" || parsed.User != nil || (parsed.Port() != "" && parsed.Port() != "443") || !slices.Contains(constants.PayPalCertHosts, host) {
+		return "", fmt.Errorf("not a PayPal certificate URL: %s", parsed.Redacted())
+	}
+	normalized := url.URL{Scheme: "https", Host: host, Path: parsed.Path, RawPath: parsed.RawPath, RawQuery: parsed.RawQuery}
+	return normalized.String(), nil
+}
+
+// parseCertificate parses the first certificate of a PEM chain
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
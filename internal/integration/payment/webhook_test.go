@@ -0,0 +1,208 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/webhook"
+)
+
+const testPayPalCertURL = "https://api.paypal.com/v1/notifications/certs/CERT-test"
+
+func TestWebhookVerifier_Stripe(t *testing.T) {
+	cfg := &config.Config{StripeWebhookSecret: "whsec_test"}
+	adapter, err := NewStripeAdapter(cfg)
+	require.NoError(t, err)
+	verifier := ProvideWebhookVerifier(cfg, adapter)
+	payload := []byte(fmt.Sprintf(`{"id":"evt_1","object":"event","type":"invoice.paid","created":1760000000,"api_version":%q,"data":{"object":{"id":"in_1"}}}`, stripe.APIVersion))
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_test"})
+	event, err := verifier.Verify(context.Background(), payload, http.Header{constants.StripeSignatureHeader: {signed.Header}})
+	require.NoError(t, err)
+	assert.Equal(t, constants.PaymentProviderStripe, event.Provider)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, "invoice.paid", event.Type)
+	assert.Equal(t, time.Unix(1760000000, 0).UTC(), event.CreatedAt)
+	assert.JSONEq(t, `{"id":"in_1"}`, string(event.Data))
+
+	forged := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_other"})
+	_, err = verifier.Verify(context.Background(), payload, http.Header{constants.StripeSignatureHeader: {forged.Header}})
+	assert.Error(t, err)
+
+	_, err = verifier.Verify(context.Background(), payload, http.Header{})
+	assert.Error(t, err, "unsigned events are refused")
+}
+
+func TestWebhookVerifier_PayPal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "messageverificationcerts.paypal.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	verifier := ProvideWebhookVerifier(&config.Config{PayPalWebhookID: "WH-1"}, nil).(*webhookVerifier)
+	verifier.certificates.Add(testPayPalCertURL, certificate)
+	payload := []byte(`{"id":"WH-EVT-1","event_type":"PAYMENT.CAPTURE.COMPLETED","create_time":"2026-01-01T00:00:00Z","resource":{"id":"CAP-1"}}`)
+
+	signAt := func(transmittedAt time.Time, webhookID string, body []byte) http.Header {
+		transmissionTime := transmittedAt.UTC().Format(time.RFC3339)
+		digest := sha256.Sum256([]byte(fmt.Sprintf("T-1|%s|%s|%d", transmissionTime, webhookID, crc32.ChecksumIEEE(body))))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return http.Header{
+			constants.PayPalTransmissionIDHeader:   {"T-1"},
+			constants.PayPalTransmissionTimeHeader: {transmissionTime},
+			constants.PayPalTransmissionSigHeader:  {base64.StdEncoding.EncodeToString(signature)},
+			constants.PayPalCertURLHeader:          {testPayPalCertURL},
+			constants.PayPalAuthAlgoHeader:         {constants.PayPalAuthAlgo},
+		}
+	}
+	sign := func(webhookID string, body []byte) http.Header {
+		return signAt(time.Now(), webhookID, body)
+	}
+
+	t.Run("signed event", func(t *testing.T) {
+		event, err := verifier.Verify(context.Background(), payload, sign("WH-1", payload))
+
+		require.NoError(t, err)
+		assert.Equal(t, constants.PaymentProviderPayPal, event.Provider)
+		assert.Equal(t, "WH-EVT-1", event.ID)
+		assert.Equal(t, "PAYMENT.CAPTURE.COMPLETED", event.Type)
+		assert.JSONEq(t, `{"id":"CAP-1"}`, string(event.Data))
+	})
+
+	t.Run("event of another webhook", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), payload, sign("WH-2", payload))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		header := sign("WH-1", payload)
+
+		_, err := verifier.Verify(context.Background(), []byte(`{"id":"WH-EVT-2"}`), header)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("replayed transmission", func(t *testing.T) {
+		header := signAt(time.Now().Add(-constants.PayPalTransmissionTolerance-time.Minute), "WH-1", payload)
+
+		_, err := verifier.Verify(context.Background(), payload, header)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("transmission in the future", func(t *testing.T) {
+		header := signAt(time.Now().Add(constants.PayPalTransmissionTolerance+time.Minute), "WH-1", payload)
+
+		_, err := verifier.Verify(context.Background(), payload, header)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("transmission without a time", func(t *testing.T) {
+		header := sign("WH-1", payload)
+		header.Del(constants.PayPalTransmissionTimeHeader)
+
+		_, err := verifier.Verify(context.Background(), payload, header)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("certificate outside PayPal", func(t *testing.T) {
+		header := sign("WH-1", payload)
+		header.Set(constants.PayPalCertURLHeader, "https://paypal.example.com/cert.pem")
+
+		_, err := verifier.Verify(context.Background(), payload, header)
+
+		assert.Error(t, err)
+	})
+}
+
+// roundTripFunc serves the requests of an HTTP client with a function
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestWebhookVerifier_PayPalCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "messageverificationcerts.paypal.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	body := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	verifier := ProvideWebhookVerifier(&config.Config{PayPalWebhookID: "WH-1"}, nil).(*webhookVerifier)
+	var downloaded []string
+	verifier.client = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		downloaded = append(downloaded, request.URL.String())
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Request: request}, nil
+	})}
+	ctx := context.Background()
+
+	// The spellings of a URL share one download
+	for _, rawURL := range []string{
+		testPayPalCertURL,
+		"https://API.PayPal.com/v1/notifications/certs/CERT-test",
+		"https://api.paypal.com:443/v1/notifications/certs/CERT-test",
+		"https://api.paypal.com/v1/notifications/certs/CERT-test#other",
+	} {
+		_, err := verifier.certificate(ctx, rawURL)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{testPayPalCertURL}, downloaded)
+
+	for _, rawURL := range []string{
+		"http://api.paypal.com/v1/notifications/certs/CERT-test",
+		"https://user@api.paypal.com/v1/notifications/certs/CERT-test",
+		"https://api.paypal.com:8443/v1/notifications/certs/CERT-test",
+	} {
+		_, err := verifier.certificate(ctx, rawURL)
+		assert.Error(t, err, rawURL)
+	}
+
+	// The oldest certificates are evicted past the bound
+	for i := range constants.PayPalCertificateCacheSize {
+		_, err := verifier.certificate(ctx, fmt.Sprintf("https://api.paypal.com/v1/notifications/certs/CERT-%d", i))
+		require.NoError(t, err)
+	}
+	assert.Len(t, downloaded, constants.PayPalCertificateCacheSize+1)
+	assert.Equal(t, constants.PayPalCertificateCacheSize, verifier.certificates.Len())
+	assert.False(t, verifier.certificates.Contains(testPayPalCertURL), "the least recently used certificate is evicted")
+}
@@ -2,19 +2,30 @@ package storage
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
 
 	"golang-boilerplate/internal/logger"
 
 	gcstorage "cloud.google.com/go/storage"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -75,6 +86,14 @@ func NewGCSAdapter(config *config.Config) (*GCSAdapter, error) {
 }
 
 func (a *GCSAdapter) UploadFile(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error) {
+	sha, crc, err := fileChecksums(file)
+	if err != nil {
+		logger.Sugar.Errorf("failed to read file: %v", err)
+		return nil, errors.ExternalServiceError("failed to read file", err).
+			WithOperation("checksum_file").
+			WithResource("storage")
+	}
+
 	f, err := file.Open()
 	if err != nil {
 		logger.Sugar.Errorf("failed to open file: %v", err)
@@ -86,25 +105,43 @@ func (a *GCSAdapter) UploadFile(ctx context.Context, file *multipart.FileHeader,
 
 	obj := a.bucket.Object(key)
 	wc := obj.NewWriter(ctx)
+	wc.ContentType = file.Header.Get("Content-Type")
+	wc.Metadata = map[string]string{constants.StorageChecksumMetadataKey: hex.EncodeToString(sha)}
+	// GCS rejects the object when the content it received does not match the CRC32C
+	wc.CRC32C = crc
+	wc.SendCRC32C = true
 
 	if _, err := io.Copy(wc, f); err != nil {
 		_ = wc.Close()
 		logger.Sugar.Errorf("failed to write to GCS: %v", err)
 		return nil, errors.ExternalServiceError("failed to write to GCS", err).
+			WithProvider(constants.StorageProviderGCS).
 			WithOperation("write_to_gcs").
 			WithResource("storage")
 	}
 
 	if err := wc.Close(); err != nil {
 		logger.Sugar.Errorf("failed to close writer: %v", err)
+		if isGCSChecksumMismatch(err) {
+			return nil, errors.ChecksumMismatchError("uploaded content does not match its checksum", err).
+				WithProvider(constants.StorageProviderGCS).
+				WithOperation("close_writer").
+				WithResource("storage").
+				WithContext("key", key)
+		}
 		return nil, errors.ExternalServiceError("failed to close writer", err).
+			WithProvider(constants.StorageProviderGCS).
 			WithOperation("close_writer").
 			WithResource("storage")
 	}
 
 	url := a.GetObjectURL(key)
 
-	return &UploadResult{URL: url, Key: key, Bucket: a.config.GCSBucket, Location: url}, nil
+	return &UploadResult{URL: url, Key: key, Bucket: a.config.GCSBucket, Location: url, Checksum: hex.EncodeToString(sha)}, nil
+}
+
+func (a *GCSAdapter) Bucket() string {
+	return a.config.GCSBucket
 }
 
 func (a *GCSAdapter) GetObjectURL(key string) string {
@@ -118,11 +155,66 @@ func (a *GCSAdapter) GetPresignedURL(ctx context.Context, key string, duration .
 		expiry = duration[0]
 	}
 
+	accessID, privateKey, err := a.signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	opts := &gcstorage.SignedURLOptions{
+		GoogleAccessID: accessID,
+		PrivateKey:     privateKey,
+		Method:         "GET",
+		Expires:        time.Now().Add(expiry),
+	}
+
+	url, err := gcstorage.SignedURL(a.config.GCSBucket, key, opts)
+	if err != nil {
+		logger.Sugar.Errorf("failed to sign GCS URL: %v", err)
+		return "", errors.ExternalServiceError("failed to sign GCS URL", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("sign_gcs_url").
+			WithResource("storage")
+	}
+
+	return url, nil
+}
+
+// PresignUpload returns a V4 POST policy of an object of contentType and of up to maxSize
+// bytes under key
+func (a *GCSAdapter) PresignUpload(ctx context.Context, key string, contentType string, maxSize int64) (*PresignedUpload, error) {
+	accessID, privateKey, err := a.signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(constants.PresignedUploadDuration)
+	policy, err := gcstorage.GenerateSignedPostPolicyV4(a.config.GCSBucket, key, &gcstorage.PostPolicyV4Options{
+		GoogleAccessID: accessID,
+		PrivateKey:     privateKey,
+		Expires:        expiresAt,
+		Fields:         &gcstorage.PolicyV4Fields{ContentType: contentType},
+		Conditions:     []gcstorage.PostPolicyV4Condition{gcstorage.ConditionContentLengthRange(1, uint64(maxSize))},
+	})
+	if err != nil {
+		logger.Sugar.Errorf("failed to sign GCS upload policy: %v", err)
+		return nil, errors.ExternalServiceError("failed to sign GCS upload policy", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("presign_upload").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	return &PresignedUpload{URL: policy.URL, Fields: policy.Fields, ExpiresAt: expiresAt}, nil
+}
+
+// signingKey returns the email and private key of the service account signing the URLs
+// and upload policies
+func (a *GCSAdapter) signingKey() (string, []byte, error) {
 	// Read service account JSON key
 	keyJSON, err := os.ReadFile(a.config.GCSCredentialsJSONPath)
 	if err != nil {
 		logger.Sugar.Errorf("failed to read service account JSON: %v", err)
-		return "", errors.ExternalServiceError("failed to read service account JSON", err).
+		return "", nil, errors.ExternalServiceError("failed to read service account JSON", err).
 			WithOperation("read_service_account_json").
 			WithResource("storage")
 	}
@@ -131,54 +223,216 @@ func (a *GCSAdapter) GetPresignedURL(ctx context.Context, key string, duration .
 	conf, err := google.JWTConfigFromJSON(keyJSON)
 	if err != nil {
 		logger.Sugar.Errorf("failed to parse service account JSON: %v", err)
-		return "", errors.ExternalServiceError("failed to parse service account JSON", err).
+		return "", nil, errors.ExternalServiceError("failed to parse service account JSON", err).
 			WithOperation("parse_service_account_json").
 			WithResource("storage")
 	}
 
-	opts := &gcstorage.SignedURLOptions{
-		GoogleAccessID: conf.Email,
-		PrivateKey:     conf.PrivateKey,
-		Method:         "GET",
-		Expires:        time.Now().Add(expiry),
+	return conf.Email, conf.PrivateKey, nil
+}
+
+// DeleteFile removes the object stored under key. Deleting a missing object is not an error.
+func (a *GCSAdapter) DeleteFile(ctx context.Context, key string) error {
+	err := a.bucket.Object(key).Delete(ctx)
+	if err != nil && !stderrors.Is(err, gcstorage.ErrObjectNotExist) {
+		logger.Sugar.Errorf("failed to delete GCS object: %v", err)
+		return errors.ExternalServiceError("failed to delete GCS object", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("delete_file").
+			WithResource("storage").
+			WithContext("key", key)
 	}
 
-	url, err := gcstorage.SignedURL(a.config.GCSBucket, key, opts)
-	if err != nil {
-		logger.Sugar.Errorf("failed to sign GCS URL: %v", err)
-		return "", errors.ExternalServiceError("failed to sign GCS URL", err).
-			WithOperation("sign_gcs_url").
+	return nil
+}
+
+// DeleteFiles removes the objects stored under keys, up to
+// constants.StorageDeleteConcurrency at once since GCS has no batch delete. A failed
+// delete does not stop the others.
+func (a *GCSAdapter) DeleteFiles(ctx context.Context, keys []string) error {
+	var mu sync.Mutex
+	var failed []string
+	var errs []error
+
+	var group errgroup.Group
+	group.SetLimit(constants.StorageDeleteConcurrency)
+	for _, key := range keys {
+		group.Go(func() error {
+			err := a.bucket.Object(key).Delete(ctx)
+			if err != nil && !stderrors.Is(err, gcstorage.ErrObjectNotExist) {
+				mu.Lock()
+				failed = append(failed, key)
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	if len(failed) > 0 {
+		err := stderrors.Join(errs...)
+		logger.Sugar.Errorf("failed to delete GCS objects: %v", err)
+		return errors.ExternalServiceError("failed to delete GCS objects", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("delete_files").
+			WithResource("storage").
+			WithContext("keys", len(keys)).
+			WithContext("failed_keys", failed)
+	}
+
+	return nil
+}
+
+// ListObjects lists the objects under prefix, whose page token is the cursor
+func (a *GCSAdapter) ListObjects(ctx context.Context, prefix string, cursor string, limit int) (*ObjectPage, error) {
+	query := &gcstorage.Query{Prefix: prefix}
+	if err := query.SetAttrSelection([]string{"Name", "Size", "Etag", "Updated"}); err != nil {
+		return nil, errors.InternalError("failed to select GCS object attributes", err).
+			WithOperation("list_objects").
 			WithResource("storage")
 	}
 
-	return url, nil
+	var attrs []*gcstorage.ObjectAttrs
+	nextCursor, err := iterator.NewPager(a.bucket.Objects(ctx, query), listLimit(limit), cursor).NextPage(&attrs)
+	if err != nil {
+		logger.Sugar.Errorf("failed to list GCS objects: %v", err)
+		return nil, errors.ExternalServiceError("failed to list GCS objects", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("list_objects").
+			WithResource("storage").
+			WithContext("prefix", prefix)
+	}
+
+	page := &ObjectPage{Objects: make([]ObjectInfo, 0, len(attrs)), NextCursor: nextCursor}
+	for _, object := range attrs {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:       object.Name,
+			Size:      object.Size,
+			ETag:      object.Etag,
+			UpdatedAt: object.Updated,
+		})
+	}
+
+	return page, nil
 }
 
-func (a *GCSAdapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*BatchUploadResult, error) {
-	result := &BatchUploadResult{Files: make([]UploadResult, 0, len(files))}
-	type uploadResult struct {
-		result *UploadResult
-		err    error
+// HeadObject returns the metadata of the object stored under key. The checksum is the
+// SHA-256 recorded on upload, or else its MD5 digest, or its CRC32C one for the composite
+// objects, which have no MD5 digest.
+func (a *GCSAdapter) HeadObject(ctx context.Context, key string) (*ObjectMetadata, error) {
+	attrs, err := a.bucket.Object(key).Attrs(ctx)
+	if err != nil {
+		return nil, a.objectError(err, "failed to get GCS object metadata", "head_object", key)
 	}
 
-	ch := make(chan uploadResult, len(files))
+	checksum := metadataChecksum(attrs.Metadata)
+	switch {
+	case checksum != "":
+	case len(attrs.MD5) > 0:
+		checksum = "md5:" + base64.StdEncoding.EncodeToString(attrs.MD5)
+	default:
+		checksum = "crc32c:" + base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, attrs.CRC32C))
+	}
 
-	for _, fh := range files {
-		go func(fh *multipart.FileHeader) {
-			res, err := a.UploadFile(ctx, fh, fh.Filename)
-			ch <- uploadResult{res, err}
-		}(fh)
+	return &ObjectMetadata{
+		Key:         key,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		Checksum:    checksum,
+		ETag:        attrs.Etag,
+		UpdatedAt:   attrs.Updated,
+	}, nil
+}
+
+func (a *GCSAdapter) CopyObject(ctx context.Context, srcKey string, dstKey string) error {
+	if _, err := a.bucket.Object(dstKey).CopierFrom(a.bucket.Object(srcKey)).Run(ctx); err != nil {
+		return a.objectError(err, "failed to copy GCS object", "copy_object", srcKey).
+			WithContext("destination_key", dstKey)
 	}
 
-	for i := 0; i < len(files); i++ {
-		r := <-ch
-		if r.err != nil {
-			return nil, errors.ExternalServiceError("failed to upload file", r.err).
-				WithOperation("upload_file").
-				WithResource("storage")
-		}
-		result.Files = append(result.Files, *r.result)
+	return nil
+}
+
+func (a *GCSAdapter) MoveObject(ctx context.Context, srcKey string, dstKey string) error {
+	// Copying an object onto itself then deleting the source would delete it
+	if srcKey == dstKey {
+		_, err := a.HeadObject(ctx, srcKey)
+		return err
 	}
 
-	return result, nil
+	if err := a.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	return a.DeleteFile(ctx, srcKey)
+}
+
+// objectError returns a missing object as not found and the other errors as errors of GCS
+func (a *GCSAdapter) objectError(err error, message string, operation string, key string) *errors.AppError {
+	if stderrors.Is(err, gcstorage.ErrObjectNotExist) {
+		return errors.NotFoundError("File", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation(operation).
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	logger.Sugar.Errorf("%s: %v", message, err)
+	return errors.ExternalServiceError(message, err).
+		WithProvider(constants.StorageProviderGCS).
+		WithOperation(operation).
+		WithResource("storage").
+		WithContext("key", key)
+}
+
+// isGCSChecksumMismatch reports whether GCS rejected an upload whose content did not
+// match the CRC32C sent with it
+func isGCSChecksumMismatch(err error) bool {
+	var apiErr *googleapi.Error
+	return stderrors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Message), "crc32c")
+}
+
+// OpenFile streams the object stored under key
+func (a *GCSAdapter) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	return a.OpenFileRange(ctx, key, 0, -1)
+}
+
+// OpenFileRange streams length bytes of the object stored under key from offset, up to
+// its end when length is negative
+func (a *GCSAdapter) OpenFileRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	reader, err := a.bucket.Object(key).NewRangeReader(ctx, offset, length)
+	if stderrors.Is(err, gcstorage.ErrObjectNotExist) {
+		return nil, errors.NotFoundError("File", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("open_file").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+	if err != nil {
+		logger.Sugar.Errorf("failed to open GCS object: %v", err)
+		return nil, errors.ExternalServiceError("failed to open GCS object", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("open_file").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	return reader, nil
+}
+
+// Ping checks that the bucket exists and the credentials can access it
+func (a *GCSAdapter) Ping(ctx context.Context) error {
+	if _, err := a.bucket.Attrs(ctx); err != nil {
+		return errors.ExternalServiceError("failed to reach GCS bucket", err).
+			WithProvider(constants.StorageProviderGCS).
+			WithOperation("ping_storage").
+			WithResource("storage").
+			WithContext("bucket", a.config.GCSBucket)
+	}
+	return nil
+}
+
+func (a *GCSAdapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*BatchUploadResult, error) {
+	return uploadFiles(ctx, constants.StorageProviderGCS, files, a.config.StorageUploadConcurrency, a.UploadFile)
 }
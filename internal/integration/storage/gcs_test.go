@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	gcstorage "cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+const testGCSBucket = "test-bucket"
+
+// fakeGCS is a bucket in memory served with the JSON API of GCS
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string]string
+	// deniedKeys are refused by the deletes, which take latency each
+	deniedKeys map[string]bool
+	latency    time.Duration
+	// maxResults are the page sizes of the list requests received
+	maxResults []string
+	// operations are the copies, deletes and heads of single objects received
+	operations []string
+
+	inFlight, maxInFlight atomic.Int32
+}
+
+func newFakeGCS(keys ...string) *fakeGCS {
+	f := &fakeGCS{objects: map[string]string{}, deniedKeys: map[string]bool{}}
+	for _, key := range keys {
+		f.objects[key] = "content of " + key
+	}
+	return f
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	current := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		seen := f.maxInFlight.Load()
+		if current <= seen || f.maxInFlight.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	time.Sleep(f.latency)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	objectsPath := "/storage/v1/b/" + testGCSBucket + "/o/"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == strings.TrimSuffix(objectsPath, "/"):
+		f.listObjects(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, objectsPath) && strings.Contains(r.URL.Path, "/rewriteTo/"):
+		srcKey, dstKey, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, objectsPath), "/rewriteTo/b/"+testGCSBucket+"/o/")
+		f.copyObject(w, srcKey, dstKey)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, objectsPath):
+		f.deleteObject(w, strings.TrimPrefix(r.URL.Path, objectsPath))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, objectsPath):
+		f.headObject(w, strings.TrimPrefix(r.URL.Path, objectsPath))
+	default:
+		writeGCSError(w, http.StatusNotImplemented, r.Method+" "+r.URL.String())
+	}
+}
+
+func (f *fakeGCS) copyObject(w http.ResponseWriter, srcKey string, dstKey string) {
+	f.operations = append(f.operations, "copy "+srcKey+" "+dstKey)
+	content, ok := f.objects[srcKey]
+	if !ok {
+		writeGCSError(w, http.StatusNotFound, "No such object")
+		return
+	}
+	f.objects[dstKey] = content
+	size := strconv.Itoa(len(content))
+	writeGCSJSON(w, map[string]any{
+		"kind":                "storage#rewriteResponse",
+		"totalBytesRewritten": size,
+		"objectSize":          size,
+		"done":                true,
+		"resource":            f.objectResource(dstKey),
+	})
+}
+
+func (f *fakeGCS) headObject(w http.ResponseWriter, key string) {
+	f.operations = append(f.operations, "head "+key)
+	if _, ok := f.objects[key]; !ok {
+		writeGCSError(w, http.StatusNotFound, "No such object")
+		return
+	}
+	writeGCSJSON(w, f.objectResource(key))
+}
+
+func (f *fakeGCS) objectResource(key string) map[string]any {
+	return map[string]any{
+		"bucket":  testGCSBucket,
+		"name":    key,
+		"size":    strconv.Itoa(len(f.objects[key])),
+		"etag":    "etag-" + key,
+		"updated": "2026-01-01T00:00:00.000Z",
+	}
+}
+
+func (f *fakeGCS) deleteObject(w http.ResponseWriter, key string) {
+	f.operations = append(f.operations, "delete "+key)
+	if f.deniedKeys[key] {
+		writeGCSError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+	if _, ok := f.objects[key]; !ok {
+		writeGCSError(w, http.StatusNotFound, "No such object")
+		return
+	}
+	delete(f.objects, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listObjects lists the keys after the one of the page token, "after:<key>"
+func (f *fakeGCS) listObjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f.maxResults = append(f.maxResults, query.Get("maxResults"))
+	maxResults, err := strconv.Atoi(query.Get("maxResults"))
+	if err != nil {
+		writeGCSError(w, http.StatusBadRequest, "Invalid maxResults")
+		return
+	}
+	after, hasToken := strings.CutPrefix(query.Get("pageToken"), "after:")
+	if query.Get("pageToken") != "" && !hasToken {
+		writeGCSError(w, http.StatusBadRequest, "Invalid page token")
+		return
+	}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	response := map[string]any{"kind": "storage#objects"}
+	if len(keys) > maxResults {
+		keys = keys[:maxResults]
+		response["nextPageToken"] = "after:" + keys[len(keys)-1]
+	}
+	items := make([]map[string]any, len(keys))
+	for i, key := range keys {
+		items[i] = f.objectResource(key)
+	}
+	response["items"] = items
+	writeGCSJSON(w, response)
+}
+
+func writeGCSJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeGCSError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": status, "message": message}})
+}
+
+// newTestGCSAdapter returns an adapter of the bucket of fake
+func newTestGCSAdapter(t *testing.T, fake *fakeGCS) *GCSAdapter {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := gcstorage.NewClient(context.Background(), option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return &GCSAdapter{config: &config.Config{GCSBucket: testGCSBucket}, client: client, bucket: client.Bucket(testGCSBucket)}
+}
+
+func TestGCSAdapter_DeleteFiles(t *testing.T) {
+	t.Run("bounded concurrency", func(t *testing.T) {
+		keys := make([]string, 3*constants.StorageDeleteConcurrency)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("uploads/%02d.txt", i)
+		}
+		fake := newFakeGCS(keys...)
+		fake.latency = 5 * time.Millisecond
+		adapter := newTestGCSAdapter(t, fake)
+
+		err := adapter.DeleteFiles(context.Background(), append(keys, "uploads/missing.txt"))
+
+		require.NoError(t, err)
+		assert.Empty(t, fake.objects)
+		assert.LessOrEqual(t, fake.maxInFlight.Load(), int32(constants.StorageDeleteConcurrency))
+	})
+
+	t.Run("failed deletes do not stop the others", func(t *testing.T) {
+		fake := newFakeGCS("a.txt", "b.txt", "c.txt", "d.txt")
+		fake.deniedKeys["b.txt"] = true
+		fake.deniedKeys["d.txt"] = true
+		adapter := newTestGCSAdapter(t, fake)
+
+		err := adapter.DeleteFiles(context.Background(), []string{"a.txt", "b.txt", "c.txt", "d.txt"})
+
+		require.Error(t, err)
+		appErr := errors.GetAppError(err)
+		assert.Equal(t, errors.ErrorTypeExternal, appErr.Type)
+		assert.ElementsMatch(t, []string{"b.txt", "d.txt"}, appErr.Context["failed_keys"])
+		assert.Len(t, fake.objects, 2)
+	})
+}
+
+func TestGCSAdapter_ListObjects(t *testing.T) {
+	fake := newFakeGCS("uploads/a.txt", "uploads/b.txt", "uploads/c.txt", "uploads/d.txt", "uploads/e.txt", "other/f.txt")
+	adapter := newTestGCSAdapter(t, fake)
+	ctx := context.Background()
+
+	var pages [][]string
+	cursor := ""
+	for {
+		page, err := adapter.ListObjects(ctx, "uploads/", cursor, 2)
+		require.NoError(t, err)
+		keys := make([]string, len(page.Objects))
+		for i, object := range page.Objects {
+			keys[i] = object.Key
+		}
+		pages = append(pages, keys)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, [][]string{{"uploads/a.txt", "uploads/b.txt"}, {"uploads/c.txt", "uploads/d.txt"}, {"uploads/e.txt"}}, pages)
+
+	page, err := adapter.ListObjects(ctx, "uploads/e", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []ObjectInfo{{
+		Key:       "uploads/e.txt",
+		Size:      int64(len("content of uploads/e.txt")),
+		ETag:      "etag-uploads/e.txt",
+		UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}, page.Objects)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = adapter.ListObjects(ctx, "uploads/", "", constants.StorageListMaxLimit+1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "2", "2", "100", "1000"}, fake.maxResults, "the page sizes are bounded")
+
+	_, err = adapter.ListObjects(ctx, "uploads/", "forged", 2)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+}
+
+func TestGCSAdapter_CopyObject(t *testing.T) {
+	fake := newFakeGCS("uploads/a b.txt")
+	adapter := newTestGCSAdapter(t, fake)
+
+	err := adapter.CopyObject(context.Background(), "uploads/a b.txt", "archive/a b.txt")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"uploads/a b.txt": "content of uploads/a b.txt", "archive/a b.txt": "content of uploads/a b.txt"}, fake.objects)
+
+	err = adapter.CopyObject(context.Background(), "uploads/missing.txt", "archive/missing.txt")
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeNotFound, errors.GetAppError(err).Type)
+	assert.NotContains(t, fake.objects, "archive/missing.txt")
+}
+
+func TestGCSAdapter_MoveObject(t *testing.T) {
+	tests := []struct {
+		name               string
+		srcKey             string
+		dstKey             string
+		expectedObjects    []string
+		expectedOperations []string
+		expectedError      errors.ErrorType
+	}{
+		{
+			name:               "other key",
+			srcKey:             "uploads/a.txt",
+			dstKey:             "archive/a.txt",
+			expectedObjects:    []string{"archive/a.txt"},
+			expectedOperations: []string{"copy uploads/a.txt archive/a.txt", "delete uploads/a.txt"},
+		},
+		{
+			name:               "same key",
+			srcKey:             "uploads/a.txt",
+			dstKey:             "uploads/a.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"head uploads/a.txt"},
+		},
+		{
+			name:               "same key of a missing object",
+			srcKey:             "uploads/missing.txt",
+			dstKey:             "uploads/missing.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"head uploads/missing.txt"},
+			expectedError:      errors.ErrorTypeNotFound,
+		},
+		{
+			name:               "missing source",
+			srcKey:             "uploads/missing.txt",
+			dstKey:             "archive/missing.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"copy uploads/missing.txt archive/missing.txt"},
+			expectedError:      errors.ErrorTypeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGCS("uploads/a.txt")
+			adapter := newTestGCSAdapter(t, fake)
+
+			err := adapter.MoveObject(context.Background(), tt.srcKey, tt.dstKey)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedObjects, slices.Collect(maps.Keys(fake.objects)))
+			assert.Equal(t, tt.expectedOperations, fake.operations)
+		})
+	}
+}
@@ -2,11 +2,20 @@ package storage
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
 
 	"golang-boilerplate/internal/logger"
@@ -17,6 +26,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 type S3Adapter struct {
@@ -39,6 +50,7 @@ func NewS3Adapter(config *config.Config) (*S3Adapter, error) {
 	)
 	if err != nil {
 		return nil, errors.ExternalServiceError("failed to load AWS config", err).
+			WithProvider(constants.StorageProviderS3).
 			WithOperation("load_aws_config").
 			WithResource("storage")
 	}
@@ -53,7 +65,27 @@ func NewS3Adapter(config *config.Config) (*S3Adapter, error) {
 	}, nil
 }
 
+// NewTenantS3Adapter creates an S3 adapter for the bucket and keys of a tenant, keeping the
+// other storage settings of config
+func NewTenantS3Adapter(config *config.Config, region, bucket, accessKey, secretKey string) (*S3Adapter, error) {
+	tenantConfig := *config
+	tenantConfig.S3Region = region
+	tenantConfig.S3Bucket = bucket
+	tenantConfig.S3AccessKey = accessKey
+	tenantConfig.S3SecretKey = secretKey
+
+	return NewS3Adapter(&tenantConfig)
+}
+
 func (a *S3Adapter) UploadFile(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error) {
+	sha, _, err := fileChecksums(file)
+	if err != nil {
+		logger.Sugar.Errorf("failed to read file: %v", err)
+		return nil, errors.ExternalServiceError("failed to read file", err).
+			WithOperation("checksum_file").
+			WithResource("storage")
+	}
+
 	f, err := file.Open()
 	if err != nil {
 		logger.Sugar.Errorf("failed to open file: %v", err)
@@ -66,15 +98,33 @@ func (a *S3Adapter) UploadFile(ctx context.Context, file *multipart.FileHeader,
 	// Use S3 uploader for efficient uploads
 	uploader := manager.NewUploader(a.client)
 
-	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(a.bucket),
-		Key:         aws.String(key),
-		Body:        f,
-		ContentType: aws.String(file.Header.Get("Content-Type")),
-	})
+	input := &s3.PutObjectInput{
+		Bucket:            aws.String(a.bucket),
+		Key:               aws.String(key),
+		Body:              f,
+		ContentType:       aws.String(file.Header.Get("Content-Type")),
+		Metadata:          map[string]string{constants.StorageChecksumMetadataKey: hex.EncodeToString(sha)},
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	}
+	// S3 rejects an object uploaded at once whose content does not match the SHA-256, and
+	// each part of the others with the one the SDK computes for it
+	if file.Size < uploader.PartSize {
+		input.ChecksumSHA256 = aws.String(base64.StdEncoding.EncodeToString(sha))
+	}
+
+	_, err = uploader.Upload(ctx, input)
 	if err != nil {
 		logger.Sugar.Errorf("failed to upload to S3: %v", err)
+		var apiErr smithy.APIError
+		if stderrors.As(err, &apiErr) && apiErr.ErrorCode() == "BadDigest" {
+			return nil, errors.ChecksumMismatchError("uploaded content does not match its checksum", err).
+				WithProvider(constants.StorageProviderS3).
+				WithOperation("upload_to_s3").
+				WithResource("storage").
+				WithContext("key", key)
+		}
 		return nil, errors.ExternalServiceError("failed to upload to S3", err).
+			WithProvider(constants.StorageProviderS3).
 			WithOperation("upload_to_s3").
 			WithResource("storage")
 	}
@@ -86,9 +136,274 @@ func (a *S3Adapter) UploadFile(ctx context.Context, file *multipart.FileHeader,
 		Key:      key,
 		Bucket:   a.bucket,
 		Location: url,
+		Checksum: hex.EncodeToString(sha),
 	}, nil
 }
 
+// DeleteFile removes the object stored under key. S3 treats deleting a missing key as success.
+func (a *S3Adapter) DeleteFile(ctx context.Context, key string) error {
+	_, err := a.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		logger.Sugar.Errorf("failed to delete S3 object: %v", err)
+		return errors.ExternalServiceError("failed to delete S3 object", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("delete_file").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	return nil
+}
+
+// DeleteFiles removes the objects stored under keys with DeleteObjects requests of up to
+// constants.StorageDeleteBatchSize keys. A failed request does not stop the next ones.
+func (a *S3Adapter) DeleteFiles(ctx context.Context, keys []string) error {
+	var failed []string
+	var errs []error
+	for batch := range slices.Chunk(keys, constants.StorageDeleteBatchSize) {
+		objects := make([]types.ObjectIdentifier, len(batch))
+		for i, key := range batch {
+			objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+		}
+
+		output, err := a.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(a.bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			failed = append(failed, batch...)
+			errs = append(errs, err)
+			continue
+		}
+		for _, objectErr := range output.Errors {
+			key := aws.ToString(objectErr.Key)
+			failed = append(failed, key)
+			errs = append(errs, fmt.Errorf("%s: %s: %s", key, aws.ToString(objectErr.Code), aws.ToString(objectErr.Message)))
+		}
+	}
+
+	if len(failed) > 0 {
+		err := stderrors.Join(errs...)
+		logger.Sugar.Errorf("failed to delete S3 objects: %v", err)
+		return errors.ExternalServiceError("failed to delete S3 objects", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("delete_files").
+			WithResource("storage").
+			WithContext("keys", len(keys)).
+			WithContext("failed_keys", failed)
+	}
+
+	return nil
+}
+
+// ListObjects lists the objects under prefix with ListObjectsV2, whose continuation token
+// is the cursor
+func (a *S3Adapter) ListObjects(ctx context.Context, prefix string, cursor string, limit int) (*ObjectPage, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(a.bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(int32(listLimit(limit))),
+	}
+	if cursor != "" {
+		input.ContinuationToken = aws.String(cursor)
+	}
+
+	output, err := a.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		logger.Sugar.Errorf("failed to list S3 objects: %v", err)
+		return nil, errors.ExternalServiceError("failed to list S3 objects", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("list_objects").
+			WithResource("storage").
+			WithContext("prefix", prefix)
+	}
+
+	page := &ObjectPage{Objects: make([]ObjectInfo, 0, len(output.Contents))}
+	for _, object := range output.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:       aws.ToString(object.Key),
+			Size:      aws.ToInt64(object.Size),
+			ETag:      strings.Trim(aws.ToString(object.ETag), `"`),
+			UpdatedAt: aws.ToTime(object.LastModified),
+		})
+	}
+	if aws.ToBool(output.IsTruncated) {
+		page.NextCursor = aws.ToString(output.NextContinuationToken)
+	}
+
+	return page, nil
+}
+
+// HeadObject returns the metadata of the object stored under key. The checksum is the
+// SHA-256 recorded on upload, or else the additional checksum the object was uploaded
+// with, or else the MD5 digest its ETag is for the objects neither uploaded in parts nor
+// encrypted with a KMS key.
+func (a *S3Adapter) HeadObject(ctx context.Context, key string) (*ObjectMetadata, error) {
+	output, err := a.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(a.bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, a.objectError(err, "failed to get S3 object metadata", "head_object", key)
+	}
+
+	etag := strings.Trim(aws.ToString(output.ETag), `"`)
+	return &ObjectMetadata{
+		Key:         key,
+		Size:        aws.ToInt64(output.ContentLength),
+		ContentType: aws.ToString(output.ContentType),
+		Checksum:    s3Checksum(output, etag),
+		ETag:        etag,
+		UpdatedAt:   aws.ToTime(output.LastModified),
+	}, nil
+}
+
+// CopyObject copies the object within the bucket. S3 copies objects of up to 5 GB in one
+// request.
+func (a *S3Adapter) CopyObject(ctx context.Context, srcKey string, dstKey string) error {
+	_, err := a.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(a.bucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String((&url.URL{Path: a.bucket + "/" + srcKey}).EscapedPath()),
+	})
+	if err != nil {
+		return a.objectError(err, "failed to copy S3 object", "copy_object", srcKey).
+			WithContext("destination_key", dstKey)
+	}
+
+	return nil
+}
+
+func (a *S3Adapter) MoveObject(ctx context.Context, srcKey string, dstKey string) error {
+	// Copying an object onto itself then deleting the source would delete it
+	if srcKey == dstKey {
+		_, err := a.HeadObject(ctx, srcKey)
+		return err
+	}
+
+	if err := a.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	return a.DeleteFile(ctx, srcKey)
+}
+
+// objectError returns a missing object as not found and the other errors as errors of S3
+func (a *S3Adapter) objectError(err error, message string, operation string, key string) *errors.AppError {
+	var notFound *types.NotFound
+	var noSuchKey *types.NoSuchKey
+	var apiErr smithy.APIError
+	if stderrors.As(err, &notFound) || stderrors.As(err, &noSuchKey) ||
+		(stderrors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey") {
+		return errors.NotFoundError("File", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation(operation).
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	logger.Sugar.Errorf("%s: %v", message, err)
+	return errors.ExternalServiceError(message, err).
+		WithProvider(constants.StorageProviderS3).
+		WithOperation(operation).
+		WithResource("storage").
+		WithContext("key", key)
+}
+
+// s3Checksum returns the SHA-256 recorded on upload, or else the strongest additional
+// checksum of the object, or else the MD5 digest of its ETag when it is one
+func s3Checksum(output *s3.HeadObjectOutput, etag string) string {
+	if checksum := metadataChecksum(output.Metadata); checksum != "" {
+		return checksum
+	}
+
+	checksums := []struct {
+		algorithm string
+		value     *string
+	}{
+		{"sha256", output.ChecksumSHA256},
+		{"sha1", output.ChecksumSHA1},
+		{"crc64nvme", output.ChecksumCRC64NVME},
+		{"crc32c", output.ChecksumCRC32C},
+		{"crc32", output.ChecksumCRC32},
+	}
+	for _, checksum := range checksums {
+		// Checksums of the objects uploaded in parts are checksums of their parts, e.g. "...-3"
+		if value := aws.ToString(checksum.value); value != "" && !strings.Contains(value, "-") {
+			return checksum.algorithm + ":" + value
+		}
+	}
+
+	// The ETags of the objects encrypted with a KMS key are not digests of their content
+	if output.ServerSideEncryption == types.ServerSideEncryptionAwsKms || output.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse {
+		return ""
+	}
+	if digest, err := hex.DecodeString(etag); err == nil && len(digest) == md5.Size {
+		return "md5:" + base64.StdEncoding.EncodeToString(digest)
+	}
+	return ""
+}
+
+// OpenFile streams the object stored under key
+func (a *S3Adapter) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	return a.OpenFileRange(ctx, key, 0, -1)
+}
+
+// OpenFileRange streams length bytes of the object stored under key from offset, up to
+// its end when length is negative
+func (a *S3Adapter) OpenFileRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	}
+	switch {
+	case length >= 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	output, err := a.client.GetObject(ctx, input)
+	var noSuchKey *types.NoSuchKey
+	if stderrors.As(err, &noSuchKey) {
+		return nil, errors.NotFoundError("File", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("open_file").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+	if err != nil {
+		logger.Sugar.Errorf("failed to open S3 object: %v", err)
+		return nil, errors.ExternalServiceError("failed to open S3 object", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("open_file").
+			WithResource("storage").
+			WithContext("key", key)
+	}
+
+	return output.Body, nil
+}
+
+// Ping checks that the bucket exists and the credentials can access it
+func (a *S3Adapter) Ping(ctx context.Context) error {
+	_, err := a.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(a.bucket)})
+	if err != nil {
+		return errors.ExternalServiceError("failed to reach S3 bucket", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("ping_storage").
+			WithResource("storage").
+			WithContext("bucket", a.bucket)
+	}
+	return nil
+}
+
+func (a *S3Adapter) Bucket() string {
+	return a.bucket
+}
+
 func (a *S3Adapter) GetObjectURL(key string) string {
 	// Public URL pattern for S3
 	// Format: https://<bucket>.s3.<region>.amazonaws.com/<key>
@@ -115,6 +430,7 @@ func (a *S3Adapter) GetPresignedURL(ctx context.Context, key string, duration ..
 	if err != nil {
 		logger.Sugar.Errorf("failed to generate presigned URL: %v", err)
 		return "", errors.ExternalServiceError("failed to generate presigned URL", err).
+			WithProvider(constants.StorageProviderS3).
 			WithOperation("generate_presigned_url").
 			WithResource("storage")
 	}
@@ -122,31 +438,38 @@ func (a *S3Adapter) GetPresignedURL(ctx context.Context, key string, duration ..
 	return request.URL, nil
 }
 
-func (a *S3Adapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*BatchUploadResult, error) {
-	result := &BatchUploadResult{Files: make([]UploadResult, 0, len(files))}
-	type uploadResult struct {
-		result *UploadResult
-		err    error
-	}
-
-	ch := make(chan uploadResult, len(files))
-
-	for _, fh := range files {
-		go func(fh *multipart.FileHeader) {
-			res, err := a.UploadFile(ctx, fh, fh.Filename)
-			ch <- uploadResult{res, err}
-		}(fh)
-	}
-
-	for i := 0; i < len(files); i++ {
-		r := <-ch
-		if r.err != nil {
-			return nil, errors.ExternalServiceError("failed to upload file", r.err).
-				WithOperation("upload_file").
-				WithResource("storage")
+// PresignUpload returns a presigned POST of an object of contentType and of up to maxSize
+// bytes under key
+func (a *S3Adapter) PresignUpload(ctx context.Context, key string, contentType string, maxSize int64) (*PresignedUpload, error) {
+	presigner := s3.NewPresignClient(a.client)
+	request, err := presigner.PresignPostObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignPostOptions) {
+		opts.Expires = constants.PresignedUploadDuration
+		opts.Conditions = []any{
+			map[string]string{"Content-Type": contentType},
+			[]any{"content-length-range", 1, maxSize},
 		}
-		result.Files = append(result.Files, *r.result)
+	})
+	if err != nil {
+		logger.Sugar.Errorf("failed to presign S3 upload: %v", err)
+		return nil, errors.ExternalServiceError("failed to presign S3 upload", err).
+			WithProvider(constants.StorageProviderS3).
+			WithOperation("presign_upload").
+			WithResource("storage").
+			WithContext("key", key)
 	}
 
-	return result, nil
+	// The policy requires the content type, which the client posts with the other fields
+	request.Values["Content-Type"] = contentType
+	return &PresignedUpload{
+		URL:       request.URL,
+		Fields:    request.Values,
+		ExpiresAt: time.Now().Add(constants.PresignedUploadDuration),
+	}, nil
+}
+
+func (a *S3Adapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*BatchUploadResult, error) {
+	return uploadFiles(ctx, constants.StorageProviderS3, files, a.config.StorageUploadConcurrency, a.UploadFile)
 }
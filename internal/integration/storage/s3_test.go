@@ -0,0 +1,431 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testS3Bucket = "test-bucket"
+
+// fakeS3 is a bucket in memory served with the S3 REST API, addressed by path
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	// deleteBatches are the keys of the DeleteObjects requests received
+	deleteBatches [][]string
+	// deniedKeys are refused by DeleteObjects, and failedBatch is the index of the
+	// DeleteObjects request failing as a whole, -1 for none
+	deniedKeys  map[string]bool
+	failedBatch int
+	// maxKeys are the page sizes of the ListObjectsV2 requests received
+	maxKeys []string
+	// operations are the copies, deletes and heads of single objects received
+	operations []string
+}
+
+func newFakeS3(keys ...string) *fakeS3 {
+	f := &fakeS3{objects: map[string]string{}, deniedKeys: map[string]bool{}, failedBatch: -1}
+	for _, key := range keys {
+		f.objects[key] = "content of " + key
+	}
+	return f
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		f.deleteObjects(w, r)
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.listObjects(w, r)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		f.copyObject(w, r)
+	case r.Method == http.MethodDelete:
+		key := strings.TrimPrefix(r.URL.Path, "/"+testS3Bucket+"/")
+		f.operations = append(f.operations, "delete "+key)
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead:
+		key := strings.TrimPrefix(r.URL.Path, "/"+testS3Bucket+"/")
+		f.operations = append(f.operations, "head "+key)
+		content, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Header().Set("ETag", `"etag-`+key+`"`)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method+" "+r.URL.String())
+	}
+}
+
+func (f *fakeS3) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	batch := make([]string, len(request.Objects))
+	for i, object := range request.Objects {
+		batch[i] = object.Key
+	}
+	f.deleteBatches = append(f.deleteBatches, batch)
+	if len(f.deleteBatches)-1 == f.failedBatch {
+		writeS3Error(w, http.StatusInternalServerError, "InternalError", "We encountered an internal error")
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(`<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	for _, key := range batch {
+		if f.deniedKeys[key] {
+			fmt.Fprintf(&response, "<Error><Key>%s</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", key)
+			continue
+		}
+		delete(f.objects, key)
+	}
+	response.WriteString("</DeleteResult>")
+	writeS3XML(w, response.String())
+}
+
+// listObjects lists the keys after the one of the continuation token, "after:<key>"
+func (f *fakeS3) listObjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	f.maxKeys = append(f.maxKeys, query.Get("max-keys"))
+	maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "max-keys")
+		return
+	}
+	after, hasToken := strings.CutPrefix(query.Get("continuation-token"), "after:")
+	if query.Has("continuation-token") && !hasToken {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect")
+		return
+	}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, query.Get("prefix")) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	truncated := len(keys) > maxKeys
+	if truncated {
+		keys = keys[:maxKeys]
+	}
+
+	var response strings.Builder
+	response.WriteString(`<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	fmt.Fprintf(&response, "<Name>%s</Name><KeyCount>%d</KeyCount><MaxKeys>%d</MaxKeys><IsTruncated>%t</IsTruncated>", testS3Bucket, len(keys), maxKeys, truncated)
+	if truncated {
+		fmt.Fprintf(&response, "<NextContinuationToken>after:%s</NextContinuationToken>", keys[len(keys)-1])
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&response, `<Contents><Key>%s</Key><LastModified>2026-01-01T00:00:00.000Z</LastModified><ETag>"etag-%s"</ETag><Size>%d</Size></Contents>`, key, key, len(f.objects[key]))
+	}
+	response.WriteString("</ListBucketResult>")
+	writeS3XML(w, response.String())
+}
+
+// copyObject copies the object of the X-Amz-Copy-Source header, <bucket>/<escaped key>
+func (f *fakeS3) copyObject(w http.ResponseWriter, r *http.Request) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "x-amz-copy-source")
+		return
+	}
+	srcKey := strings.TrimPrefix(source, testS3Bucket+"/")
+	dstKey := strings.TrimPrefix(r.URL.Path, "/"+testS3Bucket+"/")
+	f.operations = append(f.operations, "copy "+srcKey+" "+dstKey)
+
+	content, ok := f.objects[srcKey]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	f.objects[dstKey] = content
+	writeS3XML(w, `<CopyObjectResult><ETag>"etag-`+dstKey+`"</ETag><LastModified>2026-01-01T00:00:00.000Z</LastModified></CopyObjectResult>`)
+}
+
+func writeS3XML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>`+body)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, message)
+}
+
+// newTestS3Adapter returns an adapter of the bucket of fake, without retries
+func newTestS3Adapter(t *testing.T, fake *fakeS3) *S3Adapter {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		BaseEndpoint:               aws.String(server.URL),
+		UsePathStyle:               true,
+		Region:                     "us-east-1",
+		Credentials:                credentials.NewStaticCredentialsProvider("test", "test", ""),
+		RetryMaxAttempts:           1,
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+	return &S3Adapter{config: &config.Config{S3Region: "us-east-1", S3Bucket: testS3Bucket}, client: client, bucket: testS3Bucket}
+}
+
+func TestS3Adapter_DeleteFiles(t *testing.T) {
+	keys := func(n int) []string {
+		keys := make([]string, n)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("uploads/%04d.txt", i)
+		}
+		return keys
+	}
+
+	t.Run("batches of StorageDeleteBatchSize keys", func(t *testing.T) {
+		all := keys(2*constants.StorageDeleteBatchSize + 500)
+		fake := newFakeS3(all...)
+		adapter := newTestS3Adapter(t, fake)
+
+		err := adapter.DeleteFiles(context.Background(), append(all, "uploads/missing.txt"))
+
+		require.NoError(t, err)
+		require.Len(t, fake.deleteBatches, 3)
+		assert.Len(t, fake.deleteBatches[0], constants.StorageDeleteBatchSize)
+		assert.Len(t, fake.deleteBatches[1], constants.StorageDeleteBatchSize)
+		assert.Len(t, fake.deleteBatches[2], 501)
+		assert.Empty(t, fake.objects)
+	})
+
+	t.Run("keys refused by S3", func(t *testing.T) {
+		fake := newFakeS3("a.txt", "b.txt", "c.txt")
+		fake.deniedKeys["b.txt"] = true
+		adapter := newTestS3Adapter(t, fake)
+
+		err := adapter.DeleteFiles(context.Background(), []string{"a.txt", "b.txt", "c.txt"})
+
+		require.Error(t, err)
+		appErr := errors.GetAppError(err)
+		assert.Equal(t, errors.ErrorTypeExternal, appErr.Type)
+		assert.Equal(t, []string{"b.txt"}, appErr.Context["failed_keys"])
+		assert.Equal(t, map[string]string{"b.txt": "content of b.txt"}, fake.objects)
+	})
+
+	t.Run("a failed request does not stop the next ones", func(t *testing.T) {
+		all := keys(constants.StorageDeleteBatchSize + 2)
+		fake := newFakeS3(all...)
+		fake.failedBatch = 0
+		adapter := newTestS3Adapter(t, fake)
+
+		err := adapter.DeleteFiles(context.Background(), all)
+
+		require.Error(t, err)
+		assert.Equal(t, all[:constants.StorageDeleteBatchSize], errors.GetAppError(err).Context["failed_keys"])
+		assert.Len(t, fake.deleteBatches, 2)
+		assert.Len(t, fake.objects, constants.StorageDeleteBatchSize)
+	})
+}
+
+func TestS3Adapter_ListObjects(t *testing.T) {
+	fake := newFakeS3("uploads/a.txt", "uploads/b.txt", "uploads/c.txt", "uploads/d.txt", "uploads/e.txt", "other/f.txt")
+	adapter := newTestS3Adapter(t, fake)
+	ctx := context.Background()
+
+	var pages [][]string
+	cursor := ""
+	for {
+		page, err := adapter.ListObjects(ctx, "uploads/", cursor, 2)
+		require.NoError(t, err)
+		keys := make([]string, len(page.Objects))
+		for i, object := range page.Objects {
+			keys[i] = object.Key
+		}
+		pages = append(pages, keys)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, [][]string{{"uploads/a.txt", "uploads/b.txt"}, {"uploads/c.txt", "uploads/d.txt"}, {"uploads/e.txt"}}, pages)
+
+	page, err := adapter.ListObjects(ctx, "uploads/e", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []ObjectInfo{{
+		Key:       "uploads/e.txt",
+		Size:      int64(len("content of uploads/e.txt")),
+		ETag:      "etag-uploads/e.txt",
+		UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}, page.Objects)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = adapter.ListObjects(ctx, "uploads/", "", constants.StorageListMaxLimit+1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "2", "2", "100", "1000"}, fake.maxKeys, "the page sizes are bounded")
+
+	_, err = adapter.ListObjects(ctx, "uploads/", "forged", 2)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+}
+
+func TestS3Adapter_CopyObject(t *testing.T) {
+	fake := newFakeS3("uploads/a b.txt")
+	adapter := newTestS3Adapter(t, fake)
+
+	err := adapter.CopyObject(context.Background(), "uploads/a b.txt", "archive/a b.txt")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"uploads/a b.txt": "content of uploads/a b.txt", "archive/a b.txt": "content of uploads/a b.txt"}, fake.objects)
+
+	err = adapter.CopyObject(context.Background(), "uploads/missing.txt", "archive/missing.txt")
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeNotFound, errors.GetAppError(err).Type)
+	assert.NotContains(t, fake.objects, "archive/missing.txt")
+}
+
+func TestS3Adapter_MoveObject(t *testing.T) {
+	tests := []struct {
+		name               string
+		srcKey             string
+		dstKey             string
+		expectedObjects    []string
+		expectedOperations []string
+		expectedError      errors.ErrorType
+	}{
+		{
+			name:               "other key",
+			srcKey:             "uploads/a.txt",
+			dstKey:             "archive/a.txt",
+			expectedObjects:    []string{"archive/a.txt"},
+			expectedOperations: []string{"copy uploads/a.txt archive/a.txt", "delete uploads/a.txt"},
+		},
+		{
+			name:               "same key",
+			srcKey:             "uploads/a.txt",
+			dstKey:             "uploads/a.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"head uploads/a.txt"},
+		},
+		{
+			name:               "same key of a missing object",
+			srcKey:             "uploads/missing.txt",
+			dstKey:             "uploads/missing.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"head uploads/missing.txt"},
+			expectedError:      errors.ErrorTypeNotFound,
+		},
+		{
+			name:               "missing source",
+			srcKey:             "uploads/missing.txt",
+			dstKey:             "archive/missing.txt",
+			expectedObjects:    []string{"uploads/a.txt"},
+			expectedOperations: []string{"copy uploads/missing.txt archive/missing.txt"},
+			expectedError:      errors.ErrorTypeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeS3("uploads/a.txt")
+			adapter := newTestS3Adapter(t, fake)
+
+			err := adapter.MoveObject(context.Background(), tt.srcKey, tt.dstKey)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedObjects, slices.Collect(maps.Keys(fake.objects)))
+			assert.Equal(t, tt.expectedOperations, fake.operations)
+		})
+	}
+}
+
+func TestS3Checksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   *s3.HeadObjectOutput
+		etag     string
+		expected string
+	}{
+		{
+			name: "SHA-256 recorded on upload first",
+			output: &s3.HeadObjectOutput{
+				Metadata:       map[string]string{constants.StorageChecksumMetadataKey: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+				ChecksumCRC32C: aws.String("yZRlqg=="),
+			},
+			etag:     "5eb63bbbe01eeed093cb22bb8f5acdc3",
+			expected: "sha256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+		},
+		{
+			name:     "strongest additional checksum",
+			output:   &s3.HeadObjectOutput{ChecksumSHA256: aws.String("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="), ChecksumCRC32C: aws.String("yZRlqg==")},
+			etag:     "5eb63bbbe01eeed093cb22bb8f5acdc3",
+			expected: "sha256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+		},
+		{
+			name:     "checksums of the parts skipped",
+			output:   &s3.HeadObjectOutput{ChecksumSHA256: aws.String("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=-3"), ChecksumCRC32C: aws.String("yZRlqg==")},
+			etag:     "b54357faf0632cce46e942fa68356b38-3",
+			expected: "crc32c:yZRlqg==",
+		},
+		{
+			name:     "MD5 digest of the ETag",
+			output:   &s3.HeadObjectOutput{},
+			etag:     "5eb63bbbe01eeed093cb22bb8f5acdc3",
+			expected: "md5:XrY7u+Ae7tCTyyK7j1rNww==",
+		},
+		{
+			name:   "ETag of an object uploaded in parts",
+			output: &s3.HeadObjectOutput{},
+			etag:   "b54357faf0632cce46e942fa68356b38-3",
+		},
+		{
+			name:   "ETag of an object encrypted with a KMS key",
+			output: &s3.HeadObjectOutput{ServerSideEncryption: types.ServerSideEncryptionAwsKms},
+			etag:   "5eb63bbbe01eeed093cb22bb8f5acdc3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s3Checksum(tt.output, tt.etag))
+		})
+	}
+}
@@ -2,12 +2,22 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
 	"fmt"
 	"golang-boilerplate/internal/config"
 	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/monitoring"
+	"hash/crc32"
+	"io"
 	"mime/multipart"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 // UploadResult represents the result of a single file upload to a storage backend
@@ -16,19 +26,186 @@ type UploadResult struct {
 	Key      string `json:"key"`
 	Bucket   string `json:"bucket"`
 	Location string `json:"location"`
+	// Checksum is the hex SHA-256 of the uploaded content
+	Checksum string `json:"checksum"`
 }
 
-// BatchUploadResult represents the result of a multiple file upload operation
+// BatchUploadResult represents the result of a multiple file upload operation: the
+// uploaded files, in the order they were given, and the keys of the files that were not
 type BatchUploadResult struct {
-	Files []UploadResult
+	Files  []UploadResult
+	Failed []string
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	ETag      string    `json:"etag"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ObjectMetadata describes a stored object with its content. Checksum is the checksum the
+// provider keeps for the content, as <algorithm>:<base64 digest>, e.g. md5:XUFAKrxLKna5cZ2REBfFkg==,
+// or empty when it keeps none, e.g. for the objects uploaded in parts.
+type ObjectMetadata struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	Checksum    string    `json:"checksum,omitempty"`
+	ETag        string    `json:"etag"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ObjectPage is a page of the objects under a prefix, in the lexicographic order of their
+// keys. NextCursor lists the next page and is empty on the last one.
+type ObjectPage struct {
+	Objects    []ObjectInfo `json:"objects"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// PresignedUpload is an upload of an object straight to the bucket, without going
+// through the server: a multipart/form-data POST to URL of the Fields, then of the
+// content in a last "file" field, before ExpiresAt. The storage refuses another key,
+// another content type or a larger content.
+type PresignedUpload struct {
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt time.Time         `json:"expires_at"`
 }
 
 // StorageAdapter defines the interface for storage operations
 type StorageAdapter interface {
+	// UploadFile uploads file under key with the SHA-256 of its content in the
+	// StorageChecksumMetadataKey metadata. The storage verifies the checksum of the content
+	// it receives and rejects a corrupted transfer.
 	UploadFile(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error)
+	// UploadFiles uploads files under their filename, at most STORAGE_UPLOAD_CONCURRENCY
+	// at once. A failed upload does not stop the others; the result lists the uploaded
+	// and the failed files, with an error whose context lists the failed keys.
 	UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*BatchUploadResult, error)
+	// Bucket returns the name of the bucket the objects are stored in
+	Bucket() string
 	GetObjectURL(key string) string
 	GetPresignedURL(ctx context.Context, key string, duration ...time.Duration) (string, error)
+	// PresignUpload returns an upload of an object of contentType and of up to maxSize
+	// bytes under key, posted by the client straight to the bucket
+	PresignUpload(ctx context.Context, key string, contentType string, maxSize int64) (*PresignedUpload, error)
+	DeleteFile(ctx context.Context, key string) error
+	// DeleteFiles removes the objects stored under keys. Missing objects are not an error;
+	// the keys whose object could not be deleted are in the context of the error.
+	DeleteFiles(ctx context.Context, keys []string) error
+	// ListObjects returns a page of up to limit objects whose key starts with prefix,
+	// from the start with an empty cursor or from the NextCursor of the previous page
+	ListObjects(ctx context.Context, prefix string, cursor string, limit int) (*ObjectPage, error)
+	// HeadObject returns the metadata of the object stored under key, not found when
+	// there is none
+	HeadObject(ctx context.Context, key string) (*ObjectMetadata, error)
+	// CopyObject copies the object stored under srcKey to dstKey, replacing the object
+	// stored there; the source missing is not found
+	CopyObject(ctx context.Context, srcKey string, dstKey string) error
+	// MoveObject copies the object stored under srcKey to dstKey then deletes the source.
+	// When the delete fails the object is under both keys, and moving again completes it.
+	// Moving an object to its own key leaves it in place.
+	MoveObject(ctx context.Context, srcKey string, dstKey string) error
+	// OpenFile streams the object stored under key; the caller closes the reader
+	OpenFile(ctx context.Context, key string) (io.ReadCloser, error)
+	// OpenFileRange streams length bytes of the object stored under key from offset, up
+	// to its end when length is negative; the caller closes the reader
+	OpenFileRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error)
+	// Ping checks that the bucket is reachable with the configured credentials
+	Ping(ctx context.Context) error
+}
+
+// ProvideHealthChecker registers the storage in the health registry
+func ProvideHealthChecker(adapter StorageAdapter) monitoring.HealthChecker {
+	return monitoring.HealthChecker{
+		Name:     constants.DependencyStorage,
+		Critical: true,
+		Check:    adapter.Ping,
+	}
+}
+
+// listLimit returns the size of a page of objects, the default one when limit is not
+// positive and at most constants.StorageListMaxLimit
+func listLimit(limit int) int {
+	if limit <= 0 {
+		return constants.StorageListDefaultLimit
+	}
+	return min(limit, constants.StorageListMaxLimit)
+}
+
+// fileChecksums returns the SHA-256 and CRC32C digests of the content of file, sent with
+// its upload for the storage to verify it
+func fileChecksums(file *multipart.FileHeader) ([]byte, uint32, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	sha := sha256.New()
+	crc := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if _, err := io.Copy(io.MultiWriter(sha, crc), f); err != nil {
+		return nil, 0, err
+	}
+	return sha.Sum(nil), crc.Sum32(), nil
+}
+
+// metadataChecksum returns the SHA-256 recorded in the metadata of an object on upload as
+// sha256:<base64 digest>, empty when there is none
+func metadataChecksum(metadata map[string]string) string {
+	digest, err := hex.DecodeString(metadata[constants.StorageChecksumMetadataKey])
+	if err != nil || len(digest) != sha256.Size {
+		return ""
+	}
+	return "sha256:" + base64.StdEncoding.EncodeToString(digest)
+}
+
+// uploadFiles uploads files under their filename with upload, at most concurrency at once.
+// Once ctx is done the files not started yet are not uploaded, while the uploads in flight
+// are waited for, so that the result reports every file.
+func uploadFiles(ctx context.Context, provider string, files []*multipart.FileHeader, concurrency int, upload func(context.Context, *multipart.FileHeader, string) (*UploadResult, error)) (*BatchUploadResult, error) {
+	results := make([]*UploadResult, len(files))
+	errs := make([]error, len(files))
+
+	var group errgroup.Group
+	group.SetLimit(max(concurrency, 1))
+	for i, file := range files {
+		group.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return nil
+			}
+			results[i], errs[i] = upload(ctx, file, file.Filename)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	result := &BatchUploadResult{Files: make([]UploadResult, 0, len(files))}
+	var failedErrs []error
+	for i, file := range files {
+		if errs[i] != nil {
+			result.Failed = append(result.Failed, file.Filename)
+			failedErrs = append(failedErrs, fmt.Errorf("%s: %w", file.Filename, errs[i]))
+			continue
+		}
+		result.Files = append(result.Files, *results[i])
+	}
+
+	if len(result.Failed) > 0 {
+		err := stderrors.Join(failedErrs...)
+		logger.Sugar.Errorf("failed to upload %s objects: %v", provider, err)
+		return result, errors.ExternalServiceError("failed to upload files", err).
+			WithProvider(provider).
+			WithOperation("upload_files").
+			WithResource("storage").
+			WithContext("files", len(files)).
+			WithContext("failed_keys", result.Failed)
+	}
+
+	return result, nil
 }
 
 func ProvideStorageAdapter(config *config.Config) (StorageAdapter, error) {
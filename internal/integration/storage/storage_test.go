@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+func newFileHeaders(filenames ...string) []*multipart.FileHeader {
+	files := make([]*multipart.FileHeader, len(filenames))
+	for i, filename := range filenames {
+		files[i] = &multipart.FileHeader{Filename: filename}
+	}
+	return files
+}
+
+func TestUploadFiles(t *testing.T) {
+	t.Run("bounded concurrency", func(t *testing.T) {
+		var inFlight, maxInFlight atomic.Int32
+		upload := func(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return &UploadResult{Key: key}, nil
+		}
+
+		result, err := uploadFiles(context.Background(), constants.StorageProviderS3, newFileHeaders("a", "b", "c", "d", "e", "f", "g", "h"), 3, upload)
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+		keys := make([]string, len(result.Files))
+		for i, file := range result.Files {
+			keys[i] = file.Key
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "h"}, keys)
+		assert.Empty(t, result.Failed)
+	})
+
+	t.Run("failed uploads do not stop the others", func(t *testing.T) {
+		upload := func(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error) {
+			if key == "b" || key == "d" {
+				return nil, assert.AnError
+			}
+			return &UploadResult{Key: key}, nil
+		}
+
+		result, err := uploadFiles(context.Background(), constants.StorageProviderGCS, newFileHeaders("a", "b", "c", "d"), 2, upload)
+
+		require.Error(t, err)
+		appErr := errors.GetAppError(err)
+		assert.Equal(t, errors.ErrorTypeExternal, appErr.Type)
+		assert.Equal(t, []string{"b", "d"}, appErr.Context["failed_keys"])
+		assert.Equal(t, []UploadResult{{Key: "a"}, {Key: "c"}}, result.Files)
+		assert.Equal(t, []string{"b", "d"}, result.Failed)
+	})
+
+	t.Run("canceled context stops the files not started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var uploaded atomic.Int32
+		upload := func(ctx context.Context, file *multipart.FileHeader, key string) (*UploadResult, error) {
+			uploaded.Add(1)
+			// The upload in flight completes once the context is canceled
+			cancel()
+			return &UploadResult{Key: key}, nil
+		}
+
+		result, err := uploadFiles(ctx, constants.StorageProviderS3, newFileHeaders("a", "b", "c"), 1, upload)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(1), uploaded.Load())
+		assert.Equal(t, []UploadResult{{Key: "a"}}, result.Files)
+		assert.Equal(t, []string{"b", "c"}, result.Failed)
+	})
+}
+
+func TestFileChecksums(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "a.txt")
+	require.NoError(t, err)
+	part.Write([]byte("hello world"))
+	require.NoError(t, writer.Close())
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+
+	sha, crc, err := fileChecksums(form.File["file"][0])
+
+	require.NoError(t, err)
+	assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", hex.EncodeToString(sha))
+	assert.Equal(t, uint32(0xc99465aa), crc)
+}
+
+func TestMetadataChecksum(t *testing.T) {
+	assert.Equal(t, "sha256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+		metadataChecksum(map[string]string{"sha256": "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"}))
+	assert.Empty(t, metadataChecksum(map[string]string{"sha256": "b94d27b9"}))
+	assert.Empty(t, metadataChecksum(nil))
+}
+
+func TestListLimit(t *testing.T) {
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{limit: 0, expected: constants.StorageListDefaultLimit},
+		{limit: -1, expected: constants.StorageListDefaultLimit},
+		{limit: 1, expected: 1},
+		{limit: constants.StorageListMaxLimit, expected: constants.StorageListMaxLimit},
+		{limit: constants.StorageListMaxLimit + 1, expected: constants.StorageListMaxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.limit), func(t *testing.T) {
+			assert.Equal(t, tt.expected, listLimit(tt.limit))
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Package invoicing renders the invoices of the companies as branded PDF documents. The
+// Template holds the branding, set in the config, and the Invoice the data of a document.
+package invoicing
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+)
+
+// Template is the branding of the invoices
+type Template struct {
+	BrandName string
+	// BrandAddress is printed under the brand name, one line per element
+	BrandAddress []string
+	// AccentColor is the #RRGGBB color of the header band
+	AccentColor string
+	// Footer is printed at the bottom of every page
+	Footer string
+}
+
+// Line is a line of an invoice. Amounts are in the smallest unit of the currency.
+type Line struct {
+	Description string
+	Quantity    int64
+	UnitAmount  int64
+	Amount      int64
+}
+
+// Invoice is the data of an invoice document. Amounts are in the smallest unit of the
+// currency.
+type Invoice struct {
+	Number      string
+	Status      string
+	IssuedAt    time.Time
+	DueAt       *time.Time
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	// BillTo is the name of the billed company
+	BillTo     string
+	Currency   string
+	Lines      []Line
+	Subtotal   int64
+	Tax        int64
+	Total      int64
+	AmountPaid int64
+	AmountDue  int64
+}
+
+// Renderer renders the invoices with the template of the config
+type Renderer struct {
+	template Template
+}
+
+// ProvideRenderer creates the renderer of the invoices branded by the config
+func ProvideRenderer(cfg *config.Config) *Renderer {
+	return NewRenderer(Template{
+		BrandName:    cfg.InvoiceBrandName,
+		BrandAddress: cfg.InvoiceBrandAddress,
+		AccentColor:  cfg.InvoiceAccentColor,
+		Footer:       cfg.InvoiceFooter,
+	})
+}
+
+// NewRenderer creates a renderer of the invoices with template
+func NewRenderer(template Template) *Renderer {
+	return &Renderer{template: template}
+}
+
+// Layout of the table of the lines: the right edges of its columns, the height of its rows
+// and the lowest position of a row on a page
+const (
+	columnQuantity   = pageWidth - margin - 190
+	columnUnitAmount = pageWidth - margin - 95
+	columnAmount     = pageWidth - margin
+	rowHeight        = 20.0
+	bodyBottom       = margin + 40
+)
+
+// Render returns the PDF document of invoice. The lines continue on as many pages as they
+// need, each with the header of the table and the footer.
+func (r *Renderer) Render(invoice Invoice) []byte {
+	doc := &document{}
+	accent := parseColor(r.template.AccentColor)
+
+	content := doc.page()
+	y := r.header(content, accent, invoice)
+	y = r.tableHeader(content, y)
+	for _, item := range invoice.Lines {
+		if y-rowHeight < bodyBottom {
+			content = doc.page()
+			y = r.tableHeader(content, pageHeight-margin)
+		}
+		description := truncate(fontRegular, 10, item.Description, columnQuantity-margin-60)
+		text(content, fontRegular, 10, black, margin+8, y-14, description)
+		textRight(content, fontRegular, 10, black, columnQuantity, y-14, strconv.FormatInt(item.Quantity, 10))
+		textRight(content, fontRegular, 10, black, columnUnitAmount, y-14, formatAmount(item.UnitAmount, invoice.Currency))
+		textRight(content, fontRegular, 10, black, columnAmount-8, y-14, formatAmount(item.Amount, invoice.Currency))
+		line(content, lightGray, margin, pageWidth-margin, y-rowHeight)
+		y -= rowHeight
+	}
+
+	// The totals are kept together on the last page
+	totals := [][2]string{
+		{"Subtotal", formatAmount(invoice.Subtotal, invoice.Currency)},
+		{"Tax", formatAmount(invoice.Tax, invoice.Currency)},
+		{"Total", formatAmount(invoice.Total, invoice.Currency)},
+		{"Amount paid", formatAmount(invoice.AmountPaid, invoice.Currency)},
+		{"Amount due", formatAmount(invoice.AmountDue, invoice.Currency)},
+	}
+	if y-float64(len(totals)+1)*16 < bodyBottom {
+		content = doc.page()
+		y = pageHeight - margin
+	}
+	y -= 24
+	for _, total := range totals {
+		font := fontRegular
+		if total[0] == "Total" || total[0] == "Amount due" {
+			font = fontBold
+		}
+		textRight(content, font, 10, black, columnUnitAmount, y, total[0])
+		textRight(content, font, 10, black, columnAmount-8, y, total[1])
+		y -= 16
+	}
+
+	for i, pageContent := range doc.pages {
+		r.footer(pageContent, i+1, len(doc.pages))
+	}
+	return doc.bytes()
+}
+
+// header draws the brand, the details of the invoice and the billed company, and returns
+// the position under them
+func (r *Renderer) header(content *bytes.Buffer, accent color, invoice Invoice) float64 {
+	rect(content, accent, 0, pageHeight-96, pageWidth, 96)
+	text(content, fontBold, 20, white, margin, pageHeight-58, truncate(fontBold, 20, r.template.BrandName, pageWidth/2))
+	textRight(content, fontBold, 20, white, pageWidth-margin, pageHeight-58, "INVOICE")
+
+	y := pageHeight - 124
+	for _, addressLine := range r.template.BrandAddress {
+		text(content, fontRegular, 9, gray, margin, y, addressLine)
+		y -= 13
+	}
+
+	details := [][2]string{
+		{"Invoice number", invoice.Number},
+		{"Date of issue", invoice.IssuedAt.UTC().Format("January 2, 2006")},
+	}
+	if invoice.DueAt != nil {
+		details = append(details, [2]string{"Date due", invoice.DueAt.UTC().Format("January 2, 2006")})
+	}
+	if invoice.PeriodStart != nil && invoice.PeriodEnd != nil {
+		details = append(details, [2]string{"Period", invoice.PeriodStart.UTC().Format("Jan 2, 2006") + " - " + invoice.PeriodEnd.UTC().Format("Jan 2, 2006")})
+	}
+	details = append(details, [2]string{"Status", strings.ToUpper(invoice.Status)})
+	detailsY := pageHeight - 124
+	for _, detail := range details {
+		textRight(content, fontRegular, 9, gray, pageWidth-margin-150, detailsY, detail[0])
+		textRight(content, fontBold, 9, black, pageWidth-margin, detailsY, detail[1])
+		detailsY -= 13
+	}
+
+	y = min(y, detailsY) - 20
+	text(content, fontBold, 9, gray, margin, y, "BILL TO")
+	text(content, fontBold, 12, black, margin, y-16, truncate(fontBold, 12, invoice.BillTo, pageWidth-2*margin))
+	return y - 40
+}
+
+// tableHeader draws the header of the table of the lines at y and returns the position
+// under it
+func (r *Renderer) tableHeader(content *bytes.Buffer, y float64) float64 {
+	rect(content, lightGray, margin, y-rowHeight, pageWidth-2*margin, rowHeight)
+	text(content, fontBold, 9, gray, margin+8, y-14, "DESCRIPTION")
+	textRight(content, fontBold, 9, gray, columnQuantity, y-14, "QTY")
+	textRight(content, fontBold, 9, gray, columnUnitAmount, y-14, "UNIT PRICE")
+	textRight(content, fontBold, 9, gray, columnAmount-8, y-14, "AMOUNT")
+	return y - rowHeight
+}
+
+// footer draws the footer of the template and the page number
+func (r *Renderer) footer(content *bytes.Buffer, page int, pages int) {
+	line(content, lightGray, margin, pageWidth-margin, margin+18)
+	if r.template.Footer != "" {
+		text(content, fontRegular, 8, gray, margin, margin, truncate(fontRegular, 8, r.template.Footer, pageWidth-2*margin-80))
+	}
+	textRight(content, fontRegular, 8, gray, pageWidth-margin, margin, fmt.Sprintf("Page %d of %d", page, pages))
+}
+
+// zeroDecimalCurrencies are the currencies without a minor unit, whose amounts are not
+// divided by 100
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "JPY": true, "KMF": true, "KRW": true, "MGA": true,
+	"PYG": true, "RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+}
+
+// formatAmount formats an amount in the smallest unit of currency, e.g. USD 1,234.50
+func formatAmount(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	units, cents := amount, int64(-1)
+	if !zeroDecimalCurrencies[currency] {
+		units, cents = amount/100, amount%100
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if cents >= 0 {
+		fmt.Fprintf(&grouped, ".%02d", cents)
+	}
+	return strings.TrimSpace(currency + " " + sign + grouped.String())
+}
+
+// parseColor parses a #RGB or #RRGGBB color, ignoring an alpha component, black when it
+// is invalid
+func parseColor(hex string) color {
+	hex = strings.TrimPrefix(hex, "#")
+	switch len(hex) {
+	case 3, 4:
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	case 8:
+		hex = hex[:6]
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return black
+	}
+	return color{
+		r: float64(value>>16&0xFF) / 255,
+		g: float64(value>>8&0xFF) / 255,
+		b: float64(value&0xFF) / 255,
+	}
+}
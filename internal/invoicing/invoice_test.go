@@ -0,0 +1,113 @@
+package invoicing
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvoice(lines int) Invoice {
+	invoice := Invoice{
+		Number:   "ACME-0001",
+		Status:   "paid",
+		IssuedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		BillTo:   "Globex (Europe)",
+		Currency: "eur",
+	}
+	for i := range lines {
+		invoice.Lines = append(invoice.Lines, Line{Description: fmt.Sprintf("Seat %d", i+1), Quantity: 1, UnitAmount: 1000, Amount: 1000})
+		invoice.Subtotal += 1000
+	}
+	invoice.Total = invoice.Subtotal
+	invoice.AmountPaid = invoice.Total
+	return invoice
+}
+
+func TestRenderer_Render(t *testing.T) {
+	renderer := NewRenderer(Template{
+		BrandName:    "Acme",
+		BrandAddress: []string{"1 Main Street", "Springfield"},
+		AccentColor:  "#1F2937",
+		Footer:       "Thank you for your business",
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		pdf := renderer.Render(testInvoice(3))
+
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+		assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+		assert.Contains(t, string(pdf), "/Count 1")
+		assert.Contains(t, string(pdf), "(Acme)")
+		assert.Contains(t, string(pdf), "(ACME-0001)")
+		assert.Contains(t, string(pdf), `(Globex \(Europe\))`)
+		assert.Contains(t, string(pdf), "(EUR 30.00)")
+		assert.Contains(t, string(pdf), "(Thank you for your business)")
+		assert.Contains(t, string(pdf), "(Page 1 of 1)")
+	})
+
+	t.Run("lines continue on the next pages", func(t *testing.T) {
+		pdf := renderer.Render(testInvoice(60))
+
+		assert.Contains(t, string(pdf), "/Count 3")
+		assert.Contains(t, string(pdf), "(Seat 60)")
+		assert.Contains(t, string(pdf), "(Page 3 of 3)")
+	})
+
+	t.Run("cross-reference table points at the objects", func(t *testing.T) {
+		pdf := renderer.Render(testInvoice(60))
+
+		startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+		require.NotNil(t, startxref)
+		offset, err := strconv.Atoi(string(startxref[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(pdf[offset:], []byte("xref\n")))
+
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[offset:], -1)
+		require.Len(t, entries, 4+2*3)
+		for i, entry := range entries {
+			objectOffset, err := strconv.Atoi(string(entry[1]))
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(pdf[objectOffset:], fmt.Appendf(nil, "%d 0 obj\n", i+1)))
+		}
+	})
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		expected string
+	}{
+		{123450, "usd", "USD 1,234.50"},
+		{5, "eur", "EUR 0.05"},
+		{-1500, "usd", "USD -15.00"},
+		{123456789, "usd", "USD 1,234,567.89"},
+		{1500, "jpy", "JPY 1,500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatAmount(tt.amount, tt.currency))
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	assert.Equal(t, color{1, 1, 1}, parseColor("#FFFFFF"))
+	assert.Equal(t, color{1, 0, 0}, parseColor("#f00"))
+	assert.Equal(t, color{0, 0, 1}, parseColor("#0000FF80"))
+	assert.Equal(t, black, parseColor("navy"))
+	assert.Equal(t, black, parseColor(""))
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\(b\)c\\`, escape(`a(b)c\`))
+	assert.Equal(t, `\200 5 \351t\351`, escape("€ 5 été"))
+	assert.Equal(t, "?", escape("✓"))
+}
@@ -0,0 +1,175 @@
+package invoicing
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margins, in points
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 48.0
+)
+
+// Fonts of the documents: the standard Helvetica fonts, which PDF readers provide, so that
+// no font is embedded
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// color is an RGB color with components between 0 and 1
+type color struct {
+	r, g, b float64
+}
+
+var (
+	black     = color{0, 0, 0}
+	white     = color{1, 1, 1}
+	gray      = color{0.42, 0.45, 0.5}
+	lightGray = color{0.95, 0.96, 0.97}
+)
+
+// document is a minimal PDF writer drawing text and rectangles on A4 pages
+type document struct {
+	pages []*bytes.Buffer
+}
+
+// page starts a new page and returns its content stream
+func (d *document) page() *bytes.Buffer {
+	content := &bytes.Buffer{}
+	d.pages = append(d.pages, content)
+	return content
+}
+
+// text draws s with its baseline at x, y from the bottom left corner of the page
+func text(content *bytes.Buffer, font string, size float64, c color, x, y float64, s string) {
+	fmt.Fprintf(content, "BT /%s %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		font, size, c.r, c.g, c.b, x, y, escape(s))
+}
+
+// textRight draws s ending at x
+func textRight(content *bytes.Buffer, font string, size float64, c color, x, y float64, s string) {
+	text(content, font, size, c, x-textWidth(font, size, s), y, s)
+}
+
+// rect fills a rectangle whose bottom left corner is at x, y
+func rect(content *bytes.Buffer, c color, x, y, width, height float64) {
+	fmt.Fprintf(content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", c.r, c.g, c.b, x, y, width, height)
+}
+
+// line strokes a horizontal line from x1 to x2
+func line(content *bytes.Buffer, c color, x1, x2, y float64) {
+	fmt.Fprintf(content, "%.3f %.3f %.3f RG 0.5 w %.2f %.2f m %.2f %.2f l S\n", c.r, c.g, c.b, x1, y, x2, y)
+}
+
+// bytes returns the PDF file of the document
+func (d *document) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts, then each page is
+	// followed by its content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// escape encodes s as the content of a PDF string in WinAnsiEncoding. The characters the
+// encoding lacks are replaced by a question mark.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString(`\200`)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// truncate shortens s with an ellipsis until it fits in width
+func truncate(font string, size float64, s string, width float64) string {
+	if textWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(font, size, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "..."
+}
+
+// textWidth returns the width of s in points, from the metrics of the Helvetica fonts
+func textWidth(font string, size float64, s string) float64 {
+	widths := helveticaWidths
+	if font == fontBold {
+		widths = helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Widths of the printable ASCII characters of the Helvetica fonts, in thousandths of the
+// font size
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
@@ -0,0 +1,146 @@
+// Package jobs is the task queue of the server. Services enqueue typed task arguments to
+// run slow operations out of the request path; the jobs are stored in Postgres and run by
+// the worker mode of the binary, started with the `worker` argument. A failed job is
+// retried with exponential backoff, then kept in a dead-letter queue to be requeued.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Args are the typed arguments of a task, stored as the JSON payload of its jobs. Kind
+// identifies the worker running them and must be constant for the type.
+type Args interface {
+	Kind() string
+}
+
+// Enqueuer adds jobs to the queue
+type Enqueuer interface {
+	// Enqueue stores a job running the worker of the args kind as soon as possible, or
+	// later with WithDelay
+	Enqueue(ctx context.Context, args Args, opts ...EnqueueOption) (*models.Job, error)
+}
+
+// EnqueueOption customizes an enqueued job
+type EnqueueOption func(job *models.Job)
+
+// WithDelay runs the job after delay
+func WithDelay(delay time.Duration) EnqueueOption {
+	return func(job *models.Job) {
+		job.RunAt = time.Now().Add(delay)
+	}
+}
+
+// WithMaxAttempts overrides JOBS_MAX_ATTEMPTS for the job
+func WithMaxAttempts(maxAttempts int) EnqueueOption {
+	return func(job *models.Job) {
+		job.MaxAttempts = maxAttempts
+	}
+}
+
+// DeadLetters reads and requeues the dead jobs of a kind, for the services owning them
+type DeadLetters interface {
+	// ListDeadOfKind returns the dead jobs of the kind, most recently failed first
+	ListDeadOfKind(ctx context.Context, kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error)
+	// RequeueDeadOfKind runs the dead jobs of the kind again, with all their attempts:
+	// those of ids, or all of them when ids is empty. It returns their number.
+	RequeueDeadOfKind(ctx context.Context, kind string, ids []string) (int64, error)
+}
+
+// Client enqueues jobs and manages the dead-letter queue
+type Client struct {
+	repo        repositories.JobRepository
+	maxAttempts int
+}
+
+// ProvideClient creates a new task queue client
+func ProvideClient(cfg *config.Config, repo repositories.JobRepository) *Client {
+	return &Client{
+		repo:        repo,
+		maxAttempts: cfg.JobsMaxAttempts,
+	}
+}
+
+// ProvideEnqueuer exposes the client to the services
+func ProvideEnqueuer(client *Client) Enqueuer {
+	return client
+}
+
+// ProvideDeadLetters exposes the dead-letter queue of the client to the services
+func ProvideDeadLetters(client *Client) DeadLetters {
+	return client
+}
+
+// Enqueue implements Enqueuer
+func (c *Client) Enqueue(ctx context.Context, args Args, opts ...EnqueueOption) (*models.Job, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, errors.InternalError("Failed to encode job arguments", err).
+			WithOperation("enqueue_job").
+			WithResource("job").
+			WithContext("kind", args.Kind())
+	}
+
+	job := &models.Job{
+		BaseModel:   models.NewBaseModel(),
+		Kind:        args.Kind(),
+		Payload:     payload,
+		MaxAttempts: c.maxAttempts,
+		RunAt:       time.Now(),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := c.repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ListDead returns the jobs of the dead-letter queue, most recently failed first
+func (c *Client) ListDead(ctx context.Context, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	return c.repo.GetDead(pr)
+}
+
+// Requeue runs a job of the dead-letter queue again, with all its attempts
+func (c *Client) Requeue(ctx context.Context, id string) (*models.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.ValidationError("Invalid job ID", err).
+			WithOperation("requeue_job").
+			WithResource("job").
+			WithContext("job_id", id)
+	}
+
+	return c.repo.Requeue(id)
+}
+
+// ListDeadOfKind implements DeadLetters
+func (c *Client) ListDeadOfKind(ctx context.Context, kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	return c.repo.GetDeadOfKind(kind, pr)
+}
+
+// RequeueDeadOfKind implements DeadLetters
+func (c *Client) RequeueDeadOfKind(ctx context.Context, kind string, ids []string) (int64, error) {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return 0, errors.ValidationError("Invalid job ID", err).
+				WithOperation("requeue_jobs").
+				WithResource("job").
+				WithContext("job_id", id)
+		}
+	}
+
+	return c.repo.RequeueOfKind(kind, ids)
+}
@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Enqueue(t *testing.T) {
+	tests := []struct {
+		name                string
+		opts                []EnqueueOption
+		expectedMaxAttempts int
+		expectedDelay       time.Duration
+	}{
+		{
+			name:                "defaults",
+			expectedMaxAttempts: 3,
+		},
+		{
+			name:                "delay and max attempts",
+			opts:                []EnqueueOption{WithDelay(time.Hour), WithMaxAttempts(1)},
+			expectedMaxAttempts: 1,
+			expectedDelay:       time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockJobRepository)
+			repo.On("Create", mock.AnythingOfType("*models.Job")).Return(nil)
+			client := ProvideClient(testConfig(), repo)
+
+			job, err := client.Enqueue(context.Background(), greetArgs{Name: "Ada"}, tt.opts...)
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, job.ID)
+			assert.Equal(t, "greet", job.Kind)
+			assert.JSONEq(t, `{"name":"Ada"}`, string(job.Payload))
+			assert.Equal(t, tt.expectedMaxAttempts, job.MaxAttempts)
+			assert.WithinDuration(t, time.Now().Add(tt.expectedDelay), job.RunAt, time.Second)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClient_Requeue(t *testing.T) {
+	repo := new(MockJobRepository)
+	client := ProvideClient(testConfig(), repo)
+	job := &models.Job{BaseModel: models.NewBaseModel()}
+	repo.On("Requeue", job.ID).Return(job, nil)
+
+	requeued, err := client.Requeue(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, job, requeued)
+
+	_, err = client.Requeue(context.Background(), "not-a-uuid")
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+	repo.AssertExpectations(t)
+}
+
+func TestClient_RequeueDeadOfKind(t *testing.T) {
+	repo := new(MockJobRepository)
+	client := ProvideClient(testConfig(), repo)
+	id := models.NewBaseModel().ID
+	repo.On("RequeueOfKind", "greet", []string{id}).Return(int64(1), nil)
+
+	requeued, err := client.RequeueDeadOfKind(context.Background(), "greet", []string{id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+
+	_, err = client.RequeueDeadOfKind(context.Background(), "greet", []string{id, "not-a-uuid"})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/integration/email"
+	"golang-boilerplate/internal/retry"
+	"golang-boilerplate/internal/sandbox"
+)
+
+// Kinds of the tasks of the server
+const (
+	KindSendVerificationEmail = "send_verification_email"
+	KindSyncKeycloakRole      = "sync_keycloak_role"
+	KindDeliverWebhook        = "deliver_webhook"
+	KindProjectEvent          = "project_event"
+	KindRunMaintenanceTask    = "run_maintenance_task"
+	KindSendEmail             = "send_email"
+)
+
+// SendVerificationEmailArgs sends the Keycloak verification email of a user
+type SendVerificationEmailArgs struct {
+	KeycloakID string `json:"keycloak_id"`
+}
+
+// Kind implements Args
+func (SendVerificationEmailArgs) Kind() string { return KindSendVerificationEmail }
+
+// SyncKeycloakRoleArgs grants a client role of the application to a Keycloak user
+type SyncKeycloakRoleArgs struct {
+	KeycloakID string `json:"keycloak_id"`
+	Role       string `json:"role"`
+}
+
+// Kind implements Args
+func (SyncKeycloakRoleArgs) Kind() string { return KindSyncKeycloakRole }
+
+// DeliverWebhookArgs sends an attempt of a webhook delivery
+type DeliverWebhookArgs struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// Kind implements Args
+func (DeliverWebhookArgs) Kind() string { return KindDeliverWebhook }
+
+// WebhookSender sends the attempts of the webhook deliveries. It is implemented by the
+// webhooks package, which enqueues the deliveries.
+type WebhookSender interface {
+	Send(ctx context.Context, deliveryID string) error
+}
+
+// ProjectEventArgs applies a domain event of a company to the read models
+type ProjectEventArgs struct {
+	EventType  string    `json:"event_type"`
+	CompanyID  string    `json:"company_id"`
+	EntityID   string    `json:"entity_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Kind implements Args
+func (ProjectEventArgs) Kind() string { return KindProjectEvent }
+
+// EventProjector applies the domain events to the read models. It is implemented by the
+// projections package, which enqueues the events.
+type EventProjector interface {
+	Apply(ctx context.Context, args ProjectEventArgs) error
+}
+
+// RunMaintenanceTaskArgs runs the batches of a generation of a maintenance task
+type RunMaintenanceTaskArgs struct {
+	TaskID     string `json:"task_id"`
+	Generation int    `json:"generation"`
+}
+
+// Kind implements Args
+func (RunMaintenanceTaskArgs) Kind() string { return KindRunMaintenanceTask }
+
+// MaintenanceRunner runs the maintenance tasks. It is implemented by the maintenance
+// package, which enqueues the tasks.
+type MaintenanceRunner interface {
+	Run(ctx context.Context, args RunMaintenanceTaskArgs) error
+}
+
+// SendEmailArgs sends an email through the provider, in the pacing lane of Priority and
+// captured into the inbox of SandboxCompanyID when it was sent in a sandbox tenant
+type SendEmailArgs struct {
+	Request          email.EmailRequest `json:"request"`
+	Priority         email.Priority     `json:"priority,omitempty"`
+	SandboxCompanyID string             `json:"sandbox_company_id,omitempty"`
+}
+
+// Kind implements Args
+func (SendEmailArgs) Kind() string { return KindSendEmail }
+
+// ProvideWorkers registers the workers of the tasks of the server
+func ProvideWorkers(authProvider auth.AuthService, tokens auth.TokenProvider, webhookSender WebhookSender, eventProjector EventProjector, maintenanceRunner MaintenanceRunner, emailSender email.EmailSender) *Workers {
+	workers := NewWorkers()
+
+	AddWorker(workers, func(ctx context.Context, args SendVerificationEmailArgs) error {
+		if args.KeycloakID == "" {
+			return retry.Permanent(fmt.Errorf("missing keycloak_id"))
+		}
+		adminToken, err := tokens.AdminToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		clientID := authProvider.GetClientID()
+		redirectURI := authProvider.GetRedirectURI()
+		return authProvider.SendVerificationMail(ctx, adminToken, args.KeycloakID, auth.SendVerificationMailParams{
+			ClientID:    &clientID,
+			RedirectURI: &redirectURI,
+		})
+	})
+
+	AddWorker(workers, func(ctx context.Context, args SyncKeycloakRoleArgs) error {
+		if args.KeycloakID == "" || args.Role == "" {
+			return retry.Permanent(fmt.Errorf("missing keycloak_id or role"))
+		}
+		adminToken, err := tokens.AdminToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		return authProvider.AddClientRolesToUser(ctx, adminToken, args.KeycloakID, authProvider.GetClientID(), args.Role)
+	})
+
+	AddWorker(workers, func(ctx context.Context, args DeliverWebhookArgs) error {
+		if args.DeliveryID == "" {
+			return retry.Permanent(fmt.Errorf("missing delivery_id"))
+		}
+		return webhookSender.Send(ctx, args.DeliveryID)
+	})
+
+	AddWorker(workers, func(ctx context.Context, args ProjectEventArgs) error {
+		if args.CompanyID == "" && args.EntityID == "" {
+			return retry.Permanent(fmt.Errorf("missing company_id and entity_id"))
+		}
+		return eventProjector.Apply(ctx, args)
+	})
+
+	AddWorker(workers, func(ctx context.Context, args RunMaintenanceTaskArgs) error {
+		if args.TaskID == "" {
+			return retry.Permanent(fmt.Errorf("missing task_id"))
+		}
+		return maintenanceRunner.Run(ctx, args)
+	})
+
+	AddWorker(workers, sendEmail(emailSender))
+
+	return workers
+}
+
+// sendEmail sends the queued emails. The emails the provider rejects as invalid go to
+// the dead-letter queue at once, the other failures are retried.
+func sendEmail(emailSender email.EmailSender) WorkFunc[SendEmailArgs] {
+	return func(ctx context.Context, args SendEmailArgs) error {
+		if len(args.Request.To)+len(args.Request.Cc)+len(args.Request.Bcc) == 0 {
+			return retry.Permanent(fmt.Errorf("missing recipients"))
+		}
+		ctx = email.WithPriority(ctx, args.Priority)
+		if args.SandboxCompanyID != "" {
+			ctx = sandbox.WithCompany(ctx, args.SandboxCompanyID)
+		}
+
+		_, err := emailSender.SendEmail(ctx, args.Request)
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeValidation {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/email"
+	"golang-boilerplate/internal/retry"
+	"golang-boilerplate/internal/sandbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmailSender is a mock implementation of email.EmailSender
+type MockEmailSender struct {
+	mock.Mock
+}
+
+func (m *MockEmailSender) SendEmail(ctx context.Context, message email.EmailRequest) (*email.EmailResponse, error) {
+	args := m.Called(ctx, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.EmailResponse), args.Error(1)
+}
+
+func (m *MockEmailSender) SendRawEmail(ctx context.Context, rawData []byte) (*email.EmailResponse, error) {
+	args := m.Called(ctx, rawData)
+	return nil, args.Error(1)
+}
+
+func (m *MockEmailSender) SendBulkEmail(ctx context.Context, messages []email.EmailRequest) ([]email.EmailResponse, error) {
+	args := m.Called(ctx, messages)
+	return nil, args.Error(1)
+}
+
+func (m *MockEmailSender) Ping(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestSendEmail(t *testing.T) {
+	request := email.EmailRequest{To: []string{"jane@example.com"}, Subject: "Digest", TextBody: "Digest"}
+
+	tests := []struct {
+		name              string
+		args              SendEmailArgs
+		sendErr           error
+		expectedError     bool
+		expectedPermanent bool
+	}{
+		{
+			name: "sent in the lane and sandbox of the sender",
+			args: SendEmailArgs{Request: request, Priority: email.PriorityBulk, SandboxCompanyID: "company-1"},
+		},
+		{
+			name:          "provider failure retried",
+			args:          SendEmailArgs{Request: request},
+			sendErr:       errors.ExternalServiceError("Failed to send email via SES", assert.AnError),
+			expectedError: true,
+		},
+		{
+			name:              "invalid email dead at once",
+			args:              SendEmailArgs{Request: request},
+			sendErr:           errors.ValidationError("Invalid email recipient", assert.AnError),
+			expectedError:     true,
+			expectedPermanent: true,
+		},
+		{
+			name:              "no recipients",
+			args:              SendEmailArgs{Request: email.EmailRequest{Subject: "Digest"}},
+			expectedError:     true,
+			expectedPermanent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := new(MockEmailSender)
+			if len(tt.args.Request.To) > 0 {
+				sender.On("SendEmail", mock.MatchedBy(func(ctx context.Context) bool {
+					companyID, _ := sandbox.CompanyID(ctx)
+					return email.PriorityFromContext(ctx) == tt.args.Priority && companyID == tt.args.SandboxCompanyID
+				}), tt.args.Request).Return(&email.EmailResponse{Status: "sent"}, tt.sendErr)
+			}
+
+			err := sendEmail(sender)(context.Background(), tt.args)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			assert.Equal(t, tt.expectedPermanent, retry.IsPermanent(err))
+			sender.AssertExpectations(t)
+		})
+	}
+}
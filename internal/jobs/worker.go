@@ -0,0 +1,267 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// WorkFunc runs a job of the kind of T. An error wrapped by retry.Permanent moves the job
+// to the dead-letter queue without further attempts.
+type WorkFunc[T Args] func(ctx context.Context, args T) error
+
+// Workers maps the kinds of jobs to the functions running them
+type Workers struct {
+	funcs map[string]func(ctx context.Context, payload []byte) error
+}
+
+// NewWorkers creates an empty set of workers
+func NewWorkers() *Workers {
+	return &Workers{funcs: make(map[string]func(ctx context.Context, payload []byte) error)}
+}
+
+// AddWorker registers the function running the jobs of the kind of T, decoding their
+// payload into T. It panics when the kind already has a worker.
+func AddWorker[T Args](workers *Workers, work WorkFunc[T]) {
+	var zero T
+	kind := zero.Kind()
+	if _, ok := workers.funcs[kind]; ok {
+		panic(fmt.Sprintf("jobs: duplicate worker for kind %s", kind))
+	}
+
+	workers.funcs[kind] = func(ctx context.Context, payload []byte) error {
+		var args T
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return retry.Permanent(fmt.Errorf("decode %s arguments: %w", kind, err))
+		}
+		return work(ctx, args)
+	}
+}
+
+// Kinds returns the kinds of jobs the workers run, sorted
+func (w *Workers) Kinds() []string {
+	kinds := make([]string, 0, len(w.funcs))
+	for kind := range w.funcs {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// Pool claims the due jobs of the registered kinds and runs them concurrently
+type Pool struct {
+	repo         repositories.JobRepository
+	workers      *Workers
+	nrApp        *newrelic.Application
+	id           string
+	concurrency  int
+	pollInterval time.Duration
+	timeout      time.Duration
+	rescueAfter  time.Duration
+	backoff      retry.Backoff
+
+	// fetchCtx stops claiming jobs, workCtx cancels the running ones
+	fetchCtx   context.Context
+	stopFetch  context.CancelFunc
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// ProvidePool creates the worker pool, started by the worker mode
+func ProvidePool(cfg *config.Config, repo repositories.JobRepository, workers *Workers, nrApp *newrelic.Application) *Pool {
+	hostname, _ := os.Hostname()
+	fetchCtx, stopFetch := context.WithCancel(context.Background())
+	workCtx, cancelWork := context.WithCancel(context.Background())
+
+	return &Pool{
+		repo:         repo,
+		workers:      workers,
+		nrApp:        nrApp,
+		id:           fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		concurrency:  cfg.JobsConcurrency,
+		pollInterval: cfg.JobsPollInterval,
+		timeout:      cfg.JobsTimeout,
+		rescueAfter:  cfg.JobsRescueAfter,
+		backoff: retry.Backoff{
+			InitialInterval: cfg.JobsRetryInitialInterval,
+			MaxInterval:     cfg.JobsRetryMaxInterval,
+			Jitter:          retry.DefaultJitter,
+		},
+		fetchCtx:   fetchCtx,
+		stopFetch:  stopFetch,
+		workCtx:    workCtx,
+		cancelWork: cancelWork,
+	}
+}
+
+// Start starts the workers and the rescue of the jobs left running by crashed workers
+func (p *Pool) Start() {
+	logger.Log.Info("Starting job workers",
+		zap.String("worker_id", p.id),
+		zap.Int("concurrency", p.concurrency),
+		zap.Strings("kinds", p.workers.Kinds()),
+	)
+
+	for range p.concurrency {
+		p.wg.Go(p.fetch)
+	}
+	p.wg.Go(p.rescue)
+}
+
+// Stop stops claiming jobs and waits for the running ones until ctx expires, then cancels
+// their context. Canceled jobs are retried like failed ones.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.stopFetch()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancelWork()
+		return nil
+	case <-ctx.Done():
+		p.cancelWork()
+		return ctx.Err()
+	}
+}
+
+// fetch runs the due jobs one at a time, polling when the queue is empty
+func (p *Pool) fetch() {
+	kinds := p.workers.Kinds()
+	for p.fetchCtx.Err() == nil {
+		job, err := p.repo.Claim(kinds, p.id)
+		if err != nil {
+			logger.Log.Warn("Failed to claim job", zap.Error(err))
+		}
+		if job != nil {
+			p.run(job)
+			continue
+		}
+
+		select {
+		case <-p.fetchCtx.Done():
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+// rescue periodically makes pending again the jobs running for longer than rescueAfter
+func (p *Pool) rescue() {
+	ticker := time.NewTicker(constants.JobsRescueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.fetchCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		rescued, err := p.repo.RescueStale(time.Now().Add(-p.rescueAfter))
+		if err != nil {
+			logger.Log.Warn("Failed to rescue stale jobs", zap.Error(err))
+			continue
+		}
+		if rescued > 0 {
+			logger.Log.Warn("Rescued jobs left running", zap.Int64("count", rescued))
+		}
+	}
+}
+
+// run runs a claimed job under the timeout, recovering panics, and records its outcome:
+// completed, retried later with backoff, or moved to the dead-letter queue
+func (p *Pool) run(job *models.Job) {
+	txn := p.nrApp.StartTransaction("Task/" + job.Kind)
+	defer txn.End()
+
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("task", job.Kind)
+		scope.SetTag("job_id", job.ID)
+	})
+
+	fields := []zap.Field{
+		zap.String("kind", job.Kind),
+		zap.String("job_id", job.ID),
+		zap.Int("attempt", job.Attempt),
+	}
+
+	startedAt := time.Now()
+	err := p.execute(sentry.SetHubOnContext(newrelic.NewContext(p.workCtx, txn), hub), job)
+	elapsed := time.Since(startedAt)
+	fields = append(fields, zap.Duration("elapsed", elapsed))
+	p.nrApp.RecordCustomMetric("Custom/Task/"+job.Kind+"/Duration", elapsed.Seconds())
+
+	if err == nil {
+		if err := p.repo.Complete(job); err != nil {
+			logger.Log.Error("Failed to complete job", append(fields, zap.Error(err))...)
+			return
+		}
+		logger.Log.Info("Job completed", fields...)
+		return
+	}
+
+	txn.NoticeError(err)
+	p.nrApp.RecordCustomMetric("Custom/Task/"+job.Kind+"/Failure", 1)
+	lastError := err.Error()
+	if len(lastError) > constants.JobsMaxErrorLength {
+		lastError = lastError[:constants.JobsMaxErrorLength]
+	}
+
+	if retry.IsPermanent(err) || job.Attempt >= job.MaxAttempts {
+		hub.CaptureException(err)
+		p.nrApp.RecordCustomMetric("Custom/Task/"+job.Kind+"/Dead", 1)
+		if err := p.repo.Kill(job, lastError); err != nil {
+			logger.Log.Error("Failed to move job to the dead-letter queue", append(fields, zap.Error(err))...)
+			return
+		}
+		logger.Log.Warn("Job moved to the dead-letter queue", append(fields, zap.Error(err))...)
+		return
+	}
+
+	delay := p.backoff.JitteredDelay(job.Attempt)
+	if err := p.repo.Retry(job, time.Now().Add(delay), lastError); err != nil {
+		logger.Log.Error("Failed to retry job", append(fields, zap.Error(err))...)
+		return
+	}
+	logger.Log.Warn("Job failed, retrying", append(fields, zap.Duration("retry_in", delay), zap.Error(err))...)
+}
+
+// execute calls the worker of the job kind under the timeout
+func (p *Pool) execute(ctx context.Context, job *models.Job) (err error) {
+	work, ok := p.workers.funcs[job.Kind]
+	if !ok {
+		return retry.Permanent(fmt.Errorf("no worker for kind %s", job.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.Kind, r)
+		}
+	}()
+
+	return work(ctx, job.Payload)
+}
@@ -0,0 +1,276 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// MockJobRepository is a mock implementation of repositories.JobRepository
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(job *models.Job) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockJobRepository) Claim(kinds []string, workerID string) (*models.Job, error) {
+	args := m.Called(kinds, workerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Complete(job *models.Job) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockJobRepository) Retry(job *models.Job, runAt time.Time, lastError string) error {
+	return m.Called(job, runAt, lastError).Error(0)
+}
+
+func (m *MockJobRepository) Kill(job *models.Job, lastError string) error {
+	return m.Called(job, lastError).Error(0)
+}
+
+func (m *MockJobRepository) RescueStale(lockedBefore time.Time) (int64, error) {
+	args := m.Called(lockedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) GetDead(pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	args := m.Called(pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.Job]), args.Error(1)
+}
+
+func (m *MockJobRepository) GetDeadOfKind(kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	args := m.Called(kind, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.Job]), args.Error(1)
+}
+
+func (m *MockJobRepository) RequeueOfKind(kind string, ids []string) (int64, error) {
+	args := m.Called(kind, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockJobRepository) Requeue(id string) (*models.Job, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+type greetArgs struct {
+	Name string `json:"name"`
+}
+
+func (greetArgs) Kind() string { return "greet" }
+
+type pingArgs struct{}
+
+func (pingArgs) Kind() string { return "ping" }
+
+func testConfig() *config.Config {
+	return &config.Config{
+		JobsConcurrency:          2,
+		JobsPollInterval:         10 * time.Millisecond,
+		JobsTimeout:              time.Second,
+		JobsMaxAttempts:          3,
+		JobsRetryInitialInterval: time.Second,
+		JobsRetryMaxInterval:     time.Minute,
+		JobsRescueAfter:          time.Hour,
+	}
+}
+
+func TestAddWorker(t *testing.T) {
+	workers := NewWorkers()
+	var greeted string
+	AddWorker(workers, func(ctx context.Context, args greetArgs) error {
+		greeted = args.Name
+		return nil
+	})
+	AddWorker(workers, func(context.Context, pingArgs) error { return nil })
+
+	assert.Equal(t, []string{"greet", "ping"}, workers.Kinds())
+	require.NoError(t, workers.funcs["greet"](context.Background(), []byte(`{"name":"Ada"}`)))
+	assert.Equal(t, "Ada", greeted)
+
+	err := workers.funcs["greet"](context.Background(), []byte(`not json`))
+	assert.True(t, retry.IsPermanent(err), "an undecodable payload never succeeds")
+
+	assert.Panics(t, func() {
+		AddWorker(workers, func(context.Context, pingArgs) error { return nil })
+	})
+}
+
+func TestPool_Run(t *testing.T) {
+	errUnavailable := stderrors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		attempt    int
+		work       WorkFunc[greetArgs]
+		setupMocks func(repo *MockJobRepository, job *models.Job)
+	}{
+		{
+			name:    "success",
+			attempt: 1,
+			work:    func(context.Context, greetArgs) error { return nil },
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Complete", job).Return(nil)
+			},
+		},
+		{
+			name:    "failure is retried with backoff",
+			attempt: 2,
+			work:    func(context.Context, greetArgs) error { return errUnavailable },
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Retry", job, mock.MatchedBy(func(runAt time.Time) bool {
+					// Second attempt: 2s with up to 50% jitter
+					delay := time.Until(runAt)
+					return delay > 900*time.Millisecond && delay <= 3*time.Second
+				}), "connection refused").Return(nil)
+			},
+		},
+		{
+			name:    "last attempt moves the job to the dead-letter queue",
+			attempt: 3,
+			work:    func(context.Context, greetArgs) error { return errUnavailable },
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Kill", job, "connection refused").Return(nil)
+			},
+		},
+		{
+			name:    "permanent error is not retried",
+			attempt: 1,
+			work: func(context.Context, greetArgs) error {
+				return retry.Permanent(stderrors.New("user not found"))
+			},
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Kill", job, "user not found").Return(nil)
+			},
+		},
+		{
+			name:    "panic is retried",
+			attempt: 1,
+			work:    func(context.Context, greetArgs) error { panic("nil map") },
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Retry", job, mock.AnythingOfType("time.Time"), "job greet panicked: nil map").Return(nil)
+			},
+		},
+		{
+			name:    "timeout is retried",
+			attempt: 1,
+			work: func(ctx context.Context, _ greetArgs) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			setupMocks: func(repo *MockJobRepository, job *models.Job) {
+				repo.On("Retry", job, mock.AnythingOfType("time.Time"), context.DeadlineExceeded.Error()).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockJobRepository)
+			workers := NewWorkers()
+			AddWorker(workers, tt.work)
+			cfg := testConfig()
+			cfg.JobsTimeout = 20 * time.Millisecond
+			pool := ProvidePool(cfg, repo, workers, nil)
+
+			job := &models.Job{
+				BaseModel:   models.NewBaseModel(),
+				Kind:        "greet",
+				Payload:     json.RawMessage(`{"name":"Ada"}`),
+				Status:      constants.JobStatusRunning,
+				Attempt:     tt.attempt,
+				MaxAttempts: 3,
+			}
+			tt.setupMocks(repo, job)
+
+			pool.run(job)
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPool_UnknownKind(t *testing.T) {
+	repo := new(MockJobRepository)
+	pool := ProvidePool(testConfig(), repo, NewWorkers(), nil)
+	job := &models.Job{BaseModel: models.NewBaseModel(), Kind: "unknown", Attempt: 1, MaxAttempts: 3}
+	repo.On("Kill", job, "no worker for kind unknown").Return(nil)
+
+	pool.run(job)
+
+	repo.AssertExpectations(t)
+}
+
+func TestPool_StartAndStop(t *testing.T) {
+	repo := new(MockJobRepository)
+	workers := NewWorkers()
+	started := make(chan struct{})
+	AddWorker(workers, func(ctx context.Context, _ pingArgs) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	pool := ProvidePool(testConfig(), repo, workers, nil)
+
+	job := &models.Job{BaseModel: models.NewBaseModel(), Kind: "ping", Payload: json.RawMessage(`{}`), Attempt: 1, MaxAttempts: 3}
+	repo.On("Claim", []string{"ping"}, pool.id).Return(job, nil).Once()
+	repo.On("Claim", []string{"ping"}, pool.id).Return(nil, nil)
+	retried := make(chan struct{})
+	repo.On("Retry", job, mock.AnythingOfType("time.Time"), context.Canceled.Error()).
+		Run(func(mock.Arguments) { close(retried) }).
+		Return(nil)
+
+	pool.Start()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("job not run")
+	}
+
+	// Stop waits for the running job until its context expires, then cancels it
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Stop(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-retried:
+	case <-time.After(time.Second):
+		t.Fatal("the canceled job is not retried")
+	}
+}
@@ -0,0 +1,73 @@
+// Package loadshed caps the requests served at once, so that a traffic spike is shed at the
+// door with a 503 instead of piling up on the database and Keycloak until every request
+// times out.
+package loadshed
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter lets maxConcurrency requests run at once. The next maxQueue requests wait for a
+// slot up to queueTimeout, and the requests beyond the queue or waiting longer are shed.
+// Bounding the wait rather than only the queue sheds the load as soon as the requests
+// stop completing in time, whatever their mix.
+type Limiter struct {
+	slots        chan struct{}
+	maxQueue     int64
+	queueTimeout time.Duration
+	queued       atomic.Int64
+}
+
+// NewLimiter creates a limiter, maxConcurrency must be positive
+func NewLimiter(maxConcurrency int, maxQueue int, queueTimeout time.Duration) *Limiter {
+	return &Limiter{
+		slots:        make(chan struct{}, maxConcurrency),
+		maxQueue:     int64(maxQueue),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Acquire takes a slot, waiting in the queue when they are all taken. It returns false when
+// the request is shed, because the queue is full or no slot freed up within the queue
+// timeout or before ctx was done; otherwise the caller must call Release once served.
+func (l *Limiter) Acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queued.Add(1) > l.maxQueue {
+		l.queued.Add(-1)
+		return false
+	}
+	defer l.queued.Add(-1)
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release frees the slot of a served request
+func (l *Limiter) Release() {
+	<-l.slots
+}
+
+// InFlight returns the number of requests holding a slot
+func (l *Limiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Queued returns the number of requests waiting for a slot
+func (l *Limiter) Queued() int {
+	return int(l.queued.Load())
+}
@@ -0,0 +1,60 @@
+package loadshed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Acquire(t *testing.T) {
+	limiter := NewLimiter(2, 1, time.Second)
+
+	require.True(t, limiter.Acquire(context.Background()))
+	require.True(t, limiter.Acquire(context.Background()))
+	assert.Equal(t, 2, limiter.InFlight())
+
+	// The third request waits for the first slot released
+	acquired := make(chan bool)
+	go func() { acquired <- limiter.Acquire(context.Background()) }()
+	require.Eventually(t, func() bool { return limiter.Queued() == 1 }, time.Second, time.Millisecond)
+
+	// The queue is full, the fourth request is shed at once
+	assert.False(t, limiter.Acquire(context.Background()))
+
+	limiter.Release()
+	assert.True(t, <-acquired)
+	assert.Equal(t, 0, limiter.Queued())
+	assert.Equal(t, 2, limiter.InFlight())
+}
+
+func TestLimiter_Acquire_QueueTimeout(t *testing.T) {
+	limiter := NewLimiter(1, 10, 10*time.Millisecond)
+	require.True(t, limiter.Acquire(context.Background()))
+
+	start := time.Now()
+	assert.False(t, limiter.Acquire(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.Equal(t, 0, limiter.Queued())
+}
+
+func TestLimiter_Acquire_CanceledRequest(t *testing.T) {
+	limiter := NewLimiter(1, 10, time.Minute)
+	require.True(t, limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, limiter.Acquire(ctx))
+}
+
+func TestLimiter_Acquire_NoQueue(t *testing.T) {
+	limiter := NewLimiter(1, 0, time.Minute)
+	require.True(t, limiter.Acquire(context.Background()))
+
+	assert.False(t, limiter.Acquire(context.Background()))
+
+	limiter.Release()
+	assert.True(t, limiter.Acquire(context.Background()))
+}
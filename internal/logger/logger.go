@@ -11,6 +11,9 @@ import (
 var Log *zap.Logger
 var Sugar *zap.SugaredLogger
 
+// Level is the level of Log, which can be changed while the process runs
+var Level = zap.NewAtomicLevel()
+
 // Init initializes the logger with the specified level and environment
 func Init(level string, environment string) {
 	var zapLevel zapcore.Level
@@ -49,10 +52,11 @@ func Init(level string, environment string) {
 	}
 
 	// Create core
+	Level.SetLevel(zapLevel)
 	core := zapcore.NewCore(
 		encoder,
 		zapcore.AddSync(os.Stdout),
-		zapLevel,
+		Level,
 	)
 
 	// Create logger with caller and stack trace
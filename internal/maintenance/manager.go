@@ -0,0 +1,411 @@
+// Package maintenance runs the long maintenance operations, such as rebuilding the read
+// models or sealing the secrets again after a KMS key rotation, as managed tasks rather
+// than scripts run against production. A task processes its operation in batches on the
+// workers of the task queue and saves its cursor after every batch, so that it reports
+// its progress and can be throttled, paused, resumed and cancelled from the admin API.
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Operation is a maintenance operation processed in batches. A batch runs again when its
+// task fails or its worker stops before the progress is saved, so Step must be idempotent.
+type Operation interface {
+	// Name names the operation in the API
+	Name() string
+	Description() string
+	// Count validates the params of a task and returns the number of items it processes
+	Count(ctx context.Context, params json.RawMessage) (int64, error)
+	// Step processes up to size items after the cursor, empty for the first batch
+	Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error)
+}
+
+// Batch is the outcome of a step of an operation
+type Batch struct {
+	// Cursor is the position the next batch starts after
+	Cursor    string
+	Processed int
+	Done      bool
+}
+
+// Params are the dependencies of the manager
+type Params struct {
+	fx.In
+
+	Repo       repositories.MaintenanceRepository
+	Enqueuer   jobs.Enqueuer
+	Operations []Operation `group:"maintenance_operations"`
+}
+
+// Manager starts and controls the maintenance tasks, and runs their batches for the
+// workers of the task queue
+type Manager struct {
+	repo       repositories.MaintenanceRepository
+	enqueuer   jobs.Enqueuer
+	operations map[string]Operation
+	// sliceDuration is how long a job runs batches before handing over to a new job
+	sliceDuration time.Duration
+}
+
+// New creates a manager of the operations, failing on a duplicate name
+func New(repo repositories.MaintenanceRepository, enqueuer jobs.Enqueuer, operations ...Operation) (*Manager, error) {
+	byName := make(map[string]Operation, len(operations))
+	for _, operation := range operations {
+		if _, ok := byName[operation.Name()]; ok {
+			return nil, fmt.Errorf("duplicate maintenance operation %s", operation.Name())
+		}
+		byName[operation.Name()] = operation
+	}
+
+	return &Manager{
+		repo:          repo,
+		enqueuer:      enqueuer,
+		operations:    byName,
+		sliceDuration: constants.MaintenanceSliceDuration,
+	}, nil
+}
+
+// ProvideManager creates the manager of the operations of the maintenance_operations group
+func ProvideManager(p Params) (*Manager, error) {
+	return New(p.Repo, p.Enqueuer, p.Operations...)
+}
+
+// ProvideRunner returns the manager as the runner of the run_maintenance_task tasks
+func ProvideRunner(manager *Manager) jobs.MaintenanceRunner {
+	return manager
+}
+
+// Operations returns the operations, by name
+func (m *Manager) Operations() []dtos.MaintenanceOperationResponse {
+	operations := make([]dtos.MaintenanceOperationResponse, 0, len(m.operations))
+	for _, operation := range m.operations {
+		operations = append(operations, dtos.MaintenanceOperationResponse{Name: operation.Name(), Description: operation.Description()})
+	}
+	slices.SortFunc(operations, func(a, b dtos.MaintenanceOperationResponse) int {
+		if a.Name < b.Name {
+			return -1
+		}
+		if a.Name > b.Name {
+			return 1
+		}
+		return 0
+	})
+	return operations
+}
+
+// Create starts a task of the operation of the request. An operation has at most one
+// active task, so that two runs never process the same items.
+func (m *Manager) Create(ctx context.Context, req *dtos.CreateMaintenanceTaskRequest, createdBy string) (*models.MaintenanceTask, error) {
+	operation, ok := m.operations[req.Operation]
+	if !ok {
+		return nil, errors.ValidationError("Unknown maintenance operation", nil).
+			WithOperation("create_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("operation", req.Operation)
+	}
+
+	active, err := m.repo.GetActive(req.Operation)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, errors.ConflictError("The operation already has an active task; resume or cancel it", nil).
+			WithOperation("create_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("operation", req.Operation).
+			WithContext("task_id", active.ID).
+			WithContext("status", active.Status)
+	}
+
+	params := req.Params
+	if string(params) == "null" {
+		params = nil
+	}
+	total, err := operation.Count(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = constants.MaintenanceDefaultBatchSize
+	}
+	task := &models.MaintenanceTask{
+		BaseModel:  models.NewBaseModel(),
+		Operation:  req.Operation,
+		Params:     params,
+		Status:     constants.MaintenanceStatusRunning,
+		BatchSize:  batchSize,
+		ThrottleMs: req.ThrottleMs,
+		Total:      total,
+		Generation: 1,
+		CreatedBy:  createdBy,
+		StartedAt:  time.Now(),
+	}
+	if err := m.repo.Create(task); err != nil {
+		return nil, err
+	}
+
+	if err := m.enqueue(ctx, task, 0); err != nil {
+		// Left running, the task would never run; failed, it can be resumed
+		m.fail(task, err)
+		return nil, err
+	}
+
+	logger.Log.Info("Maintenance task started",
+		zap.String("task_id", task.ID),
+		zap.String("operation", task.Operation),
+		zap.Int64("total", task.Total),
+		zap.String("created_by", createdBy),
+	)
+
+	return task, nil
+}
+
+// List returns the tasks, most recently started first
+func (m *Manager) List(ctx context.Context, pr *dtos.PageableRequest) (*dtos.DataResponse[models.MaintenanceTask], error) {
+	return m.repo.Get(pr)
+}
+
+func (m *Manager) Get(ctx context.Context, id string) (*models.MaintenanceTask, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.ValidationError("Invalid maintenance task ID", err).
+			WithOperation("get_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("task_id", id)
+	}
+
+	return m.repo.GetOneByID(id)
+}
+
+// Update changes the batch size and throttling of a task that has not ended; a running
+// task applies them from its next batch
+func (m *Manager) Update(ctx context.Context, id string, req *dtos.UpdateMaintenanceTaskRequest) (*models.MaintenanceTask, error) {
+	task, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, 2)
+	if req.BatchSize != nil {
+		task.BatchSize = *req.BatchSize
+		columns = append(columns, "batch_size")
+	}
+	if req.ThrottleMs != nil {
+		task.ThrottleMs = *req.ThrottleMs
+		columns = append(columns, "throttle_ms")
+	}
+	if len(columns) == 0 {
+		return task, nil
+	}
+
+	return m.transition(task, "update_maintenance_task", constants.MaintenanceActiveStatuses, columns...)
+}
+
+// Pause stops a running task after its current batch; it keeps its cursor until resumed
+func (m *Manager) Pause(ctx context.Context, id string) (*models.MaintenanceTask, error) {
+	task, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Status = constants.MaintenanceStatusPaused
+	return m.transition(task, "pause_maintenance_task", []string{constants.MaintenanceStatusRunning})
+}
+
+// Resume runs a paused or failed task again from its cursor. Its generation is
+// incremented, so that a job of the previous run still finishing its batch stops there.
+func (m *Manager) Resume(ctx context.Context, id string) (*models.MaintenanceTask, error) {
+	task, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Status = constants.MaintenanceStatusRunning
+	task.Generation++
+	task.LastError = nil
+	task, err = m.transition(task, "resume_maintenance_task",
+		[]string{constants.MaintenanceStatusPaused, constants.MaintenanceStatusFailed},
+		"generation", "last_error")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.enqueue(ctx, task, 0); err != nil {
+		m.fail(task, err)
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// Cancel ends a task that has not ended; a running task stops after its current batch
+func (m *Manager) Cancel(ctx context.Context, id string) (*models.MaintenanceTask, error) {
+	task, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	finishedAt := time.Now()
+	task.Status = constants.MaintenanceStatusCancelled
+	task.FinishedAt = &finishedAt
+	return m.transition(task, "cancel_maintenance_task", constants.MaintenanceActiveStatuses, "finished_at")
+}
+
+// Run implements jobs.MaintenanceRunner. It runs the batches of the task, saving its
+// progress after each, until it is done, paused, cancelled or resumed by another job, or
+// until its slice is over and a new job takes over, delayed by the throttling.
+func (m *Manager) Run(ctx context.Context, args jobs.RunMaintenanceTaskArgs) error {
+	task, err := m.repo.GetOneByID(args.TaskID)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+	if task.Status != constants.MaintenanceStatusRunning || task.Generation != args.Generation {
+		return nil
+	}
+
+	operation, ok := m.operations[task.Operation]
+	if !ok {
+		m.fail(task, fmt.Errorf("unknown maintenance operation %s", task.Operation))
+		return nil
+	}
+
+	// The slice ends well before the job timeout
+	sliceEnd := time.Now().Add(m.sliceDuration)
+	if deadline, ok := ctx.Deadline(); ok {
+		if half := time.Now().Add(time.Until(deadline) / 2); half.Before(sliceEnd) {
+			sliceEnd = half
+		}
+	}
+
+	for {
+		batch, err := operation.Step(ctx, task.Params, task.Cursor, task.BatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The worker is stopping: the job runs the batch again later
+				return err
+			}
+			m.fail(task, err)
+			return nil
+		}
+
+		task.Cursor = batch.Cursor
+		task.Processed += int64(batch.Processed)
+		columns := []string{"cursor", "processed"}
+		if batch.Done {
+			finishedAt := time.Now()
+			task.Status = constants.MaintenanceStatusCompleted
+			task.FinishedAt = &finishedAt
+			columns = append(columns, "status", "finished_at")
+		}
+		saved, err := m.repo.SaveRun(task, columns...)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			return nil
+		}
+		if batch.Done {
+			logger.Log.Info("Maintenance task completed",
+				zap.String("task_id", task.ID),
+				zap.String("operation", task.Operation),
+				zap.Int64("processed", task.Processed),
+				zap.Duration("duration", time.Since(task.StartedAt)),
+			)
+			return nil
+		}
+
+		// Pick up the throttling changed meanwhile, and stop when paused or cancelled
+		task, err = m.repo.GetOneByID(args.TaskID)
+		if err != nil {
+			return err
+		}
+		if task.Status != constants.MaintenanceStatusRunning || task.Generation != args.Generation {
+			return nil
+		}
+
+		throttle := time.Duration(task.ThrottleMs) * time.Millisecond
+		if time.Now().Add(throttle).After(sliceEnd) {
+			return m.enqueue(ctx, task, throttle)
+		}
+		if throttle > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(throttle):
+			}
+		}
+	}
+}
+
+// enqueue hands the current generation of the task over to a job of the task queue
+func (m *Manager) enqueue(ctx context.Context, task *models.MaintenanceTask, delay time.Duration) error {
+	_, err := m.enqueuer.Enqueue(ctx, jobs.RunMaintenanceTaskArgs{TaskID: task.ID, Generation: task.Generation}, jobs.WithDelay(delay))
+	return err
+}
+
+// transition writes the columns and status of the task when it is in one of the from
+// statuses, and reports a conflict with its current status otherwise
+func (m *Manager) transition(task *models.MaintenanceTask, operation string, from []string, columns ...string) (*models.MaintenanceTask, error) {
+	ok, err := m.repo.Transition(task, from, columns...)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		current, err := m.repo.GetOneByID(task.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.ConflictError(fmt.Sprintf("The task is %s", current.Status), nil).
+			WithOperation(operation).
+			WithResource("maintenance_task").
+			WithContext("task_id", task.ID).
+			WithContext("status", current.Status)
+	}
+
+	return task, nil
+}
+
+// fail stops a running task on the error of a batch, which is kept to be shown to the
+// admins; resuming the task runs the batch again
+func (m *Manager) fail(task *models.MaintenanceTask, cause error) {
+	message := cause.Error()
+	if len(message) > constants.JobsMaxErrorLength {
+		message = message[:constants.JobsMaxErrorLength]
+	}
+	task.Status = constants.MaintenanceStatusFailed
+	task.LastError = &message
+
+	logger.Log.Error("Maintenance task failed",
+		zap.String("task_id", task.ID),
+		zap.String("operation", task.Operation),
+		zap.String("cursor", task.Cursor),
+		zap.Error(cause),
+	)
+	if _, err := m.repo.SaveRun(task, "status", "last_error"); err != nil {
+		logger.Log.Error("Failed to save maintenance task failure",
+			zap.String("task_id", task.ID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,303 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// MockMaintenanceRepository is a mock implementation of repositories.MaintenanceRepository
+type MockMaintenanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceRepository) Create(task *models.MaintenanceTask) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) GetOneByID(id string) (*models.MaintenanceTask, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceTask), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) Get(pr *dtos.PageableRequest) (*dtos.DataResponse[models.MaintenanceTask], error) {
+	args := m.Called(pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.MaintenanceTask]), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) GetActive(operation string) (*models.MaintenanceTask, error) {
+	args := m.Called(operation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceTask), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) Transition(task *models.MaintenanceTask, from []string, columns ...string) (bool, error) {
+	args := m.Called(task, from, columns)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) SaveRun(task *models.MaintenanceTask, columns ...string) (bool, error) {
+	args := m.Called(task, columns)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) Reindex(index string) error {
+	args := m.Called(index)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) CountTenantCredentials() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) GetTenantCredentialsAfter(afterID string, limit int) ([]models.TenantCredential, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]models.TenantCredential), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) CountWebhookEndpoints() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) GetWebhookEndpointsAfter(afterID string, limit int) ([]models.WebhookEndpoint, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) UpdateWebhookSecret(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+// MockEnqueuer is a mock implementation of jobs.Enqueuer
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+func (m *MockEnqueuer) Enqueue(ctx context.Context, args jobs.Args, opts ...jobs.EnqueueOption) (*models.Job, error) {
+	called := m.Called(ctx, args)
+	return &models.Job{}, called.Error(0)
+}
+
+// MockOperation is a mock implementation of Operation
+type MockOperation struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockOperation) Name() string {
+	return m.name
+}
+
+func (m *MockOperation) Description() string {
+	return "Mock operation " + m.name
+}
+
+func (m *MockOperation) Count(ctx context.Context, params json.RawMessage) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOperation) Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error) {
+	args := m.Called(ctx, params, cursor, size)
+	return args.Get(0).(Batch), args.Error(1)
+}
+
+func runningTask() *models.MaintenanceTask {
+	return &models.MaintenanceTask{
+		BaseModel:  models.BaseModel{ID: "0190b7f5-8f4a-7d3e-9c1b-2a6f4e8d1c3b"},
+		Operation:  "backfill",
+		Status:     constants.MaintenanceStatusRunning,
+		BatchSize:  2,
+		Generation: 1,
+	}
+}
+
+func errorType(err error) errors.ErrorType {
+	if appErr := errors.GetAppError(err); appErr != nil {
+		return appErr.Type
+	}
+	return ""
+}
+
+func TestNew_DuplicateName(t *testing.T) {
+	_, err := New(nil, nil, &MockOperation{name: "backfill"}, &MockOperation{name: "backfill"})
+
+	assert.ErrorContains(t, err, "duplicate maintenance operation backfill")
+}
+
+func TestManager_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     string
+		active        *models.MaintenanceTask
+		enqueueErr    error
+		expectedError errors.ErrorType
+	}{
+		{name: "success", operation: "backfill"},
+		{name: "unknown operation", operation: "defragment", expectedError: errors.ErrorTypeValidation},
+		{name: "active task", operation: "backfill", active: runningTask(), expectedError: errors.ErrorTypeConflict},
+		{name: "enqueue failure fails the task", operation: "backfill", enqueueErr: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMaintenanceRepository)
+			enqueuer := new(MockEnqueuer)
+			operation := &MockOperation{name: "backfill"}
+			manager, err := New(repo, enqueuer, operation)
+			require.NoError(t, err)
+
+			repo.On("GetActive", "backfill").Return(tt.active, nil).Maybe()
+			operation.On("Count", mock.Anything, json.RawMessage(nil)).Return(int64(42), nil).Maybe()
+			repo.On("Create", mock.Anything).Return(nil).Maybe()
+			var enqueued jobs.RunMaintenanceTaskArgs
+			enqueuer.On("Enqueue", mock.Anything, mock.AnythingOfType("jobs.RunMaintenanceTaskArgs")).Run(func(args mock.Arguments) {
+				enqueued = args.Get(1).(jobs.RunMaintenanceTaskArgs)
+			}).Return(tt.enqueueErr).Maybe()
+			repo.On("SaveRun", mock.Anything, []string{"status", "last_error"}).Return(true, nil).Maybe()
+
+			task, err := manager.Create(context.Background(), &dtos.CreateMaintenanceTaskRequest{Operation: tt.operation}, "admin")
+
+			switch {
+			case tt.expectedError != "":
+				assert.Equal(t, tt.expectedError, errorType(err))
+				repo.AssertNotCalled(t, "Create", mock.Anything)
+			case tt.enqueueErr != nil:
+				assert.ErrorIs(t, err, tt.enqueueErr)
+				repo.AssertCalled(t, "SaveRun", mock.Anything, []string{"status", "last_error"})
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, constants.MaintenanceStatusRunning, task.Status)
+				assert.Equal(t, constants.MaintenanceDefaultBatchSize, task.BatchSize)
+				assert.Equal(t, int64(42), task.Total)
+				assert.Equal(t, "admin", task.CreatedBy)
+				assert.Equal(t, jobs.RunMaintenanceTaskArgs{TaskID: task.ID, Generation: 1}, enqueued)
+			}
+		})
+	}
+}
+
+func TestManager_Resume(t *testing.T) {
+	tests := []struct {
+		name          string
+		transitioned  bool
+		expectedError errors.ErrorType
+	}{
+		{name: "success", transitioned: true},
+		{name: "task not paused or failed", expectedError: errors.ErrorTypeConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMaintenanceRepository)
+			enqueuer := new(MockEnqueuer)
+			manager, err := New(repo, enqueuer)
+			require.NoError(t, err)
+
+			lastError := "boom"
+			task := runningTask()
+			task.Status = constants.MaintenanceStatusFailed
+			task.LastError = &lastError
+			repo.On("GetOneByID", task.ID).Return(task, nil)
+			repo.On("Transition", task, []string{constants.MaintenanceStatusPaused, constants.MaintenanceStatusFailed},
+				[]string{"generation", "last_error"}).Return(tt.transitioned, nil)
+			enqueuer.On("Enqueue", mock.Anything, jobs.RunMaintenanceTaskArgs{TaskID: task.ID, Generation: 2}).Return(nil).Maybe()
+
+			resumed, err := manager.Resume(context.Background(), task.ID)
+
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, errorType(err))
+				enqueuer.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, resumed.Generation)
+			assert.Nil(t, resumed.LastError)
+			enqueuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	tests := []struct {
+		name              string
+		generation        int
+		pausedAfterFirst  bool
+		stepErr           error
+		expectedStatus    string
+		expectedProcessed int64
+		expectedSteps     int
+	}{
+		{name: "completes", generation: 1, expectedStatus: constants.MaintenanceStatusCompleted, expectedProcessed: 3, expectedSteps: 2},
+		{name: "stale generation is skipped", generation: 2, expectedStatus: constants.MaintenanceStatusRunning},
+		{name: "stops when paused", generation: 1, pausedAfterFirst: true, expectedStatus: constants.MaintenanceStatusPaused, expectedProcessed: 2, expectedSteps: 1},
+		{name: "step failure fails the task", generation: 1, stepErr: assert.AnError, expectedStatus: constants.MaintenanceStatusFailed, expectedSteps: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMaintenanceRepository)
+			operation := &MockOperation{name: "backfill"}
+			manager, err := New(repo, nil, operation)
+			require.NoError(t, err)
+
+			task := runningTask()
+			repo.On("GetOneByID", task.ID).Return(task, nil).Once()
+			if tt.pausedAfterFirst {
+				paused := runningTask()
+				paused.Status = constants.MaintenanceStatusPaused
+				repo.On("GetOneByID", task.ID).Return(paused, nil).Once()
+			} else {
+				repo.On("GetOneByID", task.ID).Return(task, nil)
+			}
+			operation.On("Step", mock.Anything, mock.Anything, "", 2).Return(Batch{Cursor: "b", Processed: 2}, tt.stepErr).Once()
+			operation.On("Step", mock.Anything, mock.Anything, "b", 2).Return(Batch{Cursor: "c", Processed: 1, Done: true}, nil).Once()
+			repo.On("SaveRun", task, mock.Anything).Return(true, nil)
+
+			err = manager.Run(context.Background(), jobs.RunMaintenanceTaskArgs{TaskID: task.ID, Generation: tt.generation})
+
+			require.NoError(t, err)
+			operation.AssertNumberOfCalls(t, "Step", tt.expectedSteps)
+			if tt.pausedAfterFirst {
+				assert.Equal(t, int64(2), task.Processed)
+				return
+			}
+			assert.Equal(t, tt.expectedStatus, task.Status)
+			assert.Equal(t, tt.expectedProcessed, task.Processed)
+			if tt.stepErr != nil {
+				require.NotNil(t, task.LastError)
+				assert.Equal(t, assert.AnError.Error(), *task.LastError)
+			}
+		})
+	}
+}
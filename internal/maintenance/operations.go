@@ -0,0 +1,243 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/projections"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/services"
+	"golang-boilerplate/internal/webhooks"
+)
+
+// reindexSearch rebuilds the full-text search indexes, one per batch
+type reindexSearch struct {
+	repo repositories.MaintenanceRepository
+}
+
+// ProvideReindexSearch creates the reindex_search operation
+func ProvideReindexSearch(repo repositories.MaintenanceRepository) Operation {
+	return &reindexSearch{repo: repo}
+}
+
+func (o *reindexSearch) Name() string {
+	return constants.MaintenanceOperationReindexSearch
+}
+
+func (o *reindexSearch) Description() string {
+	return "Rebuild the full-text search indexes without blocking the writes, one per batch"
+}
+
+func (o *reindexSearch) Count(ctx context.Context, params json.RawMessage) (int64, error) {
+	if err := noParams(o.Name(), params); err != nil {
+		return 0, err
+	}
+	return int64(len(constants.MaintenanceSearchIndexes)), nil
+}
+
+func (o *reindexSearch) Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error) {
+	return stepList(constants.MaintenanceSearchIndexes, cursor, func(index string) error {
+		return o.repo.Reindex(index)
+	})
+}
+
+// rebuildProjections rebuilds the read models, one per batch
+type rebuildProjections struct {
+	projector *projections.Projector
+}
+
+// rebuildProjectionsParams selects the projections to rebuild, all of them when empty
+type rebuildProjectionsParams struct {
+	Projections []string `json:"projections"`
+}
+
+// ProvideRebuildProjections creates the rebuild_projections operation
+func ProvideRebuildProjections(projector *projections.Projector) Operation {
+	return &rebuildProjections{projector: projector}
+}
+
+func (o *rebuildProjections) Name() string {
+	return constants.MaintenanceOperationRebuildProjections
+}
+
+func (o *rebuildProjections) Description() string {
+	return `Rebuild the read models from the source tables, one per batch; params {"projections": [...]} selects them, all by default`
+}
+
+func (o *rebuildProjections) Count(ctx context.Context, params json.RawMessage) (int64, error) {
+	names, err := o.names(params)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(names)), nil
+}
+
+func (o *rebuildProjections) Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error) {
+	names, err := o.names(params)
+	if err != nil {
+		return Batch{}, err
+	}
+	return stepList(names, cursor, func(name string) error {
+		return o.projector.Rebuild(ctx, name)
+	})
+}
+
+func (o *rebuildProjections) names(params json.RawMessage) ([]string, error) {
+	var p rebuildProjectionsParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(o.Name(), err)
+		}
+	}
+	known := o.projector.Names()
+	if len(p.Projections) == 0 {
+		return known, nil
+	}
+	for _, name := range p.Projections {
+		if !slices.Contains(known, name) {
+			return nil, invalidParams(o.Name(), nil).
+				WithContext("projection", name).
+				WithContext("projections", known)
+		}
+	}
+	return p.Projections, nil
+}
+
+// reencryptCredentials seals the tenant credentials again under new data keys, by ID
+type reencryptCredentials struct {
+	repo              repositories.MaintenanceRepository
+	credentialService services.TenantCredentialService
+}
+
+// ProvideReencryptCredentials creates the reencrypt_tenant_credentials operation
+func ProvideReencryptCredentials(repo repositories.MaintenanceRepository, credentialService services.TenantCredentialService) Operation {
+	return &reencryptCredentials{repo: repo, credentialService: credentialService}
+}
+
+func (o *reencryptCredentials) Name() string {
+	return constants.MaintenanceOperationReencryptCredentials
+}
+
+func (o *reencryptCredentials) Description() string {
+	return "Seal the tenant credentials again under new data keys of the current KMS key, e.g. after a key rotation"
+}
+
+func (o *reencryptCredentials) Count(ctx context.Context, params json.RawMessage) (int64, error) {
+	if err := noParams(o.Name(), params); err != nil {
+		return 0, err
+	}
+	return o.repo.CountTenantCredentials()
+}
+
+func (o *reencryptCredentials) Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error) {
+	credentials, err := o.repo.GetTenantCredentialsAfter(cursor, size)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	batch := Batch{Cursor: cursor, Done: len(credentials) < size}
+	for i := range credentials {
+		if err := o.credentialService.Reseal(ctx, &credentials[i]); err != nil {
+			return batch, err
+		}
+		batch.Cursor = credentials[i].ID
+		batch.Processed++
+	}
+	return batch, nil
+}
+
+// reencryptWebhookSecrets seals the signing secrets of the webhook endpoints again under
+// new data keys, by ID
+type reencryptWebhookSecrets struct {
+	repo repositories.MaintenanceRepository
+	keys kms.KeyManager
+}
+
+// ProvideReencryptWebhookSecrets creates the reencrypt_webhook_secrets operation
+func ProvideReencryptWebhookSecrets(repo repositories.MaintenanceRepository, keys kms.KeyManager) Operation {
+	return &reencryptWebhookSecrets{repo: repo, keys: keys}
+}
+
+func (o *reencryptWebhookSecrets) Name() string {
+	return constants.MaintenanceOperationReencryptWebhookSecrets
+}
+
+func (o *reencryptWebhookSecrets) Description() string {
+	return "Seal the signing secrets of the webhook endpoints again under new data keys of the current KMS key, e.g. after a key rotation"
+}
+
+func (o *reencryptWebhookSecrets) Count(ctx context.Context, params json.RawMessage) (int64, error) {
+	if err := noParams(o.Name(), params); err != nil {
+		return 0, err
+	}
+	return o.repo.CountWebhookEndpoints()
+}
+
+func (o *reencryptWebhookSecrets) Step(ctx context.Context, params json.RawMessage, cursor string, size int) (Batch, error) {
+	endpoints, err := o.repo.GetWebhookEndpointsAfter(cursor, size)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	batch := Batch{Cursor: cursor, Done: len(endpoints) < size}
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		secret, err := webhooks.OpenSecret(ctx, o.keys, endpoint)
+		if err != nil {
+			return batch, err
+		}
+		if err := webhooks.SealSecret(ctx, o.keys, endpoint, secret); err != nil {
+			return batch, err
+		}
+		if err := o.repo.UpdateWebhookSecret(endpoint); err != nil {
+			return batch, err
+		}
+		batch.Cursor = endpoint.ID
+		batch.Processed++
+	}
+	return batch, nil
+}
+
+// stepList runs the next item of a fixed list, the cursor being the number of items run
+func stepList(items []string, cursor string, run func(item string) error) (Batch, error) {
+	position := 0
+	if cursor != "" {
+		var err error
+		if position, err = strconv.Atoi(cursor); err != nil {
+			return Batch{}, err
+		}
+	}
+	if position >= len(items) {
+		return Batch{Cursor: cursor, Done: true}, nil
+	}
+
+	if err := run(items[position]); err != nil {
+		return Batch{Cursor: cursor}, err
+	}
+	position++
+	return Batch{Cursor: strconv.Itoa(position), Processed: 1, Done: position == len(items)}, nil
+}
+
+// noParams rejects the params of an operation that takes none
+func noParams(operation string, params json.RawMessage) error {
+	if len(params) == 0 {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(params, &fields); err != nil || len(fields) > 0 {
+		return invalidParams(operation, err)
+	}
+	return nil
+}
+
+func invalidParams(operation string, cause error) *errors.AppError {
+	return errors.ValidationError("Invalid params for the maintenance operation", cause).
+		WithOperation("create_maintenance_task").
+		WithResource("maintenance_task").
+		WithContext("operation", operation)
+}
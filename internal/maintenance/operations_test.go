@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepList(t *testing.T) {
+	items := []string{"first", "second"}
+
+	tests := []struct {
+		name     string
+		cursor   string
+		expected Batch
+		run      string
+	}{
+		{name: "first item", cursor: "", expected: Batch{Cursor: "1", Processed: 1}, run: "first"},
+		{name: "last item", cursor: "1", expected: Batch{Cursor: "2", Processed: 1, Done: true}, run: "second"},
+		{name: "past the end", cursor: "2", expected: Batch{Cursor: "2", Done: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var run string
+			batch, err := stepList(items, tt.cursor, func(item string) error {
+				run = item
+				return nil
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, batch)
+			assert.Equal(t, tt.run, run)
+		})
+	}
+}
+
+func TestNoParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        string
+		expectedError bool
+	}{
+		{name: "none"},
+		{name: "empty object", params: `{}`},
+		{name: "field", params: `{"projections":["company_summaries"]}`, expectedError: true},
+		{name: "not an object", params: `[1]`, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params json.RawMessage
+			if tt.params != "" {
+				params = json.RawMessage(tt.params)
+			}
+
+			err := noParams("reindex_search", params)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/sandbox"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyOrToken authenticates a request carrying the X-API-Key header by its API key,
+// which is only valid for the company of the :id route parameter, and any other request
+// by the token middlewares, e.g. AuthMiddleware and RequireRole. The key is stored in the
+// context under constants.ContextKeyAPIKey and its call is counted by the usage recorder.
+// A sandbox key runs the request in its sandbox tenant, flagged by the X-Sandbox header.
+func APIKeyOrToken(apiKeyService services.APIKeyService, recorder *services.APIKeyUsageRecorder, tokenMiddlewares ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withToken := next
+		for i := len(tokenMiddlewares) - 1; i >= 0; i-- {
+			withToken = tokenMiddlewares[i](withToken)
+		}
+
+		return func(c echo.Context) error {
+			rawKey := c.Request().Header.Get(constants.HeaderAPIKey)
+			if rawKey == "" {
+				return withToken(c)
+			}
+
+			key, err := apiKeyService.Authenticate(c.Request().Context(), rawKey)
+			if err != nil {
+				if errors.GetHTTPStatus(err) == http.StatusUnauthorized {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": errors.GetErrorMessage(err),
+					})
+				}
+				return err
+			}
+
+			if key.CompanyID != c.Param("id") {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Insufficient permissions",
+				})
+			}
+
+			c.Set(constants.ContextKeyAPIKey, key)
+			if key.Sandbox {
+				c.SetRequest(c.Request().WithContext(sandbox.WithCompany(c.Request().Context(), key.CompanyID)))
+				c.Response().Header().Set(constants.SandboxHeader, "true")
+			}
+			monitoring.SetPrincipal(c.Request().Context(), monitoring.Principal{
+				UserID:   "api_key:" + key.ID,
+				TenantID: key.CompanyID,
+			})
+			recorder.Record(key.ID, c.Request().Method+" "+c.Path())
+
+			return next(c)
+		}
+	}
+}
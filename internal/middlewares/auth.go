@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/integration/auth"
 	"golang-boilerplate/internal/monitoring"
 
@@ -17,8 +19,23 @@ import (
 	"go.uber.org/zap"
 )
 
-// AuthMiddleware creates middleware for JWT authentication
+// AuthMiddleware creates middleware for JWT authentication. With
+// KEYCLOAK_TOKEN_VALIDATION=jwks the token is verified offline against the keys of the
+// realm, and introspected by Keycloak only when the keys cannot be fetched; with
+// introspection every token is introspected.
 func AuthMiddleware(cfg *config.Config, authService auth.AuthService) echo.MiddlewareFunc {
+	return authMiddleware(cfg, authService, cfg.KeycloakTokenValidation == constants.TokenValidationIntrospection)
+}
+
+// IntrospectedAuthMiddleware introspects the token with Keycloak whatever
+// KEYCLOAK_TOKEN_VALIDATION, for the routes sensitive to revocation: a token revoked by a
+// logout or of a disabled user is rejected at once rather than once it expires.
+func IntrospectedAuthMiddleware(cfg *config.Config, authService auth.AuthService) echo.MiddlewareFunc {
+	return authMiddleware(cfg, authService, true)
+}
+
+func authMiddleware(cfg *config.Config, authService auth.AuthService, introspect bool) echo.MiddlewareFunc {
+	monitoringUser := MonitoringUser(cfg, authService)
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Get Authorization header
@@ -44,6 +61,22 @@ func AuthMiddleware(cfg *config.Config, authService auth.AuthService) echo.Middl
 				})
 			}
 
+			if !introspect {
+				var tokenClaims auth.TokenClaims
+				err := authService.VerifyAccessToken(c.Request().Context(), token, &tokenClaims)
+				if err == nil {
+					c.Set(authService.GetClaimsKey(), &tokenClaims)
+					return monitoringUser(next)(c)
+				}
+				if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeExternal {
+					return c.JSON(http.StatusUnauthorized, map[string]string{
+						"error": "Invalid token",
+					})
+				}
+				// The keys of the realm cannot be fetched, Keycloak decides
+				logger.Log.Warn("Falling back to token introspection", zap.Error(err))
+			}
+
 			// Validate token
 			user, err := authService.ValidateToken(token)
 			if err != nil {
@@ -92,7 +125,8 @@ func AuthMiddleware(cfg *config.Config, authService auth.AuthService) echo.Middl
 			// Store claims in context
 			c.Set(authService.GetClaimsKey(), &tokenClaims)
 
-			return next(c)
+			// Attach the principal to Sentry / New Relic before running the handler
+			return monitoringUser(next)(c)
 		}
 	}
 }
@@ -109,19 +143,7 @@ func RequireRole(cfg *config.Config, roles ...string) echo.MiddlewareFunc {
 				})
 			}
 
-			// Extract all roles from TokenClaims
-			userRoles := extractRolesFromClaims(claims, cfg.KeycloakClientID)
-
-			// Check if user has any of the required roles
-			hasRole := false
-			for _, requiredRole := range roles {
-				if slices.Contains(userRoles, requiredRole) {
-					hasRole = true
-					break
-				}
-			}
-
-			if !hasRole {
+			if !HasAnyRole(claims, cfg.KeycloakClientID, roles...) {
 				return c.JSON(http.StatusForbidden, map[string]string{
 					"error": "Insufficient permissions",
 				})
@@ -132,8 +154,20 @@ func RequireRole(cfg *config.Config, roles ...string) echo.MiddlewareFunc {
 	}
 }
 
-// extractRolesFromClaims extracts all roles from TokenClaims (realm + client roles)
-func extractRolesFromClaims(claims *auth.TokenClaims, clientID string) []string {
+// HasAnyRole reports whether the claims grant any of the roles, at realm level or for
+// the client
+func HasAnyRole(claims *auth.TokenClaims, clientID string, roles ...string) bool {
+	userRoles := ExtractRoles(claims, clientID)
+	for _, requiredRole := range roles {
+		if slices.Contains(userRoles, requiredRole) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractRoles extracts all roles from TokenClaims (realm + client roles)
+func ExtractRoles(claims *auth.TokenClaims, clientID string) []string {
 	var roles []string
 
 	// Add realm-level roles
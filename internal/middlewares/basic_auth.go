@@ -8,8 +8,13 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// BasicAuthMiddleware checks the BASIC_AUTH_USER and BASIC_AUTH_SECRET credentials; it
+// rejects every request when they are not configured
 func BasicAuthMiddleware(cfg config.Config) echo.MiddlewareFunc {
 	return middleware.BasicAuth(func(username, password string, c echo.Context) (bool, error) {
+		if cfg.BasicAuthUsername == "" || cfg.BasicAuthPassword == "" {
+			return false, nil
+		}
 		if subtle.ConstantTimeCompare([]byte(username), []byte(cfg.BasicAuthUsername)) == 1 &&
 			subtle.ConstantTimeCompare([]byte(password), []byte(cfg.BasicAuthPassword)) == 1 {
 			return true, nil
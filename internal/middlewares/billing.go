@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireEntitlement creates middleware that requires the plan of the company of the
+// route, named by its id parameter, to grant a feature. It runs after the authentication,
+// like RequireRole.
+func RequireEntitlement(billingService services.BillingService, key string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := billingService.CheckEntitlement(c.Request().Context(), c.Param("id"), key); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package middlewares
 import (
 	"net/http"
 
+	"golang-boilerplate/internal/constants"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -10,11 +12,12 @@ import (
 func CORS() echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-CSRF-Token"},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-CSRF-Token", constants.HeaderEnvelope},
 		AllowMethods: []string{
 			http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch,
 			http.MethodPost, http.MethodDelete, http.MethodOptions,
 		},
-		ExposeHeaders: []string{"X-CSRF-Token"},
+		ExposeHeaders: []string{"X-CSRF-Token", constants.DemoModeHeader, constants.HeaderDeprecation, constants.HeaderSunset, constants.HeaderLink,
+			constants.HeaderEnvelope, constants.HeaderTotalCount, constants.HeaderPage, constants.HeaderPageSize},
 	})
 }
@@ -2,17 +2,27 @@ package middlewares
 
 import (
 	"net/http"
+	"strings"
 
 	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-// CSRF returns a configured CSRF middleware.
+// CSRF returns a configured CSRF middleware. The SCIM endpoints, the Keycloak event
+// webhook, the SES notifications and the payment webhook are skipped: the identity
+// providers, Keycloak, SNS and the payment providers calling them are not browsers and
+// authenticate with a bearer token or a signature.
 func CSRF(cfg *config.Config) echo.MiddlewareFunc {
 	//nolint:gosec // G101: cookie name is not a secret
 	return middleware.CSRFWithConfig(middleware.CSRFConfig{
+		Skipper: func(c echo.Context) bool {
+			path := c.Request().URL.Path
+			return strings.HasPrefix(path, constants.SCIMBasePath+"/") || path == constants.KeycloakEventsPath ||
+				path == constants.SESNotificationsPath || path == constants.PaymentWebhookPath
+		},
 		TokenLookup:    "header:X-CSRF-Token",
 		CookieName:     "csrf_token",
 		CookiePath:     "/",
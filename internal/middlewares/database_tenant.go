@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DatabaseTenant runs the database sessions of a company route as the company of the
+// authenticated principal when DATABASE_RLS_ENABLED is set, so that the row level
+// security policies reject the rows of the other companies even if a repository forgets
+// to scope a query. The company is the one of the API key, or of the token organization;
+// a request whose :id route parameter names another company is forbidden. Admins manage
+// every company, and run as the one of the route. It runs after the authentication of the
+// route, like RequireRole.
+func DatabaseTenant(cfg *config.Config, companyRepo repositories.CompanyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.DatabaseRLSEnabled {
+			return next
+		}
+		return func(c echo.Context) error {
+			tenantID, err := principalCompany(c, cfg, companyRepo)
+			if err != nil {
+				return err
+			}
+			if tenantID == "" || tenantID != c.Param("id") {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Insufficient permissions",
+				})
+			}
+
+			c.SetRequest(c.Request().WithContext(db.WithTenant(c.Request().Context(), tenantID)))
+			return next(c)
+		}
+	}
+}
+
+// principalCompany returns the company the authenticated principal acts for, none when
+// its token has no organization of a company
+func principalCompany(c echo.Context, cfg *config.Config, companyRepo repositories.CompanyRepository) (string, error) {
+	if key, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey); ok {
+		return key.CompanyID, nil
+	}
+
+	claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return "", nil
+	}
+	if HasAnyRole(claims, cfg.KeycloakClientID, constants.AdminRoles...) {
+		return c.Param("id"), nil
+	}
+
+	organizationID, _ := c.Get(OrganizationIDContextKey).(string)
+	if organizationID == "" {
+		return "", nil
+	}
+	company, err := companyRepo.GetByKeycloakID(organizationID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return company.ID, nil
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCompanyRepository resolves the companies of the Keycloak organizations
+type fakeCompanyRepository struct {
+	repositories.CompanyRepository
+	organizations map[string]string
+}
+
+func (r *fakeCompanyRepository) GetByKeycloakID(keycloakID string) (*models.Company, error) {
+	companyID, ok := r.organizations[keycloakID]
+	if !ok {
+		return nil, errors.NotFoundError("Company", nil)
+	}
+	company := &models.Company{KeycloakID: keycloakID}
+	company.ID = companyID
+	return company, nil
+}
+
+func TestDatabaseTenant(t *testing.T) {
+	cfg := &config.Config{DatabaseRLSEnabled: true, KeycloakKeyClaim: "claims", KeycloakClientID: "api"}
+	companyRepo := &fakeCompanyRepository{organizations: map[string]string{"org-a": "company-a"}}
+	manager := &auth.TokenClaims{}
+	manager.RealmAccess.Roles = []string{constants.RoleCompanyManager}
+	admin := &auth.TokenClaims{}
+	admin.RealmAccess.Roles = []string{constants.RoleAdmin}
+
+	tests := []struct {
+		name           string
+		companyID      string
+		claims         *auth.TokenClaims
+		organizationID string
+		apiKey         *models.APIKey
+		expectedTenant string
+	}{
+		{name: "manager of the company", companyID: "company-a", claims: manager, organizationID: "org-a", expectedTenant: "company-a"},
+		{name: "manager of another company", companyID: "company-b", claims: manager, organizationID: "org-a"},
+		{name: "organization without a company", companyID: "company-a", claims: manager, organizationID: "org-c"},
+		{name: "token without an organization", companyID: "company-a", claims: manager},
+		{name: "admin runs as the company of the route", companyID: "company-b", claims: admin, expectedTenant: "company-b"},
+		{name: "API key of the company", companyID: "company-a", apiKey: &models.APIKey{CompanyID: "company-a"}, expectedTenant: "company-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues(tt.companyID)
+			if tt.claims != nil {
+				c.Set(cfg.KeycloakKeyClaim, tt.claims)
+			}
+			if tt.organizationID != "" {
+				c.Set(OrganizationIDContextKey, tt.organizationID)
+			}
+			if tt.apiKey != nil {
+				c.Set(constants.ContextKeyAPIKey, tt.apiKey)
+			}
+
+			var tenantID string
+			called := false
+			handler := DatabaseTenant(cfg, companyRepo)(func(c echo.Context) error {
+				called = true
+				tenantID, _ = db.TenantID(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := handler(c)
+
+			assert.NoError(t, err)
+			if tt.expectedTenant == "" {
+				assert.False(t, called)
+				assert.Equal(t, http.StatusForbidden, c.Response().Status)
+				return
+			}
+			assert.True(t, called)
+			assert.Equal(t, tt.expectedTenant, tenantID)
+		})
+	}
+
+	t.Run("disabled without row level security", func(t *testing.T) {
+		e := echo.New()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues("company-b")
+		called := false
+		handler := DatabaseTenant(&config.Config{}, companyRepo)(func(c echo.Context) error {
+			called = true
+			_, ok := db.TenantID(c.Request().Context())
+			assert.False(t, ok)
+			return nil
+		})
+
+		assert.NoError(t, handler(c))
+		assert.True(t, called)
+	})
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DemoMode flags every request when the instance runs in demo mode, so that clients can
+// show a banner. The flag is sent as the X-Demo-Mode header and as meta.demo in the body.
+func DemoMode(cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !cfg.DemoMode {
+			return next
+		}
+		return func(c echo.Context) error {
+			c.Set(constants.DemoModeContextKey, true)
+			c.Response().Header().Set(constants.DemoModeHeader, "true")
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/deprecation"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// DeprecatedRoute marks the responses of a deprecated route with the Deprecation and
+// Sunset headers of its deprecation and counts its calls per consumer. The consumer is
+// read once the handler returns, so the middleware can run before the authentication.
+// It panics when the deprecation is not declared.
+func DeprecatedRoute(cfg *config.Config, registry *deprecation.Registry, recorder *services.DeprecationUsageRecorder, id string) echo.MiddlewareFunc {
+	d := registry.MustGet(id)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			deprecation.SetHeaders(c.Response().Header(), d)
+			err := next(c)
+			recordDeprecationUsage(c, cfg, recorder, d.ID)
+			return err
+		}
+	}
+}
+
+// DeprecationBinder binds the requests like the default binder, then marks the responses
+// of the requests that set a deprecated field, tagged `deprecated:"<id>"`, with the
+// headers of its deprecation and counts the call per consumer
+type DeprecationBinder struct {
+	echo.DefaultBinder
+	cfg      *config.Config
+	registry *deprecation.Registry
+	recorder *services.DeprecationUsageRecorder
+}
+
+// NewDeprecationBinder creates the binder of the router
+func NewDeprecationBinder(cfg *config.Config, registry *deprecation.Registry, recorder *services.DeprecationUsageRecorder) *DeprecationBinder {
+	return &DeprecationBinder{
+		cfg:      cfg,
+		registry: registry,
+		recorder: recorder,
+	}
+}
+
+// Bind implements echo.Binder
+func (b *DeprecationBinder) Bind(i interface{}, c echo.Context) error {
+	if err := b.DefaultBinder.Bind(i, c); err != nil {
+		return err
+	}
+
+	for _, id := range deprecation.Fields(i) {
+		d, ok := b.registry.Get(id)
+		if !ok {
+			logger.Log.Warn("Request field tagged with an undeclared deprecation", zap.String("deprecation_id", id))
+			continue
+		}
+		deprecation.SetHeaders(c.Response().Header(), d)
+		recordDeprecationUsage(c, b.cfg, b.recorder, d.ID)
+	}
+	return nil
+}
+
+// recordDeprecationUsage counts a call to a deprecation by the consumer of the request
+func recordDeprecationUsage(c echo.Context, cfg *config.Config, recorder *services.DeprecationUsageRecorder, id string) {
+	consumer, tenant := deprecationConsumer(c, cfg)
+	recorder.Record(id, consumer, tenant)
+}
+
+// deprecationConsumer identifies the caller of a request: the API key, else the OAuth
+// client the token was issued to, else the subject of the token. The tenant is the company
+// of the API key or the organization of the token.
+func deprecationConsumer(c echo.Context, cfg *config.Config) (string, string) {
+	if key, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey); ok {
+		return constants.DeprecationConsumerAPIKey + ":" + key.ID, key.CompanyID
+	}
+
+	tenant, _ := c.Get(OrganizationIDContextKey).(string)
+	claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+	if !ok {
+		return constants.DeprecationConsumerAnonymous, tenant
+	}
+	if clientID, _ := claims.MapClaims["azp"].(string); clientID != "" {
+		return constants.DeprecationConsumerClient + ":" + clientID, tenant
+	}
+	return constants.DeprecationConsumerUser + ":" + claims.Sub, tenant
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Envelope selects the envelope of the responses: the one requested in the X-Envelope
+// header, or defaultEnvelope. The router applies it with the v1 envelope, a route serving
+// machine integrations applies it again with the raw one to change its default.
+func Envelope(defaultEnvelope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			envelope := c.Request().Header.Get(constants.HeaderEnvelope)
+			switch envelope {
+			case "":
+				envelope = defaultEnvelope
+			case constants.EnvelopeV1, constants.EnvelopeRaw:
+			default:
+				return errors.ValidationError("Unsupported envelope, expected "+constants.EnvelopeV1+" or "+constants.EnvelopeRaw, nil).
+					WithContext(constants.HeaderEnvelope, envelope)
+			}
+
+			if c.Get(constants.EnvelopeContextKey) == nil {
+				c.Response().Header().Add(echo.HeaderVary, constants.HeaderEnvelope)
+			}
+			c.Set(constants.EnvelopeContextKey, envelope)
+			return next(c)
+		}
+	}
+}
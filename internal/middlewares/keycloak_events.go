@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"github.com/labstack/echo/v4"
+)
+
+// KeycloakEventSignature checks the HMAC-SHA256 signature of the events posted by the
+// event listener of the realm, keyed with KEYCLOAK_EVENTS_SECRET; it rejects every request
+// when the secret is not configured. The body is read once and left for the handler.
+func KeycloakEventSignature(cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, constants.KeycloakEventMaxBodySize+1))
+			if err != nil {
+				return err
+			}
+			if len(body) > constants.KeycloakEventMaxBodySize {
+				return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+					"error": "Event too large",
+				})
+			}
+
+			signature, err := hex.DecodeString(strings.TrimPrefix(c.Request().Header.Get(constants.KeycloakEventSignatureHeader), "sha256="))
+			mac := hmac.New(sha256.New, []byte(cfg.KeycloakEventsSecret))
+			mac.Write(body)
+			if err != nil || cfg.KeycloakEventsSecret == "" || !hmac.Equal(signature, mac.Sum(nil)) {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Invalid event signature",
+				})
+			}
+
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			return next(c)
+		}
+	}
+}
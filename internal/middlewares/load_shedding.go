@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/loadshed"
+
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// LoadShedding rejects with a 503 the requests beyond the concurrency limit and its queue,
+// before they reach the authentication, the database or Keycloak. It runs after the
+// request logging so that the shed requests are logged, and writes their response itself
+// rather than reporting each one as an error while the instance is overloaded.
+// The probes and the long-lived realtime connections are not limited.
+func LoadShedding(cfg *config.Config, nrApp *newrelic.Application) echo.MiddlewareFunc {
+	if cfg.LoadShedMaxConcurrency == 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	// Echo wraps the handler with the middlewares of the router on every request, so the
+	// limiter is shared by all of them rather than created with each wrap
+	limiter := loadshed.NewLimiter(cfg.LoadShedMaxConcurrency, cfg.LoadShedMaxQueue, cfg.LoadShedQueueTimeout)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipLoadShedding(c) {
+				return next(c)
+			}
+
+			if !limiter.Acquire(c.Request().Context()) {
+				if nrApp != nil {
+					nrApp.RecordCustomMetric("Custom/LoadShedding/Rejected", 1)
+				}
+				c.Response().Header().Set(echo.HeaderRetryAfter, constants.LoadShedRetryAfter)
+				res := &dtos.BaseResponse[any]{Meta: dtos.GetMeta(c, constants.ServiceUnavailable, http.StatusServiceUnavailable)}
+				res.Meta.Message = "Server is overloaded, retry later"
+				return res.JSON(c)
+			}
+			defer limiter.Release()
+
+			return next(c)
+		}
+	}
+}
+
+// skipLoadShedding reports the requests that must not wait for a slot: the probes, which
+// would fail the instance under load, and the WebSocket and event stream connections,
+// which would hold their slot for as long as they stay open. They are matched by their
+// route, as the headers of a request are set by the client.
+func skipLoadShedding(c echo.Context) bool {
+	switch c.Path() {
+	case constants.LivenessPath, constants.ReadinessPath, constants.RealtimeWebSocketPath, constants.RealtimeStreamPath:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedding(t *testing.T) {
+	e := echo.New()
+	e.Use(LoadShedding(&config.Config{LoadShedMaxConcurrency: 1, LoadShedQueueTimeout: time.Millisecond}, nil))
+
+	// The only slot is held by a request until it is released
+	started, release := make(chan struct{}), make(chan struct{})
+	e.GET("/api/v1/slow", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusOK)
+	})
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/api/v1/users", ok)
+	e.GET(constants.LivenessPath, ok)
+	e.GET(constants.RealtimeWebSocketPath, ok)
+	e.GET(constants.RealtimeStreamPath, ok)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/slow", nil))
+	}()
+	<-started
+	defer func() {
+		close(release)
+		<-done
+	}()
+
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "route is shed", path: "/api/v1/users", expectedStatus: http.StatusServiceUnavailable},
+		{
+			name:           "route with a WebSocket upgrade is shed",
+			path:           "/api/v1/users",
+			headers:        map[string]string{echo.HeaderConnection: "Upgrade", echo.HeaderUpgrade: "websocket"},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "route accepting an event stream is shed",
+			path:           "/api/v1/users",
+			headers:        map[string]string{echo.HeaderAccept: "text/event-stream"},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{name: "probe is not shed", path: constants.LivenessPath, expectedStatus: http.StatusOK},
+		{name: "WebSocket route is not shed", path: constants.RealtimeWebSocketPath, expectedStatus: http.StatusOK},
+		{name: "event stream route is not shed", path: constants.RealtimeStreamPath, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, constants.LoadShedRetryAfter, rec.Header().Get(echo.HeaderRetryAfter))
+			}
+		})
+	}
+}
@@ -56,8 +56,8 @@ func RequestLogging(cfg *config.Config) echo.MiddlewareFunc {
 					userID = claims.Sub
 				}
 			}
-			if org := c.Get("organization_id"); org != nil {
-				organizationID = org.(string)
+			if org, ok := c.Get(OrganizationIDContextKey).(string); ok {
+				organizationID = org
 			}
 
 			// Get request body (captured by upstream middleware)
@@ -164,3 +164,14 @@ func LogBodyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// RedactLogBody keeps the body of the request, which holds credentials or tokens, out of
+// the request logs and the Sentry reports
+func RedactLogBody() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("log_body", "[redacted]")
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireMFA creates middleware that requires the token of a login with a second factor
+// on the sensitive routes, when MFA_ENFORCED is set. It runs after the authentication,
+// like RequireRole. A token without one gets a 401 with the insufficient_user_authentication
+// challenge of RFC 9470, so that the client logs the user in again with a second factor.
+func RequireMFA(cfg *config.Config) echo.MiddlewareFunc {
+	challenge := `Bearer error="insufficient_user_authentication", error_description="A login with a second factor is required"`
+	if len(cfg.MFAAcrValues) > 0 {
+		challenge += fmt.Sprintf(`, acr_values="%s"`, strings.Join(cfg.MFAAcrValues, " "))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "User not authenticated",
+				})
+			}
+
+			if cfg.MFAEnforced && !HasMFA(claims, cfg.MFAAcrValues) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Multi-factor authentication required",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// HasMFA reports whether the claims are those of a login with a second factor: their
+// authentication methods include an OTP or their authentication context is one of
+// acrValues
+func HasMFA(claims *auth.TokenClaims, acrValues []string) bool {
+	if slices.Contains(claims.AMR, constants.MFAMethodOTP) {
+		return true
+	}
+	return claims.ACR != "" && slices.Contains(acrValues, claims.ACR)
+}
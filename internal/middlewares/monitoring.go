@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/monitoring"
+
+	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// OrganizationIDContextKey is the echo context key holding the tenant of the authenticated principal
+const OrganizationIDContextKey = "organization_id"
+
+// MonitoringUser enriches the request-scoped Sentry hub and New Relic transaction with the
+// authenticated principal (user id, email hash, tenant). It expects the token claims to be
+// set by AuthMiddleware and is a no-op for anonymous requests.
+func MonitoringUser(cfg *config.Config, authService auth.AuthService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+			if !ok || claims == nil {
+				return next(c)
+			}
+
+			tenantID := ""
+			if org, err := authService.GetOrganization(claims); err == nil {
+				tenantID = org.ID
+				if tenantID == "" {
+					tenantID = org.Name
+				}
+			}
+			if tenantID != "" {
+				c.Set(OrganizationIDContextKey, tenantID)
+			}
+
+			monitoring.SetPrincipal(c.Request().Context(), monitoring.NewPrincipal(claims.Sub, claims.Email, tenantID))
+
+			return next(c)
+		}
+	}
+}
+
+// NewRelicTransaction starts a New Relic web transaction per request and stores it in the
+// request context. It is a no-op when New Relic is not configured.
+func NewRelicTransaction(app *newrelic.Application) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if app == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			txn := app.StartTransaction(req.Method + " " + c.Path())
+			defer txn.End()
+
+			txn.SetWebRequestHTTP(req)
+			c.Response().Writer = txn.SetWebResponse(c.Response().Writer)
+			c.SetRequest(req.WithContext(newrelic.NewContext(req.Context(), txn)))
+
+			err := next(c)
+			if err != nil {
+				txn.NoticeError(err)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TenantPerformance records the response time and status of the requests of the tenants
+// in the performance recorder, by route template so that the path parameters do not
+// multiply the routes. The tenant is the company of the API key or the organization set
+// by AuthMiddleware, which run after it; anonymous requests are not recorded.
+func TenantPerformance(recorder *services.PerformanceRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			tenant, _ := c.Get(OrganizationIDContextKey).(string)
+			if key, ok := c.Get(constants.ContextKeyAPIKey).(*models.APIKey); ok {
+				tenant = key.CompanyID
+			}
+			if tenant == "" {
+				return err
+			}
+
+			// Errors returned by the handlers are written by the outer error middleware
+			status := c.Response().Status
+			if err != nil && !c.Response().Committed {
+				status = errors.GetHTTPStatus(err)
+			}
+			recorder.Record(tenant, c.Request().Method+" "+c.Path(), status, time.Since(start))
+
+			return err
+		}
+	}
+}
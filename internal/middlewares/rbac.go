@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireDBPermission creates middleware that requires a permission of the database RBAC,
+// e.g. users:write, granted by the roles assigned to the authenticated user. It runs after
+// the authentication, like RequireRole.
+func RequireDBPermission(cfg *config.Config, policyService services.PolicyService, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "User not authenticated",
+				})
+			}
+
+			allowed, err := policyService.HasPermission(c.Request().Context(), claims.Sub, permission)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Insufficient permissions",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
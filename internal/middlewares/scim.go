@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMAuth checks the SCIM_TOKEN bearer token of the identity providers; it rejects every
+// request when the token is not configured. The rejections are SCIM errors.
+func SCIMAuth(cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || cfg.SCIMToken == "" ||
+				subtle.ConstantTimeCompare([]byte(token), []byte(cfg.SCIMToken)) != 1 {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="SCIM"`)
+				c.Response().Header().Set(echo.HeaderContentType, constants.SCIMContentType)
+				return c.JSON(http.StatusUnauthorized, dtos.SCIMError{
+					Schemas: []string{constants.SCIMSchemaError},
+					Status:  strconv.Itoa(http.StatusUnauthorized),
+					Detail:  "Invalid SCIM token",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/monitoring"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ServiceAuth authenticates the calls of the internal services, whose machine tokens are
+// issued by the client credentials grant of their Keycloak client. The token is verified
+// like by AuthMiddleware, then rejected unless it is the token of a service account whose
+// client is mapped to a service by SERVICE_CLIENTS; the tokens of users are rejected. The
+// service is stored in the context under constants.ContextKeyService.
+func ServiceAuth(cfg *config.Config, authService auth.AuthService) echo.MiddlewareFunc {
+	authenticate := AuthMiddleware(cfg, authService)
+	clients := cfg.ServiceIdentities()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return authenticate(func(c echo.Context) error {
+			claims, ok := c.Get(cfg.KeycloakKeyClaim).(*auth.TokenClaims)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Service not authenticated",
+				})
+			}
+
+			clientID := claims.ServiceClientID()
+			if clientID == "" {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Service token required",
+				})
+			}
+			name, ok := clients[clientID]
+			if !ok {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Unknown service client",
+				})
+			}
+
+			c.Set(constants.ContextKeyService, &auth.ServiceIdentity{
+				Name:     name,
+				ClientID: clientID,
+				Scopes:   claims.ServiceScopes(),
+			})
+			monitoring.SetPrincipal(c.Request().Context(), monitoring.Principal{
+				UserID: "service:" + name,
+			})
+
+			return next(c)
+		})
+	}
+}
+
+// RequireServiceScope creates middleware that requires all the scopes from the service
+// authenticated by ServiceAuth, the client scopes of its client in Keycloak
+func RequireServiceScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			service, ok := c.Get(constants.ContextKeyService).(*auth.ServiceIdentity)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Service not authenticated",
+				})
+			}
+
+			if !service.HasScopes(scopes...) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Insufficient scope",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/cdn"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SurrogateKeys tags the successful GET and HEAD responses of the API with the surrogate
+// keys of their route, e.g. companies:42 users for /api/v1/companies/42/users, in the
+// Surrogate-Key header of Fastly and the Cache-Tag header of Cloudflare, when CDN_PROVIDER
+// is set. The cdn projection purges the keys of the entities changed by the domain
+// events. It runs after routing, so the route and its parameters are known.
+func SurrogateKeys(cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if cfg.CDNProvider == "" {
+			return next
+		}
+		return func(c echo.Context) error {
+			method := c.Request().Method
+			if method != http.MethodGet && method != http.MethodHead {
+				return next(c)
+			}
+			keys := cdn.RouteKeys(c.Path(), c.Param)
+			if len(keys) == 0 {
+				return next(c)
+			}
+
+			response := c.Response()
+			response.Before(func() {
+				if response.Status < http.StatusOK || response.Status >= http.StatusMultipleChoices {
+					return
+				}
+				response.Header().Set(constants.HeaderSurrogateKey, strings.Join(keys, " "))
+				response.Header().Set(constants.HeaderCacheTag, strings.Join(keys, ","))
+			})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// WebSocketToken lets browsers authenticate WebSocket requests, on which they cannot set
+// the Authorization header, with the `bearer, <token>` subprotocols. The token is moved
+// to the Authorization header for AuthMiddleware and removed from the protocols, so that
+// it is neither logged nor echoed back in the handshake.
+func WebSocketToken() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			protocols := websocket.Subprotocols(req)
+			if len(protocols) == 2 && strings.EqualFold(protocols[0], constants.RealtimeTokenProtocol) {
+				if req.Header.Get(echo.HeaderAuthorization) == "" {
+					req.Header.Set(echo.HeaderAuthorization, "Bearer "+protocols[1])
+				}
+				req.Header.Set("Sec-WebSocket-Protocol", constants.RealtimeTokenProtocol)
+			}
+			return next(c)
+		}
+	}
+}
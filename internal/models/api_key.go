@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// APIKey authenticates a machine client of a company. Only the SHA-256 hash of the key is
+// stored; Prefix keeps its first characters so that the owner can recognize it.
+// LastUsedAt is updated asynchronously from the buffered usage, see APIKeyUsage. A
+// Sandbox key belongs to the sandbox tenant of a company, see Company.SandboxOfID.
+type APIKey struct {
+	BaseModel
+	CompanyID       string     `gorm:"column:company_id;type:uuid;not null;index"`
+	Company         Company    `gorm:"foreignKey:CompanyID"`
+	Name            string     `gorm:"column:name;not null"`
+	Prefix          string     `gorm:"column:prefix;not null"`
+	KeyHash         string     `gorm:"column:key_hash;not null;uniqueIndex"`
+	LastUsedAt      *time.Time `gorm:"column:last_used_at;type:timestamptz"`
+	ExpiresAt       *time.Time `gorm:"column:expires_at;type:timestamptz"`
+	RevokedAt       *time.Time `gorm:"column:revoked_at;type:timestamptz"`
+	UnusedAlertedAt *time.Time `gorm:"column:unused_alerted_at;type:timestamptz"`
+	Sandbox         bool       `gorm:"column:sandbox;not null;default:false"`
+	LegalHold
+}
+
+// Manually set table name
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether the key can authenticate requests at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyUsage counts the calls made with an API key to an endpoint during a day
+type APIKeyUsage struct {
+	BaseModel
+	APIKeyID string    `gorm:"column:api_key_id;type:uuid;not null;uniqueIndex:idx_api_key_usages_key_day_endpoint"`
+	APIKey   APIKey    `gorm:"foreignKey:APIKeyID"`
+	Day      time.Time `gorm:"column:day;type:date;not null;uniqueIndex:idx_api_key_usages_key_day_endpoint"`
+	Endpoint string    `gorm:"column:endpoint;not null;uniqueIndex:idx_api_key_usages_key_day_endpoint"`
+	Calls    int64     `gorm:"column:calls;not null;default:0"`
+}
+
+// Manually set table name
+func (APIKeyUsage) TableName() string {
+	return "api_key_usages"
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Plan is a plan the companies subscribe to, granting its entitlements. PriceID is the
+// price of the payment provider billed for it; a plan without one is free and subscribed
+// without a payment. Inactive plans keep their subscriptions but take no new ones.
+type Plan struct {
+	BaseModel
+	Key          string        `gorm:"column:key;not null;uniqueIndex"`
+	Name         string        `gorm:"column:name;not null"`
+	PriceID      *string       `gorm:"column:price_id;uniqueIndex"`
+	Active       bool          `gorm:"column:active;not null;default:true"`
+	Entitlements []Entitlement `gorm:"foreignKey:PlanID"`
+}
+
+// Manually set table name
+func (Plan) TableName() string {
+	return "plans"
+}
+
+// Entitlement grants a feature to the companies subscribed to its plan. Limit caps the
+// usage of the feature, such as the number of webhook endpoints; nil leaves it unlimited.
+type Entitlement struct {
+	BaseModel
+	PlanID string `gorm:"column:plan_id;type:uuid;not null;uniqueIndex:idx_entitlements_plan_id_key"`
+	Key    string `gorm:"column:key;not null;uniqueIndex:idx_entitlements_plan_id_key"`
+	Limit  *int64 `gorm:"column:usage_limit"`
+}
+
+// Manually set table name
+func (Entitlement) TableName() string {
+	return "entitlements"
+}
+
+// Subscription is the plan of a company. The Provider fields link a paid plan to the
+// subscription of the payment provider, synced from its events; LastEventAt is the time
+// of the last event applied, so that an older event delivered late is ignored.
+type Subscription struct {
+	BaseModel
+	CompanyID              string     `gorm:"column:company_id;type:uuid;not null;uniqueIndex"`
+	Company                Company    `gorm:"foreignKey:CompanyID"`
+	PlanID                 string     `gorm:"column:plan_id;type:uuid;not null;index"`
+	Plan                   Plan       `gorm:"foreignKey:PlanID"`
+	Status                 string     `gorm:"column:status;not null"`
+	Provider               string     `gorm:"column:provider;not null;default:''"`
+	ProviderCustomerID     string     `gorm:"column:provider_customer_id;not null;default:''"`
+	ProviderSubscriptionID *string    `gorm:"column:provider_subscription_id;uniqueIndex"`
+	CurrentPeriodEnd       *time.Time `gorm:"column:current_period_end;type:timestamptz"`
+	CancelAtPeriodEnd      bool       `gorm:"column:cancel_at_period_end;not null;default:false"`
+	LastEventAt            *time.Time `gorm:"column:last_event_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
@@ -0,0 +1,26 @@
+package models
+
+import "encoding/json"
+
+// CapturedEmail is an email stored for an inbox instead of being sent: the inbox of a
+// sandbox tenant, or the dev inbox when the capture is enabled, where CompanyID is only set
+// for the emails of the sandbox tenants. Attachments only keep their file names.
+type CapturedEmail struct {
+	BaseModel
+	CompanyID    *string         `gorm:"column:company_id;type:uuid;index"`
+	Company      Company         `gorm:"foreignKey:CompanyID"`
+	To           []string        `gorm:"column:to;type:jsonb;serializer:json;not null"`
+	Cc           []string        `gorm:"column:cc;type:jsonb;serializer:json"`
+	Bcc          []string        `gorm:"column:bcc;type:jsonb;serializer:json"`
+	Subject      string          `gorm:"column:subject;not null;default:''"`
+	TextBody     string          `gorm:"column:text_body;not null;default:''"`
+	HTMLBody     string          `gorm:"column:html_body;not null;default:''"`
+	TemplateID   string          `gorm:"column:template_id;not null;default:''"`
+	TemplateData json.RawMessage `gorm:"column:template_data;type:jsonb"`
+	Attachments  []string        `gorm:"column:attachments;type:jsonb;serializer:json"`
+}
+
+// Manually set table name
+func (CapturedEmail) TableName() string {
+	return "captured_emails"
+}
@@ -1,11 +1,22 @@
 package models
 
-// User represents a user domain entity
+// User represents a user domain entity. A company whose SandboxOfID is set is the
+// sandbox tenant of that company, where its sandbox API keys read and write. Slug,
+// StoragePrefix and DefaultRoles are set on the tenants created by the provisioning API.
+// UploadPolicy is nil for the companies that did not restrict their uploads.
 type Company struct {
 	BaseModel
-	Name       string `gorm:"column:name"`
-	KeycloakID string `gorm:"column:keycloak_id"`
-	Users      []User `gorm:"many2many:user_companies;"`
+	Name          string        `gorm:"column:name"`
+	KeycloakID    string        `gorm:"column:keycloak_id"`
+	LogoKey       string        `gorm:"column:logo_key"`
+	LogoSize      int64         `gorm:"column:logo_size;not null;default:0"`
+	SandboxOfID   *string       `gorm:"column:sandbox_of_id;type:uuid;uniqueIndex"`
+	Slug          *string       `gorm:"column:slug;uniqueIndex"`
+	StoragePrefix string        `gorm:"column:storage_prefix;not null;default:''"`
+	DefaultRoles  []string      `gorm:"column:default_roles;type:jsonb;serializer:json;not null;default:'[]'"`
+	UploadPolicy  *UploadPolicy `gorm:"column:upload_policy;type:jsonb;serializer:json"`
+	Users         []User        `gorm:"many2many:user_companies;"`
+	LegalHold
 }
 
 // Manually set table name
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CompanySummary is the read model of the dashboard of a company, projected from the
+// company and its members by the company_summaries projection. It is rebuilt from the
+// source tables at will, so it is never written by the services.
+type CompanySummary struct {
+	CompanyID   string `gorm:"column:company_id;type:uuid;primaryKey"`
+	Name        string `gorm:"column:name;not null"`
+	MemberCount int64  `gorm:"column:member_count;not null;default:0;index"`
+	// StorageBytes is the size of the logo of the company and of the avatars of its members
+	StorageBytes int64 `gorm:"column:storage_bytes;not null;default:0"`
+	// LastActivityAt is the time of the latest change of the company or of its members
+	LastActivityAt *time.Time `gorm:"column:last_activity_at;type:timestamptz;index"`
+	RefreshedAt    time.Time  `gorm:"column:refreshed_at;type:timestamptz;not null"`
+}
+
+// Manually set table name
+func (CompanySummary) TableName() string {
+	return "company_summaries"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// DeprecationUsage counts the calls of a consumer to a deprecated route or with a
+// deprecated field. The consumer is the API key, the OAuth client or the user of the
+// calls, and the tenant the company of the API key or the Keycloak organization of the
+// token.
+type DeprecationUsage struct {
+	BaseModel
+	DeprecationID string    `gorm:"column:deprecation_id;not null;uniqueIndex:idx_deprecation_usages_key"`
+	Consumer      string    `gorm:"column:consumer;not null;uniqueIndex:idx_deprecation_usages_key"`
+	Tenant        string    `gorm:"column:tenant;not null;default:''"`
+	Calls         int64     `gorm:"column:calls;not null;default:0"`
+	FirstSeenAt   time.Time `gorm:"column:first_seen_at;type:timestamptz;not null"`
+	LastSeenAt    time.Time `gorm:"column:last_seen_at;type:timestamptz;not null"`
+}
+
+// Manually set table name
+func (DeprecationUsage) TableName() string {
+	return "deprecation_usages"
+}
@@ -0,0 +1,18 @@
+package models
+
+// EmailSuppression is an email address the emails are no longer sent to, after a hard
+// bounce or a complaint reported by the email provider. Email is stored in lower case.
+type EmailSuppression struct {
+	BaseModel
+	Email  string `gorm:"column:email;not null;uniqueIndex"`
+	Reason string `gorm:"column:reason;not null"`
+	// Detail is the diagnostic of the bounce or the feedback type of the complaint
+	Detail string `gorm:"column:detail;not null;default:''"`
+	// MessageID is the provider ID of the email that bounced or was complained about
+	MessageID string `gorm:"column:message_id;not null;default:''"`
+}
+
+// Manually set table name
+func (EmailSuppression) TableName() string {
+	return "email_suppressions"
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ExportAudit records an export of a listing, or its denial by the export policies, for
+// the compliance team
+type ExportAudit struct {
+	BaseModel
+	Entity string `gorm:"column:entity;not null;index"`
+	Format string `gorm:"column:format;not null"`
+	// PrincipalType is a user, identified by the subject of their token, or an API key
+	PrincipalType string   `gorm:"column:principal_type;not null"`
+	PrincipalID   string   `gorm:"column:principal_id;not null;index"`
+	Roles         []string `gorm:"column:roles;type:jsonb;serializer:json;not null"`
+	// CompanyID is the company the exported listing is scoped to, if any
+	CompanyID *string           `gorm:"column:company_id;type:uuid;index"`
+	Fields    []string          `gorm:"column:fields;type:jsonb;serializer:json;not null"`
+	Filters   map[string]string `gorm:"column:filters;type:jsonb;serializer:json;not null"`
+	Status    string            `gorm:"column:status;not null"`
+	Rows      int64             `gorm:"column:rows;not null;default:0"`
+	Error     *string           `gorm:"column:error"`
+	// CompletedAt is when the export completed, failed or was denied
+	CompletedAt *time.Time `gorm:"column:completed_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (ExportAudit) TableName() string {
+	return "export_audits"
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// File is an object stored through the storage adapter, tracked through its upload
+// lifecycle so that it is only linked to once available. StatusReason explains a failed
+// or quarantined file.
+type File struct {
+	BaseModel
+	// OwnerID is the user or company the file belongs to, none for the files registered
+	// before it was recorded
+	OwnerID     *string `gorm:"column:owner_id;type:uuid;index"`
+	Key         string  `gorm:"column:key;not null;uniqueIndex"`
+	Bucket      string  `gorm:"column:bucket;not null;default:''"`
+	ContentType string  `gorm:"column:content_type;not null"`
+	Size        int64   `gorm:"column:size;not null;default:0"`
+	// Checksum is the hex SHA-256 of the content of the file
+	Checksum        string    `gorm:"column:checksum;not null;default:''"`
+	Status          string    `gorm:"column:status;not null;default:pending"`
+	StatusReason    *string   `gorm:"column:status_reason"`
+	StatusChangedAt time.Time `gorm:"column:status_changed_at;type:timestamptz;not null;default:now()"`
+}
+
+// Manually set table name
+func (File) TableName() string {
+	return "files"
+}
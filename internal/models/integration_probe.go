@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IntegrationProbe is the result of a scheduled probe of a dependency of the server, such
+// as the auth, storage, email or payment provider
+type IntegrationProbe struct {
+	BaseModel
+	Integration string    `gorm:"column:integration;not null;index:idx_integration_probes_integration_checked_at"`
+	Status      string    `gorm:"column:status;not null"`
+	Critical    bool      `gorm:"column:critical;not null;default:false"`
+	LatencyMs   int64     `gorm:"column:latency_ms;not null;default:0"`
+	Error       string    `gorm:"column:error;not null;default:''"`
+	CheckedAt   time.Time `gorm:"column:checked_at;type:timestamptz;not null;index:idx_integration_probes_integration_checked_at;index"`
+}
+
+// Manually set table name
+func (IntegrationProbe) TableName() string {
+	return "integration_probes"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Invitation invites a person, by email, to join a company. The token emailed to them is
+// signed and carries the ID of the invitation, which keeps its state: an invitation is
+// accepted or revoked once, and its status is derived from its dates.
+type Invitation struct {
+	BaseModel
+	CompanyID string  `gorm:"column:company_id;type:uuid;not null;index"`
+	Company   Company `gorm:"foreignKey:CompanyID"`
+	Email     string  `gorm:"column:email;not null;index"`
+	// InvitedBy is the subject of the token of the user who sent the invitation
+	InvitedBy string    `gorm:"column:invited_by;not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:timestamptz;not null"`
+	// UserID is the user who accepted the invitation
+	UserID     *string    `gorm:"column:user_id;type:uuid"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at;type:timestamptz"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (Invitation) TableName() string {
+	return "invitations"
+}
+
+// Pending reports whether the invitation can still be accepted at now
+func (i *Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Invoice is an invoice of a company issued by the payment provider, synced from its
+// events. Amounts are in the smallest unit of the currency. PDFKey is the storage key of
+// its PDF document, empty until the document is rendered and again once the invoice
+// changed; LastEventAt is the time of the last event applied, so that an older event
+// delivered late is ignored.
+type Invoice struct {
+	BaseModel
+	CompanyID         string        `gorm:"column:company_id;type:uuid;not null;index"`
+	Provider          string        `gorm:"column:provider;not null"`
+	ProviderInvoiceID string        `gorm:"column:provider_invoice_id;not null;uniqueIndex"`
+	Number            string        `gorm:"column:number;not null;default:''"`
+	Status            string        `gorm:"column:status;not null"`
+	Currency          string        `gorm:"column:currency;not null"`
+	Lines             []InvoiceLine `gorm:"column:lines;type:jsonb;serializer:json;not null"`
+	Subtotal          int64         `gorm:"column:subtotal;not null;default:0"`
+	Tax               int64         `gorm:"column:tax;not null;default:0"`
+	Total             int64         `gorm:"column:total;not null;default:0"`
+	AmountPaid        int64         `gorm:"column:amount_paid;not null;default:0"`
+	AmountDue         int64         `gorm:"column:amount_due;not null;default:0"`
+	PeriodStart       *time.Time    `gorm:"column:period_start;type:timestamptz"`
+	PeriodEnd         *time.Time    `gorm:"column:period_end;type:timestamptz"`
+	IssuedAt          time.Time     `gorm:"column:issued_at;type:timestamptz;not null"`
+	DueAt             *time.Time    `gorm:"column:due_at;type:timestamptz"`
+	PDFKey            string        `gorm:"column:pdf_key;not null;default:''"`
+	LastEventAt       time.Time     `gorm:"column:last_event_at;type:timestamptz;not null"`
+}
+
+// InvoiceLine is a line of an invoice
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Amount      int64  `json:"amount"`
+}
+
+// Manually set table name
+func (Invoice) TableName() string {
+	return "invoices"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a task of the queue, run by the workers. Payload holds the typed arguments of
+// its kind encoded as JSON. A failed job is retried at a later RunAt until MaxAttempts,
+// then kept with the dead status as the dead-letter queue.
+type Job struct {
+	BaseModel
+	Kind        string          `gorm:"column:kind;not null"`
+	Payload     json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
+	Status      string          `gorm:"column:status;not null;default:pending;index:idx_jobs_status_run_at,priority:1"`
+	Attempt     int             `gorm:"column:attempt;not null;default:0"`
+	MaxAttempts int             `gorm:"column:max_attempts;not null"`
+	RunAt       time.Time       `gorm:"column:run_at;type:timestamptz;not null;default:now();index:idx_jobs_status_run_at,priority:2"`
+	LockedAt    *time.Time      `gorm:"column:locked_at;type:timestamptz"`
+	LockedBy    *string         `gorm:"column:locked_by"`
+	LastError   *string         `gorm:"column:last_error"`
+	FinishedAt  *time.Time      `gorm:"column:finished_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (Job) TableName() string {
+	return "jobs"
+}
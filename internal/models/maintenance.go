@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaintenanceTask is a run of a maintenance operation, such as rebuilding the read models,
+// processed in batches by the workers of the task queue. Cursor is the position after the
+// last processed batch, from which a paused or failed task resumes. Generation is
+// incremented on every resume, so that the jobs of a previous run stop.
+type MaintenanceTask struct {
+	BaseModel
+	Operation  string          `gorm:"column:operation;not null;index:idx_maintenance_tasks_operation_status,priority:1"`
+	Params     json.RawMessage `gorm:"column:params;type:jsonb"`
+	Status     string          `gorm:"column:status;not null;index:idx_maintenance_tasks_operation_status,priority:2"`
+	BatchSize  int             `gorm:"column:batch_size;not null"`
+	ThrottleMs int64           `gorm:"column:throttle_ms;not null;default:0"`
+	Cursor     string          `gorm:"column:cursor;not null;default:''"`
+	Processed  int64           `gorm:"column:processed;not null;default:0"`
+	Total      int64           `gorm:"column:total;not null;default:0"`
+	Generation int             `gorm:"column:generation;not null;default:1"`
+	LastError  *string         `gorm:"column:last_error"`
+	CreatedBy  string          `gorm:"column:created_by;not null"`
+	StartedAt  time.Time       `gorm:"column:started_at;type:timestamptz;not null"`
+	FinishedAt *time.Time      `gorm:"column:finished_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (MaintenanceTask) TableName() string {
+	return "maintenance_tasks"
+}
@@ -0,0 +1,18 @@
+package models
+
+// OnboardingOverride is a manual state of an onboarding step of a company, e.g. a step
+// marked as done by the tenant or skipped. It takes precedence over the state computed
+// from the data of the company.
+type OnboardingOverride struct {
+	BaseModel
+	CompanyID string  `gorm:"column:company_id;type:uuid;not null;uniqueIndex:idx_onboarding_overrides_company_step"`
+	Company   Company `gorm:"foreignKey:CompanyID"`
+	Step      string  `gorm:"column:step;not null;uniqueIndex:idx_onboarding_overrides_company_step"`
+	Completed bool    `gorm:"column:completed;not null"`
+	UpdatedBy string  `gorm:"column:updated_by;not null"`
+}
+
+// Manually set table name
+func (OnboardingOverride) TableName() string {
+	return "onboarding_overrides"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "encoding/json"
+
+// PaymentEvent is an event received from a payment provider, recorded once it is verified
+// so that the deliveries of the same event are only dispatched once
+type PaymentEvent struct {
+	BaseModel
+	Provider string          `gorm:"column:provider;not null;uniqueIndex:idx_payment_events_provider_event_id"`
+	EventID  string          `gorm:"column:event_id;not null;uniqueIndex:idx_payment_events_provider_event_id"`
+	Type     string          `gorm:"column:type;not null"`
+	Payload  json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
+}
+
+// Manually set table name
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PaymentOperation records a refund, capture or void of a payment and who initiated it.
+// Amount is the amount requested, in the smallest unit of the currency, nil for the whole
+// payment; ProcessedAmount is the amount the provider refunded or captured.
+type PaymentOperation struct {
+	BaseModel
+	Operation string `gorm:"column:operation;not null"`
+	Provider  string `gorm:"column:provider;not null"`
+	// PaymentID is the payment intent of the provider
+	PaymentID       string `gorm:"column:payment_id;not null;index"`
+	Amount          *int64 `gorm:"column:amount"`
+	ProcessedAmount int64  `gorm:"column:processed_amount;not null;default:0"`
+	Currency        string `gorm:"column:currency;not null;default:''"`
+	Reason          string `gorm:"column:reason;not null;default:''"`
+	Note            string `gorm:"column:note;not null;default:''"`
+	Status          string `gorm:"column:status;not null"`
+	// ProviderObjectID is the refund of a refund, or the payment intent otherwise
+	ProviderObjectID string  `gorm:"column:provider_object_id;not null;default:''"`
+	ProviderStatus   string  `gorm:"column:provider_status;not null;default:''"`
+	Error            *string `gorm:"column:error"`
+	// InitiatedBy is the subject of the token of the user who initiated the operation
+	InitiatedBy string     `gorm:"column:initiated_by;not null;index"`
+	CompletedAt *time.Time `gorm:"column:completed_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (PaymentOperation) TableName() string {
+	return "payment_operations"
+}
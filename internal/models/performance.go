@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TenantRequestMetric counts the requests of a tenant to a route during an hour that
+// ended with a status class and a response time within a bucket, see
+// constants.PerformanceBucketsMs. DurationMs sums their response times. The tenant is
+// the Keycloak organization of the token, or the company of the API key.
+type TenantRequestMetric struct {
+	BaseModel
+	Tenant      string    `gorm:"column:tenant;not null;uniqueIndex:idx_tenant_request_metrics_key"`
+	Hour        time.Time `gorm:"column:hour;type:timestamptz;not null;uniqueIndex:idx_tenant_request_metrics_key;index"`
+	Route       string    `gorm:"column:route;not null;uniqueIndex:idx_tenant_request_metrics_key"`
+	StatusClass string    `gorm:"column:status_class;not null;uniqueIndex:idx_tenant_request_metrics_key"`
+	BucketMs    int64     `gorm:"column:bucket_ms;not null;uniqueIndex:idx_tenant_request_metrics_key"`
+	Requests    int64     `gorm:"column:requests;not null;default:0"`
+	DurationMs  float64   `gorm:"column:duration_ms;not null;default:0"`
+}
+
+// Manually set table name
+func (TenantRequestMetric) TableName() string {
+	return "tenant_request_metrics"
+}
@@ -0,0 +1,36 @@
+package models
+
+// Permission is a permission of the database RBAC, named <resource>:<action>, e.g.
+// users:write. The permissions are created with the first role granting them.
+type Permission struct {
+	BaseModel
+	Name        string `gorm:"column:name;not null;uniqueIndex"`
+	Description string `gorm:"column:description;not null;default:''"`
+}
+
+// Manually set table name
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role groups the permissions granted to the users assigned to it, see User.Roles
+type Role struct {
+	BaseModel
+	Name        string       `gorm:"column:name;not null;uniqueIndex"`
+	Description string       `gorm:"column:description;not null;default:''"`
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+}
+
+// Manually set table name
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionNames returns the names of the permissions of the role
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
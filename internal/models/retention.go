@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// RetentionPolicy is the number of days a company keeps the data of a resource before
+// the retention job purges it. Resources without a policy are kept forever.
+type RetentionPolicy struct {
+	BaseModel
+	CompanyID string  `gorm:"column:company_id;type:uuid;not null;uniqueIndex:idx_retention_policies_company_resource"`
+	Company   Company `gorm:"foreignKey:CompanyID"`
+	Resource  string  `gorm:"column:resource;not null;uniqueIndex:idx_retention_policies_company_resource"`
+	Days      int     `gorm:"column:days;not null"`
+	UpdatedBy string  `gorm:"column:updated_by;not null"`
+}
+
+// Manually set table name
+func (RetentionPolicy) TableName() string {
+	return "retention_policies"
+}
+
+// LegalHold exempts an entity, and the data depending on it, from the retention purges
+// while LegalHoldAt is set
+type LegalHold struct {
+	LegalHoldAt     *time.Time `gorm:"column:legal_hold_at;type:timestamptz"`
+	LegalHoldBy     string     `gorm:"column:legal_hold_by"`
+	LegalHoldReason string     `gorm:"column:legal_hold_reason"`
+}
+
+// OnLegalHold reports whether the entity is exempt from the retention purges
+func (h LegalHold) OnLegalHold() bool {
+	return h.LegalHoldAt != nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TenantCredential is a provider credential of a company, such as its SMTP or S3 keys.
+// The secret fields are sealed by the credentials vault: Ciphertext is encrypted with a
+// data key that is only stored wrapped by the KMS, in EncryptedKey. MaskedSecret keeps a
+// masked copy of the secret fields so that reads never need to decrypt.
+type TenantCredential struct {
+	BaseModel
+	CompanyID    string            `gorm:"column:company_id;type:uuid;not null;uniqueIndex:idx_tenant_credentials_company_provider_name"`
+	Company      Company           `gorm:"foreignKey:CompanyID"`
+	Provider     string            `gorm:"column:provider;not null;uniqueIndex:idx_tenant_credentials_company_provider_name"`
+	Name         string            `gorm:"column:name;not null;uniqueIndex:idx_tenant_credentials_company_provider_name"`
+	KeyID        string            `gorm:"column:key_id;not null"`
+	EncryptedKey []byte            `gorm:"column:encrypted_key;type:bytea;not null"`
+	Nonce        []byte            `gorm:"column:nonce;type:bytea;not null"`
+	Ciphertext   []byte            `gorm:"column:ciphertext;type:bytea;not null"`
+	MaskedSecret map[string]string `gorm:"column:masked_secret;type:jsonb;serializer:json;not null"`
+	Version      int               `gorm:"column:version;not null;default:1"`
+	RotatedAt    *time.Time        `gorm:"column:rotated_at;type:timestamptz"`
+}
+
+// Manually set table name
+func (TenantCredential) TableName() string {
+	return "tenant_credentials"
+}
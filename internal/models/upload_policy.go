@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UploadPolicy restricts the files a company accepts on top of the limits of each upload.
+// Empty lists and a zero MaxSizeBytes leave the built-in limits unchanged.
+type UploadPolicy struct {
+	// AllowedContentTypes lists the accepted content types, detected from the file content
+	AllowedContentTypes []string `json:"allowed_content_types,omitempty"`
+	MaxSizeBytes        int64    `json:"max_size_bytes,omitempty"`
+	// BannedExtensions lists the rejected file name extensions, lower case with the dot
+	BannedExtensions []string  `json:"banned_extensions,omitempty"`
+	UpdatedBy        string    `json:"updated_by"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
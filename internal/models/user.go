@@ -1,14 +1,36 @@
 package models
 
-// User represents a user domain entity
+import "time"
+
+// User represents a user domain entity. ExternalID is the ID of the user in the identity
+// provider provisioning it through SCIM, and DisabledAt is set while that provider
+// deactivated it, or while its Keycloak user is disabled or deleted.
 type User struct {
 	BaseModel
-	FirstName        string    `gorm:"column:first_name"`
-	LastName         string    `gorm:"column:last_name"`
-	Email            string    `gorm:"column:email"`
-	KeycloakID       string    `gorm:"column:keycloak_id"`
-	StripeCustomerID string    `gorm:"column:stripe_customer_id"`
-	Companies        []Company `gorm:"many2many:user_companies;"`
+	FirstName        string     `gorm:"column:first_name"`
+	LastName         string     `gorm:"column:last_name"`
+	Email            string     `gorm:"column:email"`
+	KeycloakID       string     `gorm:"column:keycloak_id"`
+	StripeCustomerID string     `gorm:"column:stripe_customer_id"`
+	AvatarKey        string     `gorm:"column:avatar_key"`
+	AvatarSize       int64      `gorm:"column:avatar_size;not null;default:0"`
+	ExternalID       string     `gorm:"column:external_id;index"`
+	DisabledAt       *time.Time `gorm:"column:disabled_at;type:timestamptz"`
+	// SearchVector is generated by Postgres from the name and email columns and backs the
+	// full-text search; gorm never reads nor writes it
+	SearchVector string    `gorm:"column:search_vector;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(first_name, '')), 'A') || setweight(to_tsvector('simple', coalesce(last_name, '')), 'A') || setweight(to_tsvector('simple', translate(coalesce(email, ''), '@.-_+', '     ')), 'B')) STORED;index:idx_users_search_vector,type:gin;->:false;<-:false"`
+	Companies    []Company `gorm:"many2many:user_companies;"`
+	// Roles are the roles of the database RBAC assigned to the user
+	Roles []Role `gorm:"many2many:user_roles;"`
+}
+
+// UserSearchResult is a user matched by a full-text search, with its relevance rank and
+// the matched text with highlighted words. Its fields carry no gorm tags so that the
+// Atlas schema loader does not mistake this query projection for a table.
+type UserSearchResult struct {
+	User
+	Rank      float64
+	Highlight string
 }
 
 // Manually set table name
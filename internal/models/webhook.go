@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEndpoint is an HTTP endpoint of a company receiving its domain events. It
+// subscribes to EventTypes, narrowed down by the JSONPath Conditions of the webhooks
+// package, and its deliveries are signed with a secret sealed by the credentials vault,
+// like the tenant credentials. RateLimit caps its deliveries per minute, 0 is unlimited.
+type WebhookEndpoint struct {
+	BaseModel
+	CompanyID    string          `gorm:"column:company_id;type:uuid;not null;index"`
+	Company      Company         `gorm:"foreignKey:CompanyID"`
+	URL          string          `gorm:"column:url;not null"`
+	Description  string          `gorm:"column:description;not null;default:''"`
+	EventTypes   []string        `gorm:"column:event_types;type:jsonb;serializer:json;not null"`
+	Conditions   json.RawMessage `gorm:"column:conditions;type:jsonb"`
+	Active       bool            `gorm:"column:active;not null;default:true"`
+	RateLimit    int             `gorm:"column:rate_limit;not null;default:0"`
+	KeyID        string          `gorm:"column:key_id;not null"`
+	EncryptedKey []byte          `gorm:"column:encrypted_key;type:bytea;not null"`
+	Nonce        []byte          `gorm:"column:nonce;type:bytea;not null"`
+	Ciphertext   []byte          `gorm:"column:ciphertext;type:bytea;not null"`
+	CreatedBy    string          `gorm:"column:created_by;not null"`
+}
+
+// Manually set table name
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery is the log of the delivery of an event to an endpoint. Payload is the
+// signed request body, kept to redeliver it; the Response fields and LastError describe
+// the last attempt. A redelivery is a new delivery of the same EventID.
+type WebhookDelivery struct {
+	BaseModel
+	CompanyID      string          `gorm:"column:company_id;type:uuid;not null;index"`
+	EndpointID     string          `gorm:"column:endpoint_id;type:uuid;not null;index"`
+	Endpoint       WebhookEndpoint `gorm:"foreignKey:EndpointID"`
+	EventID        string          `gorm:"column:event_id;type:uuid;not null;index"`
+	EventType      string          `gorm:"column:event_type;not null"`
+	Payload        json.RawMessage `gorm:"column:payload;type:jsonb;not null"`
+	Status         string          `gorm:"column:status;not null;default:pending"`
+	Attempts       int             `gorm:"column:attempts;not null;default:0"`
+	ResponseStatus *int            `gorm:"column:response_status"`
+	ResponseBody   *string         `gorm:"column:response_body"`
+	LastError      *string         `gorm:"column:last_error"`
+	DurationMs     *int64          `gorm:"column:duration_ms"`
+	NextAttemptAt  *time.Time      `gorm:"column:next_attempt_at;type:timestamptz"`
+	DeliveredAt    *time.Time      `gorm:"column:delivered_at;type:timestamptz"`
+	RedeliveryOf   *string         `gorm:"column:redelivery_of;type:uuid"`
+}
+
+// Manually set table name
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
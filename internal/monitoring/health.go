@@ -0,0 +1,166 @@
+package monitoring
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Health statuses of a dependency and of the rollup
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// HealthChecker checks a dependency of the server. A failing critical dependency makes the
+// server unhealthy, and not ready; any other failing dependency makes it degraded.
+type HealthChecker struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// DependencyHealth is the result of the check of a dependency
+type DependencyHealth struct {
+	Name      string `json:"name" example:"database"`
+	Status    string `json:"status" example:"healthy"`
+	Critical  bool   `json:"critical" example:"true"`
+	LatencyMs int64  `json:"latency_ms" example:"12"`
+	Error     string `json:"error,omitempty" example:"Failed to ping Redis"`
+}
+
+// HealthReport is the health of every registered dependency and their rollup
+type HealthReport struct {
+	Status       string             `json:"status" example:"healthy"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at" example:"2021-01-01T00:00:00Z"`
+}
+
+// HealthRegistry checks the dependencies registered by the integrations
+type HealthRegistry struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers []HealthChecker
+}
+
+// HealthRegistryParams are the checkers provided to the health_checkers group
+type HealthRegistryParams struct {
+	fx.In
+	Config   *config.Config
+	Checkers []HealthChecker `group:"health_checkers"`
+}
+
+// NewHealthRegistry creates a registry running each check under timeout
+func NewHealthRegistry(timeout time.Duration, checkers ...HealthChecker) *HealthRegistry {
+	registry := &HealthRegistry{timeout: timeout}
+	for _, checker := range checkers {
+		registry.Register(checker)
+	}
+	return registry
+}
+
+// ProvideHealthRegistry creates the registry of the checkers of the health_checkers group.
+// HEALTH_CRITICAL_DEPENDENCIES, when set, replaces the criticality the checkers declare.
+func ProvideHealthRegistry(p HealthRegistryParams) *HealthRegistry {
+	if critical := p.Config.HealthCriticalDependencies; len(critical) > 0 {
+		for i := range p.Checkers {
+			p.Checkers[i].Critical = slices.Contains(critical, p.Checkers[i].Name)
+		}
+	}
+	return NewHealthRegistry(constants.HealthCheckTimeout, p.Checkers...)
+}
+
+// Register adds a checker; a checker registered under the name of another replaces it
+func (r *HealthRegistry) Register(checker HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, registered := range r.checkers {
+		if registered.Name == checker.Name {
+			r.checkers[i] = checker
+			return
+		}
+	}
+	r.checkers = append(r.checkers, checker)
+}
+
+// Check runs the checks concurrently and rolls their results up
+func (r *HealthRegistry) Check(ctx context.Context) HealthReport {
+	r.mu.RLock()
+	checkers := make([]HealthChecker, len(r.checkers))
+	copy(checkers, r.checkers)
+	r.mu.RUnlock()
+
+	dependencies := make([]DependencyHealth, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dependencies[i] = r.check(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Name < dependencies[j].Name
+	})
+	return HealthReport{
+		Status:       Rollup(dependencies),
+		Dependencies: dependencies,
+		CheckedAt:    time.Now().UTC(),
+	}
+}
+
+// check runs a check under the timeout of the registry
+func (r *HealthRegistry) check(ctx context.Context, checker HealthChecker) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	err := checker.Check(ctx)
+	health := DependencyHealth{
+		Name:      checker.Name,
+		Status:    HealthStatusHealthy,
+		Critical:  checker.Critical,
+		LatencyMs: time.Since(startedAt).Milliseconds(),
+	}
+	if err != nil {
+		health.Status = HealthStatusUnhealthy
+		health.Error = errors.GetErrorMessage(err)
+		logger.Log.Warn("Dependency health check failed",
+			zap.String("dependency", checker.Name),
+			zap.Bool("critical", checker.Critical),
+			zap.Int64("latency_ms", health.LatencyMs),
+			zap.Error(err),
+		)
+	}
+	return health
+}
+
+// Rollup is unhealthy when a critical dependency is, degraded when another one is
+func Rollup(dependencies []DependencyHealth) string {
+	status := HealthStatusHealthy
+	for _, dependency := range dependencies {
+		if dependency.Status == HealthStatusHealthy {
+			continue
+		}
+		if dependency.Critical {
+			return HealthStatusUnhealthy
+		}
+		status = HealthStatusDegraded
+	}
+	return status
+}
@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func healthy(context.Context) error { return nil }
+
+func failing(context.Context) error { return stderrors.New("connection refused") }
+
+func TestHealthRegistry_Check(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	tests := []struct {
+		name             string
+		checkers         []HealthChecker
+		expectedStatus   string
+		expectedStatuses map[string]string
+	}{
+		{
+			name: "all dependencies healthy",
+			checkers: []HealthChecker{
+				{Name: "database", Critical: true, Check: healthy},
+				{Name: "email", Check: healthy},
+			},
+			expectedStatus:   HealthStatusHealthy,
+			expectedStatuses: map[string]string{"database": HealthStatusHealthy, "email": HealthStatusHealthy},
+		},
+		{
+			name: "non critical dependency failing",
+			checkers: []HealthChecker{
+				{Name: "database", Critical: true, Check: healthy},
+				{Name: "email", Check: failing},
+			},
+			expectedStatus:   HealthStatusDegraded,
+			expectedStatuses: map[string]string{"database": HealthStatusHealthy, "email": HealthStatusUnhealthy},
+		},
+		{
+			name: "critical dependency failing",
+			checkers: []HealthChecker{
+				{Name: "database", Critical: true, Check: failing},
+				{Name: "email", Check: failing},
+			},
+			expectedStatus:   HealthStatusUnhealthy,
+			expectedStatuses: map[string]string{"database": HealthStatusUnhealthy, "email": HealthStatusUnhealthy},
+		},
+		{
+			name:             "no dependencies",
+			expectedStatus:   HealthStatusHealthy,
+			expectedStatuses: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewHealthRegistry(time.Second, tt.checkers...)
+
+			report := registry.Check(context.Background())
+
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			statuses := make(map[string]string, len(report.Dependencies))
+			for _, dependency := range report.Dependencies {
+				statuses[dependency.Name] = dependency.Status
+				if dependency.Status == HealthStatusUnhealthy {
+					assert.Equal(t, "connection refused", dependency.Error)
+				}
+			}
+			assert.Equal(t, tt.expectedStatuses, statuses)
+		})
+	}
+}
+
+func TestHealthRegistry_CheckTimeout(t *testing.T) {
+	logger.Log = zap.NewNop()
+	registry := NewHealthRegistry(20*time.Millisecond, HealthChecker{
+		Name:     "cache",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	report := registry.Check(context.Background())
+
+	require.Len(t, report.Dependencies, 1)
+	assert.Equal(t, HealthStatusUnhealthy, report.Status)
+	assert.GreaterOrEqual(t, report.Dependencies[0].LatencyMs, int64(20))
+}
+
+func TestHealthRegistry_RegisterReplaces(t *testing.T) {
+	registry := NewHealthRegistry(time.Second,
+		HealthChecker{Name: "cache", Check: failing},
+		HealthChecker{Name: "storage", Check: healthy},
+	)
+	registry.Register(HealthChecker{Name: "cache", Check: healthy})
+
+	report := registry.Check(context.Background())
+
+	require.Len(t, report.Dependencies, 2)
+	assert.Equal(t, []string{"cache", "storage"}, []string{report.Dependencies[0].Name, report.Dependencies[1].Name})
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+}
+
+func TestProvideHealthRegistry_CriticalDependencies(t *testing.T) {
+	logger.Log = zap.NewNop()
+	checkers := func() []HealthChecker {
+		return []HealthChecker{
+			{Name: "database", Critical: true, Check: healthy},
+			{Name: "payment", Check: failing},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		critical       []string
+		expectedStatus string
+	}{
+		{name: "criticality of the checkers", expectedStatus: HealthStatusDegraded},
+		{name: "configured criticality", critical: []string{"database", "payment"}, expectedStatus: HealthStatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := ProvideHealthRegistry(HealthRegistryParams{
+				Config:   &config.Config{HealthCriticalDependencies: tt.critical},
+				Checkers: checkers(),
+			})
+
+			report := registry.Check(context.Background())
+
+			assert.Equal(t, tt.expectedStatus, report.Status)
+		})
+	}
+}
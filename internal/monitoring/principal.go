@@ -0,0 +1,66 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Principal identifies the authenticated caller of a request for error tracking and APM.
+// Only a hash of the email is kept so that raw PII never leaves the service.
+type Principal struct {
+	UserID    string
+	EmailHash string
+	TenantID  string
+}
+
+// NewPrincipal builds a Principal, hashing the given email address.
+func NewPrincipal(userID, email, tenantID string) Principal {
+	return Principal{
+		UserID:    userID,
+		EmailHash: HashEmail(email),
+		TenantID:  tenantID,
+	}
+}
+
+// HashEmail returns the hex encoded SHA-256 of the normalized email, or "" for an empty email.
+func HashEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetPrincipal attaches the principal to the request-scoped Sentry hub and to the
+// New Relic transaction stored in ctx. Either integration is skipped when absent.
+func SetPrincipal(ctx context.Context, principal Principal) {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		hub.ConfigureScope(func(scope *sentry.Scope) {
+			user := sentry.User{ID: principal.UserID, Data: map[string]string{}}
+			if principal.EmailHash != "" {
+				user.Data["email_hash"] = principal.EmailHash
+			}
+			if principal.TenantID != "" {
+				user.Data["tenant_id"] = principal.TenantID
+				scope.SetTag("tenant_id", principal.TenantID)
+			}
+			scope.SetUser(user)
+		})
+	}
+
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		txn.AddAttribute("enduser.id", principal.UserID)
+		if principal.EmailHash != "" {
+			txn.AddAttribute("enduser.email_hash", principal.EmailHash)
+		}
+		if principal.TenantID != "" {
+			txn.AddAttribute("tenant.id", principal.TenantID)
+		}
+	}
+}
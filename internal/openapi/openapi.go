@@ -0,0 +1,145 @@
+// Package openapi generates the OpenAPI specification of the API from the Swagger
+// annotations of the handlers and the DTOs, like `make swagger-load`, and compares it with
+// the committed one, so that a change of the API that is not reflected in the committed
+// specification fails the build.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/swaggo/swag"
+)
+
+// SearchDirs are the directories of the annotations and the types of the specification,
+// relative to the module root, the first one holding MainFile
+var SearchDirs = []string{
+	"cmd/server",
+	"internal/handlers",
+	"internal/middlewares",
+	"internal/services",
+	"internal/repositories",
+	"internal/models",
+	"internal/utils",
+	"internal/config",
+	"internal/constants",
+	"internal/dtos",
+	"internal/logger",
+	"internal/db",
+	"internal/monitoring",
+}
+
+// MainFile holds the general API annotations
+const MainFile = "main.go"
+
+// CommittedFile is the committed specification, relative to the module root
+const CommittedFile = "docs/swagger.json"
+
+// parseDepth is the depth of the dependencies followed by the parser, as swag init
+const parseDepth = 100
+
+// Generate generates the specification from the sources of the module at root
+func Generate(root string) ([]byte, error) {
+	dirs := make([]string, len(SearchDirs))
+	for i, dir := range SearchDirs {
+		dirs[i] = filepath.Join(root, dir)
+	}
+
+	parser := swag.New(
+		swag.ParseUsingGoList(true),
+		swag.SetDebugger(quietDebugger{}),
+	)
+	parser.PropNamingStrategy = swag.CamelCase
+	if err := parser.ParseAPIMultiSearchDir(dirs, MainFile, parseDepth); err != nil {
+		return nil, fmt.Errorf("parse the annotations: %w", err)
+	}
+
+	return json.MarshalIndent(parser.GetSwagger(), "", "    ")
+}
+
+// Diff compares the committed specification with the generated one and returns their
+// differences, one per line, sorted by path; none when they are equivalent
+func Diff(committed []byte, generated []byte) ([]string, error) {
+	var committedSpec, generatedSpec any
+	if err := json.Unmarshal(committed, &committedSpec); err != nil {
+		return nil, fmt.Errorf("parse the committed specification: %w", err)
+	}
+	if err := json.Unmarshal(generated, &generatedSpec); err != nil {
+		return nil, fmt.Errorf("parse the generated specification: %w", err)
+	}
+
+	differences := diff("", committedSpec, generatedSpec, nil)
+	sort.Strings(differences)
+	return differences, nil
+}
+
+// diff appends the differences between the committed and generated values at path
+func diff(path string, committed any, generated any, differences []string) []string {
+	committedObject, committedIsObject := committed.(map[string]any)
+	generatedObject, generatedIsObject := generated.(map[string]any)
+	if committedIsObject && generatedIsObject {
+		keys := make([]string, 0, len(committedObject)+len(generatedObject))
+		for key := range committedObject {
+			keys = append(keys, key)
+		}
+		for key := range generatedObject {
+			if _, ok := committedObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			keyPath := path + "/" + escape(key)
+			committedValue, inCommitted := committedObject[key]
+			generatedValue, inGenerated := generatedObject[key]
+			switch {
+			case !inCommitted:
+				differences = append(differences, keyPath+": missing from the committed specification")
+			case !inGenerated:
+				differences = append(differences, keyPath+": no longer generated")
+			default:
+				differences = diff(keyPath, committedValue, generatedValue, differences)
+			}
+		}
+		return differences
+	}
+
+	committedArray, committedIsArray := committed.([]any)
+	generatedArray, generatedIsArray := generated.([]any)
+	if committedIsArray && generatedIsArray && len(committedArray) == len(generatedArray) {
+		for i := range committedArray {
+			differences = diff(path+"/"+strconv.Itoa(i), committedArray[i], generatedArray[i], differences)
+		}
+		return differences
+	}
+
+	if !reflect.DeepEqual(committed, generated) {
+		differences = append(differences, fmt.Sprintf("%s: committed %s, generated %s", path, compact(committed), compact(generated)))
+	}
+	return differences
+}
+
+// escape escapes a key of a JSON pointer, e.g. the "/" of the paths
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+func compact(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// quietDebugger drops the progress messages of the parser
+type quietDebugger struct{}
+
+func (quietDebugger) Printf(string, ...any) {}
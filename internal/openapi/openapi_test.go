@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed string
+		generated string
+		expected  []string
+	}{
+		{
+			name:      "equivalent",
+			committed: `{"paths":{"/users":{"get":{"summary":"List users"}}},"swagger":"2.0"}`,
+			generated: `{"swagger":"2.0","paths":{"/users":{"get":{"summary":"List users"}}}}`,
+		},
+		{
+			name:      "changed value",
+			committed: `{"paths":{"/users":{"get":{"summary":"List users"}}}}`,
+			generated: `{"paths":{"/users":{"get":{"summary":"Get users"}}}}`,
+			expected:  []string{`/paths/~1users/get/summary: committed "List users", generated "Get users"`},
+		},
+		{
+			name:      "added and removed",
+			committed: `{"definitions":{"dtos.Old":{}}}`,
+			generated: `{"definitions":{"dtos.New":{}}}`,
+			expected: []string{
+				"/definitions/dtos.New: missing from the committed specification",
+				"/definitions/dtos.Old: no longer generated",
+			},
+		},
+		{
+			name:      "array element",
+			committed: `{"required":["name","email"]}`,
+			generated: `{"required":["name","phone"]}`,
+			expected:  []string{`/required/1: committed "email", generated "phone"`},
+		},
+		{
+			name:      "array length",
+			committed: `{"required":["name"]}`,
+			generated: `{"required":["name","email"]}`,
+			expected:  []string{`/required: committed ["name"], generated ["name","email"]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differences, err := Diff([]byte(tt.committed), []byte(tt.generated))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, differences)
+		})
+	}
+}
+
+func TestDiff_InvalidJSON(t *testing.T) {
+	_, err := Diff([]byte(`{`), []byte(`{}`))
+
+	assert.ErrorContains(t, err, "parse the committed specification")
+}
@@ -0,0 +1,61 @@
+package projections
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/cdn"
+)
+
+// cdnPurge keeps the responses cached by the CDN fresh: every event purges the surrogate
+// keys of the changed entity, the lists of its resource and its company, so that the
+// cached lists and sub-resources of the company are purged too.
+type cdnPurge struct {
+	purger cdn.Purger
+}
+
+// ProvideCDNPurge creates the cdn projection
+func ProvideCDNPurge(purger cdn.Purger) Projection {
+	return &cdnPurge{purger: purger}
+}
+
+func (p *cdnPurge) Name() string {
+	return constants.ProjectionCDN
+}
+
+func (p *cdnPurge) Apply(ctx context.Context, event Event) error {
+	keys := EventKeys(event)
+	if len(keys) == 0 {
+		return nil
+	}
+	return p.purger.Purge(ctx, keys)
+}
+
+// Rebuild purges the whole cache: there is nothing to count
+func (p *cdnPurge) Rebuild(ctx context.Context) (int64, error) {
+	return 0, p.purger.PurgeAll(ctx)
+}
+
+// EventKeys returns the surrogate keys invalidated by the event: those of its entity and
+// the lists of its resource, as mapped by constants.CDNEventResources, and those of its
+// company
+func EventKeys(event Event) []string {
+	var keys []string
+	entity, _, _ := strings.Cut(event.Type, ".")
+	if resource, ok := constants.CDNEventResources[entity]; ok {
+		keys = append(keys, resource)
+		if event.EntityID != "" {
+			keys = append(keys, cdn.EntityKey(resource, event.EntityID))
+		}
+	}
+
+	if event.CompanyID != "" {
+		companyKey := cdn.EntityKey(constants.CDNEventResources["company"], event.CompanyID)
+		if !slices.Contains(keys, companyKey) {
+			keys = append(keys, companyKey)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,49 @@
+package projections
+
+import (
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected []string
+	}{
+		{
+			name:     "company event",
+			event:    Event{Type: constants.WebhookEventCompanyUpdated, CompanyID: "42", EntityID: "42"},
+			expected: []string{"companies", "companies:42"},
+		},
+		{
+			name:     "user event of a company",
+			event:    Event{Type: constants.WebhookEventUserUpdated, CompanyID: "42", EntityID: "7"},
+			expected: []string{"users", "users:7", "companies:42"},
+		},
+		{
+			name:     "user without companies",
+			event:    Event{Type: constants.EventUserAvatarUpdated, EntityID: "7"},
+			expected: []string{"users", "users:7"},
+		},
+		{
+			name:     "member event",
+			event:    Event{Type: constants.WebhookEventMemberAdded, CompanyID: "42", EntityID: "7"},
+			expected: []string{"users", "users:7", "companies:42"},
+		},
+		{
+			name:     "unknown entity",
+			event:    Event{Type: "invoice.paid", CompanyID: "42"},
+			expected: []string{"companies:42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EventKeys(tt.event))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package projections
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/repositories"
+)
+
+// companySummaries projects the company_summaries read model: the member count, storage
+// usage and last activity of each company, read by the dashboards in a single row. Every
+// event projects the summary of its company again from the source tables.
+type companySummaries struct {
+	repo repositories.CompanySummaryRepository
+}
+
+// ProvideCompanySummaries creates the company_summaries projection
+func ProvideCompanySummaries(repo repositories.CompanySummaryRepository) Projection {
+	return &companySummaries{repo: repo}
+}
+
+func (p *companySummaries) Name() string {
+	return constants.ProjectionCompanySummaries
+}
+
+func (p *companySummaries) Apply(ctx context.Context, event Event) error {
+	if event.CompanyID == "" {
+		return nil
+	}
+	return p.repo.Refresh(event.CompanyID, event.OccurredAt)
+}
+
+func (p *companySummaries) Rebuild(ctx context.Context) (int64, error) {
+	return p.repo.Rebuild()
+}
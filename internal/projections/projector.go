@@ -0,0 +1,164 @@
+// Package projections maintains denormalized read models, such as the company summaries
+// of the dashboards, from the domain events of the services. Services publish an event
+// after their change is saved; the event is applied by the workers of the task queue, so
+// that requests never wait on the read models and failed applications are retried. A
+// read model is rebuilt from the source tables with the rebuild-projections run mode.
+package projections
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Event is a domain event changing the data of a company that the read models are
+// projected from
+type Event struct {
+	Type string
+	// CompanyID is empty for the events of an entity outside any company, e.g. a user
+	// without companies
+	CompanyID string
+	// EntityID is the changed entity, e.g. the user of a user event or the company of a
+	// company event
+	EntityID   string
+	OccurredAt time.Time
+}
+
+// Projection maintains a read model. Events are delivered at least once and in any
+// order, so Apply must be idempotent, e.g. by projecting the rows of the company again
+// from the source tables rather than adding the change.
+type Projection interface {
+	// Name names the projection in the rebuild-projections arguments
+	Name() string
+	Apply(ctx context.Context, event Event) error
+	// Rebuild replaces the read model with one projected from the source tables and
+	// returns its number of rows
+	Rebuild(ctx context.Context) (int64, error)
+}
+
+// Publisher publishes the domain events to the projections
+type Publisher interface {
+	Publish(ctx context.Context, companyID string, eventType string, entityID string) error
+}
+
+// Params are the dependencies of the projector
+type Params struct {
+	fx.In
+
+	Enqueuer    jobs.Enqueuer
+	Projections []Projection `group:"projections"`
+}
+
+// Projector enqueues the domain events and applies them to the projections of the
+// projections fx group
+type Projector struct {
+	enqueuer    jobs.Enqueuer
+	projections []Projection
+}
+
+// New creates a projector of the projections, failing on a duplicate name
+func New(enqueuer jobs.Enqueuer, projections ...Projection) (*Projector, error) {
+	names := make(map[string]bool, len(projections))
+	for _, projection := range projections {
+		if names[projection.Name()] {
+			return nil, fmt.Errorf("duplicate projection %s", projection.Name())
+		}
+		names[projection.Name()] = true
+	}
+
+	return &Projector{enqueuer: enqueuer, projections: projections}, nil
+}
+
+// ProvideProjector creates the projector of the projections group
+func ProvideProjector(p Params) (*Projector, error) {
+	return New(p.Enqueuer, p.Projections...)
+}
+
+// ProvidePublisher returns the projector as the publisher of the services
+func ProvidePublisher(projector *Projector) Publisher {
+	return projector
+}
+
+// ProvideEventProjector returns the projector as the applier of the project_event tasks
+func ProvideEventProjector(projector *Projector) jobs.EventProjector {
+	return projector
+}
+
+// Publish implements Publisher by enqueuing the event for the workers
+func (p *Projector) Publish(ctx context.Context, companyID string, eventType string, entityID string) error {
+	_, err := p.enqueuer.Enqueue(ctx, jobs.ProjectEventArgs{
+		EventType:  eventType,
+		CompanyID:  companyID,
+		EntityID:   entityID,
+		OccurredAt: time.Now().UTC(),
+	})
+	return err
+}
+
+// Apply implements jobs.EventProjector. An event is applied to every projection, even
+// when one fails, and the failures are returned together so that the task is retried.
+func (p *Projector) Apply(ctx context.Context, args jobs.ProjectEventArgs) error {
+	event := Event{
+		Type:       args.EventType,
+		CompanyID:  args.CompanyID,
+		EntityID:   args.EntityID,
+		OccurredAt: args.OccurredAt,
+	}
+
+	var errs []error
+	for _, projection := range p.projections {
+		if err := projection.Apply(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("projection %s: %w", projection.Name(), err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// Names returns the names of the projections
+func (p *Projector) Names() []string {
+	names := make([]string, len(p.projections))
+	for i, projection := range p.projections {
+		names[i] = projection.Name()
+	}
+	return names
+}
+
+// Rebuild rebuilds the named projections, or all of them when no name is given, one after
+// the other. It fails before rebuilding anything when a name is unknown.
+func (p *Projector) Rebuild(ctx context.Context, names ...string) error {
+	projections := p.projections
+	if len(names) > 0 {
+		projections = make([]Projection, 0, len(names))
+		for _, name := range names {
+			index := slices.IndexFunc(p.projections, func(projection Projection) bool { return projection.Name() == name })
+			if index < 0 {
+				return fmt.Errorf("unknown projection %q, expected one of %v", name, p.Names())
+			}
+			projections = append(projections, p.projections[index])
+		}
+	}
+
+	for _, projection := range projections {
+		start := time.Now()
+		rows, err := projection.Rebuild(ctx)
+		if err != nil {
+			return fmt.Errorf("rebuild projection %s: %w", projection.Name(), err)
+		}
+
+		logger.Log.Info("Projection rebuilt",
+			zap.String("projection", projection.Name()),
+			zap.Int64("rows", rows),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package projections
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// MockProjection is a mock implementation of Projection
+type MockProjection struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockProjection) Name() string {
+	return m.name
+}
+
+func (m *MockProjection) Apply(ctx context.Context, event Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockProjection) Rebuild(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockEnqueuer is a mock implementation of jobs.Enqueuer
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+func (m *MockEnqueuer) Enqueue(ctx context.Context, args jobs.Args, opts ...jobs.EnqueueOption) (*models.Job, error) {
+	called := m.Called(ctx, args)
+	return &models.Job{}, called.Error(0)
+}
+
+func TestNew_DuplicateName(t *testing.T) {
+	_, err := New(nil, &MockProjection{name: "summaries"}, &MockProjection{name: "summaries"})
+
+	assert.ErrorContains(t, err, "duplicate projection summaries")
+}
+
+func TestProjector_Publish(t *testing.T) {
+	enqueuer := new(MockEnqueuer)
+	projector, err := New(enqueuer)
+	require.NoError(t, err)
+
+	var published jobs.ProjectEventArgs
+	enqueuer.On("Enqueue", mock.Anything, mock.AnythingOfType("jobs.ProjectEventArgs")).Run(func(args mock.Arguments) {
+		published = args.Get(1).(jobs.ProjectEventArgs)
+	}).Return(nil)
+
+	require.NoError(t, projector.Publish(context.Background(), "company-1", "user.updated", "user-1"))
+
+	assert.Equal(t, "user.updated", published.EventType)
+	assert.Equal(t, "company-1", published.CompanyID)
+	assert.Equal(t, "user-1", published.EntityID)
+	assert.WithinDuration(t, time.Now(), published.OccurredAt, time.Minute)
+}
+
+func TestProjector_Apply(t *testing.T) {
+	occurredAt := time.Now().UTC()
+	event := Event{Type: "user.updated", CompanyID: "company-1", OccurredAt: occurredAt}
+
+	tests := []struct {
+		name          string
+		firstErr      error
+		secondErr     error
+		expectedError string
+	}{
+		{name: "success"},
+		{name: "failure does not skip the other projections", firstErr: assert.AnError, expectedError: "projection first: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &MockProjection{name: "first"}
+			second := &MockProjection{name: "second"}
+			first.On("Apply", mock.Anything, event).Return(tt.firstErr)
+			second.On("Apply", mock.Anything, event).Return(tt.secondErr)
+			projector, err := New(nil, first, second)
+			require.NoError(t, err)
+
+			err = projector.Apply(context.Background(), jobs.ProjectEventArgs{
+				EventType:  event.Type,
+				CompanyID:  event.CompanyID,
+				OccurredAt: occurredAt,
+			})
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				assert.ErrorIs(t, err, assert.AnError)
+			} else {
+				assert.NoError(t, err)
+			}
+			first.AssertExpectations(t)
+			second.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProjector_Rebuild(t *testing.T) {
+	tests := []struct {
+		name            string
+		names           []string
+		expectedRebuilt []string
+		expectedError   string
+	}{
+		{name: "all", expectedRebuilt: []string{"first", "second"}},
+		{name: "named", names: []string{"second"}, expectedRebuilt: []string{"second"}},
+		{name: "unknown", names: []string{"second", "third"}, expectedError: `unknown projection "third"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &MockProjection{name: "first"}
+			second := &MockProjection{name: "second"}
+			first.On("Rebuild", mock.Anything).Return(int64(3), nil).Maybe()
+			second.On("Rebuild", mock.Anything).Return(int64(0), nil).Maybe()
+			projector, err := New(nil, first, second)
+			require.NoError(t, err)
+
+			err = projector.Rebuild(context.Background(), tt.names...)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, projection := range []*MockProjection{first, second} {
+				if slices.Contains(tt.expectedRebuilt, projection.name) {
+					projection.AssertCalled(t, "Rebuild", mock.Anything)
+				} else {
+					projection.AssertNotCalled(t, "Rebuild", mock.Anything)
+				}
+			}
+		})
+	}
+}
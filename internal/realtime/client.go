@@ -0,0 +1,104 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+
+	"github.com/gorilla/websocket"
+)
+
+// client is a WebSocket connection of a user. Its read pump runs on the connection
+// goroutine and its write pump, the only writer of the connection, on its own.
+type client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID string
+	send   chan []byte
+
+	stopOnce    sync.Once
+	stopped     chan struct{}
+	closeCode   int
+	closeReason string
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, userID string) *client {
+	return &client{
+		hub:     hub,
+		conn:    conn,
+		userID:  userID,
+		send:    make(chan []byte, constants.RealtimeSendBuffer),
+		stopped: make(chan struct{}),
+	}
+}
+
+// enqueue queues the encoded event for the write pump
+func (c *client) enqueue(_ Event, message []byte) bool {
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop asks the write pump to close the connection with the code; only the first call counts
+func (c *client) stop(code int, reason string) {
+	c.stopOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.stopped)
+	})
+}
+
+// readPump discards the messages of the client and keeps the connection alive with pongs,
+// until the connection fails or is closed
+func (c *client) readPump() {
+	c.conn.SetReadLimit(constants.RealtimeMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(constants.RealtimePongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(constants.RealtimePongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump writes the queued events and pings to the connection, and closes it once the
+// client is stopped or a write fails
+func (c *client) writePump() {
+	ticker := time.NewTicker(constants.RealtimePingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(constants.RealtimeWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(constants.RealtimeWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.stopped:
+			c.closeWith(c.closeCode, c.closeReason)
+			return
+		}
+	}
+}
+
+// closeWith sends a close message before closing the connection
+func (c *client) closeWith(code int, reason string) {
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(constants.RealtimeWriteWait))
+	_ = c.conn.Close()
+}
@@ -0,0 +1,34 @@
+// Package realtime pushes events to the connected clients of a user over WebSockets.
+// Clients authenticate with their Keycloak token and join the channel of the token
+// subject; services publish events to a user through the Publisher interface.
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a message pushed to the clients of a user
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// NewEvent creates an event of the given type with data encoded as JSON
+func NewEvent(eventType string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Data:      raw,
+		Timestamp: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,279 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/shutdown"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// ErrHubClosed is returned when subscribing to a hub that is shutting down
+var ErrHubClosed = errors.New("realtime hub is closed")
+
+// Publisher pushes events to the connected clients of a user
+type Publisher interface {
+	// Publish sends an event with data encoded as JSON to the clients of the user, identified
+	// by the subject of their Keycloak token. Users without connected clients are skipped.
+	Publish(ctx context.Context, userID string, eventType string, data any) error
+}
+
+// subscriber is a WebSocket connection or a server-sent events stream of a user
+type subscriber interface {
+	// enqueue queues the event without blocking and reports whether there was room for it
+	enqueue(event Event, message []byte) bool
+	// stop disconnects the subscriber; the close code only applies to WebSockets
+	stop(code int, reason string)
+}
+
+// Hub tracks the WebSocket connections and event streams of each user, and keeps their
+// recent events so that streams can resume after a reconnection
+type Hub struct {
+	mu        sync.RWMutex
+	clients   map[string]map[subscriber]struct{}
+	history   map[string][]Event
+	lastSweep time.Time
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewHub creates a hub without connections
+func NewHub() *Hub {
+	return &Hub{
+		clients:   make(map[string]map[subscriber]struct{}),
+		history:   make(map[string][]Event),
+		lastSweep: time.Now(),
+	}
+}
+
+// ProvideHub creates the hub of the server. Hijacked WebSocket connections are not drained
+// by the HTTP server, so the hub closes them on shutdown.
+func ProvideHub(sequence *shutdown.Sequence) *Hub {
+	hub := NewHub()
+	sequence.Register(shutdown.PhaseIngress, shutdown.ComponentRealtime, constants.RealtimeCloseTimeout, hub.Close)
+	return hub
+}
+
+// ProvidePublisher exposes the hub to the services
+func ProvidePublisher(hub *Hub) Publisher {
+	return hub
+}
+
+// Publish implements Publisher. The event is kept in the history of the user, and clients
+// too slow to take it are disconnected.
+func (h *Hub) Publish(ctx context.Context, userID string, eventType string, data any) error {
+	event, err := NewEvent(eventType, data)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+
+	var slow []subscriber
+	h.mu.Lock()
+	h.record(userID, event)
+	for s := range h.clients[userID] {
+		if !s.enqueue(event, message) {
+			slow = append(slow, s)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, s := range slow {
+		logger.Log.Warn("Dropping slow realtime connection",
+			zap.String("user_id", userID),
+			zap.String("event_type", eventType),
+		)
+		h.remove(userID, s, websocket.ClosePolicyViolation, "client too slow")
+	}
+
+	return nil
+}
+
+// Serve runs a connection of the user until it is closed by the client or the hub
+func (h *Hub) Serve(conn *websocket.Conn, userID string) {
+	c := newClient(h, conn, userID)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		c.closeWith(websocket.CloseGoingAway, "server shutting down")
+		return
+	}
+	h.add(userID, c)
+	h.wg.Add(1)
+	h.mu.Unlock()
+
+	defer h.wg.Done()
+	logger.Log.Debug("Realtime connection opened", zap.String("user_id", userID))
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.writePump()
+	}()
+	c.readPump()
+
+	h.remove(userID, c, websocket.CloseNormalClosure, "")
+	<-writerDone
+	logger.Log.Debug("Realtime connection closed", zap.String("user_id", userID))
+}
+
+// Subscribe opens an event stream of the user. With the ID of the last event the client
+// received, the events published after it are replayed first; when that event is no longer
+// in the history, every event still kept is replayed. The stream must be closed once served.
+func (h *Hub) Subscribe(userID string, lastEventID string) (*Stream, error) {
+	s := newStream(h, userID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+
+	// Replay under the lock so that no event is published between the replay and the
+	// registration; the stream buffer holds the whole history
+	for _, event := range h.replay(userID, lastEventID) {
+		s.enqueue(event, nil)
+	}
+	h.add(userID, s)
+	h.wg.Add(1)
+
+	return s, nil
+}
+
+// Connections returns the number of connections and streams of the user
+func (h *Hub) Connections(userID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID])
+}
+
+// CloseStreams ends the event streams, which are regular HTTP requests that would otherwise
+// hold the HTTP server drain until its deadline. Clients reconnect with Last-Event-ID.
+func (h *Hub) CloseStreams() {
+	for s, userID := range h.subscribers() {
+		if _, ok := s.(*Stream); ok {
+			h.remove(userID, s, websocket.CloseGoingAway, "server shutting down")
+		}
+	}
+}
+
+// Close disconnects every client and waits for their connections to close until ctx expires
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	for s, userID := range h.subscribers() {
+		h.remove(userID, s, websocket.CloseGoingAway, "server shutting down")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// subscribers returns a snapshot of the subscribers with their user
+func (h *Hub) subscribers() map[subscriber]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	all := make(map[subscriber]string)
+	for userID, userClients := range h.clients {
+		for s := range userClients {
+			all[s] = userID
+		}
+	}
+	return all
+}
+
+// add registers a subscriber of the user; h.mu must be held
+func (h *Hub) add(userID string, s subscriber) {
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[subscriber]struct{})
+	}
+	h.clients[userID][s] = struct{}{}
+}
+
+// remove unregisters a subscriber and stops it with the close code
+func (h *Hub) remove(userID string, s subscriber, code int, reason string) {
+	h.mu.Lock()
+	if userClients, ok := h.clients[userID]; ok {
+		delete(userClients, s)
+		if len(userClients) == 0 {
+			delete(h.clients, userID)
+		}
+	}
+	h.mu.Unlock()
+
+	s.stop(code, reason)
+}
+
+// record appends the event to the history of the user, keeping the last
+// RealtimeHistorySize events within RealtimeHistoryTTL; h.mu must be held. The histories
+// of the other users are swept once per TTL so that idle users do not keep theirs forever.
+func (h *Hub) record(userID string, event Event) {
+	events := append(h.history[userID], event)
+	if len(events) > constants.RealtimeHistorySize {
+		events = events[len(events)-constants.RealtimeHistorySize:]
+	}
+	h.history[userID] = events
+
+	now := time.Now()
+	if now.Sub(h.lastSweep) < constants.RealtimeHistoryTTL {
+		return
+	}
+	h.lastSweep = now
+	for id, userEvents := range h.history {
+		if kept := unexpired(userEvents, now); len(kept) > 0 {
+			h.history[id] = kept
+		} else {
+			delete(h.history, id)
+		}
+	}
+}
+
+// replay returns the events of the user published after lastEventID; h.mu must be held
+func (h *Hub) replay(userID string, lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+
+	events := unexpired(h.history[userID], time.Now())
+	for i, event := range events {
+		if event.ID == lastEventID {
+			return events[i+1:]
+		}
+	}
+	return events
+}
+
+// unexpired returns the events published within RealtimeHistoryTTL, oldest first
+func unexpired(events []Event, now time.Time) []Event {
+	for i, event := range events {
+		if now.Sub(event.Timestamp) < constants.RealtimeHistoryTTL {
+			return events[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// newTestServer serves the hub, taking the user ID from the user query parameter
+func newTestServer(t *testing.T, hub *Hub) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, r.URL.Query().Get("user"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, hub *Hub, userID string) *websocket.Conn {
+	t.Helper()
+	before := hub.Connections(userID)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?user=" + userID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	require.Eventually(t, func() bool { return hub.Connections(userID) == before+1 }, time.Second, time.Millisecond)
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) Event {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var event Event
+	require.NoError(t, conn.ReadJSON(&event))
+	return event
+}
+
+func TestHub_PublishToUserConnections(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(t, hub)
+	first := dial(t, srv, hub, "user-1")
+	second := dial(t, srv, hub, "user-1")
+	other := dial(t, srv, hub, "user-2")
+
+	err := hub.Publish(context.Background(), "user-1", "user.updated", map[string]string{"email": "ada@example.com"})
+	require.NoError(t, err)
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		event := readEvent(t, conn)
+		assert.Equal(t, "user.updated", event.Type)
+		assert.NotEmpty(t, event.ID)
+		assert.False(t, event.Timestamp.IsZero())
+		assert.JSONEq(t, `{"email":"ada@example.com"}`, string(event.Data))
+	}
+
+	require.NoError(t, other.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, _, err = other.ReadMessage()
+	assert.Error(t, err, "other users must not receive the event")
+}
+
+func TestHub_PublishWithoutConnections(t *testing.T) {
+	hub := NewHub()
+
+	assert.NoError(t, hub.Publish(context.Background(), "nobody", "user.updated", nil))
+}
+
+func TestHub_PublishEncodingError(t *testing.T) {
+	hub := NewHub()
+
+	err := hub.Publish(context.Background(), "user-1", "user.updated", func() {})
+
+	assert.ErrorContains(t, err, "encode user.updated event")
+}
+
+func TestHub_UnregistersClosedConnections(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(t, hub)
+	conn := dial(t, srv, hub, "user-1")
+
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool { return hub.Connections("user-1") == 0 }, time.Second, time.Millisecond)
+}
+
+func TestHub_DropsSlowConnections(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(t, hub)
+	dial(t, srv, hub, "user-1")
+
+	// The client never reads, the send buffer fills up and the connection is dropped
+	payload := strings.Repeat("x", 64<<10)
+	assert.Eventually(t, func() bool {
+		require.NoError(t, hub.Publish(context.Background(), "user-1", "user.updated", payload))
+		return hub.Connections("user-1") == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(t, hub)
+	conn := dial(t, srv, hub, "user-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, hub.Close(ctx))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	assert.Equal(t, 0, hub.Connections("user-1"))
+
+	// Connections opened after the shutdown started are refused
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?user=user-1"
+	late, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer late.Close()
+	require.NoError(t, late.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err = late.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("user.updated", struct {
+		ID string `json:"id"`
+	}{ID: "42"})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"data":{"id":"42"}`)
+	assert.Contains(t, string(encoded), `"type":"user.updated"`)
+}
+
+func receive(t *testing.T, stream *Stream) Event {
+	t.Helper()
+	select {
+	case event := <-stream.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestHub_SubscribeReceivesEvents(t *testing.T) {
+	hub := NewHub()
+	stream, err := hub.Subscribe("user-1", "")
+	require.NoError(t, err)
+	defer stream.Close()
+	assert.Equal(t, 1, hub.Connections("user-1"))
+
+	require.NoError(t, hub.Publish(context.Background(), "user-1", "user.updated", map[string]string{"email": "ada@example.com"}))
+	require.NoError(t, hub.Publish(context.Background(), "user-2", "user.updated", nil))
+
+	event := receive(t, stream)
+	assert.Equal(t, "user.updated", event.Type)
+	assert.JSONEq(t, `{"email":"ada@example.com"}`, string(event.Data))
+	assert.Empty(t, stream.Events(), "other users events must not be received")
+
+	stream.Close()
+	assert.Equal(t, 0, hub.Connections("user-1"))
+}
+
+func TestHub_SubscribeResumesAfterLastEventID(t *testing.T) {
+	hub := NewHub()
+	for _, eventType := range []string{"first", "second", "third"} {
+		require.NoError(t, hub.Publish(context.Background(), "user-1", eventType, nil))
+	}
+	first, err := hub.Subscribe("user-1", "")
+	require.NoError(t, err)
+	defer first.Close()
+	assert.Empty(t, first.Events(), "new streams start with live events")
+
+	hub.mu.RLock()
+	lastEventID := hub.history["user-1"][0].ID
+	hub.mu.RUnlock()
+
+	resumed, err := hub.Subscribe("user-1", lastEventID)
+	require.NoError(t, err)
+	defer resumed.Close()
+	assert.Equal(t, "second", receive(t, resumed).Type)
+	assert.Equal(t, "third", receive(t, resumed).Type)
+
+	// An event no longer in the history replays everything kept
+	unknown, err := hub.Subscribe("user-1", "expired-event")
+	require.NoError(t, err)
+	defer unknown.Close()
+	assert.Len(t, unknown.Events(), 3)
+}
+
+func TestHub_HistoryIsBounded(t *testing.T) {
+	hub := NewHub()
+	for i := 0; i < constants.RealtimeHistorySize+10; i++ {
+		require.NoError(t, hub.Publish(context.Background(), "user-1", "user.updated", i))
+	}
+
+	stream, err := hub.Subscribe("user-1", "expired-event")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	assert.Len(t, stream.Events(), constants.RealtimeHistorySize)
+	assert.JSONEq(t, "10", string(receive(t, stream).Data), "the oldest events are dropped")
+}
+
+func TestHub_DropsSlowStreams(t *testing.T) {
+	hub := NewHub()
+	stream, err := hub.Subscribe("user-1", "")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	// The stream is never read, its buffer fills up and it is dropped
+	for i := 0; i <= cap(stream.Events()); i++ {
+		require.NoError(t, hub.Publish(context.Background(), "user-1", "user.updated", i))
+	}
+
+	select {
+	case <-stream.Done():
+	case <-time.After(time.Second):
+		t.Fatal("slow stream not dropped")
+	}
+	assert.Equal(t, 0, hub.Connections("user-1"))
+}
+
+func TestHub_CloseStreams(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(t, hub)
+	dial(t, srv, hub, "user-1")
+	stream, err := hub.Subscribe("user-1", "")
+	require.NoError(t, err)
+
+	hub.CloseStreams()
+
+	select {
+	case <-stream.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream not closed")
+	}
+	assert.Equal(t, 1, hub.Connections("user-1"), "WebSocket connections are left to Close")
+
+	// Close waits for the stream to be closed by its handler
+	stream.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, hub.Close(ctx))
+
+	_, err = hub.Subscribe("user-1", "")
+	assert.ErrorIs(t, err, ErrHubClosed)
+}
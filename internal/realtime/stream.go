@@ -0,0 +1,62 @@
+package realtime
+
+import (
+	"sync"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Stream is a server-sent events subscription of a user. The hub stops it when the client
+// falls behind or on shutdown; the client then resumes from the last event it received.
+type Stream struct {
+	hub    *Hub
+	userID string
+	events chan Event
+
+	stopOnce  sync.Once
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func newStream(hub *Hub, userID string) *Stream {
+	return &Stream{
+		hub:    hub,
+		userID: userID,
+		// Room for a full replay of the history on top of the live events
+		events:  make(chan Event, constants.RealtimeHistorySize+constants.RealtimeSendBuffer),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Events returns the events to send to the client
+func (s *Stream) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed when the hub stops the stream
+func (s *Stream) Done() <-chan struct{} {
+	return s.stopped
+}
+
+// Close unsubscribes the stream from the hub
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.remove(s.userID, s, 0, "")
+		s.hub.wg.Done()
+	})
+}
+
+func (s *Stream) enqueue(event Event, _ []byte) bool {
+	select {
+	case s.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Stream) stop(int, string) {
+	s.stopOnce.Do(func() {
+		close(s.stopped)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"golang-boilerplate/internal/dtos"
 
 	"reflect"
+	"slices"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -140,6 +141,57 @@ func (r *abstractRepository[T]) find(tx *gorm.DB, pr *dtos.PageableRequest) (*dt
 	}, nil
 }
 
+// findAfter returns the next limit entities of the query, newest first, after the cursor,
+// or the first ones without cursor. Unlike offsets, the cursor neither scans the skipped
+// rows nor skips or repeats rows inserted while the listing is read.
+func (r *abstractRepository[T]) findAfter(tx *gorm.DB, table string, after *dtos.Cursor, limit int) ([]T, error) {
+	var model T
+	tx = tx.Model(&model)
+
+	if after != nil {
+		tx = tx.Where("("+table+".created_at, "+table+".id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var entities []T
+	res := tx.Order(table + ".created_at desc").Order(table + ".id desc").Limit(limit).Find(&entities)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return entities, nil
+}
+
+// findRange returns limit entities of the query from offset, oldest first, and the count of
+// all of them, for the listings paged by index such as SCIM. filters are columns of table
+// the entities must equal, whatever the case.
+func (r *abstractRepository[T]) findRange(tx *gorm.DB, table string, filters map[string]string, offset int, limit int) ([]T, int64, error) {
+	var model T
+	tx = tx.Model(&model)
+
+	columns := make([]string, 0, len(filters))
+	for column := range filters {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	for _, column := range columns {
+		tx = tx.Where("LOWER("+table+"."+column+") = LOWER(?)", filters[column])
+	}
+
+	var total int64
+	if err := tx.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entities []T
+	if total == 0 || limit == 0 {
+		return entities, total, nil
+	}
+	res := tx.Order(table + ".created_at asc").Order(table + ".id asc").Offset(offset).Limit(limit).Find(&entities)
+	if res.Error != nil {
+		return nil, 0, res.Error
+	}
+	return entities, total, nil
+}
+
 // ensureUUIDPrimaryKey sets the `ID` field to a new uuid if it exists,
 // is of type uuid.UUID, and is currently zero (uuid.Nil).
 func ensureUUIDPrimaryKey(entity any) {
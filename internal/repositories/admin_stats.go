@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+)
+
+// AdminStatsRepository defines the aggregate queries of the admin dashboard
+type AdminStatsRepository interface {
+	// GetUserStats counts the active and deleted users, and the users created since day
+	// and since week
+	GetUserStats(day time.Time, week time.Time) (*dtos.AdminUserStats, error)
+	// GetRecentSignups returns the users created last, most recent first
+	GetRecentSignups(limit int) ([]models.User, error)
+	// GetTenantStats sums the company summaries read model
+	GetTenantStats() (*dtos.AdminTenantStats, error)
+	// GetFailingWebhooks returns the endpoints with the most deliveries failed since,
+	// most failures first
+	GetFailingWebhooks(since time.Time, limit int) ([]dtos.AdminFailingWebhook, error)
+	// GetJobQueueStats counts the jobs of the task queue by status
+	GetJobQueueStats() (*dtos.AdminJobQueueStats, error)
+}
+
+// adminStatsRepository implements AdminStatsRepository
+type adminStatsRepository struct {
+	db *db.PostgresDB
+}
+
+// ProvideAdminStatsRepository creates a new admin stats repository
+func ProvideAdminStatsRepository(db *db.PostgresDB) AdminStatsRepository {
+	return &adminStatsRepository{db: db}
+}
+
+func (r *adminStatsRepository) GetUserStats(day time.Time, week time.Time) (*dtos.AdminUserStats, error) {
+	stats := &dtos.AdminUserStats{}
+	err := r.db.Unscoped().Model(&models.User{}).
+		Select(`count(*) FILTER (WHERE deleted_at IS NULL) AS active,
+			count(*) FILTER (WHERE deleted_at IS NOT NULL) AS deleted,
+			count(*) FILTER (WHERE deleted_at IS NULL AND created_at >= ?) AS signups_last_day,
+			count(*) FILTER (WHERE deleted_at IS NULL AND created_at >= ?) AS signups_last_week`, day, week).
+		Scan(stats).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to count users", err).
+			WithOperation("get_user_stats").
+			WithResource("user")
+	}
+
+	return stats, nil
+}
+
+func (r *adminStatsRepository) GetRecentSignups(limit int) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Order("created_at desc").Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get recent signups", err).
+			WithOperation("get_recent_signups").
+			WithResource("user")
+	}
+
+	return users, nil
+}
+
+func (r *adminStatsRepository) GetTenantStats() (*dtos.AdminTenantStats, error) {
+	stats := &dtos.AdminTenantStats{}
+	err := r.db.Model(&models.CompanySummary{}).
+		Select(`count(*) AS companies, coalesce(sum(member_count), 0) AS members,
+			coalesce(sum(storage_bytes), 0) AS storage_bytes, max(refreshed_at) AS refreshed_at`).
+		Scan(stats).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to sum company summaries", err).
+			WithOperation("get_tenant_stats").
+			WithResource("company_summary")
+	}
+
+	return stats, nil
+}
+
+func (r *adminStatsRepository) GetFailingWebhooks(since time.Time, limit int) ([]dtos.AdminFailingWebhook, error) {
+	var endpoints []dtos.AdminFailingWebhook
+	err := r.db.Model(&models.WebhookDelivery{}).
+		Select(`webhook_deliveries.endpoint_id, webhook_endpoints.company_id, webhook_endpoints.url,
+			webhook_endpoints.active, count(*) AS failures, max(webhook_deliveries.updated_at) AS last_failure_at`).
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id AND webhook_endpoints.deleted_at IS NULL").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.updated_at >= ?", constants.WebhookDeliveryFailed, since).
+		Group("webhook_deliveries.endpoint_id, webhook_endpoints.company_id, webhook_endpoints.url, webhook_endpoints.active").
+		Order("failures desc, last_failure_at desc").
+		Limit(limit).
+		Scan(&endpoints).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get failing webhooks", err).
+			WithOperation("get_failing_webhooks").
+			WithResource("webhook_delivery")
+	}
+
+	return endpoints, nil
+}
+
+func (r *adminStatsRepository) GetJobQueueStats() (*dtos.AdminJobQueueStats, error) {
+	stats := &dtos.AdminJobQueueStats{}
+	err := r.db.Model(&models.Job{}).
+		Select(`count(*) FILTER (WHERE status = @pending AND run_at <= now()) AS pending,
+			count(*) FILTER (WHERE status = @pending AND run_at > now()) AS scheduled,
+			count(*) FILTER (WHERE status = @running) AS running,
+			count(*) FILTER (WHERE status = @dead) AS dead,
+			min(run_at) FILTER (WHERE status = @pending AND run_at <= now()) AS oldest_pending_at`, map[string]any{
+			"pending": constants.JobStatusPending,
+			"running": constants.JobStatusRunning,
+			"dead":    constants.JobStatusDead,
+		}).
+		Scan(stats).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to count jobs", err).
+			WithOperation("get_job_queue_stats").
+			WithResource("job")
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIKeyRepository defines the data operations of the API keys and their usage
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByID(companyID string, id string) (*models.APIKey, error)
+	GetByHash(keyHash string) (*models.APIKey, error)
+	GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.APIKey], error)
+	Revoke(key *models.APIKey) error
+	// RecordUsage adds the calls to the daily counters and moves the last use of the keys
+	// forward, clearing their unused alert, in one transaction
+	RecordUsage(usages []models.APIKeyUsage, lastUsed map[string]time.Time) error
+	GetDailyUsage(keyID string, since time.Time) ([]dtos.APIKeyDailyUsage, error)
+	GetEndpointUsage(keyID string, since time.Time) ([]dtos.APIKeyEndpointUsage, error)
+	// GetUnused returns the active keys neither used nor created since before
+	GetUnused(before time.Time) ([]models.APIKey, error)
+	MarkUnusedAlerted(ids []string, alertedAt time.Time) error
+}
+
+// apiKeyRepository implements APIKeyRepository
+type apiKeyRepository struct {
+	abstractRepository[models.APIKey]
+}
+
+// ProvideAPIKeyRepository creates a new API key repository
+func ProvideAPIKeyRepository(db *db.PostgresDB) APIKeyRepository {
+	return &apiKeyRepository{
+		abstractRepository: abstractRepository[models.APIKey]{db: db},
+	}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	if err := r.db.Omit("Company").Create(key).Error; err != nil {
+		return errors.DatabaseError("Failed to create API key", err).
+			WithOperation("create_api_key").
+			WithResource("api_key").
+			WithContext("company_id", key.CompanyID)
+	}
+
+	return nil
+}
+
+// GetByID returns a key of the company, so that a key id of another company is reported
+// as not found
+func (r *apiKeyRepository) GetByID(companyID string, id string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(key).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("API key", err).
+				WithOperation("get_api_key").
+				WithResource("api_key").
+				WithContext("company_id", companyID).
+				WithContext("api_key_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get API key", err).
+			WithOperation("get_api_key").
+			WithResource("api_key").
+			WithContext("company_id", companyID).
+			WithContext("api_key_id", id)
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := r.db.Where("key_hash = ?", keyHash).First(key).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("API key", err).
+				WithOperation("get_api_key_by_hash").
+				WithResource("api_key")
+		}
+		return nil, errors.DatabaseError("Failed to get API key", err).
+			WithOperation("get_api_key_by_hash").
+			WithResource("api_key")
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.APIKey], error) {
+	query := r.db.DB.
+		Where("company_id = ?", companyID).
+		Order("created_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get API keys", err).
+			WithOperation("get_api_keys").
+			WithResource("api_keys").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepository) Revoke(key *models.APIKey) error {
+	err := r.db.Model(&models.APIKey{}).
+		Where("id = ?", key.ID).
+		Update("revoked_at", key.RevokedAt).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to revoke API key", err).
+			WithOperation("revoke_api_key").
+			WithResource("api_key").
+			WithContext("api_key_id", key.ID)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) RecordUsage(usages []models.APIKeyUsage, lastUsed map[string]time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if len(usages) > 0 {
+			err := tx.Omit("APIKey").Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "api_key_id"}, {Name: "day"}, {Name: "endpoint"}},
+				DoUpdates: clause.Assignments(map[string]any{
+					"calls":      gorm.Expr("api_key_usages.calls + excluded.calls"),
+					"updated_at": gorm.Expr("now()"),
+				}),
+			}).Create(&usages).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for keyID, usedAt := range lastUsed {
+			err := tx.Model(&models.APIKey{}).
+				Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, usedAt).
+				Updates(map[string]any{
+					"last_used_at":      usedAt,
+					"unused_alerted_at": nil,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to record API key usage", err).
+			WithOperation("record_api_key_usage").
+			WithResource("api_key_usage").
+			WithContext("keys", len(lastUsed))
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) GetDailyUsage(keyID string, since time.Time) ([]dtos.APIKeyDailyUsage, error) {
+	var daily []dtos.APIKeyDailyUsage
+	err := r.db.Model(&models.APIKeyUsage{}).
+		Select("to_char(day, 'YYYY-MM-DD') AS day, SUM(calls) AS calls").
+		Where("api_key_id = ? AND day >= ?", keyID, since).
+		Group("day").
+		Order("day asc").
+		Scan(&daily).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get API key usage", err).
+			WithOperation("get_api_key_daily_usage").
+			WithResource("api_key_usage").
+			WithContext("api_key_id", keyID)
+	}
+
+	return daily, nil
+}
+
+func (r *apiKeyRepository) GetEndpointUsage(keyID string, since time.Time) ([]dtos.APIKeyEndpointUsage, error) {
+	var endpoints []dtos.APIKeyEndpointUsage
+	err := r.db.Model(&models.APIKeyUsage{}).
+		Select("endpoint, SUM(calls) AS calls").
+		Where("api_key_id = ? AND day >= ?", keyID, since).
+		Group("endpoint").
+		Order("calls desc, endpoint asc").
+		Scan(&endpoints).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get API key usage", err).
+			WithOperation("get_api_key_endpoint_usage").
+			WithResource("api_key_usage").
+			WithContext("api_key_id", keyID)
+	}
+
+	return endpoints, nil
+}
+
+func (r *apiKeyRepository) GetUnused(before time.Time) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.
+		Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())").
+		Where("COALESCE(last_used_at, created_at) < ?", before).
+		Order("created_at asc").
+		Find(&keys).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get unused API keys", err).
+			WithOperation("get_unused_api_keys").
+			WithResource("api_keys")
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) MarkUnusedAlerted(ids []string, alertedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := r.db.Model(&models.APIKey{}).
+		Where("id IN ?", ids).
+		Update("unused_alerted_at", alertedAt).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to mark unused API keys", err).
+			WithOperation("mark_unused_api_keys").
+			WithResource("api_keys").
+			WithContext("count", len(ids))
+	}
+
+	return nil
+}
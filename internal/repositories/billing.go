@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	stderrors "errors"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BillingRepository defines the data operations of the plans and the subscriptions of the
+// companies
+type BillingRepository interface {
+	// GetPlanByKey returns the plan with its entitlements
+	GetPlanByKey(key string) (*models.Plan, error)
+	// GetPlanByPriceID returns the plan billed with the price of the payment provider,
+	// with its entitlements
+	GetPlanByPriceID(priceID string) (*models.Plan, error)
+	// GetSubscription returns the subscription of the company with its plan and the
+	// entitlements of the plan
+	GetSubscription(companyID string) (*models.Subscription, error)
+	// GetSubscriptionByProviderID returns the subscription linked to the subscription of
+	// the payment provider
+	GetSubscriptionByProviderID(providerSubscriptionID string) (*models.Subscription, error)
+	// SaveSubscription creates or updates the subscription
+	SaveSubscription(subscription *models.Subscription) error
+}
+
+// billingRepository implements BillingRepository
+type billingRepository struct {
+	abstractRepository[models.Subscription]
+}
+
+// ProvideBillingRepository creates a new billing repository
+func ProvideBillingRepository(db *db.PostgresDB) BillingRepository {
+	return &billingRepository{
+		abstractRepository: abstractRepository[models.Subscription]{db: db},
+	}
+}
+
+func (r *billingRepository) GetPlanByKey(key string) (*models.Plan, error) {
+	plan := &models.Plan{}
+	err := r.db.Preload("Entitlements").Where("key = ?", key).First(plan).Error
+	if err != nil {
+		return nil, planError(err, "get_plan_by_key", "key", key)
+	}
+
+	return plan, nil
+}
+
+func (r *billingRepository) GetPlanByPriceID(priceID string) (*models.Plan, error) {
+	plan := &models.Plan{}
+	err := r.db.Preload("Entitlements").Where("price_id = ?", priceID).First(plan).Error
+	if err != nil {
+		return nil, planError(err, "get_plan_by_price_id", "price_id", priceID)
+	}
+
+	return plan, nil
+}
+
+func (r *billingRepository) GetSubscription(companyID string) (*models.Subscription, error) {
+	subscription := &models.Subscription{}
+	err := r.db.Preload("Plan.Entitlements").Where("company_id = ?", companyID).First(subscription).Error
+	if err != nil {
+		return nil, subscriptionError(err, "get_subscription", "company_id", companyID)
+	}
+
+	return subscription, nil
+}
+
+func (r *billingRepository) GetSubscriptionByProviderID(providerSubscriptionID string) (*models.Subscription, error) {
+	subscription := &models.Subscription{}
+	err := r.db.Preload("Plan.Entitlements").Where("provider_subscription_id = ?", providerSubscriptionID).First(subscription).Error
+	if err != nil {
+		return nil, subscriptionError(err, "get_subscription_by_provider_id", "provider_subscription_id", providerSubscriptionID)
+	}
+
+	return subscription, nil
+}
+
+func (r *billingRepository) SaveSubscription(subscription *models.Subscription) error {
+	if err := r.db.Omit("Company", "Plan").Save(subscription).Error; err != nil {
+		return errors.DatabaseError("Failed to save subscription", err).
+			WithOperation("save_subscription").
+			WithResource("subscription").
+			WithContext("company_id", subscription.CompanyID)
+	}
+
+	return nil
+}
+
+func planError(err error, operation string, key string, value string) error {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NotFoundError("Plan", err).
+			WithOperation(operation).
+			WithResource("plan").
+			WithContext(key, value)
+	}
+	return errors.DatabaseError("Failed to get plan", err).
+		WithOperation(operation).
+		WithResource("plan").
+		WithContext(key, value)
+}
+
+func subscriptionError(err error, operation string, key string, value string) error {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NotFoundError("Subscription", err).
+			WithOperation(operation).
+			WithResource("subscription").
+			WithContext(key, value)
+	}
+	return errors.DatabaseError("Failed to get subscription", err).
+		WithOperation(operation).
+		WithResource("subscription").
+		WithContext(key, value)
+}
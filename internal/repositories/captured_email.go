@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	stderrors "errors"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CapturedEmailRepository defines the data operations of the inboxes of the sandbox
+// tenants and of the dev inbox
+type CapturedEmailRepository interface {
+	Create(email *models.CapturedEmail) error
+	GetByID(companyID string, id string) (*models.CapturedEmail, error)
+	GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.CapturedEmail], error)
+	// GetOne returns a captured email of any inbox, for the dev inbox
+	GetOne(id string) (*models.CapturedEmail, error)
+	// Search returns the captured emails of all the inboxes matching the request, most
+	// recent first, for the dev inbox
+	Search(pr *dtos.DevInboxPageableRequest) (*dtos.DataResponse[models.CapturedEmail], error)
+}
+
+// capturedEmailRepository implements CapturedEmailRepository
+type capturedEmailRepository struct {
+	abstractRepository[models.CapturedEmail]
+}
+
+// ProvideCapturedEmailRepository creates a new captured email repository
+func ProvideCapturedEmailRepository(db *db.PostgresDB) CapturedEmailRepository {
+	return &capturedEmailRepository{
+		abstractRepository: abstractRepository[models.CapturedEmail]{db: db},
+	}
+}
+
+func (r *capturedEmailRepository) Create(email *models.CapturedEmail) error {
+	if err := r.db.Omit("Company").Create(email).Error; err != nil {
+		return errors.DatabaseError("Failed to capture email", err).
+			WithOperation("create_captured_email").
+			WithResource("captured_email").
+			WithContext("company_id", email.CompanyID)
+	}
+
+	return nil
+}
+
+// GetByID returns an email of the company, so that an email id of another company is
+// reported as not found
+func (r *capturedEmailRepository) GetByID(companyID string, id string) (*models.CapturedEmail, error) {
+	email := &models.CapturedEmail{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(email).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Captured email", err).
+				WithOperation("get_captured_email").
+				WithResource("captured_email").
+				WithContext("company_id", companyID).
+				WithContext("email_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get captured email", err).
+			WithOperation("get_captured_email").
+			WithResource("captured_email").
+			WithContext("company_id", companyID).
+			WithContext("email_id", id)
+	}
+
+	return email, nil
+}
+
+func (r *capturedEmailRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.CapturedEmail], error) {
+	query := r.db.DB.
+		Where("company_id = ?", companyID).
+		Order("created_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get captured emails", err).
+			WithOperation("get_captured_emails").
+			WithResource("captured_emails").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *capturedEmailRepository) GetOne(id string) (*models.CapturedEmail, error) {
+	email := &models.CapturedEmail{}
+	if err := r.db.Where("id = ?", id).First(email).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Captured email", err).
+				WithOperation("get_captured_email").
+				WithResource("captured_email").
+				WithContext("email_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get captured email", err).
+			WithOperation("get_captured_email").
+			WithResource("captured_email").
+			WithContext("email_id", id)
+	}
+
+	return email, nil
+}
+
+func (r *capturedEmailRepository) Search(pr *dtos.DevInboxPageableRequest) (*dtos.DataResponse[models.CapturedEmail], error) {
+	query := r.db.DB
+
+	if pr.Q != "" {
+		q := "%" + pr.Q + "%"
+		query = query.Where(`captured_emails.subject ILIKE ? OR captured_emails."to"::text ILIKE ? OR captured_emails.text_body ILIKE ? OR captured_emails.html_body ILIKE ?`, q, q, q, q)
+	}
+
+	if pr.Recipient != "" {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM jsonb_array_elements_text(
+				coalesce(captured_emails."to", '[]') || coalesce(captured_emails.cc, '[]') || coalesce(captured_emails.bcc, '[]')
+			) AS recipient
+			WHERE lower(recipient) = lower(?)
+		)`, pr.Recipient)
+	}
+
+	query = query.Order("captured_emails.created_at desc")
+
+	result, err := r.find(query, &pr.PageableRequest)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to search captured emails", err).
+			WithOperation("search_captured_emails").
+			WithResource("captured_emails").
+			WithContext("pageable_request", pr)
+	}
+
+	return result, nil
+}
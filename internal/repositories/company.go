@@ -1,20 +1,38 @@
 package repositories
 
 import (
+	stderrors "errors"
+
 	"golang-boilerplate/internal/db"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/models"
 	"strings"
+
+	"gorm.io/gorm"
 )
 
 // CompanyRepository defines the interface for company data operations
 type CompanyRepository interface {
 	Create(company *models.Company) (*models.Company, error)
 	GetOneByID(id string) (*models.Company, error)
+	// GetByKeycloakID returns the company of a Keycloak organization
+	GetByKeycloakID(keycloakID string) (*models.Company, error)
+	// GetBySlug returns the provisioned tenant of a slug
+	GetBySlug(slug string) (*models.Company, error)
+	// GetSandbox returns the sandbox tenant of a company
+	GetSandbox(companyID string) (*models.Company, error)
 	Update(company *models.Company) error
+	UpdateColumns(company *models.Company, columns ...string) error
 	Delete(company *models.Company) error
 	Get(pr *dtos.CompanyPageableRequest, preloads ...string) (*dtos.DataResponse[models.Company], error)
+	// GetAfter returns the next limit companies matching the filters of pr, newest first,
+	// after the cursor; the sort and pagination of pr are ignored
+	GetAfter(pr *dtos.CompanyPageableRequest, after *dtos.Cursor, limit int) ([]models.Company, error)
+	// GetRange returns limit companies from offset, oldest first, whose columns equal the
+	// filters whatever the case, and the count of all of them; sandboxes are left out
+	GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.Company, int64, error)
+	GetByUserIDs(userIDs []string) (map[string][]models.Company, error)
 }
 
 // companyRepository implements CompanyRepository
@@ -52,6 +70,63 @@ func (r *companyRepository) GetOneByID(id string) (*models.Company, error) {
 	return company, nil
 }
 
+func (r *companyRepository) GetByKeycloakID(keycloakID string) (*models.Company, error) {
+	company := &models.Company{}
+	err := r.db.Where("keycloak_id = ?", keycloakID).First(company).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Company", err).
+				WithOperation("get_company_by_keycloak_id").
+				WithResource("company").
+				WithContext("keycloak_id", keycloakID)
+		}
+		return nil, errors.DatabaseError("Failed to get company by Keycloak ID", err).
+			WithOperation("get_company_by_keycloak_id").
+			WithResource("company").
+			WithContext("keycloak_id", keycloakID)
+	}
+
+	return company, nil
+}
+
+func (r *companyRepository) GetBySlug(slug string) (*models.Company, error) {
+	company := &models.Company{}
+	err := r.db.Where("slug = ?", slug).First(company).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Company", err).
+				WithOperation("get_company_by_slug").
+				WithResource("company").
+				WithContext("slug", slug)
+		}
+		return nil, errors.DatabaseError("Failed to get company by slug", err).
+			WithOperation("get_company_by_slug").
+			WithResource("company").
+			WithContext("slug", slug)
+	}
+
+	return company, nil
+}
+
+func (r *companyRepository) GetSandbox(companyID string) (*models.Company, error) {
+	company := &models.Company{}
+	err := r.db.Where("sandbox_of_id = ?", companyID).First(company).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Sandbox company", err).
+				WithOperation("get_sandbox_company").
+				WithResource("company").
+				WithContext("company_id", companyID)
+		}
+		return nil, errors.DatabaseError("Failed to get sandbox company", err).
+			WithOperation("get_sandbox_company").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return company, nil
+}
+
 func (r *companyRepository) Update(company *models.Company) error {
 	result := r.db.Updates(company)
 	if result.Error != nil {
@@ -64,6 +139,19 @@ func (r *companyRepository) Update(company *models.Company) error {
 	return nil
 }
 
+// UpdateColumns writes the given columns of a company, including zero values
+func (r *companyRepository) UpdateColumns(company *models.Company, columns ...string) error {
+	result := r.db.Model(company).Select(append(columns, "updated_at")).Updates(company)
+	if result.Error != nil {
+		return errors.DatabaseError("Failed to update company", result.Error).
+			WithOperation("update_company_columns").
+			WithResource("company").
+			WithContext("company_id", company.ID)
+	}
+
+	return nil
+}
+
 func (r *companyRepository) Delete(company *models.Company) error {
 	result := r.db.Delete(company)
 	if result.Error != nil {
@@ -76,8 +164,9 @@ func (r *companyRepository) Delete(company *models.Company) error {
 	return nil
 }
 
+// Get lists the companies, without the sandbox tenants
 func (r *companyRepository) Get(pr *dtos.CompanyPageableRequest, preloads ...string) (*dtos.DataResponse[models.Company], error) {
-	query := r.db.DB
+	query := r.db.DB.Where("companies.sandbox_of_id IS NULL")
 
 	// Apply preloading if set
 	if len(preloads) > 0 {
@@ -86,17 +175,7 @@ func (r *companyRepository) Get(pr *dtos.CompanyPageableRequest, preloads ...str
 		}
 	}
 
-	if pr.Q != "" {
-		query = query.Where("companies.name LIKE ?", "%"+pr.Q+"%")
-	}
-
-	if pr.StartDate != nil {
-		query = query.Where("companies.created_at >= ?", pr.StartDate)
-	}
-
-	if pr.EndDate != nil {
-		query = query.Where("companies.created_at <= ?", pr.EndDate)
-	}
+	query = filterCompanies(query, pr)
 
 	// Apply multiple sort criteria
 	if len(pr.Sort) > 0 {
@@ -130,3 +209,91 @@ func (r *companyRepository) Get(pr *dtos.CompanyPageableRequest, preloads ...str
 
 	return result, nil
 }
+
+func (r *companyRepository) GetAfter(pr *dtos.CompanyPageableRequest, after *dtos.Cursor, limit int) ([]models.Company, error) {
+	query := filterCompanies(r.db.DB.Where("companies.sandbox_of_id IS NULL"), pr)
+
+	companies, err := r.findAfter(query, "companies", after, limit)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get companies", err).
+			WithOperation("get_companies_after").
+			WithResource("companies").
+			WithContext("pageable_request", pr)
+	}
+
+	return companies, nil
+}
+
+func (r *companyRepository) GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.Company, int64, error) {
+	query := r.db.DB.Where("companies.sandbox_of_id IS NULL")
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	companies, total, err := r.findRange(query, "companies", filters, offset, limit)
+	if err != nil {
+		return nil, 0, errors.DatabaseError("Failed to get companies", err).
+			WithOperation("get_companies_range").
+			WithResource("companies").
+			WithContext("filters", filters)
+	}
+
+	return companies, total, nil
+}
+
+// filterCompanies applies the search and date filters of a company listing
+func filterCompanies(query *gorm.DB, pr *dtos.CompanyPageableRequest) *gorm.DB {
+	if pr.Q != "" {
+		query = query.Where("companies.name LIKE ?", "%"+pr.Q+"%")
+	}
+
+	if pr.StartDate != nil {
+		query = query.Where("companies.created_at >= ?", pr.StartDate)
+	}
+
+	if pr.EndDate != nil {
+		query = query.Where("companies.created_at <= ?", pr.EndDate)
+	}
+
+	return query
+}
+
+// GetByUserIDs returns the companies of each of the given users, by name, keyed by user ID.
+// Users without companies are absent from the map.
+func (r *companyRepository) GetByUserIDs(userIDs []string) (map[string][]models.Company, error) {
+	companies := make(map[string][]models.Company, len(userIDs))
+	if len(userIDs) == 0 {
+		return companies, nil
+	}
+
+	memberships, err := findMemberships(r.db.DB, "user_id", userIDs)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get user memberships", err).
+			WithOperation("get_users_companies").
+			WithResource("company")
+	}
+	if len(memberships) == 0 {
+		return companies, nil
+	}
+
+	companyIDs := make([]string, len(memberships))
+	for i, membership := range memberships {
+		companyIDs[i] = membership.CompanyID
+	}
+
+	found := []models.Company{}
+	if err := r.db.Where("companies.id IN ?", companyIDs).Order("companies.name asc").Find(&found).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get users companies", err).
+			WithOperation("get_users_companies").
+			WithResource("company")
+	}
+
+	usersByCompany := groupMemberships(memberships, func(m membership) (string, string) { return m.CompanyID, m.UserID })
+	for _, company := range found {
+		for _, userID := range usersByCompany[company.ID] {
+			companies[userID] = append(companies[userID], company)
+		}
+	}
+
+	return companies, nil
+}
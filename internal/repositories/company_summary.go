@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// projectCompanySummariesSQL computes the summaries of the companies matching the filter
+// from the source tables and writes them, leaving out the sandbox tenants. The last
+// activity never goes back, so that an event applied late does not hide a more recent one.
+const projectCompanySummariesSQL = `
+INSERT INTO company_summaries (company_id, name, member_count, storage_bytes, last_activity_at, refreshed_at)
+SELECT companies.id, companies.name, count(users.id), companies.logo_size + coalesce(sum(users.avatar_size), 0),
+	greatest(companies.updated_at, max(users.updated_at), cast(@activity AS timestamptz)), now()
+FROM companies
+LEFT JOIN user_companies ON user_companies.company_id = companies.id
+LEFT JOIN users ON users.id = user_companies.user_id AND users.deleted_at IS NULL
+WHERE companies.deleted_at IS NULL AND companies.sandbox_of_id IS NULL %s
+GROUP BY companies.id
+ON CONFLICT (company_id) DO UPDATE SET
+	name = excluded.name,
+	member_count = excluded.member_count,
+	storage_bytes = excluded.storage_bytes,
+	last_activity_at = greatest(company_summaries.last_activity_at, excluded.last_activity_at),
+	refreshed_at = excluded.refreshed_at`
+
+// CompanySummaryRepository defines the data operations of the company summaries read
+// model
+type CompanySummaryRepository interface {
+	Get(companyID string) (*models.CompanySummary, error)
+	List(pr *dtos.CompanySummaryPageableRequest) (*dtos.DataResponse[models.CompanySummary], error)
+	// Refresh projects the summary of a company again, with activity as its last activity
+	// when more recent, and deletes it once the company is deleted
+	Refresh(companyID string, activity time.Time) error
+	// Rebuild replaces all the summaries with summaries projected from the source tables
+	// and returns their number
+	Rebuild() (int64, error)
+}
+
+// companySummaryRepository implements CompanySummaryRepository
+type companySummaryRepository struct {
+	abstractRepository[models.CompanySummary]
+}
+
+// ProvideCompanySummaryRepository creates a new company summary repository
+func ProvideCompanySummaryRepository(db *db.PostgresDB) CompanySummaryRepository {
+	return &companySummaryRepository{
+		abstractRepository: abstractRepository[models.CompanySummary]{db: db},
+	}
+}
+
+func (r *companySummaryRepository) Get(companyID string) (*models.CompanySummary, error) {
+	summary := &models.CompanySummary{}
+	if err := r.db.Where("company_id = ?", companyID).First(summary).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Company summary", err).
+				WithOperation("get_company_summary").
+				WithResource("company_summary").
+				WithContext("company_id", companyID)
+		}
+		return nil, errors.DatabaseError("Failed to get company summary", err).
+			WithOperation("get_company_summary").
+			WithResource("company_summary").
+			WithContext("company_id", companyID)
+	}
+
+	return summary, nil
+}
+
+func (r *companySummaryRepository) List(pr *dtos.CompanySummaryPageableRequest) (*dtos.DataResponse[models.CompanySummary], error) {
+	query := r.db.DB
+
+	// Apply multiple sort criteria
+	if len(pr.Sort) > 0 {
+		for _, field := range pr.Sort {
+			if strings.HasPrefix(field, "-") {
+				query = query.Order("company_summaries." + strings.TrimPrefix(field, "-") + " desc nulls last")
+			} else {
+				query = query.Order("company_summaries." + field + " asc nulls first")
+			}
+		}
+	} else {
+		query = query.Order("company_summaries.last_activity_at desc nulls last")
+	}
+	query = query.Order("company_summaries.company_id")
+
+	result, err := r.find(query, &pr.PageableRequest)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get company summaries", err).
+			WithOperation("get_company_summaries").
+			WithResource("company_summary").
+			WithContext("pageable_request", pr)
+	}
+
+	return result, nil
+}
+
+func (r *companySummaryRepository) Refresh(companyID string, activity time.Time) error {
+	result := r.db.Exec(fmt.Sprintf(projectCompanySummariesSQL, "AND companies.id = @id"), map[string]any{
+		"id":       companyID,
+		"activity": activity,
+	})
+	if result.Error != nil {
+		return errors.DatabaseError("Failed to refresh company summary", result.Error).
+			WithOperation("refresh_company_summary").
+			WithResource("company_summary").
+			WithContext("company_id", companyID)
+	}
+
+	// The company is gone, and so is its summary
+	if result.RowsAffected == 0 {
+		if err := r.db.Where("company_id = ?", companyID).Delete(&models.CompanySummary{}).Error; err != nil {
+			return errors.DatabaseError("Failed to delete company summary", err).
+				WithOperation("refresh_company_summary").
+				WithResource("company_summary").
+				WithContext("company_id", companyID)
+		}
+	}
+
+	return nil
+}
+
+func (r *companySummaryRepository) Rebuild() (int64, error) {
+	var rebuilt int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM company_summaries").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(fmt.Sprintf(projectCompanySummariesSQL, ""), map[string]any{
+			"activity": nil,
+		})
+		rebuilt = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, errors.DatabaseError("Failed to rebuild company summaries", err).
+			WithOperation("rebuild_company_summaries").
+			WithResource("company_summary")
+	}
+
+	return rebuilt, nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DemoRepository defines the data operations used to seed and reset demo mode
+type DemoRepository interface {
+	HasData() (bool, error)
+	Seed(companies []models.Company) error
+	Purge() ([]string, error)
+}
+
+// demoRepository implements DemoRepository
+type demoRepository struct {
+	db *db.PostgresDB
+}
+
+// ProvideDemoRepository creates a new demo repository
+func ProvideDemoRepository(db *db.PostgresDB) DemoRepository {
+	return &demoRepository{db: db}
+}
+
+// HasData reports whether any company or user exists, including soft deleted ones
+func (r *demoRepository) HasData() (bool, error) {
+	var companies, users int64
+	if err := r.db.Unscoped().Model(&models.Company{}).Count(&companies).Error; err != nil {
+		return false, errors.DatabaseError("Failed to count companies", err).
+			WithOperation("demo_has_data").
+			WithResource("company")
+	}
+	if err := r.db.Unscoped().Model(&models.User{}).Count(&users).Error; err != nil {
+		return false, errors.DatabaseError("Failed to count users", err).
+			WithOperation("demo_has_data").
+			WithResource("user")
+	}
+
+	return companies > 0 || users > 0, nil
+}
+
+// Seed creates the companies together with their users and memberships in one transaction
+func (r *demoRepository) Seed(companies []models.Company) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&companies).Error
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to seed demo data", err).
+			WithOperation("demo_seed").
+			WithResource("demo")
+	}
+
+	return nil
+}
+
+// Purge hard deletes every membership, tenant credential, user and company and returns
+// the avatar keys of the deleted users so that their objects can be removed from storage
+func (r *demoRepository) Purge() ([]string, error) {
+	avatarKeys := []string{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.User{}).
+			Where("avatar_key IS NOT NULL AND avatar_key <> ''").
+			Pluck("avatar_key", &avatarKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_companies").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM tenant_credentials").Error; err != nil {
+			return err
+		}
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.User{}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.Company{}).Error
+	})
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to purge demo data", err).
+			WithOperation("demo_purge").
+			WithResource("demo")
+	}
+
+	return avatarKeys, nil
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeprecationRepository defines the data operations of the usage of the deprecated surfaces
+type DeprecationRepository interface {
+	// RecordUsage adds the calls of the usages to the counters of their consumers
+	RecordUsage(usages []models.DeprecationUsage) error
+	// GetUsage returns the consumers of the deprecations, the most recent first
+	GetUsage() ([]models.DeprecationUsage, error)
+}
+
+// deprecationRepository implements DeprecationRepository
+type deprecationRepository struct {
+	abstractRepository[models.DeprecationUsage]
+}
+
+// ProvideDeprecationRepository creates a new deprecation repository
+func ProvideDeprecationRepository(db *db.PostgresDB) DeprecationRepository {
+	return &deprecationRepository{
+		abstractRepository: abstractRepository[models.DeprecationUsage]{db: db},
+	}
+}
+
+func (r *deprecationRepository) RecordUsage(usages []models.DeprecationUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "deprecation_id"}, {Name: "consumer"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"calls":         gorm.Expr("deprecation_usages.calls + excluded.calls"),
+			"tenant":        gorm.Expr("excluded.tenant"),
+			"first_seen_at": gorm.Expr("LEAST(deprecation_usages.first_seen_at, excluded.first_seen_at)"),
+			"last_seen_at":  gorm.Expr("GREATEST(deprecation_usages.last_seen_at, excluded.last_seen_at)"),
+			"updated_at":    gorm.Expr("now()"),
+		}),
+	}).Create(&usages).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to record deprecation usage", err).
+			WithOperation("record_deprecation_usage").
+			WithResource("deprecation_usage").
+			WithContext("usages", len(usages))
+	}
+
+	return nil
+}
+
+func (r *deprecationRepository) GetUsage() ([]models.DeprecationUsage, error) {
+	var usages []models.DeprecationUsage
+	if err := r.db.Order("last_seen_at desc").Find(&usages).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get deprecation usage", err).
+			WithOperation("get_deprecation_usage").
+			WithResource("deprecation_usage")
+	}
+
+	return usages, nil
+}
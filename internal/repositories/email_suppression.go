@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"strings"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailSuppressionRepository defines the data operations of the email addresses the
+// emails are no longer sent to
+type EmailSuppressionRepository interface {
+	// Suppress records the suppressions, replacing the reason of the addresses already
+	// suppressed
+	Suppress(suppressions []models.EmailSuppression) error
+	// GetSuppressed returns the addresses of emails that are suppressed, in lower case
+	GetSuppressed(emails []string) ([]string, error)
+}
+
+// emailSuppressionRepository implements EmailSuppressionRepository
+type emailSuppressionRepository struct {
+	abstractRepository[models.EmailSuppression]
+}
+
+// ProvideEmailSuppressionRepository creates a new email suppression repository
+func ProvideEmailSuppressionRepository(db *db.PostgresDB) EmailSuppressionRepository {
+	return &emailSuppressionRepository{
+		abstractRepository: abstractRepository[models.EmailSuppression]{db: db},
+	}
+}
+
+func (r *emailSuppressionRepository) Suppress(suppressions []models.EmailSuppression) error {
+	if len(suppressions) == 0 {
+		return nil
+	}
+
+	for i := range suppressions {
+		suppressions[i].Email = strings.ToLower(suppressions[i].Email)
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "email"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"reason":     gorm.Expr("excluded.reason"),
+			"detail":     gorm.Expr("excluded.detail"),
+			"message_id": gorm.Expr("excluded.message_id"),
+			"updated_at": gorm.Expr("now()"),
+			"deleted_at": nil,
+		}),
+	}).Create(&suppressions).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to suppress email addresses", err).
+			WithOperation("suppress_emails").
+			WithResource("email_suppression").
+			WithContext("suppressions", len(suppressions))
+	}
+
+	return nil
+}
+
+func (r *emailSuppressionRepository) GetSuppressed(emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	var suppressed []string
+	err := r.db.Model(&models.EmailSuppression{}).
+		Where("email IN ?", lowered).
+		Pluck("email", &suppressed).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get suppressed email addresses", err).
+			WithOperation("get_suppressed_emails").
+			WithResource("email_suppression").
+			WithContext("emails", len(emails))
+	}
+
+	return suppressed, nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+)
+
+// ExportRepository defines the data operations of the export audit records
+type ExportRepository interface {
+	Create(audit *models.ExportAudit) error
+	// Complete saves the outcome of an export
+	Complete(audit *models.ExportAudit) error
+	// Get returns the audit records matching the filters, most recent first
+	Get(pr *dtos.ExportAuditPageableRequest) (*dtos.DataResponse[models.ExportAudit], error)
+}
+
+// exportRepository implements ExportRepository
+type exportRepository struct {
+	abstractRepository[models.ExportAudit]
+}
+
+// ProvideExportRepository creates a new export repository
+func ProvideExportRepository(db *db.PostgresDB) ExportRepository {
+	return &exportRepository{
+		abstractRepository: abstractRepository[models.ExportAudit]{db: db},
+	}
+}
+
+func (r *exportRepository) Create(audit *models.ExportAudit) error {
+	if err := r.db.Create(audit).Error; err != nil {
+		return errors.DatabaseError("Failed to create export audit record", err).
+			WithOperation("create_export_audit").
+			WithResource("export_audit").
+			WithContext("entity", audit.Entity).
+			WithContext("principal_id", audit.PrincipalID)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) Complete(audit *models.ExportAudit) error {
+	err := r.db.Model(&models.ExportAudit{}).
+		Where("id = ?", audit.ID).
+		Updates(map[string]any{
+			"status":       audit.Status,
+			"rows":         audit.Rows,
+			"error":        audit.Error,
+			"completed_at": audit.CompletedAt,
+		}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to complete export audit record", err).
+			WithOperation("complete_export_audit").
+			WithResource("export_audit").
+			WithContext("export_id", audit.ID)
+	}
+
+	return nil
+}
+
+func (r *exportRepository) Get(pr *dtos.ExportAuditPageableRequest) (*dtos.DataResponse[models.ExportAudit], error) {
+	query := r.db.DB
+
+	if pr.Entity != "" {
+		query = query.Where("entity = ?", pr.Entity)
+	}
+
+	if pr.PrincipalID != "" {
+		query = query.Where("principal_id = ?", pr.PrincipalID)
+	}
+
+	if pr.CompanyID != "" {
+		query = query.Where("company_id = ?", pr.CompanyID)
+	}
+
+	if pr.Status != "" {
+		query = query.Where("status = ?", pr.Status)
+	}
+
+	if pr.StartDate != nil {
+		query = query.Where("created_at >= ?", pr.StartDate)
+	}
+
+	if pr.EndDate != nil {
+		query = query.Where("created_at <= ?", pr.EndDate)
+	}
+
+	query = query.Order("created_at desc")
+
+	result, err := r.find(query, &pr.PageableRequest)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get export audit records", err).
+			WithOperation("get_export_audits").
+			WithResource("export_audit").
+			WithContext("pageable_request", pr)
+	}
+
+	return result, nil
+}
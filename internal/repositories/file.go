@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// FileRepository defines the data operations of the file registry
+type FileRepository interface {
+	Create(file *models.File) error
+	GetByID(id string) (*models.File, error)
+	GetByKey(key string) (*models.File, error)
+	// List returns the files matching the filters, most recent first
+	List(pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error)
+	// UpdateStatus moves the file to its Status, StatusReason and StatusChangedAt when it
+	// still has the from status, and reports whether it did, so that two concurrent
+	// transitions cannot both apply
+	UpdateStatus(file *models.File, from string) (bool, error)
+	// UpdateContent records the Size and Checksum of a file whose object was uploaded
+	// straight to the storage
+	UpdateContent(file *models.File) error
+	Delete(file *models.File) error
+}
+
+// fileRepository implements FileRepository
+type fileRepository struct {
+	abstractRepository[models.File]
+}
+
+// ProvideFileRepository creates a new file repository
+func ProvideFileRepository(db *db.PostgresDB) FileRepository {
+	return &fileRepository{
+		abstractRepository: abstractRepository[models.File]{db: db},
+	}
+}
+
+func (r *fileRepository) Create(file *models.File) error {
+	if err := r.db.Create(file).Error; err != nil {
+		return errors.DatabaseError("Failed to create file", err).
+			WithOperation("create_file").
+			WithResource("file").
+			WithContext("key", file.Key)
+	}
+
+	return nil
+}
+
+func (r *fileRepository) GetByID(id string) (*models.File, error) {
+	file := &models.File{}
+	if err := r.db.Where("id = ?", id).First(file).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("File", err).
+				WithOperation("get_file").
+				WithResource("file").
+				WithContext("file_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get file", err).
+			WithOperation("get_file").
+			WithResource("file").
+			WithContext("file_id", id)
+	}
+
+	return file, nil
+}
+
+func (r *fileRepository) GetByKey(key string) (*models.File, error) {
+	file := &models.File{}
+	if err := r.db.Where("key = ?", key).First(file).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("File", err).
+				WithOperation("get_file").
+				WithResource("file").
+				WithContext("key", key)
+		}
+		return nil, errors.DatabaseError("Failed to get file", err).
+			WithOperation("get_file").
+			WithResource("file").
+			WithContext("key", key)
+	}
+
+	return file, nil
+}
+
+func (r *fileRepository) List(pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error) {
+	query := r.db.DB
+
+	if pr.OwnerID != "" {
+		query = query.Where("owner_id = ?", pr.OwnerID)
+	}
+
+	if pr.Status != "" {
+		query = query.Where("status = ?", pr.Status)
+	}
+
+	if pr.Prefix != "" {
+		query = query.Where("starts_with(key, ?)", pr.Prefix)
+	}
+
+	query = query.Order("created_at desc").Order("id desc")
+
+	result, err := r.find(query, &pr.PageableRequest)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get files", err).
+			WithOperation("get_files").
+			WithResource("files").
+			WithContext("pageable_request", pr)
+	}
+
+	return result, nil
+}
+
+func (r *fileRepository) UpdateStatus(file *models.File, from string) (bool, error) {
+	result := r.db.Model(&models.File{}).
+		Where("id = ? AND status = ?", file.ID, from).
+		Updates(map[string]any{
+			"status":            file.Status,
+			"status_reason":     file.StatusReason,
+			"status_changed_at": file.StatusChangedAt,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return false, errors.DatabaseError("Failed to update file status", result.Error).
+			WithOperation("update_file_status").
+			WithResource("file").
+			WithContext("file_id", file.ID).
+			WithContext("status", file.Status)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *fileRepository) UpdateContent(file *models.File) error {
+	err := r.db.Model(&models.File{}).
+		Where("id = ?", file.ID).
+		Updates(map[string]any{
+			"size":       file.Size,
+			"checksum":   file.Checksum,
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to update file content", err).
+			WithOperation("update_file_content").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+
+	return nil
+}
+
+func (r *fileRepository) Delete(file *models.File) error {
+	if err := r.db.Delete(file).Error; err != nil {
+		return errors.DatabaseError("Failed to delete file", err).
+			WithOperation("delete_file").
+			WithResource("file").
+			WithContext("file_id", file.ID)
+	}
+
+	return nil
+}
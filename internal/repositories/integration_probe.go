@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+)
+
+// IntegrationProbeRepository defines the data operations of the probes of the integrations
+type IntegrationProbeRepository interface {
+	// Record saves the results of a round of probes
+	Record(probes []models.IntegrationProbe) error
+	// GetLatest returns the last probe of each integration, by integration name
+	GetLatest() ([]models.IntegrationProbe, error)
+	// GetStats summarizes the probes of each integration since since
+	GetStats(since time.Time) ([]dtos.IntegrationProbeStats, error)
+	// Purge deletes the probes checked before before
+	Purge(before time.Time) (int64, error)
+}
+
+// integrationProbeRepository implements IntegrationProbeRepository
+type integrationProbeRepository struct {
+	abstractRepository[models.IntegrationProbe]
+}
+
+// ProvideIntegrationProbeRepository creates a new integration probe repository
+func ProvideIntegrationProbeRepository(db *db.PostgresDB) IntegrationProbeRepository {
+	return &integrationProbeRepository{
+		abstractRepository: abstractRepository[models.IntegrationProbe]{db: db},
+	}
+}
+
+func (r *integrationProbeRepository) Record(probes []models.IntegrationProbe) error {
+	if len(probes) == 0 {
+		return nil
+	}
+
+	if err := r.db.Create(&probes).Error; err != nil {
+		return errors.DatabaseError("Failed to record integration probes", err).
+			WithOperation("record_integration_probes").
+			WithResource("integration_probe").
+			WithContext("probes", len(probes))
+	}
+
+	return nil
+}
+
+func (r *integrationProbeRepository) GetLatest() ([]models.IntegrationProbe, error) {
+	var probes []models.IntegrationProbe
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (integration) *
+		FROM integration_probes
+		WHERE deleted_at IS NULL
+		ORDER BY integration, checked_at DESC`).
+		Scan(&probes).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get latest integration probes", err).
+			WithOperation("get_latest_integration_probes").
+			WithResource("integration_probe")
+	}
+
+	return probes, nil
+}
+
+func (r *integrationProbeRepository) GetStats(since time.Time) ([]dtos.IntegrationProbeStats, error) {
+	var stats []dtos.IntegrationProbeStats
+	err := r.db.Model(&models.IntegrationProbe{}).
+		Select(`integration,
+			COUNT(*) AS probes,
+			COUNT(*) FILTER (WHERE status <> 'healthy') AS failures,
+			AVG(latency_ms) AS avg_latency_ms,
+			MAX(checked_at) FILTER (WHERE status <> 'healthy') AS last_failure_at`).
+		Where("checked_at >= ?", since).
+		Group("integration").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get integration probe stats", err).
+			WithOperation("get_integration_probe_stats").
+			WithResource("integration_probe").
+			WithContext("since", since)
+	}
+
+	return stats, nil
+}
+
+func (r *integrationProbeRepository) Purge(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("checked_at < ?", before).Delete(&models.IntegrationProbe{})
+	if result.Error != nil {
+		return 0, errors.DatabaseError("Failed to purge integration probes", result.Error).
+			WithOperation("purge_integration_probes").
+			WithResource("integration_probe").
+			WithContext("before", before)
+	}
+
+	return result.RowsAffected, nil
+}
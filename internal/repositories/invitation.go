@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InvitationRepository defines the data operations of the invitations to the companies
+type InvitationRepository interface {
+	Create(invitation *models.Invitation) error
+	Delete(invitation *models.Invitation) error
+	// GetByID returns an invitation of the company, so that an invitation id of another
+	// company is reported as not found
+	GetByID(companyID string, id string) (*models.Invitation, error)
+	// GetWithCompany returns the invitation with its company
+	GetWithCompany(id string) (*models.Invitation, error)
+	// FindPending returns the invitation of the email to the company that can still be
+	// accepted at now, nil when there is none
+	FindPending(companyID string, email string, now time.Time) (*models.Invitation, error)
+	GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invitation], error)
+	Revoke(invitation *models.Invitation) error
+	// Accept marks the invitation accepted by the user and makes the user a member of the
+	// company of the invitation, in one transaction, creating the user when create is set
+	// and linking its Keycloak id otherwise. It fails with a conflict when the invitation was accepted or revoked meanwhile.
+	Accept(invitation *models.Invitation, user *models.User, create bool) error
+}
+
+// invitationRepository implements InvitationRepository
+type invitationRepository struct {
+	abstractRepository[models.Invitation]
+}
+
+// ProvideInvitationRepository creates a new invitation repository
+func ProvideInvitationRepository(db *db.PostgresDB) InvitationRepository {
+	return &invitationRepository{
+		abstractRepository: abstractRepository[models.Invitation]{db: db},
+	}
+}
+
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	if err := r.db.Omit("Company").Create(invitation).Error; err != nil {
+		return errors.DatabaseError("Failed to create invitation", err).
+			WithOperation("create_invitation").
+			WithResource("invitation").
+			WithContext("company_id", invitation.CompanyID)
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) Delete(invitation *models.Invitation) error {
+	if err := r.db.Delete(invitation).Error; err != nil {
+		return errors.DatabaseError("Failed to delete invitation", err).
+			WithOperation("delete_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) GetByID(companyID string, id string) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(invitation).Error
+	if err != nil {
+		return nil, invitationError(err, "get_invitation", id)
+	}
+
+	return invitation, nil
+}
+
+func (r *invitationRepository) GetWithCompany(id string) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	err := r.db.Preload("Company").Where("id = ?", id).First(invitation).Error
+	if err != nil {
+		return nil, invitationError(err, "get_invitation_with_company", id)
+	}
+
+	return invitation, nil
+}
+
+func (r *invitationRepository) FindPending(companyID string, email string, now time.Time) (*models.Invitation, error) {
+	var invitations []models.Invitation
+	err := r.db.
+		Where("company_id = ? AND lower(email) = lower(?)", companyID, email).
+		Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now).
+		Limit(1).
+		Find(&invitations).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to find pending invitation", err).
+			WithOperation("find_pending_invitation").
+			WithResource("invitation").
+			WithContext("company_id", companyID)
+	}
+	if len(invitations) == 0 {
+		return nil, nil
+	}
+
+	return &invitations[0], nil
+}
+
+func (r *invitationRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invitation], error) {
+	query := r.db.DB.
+		Where("company_id = ?", companyID).
+		Order("created_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get invitations", err).
+			WithOperation("get_invitations").
+			WithResource("invitations").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) Revoke(invitation *models.Invitation) error {
+	err := r.db.Model(&models.Invitation{}).
+		Where("id = ?", invitation.ID).
+		Update("revoked_at", invitation.RevokedAt).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to revoke invitation", err).
+			WithOperation("revoke_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) Accept(invitation *models.Invitation, user *models.User, create bool) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if create {
+			if err := tx.Omit("Companies", "Roles").Create(user).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(user).Update("keycloak_id", user.KeycloakID).Error; err != nil {
+			return err
+		}
+
+		// The invitation is only accepted once, whatever the concurrent requests
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			Updates(map[string]any{
+				"accepted_at": invitation.AcceptedAt,
+				"user_id":     user.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.ConflictError("The invitation was already accepted or revoked", nil)
+		}
+
+		// Omit the company upsert; only the join row is written
+		return tx.Model(user).Omit("Companies.*").Association("Companies").Append(&invitation.Company)
+	})
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil {
+			return appErr.
+				WithOperation("accept_invitation").
+				WithResource("invitation").
+				WithContext("invitation_id", invitation.ID)
+		}
+		return errors.DatabaseError("Failed to accept invitation", err).
+			WithOperation("accept_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID).
+			WithContext("user_id", user.ID)
+	}
+
+	invitation.UserID = &user.ID
+	return nil
+}
+
+// invitationError classifies the error of a lookup of an invitation
+func invitationError(err error, operation string, id string) error {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NotFoundError("Invitation", err).
+			WithOperation(operation).
+			WithResource("invitation").
+			WithContext("invitation_id", id)
+	}
+	return errors.DatabaseError("Failed to get invitation", err).
+		WithOperation(operation).
+		WithResource("invitation").
+		WithContext("invitation_id", id)
+}
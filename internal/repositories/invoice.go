@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InvoiceRepository defines the data operations of the invoices of the companies
+type InvoiceRepository interface {
+	// Get returns an invoice of the company, so that an invoice id of another company is
+	// reported as not found
+	Get(companyID string, id string) (*models.Invoice, error)
+	// GetByProviderID returns the invoice synced from the invoice of the payment provider
+	GetByProviderID(providerInvoiceID string) (*models.Invoice, error)
+	// GetByCompany returns the invoices of the company, most recently issued first
+	GetByCompany(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invoice], error)
+	// Save creates or updates the invoice
+	Save(invoice *models.Invoice) error
+	// SetPDFKey sets the key of the PDF document of the invoice when it has none and no
+	// event changed it since lastEventAt, and reports whether it did, so that neither a
+	// concurrent render nor a stale document wins
+	SetPDFKey(id string, key string, lastEventAt time.Time) (bool, error)
+}
+
+// invoiceRepository implements InvoiceRepository
+type invoiceRepository struct {
+	abstractRepository[models.Invoice]
+}
+
+// ProvideInvoiceRepository creates a new invoice repository
+func ProvideInvoiceRepository(db *db.PostgresDB) InvoiceRepository {
+	return &invoiceRepository{
+		abstractRepository: abstractRepository[models.Invoice]{db: db},
+	}
+}
+
+func (r *invoiceRepository) Get(companyID string, id string) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(invoice).Error
+	if err != nil {
+		return nil, invoiceError(err, "get_invoice", "invoice_id", id)
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) GetByProviderID(providerInvoiceID string) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	err := r.db.Where("provider_invoice_id = ?", providerInvoiceID).First(invoice).Error
+	if err != nil {
+		return nil, invoiceError(err, "get_invoice_by_provider_id", "provider_invoice_id", providerInvoiceID)
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) GetByCompany(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invoice], error) {
+	query := r.db.DB.
+		Where("company_id = ?", companyID).
+		Order("issued_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get invoices", err).
+			WithOperation("get_invoices").
+			WithResource("invoices").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *invoiceRepository) Save(invoice *models.Invoice) error {
+	if err := r.db.Save(invoice).Error; err != nil {
+		return errors.DatabaseError("Failed to save invoice", err).
+			WithOperation("save_invoice").
+			WithResource("invoice").
+			WithContext("company_id", invoice.CompanyID).
+			WithContext("provider_invoice_id", invoice.ProviderInvoiceID)
+	}
+
+	return nil
+}
+
+func (r *invoiceRepository) SetPDFKey(id string, key string, lastEventAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Invoice{}).
+		Where("id = ? AND pdf_key = '' AND last_event_at = ?", id, lastEventAt).
+		Update("pdf_key", key)
+	if result.Error != nil {
+		return false, errors.DatabaseError("Failed to set invoice PDF", result.Error).
+			WithOperation("set_invoice_pdf_key").
+			WithResource("invoice").
+			WithContext("invoice_id", id)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func invoiceError(err error, operation string, key string, value string) error {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NotFoundError("Invoice", err).
+			WithOperation(operation).
+			WithResource("invoice").
+			WithContext(key, value)
+	}
+	return errors.DatabaseError("Failed to get invoice", err).
+		WithOperation(operation).
+		WithResource("invoice").
+		WithContext(key, value)
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+)
+
+// JobRepository defines the data operations of the task queue
+type JobRepository interface {
+	Create(job *models.Job) error
+	// Claim locks the next pending job of one of the kinds for the worker, nil when none is due
+	Claim(kinds []string, workerID string) (*models.Job, error)
+	Complete(job *models.Job) error
+	// Retry makes a failed job pending again from runAt
+	Retry(job *models.Job, runAt time.Time, lastError string) error
+	// Kill moves a failed job to the dead-letter queue
+	Kill(job *models.Job, lastError string) error
+	// RescueStale makes pending again the jobs locked before lockedBefore, left running by
+	// a worker that crashed, and returns their number
+	RescueStale(lockedBefore time.Time) (int64, error)
+	GetDead(pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error)
+	// GetDeadOfKind returns the dead jobs of a kind, most recently failed first
+	GetDeadOfKind(kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error)
+	// Requeue makes a dead job pending again with its attempts reset
+	Requeue(id string) (*models.Job, error)
+	// RequeueOfKind makes the dead jobs of a kind pending again with their attempts
+	// reset, only those of ids when given, and returns their number
+	RequeueOfKind(kind string, ids []string) (int64, error)
+}
+
+// jobRepository implements JobRepository
+type jobRepository struct {
+	abstractRepository[models.Job]
+}
+
+// ProvideJobRepository creates a new job repository
+func ProvideJobRepository(db *db.PostgresDB) JobRepository {
+	return &jobRepository{
+		abstractRepository: abstractRepository[models.Job]{db: db},
+	}
+}
+
+func (r *jobRepository) Create(job *models.Job) error {
+	if err := r.db.Create(job).Error; err != nil {
+		return errors.DatabaseError("Failed to enqueue job", err).
+			WithOperation("create_job").
+			WithResource("job").
+			WithContext("kind", job.Kind)
+	}
+
+	return nil
+}
+
+// Claim skips the rows locked by other workers, so that concurrent workers never wait on
+// each other nor claim the same job
+func (r *jobRepository) Claim(kinds []string, workerID string) (*models.Job, error) {
+	job := &models.Job{}
+	err := r.db.Raw(`UPDATE jobs
+		SET status = ?, attempt = attempt + 1, locked_at = now(), locked_by = ?, updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? AND run_at <= now() AND kind IN ? AND deleted_at IS NULL
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		constants.JobStatusRunning, workerID, constants.JobStatusPending, kinds,
+	).Scan(job).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to claim job", err).
+			WithOperation("claim_job").
+			WithResource("job").
+			WithContext("worker_id", workerID)
+	}
+	if job.ID == "" {
+		return nil, nil
+	}
+
+	return job, nil
+}
+
+func (r *jobRepository) Complete(job *models.Job) error {
+	return r.finish(job, "complete_job", map[string]any{
+		"status":      constants.JobStatusCompleted,
+		"finished_at": time.Now(),
+		"locked_at":   nil,
+		"locked_by":   nil,
+	})
+}
+
+func (r *jobRepository) Retry(job *models.Job, runAt time.Time, lastError string) error {
+	return r.finish(job, "retry_job", map[string]any{
+		"status":     constants.JobStatusPending,
+		"run_at":     runAt,
+		"last_error": lastError,
+		"locked_at":  nil,
+		"locked_by":  nil,
+	})
+}
+
+func (r *jobRepository) Kill(job *models.Job, lastError string) error {
+	return r.finish(job, "kill_job", map[string]any{
+		"status":      constants.JobStatusDead,
+		"last_error":  lastError,
+		"finished_at": time.Now(),
+		"locked_at":   nil,
+		"locked_by":   nil,
+	})
+}
+
+// finish records the outcome of a running job
+func (r *jobRepository) finish(job *models.Job, operation string, updates map[string]any) error {
+	err := r.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", job.ID, constants.JobStatusRunning).
+		Updates(updates).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to update job", err).
+			WithOperation(operation).
+			WithResource("job").
+			WithContext("job_id", job.ID).
+			WithContext("kind", job.Kind)
+	}
+
+	return nil
+}
+
+func (r *jobRepository) RescueStale(lockedBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.Job{}).
+		Where("status = ? AND locked_at < ?", constants.JobStatusRunning, lockedBefore).
+		Updates(map[string]any{
+			"status":    constants.JobStatusPending,
+			"run_at":    time.Now(),
+			"locked_at": nil,
+			"locked_by": nil,
+		})
+	if result.Error != nil {
+		return 0, errors.DatabaseError("Failed to rescue stale jobs", result.Error).
+			WithOperation("rescue_stale_jobs").
+			WithResource("job")
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *jobRepository) GetDead(pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	query := r.db.DB.
+		Where("status = ?", constants.JobStatusDead).
+		Order("finished_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get dead jobs", err).
+			WithOperation("get_dead_jobs").
+			WithResource("jobs")
+	}
+
+	return result, nil
+}
+
+func (r *jobRepository) GetDeadOfKind(kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	query := r.db.DB.
+		Where("status = ? AND kind = ?", constants.JobStatusDead, kind).
+		Order("finished_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get dead jobs", err).
+			WithOperation("get_dead_jobs").
+			WithResource("jobs").
+			WithContext("kind", kind)
+	}
+
+	return result, nil
+}
+
+func (r *jobRepository) Requeue(id string) (*models.Job, error) {
+	result := r.db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, constants.JobStatusDead).
+		Updates(map[string]any{
+			"status":      constants.JobStatusPending,
+			"attempt":     0,
+			"run_at":      time.Now(),
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		return nil, errors.DatabaseError("Failed to requeue job", result.Error).
+			WithOperation("requeue_job").
+			WithResource("job").
+			WithContext("job_id", id)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.NotFoundError("Dead job", nil).
+			WithOperation("requeue_job").
+			WithResource("job").
+			WithContext("job_id", id)
+	}
+
+	job := &models.Job{}
+	if err := r.db.First(job, "id = ?", id).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get job", err).
+			WithOperation("requeue_job").
+			WithResource("job").
+			WithContext("job_id", id)
+	}
+
+	return job, nil
+}
+
+func (r *jobRepository) RequeueOfKind(kind string, ids []string) (int64, error) {
+	query := r.db.Model(&models.Job{}).
+		Where("status = ? AND kind = ?", constants.JobStatusDead, kind)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	result := query.Updates(map[string]any{
+		"status":      constants.JobStatusPending,
+		"attempt":     0,
+		"run_at":      time.Now(),
+		"finished_at": nil,
+	})
+	if result.Error != nil {
+		return 0, errors.DatabaseError("Failed to requeue jobs", result.Error).
+			WithOperation("requeue_jobs").
+			WithResource("job").
+			WithContext("kind", kind)
+	}
+
+	return result.RowsAffected, nil
+}
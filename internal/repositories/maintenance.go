@@ -0,0 +1,229 @@
+package repositories
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceRepository defines the data operations of the maintenance tasks and of the
+// maintenance operations they run
+type MaintenanceRepository interface {
+	Create(task *models.MaintenanceTask) error
+	GetOneByID(id string) (*models.MaintenanceTask, error)
+	// Get returns the tasks, most recently started first
+	Get(pr *dtos.PageableRequest) (*dtos.DataResponse[models.MaintenanceTask], error)
+	// GetActive returns the task of the operation that is running, paused or failed, or nil
+	GetActive(operation string) (*models.MaintenanceTask, error)
+	// Transition writes the columns of the task, its status included, when it is in one of
+	// the from statuses, and reports whether it was
+	Transition(task *models.MaintenanceTask, from []string, columns ...string) (bool, error)
+	// SaveRun writes the columns of the task when it is still running its generation, and
+	// reports whether it was: false when it was paused, cancelled or resumed meanwhile
+	SaveRun(task *models.MaintenanceTask, columns ...string) (bool, error)
+
+	// Reindex rebuilds an index without blocking the writes to its table
+	Reindex(index string) error
+	CountTenantCredentials() (int64, error)
+	// GetTenantCredentialsAfter returns the next limit credentials by ID after afterID, or
+	// from the first one when it is empty
+	GetTenantCredentialsAfter(afterID string, limit int) ([]models.TenantCredential, error)
+	CountWebhookEndpoints() (int64, error)
+	// GetWebhookEndpointsAfter returns the next limit endpoints by ID after afterID, or from
+	// the first one when it is empty
+	GetWebhookEndpointsAfter(afterID string, limit int) ([]models.WebhookEndpoint, error)
+	// UpdateWebhookSecret writes the sealed signing secret of an endpoint only, leaving the
+	// settings its company may change meanwhile
+	UpdateWebhookSecret(endpoint *models.WebhookEndpoint) error
+}
+
+// maintenanceRepository implements MaintenanceRepository
+type maintenanceRepository struct {
+	abstractRepository[models.MaintenanceTask]
+}
+
+// ProvideMaintenanceRepository creates a new maintenance repository
+func ProvideMaintenanceRepository(db *db.PostgresDB) MaintenanceRepository {
+	return &maintenanceRepository{
+		abstractRepository: abstractRepository[models.MaintenanceTask]{db: db},
+	}
+}
+
+func (r *maintenanceRepository) Create(task *models.MaintenanceTask) error {
+	if err := r.db.Create(task).Error; err != nil {
+		return errors.DatabaseError("Failed to create maintenance task", err).
+			WithOperation("create_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("operation", task.Operation)
+	}
+
+	return nil
+}
+
+func (r *maintenanceRepository) GetOneByID(id string) (*models.MaintenanceTask, error) {
+	task, err := r.FindOneByID(id)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Maintenance task", err).
+				WithOperation("get_maintenance_task").
+				WithResource("maintenance_task").
+				WithContext("task_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get maintenance task", err).
+			WithOperation("get_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("task_id", id)
+	}
+
+	return task, nil
+}
+
+func (r *maintenanceRepository) Get(pr *dtos.PageableRequest) (*dtos.DataResponse[models.MaintenanceTask], error) {
+	result, err := r.find(r.db.Order("started_at desc, id desc"), pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get maintenance tasks", err).
+			WithOperation("get_maintenance_tasks").
+			WithResource("maintenance_task")
+	}
+
+	return result, nil
+}
+
+func (r *maintenanceRepository) GetActive(operation string) (*models.MaintenanceTask, error) {
+	var tasks []models.MaintenanceTask
+	err := r.db.Where("operation = ? AND status IN ?", operation, constants.MaintenanceActiveStatuses).
+		Order("started_at desc").
+		Limit(1).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get active maintenance task", err).
+			WithOperation("get_active_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("operation", operation)
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	return &tasks[0], nil
+}
+
+func (r *maintenanceRepository) Transition(task *models.MaintenanceTask, from []string, columns ...string) (bool, error) {
+	result := r.db.Model(task).
+		Where("status IN ?", from).
+		Select(append(columns, "status", "updated_at")).
+		Updates(task)
+	if result.Error != nil {
+		return false, errors.DatabaseError("Failed to update maintenance task", result.Error).
+			WithOperation("transition_maintenance_task").
+			WithResource("maintenance_task").
+			WithContext("task_id", task.ID).
+			WithContext("status", task.Status)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *maintenanceRepository) SaveRun(task *models.MaintenanceTask, columns ...string) (bool, error) {
+	result := r.db.Model(task).
+		Where("generation = ? AND status = ?", task.Generation, constants.MaintenanceStatusRunning).
+		Select(append(columns, "updated_at")).
+		Updates(task)
+	if result.Error != nil {
+		return false, errors.DatabaseError("Failed to save maintenance task progress", result.Error).
+			WithOperation("save_maintenance_task_run").
+			WithResource("maintenance_task").
+			WithContext("task_id", task.ID)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *maintenanceRepository) Reindex(index string) error {
+	// REINDEX cannot take a bind parameter; the names come from
+	// constants.MaintenanceSearchIndexes
+	if err := r.db.Exec(fmt.Sprintf(`REINDEX INDEX CONCURRENTLY %q`, index)).Error; err != nil {
+		return errors.DatabaseError("Failed to rebuild index", err).
+			WithOperation("reindex").
+			WithResource("maintenance_task").
+			WithContext("index", index)
+	}
+
+	return nil
+}
+
+func (r *maintenanceRepository) CountTenantCredentials() (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.TenantCredential{}).Count(&count).Error; err != nil {
+		return 0, errors.DatabaseError("Failed to count tenant credentials", err).
+			WithOperation("count_tenant_credentials").
+			WithResource("tenant_credential")
+	}
+
+	return count, nil
+}
+
+func (r *maintenanceRepository) GetTenantCredentialsAfter(afterID string, limit int) ([]models.TenantCredential, error) {
+	credentials := []models.TenantCredential{}
+	if err := r.after(afterID, limit).Find(&credentials).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get tenant credentials", err).
+			WithOperation("get_tenant_credentials_after").
+			WithResource("tenant_credential").
+			WithContext("after_id", afterID)
+	}
+
+	return credentials, nil
+}
+
+func (r *maintenanceRepository) CountWebhookEndpoints() (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.WebhookEndpoint{}).Count(&count).Error; err != nil {
+		return 0, errors.DatabaseError("Failed to count webhook endpoints", err).
+			WithOperation("count_webhook_endpoints").
+			WithResource("webhook_endpoint")
+	}
+
+	return count, nil
+}
+
+func (r *maintenanceRepository) GetWebhookEndpointsAfter(afterID string, limit int) ([]models.WebhookEndpoint, error) {
+	endpoints := []models.WebhookEndpoint{}
+	if err := r.after(afterID, limit).Find(&endpoints).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get webhook endpoints", err).
+			WithOperation("get_webhook_endpoints_after").
+			WithResource("webhook_endpoint").
+			WithContext("after_id", afterID)
+	}
+
+	return endpoints, nil
+}
+
+func (r *maintenanceRepository) UpdateWebhookSecret(endpoint *models.WebhookEndpoint) error {
+	result := r.db.Model(endpoint).
+		Select("key_id", "encrypted_key", "nonce", "ciphertext", "updated_at").
+		Updates(endpoint)
+	if result.Error != nil {
+		return errors.DatabaseError("Failed to update webhook secret", result.Error).
+			WithOperation("update_webhook_secret").
+			WithResource("webhook_endpoint").
+			WithContext("webhook_id", endpoint.ID)
+	}
+
+	return nil
+}
+
+// after pages through a table by ID, the IDs being UUIDv7 ordered by creation
+func (r *maintenanceRepository) after(afterID string, limit int) *gorm.DB {
+	query := r.db.Order("id asc").Limit(limit)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	return query
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OnboardingRepository defines the data operations of the onboarding checklist
+type OnboardingRepository interface {
+	GetOverrides(companyID string) ([]models.OnboardingOverride, error)
+	// SaveOverride creates or replaces the override of the step of the company
+	SaveOverride(override *models.OnboardingOverride) error
+	DeleteOverride(companyID string, step string) error
+	// CountMembers returns the number of users of the company
+	CountMembers(companyID string) (int64, error)
+	// HasPaymentCustomer reports whether a user of the company has a Stripe customer
+	HasPaymentCustomer(companyID string) (bool, error)
+	// HasActiveWebhook reports whether the company has an active webhook endpoint
+	HasActiveWebhook(companyID string) (bool, error)
+}
+
+// onboardingRepository implements OnboardingRepository
+type onboardingRepository struct {
+	abstractRepository[models.OnboardingOverride]
+}
+
+// ProvideOnboardingRepository creates a new onboarding repository
+func ProvideOnboardingRepository(db *db.PostgresDB) OnboardingRepository {
+	return &onboardingRepository{
+		abstractRepository: abstractRepository[models.OnboardingOverride]{db: db},
+	}
+}
+
+func (r *onboardingRepository) GetOverrides(companyID string) ([]models.OnboardingOverride, error) {
+	var overrides []models.OnboardingOverride
+	if err := r.db.Where("company_id = ?", companyID).Find(&overrides).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get onboarding overrides", err).
+			WithOperation("get_onboarding_overrides").
+			WithResource("onboarding_override").
+			WithContext("company_id", companyID)
+	}
+
+	return overrides, nil
+}
+
+func (r *onboardingRepository) SaveOverride(override *models.OnboardingOverride) error {
+	err := r.db.Omit("Company").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "company_id"}, {Name: "step"}},
+		DoUpdates: clause.AssignmentColumns([]string{"completed", "updated_by", "updated_at"}),
+	}).Create(override).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to save onboarding override", err).
+			WithOperation("save_onboarding_override").
+			WithResource("onboarding_override").
+			WithContext("company_id", override.CompanyID).
+			WithContext("step", override.Step)
+	}
+
+	return nil
+}
+
+// DeleteOverride removes the row, so that the step can be overridden again
+func (r *onboardingRepository) DeleteOverride(companyID string, step string) error {
+	err := r.db.Unscoped().
+		Where("company_id = ? AND step = ?", companyID, step).
+		Delete(&models.OnboardingOverride{}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to delete onboarding override", err).
+			WithOperation("delete_onboarding_override").
+			WithResource("onboarding_override").
+			WithContext("company_id", companyID).
+			WithContext("step", step)
+	}
+
+	return nil
+}
+
+func (r *onboardingRepository) CountMembers(companyID string) (int64, error) {
+	var count int64
+	if err := r.members(companyID).Count(&count).Error; err != nil {
+		return 0, errors.DatabaseError("Failed to count company members", err).
+			WithOperation("count_company_members").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return count, nil
+}
+
+func (r *onboardingRepository) HasPaymentCustomer(companyID string) (bool, error) {
+	var count int64
+	err := r.members(companyID).
+		Where("users.stripe_customer_id IS NOT NULL AND users.stripe_customer_id <> ''").
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.DatabaseError("Failed to check company payment customer", err).
+			WithOperation("check_company_payment_customer").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return count > 0, nil
+}
+
+func (r *onboardingRepository) HasActiveWebhook(companyID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.WebhookEndpoint{}).
+		Where("company_id = ? AND active", companyID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.DatabaseError("Failed to check company webhooks", err).
+			WithOperation("check_company_webhooks").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return count > 0, nil
+}
+
+// members selects the users of the company that are not deleted
+func (r *onboardingRepository) members(companyID string) *gorm.DB {
+	return r.db.Table("user_companies").
+		Joins("JOIN users ON users.id = user_companies.user_id AND users.deleted_at IS NULL").
+		Where("user_companies.company_id = ?", companyID)
+}
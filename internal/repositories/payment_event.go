@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// PaymentEventRepository defines the data operations of the events received from the
+// payment providers
+type PaymentEventRepository interface {
+	// Record stores the event and reports whether it is new, false when the provider
+	// already delivered it
+	Record(event *models.PaymentEvent) (bool, error)
+	// Delete removes an event whose dispatch failed, so that its next delivery is
+	// dispatched
+	Delete(provider string, eventID string) error
+}
+
+// paymentEventRepository implements PaymentEventRepository
+type paymentEventRepository struct {
+	abstractRepository[models.PaymentEvent]
+}
+
+// ProvidePaymentEventRepository creates a new payment event repository
+func ProvidePaymentEventRepository(db *db.PostgresDB) PaymentEventRepository {
+	return &paymentEventRepository{
+		abstractRepository: abstractRepository[models.PaymentEvent]{db: db},
+	}
+}
+
+func (r *paymentEventRepository) Record(event *models.PaymentEvent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(event)
+	if result.Error != nil {
+		return false, errors.DatabaseError("Failed to record payment event", result.Error).
+			WithOperation("record_payment_event").
+			WithResource("payment_event").
+			WithContext("provider", event.Provider).
+			WithContext("event_id", event.EventID)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentEventRepository) Delete(provider string, eventID string) error {
+	err := r.db.Unscoped().
+		Where("provider = ? AND event_id = ?", provider, eventID).
+		Delete(&models.PaymentEvent{}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to delete payment event", err).
+			WithOperation("delete_payment_event").
+			WithResource("payment_event").
+			WithContext("provider", provider).
+			WithContext("event_id", eventID)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+)
+
+// PaymentOperationRepository defines the data operations of the records of the refunds,
+// captures and voids of the payments
+type PaymentOperationRepository interface {
+	Create(operation *models.PaymentOperation) error
+	// Complete saves the outcome of an operation
+	Complete(operation *models.PaymentOperation) error
+	// GetByPaymentID returns the operations on a payment, most recent first
+	GetByPaymentID(paymentID string) ([]models.PaymentOperation, error)
+}
+
+// paymentOperationRepository implements PaymentOperationRepository
+type paymentOperationRepository struct {
+	abstractRepository[models.PaymentOperation]
+}
+
+// ProvidePaymentOperationRepository creates a new payment operation repository
+func ProvidePaymentOperationRepository(db *db.PostgresDB) PaymentOperationRepository {
+	return &paymentOperationRepository{
+		abstractRepository: abstractRepository[models.PaymentOperation]{db: db},
+	}
+}
+
+func (r *paymentOperationRepository) Create(operation *models.PaymentOperation) error {
+	if err := r.db.Create(operation).Error; err != nil {
+		return errors.DatabaseError("Failed to create payment operation record", err).
+			WithOperation("create_payment_operation").
+			WithResource("payment_operation").
+			WithContext("payment_id", operation.PaymentID).
+			WithContext("initiated_by", operation.InitiatedBy)
+	}
+
+	return nil
+}
+
+func (r *paymentOperationRepository) Complete(operation *models.PaymentOperation) error {
+	err := r.db.Model(&models.PaymentOperation{}).
+		Where("id = ?", operation.ID).
+		Updates(map[string]any{
+			"status":             operation.Status,
+			"processed_amount":   operation.ProcessedAmount,
+			"currency":           operation.Currency,
+			"provider_object_id": operation.ProviderObjectID,
+			"provider_status":    operation.ProviderStatus,
+			"error":              operation.Error,
+			"completed_at":       operation.CompletedAt,
+		}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to complete payment operation record", err).
+			WithOperation("complete_payment_operation").
+			WithResource("payment_operation").
+			WithContext("payment_operation_id", operation.ID)
+	}
+
+	return nil
+}
+
+func (r *paymentOperationRepository) GetByPaymentID(paymentID string) ([]models.PaymentOperation, error) {
+	var operations []models.PaymentOperation
+	if err := r.db.Where("payment_id = ?", paymentID).Order("created_at desc").Find(&operations).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get payment operations", err).
+			WithOperation("get_payment_operations").
+			WithResource("payment_operation").
+			WithContext("payment_id", paymentID)
+	}
+
+	return operations, nil
+}
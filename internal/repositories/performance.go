@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PerformanceRepository defines the data operations of the tenant performance metrics
+type PerformanceRepository interface {
+	// Record adds the requests and durations of the metrics to the hourly counters
+	Record(metrics []models.TenantRequestMetric) error
+	// Get returns the metrics of the tenants since since, summed over the tenants, by
+	// hour, route, status class and bucket
+	Get(tenants []string, since time.Time) ([]models.TenantRequestMetric, error)
+	// Purge deletes the metrics of the hours before before
+	Purge(before time.Time) (int64, error)
+}
+
+// performanceRepository implements PerformanceRepository
+type performanceRepository struct {
+	abstractRepository[models.TenantRequestMetric]
+}
+
+// ProvidePerformanceRepository creates a new performance repository
+func ProvidePerformanceRepository(db *db.PostgresDB) PerformanceRepository {
+	return &performanceRepository{
+		abstractRepository: abstractRepository[models.TenantRequestMetric]{db: db},
+	}
+}
+
+func (r *performanceRepository) Record(metrics []models.TenantRequestMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant"}, {Name: "hour"}, {Name: "route"}, {Name: "status_class"}, {Name: "bucket_ms"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests":    gorm.Expr("tenant_request_metrics.requests + excluded.requests"),
+			"duration_ms": gorm.Expr("tenant_request_metrics.duration_ms + excluded.duration_ms"),
+			"updated_at":  gorm.Expr("now()"),
+		}),
+	}).Create(&metrics).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to record tenant performance metrics", err).
+			WithOperation("record_tenant_performance").
+			WithResource("tenant_request_metric").
+			WithContext("metrics", len(metrics))
+	}
+
+	return nil
+}
+
+func (r *performanceRepository) Get(tenants []string, since time.Time) ([]models.TenantRequestMetric, error) {
+	var metrics []models.TenantRequestMetric
+	err := r.db.Model(&models.TenantRequestMetric{}).
+		Select("hour, route, status_class, bucket_ms, SUM(requests) AS requests, SUM(duration_ms) AS duration_ms").
+		Where("tenant IN ? AND hour >= ?", tenants, since).
+		Group("hour, route, status_class, bucket_ms").
+		Order("hour asc, route asc").
+		Scan(&metrics).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get tenant performance metrics", err).
+			WithOperation("get_tenant_performance").
+			WithResource("tenant_request_metric").
+			WithContext("tenants", tenants)
+	}
+
+	return metrics, nil
+}
+
+func (r *performanceRepository) Purge(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("hour < ?", before).Delete(&models.TenantRequestMetric{})
+	if result.Error != nil {
+		return 0, errors.DatabaseError("Failed to purge tenant performance metrics", result.Error).
+			WithOperation("purge_tenant_performance").
+			WithResource("tenant_request_metric")
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	stderrors "errors"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RBACRepository defines the data operations of the roles and permissions of the database
+// RBAC
+type RBACRepository interface {
+	// GetUserPermissions returns the names of the permissions granted to the user of the
+	// Keycloak ID by their roles
+	GetUserPermissions(keycloakID string) ([]string, error)
+	// ListRoles returns the roles with their permissions, by name
+	ListRoles() ([]models.Role, error)
+	// SaveRole creates the role of the name or replaces its description and permissions,
+	// creating the permissions missing from the database
+	SaveRole(role *models.Role, permissions []string) error
+	// GetUserWithRoles returns the user with their roles and the permissions of the roles
+	GetUserWithRoles(userID string) (*models.User, error)
+	// SetUserRoles replaces the roles of the user with the roles of the names
+	SetUserRoles(user *models.User, roles []string) error
+}
+
+// rbacRepository implements RBACRepository
+type rbacRepository struct {
+	abstractRepository[models.Role]
+}
+
+// ProvideRBACRepository creates a new RBAC repository
+func ProvideRBACRepository(db *db.PostgresDB) RBACRepository {
+	return &rbacRepository{
+		abstractRepository: abstractRepository[models.Role]{db: db},
+	}
+}
+
+func (r *rbacRepository) GetUserPermissions(keycloakID string) ([]string, error) {
+	permissions := []string{}
+	err := r.db.Raw(`
+		SELECT DISTINCT p.name
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN roles ro ON ro.id = ur.role_id AND ro.deleted_at IS NULL
+		JOIN role_permissions rp ON rp.role_id = ro.id
+		JOIN permissions p ON p.id = rp.permission_id AND p.deleted_at IS NULL
+		WHERE u.keycloak_id = ? AND u.deleted_at IS NULL
+		ORDER BY p.name`, keycloakID).
+		Scan(&permissions).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get user permissions", err).
+			WithOperation("get_user_permissions").
+			WithResource("permission").
+			WithContext("keycloak_id", keycloakID)
+	}
+
+	return permissions, nil
+}
+
+func (r *rbacRepository) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).Order("name").Find(&roles).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to list roles", err).
+			WithOperation("list_roles").
+			WithResource("role")
+	}
+
+	return roles, nil
+}
+
+func (r *rbacRepository) SaveRole(role *models.Role, permissions []string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		description := role.Description
+		if err := tx.Where("name = ?", role.Name).FirstOrCreate(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(role).Update("description", description).Error; err != nil {
+			return err
+		}
+
+		role.Permissions = []models.Permission{}
+		if len(permissions) > 0 {
+			missing := make([]models.Permission, 0, len(permissions))
+			for _, name := range permissions {
+				missing = append(missing, models.Permission{BaseModel: models.NewBaseModel(), Name: name})
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("name IN ?", permissions).Order("name").Find(&role.Permissions).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(role).Association("Permissions").Replace(role.Permissions)
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to save role", err).
+			WithOperation("save_role").
+			WithResource("role").
+			WithContext("role", role.Name)
+	}
+
+	return nil
+}
+
+func (r *rbacRepository) GetUserWithRoles(userID string) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Preload("Roles", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).Preload("Roles.Permissions").First(user, "id = ?", userID).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("User", err).
+				WithOperation("get_user_roles").
+				WithResource("user").
+				WithContext("user_id", userID)
+		}
+		return nil, errors.DatabaseError("Failed to get user roles", err).
+			WithOperation("get_user_roles").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	return user, nil
+}
+
+func (r *rbacRepository) SetUserRoles(user *models.User, roles []string) error {
+	found := []models.Role{}
+	if len(roles) > 0 {
+		if err := r.db.Where("name IN ?", roles).Find(&found).Error; err != nil {
+			return errors.DatabaseError("Failed to get roles", err).
+				WithOperation("set_user_roles").
+				WithResource("role").
+				WithContext("user_id", user.ID)
+		}
+	}
+	if len(found) != len(roles) {
+		return errors.NotFoundError("Role", nil).
+			WithOperation("set_user_roles").
+			WithResource("role").
+			WithContext("user_id", user.ID).
+			WithContext("roles", roles)
+	}
+
+	if err := r.db.Model(user).Association("Roles").Replace(found); err != nil {
+		return errors.DatabaseError("Failed to set user roles", err).
+			WithOperation("set_user_roles").
+			WithResource("user").
+			WithContext("user_id", user.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"time"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionRepository defines the data operations of the retention policies, the legal
+// holds and the purges enforcing the policies
+type RetentionRepository interface {
+	GetPolicies(companyID string) ([]models.RetentionPolicy, error)
+	// GetEnforceablePolicies returns the policies of the companies not on legal hold
+	GetEnforceablePolicies() ([]models.RetentionPolicy, error)
+	// SavePolicy creates or replaces the policy of the resource of the company
+	SavePolicy(policy *models.RetentionPolicy) error
+	DeletePolicy(companyID string, resource string) error
+	SetCompanyLegalHold(companyID string, hold models.LegalHold) error
+	SetAPIKeyLegalHold(keyID string, hold models.LegalHold) error
+	// PurgeAPIKeyUsage deletes the usage of the days before before of the keys of the
+	// company not on legal hold
+	PurgeAPIKeyUsage(companyID string, before time.Time) (int64, error)
+	// PurgeRevokedAPIKeys deletes the keys of the company revoked or expired before before
+	// and not on legal hold, with their usage
+	PurgeRevokedAPIKeys(companyID string, before time.Time) (int64, error)
+}
+
+// retentionRepository implements RetentionRepository
+type retentionRepository struct {
+	abstractRepository[models.RetentionPolicy]
+}
+
+// ProvideRetentionRepository creates a new retention repository
+func ProvideRetentionRepository(db *db.PostgresDB) RetentionRepository {
+	return &retentionRepository{
+		abstractRepository: abstractRepository[models.RetentionPolicy]{db: db},
+	}
+}
+
+func (r *retentionRepository) GetPolicies(companyID string) ([]models.RetentionPolicy, error) {
+	var policies []models.RetentionPolicy
+	if err := r.db.Where("company_id = ?", companyID).Find(&policies).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get retention policies", err).
+			WithOperation("get_retention_policies").
+			WithResource("retention_policy").
+			WithContext("company_id", companyID)
+	}
+
+	return policies, nil
+}
+
+// GetEnforceablePolicies also returns the policies of the deleted companies, whose data
+// is still purged
+func (r *retentionRepository) GetEnforceablePolicies() ([]models.RetentionPolicy, error) {
+	var policies []models.RetentionPolicy
+	err := r.db.
+		Joins("JOIN companies ON companies.id = retention_policies.company_id").
+		Where("companies.legal_hold_at IS NULL").
+		Order("retention_policies.company_id, retention_policies.resource").
+		Find(&policies).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get retention policies", err).
+			WithOperation("get_enforceable_retention_policies").
+			WithResource("retention_policy")
+	}
+
+	return policies, nil
+}
+
+func (r *retentionRepository) SavePolicy(policy *models.RetentionPolicy) error {
+	err := r.db.Omit("Company").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "company_id"}, {Name: "resource"}},
+		DoUpdates: clause.AssignmentColumns([]string{"days", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to save retention policy", err).
+			WithOperation("save_retention_policy").
+			WithResource("retention_policy").
+			WithContext("company_id", policy.CompanyID).
+			WithContext("resource", policy.Resource)
+	}
+
+	return nil
+}
+
+// DeletePolicy removes the row, so that a policy can be set again
+func (r *retentionRepository) DeletePolicy(companyID string, resource string) error {
+	err := r.db.Unscoped().
+		Where("company_id = ? AND resource = ?", companyID, resource).
+		Delete(&models.RetentionPolicy{}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to delete retention policy", err).
+			WithOperation("delete_retention_policy").
+			WithResource("retention_policy").
+			WithContext("company_id", companyID).
+			WithContext("resource", resource)
+	}
+
+	return nil
+}
+
+func (r *retentionRepository) SetCompanyLegalHold(companyID string, hold models.LegalHold) error {
+	if err := r.setLegalHold(&models.Company{}, companyID, hold); err != nil {
+		return errors.DatabaseError("Failed to update company legal hold", err).
+			WithOperation("set_company_legal_hold").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return nil
+}
+
+func (r *retentionRepository) SetAPIKeyLegalHold(keyID string, hold models.LegalHold) error {
+	if err := r.setLegalHold(&models.APIKey{}, keyID, hold); err != nil {
+		return errors.DatabaseError("Failed to update API key legal hold", err).
+			WithOperation("set_api_key_legal_hold").
+			WithResource("api_key").
+			WithContext("api_key_id", keyID)
+	}
+
+	return nil
+}
+
+// setLegalHold writes the hold columns only, so that a concurrent update of the entity
+// is not overwritten
+func (r *retentionRepository) setLegalHold(model any, id string, hold models.LegalHold) error {
+	return r.db.Model(model).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"legal_hold_at":     hold.LegalHoldAt,
+			"legal_hold_by":     hold.LegalHoldBy,
+			"legal_hold_reason": hold.LegalHoldReason,
+		}).Error
+}
+
+func (r *retentionRepository) PurgeAPIKeyUsage(companyID string, before time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("day < ?", before.Format(time.DateOnly)).
+		Where("api_key_id IN (?)", r.db.Unscoped().Model(&models.APIKey{}).
+			Select("id").
+			Where("company_id = ? AND legal_hold_at IS NULL", companyID)).
+		Delete(&models.APIKeyUsage{})
+	if result.Error != nil {
+		return 0, errors.DatabaseError("Failed to purge API key usage", result.Error).
+			WithOperation("purge_api_key_usage").
+			WithResource("api_key_usage").
+			WithContext("company_id", companyID)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *retentionRepository) PurgeRevokedAPIKeys(companyID string, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		err := tx.Unscoped().Model(&models.APIKey{}).
+			Where("company_id = ? AND legal_hold_at IS NULL", companyID).
+			Where("revoked_at < ? OR expires_at < ?", before, before).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("api_key_id IN ?", ids).Delete(&models.APIKeyUsage{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.APIKey{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, errors.DatabaseError("Failed to purge revoked API keys", err).
+			WithOperation("purge_revoked_api_keys").
+			WithResource("api_key").
+			WithContext("company_id", companyID)
+	}
+
+	return purged, nil
+}
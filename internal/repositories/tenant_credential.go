@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	stderrors "errors"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TenantCredentialRepository defines the data operations of the tenant credentials vault.
+// They run in the database tenant of ctx, see db.PostgresDB.TenantTransaction.
+type TenantCredentialRepository interface {
+	Create(ctx context.Context, credential *models.TenantCredential) error
+	GetByID(ctx context.Context, companyID string, id string) (*models.TenantCredential, error)
+	GetByProvider(ctx context.Context, companyID string, provider string, name string) (*models.TenantCredential, error)
+	GetByCompanyID(ctx context.Context, companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.TenantCredential], error)
+	Exists(ctx context.Context, companyID string, provider string, name string) (bool, error)
+	UpdateSecret(ctx context.Context, credential *models.TenantCredential) error
+	Delete(ctx context.Context, credential *models.TenantCredential) error
+}
+
+// tenantCredentialRepository implements TenantCredentialRepository
+type tenantCredentialRepository struct {
+	abstractRepository[models.TenantCredential]
+}
+
+// ProvideTenantCredentialRepository creates a new tenant credential repository
+func ProvideTenantCredentialRepository(db *db.PostgresDB) TenantCredentialRepository {
+	return &tenantCredentialRepository{
+		abstractRepository: abstractRepository[models.TenantCredential]{db: db},
+	}
+}
+
+func (r *tenantCredentialRepository) Create(ctx context.Context, credential *models.TenantCredential) error {
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Omit("Company").Create(credential).Error
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to create tenant credential", err).
+			WithOperation("create_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("company_id", credential.CompanyID)
+	}
+
+	return nil
+}
+
+// GetByID returns a credential of the company, so that a credential id of another
+// company is reported as not found
+func (r *tenantCredentialRepository) GetByID(ctx context.Context, companyID string, id string) (*models.TenantCredential, error) {
+	credential := &models.TenantCredential{}
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Where("company_id = ? AND id = ?", companyID, id).First(credential).Error
+	})
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Tenant credential", err).
+				WithOperation("get_tenant_credential").
+				WithResource("tenant_credential").
+				WithContext("company_id", companyID).
+				WithContext("credential_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get tenant credential", err).
+			WithOperation("get_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("company_id", companyID).
+			WithContext("credential_id", id)
+	}
+
+	return credential, nil
+}
+
+func (r *tenantCredentialRepository) GetByProvider(ctx context.Context, companyID string, provider string, name string) (*models.TenantCredential, error) {
+	credential := &models.TenantCredential{}
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Where("company_id = ? AND provider = ? AND name = ?", companyID, provider, name).First(credential).Error
+	})
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Tenant credential", err).
+				WithOperation("get_tenant_credential_by_provider").
+				WithResource("tenant_credential").
+				WithContext("company_id", companyID).
+				WithContext("provider", provider)
+		}
+		return nil, errors.DatabaseError("Failed to get tenant credential", err).
+			WithOperation("get_tenant_credential_by_provider").
+			WithResource("tenant_credential").
+			WithContext("company_id", companyID).
+			WithContext("provider", provider)
+	}
+
+	return credential, nil
+}
+
+func (r *tenantCredentialRepository) GetByCompanyID(ctx context.Context, companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.TenantCredential], error) {
+	var result *dtos.DataResponse[models.TenantCredential]
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		query := tx.
+			Where("company_id = ?", companyID).
+			Order("provider asc, name asc")
+
+		var err error
+		result, err = r.find(query, pr)
+		return err
+	})
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get tenant credentials", err).
+			WithOperation("get_tenant_credentials").
+			WithResource("tenant_credentials").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *tenantCredentialRepository) Exists(ctx context.Context, companyID string, provider string, name string) (bool, error) {
+	var count int64
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&models.TenantCredential{}).
+			Where("company_id = ? AND provider = ? AND name = ?", companyID, provider, name).
+			Count(&count).Error
+	})
+	if err != nil {
+		return false, errors.DatabaseError("Failed to check tenant credential", err).
+			WithOperation("check_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("company_id", companyID).
+			WithContext("provider", provider)
+	}
+
+	return count > 0, nil
+}
+
+// UpdateSecret writes the sealed secret, version and rotation time of a credential
+func (r *tenantCredentialRepository) UpdateSecret(ctx context.Context, credential *models.TenantCredential) error {
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Model(credential).
+			Select("key_id", "encrypted_key", "nonce", "ciphertext", "version", "rotated_at", "updated_at").
+			Updates(credential).Error
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to update tenant credential", err).
+			WithOperation("update_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("credential_id", credential.ID)
+	}
+
+	return nil
+}
+
+// Delete hard deletes a credential, so that no sealed secret is left behind
+func (r *tenantCredentialRepository) Delete(ctx context.Context, credential *models.TenantCredential) error {
+	err := r.db.TenantTransaction(ctx, func(tx *gorm.DB) error {
+		return tx.Unscoped().Delete(credential).Error
+	})
+	if err != nil {
+		return errors.DatabaseError("Failed to delete tenant credential", err).
+			WithOperation("delete_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("credential_id", credential.ID)
+	}
+
+	return nil
+}
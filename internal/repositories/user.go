@@ -1,22 +1,51 @@
 package repositories
 
 import (
+	stderrors "errors"
+
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/db"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/utils"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Create(user *models.User) (*models.User, error)
 	GetOneByID(id string, preloads ...string) (*models.User, error)
-	Update(user *models.User) error
+	// Update writes the user fields, adds the user to the added companies and removes it
+	// from the removed ones in one transaction; the other memberships are left untouched
+	Update(user *models.User, added []models.Company, removed []models.Company) error
 	Delete(user *models.User) error
 	Get(pr *dtos.UserPageableRequest, preloads ...string) (*dtos.DataResponse[models.User], error)
+	// GetAfter returns the next limit users matching the filters of pr, newest first,
+	// after the cursor; the sort and pagination of pr are ignored
+	GetAfter(pr *dtos.UserPageableRequest, after *dtos.Cursor, limit int, preloads ...string) ([]models.User, error)
+	// GetRange returns limit users from offset, oldest first, whose columns equal the
+	// filters whatever the case, and the count of all of them
+	GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.User, int64, error)
+	CreateInBatches(users []models.User, batchSize int) (int, error)
+	FindExistingEmails(emails []string) ([]string, error)
+	// GetByEmail returns the user with the email, whatever its case
+	GetByEmail(email string) (*models.User, error)
+	// GetByKeycloakID returns the user of a Keycloak user, the subject of its tokens
+	GetByKeycloakID(keycloakID string) (*models.User, error)
+	UpdateAvatar(id string, avatarKey string, avatarSize int64) error
+	UpdateColumns(user *models.User, columns ...string) error
+	AddCompany(user *models.User, company *models.Company) error
+	RemoveCompany(user *models.User, company *models.Company) error
+	GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.User], error)
+	// GetByCompanyIDAfter returns the next limit members of the company, newest first,
+	// after the cursor
+	GetByCompanyIDAfter(companyID string, after *dtos.Cursor, limit int) ([]models.User, error)
+	GetByCompanyIDs(companyIDs []string) (map[string][]models.User, error)
+	Search(q string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.UserSearchResult], error)
 }
 
 // userRepository implements UserRepository
@@ -64,26 +93,23 @@ func (r *userRepository) GetOneByID(id string, preloads ...string) (*models.User
 	return user, nil
 }
 
-func (r *userRepository) Update(user *models.User) error {
+func (r *userRepository) Update(user *models.User, added []models.Company, removed []models.Company) error {
 	// Use a transaction to ensure atomicity
 	err := r.db.Transaction(func(tx *gorm.DB) error {
-		// First update the user fields
-		result := tx.Updates(user)
+		// First update the user fields, without upserting the loaded companies
+		result := tx.Omit(clause.Associations).Updates(user)
 		if result.Error != nil {
 			return result.Error
 		}
 
-		// Save the companies we want to associate before clearing
-		companiesToAssociate := user.Companies
-
-		// Clear existing associations first
-		if err := tx.Model(user).Association("Companies").Clear(); err != nil {
-			return err
+		// Then apply the membership delta; only the join rows are written
+		if len(added) > 0 {
+			if err := tx.Model(user).Omit("Companies.*").Association("Companies").Append(added); err != nil {
+				return err
+			}
 		}
-
-		// Then add the new associations
-		if len(companiesToAssociate) > 0 {
-			if err := tx.Model(user).Association("Companies").Append(companiesToAssociate); err != nil {
+		if len(removed) > 0 {
+			if err := tx.Model(user).Association("Companies").Delete(removed); err != nil {
 				return err
 			}
 		}
@@ -123,19 +149,7 @@ func (r *userRepository) Get(pr *dtos.UserPageableRequest, preloads ...string) (
 		}
 	}
 
-	if pr.Q != "" {
-		query = query.Where("users.first_name LIKE ?", "%"+pr.Q+"%")
-		query = query.Or("users.last_name LIKE ?", "%"+pr.Q+"%")
-		query = query.Or("users.email LIKE ?", "%"+pr.Q+"%")
-	}
-
-	if pr.StartDate != nil {
-		query = query.Where("users.created_at >= ?", pr.StartDate)
-	}
-
-	if pr.EndDate != nil {
-		query = query.Where("users.created_at <= ?", pr.EndDate)
-	}
+	query = filterUsers(query, pr)
 
 	// Apply multiple sort criteria
 	if len(pr.Sort) > 0 {
@@ -169,3 +183,365 @@ func (r *userRepository) Get(pr *dtos.UserPageableRequest, preloads ...string) (
 
 	return result, nil
 }
+
+func (r *userRepository) GetAfter(pr *dtos.UserPageableRequest, after *dtos.Cursor, limit int, preloads ...string) ([]models.User, error) {
+	query := r.db.DB
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	users, err := r.findAfter(filterUsers(query, pr), "users", after, limit)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get users", err).
+			WithOperation("get_users_after").
+			WithResource("users").
+			WithContext("pageable_request", pr)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.User, int64, error) {
+	query := r.db.DB
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	users, total, err := r.findRange(query, "users", filters, offset, limit)
+	if err != nil {
+		return nil, 0, errors.DatabaseError("Failed to get users", err).
+			WithOperation("get_users_range").
+			WithResource("users").
+			WithContext("filters", filters)
+	}
+
+	return users, total, nil
+}
+
+// filterUsers applies the search and date filters of a user listing
+func filterUsers(query *gorm.DB, pr *dtos.UserPageableRequest) *gorm.DB {
+	if pr.Q != "" {
+		query = query.Where("users.first_name LIKE ?", "%"+pr.Q+"%")
+		query = query.Or("users.last_name LIKE ?", "%"+pr.Q+"%")
+		query = query.Or("users.email LIKE ?", "%"+pr.Q+"%")
+	}
+
+	if pr.StartDate != nil {
+		query = query.Where("users.created_at >= ?", pr.StartDate)
+	}
+
+	if pr.EndDate != nil {
+		query = query.Where("users.created_at <= ?", pr.EndDate)
+	}
+
+	return query
+}
+
+// CreateInBatches inserts users in chunks of batchSize, each chunk in its own transaction.
+// It returns the number of users persisted before the first failing batch.
+func (r *userRepository) CreateInBatches(users []models.User, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = len(users)
+	}
+
+	created := 0
+	for start := 0; start < len(users); start += batchSize {
+		end := min(start+batchSize, len(users))
+		batch := users[start:end]
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&batch).Error
+		})
+		if err != nil {
+			return created, errors.DatabaseError("Failed to import users", err).
+				WithOperation("import_users").
+				WithResource("user").
+				WithContext("batch_start", start).
+				WithContext("batch_size", len(batch))
+		}
+		created += len(batch)
+	}
+
+	return created, nil
+}
+
+// FindExistingEmails returns the subset of emails that already belong to a user
+func (r *userRepository) FindExistingEmails(emails []string) ([]string, error) {
+	existing := []string{}
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	err := r.db.Model(&models.User{}).
+		Where("LOWER(users.email) IN ?", emails).
+		Pluck("LOWER(users.email)", &existing).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to look up user emails", err).
+			WithOperation("find_existing_emails").
+			WithResource("user")
+	}
+
+	return existing, nil
+}
+
+// GetByEmail returns the user with the email, whatever its case
+func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Where("LOWER(users.email) = LOWER(?)", email).First(user).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("User", err).
+				WithOperation("get_user_by_email").
+				WithResource("user")
+		}
+		return nil, errors.DatabaseError("Failed to get user by email", err).
+			WithOperation("get_user_by_email").
+			WithResource("user")
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) GetByKeycloakID(keycloakID string) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Where("keycloak_id = ?", keycloakID).First(user).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("User", err).
+				WithOperation("get_user_by_keycloak_id").
+				WithResource("user").
+				WithContext("keycloak_id", keycloakID)
+		}
+		return nil, errors.DatabaseError("Failed to get user by Keycloak ID", err).
+			WithOperation("get_user_by_keycloak_id").
+			WithResource("user").
+			WithContext("keycloak_id", keycloakID)
+	}
+
+	return user, nil
+}
+
+// UpdateAvatar sets the avatar object key and size of a user without touching its
+// associations
+func (r *userRepository) UpdateAvatar(id string, avatarKey string, avatarSize int64) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]any{
+		"avatar_key":  avatarKey,
+		"avatar_size": avatarSize,
+	})
+	if result.Error != nil {
+		return errors.DatabaseError("Failed to update user avatar", result.Error).
+			WithOperation("update_user_avatar").
+			WithResource("user").
+			WithContext("user_id", id)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFoundError("User", gorm.ErrRecordNotFound).
+			WithOperation("update_user_avatar").
+			WithResource("user").
+			WithContext("user_id", id)
+	}
+
+	return nil
+}
+
+// UpdateColumns writes the given columns of a user, including zero values, without
+// touching its associations
+func (r *userRepository) UpdateColumns(user *models.User, columns ...string) error {
+	result := r.db.Model(user).Select(append(columns, "updated_at")).Updates(user)
+	if result.Error != nil {
+		return errors.DatabaseError("Failed to update user", result.Error).
+			WithOperation("update_user_columns").
+			WithResource("user").
+			WithContext("user_id", user.ID)
+	}
+
+	return nil
+}
+
+// AddCompany links a company to the user. Linking an existing member is a no-op.
+func (r *userRepository) AddCompany(user *models.User, company *models.Company) error {
+	// Omit the company upsert; only the join row is written
+	err := r.db.Model(user).Omit("Companies.*").Association("Companies").Append(company)
+	if err != nil {
+		return errors.DatabaseError("Failed to add company to user", err).
+			WithOperation("add_user_company").
+			WithResource("user").
+			WithContext("user_id", user.ID).
+			WithContext("company_id", company.ID)
+	}
+
+	return nil
+}
+
+// RemoveCompany unlinks a company from the user without deleting either record
+func (r *userRepository) RemoveCompany(user *models.User, company *models.Company) error {
+	err := r.db.Model(user).Association("Companies").Delete(company)
+	if err != nil {
+		return errors.DatabaseError("Failed to remove company from user", err).
+			WithOperation("remove_user_company").
+			WithResource("user").
+			WithContext("user_id", user.ID).
+			WithContext("company_id", company.ID)
+	}
+
+	return nil
+}
+
+// GetByCompanyID returns the users that are members of the given company
+func (r *userRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.User], error) {
+	query := r.db.DB.
+		Joins("JOIN user_companies ON user_companies.user_id = users.id").
+		Where("user_companies.company_id = ?", companyID).
+		Order("users.created_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get company members", err).
+			WithOperation("get_company_members").
+			WithResource("users").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *userRepository) GetByCompanyIDAfter(companyID string, after *dtos.Cursor, limit int) ([]models.User, error) {
+	query := r.db.DB.
+		Joins("JOIN user_companies ON user_companies.user_id = users.id").
+		Where("user_companies.company_id = ?", companyID)
+
+	users, err := r.findAfter(query, "users", after, limit)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get company members", err).
+			WithOperation("get_company_members_after").
+			WithResource("users").
+			WithContext("company_id", companyID)
+	}
+
+	return users, nil
+}
+
+// GetByCompanyIDs returns the members of each of the given companies, newest first, keyed
+// by company ID. Companies without members are absent from the map.
+func (r *userRepository) GetByCompanyIDs(companyIDs []string) (map[string][]models.User, error) {
+	members := make(map[string][]models.User, len(companyIDs))
+	if len(companyIDs) == 0 {
+		return members, nil
+	}
+
+	memberships, err := findMemberships(r.db.DB, "company_id", companyIDs)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get company memberships", err).
+			WithOperation("get_companies_members").
+			WithResource("users")
+	}
+	if len(memberships) == 0 {
+		return members, nil
+	}
+
+	userIDs := make([]string, len(memberships))
+	for i, membership := range memberships {
+		userIDs[i] = membership.UserID
+	}
+
+	users := []models.User{}
+	if err := r.db.Where("users.id IN ?", userIDs).Order("users.created_at desc").Find(&users).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get companies members", err).
+			WithOperation("get_companies_members").
+			WithResource("users")
+	}
+
+	companiesByUser := groupMemberships(memberships, func(m membership) (string, string) { return m.UserID, m.CompanyID })
+	for _, user := range users {
+		for _, companyID := range companiesByUser[user.ID] {
+			members[companyID] = append(members[companyID], user)
+		}
+	}
+
+	return members, nil
+}
+
+// Search runs a prefix full-text search of q over the users search vector, which covers
+// the first name, last name and email. Results are ordered by rank, then newest first,
+// and carry the matched text with highlighted words. A q without any word matches nothing.
+func (r *userRepository) Search(q string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.UserSearchResult], error) {
+	results := []models.UserSearchResult{}
+
+	tsQuery := utils.PrefixTSQuery(q, constants.UserSearchMaxTerms)
+	if tsQuery == "" {
+		response := &dtos.DataResponse[models.UserSearchResult]{Data: results}
+		if pr.ShouldPaginate() {
+			response.Pageable = &dtos.Pageable{Page: pr.Page, PageSize: pr.PageSize}
+		}
+		return response, nil
+	}
+
+	query := r.db.Model(&models.User{}).
+		Where("users.search_vector @@ to_tsquery(?::regconfig, ?)", constants.UserSearchConfig, tsQuery)
+
+	var total int64
+	if pr.ShouldPaginate() {
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, errors.DatabaseError("Failed to count matching users", err).
+				WithOperation("search_users").
+				WithResource("users").
+				WithContext("q", q)
+		}
+		if total == 0 {
+			return &dtos.DataResponse[models.UserSearchResult]{
+				Data:     results,
+				Pageable: &dtos.Pageable{Page: pr.Page, PageSize: pr.PageSize},
+			}, nil
+		}
+		query = query.Limit(pr.GetLimit()).Offset(pr.GetOffset())
+	}
+
+	err := query.
+		Select(`users.*,
+			ts_rank(users.search_vector, to_tsquery(?::regconfig, ?)) AS rank,
+			ts_headline(?::regconfig, concat_ws(' ', users.first_name, users.last_name, users.email), to_tsquery(?::regconfig, ?), ?) AS highlight`,
+			constants.UserSearchConfig, tsQuery,
+			constants.UserSearchConfig, constants.UserSearchConfig, tsQuery, constants.UserSearchHeadlineOptions).
+		Order("rank desc, users.created_at desc").
+		Find(&results).Error
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to search users", err).
+			WithOperation("search_users").
+			WithResource("users").
+			WithContext("q", q)
+	}
+
+	response := &dtos.DataResponse[models.UserSearchResult]{Data: results}
+	if pr.ShouldPaginate() {
+		response.Pageable = &dtos.Pageable{Page: pr.Page, PageSize: pr.PageSize, Total: total}
+	}
+
+	return response, nil
+}
+
+// membership is a row of the user_companies join table
+type membership struct {
+	UserID    string
+	CompanyID string
+}
+
+// findMemberships returns the user_companies rows whose column is one of ids
+func findMemberships(db *gorm.DB, column string, ids []string) ([]membership, error) {
+	memberships := []membership{}
+	err := db.Table("user_companies").
+		Select("user_id, company_id").
+		Where(column+" IN ?", ids).
+		Scan(&memberships).Error
+
+	return memberships, err
+}
+
+// groupMemberships maps each key of the memberships to its values, as returned by pair
+func groupMemberships(memberships []membership, pair func(membership) (string, string)) map[string][]string {
+	grouped := make(map[string][]string)
+	for _, m := range memberships {
+		key, value := pair(m)
+		grouped[key] = append(grouped[key], value)
+	}
+	return grouped
+}
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	stderrors "errors"
+
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository defines the data operations of the webhook endpoints and their
+// deliveries
+type WebhookRepository interface {
+	CreateEndpoint(endpoint *models.WebhookEndpoint) error
+	GetEndpoint(companyID string, id string) (*models.WebhookEndpoint, error)
+	GetEndpoints(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error)
+	// CountEndpoints returns the number of endpoints of the company, active or not
+	CountEndpoints(companyID string) (int64, error)
+	// GetActiveEndpoints returns the active endpoints of the company, the candidates of
+	// the deliveries of its events
+	GetActiveEndpoints(companyID string) ([]models.WebhookEndpoint, error)
+	// GetEndpointsByURL returns the endpoints of the company with one of the URLs, active
+	// or not
+	GetEndpointsByURL(companyID string, urls []string) ([]models.WebhookEndpoint, error)
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(endpoint *models.WebhookEndpoint) error
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	GetDelivery(companyID string, id string) (*models.WebhookDelivery, error)
+	// GetDeliveryWithEndpoint returns a delivery with its endpoint, including a deleted
+	// one, for the sender
+	GetDeliveryWithEndpoint(id string) (*models.WebhookDelivery, error)
+	GetDeliveries(companyID string, pr *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error)
+	// SaveAttempt writes the status and the outcome of the last attempt of a delivery
+	SaveAttempt(delivery *models.WebhookDelivery) error
+}
+
+// webhookRepository implements WebhookRepository
+type webhookRepository struct {
+	abstractRepository[models.WebhookEndpoint]
+	deliveries abstractRepository[models.WebhookDelivery]
+}
+
+// ProvideWebhookRepository creates a new webhook repository
+func ProvideWebhookRepository(db *db.PostgresDB) WebhookRepository {
+	return &webhookRepository{
+		abstractRepository: abstractRepository[models.WebhookEndpoint]{db: db},
+		deliveries:         abstractRepository[models.WebhookDelivery]{db: db},
+	}
+}
+
+func (r *webhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := r.db.Omit("Company").Create(endpoint).Error; err != nil {
+		return errors.DatabaseError("Failed to create webhook endpoint", err).
+			WithOperation("create_webhook_endpoint").
+			WithResource("webhook_endpoint").
+			WithContext("company_id", endpoint.CompanyID)
+	}
+
+	return nil
+}
+
+// GetEndpoint returns an endpoint of the company, so that an endpoint id of another
+// company is reported as not found
+func (r *webhookRepository) GetEndpoint(companyID string, id string) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(endpoint).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Webhook endpoint", err).
+				WithOperation("get_webhook_endpoint").
+				WithResource("webhook_endpoint").
+				WithContext("company_id", companyID).
+				WithContext("webhook_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get webhook endpoint", err).
+			WithOperation("get_webhook_endpoint").
+			WithResource("webhook_endpoint").
+			WithContext("company_id", companyID).
+			WithContext("webhook_id", id)
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) GetEndpoints(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error) {
+	query := r.db.DB.
+		Where("company_id = ?", companyID).
+		Order("created_at desc")
+
+	result, err := r.find(query, pr)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get webhook endpoints", err).
+			WithOperation("get_webhook_endpoints").
+			WithResource("webhook_endpoints").
+			WithContext("company_id", companyID)
+	}
+
+	return result, nil
+}
+
+func (r *webhookRepository) CountEndpoints(companyID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.WebhookEndpoint{}).Where("company_id = ?", companyID).Count(&count).Error; err != nil {
+		return 0, errors.DatabaseError("Failed to count webhook endpoints", err).
+			WithOperation("count_webhook_endpoints").
+			WithResource("webhook_endpoints").
+			WithContext("company_id", companyID)
+	}
+
+	return count, nil
+}
+
+func (r *webhookRepository) GetActiveEndpoints(companyID string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.Where("company_id = ? AND active", companyID).Find(&endpoints).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get webhook endpoints", err).
+			WithOperation("get_active_webhook_endpoints").
+			WithResource("webhook_endpoints").
+			WithContext("company_id", companyID)
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) GetEndpointsByURL(companyID string, urls []string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := r.db.Where("company_id = ? AND url IN ?", companyID, urls).Order("created_at asc").Find(&endpoints).Error; err != nil {
+		return nil, errors.DatabaseError("Failed to get webhook endpoints", err).
+			WithOperation("get_webhook_endpoints_by_url").
+			WithResource("webhook_endpoints").
+			WithContext("company_id", companyID)
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := r.db.Omit("Company").Save(endpoint).Error; err != nil {
+		return errors.DatabaseError("Failed to update webhook endpoint", err).
+			WithOperation("update_webhook_endpoint").
+			WithResource("webhook_endpoint").
+			WithContext("webhook_id", endpoint.ID)
+	}
+
+	return nil
+}
+
+// DeleteEndpoint soft deletes the endpoint, so that its delivery logs keep their endpoint
+func (r *webhookRepository) DeleteEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := r.db.Delete(endpoint).Error; err != nil {
+		return errors.DatabaseError("Failed to delete webhook endpoint", err).
+			WithOperation("delete_webhook_endpoint").
+			WithResource("webhook_endpoint").
+			WithContext("webhook_id", endpoint.ID)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := r.db.Omit("Endpoint").Create(&deliveries).Error; err != nil {
+		return errors.DatabaseError("Failed to create webhook deliveries", err).
+			WithOperation("create_webhook_deliveries").
+			WithResource("webhook_delivery").
+			WithContext("company_id", deliveries[0].CompanyID).
+			WithContext("event_id", deliveries[0].EventID)
+	}
+
+	return nil
+}
+
+// GetDelivery returns a delivery of the company, so that a delivery id of another company
+// is reported as not found
+func (r *webhookRepository) GetDelivery(companyID string, id string) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := r.db.Where("company_id = ? AND id = ?", companyID, id).First(delivery).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Webhook delivery", err).
+				WithOperation("get_webhook_delivery").
+				WithResource("webhook_delivery").
+				WithContext("company_id", companyID).
+				WithContext("delivery_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get webhook delivery", err).
+			WithOperation("get_webhook_delivery").
+			WithResource("webhook_delivery").
+			WithContext("company_id", companyID).
+			WithContext("delivery_id", id)
+	}
+
+	return delivery, nil
+}
+
+func (r *webhookRepository) GetDeliveryWithEndpoint(id string) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := r.db.
+		Preload("Endpoint", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("id = ?", id).
+		First(delivery).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NotFoundError("Webhook delivery", err).
+				WithOperation("get_webhook_delivery_with_endpoint").
+				WithResource("webhook_delivery").
+				WithContext("delivery_id", id)
+		}
+		return nil, errors.DatabaseError("Failed to get webhook delivery", err).
+			WithOperation("get_webhook_delivery_with_endpoint").
+			WithResource("webhook_delivery").
+			WithContext("delivery_id", id)
+	}
+
+	return delivery, nil
+}
+
+func (r *webhookRepository) GetDeliveries(companyID string, pr *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error) {
+	query := r.db.DB.Where("company_id = ?", companyID)
+
+	if pr.WebhookID != "" {
+		query = query.Where("endpoint_id = ?", pr.WebhookID)
+	}
+
+	if pr.Status != "" {
+		query = query.Where("status = ?", pr.Status)
+	}
+
+	if pr.EventType != "" {
+		query = query.Where("event_type = ?", pr.EventType)
+	}
+
+	query = query.Order("created_at desc")
+
+	result, err := r.deliveries.find(query, &pr.PageableRequest)
+	if err != nil {
+		return nil, errors.DatabaseError("Failed to get webhook deliveries", err).
+			WithOperation("get_webhook_deliveries").
+			WithResource("webhook_deliveries").
+			WithContext("company_id", companyID).
+			WithContext("pageable_request", pr)
+	}
+
+	return result, nil
+}
+
+func (r *webhookRepository) SaveAttempt(delivery *models.WebhookDelivery) error {
+	err := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_status": delivery.ResponseStatus,
+			"response_body":   delivery.ResponseBody,
+			"last_error":      delivery.LastError,
+			"duration_ms":     delivery.DurationMs,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+	if err != nil {
+		return errors.DatabaseError("Failed to save webhook delivery attempt", err).
+			WithOperation("save_webhook_delivery_attempt").
+			WithResource("webhook_delivery").
+			WithContext("delivery_id", delivery.ID)
+	}
+
+	return nil
+}
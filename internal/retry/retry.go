@@ -0,0 +1,158 @@
+// Package retry runs operations again with exponential backoff and jitter, for the
+// connections the server must establish on startup (database, Redis, Keycloak).
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand/v2"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Default backoff settings, used for the zero fields of a Backoff
+const (
+	DefaultInitialInterval = 500 * time.Millisecond
+	DefaultMaxInterval     = 30 * time.Second
+	DefaultMultiplier      = 2.0
+	DefaultJitter          = 0.5
+)
+
+// Backoff describes when an operation is attempted again
+type Backoff struct {
+	// InitialInterval is the delay before the second attempt
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two attempts
+	MaxInterval time.Duration
+	// Multiplier grows the delay after each failed attempt
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction, in [0, 1], so that instances
+	// restarted together do not retry in lockstep
+	Jitter float64
+	// MaxAttempts stops after this many attempts, 0 means no limit
+	MaxAttempts int
+	// MaxElapsedTime stops when the next attempt would start after this duration since the
+	// first one, 0 means no limit
+	MaxElapsedTime time.Duration
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it without further attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return stderrors.As(err, &permanent)
+}
+
+// Delay returns the delay to wait after the given failed attempt, starting at 1, before
+// jitter is applied
+func (b Backoff) Delay(attempt int) time.Duration {
+	b = b.withDefaults()
+
+	delay := float64(b.InitialInterval)
+	for i := 1; i < attempt && delay < float64(b.MaxInterval); i++ {
+		delay *= b.Multiplier
+	}
+
+	return min(time.Duration(delay), b.MaxInterval)
+}
+
+// JitteredDelay returns the delay to wait after the given failed attempt, with jitter
+func (b Backoff) JitteredDelay(attempt int) time.Duration {
+	return b.jittered(b.Delay(attempt))
+}
+
+// jittered spreads delay uniformly over [delay*(1-Jitter), delay*(1+Jitter)]
+func (b Backoff) jittered(delay time.Duration) time.Duration {
+	if b.Jitter <= 0 {
+		return delay
+	}
+	spread := float64(delay) * min(b.Jitter, 1)
+	return time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+}
+
+func (b Backoff) withDefaults() Backoff {
+	if b.InitialInterval <= 0 {
+		b.InitialInterval = DefaultInitialInterval
+	}
+	if b.MaxInterval <= 0 {
+		b.MaxInterval = DefaultMaxInterval
+	}
+	b.MaxInterval = max(b.MaxInterval, b.InitialInterval)
+	if b.Multiplier < 1 {
+		b.Multiplier = DefaultMultiplier
+	}
+	return b
+}
+
+// Do calls op until it succeeds, returns a Permanent error, the attempts or elapsed time
+// of the backoff are exhausted, or ctx is done. It returns the last error of op, joined with
+// the context error when ctx ended the retries. The name identifies the operation in logs.
+func Do(ctx context.Context, name string, backoff Backoff, op func(ctx context.Context, attempt int) error) error {
+	backoff = backoff.withDefaults()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := op(ctx, attempt)
+		if err == nil {
+			if attempt > 1 {
+				logger.Log.Info("Operation succeeded after retries",
+					zap.String("operation", name),
+					zap.Int("attempt", attempt),
+					zap.Duration("elapsed", time.Since(start)),
+				)
+			}
+			return nil
+		}
+
+		var permanent *permanentError
+		if stderrors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if backoff.MaxAttempts > 0 && attempt >= backoff.MaxAttempts {
+			return err
+		}
+
+		delay := backoff.jittered(backoff.Delay(attempt))
+		if backoff.MaxElapsedTime > 0 && time.Since(start)+delay > backoff.MaxElapsedTime {
+			return err
+		}
+
+		logger.Log.Warn("Operation failed, retrying",
+			zap.String("operation", name),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stderrors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
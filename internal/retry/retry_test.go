@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+var errUnavailable = stderrors.New("connection refused")
+
+func TestBackoff_Delay(t *testing.T) {
+	backoff := Backoff{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+
+	assert.Equal(t, 100*time.Millisecond, backoff.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, backoff.Delay(2))
+	assert.Equal(t, 800*time.Millisecond, backoff.Delay(4))
+	assert.Equal(t, time.Second, backoff.Delay(5))
+	assert.Equal(t, time.Second, backoff.Delay(100))
+}
+
+func TestBackoff_Defaults(t *testing.T) {
+	backoff := Backoff{}
+
+	assert.Equal(t, DefaultInitialInterval, backoff.Delay(1))
+	assert.Equal(t, DefaultInitialInterval*2, backoff.Delay(2))
+	assert.Equal(t, DefaultMaxInterval, backoff.Delay(50))
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	backoff := Backoff{Jitter: 0.5}.withDefaults()
+
+	for range 100 {
+		delay := backoff.jittered(time.Second)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+	assert.Equal(t, time.Second, Backoff{}.jittered(time.Second))
+}
+
+func TestBackoff_JitteredDelay(t *testing.T) {
+	backoff := Backoff{InitialInterval: time.Second, MaxInterval: time.Minute, Jitter: 0.5}
+
+	for range 100 {
+		delay := backoff.JitteredDelay(3)
+		assert.GreaterOrEqual(t, delay, 2*time.Second)
+		assert.LessOrEqual(t, delay, 6*time.Second)
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.True(t, IsPermanent(Permanent(errUnavailable)))
+	assert.True(t, IsPermanent(fmt.Errorf("connect: %w", Permanent(errUnavailable))))
+	assert.False(t, IsPermanent(errUnavailable))
+	assert.False(t, IsPermanent(nil))
+}
+
+func TestDo(t *testing.T) {
+	fast := Backoff{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	tests := []struct {
+		name             string
+		backoff          Backoff
+		failures         int
+		permanent        bool
+		expectedError    bool
+		expectedAttempts int
+	}{
+		{
+			name:             "succeeds first time",
+			backoff:          fast,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "succeeds after failures",
+			backoff:          Backoff{InitialInterval: time.Millisecond, MaxAttempts: 5},
+			failures:         3,
+			expectedAttempts: 4,
+		},
+		{
+			name:             "max attempts exhausted",
+			backoff:          Backoff{InitialInterval: time.Millisecond, MaxAttempts: 3},
+			failures:         10,
+			expectedError:    true,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "permanent error is not retried",
+			backoff:          Backoff{InitialInterval: time.Millisecond, MaxAttempts: 5},
+			failures:         10,
+			permanent:        true,
+			expectedError:    true,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := Do(context.Background(), "test", tt.backoff, func(ctx context.Context, attempt int) error {
+				attempts++
+				assert.Equal(t, attempts, attempt)
+				if attempt <= tt.failures {
+					if tt.permanent {
+						return Permanent(errUnavailable)
+					}
+					return errUnavailable
+				}
+				return nil
+			})
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, errUnavailable)
+				var permanent *permanentError
+				assert.False(t, stderrors.As(err, &permanent), "permanent marker must be unwrapped")
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestDo_MaxElapsedTime(t *testing.T) {
+	backoff := Backoff{InitialInterval: 20 * time.Millisecond, MaxInterval: 20 * time.Millisecond, MaxElapsedTime: 50 * time.Millisecond}
+
+	attempts := 0
+	start := time.Now()
+	err := Do(context.Background(), "test", backoff, func(ctx context.Context, attempt int) error {
+		attempts++
+		return errUnavailable
+	})
+
+	// Attempts start at 0, 20 and 40ms; the next one would start after the 50ms budget
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.GreaterOrEqual(t, attempts, 2)
+	assert.LessOrEqual(t, attempts, 3)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestDo_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := 0
+	err := Do(ctx, "test", Backoff{InitialInterval: time.Hour}, func(ctx context.Context, attempt int) error {
+		attempts++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return errUnavailable
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestDo_ContextAlreadyDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := Do(ctx, "test", Backoff{}, func(ctx context.Context, attempt int) error {
+		called = true
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called)
+}
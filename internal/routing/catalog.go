@@ -0,0 +1,212 @@
+// Package routing records the routes registered on the Echo routers with their middleware
+// chain and the credentials and roles they require, for the routes command and GET /admin/routes.
+// Echo keeps no trace of the middlewares of a route, so routes are registered through the
+// Group of a Catalog, with middlewares described by Describe.
+package routing
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware is a middleware with the description the catalog lists
+type Middleware struct {
+	Name string
+	// Schemes are the authentication schemes the middleware accepts, any of them
+	Schemes []string
+	// Roles are the roles the middleware requires, any of them
+	Roles []string
+	Func  echo.MiddlewareFunc
+}
+
+// Describe names a middleware that neither authenticates nor authorizes
+func Describe(name string, fn echo.MiddlewareFunc) Middleware {
+	return Middleware{Name: name, Func: fn}
+}
+
+// Route describes a registered route
+type Route struct {
+	// Listener is the HTTP listener serving the route, e.g. public or internal
+	Listener string
+	Method   string
+	Path     string
+	// Handler is the name of the handler function
+	Handler string
+	// Middlewares are the names of the middlewares the route runs, in order, the global
+	// ones of the listener first
+	Middlewares []string
+	// Schemes are the authentication schemes the route accepts; a route without schemes
+	// is public
+	Schemes []string
+	// Roles are the roles the route requires, any of them
+	Roles []string
+}
+
+// Catalog records the routes of the listeners
+type Catalog struct {
+	mu     sync.Mutex
+	routes []Route
+}
+
+// ProvideCatalog creates the catalog, filled by the routers as they are built
+func ProvideCatalog() *Catalog {
+	return &Catalog{}
+}
+
+// Routes returns the recorded routes sorted by listener, path and method
+func (c *Catalog) Routes() []Route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	routes := make([]Route, len(c.routes))
+	copy(routes, c.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Listener != routes[j].Listener {
+			return routes[i].Listener < routes[j].Listener
+		}
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Listener returns the root group of the router of a listener. The routes of a listener
+// registered again, e.g. by a router built twice, replace the previous ones.
+func (c *Catalog) Listener(name string, e *echo.Echo) *Group {
+	c.mu.Lock()
+	routes := c.routes[:0]
+	for _, route := range c.routes {
+		if route.Listener != name {
+			routes = append(routes, route)
+		}
+	}
+	c.routes = routes
+	c.mu.Unlock()
+
+	return &Group{catalog: c, listener: name, echo: e, global: &[]Middleware{}}
+}
+
+func (c *Catalog) add(route Route) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.routes = append(c.routes, route)
+}
+
+// Group registers routes under a path prefix and records them in the catalog
+type Group struct {
+	catalog  *Catalog
+	listener string
+	echo     *echo.Echo
+	group    *echo.Group
+	prefix   string
+	// global are the middlewares of every route of the listener, shared by its groups
+	global *[]Middleware
+}
+
+// Use adds middlewares run by every route of the listener, before routing. It can only be
+// called on the root group.
+func (g *Group) Use(middlewares ...Middleware) {
+	for _, m := range middlewares {
+		g.echo.Use(m.Func)
+	}
+	*g.global = append(*g.global, middlewares...)
+}
+
+// Group returns a group of the routes under the prefix
+func (g *Group) Group(prefix string) *Group {
+	group := *g
+	if g.group == nil {
+		group.group = g.echo.Group(prefix)
+	} else {
+		group.group = g.group.Group(prefix)
+	}
+	group.prefix = g.prefix + prefix
+	return &group
+}
+
+// GET registers a route for the GET method
+func (g *Group) GET(path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	return g.Add(http.MethodGet, path, h, m...)
+}
+
+// POST registers a route for the POST method
+func (g *Group) POST(path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	return g.Add(http.MethodPost, path, h, m...)
+}
+
+// PUT registers a route for the PUT method
+func (g *Group) PUT(path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	return g.Add(http.MethodPut, path, h, m...)
+}
+
+// PATCH registers a route for the PATCH method
+func (g *Group) PATCH(path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	return g.Add(http.MethodPatch, path, h, m...)
+}
+
+// DELETE registers a route for the DELETE method
+func (g *Group) DELETE(path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	return g.Add(http.MethodDelete, path, h, m...)
+}
+
+// Add registers a route for the method and records it
+func (g *Group) Add(method string, path string, h echo.HandlerFunc, m ...Middleware) *echo.Route {
+	funcs := make([]echo.MiddlewareFunc, len(m))
+	for i, middleware := range m {
+		funcs[i] = middleware.Func
+	}
+
+	var route *echo.Route
+	if g.group == nil {
+		route = g.echo.Add(method, path, h, funcs...)
+	} else {
+		route = g.group.Add(method, path, h, funcs...)
+	}
+
+	chain := append(append([]Middleware{}, *g.global...), m...)
+	names := make([]string, 0, len(chain))
+	var schemes, roles []string
+	for _, middleware := range chain {
+		names = append(names, middleware.Name)
+		schemes = appendMissing(schemes, middleware.Schemes...)
+		roles = appendMissing(roles, middleware.Roles...)
+	}
+
+	g.catalog.add(Route{
+		Listener:    g.listener,
+		Method:      method,
+		Path:        route.Path,
+		Handler:     handlerName(route.Name),
+		Middlewares: names,
+		Schemes:     schemes,
+		Roles:       roles,
+	})
+	return route
+}
+
+// handlerName trims the package path and the method value suffix from the name Echo gives
+// a handler, e.g. handlers.(*UserHandler).GetUsers
+func handlerName(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// appendMissing appends the values missing from values
+func appendMissing(values []string, more ...string) []string {
+	for _, value := range more {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passThrough is a middleware that records its name in the order it ran
+func passThrough(name string, ran *[]string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			*ran = append(*ran, name)
+			return next(c)
+		}
+	}
+}
+
+func TestCatalog_Routes(t *testing.T) {
+	var ran []string
+	catalog := ProvideCatalog()
+	e := echo.New()
+	root := catalog.Listener("public", e)
+	root.Use(Describe("request_id", passThrough("request_id", &ran)))
+
+	token := Middleware{Name: "auth", Schemes: []string{"bearer"}, Func: passThrough("auth", &ran)}
+	admin := Middleware{Name: "require_role", Roles: []string{"admin"}, Func: passThrough("require_role", &ran)}
+	apiKey := Middleware{Name: "api_key_or_token", Schemes: []string{"api_key", "bearer"}, Roles: []string{"admin", "company-manager"}, Func: passThrough("api_key_or_token", &ran)}
+
+	handler := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	root.GET("/health", handler)
+	v1 := root.Group("/api/v1")
+	companies := v1.Group("/companies")
+	companies.GET("/:id", handler, apiKey)
+	companies.DELETE("/:id", handler, token, admin)
+
+	routes := catalog.Routes()
+	require.Len(t, routes, 3)
+	assert.Equal(t, Route{
+		Listener:    "public",
+		Method:      http.MethodDelete,
+		Path:        "/api/v1/companies/:id",
+		Handler:     "routing.TestCatalog_Routes.func1",
+		Middlewares: []string{"request_id", "auth", "require_role"},
+		Schemes:     []string{"bearer"},
+		Roles:       []string{"admin"},
+	}, routes[0])
+	assert.Equal(t, http.MethodGet, routes[1].Method)
+	assert.Equal(t, []string{"api_key", "bearer"}, routes[1].Schemes)
+	assert.Equal(t, []string{"admin", "company-manager"}, routes[1].Roles)
+	// A route without schemes is public
+	assert.Equal(t, "/health", routes[2].Path)
+	assert.Empty(t, routes[2].Schemes)
+
+	// The routes are served with the middlewares they are listed with
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/companies/company-1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, routes[0].Middlewares, ran)
+
+	// A listener built again replaces its routes
+	catalog.Listener("internal", echo.New()).GET("/", handler)
+	catalog.Listener("public", echo.New()).GET("/health", handler)
+	routes = catalog.Routes()
+	require.Len(t, routes, 2)
+	assert.Equal(t, "internal", routes[0].Listener)
+	assert.Empty(t, routes[1].Middlewares)
+}
@@ -0,0 +1,84 @@
+// Package saga runs the workflows spanning systems that cannot share a transaction, such as
+// Keycloak and the database, as a sequence of steps undone by their compensations when a
+// later step fails.
+package saga
+
+import (
+	"context"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+)
+
+// compensationTimeout bounds the compensations of a failed saga, which run after the
+// context of the request may be done
+const compensationTimeout = 30 * time.Second
+
+// Step is a step of a saga
+type Step struct {
+	// Name names the step in the logs, e.g. create_keycloak_user
+	Name string
+	// Action does the step
+	Action func(ctx context.Context) error
+	// Compensate undoes what Action did, once it succeeded and a later step failed. It is
+	// nil when there is nothing to undo; it may also find that Action changed nothing,
+	// e.g. when the resource already existed, and return nil.
+	Compensate func(ctx context.Context) error
+}
+
+// Run runs the steps in order. When a step fails, the compensations of the steps done
+// before it run in reverse order, and the error of the failed step is returned. The
+// compensations run even when ctx is done; a failing compensation is logged and reported
+// to Sentry, as it leaves a resource to clean up by hand, and the others still run.
+func Run(ctx context.Context, name string, steps ...Step) error {
+	for i, step := range steps {
+		if err := step.Action(ctx); err != nil {
+			logger.Log.Warn("Saga step failed, compensating",
+				zap.String("saga", name),
+				zap.String("step", step.Name),
+				zap.Error(err),
+			)
+			compensate(ctx, name, steps[:i])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compensate runs the compensations of the done steps, last step first
+func compensate(ctx context.Context, name string, done []Step) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	for i := len(done) - 1; i >= 0; i-- {
+		step := done[i]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(ctx); err != nil {
+			if hub := sentry.GetHubFromContext(ctx); hub != nil {
+				hub.WithScope(func(scope *sentry.Scope) {
+					scope.SetTag("saga", name)
+					scope.SetTag("step", step.Name)
+					hub.CaptureException(err)
+				})
+			}
+
+			logger.Log.Error("Saga compensation failed",
+				zap.String("saga", name),
+				zap.String("step", step.Name),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		logger.Log.Info("Saga step compensated",
+			zap.String("saga", name),
+			zap.String("step", step.Name),
+		)
+	}
+}
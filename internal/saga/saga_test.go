@@ -0,0 +1,128 @@
+package saga
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"testing"
+
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// recorder records the actions and compensations run, in order
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) step(name string, actionErr error, compensateErr error) Step {
+	return Step{
+		Name: name,
+		Action: func(ctx context.Context) error {
+			r.calls = append(r.calls, name)
+			return actionErr
+		},
+		Compensate: func(ctx context.Context) error {
+			r.calls = append(r.calls, "undo "+name)
+			return compensateErr
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	errFailed := stderrors.New("failed")
+	errUndo := stderrors.New("undo failed")
+
+	tests := []struct {
+		name          string
+		steps         func(r *recorder) []Step
+		expectedError error
+		expectedCalls []string
+	}{
+		{
+			name: "every step succeeds",
+			steps: func(r *recorder) []Step {
+				return []Step{r.step("a", nil, nil), r.step("b", nil, nil), r.step("c", nil, nil)}
+			},
+			expectedCalls: []string{"a", "b", "c"},
+		},
+		{
+			name: "the steps done are compensated in reverse order",
+			steps: func(r *recorder) []Step {
+				return []Step{r.step("a", nil, nil), r.step("b", nil, nil), r.step("c", errFailed, nil)}
+			},
+			expectedError: errFailed,
+			expectedCalls: []string{"a", "b", "c", "undo b", "undo a"},
+		},
+		{
+			name: "the first step failing compensates nothing",
+			steps: func(r *recorder) []Step {
+				return []Step{r.step("a", errFailed, nil), r.step("b", nil, nil)}
+			},
+			expectedError: errFailed,
+			expectedCalls: []string{"a"},
+		},
+		{
+			name: "a failing compensation does not stop the others",
+			steps: func(r *recorder) []Step {
+				return []Step{r.step("a", nil, nil), r.step("b", nil, errUndo), r.step("c", errFailed, nil)}
+			},
+			expectedError: errFailed,
+			expectedCalls: []string{"a", "b", "c", "undo b", "undo a"},
+		},
+		{
+			name: "steps without compensation are skipped",
+			steps: func(r *recorder) []Step {
+				b := r.step("b", nil, nil)
+				b.Compensate = nil
+				return []Step{r.step("a", nil, nil), b, r.step("c", errFailed, nil)}
+			},
+			expectedError: errFailed,
+			expectedCalls: []string{"a", "b", "c", "undo a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+
+			err := Run(context.Background(), "test", tt.steps(r)...)
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expectedCalls, r.calls)
+		})
+	}
+}
+
+func TestRun_CompensatesAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var compensationErr error
+
+	err := Run(ctx, "test",
+		Step{
+			Name:   "a",
+			Action: func(ctx context.Context) error { return nil },
+			Compensate: func(ctx context.Context) error {
+				compensationErr = ctx.Err()
+				return nil
+			},
+		},
+		Step{
+			Name: "b",
+			Action: func(ctx context.Context) error {
+				cancel()
+				return ctx.Err()
+			},
+		},
+	)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, compensationErr)
+}
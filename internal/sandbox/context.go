@@ -0,0 +1,19 @@
+// Package sandbox carries the sandbox tenant of a request. Requests authenticated with a
+// sandbox API key run in the sandbox of the key, and the integrations replace their side
+// effects with fakes, e.g. emails are captured instead of being sent.
+package sandbox
+
+import "context"
+
+type companyContextKey struct{}
+
+// WithCompany returns a context running in the sandbox tenant companyID
+func WithCompany(ctx context.Context, companyID string) context.Context {
+	return context.WithValue(ctx, companyContextKey{}, companyID)
+}
+
+// CompanyID returns the sandbox tenant set by WithCompany, if any
+func CompanyID(ctx context.Context) (string, bool) {
+	companyID, ok := ctx.Value(companyContextKey{}).(string)
+	return companyID, ok
+}
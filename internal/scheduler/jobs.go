@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/db"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/services"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// Names of the jobs of the server
+const (
+	JobDatabaseMetrics  = "database_metrics"
+	JobDemoReset        = "demo_reset"
+	JobAPIKeyHygiene    = "api_key_hygiene"
+	JobDataRetention    = "data_retention"
+	JobPerformancePurge = "performance_purge"
+	JobIntegrationProbe = "integration_probe"
+	JobKeycloakSync     = "keycloak_sync"
+)
+
+// ProvideDatabaseMetricsJob records the connection pool metrics in New Relic as
+// Custom/Database/<metric>, on DATABASE_METRICS_SCHEDULE
+func ProvideDatabaseMetricsJob(cfg *config.Config, database *db.PostgresDB, nrApp *newrelic.Application) Job {
+	return Job{
+		Name:     JobDatabaseMetrics,
+		Schedule: cfg.DatabaseMetricsSchedule,
+		Timeout:  10 * time.Second,
+		Run: func(context.Context) error {
+			metrics := database.GetMetrics()
+			nrApp.RecordCustomMetric("Custom/Database/OpenConnections", float64(metrics.OpenConnections))
+			nrApp.RecordCustomMetric("Custom/Database/IdleConnections", float64(metrics.IdleConnections))
+			nrApp.RecordCustomMetric("Custom/Database/InUseConnections", float64(metrics.InUseConnections))
+			nrApp.RecordCustomMetric("Custom/Database/WaitCount", float64(metrics.WaitCount))
+			nrApp.RecordCustomMetric("Custom/Database/WaitDuration", metrics.WaitDuration.Seconds())
+			return nil
+		},
+	}
+}
+
+// ProvideDemoResetJob resets the demo dataset on DEMO_RESET_SCHEDULE, e.g. `0 3 * * *`
+// for every night. It is disabled outside of demo mode.
+func ProvideDemoResetJob(cfg *config.Config, demoService services.DemoService) Job {
+	job := Job{
+		Name:      JobDemoReset,
+		Timeout:   5 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			seeded, err := demoService.Reset(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Log.Info("Demo data reset",
+				zap.Int("companies", seeded.Companies),
+				zap.Int("users", seeded.Users),
+			)
+			return nil
+		},
+	}
+	if cfg.DemoMode {
+		job.Schedule = cfg.DemoResetSchedule
+	}
+	return job
+}
+
+// ProvideAPIKeyHygieneJob reports the API keys unused for API_KEY_UNUSED_ALERT_DAYS and
+// revokes the ones unused for API_KEY_UNUSED_EXPIRY_DAYS, on API_KEY_HYGIENE_SCHEDULE
+func ProvideAPIKeyHygieneJob(cfg *config.Config, apiKeyService services.APIKeyService) Job {
+	return Job{
+		Name:      JobAPIKeyHygiene,
+		Schedule:  cfg.APIKeyHygieneSchedule,
+		Timeout:   time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			result, err := apiKeyService.CheckUnused(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Log.Info("Unused API keys checked",
+				zap.Int("alerted", result.Alerted),
+				zap.Int("revoked", result.Revoked),
+			)
+			return nil
+		},
+	}
+}
+
+// ProvideDataRetentionJob purges the data older than the retention policies of the
+// companies, except the entities on legal hold, on RETENTION_SCHEDULE
+func ProvideDataRetentionJob(cfg *config.Config, retentionService services.RetentionService) Job {
+	return Job{
+		Name:      JobDataRetention,
+		Schedule:  cfg.RetentionSchedule,
+		Timeout:   30 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			result, err := retentionService.Enforce(ctx)
+			if result != nil {
+				fields := []zap.Field{zap.Int("policies", result.Policies), zap.Int("failed", result.Failed)}
+				for resource, purged := range result.Purged {
+					fields = append(fields, zap.Int64(resource, purged))
+				}
+				logger.Log.Info("Retention policies enforced", fields...)
+			}
+			return err
+		},
+	}
+}
+
+// ProvidePerformancePurgeJob deletes the tenant performance metrics older than
+// PERFORMANCE_RETENTION_DAYS, on PERFORMANCE_PURGE_SCHEDULE
+func ProvidePerformancePurgeJob(cfg *config.Config, performanceService services.PerformanceService) Job {
+	return Job{
+		Name:      JobPerformancePurge,
+		Schedule:  cfg.PerformancePurgeSchedule,
+		Timeout:   10 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			purged, err := performanceService.Purge(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Log.Info("Tenant performance metrics purged", zap.Int64("purged", purged))
+			return nil
+		},
+	}
+}
+
+// ProvideIntegrationProbeJob probes the dependencies of the health registry and records
+// the results, on INTEGRATION_PROBE_SCHEDULE
+func ProvideIntegrationProbeJob(cfg *config.Config, integrationHealthService services.IntegrationHealthService) Job {
+	return Job{
+		Name:     JobIntegrationProbe,
+		Schedule: cfg.IntegrationProbeSchedule,
+		Timeout:  30 * time.Second,
+		Run: func(ctx context.Context) error {
+			report, err := integrationHealthService.Probe(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Log.Debug("Integrations probed", zap.String("status", report.Status))
+			return nil
+		},
+	}
+}
+
+// ProvideKeycloakSyncJob reconciles the users and companies with Keycloak on
+// KEYCLOAK_SYNC_SCHEDULE, only reporting the changes while KEYCLOAK_SYNC_DRY_RUN is set
+func ProvideKeycloakSyncJob(cfg *config.Config, keycloakSyncService services.KeycloakSyncService) Job {
+	return Job{
+		Name:      JobKeycloakSync,
+		Schedule:  cfg.KeycloakSyncSchedule,
+		Timeout:   10 * time.Minute,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			report, err := keycloakSyncService.Sync(ctx, cfg.KeycloakSyncDryRun)
+			if err != nil {
+				return err
+			}
+			logger.Log.Info("Keycloak synced",
+				zap.Bool("dry_run", report.DryRun),
+				zap.Any("counts", report.Counts),
+				zap.Int("failed", report.Failed),
+			)
+			return nil
+		},
+	}
+}
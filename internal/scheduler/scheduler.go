@@ -0,0 +1,241 @@
+// Package scheduler runs background jobs on cron schedules. Jobs are declared by their
+// providers in the "jobs" fx group with a schedule read from the config; every run is
+// logged, traced as a New Relic background transaction and reported to Sentry on failure.
+package scheduler
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/shutdown"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Job is a task run on a schedule
+type Job struct {
+	// Name identifies the job in logs, metrics and Sentry events
+	Name string
+	// Schedule is a standard 5 field cron spec or a descriptor such as `@hourly` or
+	// `@every 10m`, evaluated in the application timezone; the job is disabled when empty
+	Schedule string
+	// Timeout bounds a run, whose context is canceled when it expires
+	Timeout time.Duration
+	// Run does the work; a run still going on at the next tick makes that tick skipped
+	Run func(ctx context.Context) error
+	// Exclusive jobs run on one instance at a time, under a lock in the cache: the ticks
+	// of the other instances are skipped while it is held
+	Exclusive bool
+}
+
+// Params are the dependencies of the scheduler
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    *config.Config
+	NrApp     *newrelic.Application
+	Cache     cache.Cache
+	Sequence  *shutdown.Sequence
+	Jobs      []Job `group:"jobs"`
+}
+
+// Scheduler runs the enabled jobs on their schedules
+type Scheduler struct {
+	cron  *cron.Cron
+	nrApp *newrelic.Application
+	locks cache.Cache
+	jobs  []Job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a scheduler of the jobs, failing on an invalid schedule or a duplicate name.
+// Jobs without a schedule are skipped. locks holds the locks of the exclusive jobs.
+func New(nrApp *newrelic.Application, locks cache.Cache, jobs ...Job) (*Scheduler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		cron:   cron.New(cron.WithLocation(time.Local), cron.WithLogger(cronLogger{})),
+		nrApp:  nrApp,
+		locks:  locks,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	names := make(map[string]bool)
+	for _, job := range jobs {
+		if names[job.Name] {
+			cancel()
+			return nil, fmt.Errorf("duplicate job %s", job.Name)
+		}
+		names[job.Name] = true
+		if job.Schedule == "" {
+			continue
+		}
+		if job.Exclusive && locks == nil {
+			cancel()
+			return nil, fmt.Errorf("exclusive job %s needs a cache for its lock", job.Name)
+		}
+
+		skipIfRunning := cron.NewChain(cron.SkipIfStillRunning(cronLogger{}))
+		if _, err := s.cron.AddJob(job.Schedule, skipIfRunning.Then(cron.FuncJob(func() { _ = s.tick(job) }))); err != nil {
+			cancel()
+			return nil, fmt.Errorf("invalid schedule %q of job %s: %w", job.Schedule, job.Name, err)
+		}
+		s.jobs = append(s.jobs, job)
+	}
+
+	return s, nil
+}
+
+// ProvideScheduler creates the scheduler of the jobs group, started with the application
+// unless SCHEDULER_ENABLED is false. Jobs run on every instance of the server, the
+// exclusive ones on a single instance at a time.
+func ProvideScheduler(p Params) (*Scheduler, error) {
+	s, err := New(p.NrApp, p.Cache, p.Jobs...)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Config.SchedulerEnabled {
+		return s, nil
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			s.Start()
+			return nil
+		},
+	})
+	p.Sequence.Register(shutdown.PhaseSchedulers, shutdown.ComponentScheduler, p.Config.ShutdownSchedulerTimeout, s.Stop)
+
+	return s, nil
+}
+
+// Jobs returns the enabled jobs
+func (s *Scheduler) Jobs() []Job {
+	return s.jobs
+}
+
+// Start starts running the jobs on their schedules
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		logger.Log.Info("Scheduled job",
+			zap.String("job", job.Name),
+			zap.String("schedule", job.Schedule),
+		)
+	}
+	s.cron.Start()
+}
+
+// Stop stops scheduling runs and waits for the running ones until ctx expires, then
+// cancels their context
+func (s *Scheduler) Stop(ctx context.Context) error {
+	running := s.cron.Stop()
+
+	select {
+	case <-running.Done():
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// tick runs a job on its schedule. An exclusive job runs under its lock, in a context
+// canceled if the lock is lost, and is skipped while another instance holds it.
+func (s *Scheduler) tick(job Job) error {
+	if !job.Exclusive {
+		return s.run(s.ctx, job)
+	}
+
+	lease, err := cache.Lock(s.ctx, s.locks, constants.SchedulerLockKeyPrefix+job.Name, constants.SchedulerLockTTL)
+	if stderrors.Is(err, cache.ErrLockHeld) {
+		logger.Log.Debug("Job skipped, running on another instance", zap.String("job", job.Name))
+		return nil
+	}
+	if err != nil {
+		logger.Log.Warn("Job skipped, failed to acquire its lock", zap.String("job", job.Name), zap.Error(err))
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.SchedulerLockTTL)
+		defer cancel()
+		if err := lease.Release(ctx); err != nil {
+			logger.Log.Warn("Failed to release job lock", zap.String("job", job.Name), zap.Error(err))
+		}
+	}()
+
+	return s.run(lease.Context(), job)
+}
+
+// run runs a job once in parent under its timeout, recovering panics, and records the run
+func (s *Scheduler) run(parent context.Context, job Job) (err error) {
+	txn := s.nrApp.StartTransaction("Job/" + job.Name)
+	defer txn.End()
+
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("job", job.Name)
+	})
+
+	ctx := newrelic.NewContext(parent, txn)
+	ctx = sentry.SetHubOnContext(ctx, hub)
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	logger.Log.Info("Job started", zap.String("job", job.Name))
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.Name, r)
+		}
+
+		elapsed := time.Since(startedAt)
+		s.nrApp.RecordCustomMetric("Custom/Job/"+job.Name+"/Duration", elapsed.Seconds())
+		if err != nil {
+			txn.NoticeError(err)
+			hub.CaptureException(err)
+			s.nrApp.RecordCustomMetric("Custom/Job/"+job.Name+"/Failure", 1)
+			logger.Log.Warn("Job failed",
+				zap.String("job", job.Name),
+				zap.Duration("elapsed", elapsed),
+				zap.Error(err),
+			)
+			return
+		}
+
+		logger.Log.Info("Job completed",
+			zap.String("job", job.Name),
+			zap.Duration("elapsed", elapsed),
+		)
+	}()
+
+	return job.Run(ctx)
+}
+
+// cronLogger routes the cron logs to the application logger
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...any) {
+	logger.Log.Debug("Cron: "+msg, zap.Any("details", keysAndValues))
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...any) {
+	logger.Log.Warn("Cron: "+msg, zap.Any("details", keysAndValues), zap.Error(err))
+}
@@ -0,0 +1,248 @@
+package scheduler
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// observeLogs routes the global logger to an observer for the duration of the test
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.InfoLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core)
+	t.Cleanup(func() { logger.Log = previous })
+	return logs
+}
+
+func noop(context.Context) error { return nil }
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		jobs     []Job
+		expected []string
+		errMsg   string
+	}{
+		{
+			name: "cron spec and descriptor",
+			jobs: []Job{
+				{Name: "nightly", Schedule: "0 3 * * *", Run: noop},
+				{Name: "frequent", Schedule: "@every 10m", Run: noop},
+			},
+			expected: []string{"nightly", "frequent"},
+		},
+		{
+			name: "jobs without schedule are disabled",
+			jobs: []Job{
+				{Name: "disabled", Run: noop},
+				{Name: "hourly", Schedule: "@hourly", Run: noop},
+			},
+			expected: []string{"hourly"},
+		},
+		{
+			name:   "invalid schedule",
+			jobs:   []Job{{Name: "broken", Schedule: "every minute", Run: noop}},
+			errMsg: `invalid schedule "every minute" of job broken`,
+		},
+		{
+			name: "duplicate name",
+			jobs: []Job{
+				{Name: "cleanup", Schedule: "@hourly", Run: noop},
+				{Name: "cleanup", Run: noop},
+			},
+			errMsg: "duplicate job cleanup",
+		},
+		{
+			name:   "exclusive job without cache",
+			jobs:   []Job{{Name: "purge", Schedule: "@hourly", Exclusive: true, Run: noop}},
+			errMsg: "exclusive job purge needs a cache for its lock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(nil, nil, tt.jobs...)
+
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, job := range s.Jobs() {
+				names = append(names, job.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	logs := observeLogs(t)
+	s, err := New(nil, nil)
+	require.NoError(t, err)
+
+	err = s.run(context.Background(), Job{Name: "sync", Timeout: time.Second, Run: func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "the run must be bounded by the job timeout")
+		return nil
+	}})
+
+	require.NoError(t, err)
+	completed := logs.FilterMessage("Job completed").All()
+	require.Len(t, completed, 1)
+	assert.Equal(t, "sync", completed[0].ContextMap()["job"])
+}
+
+func TestScheduler_RunFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		run    func(ctx context.Context) error
+		errMsg string
+	}{
+		{
+			name:   "error",
+			run:    func(context.Context) error { return stderrors.New("upstream unavailable") },
+			errMsg: "upstream unavailable",
+		},
+		{
+			name:   "panic",
+			run:    func(context.Context) error { panic("nil map") },
+			errMsg: "job cleanup panicked: nil map",
+		},
+		{
+			name: "timeout",
+			run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			errMsg: context.DeadlineExceeded.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := observeLogs(t)
+			s, err := New(nil, nil)
+			require.NoError(t, err)
+
+			err = s.run(context.Background(), Job{Name: "cleanup", Timeout: 10 * time.Millisecond, Run: tt.run})
+
+			assert.ErrorContains(t, err, tt.errMsg)
+			assert.Equal(t, 1, logs.FilterMessage("Job failed").Len())
+		})
+	}
+}
+
+// lockCache holds the locks of the exclusive jobs in memory; the other methods of the
+// cache are not used by the scheduler
+type lockCache struct {
+	cache.Cache
+	mu    sync.Mutex
+	locks map[string]string
+}
+
+func newLockCache() *lockCache {
+	return &lockCache{locks: make(map[string]string)}
+}
+
+func (c *lockCache) SetNX(_ context.Context, key string, value string, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.locks[key]; ok {
+		return false, nil
+	}
+	c.locks[key] = value
+	return true, nil
+}
+
+func (c *lockCache) CompareAndDelete(_ context.Context, key string, value string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks[key] != value {
+		return false, nil
+	}
+	delete(c.locks, key)
+	return true, nil
+}
+
+func (c *lockCache) CompareAndExpire(_ context.Context, key string, value string, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.locks[key] == value, nil
+}
+
+func TestScheduler_TickExclusive(t *testing.T) {
+	locks := newLockCache()
+	var runs atomic.Int32
+	job := Job{Name: "purge", Exclusive: true, Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+
+	// The lock is held by another instance
+	other, err := cache.Lock(context.Background(), locks, "scheduler:purge", time.Minute)
+	require.NoError(t, err)
+	s, err := New(nil, locks)
+	require.NoError(t, err)
+
+	require.NoError(t, s.tick(job))
+	assert.Equal(t, int32(0), runs.Load(), "the job must be skipped while the lock is held")
+
+	require.NoError(t, other.Release(context.Background()))
+	require.NoError(t, s.tick(job))
+	assert.Equal(t, int32(1), runs.Load())
+	assert.Empty(t, locks.locks, "the lock must be released after the run")
+}
+
+func TestScheduler_StartAndStop(t *testing.T) {
+	var runs atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s, err := New(nil, nil, Job{Name: "tick", Schedule: "@every 1s", Run: func(ctx context.Context) error {
+		runs.Add(1)
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}})
+	require.NoError(t, err)
+
+	s.Start()
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job not run")
+	}
+
+	// Stop waits for the running job until its context expires, then cancels it
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Stop(ctx), context.DeadlineExceeded)
+	close(release)
+
+	time.Sleep(1100 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load(), "no run after Stop")
+}
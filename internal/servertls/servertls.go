@@ -0,0 +1,119 @@
+// Package servertls terminates TLS on the public listener, for single-instance deployments
+// without a TLS terminating proxy or load balancer in front of the server.
+package servertls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLS is the TLS setup of the public listener, with its certificate from files or from
+// Let's Encrypt
+type TLS struct {
+	config  *tls.Config
+	manager *autocert.Manager
+	// port is the port of the public listener, the target of the redirects
+	port string
+}
+
+// ProvideTLS returns the TLS setup of APP_HTTP_SERVER, nil when TLS is terminated upstream
+func ProvideTLS(cfg *config.Config) (*TLS, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil
+	}
+	return New(cfg)
+}
+
+// New creates the TLS setup of the config, loading the certificate and key files or
+// obtaining the certificates of the autocert domains on their first handshake
+func New(cfg *config.Config) (*TLS, error) {
+	_, port, err := net.SplitHostPort(cfg.AppHTTPServer)
+	if err != nil {
+		return nil, fmt.Errorf("parse APP_HTTP_SERVER: %w", err)
+	}
+	t := &TLS{port: port}
+
+	if len(cfg.TLSAutocertDomains) > 0 {
+		t.manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		if cfg.TLSAutocertDirectory != "" {
+			t.manager.Client = &acme.Client{DirectoryURL: cfg.TLSAutocertDirectory}
+		}
+		// Offers h2, http/1.1 and the ACME TLS-ALPN challenge protocol
+		t.config = t.manager.TLSConfig()
+	} else {
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load TLS_CERT_FILE and TLS_KEY_FILE: %w", err)
+		}
+		t.config = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			NextProtos:   []string{"h2", "http/1.1"},
+		}
+	}
+
+	t.config.MinVersion = tls.VersionTLS12
+	t.config.CipherSuites = slices.Clone(constants.TLSCipherSuites)
+	t.config.CurvePreferences = slices.Clone(constants.TLSCurvePreferences)
+
+	return t, nil
+}
+
+// Config returns the TLS config of the public listener
+func (t *TLS) Config() *tls.Config {
+	return t.config
+}
+
+// RedirectHandler redirects the plain HTTP requests to HTTPS. With autocert, it answers the
+// ACME HTTP challenges first and redirects only the requests for the autocert domains.
+func (t *TLS) RedirectHandler() http.Handler {
+	if t.manager == nil {
+		return http.HandlerFunc(t.redirect)
+	}
+	return t.manager.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := t.manager.HostPolicy(r.Context(), hostname(r.Host)); err != nil {
+			http.Error(w, "Unknown host", http.StatusBadRequest)
+			return
+		}
+		t.redirect(w, r)
+	}))
+}
+
+// redirect sends the request to the same host and URI on the HTTPS port. Other methods
+// than GET and HEAD get a 308 so that clients repeat them with their body.
+func (t *TLS) redirect(w http.ResponseWriter, r *http.Request) {
+	host := hostname(r.Host)
+	if t.port != "443" {
+		host = net.JoinHostPort(host, t.port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	status := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+}
+
+// hostname strips the port of a Host header
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
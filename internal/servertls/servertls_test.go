@@ -0,0 +1,175 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed certificate for localhost and its key, and returns
+// their paths
+func writeCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestProvideTLS_Disabled(t *testing.T) {
+	serverTLS, err := ProvideTLS(&config.Config{AppHTTPServer: ":3000"})
+
+	require.NoError(t, err)
+	assert.Nil(t, serverTLS)
+}
+
+func TestNew_CertificateFiles(t *testing.T) {
+	certFile, keyFile := writeCertificate(t)
+
+	serverTLS, err := New(&config.Config{AppHTTPServer: ":443", TLSCertFile: certFile, TLSKeyFile: keyFile})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), serverTLS.Config().MinVersion)
+	assert.Equal(t, []string{"h2", "http/1.1"}, serverTLS.Config().NextProtos)
+	assert.Len(t, serverTLS.Config().Certificates, 1)
+
+	_, err = New(&config.Config{AppHTTPServer: ":443", TLSCertFile: certFile, TLSKeyFile: certFile})
+	assert.Error(t, err)
+}
+
+func TestNew_Autocert(t *testing.T) {
+	serverTLS, err := New(&config.Config{AppHTTPServer: ":443", TLSAutocertDomains: []string{"api.example.com"}, TLSAutocertCacheDir: t.TempDir()})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), serverTLS.Config().MinVersion)
+	assert.Contains(t, serverTLS.Config().NextProtos, "h2")
+	assert.NotNil(t, serverTLS.Config().GetCertificate)
+}
+
+func TestTLS_ServesHTTP2(t *testing.T) {
+	certFile, keyFile := writeCertificate(t)
+	serverTLS, err := New(&config.Config{AppHTTPServer: ":443", TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+		TLSConfig: serverTLS.Config(),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + ln.Addr().String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+}
+
+func TestTLS_RedirectHandler(t *testing.T) {
+	tests := []struct {
+		name             string
+		cfg              *config.Config
+		method           string
+		target           string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "default port",
+			cfg:              &config.Config{AppHTTPServer: ":443"},
+			method:           http.MethodGet,
+			target:           "http://api.example.com/api/v1/users?page=2",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://api.example.com/api/v1/users?page=2",
+		},
+		{
+			name:             "other port",
+			cfg:              &config.Config{AppHTTPServer: ":8443"},
+			method:           http.MethodGet,
+			target:           "http://api.example.com:8080/health",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://api.example.com:8443/health",
+		},
+		{
+			name:             "method with a body",
+			cfg:              &config.Config{AppHTTPServer: ":443"},
+			method:           http.MethodPost,
+			target:           "http://api.example.com/api/v1/users",
+			expectedStatus:   http.StatusPermanentRedirect,
+			expectedLocation: "https://api.example.com/api/v1/users",
+		},
+		{
+			name:             "autocert domain",
+			cfg:              &config.Config{AppHTTPServer: ":443", TLSAutocertDomains: []string{"api.example.com"}},
+			method:           http.MethodGet,
+			target:           "http://api.example.com/health",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://api.example.com/health",
+		},
+		{
+			name:           "unknown host with autocert",
+			cfg:            &config.Config{AppHTTPServer: ":443", TLSAutocertDomains: []string{"api.example.com"}},
+			method:         http.MethodGet,
+			target:         "http://evil.example.com/health",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cfg.TLSCertFile == "" && len(tt.cfg.TLSAutocertDomains) == 0 {
+				tt.cfg.TLSCertFile, tt.cfg.TLSKeyFile = writeCertificate(t)
+			}
+			tt.cfg.TLSAutocertCacheDir = t.TempDir()
+			serverTLS, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			serverTLS.RedirectHandler().ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type AdminDashboardService interface {
+	// Get returns the admin dashboard, computed at most once per
+	// constants.AdminDashboardCacheTTL
+	Get(ctx context.Context) (*dtos.AdminDashboardResponse, error)
+}
+
+// adminDashboardService aggregates the stats of the admin frontends from the read models,
+// the aggregate queries and the health registry
+type adminDashboardService struct {
+	statsRepo repositories.AdminStatsRepository
+	health    *monitoring.HealthRegistry
+	cache     cache.Cache
+}
+
+// ProvideAdminDashboardService creates a new admin dashboard service
+func ProvideAdminDashboardService(
+	statsRepo repositories.AdminStatsRepository,
+	health *monitoring.HealthRegistry,
+	cache cache.Cache,
+) AdminDashboardService {
+	return &adminDashboardService{
+		statsRepo: statsRepo,
+		health:    health,
+		cache:     cache,
+	}
+}
+
+func (s *adminDashboardService) Get(ctx context.Context) (*dtos.AdminDashboardResponse, error) {
+	if dashboard := s.cached(ctx); dashboard != nil {
+		return dashboard, nil
+	}
+
+	dashboard, err := s.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cache failure only costs the next request a recomputation
+	if data, err := json.Marshal(dashboard); err == nil {
+		if err := s.cache.Set(ctx, constants.AdminDashboardCacheKey, string(data), constants.AdminDashboardCacheTTL); err != nil {
+			logger.Log.Warn("Failed to cache admin dashboard", zap.Error(err))
+		}
+	}
+
+	return dashboard, nil
+}
+
+// cached returns the dashboard in the cache, or nil on a miss
+func (s *adminDashboardService) cached(ctx context.Context) *dtos.AdminDashboardResponse {
+	data, err := s.cache.Get(ctx, constants.AdminDashboardCacheKey)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+			logger.Log.Warn("Failed to read cached admin dashboard", zap.Error(err))
+		}
+		return nil
+	}
+
+	var dashboard dtos.AdminDashboardResponse
+	if err := json.Unmarshal([]byte(data), &dashboard); err != nil {
+		logger.Log.Warn("Failed to decode cached admin dashboard", zap.Error(err))
+		return nil
+	}
+
+	return &dashboard
+}
+
+func (s *adminDashboardService) compute(ctx context.Context) (*dtos.AdminDashboardResponse, error) {
+	now := time.Now().UTC()
+
+	users, err := s.statsRepo.GetUserStats(now.Add(-24*time.Hour), now.Add(-7*24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	signups, err := s.statsRepo.GetRecentSignups(constants.AdminDashboardRecentSignups)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants, err := s.statsRepo.GetTenantStats()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.statsRepo.GetFailingWebhooks(now.Add(-constants.AdminDashboardFailureWindow), constants.AdminDashboardFailingWebhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.statsRepo.GetJobQueueStats()
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &dtos.AdminDashboardResponse{
+		Users:           *users,
+		RecentSignups:   make([]dtos.AdminRecentSignup, 0, len(signups)),
+		Tenants:         *tenants,
+		FailingWebhooks: webhooks,
+		Jobs:            *jobs,
+		Health:          healthStats(s.health.Check(ctx)),
+		GeneratedAt:     now,
+	}
+	if dashboard.FailingWebhooks == nil {
+		dashboard.FailingWebhooks = []dtos.AdminFailingWebhook{}
+	}
+	for _, user := range signups {
+		dashboard.RecentSignups = append(dashboard.RecentSignups, dtos.AdminRecentSignup{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+
+	return dashboard, nil
+}
+
+// healthStats maps a health report to the dashboard
+func healthStats(report monitoring.HealthReport) dtos.AdminHealthStats {
+	stats := dtos.AdminHealthStats{
+		Status:       report.Status,
+		Dependencies: make([]dtos.AdminDependencyHealth, 0, len(report.Dependencies)),
+	}
+	for _, dependency := range report.Dependencies {
+		stats.Dependencies = append(stats.Dependencies, dtos.AdminDependencyHealth{
+			Name:      dependency.Name,
+			Status:    dependency.Status,
+			Critical:  dependency.Critical,
+			LatencyMs: dependency.LatencyMs,
+			Error:     dependency.Error,
+		})
+	}
+
+	return stats
+}
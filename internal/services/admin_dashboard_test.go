@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAdminStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminStatsRepository) GetUserStats(day time.Time, week time.Time) (*dtos.AdminUserStats, error) {
+	args := m.Called(day, week)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.AdminUserStats), args.Error(1)
+}
+
+func (m *MockAdminStatsRepository) GetRecentSignups(limit int) ([]models.User, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockAdminStatsRepository) GetTenantStats() (*dtos.AdminTenantStats, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.AdminTenantStats), args.Error(1)
+}
+
+func (m *MockAdminStatsRepository) GetFailingWebhooks(since time.Time, limit int) ([]dtos.AdminFailingWebhook, error) {
+	args := m.Called(since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.AdminFailingWebhook), args.Error(1)
+}
+
+func (m *MockAdminStatsRepository) GetJobQueueStats() (*dtos.AdminJobQueueStats, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.AdminJobQueueStats), args.Error(1)
+}
+
+func TestAdminDashboardService_Get(t *testing.T) {
+	cachedDashboard := dtos.AdminDashboardResponse{Users: dtos.AdminUserStats{Active: 99}}
+	cachedData, err := json.Marshal(cachedDashboard)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		cached         string
+		cacheErr       error
+		tenantsErr     error
+		expectedActive int64
+		expectedError  errors.ErrorType
+		expectComputed bool
+	}{
+		{
+			name:           "served from the cache",
+			cached:         string(cachedData),
+			expectedActive: 99,
+		},
+		{
+			name:           "computed on a miss",
+			cacheErr:       errors.NotFoundError("Cache key", nil),
+			expectedActive: 12,
+			expectComputed: true,
+		},
+		{
+			name:           "computed when the cache fails",
+			cacheErr:       errors.InternalError("Failed to get cache value", nil),
+			expectedActive: 12,
+			expectComputed: true,
+		},
+		{
+			name:           "computed when the cached value is corrupt",
+			cached:         "{",
+			expectedActive: 12,
+			expectComputed: true,
+		},
+		{
+			name:          "database failure",
+			cacheErr:      errors.NotFoundError("Cache key", nil),
+			tenantsErr:    errors.DatabaseError("Failed to sum company summaries", nil),
+			expectedError: errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statsRepo := new(MockAdminStatsRepository)
+			mockCache := new(MockCache)
+			health := monitoring.NewHealthRegistry(time.Second, monitoring.HealthChecker{
+				Name:     "database",
+				Critical: true,
+				Check:    func(ctx context.Context) error { return nil },
+			})
+			service := ProvideAdminDashboardService(statsRepo, health, mockCache)
+
+			mockCache.On("Get", mock.Anything, constants.AdminDashboardCacheKey).Return(tt.cached, tt.cacheErr)
+			mockCache.On("Set", mock.Anything, constants.AdminDashboardCacheKey, mock.Anything, constants.AdminDashboardCacheTTL).Return(nil).Maybe()
+			statsRepo.On("GetUserStats", mock.Anything, mock.Anything).Return(&dtos.AdminUserStats{Active: 12}, nil).Maybe()
+			signup := factory.User().WithID("user-1").WithName("John", "Doe").WithEmail("john.doe@example.com").Build()
+			statsRepo.On("GetRecentSignups", constants.AdminDashboardRecentSignups).Return([]models.User{*signup}, nil).Maybe()
+			if tt.tenantsErr != nil {
+				statsRepo.On("GetTenantStats").Return(nil, tt.tenantsErr).Maybe()
+			} else {
+				statsRepo.On("GetTenantStats").Return(&dtos.AdminTenantStats{Companies: 3}, nil).Maybe()
+			}
+			statsRepo.On("GetFailingWebhooks", mock.Anything, constants.AdminDashboardFailingWebhooks).Return(nil, nil).Maybe()
+			statsRepo.On("GetJobQueueStats").Return(&dtos.AdminJobQueueStats{Pending: 2}, nil).Maybe()
+
+			dashboard, err := service.Get(context.Background())
+
+			if tt.expectedError != "" {
+				appErr := errors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedActive, dashboard.Users.Active)
+			if !tt.expectComputed {
+				statsRepo.AssertNotCalled(t, "GetUserStats", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, []dtos.AdminRecentSignup{{ID: "user-1", Email: "john.doe@example.com", FirstName: "John", LastName: "Doe"}}, dashboard.RecentSignups)
+			assert.Empty(t, dashboard.FailingWebhooks)
+			assert.NotNil(t, dashboard.FailingWebhooks)
+			assert.Equal(t, monitoring.HealthStatusHealthy, dashboard.Health.Status)
+			require.Len(t, dashboard.Health.Dependencies, 1)
+			assert.Equal(t, "database", dashboard.Health.Dependencies[0].Name)
+			mockCache.AssertCalled(t, "Set", mock.Anything, constants.AdminDashboardCacheKey, mock.Anything, constants.AdminDashboardCacheTTL)
+		})
+	}
+}
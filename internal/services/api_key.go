@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/getsentry/sentry-go"
+	"go.uber.org/zap"
+)
+
+type APIKeyService interface {
+	// Create returns the new key with its secret, which is not stored and cannot be read again
+	Create(ctx context.Context, companyID string, req *dtos.CreateAPIKeyRequest) (*models.APIKey, string, error)
+	List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.APIKey], error)
+	GetOneByID(ctx context.Context, companyID string, keyID string) (*models.APIKey, error)
+	Revoke(ctx context.Context, companyID string, keyID string) (*models.APIKey, error)
+	Usage(ctx context.Context, companyID string, keyID string, days int) (*dtos.APIKeyUsageResponse, error)
+	// Authenticate returns the active key matching a secret sent by a client
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+	// CheckUnused reports the keys unused for API_KEY_UNUSED_ALERT_DAYS, once, and revokes
+	// the keys unused for API_KEY_UNUSED_EXPIRY_DAYS
+	CheckUnused(ctx context.Context) (*dtos.APIKeyHygieneResult, error)
+}
+
+// apiKeyService handles the API keys of the companies
+type apiKeyService struct {
+	apiKeyRepo  repositories.APIKeyRepository
+	companyRepo repositories.CompanyRepository
+	cfg         *config.Config
+}
+
+// ProvideAPIKeyService creates a new API key service
+func ProvideAPIKeyService(
+	apiKeyRepo repositories.APIKeyRepository,
+	companyRepo repositories.CompanyRepository,
+	cfg *config.Config,
+) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		companyRepo: companyRepo,
+		cfg:         cfg,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, companyID string, req *dtos.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, "", errors.NotFoundError("Company", err).
+			WithOperation("create_api_key").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	prefix := constants.APIKeyPrefix
+	if req.Sandbox {
+		if company, err = s.getOrCreateSandbox(company); err != nil {
+			return nil, "", err
+		}
+		prefix = constants.APIKeySandboxPrefix
+	} else if company.SandboxOfID != nil {
+		return nil, "", errors.ValidationError("A sandbox company only has sandbox API keys", nil).
+			WithOperation("create_api_key").
+			WithResource("api_key").
+			WithContext("company_id", companyID)
+	}
+
+	secret := make([]byte, constants.APIKeySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", errors.InternalError("Failed to generate API key", err).
+			WithOperation("create_api_key").
+			WithResource("api_key")
+	}
+	rawKey := prefix + base64.RawURLEncoding.EncodeToString(secret)
+	// Sandbox keys show as many characters of their secret as the production keys
+	displayLength := constants.APIKeyDisplayLength + len(prefix) - len(constants.APIKeyPrefix)
+
+	key := &models.APIKey{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: company.ID,
+		Name:      req.Name,
+		Prefix:    rawKey[:displayLength],
+		KeyHash:   hashAPIKey(rawKey),
+		Sandbox:   req.Sandbox,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		s.logError("create_api_key", key, err)
+		return nil, "", err
+	}
+
+	logger.Log.Info("API key created",
+		zap.String("company_id", key.CompanyID),
+		zap.String("api_key_id", key.ID),
+		zap.String("prefix", key.Prefix),
+		zap.Bool("sandbox", key.Sandbox),
+	)
+
+	return key, rawKey, nil
+}
+
+// getOrCreateSandbox returns the sandbox tenant of a company, created on its first
+// sandbox key. A sandbox company is its own sandbox.
+func (s *apiKeyService) getOrCreateSandbox(company *models.Company) (*models.Company, error) {
+	if company.SandboxOfID != nil {
+		return company, nil
+	}
+
+	sandbox, err := s.companyRepo.GetSandbox(company.ID)
+	if err == nil {
+		return sandbox, nil
+	}
+	if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+		return nil, err
+	}
+
+	sandbox, err = s.companyRepo.Create(&models.Company{
+		BaseModel:   models.NewBaseModel(),
+		Name:        company.Name + constants.SandboxCompanyNameSuffix,
+		SandboxOfID: &company.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Sandbox company created",
+		zap.String("company_id", company.ID),
+		zap.String("sandbox_id", sandbox.ID),
+	)
+
+	return sandbox, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.APIKey], error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_api_keys").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return s.apiKeyRepo.GetByCompanyID(companyID, pageableRequest)
+}
+
+func (s *apiKeyService) GetOneByID(ctx context.Context, companyID string, keyID string) (*models.APIKey, error) {
+	return s.apiKeyRepo.GetByID(companyID, keyID)
+}
+
+// Revoke stops a key from authenticating requests. The key is kept with its usage.
+func (s *apiKeyService) Revoke(ctx context.Context, companyID string, keyID string) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(companyID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	revokedAt := time.Now()
+	key.RevokedAt = &revokedAt
+	if err := s.apiKeyRepo.Revoke(key); err != nil {
+		s.logError("revoke_api_key", key, err)
+		return nil, err
+	}
+
+	logger.Log.Info("API key revoked",
+		zap.String("company_id", companyID),
+		zap.String("api_key_id", key.ID),
+	)
+
+	return key, nil
+}
+
+// Usage returns the calls of the key per day and per endpoint over the last days, today
+// included
+func (s *apiKeyService) Usage(ctx context.Context, companyID string, keyID string, days int) (*dtos.APIKeyUsageResponse, error) {
+	if days <= 0 {
+		days = constants.APIKeyUsageDefaultDays
+	}
+	if days > constants.APIKeyUsageMaxDays {
+		days = constants.APIKeyUsageMaxDays
+	}
+
+	key, err := s.apiKeyRepo.GetByID(companyID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, now.Location())
+	daily, err := s.apiKeyRepo.GetDailyUsage(key.ID, since)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := s.apiKeyRepo.GetEndpointUsage(key.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Days without calls are left out; the lists are never null
+	usage := &dtos.APIKeyUsageResponse{
+		APIKeyID:   key.ID,
+		Days:       days,
+		LastUsedAt: key.LastUsedAt,
+		Daily:      append([]dtos.APIKeyDailyUsage{}, daily...),
+		Endpoints:  append([]dtos.APIKeyEndpointUsage{}, endpoints...),
+	}
+	for _, day := range daily {
+		usage.TotalCalls += day.Calls
+	}
+
+	return usage, nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, constants.APIKeyPrefix) {
+		return nil, errors.UnauthorizedError("Invalid API key", nil).
+			WithOperation("authenticate_api_key").
+			WithResource("api_key")
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(hashAPIKey(rawKey))
+	if err != nil {
+		if errors.GetHTTPStatus(err) == http.StatusNotFound {
+			return nil, errors.UnauthorizedError("Invalid API key", err).
+				WithOperation("authenticate_api_key").
+				WithResource("api_key")
+		}
+		return nil, err
+	}
+
+	if !key.Active(time.Now()) {
+		return nil, errors.UnauthorizedError("API key is revoked or expired", nil).
+			WithOperation("authenticate_api_key").
+			WithResource("api_key").
+			WithContext("api_key_id", key.ID)
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyService) CheckUnused(ctx context.Context) (*dtos.APIKeyHygieneResult, error) {
+	now := time.Now()
+	keys, err := s.apiKeyRepo.GetUnused(now.AddDate(0, 0, -s.cfg.APIKeyUnusedAlertDays))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.APIKeyHygieneResult{}
+	var alerted []string
+	for i := range keys {
+		key := &keys[i]
+		lastActivity := key.CreatedAt
+		if key.LastUsedAt != nil {
+			lastActivity = *key.LastUsedAt
+		}
+		fields := []zap.Field{
+			zap.String("company_id", key.CompanyID),
+			zap.String("api_key_id", key.ID),
+			zap.String("prefix", key.Prefix),
+			zap.Time("last_activity", lastActivity),
+		}
+
+		if s.cfg.APIKeyUnusedExpiryDays > 0 && lastActivity.Before(now.AddDate(0, 0, -s.cfg.APIKeyUnusedExpiryDays)) {
+			revokedAt := now
+			key.RevokedAt = &revokedAt
+			if err := s.apiKeyRepo.Revoke(key); err != nil {
+				return result, err
+			}
+			result.Revoked++
+			logger.Log.Warn("Unused API key revoked", fields...)
+			continue
+		}
+
+		if key.UnusedAlertedAt != nil {
+			continue
+		}
+		alerted = append(alerted, key.ID)
+		logger.Log.Warn("API key unused", fields...)
+		if hub := sentry.GetHubFromContext(ctx); hub != nil {
+			hub.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("service", "api_key_service")
+				scope.SetExtra("company_id", key.CompanyID)
+				scope.SetExtra("api_key_id", key.ID)
+				scope.SetExtra("last_activity", lastActivity)
+				hub.CaptureMessage(fmt.Sprintf("API key %s unused for %d days", key.Prefix, int(now.Sub(lastActivity).Hours()/24)))
+			})
+		}
+	}
+
+	if err := s.apiKeyRepo.MarkUnusedAlerted(alerted, now); err != nil {
+		return result, err
+	}
+	result.Alerted = len(alerted)
+
+	return result, nil
+}
+
+func (s *apiKeyService) logError(operation string, key *models.APIKey, err error) {
+	logger.Log.Error("API key operation failed",
+		zap.String("operation", operation),
+		zap.String("company_id", key.CompanyID),
+		zap.String("api_key_id", key.ID),
+		zap.Error(err),
+	)
+}
+
+// hashAPIKey returns the stored form of a key. The keys are random, so a fast unsalted
+// hash is enough to look them up without keeping them.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
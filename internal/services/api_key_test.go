@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key *models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByID(companyID string, id string) (*models.APIKey, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.APIKey], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.APIKey]), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(key *models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) RecordUsage(usages []models.APIKeyUsage, lastUsed map[string]time.Time) error {
+	args := m.Called(usages, lastUsed)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetDailyUsage(keyID string, since time.Time) ([]dtos.APIKeyDailyUsage, error) {
+	args := m.Called(keyID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.APIKeyDailyUsage), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetEndpointUsage(keyID string, since time.Time) ([]dtos.APIKeyEndpointUsage, error) {
+	args := m.Called(keyID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.APIKeyEndpointUsage), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetUnused(before time.Time) ([]models.APIKey, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) MarkUnusedAlerted(ids []string, alertedAt time.Time) error {
+	args := m.Called(ids, alertedAt)
+	return args.Error(0)
+}
+
+func newTestAPIKeyService(apiKeyRepo *MockAPIKeyRepository, companyRepo *MockCompanyRepositoryForCompanyService) *apiKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		companyRepo: companyRepo,
+		cfg: &config.Config{
+			APIKeyUnusedAlertDays:  30,
+			APIKeyUnusedExpiryDays: 90,
+		},
+	}
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := newTestAPIKeyService(apiKeyRepo, companyRepo)
+
+	companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+	apiKeyRepo.On("Create", mock.AnythingOfType("*models.APIKey")).Return(nil)
+
+	days := 7
+	key, rawKey, err := service.Create(context.Background(), "company-1", &dtos.CreateAPIKeyRequest{Name: "CRM sync", ExpiresInDays: &days})
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, constants.APIKeyPrefix))
+	assert.Equal(t, rawKey[:constants.APIKeyDisplayLength], key.Prefix)
+	assert.Equal(t, hashAPIKey(rawKey), key.KeyHash, "only the hash of the key is stored")
+	require.NotNil(t, key.ExpiresAt)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 7), *key.ExpiresAt, time.Minute)
+
+	// The key authenticates by its hash
+	apiKeyRepo.On("GetByHash", key.KeyHash).Return(key, nil)
+	authenticated, err := service.Authenticate(context.Background(), rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, key, authenticated)
+
+	apiKeyRepo.AssertExpectations(t)
+	companyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_Create_Sandbox(t *testing.T) {
+	companyID := "00000000-0000-0000-0000-000000000001"
+	company := factory.Company().WithID(companyID).WithName("Acme").Build()
+	sandboxCompany := factory.Company().WithID("00000000-0000-0000-0000-000000000002").WithName("Acme (Sandbox)").SandboxOf(company).Build()
+
+	tests := []struct {
+		name          string
+		companyID     string
+		sandbox       bool
+		setupMocks    func(*MockCompanyRepositoryForCompanyService)
+		expectedError bool
+	}{
+		{
+			name:      "first sandbox key creates the sandbox",
+			companyID: companyID,
+			sandbox:   true,
+			setupMocks: func(repo *MockCompanyRepositoryForCompanyService) {
+				repo.On("GetOneByID", companyID).Return(company, nil)
+				repo.On("GetSandbox", companyID).Return(nil, errors.NotFoundError("Sandbox company", nil))
+				repo.On("Create", mock.MatchedBy(func(created *models.Company) bool {
+					return created.Name == "Acme (Sandbox)" && *created.SandboxOfID == companyID
+				})).Return(sandboxCompany, nil)
+			},
+		},
+		{
+			name:      "existing sandbox is reused",
+			companyID: companyID,
+			sandbox:   true,
+			setupMocks: func(repo *MockCompanyRepositoryForCompanyService) {
+				repo.On("GetOneByID", companyID).Return(company, nil)
+				repo.On("GetSandbox", companyID).Return(sandboxCompany, nil)
+			},
+		},
+		{
+			name:      "sandbox company is its own sandbox",
+			companyID: sandboxCompany.ID,
+			sandbox:   true,
+			setupMocks: func(repo *MockCompanyRepositoryForCompanyService) {
+				repo.On("GetOneByID", sandboxCompany.ID).Return(sandboxCompany, nil)
+			},
+		},
+		{
+			name:      "production key of a sandbox company",
+			companyID: sandboxCompany.ID,
+			setupMocks: func(repo *MockCompanyRepositoryForCompanyService) {
+				repo.On("GetOneByID", sandboxCompany.ID).Return(sandboxCompany, nil)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := newTestAPIKeyService(apiKeyRepo, companyRepo)
+			tt.setupMocks(companyRepo)
+			apiKeyRepo.On("Create", mock.AnythingOfType("*models.APIKey")).Return(nil).Maybe()
+
+			key, rawKey, err := service.Create(context.Background(), tt.companyID, &dtos.CreateAPIKeyRequest{Name: "Partner", Sandbox: tt.sandbox})
+
+			if tt.expectedError {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+				apiKeyRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, key.Sandbox)
+			assert.Equal(t, sandboxCompany.ID, key.CompanyID, "the key belongs to the sandbox tenant")
+			assert.True(t, strings.HasPrefix(rawKey, constants.APIKeySandboxPrefix))
+			assert.Len(t, key.Prefix, len(constants.APIKeySandboxPrefix)+constants.APIKeyDisplayLength-len(constants.APIKeyPrefix))
+			companyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate_Errors(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	company := factory.Company().Build()
+
+	tests := []struct {
+		name       string
+		rawKey     string
+		setupMocks func(*MockAPIKeyRepository)
+	}{
+		{
+			name:   "missing prefix",
+			rawKey: "not-a-key",
+		},
+		{
+			name:   "unknown key",
+			rawKey: constants.APIKeyPrefix + "unknown",
+			setupMocks: func(repo *MockAPIKeyRepository) {
+				repo.On("GetByHash", hashAPIKey(constants.APIKeyPrefix+"unknown")).Return(nil, errors.NotFoundError("API key", nil))
+			},
+		},
+		{
+			name:   "revoked key",
+			rawKey: constants.APIKeyPrefix + "revoked",
+			setupMocks: func(repo *MockAPIKeyRepository) {
+				repo.On("GetByHash", hashAPIKey(constants.APIKeyPrefix+"revoked")).Return(factory.APIKey(company).RevokedAt(past).Build(), nil)
+			},
+		},
+		{
+			name:   "expired key",
+			rawKey: constants.APIKeyPrefix + "expired",
+			setupMocks: func(repo *MockAPIKeyRepository) {
+				repo.On("GetByHash", hashAPIKey(constants.APIKeyPrefix+"expired")).Return(factory.APIKey(company).ExpiresAt(past).Build(), nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(apiKeyRepo)
+			}
+			service := newTestAPIKeyService(apiKeyRepo, new(MockCompanyRepositoryForCompanyService))
+
+			key, err := service.Authenticate(context.Background(), tt.rawKey)
+
+			require.Error(t, err)
+			assert.Nil(t, key)
+			assert.Equal(t, errors.ErrorTypeUnauthorized, errors.GetAppError(err).Type)
+			apiKeyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_Usage(t *testing.T) {
+	tests := []struct {
+		name         string
+		days         int
+		expectedDays int
+	}{
+		{name: "default", days: 0, expectedDays: constants.APIKeyUsageDefaultDays},
+		{name: "custom", days: 7, expectedDays: 7},
+		{name: "capped", days: 365, expectedDays: constants.APIKeyUsageMaxDays},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			service := newTestAPIKeyService(apiKeyRepo, new(MockCompanyRepositoryForCompanyService))
+			key := factory.APIKey(factory.Company().WithID("company-1").Build()).Build()
+
+			now := time.Now()
+			since := time.Date(now.Year(), now.Month(), now.Day()-tt.expectedDays+1, 0, 0, 0, 0, now.Location())
+			apiKeyRepo.On("GetByID", "company-1", key.ID).Return(key, nil)
+			apiKeyRepo.On("GetDailyUsage", key.ID, since).Return([]dtos.APIKeyDailyUsage{
+				{Day: "2026-10-14", Calls: 3},
+				{Day: "2026-10-15", Calls: 4},
+			}, nil)
+			apiKeyRepo.On("GetEndpointUsage", key.ID, since).Return(nil, nil)
+
+			usage, err := service.Usage(context.Background(), "company-1", key.ID, tt.days)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDays, usage.Days)
+			assert.Equal(t, int64(7), usage.TotalCalls)
+			assert.Len(t, usage.Daily, 2)
+			assert.NotNil(t, usage.Endpoints, "an empty list is returned rather than null")
+			apiKeyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	service := newTestAPIKeyService(apiKeyRepo, new(MockCompanyRepositoryForCompanyService))
+	key := factory.APIKey(factory.Company().WithID("company-1").Build()).Build()
+	apiKeyRepo.On("GetByID", "company-1", key.ID).Return(key, nil)
+	apiKeyRepo.On("Revoke", key).Return(nil).Once()
+
+	revoked, err := service.Revoke(context.Background(), "company-1", key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	assert.False(t, revoked.Active(time.Now()))
+
+	// Revoking again keeps the first revocation date
+	revokedAt := *revoked.RevokedAt
+	revoked, err = service.Revoke(context.Background(), "company-1", key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revokedAt, *revoked.RevokedAt)
+	apiKeyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_CheckUnused(t *testing.T) {
+	daysAgo := func(days int) time.Time { return time.Now().AddDate(0, 0, -days) }
+	alertedAt := daysAgo(10)
+
+	company := factory.Company().Build()
+	unused := factory.APIKey(company).CreatedAt(daysAgo(45)).Build()
+	alreadyAlerted := factory.APIKey(company).CreatedAt(daysAgo(60)).UnusedAlertedAt(alertedAt).Build()
+	expired := factory.APIKey(company).CreatedAt(daysAgo(200)).LastUsedAt(daysAgo(100)).Build()
+
+	tests := []struct {
+		name            string
+		expiryDays      int
+		expectedAlerted []string
+		expectedRevoked []string
+	}{
+		{
+			name:            "alerts once and revokes after the expiry",
+			expiryDays:      90,
+			expectedAlerted: []string{unused.ID},
+			expectedRevoked: []string{expired.ID},
+		},
+		{
+			name:            "expiry disabled",
+			expiryDays:      0,
+			expectedAlerted: []string{unused.ID, expired.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepo := new(MockAPIKeyRepository)
+			service := newTestAPIKeyService(apiKeyRepo, new(MockCompanyRepositoryForCompanyService))
+			service.cfg.APIKeyUnusedExpiryDays = tt.expiryDays
+
+			keys := []models.APIKey{*unused, *alreadyAlerted, *expired}
+			apiKeyRepo.On("GetUnused", mock.MatchedBy(func(before time.Time) bool {
+				return before.Sub(daysAgo(30)).Abs() < time.Minute
+			})).Return(keys, nil)
+			var revoked []string
+			apiKeyRepo.On("Revoke", mock.AnythingOfType("*models.APIKey")).
+				Run(func(args mock.Arguments) {
+					key := args.Get(0).(*models.APIKey)
+					assert.NotNil(t, key.RevokedAt)
+					revoked = append(revoked, key.ID)
+				}).
+				Return(nil).Maybe()
+			apiKeyRepo.On("MarkUnusedAlerted", tt.expectedAlerted, mock.AnythingOfType("time.Time")).Return(nil)
+
+			result, err := service.CheckUnused(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.expectedAlerted), result.Alerted)
+			assert.Equal(t, len(tt.expectedRevoked), result.Revoked)
+			assert.Equal(t, tt.expectedRevoked, revoked)
+			apiKeyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyUsageRecorder_Flush(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	recorder := ProvideAPIKeyUsageRecorder(&config.Config{APIKeyUsageFlushInterval: time.Hour}, apiKeyRepo)
+
+	recorder.Record("key-1", "GET /api/v1/companies/:id")
+	recorder.Record("key-1", "GET /api/v1/companies/:id")
+	recorder.Record("key-1", "GET /api/v1/companies/:id/members")
+	recorder.Record("key-2", "GET /api/v1/companies/:id")
+
+	// A failed flush keeps the counts for the next one
+	apiKeyRepo.On("RecordUsage", mock.Anything, mock.Anything).Return(stderrors.New("connection refused")).Once()
+	require.Error(t, recorder.Flush())
+	recorder.Record("key-2", "GET /api/v1/companies/:id")
+
+	var flushed map[string]int64
+	var lastUsed map[string]time.Time
+	apiKeyRepo.On("RecordUsage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			flushed = make(map[string]int64)
+			for _, usage := range args.Get(0).([]models.APIKeyUsage) {
+				flushed[usage.APIKeyID+" "+usage.Endpoint] += usage.Calls
+			}
+			lastUsed = args.Get(1).(map[string]time.Time)
+		}).
+		Return(nil).Once()
+	require.NoError(t, recorder.Flush())
+
+	assert.Equal(t, map[string]int64{
+		"key-1 GET /api/v1/companies/:id":         2,
+		"key-1 GET /api/v1/companies/:id/members": 1,
+		"key-2 GET /api/v1/companies/:id":         2,
+	}, flushed)
+	assert.Len(t, lastUsed, 2)
+
+	// Nothing left to flush
+	require.NoError(t, recorder.Flush())
+	apiKeyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyUsageRecorder_StopFlushes(t *testing.T) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	recorder := ProvideAPIKeyUsageRecorder(&config.Config{APIKeyUsageFlushInterval: time.Hour}, apiKeyRepo)
+	apiKeyRepo.On("RecordUsage", mock.Anything, mock.Anything).Return(nil).Once()
+
+	recorder.Start()
+	recorder.Record("key-1", "GET /api/v1/companies/:id")
+	require.NoError(t, recorder.Stop(context.Background()))
+
+	apiKeyRepo.AssertExpectations(t)
+}
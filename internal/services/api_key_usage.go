@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// apiKeyUsageKey identifies a daily counter of the calls of a key to an endpoint
+type apiKeyUsageKey struct {
+	keyID    string
+	day      time.Time
+	endpoint string
+}
+
+// APIKeyUsageRecorder counts the calls made with API keys in memory and adds them to the
+// database every API_KEY_USAGE_FLUSH_INTERVAL, so that authenticated requests never wait
+// on a write. Counts that fail to flush are kept for the next flush.
+type APIKeyUsageRecorder struct {
+	repo     repositories.APIKeyRepository
+	interval time.Duration
+
+	mu       sync.Mutex
+	calls    map[apiKeyUsageKey]int64
+	lastUsed map[string]time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// ProvideAPIKeyUsageRecorder creates the usage recorder, started with the HTTP server
+func ProvideAPIKeyUsageRecorder(cfg *config.Config, repo repositories.APIKeyRepository) *APIKeyUsageRecorder {
+	return &APIKeyUsageRecorder{
+		repo:     repo,
+		interval: cfg.APIKeyUsageFlushInterval,
+		calls:    make(map[apiKeyUsageKey]int64),
+		lastUsed: make(map[string]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record counts a call made with the key to the endpoint
+func (r *APIKeyUsageRecorder) Record(keyID string, endpoint string) {
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[apiKeyUsageKey{keyID: keyID, day: day, endpoint: endpoint}]++
+	if now.After(r.lastUsed[keyID]) {
+		r.lastUsed[keyID] = now
+	}
+}
+
+// Start flushes the counts periodically until Stop
+func (r *APIKeyUsageRecorder) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					logger.Log.Warn("Failed to flush API key usage", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flushes and flushes the remaining counts. It must run before
+// the database connections are closed.
+func (r *APIKeyUsageRecorder) Stop(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return r.Flush()
+}
+
+// Flush adds the counts recorded since the last flush to the database
+func (r *APIKeyUsageRecorder) Flush() error {
+	r.mu.Lock()
+	calls, lastUsed := r.calls, r.lastUsed
+	r.calls = make(map[apiKeyUsageKey]int64)
+	r.lastUsed = make(map[string]time.Time)
+	r.mu.Unlock()
+
+	if len(calls) == 0 {
+		return nil
+	}
+
+	usages := make([]models.APIKeyUsage, 0, len(calls))
+	for key, count := range calls {
+		usages = append(usages, models.APIKeyUsage{
+			BaseModel: models.NewBaseModel(),
+			APIKeyID:  key.keyID,
+			Day:       key.day,
+			Endpoint:  key.endpoint,
+			Calls:     count,
+		})
+	}
+
+	if err := r.repo.RecordUsage(usages, lastUsed); err != nil {
+		r.restore(calls, lastUsed)
+		return err
+	}
+
+	return nil
+}
+
+// restore merges back counts that failed to flush
+func (r *APIKeyUsageRecorder) restore(calls map[apiKeyUsageKey]int64, lastUsed map[string]time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, count := range calls {
+		r.calls[key] += count
+	}
+	for keyID, usedAt := range lastUsed {
+		if usedAt.After(r.lastUsed[keyID]) {
+			r.lastUsed[keyID] = usedAt
+		}
+	}
+}
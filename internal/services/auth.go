@@ -1,21 +1,28 @@
 package services
 
 import (
+	"context"
+
+	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
 
 	"github.com/Nerzal/gocloak/v13"
+	"go.uber.org/zap"
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
 	authProvider auth.AuthService
+	tokens       auth.TokenProvider
 }
 
 // NewAuthService creates a new auth service
-func ProvideAuthService(authProvider auth.AuthService) AuthService {
+func ProvideAuthService(authProvider auth.AuthService, tokens auth.TokenProvider) AuthService {
 	return AuthService{
 		authProvider: authProvider,
+		tokens:       tokens,
 	}
 }
 
@@ -53,3 +60,105 @@ func (s *AuthService) HasAnyRole(user *auth.User, roles ...string) bool {
 	}
 	return false
 }
+
+// SwitchOrganization exchanges the access token of the user for tokens bound to one of
+// their organizations, so that a frontend switches the tenant without a new login. The
+// membership is checked against Keycloak rather than the claims of the token, which only
+// hold the organization it is bound to.
+func (s *AuthService) SwitchOrganization(ctx context.Context, userID string, accessToken string, organizationID string) (*dtos.SwitchOrganizationResponse, error) {
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organizations, err := s.authProvider.ListUserOrganizations(ctx, adminToken, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var organization *auth.TenantOrganization
+	for i := range organizations {
+		if organizations[i].ID == organizationID {
+			organization = &organizations[i]
+			break
+		}
+	}
+	if organization == nil {
+		return nil, errors.ForbiddenError("User is not a member of the organization", nil).
+			WithOperation("switch_organization").
+			WithResource("organization").
+			WithContext("organization_id", organizationID)
+	}
+
+	token, err := s.authProvider.ExchangeOrganizationToken(ctx, accessToken, organization.Alias)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("User switched organization",
+		zap.String("user_id", userID),
+		zap.String("organization_id", organization.ID),
+	)
+
+	return &dtos.SwitchOrganizationResponse{
+		AccessToken:      token.AccessToken,
+		RefreshToken:     token.RefreshToken,
+		IDToken:          token.IDToken,
+		TokenType:        token.TokenType,
+		ExpiresIn:        token.ExpiresIn,
+		RefreshExpiresIn: token.RefreshExpiresIn,
+		Scope:            token.Scope,
+		Organization: dtos.OrganizationResponse{
+			ID:    organization.ID,
+			Name:  organization.Name,
+			Alias: organization.Alias,
+		},
+	}, nil
+}
+
+// Login logs a user in with their credentials, so that the first-party clients get their
+// tokens without talking to Keycloak
+func (s *AuthService) Login(ctx context.Context, requestDto dtos.LoginRequest) (*dtos.TokenResponse, error) {
+	token, err := s.authProvider.Login(ctx, requestDto.Username, requestDto.Password)
+	if err != nil {
+		return nil, err
+	}
+	return newTokenResponse(token), nil
+}
+
+// RefreshToken refreshes the tokens of a session with its refresh token
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*dtos.TokenResponse, error) {
+	token, err := s.authProvider.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return newTokenResponse(token), nil
+}
+
+// ExchangeToken exchanges the access token of the user for an access token of another
+// client of the realm, e.g. to call another service on their behalf
+func (s *AuthService) ExchangeToken(ctx context.Context, userID string, accessToken string, requestDto dtos.ExchangeTokenRequest) (*dtos.TokenResponse, error) {
+	token, err := s.authProvider.ExchangeToken(ctx, accessToken, requestDto.Audience, requestDto.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("User exchanged token",
+		zap.String("user_id", userID),
+		zap.String("audience", requestDto.Audience),
+	)
+
+	return newTokenResponse(token), nil
+}
+
+// newTokenResponse maps the tokens issued by Keycloak to the response
+func newTokenResponse(token *auth.JWT) *dtos.TokenResponse {
+	return &dtos.TokenResponse{
+		AccessToken:      token.AccessToken,
+		RefreshToken:     token.RefreshToken,
+		IDToken:          token.IDToken,
+		TokenType:        token.TokenType,
+		ExpiresIn:        token.ExpiresIn,
+		RefreshExpiresIn: token.RefreshExpiresIn,
+		Scope:            token.Scope,
+	}
+}
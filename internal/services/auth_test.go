@@ -40,6 +40,11 @@ func (m *MockAuthProvider) ValidateToken(token string) (*gocloak.IntroSpectToken
 	return args.Get(0).(*gocloak.IntroSpectTokenResult), args.Error(1)
 }
 
+func (m *MockAuthProvider) VerifyAccessToken(ctx context.Context, token string, claims *auth.TokenClaims) error {
+	args := m.Called(ctx, token, claims)
+	return args.Error(0)
+}
+
 func (m *MockAuthProvider) ClientLogin() (*auth.TokenInfo, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -77,6 +82,14 @@ func (m *MockAuthProvider) CreateUser(ctx context.Context, adminToken string, us
 	return args.Get(0).(*auth.User), args.Error(1)
 }
 
+func (m *MockAuthProvider) FindUserByEmail(ctx context.Context, adminToken string, email string) (*auth.User, error) {
+	args := m.Called(ctx, adminToken, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.User), args.Error(1)
+}
+
 func (m *MockAuthProvider) SetPassword(ctx context.Context, adminToken string, userID string, password string, temporary bool) error {
 	args := m.Called(ctx, adminToken, userID, password, temporary)
 	return args.Error(0)
@@ -110,16 +123,185 @@ func (m *MockAuthProvider) AddUserToOrganization(ctx context.Context, adminToken
 	return args.Error(0)
 }
 
+func (m *MockAuthProvider) RemoveUserFromOrganization(ctx context.Context, adminToken string, userID string, organizationID string) error {
+	args := m.Called(ctx, adminToken, userID, organizationID)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) DeleteUser(ctx context.Context, adminToken string, userID string) error {
+	args := m.Called(ctx, adminToken, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) GetMFAStatus(ctx context.Context, adminToken string, userID string) (*auth.MFAStatus, error) {
+	args := m.Called(ctx, adminToken, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.MFAStatus), args.Error(1)
+}
+
+func (m *MockAuthProvider) RequireOTP(ctx context.Context, adminToken string, userID string) error {
+	args := m.Called(ctx, adminToken, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) SendOTPEnrollmentEmail(ctx context.Context, adminToken string, userID string) error {
+	args := m.Called(ctx, adminToken, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) ResetOTP(ctx context.Context, adminToken string, userID string) error {
+	args := m.Called(ctx, adminToken, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) ListOrganizationMembers(ctx context.Context, adminToken string, organizationID string) ([]auth.User, error) {
+	args := m.Called(ctx, adminToken, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.User), args.Error(1)
+}
+
+func (m *MockAuthProvider) ListUsers(ctx context.Context, adminToken string) ([]auth.User, error) {
+	args := m.Called(ctx, adminToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.User), args.Error(1)
+}
+
+func (m *MockAuthProvider) ListOrganizations(ctx context.Context, adminToken string) ([]auth.TenantOrganization, error) {
+	args := m.Called(ctx, adminToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.TenantOrganization), args.Error(1)
+}
+
+func (m *MockAuthProvider) ListClientRoleMembers(ctx context.Context, adminToken string, roles []string) (map[string][]string, error) {
+	args := m.Called(ctx, adminToken, roles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+func (m *MockAuthProvider) GetUser(ctx context.Context, adminToken string, userID string) (*auth.User, error) {
+	args := m.Called(ctx, adminToken, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.User), args.Error(1)
+}
+
 func (m *MockAuthProvider) AddClientRolesToUser(ctx context.Context, adminToken string, userID string, clientID string, role string) error {
 	args := m.Called(ctx, adminToken, userID, clientID, role)
 	return args.Error(0)
 }
 
+func (m *MockAuthProvider) GetUserSessions(ctx context.Context, adminToken string, userID string) ([]auth.Session, error) {
+	args := m.Called(ctx, adminToken, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.Session), args.Error(1)
+}
+
+func (m *MockAuthProvider) LogoutUserSession(ctx context.Context, adminToken string, sessionID string) error {
+	args := m.Called(ctx, adminToken, sessionID)
+	return args.Error(0)
+}
+
 func (m *MockAuthProvider) UpdateUser(ctx context.Context, adminToken string, userID string, userDto *dtos.UpdateUserRequest) error {
 	args := m.Called(ctx, adminToken, userID, userDto)
 	return args.Error(0)
 }
 
+func (m *MockAuthProvider) FindTenantOrganization(ctx context.Context, adminToken string, slug string) (*auth.TenantOrganization, error) {
+	args := m.Called(ctx, adminToken, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TenantOrganization), args.Error(1)
+}
+
+func (m *MockAuthProvider) SaveTenantOrganization(ctx context.Context, adminToken string, organization *auth.TenantOrganization) (*auth.TenantOrganization, error) {
+	args := m.Called(ctx, adminToken, organization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TenantOrganization), args.Error(1)
+}
+
+func (m *MockAuthProvider) EnsureClientRoles(ctx context.Context, adminToken string, roles []string) error {
+	args := m.Called(ctx, adminToken, roles)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) ListUserOrganizations(ctx context.Context, adminToken string, userID string) ([]auth.TenantOrganization, error) {
+	args := m.Called(ctx, adminToken, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]auth.TenantOrganization), args.Error(1)
+}
+
+func (m *MockAuthProvider) ExchangeOrganizationToken(ctx context.Context, subjectToken string, organizationAlias string) (*auth.JWT, error) {
+	args := m.Called(ctx, subjectToken, organizationAlias)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.JWT), args.Error(1)
+}
+
+func (m *MockAuthProvider) Login(ctx context.Context, username string, password string) (*auth.JWT, error) {
+	args := m.Called(ctx, username, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.JWT), args.Error(1)
+}
+
+func (m *MockAuthProvider) RefreshToken(ctx context.Context, refreshToken string) (*auth.JWT, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.JWT), args.Error(1)
+}
+
+func (m *MockAuthProvider) ExchangeToken(ctx context.Context, subjectToken string, audience string, scope string) (*auth.JWT, error) {
+	args := m.Called(ctx, subjectToken, audience, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.JWT), args.Error(1)
+}
+
+func (m *MockAuthProvider) HealthCheck(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// MockTokenProvider is a mock implementation of auth.TokenProvider
+type MockTokenProvider struct {
+	mock.Mock
+}
+
+func (m *MockTokenProvider) AdminToken(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+// newMockTokenProvider returns a token provider serving admin-token
+func newMockTokenProvider() *MockTokenProvider {
+	tokens := new(MockTokenProvider)
+	tokens.On("AdminToken", mock.Anything).Return("admin-token", nil)
+	return tokens
+}
+
 func TestAuthService_ValidateUserToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -182,6 +364,173 @@ func TestAuthService_ValidateUserToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_SwitchOrganization(t *testing.T) {
+	organizations := []auth.TenantOrganization{
+		{ID: "org-1", Name: "Acme", Alias: "acme"},
+		{ID: "org-2", Name: "Globex", Alias: "globex"},
+	}
+
+	tests := []struct {
+		name           string
+		organizationID string
+		setupMock      func(*MockAuthProvider)
+		expectedError  errors.ErrorType
+		expectedAlias  string
+	}{
+		{
+			name:           "success - exchanges the token for the organization",
+			organizationID: "org-2",
+			setupMock: func(m *MockAuthProvider) {
+				m.On("ListUserOrganizations", mock.Anything, "admin-token", "user-1").Return(organizations, nil)
+				m.On("ExchangeOrganizationToken", mock.Anything, "user-token", "globex").
+					Return(&auth.JWT{AccessToken: "org-token", RefreshToken: "org-refresh", TokenType: "Bearer", ExpiresIn: 300}, nil)
+			},
+			expectedAlias: "globex",
+		},
+		{
+			name:           "error - not a member of the organization",
+			organizationID: "org-3",
+			setupMock: func(m *MockAuthProvider) {
+				m.On("ListUserOrganizations", mock.Anything, "admin-token", "user-1").Return(organizations, nil)
+			},
+			expectedError: errors.ErrorTypeForbidden,
+		},
+		{
+			name:           "error - token exchange fails",
+			organizationID: "org-1",
+			setupMock: func(m *MockAuthProvider) {
+				m.On("ListUserOrganizations", mock.Anything, "admin-token", "user-1").Return(organizations, nil)
+				m.On("ExchangeOrganizationToken", mock.Anything, "user-token", "acme").
+					Return(nil, errors.ExternalServiceError("Failed to exchange token for the organization", assert.AnError))
+			},
+			expectedError: errors.ErrorTypeExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthProvider := new(MockAuthProvider)
+			tt.setupMock(mockAuthProvider)
+
+			service := &AuthService{
+				authProvider: mockAuthProvider,
+				tokens:       newMockTokenProvider(),
+			}
+
+			result, err := service.SwitchOrganization(context.Background(), "user-1", "user-token", tt.organizationID)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "org-token", result.AccessToken)
+				assert.Equal(t, "org-refresh", result.RefreshToken)
+				assert.Equal(t, tt.organizationID, result.Organization.ID)
+				assert.Equal(t, tt.expectedAlias, result.Organization.Alias)
+			}
+
+			mockAuthProvider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMock     func(*MockAuthProvider)
+		expectedError errors.ErrorType
+	}{
+		{
+			name: "success - returns the tokens of the user",
+			setupMock: func(m *MockAuthProvider) {
+				m.On("Login", mock.Anything, "jane@example.com", "secret").
+					Return(&auth.JWT{AccessToken: "access", RefreshToken: "refresh", IDToken: "id", TokenType: "Bearer", ExpiresIn: 300, RefreshExpiresIn: 1800}, nil)
+			},
+		},
+		{
+			name: "error - invalid credentials",
+			setupMock: func(m *MockAuthProvider) {
+				m.On("Login", mock.Anything, "jane@example.com", "secret").
+					Return(nil, errors.UnauthorizedError("Invalid username or password", assert.AnError))
+			},
+			expectedError: errors.ErrorTypeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthProvider := new(MockAuthProvider)
+			tt.setupMock(mockAuthProvider)
+			service := &AuthService{authProvider: mockAuthProvider}
+
+			result, err := service.Login(context.Background(), dtos.LoginRequest{Username: "jane@example.com", Password: "secret"})
+
+			if tt.expectedError != "" {
+				appErr := errors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, &dtos.TokenResponse{
+					AccessToken:      "access",
+					RefreshToken:     "refresh",
+					IDToken:          "id",
+					TokenType:        "Bearer",
+					ExpiresIn:        300,
+					RefreshExpiresIn: 1800,
+				}, result)
+			}
+
+			mockAuthProvider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_RefreshToken(t *testing.T) {
+	mockAuthProvider := new(MockAuthProvider)
+	mockAuthProvider.On("RefreshToken", mock.Anything, "refresh").
+		Return(&auth.JWT{AccessToken: "new-access", RefreshToken: "new-refresh", TokenType: "Bearer", ExpiresIn: 300}, nil)
+	mockAuthProvider.On("RefreshToken", mock.Anything, "expired").
+		Return(nil, errors.UnauthorizedError("Invalid or expired refresh token", assert.AnError))
+	service := &AuthService{authProvider: mockAuthProvider}
+
+	result, err := service.RefreshToken(context.Background(), "refresh")
+	require.NoError(t, err)
+	assert.Equal(t, "new-access", result.AccessToken)
+	assert.Equal(t, "new-refresh", result.RefreshToken)
+
+	result, err = service.RefreshToken(context.Background(), "expired")
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrorTypeUnauthorized, appErr.Type)
+	assert.Nil(t, result)
+}
+
+func TestAuthService_ExchangeToken(t *testing.T) {
+	mockAuthProvider := new(MockAuthProvider)
+	mockAuthProvider.On("ExchangeToken", mock.Anything, "user-token", "reporting-api", "openid").
+		Return(&auth.JWT{AccessToken: "reporting-token", TokenType: "Bearer", ExpiresIn: 300}, nil)
+	mockAuthProvider.On("ExchangeToken", mock.Anything, "user-token", "billing-api", "").
+		Return(nil, errors.ForbiddenError("Token exchange not allowed for the audience", assert.AnError))
+	service := &AuthService{authProvider: mockAuthProvider}
+
+	result, err := service.ExchangeToken(context.Background(), "user-1", "user-token", dtos.ExchangeTokenRequest{Audience: "reporting-api", Scope: "openid"})
+	require.NoError(t, err)
+	assert.Equal(t, "reporting-token", result.AccessToken)
+	assert.Empty(t, result.RefreshToken)
+
+	result, err = service.ExchangeToken(context.Background(), "user-1", "user-token", dtos.ExchangeTokenRequest{Audience: "billing-api"})
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrorTypeForbidden, appErr.Type)
+	assert.Nil(t, result)
+}
+
 func TestAuthService_HasRole(t *testing.T) {
 	tests := []struct {
 		name     string
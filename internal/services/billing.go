@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/payment"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/stripe/stripe-go/v82"
+	"go.uber.org/zap"
+)
+
+// BillingService manages the subscriptions of the companies to the plans, synced with the
+// payment provider, and gates their features by the entitlements of their plan
+type BillingService interface {
+	// GetSubscription returns the plan in effect for a company and its entitlements
+	GetSubscription(ctx context.Context, companyID string) (*dtos.SubscriptionResponse, error)
+	// ChangeSubscription subscribes a company to a plan. A free plan and a change of the
+	// paid plan of a company take effect at once; the first paid plan returns the URL of
+	// the checkout and takes effect once the provider reports the subscription paid.
+	ChangeSubscription(ctx context.Context, companyID string, request *dtos.ChangeSubscriptionRequest) (*dtos.SubscriptionResponse, error)
+	// CheckEntitlement rejects the features the plan of a company does not grant
+	CheckEntitlement(ctx context.Context, companyID string, key string) error
+	// CheckQuota rejects one more use of a feature when the company already has usage of
+	// them and the limit of its plan is reached
+	CheckQuota(ctx context.Context, companyID string, key string, usage int64) error
+	// HandleSubscriptionEvent syncs the subscription of a payment event consumed from the
+	// broker
+	HandleSubscriptionEvent(ctx context.Context, msg messaging.Message) error
+}
+
+// billingService implements BillingService
+type billingService struct {
+	billingRepo repositories.BillingRepository
+	companyRepo repositories.CompanyRepository
+	adapter     payment.PaymentAdapter
+	cfg         *config.Config
+}
+
+// ProvideBillingService creates a new billing service
+func ProvideBillingService(
+	billingRepo repositories.BillingRepository,
+	companyRepo repositories.CompanyRepository,
+	adapter payment.PaymentAdapter,
+	cfg *config.Config,
+) BillingService {
+	return &billingService{
+		billingRepo: billingRepo,
+		companyRepo: companyRepo,
+		adapter:     adapter,
+		cfg:         cfg,
+	}
+}
+
+func (s *billingService) GetSubscription(ctx context.Context, companyID string) (*dtos.SubscriptionResponse, error) {
+	if err := s.checkCompany(companyID, "get_subscription"); err != nil {
+		return nil, err
+	}
+
+	plan, subscription, err := s.effectivePlan(companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewSubscriptionResponse(companyID, plan, subscription), nil
+}
+
+func (s *billingService) ChangeSubscription(ctx context.Context, companyID string, request *dtos.ChangeSubscriptionRequest) (*dtos.SubscriptionResponse, error) {
+	if err := s.checkCompany(companyID, "change_subscription"); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.billingRepo.GetPlanByKey(request.Plan)
+	if appErr := errors.GetAppError(err); (appErr != nil && appErr.Type == errors.ErrorTypeNotFound) || (err == nil && !plan.Active) {
+		return nil, errors.ValidationErrorWithDetails("Validation failed", err, map[string]string{
+			"plan": "must be an active plan",
+		}).
+			WithOperation("change_subscription").
+			WithResource("subscription").
+			WithContext("company_id", companyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.getSubscription(companyID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.PlanID == plan.ID && slices.Contains(constants.SubscriptionEntitledStatuses, current.Status) {
+		return dtos.NewSubscriptionResponse(companyID, &current.Plan, current), nil
+	}
+
+	subscription := current
+	if subscription == nil {
+		subscription = &models.Subscription{BaseModel: models.NewBaseModel(), CompanyID: companyID}
+	}
+	providerSubscriptionID := liveProviderSubscriptionID(current)
+	metadata := map[string]string{
+		constants.BillingMetadataCompanyID: companyID,
+		constants.BillingMetadataPlan:      plan.Key,
+	}
+
+	switch {
+	case plan.PriceID == nil:
+		if providerSubscriptionID != "" {
+			if _, err := s.adapter.CancelSubscription(ctx, providerSubscriptionID); err != nil {
+				return nil, s.providerError("Failed to cancel subscription", err, "change_subscription", companyID)
+			}
+		}
+		// The customer is kept for the next checkout of the company
+		subscription.PlanID = plan.ID
+		subscription.Plan = *plan
+		subscription.Status = constants.SubscriptionStatusActive
+		subscription.Provider = ""
+		subscription.ProviderSubscriptionID = nil
+		subscription.CurrentPeriodEnd = nil
+		subscription.CancelAtPeriodEnd = false
+	case providerSubscriptionID != "":
+		updated, err := s.adapter.UpdateSubscriptionPrice(ctx, providerSubscriptionID, *plan.PriceID, metadata)
+		if err != nil {
+			return nil, s.providerError("Failed to update subscription", err, "change_subscription", companyID)
+		}
+		applyProviderSubscription(subscription, plan, updated)
+	default:
+		customerID := ""
+		if current != nil {
+			customerID = current.ProviderCustomerID
+		}
+		checkout, err := s.adapter.CreateSubscriptionCheckoutSession(ctx, *plan.PriceID, customerID, metadata)
+		if err != nil {
+			return nil, s.providerError("Failed to create subscription checkout", err, "change_subscription", companyID)
+		}
+		// The sandbox completes its checkouts at once, and no event of the provider follows
+		if checkout.Status != stripe.CheckoutSessionStatusComplete || checkout.Subscription == nil {
+			effective, inEffect, err := s.effectivePlan(companyID)
+			if err != nil {
+				return nil, err
+			}
+			response := dtos.NewSubscriptionResponse(companyID, effective, inEffect)
+			response.CheckoutURL = checkout.URL
+			return response, nil
+		}
+		applyProviderSubscription(subscription, plan, checkout.Subscription)
+	}
+
+	if err := s.billingRepo.SaveSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Subscription changed",
+		zap.String("company_id", companyID),
+		zap.String("plan", plan.Key),
+		zap.String("status", subscription.Status),
+	)
+
+	return dtos.NewSubscriptionResponse(companyID, plan, subscription), nil
+}
+
+func (s *billingService) CheckEntitlement(ctx context.Context, companyID string, key string) error {
+	_, err := s.entitlement(companyID, key, "check_entitlement")
+	return err
+}
+
+func (s *billingService) CheckQuota(ctx context.Context, companyID string, key string, usage int64) error {
+	entitlement, err := s.entitlement(companyID, key, "check_quota")
+	if err != nil || entitlement == nil || entitlement.Limit == nil {
+		return err
+	}
+
+	if usage >= *entitlement.Limit {
+		return errors.ForbiddenError("Plan limit reached", nil).
+			WithOperation("check_quota").
+			WithResource("subscription").
+			WithContext("company_id", companyID).
+			WithContext("entitlement", key).
+			WithContext("limit", *entitlement.Limit)
+	}
+	return nil
+}
+
+// HandleSubscriptionEvent applies the events older than the last one applied to the
+// subscription as no-ops, since the provider does not deliver them in order. The
+// subscriptions not created by a company of the server are ignored.
+func (s *billingService) HandleSubscriptionEvent(ctx context.Context, msg messaging.Message) error {
+	var event dtos.PaymentEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return retry.Permanent(errors.ValidationError("Invalid payment event", err).
+			WithOperation("sync_subscription").
+			WithResource("subscription").
+			WithContext("message_id", msg.ID))
+	}
+	var providerSubscription stripe.Subscription
+	if err := json.Unmarshal(event.Data, &providerSubscription); err != nil || providerSubscription.ID == "" {
+		return retry.Permanent(errors.ValidationError("Invalid subscription event", err).
+			WithProvider(event.Provider).
+			WithOperation("sync_subscription").
+			WithResource("subscription").
+			WithContext("event_id", event.ID))
+	}
+
+	subscription, err := s.billingRepo.GetSubscriptionByProviderID(providerSubscription.ID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		subscription, err = s.subscriptionOfEvent(&providerSubscription)
+	}
+	if err != nil || subscription == nil {
+		return err
+	}
+	if subscription.LastEventAt != nil && event.CreatedAt.Before(*subscription.LastEventAt) {
+		logger.Log.Info("Ignoring outdated subscription event",
+			zap.String("company_id", subscription.CompanyID),
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+		)
+		return nil
+	}
+
+	var plan *models.Plan
+	if providerSubscription.Items != nil && len(providerSubscription.Items.Data) > 0 && providerSubscription.Items.Data[0].Price != nil {
+		priceID := providerSubscription.Items.Data[0].Price.ID
+		if plan, err = s.billingRepo.GetPlanByPriceID(priceID); err != nil {
+			if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+	}
+	if plan == nil && subscription.PlanID == "" {
+		return retry.Permanent(errors.ValidationError("Subscription event without a price", nil).
+			WithProvider(event.Provider).
+			WithOperation("sync_subscription").
+			WithResource("subscription").
+			WithContext("event_id", event.ID))
+	}
+
+	applyProviderSubscription(subscription, plan, &providerSubscription)
+	eventAt := event.CreatedAt
+	subscription.LastEventAt = &eventAt
+	if err := s.billingRepo.SaveSubscription(subscription); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Subscription synced",
+		zap.String("company_id", subscription.CompanyID),
+		zap.String("status", subscription.Status),
+		zap.String("event_id", event.ID),
+		zap.String("event_type", event.Type),
+	)
+	return nil
+}
+
+// subscriptionOfEvent returns the subscription of the company of a provider subscription
+// seen for the first time, nil when it belongs to no company or the company already has
+// another one in effect with the provider
+func (s *billingService) subscriptionOfEvent(providerSubscription *stripe.Subscription) (*models.Subscription, error) {
+	companyID := providerSubscription.Metadata[constants.BillingMetadataCompanyID]
+	if companyID == "" {
+		logger.Log.Info("Ignoring subscription without a company",
+			zap.String("provider_subscription_id", providerSubscription.ID),
+		)
+		return nil, nil
+	}
+
+	subscription, err := s.getSubscription(companyID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return &models.Subscription{BaseModel: models.NewBaseModel(), CompanyID: companyID}, nil
+	}
+	if current := liveProviderSubscriptionID(subscription); current != "" && current != providerSubscription.ID {
+		logger.Log.Warn("Ignoring second subscription of a company",
+			zap.String("company_id", companyID),
+			zap.String("provider_subscription_id", providerSubscription.ID),
+			zap.String("current_provider_subscription_id", current),
+		)
+		return nil, nil
+	}
+	return subscription, nil
+}
+
+// effectivePlan returns the plan in effect for a company, with the subscription granting
+// it or nil for the default plan. The plan is nil when no default plan is configured.
+func (s *billingService) effectivePlan(companyID string) (*models.Plan, *models.Subscription, error) {
+	subscription, err := s.getSubscription(companyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if subscription != nil && slices.Contains(constants.SubscriptionEntitledStatuses, subscription.Status) {
+		return &subscription.Plan, subscription, nil
+	}
+	if s.cfg.BillingDefaultPlan == "" {
+		return nil, nil, nil
+	}
+
+	plan, err := s.billingRepo.GetPlanByKey(s.cfg.BillingDefaultPlan)
+	if err != nil {
+		return nil, nil, errors.InternalError("Failed to get default plan", err).
+			WithOperation("get_default_plan").
+			WithResource("plan").
+			WithContext("plan", s.cfg.BillingDefaultPlan)
+	}
+	return plan, nil, nil
+}
+
+// entitlement returns the entitlement of the plan in effect for a company, nil when its
+// features are not restricted, and rejects the keys the plan does not grant
+func (s *billingService) entitlement(companyID string, key string, operation string) (*models.Entitlement, error) {
+	plan, _, err := s.effectivePlan(companyID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	for i := range plan.Entitlements {
+		if plan.Entitlements[i].Key == key {
+			return &plan.Entitlements[i], nil
+		}
+	}
+	return nil, errors.ForbiddenError("Feature not included in the plan", nil).
+		WithOperation(operation).
+		WithResource("subscription").
+		WithContext("company_id", companyID).
+		WithContext("entitlement", key).
+		WithContext("plan", plan.Key)
+}
+
+// getSubscription returns the subscription of a company, nil when it never subscribed
+func (s *billingService) getSubscription(companyID string) (*models.Subscription, error) {
+	subscription, err := s.billingRepo.GetSubscription(companyID)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *billingService) checkCompany(companyID string, operation string) error {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	return nil
+}
+
+func (s *billingService) providerError(message string, err error, operation string, companyID string) error {
+	return errors.ExternalServiceError(message, err).
+		WithProvider(s.cfg.PaymentProvider).
+		WithOperation(operation).
+		WithResource("subscription").
+		WithContext("company_id", companyID)
+}
+
+// liveProviderSubscriptionID returns the ID of the provider subscription of a company
+// that is not over yet, empty when it has none
+func liveProviderSubscriptionID(subscription *models.Subscription) string {
+	if subscription == nil || subscription.ProviderSubscriptionID == nil ||
+		subscription.Status == constants.SubscriptionStatusCanceled ||
+		subscription.Status == constants.SubscriptionStatusIncompleteExpired {
+		return ""
+	}
+	return *subscription.ProviderSubscriptionID
+}
+
+// applyProviderSubscription copies the state of the provider subscription to the
+// subscription of the company, and its plan when plan is not nil
+func applyProviderSubscription(subscription *models.Subscription, plan *models.Plan, providerSubscription *stripe.Subscription) {
+	if plan != nil {
+		subscription.PlanID = plan.ID
+		subscription.Plan = *plan
+	}
+	providerSubscriptionID := providerSubscription.ID
+	subscription.Status = string(providerSubscription.Status)
+	subscription.Provider = constants.PaymentProviderStripe
+	subscription.ProviderSubscriptionID = &providerSubscriptionID
+	subscription.CancelAtPeriodEnd = providerSubscription.CancelAtPeriodEnd
+	if providerSubscription.Customer != nil {
+		subscription.ProviderCustomerID = providerSubscription.Customer.ID
+	}
+	if providerSubscription.Items != nil && len(providerSubscription.Items.Data) > 0 && providerSubscription.Items.Data[0].CurrentPeriodEnd > 0 {
+		periodEnd := time.Unix(providerSubscription.Items.Data[0].CurrentPeriodEnd, 0).UTC()
+		subscription.CurrentPeriodEnd = &periodEnd
+	}
+}
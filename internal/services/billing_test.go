@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v82"
+)
+
+// MockBillingService is a mock implementation of BillingService
+type MockBillingService struct {
+	mock.Mock
+}
+
+func (m *MockBillingService) GetSubscription(ctx context.Context, companyID string) (*dtos.SubscriptionResponse, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SubscriptionResponse), args.Error(1)
+}
+
+func (m *MockBillingService) ChangeSubscription(ctx context.Context, companyID string, request *dtos.ChangeSubscriptionRequest) (*dtos.SubscriptionResponse, error) {
+	args := m.Called(companyID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SubscriptionResponse), args.Error(1)
+}
+
+func (m *MockBillingService) CheckEntitlement(ctx context.Context, companyID string, key string) error {
+	return m.Called(companyID, key).Error(0)
+}
+
+func (m *MockBillingService) CheckQuota(ctx context.Context, companyID string, key string, usage int64) error {
+	return m.Called(companyID, key, usage).Error(0)
+}
+
+func (m *MockBillingService) HandleSubscriptionEvent(ctx context.Context, msg messaging.Message) error {
+	return m.Called(msg).Error(0)
+}
+
+// MockBillingRepository is a mock implementation of repositories.BillingRepository
+type MockBillingRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillingRepository) GetPlanByKey(key string) (*models.Plan, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Plan), args.Error(1)
+}
+
+func (m *MockBillingRepository) GetPlanByPriceID(priceID string) (*models.Plan, error) {
+	args := m.Called(priceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Plan), args.Error(1)
+}
+
+func (m *MockBillingRepository) GetSubscription(companyID string) (*models.Subscription, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockBillingRepository) GetSubscriptionByProviderID(providerSubscriptionID string) (*models.Subscription, error) {
+	args := m.Called(providerSubscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockBillingRepository) SaveSubscription(subscription *models.Subscription) error {
+	return m.Called(subscription).Error(0)
+}
+
+// MockPaymentAdapter is a mock implementation of payment.PaymentAdapter
+type MockPaymentAdapter struct {
+	mock.Mock
+}
+
+func (m *MockPaymentAdapter) CreateCheckoutSession(ctx context.Context, priceID string, user models.User, mode stripe.CheckoutSessionMode) (*stripe.CheckoutSession, error) {
+	args := m.Called(priceID, user, mode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.CheckoutSession), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) GetCheckoutSession(ctx context.Context, sessionID string) (*stripe.CheckoutSession, error) {
+	args := m.Called(sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.CheckoutSession), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) CreateCustomerPortalSession(ctx context.Context, customerID string) (*stripe.BillingPortalSession, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.BillingPortalSession), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) HandleWebhook(ctx context.Context, payload []byte, signature string) (stripe.Event, error) {
+	args := m.Called(payload, signature)
+	return args.Get(0).(stripe.Event), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) CreateCustomer(ctx context.Context, email string, userID string) (*stripe.Customer, error) {
+	args := m.Called(email, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.Customer), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) CreateSubscriptionCheckoutSession(ctx context.Context, priceID string, customerID string, metadata map[string]string) (*stripe.CheckoutSession, error) {
+	args := m.Called(priceID, customerID, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.CheckoutSession), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) UpdateSubscriptionPrice(ctx context.Context, subscriptionID string, priceID string, metadata map[string]string) (*stripe.Subscription, error) {
+	args := m.Called(subscriptionID, priceID, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.Subscription), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) CancelSubscription(ctx context.Context, subscriptionID string) (*stripe.Subscription, error) {
+	args := m.Called(subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.Subscription), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) Refund(ctx context.Context, paymentID string, amount int64, reason string) (*stripe.Refund, error) {
+	args := m.Called(paymentID, amount, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.Refund), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) Capture(ctx context.Context, paymentID string, amount int64) (*stripe.PaymentIntent, error) {
+	args := m.Called(paymentID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) Void(ctx context.Context, paymentID string) (*stripe.PaymentIntent, error) {
+	args := m.Called(paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stripe.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentAdapter) Ping(ctx context.Context) error {
+	return m.Called().Error(0)
+}
+
+// Plans of the billing tests: free grants 1 webhook endpoint, pro grants 10
+var (
+	testFreePlan = &models.Plan{
+		BaseModel:    models.BaseModel{ID: "plan-free"},
+		Key:          "free",
+		Name:         "Free",
+		Active:       true,
+		Entitlements: []models.Entitlement{{Key: constants.EntitlementWebhooks, Limit: int64Ptr(1)}},
+	}
+	testProPlan = &models.Plan{
+		BaseModel:    models.BaseModel{ID: "plan-pro"},
+		Key:          "pro",
+		Name:         "Pro",
+		PriceID:      stringPtr("price_pro"),
+		Active:       true,
+		Entitlements: []models.Entitlement{{Key: constants.EntitlementWebhooks, Limit: int64Ptr(10)}},
+	}
+	testTeamPlan = &models.Plan{
+		BaseModel: models.BaseModel{ID: "plan-team"},
+		Key:       "team",
+		Name:      "Team",
+		PriceID:   stringPtr("price_team"),
+		Active:    true,
+	}
+)
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func newTestBillingService(billingRepo *MockBillingRepository, adapter *MockPaymentAdapter, defaultPlan string) *billingService {
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	companyRepo.On("GetOneByID", mock.Anything).Return(factory.Company().Build(), nil)
+
+	return &billingService{
+		billingRepo: billingRepo,
+		companyRepo: companyRepo,
+		adapter:     adapter,
+		cfg:         &config.Config{PaymentProvider: constants.PaymentProviderStripe, BillingDefaultPlan: defaultPlan},
+	}
+}
+
+// proSubscription returns the subscription of company-1 to the pro plan with sub_1
+func proSubscription(status string) *models.Subscription {
+	return &models.Subscription{
+		BaseModel:              models.BaseModel{ID: "subscription-1"},
+		CompanyID:              "company-1",
+		PlanID:                 testProPlan.ID,
+		Plan:                   *testProPlan,
+		Status:                 status,
+		Provider:               constants.PaymentProviderStripe,
+		ProviderCustomerID:     "cus_1",
+		ProviderSubscriptionID: stringPtr("sub_1"),
+	}
+}
+
+func TestBillingService_GetSubscription(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription *models.Subscription
+		defaultPlan  string
+		expectedPlan string
+		expectedErr  bool
+	}{
+		{name: "active subscription", subscription: proSubscription(constants.SubscriptionStatusActive), defaultPlan: "free", expectedPlan: "pro"},
+		{name: "past due subscription keeps its plan", subscription: proSubscription(constants.SubscriptionStatusPastDue), defaultPlan: "free", expectedPlan: "pro"},
+		{name: "canceled subscription falls back to the default plan", subscription: proSubscription(constants.SubscriptionStatusCanceled), defaultPlan: "free", expectedPlan: "free"},
+		{name: "no subscription without default plan", expectedPlan: ""},
+		{name: "missing default plan", defaultPlan: "basic", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			billingRepo := new(MockBillingRepository)
+			if tt.subscription != nil {
+				billingRepo.On("GetSubscription", "company-1").Return(tt.subscription, nil)
+			} else {
+				billingRepo.On("GetSubscription", "company-1").Return(nil, errors.NotFoundError("Subscription", nil))
+			}
+			billingRepo.On("GetPlanByKey", "free").Return(testFreePlan, nil).Maybe()
+			billingRepo.On("GetPlanByKey", "basic").Return(nil, errors.NotFoundError("Plan", nil)).Maybe()
+			service := newTestBillingService(billingRepo, new(MockPaymentAdapter), tt.defaultPlan)
+
+			response, err := service.GetSubscription(context.Background(), "company-1")
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPlan, response.Plan)
+			if tt.expectedPlan == "" {
+				assert.Empty(t, response.Entitlements)
+			}
+		})
+	}
+}
+
+func TestBillingService_CheckQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		plan          *models.Plan
+		usage         int64
+		expectedError bool
+	}{
+		{name: "under the limit", plan: testProPlan, usage: 9},
+		{name: "limit reached", plan: testProPlan, usage: 10, expectedError: true},
+		{name: "feature not in the plan", plan: testTeamPlan, expectedError: true},
+		{
+			name: "unlimited feature",
+			plan: &models.Plan{
+				Key:          "enterprise",
+				Entitlements: []models.Entitlement{{Key: constants.EntitlementWebhooks}},
+			},
+			usage: 1000,
+		},
+		{name: "unrestricted without default plan", usage: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			billingRepo := new(MockBillingRepository)
+			if tt.plan != nil {
+				subscription := proSubscription(constants.SubscriptionStatusActive)
+				subscription.Plan = *tt.plan
+				billingRepo.On("GetSubscription", "company-1").Return(subscription, nil)
+			} else {
+				billingRepo.On("GetSubscription", "company-1").Return(nil, errors.NotFoundError("Subscription", nil))
+			}
+			service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+			err := service.CheckQuota(context.Background(), "company-1", constants.EntitlementWebhooks, tt.usage)
+
+			if tt.expectedError {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, errors.ErrorTypeForbidden, appErr.Type)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBillingService_ChangeSubscription(t *testing.T) {
+	metadata := func(plan string) map[string]string {
+		return map[string]string{constants.BillingMetadataCompanyID: "company-1", constants.BillingMetadataPlan: plan}
+	}
+
+	t.Run("free plan cancels the provider subscription", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		adapter := new(MockPaymentAdapter)
+		billingRepo.On("GetPlanByKey", "free").Return(testFreePlan, nil)
+		billingRepo.On("GetSubscription", "company-1").Return(proSubscription(constants.SubscriptionStatusActive), nil)
+		adapter.On("CancelSubscription", "sub_1").Return(&stripe.Subscription{ID: "sub_1", Status: stripe.SubscriptionStatusCanceled}, nil)
+		billingRepo.On("SaveSubscription", mock.MatchedBy(func(subscription *models.Subscription) bool {
+			return subscription.PlanID == testFreePlan.ID && subscription.Status == constants.SubscriptionStatusActive &&
+				subscription.ProviderSubscriptionID == nil && subscription.ProviderCustomerID == "cus_1"
+		})).Return(nil)
+		service := newTestBillingService(billingRepo, adapter, "free")
+
+		response, err := service.ChangeSubscription(context.Background(), "company-1", &dtos.ChangeSubscriptionRequest{Plan: "free"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "free", response.Plan)
+		adapter.AssertExpectations(t)
+		billingRepo.AssertExpectations(t)
+	})
+
+	t.Run("paid plan change updates the provider subscription", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		adapter := new(MockPaymentAdapter)
+		billingRepo.On("GetPlanByKey", "team").Return(testTeamPlan, nil)
+		billingRepo.On("GetSubscription", "company-1").Return(proSubscription(constants.SubscriptionStatusActive), nil)
+		adapter.On("UpdateSubscriptionPrice", "sub_1", "price_team", metadata("team")).Return(&stripe.Subscription{
+			ID:     "sub_1",
+			Status: stripe.SubscriptionStatusActive,
+			Items:  &stripe.SubscriptionItemList{Data: []*stripe.SubscriptionItem{{Price: &stripe.Price{ID: "price_team"}, CurrentPeriodEnd: 1760000000}}},
+		}, nil)
+		billingRepo.On("SaveSubscription", mock.MatchedBy(func(subscription *models.Subscription) bool {
+			return subscription.PlanID == testTeamPlan.ID && subscription.CurrentPeriodEnd.Equal(time.Unix(1760000000, 0))
+		})).Return(nil)
+		service := newTestBillingService(billingRepo, adapter, "")
+
+		response, err := service.ChangeSubscription(context.Background(), "company-1", &dtos.ChangeSubscriptionRequest{Plan: "team"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "team", response.Plan)
+		assert.Empty(t, response.CheckoutURL)
+		adapter.AssertExpectations(t)
+		billingRepo.AssertExpectations(t)
+	})
+
+	t.Run("first paid plan returns the checkout", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		adapter := new(MockPaymentAdapter)
+		billingRepo.On("GetPlanByKey", "pro").Return(testProPlan, nil)
+		billingRepo.On("GetPlanByKey", "free").Return(testFreePlan, nil)
+		billingRepo.On("GetSubscription", "company-1").Return(nil, errors.NotFoundError("Subscription", nil))
+		adapter.On("CreateSubscriptionCheckoutSession", "price_pro", "", metadata("pro")).Return(&stripe.CheckoutSession{
+			ID:     "cs_1",
+			Status: stripe.CheckoutSessionStatusOpen,
+			URL:    "https://checkout.stripe.com/c/pay/cs_1",
+		}, nil)
+		service := newTestBillingService(billingRepo, adapter, "free")
+
+		response, err := service.ChangeSubscription(context.Background(), "company-1", &dtos.ChangeSubscriptionRequest{Plan: "pro"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "free", response.Plan, "the plan in effect stays until the checkout is paid")
+		assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_1", response.CheckoutURL)
+		billingRepo.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+	})
+
+	t.Run("same plan", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		adapter := new(MockPaymentAdapter)
+		billingRepo.On("GetPlanByKey", "pro").Return(testProPlan, nil)
+		billingRepo.On("GetSubscription", "company-1").Return(proSubscription(constants.SubscriptionStatusActive), nil)
+		service := newTestBillingService(billingRepo, adapter, "")
+
+		response, err := service.ChangeSubscription(context.Background(), "company-1", &dtos.ChangeSubscriptionRequest{Plan: "pro"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pro", response.Plan)
+		adapter.AssertNotCalled(t, "UpdateSubscriptionPrice", mock.Anything, mock.Anything, mock.Anything)
+		billingRepo.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+	})
+
+	t.Run("inactive plan", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		billingRepo.On("GetPlanByKey", "legacy").Return(&models.Plan{Key: "legacy"}, nil)
+		service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+		_, err := service.ChangeSubscription(context.Background(), "company-1", &dtos.ChangeSubscriptionRequest{Plan: "legacy"})
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+		assert.Contains(t, appErr.Context, "plan")
+	})
+}
+
+func TestBillingService_HandleSubscriptionEvent(t *testing.T) {
+	lastEventAt := time.Unix(1760000000, 0).UTC()
+	message := func(t *testing.T, createdAt time.Time, providerSubscription string) messaging.Message {
+		body, err := json.Marshal(dtos.PaymentEvent{
+			ID:        "evt_1",
+			Provider:  constants.PaymentProviderStripe,
+			Type:      "customer.subscription.updated",
+			CreatedAt: createdAt,
+			Data:      json.RawMessage(providerSubscription),
+		})
+		require.NoError(t, err)
+		return messaging.Message{ID: "evt_1", Body: body}
+	}
+	const proEvent = `{"id":"sub_2","object":"subscription","status":"active","customer":"cus_2","metadata":{"company_id":"company-1","plan":"pro"},
+		"items":{"object":"list","data":[{"id":"si_1","current_period_end":1762592400,"price":{"id":"price_pro"}}]}}`
+
+	t.Run("first event of a company subscription", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		billingRepo.On("GetSubscriptionByProviderID", "sub_2").Return(nil, errors.NotFoundError("Subscription", nil))
+		billingRepo.On("GetSubscription", "company-1").Return(nil, errors.NotFoundError("Subscription", nil))
+		billingRepo.On("GetPlanByPriceID", "price_pro").Return(testProPlan, nil)
+		billingRepo.On("SaveSubscription", mock.MatchedBy(func(subscription *models.Subscription) bool {
+			return subscription.CompanyID == "company-1" && subscription.PlanID == testProPlan.ID &&
+				subscription.Status == constants.SubscriptionStatusActive && subscription.ProviderCustomerID == "cus_2" &&
+				*subscription.ProviderSubscriptionID == "sub_2" && subscription.LastEventAt.Equal(lastEventAt)
+		})).Return(nil)
+		service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+		err := service.HandleSubscriptionEvent(context.Background(), message(t, lastEventAt, proEvent))
+
+		require.NoError(t, err)
+		billingRepo.AssertExpectations(t)
+	})
+
+	t.Run("outdated event ignored", func(t *testing.T) {
+		subscription := proSubscription(constants.SubscriptionStatusCanceled)
+		subscription.LastEventAt = &lastEventAt
+		billingRepo := new(MockBillingRepository)
+		billingRepo.On("GetSubscriptionByProviderID", "sub_2").Return(subscription, nil)
+		service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+		err := service.HandleSubscriptionEvent(context.Background(), message(t, lastEventAt.Add(-time.Minute), proEvent))
+
+		require.NoError(t, err)
+		billingRepo.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+	})
+
+	t.Run("price of no plan", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		billingRepo.On("GetSubscriptionByProviderID", "sub_2").Return(proSubscription(constants.SubscriptionStatusActive), nil)
+		billingRepo.On("GetPlanByPriceID", "price_pro").Return(nil, errors.NotFoundError("Plan", nil))
+		service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+		err := service.HandleSubscriptionEvent(context.Background(), message(t, lastEventAt, proEvent))
+
+		assert.True(t, retry.IsPermanent(err))
+	})
+
+	t.Run("subscription without a company ignored", func(t *testing.T) {
+		billingRepo := new(MockBillingRepository)
+		billingRepo.On("GetSubscriptionByProviderID", "sub_3").Return(nil, errors.NotFoundError("Subscription", nil))
+		service := newTestBillingService(billingRepo, new(MockPaymentAdapter), "")
+
+		err := service.HandleSubscriptionEvent(context.Background(), message(t, lastEventAt, `{"id":"sub_3","object":"subscription","status":"active"}`))
+
+		require.NoError(t, err)
+		billingRepo.AssertNotCalled(t, "SaveSubscription", mock.Anything)
+	})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// BootstrapService provisions what a fresh environment needs before anyone can log in
+type BootstrapService interface {
+	// CreateAdmin creates the admin user in Keycloak and in the database, sets its
+	// password and grants it the admin client role. The steps already done are skipped,
+	// so it can be run again after a failure half way, or to reset the password.
+	CreateAdmin(ctx context.Context, req *dtos.CreateAdminRequest) (*dtos.CreateAdminResponse, error)
+}
+
+// bootstrapService implements BootstrapService
+type bootstrapService struct {
+	auth     auth.AuthService
+	tokens   auth.TokenProvider
+	userRepo repositories.UserRepository
+}
+
+// ProvideBootstrapService creates a new bootstrap service
+func ProvideBootstrapService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	userRepo repositories.UserRepository,
+) BootstrapService {
+	return &bootstrapService{
+		auth:     authProvider,
+		tokens:   tokens,
+		userRepo: userRepo,
+	}
+}
+
+func (s *bootstrapService) CreateAdmin(ctx context.Context, req *dtos.CreateAdminRequest) (*dtos.CreateAdminResponse, error) {
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auth.EnsureClientRoles(ctx, adminToken, []string{constants.RoleAdmin}); err != nil {
+		return nil, err
+	}
+
+	// Keycloak goes first: the user of the database then references a Keycloak user
+	// that exists, and a retry finds that user by its email
+	keycloakUser, err := s.auth.FindUserByEmail(ctx, adminToken, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	keycloakUserCreated := keycloakUser == nil
+	if keycloakUserCreated {
+		keycloakUser, err = s.auth.CreateUser(ctx, adminToken, &dtos.CreateUserRequest{
+			UserRequest: dtos.UserRequest{
+				Email:     req.Email,
+				FirstName: req.FirstName,
+				LastName:  req.LastName,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.auth.SetPassword(ctx, adminToken, keycloakUser.ID, req.Password, req.TemporaryPassword); err != nil {
+		return nil, err
+	}
+	if err := s.auth.AddClientRolesToUser(ctx, adminToken, keycloakUser.ID, s.auth.GetClientID(), constants.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+			return nil, err
+		}
+	}
+	userCreated := user == nil
+	if userCreated {
+		user, err = s.userRepo.Create(&models.User{
+			BaseModel:  models.NewBaseModel(),
+			FirstName:  req.FirstName,
+			LastName:   req.LastName,
+			Email:      req.Email,
+			KeycloakID: keycloakUser.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if user.KeycloakID != keycloakUser.ID {
+		user.KeycloakID = keycloakUser.ID
+		if err := s.userRepo.UpdateColumns(user, "keycloak_id"); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Log.Info("Admin user created",
+		zap.String("user_id", user.ID),
+		zap.String("keycloak_id", keycloakUser.ID),
+		zap.Bool("keycloak_user_created", keycloakUserCreated),
+		zap.Bool("user_created", userCreated),
+	)
+
+	return &dtos.CreateAdminResponse{
+		UserID:              user.ID,
+		KeycloakID:          keycloakUser.ID,
+		Email:               user.Email,
+		KeycloakUserCreated: keycloakUserCreated,
+		UserCreated:         userCreated,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapService_CreateAdmin(t *testing.T) {
+	request := &dtos.CreateAdminRequest{
+		Email:     "admin@example.com",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Password:  "correct-horse-battery",
+	}
+	existingUser := func(keycloakID string) *models.User {
+		return factory.User().WithID("user-1").WithEmail(request.Email).WithKeycloakID(keycloakID).Build()
+	}
+
+	tests := []struct {
+		name                        string
+		setupMocks                  func(authProvider *MockAuthProvider, userRepo *MockUserRepository)
+		expectedError               bool
+		expectedKeycloakUserCreated bool
+		expectedUserCreated         bool
+	}{
+		{
+			name: "fresh environment",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", request.Email).Return(nil, nil)
+				authProvider.On("CreateUser", mock.Anything, "admin-token", mock.MatchedBy(func(req *dtos.CreateUserRequest) bool {
+					return req.Email == request.Email && req.FirstName == "Jane"
+				})).Return(&auth.User{ID: "kc-1"}, nil)
+				userRepo.On("GetByEmail", request.Email).Return(nil, errors.NotFoundError("User", nil))
+				userRepo.On("Create", mock.MatchedBy(func(user *models.User) bool {
+					return user.KeycloakID == "kc-1" && user.Email == request.Email
+				})).Return(existingUser("kc-1"), nil)
+			},
+			expectedKeycloakUserCreated: true,
+			expectedUserCreated:         true,
+		},
+		{
+			name: "run again",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", request.Email).Return(&auth.User{ID: "kc-1"}, nil)
+				userRepo.On("GetByEmail", request.Email).Return(existingUser("kc-1"), nil)
+			},
+		},
+		{
+			name: "existing user linked to the Keycloak user",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", request.Email).Return(&auth.User{ID: "kc-1"}, nil)
+				userRepo.On("GetByEmail", request.Email).Return(existingUser(""), nil)
+				userRepo.On("UpdateColumns", mock.MatchedBy(func(user *models.User) bool {
+					return user.KeycloakID == "kc-1"
+				}), []string{"keycloak_id"}).Return(nil)
+			},
+		},
+		{
+			name: "database failure",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", request.Email).Return(&auth.User{ID: "kc-1"}, nil)
+				userRepo.On("GetByEmail", request.Email).Return(nil, errors.DatabaseError("Failed to get user by email", assert.AnError))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+
+			authProvider.On("EnsureClientRoles", mock.Anything, "admin-token", []string{constants.RoleAdmin}).Return(nil)
+			authProvider.On("SetPassword", mock.Anything, "admin-token", "kc-1", request.Password, false).Return(nil)
+			authProvider.On("GetClientID").Return("backend")
+			authProvider.On("AddClientRolesToUser", mock.Anything, "admin-token", "kc-1", "backend", constants.RoleAdmin).Return(nil)
+			tt.setupMocks(authProvider, userRepo)
+
+			service := ProvideBootstrapService(authProvider, newMockTokenProvider(), userRepo)
+			admin, err := service.CreateAdmin(context.Background(), request)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", admin.UserID)
+			assert.Equal(t, "kc-1", admin.KeycloakID)
+			assert.Equal(t, tt.expectedKeycloakUserCreated, admin.KeycloakUserCreated)
+			assert.Equal(t, tt.expectedUserCreated, admin.UserCreated)
+			authProvider.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
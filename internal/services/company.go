@@ -2,41 +2,68 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"mime/multipart"
 
 	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
 	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/projections"
 	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/webhooks"
 
 	"golang-boilerplate/internal/logger"
 
-	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type CompanyService interface {
 	Create(ctx context.Context, req *dtos.CreateCompanyRequest) (*models.Company, error)
+	CreateWithLogo(ctx context.Context, req *dtos.CreateCompanyRequest, logo *multipart.FileHeader, contentType string) (*models.Company, error)
 	GetOneByID(ctx context.Context, companyID string) (*models.Company, error)
 	Update(ctx context.Context, companyID string, req *dtos.UpdateCompanyRequest) (*models.Company, error)
+	Patch(ctx context.Context, companyID string, req *dtos.PatchCompanyRequest) (*models.Company, error)
 	Delete(ctx context.Context, companyID string) error
 	List(ctx context.Context, pageableRequest *dtos.CompanyPageableRequest) (*dtos.DataResponse[models.Company], error)
+	ListMembers(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.User], error)
+	// Stream passes every company matching the filters of pageableRequest to yield, newest
+	// first, constants.StreamBatchSize at a time; the sort and pagination are ignored
+	Stream(ctx context.Context, pageableRequest *dtos.CompanyPageableRequest, yield func([]models.Company) error) error
+	// StreamMembers passes every member of the company to yield, newest first,
+	// constants.StreamBatchSize at a time
+	StreamMembers(ctx context.Context, companyID string, yield func([]models.User) error) error
 }
 
 // CompanyService handles company business logic
 type companyService struct {
 	companyRepo repositories.CompanyRepository
+	userRepo    repositories.UserRepository
 	cache       cache.Cache
+	files       files.Registry
+	webhooks    webhooks.Dispatcher
+	projections projections.Publisher
 }
 
 // ProvideCompanyService creates a new company service
 func ProvideCompanyService(
 	companyRepo repositories.CompanyRepository,
+	userRepo repositories.UserRepository,
 	cache cache.Cache,
+	files files.Registry,
+	webhooks webhooks.Dispatcher,
+	projections projections.Publisher,
 ) CompanyService {
 	return &companyService{
 		companyRepo: companyRepo,
+		userRepo:    userRepo,
 		cache:       cache,
+		files:       files,
+		webhooks:    webhooks,
+		projections: projections,
 	}
 }
 
@@ -48,16 +75,6 @@ func (s *companyService) Create(ctx context.Context, req *dtos.CreateCompanyRequ
 
 	company, err := s.companyRepo.Create(company)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "create_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to create company",
 			zap.Any("body_request", req),
 			zap.Error(err),
@@ -69,22 +86,65 @@ func (s *companyService) Create(ctx context.Context, req *dtos.CreateCompanyRequ
 			WithContext("request", req)
 	}
 
+	s.publishProjectionEvent(ctx, company.ID, constants.EventCompanyCreated, company.ID)
 	return company, nil
 }
 
-func (s *companyService) GetOneByID(ctx context.Context, companyID string) (*models.Company, error) {
-	company, err := s.companyRepo.GetOneByID(companyID)
+// CreateWithLogo uploads the logo to the file registry and creates the company
+// referencing it. The uploaded file is deleted again when the company cannot be saved.
+// contentType must be one of constants.CompanyLogoContentTypes.
+func (s *companyService) CreateWithLogo(ctx context.Context, req *dtos.CreateCompanyRequest, logo *multipart.FileHeader, contentType string) (*models.Company, error) {
+	company := &models.Company{
+		BaseModel:  models.NewBaseModel(),
+		Name:       req.Name,
+		KeycloakID: req.KeycloakID,
+	}
+
+	key := fmt.Sprintf("%s/%s/%s%s", constants.CompanyLogoKeyPrefix, company.ID, uuid.Must(uuid.NewV7()).String(), constants.CompanyLogoContentTypes[contentType])
+	if _, err := s.files.Upload(ctx, logo, key, contentType, company.ID); err != nil {
+		logger.Log.Error("Failed to upload company logo",
+			zap.String("logo_key", key),
+			zap.Error(err),
+		)
+
+		return nil, errors.ExternalServiceError("Failed to upload company logo", err).
+			WithOperation("create_company_with_logo").
+			WithResource("company")
+	}
+	company.LogoKey = key
+	company.LogoSize = logo.Size
+
+	company, err := s.companyRepo.Create(company)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "get_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("company_id", companyID)
-				hub.CaptureException(err)
-			})
+		logger.Log.Error("Failed to create company with logo",
+			zap.Any("body_request", req),
+			zap.Error(err),
+		)
+
+		// Roll back the upload so that no orphaned logo is left in storage
+		if deleteErr := s.files.Delete(ctx, key); deleteErr != nil {
+			logger.Log.Warn("Failed to delete logo of company that was not created",
+				zap.String("logo_key", key),
+				zap.Error(deleteErr),
+			)
 		}
 
+		return nil, errors.DatabaseError("Failed to create company", err).
+			WithOperation("create_company_with_logo").
+			WithResource("company").
+			WithContext("request", req)
+	}
+
+	s.publishProjectionEvent(ctx, company.ID, constants.EventCompanyCreated, company.ID)
+	return company, nil
+}
+
+// GetOneByID serves the company from the cache for constants.EntityCacheTTL
+func (s *companyService) GetOneByID(ctx context.Context, companyID string) (*models.Company, error) {
+	company, err := cache.GetOrLoad(ctx, s.cache, constants.CompanyCacheKeyPrefix+companyID, constants.EntityCacheTTL, func(context.Context) (*models.Company, error) {
+		return s.companyRepo.GetOneByID(companyID)
+	})
+	if err != nil {
 		logger.Log.Error("Failed to get company",
 			zap.String("company_id", companyID),
 			zap.Error(err),
@@ -102,17 +162,6 @@ func (s *companyService) GetOneByID(ctx context.Context, companyID string) (*mod
 func (s *companyService) Update(ctx context.Context, companyID string, req *dtos.UpdateCompanyRequest) (*models.Company, error) {
 	company, err := s.companyRepo.GetOneByID(companyID)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "update_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("company_id", companyID)
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to get company for update",
 			zap.String("company_id", companyID),
 			zap.Any("body_request", req),
@@ -136,17 +185,6 @@ func (s *companyService) Update(ctx context.Context, companyID string, req *dtos
 	// Save the updated company
 	err = s.companyRepo.Update(company)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "update_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("company_id", companyID)
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to update company",
 			zap.String("company_id", companyID),
 			zap.Any("body_request", req),
@@ -158,23 +196,55 @@ func (s *companyService) Update(ctx context.Context, companyID string, req *dtos
 			WithResource("company").
 			WithContext("company_id", companyID)
 	}
+	s.invalidateCompany(ctx, companyID)
 
+	s.dispatchWebhook(ctx, company, constants.WebhookEventCompanyUpdated)
+	s.publishProjectionEvent(ctx, company.ID, constants.WebhookEventCompanyUpdated, company.ID)
 	return company, nil
 }
 
-func (s *companyService) Delete(ctx context.Context, companyID string) error {
+// Patch replaces the patchable fields of a company with the merged document, so that
+// fields cleared by the merge patch are written as empty values
+func (s *companyService) Patch(ctx context.Context, companyID string, req *dtos.PatchCompanyRequest) (*models.Company, error) {
 	company, err := s.companyRepo.GetOneByID(companyID)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "delete_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("company_id", companyID)
-				hub.CaptureException(err)
-			})
-		}
+		logger.Log.Error("Failed to get company for patch",
+			zap.String("company_id", companyID),
+			zap.Error(err),
+		)
+
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("patch_company").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	company.Name = req.Name
+	company.KeycloakID = req.KeycloakID
 
+	err = s.companyRepo.UpdateColumns(company, "name", "keycloak_id")
+	if err != nil {
+		logger.Log.Error("Failed to patch company",
+			zap.String("company_id", companyID),
+			zap.Any("body_request", req),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to patch company", err).
+			WithOperation("patch_company").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	s.invalidateCompany(ctx, companyID)
+
+	s.dispatchWebhook(ctx, company, constants.WebhookEventCompanyUpdated)
+	s.publishProjectionEvent(ctx, company.ID, constants.WebhookEventCompanyUpdated, company.ID)
+	return company, nil
+}
+
+func (s *companyService) Delete(ctx context.Context, companyID string) error {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
 		logger.Log.Error("Failed to get company for delete",
 			zap.String("company_id", companyID),
 			zap.Error(err),
@@ -188,16 +258,6 @@ func (s *companyService) Delete(ctx context.Context, companyID string) error {
 
 	err = s.companyRepo.Delete(company)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "delete_company")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("company_id", companyID)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to delete company",
 			zap.String("company_id", companyID),
 			zap.Error(err),
@@ -208,23 +268,46 @@ func (s *companyService) Delete(ctx context.Context, companyID string) error {
 			WithResource("company").
 			WithContext("company_id", companyID)
 	}
+	s.invalidateCompany(ctx, companyID)
 
+	s.dispatchWebhook(ctx, company, constants.WebhookEventCompanyDeleted)
+	s.publishProjectionEvent(ctx, company.ID, constants.WebhookEventCompanyDeleted, company.ID)
 	return nil
 }
 
+// invalidateCompany removes the cached company after a change
+func (s *companyService) invalidateCompany(ctx context.Context, companyID string) {
+	cache.Invalidate(ctx, s.cache, constants.CompanyCacheKeyPrefix+companyID)
+}
+
+// dispatchWebhook delivers an event of the company to its webhook endpoints. The change is
+// already saved, so a failure is only logged.
+func (s *companyService) dispatchWebhook(ctx context.Context, company *models.Company, eventType string) {
+	if err := s.webhooks.Dispatch(ctx, company.ID, eventType, dtos.NewCompanyResponse(company)); err != nil {
+		logger.Log.Warn("Failed to dispatch webhook event",
+			zap.String("company_id", company.ID),
+			zap.String("event_type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishProjectionEvent updates the read models of the company with the event. The change
+// is already saved and the read models can be rebuilt, so a failure is only logged.
+func (s *companyService) publishProjectionEvent(ctx context.Context, companyID string, eventType string, entityID string) {
+	if err := s.projections.Publish(ctx, companyID, eventType, entityID); err != nil {
+		logger.Log.Warn("Failed to publish projection event",
+			zap.String("company_id", companyID),
+			zap.String("event_type", eventType),
+			zap.String("entity_id", entityID),
+			zap.Error(err),
+		)
+	}
+}
+
 func (s *companyService) List(ctx context.Context, pageableRequest *dtos.CompanyPageableRequest) (*dtos.DataResponse[models.Company], error) {
 	companies, err := s.companyRepo.Get(pageableRequest)
 	if err != nil {
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "company_service")
-				scope.SetTag("operation", "get_companies")
-				scope.SetExtra("error_details", err.Error())
-				scope.SetExtra("pageable_request", pageableRequest)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to get companies",
 			zap.Any("pageable_request", pageableRequest),
 			zap.Error(err),
@@ -238,3 +321,63 @@ func (s *companyService) List(ctx context.Context, pageableRequest *dtos.Company
 
 	return companies, nil
 }
+
+func (s *companyService) ListMembers(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.User], error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		logger.Log.Error("Failed to get company for members listing",
+			zap.String("company_id", companyID),
+			zap.Error(err),
+		)
+
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_company_members").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	members, err := s.userRepo.GetByCompanyID(companyID, pageableRequest)
+	if err != nil {
+		logger.Log.Error("Failed to get company members",
+			zap.String("company_id", companyID),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to get company members", err).
+			WithOperation("get_company_members").
+			WithResource("users").
+			WithContext("company_id", companyID)
+	}
+
+	return members, nil
+}
+
+func (s *companyService) Stream(ctx context.Context, pageableRequest *dtos.CompanyPageableRequest, yield func([]models.Company) error) error {
+	return streamPages(
+		func(after *dtos.Cursor, limit int) ([]models.Company, error) {
+			return s.companyRepo.GetAfter(pageableRequest, after, limit)
+		},
+		func(company models.Company) dtos.Cursor {
+			return dtos.Cursor{CreatedAt: company.CreatedAt, ID: company.ID}
+		},
+		yield,
+	)
+}
+
+func (s *companyService) StreamMembers(ctx context.Context, companyID string, yield func([]models.User) error) error {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return errors.NotFoundError("Company", err).
+			WithOperation("stream_company_members").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return streamPages(
+		func(after *dtos.Cursor, limit int) ([]models.User, error) {
+			return s.userRepo.GetByCompanyIDAfter(companyID, after, limit)
+		},
+		func(user models.User) dtos.Cursor {
+			return dtos.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+		},
+		yield,
+	)
+}
@@ -2,10 +2,16 @@ package services
 
 import (
 	"context"
+	stderrors "errors"
+	"mime/multipart"
+	"strings"
 	"testing"
+	"time"
 
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
 	"golang-boilerplate/internal/models"
 
 	"github.com/google/uuid"
@@ -35,11 +41,40 @@ func (m *MockCompanyRepositoryForCompanyService) GetOneByID(id string) (*models.
 	return args.Get(0).(*models.Company), args.Error(1)
 }
 
+func (m *MockCompanyRepositoryForCompanyService) GetByKeycloakID(keycloakID string) (*models.Company, error) {
+	args := m.Called(keycloakID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepositoryForCompanyService) GetBySlug(slug string) (*models.Company, error) {
+	args := m.Called(slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepositoryForCompanyService) GetSandbox(companyID string) (*models.Company, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
 func (m *MockCompanyRepositoryForCompanyService) Update(company *models.Company) error {
 	args := m.Called(company)
 	return args.Error(0)
 }
 
+func (m *MockCompanyRepositoryForCompanyService) UpdateColumns(company *models.Company, columns ...string) error {
+	args := m.Called(company, columns)
+	return args.Error(0)
+}
+
 func (m *MockCompanyRepositoryForCompanyService) Delete(company *models.Company) error {
 	args := m.Called(company)
 	return args.Error(0)
@@ -53,6 +88,30 @@ func (m *MockCompanyRepositoryForCompanyService) Get(pr *dtos.CompanyPageableReq
 	return args.Get(0).(*dtos.DataResponse[models.Company]), args.Error(1)
 }
 
+func (m *MockCompanyRepositoryForCompanyService) GetAfter(pr *dtos.CompanyPageableRequest, after *dtos.Cursor, limit int) ([]models.Company, error) {
+	args := m.Called(pr, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepositoryForCompanyService) GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.Company, int64, error) {
+	args := m.Called(filters, offset, limit, preloads)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.Company), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCompanyRepositoryForCompanyService) GetByUserIDs(userIDs []string) (map[string][]models.Company, error) {
+	args := m.Called(userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]models.Company), args.Error(1)
+}
+
 func TestCompanyService_Create(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -70,13 +129,7 @@ func TestCompanyService_Create(t *testing.T) {
 				},
 			},
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
-				createdCompany := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					Name:       "Acme Corp",
-					KeycloakID: "keycloak-123",
-				}
+				createdCompany := factory.Company().WithName("Acme Corp").WithKeycloakID("keycloak-123").Build()
 
 				companyRepo.On("Create", mock.AnythingOfType("*models.Company")).Return(createdCompany, nil)
 			},
@@ -110,6 +163,7 @@ func TestCompanyService_Create(t *testing.T) {
 			service := &companyService{
 				companyRepo: mockCompanyRepo,
 				cache:       mockCache,
+				projections: newMockProjectionPublisher(),
 			}
 
 			ctx := context.Background()
@@ -138,6 +192,80 @@ func TestCompanyService_Create(t *testing.T) {
 	}
 }
 
+func TestCompanyService_CreateWithLogo(t *testing.T) {
+	req := &dtos.CreateCompanyRequest{CompanyRequest: dtos.CompanyRequest{Name: "Acme Corp"}}
+	isLogoKey := mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "logos/") && strings.HasSuffix(key, ".png")
+	})
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockCompanyRepositoryForCompanyService, *MockFileRegistry)
+		expectedError bool
+		errorType     errors.ErrorType
+	}{
+		{
+			name: "success - logo key is stored on the company",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, files *MockFileRegistry) {
+				files.On("Upload", mock.Anything, mock.Anything, isLogoKey, "image/png", mock.AnythingOfType("string")).Return(factory.File().Build(), nil)
+				companyRepo.On("Create", mock.MatchedBy(func(c *models.Company) bool {
+					return c.Name == "Acme Corp" && strings.HasPrefix(c.LogoKey, "logos/"+c.ID+"/") && c.LogoSize == 2048
+				})).Return(factory.Company().WithName("Acme Corp").WithLogo("logos/123/0190b7f5.png", 0).Build(), nil)
+			},
+		},
+		{
+			name: "error - upload fails and nothing is saved",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, files *MockFileRegistry) {
+				files.On("Upload", mock.Anything, mock.Anything, isLogoKey, "image/png", mock.AnythingOfType("string")).Return(nil, errors.ExternalServiceError("Failed to upload file", assert.AnError))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeExternal,
+		},
+		{
+			name: "error - database error deletes the uploaded logo",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, files *MockFileRegistry) {
+				files.On("Upload", mock.Anything, mock.Anything, isLogoKey, "image/png", mock.AnythingOfType("string")).Return(factory.File().Build(), nil)
+				companyRepo.On("Create", mock.AnythingOfType("*models.Company")).Return(nil, errors.DatabaseError("Failed to create company", nil))
+				files.On("Delete", mock.Anything, isLogoKey).Return(nil)
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCompanyRepo := new(MockCompanyRepositoryForCompanyService)
+			mockFiles := new(MockFileRegistry)
+			tt.setupMocks(mockCompanyRepo, mockFiles)
+
+			service := &companyService{
+				companyRepo: mockCompanyRepo,
+				cache:       new(MockCache),
+				files:       mockFiles,
+				projections: newMockProjectionPublisher(),
+			}
+
+			result, err := service.CreateWithLogo(context.Background(), req, &multipart.FileHeader{Filename: "logo.png", Size: 2048}, "image/png")
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.errorType, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.NotEmpty(t, result.LogoKey)
+			}
+
+			mockCompanyRepo.AssertExpectations(t)
+			mockFiles.AssertExpectations(t)
+		})
+	}
+}
+
 func TestCompanyService_GetOneByID(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -149,14 +277,18 @@ func TestCompanyService_GetOneByID(t *testing.T) {
 			name:      "success",
 			companyID: uuid.New().String(),
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
-				company := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					Name:       "Acme Corp",
-					KeycloakID: "keycloak-123",
-				}
+				company := factory.Company().WithName("Acme Corp").WithKeycloakID("keycloak-123").Build()
+				cache.On("Get", mock.Anything, mock.AnythingOfType("string")).Return("", errors.NotFoundError("Cache key", nil))
 				companyRepo.On("GetOneByID", mock.AnythingOfType("string")).Return(company, nil)
+				cache.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), isEntityCacheTTL).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:      "success - cache hit",
+			companyID: "3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
+				cache.On("Get", mock.Anything, "company:3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f").Return(`{"id":"3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f","Name":"Acme Corp"}`, nil)
 			},
 			expectedError: false,
 		},
@@ -164,6 +296,7 @@ func TestCompanyService_GetOneByID(t *testing.T) {
 			name:      "error - company not found",
 			companyID: "non-existent",
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
+				cache.On("Get", mock.Anything, "company:non-existent").Return("", errors.NotFoundError("Cache key", nil))
 				companyRepo.On("GetOneByID", "non-existent").Return(nil, errors.NotFoundError("Company", nil))
 			},
 			expectedError: true,
@@ -197,6 +330,7 @@ func TestCompanyService_GetOneByID(t *testing.T) {
 			}
 
 			mockCompanyRepo.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
 		})
 	}
 }
@@ -220,16 +354,11 @@ func TestCompanyService_Update(t *testing.T) {
 			},
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
 				companyID := uuid.New()
-				company := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: companyID.String(),
-					},
-					Name:       "Old Name",
-					KeycloakID: "keycloak-123",
-				}
+				company := factory.Company().WithID(companyID.String()).WithName("Old Name").WithKeycloakID("keycloak-123").Build()
 
 				companyRepo.On("GetOneByID", mock.AnythingOfType("string")).Return(company, nil)
 				companyRepo.On("Update", mock.AnythingOfType("*models.Company")).Return(nil)
+				cache.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil)
 			},
 			expectedError: false,
 		},
@@ -256,12 +385,7 @@ func TestCompanyService_Update(t *testing.T) {
 				},
 			},
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
-				company := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					Name: "Old Name",
-				}
+				company := factory.Company().WithName("Old Name").Build()
 				companyRepo.On("GetOneByID", mock.AnythingOfType("string")).Return(company, nil)
 				companyRepo.On("Update", mock.AnythingOfType("*models.Company")).Return(errors.DatabaseError("Failed to update company", nil))
 			},
@@ -282,6 +406,8 @@ func TestCompanyService_Update(t *testing.T) {
 			service := &companyService{
 				companyRepo: mockCompanyRepo,
 				cache:       mockCache,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
 			}
 
 			ctx := context.Background()
@@ -315,6 +441,86 @@ func TestCompanyService_Update(t *testing.T) {
 	}
 }
 
+func TestCompanyService_Patch(t *testing.T) {
+	companyID := uuid.New().String()
+	columns := []string{"name", "keycloak_id"}
+
+	tests := []struct {
+		name          string
+		req           *dtos.PatchCompanyRequest
+		setupMocks    func(*MockCompanyRepositoryForCompanyService)
+		expectedError bool
+		errorType     errors.ErrorType
+	}{
+		{
+			name: "success - cleared keycloak id is written",
+			req:  &dtos.PatchCompanyRequest{Name: "Acme Corp"},
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService) {
+				company := factory.Company().WithID(companyID).WithName("Acme").WithKeycloakID("keycloak-123").Build()
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+				companyRepo.On("UpdateColumns", mock.MatchedBy(func(c *models.Company) bool {
+					return c.Name == "Acme Corp" && c.KeycloakID == ""
+				}), columns).Return(nil)
+			},
+		},
+		{
+			name: "error - company not found",
+			req:  &dtos.PatchCompanyRequest{Name: "Acme Corp"},
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService) {
+				companyRepo.On("GetOneByID", companyID).Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeNotFound,
+		},
+		{
+			name: "error - database error on update",
+			req:  &dtos.PatchCompanyRequest{Name: "Acme Corp"},
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService) {
+				company := factory.Company().WithID(companyID).WithName("Acme").Build()
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+				companyRepo.On("UpdateColumns", company, columns).Return(errors.DatabaseError("Failed to update company", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCompanyRepo := new(MockCompanyRepositoryForCompanyService)
+			mockCache := new(MockCache)
+			tt.setupMocks(mockCompanyRepo)
+			if !tt.expectedError {
+				mockCache.On("Delete", mock.Anything, constants.CompanyCacheKeyPrefix+companyID).Return(nil)
+			}
+
+			service := &companyService{
+				companyRepo: mockCompanyRepo,
+				cache:       mockCache,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
+			}
+
+			result, err := service.Patch(context.Background(), companyID, tt.req)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.errorType, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Equal(t, "Acme Corp", result.Name)
+				assert.Empty(t, result.KeycloakID)
+			}
+
+			mockCompanyRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestCompanyService_Delete(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -327,14 +533,10 @@ func TestCompanyService_Delete(t *testing.T) {
 			name:      "success",
 			companyID: uuid.New().String(),
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
-				company := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					Name: "Acme Corp",
-				}
+				company := factory.Company().WithName("Acme Corp").Build()
 				companyRepo.On("GetOneByID", mock.AnythingOfType("string")).Return(company, nil)
 				companyRepo.On("Delete", mock.AnythingOfType("*models.Company")).Return(nil)
+				cache.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil)
 			},
 			expectedError: false,
 		},
@@ -351,12 +553,7 @@ func TestCompanyService_Delete(t *testing.T) {
 			name:      "error - database error on delete",
 			companyID: uuid.New().String(),
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
-				company := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					Name: "Acme Corp",
-				}
+				company := factory.Company().WithName("Acme Corp").Build()
 				companyRepo.On("GetOneByID", mock.AnythingOfType("string")).Return(company, nil)
 				companyRepo.On("Delete", mock.AnythingOfType("*models.Company")).Return(errors.DatabaseError("Failed to delete company", nil))
 			},
@@ -374,9 +571,20 @@ func TestCompanyService_Delete(t *testing.T) {
 				tt.setupMocks(mockCompanyRepo, mockCache)
 			}
 
+			mockWebhooks := new(MockWebhookDispatcher)
+			mockProjections := new(MockProjectionPublisher)
+			if !tt.expectedError {
+				mockWebhooks.On("Dispatch", mock.Anything, mock.AnythingOfType("string"), constants.WebhookEventCompanyDeleted, mock.AnythingOfType("*dtos.CompanyResponse")).
+					Return(nil).Once()
+				mockProjections.On("Publish", mock.Anything, mock.AnythingOfType("string"), constants.WebhookEventCompanyDeleted, mock.AnythingOfType("string")).
+					Return(nil).Once()
+			}
+
 			service := &companyService{
 				companyRepo: mockCompanyRepo,
 				cache:       mockCache,
+				webhooks:    mockWebhooks,
+				projections: mockProjections,
 			}
 
 			ctx := context.Background()
@@ -399,6 +607,8 @@ func TestCompanyService_Delete(t *testing.T) {
 			}
 
 			mockCompanyRepo.AssertExpectations(t)
+			mockWebhooks.AssertExpectations(t)
+			mockProjections.AssertExpectations(t)
 		})
 	}
 }
@@ -421,14 +631,8 @@ func TestCompanyService_List(t *testing.T) {
 			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, cache *MockCache) {
 				companies := &dtos.DataResponse[models.Company]{
 					Data: []models.Company{
-						{
-							BaseModel: models.BaseModel{ID: uuid.New().String()},
-							Name:      "Company 1",
-						},
-						{
-							BaseModel: models.BaseModel{ID: uuid.New().String()},
-							Name:      "Company 2",
-						},
+						*factory.Company().WithName("Company 1").Build(),
+						*factory.Company().WithName("Company 2").Build(),
 					},
 				}
 				companyRepo.On("Get", mock.AnythingOfType("*dtos.CompanyPageableRequest"), mock.AnythingOfType("[]string")).Return(companies, nil)
@@ -480,3 +684,163 @@ func TestCompanyService_List(t *testing.T) {
 		})
 	}
 }
+
+func TestCompanyService_ListMembers(t *testing.T) {
+	companyID := uuid.New().String()
+	pr := &dtos.PageableRequest{Page: 1, PageSize: 10}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockCompanyRepositoryForCompanyService, *MockUserRepository)
+		expectedError bool
+		expectedCount int
+	}{
+		{
+			name: "success - list members",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(factory.Company().WithID(companyID).Build(), nil)
+				userRepo.On("GetByCompanyID", companyID, pr).Return(&dtos.DataResponse[models.User]{
+					Data: []models.User{
+						*factory.User().WithEmail("john@example.com").Build(),
+						*factory.User().WithEmail("jane@example.com").Build(),
+					},
+					Pageable: &dtos.Pageable{Page: 1, PageSize: 10, Total: 2},
+				}, nil)
+			},
+			expectedCount: 2,
+		},
+		{
+			name: "error - company not found",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+		},
+		{
+			name: "error - database error",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(factory.Company().WithID(companyID).Build(), nil)
+				userRepo.On("GetByCompanyID", companyID, pr).Return(nil, errors.DatabaseError("Failed to get company members", nil))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCompanyRepo := new(MockCompanyRepositoryForCompanyService)
+			mockUserRepo := new(MockUserRepository)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockCompanyRepo, mockUserRepo)
+			}
+
+			service := &companyService{
+				companyRepo: mockCompanyRepo,
+				userRepo:    mockUserRepo,
+				cache:       new(MockCache),
+			}
+
+			result, err := service.ListMembers(context.Background(), companyID, pr)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Len(t, result.Data, tt.expectedCount)
+			}
+
+			mockCompanyRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompanyService_StreamMembers(t *testing.T) {
+	companyID := uuid.New().String()
+	createdAt := time.Date(2025, 9, 11, 0, 0, 0, 0, time.UTC)
+	newMembers := func(count int) []models.User {
+		members := make([]models.User, count)
+		for i := range members {
+			members[i] = *factory.User().WithCreatedAt(createdAt.Add(-time.Duration(i) * time.Minute)).Build()
+		}
+		return members
+	}
+	firstPage := newMembers(constants.StreamBatchSize)
+	last := firstPage[len(firstPage)-1]
+	cursor := &dtos.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockCompanyRepositoryForCompanyService, *MockUserRepository)
+		yieldErr      error
+		expectedError bool
+		expectedPages []int
+	}{
+		{
+			name: "success - pages read after the last member",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(factory.Company().WithID(companyID).Build(), nil)
+				userRepo.On("GetByCompanyIDAfter", companyID, (*dtos.Cursor)(nil), constants.StreamBatchSize).Return(firstPage, nil)
+				userRepo.On("GetByCompanyIDAfter", companyID, cursor, constants.StreamBatchSize).Return(newMembers(3), nil)
+			},
+			expectedPages: []int{constants.StreamBatchSize, 3},
+		},
+		{
+			name: "success - no members",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(factory.Company().WithID(companyID).Build(), nil)
+				userRepo.On("GetByCompanyIDAfter", companyID, (*dtos.Cursor)(nil), constants.StreamBatchSize).Return([]models.User{}, nil)
+			},
+		},
+		{
+			name: "error - yield stops the stream",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(factory.Company().WithID(companyID).Build(), nil)
+				userRepo.On("GetByCompanyIDAfter", companyID, (*dtos.Cursor)(nil), constants.StreamBatchSize).Return(firstPage, nil)
+			},
+			yieldErr:      stderrors.New("broken pipe"),
+			expectedError: true,
+			expectedPages: []int{constants.StreamBatchSize},
+		},
+		{
+			name: "error - company not found",
+			setupMocks: func(companyRepo *MockCompanyRepositoryForCompanyService, userRepo *MockUserRepository) {
+				companyRepo.On("GetOneByID", companyID).Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCompanyRepo := new(MockCompanyRepositoryForCompanyService)
+			mockUserRepo := new(MockUserRepository)
+			tt.setupMocks(mockCompanyRepo, mockUserRepo)
+
+			service := &companyService{
+				companyRepo: mockCompanyRepo,
+				userRepo:    mockUserRepo,
+				cache:       new(MockCache),
+			}
+
+			var pages []int
+			err := service.StreamMembers(context.Background(), companyID, func(members []models.User) error {
+				pages = append(pages, len(members))
+				return tt.yieldErr
+			})
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPages, pages)
+
+			mockCompanyRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+)
+
+type DashboardService interface {
+	// GetCompanySummary returns the summary of a company from the company_summaries read
+	// model, or an empty summary while the company is not projected yet
+	GetCompanySummary(ctx context.Context, companyID string) (*models.CompanySummary, error)
+	ListCompanySummaries(ctx context.Context, pageableRequest *dtos.CompanySummaryPageableRequest) (*dtos.DataResponse[models.CompanySummary], error)
+}
+
+// dashboardService serves the dashboards from the read models, so that their queries read
+// one row per company whatever its size
+type dashboardService struct {
+	summaryRepo repositories.CompanySummaryRepository
+	companyRepo repositories.CompanyRepository
+}
+
+// ProvideDashboardService creates a new dashboard service
+func ProvideDashboardService(
+	summaryRepo repositories.CompanySummaryRepository,
+	companyRepo repositories.CompanyRepository,
+) DashboardService {
+	return &dashboardService{
+		summaryRepo: summaryRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+func (s *dashboardService) GetCompanySummary(ctx context.Context, companyID string) (*models.CompanySummary, error) {
+	summary, err := s.summaryRepo.Get(companyID)
+	if err == nil {
+		return summary, nil
+	}
+	if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+		return nil, err
+	}
+
+	// The events of a new company may not be applied yet
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_company_summary").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return &models.CompanySummary{CompanyID: company.ID, Name: company.Name}, nil
+}
+
+func (s *dashboardService) ListCompanySummaries(ctx context.Context, pageableRequest *dtos.CompanySummaryPageableRequest) (*dtos.DataResponse[models.CompanySummary], error) {
+	return s.summaryRepo.List(pageableRequest)
+}
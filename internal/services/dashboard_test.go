@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCompanySummaryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCompanySummaryRepository) Get(companyID string) (*models.CompanySummary, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CompanySummary), args.Error(1)
+}
+
+func (m *MockCompanySummaryRepository) List(pr *dtos.CompanySummaryPageableRequest) (*dtos.DataResponse[models.CompanySummary], error) {
+	args := m.Called(pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.CompanySummary]), args.Error(1)
+}
+
+func (m *MockCompanySummaryRepository) Refresh(companyID string, activity time.Time) error {
+	args := m.Called(companyID, activity)
+	return args.Error(0)
+}
+
+func (m *MockCompanySummaryRepository) Rebuild() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestDashboardService_GetCompanySummary(t *testing.T) {
+	lastActivityAt := time.Now()
+	summary := &models.CompanySummary{
+		CompanyID:      "company-1",
+		Name:           "Acme",
+		MemberCount:    4,
+		StorageBytes:   4096,
+		LastActivityAt: &lastActivityAt,
+		RefreshedAt:    lastActivityAt,
+	}
+
+	tests := []struct {
+		name          string
+		summaryErr    error
+		company       *models.Company
+		companyErr    error
+		expected      *models.CompanySummary
+		expectedError errors.ErrorType
+	}{
+		{
+			name:     "projected",
+			expected: summary,
+		},
+		{
+			name:       "not projected yet",
+			summaryErr: errors.NotFoundError("Company summary", nil),
+			company:    factory.Company().WithID("company-1").WithName("Acme").Build(),
+			expected:   &models.CompanySummary{CompanyID: "company-1", Name: "Acme"},
+		},
+		{
+			name:          "unknown company",
+			summaryErr:    errors.NotFoundError("Company summary", nil),
+			companyErr:    errors.DatabaseError("Failed to get company by ID", nil),
+			expectedError: errors.ErrorTypeNotFound,
+		},
+		{
+			name:          "database failure",
+			summaryErr:    errors.DatabaseError("Failed to get company summary", nil),
+			expectedError: errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaryRepo := new(MockCompanySummaryRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := ProvideDashboardService(summaryRepo, companyRepo)
+
+			if tt.summaryErr != nil {
+				summaryRepo.On("Get", "company-1").Return(nil, tt.summaryErr)
+			} else {
+				summaryRepo.On("Get", "company-1").Return(summary, nil)
+			}
+			companyRepo.On("GetOneByID", "company-1").Return(tt.company, tt.companyErr).Maybe()
+
+			result, err := service.GetCompanySummary(context.Background(), "company-1")
+
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			if tt.summaryErr == nil {
+				companyRepo.AssertNotCalled(t, "GetOneByID", mock.Anything)
+			}
+		})
+	}
+}
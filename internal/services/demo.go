@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/demo"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/projections"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type DemoService interface {
+	SeedIfEmpty(ctx context.Context) (*dtos.DemoSeedResponse, error)
+	Reset(ctx context.Context) (*dtos.DemoSeedResponse, error)
+}
+
+// demoService seeds and resets the demo dataset
+type demoService struct {
+	demoRepo  repositories.DemoRepository
+	files     files.Registry
+	projector *projections.Projector
+	cfg       *config.Config
+}
+
+// ProvideDemoService creates a new demo service
+func ProvideDemoService(
+	demoRepo repositories.DemoRepository,
+	files files.Registry,
+	projector *projections.Projector,
+	cfg *config.Config,
+) DemoService {
+	return &demoService{
+		demoRepo:  demoRepo,
+		files:     files,
+		projector: projector,
+		cfg:       cfg,
+	}
+}
+
+// SeedIfEmpty seeds the demo dataset unless the database already holds companies or users.
+// It returns nil without error when nothing was seeded.
+func (s *demoService) SeedIfEmpty(ctx context.Context) (*dtos.DemoSeedResponse, error) {
+	hasData, err := s.demoRepo.HasData()
+	if err != nil {
+		return nil, err
+	}
+	if hasData {
+		logger.Log.Info("Demo dataset not seeded, database is not empty")
+		return nil, nil
+	}
+
+	return s.seed(ctx)
+}
+
+// Reset deletes every company and user with their avatars and seeds the demo dataset again
+func (s *demoService) Reset(ctx context.Context) (*dtos.DemoSeedResponse, error) {
+	avatarKeys, err := s.demoRepo.Purge()
+	if err != nil {
+		logger.Log.Error("Failed to purge demo data", zap.Error(err))
+
+		return nil, errors.DatabaseError("Failed to reset demo data", err).
+			WithOperation("reset_demo").
+			WithResource("demo")
+	}
+
+	s.deleteAvatars(ctx, avatarKeys)
+
+	return s.seed(ctx)
+}
+
+// seed renders the dataset, uploads generated avatars and persists the companies and users,
+// then rebuilds the read models, as the dataset is written without domain events. A failed
+// avatar upload only leaves the user without an avatar.
+func (s *demoService) seed(ctx context.Context) (*dtos.DemoSeedResponse, error) {
+	dataset, err := demo.Load(s.cfg.DemoDatasetPath)
+	if err != nil {
+		return nil, errors.InternalError("Failed to load demo dataset", err).
+			WithOperation("seed_demo").
+			WithResource("demo").
+			WithContext("dataset_path", s.cfg.DemoDatasetPath)
+	}
+
+	seed, err := dataset.Build()
+	if err != nil {
+		return nil, errors.InternalError("Invalid demo dataset", err).
+			WithOperation("seed_demo").
+			WithResource("demo").
+			WithContext("dataset_path", s.cfg.DemoDatasetPath)
+	}
+
+	uploaded := make([]string, 0, len(seed.Users))
+	for _, seedUser := range seed.Users {
+		key, size, err := s.uploadAvatar(ctx, seedUser)
+		if err != nil {
+			logger.Log.Warn("Failed to upload demo avatar",
+				zap.String("user_id", seedUser.User.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		seedUser.User.AvatarKey = key
+		seedUser.User.AvatarSize = size
+		uploaded = append(uploaded, key)
+	}
+
+	if err := s.demoRepo.Seed(seed.Companies); err != nil {
+		logger.Log.Error("Failed to seed demo data",
+			zap.Int("companies", len(seed.Companies)),
+			zap.Int("users", len(seed.Users)),
+			zap.Error(err),
+		)
+
+		s.deleteAvatars(ctx, uploaded)
+
+		return nil, errors.DatabaseError("Failed to seed demo data", err).
+			WithOperation("seed_demo").
+			WithResource("demo")
+	}
+
+	if err := s.projector.Rebuild(ctx); err != nil {
+		logger.Log.Warn("Failed to rebuild projections of demo data", zap.Error(err))
+	}
+
+	logger.Log.Info("Seeded demo dataset",
+		zap.Int("companies", len(seed.Companies)),
+		zap.Int("users", len(seed.Users)),
+		zap.Int("avatars", len(uploaded)),
+	)
+
+	return &dtos.DemoSeedResponse{
+		Companies: len(seed.Companies),
+		Users:     len(seed.Users),
+		Avatars:   len(uploaded),
+		SeededAt:  time.Now().UTC(),
+	}, nil
+}
+
+// uploadAvatar stores a generated avatar for the user and returns its object key and size
+func (s *demoService) uploadAvatar(ctx context.Context, seedUser demo.SeedUser) (string, int64, error) {
+	content, err := demo.Avatar(seedUser.AvatarColor, constants.DemoAvatarSize)
+	if err != nil {
+		return "", 0, err
+	}
+
+	file, err := utils.NewFileHeader("avatar.png", "image/png", content)
+	if err != nil {
+		return "", 0, err
+	}
+
+	key := fmt.Sprintf("%s/%s/%s.png", constants.UserAvatarKeyPrefix, seedUser.User.ID, uuid.Must(uuid.NewV7()).String())
+	if _, err := s.files.Upload(ctx, file, key, "image/png", seedUser.User.ID); err != nil {
+		return "", 0, err
+	}
+
+	return key, file.Size, nil
+}
+
+// deleteAvatars removes avatar objects, logging the ones that could not be deleted
+func (s *demoService) deleteAvatars(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.files.Delete(ctx, key); err != nil {
+			logger.Log.Warn("Failed to delete demo avatar",
+				zap.String("avatar_key", key),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/projections"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDemoRepository is a mock implementation of DemoRepository
+type MockDemoRepository struct {
+	mock.Mock
+}
+
+func (m *MockDemoRepository) HasData() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDemoRepository) Seed(companies []models.Company) error {
+	args := m.Called(companies)
+	return args.Error(0)
+}
+
+func (m *MockDemoRepository) Purge() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockProjection is a mock implementation of projections.Projection
+type MockProjection struct {
+	mock.Mock
+}
+
+func (m *MockProjection) Name() string {
+	return "mock"
+}
+
+func (m *MockProjection) Apply(ctx context.Context, event projections.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockProjection) Rebuild(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// newTestProjector returns a projector of the projection, without enqueuer
+func newTestProjector(t *testing.T, projection projections.Projection) *projections.Projector {
+	t.Helper()
+	projector, err := projections.New(nil, projection)
+	require.NoError(t, err)
+	return projector
+}
+
+// seededWithAvatars matches seeded companies whose users all have an avatar key and size
+func seededWithAvatars(companies []models.Company) bool {
+	for _, company := range companies {
+		for _, user := range company.Users {
+			if !strings.HasPrefix(user.AvatarKey, "avatars/"+user.ID+"/") || user.AvatarSize <= 0 {
+				return false
+			}
+		}
+	}
+	return len(companies) > 0
+}
+
+func TestDemoService_SeedIfEmpty(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockDemoRepository, *MockFileRegistry)
+		expectSeeded  bool
+		expectedError bool
+	}{
+		{
+			name: "success - seeds an empty database",
+			setupMocks: func(demoRepo *MockDemoRepository, files *MockFileRegistry) {
+				demoRepo.On("HasData").Return(false, nil)
+				files.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "image/png", mock.AnythingOfType("string")).Return(factory.File().Build(), nil)
+				demoRepo.On("Seed", mock.MatchedBy(seededWithAvatars)).Return(nil)
+			},
+			expectSeeded: true,
+		},
+		{
+			name: "success - skips a database with data",
+			setupMocks: func(demoRepo *MockDemoRepository, files *MockFileRegistry) {
+				demoRepo.On("HasData").Return(true, nil)
+			},
+		},
+		{
+			name: "success - avatar upload failures do not block seeding",
+			setupMocks: func(demoRepo *MockDemoRepository, files *MockFileRegistry) {
+				demoRepo.On("HasData").Return(false, nil)
+				files.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "image/png", mock.AnythingOfType("string")).Return(nil, assert.AnError)
+				demoRepo.On("Seed", mock.Anything).Return(nil)
+			},
+			expectSeeded: true,
+		},
+		{
+			name: "error - seed fails and uploaded avatars are removed",
+			setupMocks: func(demoRepo *MockDemoRepository, files *MockFileRegistry) {
+				demoRepo.On("HasData").Return(false, nil)
+				files.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "image/png", mock.AnythingOfType("string")).Return(factory.File().Build(), nil)
+				demoRepo.On("Seed", mock.Anything).Return(errors.DatabaseError("Failed to seed demo data", nil))
+				files.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDemoRepo := new(MockDemoRepository)
+			mockFiles := new(MockFileRegistry)
+			projection := new(MockProjection)
+			tt.setupMocks(mockDemoRepo, mockFiles)
+			if tt.expectSeeded {
+				projection.On("Rebuild", mock.Anything).Return(int64(3), nil)
+			}
+
+			service := &demoService{
+				demoRepo:  mockDemoRepo,
+				files:     mockFiles,
+				projector: newTestProjector(t, projection),
+				cfg:       &config.Config{DemoMode: true},
+			}
+
+			result, err := service.SeedIfEmpty(context.Background())
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, errors.ErrorTypeDatabase, appErr.Type)
+			} else {
+				require.NoError(t, err)
+				if tt.expectSeeded {
+					require.NotNil(t, result)
+					assert.Positive(t, result.Companies)
+					assert.Positive(t, result.Users)
+				} else {
+					assert.Nil(t, result)
+				}
+			}
+
+			mockDemoRepo.AssertExpectations(t)
+			mockFiles.AssertExpectations(t)
+			projection.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDemoService_Reset(t *testing.T) {
+	mockDemoRepo := new(MockDemoRepository)
+	mockFiles := new(MockFileRegistry)
+
+	mockDemoRepo.On("Purge").Return([]string{"avatars/1/old.png"}, nil)
+	mockFiles.On("Delete", mock.Anything, "avatars/1/old.png").Return(assert.AnError)
+	mockFiles.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "image/png", mock.AnythingOfType("string")).Return(factory.File().Build(), nil)
+	mockDemoRepo.On("Seed", mock.MatchedBy(seededWithAvatars)).Return(nil)
+	// A failed rebuild of the read models does not fail the reset
+	projection := new(MockProjection)
+	projection.On("Rebuild", mock.Anything).Return(int64(0), assert.AnError)
+
+	service := &demoService{
+		demoRepo:  mockDemoRepo,
+		files:     mockFiles,
+		projector: newTestProjector(t, projection),
+		cfg:       &config.Config{DemoMode: true},
+	}
+
+	result, err := service.Reset(context.Background())
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, result.Users, result.Avatars)
+	mockDemoRepo.AssertExpectations(t)
+	mockFiles.AssertExpectations(t)
+	projection.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"golang-boilerplate/internal/deprecation"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/repositories"
+)
+
+// DeprecationService reports the usage of the deprecated surfaces, recorded by the
+// DeprecationUsageRecorder
+type DeprecationService interface {
+	// Report returns the deprecations with their consumers
+	Report(ctx context.Context) (*dtos.DeprecationReportResponse, error)
+}
+
+// deprecationService implements DeprecationService
+type deprecationService struct {
+	registry        *deprecation.Registry
+	deprecationRepo repositories.DeprecationRepository
+}
+
+// ProvideDeprecationService creates a new deprecation service
+func ProvideDeprecationService(registry *deprecation.Registry, deprecationRepo repositories.DeprecationRepository) DeprecationService {
+	return &deprecationService{
+		registry:        registry,
+		deprecationRepo: deprecationRepo,
+	}
+}
+
+func (s *deprecationService) Report(ctx context.Context) (*dtos.DeprecationReportResponse, error) {
+	usages, err := s.deprecationRepo.GetUsage()
+	if err != nil {
+		return nil, err
+	}
+	consumers := make(map[string][]dtos.DeprecationConsumerResponse)
+	for _, usage := range usages {
+		consumers[usage.DeprecationID] = append(consumers[usage.DeprecationID], dtos.DeprecationConsumerResponse{
+			Consumer:    usage.Consumer,
+			Tenant:      usage.Tenant,
+			Calls:       usage.Calls,
+			FirstSeenAt: usage.FirstSeenAt,
+			LastSeenAt:  usage.LastSeenAt,
+		})
+	}
+
+	now := time.Now()
+	deprecations := s.registry.All()
+	report := &dtos.DeprecationReportResponse{
+		Deprecations: make([]dtos.DeprecationResponse, 0, len(deprecations)),
+	}
+	for _, d := range deprecations {
+		response := dtos.DeprecationResponse{
+			ID:           d.ID,
+			Kind:         d.Kind,
+			Surface:      d.Surface,
+			DeprecatedAt: d.DeprecatedAt,
+			Sunset:       d.Sunset,
+			DaysToSunset: max(0, int(d.Sunset.Sub(now).Hours()/24)),
+			Replacement:  d.Replacement,
+			Link:         d.Link,
+			Consumers:    consumers[d.ID],
+		}
+		if response.Consumers == nil {
+			response.Consumers = []dtos.DeprecationConsumerResponse{}
+		}
+		for _, consumer := range response.Consumers {
+			response.Calls += consumer.Calls
+		}
+		report.Deprecations = append(report.Deprecations, response)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/deprecation"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDeprecationRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeprecationRepository) RecordUsage(usages []models.DeprecationUsage) error {
+	args := m.Called(usages)
+	return args.Error(0)
+}
+
+func (m *MockDeprecationRepository) GetUsage() ([]models.DeprecationUsage, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DeprecationUsage), args.Error(1)
+}
+
+func TestDeprecationService_Report(t *testing.T) {
+	deprecationRepo := new(MockDeprecationRepository)
+	registry := deprecation.NewRegistry(
+		deprecation.Deprecation{ID: "health-database", Kind: constants.DeprecationKindRoute, Sunset: time.Now().AddDate(0, 0, 30)},
+		deprecation.Deprecation{ID: "user-phone", Kind: constants.DeprecationKindField, Sunset: time.Now().AddDate(0, 0, 90)},
+		deprecation.Deprecation{ID: "health-auth", Kind: constants.DeprecationKindRoute, Sunset: time.Now().AddDate(0, 0, -1)},
+	)
+	service := ProvideDeprecationService(registry, deprecationRepo)
+
+	deprecationRepo.On("GetUsage").Return([]models.DeprecationUsage{
+		{DeprecationID: "health-database", Consumer: "api_key:key-1", Tenant: "company-1", Calls: 40},
+		{DeprecationID: "health-database", Consumer: "client:frontend", Tenant: "org-1", Calls: 2},
+		{DeprecationID: "health-auth", Consumer: "anonymous", Calls: 1},
+		{DeprecationID: "removed", Consumer: "api_key:key-1", Calls: 9},
+	}, nil)
+
+	report, err := service.Report(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Deprecations, 3)
+	sunsetPassed, database, phone := report.Deprecations[0], report.Deprecations[1], report.Deprecations[2]
+
+	assert.Equal(t, "health-auth", sunsetPassed.ID)
+	assert.Equal(t, 0, sunsetPassed.DaysToSunset)
+	assert.Equal(t, int64(1), sunsetPassed.Calls)
+
+	assert.Equal(t, "health-database", database.ID)
+	assert.InDelta(t, 30, database.DaysToSunset, 1)
+	assert.Equal(t, int64(42), database.Calls)
+	require.Len(t, database.Consumers, 2)
+	assert.Equal(t, "api_key:key-1", database.Consumers[0].Consumer)
+	assert.Equal(t, "company-1", database.Consumers[0].Tenant)
+
+	assert.Equal(t, "user-phone", phone.ID)
+	assert.Zero(t, phone.Calls)
+	assert.NotNil(t, phone.Consumers, "the consumers are never null")
+	assert.Empty(t, phone.Consumers)
+}
+
+func TestDeprecationUsageRecorder_Flush(t *testing.T) {
+	deprecationRepo := new(MockDeprecationRepository)
+	recorder := ProvideDeprecationUsageRecorder(&config.Config{DeprecationFlushInterval: time.Hour}, deprecationRepo)
+
+	recorder.Record("health-database", "api_key:key-1", "company-1")
+	recorder.Record("health-database", "api_key:key-1", "company-1")
+	recorder.Record("health-auth", "client:frontend", "org-1")
+
+	// A failed flush keeps the counts for the next one
+	deprecationRepo.On("RecordUsage", mock.Anything).Return(stderrors.New("connection refused")).Once()
+	require.Error(t, recorder.Flush())
+	recorder.Record("health-database", "api_key:key-1", "company-1")
+
+	var flushed map[string]models.DeprecationUsage
+	deprecationRepo.On("RecordUsage", mock.Anything).
+		Run(func(args mock.Arguments) {
+			flushed = make(map[string]models.DeprecationUsage)
+			for _, usage := range args.Get(0).([]models.DeprecationUsage) {
+				flushed[usage.DeprecationID+" "+usage.Consumer] = usage
+			}
+		}).
+		Return(nil).Once()
+	require.NoError(t, recorder.Flush())
+
+	require.Len(t, flushed, 2)
+	database := flushed["health-database api_key:key-1"]
+	assert.Equal(t, int64(3), database.Calls)
+	assert.Equal(t, "company-1", database.Tenant)
+	assert.False(t, database.LastSeenAt.Before(database.FirstSeenAt))
+	assert.Equal(t, int64(1), flushed["health-auth client:frontend"].Calls)
+
+	// Nothing left to flush
+	require.NoError(t, recorder.Flush())
+	deprecationRepo.AssertExpectations(t)
+}
+
+func TestDeprecationUsageRecorder_StopFlushes(t *testing.T) {
+	deprecationRepo := new(MockDeprecationRepository)
+	recorder := ProvideDeprecationUsageRecorder(&config.Config{DeprecationFlushInterval: time.Hour}, deprecationRepo)
+	deprecationRepo.On("RecordUsage", mock.Anything).Return(nil).Once()
+
+	recorder.Start()
+	recorder.Record("health-database", "anonymous", "")
+	require.NoError(t, recorder.Stop(context.Background()))
+
+	deprecationRepo.AssertExpectations(t)
+}
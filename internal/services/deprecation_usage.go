@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// deprecationUsageKey identifies the counter of the calls of a consumer to a deprecation
+type deprecationUsageKey struct {
+	deprecationID string
+	consumer      string
+}
+
+// deprecationUsageCounts are the calls of a counter since the last flush
+type deprecationUsageCounts struct {
+	tenant      string
+	calls       int64
+	firstSeenAt time.Time
+	lastSeenAt  time.Time
+}
+
+// DeprecationUsageRecorder counts the calls to the deprecated surfaces per consumer in
+// memory and adds them to the database every DEPRECATION_USAGE_FLUSH_INTERVAL, so that
+// requests never wait on a write. Counts that fail to flush are kept for the next flush.
+type DeprecationUsageRecorder struct {
+	repo     repositories.DeprecationRepository
+	interval time.Duration
+
+	mu    sync.Mutex
+	usage map[deprecationUsageKey]deprecationUsageCounts
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// ProvideDeprecationUsageRecorder creates the usage recorder, started with the HTTP server
+func ProvideDeprecationUsageRecorder(cfg *config.Config, repo repositories.DeprecationRepository) *DeprecationUsageRecorder {
+	return &DeprecationUsageRecorder{
+		repo:     repo,
+		interval: cfg.DeprecationFlushInterval,
+		usage:    make(map[deprecationUsageKey]deprecationUsageCounts),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Record counts a call of the consumer, from the tenant, to the deprecation
+func (r *DeprecationUsageRecorder) Record(deprecationID string, consumer string, tenant string) {
+	now := time.Now()
+	key := deprecationUsageKey{deprecationID: deprecationID, consumer: consumer}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts, ok := r.usage[key]
+	if !ok {
+		counts.firstSeenAt = now
+	}
+	counts.tenant = tenant
+	counts.calls++
+	counts.lastSeenAt = now
+	r.usage[key] = counts
+}
+
+// Start flushes the counts periodically until Stop
+func (r *DeprecationUsageRecorder) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					logger.Log.Warn("Failed to flush deprecation usage", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flushes and flushes the remaining counts. It must run before
+// the database connections are closed.
+func (r *DeprecationUsageRecorder) Stop(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return r.Flush()
+}
+
+// Flush adds the counts recorded since the last flush to the database
+func (r *DeprecationUsageRecorder) Flush() error {
+	r.mu.Lock()
+	usage := r.usage
+	r.usage = make(map[deprecationUsageKey]deprecationUsageCounts)
+	r.mu.Unlock()
+
+	if len(usage) == 0 {
+		return nil
+	}
+
+	rows := make([]models.DeprecationUsage, 0, len(usage))
+	for key, counts := range usage {
+		rows = append(rows, models.DeprecationUsage{
+			BaseModel:     models.NewBaseModel(),
+			DeprecationID: key.deprecationID,
+			Consumer:      key.consumer,
+			Tenant:        counts.tenant,
+			Calls:         counts.calls,
+			FirstSeenAt:   counts.firstSeenAt,
+			LastSeenAt:    counts.lastSeenAt,
+		})
+	}
+
+	if err := r.repo.RecordUsage(rows); err != nil {
+		r.restore(usage)
+		return err
+	}
+
+	return nil
+}
+
+// restore merges back counts that failed to flush
+func (r *DeprecationUsageRecorder) restore(usage map[deprecationUsageKey]deprecationUsageCounts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, counts := range usage {
+		current, ok := r.usage[key]
+		if !ok {
+			r.usage[key] = counts
+			continue
+		}
+		current.calls += counts.calls
+		if counts.firstSeenAt.Before(current.firstSeenAt) {
+			current.firstSeenAt = counts.firstSeenAt
+		}
+		r.usage[key] = current
+	}
+}
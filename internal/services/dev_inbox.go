@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+)
+
+type DevInboxService interface {
+	// List returns the captured emails of all the inboxes matching the request, most
+	// recent first
+	List(ctx context.Context, pageableRequest *dtos.DevInboxPageableRequest) (*dtos.DataResponse[models.CapturedEmail], error)
+	Get(ctx context.Context, emailID string) (*models.CapturedEmail, error)
+}
+
+// devInboxService serves the emails captured instead of being sent in the non-production
+// environments, so that QA can follow the email flows without real mailboxes
+type devInboxService struct {
+	emailRepo repositories.CapturedEmailRepository
+}
+
+// ProvideDevInboxService creates a new dev inbox service
+func ProvideDevInboxService(emailRepo repositories.CapturedEmailRepository) DevInboxService {
+	return &devInboxService{
+		emailRepo: emailRepo,
+	}
+}
+
+func (s *devInboxService) List(ctx context.Context, pageableRequest *dtos.DevInboxPageableRequest) (*dtos.DataResponse[models.CapturedEmail], error) {
+	return s.emailRepo.Search(pageableRequest)
+}
+
+func (s *devInboxService) Get(ctx context.Context, emailID string) (*models.CapturedEmail, error) {
+	return s.emailRepo.GetOne(emailID)
+}
@@ -2,47 +2,108 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/integration/email"
+	"golang-boilerplate/internal/integration/email/templates"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/sandbox"
 )
 
-// EmailService handles email business logic
+// EmailService handles email business logic. The emails are rendered from the templates
+// of the registry rather than built inline, and queued: the workers send them with
+// retries, so that a provider outage does not fail the requests sending them. The emails
+// still failing after their attempts are kept in the dead-letter queue to be re-driven.
+// The addresses that bounced or complained, as reported by the SES notifications, are
+// suppressed: no email is sent to them anymore.
 type EmailService struct {
-	emailSender email.EmailSender
+	enqueuer     jobs.Enqueuer
+	deadLetters  jobs.DeadLetters
+	suppressions repositories.EmailSuppressionRepository
+	snsVerifier  email.SNSVerifier
+	templates    *templates.Registry
 }
 
 // NewEmailService creates a new email service
-func ProvideEmailService(emailSender email.EmailSender) EmailService {
+func ProvideEmailService(
+	enqueuer jobs.Enqueuer,
+	deadLetters jobs.DeadLetters,
+	suppressions repositories.EmailSuppressionRepository,
+	snsVerifier email.SNSVerifier,
+	registry *templates.Registry,
+) EmailService {
 	return EmailService{
-		emailSender: emailSender,
+		enqueuer:     enqueuer,
+		deadLetters:  deadLetters,
+		suppressions: suppressions,
+		snsVerifier:  snsVerifier,
+		templates:    registry,
 	}
 }
 
 // SendWelcomeEmail sends a welcome email to a new user
 func (s *EmailService) SendWelcomeEmail(ctx context.Context, userEmail, userName string) error {
-	message := &email.EmailRequest{
-		To:       []string{userEmail},
-		Subject:  "Welcome to My Echo App!",
-		TextBody: fmt.Sprintf("Hello %s,\n\nWelcome to My Echo App! We're excited to have you on board.\n\nBest regards,\nThe Team", userName),
-		HTMLBody: fmt.Sprintf(`
-			<html>
-				<body>
-					<h2>Welcome to My Echo App!</h2>
-					<p>Hello %s,</p>
-					<p>Welcome to My Echo App! We're excited to have you on board.</p>
-					<p>Best regards,<br>The Team</p>
-				</body>
-			</html>
-		`, userName),
+	return s.send(ctx, userEmail, templates.Welcome{UserName: userName}, "send_welcome_email", "Failed to send welcome email")
+}
+
+// SendPasswordResetEmail sends a password reset email
+func (s *EmailService) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
+	resetURL := fmt.Sprintf("https://yourapp.com/reset-password?token=%s", resetToken)
+
+	return s.send(ctx, userEmail, templates.PasswordReset{ResetURL: resetURL}, "send_password_reset_email", "Failed to send password reset email")
+}
+
+// SendInvitationEmail sends the invitation to join a company, with the link accepting it
+func (s *EmailService) SendInvitationEmail(ctx context.Context, userEmail, companyName, acceptURL string, expiresAt time.Time) error {
+	return s.send(ctx, userEmail, templates.Invitation{
+		CompanyName: companyName,
+		AcceptURL:   acceptURL,
+		ExpiresAt:   expiresAt,
+	}, "send_invitation_email", "Failed to send invitation email")
+}
+
+// SendNotificationEmail sends a notification email
+func (s *EmailService) SendNotificationEmail(ctx context.Context, userEmail, subject, message string) error {
+	return s.send(ctx, userEmail, templates.Notification{Subject: subject, Message: message}, "send_notification_email", "Failed to send notification email")
+}
+
+// send renders the email and queues it to the user, refusing a suppressed address
+func (s *EmailService) send(ctx context.Context, userEmail string, data templates.Email, operation, failure string) error {
+	suppressed, err := s.suppressions.GetSuppressed([]string{userEmail})
+	if err != nil {
+		return err
+	}
+	if len(suppressed) > 0 {
+		return errors.ValidationError("Email address suppressed after a bounce or complaint", nil).
+			WithOperation(operation).
+			WithResource("email").
+			WithContext("user_email", userEmail)
+	}
+
+	rendered, err := s.templates.Render(data)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.emailSender.SendEmail(ctx, *message)
+	err = s.enqueue(ctx, email.EmailRequest{
+		To:       []string{userEmail},
+		Subject:  rendered.Subject,
+		TextBody: rendered.Text,
+		HTMLBody: rendered.HTML,
+	})
 
 	if err != nil {
-		return errors.ExternalServiceError("Failed to send welcome email", err).
-			WithOperation("send_welcome_email").
+		return errors.ExternalServiceError(failure, err).
+			WithOperation(operation).
 			WithResource("email").
 			WithContext("user_email", userEmail)
 	}
@@ -50,62 +111,145 @@ func (s *EmailService) SendWelcomeEmail(ctx context.Context, userEmail, userName
 	return nil
 }
 
-// SendPasswordResetEmail sends a password reset email
-func (s *EmailService) SendPasswordResetEmail(ctx context.Context, userEmail, resetToken string) error {
-	resetURL := fmt.Sprintf("https://yourapp.com/reset-password?token=%s", resetToken)
+// enqueue queues an email, sent in the pacing lane and the sandbox tenant of ctx
+func (s *EmailService) enqueue(ctx context.Context, request email.EmailRequest) error {
+	args := jobs.SendEmailArgs{Request: request, Priority: email.PriorityFromContext(ctx)}
+	if companyID, ok := sandbox.CompanyID(ctx); ok {
+		args.SandboxCompanyID = companyID
+	}
 
-	message := &email.EmailRequest{
-		To:       []string{userEmail},
-		Subject:  "Password Reset Request",
-		TextBody: fmt.Sprintf("You requested a password reset. Click the link below to reset your password:\n\n%s\n\nIf you didn't request this, please ignore this email.", resetURL),
-		HTMLBody: fmt.Sprintf(`
-			<html>
-				<body>
-					<h2>Password Reset Request</h2>
-					<p>You requested a password reset. Click the link below to reset your password:</p>
-					<p><a href="%s">Reset Password</a></p>
-					<p>If you didn't request this, please ignore this email.</p>
-				</body>
-			</html>
-		`, resetURL),
+	_, err := s.enqueuer.Enqueue(ctx, args)
+	return err
+}
+
+// SendBulkNotificationEmail queues the same notification to many users in the bulk lane,
+// so the campaign is paced behind transactional emails. The suppressed addresses are
+// skipped. It returns the number of emails queued.
+func (s *EmailService) SendBulkNotificationEmail(ctx context.Context, userEmails []string, subject, message string) (int, error) {
+	rendered, err := s.templates.Render(templates.Notification{Subject: subject, Message: message})
+	if err != nil {
+		return 0, err
+	}
+
+	suppressed, err := s.suppressions.GetSuppressed(userEmails)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = email.WithPriority(ctx, email.PriorityBulk)
+	queued := 0
+	for _, userEmail := range userEmails {
+		if slices.Contains(suppressed, strings.ToLower(userEmail)) {
+			continue
+		}
+		err := s.enqueue(ctx, email.EmailRequest{
+			To:       []string{userEmail},
+			Subject:  rendered.Subject,
+			TextBody: rendered.Text,
+			HTMLBody: rendered.HTML,
+		})
+		if err != nil {
+			return queued, errors.ExternalServiceError("Failed to send bulk notification email", err).
+				WithOperation("send_bulk_notification_email").
+				WithResource("email").
+				WithContext("recipients", len(userEmails)).
+				WithContext("queued", queued)
+		}
+		queued++
 	}
 
-	_, err := s.emailSender.SendEmail(ctx, *message)
+	return queued, nil
+}
 
+// ListFailedEmails returns the emails still failing after their attempts, most recently
+// failed first
+func (s *EmailService) ListFailedEmails(ctx context.Context, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[dtos.FailedEmailResponse], error) {
+	deadJobs, err := s.deadLetters.ListDeadOfKind(ctx, jobs.KindSendEmail, pageableRequest)
 	if err != nil {
-		return errors.ExternalServiceError("Failed to send password reset email", err).
-			WithOperation("send_password_reset_email").
-			WithResource("email").
-			WithContext("user_email", userEmail)
+		return nil, err
 	}
 
-	return nil
+	failed := make([]dtos.FailedEmailResponse, len(deadJobs.Data))
+	for i, job := range deadJobs.Data {
+		var args jobs.SendEmailArgs
+		if err := json.Unmarshal(job.Payload, &args); err != nil {
+			return nil, errors.InternalError("Failed to decode failed email", err).
+				WithOperation("list_failed_emails").
+				WithResource("email").
+				WithContext("job_id", job.ID)
+		}
+		failed[i] = dtos.FailedEmailResponse{
+			ID:        job.ID,
+			To:        args.Request.To,
+			Cc:        args.Request.Cc,
+			Subject:   args.Request.Subject,
+			Attempts:  job.Attempt,
+			LastError: job.LastError,
+			FailedAt:  job.FinishedAt,
+			CreatedAt: job.CreatedAt,
+		}
+	}
+
+	return &dtos.DataResponse[dtos.FailedEmailResponse]{Data: failed, Pageable: deadJobs.Pageable}, nil
 }
 
-// SendNotificationEmail sends a notification email
-func (s *EmailService) SendNotificationEmail(ctx context.Context, userEmail, subject, message string) error {
-	emailMessage := &email.EmailRequest{
-		To:       []string{userEmail},
-		Subject:  subject,
-		TextBody: message,
-		HTMLBody: fmt.Sprintf(`
-			<html>
-				<body>
-					<h2>%s</h2>
-					<p>%s</p>
-				</body>
-			</html>
-		`, subject, message),
+// RedriveFailedEmails queues the failed emails of ids again, or all of them when ids is
+// empty, with all their attempts. It returns the number of emails queued.
+func (s *EmailService) RedriveFailedEmails(ctx context.Context, ids []string) (int64, error) {
+	return s.deadLetters.RequeueDeadOfKind(ctx, jobs.KindSendEmail, ids)
+}
+
+// HandleSESNotification handles a message of the SNS topic of the SES notifications once
+// its signature is verified: it confirms the subscription of the endpoint to the topic,
+// and suppresses the recipients of the hard bounces and complaints. Transient bounces and
+// other notifications are ignored.
+func (s *EmailService) HandleSESNotification(ctx context.Context, message *dtos.SNSMessage) error {
+	if err := s.snsVerifier.Verify(ctx, message); err != nil {
+		return err
 	}
 
-	_, err := s.emailSender.SendEmail(ctx, *emailMessage)
+	switch message.Type {
+	case constants.SNSMessageTypeSubscriptionConfirmation:
+		return s.snsVerifier.ConfirmSubscription(ctx, message)
+	case constants.SNSMessageTypeNotification:
+		return s.suppressRecipients(message)
+	}
 
-	if err != nil {
-		return errors.ExternalServiceError("Failed to send notification email", err).
-			WithOperation("send_notification_email").
+	return nil
+}
+
+// suppressRecipients suppresses the recipients of a hard bounce or complaint notification
+func (s *EmailService) suppressRecipients(message *dtos.SNSMessage) error {
+	var notification dtos.SESNotification
+	if err := json.Unmarshal([]byte(message.Message), &notification); err != nil {
+		return errors.ValidationError("Invalid SES notification", err).
+			WithOperation("handle_ses_notification").
 			WithResource("email").
-			WithContext("user_email", userEmail)
+			WithContext("message_id", message.MessageID)
 	}
 
-	return nil
+	var suppressions []models.EmailSuppression
+	switch {
+	case notification.NotificationType == constants.SESNotificationTypeBounce && notification.Bounce != nil &&
+		notification.Bounce.BounceType == constants.SESBounceTypePermanent:
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			suppressions = append(suppressions, models.EmailSuppression{
+				Email:     recipient.EmailAddress,
+				Reason:    constants.EmailSuppressionReasonBounce,
+				Detail:    recipient.DiagnosticCode,
+				MessageID: notification.Mail.MessageID,
+			})
+		}
+	case notification.NotificationType == constants.SESNotificationTypeComplaint && notification.Complaint != nil:
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			suppressions = append(suppressions, models.EmailSuppression{
+				Email:     recipient.EmailAddress,
+				Reason:    constants.EmailSuppressionReasonComplaint,
+				Detail:    notification.Complaint.ComplaintFeedbackType,
+				MessageID: notification.Mail.MessageID,
+			})
+		}
+	}
+
+	return s.suppressions.Suppress(suppressions)
 }
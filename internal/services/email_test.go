@@ -2,35 +2,93 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
 	"golang-boilerplate/internal/integration/email"
+	"golang-boilerplate/internal/integration/email/templates"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/sandbox"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockEmailSender is a mock implementation of EmailSender
-type MockEmailSender struct {
+// newTestEmailTemplates returns the registry of the email templates
+func newTestEmailTemplates() *templates.Registry {
+	registry, err := templates.NewRegistry(templates.Brand{ProductName: "My Echo App"})
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// MockDeadLetters is a mock implementation of jobs.DeadLetters
+type MockDeadLetters struct {
 	mock.Mock
 }
 
-func (m *MockEmailSender) SendEmail(ctx context.Context, message email.EmailRequest) (*email.EmailResponse, error) {
-	args := m.Called(ctx, message)
+func (m *MockDeadLetters) ListDeadOfKind(ctx context.Context, kind string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Job], error) {
+	args := m.Called(kind, pr)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*email.EmailResponse), args.Error(1)
+	return args.Get(0).(*dtos.DataResponse[models.Job]), args.Error(1)
 }
 
-func (m *MockEmailSender) SendRawEmail(ctx context.Context, rawData []byte) (*email.EmailResponse, error) {
-	args := m.Called(ctx, rawData)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*email.EmailResponse), args.Error(1)
+func (m *MockDeadLetters) RequeueDeadOfKind(ctx context.Context, kind string, ids []string) (int64, error) {
+	args := m.Called(kind, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockEmailSuppressionRepository is a mock implementation of
+// repositories.EmailSuppressionRepository
+type MockEmailSuppressionRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailSuppressionRepository) Suppress(suppressions []models.EmailSuppression) error {
+	return m.Called(suppressions).Error(0)
+}
+
+func (m *MockEmailSuppressionRepository) GetSuppressed(emails []string) ([]string, error) {
+	args := m.Called(emails)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockSNSVerifier is a mock implementation of email.SNSVerifier
+type MockSNSVerifier struct {
+	mock.Mock
+}
+
+func (m *MockSNSVerifier) Verify(ctx context.Context, message *dtos.SNSMessage) error {
+	return m.Called(message).Error(0)
+}
+
+func (m *MockSNSVerifier) ConfirmSubscription(ctx context.Context, message *dtos.SNSMessage) error {
+	return m.Called(message).Error(0)
+}
+
+// newTestEmailService returns an email service suppressing no address
+func newTestEmailService(enqueuer jobs.Enqueuer, deadLetters jobs.DeadLetters) EmailService {
+	suppressions := new(MockEmailSuppressionRepository)
+	suppressions.On("GetSuppressed", mock.Anything).Return([]string(nil), nil).Maybe()
+	return ProvideEmailService(enqueuer, deadLetters, suppressions, new(MockSNSVerifier), newTestEmailTemplates())
+}
+
+// queuedEmail matches the jobs sending an email matching the request
+func queuedEmail(match func(req email.EmailRequest) bool) any {
+	return mock.MatchedBy(func(args jobs.Args) bool {
+		sendEmail, ok := args.(jobs.SendEmailArgs)
+		return ok && match(sendEmail.Request)
+	})
 }
 
 func TestEmailService_SendWelcomeEmail(t *testing.T) {
@@ -38,7 +96,7 @@ func TestEmailService_SendWelcomeEmail(t *testing.T) {
 		name          string
 		userEmail     string
 		userName      string
-		setupMock     func(*MockEmailSender)
+		setupMock     func(*MockEnqueuer)
 		expectedError bool
 		errorType     string
 	}{
@@ -46,28 +104,23 @@ func TestEmailService_SendWelcomeEmail(t *testing.T) {
 			name:      "success - send welcome email",
 			userEmail: "john.doe@example.com",
 			userName:  "John Doe",
-			setupMock: func(m *MockEmailSender) {
-				response := &email.EmailResponse{
-					MessageID: "msg-123",
-					Provider:  "ses",
-					Status:    "sent",
-				}
-				m.On("SendEmail", mock.Anything, mock.MatchedBy(func(req email.EmailRequest) bool {
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, queuedEmail(func(req email.EmailRequest) bool {
 					return req.Subject == "Welcome to My Echo App!" &&
 						len(req.To) == 1 &&
 						req.To[0] == "john.doe@example.com" &&
 						req.TextBody != "" &&
 						req.HTMLBody != ""
-				})).Return(response, nil)
+				})).Return(&models.Job{}, nil)
 			},
 			expectedError: false,
 		},
 		{
-			name:      "error - email sender fails",
+			name:      "error - email not queued",
 			userEmail: "john.doe@example.com",
 			userName:  "John Doe",
-			setupMock: func(m *MockEmailSender) {
-				m.On("SendEmail", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 			},
 			expectedError: true,
 			errorType:     "ExternalServiceError",
@@ -76,14 +129,12 @@ func TestEmailService_SendWelcomeEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockEmailSender := new(MockEmailSender)
+			mockEnqueuer := new(MockEnqueuer)
 			if tt.setupMock != nil {
-				tt.setupMock(mockEmailSender)
+				tt.setupMock(mockEnqueuer)
 			}
 
-			service := &EmailService{
-				emailSender: mockEmailSender,
-			}
+			service := newTestEmailService(mockEnqueuer, new(MockDeadLetters))
 
 			ctx := context.Background()
 			err := service.SendWelcomeEmail(ctx, tt.userEmail, tt.userName)
@@ -99,7 +150,7 @@ func TestEmailService_SendWelcomeEmail(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			mockEmailSender.AssertExpectations(t)
+			mockEnqueuer.AssertExpectations(t)
 		})
 	}
 }
@@ -109,7 +160,7 @@ func TestEmailService_SendPasswordResetEmail(t *testing.T) {
 		name          string
 		userEmail     string
 		resetToken    string
-		setupMock     func(*MockEmailSender)
+		setupMock     func(*MockEnqueuer)
 		expectedError bool
 		errorType     string
 	}{
@@ -117,29 +168,24 @@ func TestEmailService_SendPasswordResetEmail(t *testing.T) {
 			name:       "success - send password reset email",
 			userEmail:  "john.doe@example.com",
 			resetToken: "reset-token-123",
-			setupMock: func(m *MockEmailSender) {
-				response := &email.EmailResponse{
-					MessageID: "msg-456",
-					Provider:  "ses",
-					Status:    "sent",
-				}
-				m.On("SendEmail", mock.Anything, mock.MatchedBy(func(req email.EmailRequest) bool {
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, queuedEmail(func(req email.EmailRequest) bool {
 					return req.Subject == "Password Reset Request" &&
 						len(req.To) == 1 &&
 						req.To[0] == "john.doe@example.com" &&
 						req.TextBody != "" &&
 						req.HTMLBody != "" &&
 						contains(req.TextBody, "reset-token-123")
-				})).Return(response, nil)
+				})).Return(&models.Job{}, nil)
 			},
 			expectedError: false,
 		},
 		{
-			name:       "error - email sender fails",
+			name:       "error - email not queued",
 			userEmail:  "john.doe@example.com",
 			resetToken: "reset-token-123",
-			setupMock: func(m *MockEmailSender) {
-				m.On("SendEmail", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 			},
 			expectedError: true,
 			errorType:     "ExternalServiceError",
@@ -148,14 +194,12 @@ func TestEmailService_SendPasswordResetEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockEmailSender := new(MockEmailSender)
+			mockEnqueuer := new(MockEnqueuer)
 			if tt.setupMock != nil {
-				tt.setupMock(mockEmailSender)
+				tt.setupMock(mockEnqueuer)
 			}
 
-			service := &EmailService{
-				emailSender: mockEmailSender,
-			}
+			service := newTestEmailService(mockEnqueuer, new(MockDeadLetters))
 
 			ctx := context.Background()
 			err := service.SendPasswordResetEmail(ctx, tt.userEmail, tt.resetToken)
@@ -171,7 +215,7 @@ func TestEmailService_SendPasswordResetEmail(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			mockEmailSender.AssertExpectations(t)
+			mockEnqueuer.AssertExpectations(t)
 		})
 	}
 }
@@ -182,7 +226,7 @@ func TestEmailService_SendNotificationEmail(t *testing.T) {
 		userEmail     string
 		subject       string
 		message       string
-		setupMock     func(*MockEmailSender)
+		setupMock     func(*MockEnqueuer)
 		expectedError bool
 		errorType     string
 	}{
@@ -191,29 +235,24 @@ func TestEmailService_SendNotificationEmail(t *testing.T) {
 			userEmail: "john.doe@example.com",
 			subject:   "Test Notification",
 			message:   "This is a test notification message",
-			setupMock: func(m *MockEmailSender) {
-				response := &email.EmailResponse{
-					MessageID: "msg-789",
-					Provider:  "ses",
-					Status:    "sent",
-				}
-				m.On("SendEmail", mock.Anything, mock.MatchedBy(func(req email.EmailRequest) bool {
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, queuedEmail(func(req email.EmailRequest) bool {
 					return req.Subject == "Test Notification" &&
 						len(req.To) == 1 &&
 						req.To[0] == "john.doe@example.com" &&
-						req.TextBody == "This is a test notification message" &&
+						strings.HasPrefix(req.TextBody, "This is a test notification message\n") &&
 						req.HTMLBody != ""
-				})).Return(response, nil)
+				})).Return(&models.Job{}, nil)
 			},
 			expectedError: false,
 		},
 		{
-			name:      "error - email sender fails",
+			name:      "error - email not queued",
 			userEmail: "john.doe@example.com",
 			subject:   "Test Notification",
 			message:   "This is a test notification message",
-			setupMock: func(m *MockEmailSender) {
-				m.On("SendEmail", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 			},
 			expectedError: true,
 			errorType:     "ExternalServiceError",
@@ -222,14 +261,12 @@ func TestEmailService_SendNotificationEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockEmailSender := new(MockEmailSender)
+			mockEnqueuer := new(MockEnqueuer)
 			if tt.setupMock != nil {
-				tt.setupMock(mockEmailSender)
+				tt.setupMock(mockEnqueuer)
 			}
 
-			service := &EmailService{
-				emailSender: mockEmailSender,
-			}
+			service := newTestEmailService(mockEnqueuer, new(MockDeadLetters))
 
 			ctx := context.Background()
 			err := service.SendNotificationEmail(ctx, tt.userEmail, tt.subject, tt.message)
@@ -245,7 +282,216 @@ func TestEmailService_SendNotificationEmail(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			mockEmailSender.AssertExpectations(t)
+			mockEnqueuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailService_SendBulkNotificationEmail(t *testing.T) {
+	userEmails := []string{"john.doe@example.com", "jane.doe@example.com"}
+
+	tests := []struct {
+		name           string
+		setupMock      func(*MockEnqueuer)
+		expectedQueued int
+		expectedError  bool
+	}{
+		{
+			name: "success - queues one message per recipient in the bulk lane",
+			setupMock: func(m *MockEnqueuer) {
+				for _, userEmail := range userEmails {
+					m.On("Enqueue", mock.Anything, mock.MatchedBy(func(args jobs.Args) bool {
+						sendEmail, ok := args.(jobs.SendEmailArgs)
+						return ok && sendEmail.Priority == email.PriorityBulk &&
+							sendEmail.Request.To[0] == userEmail &&
+							sendEmail.Request.Subject == "Maintenance"
+					})).Return(&models.Job{}, nil).Once()
+				}
+			},
+			expectedQueued: 2,
+		},
+		{
+			name: "error - queue fails after a partial send",
+			setupMock: func(m *MockEnqueuer) {
+				m.On("Enqueue", mock.Anything, mock.Anything).Return(&models.Job{}, nil).Once()
+				m.On("Enqueue", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+			},
+			expectedQueued: 1,
+			expectedError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockEnqueuer := new(MockEnqueuer)
+			tt.setupMock(mockEnqueuer)
+
+			service := newTestEmailService(mockEnqueuer, new(MockDeadLetters))
+
+			queued, err := service.SendBulkNotificationEmail(context.Background(), userEmails, "Maintenance", "Scheduled downtime")
+
+			assert.Equal(t, tt.expectedQueued, queued)
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, errors.ErrorTypeExternal, appErr.Type)
+			} else {
+				require.NoError(t, err)
+			}
+
+			mockEnqueuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailService_SendEmailInSandbox(t *testing.T) {
+	mockEnqueuer := new(MockEnqueuer)
+	mockEnqueuer.On("Enqueue", mock.Anything, mock.MatchedBy(func(args jobs.Args) bool {
+		sendEmail, ok := args.(jobs.SendEmailArgs)
+		return ok && sendEmail.SandboxCompanyID == "company-1" && sendEmail.Priority == email.PriorityTransactional
+	})).Return(&models.Job{}, nil)
+	service := newTestEmailService(mockEnqueuer, new(MockDeadLetters))
+
+	err := service.SendWelcomeEmail(sandbox.WithCompany(context.Background(), "company-1"), "john.doe@example.com", "John Doe")
+
+	require.NoError(t, err)
+	mockEnqueuer.AssertExpectations(t)
+}
+
+func TestEmailService_ListFailedEmails(t *testing.T) {
+	lastError := "Failed to send email via SES"
+	failedAt := time.Now()
+	payload, err := json.Marshal(jobs.SendEmailArgs{Request: email.EmailRequest{To: []string{"jane@example.com"}, Subject: "Welcome"}})
+	require.NoError(t, err)
+	pageable := &dtos.PageableRequest{Page: 1, PageSize: 10}
+
+	deadLetters := new(MockDeadLetters)
+	deadLetters.On("ListDeadOfKind", jobs.KindSendEmail, pageable).Return(&dtos.DataResponse[models.Job]{
+		Data: []models.Job{{
+			BaseModel:  models.BaseModel{ID: "job-1"},
+			Kind:       jobs.KindSendEmail,
+			Payload:    payload,
+			Attempt:    10,
+			LastError:  &lastError,
+			FinishedAt: &failedAt,
+		}},
+		Pageable: &dtos.Pageable{Page: 1, PageSize: 10, Total: 1},
+	}, nil)
+	service := newTestEmailService(new(MockEnqueuer), deadLetters)
+
+	failed, err := service.ListFailedEmails(context.Background(), pageable)
+
+	require.NoError(t, err)
+	require.Len(t, failed.Data, 1)
+	assert.Equal(t, "job-1", failed.Data[0].ID)
+	assert.Equal(t, []string{"jane@example.com"}, failed.Data[0].To)
+	assert.Equal(t, "Welcome", failed.Data[0].Subject)
+	assert.Equal(t, 10, failed.Data[0].Attempts)
+	assert.Equal(t, &lastError, failed.Data[0].LastError)
+	assert.Equal(t, int64(1), failed.Pageable.Total)
+	deadLetters.AssertExpectations(t)
+}
+
+func TestEmailService_SuppressedAddresses(t *testing.T) {
+	suppressions := new(MockEmailSuppressionRepository)
+	suppressions.On("GetSuppressed", []string{"Bounced@example.com"}).Return([]string{"bounced@example.com"}, nil)
+	suppressions.On("GetSuppressed", []string{"Bounced@example.com", "jane@example.com"}).Return([]string{"bounced@example.com"}, nil)
+	enqueuer := new(MockEnqueuer)
+	enqueuer.On("Enqueue", mock.Anything, queuedEmail(func(req email.EmailRequest) bool {
+		return req.To[0] == "jane@example.com"
+	})).Return(&models.Job{}, nil).Once()
+	service := ProvideEmailService(enqueuer, new(MockDeadLetters), suppressions, new(MockSNSVerifier), newTestEmailTemplates())
+
+	err := service.SendWelcomeEmail(context.Background(), "Bounced@example.com", "John Doe")
+	require.Error(t, err)
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok, "Expected AppError")
+	assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+
+	queued, err := service.SendBulkNotificationEmail(context.Background(), []string{"Bounced@example.com", "jane@example.com"}, "Maintenance", "Scheduled downtime")
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+	enqueuer.AssertExpectations(t)
+}
+
+func TestEmailService_HandleSESNotification(t *testing.T) {
+	notification := func(message string) *dtos.SNSMessage {
+		return &dtos.SNSMessage{Type: constants.SNSMessageTypeNotification, MessageID: "sns-1", Message: message}
+	}
+
+	tests := []struct {
+		name                 string
+		message              *dtos.SNSMessage
+		verifyErr            error
+		expectedSuppressions []models.EmailSuppression
+		expectedConfirmation bool
+		expectedError        bool
+	}{
+		{
+			name: "hard bounce suppresses its recipients",
+			message: notification(`{"notificationType":"Bounce","mail":{"messageId":"ses-1"},"bounce":{"bounceType":"Permanent",
+				"bouncedRecipients":[{"emailAddress":"gone@example.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]}}`),
+			expectedSuppressions: []models.EmailSuppression{{
+				Email:     "gone@example.com",
+				Reason:    constants.EmailSuppressionReasonBounce,
+				Detail:    "smtp; 550 5.1.1 user unknown",
+				MessageID: "ses-1",
+			}},
+		},
+		{
+			name: "complaint suppresses its recipients",
+			message: notification(`{"notificationType":"Complaint","mail":{"messageId":"ses-2"},"complaint":{"complaintFeedbackType":"abuse",
+				"complainedRecipients":[{"emailAddress":"angry@example.com"}]}}`),
+			expectedSuppressions: []models.EmailSuppression{{
+				Email:     "angry@example.com",
+				Reason:    constants.EmailSuppressionReasonComplaint,
+				Detail:    "abuse",
+				MessageID: "ses-2",
+			}},
+		},
+		{
+			name: "transient bounce ignored",
+			message: notification(`{"notificationType":"Bounce","mail":{"messageId":"ses-3"},"bounce":{"bounceType":"Transient",
+				"bouncedRecipients":[{"emailAddress":"full@example.com"}]}}`),
+			expectedSuppressions: nil,
+		},
+		{
+			name:                 "subscription confirmed",
+			message:              &dtos.SNSMessage{Type: constants.SNSMessageTypeSubscriptionConfirmation, SubscribeURL: "https://sns.ap-southeast-1.amazonaws.com/"},
+			expectedConfirmation: true,
+		},
+		{
+			name:          "invalid signature",
+			message:       notification(`{"notificationType":"Complaint"}`),
+			verifyErr:     errors.UnauthorizedError("Invalid SNS message signature", nil),
+			expectedError: true,
+		},
+		{
+			name:          "invalid notification",
+			message:       notification(`not json`),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := new(MockSNSVerifier)
+			verifier.On("Verify", tt.message).Return(tt.verifyErr)
+			if tt.expectedConfirmation {
+				verifier.On("ConfirmSubscription", tt.message).Return(nil)
+			}
+			suppressions := new(MockEmailSuppressionRepository)
+			if !tt.expectedError && !tt.expectedConfirmation {
+				suppressions.On("Suppress", tt.expectedSuppressions).Return(nil)
+			}
+			service := ProvideEmailService(new(MockEnqueuer), new(MockDeadLetters), suppressions, verifier, newTestEmailTemplates())
+
+			err := service.HandleSESNotification(context.Background(), tt.message)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			verifier.AssertExpectations(t)
+			suppressions.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// ExportPrincipal is the user or the API key exporting a listing
+type ExportPrincipal struct {
+	// Type is constants.ExportPrincipalUser or constants.ExportPrincipalAPIKey
+	Type string
+	// ID is the subject of the token of a user or the ID of an API key
+	ID    string
+	Roles []string
+}
+
+// ExportRequest describes an export of a listing
+type ExportRequest struct {
+	Entity string
+	// Format is the media type of the export
+	Format string
+	// CompanyID is the company the listing is scoped to, if any
+	CompanyID string
+	Filters   map[string]string
+}
+
+// ExportService enforces the export policies declared in constants.ExportPolicies and
+// keeps an audit record of every export
+type ExportService interface {
+	// Begin checks the export against the policy of its entity and records it as started.
+	// The returned record holds the fields the principal may export. An export the policy
+	// does not allow is recorded as denied and fails with a forbidden error; an export
+	// that could not be recorded fails as well.
+	Begin(ctx context.Context, principal ExportPrincipal, req ExportRequest) (*models.ExportAudit, error)
+	// Complete records the outcome of a begun export: the rows exported and the error
+	// that interrupted it, if any
+	Complete(ctx context.Context, audit *models.ExportAudit, rows int64, exportErr error)
+	// List returns the audit records matching the filters, most recent first
+	List(ctx context.Context, pr *dtos.ExportAuditPageableRequest) (*dtos.DataResponse[models.ExportAudit], error)
+}
+
+// exportService implements ExportService
+type exportService struct {
+	exportRepo repositories.ExportRepository
+	policies   map[string]map[string][]string
+}
+
+// ProvideExportService creates a new export service
+func ProvideExportService(exportRepo repositories.ExportRepository) ExportService {
+	return &exportService{
+		exportRepo: exportRepo,
+		policies:   constants.ExportPolicies,
+	}
+}
+
+func (s *exportService) Begin(ctx context.Context, principal ExportPrincipal, req ExportRequest) (*models.ExportAudit, error) {
+	fields := s.allowedFields(req.Entity, principal.Roles)
+
+	audit := &models.ExportAudit{
+		BaseModel:     models.NewBaseModel(),
+		Entity:        req.Entity,
+		Format:        req.Format,
+		PrincipalType: principal.Type,
+		PrincipalID:   principal.ID,
+		Roles:         principal.Roles,
+		Fields:        fields,
+		Filters:       req.Filters,
+		Status:        constants.ExportStatusStarted,
+	}
+	if audit.Roles == nil {
+		audit.Roles = []string{}
+	}
+	if audit.Filters == nil {
+		audit.Filters = map[string]string{}
+	}
+	if req.CompanyID != "" {
+		audit.CompanyID = &req.CompanyID
+	}
+
+	if len(fields) == 0 {
+		now := time.Now().UTC()
+		audit.Status = constants.ExportStatusDenied
+		audit.CompletedAt = &now
+		if err := s.exportRepo.Create(audit); err != nil {
+			return nil, err
+		}
+
+		logger.Log.Warn("Export denied by the export policies",
+			zap.String("entity", req.Entity),
+			zap.String("principal_type", principal.Type),
+			zap.String("principal_id", principal.ID),
+			zap.Strings("roles", principal.Roles),
+		)
+
+		return nil, errors.ForbiddenError("Not allowed to export "+req.Entity, nil).
+			WithOperation("export").
+			WithResource(req.Entity).
+			WithContext("principal_id", principal.ID)
+	}
+
+	// Nothing is exported unless the export is on record
+	if err := s.exportRepo.Create(audit); err != nil {
+		return nil, err
+	}
+
+	return audit, nil
+}
+
+func (s *exportService) Complete(ctx context.Context, audit *models.ExportAudit, rows int64, exportErr error) {
+	now := time.Now().UTC()
+	audit.Rows = rows
+	audit.CompletedAt = &now
+	audit.Status = constants.ExportStatusCompleted
+	if exportErr != nil {
+		message := exportErr.Error()
+		audit.Status = constants.ExportStatusFailed
+		audit.Error = &message
+	}
+
+	// The export is over either way, the record stays started if it cannot be completed
+	if err := s.exportRepo.Complete(audit); err != nil {
+		logger.Log.Error("Failed to complete export audit record",
+			zap.String("export_id", audit.ID),
+			zap.Int64("rows", rows),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *exportService) List(ctx context.Context, pr *dtos.ExportAuditPageableRequest) (*dtos.DataResponse[models.ExportAudit], error) {
+	return s.exportRepo.Get(pr)
+}
+
+// allowedFields returns the fields of the entity the roles may export, sorted, or none
+// when the entity has no policy or none of the roles is in it
+func (s *exportService) allowedFields(entity string, roles []string) []string {
+	var fields []string
+	for role, roleFields := range s.policies[entity] {
+		if !slices.Contains(roles, role) {
+			continue
+		}
+		for _, field := range roleFields {
+			if !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
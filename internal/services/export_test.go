@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) Create(audit *models.ExportAudit) error {
+	args := m.Called(audit)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Complete(audit *models.ExportAudit) error {
+	args := m.Called(audit)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Get(pr *dtos.ExportAuditPageableRequest) (*dtos.DataResponse[models.ExportAudit], error) {
+	args := m.Called(pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.ExportAudit]), args.Error(1)
+}
+
+func TestExportService_Begin(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      ExportPrincipal
+		entity         string
+		createErr      error
+		expectedFields []string
+		expectedStatus string
+		expectedError  errors.ErrorType
+	}{
+		{
+			name:           "fields of all the roles",
+			principal:      ExportPrincipal{Type: constants.ExportPrincipalUser, ID: "user-1", Roles: []string{constants.RoleUserViewer, constants.RoleUserManager, constants.RoleUser}},
+			entity:         constants.ExportEntityUsers,
+			expectedFields: []string{"companies", "created_at", "email", "first_name", "id", "last_name"},
+			expectedStatus: constants.ExportStatusStarted,
+		},
+		{
+			name:           "api key",
+			principal:      ExportPrincipal{Type: constants.ExportPrincipalAPIKey, ID: "key-1", Roles: []string{constants.ExportRoleAPIKey}},
+			entity:         constants.ExportEntityCompanyMembers,
+			expectedFields: []string{"created_at", "first_name", "id", "last_name"},
+			expectedStatus: constants.ExportStatusStarted,
+		},
+		{
+			name:           "role allowed to read but not to export",
+			principal:      ExportPrincipal{Type: constants.ExportPrincipalUser, ID: "user-1", Roles: []string{constants.RoleCompanyViewer}},
+			entity:         constants.ExportEntityCompanies,
+			expectedStatus: constants.ExportStatusDenied,
+			expectedError:  errors.ErrorTypeForbidden,
+		},
+		{
+			name:           "entity without policy",
+			principal:      ExportPrincipal{Type: constants.ExportPrincipalUser, ID: "user-1", Roles: []string{constants.RoleAdmin}},
+			entity:         "invoices",
+			expectedStatus: constants.ExportStatusDenied,
+			expectedError:  errors.ErrorTypeForbidden,
+		},
+		{
+			name:           "export not recorded",
+			principal:      ExportPrincipal{Type: constants.ExportPrincipalUser, ID: "user-1", Roles: []string{constants.RoleAdmin}},
+			entity:         constants.ExportEntityCompanies,
+			createErr:      errors.DatabaseError("Failed to create export audit record", nil),
+			expectedStatus: constants.ExportStatusStarted,
+			expectedError:  errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exportRepo := new(MockExportRepository)
+			service := ProvideExportService(exportRepo)
+
+			var recorded *models.ExportAudit
+			exportRepo.On("Create", mock.AnythingOfType("*models.ExportAudit")).
+				Run(func(args mock.Arguments) { recorded = args.Get(0).(*models.ExportAudit) }).
+				Return(tt.createErr)
+
+			audit, err := service.Begin(context.Background(), tt.principal, ExportRequest{
+				Entity:  tt.entity,
+				Format:  "text/csv",
+				Filters: map[string]string{"q": "john"},
+			})
+
+			require.NotNil(t, recorded)
+			assert.Equal(t, tt.expectedStatus, recorded.Status)
+			assert.Equal(t, tt.principal.ID, recorded.PrincipalID)
+			assert.Equal(t, map[string]string{"q": "john"}, recorded.Filters)
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				assert.Nil(t, audit)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFields, audit.Fields)
+			assert.Nil(t, audit.CompletedAt)
+		})
+	}
+}
+
+func TestExportService_Complete(t *testing.T) {
+	tests := []struct {
+		name           string
+		exportErr      error
+		expectedStatus string
+	}{
+		{name: "completed", expectedStatus: constants.ExportStatusCompleted},
+		{name: "interrupted", exportErr: stderrors.New("broken pipe"), expectedStatus: constants.ExportStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exportRepo := new(MockExportRepository)
+			service := ProvideExportService(exportRepo)
+			audit := &models.ExportAudit{BaseModel: models.NewBaseModel(), Status: constants.ExportStatusStarted}
+
+			// A record that cannot be completed does not fail the export
+			exportRepo.On("Complete", audit).Return(errors.DatabaseError("Failed to complete export audit record", nil))
+
+			service.Complete(context.Background(), audit, 1200, tt.exportErr)
+
+			assert.Equal(t, tt.expectedStatus, audit.Status)
+			assert.Equal(t, int64(1200), audit.Rows)
+			assert.NotNil(t, audit.CompletedAt)
+			if tt.exportErr != nil {
+				require.NotNil(t, audit.Error)
+				assert.Equal(t, "broken pipe", *audit.Error)
+			} else {
+				assert.Nil(t, audit.Error)
+			}
+			exportRepo.AssertExpectations(t)
+		})
+	}
+}
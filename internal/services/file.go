@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FileService serves the files of the file registry through the API, for the buckets
+// that stay private without presigned URLs, and lists and deletes them for the admins
+type FileService interface {
+	// Get returns a file of the registry the roles can download, by the prefix of its key
+	// in constants.FileDownloadRoles. A file under constants.FileCompanyPrefixes must also
+	// belong to the company of organizationID, the organization of the token of the
+	// caller, and a file under constants.FileOwnerPrefixes to the user of subject, the
+	// subject of that token, unless the caller is an admin.
+	Get(ctx context.Context, id string, roles []string, organizationID string, subject string) (*models.File, error)
+	// Open streams the object of an available file, only byteRange of it when given; the
+	// caller closes the reader. A whole file with a checksum is verified as it is read: the
+	// reader holds back its last byte until the content is verified, and returns an
+	// errors.ChecksumMismatchError instead of it when the content does not match, so that
+	// a corrupted file is never read whole.
+	Open(ctx context.Context, file *models.File, byteRange *utils.ByteRange) (io.ReadCloser, error)
+	// List returns the files of the registry matching the filters, most recent first
+	List(ctx context.Context, pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error)
+	// Delete deletes a file of the registry and its object. An available file under one of
+	// constants.FileReferencedPrefixes is in use, a conflict.
+	Delete(ctx context.Context, id string) error
+}
+
+// fileService implements FileService
+type fileService struct {
+	files       files.Registry
+	companyRepo repositories.CompanyRepository
+	userRepo    repositories.UserRepository
+}
+
+// ProvideFileService creates a new file service
+func ProvideFileService(
+	files files.Registry,
+	companyRepo repositories.CompanyRepository,
+	userRepo repositories.UserRepository,
+) FileService {
+	return &fileService{
+		files:       files,
+		companyRepo: companyRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *fileService) Get(ctx context.Context, id string, roles []string, organizationID string, subject string) (*models.File, error) {
+	file, err := s.getByID(ctx, id, "download_file")
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, _, _ := strings.Cut(file.Key, "/")
+	isAdmin := slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(constants.AdminRoles, role) })
+	if slices.Contains(constants.FileOwnerPrefixes, prefix) {
+		if !isAdmin {
+			if err := s.checkOwner(file, subject); err != nil {
+				return nil, err
+			}
+		}
+		return file, nil
+	}
+
+	allowed, ok := constants.FileDownloadRoles[prefix]
+	if !ok {
+		allowed = constants.AdminRoles
+	}
+	if !slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(allowed, role) }) {
+		return nil, errors.ForbiddenError("Insufficient permissions to download file", nil).
+			WithOperation("download_file").
+			WithResource("file").
+			WithContext("file_id", id)
+	}
+
+	if slices.Contains(constants.FileCompanyPrefixes, prefix) && !isAdmin {
+		if err := s.checkCompany(file, organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+// checkCompany checks that file belongs to the company of organizationID. The owner of the
+// files registered before it was recorded is the company of their key, <prefix>/<company
+// ID>/...
+func (s *fileService) checkCompany(file *models.File, organizationID string) error {
+	forbidden := errors.ForbiddenError("File belongs to another company", nil).
+		WithOperation("download_file").
+		WithResource("file").
+		WithContext("file_id", file.ID)
+	if organizationID == "" {
+		return forbidden
+	}
+
+	company, err := s.companyRepo.GetByKeycloakID(organizationID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		return forbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	ownerID := ""
+	if file.OwnerID != nil {
+		ownerID = *file.OwnerID
+	} else if segments := strings.Split(file.Key, "/"); len(segments) > 2 {
+		ownerID = segments[1]
+	}
+	if ownerID != company.ID {
+		return forbidden
+	}
+	return nil
+}
+
+// checkOwner checks that file is owned by the user of subject, the Keycloak user of the
+// caller
+func (s *fileService) checkOwner(file *models.File, subject string) error {
+	forbidden := errors.ForbiddenError("File belongs to another user", nil).
+		WithOperation("download_file").
+		WithResource("file").
+		WithContext("file_id", file.ID)
+	if subject == "" || file.OwnerID == nil {
+		return forbidden
+	}
+
+	user, err := s.userRepo.GetByKeycloakID(subject)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		return forbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	if *file.OwnerID != user.ID {
+		return forbidden
+	}
+	return nil
+}
+
+func (s *fileService) Open(ctx context.Context, file *models.File, byteRange *utils.ByteRange) (io.ReadCloser, error) {
+	if byteRange != nil {
+		return s.files.Open(ctx, file, byteRange.Start, byteRange.Length())
+	}
+
+	reader, err := s.files.Open(ctx, file, 0, -1)
+	if err != nil || file.Checksum == "" {
+		return reader, err
+	}
+	return &checksumReader{ReadCloser: reader, file: file, hash: sha256.New(), buf: make([]byte, 32<<10)}, nil
+}
+
+// checksumReader reads the content of a file, verifying its checksum at its end. The last
+// byte read is held back until then, and is never returned when the content does not
+// match.
+type checksumReader struct {
+	io.ReadCloser
+	file *models.File
+	hash hash.Hash
+	buf  []byte
+	// pending is the content read from the object and not returned yet, and err the error
+	// returned once it is: io.EOF, the mismatch of the checksum or a failed read
+	pending []byte
+	err     error
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	// Read ahead until a byte can be returned while holding back another one
+	for r.err == nil && len(r.pending) < 2 {
+		n := copy(r.buf, r.pending)
+		m, err := r.ReadCloser.Read(r.buf[n:])
+		r.hash.Write(r.buf[n : n+m])
+		r.pending = r.buf[:n+m]
+		if err == io.EOF {
+			err = r.verify()
+		}
+		r.err = err
+	}
+
+	release := len(r.pending)
+	if r.err != io.EOF && release > 0 {
+		release--
+	}
+	n := copy(p, r.pending[:release])
+	r.pending = r.pending[n:]
+	if n == release && r.err != nil {
+		return n, r.err
+	}
+	return n, nil
+}
+
+// verify returns io.EOF when the content read matches the checksum of the file, and an
+// errors.ChecksumMismatchError when it does not
+func (r *checksumReader) verify() error {
+	if checksum := hex.EncodeToString(r.hash.Sum(nil)); checksum != r.file.Checksum {
+		return errors.ChecksumMismatchError("Stored file does not match its checksum", nil).
+			WithOperation("download_file").
+			WithResource("file").
+			WithContext("file_id", r.file.ID).
+			WithContext("checksum", r.file.Checksum).
+			WithContext("stored_checksum", checksum)
+	}
+	return io.EOF
+}
+
+func (s *fileService) List(ctx context.Context, pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error) {
+	return s.files.List(ctx, pr)
+}
+
+func (s *fileService) Delete(ctx context.Context, id string) error {
+	file, err := s.getByID(ctx, id, "delete_file")
+	if err != nil {
+		return err
+	}
+
+	prefix, _, _ := strings.Cut(file.Key, "/")
+	if file.Status == constants.FileStatusAvailable && slices.Contains(constants.FileReferencedPrefixes, prefix) {
+		return errors.ConflictError("File is in use", nil).
+			WithOperation("delete_file").
+			WithResource("file").
+			WithContext("file_id", id).
+			WithContext("key", file.Key)
+	}
+
+	if err := s.files.Delete(ctx, file.Key); err != nil {
+		return err
+	}
+
+	logger.Log.Info("File deleted",
+		zap.String("file_id", file.ID),
+		zap.String("key", file.Key),
+		zap.String("status", file.Status),
+	)
+	return nil
+}
+
+// getByID returns the file of id for operation
+func (s *fileService) getByID(ctx context.Context, id string, operation string) (*models.File, error) {
+	// A file ID that is not a UUID cannot exist, rather than failing the query
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.NotFoundError("File", err).
+			WithOperation(operation).
+			WithResource("file").
+			WithContext("file_id", id)
+	}
+
+	return s.files.GetByID(ctx, id)
+}
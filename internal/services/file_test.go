@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileService_Get(t *testing.T) {
+	const fileID = "0199e3a4-5b6c-7d8e-9f00-112233445566"
+
+	tests := []struct {
+		name           string
+		id             string
+		key            string
+		ownerID        string
+		roles          []string
+		organizationID string
+		subject        string
+		expectedError  errors.ErrorType
+	}{
+		{name: "avatar viewed by a user viewer", id: fileID, key: "avatars/user-1/a.png", roles: []string{constants.RoleUserViewer}},
+		{name: "logo viewed by a company viewer", id: fileID, key: "logos/company-1/a.png", roles: []string{constants.RoleCompanyViewer}},
+		{name: "invoice downloaded by a company manager of its company", id: fileID, key: "invoices/company-1/a.pdf", ownerID: "company-1", roles: []string{constants.RoleCompanyManager}, organizationID: "org-1"},
+		{name: "invoice registered without owner downloaded by a company manager of its company", id: fileID, key: "invoices/company-1/a.pdf", roles: []string{constants.RoleCompanyManager}, organizationID: "org-1"},
+		{name: "invoice downloaded by a company manager of another company", id: fileID, key: "invoices/company-2/a.pdf", ownerID: "company-2", roles: []string{constants.RoleCompanyManager}, organizationID: "org-1", expectedError: errors.ErrorTypeForbidden},
+		{name: "invoice registered without owner downloaded by a company manager of another company", id: fileID, key: "invoices/company-2/a.pdf", roles: []string{constants.RoleCompanyManager}, organizationID: "org-1", expectedError: errors.ErrorTypeForbidden},
+		{name: "invoice downloaded by a company manager without organization", id: fileID, key: "invoices/company-1/a.pdf", ownerID: "company-1", roles: []string{constants.RoleCompanyManager}, expectedError: errors.ErrorTypeForbidden},
+		{name: "invoice downloaded by a company manager of an unknown organization", id: fileID, key: "invoices/company-1/a.pdf", ownerID: "company-1", roles: []string{constants.RoleCompanyManager}, organizationID: "org-unknown", expectedError: errors.ErrorTypeForbidden},
+		{name: "invoice downloaded by an admin of another company", id: fileID, key: "invoices/company-2/a.pdf", ownerID: "company-2", roles: []string{constants.RoleAdmin}, organizationID: "org-1"},
+		{name: "invoice downloaded by a company viewer", id: fileID, key: "invoices/company-1/a.pdf", ownerID: "company-1", roles: []string{constants.RoleCompanyViewer}, organizationID: "org-1", expectedError: errors.ErrorTypeForbidden},
+		{name: "upload downloaded by its owner without role", id: fileID, key: "uploads/user-1/a.pdf", ownerID: "user-1", subject: "kc-1"},
+		{name: "upload downloaded by another user", id: fileID, key: "uploads/user-2/a.pdf", ownerID: "user-2", roles: []string{constants.RoleUserManager}, subject: "kc-1", expectedError: errors.ErrorTypeForbidden},
+		{name: "upload downloaded by an unknown user", id: fileID, key: "uploads/user-1/a.pdf", ownerID: "user-1", subject: "kc-unknown", expectedError: errors.ErrorTypeForbidden},
+		{name: "upload without owner downloaded by a user", id: fileID, key: "uploads/user-1/a.pdf", subject: "kc-1", expectedError: errors.ErrorTypeForbidden},
+		{name: "upload downloaded by an admin", id: fileID, key: "uploads/user-2/a.pdf", ownerID: "user-2", roles: []string{constants.RoleAdmin}, subject: "kc-1"},
+		{name: "other prefix downloaded by an admin", id: fileID, key: "tenants/acme/a.bin", roles: []string{constants.RoleUser, constants.RoleAdmin}},
+		{name: "other prefix downloaded by a user", id: fileID, key: "tenants/acme/a.bin", roles: []string{constants.RoleUserManager}, expectedError: errors.ErrorTypeForbidden},
+		{name: "ID that is not a UUID", id: "a.png", roles: []string{constants.RoleAdmin}, expectedError: errors.ErrorTypeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := new(MockFileRegistry)
+			companyRepo := new(MockCompanyRepository)
+			userRepo := new(MockUserRepository)
+			service := ProvideFileService(registry, companyRepo, userRepo)
+			builder := factory.File().WithKey(tt.key)
+			if tt.ownerID != "" {
+				builder = builder.WithOwner(tt.ownerID)
+			}
+			registry.On("GetByID", mock.Anything, fileID).Return(builder.Build(), nil).Maybe()
+			companyRepo.On("GetByKeycloakID", "org-1").Return(factory.Company().WithID("company-1").Build(), nil).Maybe()
+			companyRepo.On("GetByKeycloakID", "org-unknown").Return(nil, errors.NotFoundError("Company", nil)).Maybe()
+			userRepo.On("GetByKeycloakID", "kc-1").Return(factory.User().WithID("user-1").Build(), nil).Maybe()
+			userRepo.On("GetByKeycloakID", "kc-unknown").Return(nil, errors.NotFoundError("User", nil)).Maybe()
+
+			file, err := service.Get(context.Background(), tt.id, tt.roles, tt.organizationID, tt.subject)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				assert.Nil(t, file)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.key, file.Key)
+		})
+	}
+}
+
+func TestFileService_Open(t *testing.T) {
+	registry := new(MockFileRegistry)
+	service := ProvideFileService(registry, new(MockCompanyRepository), new(MockUserRepository))
+	file := factory.File().WithKey("invoices/company-1/a.pdf").Build()
+	registry.On("Open", mock.Anything, file, int64(0), int64(-1)).Return(io.NopCloser(strings.NewReader("whole")), nil)
+	registry.On("Open", mock.Anything, file, int64(500), int64(500)).Return(io.NopCloser(strings.NewReader("part")), nil)
+
+	reader, err := service.Open(context.Background(), file, nil)
+	require.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "whole", string(content))
+
+	reader, err = service.Open(context.Background(), file, &utils.ByteRange{Start: 500, End: 999})
+	require.NoError(t, err)
+	content, _ = io.ReadAll(reader)
+	assert.Equal(t, "part", string(content))
+}
+
+func TestFileService_Open_Checksum(t *testing.T) {
+	tests := []struct {
+		name            string
+		wrap            func(object io.Reader) io.Reader
+		content         string
+		expectedContent string
+		expectedError   bool
+	}{
+		{name: "content matching the checksum", content: "hello world", expectedContent: "hello world"},
+		{name: "content matching the checksum read byte by byte", wrap: iotest.OneByteReader, content: "hello world", expectedContent: "hello world"},
+		{name: "content matching the checksum read with its end", wrap: iotest.DataErrReader, content: "hello world", expectedContent: "hello world"},
+		// The last byte of a corrupted object is never returned, so that it is not served whole
+		{name: "corrupted object", content: "hello w0rld", expectedContent: "hello w0rl", expectedError: true},
+		{name: "corrupted object read byte by byte", wrap: iotest.OneByteReader, content: "hello w0rld", expectedContent: "hello w0rl", expectedError: true},
+		{name: "truncated object", content: "hello", expectedContent: "hell", expectedError: true},
+		{name: "empty object", content: "", expectedContent: "", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := new(MockFileRegistry)
+			service := ProvideFileService(registry, new(MockCompanyRepository), new(MockUserRepository))
+			// SHA-256 of "hello world"
+			file := factory.File().WithKey("invoices/company-1/a.pdf").WithChecksum("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9").Build()
+			var object io.Reader = strings.NewReader(tt.content)
+			if tt.wrap != nil {
+				object = tt.wrap(object)
+			}
+			registry.On("Open", mock.Anything, file, int64(0), int64(-1)).Return(io.NopCloser(object), nil)
+
+			reader, err := service.Open(context.Background(), file, nil)
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+
+			assert.Equal(t, tt.expectedContent, string(content))
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Equal(t, constants.ChecksumMismatch, errors.GetAppError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFileService_Open_ChecksumReadError(t *testing.T) {
+	registry := new(MockFileRegistry)
+	service := ProvideFileService(registry, new(MockCompanyRepository), new(MockUserRepository))
+	file := factory.File().WithKey("invoices/company-1/a.pdf").WithChecksum("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9").Build()
+	object := io.MultiReader(strings.NewReader("hello"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	registry.On("Open", mock.Anything, file, int64(0), int64(-1)).Return(io.NopCloser(object), nil)
+
+	reader, err := service.Open(context.Background(), file, nil)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "hell", string(content))
+}
+
+func TestFileService_Delete(t *testing.T) {
+	const fileID = "0199e3a4-5b6c-7d8e-9f00-112233445566"
+
+	tests := []struct {
+		name          string
+		id            string
+		key           string
+		status        string
+		expectedError errors.ErrorType
+	}{
+		{name: "failed avatar", id: fileID, key: "avatars/user-1/a.png", status: constants.FileStatusFailed},
+		{name: "quarantined invoice", id: fileID, key: "invoices/company-1/a.pdf", status: constants.FileStatusQuarantined},
+		{name: "available file of another prefix", id: fileID, key: "tenants/acme/a.bin", status: constants.FileStatusAvailable},
+		{name: "available logo in use", id: fileID, key: "logos/company-1/a.png", status: constants.FileStatusAvailable, expectedError: errors.ErrorTypeConflict},
+		{name: "ID that is not a UUID", id: "a.png", expectedError: errors.ErrorTypeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := new(MockFileRegistry)
+			service := ProvideFileService(registry, new(MockCompanyRepository), new(MockUserRepository))
+			registry.On("GetByID", mock.Anything, fileID).Return(factory.File().WithKey(tt.key).WithStatus(tt.status, "").Build(), nil).Maybe()
+			registry.On("Delete", mock.Anything, tt.key).Return(nil).Maybe()
+
+			err := service.Delete(context.Background(), tt.id)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				registry.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			registry.AssertCalled(t, "Delete", mock.Anything, tt.key)
+		})
+	}
+}
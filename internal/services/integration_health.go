@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/monitoring"
+	"golang-boilerplate/internal/repositories"
+)
+
+// IntegrationHealthService probes the dependencies of the health registry on a schedule
+// and reports their health over time
+type IntegrationHealthService interface {
+	// Probe checks every dependency, records the results and purges the probes older than
+	// INTEGRATION_PROBE_RETENTION_DAYS
+	Probe(ctx context.Context) (*monitoring.HealthReport, error)
+	// Get returns the last probe of each integration with its availability over
+	// constants.IntegrationHealthWindow
+	Get(ctx context.Context) (*dtos.IntegrationHealthResponse, error)
+}
+
+// integrationHealthService implements IntegrationHealthService
+type integrationHealthService struct {
+	cfg       *config.Config
+	registry  *monitoring.HealthRegistry
+	probeRepo repositories.IntegrationProbeRepository
+}
+
+// ProvideIntegrationHealthService creates a new integration health service
+func ProvideIntegrationHealthService(
+	cfg *config.Config,
+	registry *monitoring.HealthRegistry,
+	probeRepo repositories.IntegrationProbeRepository,
+) IntegrationHealthService {
+	return &integrationHealthService{
+		cfg:       cfg,
+		registry:  registry,
+		probeRepo: probeRepo,
+	}
+}
+
+func (s *integrationHealthService) Probe(ctx context.Context) (*monitoring.HealthReport, error) {
+	report := s.registry.Check(ctx)
+
+	probes := make([]models.IntegrationProbe, len(report.Dependencies))
+	for i, dependency := range report.Dependencies {
+		probes[i] = models.IntegrationProbe{
+			BaseModel:   models.NewBaseModel(),
+			Integration: dependency.Name,
+			Status:      dependency.Status,
+			Critical:    dependency.Critical,
+			LatencyMs:   dependency.LatencyMs,
+			Error:       dependency.Error,
+			CheckedAt:   report.CheckedAt,
+		}
+	}
+	if err := s.probeRepo.Record(probes); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.probeRepo.Purge(report.CheckedAt.AddDate(0, 0, -s.cfg.IntegrationRetentionDays)); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (s *integrationHealthService) Get(ctx context.Context) (*dtos.IntegrationHealthResponse, error) {
+	latest, err := s.probeRepo.GetLatest()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.probeRepo.GetStats(time.Now().UTC().Add(-constants.IntegrationHealthWindow))
+	if err != nil {
+		return nil, err
+	}
+	statsByIntegration := make(map[string]dtos.IntegrationProbeStats, len(stats))
+	for _, stat := range stats {
+		statsByIntegration[stat.Integration] = stat
+	}
+
+	integrations := make([]dtos.IntegrationHealth, len(latest))
+	dependencies := make([]monitoring.DependencyHealth, len(latest))
+	for i, probe := range latest {
+		stat := statsByIntegration[probe.Integration]
+		integrations[i] = dtos.IntegrationHealth{
+			Name:          probe.Integration,
+			Status:        probe.Status,
+			Critical:      probe.Critical,
+			LatencyMs:     probe.LatencyMs,
+			Error:         probe.Error,
+			CheckedAt:     probe.CheckedAt,
+			Uptime:        uptime(stat),
+			Probes:        stat.Probes,
+			Failures:      stat.Failures,
+			AvgLatencyMs:  math.Round(stat.AvgLatencyMs*10) / 10,
+			LastFailureAt: stat.LastFailureAt,
+		}
+		dependencies[i] = monitoring.DependencyHealth{Name: probe.Integration, Status: probe.Status, Critical: probe.Critical}
+	}
+
+	return &dtos.IntegrationHealthResponse{
+		Status:       monitoring.Rollup(dependencies),
+		WindowHours:  int(constants.IntegrationHealthWindow / time.Hour),
+		Integrations: integrations,
+	}, nil
+}
+
+// uptime is the percentage of the probes that succeeded, rounded to 2 decimals; an
+// integration without probes in the window is reported up
+func uptime(stat dtos.IntegrationProbeStats) float64 {
+	if stat.Probes == 0 {
+		return 100
+	}
+	return math.Round(float64(stat.Probes-stat.Failures)/float64(stat.Probes)*10000) / 100
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockIntegrationProbeRepository struct {
+	mock.Mock
+}
+
+func (m *MockIntegrationProbeRepository) Record(probes []models.IntegrationProbe) error {
+	args := m.Called(probes)
+	return args.Error(0)
+}
+
+func (m *MockIntegrationProbeRepository) GetLatest() ([]models.IntegrationProbe, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IntegrationProbe), args.Error(1)
+}
+
+func (m *MockIntegrationProbeRepository) GetStats(since time.Time) ([]dtos.IntegrationProbeStats, error) {
+	args := m.Called(since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.IntegrationProbeStats), args.Error(1)
+}
+
+func (m *MockIntegrationProbeRepository) Purge(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIntegrationHealthService_Probe(t *testing.T) {
+	registry := monitoring.NewHealthRegistry(time.Second,
+		monitoring.HealthChecker{Name: "auth", Critical: true, Check: func(context.Context) error { return nil }},
+		monitoring.HealthChecker{Name: "payment", Check: func(context.Context) error { return stderrors.New("connection refused") }},
+	)
+	probeRepo := new(MockIntegrationProbeRepository)
+	service := ProvideIntegrationHealthService(&config.Config{IntegrationRetentionDays: 7}, registry, probeRepo)
+
+	var recorded []models.IntegrationProbe
+	probeRepo.On("Record", mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(0).([]models.IntegrationProbe)
+	}).Return(nil)
+	probeRepo.On("Purge", mock.Anything).Return(int64(3), nil)
+
+	report, err := service.Probe(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, monitoring.HealthStatusDegraded, report.Status)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "auth", recorded[0].Integration)
+	assert.Equal(t, monitoring.HealthStatusHealthy, recorded[0].Status)
+	assert.True(t, recorded[0].Critical)
+	assert.Equal(t, "payment", recorded[1].Integration)
+	assert.Equal(t, monitoring.HealthStatusUnhealthy, recorded[1].Status)
+	assert.Equal(t, "connection refused", recorded[1].Error)
+	assert.Equal(t, report.CheckedAt, recorded[1].CheckedAt)
+	probeRepo.AssertCalled(t, "Purge", report.CheckedAt.AddDate(0, 0, -7))
+}
+
+func TestIntegrationHealthService_Get(t *testing.T) {
+	checkedAt := time.Now().UTC()
+	failedAt := checkedAt.Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		latest           []models.IntegrationProbe
+		stats            []dtos.IntegrationProbeStats
+		expectedStatus   string
+		expectedUptime   map[string]float64
+		expectedFailedAt map[string]*time.Time
+	}{
+		{
+			name: "degraded by a failing optional integration",
+			latest: []models.IntegrationProbe{
+				{Integration: "auth", Status: monitoring.HealthStatusHealthy, Critical: true, CheckedAt: checkedAt},
+				{Integration: "payment", Status: monitoring.HealthStatusUnhealthy, Error: "connection refused", CheckedAt: checkedAt},
+			},
+			stats: []dtos.IntegrationProbeStats{
+				{Integration: "auth", Probes: 1440, AvgLatencyMs: 42.26},
+				{Integration: "payment", Probes: 1440, Failures: 5, AvgLatencyMs: 120, LastFailureAt: &failedAt},
+			},
+			expectedStatus:   monitoring.HealthStatusDegraded,
+			expectedUptime:   map[string]float64{"auth": 100, "payment": 99.65},
+			expectedFailedAt: map[string]*time.Time{"auth": nil, "payment": &failedAt},
+		},
+		{
+			name: "unhealthy by a failing critical integration",
+			latest: []models.IntegrationProbe{
+				{Integration: "storage", Status: monitoring.HealthStatusUnhealthy, Critical: true, CheckedAt: checkedAt},
+			},
+			stats: []dtos.IntegrationProbeStats{
+				{Integration: "storage", Probes: 4, Failures: 4, LastFailureAt: &failedAt},
+			},
+			expectedStatus:   monitoring.HealthStatusUnhealthy,
+			expectedUptime:   map[string]float64{"storage": 0},
+			expectedFailedAt: map[string]*time.Time{"storage": &failedAt},
+		},
+		{
+			name: "no probes in the window",
+			latest: []models.IntegrationProbe{
+				{Integration: "email", Status: monitoring.HealthStatusHealthy, CheckedAt: checkedAt.AddDate(0, 0, -2)},
+			},
+			stats:            []dtos.IntegrationProbeStats{},
+			expectedStatus:   monitoring.HealthStatusHealthy,
+			expectedUptime:   map[string]float64{"email": 100},
+			expectedFailedAt: map[string]*time.Time{"email": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probeRepo := new(MockIntegrationProbeRepository)
+			service := ProvideIntegrationHealthService(&config.Config{}, monitoring.NewHealthRegistry(time.Second), probeRepo)
+			probeRepo.On("GetLatest").Return(tt.latest, nil)
+			probeRepo.On("GetStats", mock.Anything).Return(tt.stats, nil)
+
+			health, err := service.Get(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, health.Status)
+			assert.Equal(t, 24, health.WindowHours)
+			require.Len(t, health.Integrations, len(tt.latest))
+			for _, integration := range health.Integrations {
+				assert.Equal(t, tt.expectedUptime[integration.Name], integration.Uptime, integration.Name)
+				assert.Equal(t, tt.expectedFailedAt[integration.Name], integration.LastFailureAt, integration.Name)
+			}
+			probeRepo.AssertExpectations(t)
+		})
+	}
+}
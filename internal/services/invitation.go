@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/saga"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// InvitationService invites people by email to join the companies
+type InvitationService interface {
+	// Create stores the invitation and emails its signed token to the invited person. The
+	// invitation is deleted again when the email fails to be sent.
+	Create(ctx context.Context, companyID string, req *dtos.CreateInvitationRequest, invitedBy string) (*models.Invitation, error)
+	List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.Invitation], error)
+	Revoke(ctx context.Context, companyID string, invitationID string) (*models.Invitation, error)
+	// Accept makes the invited person a member of the company: it creates their Keycloak
+	// user when they have none, adds them to the organization of the company and creates
+	// the membership in the database. The steps done are undone when a later one fails.
+	Accept(ctx context.Context, req *dtos.AcceptInvitationRequest) (*dtos.AcceptInvitationResponse, error)
+}
+
+// invitationService implements InvitationService
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	companyRepo    repositories.CompanyRepository
+	userRepo       repositories.UserRepository
+	auth           auth.AuthService
+	tokens         auth.TokenProvider
+	emailService   EmailService
+	cache          cache.Cache
+	cfg            *config.Config
+}
+
+// ProvideInvitationService creates a new invitation service
+func ProvideInvitationService(
+	invitationRepo repositories.InvitationRepository,
+	companyRepo repositories.CompanyRepository,
+	userRepo repositories.UserRepository,
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	emailService EmailService,
+	cache cache.Cache,
+	cfg *config.Config,
+) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		companyRepo:    companyRepo,
+		userRepo:       userRepo,
+		auth:           authProvider,
+		tokens:         tokens,
+		emailService:   emailService,
+		cache:          cache,
+		cfg:            cfg,
+	}
+}
+
+func (s *invitationService) Create(ctx context.Context, companyID string, req *dtos.CreateInvitationRequest, invitedBy string) (*models.Invitation, error) {
+	if s.cfg.InvitationSigningKey == "" {
+		return nil, errors.InternalError("Invitations are not configured", nil).
+			WithOperation("create_invitation").
+			WithResource("invitation")
+	}
+
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("create_invitation").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	email := strings.ToLower(req.Email)
+	now := time.Now()
+	pending, err := s.invitationRepo.FindPending(company.ID, email, now)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, errors.ConflictError("The email already has a pending invitation to the company", nil).
+			WithOperation("create_invitation").
+			WithResource("invitation").
+			WithContext("company_id", company.ID).
+			WithContext("invitation_id", pending.ID)
+	}
+
+	invitation := &models.Invitation{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: company.ID,
+		Email:     email,
+		InvitedBy: invitedBy,
+		ExpiresAt: now.Add(s.cfg.InvitationTTL),
+	}
+	token, err := s.signToken(invitation, now)
+	if err != nil {
+		return nil, err
+	}
+	acceptURL, err := s.acceptURL(token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = saga.Run(ctx, constants.SagaCreateInvitation,
+		saga.Step{
+			Name: "create_invitation",
+			Action: func(context.Context) error {
+				return s.invitationRepo.Create(invitation)
+			},
+			Compensate: func(context.Context) error {
+				return s.invitationRepo.Delete(invitation)
+			},
+		},
+		saga.Step{
+			Name: "send_invitation_email",
+			Action: func(ctx context.Context) error {
+				return s.emailService.SendInvitationEmail(ctx, invitation.Email, company.Name, acceptURL, invitation.ExpiresAt)
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Invitation created",
+		zap.String("company_id", company.ID),
+		zap.String("invitation_id", invitation.ID),
+		zap.String("invited_by", invitedBy),
+	)
+
+	return invitation, nil
+}
+
+func (s *invitationService) List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.Invitation], error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_invitations").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return s.invitationRepo.GetByCompanyID(companyID, pageableRequest)
+}
+
+// Revoke stops an invitation from being accepted. An accepted invitation cannot be
+// revoked; the membership it created is removed with the user instead.
+func (s *invitationService) Revoke(ctx context.Context, companyID string, invitationID string) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(companyID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.ConflictError("The invitation was already accepted", nil).
+			WithOperation("revoke_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	}
+	if invitation.RevokedAt != nil {
+		return invitation, nil
+	}
+
+	revokedAt := time.Now()
+	invitation.RevokedAt = &revokedAt
+	if err := s.invitationRepo.Revoke(invitation); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Invitation revoked",
+		zap.String("company_id", companyID),
+		zap.String("invitation_id", invitation.ID),
+	)
+
+	return invitation, nil
+}
+
+func (s *invitationService) Accept(ctx context.Context, req *dtos.AcceptInvitationRequest) (*dtos.AcceptInvitationResponse, error) {
+	claims, err := s.parseToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationRepo.GetWithCompany(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, claims.Subject) {
+		return nil, invalidInvitationToken(nil)
+	}
+	if err := checkPending(invitation, time.Now()); err != nil {
+		return nil, err
+	}
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keycloakUser, err := s.auth.FindUserByEmail(ctx, adminToken, invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+	keycloakUserCreated := keycloakUser == nil
+	if keycloakUserCreated && req.Password == "" {
+		return nil, errors.ValidationError("A password is required to create the account", nil).
+			WithOperation("accept_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	}
+
+	user, err := s.userRepo.GetByEmail(invitation.Email)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+			return nil, err
+		}
+	}
+	userCreated := user == nil
+	if userCreated {
+		user = &models.User{
+			BaseModel: models.NewBaseModel(),
+			FirstName: req.FirstName,
+			LastName:  req.LastName,
+			Email:     invitation.Email,
+		}
+	}
+
+	organizationID := invitation.Company.KeycloakID
+	memberAdded := false
+	acceptedAt := time.Now()
+
+	// Keycloak goes first, as in the bootstrap: the membership of the database then
+	// references a Keycloak user that exists and belongs to the organization
+	err = saga.Run(ctx, constants.SagaAcceptInvitation,
+		saga.Step{
+			Name: "create_keycloak_user",
+			Action: func(ctx context.Context) error {
+				if !keycloakUserCreated {
+					return nil
+				}
+				keycloakUser, err = s.auth.CreateUser(ctx, adminToken, &dtos.CreateUserRequest{
+					UserRequest: dtos.UserRequest{
+						Email:     invitation.Email,
+						FirstName: req.FirstName,
+						LastName:  req.LastName,
+					},
+				})
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				if !keycloakUserCreated {
+					return nil
+				}
+				return s.auth.DeleteUser(ctx, adminToken, keycloakUser.ID)
+			},
+		},
+		saga.Step{
+			Name: "set_password",
+			Action: func(ctx context.Context) error {
+				if !keycloakUserCreated {
+					return nil
+				}
+				return s.auth.SetPassword(ctx, adminToken, keycloakUser.ID, req.Password, false)
+			},
+		},
+		saga.Step{
+			Name: "add_organization_member",
+			Action: func(ctx context.Context) error {
+				if organizationID == "" {
+					return nil
+				}
+				if !keycloakUserCreated {
+					organizations, err := s.auth.ListUserOrganizations(ctx, adminToken, keycloakUser.ID)
+					if err != nil {
+						return err
+					}
+					for _, organization := range organizations {
+						if organization.ID == organizationID {
+							return nil
+						}
+					}
+				}
+				if err := s.auth.AddUserToOrganization(ctx, adminToken, keycloakUser.ID, organizationID); err != nil {
+					return err
+				}
+				memberAdded = true
+				return nil
+			},
+			Compensate: func(ctx context.Context) error {
+				if !memberAdded {
+					return nil
+				}
+				return s.auth.RemoveUserFromOrganization(ctx, adminToken, keycloakUser.ID, organizationID)
+			},
+		},
+		saga.Step{
+			Name: "create_membership",
+			Action: func(context.Context) error {
+				user.KeycloakID = keycloakUser.ID
+				invitation.AcceptedAt = &acceptedAt
+				return s.invitationRepo.Accept(invitation, user, userCreated)
+			},
+		},
+	)
+	if err != nil {
+		invitation.AcceptedAt = nil
+		return nil, err
+	}
+
+	if !userCreated {
+		cache.Invalidate(ctx, s.cache, constants.UserCacheKeyPrefix+user.ID)
+	}
+
+	logger.Log.Info("Invitation accepted",
+		zap.String("company_id", invitation.CompanyID),
+		zap.String("invitation_id", invitation.ID),
+		zap.String("user_id", user.ID),
+		zap.Bool("keycloak_user_created", keycloakUserCreated),
+		zap.Bool("user_created", userCreated),
+	)
+
+	return &dtos.AcceptInvitationResponse{
+		InvitationID:        invitation.ID,
+		CompanyID:           invitation.CompanyID,
+		UserID:              user.ID,
+		KeycloakID:          keycloakUser.ID,
+		Email:               invitation.Email,
+		KeycloakUserCreated: keycloakUserCreated,
+		UserCreated:         userCreated,
+	}, nil
+}
+
+// signToken signs the token of the invitation, which carries its ID and email and
+// expires with it
+func (s *invitationService) signToken(invitation *models.Invitation, now time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        invitation.ID,
+		Subject:   invitation.Email,
+		Audience:  jwt.ClaimStrings{constants.InvitationTokenAudience},
+		ExpiresAt: jwt.NewNumericDate(invitation.ExpiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+	})
+	signed, err := token.SignedString([]byte(s.cfg.InvitationSigningKey))
+	if err != nil {
+		return "", errors.InternalError("Failed to sign invitation token", err).
+			WithOperation("create_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	}
+
+	return signed, nil
+}
+
+// parseToken verifies the signature, audience and expiry of an invitation token
+func (s *invitationService) parseToken(token string) (*jwt.RegisteredClaims, error) {
+	if s.cfg.InvitationSigningKey == "" {
+		return nil, errors.InternalError("Invitations are not configured", nil).
+			WithOperation("accept_invitation").
+			WithResource("invitation")
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(constants.InvitationTokenAudience),
+		jwt.WithExpirationRequired(),
+	)
+	claims := &jwt.RegisteredClaims{}
+	_, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(s.cfg.InvitationSigningKey), nil
+	})
+	if err != nil || claims.ID == "" {
+		return nil, invalidInvitationToken(err)
+	}
+
+	return claims, nil
+}
+
+// acceptURL returns the link of the invitation email, INVITATION_ACCEPT_URL or the accept
+// endpoint of the API, with the token
+func (s *invitationService) acceptURL(token string) (string, error) {
+	base := s.cfg.InvitationAcceptURL
+	if base == "" {
+		base = strings.TrimRight(s.cfg.AppBaseURL, "/") + constants.InvitationAcceptPath
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", errors.InternalError("Invalid invitation accept URL", err).
+			WithOperation("create_invitation").
+			WithResource("invitation")
+	}
+	query := u.Query()
+	query.Set(constants.InvitationTokenParam, token)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// checkPending fails when the invitation cannot be accepted at now
+func checkPending(invitation *models.Invitation, now time.Time) error {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return errors.ConflictError("The invitation was already accepted", nil).
+			WithOperation("accept_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	case invitation.RevokedAt != nil:
+		return errors.ConflictError("The invitation was revoked", nil).
+			WithOperation("accept_invitation").
+			WithResource("invitation").
+			WithContext("invitation_id", invitation.ID)
+	case !invitation.Pending(now):
+		return invalidInvitationToken(nil).
+			WithContext("invitation_id", invitation.ID)
+	}
+
+	return nil
+}
+
+func invalidInvitationToken(cause error) *errors.AppError {
+	return errors.UnauthorizedError("Invalid or expired invitation token", cause).
+		WithOperation("accept_invitation").
+		WithResource("invitation")
+}
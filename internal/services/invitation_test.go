@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvitationSigningKey = "0123456789abcdef0123456789abcdef"
+
+// MockInvitationRepository is a mock implementation of InvitationRepository
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Delete(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetByID(companyID string, id string) (*models.Invitation, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetWithCompany(id string) (*models.Invitation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) FindPending(companyID string, email string, now time.Time) (*models.Invitation, error) {
+	args := m.Called(companyID, email, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invitation], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.Invitation]), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Revoke(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Accept(invitation *models.Invitation, user *models.User, create bool) error {
+	args := m.Called(invitation, user, create)
+	return args.Error(0)
+}
+
+func newInvitationTestConfig() *config.Config {
+	return &config.Config{
+		AppBaseURL:           "https://api.example.com",
+		InvitationSigningKey: testInvitationSigningKey,
+		InvitationTTL:        time.Hour,
+	}
+}
+
+// signTestInvitationToken signs a token of the invitation as the service does
+func signTestInvitationToken(t *testing.T, invitation *models.Invitation, key string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        invitation.ID,
+		Subject:   invitation.Email,
+		Audience:  jwt.ClaimStrings{constants.InvitationTokenAudience},
+		ExpiresAt: jwt.NewNumericDate(invitation.ExpiresAt),
+	}).SignedString([]byte(key))
+	require.NoError(t, err)
+	return token
+}
+
+func TestInvitationService_Create(t *testing.T) {
+	company := factory.Company().WithID("company-1").WithName("Acme").Build()
+
+	tests := []struct {
+		name          string
+		setupMocks    func(invitationRepo *MockInvitationRepository, enqueuer *MockEnqueuer)
+		expectedError errors.ErrorType
+	}{
+		{
+			name: "invitation sent",
+			setupMocks: func(invitationRepo *MockInvitationRepository, enqueuer *MockEnqueuer) {
+				invitationRepo.On("FindPending", "company-1", "jane@example.com", mock.Anything).Return(nil, nil)
+				invitationRepo.On("Create", mock.AnythingOfType("*models.Invitation")).Return(nil)
+				enqueuer.On("Enqueue", mock.Anything, mock.MatchedBy(func(args jobs.Args) bool {
+					message := args.(jobs.SendEmailArgs).Request
+					return message.To[0] == "jane@example.com" &&
+						strings.Contains(message.TextBody, "https://api.example.com"+constants.InvitationAcceptPath+"?token=")
+				})).Return(&models.Job{}, nil)
+			},
+		},
+		{
+			name: "pending invitation",
+			setupMocks: func(invitationRepo *MockInvitationRepository, enqueuer *MockEnqueuer) {
+				invitationRepo.On("FindPending", "company-1", "jane@example.com", mock.Anything).
+					Return(&models.Invitation{BaseModel: models.BaseModel{ID: "invitation-0"}}, nil)
+			},
+			expectedError: errors.ErrorTypeConflict,
+		},
+		{
+			name: "email failure deletes the invitation",
+			setupMocks: func(invitationRepo *MockInvitationRepository, enqueuer *MockEnqueuer) {
+				invitationRepo.On("FindPending", "company-1", "jane@example.com", mock.Anything).Return(nil, nil)
+				invitationRepo.On("Create", mock.AnythingOfType("*models.Invitation")).Return(nil)
+				enqueuer.On("Enqueue", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+				invitationRepo.On("Delete", mock.AnythingOfType("*models.Invitation")).Return(nil)
+			},
+			expectedError: errors.ErrorTypeExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invitationRepo := new(MockInvitationRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			enqueuer := new(MockEnqueuer)
+			companyRepo.On("GetOneByID", "company-1").Return(company, nil)
+			tt.setupMocks(invitationRepo, enqueuer)
+
+			service := ProvideInvitationService(invitationRepo, companyRepo, new(MockUserRepository), new(MockAuthProvider),
+				newMockTokenProvider(), newTestEmailService(enqueuer, new(MockDeadLetters)), new(MockCache), newInvitationTestConfig())
+			invitation, err := service.Create(context.Background(), "company-1", &dtos.CreateInvitationRequest{Email: "Jane@Example.com"}, "admin-1")
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "jane@example.com", invitation.Email)
+				assert.Equal(t, "admin-1", invitation.InvitedBy)
+				assert.True(t, invitation.Pending(time.Now()))
+			}
+			invitationRepo.AssertExpectations(t)
+			enqueuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInvitationService_Create_NotConfigured(t *testing.T) {
+	cfg := newInvitationTestConfig()
+	cfg.InvitationSigningKey = ""
+
+	service := ProvideInvitationService(new(MockInvitationRepository), new(MockCompanyRepositoryForCompanyService), new(MockUserRepository),
+		new(MockAuthProvider), newMockTokenProvider(), newTestEmailService(new(MockEnqueuer), new(MockDeadLetters)), new(MockCache), cfg)
+	_, err := service.Create(context.Background(), "company-1", &dtos.CreateInvitationRequest{Email: "jane@example.com"}, "admin-1")
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeInternal, errors.GetAppError(err).Type)
+}
+
+func TestInvitationService_Accept(t *testing.T) {
+	newInvitation := func() *models.Invitation {
+		return &models.Invitation{
+			BaseModel: models.BaseModel{ID: "invitation-1"},
+			CompanyID: "company-1",
+			Company:   *factory.Company().WithID("company-1").WithKeycloakID("org-1").Build(),
+			Email:     "jane@example.com",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+	request := &dtos.AcceptInvitationRequest{FirstName: "Jane", LastName: "Doe", Password: "correct-horse-battery"}
+
+	tests := []struct {
+		name                        string
+		token                       func(t *testing.T, invitation *models.Invitation) string
+		password                    string
+		setupMocks                  func(authProvider *MockAuthProvider, userRepo *MockUserRepository, invitationRepo *MockInvitationRepository, c *MockCache)
+		expectedError               errors.ErrorType
+		expectedKeycloakUserCreated bool
+		expectedUserCreated         bool
+	}{
+		{
+			name:     "new user",
+			password: request.Password,
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository, invitationRepo *MockInvitationRepository, c *MockCache) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", "jane@example.com").Return(nil, nil)
+				userRepo.On("GetByEmail", "jane@example.com").Return(nil, errors.NotFoundError("User", nil))
+				authProvider.On("CreateUser", mock.Anything, "admin-token", mock.MatchedBy(func(req *dtos.CreateUserRequest) bool {
+					return req.Email == "jane@example.com" && req.FirstName == "Jane"
+				})).Return(&auth.User{ID: "kc-1"}, nil)
+				authProvider.On("SetPassword", mock.Anything, "admin-token", "kc-1", request.Password, false).Return(nil)
+				authProvider.On("AddUserToOrganization", mock.Anything, "admin-token", "kc-1", "org-1").Return(nil)
+				invitationRepo.On("Accept", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.KeycloakID == "kc-1" && user.Email == "jane@example.com"
+				}), true).Return(nil)
+			},
+			expectedKeycloakUserCreated: true,
+			expectedUserCreated:         true,
+		},
+		{
+			name: "existing member of the organization",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository, invitationRepo *MockInvitationRepository, c *MockCache) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", "jane@example.com").Return(&auth.User{ID: "kc-1"}, nil)
+				userRepo.On("GetByEmail", "jane@example.com").
+					Return(factory.User().WithID("user-1").WithEmail("jane@example.com").WithKeycloakID("kc-1").Build(), nil)
+				authProvider.On("ListUserOrganizations", mock.Anything, "admin-token", "kc-1").
+					Return([]auth.TenantOrganization{{ID: "org-1"}}, nil)
+				invitationRepo.On("Accept", mock.Anything, mock.Anything, false).Return(nil)
+				c.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+"user-1").Return(nil)
+			},
+		},
+		{
+			name:     "membership failure compensates the Keycloak steps",
+			password: request.Password,
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository, invitationRepo *MockInvitationRepository, c *MockCache) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", "jane@example.com").Return(nil, nil)
+				userRepo.On("GetByEmail", "jane@example.com").Return(nil, errors.NotFoundError("User", nil))
+				authProvider.On("CreateUser", mock.Anything, "admin-token", mock.Anything).Return(&auth.User{ID: "kc-1"}, nil)
+				authProvider.On("SetPassword", mock.Anything, "admin-token", "kc-1", request.Password, false).Return(nil)
+				authProvider.On("AddUserToOrganization", mock.Anything, "admin-token", "kc-1", "org-1").Return(nil)
+				invitationRepo.On("Accept", mock.Anything, mock.Anything, true).
+					Return(errors.ConflictError("The invitation was already accepted or revoked", nil))
+				authProvider.On("RemoveUserFromOrganization", mock.Anything, "admin-token", "kc-1", "org-1").Return(nil)
+				authProvider.On("DeleteUser", mock.Anything, "admin-token", "kc-1").Return(nil)
+			},
+			expectedError: errors.ErrorTypeConflict,
+		},
+		{
+			name: "password missing for a new account",
+			setupMocks: func(authProvider *MockAuthProvider, userRepo *MockUserRepository, invitationRepo *MockInvitationRepository, c *MockCache) {
+				authProvider.On("FindUserByEmail", mock.Anything, "admin-token", "jane@example.com").Return(nil, nil)
+			},
+			expectedError: errors.ErrorTypeValidation,
+		},
+		{
+			name: "token signed with another key",
+			token: func(t *testing.T, invitation *models.Invitation) string {
+				return signTestInvitationToken(t, invitation, "another-key-another-key-another-key")
+			},
+			expectedError: errors.ErrorTypeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invitation := newInvitation()
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+			invitationRepo := new(MockInvitationRepository)
+			c := new(MockCache)
+			invitationRepo.On("GetWithCompany", "invitation-1").Return(invitation, nil).Maybe()
+			if tt.setupMocks != nil {
+				tt.setupMocks(authProvider, userRepo, invitationRepo, c)
+			}
+
+			token := signTestInvitationToken(t, invitation, testInvitationSigningKey)
+			if tt.token != nil {
+				token = tt.token(t, invitation)
+			}
+			req := *request
+			req.Token = token
+			req.Password = tt.password
+
+			service := ProvideInvitationService(invitationRepo, new(MockCompanyRepositoryForCompanyService), userRepo, authProvider,
+				newMockTokenProvider(), newTestEmailService(new(MockEnqueuer), new(MockDeadLetters)), c, newInvitationTestConfig())
+			accepted, err := service.Accept(context.Background(), &req)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				assert.Nil(t, invitation.AcceptedAt)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "invitation-1", accepted.InvitationID)
+				assert.Equal(t, "kc-1", accepted.KeycloakID)
+				assert.Equal(t, tt.expectedKeycloakUserCreated, accepted.KeycloakUserCreated)
+				assert.Equal(t, tt.expectedUserCreated, accepted.UserCreated)
+			}
+			authProvider.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+			invitationRepo.AssertExpectations(t)
+			c.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInvitationService_Revoke(t *testing.T) {
+	acceptedAt := time.Now()
+
+	tests := []struct {
+		name          string
+		invitation    *models.Invitation
+		expectRevoke  bool
+		expectedError errors.ErrorType
+	}{
+		{
+			name:         "pending invitation",
+			invitation:   &models.Invitation{BaseModel: models.BaseModel{ID: "invitation-1"}},
+			expectRevoke: true,
+		},
+		{
+			name:          "accepted invitation",
+			invitation:    &models.Invitation{BaseModel: models.BaseModel{ID: "invitation-1"}, AcceptedAt: &acceptedAt},
+			expectedError: errors.ErrorTypeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invitationRepo := new(MockInvitationRepository)
+			invitationRepo.On("GetByID", "company-1", "invitation-1").Return(tt.invitation, nil)
+			if tt.expectRevoke {
+				invitationRepo.On("Revoke", tt.invitation).Return(nil)
+			}
+
+			service := ProvideInvitationService(invitationRepo, new(MockCompanyRepositoryForCompanyService), new(MockUserRepository),
+				new(MockAuthProvider), newMockTokenProvider(), newTestEmailService(new(MockEnqueuer), new(MockDeadLetters)), new(MockCache), newInvitationTestConfig())
+			invitation, err := service.Revoke(context.Background(), "company-1", "invitation-1")
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, invitation.RevokedAt)
+			}
+			invitationRepo.AssertExpectations(t)
+		})
+	}
+}
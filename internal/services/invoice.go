@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/invoicing"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/retry"
+	"golang-boilerplate/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v82"
+	"go.uber.org/zap"
+)
+
+// InvoiceService syncs the invoices of the companies from the events of the payment
+// provider and serves them as branded PDF documents stored in the file registry
+type InvoiceService interface {
+	// List returns the invoices of a company, most recently issued first. The company must
+	// be the one of organizationID, the organization of the token of the caller, unless
+	// the caller is an admin.
+	List(ctx context.Context, companyID string, pr *dtos.PageableRequest, roles []string, organizationID string) (*dtos.DataResponse[models.Invoice], error)
+	// GetInvoicePDF returns a presigned URL of the PDF document of an invoice, rendered
+	// and stored the first time it is requested after the invoice changed. The company
+	// must be the one of organizationID unless the caller is an admin, as for List.
+	GetInvoicePDF(ctx context.Context, companyID string, invoiceID string, roles []string, organizationID string) (*dtos.InvoicePDFResponse, error)
+	// HandleInvoiceEvent syncs the invoice of a payment event consumed from the broker
+	HandleInvoiceEvent(ctx context.Context, msg messaging.Message) error
+}
+
+// invoiceService implements InvoiceService
+type invoiceService struct {
+	invoiceRepo repositories.InvoiceRepository
+	billingRepo repositories.BillingRepository
+	companyRepo repositories.CompanyRepository
+	files       files.Registry
+	renderer    *invoicing.Renderer
+}
+
+// ProvideInvoiceService creates a new invoice service
+func ProvideInvoiceService(
+	invoiceRepo repositories.InvoiceRepository,
+	billingRepo repositories.BillingRepository,
+	companyRepo repositories.CompanyRepository,
+	files files.Registry,
+	renderer *invoicing.Renderer,
+) InvoiceService {
+	return &invoiceService{
+		invoiceRepo: invoiceRepo,
+		billingRepo: billingRepo,
+		companyRepo: companyRepo,
+		files:       files,
+		renderer:    renderer,
+	}
+}
+
+func (s *invoiceService) List(ctx context.Context, companyID string, pr *dtos.PageableRequest, roles []string, organizationID string) (*dtos.DataResponse[models.Invoice], error) {
+	if err := s.checkCompany(companyID, roles, organizationID, "get_invoices"); err != nil {
+		return nil, err
+	}
+	if _, err := s.getCompany(companyID, "get_invoices"); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.GetByCompany(companyID, pr)
+}
+
+func (s *invoiceService) GetInvoicePDF(ctx context.Context, companyID string, invoiceID string, roles []string, organizationID string) (*dtos.InvoicePDFResponse, error) {
+	if err := s.checkCompany(companyID, roles, organizationID, "get_invoice_pdf"); err != nil {
+		return nil, err
+	}
+
+	// An invoice ID that is not a UUID cannot exist, rather than failing the query
+	if _, err := uuid.Parse(invoiceID); err != nil {
+		return nil, errors.NotFoundError("Invoice", err).
+			WithOperation("get_invoice_pdf").
+			WithResource("invoice").
+			WithContext("company_id", companyID).
+			WithContext("invoice_id", invoiceID)
+	}
+
+	invoice, err := s.invoiceRepo.Get(companyID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pdf *models.File
+	if invoice.PDFKey != "" {
+		pdf, err = s.files.Get(ctx, invoice.PDFKey)
+	} else {
+		pdf, err = s.renderPDF(ctx, invoice)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.files.PresignedURL(ctx, pdf, constants.InvoicePDFURLDuration)
+	if err != nil {
+		logger.Log.Error("Failed to presign invoice PDF URL",
+			zap.String("invoice_id", invoice.ID),
+			zap.String("pdf_key", pdf.Key),
+			zap.String("status", pdf.Status),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return &dtos.InvoicePDFResponse{
+		InvoiceID: invoice.ID,
+		PDFKey:    pdf.Key,
+		PDFURL:    url,
+		ExpiresAt: time.Now().Add(constants.InvoicePDFURLDuration),
+	}, nil
+}
+
+// renderPDF renders the PDF document of the invoice and stores it. When another request
+// stored one first, or an event changed the invoice meanwhile, the document is deleted
+// and the one of the other request returned, or a conflict.
+func (s *invoiceService) renderPDF(ctx context.Context, invoice *models.Invoice) (*models.File, error) {
+	company, err := s.getCompany(invoice.CompanyID, "render_invoice_pdf")
+	if err != nil {
+		return nil, err
+	}
+
+	content := s.renderer.Render(newInvoiceDocument(invoice, company))
+	fileHeader, err := utils.NewFileHeader(invoiceFilename(invoice), "application/pdf", content)
+	if err != nil {
+		return nil, errors.InternalError("Failed to prepare invoice PDF", err).
+			WithOperation("render_invoice_pdf").
+			WithResource("invoice").
+			WithContext("invoice_id", invoice.ID)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s.pdf", constants.InvoiceKeyPrefix, invoice.CompanyID, uuid.Must(uuid.NewV7()).String())
+	pdf, err := s.files.Upload(ctx, fileHeader, key, "application/pdf", invoice.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := s.invoiceRepo.SetPDFKey(invoice.ID, key, invoice.LastEventAt)
+	if err == nil && set {
+		invoice.PDFKey = key
+		logger.Log.Info("Invoice PDF rendered",
+			zap.String("invoice_id", invoice.ID),
+			zap.String("pdf_key", key),
+			zap.Int64("size", pdf.Size),
+		)
+		return pdf, nil
+	}
+	s.deletePDF(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.invoiceRepo.Get(invoice.CompanyID, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.PDFKey == "" || !current.LastEventAt.Equal(invoice.LastEventAt) {
+		return nil, errors.ConflictError("Invoice changed concurrently", nil).
+			WithOperation("render_invoice_pdf").
+			WithResource("invoice").
+			WithContext("invoice_id", invoice.ID)
+	}
+	return s.files.Get(ctx, current.PDFKey)
+}
+
+// HandleInvoiceEvent applies the events older than the last one applied to the invoice
+// as no-ops, since the provider does not deliver them in order. The drafts and the
+// invoices of no company of the server are ignored. The PDF document of a changed
+// invoice is deleted, to be rendered again when requested.
+func (s *invoiceService) HandleInvoiceEvent(ctx context.Context, msg messaging.Message) error {
+	var event dtos.PaymentEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return retry.Permanent(errors.ValidationError("Invalid payment event", err).
+			WithOperation("sync_invoice").
+			WithResource("invoice").
+			WithContext("message_id", msg.ID))
+	}
+	var providerInvoice stripe.Invoice
+	if err := json.Unmarshal(event.Data, &providerInvoice); err != nil || providerInvoice.ID == "" {
+		return retry.Permanent(errors.ValidationError("Invalid invoice event", err).
+			WithProvider(event.Provider).
+			WithOperation("sync_invoice").
+			WithResource("invoice").
+			WithContext("event_id", event.ID))
+	}
+	if providerInvoice.Status == stripe.InvoiceStatusDraft {
+		logger.Log.Debug("Ignoring draft invoice",
+			zap.String("provider_invoice_id", providerInvoice.ID),
+			zap.String("event_type", event.Type),
+		)
+		return nil
+	}
+
+	invoice, err := s.invoiceRepo.GetByProviderID(providerInvoice.ID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		invoice, err = s.invoiceOfEvent(&providerInvoice)
+	}
+	if err != nil || invoice == nil {
+		return err
+	}
+	if event.CreatedAt.Before(invoice.LastEventAt) {
+		logger.Log.Info("Ignoring outdated invoice event",
+			zap.String("company_id", invoice.CompanyID),
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+		)
+		return nil
+	}
+
+	staleKey := invoice.PDFKey
+	applyProviderInvoice(invoice, &providerInvoice)
+	invoice.PDFKey = ""
+	invoice.LastEventAt = event.CreatedAt
+	if err := s.invoiceRepo.Save(invoice); err != nil {
+		return err
+	}
+	if staleKey != "" {
+		s.deletePDF(ctx, staleKey)
+	}
+
+	logger.Log.Info("Invoice synced",
+		zap.String("company_id", invoice.CompanyID),
+		zap.String("invoice_id", invoice.ID),
+		zap.String("status", invoice.Status),
+		zap.String("event_id", event.ID),
+		zap.String("event_type", event.Type),
+	)
+	return nil
+}
+
+// invoiceOfEvent returns a new invoice for the company of a provider invoice seen for the
+// first time, from the metadata of its subscription or of the invoice, or from the
+// subscription the company has with the provider. It is nil when the invoice belongs to
+// no company.
+func (s *invoiceService) invoiceOfEvent(providerInvoice *stripe.Invoice) (*models.Invoice, error) {
+	companyID := providerInvoice.Metadata[constants.BillingMetadataCompanyID]
+	var providerSubscriptionID string
+	if providerInvoice.Parent != nil && providerInvoice.Parent.SubscriptionDetails != nil {
+		details := providerInvoice.Parent.SubscriptionDetails
+		if id := details.Metadata[constants.BillingMetadataCompanyID]; id != "" {
+			companyID = id
+		}
+		if details.Subscription != nil {
+			providerSubscriptionID = details.Subscription.ID
+		}
+	}
+
+	if companyID == "" && providerSubscriptionID != "" {
+		subscription, err := s.billingRepo.GetSubscriptionByProviderID(providerSubscriptionID)
+		if appErr := errors.GetAppError(err); err != nil && (appErr == nil || appErr.Type != errors.ErrorTypeNotFound) {
+			return nil, err
+		}
+		if subscription != nil {
+			companyID = subscription.CompanyID
+		}
+	}
+	if companyID == "" {
+		logger.Log.Info("Ignoring invoice without a company",
+			zap.String("provider_invoice_id", providerInvoice.ID),
+		)
+		return nil, nil
+	}
+
+	return &models.Invoice{BaseModel: models.NewBaseModel(), CompanyID: companyID}, nil
+}
+
+// deletePDF deletes a PDF document no invoice links to. Its object is only left behind
+// when it fails, so the failure is logged.
+func (s *invoiceService) deletePDF(ctx context.Context, key string) {
+	if err := s.files.Delete(ctx, key); err != nil {
+		logger.Log.Warn("Failed to delete invoice PDF",
+			zap.String("pdf_key", key),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkCompany checks that the caller belongs to the company of companyID, by the
+// organization of its token, unless it is an admin
+func (s *invoiceService) checkCompany(companyID string, roles []string, organizationID string, operation string) error {
+	if slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(constants.AdminRoles, role) }) {
+		return nil
+	}
+
+	forbidden := errors.ForbiddenError("Invoices belong to another company", nil).
+		WithOperation(operation).
+		WithResource("invoice").
+		WithContext("company_id", companyID)
+	if organizationID == "" {
+		return forbidden
+	}
+
+	company, err := s.companyRepo.GetByKeycloakID(organizationID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+		return forbidden
+	}
+	if err != nil {
+		return err
+	}
+	if company.ID != companyID {
+		return forbidden
+	}
+	return nil
+}
+
+func (s *invoiceService) getCompany(companyID string, operation string) (*models.Company, error) {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	return company, nil
+}
+
+// applyProviderInvoice copies the state of the provider invoice to the invoice of the
+// company. The period is the one of the lines, which is the one billed, rather than the
+// one of the invoice, which Stripe sets to the period before a subscription invoice.
+func applyProviderInvoice(invoice *models.Invoice, providerInvoice *stripe.Invoice) {
+	invoice.Provider = constants.PaymentProviderStripe
+	invoice.ProviderInvoiceID = providerInvoice.ID
+	invoice.Number = providerInvoice.Number
+	invoice.Status = string(providerInvoice.Status)
+	invoice.Currency = string(providerInvoice.Currency)
+	invoice.Subtotal = providerInvoice.Subtotal
+	invoice.Total = providerInvoice.Total
+	invoice.AmountPaid = providerInvoice.AmountPaid
+	invoice.AmountDue = providerInvoice.AmountDue
+
+	invoice.Tax = 0
+	for _, tax := range providerInvoice.TotalTaxes {
+		if tax != nil {
+			invoice.Tax += tax.Amount
+		}
+	}
+
+	invoice.Lines = []models.InvoiceLine{}
+	var periodStart, periodEnd int64
+	if providerInvoice.Lines != nil {
+		for _, item := range providerInvoice.Lines.Data {
+			if item == nil {
+				continue
+			}
+			line := models.InvoiceLine{
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitAmount:  item.Amount,
+				Amount:      item.Amount,
+			}
+			if item.Quantity > 0 {
+				line.UnitAmount = item.Amount / item.Quantity
+			}
+			invoice.Lines = append(invoice.Lines, line)
+
+			if item.Period != nil {
+				if periodStart == 0 || item.Period.Start < periodStart {
+					periodStart = item.Period.Start
+				}
+				periodEnd = max(periodEnd, item.Period.End)
+			}
+		}
+	}
+	if periodStart == 0 {
+		periodStart, periodEnd = providerInvoice.PeriodStart, providerInvoice.PeriodEnd
+	}
+	invoice.PeriodStart = unixTime(periodStart)
+	invoice.PeriodEnd = unixTime(periodEnd)
+	invoice.DueAt = unixTime(providerInvoice.DueDate)
+
+	issuedAt := providerInvoice.Created
+	if providerInvoice.StatusTransitions != nil && providerInvoice.StatusTransitions.FinalizedAt > 0 {
+		issuedAt = providerInvoice.StatusTransitions.FinalizedAt
+	}
+	invoice.IssuedAt = time.Unix(issuedAt, 0).UTC()
+}
+
+// newInvoiceDocument returns the data of the PDF document of the invoice of company
+func newInvoiceDocument(invoice *models.Invoice, company *models.Company) invoicing.Invoice {
+	lines := make([]invoicing.Line, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = invoicing.Line(line)
+	}
+
+	return invoicing.Invoice{
+		Number:      invoice.Number,
+		Status:      invoice.Status,
+		IssuedAt:    invoice.IssuedAt,
+		DueAt:       invoice.DueAt,
+		PeriodStart: invoice.PeriodStart,
+		PeriodEnd:   invoice.PeriodEnd,
+		BillTo:      company.Name,
+		Currency:    invoice.Currency,
+		Lines:       lines,
+		Subtotal:    invoice.Subtotal,
+		Tax:         invoice.Tax,
+		Total:       invoice.Total,
+		AmountPaid:  invoice.AmountPaid,
+		AmountDue:   invoice.AmountDue,
+	}
+}
+
+// invoiceFilename returns the name of the PDF document of the invoice
+func invoiceFilename(invoice *models.Invoice) string {
+	if invoice.Number == "" {
+		return "invoice.pdf"
+	}
+	return "invoice-" + invoice.Number + ".pdf"
+}
+
+// unixTime returns the time of a Unix timestamp of the provider, nil when it is not set
+func unixTime(timestamp int64) *time.Time {
+	if timestamp <= 0 {
+		return nil
+	}
+	t := time.Unix(timestamp, 0).UTC()
+	return &t
+}
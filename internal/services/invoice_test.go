@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/invoicing"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockInvoiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceRepository) Get(companyID string, id string) (*models.Invoice, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) GetByProviderID(providerInvoiceID string) (*models.Invoice, error) {
+	args := m.Called(providerInvoiceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) GetByCompany(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.Invoice], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.Invoice]), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) Save(invoice *models.Invoice) error {
+	args := m.Called(invoice)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceRepository) SetPDFKey(id string, key string, lastEventAt time.Time) (bool, error) {
+	args := m.Called(id, key, lastEventAt)
+	return args.Bool(0), args.Error(1)
+}
+
+const testInvoiceID = "0190b7f5-8c2e-7a3b-9d41-6f0e2c8a1b77"
+
+func newTestInvoiceService(invoiceRepo *MockInvoiceRepository, billingRepo *MockBillingRepository, companyRepo *MockCompanyRepository, registry *MockFileRegistry) *invoiceService {
+	return &invoiceService{
+		invoiceRepo: invoiceRepo,
+		billingRepo: billingRepo,
+		companyRepo: companyRepo,
+		files:       registry,
+		renderer:    invoicing.NewRenderer(invoicing.Template{BrandName: "Acme", AccentColor: "#1F2937"}),
+	}
+}
+
+func paidInvoice(pdfKey string) *models.Invoice {
+	return &models.Invoice{
+		BaseModel:         models.BaseModel{ID: testInvoiceID},
+		CompanyID:         "company-1",
+		Provider:          constants.PaymentProviderStripe,
+		ProviderInvoiceID: "in_1",
+		Number:            "ACME-0001",
+		Status:            constants.InvoiceStatusPaid,
+		Currency:          "usd",
+		Lines:             []models.InvoiceLine{{Description: "Pro", Quantity: 1, UnitAmount: 4900, Amount: 4900}},
+		Subtotal:          4900,
+		Total:             4900,
+		AmountPaid:        4900,
+		IssuedAt:          time.Unix(1760000000, 0).UTC(),
+		PDFKey:            pdfKey,
+		LastEventAt:       time.Unix(1760000000, 0).UTC(),
+	}
+}
+
+func TestInvoiceService_HandleInvoiceEvent(t *testing.T) {
+	lastEventAt := time.Unix(1760000000, 0).UTC()
+	message := func(t *testing.T, createdAt time.Time, providerInvoice string) messaging.Message {
+		body, err := json.Marshal(dtos.PaymentEvent{
+			ID:        "evt_1",
+			Provider:  constants.PaymentProviderStripe,
+			Type:      "invoice.paid",
+			CreatedAt: createdAt,
+			Data:      json.RawMessage(providerInvoice),
+		})
+		require.NoError(t, err)
+		return messaging.Message{ID: "evt_1", Body: body}
+	}
+	const paidEvent = `{"id":"in_1","object":"invoice","number":"ACME-0001","status":"paid","currency":"usd","created":1759990000,
+		"subtotal":9800,"total":11760,"amount_paid":11760,"amount_due":0,"total_taxes":[{"amount":1960}],
+		"status_transitions":{"finalized_at":1759999000},
+		"parent":{"subscription_details":{"subscription":"sub_2","metadata":{}}},
+		"lines":{"object":"list","data":[{"id":"il_1","description":"2 × Pro","quantity":2,"amount":9800,"period":{"start":1759999000,"end":1762591000}}]}}`
+
+	t.Run("first event of an invoice of a company subscription", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		billingRepo := new(MockBillingRepository)
+		invoiceRepo.On("GetByProviderID", "in_1").Return(nil, errors.NotFoundError("Invoice", nil))
+		billingRepo.On("GetSubscriptionByProviderID", "sub_2").Return(proSubscription(constants.SubscriptionStatusActive), nil)
+		var saved *models.Invoice
+		invoiceRepo.On("Save", mock.AnythingOfType("*models.Invoice")).Run(func(args mock.Arguments) {
+			saved = args.Get(0).(*models.Invoice)
+		}).Return(nil)
+		service := newTestInvoiceService(invoiceRepo, billingRepo, new(MockCompanyRepository), new(MockFileRegistry))
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt, paidEvent))
+
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Equal(t, "company-1", saved.CompanyID)
+		assert.Equal(t, "ACME-0001", saved.Number)
+		assert.Equal(t, constants.InvoiceStatusPaid, saved.Status)
+		assert.Equal(t, int64(1960), saved.Tax)
+		assert.Equal(t, int64(11760), saved.Total)
+		assert.Equal(t, []models.InvoiceLine{{Description: "2 × Pro", Quantity: 2, UnitAmount: 4900, Amount: 9800}}, saved.Lines)
+		assert.Equal(t, time.Unix(1759999000, 0).UTC(), saved.IssuedAt)
+		assert.Equal(t, time.Unix(1762591000, 0).UTC(), *saved.PeriodEnd)
+		assert.True(t, saved.LastEventAt.Equal(lastEventAt))
+	})
+
+	t.Run("changed invoice loses its PDF", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		registry := new(MockFileRegistry)
+		invoiceRepo.On("GetByProviderID", "in_1").Return(paidInvoice("invoices/company-1/old.pdf"), nil)
+		invoiceRepo.On("Save", mock.MatchedBy(func(invoice *models.Invoice) bool {
+			return invoice.PDFKey == "" && invoice.Total == 11760
+		})).Return(nil)
+		registry.On("Delete", mock.Anything, "invoices/company-1/old.pdf").Return(nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), registry)
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt.Add(time.Minute), paidEvent))
+
+		require.NoError(t, err)
+		invoiceRepo.AssertExpectations(t)
+		registry.AssertExpectations(t)
+	})
+
+	t.Run("outdated event ignored", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		invoiceRepo.On("GetByProviderID", "in_1").Return(paidInvoice(""), nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), new(MockFileRegistry))
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt.Add(-time.Minute), paidEvent))
+
+		require.NoError(t, err)
+		invoiceRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("draft ignored", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), new(MockFileRegistry))
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt, `{"id":"in_2","object":"invoice","status":"draft"}`))
+
+		require.NoError(t, err)
+		invoiceRepo.AssertNotCalled(t, "GetByProviderID", mock.Anything)
+	})
+
+	t.Run("invoice without a company ignored", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		invoiceRepo.On("GetByProviderID", "in_3").Return(nil, errors.NotFoundError("Invoice", nil))
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), new(MockFileRegistry))
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt, `{"id":"in_3","object":"invoice","status":"open"}`))
+
+		require.NoError(t, err)
+		invoiceRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		service := newTestInvoiceService(new(MockInvoiceRepository), new(MockBillingRepository), new(MockCompanyRepository), new(MockFileRegistry))
+
+		err := service.HandleInvoiceEvent(context.Background(), message(t, lastEventAt, `{"object":"invoice"}`))
+
+		assert.True(t, retry.IsPermanent(err))
+	})
+}
+
+func TestInvoiceService_GetInvoicePDF(t *testing.T) {
+	adminRoles := []string{constants.RoleAdmin}
+	available := func(key string) *models.File {
+		return factory.File().WithKey(key).Build()
+	}
+
+	t.Run("first request renders and stores the PDF", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		companyRepo := new(MockCompanyRepository)
+		registry := new(MockFileRegistry)
+		invoice := paidInvoice("")
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(invoice, nil)
+		companyRepo.On("GetOneByID", "company-1").Return(factory.Company().WithName("Globex").Build(), nil)
+
+		var uploadedKey string
+		registry.On("Upload", mock.Anything, mock.AnythingOfType("*multipart.FileHeader"), mock.AnythingOfType("string"), "application/pdf", "company-1").
+			Run(func(args mock.Arguments) {
+				uploadedKey = args.String(2)
+				fileHeader := args.Get(1).(*multipart.FileHeader)
+				file, err := fileHeader.Open()
+				require.NoError(t, err)
+				defer file.Close()
+				header := make([]byte, 5)
+				_, err = file.Read(header)
+				require.NoError(t, err)
+				assert.Equal(t, "%PDF-", string(header))
+			}).
+			Return(available("invoices/company-1/new.pdf"), nil)
+		invoiceRepo.On("SetPDFKey", testInvoiceID, mock.AnythingOfType("string"), invoice.LastEventAt).Return(true, nil)
+		registry.On("PresignedURL", mock.Anything, mock.Anything, constants.InvoicePDFURLDuration).Return("https://storage.example.com/invoice.pdf", nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), companyRepo, registry)
+
+		pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, adminRoles, "")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(uploadedKey, constants.InvoiceKeyPrefix+"/company-1/"))
+		assert.True(t, strings.HasSuffix(uploadedKey, ".pdf"))
+		assert.Equal(t, "https://storage.example.com/invoice.pdf", pdf.PDFURL)
+		assert.Equal(t, testInvoiceID, pdf.InvoiceID)
+		invoiceRepo.AssertCalled(t, "SetPDFKey", testInvoiceID, uploadedKey, invoice.LastEventAt)
+	})
+
+	t.Run("stored PDF reused", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		registry := new(MockFileRegistry)
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(paidInvoice("invoices/company-1/old.pdf"), nil)
+		registry.On("Get", mock.Anything, "invoices/company-1/old.pdf").Return(available("invoices/company-1/old.pdf"), nil)
+		registry.On("PresignedURL", mock.Anything, mock.Anything, constants.InvoicePDFURLDuration).Return("https://storage.example.com/old.pdf", nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), registry)
+
+		pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, adminRoles, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "invoices/company-1/old.pdf", pdf.PDFKey)
+		registry.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent render wins", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		companyRepo := new(MockCompanyRepository)
+		registry := new(MockFileRegistry)
+		invoice := paidInvoice("")
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(invoice, nil).Once()
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(paidInvoice("invoices/company-1/other.pdf"), nil).Once()
+		companyRepo.On("GetOneByID", "company-1").Return(factory.Company().WithName("Globex").Build(), nil)
+		registry.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "application/pdf", "company-1").Return(available("invoices/company-1/mine.pdf"), nil)
+		invoiceRepo.On("SetPDFKey", testInvoiceID, mock.AnythingOfType("string"), invoice.LastEventAt).Return(false, nil)
+		registry.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+		registry.On("Get", mock.Anything, "invoices/company-1/other.pdf").Return(available("invoices/company-1/other.pdf"), nil)
+		registry.On("PresignedURL", mock.Anything, mock.Anything, constants.InvoicePDFURLDuration).Return("https://storage.example.com/other.pdf", nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), companyRepo, registry)
+
+		pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, adminRoles, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "invoices/company-1/other.pdf", pdf.PDFKey)
+		registry.AssertNumberOfCalls(t, "Delete", 1)
+	})
+
+	t.Run("upload failed", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		companyRepo := new(MockCompanyRepository)
+		registry := new(MockFileRegistry)
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(paidInvoice(""), nil)
+		companyRepo.On("GetOneByID", "company-1").Return(factory.Company().WithName("Globex").Build(), nil)
+		registry.On("Upload", mock.Anything, mock.Anything, mock.AnythingOfType("string"), "application/pdf", "company-1").
+			Return(nil, errors.ExternalServiceError("Failed to upload file", stderrors.New("connection reset")))
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), companyRepo, registry)
+
+		pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, adminRoles, "")
+
+		require.Error(t, err)
+		assert.Nil(t, pdf)
+		assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+		invoiceRepo.AssertNotCalled(t, "SetPDFKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invoice ID that is not a UUID", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), new(MockCompanyRepository), new(MockFileRegistry))
+
+		_, err := service.GetInvoicePDF(context.Background(), "company-1", "in_1", adminRoles, "")
+
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrorTypeNotFound, errors.GetAppError(err).Type)
+		invoiceRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("manager of the company", func(t *testing.T) {
+		invoiceRepo := new(MockInvoiceRepository)
+		companyRepo := new(MockCompanyRepository)
+		registry := new(MockFileRegistry)
+		companyRepo.On("GetByKeycloakID", "org-1").Return(factory.Company().WithID("company-1").Build(), nil)
+		invoiceRepo.On("Get", "company-1", testInvoiceID).Return(paidInvoice("invoices/company-1/old.pdf"), nil)
+		registry.On("Get", mock.Anything, "invoices/company-1/old.pdf").Return(available("invoices/company-1/old.pdf"), nil)
+		registry.On("PresignedURL", mock.Anything, mock.Anything, constants.InvoicePDFURLDuration).Return("https://storage.example.com/old.pdf", nil)
+		service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), companyRepo, registry)
+
+		pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, []string{constants.RoleCompanyManager}, "org-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://storage.example.com/old.pdf", pdf.PDFURL)
+	})
+
+	managerTests := []struct {
+		name           string
+		organizationID string
+		company        *models.Company
+		companyErr     error
+	}{
+		{name: "manager of another company", organizationID: "org-2", company: factory.Company().WithID("company-2").Build()},
+		{name: "organization without a company", organizationID: "org-3", companyErr: errors.NotFoundError("Company", nil)},
+		{name: "token without an organization"},
+	}
+	for _, tt := range managerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoiceRepo := new(MockInvoiceRepository)
+			companyRepo := new(MockCompanyRepository)
+			registry := new(MockFileRegistry)
+			companyRepo.On("GetByKeycloakID", tt.organizationID).Return(tt.company, tt.companyErr).Maybe()
+			service := newTestInvoiceService(invoiceRepo, new(MockBillingRepository), companyRepo, registry)
+
+			pdf, err := service.GetInvoicePDF(context.Background(), "company-1", testInvoiceID, []string{constants.RoleCompanyManager}, tt.organizationID)
+
+			require.Error(t, err)
+			assert.Nil(t, pdf)
+			assert.Equal(t, errors.ErrorTypeForbidden, errors.GetAppError(err).Type)
+			invoiceRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+			registry.AssertNotCalled(t, "PresignedURL", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
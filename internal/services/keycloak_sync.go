@@ -0,0 +1,624 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// KeycloakSyncService reconciles the users and companies with the users, organizations
+// and client roles of the Keycloak realm, Keycloak being the source of truth
+type KeycloakSyncService interface {
+	// Sync creates the users and companies missing locally, links the users to their
+	// Keycloak user by email, fixes the drift of their names, emails, memberships and
+	// roles, and disables the users disabled in or deleted from Keycloak. In dry run it
+	// only reports these changes.
+	Sync(ctx context.Context, dryRun bool) (*dtos.KeycloakSyncReport, error)
+	// HandleEvent brings the user of an event on a Keycloak user to the state of that
+	// user in Keycloak, disabling them when it was deleted. The events on other resources,
+	// the creations and the users missing locally are left to Sync.
+	HandleEvent(ctx context.Context, event *dtos.KeycloakEvent) error
+}
+
+// keycloakSyncService implements KeycloakSyncService
+type keycloakSyncService struct {
+	auth           auth.AuthService
+	tokens         auth.TokenProvider
+	userService    UserService
+	companyService CompanyService
+	userRepo       repositories.UserRepository
+	companyRepo    repositories.CompanyRepository
+	rbacRepo       repositories.RBACRepository
+	cache          cache.Cache
+}
+
+// ProvideKeycloakSyncService creates a new Keycloak sync service
+func ProvideKeycloakSyncService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	userService UserService,
+	companyService CompanyService,
+	userRepo repositories.UserRepository,
+	companyRepo repositories.CompanyRepository,
+	rbacRepo repositories.RBACRepository,
+	cache cache.Cache,
+) KeycloakSyncService {
+	return &keycloakSyncService{
+		auth:           authProvider,
+		tokens:         tokens,
+		userService:    userService,
+		companyService: companyService,
+		userRepo:       userRepo,
+		companyRepo:    companyRepo,
+		rbacRepo:       rbacRepo,
+		cache:          cache,
+	}
+}
+
+// keycloakSyncRun holds the state of one sync: the report and the local users by their
+// Keycloak ID, the ones created by the run included
+type keycloakSyncRun struct {
+	report     *dtos.KeycloakSyncReport
+	linked     map[string]*models.User
+	adminToken string
+	// err is the error of the last change that failed
+	err error
+}
+
+// newKeycloakSyncRun starts a sync
+func newKeycloakSyncRun(dryRun bool) *keycloakSyncRun {
+	return &keycloakSyncRun{
+		report: &dtos.KeycloakSyncReport{
+			DryRun:    dryRun,
+			StartedAt: time.Now().UTC(),
+			Counts:    map[string]int{},
+			Changes:   []dtos.KeycloakSyncChange{},
+		},
+		linked: map[string]*models.User{},
+	}
+}
+
+func (s *keycloakSyncService) Sync(ctx context.Context, dryRun bool) (*dtos.KeycloakSyncReport, error) {
+	run := newKeycloakSyncRun(dryRun)
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	run.adminToken = adminToken
+
+	keycloakUsers, err := s.auth.ListUsers(ctx, adminToken)
+	if err != nil {
+		return nil, err
+	}
+	organizations, err := s.auth.ListOrganizations(ctx, adminToken)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.localUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty realm more likely means a misconfigured realm or client than users all
+	// deleted, so the users are not disabled on its word
+	if len(keycloakUsers) == 0 && slices.ContainsFunc(users, func(user models.User) bool { return user.KeycloakID != "" }) {
+		return nil, errors.ExternalServiceError("Keycloak returned no users while users are linked to Keycloak users", nil).
+			WithOperation("keycloak_sync").
+			WithResource("user")
+	}
+
+	s.syncUsers(ctx, run, keycloakUsers, users)
+	if err := s.syncCompanies(ctx, run, organizations); err != nil {
+		return nil, err
+	}
+	if err := s.syncRoles(ctx, run); err != nil {
+		return nil, err
+	}
+
+	run.report.FinishedAt = time.Now().UTC()
+	logger.Log.Info("Keycloak sync finished",
+		zap.Bool("dry_run", dryRun),
+		zap.Any("counts", run.report.Counts),
+		zap.Int("failed", run.report.Failed),
+	)
+	return run.report, nil
+}
+
+func (s *keycloakSyncService) HandleEvent(ctx context.Context, event *dtos.KeycloakEvent) error {
+	keycloakID := keycloakEventUser(event)
+	if keycloakID == "" {
+		logger.Log.Debug("Keycloak event ignored",
+			zap.String("type", event.Type),
+			zap.String("resource_type", event.ResourceType),
+			zap.String("operation_type", event.OperationType),
+		)
+		return nil
+	}
+
+	users, _, err := s.userRepo.GetRange(map[string]string{"keycloak_id": keycloakID}, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	user := &users[0]
+
+	// The event only tells which user changed: the user is read from Keycloak again, so
+	// that replayed or reordered events apply its latest state
+	run := newKeycloakSyncRun(false)
+	run.adminToken, err = s.tokens.AdminToken(ctx)
+	if err != nil {
+		return err
+	}
+	keycloakUser, err := s.auth.GetUser(ctx, run.adminToken, keycloakID)
+	if err != nil {
+		return err
+	}
+
+	if keycloakUser == nil {
+		if user.DisabledAt == nil {
+			s.setDisabled(ctx, run, user, true, "deleted from Keycloak")
+		}
+	} else {
+		if keycloakUser.Email != "" {
+			s.patchUser(ctx, run, user, *keycloakUser, constants.KeycloakSyncUserUpdated)
+		}
+		if disabled := keycloakUser.Enabled != nil && !*keycloakUser.Enabled; disabled != (user.DisabledAt != nil) {
+			s.setDisabled(ctx, run, user, disabled, "disabled in Keycloak")
+		}
+	}
+	if run.err != nil {
+		return run.err
+	}
+
+	if len(run.report.Changes) > 0 {
+		logger.Log.Info("Keycloak event applied",
+			zap.String("user_id", user.ID),
+			zap.String("keycloak_id", keycloakID),
+			zap.Any("counts", run.report.Counts),
+		)
+	}
+	return nil
+}
+
+// keycloakEventUser returns the ID of the Keycloak user whose email, names or existence
+// the event changed, empty for the other events
+func keycloakEventUser(event *dtos.KeycloakEvent) string {
+	if event.ResourceType != "" {
+		if event.ResourceType != constants.KeycloakEventResourceUser || event.OperationType == constants.KeycloakEventOperationCreate {
+			return ""
+		}
+		// The events on the sub-resources of the user, e.g. users/<id>/role-mappings,
+		// leave the user itself unchanged
+		id, found := strings.CutPrefix(event.ResourcePath, "users/")
+		if !found || strings.Contains(id, "/") {
+			return ""
+		}
+		return id
+	}
+
+	if slices.Contains(constants.KeycloakUserEventTypes, strings.TrimPrefix(event.Type, "access.")) {
+		return event.UserID
+	}
+	return ""
+}
+
+// syncUsers matches the Keycloak users with the local users, by Keycloak ID and then by
+// email, and brings the local users to the state of Keycloak
+func (s *keycloakSyncService) syncUsers(ctx context.Context, run *keycloakSyncRun, keycloakUsers []auth.User, users []models.User) {
+	byKeycloakID := make(map[string]*models.User, len(users))
+	byEmail := make(map[string]*models.User, len(users))
+	for i := range users {
+		user := &users[i]
+		if user.KeycloakID != "" {
+			byKeycloakID[user.KeycloakID] = user
+		}
+		byEmail[strings.ToLower(user.Email)] = user
+	}
+	inKeycloak := make(map[string]bool, len(keycloakUsers))
+	for _, keycloakUser := range keycloakUsers {
+		inKeycloak[keycloakUser.ID] = true
+	}
+
+	for _, keycloakUser := range keycloakUsers {
+		if keycloakUser.Email == "" {
+			run.record(dtos.KeycloakSyncChange{
+				Action:     constants.KeycloakSyncUserSkipped,
+				KeycloakID: keycloakUser.ID,
+				Detail:     "no email",
+			})
+			continue
+		}
+
+		user, ok := byKeycloakID[keycloakUser.ID]
+		candidate := byEmail[strings.ToLower(keycloakUser.Email)]
+		switch {
+		case ok:
+			s.patchUser(ctx, run, user, keycloakUser, constants.KeycloakSyncUserUpdated)
+		case candidate != nil && !inKeycloak[candidate.KeycloakID]:
+			// The user without Keycloak user, or linked to one since deleted, is linked
+			// to the Keycloak user with their email
+			user = candidate
+			if !s.patchUser(ctx, run, user, keycloakUser, constants.KeycloakSyncUserLinked) {
+				continue
+			}
+			user.KeycloakID = keycloakUser.ID
+		default:
+			if user = s.createUser(run, keycloakUser); user == nil {
+				continue
+			}
+		}
+		run.linked[keycloakUser.ID] = user
+
+		if disabled := keycloakUser.Enabled != nil && !*keycloakUser.Enabled; disabled != (user.DisabledAt != nil) {
+			s.setDisabled(ctx, run, user, disabled, "disabled in Keycloak")
+		}
+	}
+
+	for i := range users {
+		user := &users[i]
+		if user.KeycloakID != "" && !inKeycloak[user.KeycloakID] && user.DisabledAt == nil {
+			s.setDisabled(ctx, run, user, true, "deleted from Keycloak")
+		}
+	}
+}
+
+// syncCompanies creates the companies of the organizations missing locally, renames the
+// others and brings their members to the members of the organizations. The companies of
+// organizations deleted from Keycloak are reported, never deleted.
+func (s *keycloakSyncService) syncCompanies(ctx context.Context, run *keycloakSyncRun, organizations []auth.TenantOrganization) error {
+	companies, err := s.localCompanies()
+	if err != nil {
+		return err
+	}
+	byKeycloakID := make(map[string]*models.Company, len(companies))
+	for i := range companies {
+		if companies[i].KeycloakID != "" {
+			byKeycloakID[companies[i].KeycloakID] = &companies[i]
+		}
+	}
+
+	inKeycloak := make(map[string]bool, len(organizations))
+	for _, organization := range organizations {
+		inKeycloak[organization.ID] = true
+
+		company, ok := byKeycloakID[organization.ID]
+		if !ok {
+			company = s.createCompany(ctx, run, organization)
+		} else if company.Name != organization.Name {
+			change := dtos.KeycloakSyncChange{
+				Action:     constants.KeycloakSyncCompanyRenamed,
+				CompanyID:  company.ID,
+				KeycloakID: organization.ID,
+				Detail:     fmt.Sprintf("name: %s -> %s", company.Name, organization.Name),
+			}
+			run.apply(change, func() error {
+				_, err := s.companyService.Update(ctx, company.ID, &dtos.UpdateCompanyRequest{
+					CompanyRequest: dtos.CompanyRequest{Name: organization.Name},
+				})
+				return err
+			})
+		}
+		if company == nil {
+			continue
+		}
+
+		members, err := s.auth.ListOrganizationMembers(ctx, run.adminToken, organization.ID)
+		if err != nil {
+			return err
+		}
+		s.syncMembers(ctx, run, company, organization.ID, members)
+	}
+
+	for _, company := range companies {
+		if company.KeycloakID != "" && !inKeycloak[company.KeycloakID] {
+			run.record(dtos.KeycloakSyncChange{
+				Action:     constants.KeycloakSyncCompanyOrphaned,
+				CompanyID:  company.ID,
+				KeycloakID: company.KeycloakID,
+				Detail:     "organization deleted from Keycloak",
+			})
+		}
+	}
+	return nil
+}
+
+// syncMembers adds the members of the organization to the company and removes the other
+// users linked to Keycloak from it; the users without Keycloak user are left untouched
+func (s *keycloakSyncService) syncMembers(ctx context.Context, run *keycloakSyncRun, company *models.Company, organizationID string, members []auth.User) {
+	desired := make(map[string]bool, len(members))
+	for _, member := range members {
+		desired[member.ID] = true
+
+		user := run.linked[member.ID]
+		if user == nil || slices.ContainsFunc(user.Companies, func(c models.Company) bool { return c.ID == company.ID }) {
+			continue
+		}
+		change := dtos.KeycloakSyncChange{
+			Action:     constants.KeycloakSyncMembershipAdded,
+			UserID:     user.ID,
+			CompanyID:  company.ID,
+			KeycloakID: member.ID,
+		}
+		run.apply(change, func() error {
+			_, err := s.userService.AddCompany(ctx, user.ID, company.ID)
+			return err
+		})
+	}
+
+	for keycloakID, user := range run.linked {
+		if desired[keycloakID] || !slices.ContainsFunc(user.Companies, func(c models.Company) bool { return c.ID == company.ID }) {
+			continue
+		}
+		change := dtos.KeycloakSyncChange{
+			Action:     constants.KeycloakSyncMembershipRemoved,
+			UserID:     user.ID,
+			CompanyID:  company.ID,
+			KeycloakID: keycloakID,
+			Detail:     "not a member of organization " + organizationID,
+		}
+		run.apply(change, func() error {
+			_, err := s.userService.RemoveCompany(ctx, user.ID, company.ID)
+			return err
+		})
+	}
+}
+
+// syncRoles grants the roles of the database RBAC that are also roles of the client to
+// the users holding the client role, and revokes them from the others. The roles the
+// client does not define are left untouched.
+func (s *keycloakSyncService) syncRoles(ctx context.Context, run *keycloakSyncRun) error {
+	roles, err := s.rbacRepo.ListRoles()
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+
+	members, err := s.auth.ListClientRoleMembers(ctx, run.adminToken, names)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	granted := make(map[string][]string)
+	for role, keycloakIDs := range members {
+		for _, keycloakID := range keycloakIDs {
+			granted[keycloakID] = append(granted[keycloakID], role)
+		}
+	}
+
+	for keycloakID, user := range run.linked {
+		var current []string
+		for _, role := range user.Roles {
+			current = append(current, role.Name)
+		}
+		desired := slices.Clone(granted[keycloakID])
+		for _, role := range current {
+			if _, synced := members[role]; !synced {
+				desired = append(desired, role)
+			}
+		}
+		slices.Sort(current)
+		slices.Sort(desired)
+		desired = slices.Compact(desired)
+		if slices.Equal(current, desired) {
+			continue
+		}
+
+		change := dtos.KeycloakSyncChange{
+			Action:     constants.KeycloakSyncRolesUpdated,
+			UserID:     user.ID,
+			KeycloakID: keycloakID,
+			Detail:     fmt.Sprintf("roles: [%s] -> [%s]", strings.Join(current, ","), strings.Join(desired, ",")),
+		}
+		run.apply(change, func() error {
+			if err := s.rbacRepo.SetUserRoles(user, desired); err != nil {
+				return err
+			}
+			cache.Invalidate(ctx, s.cache, constants.PermissionsCacheKeyPrefix+keycloakID)
+			return nil
+		})
+	}
+	return nil
+}
+
+// patchUser brings the email and names of the user to the ones of their Keycloak user,
+// linking the user to it. It reports whether the user is linked.
+func (s *keycloakSyncService) patchUser(ctx context.Context, run *keycloakSyncRun, user *models.User, keycloakUser auth.User, action string) bool {
+	var drift []string
+	if user.Email != keycloakUser.Email {
+		drift = append(drift, fmt.Sprintf("email: %s -> %s", user.Email, keycloakUser.Email))
+	}
+	if user.FirstName != keycloakUser.GivenName {
+		drift = append(drift, fmt.Sprintf("first_name: %s -> %s", user.FirstName, keycloakUser.GivenName))
+	}
+	if user.LastName != keycloakUser.FamilyName {
+		drift = append(drift, fmt.Sprintf("last_name: %s -> %s", user.LastName, keycloakUser.FamilyName))
+	}
+	if user.KeycloakID == keycloakUser.ID && len(drift) == 0 {
+		return true
+	}
+
+	change := dtos.KeycloakSyncChange{
+		Action:     action,
+		UserID:     user.ID,
+		KeycloakID: keycloakUser.ID,
+		Detail:     strings.Join(drift, "; "),
+	}
+	return run.apply(change, func() error {
+		_, err := s.userService.Patch(ctx, user.ID, &dtos.PatchUserRequest{
+			Email:      keycloakUser.Email,
+			FirstName:  keycloakUser.GivenName,
+			LastName:   keycloakUser.FamilyName,
+			KeycloakID: keycloakUser.ID,
+		})
+		return err
+	})
+}
+
+// createUser creates the user of a Keycloak user, without the verification email of the
+// users created through the API; in dry run the user is returned unsaved, without ID
+func (s *keycloakSyncService) createUser(run *keycloakSyncRun, keycloakUser auth.User) *models.User {
+	user := &models.User{
+		Email:      keycloakUser.Email,
+		FirstName:  keycloakUser.GivenName,
+		LastName:   keycloakUser.FamilyName,
+		KeycloakID: keycloakUser.ID,
+	}
+	if keycloakUser.Enabled != nil && !*keycloakUser.Enabled {
+		disabledAt := time.Now().UTC()
+		user.DisabledAt = &disabledAt
+	}
+
+	change := dtos.KeycloakSyncChange{
+		Action:     constants.KeycloakSyncUserCreated,
+		KeycloakID: keycloakUser.ID,
+		Detail:     keycloakUser.Email,
+	}
+	ok := run.apply(change, func() error {
+		created, err := s.userRepo.Create(user)
+		if err != nil {
+			return err
+		}
+		user = created
+		return nil
+	})
+	if !ok {
+		return nil
+	}
+	return user
+}
+
+// createCompany creates the company of an organization; in dry run the company is
+// returned unsaved, without ID
+func (s *keycloakSyncService) createCompany(ctx context.Context, run *keycloakSyncRun, organization auth.TenantOrganization) *models.Company {
+	company := &models.Company{Name: organization.Name, KeycloakID: organization.ID}
+
+	change := dtos.KeycloakSyncChange{
+		Action:     constants.KeycloakSyncCompanyCreated,
+		KeycloakID: organization.ID,
+		Detail:     organization.Name,
+	}
+	ok := run.apply(change, func() error {
+		created, err := s.companyService.Create(ctx, &dtos.CreateCompanyRequest{
+			CompanyRequest: dtos.CompanyRequest{Name: organization.Name, KeycloakID: organization.ID},
+		})
+		if err != nil {
+			return err
+		}
+		company = created
+		return nil
+	})
+	if !ok {
+		return nil
+	}
+	return company
+}
+
+// setDisabled sets or clears the disabled time of the user
+func (s *keycloakSyncService) setDisabled(ctx context.Context, run *keycloakSyncRun, user *models.User, disabled bool, reason string) {
+	change := dtos.KeycloakSyncChange{
+		Action:     constants.KeycloakSyncUserEnabled,
+		UserID:     user.ID,
+		KeycloakID: user.KeycloakID,
+	}
+	if disabled {
+		change.Action = constants.KeycloakSyncUserDisabled
+		change.Detail = reason
+	}
+
+	run.apply(change, func() error {
+		previous := user.DisabledAt
+		user.DisabledAt = nil
+		if disabled {
+			disabledAt := time.Now().UTC()
+			user.DisabledAt = &disabledAt
+		}
+		if err := s.userRepo.UpdateColumns(user, "disabled_at"); err != nil {
+			user.DisabledAt = previous
+			return err
+		}
+		cache.Invalidate(ctx, s.cache, constants.UserCacheKeyPrefix+user.ID)
+		return nil
+	})
+}
+
+// localUsers loads all the users with their companies and roles, by pages
+func (s *keycloakSyncService) localUsers() ([]models.User, error) {
+	var users []models.User
+	for {
+		page, total, err := s.userRepo.GetRange(map[string]string{}, len(users), constants.StreamBatchSize, "Companies", "Roles")
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+		if len(page) == 0 || int64(len(users)) >= total {
+			return users, nil
+		}
+	}
+}
+
+// localCompanies loads all the companies, sandboxes left out, by pages
+func (s *keycloakSyncService) localCompanies() ([]models.Company, error) {
+	var companies []models.Company
+	for {
+		page, total, err := s.companyRepo.GetRange(map[string]string{}, len(companies), constants.StreamBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		companies = append(companies, page...)
+		if len(page) == 0 || int64(len(companies)) >= total {
+			return companies, nil
+		}
+	}
+}
+
+// apply makes the change, unless in dry run, and records it; a failed change is logged
+// and left for the next run. It reports whether the change was made.
+func (r *keycloakSyncRun) apply(change dtos.KeycloakSyncChange, fn func() error) bool {
+	if !r.report.DryRun {
+		if err := fn(); err != nil {
+			r.err = err
+			r.report.Failed++
+			logger.Log.Warn("Failed to apply Keycloak sync change",
+				zap.String("action", change.Action),
+				zap.String("user_id", change.UserID),
+				zap.String("company_id", change.CompanyID),
+				zap.String("keycloak_id", change.KeycloakID),
+				zap.Error(err),
+			)
+			return false
+		}
+	}
+	r.record(change)
+	return true
+}
+
+// record counts the change and lists it, up to KeycloakSyncMaxReportedChanges
+func (r *keycloakSyncRun) record(change dtos.KeycloakSyncChange) {
+	r.report.Counts[change.Action]++
+	if len(r.report.Changes) < constants.KeycloakSyncMaxReportedChanges {
+		r.report.Changes = append(r.report.Changes, change)
+	} else {
+		r.report.Truncated = true
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeycloakSyncService_Sync_DryRun(t *testing.T) {
+	disabled := false
+	authProvider := new(MockAuthProvider)
+	authProvider.On("ListUsers", mock.Anything, "admin-token").Return([]auth.User{
+		{ID: "kc-1", Email: "john@example.com", GivenName: "John", FamilyName: "Doe"},
+		{ID: "kc-2", Email: "jane.doe@example.com", GivenName: "Jane", FamilyName: "Doe"},
+		{ID: "kc-3", Email: "Ada@example.com", GivenName: "Ada", FamilyName: "Lovelace"},
+		{ID: "kc-4", Email: "new@example.com", GivenName: "New", FamilyName: "User", Enabled: &disabled},
+		{ID: "kc-5"},
+	}, nil)
+	authProvider.On("ListOrganizations", mock.Anything, "admin-token").Return([]auth.TenantOrganization{
+		{ID: "org-1", Name: "Acme"},
+		{ID: "org-2", Name: "Globex"},
+	}, nil)
+	authProvider.On("ListOrganizationMembers", mock.Anything, "admin-token", "org-1").Return([]auth.User{{ID: "kc-2"}}, nil)
+	authProvider.On("ListOrganizationMembers", mock.Anything, "admin-token", "org-2").Return([]auth.User{{ID: "kc-4"}}, nil)
+	authProvider.On("ListClientRoleMembers", mock.Anything, "admin-token", []string{"admin", "billing"}).
+		Return(map[string][]string{"admin": {"kc-2"}, "billing": {}}, nil)
+
+	acme := *factory.Company().WithID("company-1").WithName("Acme").WithKeycloakID("org-1").Build()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetRange", map[string]string{}, 0, constants.StreamBatchSize, []string{"Companies", "Roles"}).Return([]models.User{
+		// in sync
+		*factory.User().WithID("user-1").WithName("John", "Doe").WithEmail("john@example.com").WithKeycloakID("kc-1").Build(),
+		// email drift, member of Acme in Keycloak only, admin in Keycloak only
+		*factory.User().WithID("user-2").WithName("Jane", "Doe").WithEmail("jane@example.com").WithKeycloakID("kc-2").
+			WithRoles(models.Role{Name: "billing"}, models.Role{Name: "support"}).Build(),
+		// not linked yet
+		*factory.User().WithID("user-3").WithName("Ada", "Lovelace").WithEmail("ada@example.com").WithKeycloakID("").Build(),
+		// deleted from Keycloak, still a member of Acme
+		*factory.User().WithID("user-6").WithEmail("gone@example.com").WithKeycloakID("kc-6").WithCompany(&acme).Build(),
+	}, int64(4), nil)
+	companyRepo := new(MockCompanyRepository)
+	companyRepo.On("GetRange", map[string]string{}, 0, constants.StreamBatchSize, []string(nil)).Return([]models.Company{
+		acme,
+		*factory.Company().WithID("company-3").WithName("Initech").WithKeycloakID("org-3").Build(),
+	}, int64(2), nil)
+	rbacRepo := new(MockRBACRepository)
+	rbacRepo.On("ListRoles").Return([]models.Role{{Name: "admin"}, {Name: "billing"}}, nil)
+
+	service := ProvideKeycloakSyncService(authProvider, newMockTokenProvider(), nil, nil, userRepo, companyRepo, rbacRepo, new(MockCache))
+	report, err := service.Sync(context.Background(), true)
+
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, map[string]int{
+		constants.KeycloakSyncUserUpdated:     1,
+		constants.KeycloakSyncUserLinked:      1,
+		constants.KeycloakSyncUserCreated:     1,
+		constants.KeycloakSyncUserDisabled:    1,
+		constants.KeycloakSyncUserSkipped:     1,
+		constants.KeycloakSyncCompanyCreated:  1,
+		constants.KeycloakSyncCompanyOrphaned: 1,
+		constants.KeycloakSyncMembershipAdded: 2,
+		constants.KeycloakSyncRolesUpdated:    1,
+	}, report.Counts)
+	assert.Contains(t, report.Changes, dtos.KeycloakSyncChange{
+		Action:     constants.KeycloakSyncRolesUpdated,
+		UserID:     "user-2",
+		KeycloakID: "kc-2",
+		Detail:     "roles: [billing,support] -> [admin,support]",
+	})
+	assert.Equal(t, 0, report.Failed)
+	userRepo.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything)
+	userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	rbacRepo.AssertNotCalled(t, "SetUserRoles", mock.Anything, mock.Anything)
+}
+
+func TestKeycloakSyncService_Sync_Apply(t *testing.T) {
+	disabled := false
+	authProvider := new(MockAuthProvider)
+	authProvider.On("ListUsers", mock.Anything, "admin-token").Return([]auth.User{
+		{ID: "kc-1", Email: "john@example.com", GivenName: "John", FamilyName: "Doe", Enabled: &disabled},
+	}, nil)
+	authProvider.On("ListOrganizations", mock.Anything, "admin-token").Return([]auth.TenantOrganization{}, nil)
+	authProvider.On("ListClientRoleMembers", mock.Anything, "admin-token", []string{"admin"}).
+		Return(map[string][]string{"admin": {"kc-1"}}, nil)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetRange", map[string]string{}, 0, constants.StreamBatchSize, []string{"Companies", "Roles"}).Return([]models.User{
+		*factory.User().WithID("user-1").WithName("John", "Doe").WithEmail("john@example.com").WithKeycloakID("kc-1").Build(),
+		*factory.User().WithID("user-2").WithEmail("gone@example.com").WithKeycloakID("kc-2").Build(),
+	}, int64(2), nil)
+	userRepo.On("UpdateColumns", mock.MatchedBy(func(user *models.User) bool { return user.ID == "user-1" }), []string{"disabled_at"}).Return(nil)
+	userRepo.On("UpdateColumns", mock.MatchedBy(func(user *models.User) bool { return user.ID == "user-2" }), []string{"disabled_at"}).
+		Return(errors.DatabaseError("Failed to update user", nil))
+	companyRepo := new(MockCompanyRepository)
+	companyRepo.On("GetRange", map[string]string{}, 0, constants.StreamBatchSize, []string(nil)).Return([]models.Company{}, int64(0), nil)
+	rbacRepo := new(MockRBACRepository)
+	rbacRepo.On("ListRoles").Return([]models.Role{{Name: "admin"}}, nil)
+	rbacRepo.On("SetUserRoles", mock.MatchedBy(func(user *models.User) bool { return user.ID == "user-1" }), []string{"admin"}).Return(nil)
+	cache := new(MockCache)
+	cache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+"user-1").Return(nil)
+	cache.On("Delete", mock.Anything, constants.PermissionsCacheKeyPrefix+"kc-1").Return(nil)
+
+	service := ProvideKeycloakSyncService(authProvider, newMockTokenProvider(), nil, nil, userRepo, companyRepo, rbacRepo, cache)
+	report, err := service.Sync(context.Background(), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		constants.KeycloakSyncUserDisabled: 1,
+		constants.KeycloakSyncRolesUpdated: 1,
+	}, report.Counts)
+	assert.Equal(t, 1, report.Failed)
+	userRepo.AssertExpectations(t)
+	rbacRepo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func TestKeycloakSyncService_Sync_EmptyRealm(t *testing.T) {
+	authProvider := new(MockAuthProvider)
+	authProvider.On("ListUsers", mock.Anything, "admin-token").Return([]auth.User{}, nil)
+	authProvider.On("ListOrganizations", mock.Anything, "admin-token").Return([]auth.TenantOrganization{}, nil)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetRange", map[string]string{}, 0, constants.StreamBatchSize, []string{"Companies", "Roles"}).Return([]models.User{
+		*factory.User().WithID("user-1").WithEmail("john@example.com").WithKeycloakID("kc-1").Build(),
+	}, int64(1), nil)
+
+	service := ProvideKeycloakSyncService(authProvider, newMockTokenProvider(), nil, nil, userRepo, new(MockCompanyRepository), new(MockRBACRepository), new(MockCache))
+	report, err := service.Sync(context.Background(), false)
+
+	require.Error(t, err)
+	assert.Nil(t, report)
+	assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+	userRepo.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything)
+}
+
+func TestKeycloakSyncService_HandleEvent(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name         string
+		event        dtos.KeycloakEvent
+		keycloakUser *auth.User
+		// disables is set when the user gets disabled_at
+		disables bool
+		ignored  bool
+	}{
+		{
+			name:     "user deleted by an admin",
+			event:    dtos.KeycloakEvent{OperationType: "DELETE", ResourceType: "USER", ResourcePath: "users/kc-1"},
+			disables: true,
+		},
+		{
+			name:         "user disabled by an admin",
+			event:        dtos.KeycloakEvent{OperationType: "UPDATE", ResourceType: "USER", ResourcePath: "users/kc-1"},
+			keycloakUser: &auth.User{ID: "kc-1", Email: "john@example.com", GivenName: "John", FamilyName: "Doe", Enabled: &disabled},
+			disables:     true,
+		},
+		{
+			name:     "account deleted by the user, prefixed type",
+			event:    dtos.KeycloakEvent{Type: "access.DELETE_ACCOUNT", UserID: "kc-1"},
+			disables: true,
+		},
+		{
+			name:         "email updated, already applied",
+			event:        dtos.KeycloakEvent{Type: "UPDATE_EMAIL", UserID: "kc-1"},
+			keycloakUser: &auth.User{ID: "kc-1", Email: "john@example.com", GivenName: "John", FamilyName: "Doe", Enabled: &enabled},
+		},
+		{
+			name:    "user created",
+			event:   dtos.KeycloakEvent{OperationType: "CREATE", ResourceType: "USER", ResourcePath: "users/kc-1"},
+			ignored: true,
+		},
+		{
+			name:    "roles of the user changed",
+			event:   dtos.KeycloakEvent{OperationType: "UPDATE", ResourceType: "USER", ResourcePath: "users/kc-1/role-mappings/realm"},
+			ignored: true,
+		},
+		{
+			name:    "login",
+			event:   dtos.KeycloakEvent{Type: "LOGIN", UserID: "kc-1"},
+			ignored: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+			cache := new(MockCache)
+			if !tt.ignored {
+				userRepo.On("GetRange", map[string]string{"keycloak_id": "kc-1"}, 0, 1, []string(nil)).Return([]models.User{
+					*factory.User().WithID("user-1").WithName("John", "Doe").WithEmail("john@example.com").WithKeycloakID("kc-1").Build(),
+				}, int64(1), nil)
+				authProvider.On("GetUser", mock.Anything, "admin-token", "kc-1").Return(tt.keycloakUser, nil)
+			}
+			if tt.disables {
+				userRepo.On("UpdateColumns", mock.MatchedBy(func(user *models.User) bool { return user.DisabledAt != nil }), []string{"disabled_at"}).Return(nil)
+				cache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+"user-1").Return(nil)
+			}
+
+			service := ProvideKeycloakSyncService(authProvider, newMockTokenProvider(), nil, nil, userRepo, new(MockCompanyRepository), new(MockRBACRepository), cache)
+			err := service.HandleEvent(context.Background(), &tt.event)
+
+			require.NoError(t, err)
+			userRepo.AssertExpectations(t)
+			authProvider.AssertExpectations(t)
+			cache.AssertExpectations(t)
+			if !tt.disables {
+				userRepo.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestKeycloakSyncService_HandleEvent_Failure(t *testing.T) {
+	authProvider := new(MockAuthProvider)
+	authProvider.On("GetUser", mock.Anything, "admin-token", "kc-1").Return(nil, nil)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetRange", map[string]string{"keycloak_id": "kc-1"}, 0, 1, []string(nil)).Return([]models.User{
+		*factory.User().WithID("user-1").WithEmail("john@example.com").WithKeycloakID("kc-1").Build(),
+	}, int64(1), nil)
+	userRepo.On("UpdateColumns", mock.Anything, []string{"disabled_at"}).Return(errors.DatabaseError("Failed to update user", nil))
+
+	service := ProvideKeycloakSyncService(authProvider, newMockTokenProvider(), nil, nil, userRepo, new(MockCompanyRepository), new(MockRBACRepository), new(MockCache))
+	err := service.HandleEvent(context.Background(), &dtos.KeycloakEvent{OperationType: "DELETE", ResourceType: "USER", ResourcePath: "users/kc-1"})
+
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorTypeDatabase, errors.GetAppError(err).Type)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// MFAService manages the OTP authenticators of the users, kept by Keycloak
+type MFAService interface {
+	Status(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error)
+	// UserStatus returns the status of a loaded user, nil when they have no Keycloak user
+	// or Keycloak fails, so that the user can still be returned without it
+	UserStatus(ctx context.Context, user *models.User) *dtos.MFAStatusResponse
+	// Require asks the user to configure an OTP authenticator at their next login
+	Require(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error)
+	// Enroll emails the user a link to configure an OTP authenticator now
+	Enroll(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error)
+	// Reset removes the OTP authenticators of the user and the request to configure one
+	Reset(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error)
+}
+
+// mfaService implements MFAService
+type mfaService struct {
+	auth     auth.AuthService
+	tokens   auth.TokenProvider
+	userRepo repositories.UserRepository
+}
+
+// ProvideMFAService creates a new MFA service
+func ProvideMFAService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	userRepo repositories.UserRepository,
+) MFAService {
+	return &mfaService{
+		auth:     authProvider,
+		tokens:   tokens,
+		userRepo: userRepo,
+	}
+}
+
+func (s *mfaService) Status(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error) {
+	user, adminToken, err := s.keycloakUser(ctx, "get_mfa_status", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.status(ctx, adminToken, user)
+}
+
+func (s *mfaService) UserStatus(ctx context.Context, user *models.User) *dtos.MFAStatusResponse {
+	if user.KeycloakID == "" {
+		return nil
+	}
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		logger.Log.Warn("Failed to get MFA status", zap.String("user_id", user.ID), zap.Error(err))
+		return nil
+	}
+	status, err := s.status(ctx, adminToken, user)
+	if err != nil {
+		logger.Log.Warn("Failed to get MFA status", zap.String("user_id", user.ID), zap.Error(err))
+		return nil
+	}
+
+	return status
+}
+
+func (s *mfaService) Require(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error) {
+	user, adminToken, err := s.keycloakUser(ctx, "require_mfa", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RequireOTP(ctx, adminToken, user.KeycloakID); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("MFA required", zap.String("user_id", user.ID))
+
+	return s.status(ctx, adminToken, user)
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error) {
+	user, adminToken, err := s.keycloakUser(ctx, "enroll_mfa", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.status(ctx, adminToken, user)
+	if err != nil {
+		return nil, err
+	}
+	if status.Configured {
+		return nil, errors.ConflictError("The user already has an OTP authenticator", nil).
+			WithOperation("enroll_mfa").
+			WithResource("user").
+			WithContext("user_id", user.ID)
+	}
+
+	if err := s.auth.SendOTPEnrollmentEmail(ctx, adminToken, user.KeycloakID); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("MFA enrollment email sent", zap.String("user_id", user.ID))
+
+	return status, nil
+}
+
+func (s *mfaService) Reset(ctx context.Context, userID string) (*dtos.MFAStatusResponse, error) {
+	user, adminToken, err := s.keycloakUser(ctx, "reset_mfa", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.ResetOTP(ctx, adminToken, user.KeycloakID); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("MFA reset", zap.String("user_id", user.ID))
+
+	return &dtos.MFAStatusResponse{}, nil
+}
+
+// keycloakUser loads the user and an admin token, failing when the user has no Keycloak
+// user to hold their authenticators
+func (s *mfaService) keycloakUser(ctx context.Context, operation string, userID string) (*models.User, string, error) {
+	user, err := s.userRepo.GetOneByID(userID)
+	if err != nil {
+		return nil, "", errors.NotFoundError("User", err).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+	if user.KeycloakID == "" {
+		return nil, "", errors.ValidationError("The user has no Keycloak user", nil).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, adminToken, nil
+}
+
+func (s *mfaService) status(ctx context.Context, adminToken string, user *models.User) (*dtos.MFAStatusResponse, error) {
+	status, err := s.auth.GetMFAStatus(ctx, adminToken, user.KeycloakID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.MFAStatusResponse{
+		Configured: status.Configured,
+		Required:   status.Required,
+	}, nil
+}
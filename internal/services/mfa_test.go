@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAService_Require(t *testing.T) {
+	authProvider := new(MockAuthProvider)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOneByID", "user-1", []string{}).
+		Return(factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(), nil)
+	authProvider.On("RequireOTP", mock.Anything, "admin-token", "kc-1").Return(nil)
+	authProvider.On("GetMFAStatus", mock.Anything, "admin-token", "kc-1").Return(&auth.MFAStatus{Required: true}, nil)
+
+	service := ProvideMFAService(authProvider, newMockTokenProvider(), userRepo)
+	status, err := service.Require(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.True(t, status.Required)
+	assert.False(t, status.Configured)
+	authProvider.AssertExpectations(t)
+}
+
+func TestMFAService_Enroll(t *testing.T) {
+	tests := []struct {
+		name          string
+		user          *models.User
+		setupMocks    func(authProvider *MockAuthProvider)
+		expectedError errors.ErrorType
+	}{
+		{
+			name: "enrollment email sent",
+			user: factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetMFAStatus", mock.Anything, "admin-token", "kc-1").Return(&auth.MFAStatus{}, nil)
+				authProvider.On("SendOTPEnrollmentEmail", mock.Anything, "admin-token", "kc-1").Return(nil)
+			},
+		},
+		{
+			name: "authenticator already configured",
+			user: factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetMFAStatus", mock.Anything, "admin-token", "kc-1").Return(&auth.MFAStatus{Configured: true}, nil)
+			},
+			expectedError: errors.ErrorTypeConflict,
+		},
+		{
+			name:          "user without Keycloak user",
+			user:          factory.User().WithID("user-1").WithKeycloakID("").Build(),
+			setupMocks:    func(authProvider *MockAuthProvider) {},
+			expectedError: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetOneByID", "user-1", []string{}).Return(tt.user, nil)
+			tt.setupMocks(authProvider)
+
+			service := ProvideMFAService(authProvider, newMockTokenProvider(), userRepo)
+			_, err := service.Enroll(context.Background(), "user-1")
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+			}
+			authProvider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMFAService_Reset(t *testing.T) {
+	authProvider := new(MockAuthProvider)
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOneByID", "user-1", []string{}).
+		Return(factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(), nil)
+	authProvider.On("ResetOTP", mock.Anything, "admin-token", "kc-1").Return(nil)
+
+	service := ProvideMFAService(authProvider, newMockTokenProvider(), userRepo)
+	status, err := service.Reset(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.False(t, status.Configured)
+	assert.False(t, status.Required)
+	authProvider.AssertExpectations(t)
+}
+
+func TestMFAService_UserStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *models.User
+		setupMocks func(authProvider *MockAuthProvider)
+		expected   bool
+	}{
+		{
+			name: "status of the Keycloak user",
+			user: factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetMFAStatus", mock.Anything, "admin-token", "kc-1").Return(&auth.MFAStatus{Configured: true}, nil)
+			},
+			expected: true,
+		},
+		{
+			name: "Keycloak failure",
+			user: factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetMFAStatus", mock.Anything, "admin-token", "kc-1").
+					Return(nil, errors.ExternalServiceError("Failed to get MFA status", assert.AnError))
+			},
+		},
+		{
+			name:       "user without Keycloak user",
+			user:       factory.User().WithID("user-1").WithKeycloakID("").Build(),
+			setupMocks: func(authProvider *MockAuthProvider) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			tt.setupMocks(authProvider)
+
+			service := ProvideMFAService(authProvider, newMockTokenProvider(), new(MockUserRepository))
+			status := service.UserStatus(context.Background(), tt.user)
+
+			if tt.expected {
+				require.NotNil(t, status)
+				assert.True(t, status.Configured)
+			} else {
+				assert.Nil(t, status)
+			}
+			authProvider.AssertExpectations(t)
+		})
+	}
+}
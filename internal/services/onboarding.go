@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type OnboardingService interface {
+	// ResolveCompany returns the company of the Keycloak organization of the caller
+	ResolveCompany(ctx context.Context, organizationID string) (*models.Company, error)
+	Get(ctx context.Context, companyID string) (*dtos.OnboardingResponse, error)
+	// SetStep overrides the computed state of a step
+	SetStep(ctx context.Context, companyID string, step string, completed bool, updatedBy string) (*dtos.OnboardingResponse, error)
+	// ResetStep removes the override of a step, which is computed again
+	ResetStep(ctx context.Context, companyID string, step string) (*dtos.OnboardingResponse, error)
+}
+
+// onboardingCheck computes whether a step is completed from the data of a company
+type onboardingCheck func(companyID string) (bool, error)
+
+// onboardingService computes the onboarding checklist of the tenants
+type onboardingService struct {
+	onboardingRepo repositories.OnboardingRepository
+	companyRepo    repositories.CompanyRepository
+
+	// checks of the steps; a step without a check is only completed manually
+	checks map[string]onboardingCheck
+}
+
+// ProvideOnboardingService creates a new onboarding service
+func ProvideOnboardingService(
+	onboardingRepo repositories.OnboardingRepository,
+	companyRepo repositories.CompanyRepository,
+) OnboardingService {
+	s := &onboardingService{
+		onboardingRepo: onboardingRepo,
+		companyRepo:    companyRepo,
+	}
+	s.checks = map[string]onboardingCheck{
+		constants.OnboardingStepInviteUsers: func(companyID string) (bool, error) {
+			members, err := onboardingRepo.CountMembers(companyID)
+			return members >= constants.OnboardingMinMembers, err
+		},
+		constants.OnboardingStepConfigureWebhooks: onboardingRepo.HasActiveWebhook,
+		constants.OnboardingStepAddPaymentMethod:  onboardingRepo.HasPaymentCustomer,
+	}
+	return s
+}
+
+func (s *onboardingService) ResolveCompany(ctx context.Context, organizationID string) (*models.Company, error) {
+	return s.companyRepo.GetByKeycloakID(organizationID)
+}
+
+func (s *onboardingService) Get(ctx context.Context, companyID string) (*dtos.OnboardingResponse, error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_onboarding").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	overrides, err := s.onboardingRepo.GetOverrides(companyID)
+	if err != nil {
+		return nil, err
+	}
+	overridesByStep := make(map[string]models.OnboardingOverride, len(overrides))
+	for _, override := range overrides {
+		overridesByStep[override.Step] = override
+	}
+
+	onboarding := &dtos.OnboardingResponse{
+		CompanyID: companyID,
+		Total:     len(constants.OnboardingSteps),
+		Steps:     make([]dtos.OnboardingStepResponse, 0, len(constants.OnboardingSteps)),
+	}
+	for _, step := range constants.OnboardingSteps {
+		state := dtos.OnboardingStepResponse{Key: step, Source: constants.OnboardingSourceComputed}
+		if override, ok := overridesByStep[step]; ok {
+			state.Completed = override.Completed
+			state.Source = constants.OnboardingSourceManual
+			state.OverriddenBy = override.UpdatedBy
+			state.OverriddenAt = &override.UpdatedAt
+		} else if check := s.checks[step]; check != nil {
+			completed, err := check(companyID)
+			if err != nil {
+				return nil, err
+			}
+			state.Completed = completed
+		}
+
+		if state.Completed {
+			onboarding.Completed++
+		}
+		onboarding.Steps = append(onboarding.Steps, state)
+	}
+	onboarding.Progress = onboarding.Completed * 100 / onboarding.Total
+	onboarding.Done = onboarding.Completed == onboarding.Total
+
+	return onboarding, nil
+}
+
+func (s *onboardingService) SetStep(ctx context.Context, companyID string, step string, completed bool, updatedBy string) (*dtos.OnboardingResponse, error) {
+	if err := validateOnboardingStep(step, "set_onboarding_step"); err != nil {
+		return nil, err
+	}
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("set_onboarding_step").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	override := &models.OnboardingOverride{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: companyID,
+		Step:      step,
+		Completed: completed,
+		UpdatedBy: updatedBy,
+	}
+	if err := s.onboardingRepo.SaveOverride(override); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Onboarding step overridden",
+		zap.String("company_id", companyID),
+		zap.String("step", step),
+		zap.Bool("completed", completed),
+		zap.String("updated_by", updatedBy),
+	)
+
+	return s.Get(ctx, companyID)
+}
+
+func (s *onboardingService) ResetStep(ctx context.Context, companyID string, step string) (*dtos.OnboardingResponse, error) {
+	if err := validateOnboardingStep(step, "reset_onboarding_step"); err != nil {
+		return nil, err
+	}
+
+	if err := s.onboardingRepo.DeleteOverride(companyID, step); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, companyID)
+}
+
+func validateOnboardingStep(step string, operation string) error {
+	if !slices.Contains(constants.OnboardingSteps, step) {
+		return errors.ValidationError("Unknown onboarding step", nil).
+			WithOperation(operation).
+			WithResource("onboarding_override").
+			WithContext("step", step)
+	}
+	return nil
+}
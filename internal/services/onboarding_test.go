@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOnboardingRepository struct {
+	mock.Mock
+}
+
+func (m *MockOnboardingRepository) GetOverrides(companyID string) ([]models.OnboardingOverride, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OnboardingOverride), args.Error(1)
+}
+
+func (m *MockOnboardingRepository) SaveOverride(override *models.OnboardingOverride) error {
+	args := m.Called(override)
+	return args.Error(0)
+}
+
+func (m *MockOnboardingRepository) DeleteOverride(companyID string, step string) error {
+	args := m.Called(companyID, step)
+	return args.Error(0)
+}
+
+func (m *MockOnboardingRepository) CountMembers(companyID string) (int64, error) {
+	args := m.Called(companyID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOnboardingRepository) HasPaymentCustomer(companyID string) (bool, error) {
+	args := m.Called(companyID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOnboardingRepository) HasActiveWebhook(companyID string) (bool, error) {
+	args := m.Called(companyID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestOnboardingService_Get(t *testing.T) {
+	overriddenAt := time.Now()
+
+	tests := []struct {
+		name              string
+		members           int64
+		paymentCustomer   bool
+		activeWebhook     bool
+		overrides         []models.OnboardingOverride
+		expectedCompleted map[string]bool
+		expectedSources   map[string]string
+		expectedProgress  int
+		expectedDone      bool
+	}{
+		{
+			name:    "nothing set up",
+			members: 1,
+			expectedCompleted: map[string]bool{
+				constants.OnboardingStepInviteUsers:       false,
+				constants.OnboardingStepConfigureWebhooks: false,
+				constants.OnboardingStepAddPaymentMethod:  false,
+			},
+			expectedProgress: 0,
+		},
+		{
+			name:            "computed from the company data",
+			members:         3,
+			paymentCustomer: true,
+			expectedCompleted: map[string]bool{
+				constants.OnboardingStepInviteUsers:       true,
+				constants.OnboardingStepConfigureWebhooks: false,
+				constants.OnboardingStepAddPaymentMethod:  true,
+			},
+			expectedProgress: 66,
+		},
+		{
+			name:            "webhook configured",
+			members:         2,
+			paymentCustomer: true,
+			activeWebhook:   true,
+			expectedCompleted: map[string]bool{
+				constants.OnboardingStepInviteUsers:       true,
+				constants.OnboardingStepConfigureWebhooks: true,
+				constants.OnboardingStepAddPaymentMethod:  true,
+			},
+			expectedProgress: 100,
+			expectedDone:     true,
+		},
+		{
+			name:            "overrides take precedence",
+			members:         3,
+			paymentCustomer: true,
+			overrides: []models.OnboardingOverride{
+				{Step: constants.OnboardingStepConfigureWebhooks, Completed: true, UpdatedBy: "user-1", BaseModel: models.BaseModel{UpdatedAt: overriddenAt}},
+				{Step: constants.OnboardingStepAddPaymentMethod, Completed: false, UpdatedBy: "user-1", BaseModel: models.BaseModel{UpdatedAt: overriddenAt}},
+			},
+			expectedCompleted: map[string]bool{
+				constants.OnboardingStepInviteUsers:       true,
+				constants.OnboardingStepConfigureWebhooks: true,
+				constants.OnboardingStepAddPaymentMethod:  false,
+			},
+			expectedSources: map[string]string{
+				constants.OnboardingStepConfigureWebhooks: constants.OnboardingSourceManual,
+				constants.OnboardingStepAddPaymentMethod:  constants.OnboardingSourceManual,
+			},
+			expectedProgress: 66,
+		},
+		{
+			name:    "all steps done",
+			members: 2,
+			overrides: []models.OnboardingOverride{
+				{Step: constants.OnboardingStepConfigureWebhooks, Completed: true},
+				{Step: constants.OnboardingStepAddPaymentMethod, Completed: true},
+			},
+			expectedCompleted: map[string]bool{
+				constants.OnboardingStepInviteUsers:       true,
+				constants.OnboardingStepConfigureWebhooks: true,
+				constants.OnboardingStepAddPaymentMethod:  true,
+			},
+			expectedSources: map[string]string{
+				constants.OnboardingStepConfigureWebhooks: constants.OnboardingSourceManual,
+				constants.OnboardingStepAddPaymentMethod:  constants.OnboardingSourceManual,
+			},
+			expectedProgress: 100,
+			expectedDone:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onboardingRepo := new(MockOnboardingRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := ProvideOnboardingService(onboardingRepo, companyRepo)
+
+			companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+			onboardingRepo.On("GetOverrides", "company-1").Return(tt.overrides, nil)
+			onboardingRepo.On("CountMembers", "company-1").Return(tt.members, nil).Maybe()
+			onboardingRepo.On("HasPaymentCustomer", "company-1").Return(tt.paymentCustomer, nil).Maybe()
+			onboardingRepo.On("HasActiveWebhook", "company-1").Return(tt.activeWebhook, nil).Maybe()
+
+			onboarding, err := service.Get(context.Background(), "company-1")
+
+			require.NoError(t, err)
+			require.Len(t, onboarding.Steps, len(constants.OnboardingSteps))
+			for i, step := range onboarding.Steps {
+				assert.Equal(t, constants.OnboardingSteps[i], step.Key, "steps keep their display order")
+				assert.Equal(t, tt.expectedCompleted[step.Key], step.Completed, step.Key)
+				expectedSource := constants.OnboardingSourceComputed
+				if source, ok := tt.expectedSources[step.Key]; ok {
+					expectedSource = source
+				}
+				assert.Equal(t, expectedSource, step.Source, step.Key)
+			}
+			assert.Equal(t, 3, onboarding.Total)
+			assert.Equal(t, tt.expectedProgress, onboarding.Progress)
+			assert.Equal(t, tt.expectedDone, onboarding.Done)
+			onboardingRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOnboardingService_SetStep(t *testing.T) {
+	onboardingRepo := new(MockOnboardingRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideOnboardingService(onboardingRepo, companyRepo)
+
+	companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+	onboardingRepo.On("SaveOverride", mock.MatchedBy(func(override *models.OnboardingOverride) bool {
+		return override.CompanyID == "company-1" &&
+			override.Step == constants.OnboardingStepConfigureWebhooks &&
+			override.Completed &&
+			override.UpdatedBy == "user-1"
+	})).Return(nil)
+	onboardingRepo.On("GetOverrides", "company-1").Return([]models.OnboardingOverride{
+		{Step: constants.OnboardingStepConfigureWebhooks, Completed: true, UpdatedBy: "user-1"},
+	}, nil)
+	onboardingRepo.On("CountMembers", "company-1").Return(int64(1), nil)
+	onboardingRepo.On("HasPaymentCustomer", "company-1").Return(false, nil)
+	onboardingRepo.On("HasActiveWebhook", "company-1").Return(false, nil).Maybe()
+
+	onboarding, err := service.SetStep(context.Background(), "company-1", constants.OnboardingStepConfigureWebhooks, true, "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, onboarding.Completed)
+	onboardingRepo.AssertExpectations(t)
+
+	// Unknown steps are rejected
+	_, err = service.SetStep(context.Background(), "company-1", "unknown", true, "user-1")
+	assert.Equal(t, errors.ErrorTypeValidation, errors.GetAppError(err).Type)
+	_, err = service.ResetStep(context.Background(), "company-1", "unknown")
+	assert.Equal(t, errors.ErrorTypeValidation, errors.GetAppError(err).Type)
+	onboardingRepo.AssertNotCalled(t, "DeleteOverride", mock.Anything, mock.Anything)
+}
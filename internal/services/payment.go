@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/payment"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/stripe/stripe-go/v82"
+	"go.uber.org/zap"
+)
+
+// PaymentService refunds, captures and voids the payments through the payment adapter,
+// keeping a record of every operation and of the user who initiated it
+type PaymentService interface {
+	// Refund refunds a payment, in part when the request has an amount
+	Refund(ctx context.Context, paymentID string, request *dtos.RefundPaymentRequest, initiatedBy string) (*models.PaymentOperation, error)
+	// Capture captures an authorized payment, in part when the request has an amount
+	Capture(ctx context.Context, paymentID string, request *dtos.CapturePaymentRequest, initiatedBy string) (*models.PaymentOperation, error)
+	// Void cancels an authorized payment that was not captured
+	Void(ctx context.Context, paymentID string, request *dtos.VoidPaymentRequest, initiatedBy string) (*models.PaymentOperation, error)
+	// ListOperations returns the operations on a payment, most recent first
+	ListOperations(ctx context.Context, paymentID string) ([]models.PaymentOperation, error)
+}
+
+// paymentService implements PaymentService
+type paymentService struct {
+	operationRepo repositories.PaymentOperationRepository
+	adapter       payment.PaymentAdapter
+	cfg           *config.Config
+}
+
+// ProvidePaymentService creates a new payment service
+func ProvidePaymentService(
+	operationRepo repositories.PaymentOperationRepository,
+	adapter payment.PaymentAdapter,
+	cfg *config.Config,
+) PaymentService {
+	return &paymentService{
+		operationRepo: operationRepo,
+		adapter:       adapter,
+		cfg:           cfg,
+	}
+}
+
+func (s *paymentService) Refund(ctx context.Context, paymentID string, request *dtos.RefundPaymentRequest, initiatedBy string) (*models.PaymentOperation, error) {
+	operation := s.newOperation(constants.PaymentOperationRefund, paymentID, request.Amount, request.Note, initiatedBy)
+	operation.Reason = request.Reason
+
+	err := s.run(ctx, operation, func() error {
+		refund, err := s.adapter.Refund(ctx, paymentID, request.Amount, request.Reason)
+		if err != nil {
+			return err
+		}
+		operation.ProcessedAmount = refund.Amount
+		operation.Currency = string(refund.Currency)
+		operation.ProviderObjectID = refund.ID
+		operation.ProviderStatus = string(refund.Status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (s *paymentService) Capture(ctx context.Context, paymentID string, request *dtos.CapturePaymentRequest, initiatedBy string) (*models.PaymentOperation, error) {
+	operation := s.newOperation(constants.PaymentOperationCapture, paymentID, request.Amount, request.Note, initiatedBy)
+
+	err := s.run(ctx, operation, func() error {
+		paymentIntent, err := s.adapter.Capture(ctx, paymentID, request.Amount)
+		if err != nil {
+			return err
+		}
+		operation.ProcessedAmount = paymentIntent.AmountReceived
+		applyPaymentIntent(operation, paymentIntent)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (s *paymentService) Void(ctx context.Context, paymentID string, request *dtos.VoidPaymentRequest, initiatedBy string) (*models.PaymentOperation, error) {
+	operation := s.newOperation(constants.PaymentOperationVoid, paymentID, 0, request.Note, initiatedBy)
+
+	err := s.run(ctx, operation, func() error {
+		paymentIntent, err := s.adapter.Void(ctx, paymentID)
+		if err != nil {
+			return err
+		}
+		applyPaymentIntent(operation, paymentIntent)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (s *paymentService) ListOperations(ctx context.Context, paymentID string) ([]models.PaymentOperation, error) {
+	return s.operationRepo.GetByPaymentID(paymentID)
+}
+
+// newOperation returns the record of an operation on a payment; an amount that is not
+// positive is the whole payment
+func (s *paymentService) newOperation(operationType string, paymentID string, amount int64, note string, initiatedBy string) *models.PaymentOperation {
+	operation := &models.PaymentOperation{
+		BaseModel:   models.NewBaseModel(),
+		Operation:   operationType,
+		Provider:    s.cfg.PaymentProvider,
+		PaymentID:   paymentID,
+		Note:        note,
+		Status:      constants.PaymentOperationStatusStarted,
+		InitiatedBy: initiatedBy,
+	}
+	operation.CreatedAt = time.Now().UTC()
+	if amount > 0 {
+		operation.Amount = &amount
+	}
+	return operation
+}
+
+// run records an operation as started, calls the provider and records its outcome. The
+// provider is not called unless the operation is on record.
+func (s *paymentService) run(ctx context.Context, operation *models.PaymentOperation, call func() error) error {
+	if err := s.operationRepo.Create(operation); err != nil {
+		return err
+	}
+
+	callErr := call()
+
+	now := time.Now().UTC()
+	operation.CompletedAt = &now
+	operation.Status = constants.PaymentOperationStatusSucceeded
+	if callErr != nil {
+		message := callErr.Error()
+		var stripeErr *stripe.Error
+		if stderrors.As(callErr, &stripeErr) && stripeErr.Msg != "" {
+			message = stripeErr.Msg
+		}
+		operation.Status = constants.PaymentOperationStatusFailed
+		operation.Error = &message
+	}
+
+	// The money moved either way, the record stays started if it cannot be completed
+	if err := s.operationRepo.Complete(operation); err != nil {
+		logger.Log.Error("Failed to complete payment operation record",
+			zap.String("payment_operation_id", operation.ID),
+			zap.String("payment_id", operation.PaymentID),
+			zap.String("status", operation.Status),
+			zap.Error(err),
+		)
+	}
+
+	fields := []zap.Field{
+		zap.String("operation", operation.Operation),
+		zap.String("payment_id", operation.PaymentID),
+		zap.Int64("processed_amount", operation.ProcessedAmount),
+		zap.String("initiated_by", operation.InitiatedBy),
+	}
+	if callErr != nil {
+		logger.Log.Warn("Payment operation failed", append(fields, zap.Error(callErr))...)
+		return s.providerError(operation, callErr)
+	}
+	logger.Log.Info("Payment operation succeeded", fields...)
+	return nil
+}
+
+// providerError returns the errors of the requests the provider refused, such as a
+// refund over the amount of the payment, as validation errors, and the others as errors
+// of the provider
+func (s *paymentService) providerError(operation *models.PaymentOperation, err error) error {
+	var appErr *errors.AppError
+	var stripeErr *stripe.Error
+	switch {
+	case stderrors.As(err, &stripeErr) && stripeErr.HTTPStatusCode == http.StatusNotFound:
+		appErr = errors.NotFoundError("Payment", err)
+	case stderrors.As(err, &stripeErr) && stripeErr.HTTPStatusCode >= 400 && stripeErr.HTTPStatusCode < 500 &&
+		stripeErr.HTTPStatusCode != http.StatusTooManyRequests:
+		appErr = errors.ValidationError(stripeErr.Msg, err)
+	default:
+		appErr = errors.ExternalServiceError("Failed to "+operation.Operation+" payment", err)
+	}
+	return appErr.
+		WithProvider(s.cfg.PaymentProvider).
+		WithOperation(operation.Operation+"_payment").
+		WithResource("payment").
+		WithContext("payment_id", operation.PaymentID)
+}
+
+// applyPaymentIntent copies the outcome of a capture or void of a payment intent
+func applyPaymentIntent(operation *models.PaymentOperation, paymentIntent *stripe.PaymentIntent) {
+	operation.Currency = string(paymentIntent.Currency)
+	operation.ProviderObjectID = paymentIntent.ID
+	operation.ProviderStatus = string(paymentIntent.Status)
+}
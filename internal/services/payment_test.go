@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v82"
+)
+
+type MockPaymentOperationRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentOperationRepository) Create(operation *models.PaymentOperation) error {
+	args := m.Called(operation)
+	return args.Error(0)
+}
+
+func (m *MockPaymentOperationRepository) Complete(operation *models.PaymentOperation) error {
+	args := m.Called(operation)
+	return args.Error(0)
+}
+
+func (m *MockPaymentOperationRepository) GetByPaymentID(paymentID string) ([]models.PaymentOperation, error) {
+	args := m.Called(paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PaymentOperation), args.Error(1)
+}
+
+func newTestPaymentService(operationRepo *MockPaymentOperationRepository, adapter *MockPaymentAdapter) *paymentService {
+	return &paymentService{
+		operationRepo: operationRepo,
+		adapter:       adapter,
+		cfg:           &config.Config{PaymentProvider: constants.PaymentProviderStripe},
+	}
+}
+
+func TestPaymentService_Refund(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         dtos.RefundPaymentRequest
+		createErr       error
+		refund          *stripe.Refund
+		refundErr       error
+		expectedAmount  *int64
+		expectedStatus  string
+		expectedMessage string
+		expectedError   errors.ErrorType
+	}{
+		{
+			name:           "partial refund",
+			request:        dtos.RefundPaymentRequest{Amount: 1500, Reason: "requested_by_customer", Note: "Order 1042 returned"},
+			refund:         &stripe.Refund{ID: "re_1", Amount: 1500, Currency: stripe.CurrencyUSD, Status: stripe.RefundStatusSucceeded},
+			expectedAmount: int64Ptr(1500),
+			expectedStatus: constants.PaymentOperationStatusSucceeded,
+		},
+		{
+			name:           "refund of the rest of the payment",
+			refund:         &stripe.Refund{ID: "re_1", Amount: 4200, Currency: stripe.CurrencyUSD, Status: stripe.RefundStatusPending},
+			expectedStatus: constants.PaymentOperationStatusSucceeded,
+		},
+		{
+			name:            "refund over the amount of the payment",
+			request:         dtos.RefundPaymentRequest{Amount: 999999},
+			refundErr:       &stripe.Error{HTTPStatusCode: http.StatusBadRequest, Msg: "Refund amount is greater than unrefunded amount on charge"},
+			expectedAmount:  int64Ptr(999999),
+			expectedStatus:  constants.PaymentOperationStatusFailed,
+			expectedMessage: "Refund amount is greater than unrefunded amount on charge",
+			expectedError:   errors.ErrorTypeValidation,
+		},
+		{
+			name:            "unknown payment",
+			refundErr:       &stripe.Error{HTTPStatusCode: http.StatusNotFound, Msg: "No such payment_intent: 'pi_1'"},
+			expectedStatus:  constants.PaymentOperationStatusFailed,
+			expectedMessage: "No such payment_intent: 'pi_1'",
+			expectedError:   errors.ErrorTypeNotFound,
+		},
+		{
+			name:            "provider unreachable",
+			refundErr:       stderrors.New("connection reset by peer"),
+			expectedStatus:  constants.PaymentOperationStatusFailed,
+			expectedMessage: "connection reset by peer",
+			expectedError:   errors.ErrorTypeExternal,
+		},
+		{
+			name:          "refund not recorded",
+			createErr:     errors.DatabaseError("Failed to create payment operation record", stderrors.New("connection refused")),
+			expectedError: errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operationRepo := new(MockPaymentOperationRepository)
+			adapter := new(MockPaymentAdapter)
+			service := newTestPaymentService(operationRepo, adapter)
+
+			var recorded *models.PaymentOperation
+			operationRepo.On("Create", mock.AnythingOfType("*models.PaymentOperation")).Run(func(args mock.Arguments) {
+				recorded = args.Get(0).(*models.PaymentOperation)
+				// The operation is on record before the provider is called
+				assert.Equal(t, constants.PaymentOperationStatusStarted, recorded.Status)
+			}).Return(tt.createErr)
+			operationRepo.On("Complete", mock.AnythingOfType("*models.PaymentOperation")).Return(nil).Maybe()
+			adapter.On("Refund", "pi_1", tt.request.Amount, tt.request.Reason).Return(tt.refund, tt.refundErr).Maybe()
+
+			operation, err := service.Refund(context.Background(), "pi_1", &tt.request, "admin-1")
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				appErr := errors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				assert.Nil(t, operation)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.refund.ID, operation.ProviderObjectID)
+				assert.Equal(t, tt.refund.Amount, operation.ProcessedAmount)
+				assert.Equal(t, "usd", operation.Currency)
+			}
+			if tt.createErr != nil {
+				adapter.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything, mock.Anything)
+				operationRepo.AssertNotCalled(t, "Complete", mock.Anything)
+				return
+			}
+
+			operationRepo.AssertCalled(t, "Complete", recorded)
+			assert.Equal(t, constants.PaymentOperationRefund, recorded.Operation)
+			assert.Equal(t, constants.PaymentProviderStripe, recorded.Provider)
+			assert.Equal(t, "pi_1", recorded.PaymentID)
+			assert.Equal(t, "admin-1", recorded.InitiatedBy)
+			assert.Equal(t, tt.request.Reason, recorded.Reason)
+			assert.Equal(t, tt.request.Note, recorded.Note)
+			assert.Equal(t, tt.expectedAmount, recorded.Amount)
+			assert.Equal(t, tt.expectedStatus, recorded.Status)
+			assert.NotNil(t, recorded.CompletedAt)
+			if tt.expectedMessage != "" {
+				require.NotNil(t, recorded.Error)
+				assert.Equal(t, tt.expectedMessage, *recorded.Error)
+			} else {
+				assert.Nil(t, recorded.Error)
+			}
+		})
+	}
+}
+
+func TestPaymentService_Capture(t *testing.T) {
+	operationRepo := new(MockPaymentOperationRepository)
+	adapter := new(MockPaymentAdapter)
+	service := newTestPaymentService(operationRepo, adapter)
+
+	operationRepo.On("Create", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+	operationRepo.On("Complete", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+	adapter.On("Capture", "pi_1", int64(3000)).Return(&stripe.PaymentIntent{
+		ID:             "pi_1",
+		AmountReceived: 3000,
+		Currency:       stripe.CurrencyEUR,
+		Status:         stripe.PaymentIntentStatusSucceeded,
+	}, nil)
+
+	operation, err := service.Capture(context.Background(), "pi_1", &dtos.CapturePaymentRequest{Amount: 3000}, "admin-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, constants.PaymentOperationCapture, operation.Operation)
+	assert.Equal(t, int64Ptr(3000), operation.Amount)
+	assert.Equal(t, int64(3000), operation.ProcessedAmount)
+	assert.Equal(t, "eur", operation.Currency)
+	assert.Equal(t, "succeeded", operation.ProviderStatus)
+	assert.Equal(t, constants.PaymentOperationStatusSucceeded, operation.Status)
+	assert.Equal(t, "admin-1", operation.InitiatedBy)
+}
+
+func TestPaymentService_Void(t *testing.T) {
+	t.Run("authorization released", func(t *testing.T) {
+		operationRepo := new(MockPaymentOperationRepository)
+		adapter := new(MockPaymentAdapter)
+		service := newTestPaymentService(operationRepo, adapter)
+
+		operationRepo.On("Create", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+		operationRepo.On("Complete", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+		adapter.On("Void", "pi_1").Return(&stripe.PaymentIntent{ID: "pi_1", Status: stripe.PaymentIntentStatusCanceled}, nil)
+
+		operation, err := service.Void(context.Background(), "pi_1", &dtos.VoidPaymentRequest{Note: "Order canceled"}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, constants.PaymentOperationVoid, operation.Operation)
+		assert.Nil(t, operation.Amount)
+		assert.Equal(t, "canceled", operation.ProviderStatus)
+		assert.Equal(t, "Order canceled", operation.Note)
+	})
+
+	t.Run("outcome not recorded", func(t *testing.T) {
+		operationRepo := new(MockPaymentOperationRepository)
+		adapter := new(MockPaymentAdapter)
+		service := newTestPaymentService(operationRepo, adapter)
+
+		operationRepo.On("Create", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+		operationRepo.On("Complete", mock.AnythingOfType("*models.PaymentOperation")).Return(errors.DatabaseError("Failed to complete payment operation record", stderrors.New("connection refused")))
+		adapter.On("Void", "pi_1").Return(&stripe.PaymentIntent{ID: "pi_1", Status: stripe.PaymentIntentStatusCanceled}, nil)
+
+		// The payment is voided at the provider, so the void still succeeds
+		operation, err := service.Void(context.Background(), "pi_1", &dtos.VoidPaymentRequest{}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, constants.PaymentOperationStatusSucceeded, operation.Status)
+	})
+
+	t.Run("payment already captured", func(t *testing.T) {
+		operationRepo := new(MockPaymentOperationRepository)
+		adapter := new(MockPaymentAdapter)
+		service := newTestPaymentService(operationRepo, adapter)
+
+		operationRepo.On("Create", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+		operationRepo.On("Complete", mock.AnythingOfType("*models.PaymentOperation")).Return(nil)
+		adapter.On("Void", "pi_1").Return(nil, &stripe.Error{HTTPStatusCode: http.StatusBadRequest, Msg: "This PaymentIntent could not be canceled"})
+
+		operation, err := service.Void(context.Background(), "pi_1", &dtos.VoidPaymentRequest{}, "admin-1")
+
+		require.Error(t, err)
+		assert.Nil(t, operation)
+		assert.Equal(t, errors.ErrorTypeValidation, errors.GetAppError(err).Type)
+	})
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/payment"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// PaymentWebhookService receives the events of the payment providers and dispatches them
+// to the services reacting to them through the message broker
+type PaymentWebhookService interface {
+	// HandleEvent verifies the signature of an event, records it and publishes it to
+	// payment.<provider>.<type>. The deliveries of an event already published are
+	// acknowledged without publishing it again.
+	HandleEvent(ctx context.Context, payload []byte, header http.Header) error
+}
+
+// paymentWebhookService implements PaymentWebhookService
+type paymentWebhookService struct {
+	verifier payment.WebhookVerifier
+	events   repositories.PaymentEventRepository
+	broker   messaging.Publisher
+}
+
+// ProvidePaymentWebhookService creates a new payment webhook service
+func ProvidePaymentWebhookService(
+	verifier payment.WebhookVerifier,
+	events repositories.PaymentEventRepository,
+	broker messaging.Publisher,
+) PaymentWebhookService {
+	return &paymentWebhookService{
+		verifier: verifier,
+		events:   events,
+		broker:   broker,
+	}
+}
+
+func (s *paymentWebhookService) HandleEvent(ctx context.Context, payload []byte, header http.Header) error {
+	event, err := s.verifier.Verify(ctx, payload, header)
+	if err != nil {
+		return err
+	}
+
+	recorded, err := s.events.Record(&models.PaymentEvent{
+		Provider: event.Provider,
+		EventID:  event.ID,
+		Type:     event.Type,
+		Payload:  json.RawMessage(payload),
+	})
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		logger.Log.Info("Payment event already received",
+			zap.String("provider", event.Provider),
+			zap.String("event_id", event.ID),
+		)
+		return nil
+	}
+
+	body, err := json.Marshal(dtos.PaymentEvent{
+		ID:        event.ID,
+		Provider:  event.Provider,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Data,
+	})
+	topic := constants.MessagingTopicPaymentPrefix + event.Provider + "." + strings.ToLower(event.Type)
+	if err == nil {
+		err = s.broker.Publish(ctx, topic, messaging.Message{
+			ID:      event.ID,
+			Body:    body,
+			Headers: map[string]string{"provider": event.Provider, "type": event.Type},
+		})
+	}
+	if err != nil {
+		// Forget the event, so that the provider delivers it again
+		if deleteErr := s.events.Delete(event.Provider, event.ID); deleteErr != nil {
+			logger.Log.Error("Failed to forget undispatched payment event",
+				zap.String("provider", event.Provider),
+				zap.String("event_id", event.ID),
+				zap.Error(deleteErr),
+			)
+		}
+		return errors.ExternalServiceError("Failed to dispatch payment event", err).
+			WithProvider(event.Provider).
+			WithOperation("dispatch_payment_event").
+			WithResource("payment").
+			WithContext("event_id", event.ID).
+			WithContext("topic", topic)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/payment"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookVerifier is a mock implementation of payment.WebhookVerifier
+type MockWebhookVerifier struct {
+	mock.Mock
+}
+
+func (m *MockWebhookVerifier) Verify(ctx context.Context, payload []byte, header http.Header) (*payment.WebhookEvent, error) {
+	args := m.Called(payload, header)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payment.WebhookEvent), args.Error(1)
+}
+
+// MockPaymentEventRepository is a mock implementation of repositories.PaymentEventRepository
+type MockPaymentEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentEventRepository) Record(event *models.PaymentEvent) (bool, error) {
+	args := m.Called(event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentEventRepository) Delete(provider string, eventID string) error {
+	return m.Called(provider, eventID).Error(0)
+}
+
+func TestPaymentWebhookService_HandleEvent(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"invoice.paid"}`)
+	header := http.Header{constants.StripeSignatureHeader: {"t=1,v1=abc"}}
+	event := &payment.WebhookEvent{
+		Provider:  constants.PaymentProviderStripe,
+		ID:        "evt_1",
+		Type:      "invoice.paid",
+		CreatedAt: time.Unix(1760000000, 0).UTC(),
+		Data:      json.RawMessage(`{"id":"in_1"}`),
+	}
+
+	tests := []struct {
+		name          string
+		verifyErr     error
+		recorded      bool
+		publishErr    error
+		expectPublish bool
+		expectForget  bool
+		expectedError bool
+	}{
+		{
+			name:          "new event published to its topic",
+			recorded:      true,
+			expectPublish: true,
+		},
+		{
+			name:     "duplicate delivery acknowledged without publishing",
+			recorded: false,
+		},
+		{
+			name:          "invalid signature",
+			verifyErr:     errors.UnauthorizedError("Invalid Stripe event signature", nil),
+			expectedError: true,
+		},
+		{
+			name:          "publish failure forgets the event",
+			recorded:      true,
+			publishErr:    assert.AnError,
+			expectPublish: true,
+			expectForget:  true,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := new(MockWebhookVerifier)
+			if tt.verifyErr != nil {
+				verifier.On("Verify", payload, header).Return(nil, tt.verifyErr)
+			} else {
+				verifier.On("Verify", payload, header).Return(event, nil)
+			}
+			events := new(MockPaymentEventRepository)
+			if tt.verifyErr == nil {
+				events.On("Record", mock.MatchedBy(func(recorded *models.PaymentEvent) bool {
+					return recorded.Provider == constants.PaymentProviderStripe && recorded.EventID == "evt_1" &&
+						recorded.Type == "invoice.paid" && string(recorded.Payload) == string(payload)
+				})).Return(tt.recorded, nil)
+			}
+			if tt.expectForget {
+				events.On("Delete", constants.PaymentProviderStripe, "evt_1").Return(nil)
+			}
+			broker := new(MockMessagePublisher)
+			if tt.expectPublish {
+				broker.On("Publish", mock.Anything, "payment.stripe.invoice.paid", mock.MatchedBy(func(msg messaging.Message) bool {
+					var published dtos.PaymentEvent
+					return json.Unmarshal(msg.Body, &published) == nil && msg.ID == "evt_1" &&
+						published.Type == "invoice.paid" && string(published.Data) == `{"id":"in_1"}`
+				})).Return(tt.publishErr)
+			}
+			service := ProvidePaymentWebhookService(verifier, events, broker)
+
+			err := service.HandleEvent(context.Background(), payload, header)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			verifier.AssertExpectations(t)
+			events.AssertExpectations(t)
+			broker.AssertExpectations(t)
+		})
+	}
+}
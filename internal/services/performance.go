@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+)
+
+// PerformanceService reports the response times and errors of the requests of the
+// tenants, recorded by the PerformanceRecorder
+type PerformanceService interface {
+	// ResolveCompany returns the company of the Keycloak organization of the caller
+	ResolveCompany(ctx context.Context, organizationID string) (*models.Company, error)
+	// Get returns the performance of the company over the last hours, the current one
+	// included
+	Get(ctx context.Context, companyID string, hours int) (*dtos.TenantPerformanceResponse, error)
+	// Purge deletes the metrics older than PERFORMANCE_RETENTION_DAYS
+	Purge(ctx context.Context) (int64, error)
+}
+
+// performanceService implements PerformanceService
+type performanceService struct {
+	cfg             *config.Config
+	performanceRepo repositories.PerformanceRepository
+	companyRepo     repositories.CompanyRepository
+}
+
+// ProvidePerformanceService creates a new performance service
+func ProvidePerformanceService(
+	cfg *config.Config,
+	performanceRepo repositories.PerformanceRepository,
+	companyRepo repositories.CompanyRepository,
+) PerformanceService {
+	return &performanceService{
+		cfg:             cfg,
+		performanceRepo: performanceRepo,
+		companyRepo:     companyRepo,
+	}
+}
+
+func (s *performanceService) ResolveCompany(ctx context.Context, organizationID string) (*models.Company, error) {
+	return s.companyRepo.GetByKeycloakID(organizationID)
+}
+
+func (s *performanceService) Get(ctx context.Context, companyID string, hours int) (*dtos.TenantPerformanceResponse, error) {
+	if hours <= 0 {
+		hours = constants.PerformanceDefaultHours
+	}
+	if hours > constants.PerformanceMaxHours {
+		hours = constants.PerformanceMaxHours
+	}
+
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_tenant_performance").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	// The requests authenticated by a token are recorded under the organization of the
+	// company, the ones authenticated by an API key under the company itself
+	tenants := []string{company.ID}
+	if company.KeycloakID != "" {
+		tenants = append(tenants, company.KeycloakID)
+	}
+	since := time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+	metrics, err := s.performanceRepo.Get(tenants, since)
+	if err != nil {
+		return nil, err
+	}
+
+	overall := newPerformanceAggregate()
+	routes := make(map[string]*performanceAggregate)
+	hourly := make(map[time.Time]*performanceAggregate)
+	for _, metric := range metrics {
+		overall.add(metric)
+		if routes[metric.Route] == nil {
+			routes[metric.Route] = newPerformanceAggregate()
+		}
+		routes[metric.Route].add(metric)
+		hour := metric.Hour.UTC()
+		if hourly[hour] == nil {
+			hourly[hour] = newPerformanceAggregate()
+		}
+		hourly[hour].add(metric)
+	}
+
+	// Routes and hours without requests are left out; the lists are never null
+	performance := &dtos.TenantPerformanceResponse{
+		CompanyID: company.ID,
+		Hours:     hours,
+		Overall:   overall.stats(),
+		Histogram: overall.histogram(),
+		Routes:    make([]dtos.RoutePerformance, 0, len(routes)),
+		Hourly:    make([]dtos.HourlyPerformance, 0, len(hourly)),
+	}
+	for route, aggregate := range routes {
+		performance.Routes = append(performance.Routes, dtos.RoutePerformance{Route: route, PerformanceStats: aggregate.stats()})
+	}
+	sort.Slice(performance.Routes, func(i, j int) bool {
+		if performance.Routes[i].Requests != performance.Routes[j].Requests {
+			return performance.Routes[i].Requests > performance.Routes[j].Requests
+		}
+		return performance.Routes[i].Route < performance.Routes[j].Route
+	})
+	for hour, aggregate := range hourly {
+		performance.Hourly = append(performance.Hourly, dtos.HourlyPerformance{Hour: hour, PerformanceStats: aggregate.stats()})
+	}
+	sort.Slice(performance.Hourly, func(i, j int) bool {
+		return performance.Hourly[i].Hour.Before(performance.Hourly[j].Hour)
+	})
+
+	return performance, nil
+}
+
+func (s *performanceService) Purge(ctx context.Context) (int64, error) {
+	before := time.Now().UTC().Truncate(time.Hour).AddDate(0, 0, -s.cfg.PerformanceRetentionDays)
+	return s.performanceRepo.Purge(before)
+}
+
+// performanceAggregate sums metrics into a response time histogram
+type performanceAggregate struct {
+	requests     int64
+	clientErrors int64
+	serverErrors int64
+	durationMs   float64
+	buckets      map[int64]int64
+}
+
+func newPerformanceAggregate() *performanceAggregate {
+	return &performanceAggregate{buckets: make(map[int64]int64)}
+}
+
+func (a *performanceAggregate) add(metric models.TenantRequestMetric) {
+	a.requests += metric.Requests
+	a.durationMs += metric.DurationMs
+	a.buckets[metric.BucketMs] += metric.Requests
+	switch metric.StatusClass {
+	case constants.PerformanceStatusClientError:
+		a.clientErrors += metric.Requests
+	case constants.PerformanceStatusServerError:
+		a.serverErrors += metric.Requests
+	}
+}
+
+func (a *performanceAggregate) stats() dtos.PerformanceStats {
+	stats := dtos.PerformanceStats{
+		Requests:     a.requests,
+		ClientErrors: a.clientErrors,
+		ServerErrors: a.serverErrors,
+	}
+	if a.requests == 0 {
+		return stats
+	}
+
+	stats.ErrorRate = roundDecimals(float64(a.serverErrors)/float64(a.requests), 4)
+	stats.AverageMs = roundDecimals(a.durationMs/float64(a.requests), 2)
+	stats.P50Ms = a.percentile(0.50)
+	stats.P95Ms = a.percentile(0.95)
+	stats.P99Ms = a.percentile(0.99)
+	return stats
+}
+
+// histogram returns every bucket, the empty ones included, the unbounded one last
+func (a *performanceAggregate) histogram() []dtos.PerformanceBucket {
+	histogram := make([]dtos.PerformanceBucket, 0, len(constants.PerformanceBucketsMs)+1)
+	for _, bound := range constants.PerformanceBucketsMs {
+		upperBound := bound
+		histogram = append(histogram, dtos.PerformanceBucket{UpperBoundMs: &upperBound, Requests: a.buckets[bound]})
+	}
+	return append(histogram, dtos.PerformanceBucket{Requests: a.buckets[constants.PerformanceOverflowBucket]})
+}
+
+// percentile estimates the response time under which the share q of the requests
+// completed, interpolating linearly within its bucket. Percentiles within the unbounded
+// bucket are reported as the last bound.
+func (a *performanceAggregate) percentile(q float64) float64 {
+	rank := q * float64(a.requests)
+	var cumulative int64
+	lower := int64(0)
+	for _, bound := range constants.PerformanceBucketsMs {
+		count := a.buckets[bound]
+		if count > 0 && float64(cumulative+count) >= rank {
+			return roundDecimals(float64(lower)+float64(bound-lower)*(rank-float64(cumulative))/float64(count), 2)
+		}
+		cumulative += count
+		lower = bound
+	}
+	return float64(lower)
+}
+
+// roundDecimals rounds x to the given number of decimals
+func roundDecimals(x float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(x*scale) / scale
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// tenantRequestKey identifies an hourly counter of the requests of a tenant to a route
+// with a status class and a response time bucket
+type tenantRequestKey struct {
+	tenant      string
+	hour        time.Time
+	route       string
+	statusClass string
+	bucketMs    int64
+}
+
+// tenantRequestCounts are the requests of a counter and the sum of their response times
+type tenantRequestCounts struct {
+	requests   int64
+	durationMs float64
+}
+
+// PerformanceRecorder aggregates the response times of the requests of the tenants in
+// memory and adds them to the database every PERFORMANCE_FLUSH_INTERVAL, so that requests
+// never wait on a write. The number of tenants and of routes per tenant tracked between
+// two flushes is bounded: the requests of the other tenants are dropped and the other
+// routes of a tenant are counted as constants.PerformanceOtherRoute. Counts that fail to
+// flush are kept for the next flush.
+type PerformanceRecorder struct {
+	repo       repositories.PerformanceRepository
+	interval   time.Duration
+	maxTenants int
+	maxRoutes  int
+
+	mu      sync.Mutex
+	metrics map[tenantRequestKey]tenantRequestCounts
+	// routes are the routes tracked per tenant since the last flush
+	routes  map[string]map[string]bool
+	dropped int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// ProvidePerformanceRecorder creates the performance recorder, started with the HTTP server
+func ProvidePerformanceRecorder(cfg *config.Config, repo repositories.PerformanceRepository) *PerformanceRecorder {
+	return &PerformanceRecorder{
+		repo:       repo,
+		interval:   cfg.PerformanceFlushInterval,
+		maxTenants: cfg.PerformanceMaxTenants,
+		maxRoutes:  cfg.PerformanceMaxRoutes,
+		metrics:    make(map[tenantRequestKey]tenantRequestCounts),
+		routes:     make(map[string]map[string]bool),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Record counts a request of the tenant to the route that ended with the status after
+// duration
+func (r *PerformanceRecorder) Record(tenant string, route string, status int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenantRoutes, ok := r.routes[tenant]
+	if !ok {
+		if len(r.routes) >= r.maxTenants {
+			r.dropped++
+			return
+		}
+		tenantRoutes = make(map[string]bool)
+		r.routes[tenant] = tenantRoutes
+	}
+	if !tenantRoutes[route] {
+		if len(tenantRoutes) >= r.maxRoutes {
+			route = constants.PerformanceOtherRoute
+		} else {
+			tenantRoutes[route] = true
+		}
+	}
+
+	durationMs := float64(duration) / float64(time.Millisecond)
+	key := tenantRequestKey{
+		tenant:      tenant,
+		hour:        time.Now().UTC().Truncate(time.Hour),
+		route:       route,
+		statusClass: performanceStatusClass(status),
+		bucketMs:    performanceBucket(durationMs),
+	}
+	counts := r.metrics[key]
+	counts.requests++
+	counts.durationMs += durationMs
+	r.metrics[key] = counts
+}
+
+// Start flushes the metrics periodically until Stop
+func (r *PerformanceRecorder) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					logger.Log.Warn("Failed to flush tenant performance metrics", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic flushes and flushes the remaining metrics. It must run before
+// the database connections are closed.
+func (r *PerformanceRecorder) Stop(ctx context.Context) error {
+	close(r.stop)
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return r.Flush()
+}
+
+// Flush adds the metrics recorded since the last flush to the database
+func (r *PerformanceRecorder) Flush() error {
+	r.mu.Lock()
+	metrics, dropped := r.metrics, r.dropped
+	r.metrics = make(map[tenantRequestKey]tenantRequestCounts)
+	r.routes = make(map[string]map[string]bool)
+	r.dropped = 0
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Log.Warn("Tenant performance metrics dropped, PERFORMANCE_MAX_TENANTS reached",
+			zap.Int64("requests", dropped),
+			zap.Int("max_tenants", r.maxTenants),
+		)
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	rows := make([]models.TenantRequestMetric, 0, len(metrics))
+	for key, counts := range metrics {
+		rows = append(rows, models.TenantRequestMetric{
+			BaseModel:   models.NewBaseModel(),
+			Tenant:      key.tenant,
+			Hour:        key.hour,
+			Route:       key.route,
+			StatusClass: key.statusClass,
+			BucketMs:    key.bucketMs,
+			Requests:    counts.requests,
+			DurationMs:  counts.durationMs,
+		})
+	}
+
+	if err := r.repo.Record(rows); err != nil {
+		r.restore(metrics)
+		return err
+	}
+
+	return nil
+}
+
+// restore merges back metrics that failed to flush
+func (r *PerformanceRecorder) restore(metrics map[tenantRequestKey]tenantRequestCounts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, counts := range metrics {
+		current := r.metrics[key]
+		current.requests += counts.requests
+		current.durationMs += counts.durationMs
+		r.metrics[key] = current
+	}
+}
+
+// performanceStatusClass returns the performance status class of an HTTP status
+func performanceStatusClass(status int) string {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return constants.PerformanceStatusServerError
+	case status >= http.StatusBadRequest:
+		return constants.PerformanceStatusClientError
+	default:
+		return constants.PerformanceStatusSuccess
+	}
+}
+
+// performanceBucket returns the histogram bucket of a response time
+func performanceBucket(durationMs float64) int64 {
+	for _, bound := range constants.PerformanceBucketsMs {
+		if durationMs <= float64(bound) {
+			return bound
+		}
+	}
+	return constants.PerformanceOverflowBucket
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPerformanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPerformanceRepository) Record(metrics []models.TenantRequestMetric) error {
+	args := m.Called(metrics)
+	return args.Error(0)
+}
+
+func (m *MockPerformanceRepository) Get(tenants []string, since time.Time) ([]models.TenantRequestMetric, error) {
+	args := m.Called(tenants, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TenantRequestMetric), args.Error(1)
+}
+
+func (m *MockPerformanceRepository) Purge(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestPerformanceService_Get(t *testing.T) {
+	hour := time.Now().UTC().Truncate(time.Hour)
+	previousHour := hour.Add(-time.Hour)
+	company := factory.Company().WithID("company-1").WithKeycloakID("org-1").Build()
+	metrics := []models.TenantRequestMetric{
+		{Hour: previousHour, Route: "GET /api/v1/companies/:id", StatusClass: constants.PerformanceStatusSuccess, BucketMs: 50, Requests: 60, DurationMs: 2400},
+		{Hour: hour, Route: "GET /api/v1/companies/:id", StatusClass: constants.PerformanceStatusSuccess, BucketMs: 100, Requests: 30, DurationMs: 2400},
+		{Hour: hour, Route: "PUT /api/v1/companies/:id", StatusClass: constants.PerformanceStatusClientError, BucketMs: 25, Requests: 8, DurationMs: 160},
+		{Hour: hour, Route: "PUT /api/v1/companies/:id", StatusClass: constants.PerformanceStatusServerError, BucketMs: constants.PerformanceOverflowBucket, Requests: 2, DurationMs: 30000},
+	}
+
+	tests := []struct {
+		name          string
+		hours         int
+		expectedHours int
+		companyErr    error
+		expectedError errors.ErrorType
+	}{
+		{name: "default hours", expectedHours: constants.PerformanceDefaultHours},
+		{name: "hours bounded", hours: 10000, expectedHours: constants.PerformanceMaxHours},
+		{name: "unknown company", companyErr: errors.DatabaseError("Failed to get company by ID", nil), expectedError: errors.ErrorTypeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			performanceRepo := new(MockPerformanceRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := ProvidePerformanceService(&config.Config{}, performanceRepo, companyRepo)
+
+			if tt.companyErr != nil {
+				companyRepo.On("GetOneByID", "company-1").Return(nil, tt.companyErr)
+			} else {
+				companyRepo.On("GetOneByID", "company-1").Return(company, nil)
+			}
+			since := hour.Add(-time.Duration(tt.expectedHours-1) * time.Hour)
+			performanceRepo.On("Get", []string{"company-1", "org-1"}, since).Return(metrics, nil).Maybe()
+
+			performance, err := service.Get(context.Background(), "company-1", tt.hours)
+
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedHours, performance.Hours)
+
+			overall := performance.Overall
+			assert.Equal(t, int64(100), overall.Requests)
+			assert.Equal(t, int64(8), overall.ClientErrors)
+			assert.Equal(t, int64(2), overall.ServerErrors)
+			assert.Equal(t, 0.02, overall.ErrorRate)
+			assert.Equal(t, 349.6, overall.AverageMs)
+			// 8 requests under 25ms, 60 under 50ms, 30 under 100ms and 2 slower than 10s
+			assert.Equal(t, 42.5, overall.P50Ms)
+			assert.Equal(t, 95.0, overall.P95Ms)
+			assert.Equal(t, 10000.0, overall.P99Ms)
+
+			require.Len(t, performance.Histogram, len(constants.PerformanceBucketsMs)+1)
+			assert.Equal(t, int64(8), performance.Histogram[1].Requests)
+			assert.Nil(t, performance.Histogram[len(constants.PerformanceBucketsMs)].UpperBoundMs)
+			assert.Equal(t, int64(2), performance.Histogram[len(constants.PerformanceBucketsMs)].Requests)
+
+			require.Len(t, performance.Routes, 2)
+			assert.Equal(t, "GET /api/v1/companies/:id", performance.Routes[0].Route)
+			assert.Equal(t, int64(90), performance.Routes[0].Requests)
+			assert.Equal(t, 0.2, performance.Routes[1].ErrorRate)
+
+			require.Len(t, performance.Hourly, 2)
+			assert.Equal(t, previousHour, performance.Hourly[0].Hour)
+			assert.Equal(t, int64(40), performance.Hourly[1].Requests)
+		})
+	}
+}
+
+func TestPerformanceRecorder_Flush(t *testing.T) {
+	performanceRepo := new(MockPerformanceRepository)
+	recorder := ProvidePerformanceRecorder(&config.Config{
+		PerformanceFlushInterval: time.Hour,
+		PerformanceMaxTenants:    2,
+		PerformanceMaxRoutes:     2,
+	}, performanceRepo)
+
+	recorder.Record("org-1", "GET /api/v1/users", 200, 20*time.Millisecond)
+	recorder.Record("org-1", "GET /api/v1/users", 200, 30*time.Millisecond)
+	recorder.Record("org-1", "GET /api/v1/users/:id", 404, 5*time.Millisecond)
+	// Past the routes of the tenant
+	recorder.Record("org-1", "DELETE /api/v1/users/:id", 500, 20*time.Second)
+	recorder.Record("org-2", "GET /api/v1/users", 200, 20*time.Millisecond)
+	// Past the tenants
+	recorder.Record("org-3", "GET /api/v1/users", 200, 20*time.Millisecond)
+
+	// A failed flush keeps the metrics for the next one
+	performanceRepo.On("Record", mock.Anything).Return(stderrors.New("connection refused")).Once()
+	require.Error(t, recorder.Flush())
+	recorder.Record("org-2", "GET /api/v1/users", 200, 20*time.Millisecond)
+
+	flushed := make(map[string]int64)
+	performanceRepo.On("Record", mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, metric := range args.Get(0).([]models.TenantRequestMetric) {
+				flushed[metric.Tenant+" "+metric.Route+" "+metric.StatusClass] += metric.Requests
+			}
+		}).
+		Return(nil).Once()
+	require.NoError(t, recorder.Flush())
+
+	assert.Equal(t, map[string]int64{
+		"org-1 GET /api/v1/users success":                            2,
+		"org-1 GET /api/v1/users/:id client_error":                   1,
+		"org-1 " + constants.PerformanceOtherRoute + " server_error": 1,
+		"org-2 GET /api/v1/users success":                            2,
+	}, flushed)
+
+	// Nothing left to flush
+	require.NoError(t, recorder.Flush())
+	performanceRepo.AssertExpectations(t)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// roleNamePattern matches the names of the roles, e.g. user-manager
+	roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,99}$`)
+	// permissionNamePattern matches the names of the permissions, <resource>:<action> with
+	// * for any action, or * alone
+	permissionNamePattern = regexp.MustCompile(`^(\*|[a-z0-9_.-]+:([a-z0-9_.-]+|\*))$`)
+)
+
+// PolicyService evaluates and manages the roles and permissions of the database RBAC, for
+// the deployments that do not model their permissions in Keycloak
+type PolicyService interface {
+	// HasPermission reports whether the roles of the user of the Keycloak ID grant the
+	// permission
+	HasPermission(ctx context.Context, keycloakID string, permission string) (bool, error)
+	ListRoles(ctx context.Context) ([]dtos.RoleResponse, error)
+	// SaveRole creates the role of the name or replaces its description and permissions
+	SaveRole(ctx context.Context, name string, req *dtos.SaveRoleRequest) (*dtos.RoleResponse, error)
+	GetUserRoles(ctx context.Context, userID string) (*dtos.UserRolesResponse, error)
+	// SetUserRoles replaces the roles of the user
+	SetUserRoles(ctx context.Context, userID string, req *dtos.SetUserRolesRequest) (*dtos.UserRolesResponse, error)
+}
+
+// policyService implements PolicyService
+type policyService struct {
+	rbacRepo repositories.RBACRepository
+	cache    cache.Cache
+}
+
+// ProvidePolicyService creates a new policy service
+func ProvidePolicyService(rbacRepo repositories.RBACRepository, cache cache.Cache) PolicyService {
+	return &policyService{
+		rbacRepo: rbacRepo,
+		cache:    cache,
+	}
+}
+
+// HasPermission serves the permissions of the user from the cache for
+// constants.EntityCacheTTL. A permission is granted by itself, by <resource>:* and by *.
+func (s *policyService) HasPermission(ctx context.Context, keycloakID string, permission string) (bool, error) {
+	permissions, err := cache.GetOrLoad(ctx, s.cache, constants.PermissionsCacheKeyPrefix+keycloakID, constants.EntityCacheTTL, func(context.Context) ([]string, error) {
+		return s.rbacRepo.GetUserPermissions(keycloakID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return grantsPermission(permissions, permission), nil
+}
+
+// grantsPermission reports whether the permissions grant permission
+func grantsPermission(permissions []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, constants.PermissionSeparator)
+	return slices.ContainsFunc(permissions, func(granted string) bool {
+		return granted == permission ||
+			granted == constants.PermissionWildcard ||
+			granted == resource+constants.PermissionSeparator+constants.PermissionWildcard
+	})
+}
+
+func (s *policyService) ListRoles(ctx context.Context) ([]dtos.RoleResponse, error) {
+	roles, err := s.rbacRepo.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dtos.RoleResponse, 0, len(roles))
+	for i := range roles {
+		responses = append(responses, *dtos.NewRoleResponse(&roles[i]))
+	}
+	return responses, nil
+}
+
+// SaveRole changes the permissions of every user of the role, so the cached permissions
+// of all the users are invalidated
+func (s *policyService) SaveRole(ctx context.Context, name string, req *dtos.SaveRoleRequest) (*dtos.RoleResponse, error) {
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.ValidationError("Invalid role name", nil).
+			WithOperation("save_role").
+			WithResource("role").
+			WithContext("role", name)
+	}
+	permissions := uniqueSorted(req.Permissions)
+	for _, permission := range permissions {
+		if !permissionNamePattern.MatchString(permission) {
+			return nil, errors.ValidationError("Invalid permission name, expected <resource>:<action>", nil).
+				WithOperation("save_role").
+				WithResource("permission").
+				WithContext("permission", permission)
+		}
+	}
+
+	role := &models.Role{Name: name, Description: req.Description}
+	if err := s.rbacRepo.SaveRole(role, permissions); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.cache.Flush(ctx, constants.PermissionsCacheKeyPrefix+"*"); err != nil {
+		logger.Log.Warn("Failed to invalidate cached permissions", zap.String("role", name), zap.Error(err))
+	}
+
+	logger.Log.Info("Role saved",
+		zap.String("role", name),
+		zap.Strings("permissions", permissions),
+	)
+
+	return dtos.NewRoleResponse(role), nil
+}
+
+func (s *policyService) GetUserRoles(ctx context.Context, userID string) (*dtos.UserRolesResponse, error) {
+	user, err := s.rbacRepo.GetUserWithRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	return newUserRolesResponse(user), nil
+}
+
+func (s *policyService) SetUserRoles(ctx context.Context, userID string, req *dtos.SetUserRolesRequest) (*dtos.UserRolesResponse, error) {
+	user, err := s.rbacRepo.GetUserWithRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rbacRepo.SetUserRoles(user, uniqueSorted(req.Roles)); err != nil {
+		return nil, err
+	}
+	cache.Invalidate(ctx, s.cache, constants.PermissionsCacheKeyPrefix+user.KeycloakID)
+
+	// Reload the permissions of the new roles
+	user, err = s.rbacRepo.GetUserWithRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("User roles set",
+		zap.String("user_id", userID),
+		zap.Strings("roles", req.Roles),
+	)
+
+	return newUserRolesResponse(user), nil
+}
+
+// newUserRolesResponse lists the roles of the user and the permissions they grant
+func newUserRolesResponse(user *models.User) *dtos.UserRolesResponse {
+	response := &dtos.UserRolesResponse{
+		UserID:      user.ID,
+		Roles:       make([]string, 0, len(user.Roles)),
+		Permissions: []string{},
+	}
+	for i := range user.Roles {
+		response.Roles = append(response.Roles, user.Roles[i].Name)
+		response.Permissions = append(response.Permissions, user.Roles[i].PermissionNames()...)
+	}
+	response.Permissions = uniqueSorted(response.Permissions)
+	return response
+}
+
+// uniqueSorted returns the values sorted without duplicates
+func uniqueSorted(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
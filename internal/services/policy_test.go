@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRBACRepository is a mock implementation of repositories.RBACRepository
+type MockRBACRepository struct {
+	mock.Mock
+}
+
+func (m *MockRBACRepository) GetUserPermissions(keycloakID string) ([]string, error) {
+	args := m.Called(keycloakID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRBACRepository) ListRoles() ([]models.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRBACRepository) SaveRole(role *models.Role, permissions []string) error {
+	args := m.Called(role, permissions)
+	return args.Error(0)
+}
+
+func (m *MockRBACRepository) GetUserWithRoles(userID string) (*models.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockRBACRepository) SetUserRoles(user *models.User, roles []string) error {
+	args := m.Called(user, roles)
+	return args.Error(0)
+}
+
+func TestPolicyService_HasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		granted    []string
+		permission string
+		expected   bool
+	}{
+		{name: "granted permission", granted: []string{"users:read", "users:write"}, permission: "users:write", expected: true},
+		{name: "missing permission", granted: []string{"users:read"}, permission: "users:write", expected: false},
+		{name: "no roles", granted: []string{}, permission: "users:read", expected: false},
+		{name: "wildcard of the resource", granted: []string{"users:*"}, permission: "users:delete", expected: true},
+		{name: "wildcard of another resource", granted: []string{"companies:*"}, permission: "users:delete", expected: false},
+		{name: "wildcard", granted: []string{"*"}, permission: "companies:write", expected: true},
+		{name: "prefix of another resource", granted: []string{"user:*"}, permission: "users:read", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			mockCache := new(MockCache)
+			mockCache.On("Get", mock.Anything, "permissions:kc-1").Return("", errors.NotFoundError("Cache key", nil))
+			mockCache.On("Set", mock.Anything, "permissions:kc-1", mock.Anything, mock.Anything).Return(nil)
+			mockRepo.On("GetUserPermissions", "kc-1").Return(tt.granted, nil)
+			service := &policyService{rbacRepo: mockRepo, cache: mockCache}
+
+			allowed, err := service.HasPermission(context.Background(), "kc-1", tt.permission)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyService_HasPermission_Cached(t *testing.T) {
+	mockRepo := new(MockRBACRepository)
+	mockCache := new(MockCache)
+	mockCache.On("Get", mock.Anything, "permissions:kc-1").Return(`["users:read"]`, nil)
+	service := &policyService{rbacRepo: mockRepo, cache: mockCache}
+
+	allowed, err := service.HasPermission(context.Background(), "kc-1", "users:read")
+
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockRepo.AssertNotCalled(t, "GetUserPermissions", mock.Anything)
+}
+
+func TestPolicyService_SaveRole(t *testing.T) {
+	tests := []struct {
+		name          string
+		roleName      string
+		req           *dtos.SaveRoleRequest
+		setupMocks    func(*MockRBACRepository, *MockCache)
+		expectedError errors.ErrorType
+	}{
+		{
+			name:     "success - saves the role and invalidates the permissions",
+			roleName: "user-manager",
+			req:      &dtos.SaveRoleRequest{Description: "Users", Permissions: []string{"users:write", "users:read", "users:write"}},
+			setupMocks: func(repo *MockRBACRepository, cache *MockCache) {
+				repo.On("SaveRole", mock.MatchedBy(func(role *models.Role) bool {
+					return role.Name == "user-manager" && role.Description == "Users"
+				}), []string{"users:read", "users:write"}).Return(nil)
+				cache.On("Flush", mock.Anything, "permissions:*").Return(int64(3), nil)
+			},
+		},
+		{
+			name:          "error - invalid role name",
+			roleName:      "User Manager",
+			req:           &dtos.SaveRoleRequest{Permissions: []string{"users:read"}},
+			expectedError: errors.ErrorTypeValidation,
+		},
+		{
+			name:          "error - invalid permission name",
+			roleName:      "user-manager",
+			req:           &dtos.SaveRoleRequest{Permissions: []string{"users"}},
+			expectedError: errors.ErrorTypeValidation,
+		},
+		{
+			name:     "error - database error",
+			roleName: "user-manager",
+			req:      &dtos.SaveRoleRequest{Permissions: []string{"users:*"}},
+			setupMocks: func(repo *MockRBACRepository, cache *MockCache) {
+				repo.On("SaveRole", mock.Anything, []string{"users:*"}).Return(errors.DatabaseError("Failed to save role", nil))
+			},
+			expectedError: errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRBACRepository)
+			mockCache := new(MockCache)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockRepo, mockCache)
+			}
+			service := &policyService{rbacRepo: mockRepo, cache: mockCache}
+
+			role, err := service.SaveRole(context.Background(), tt.roleName, tt.req)
+
+			if tt.expectedError != "" {
+				appErr := errors.GetAppError(err)
+				require.NotNil(t, appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				assert.Nil(t, role)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "user-manager", role.Name)
+			}
+			mockRepo.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyService_SetUserRoles(t *testing.T) {
+	mockRepo := new(MockRBACRepository)
+	mockCache := new(MockCache)
+	user := factory.User().WithID("user-1").WithKeycloakID("kc-1").Build()
+	updated := factory.User().WithID("user-1").WithKeycloakID("kc-1").WithRoles(
+		models.Role{Name: "auditor", Permissions: []models.Permission{{Name: "users:read"}}},
+		models.Role{Name: "user-manager", Permissions: []models.Permission{{Name: "users:read"}, {Name: "users:write"}}},
+	).Build()
+	mockRepo.On("GetUserWithRoles", "user-1").Return(user, nil).Once()
+	mockRepo.On("SetUserRoles", user, []string{"auditor", "user-manager"}).Return(nil)
+	mockRepo.On("GetUserWithRoles", "user-1").Return(updated, nil).Once()
+	mockCache.On("Delete", mock.Anything, "permissions:kc-1").Return(nil)
+	service := &policyService{rbacRepo: mockRepo, cache: mockCache}
+
+	roles, err := service.SetUserRoles(context.Background(), "user-1", &dtos.SetUserRolesRequest{Roles: []string{"user-manager", "auditor"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, &dtos.UserRolesResponse{
+		UserID:      "user-1",
+		Roles:       []string{"auditor", "user-manager"},
+		Permissions: []string{"users:read", "users:write"},
+	}, roles)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestPolicyService_SetUserRoles_UnknownRole(t *testing.T) {
+	mockRepo := new(MockRBACRepository)
+	mockCache := new(MockCache)
+	user := factory.User().WithID("user-1").WithKeycloakID("kc-1").Build()
+	mockRepo.On("GetUserWithRoles", "user-1").Return(user, nil)
+	mockRepo.On("SetUserRoles", user, []string{"unknown"}).Return(errors.NotFoundError("Role", nil))
+	service := &policyService{rbacRepo: mockRepo, cache: mockCache}
+
+	_, err := service.SetUserRoles(context.Background(), "user-1", &dtos.SetUserRolesRequest{Roles: []string{"unknown"}})
+
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+	mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/projections"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/webhooks"
+
+	"go.uber.org/zap"
+)
+
+var tenantSlugPattern = regexp.MustCompile(constants.TenantSlugPattern)
+
+// ProvisioningService provisions the tenants of the provisioning pipeline end to end
+type ProvisioningService interface {
+	// Provision brings the tenant of the slug to the state of the request, creating what
+	// is missing and updating what differs: the Keycloak organization, the client roles,
+	// the company and its default webhook endpoints. It can be called again with the same
+	// request, e.g. after a failure half way, and ends in the same state.
+	Provision(ctx context.Context, slug string, req *dtos.ProvisionTenantRequest, provisionedBy string) (*dtos.ProvisionTenantResponse, error)
+}
+
+// provisioningService implements ProvisioningService
+type provisioningService struct {
+	auth           auth.AuthService
+	tokens         auth.TokenProvider
+	companyRepo    repositories.CompanyRepository
+	webhookRepo    repositories.WebhookRepository
+	webhookService WebhookService
+	webhooks       webhooks.Dispatcher
+	projections    projections.Publisher
+}
+
+// ProvideProvisioningService creates a new provisioning service
+func ProvideProvisioningService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	companyRepo repositories.CompanyRepository,
+	webhookRepo repositories.WebhookRepository,
+	webhookService WebhookService,
+	webhooks webhooks.Dispatcher,
+	projections projections.Publisher,
+) ProvisioningService {
+	return &provisioningService{
+		auth:           authProvider,
+		tokens:         tokens,
+		companyRepo:    companyRepo,
+		webhookRepo:    webhookRepo,
+		webhookService: webhookService,
+		webhooks:       webhooks,
+		projections:    projections,
+	}
+}
+
+func (s *provisioningService) Provision(ctx context.Context, slug string, req *dtos.ProvisionTenantRequest, provisionedBy string) (*dtos.ProvisionTenantResponse, error) {
+	if err := validateProvisioning(slug, req); err != nil {
+		return nil, err
+	}
+
+	company, err := s.companyRepo.GetBySlug(slug)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Type != errors.ErrorTypeNotFound {
+			return nil, err
+		}
+	}
+
+	storagePrefix := req.StoragePrefix
+	switch {
+	case company != nil && storagePrefix == "":
+		storagePrefix = company.StoragePrefix
+	case company != nil && storagePrefix != company.StoragePrefix:
+		// The objects of the tenant are stored under its prefix
+		return nil, errors.ConflictError("The storage prefix of a tenant cannot change", nil).
+			WithOperation("provision_tenant").
+			WithResource("company").
+			WithContext("slug", slug).
+			WithContext("storage_prefix", company.StoragePrefix)
+	case storagePrefix == "":
+		storagePrefix = constants.TenantStoragePrefix + "/" + slug
+	}
+
+	// Keycloak is provisioned first: the company then references an organization that
+	// exists, and a retry finds the organization by its slug
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	organization, err := s.provisionOrganization(ctx, adminToken, slug, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auth.EnsureClientRoles(ctx, adminToken, req.DefaultRoles); err != nil {
+		return nil, err
+	}
+
+	defaultRoles := req.DefaultRoles
+	if defaultRoles == nil {
+		defaultRoles = []string{}
+	}
+	created := company == nil
+	if created {
+		company, err = s.companyRepo.Create(&models.Company{
+			BaseModel:     models.NewBaseModel(),
+			Name:          req.Name,
+			KeycloakID:    organization.ID,
+			Slug:          &slug,
+			StoragePrefix: storagePrefix,
+			DefaultRoles:  defaultRoles,
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.publishProjectionEvent(ctx, company.ID, constants.EventCompanyCreated, company.ID)
+	} else if company.Name != req.Name || company.KeycloakID != organization.ID || !slices.Equal(company.DefaultRoles, defaultRoles) {
+		company.Name = req.Name
+		company.KeycloakID = organization.ID
+		company.DefaultRoles = defaultRoles
+		if err := s.companyRepo.UpdateColumns(company, "name", "keycloak_id", "default_roles"); err != nil {
+			return nil, err
+		}
+		if err := s.webhooks.Dispatch(ctx, company.ID, constants.WebhookEventCompanyUpdated, dtos.NewCompanyResponse(company)); err != nil {
+			logger.Log.Warn("Failed to dispatch webhook event",
+				zap.String("company_id", company.ID),
+				zap.String("event_type", constants.WebhookEventCompanyUpdated),
+				zap.Error(err),
+			)
+		}
+		s.publishProjectionEvent(ctx, company.ID, constants.WebhookEventCompanyUpdated, company.ID)
+	}
+
+	provisionedWebhooks, err := s.provisionWebhooks(ctx, company.ID, req.Webhooks, provisionedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Tenant provisioned",
+		zap.String("slug", slug),
+		zap.String("company_id", company.ID),
+		zap.String("organization_id", organization.ID),
+		zap.Bool("created", created),
+		zap.String("provisioned_by", provisionedBy),
+	)
+
+	return &dtos.ProvisionTenantResponse{
+		Slug:          slug,
+		CompanyID:     company.ID,
+		Name:          company.Name,
+		KeycloakID:    company.KeycloakID,
+		Domains:       organization.Domains,
+		DefaultRoles:  company.DefaultRoles,
+		StoragePrefix: company.StoragePrefix,
+		Webhooks:      provisionedWebhooks,
+		Created:       created,
+	}, nil
+}
+
+// provisionOrganization creates the Keycloak organization of the tenant, or updates its
+// name and domains when they differ from the request
+func (s *provisioningService) provisionOrganization(ctx context.Context, token string, slug string, req *dtos.ProvisionTenantRequest) (*auth.TenantOrganization, error) {
+	organization, err := s.auth.FindTenantOrganization(ctx, token, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := req.Domains
+	if domains == nil {
+		domains = []string{}
+	}
+	if organization != nil && organization.Name == req.Name && slices.Equal(sortedCopy(organization.Domains), sortedCopy(domains)) {
+		return organization, nil
+	}
+
+	desired := &auth.TenantOrganization{
+		Name:       req.Name,
+		Alias:      slug,
+		Domains:    domains,
+		Attributes: map[string][]string{},
+	}
+	if organization != nil {
+		desired.ID = organization.ID
+		desired.Alias = organization.Alias
+		for key, values := range organization.Attributes {
+			desired.Attributes[key] = values
+		}
+	}
+	desired.Attributes[constants.KeycloakTenantSlugAttribute] = []string{slug}
+
+	return s.auth.SaveTenantOrganization(ctx, token, desired)
+}
+
+// provisionWebhooks creates the default webhook endpoints missing from the company and
+// updates the filter, description and rate limit of the existing ones. Whether an endpoint
+// is active is left to the tenant, as are the endpoints it registered itself.
+func (s *provisioningService) provisionWebhooks(ctx context.Context, companyID string, requests []dtos.CreateWebhookRequest, provisionedBy string) ([]dtos.ProvisionedWebhookResponse, error) {
+	provisioned := make([]dtos.ProvisionedWebhookResponse, 0, len(requests))
+	if len(requests) == 0 {
+		return provisioned, nil
+	}
+
+	urls := make([]string, len(requests))
+	for i, req := range requests {
+		urls[i] = req.URL
+	}
+	endpoints, err := s.webhookRepo.GetEndpointsByURL(companyID, urls)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]string, len(endpoints))
+	for _, endpoint := range endpoints {
+		if _, ok := existing[endpoint.URL]; !ok {
+			existing[endpoint.URL] = endpoint.ID
+		}
+	}
+
+	for _, req := range requests {
+		if id, ok := existing[req.URL]; ok {
+			conditions := req.Conditions
+			if conditions == nil {
+				conditions = []dtos.WebhookCondition{}
+			}
+			endpoint, err := s.webhookService.Update(ctx, companyID, id, &dtos.UpdateWebhookRequest{
+				Description: &req.Description,
+				EventTypes:  req.EventTypes,
+				Conditions:  conditions,
+				RateLimit:   &req.RateLimit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			provisioned = append(provisioned, dtos.ProvisionedWebhookResponse{ID: endpoint.ID, URL: endpoint.URL})
+			continue
+		}
+
+		endpoint, secret, err := s.webhookService.Create(ctx, companyID, &req, provisionedBy)
+		if err != nil {
+			return nil, err
+		}
+		provisioned = append(provisioned, dtos.ProvisionedWebhookResponse{ID: endpoint.ID, URL: endpoint.URL, Secret: secret, Created: true})
+	}
+
+	return provisioned, nil
+}
+
+// publishProjectionEvent updates the read models of the company with the event. The change
+// is already saved and the read models can be rebuilt, so a failure is only logged.
+func (s *provisioningService) publishProjectionEvent(ctx context.Context, companyID string, eventType string, entityID string) {
+	if err := s.projections.Publish(ctx, companyID, eventType, entityID); err != nil {
+		logger.Log.Warn("Failed to publish projection event",
+			zap.String("company_id", companyID),
+			zap.String("event_type", eventType),
+			zap.String("entity_id", entityID),
+			zap.Error(err),
+		)
+	}
+}
+
+// validateProvisioning checks what the request tags cannot before anything is provisioned,
+// so that an invalid webhook filter does not leave a tenant half provisioned
+func validateProvisioning(slug string, req *dtos.ProvisionTenantRequest) error {
+	if !tenantSlugPattern.MatchString(slug) {
+		return errors.ValidationError("The slug must be 2 to 63 lowercase letters, digits or dashes, starting with a letter or digit", nil).
+			WithOperation("provision_tenant").
+			WithContext("slug", slug)
+	}
+
+	if prefix := req.StoragePrefix; prefix != "" && (strings.Trim(prefix, "/") != prefix || slices.Contains(strings.Split(prefix, "/"), "..")) {
+		return errors.ValidationError("The storage prefix cannot start or end with a slash or contain ..", nil).
+			WithOperation("provision_tenant").
+			WithContext("storage_prefix", prefix)
+	}
+
+	urls := make(map[string]bool, len(req.Webhooks))
+	for i, webhook := range req.Webhooks {
+		if urls[webhook.URL] {
+			return errors.ValidationError(fmt.Sprintf("webhooks[%d]: duplicate URL", i), nil).
+				WithOperation("provision_tenant").
+				WithContext("url", webhook.URL)
+		}
+		urls[webhook.URL] = true
+
+		if _, err := validateWebhookFilter(webhook.EventTypes, webhook.Conditions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedCopy returns the values sorted, leaving them untouched
+func sortedCopy(values []string) []string {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioningService_Provision(t *testing.T) {
+	slug := "acme"
+	request := func() *dtos.ProvisionTenantRequest {
+		return &dtos.ProvisionTenantRequest{
+			Name:         "Acme",
+			Domains:      []string{"acme.com"},
+			DefaultRoles: []string{constants.RoleCompanyViewer},
+			Webhooks: []dtos.CreateWebhookRequest{
+				{URL: "https://hooks.acme.com/crm", EventTypes: []string{"user.*"}},
+				{URL: "https://hooks.acme.com/billing", EventTypes: []string{constants.WebhookEventCompanyUpdated}},
+			},
+		}
+	}
+	organization := &auth.TenantOrganization{
+		ID:         "org-1",
+		Name:       "Acme",
+		Alias:      slug,
+		Domains:    []string{"acme.com"},
+		Attributes: map[string][]string{constants.KeycloakTenantSlugAttribute: {slug}},
+	}
+	existingCompany := func() *models.Company {
+		return factory.Company().
+			WithID("company-1").
+			WithName("Acme").
+			WithKeycloakID("org-1").
+			AsTenant(slug, "tenants/acme", constants.RoleCompanyViewer).
+			Build()
+	}
+
+	tests := []struct {
+		name            string
+		slug            string
+		request         func() *dtos.ProvisionTenantRequest
+		setupMocks      func(authProvider *MockAuthProvider, companyRepo *MockCompanyRepositoryForCompanyService, webhookRepo *MockWebhookRepository)
+		expectedError   errors.ErrorType
+		expectedCreated bool
+		expectedSecrets int
+	}{
+		{
+			name:    "new tenant",
+			slug:    slug,
+			request: request,
+			setupMocks: func(authProvider *MockAuthProvider, companyRepo *MockCompanyRepositoryForCompanyService, webhookRepo *MockWebhookRepository) {
+				companyRepo.On("GetBySlug", slug).Return(nil, errors.NotFoundError("Company", nil))
+				authProvider.On("FindTenantOrganization", mock.Anything, "admin-token", slug).Return(nil, nil)
+				authProvider.On("SaveTenantOrganization", mock.Anything, "admin-token", mock.MatchedBy(func(org *auth.TenantOrganization) bool {
+					return org.ID == "" && org.Alias == slug && org.Attributes[constants.KeycloakTenantSlugAttribute][0] == slug
+				})).Return(organization, nil)
+				authProvider.On("EnsureClientRoles", mock.Anything, "admin-token", []string{constants.RoleCompanyViewer}).Return(nil)
+				companyRepo.On("Create", mock.MatchedBy(func(company *models.Company) bool {
+					return *company.Slug == slug && company.KeycloakID == "org-1" && company.StoragePrefix == "tenants/acme"
+				})).Return(existingCompany(), nil)
+				companyRepo.On("GetOneByID", "company-1").Return(existingCompany(), nil)
+				webhookRepo.On("GetEndpointsByURL", "company-1", []string{"https://hooks.acme.com/crm", "https://hooks.acme.com/billing"}).Return([]models.WebhookEndpoint{}, nil)
+				webhookRepo.On("CreateEndpoint", mock.Anything).Return(nil).Twice()
+			},
+			expectedCreated: true,
+			expectedSecrets: 2,
+		},
+		{
+			name:    "provisioned again",
+			slug:    slug,
+			request: request,
+			setupMocks: func(authProvider *MockAuthProvider, companyRepo *MockCompanyRepositoryForCompanyService, webhookRepo *MockWebhookRepository) {
+				companyRepo.On("GetBySlug", slug).Return(existingCompany(), nil)
+				// Nothing differs, so neither Keycloak nor the company are written
+				authProvider.On("FindTenantOrganization", mock.Anything, "admin-token", slug).Return(organization, nil)
+				authProvider.On("EnsureClientRoles", mock.Anything, "admin-token", []string{constants.RoleCompanyViewer}).Return(nil)
+				webhookRepo.On("GetEndpointsByURL", "company-1", mock.Anything).Return([]models.WebhookEndpoint{
+					{BaseModel: models.BaseModel{ID: "webhook-1"}, CompanyID: "company-1", URL: "https://hooks.acme.com/crm", EventTypes: []string{"user.*"}, Active: false},
+				}, nil)
+				webhookRepo.On("GetEndpoint", "company-1", "webhook-1").Return(&models.WebhookEndpoint{
+					BaseModel: models.BaseModel{ID: "webhook-1"}, CompanyID: "company-1", URL: "https://hooks.acme.com/crm", EventTypes: []string{"user.*"}, Active: false,
+				}, nil)
+				webhookRepo.On("UpdateEndpoint", mock.MatchedBy(func(endpoint *models.WebhookEndpoint) bool {
+					// A paused endpoint stays paused
+					return endpoint.ID == "webhook-1" && !endpoint.Active
+				})).Return(nil)
+				companyRepo.On("GetOneByID", "company-1").Return(existingCompany(), nil)
+				webhookRepo.On("CreateEndpoint", mock.Anything).Return(nil).Once()
+			},
+			expectedSecrets: 1,
+		},
+		{
+			name: "renamed tenant",
+			slug: slug,
+			request: func() *dtos.ProvisionTenantRequest {
+				req := request()
+				req.Name = "Acme Corp"
+				req.Webhooks = nil
+				return req
+			},
+			setupMocks: func(authProvider *MockAuthProvider, companyRepo *MockCompanyRepositoryForCompanyService, webhookRepo *MockWebhookRepository) {
+				companyRepo.On("GetBySlug", slug).Return(existingCompany(), nil)
+				authProvider.On("FindTenantOrganization", mock.Anything, "admin-token", slug).Return(organization, nil)
+				authProvider.On("SaveTenantOrganization", mock.Anything, "admin-token", mock.MatchedBy(func(org *auth.TenantOrganization) bool {
+					return org.ID == "org-1" && org.Name == "Acme Corp"
+				})).Return(&auth.TenantOrganization{ID: "org-1", Name: "Acme Corp", Alias: slug, Domains: []string{"acme.com"}}, nil)
+				authProvider.On("EnsureClientRoles", mock.Anything, "admin-token", []string{constants.RoleCompanyViewer}).Return(nil)
+				companyRepo.On("UpdateColumns", mock.MatchedBy(func(company *models.Company) bool {
+					return company.Name == "Acme Corp"
+				}), []string{"name", "keycloak_id", "default_roles"}).Return(nil)
+			},
+		},
+		{
+			name: "storage prefix changed",
+			slug: slug,
+			request: func() *dtos.ProvisionTenantRequest {
+				req := request()
+				req.StoragePrefix = "customers/acme"
+				return req
+			},
+			setupMocks: func(authProvider *MockAuthProvider, companyRepo *MockCompanyRepositoryForCompanyService, webhookRepo *MockWebhookRepository) {
+				companyRepo.On("GetBySlug", slug).Return(existingCompany(), nil)
+			},
+			expectedError: errors.ErrorTypeConflict,
+		},
+		{
+			name:          "invalid slug",
+			slug:          "Acme_Inc",
+			request:       request,
+			setupMocks:    func(*MockAuthProvider, *MockCompanyRepositoryForCompanyService, *MockWebhookRepository) {},
+			expectedError: errors.ErrorTypeValidation,
+		},
+		{
+			name: "invalid webhook filter",
+			slug: slug,
+			request: func() *dtos.ProvisionTenantRequest {
+				req := request()
+				req.Webhooks[1].EventTypes = []string{"invoice.paid"}
+				return req
+			},
+			// Rejected before anything is provisioned
+			setupMocks:    func(*MockAuthProvider, *MockCompanyRepositoryForCompanyService, *MockWebhookRepository) {},
+			expectedError: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			webhookRepo := new(MockWebhookRepository)
+			dispatcher := new(MockWebhookDispatcher)
+			dispatcher.On("Dispatch", mock.Anything, "company-1", constants.WebhookEventCompanyUpdated, mock.Anything).Return(nil).Maybe()
+			webhookRepo.On("CountEndpoints", "company-1").Return(int64(0), nil).Maybe()
+			tt.setupMocks(authProvider, companyRepo, webhookRepo)
+
+			service := ProvideProvisioningService(
+				authProvider,
+				newMockTokenProvider(),
+				companyRepo,
+				webhookRepo,
+				newTestWebhookService(t, webhookRepo, companyRepo, dispatcher),
+				dispatcher,
+				newMockProjectionPublisher(),
+			)
+
+			tenant, err := service.Provision(context.Background(), tt.slug, tt.request(), "provisioner")
+
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				authProvider.AssertNotCalled(t, "SaveTenantOrganization", mock.Anything, mock.Anything, mock.Anything)
+				companyRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCreated, tenant.Created)
+			assert.Equal(t, "company-1", tenant.CompanyID)
+			assert.Equal(t, "org-1", tenant.KeycloakID)
+			assert.Equal(t, "tenants/acme", tenant.StoragePrefix)
+
+			secrets := 0
+			for _, webhook := range tenant.Webhooks {
+				if webhook.Secret != "" {
+					assert.True(t, webhook.Created)
+					secrets++
+				}
+			}
+			assert.Equal(t, tt.expectedSecrets, secrets)
+
+			authProvider.AssertExpectations(t)
+			companyRepo.AssertExpectations(t)
+			webhookRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type RetentionService interface {
+	Get(ctx context.Context, companyID string) (*dtos.RetentionResponse, error)
+	// SetPolicy sets the number of days the data of a resource is kept
+	SetPolicy(ctx context.Context, companyID string, resource string, days int, updatedBy string) (*dtos.RetentionResponse, error)
+	// DeletePolicy removes the policy of a resource, which is kept forever again
+	DeletePolicy(ctx context.Context, companyID string, resource string) (*dtos.RetentionResponse, error)
+	PlaceCompanyLegalHold(ctx context.Context, companyID string, reason string, placedBy string) (*dtos.LegalHoldResponse, error)
+	ClearCompanyLegalHold(ctx context.Context, companyID string) (*dtos.LegalHoldResponse, error)
+	PlaceAPIKeyLegalHold(ctx context.Context, companyID string, keyID string, reason string, placedBy string) (*dtos.LegalHoldResponse, error)
+	ClearAPIKeyLegalHold(ctx context.Context, companyID string, keyID string) (*dtos.LegalHoldResponse, error)
+	// Enforce purges the data older than the policies of the companies not on legal hold
+	Enforce(ctx context.Context) (*dtos.RetentionResult, error)
+}
+
+// retentionPurge deletes the data of a resource of a company older than before, skipping
+// the entities on legal hold, and returns the number of rows deleted
+type retentionPurge func(companyID string, before time.Time) (int64, error)
+
+// retentionService manages the retention policies and legal holds of the tenants
+type retentionService struct {
+	retentionRepo repositories.RetentionRepository
+	companyRepo   repositories.CompanyRepository
+	apiKeyRepo    repositories.APIKeyRepository
+
+	// purges of the resources of constants.RetentionResources
+	purges map[string]retentionPurge
+}
+
+// ProvideRetentionService creates a new retention service
+func ProvideRetentionService(
+	retentionRepo repositories.RetentionRepository,
+	companyRepo repositories.CompanyRepository,
+	apiKeyRepo repositories.APIKeyRepository,
+) RetentionService {
+	return &retentionService{
+		retentionRepo: retentionRepo,
+		companyRepo:   companyRepo,
+		apiKeyRepo:    apiKeyRepo,
+		purges: map[string]retentionPurge{
+			constants.RetentionResourceAPIKeyUsage:    retentionRepo.PurgeAPIKeyUsage,
+			constants.RetentionResourceRevokedAPIKeys: retentionRepo.PurgeRevokedAPIKeys,
+		},
+	}
+}
+
+func (s *retentionService) Get(ctx context.Context, companyID string) (*dtos.RetentionResponse, error) {
+	company, err := s.getCompany(companyID, "get_retention")
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := s.retentionRepo.GetPolicies(companyID)
+	if err != nil {
+		return nil, err
+	}
+	policiesByResource := make(map[string]models.RetentionPolicy, len(policies))
+	for _, policy := range policies {
+		policiesByResource[policy.Resource] = policy
+	}
+
+	retention := &dtos.RetentionResponse{
+		CompanyID: companyID,
+		LegalHold: *dtos.NewLegalHoldResponse(company.LegalHold),
+		Policies:  make([]dtos.RetentionPolicyResponse, 0, len(constants.RetentionResources)),
+	}
+	for _, resource := range constants.RetentionResources {
+		response := dtos.RetentionPolicyResponse{Resource: resource}
+		if policy, ok := policiesByResource[resource]; ok {
+			response.Days = &policy.Days
+			response.UpdatedBy = policy.UpdatedBy
+			response.UpdatedAt = &policy.UpdatedAt
+		}
+		retention.Policies = append(retention.Policies, response)
+	}
+
+	return retention, nil
+}
+
+func (s *retentionService) SetPolicy(ctx context.Context, companyID string, resource string, days int, updatedBy string) (*dtos.RetentionResponse, error) {
+	if err := validateRetentionResource(resource, "set_retention_policy"); err != nil {
+		return nil, err
+	}
+	if days < constants.RetentionMinDays || days > constants.RetentionMaxDays {
+		return nil, errors.ValidationError("Retention period out of range", nil).
+			WithOperation("set_retention_policy").
+			WithResource("retention_policy").
+			WithContext("days", days)
+	}
+	if _, err := s.getCompany(companyID, "set_retention_policy"); err != nil {
+		return nil, err
+	}
+
+	policy := &models.RetentionPolicy{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: companyID,
+		Resource:  resource,
+		Days:      days,
+		UpdatedBy: updatedBy,
+	}
+	if err := s.retentionRepo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Retention policy updated",
+		zap.String("company_id", companyID),
+		zap.String("resource", resource),
+		zap.Int("days", days),
+		zap.String("updated_by", updatedBy),
+	)
+
+	return s.Get(ctx, companyID)
+}
+
+func (s *retentionService) DeletePolicy(ctx context.Context, companyID string, resource string) (*dtos.RetentionResponse, error) {
+	if err := validateRetentionResource(resource, "delete_retention_policy"); err != nil {
+		return nil, err
+	}
+
+	if err := s.retentionRepo.DeletePolicy(companyID, resource); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, companyID)
+}
+
+func (s *retentionService) PlaceCompanyLegalHold(ctx context.Context, companyID string, reason string, placedBy string) (*dtos.LegalHoldResponse, error) {
+	company, err := s.getCompany(companyID, "place_company_legal_hold")
+	if err != nil {
+		return nil, err
+	}
+	if company.OnLegalHold() {
+		return nil, errors.ConflictError("Company is already on legal hold", nil).
+			WithOperation("place_company_legal_hold").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	hold := newLegalHold(reason, placedBy)
+	if err := s.retentionRepo.SetCompanyLegalHold(companyID, hold); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Warn("Company placed on legal hold",
+		zap.String("company_id", companyID),
+		zap.String("placed_by", placedBy),
+	)
+
+	return dtos.NewLegalHoldResponse(hold), nil
+}
+
+// ClearCompanyLegalHold is idempotent, clearing a company not on hold succeeds
+func (s *retentionService) ClearCompanyLegalHold(ctx context.Context, companyID string) (*dtos.LegalHoldResponse, error) {
+	company, err := s.getCompany(companyID, "clear_company_legal_hold")
+	if err != nil {
+		return nil, err
+	}
+
+	if company.OnLegalHold() {
+		if err := s.retentionRepo.SetCompanyLegalHold(companyID, models.LegalHold{}); err != nil {
+			return nil, err
+		}
+		logger.Log.Warn("Company legal hold cleared",
+			zap.String("company_id", companyID),
+			zap.String("placed_by", company.LegalHoldBy),
+		)
+	}
+
+	return dtos.NewLegalHoldResponse(models.LegalHold{}), nil
+}
+
+func (s *retentionService) PlaceAPIKeyLegalHold(ctx context.Context, companyID string, keyID string, reason string, placedBy string) (*dtos.LegalHoldResponse, error) {
+	key, err := s.apiKeyRepo.GetByID(companyID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.OnLegalHold() {
+		return nil, errors.ConflictError("API key is already on legal hold", nil).
+			WithOperation("place_api_key_legal_hold").
+			WithResource("api_key").
+			WithContext("api_key_id", keyID)
+	}
+
+	hold := newLegalHold(reason, placedBy)
+	if err := s.retentionRepo.SetAPIKeyLegalHold(keyID, hold); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Warn("API key placed on legal hold",
+		zap.String("company_id", companyID),
+		zap.String("api_key_id", keyID),
+		zap.String("placed_by", placedBy),
+	)
+
+	return dtos.NewLegalHoldResponse(hold), nil
+}
+
+// ClearAPIKeyLegalHold is idempotent, clearing a key not on hold succeeds
+func (s *retentionService) ClearAPIKeyLegalHold(ctx context.Context, companyID string, keyID string) (*dtos.LegalHoldResponse, error) {
+	key, err := s.apiKeyRepo.GetByID(companyID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.OnLegalHold() {
+		if err := s.retentionRepo.SetAPIKeyLegalHold(keyID, models.LegalHold{}); err != nil {
+			return nil, err
+		}
+		logger.Log.Warn("API key legal hold cleared",
+			zap.String("company_id", companyID),
+			zap.String("api_key_id", keyID),
+			zap.String("placed_by", key.LegalHoldBy),
+		)
+	}
+
+	return dtos.NewLegalHoldResponse(models.LegalHold{}), nil
+}
+
+// Enforce keeps purging the other policies when one fails; the run fails when any did
+func (s *retentionService) Enforce(ctx context.Context) (*dtos.RetentionResult, error) {
+	policies, err := s.retentionRepo.GetEnforceablePolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.RetentionResult{
+		Policies: len(policies),
+		Purged:   make(map[string]int64, len(s.purges)),
+	}
+	var lastErr error
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		purge, ok := s.purges[policy.Resource]
+		if !ok {
+			continue
+		}
+		before := time.Now().AddDate(0, 0, -policy.Days)
+		purged, err := purge(policy.CompanyID, before)
+		if err != nil {
+			logger.Log.Error("Failed to enforce retention policy",
+				zap.String("company_id", policy.CompanyID),
+				zap.String("resource", policy.Resource),
+				zap.Error(err),
+			)
+			result.Failed++
+			lastErr = err
+			continue
+		}
+		result.Purged[policy.Resource] += purged
+	}
+
+	return result, lastErr
+}
+
+func (s *retentionService) getCompany(companyID string, operation string) (*models.Company, error) {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	return company, nil
+}
+
+func newLegalHold(reason string, placedBy string) models.LegalHold {
+	now := time.Now()
+	return models.LegalHold{
+		LegalHoldAt:     &now,
+		LegalHoldBy:     placedBy,
+		LegalHoldReason: reason,
+	}
+}
+
+func validateRetentionResource(resource string, operation string) error {
+	if !slices.Contains(constants.RetentionResources, resource) {
+		return errors.ValidationError("Unknown retention resource", nil).
+			WithOperation(operation).
+			WithResource("retention_policy").
+			WithContext("resource", resource)
+	}
+	return nil
+}
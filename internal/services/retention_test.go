@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRetentionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRetentionRepository) GetPolicies(companyID string) ([]models.RetentionPolicy, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockRetentionRepository) GetEnforceablePolicies() ([]models.RetentionPolicy, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RetentionPolicy), args.Error(1)
+}
+
+func (m *MockRetentionRepository) SavePolicy(policy *models.RetentionPolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockRetentionRepository) DeletePolicy(companyID string, resource string) error {
+	args := m.Called(companyID, resource)
+	return args.Error(0)
+}
+
+func (m *MockRetentionRepository) SetCompanyLegalHold(companyID string, hold models.LegalHold) error {
+	args := m.Called(companyID, hold)
+	return args.Error(0)
+}
+
+func (m *MockRetentionRepository) SetAPIKeyLegalHold(keyID string, hold models.LegalHold) error {
+	args := m.Called(keyID, hold)
+	return args.Error(0)
+}
+
+func (m *MockRetentionRepository) PurgeAPIKeyUsage(companyID string, before time.Time) (int64, error) {
+	args := m.Called(companyID, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRetentionRepository) PurgeRevokedAPIKeys(companyID string, before time.Time) (int64, error) {
+	args := m.Called(companyID, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRetentionService_Get(t *testing.T) {
+	retentionRepo := new(MockRetentionRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideRetentionService(retentionRepo, companyRepo, new(MockAPIKeyRepository))
+
+	company := factory.Company().OnLegalHold("Litigation", "admin-1").Build()
+	companyRepo.On("GetOneByID", company.ID).Return(company, nil)
+	retentionRepo.On("GetPolicies", company.ID).Return([]models.RetentionPolicy{
+		{CompanyID: company.ID, Resource: constants.RetentionResourceAPIKeyUsage, Days: 365, UpdatedBy: "manager-1"},
+	}, nil)
+
+	retention, err := service.Get(context.Background(), company.ID)
+	require.NoError(t, err)
+
+	assert.True(t, retention.LegalHold.OnHold)
+	assert.Equal(t, "Litigation", retention.LegalHold.Reason)
+	require.Len(t, retention.Policies, len(constants.RetentionResources))
+	assert.Equal(t, constants.RetentionResourceAPIKeyUsage, retention.Policies[0].Resource)
+	require.NotNil(t, retention.Policies[0].Days)
+	assert.Equal(t, 365, *retention.Policies[0].Days)
+	assert.Equal(t, "manager-1", retention.Policies[0].UpdatedBy)
+	assert.Equal(t, constants.RetentionResourceRevokedAPIKeys, retention.Policies[1].Resource)
+	assert.Nil(t, retention.Policies[1].Days, "a resource without policy is kept forever")
+}
+
+func TestRetentionService_SetPolicy_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		days     int
+	}{
+		{name: "unknown resource", resource: "audit_logs", days: 365},
+		{name: "period too short", resource: constants.RetentionResourceAPIKeyUsage, days: 0},
+		{name: "period too long", resource: constants.RetentionResourceAPIKeyUsage, days: constants.RetentionMaxDays + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retentionRepo := new(MockRetentionRepository)
+			service := ProvideRetentionService(retentionRepo, new(MockCompanyRepositoryForCompanyService), new(MockAPIKeyRepository))
+
+			_, err := service.SetPolicy(context.Background(), "company-1", tt.resource, tt.days, "manager-1")
+
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+			retentionRepo.AssertNotCalled(t, "SavePolicy", mock.Anything)
+		})
+	}
+}
+
+func TestRetentionService_CompanyLegalHold(t *testing.T) {
+	retentionRepo := new(MockRetentionRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideRetentionService(retentionRepo, companyRepo, new(MockAPIKeyRepository))
+
+	company := factory.Company().Build()
+	companyRepo.On("GetOneByID", company.ID).Return(company, nil)
+	retentionRepo.On("SetCompanyLegalHold", company.ID, mock.MatchedBy(func(hold models.LegalHold) bool {
+		return hold.OnLegalHold() && hold.LegalHoldBy == "manager-1" && hold.LegalHoldReason == "Litigation"
+	})).Run(func(args mock.Arguments) {
+		company.LegalHold = args.Get(1).(models.LegalHold)
+	}).Return(nil).Once()
+
+	hold, err := service.PlaceCompanyLegalHold(context.Background(), company.ID, "Litigation", "manager-1")
+	require.NoError(t, err)
+	assert.True(t, hold.OnHold)
+	assert.Equal(t, "manager-1", hold.PlacedBy)
+
+	// A hold is not replaced, it has to be cleared first
+	_, err = service.PlaceCompanyLegalHold(context.Background(), company.ID, "Audit", "manager-2")
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, errors.ErrorTypeConflict, appErr.Type)
+
+	retentionRepo.On("SetCompanyLegalHold", company.ID, models.LegalHold{}).Run(func(args mock.Arguments) {
+		company.LegalHold = models.LegalHold{}
+	}).Return(nil).Once()
+
+	hold, err = service.ClearCompanyLegalHold(context.Background(), company.ID)
+	require.NoError(t, err)
+	assert.False(t, hold.OnHold)
+
+	// Clearing again succeeds without writing
+	_, err = service.ClearCompanyLegalHold(context.Background(), company.ID)
+	require.NoError(t, err)
+	retentionRepo.AssertExpectations(t)
+}
+
+func TestRetentionService_Enforce(t *testing.T) {
+	retentionRepo := new(MockRetentionRepository)
+	service := ProvideRetentionService(retentionRepo, new(MockCompanyRepositoryForCompanyService), new(MockAPIKeyRepository))
+
+	retentionRepo.On("GetEnforceablePolicies").Return([]models.RetentionPolicy{
+		{CompanyID: "company-1", Resource: constants.RetentionResourceAPIKeyUsage, Days: 30},
+		{CompanyID: "company-1", Resource: constants.RetentionResourceRevokedAPIKeys, Days: 90},
+		{CompanyID: "company-2", Resource: constants.RetentionResourceAPIKeyUsage, Days: 365},
+		{CompanyID: "company-3", Resource: constants.RetentionResourceAPIKeyUsage, Days: 7},
+	}, nil)
+	cutoff := func(days int) any {
+		return mock.MatchedBy(func(before time.Time) bool {
+			return before.Sub(time.Now().AddDate(0, 0, -days)).Abs() < time.Minute
+		})
+	}
+	retentionRepo.On("PurgeAPIKeyUsage", "company-1", cutoff(30)).Return(int64(12), nil)
+	retentionRepo.On("PurgeRevokedAPIKeys", "company-1", cutoff(90)).Return(int64(2), nil)
+	retentionRepo.On("PurgeAPIKeyUsage", "company-2", cutoff(365)).Return(int64(3), nil)
+	errUnavailable := stderrors.New("connection refused")
+	retentionRepo.On("PurgeAPIKeyUsage", "company-3", cutoff(7)).Return(int64(0), errUnavailable)
+
+	result, err := service.Enforce(context.Background())
+
+	assert.ErrorIs(t, err, errUnavailable, "a failed policy fails the run after the other policies")
+	assert.Equal(t, 4, result.Policies)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, map[string]int64{
+		constants.RetentionResourceAPIKeyUsage:    15,
+		constants.RetentionResourceRevokedAPIKeys: 2,
+	}, result.Purged)
+	retentionRepo.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+)
+
+type SandboxService interface {
+	// ListEmails returns the inbox of the sandbox tenant, or of the sandbox of a company,
+	// most recent first
+	ListEmails(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.CapturedEmail], error)
+	GetEmail(ctx context.Context, companyID string, emailID string) (*models.CapturedEmail, error)
+}
+
+// sandboxService serves the inboxes of the sandbox tenants
+type sandboxService struct {
+	emailRepo   repositories.CapturedEmailRepository
+	companyRepo repositories.CompanyRepository
+}
+
+// ProvideSandboxService creates a new sandbox service
+func ProvideSandboxService(
+	emailRepo repositories.CapturedEmailRepository,
+	companyRepo repositories.CompanyRepository,
+) SandboxService {
+	return &sandboxService{
+		emailRepo:   emailRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+func (s *sandboxService) ListEmails(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.CapturedEmail], error) {
+	sandboxID, err := s.resolveSandbox(companyID, "get_captured_emails")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.emailRepo.GetByCompanyID(sandboxID, pageableRequest)
+}
+
+func (s *sandboxService) GetEmail(ctx context.Context, companyID string, emailID string) (*models.CapturedEmail, error) {
+	sandboxID, err := s.resolveSandbox(companyID, "get_captured_email")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.emailRepo.GetByID(sandboxID, emailID)
+}
+
+// resolveSandbox returns the ID of the sandbox tenant of a company, the company itself
+// when it is a sandbox
+func (s *sandboxService) resolveSandbox(companyID string, operation string) (string, error) {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return "", errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	if company.SandboxOfID != nil {
+		return company.ID, nil
+	}
+
+	sandbox, err := s.companyRepo.GetSandbox(companyID)
+	if err != nil {
+		return "", err
+	}
+	return sandbox.ID, nil
+}
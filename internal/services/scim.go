@@ -0,0 +1,946 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/saga"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// scimFilterPattern matches the filters on the equality of one attribute
+	scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9._]*)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+	// scimMemberPathPattern matches the path of one member of a group
+	scimMemberPathPattern = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+	// scimEmailPathPattern matches the paths of the value of an email of a user
+	scimEmailPathPattern = regexp.MustCompile(`(?i)^emails(\[[^\]]*\])?\.value$`)
+)
+
+// SCIMService provisions the users and groups of the identity providers through SCIM 2.0.
+// The users are the users of the service and their Keycloak users; the groups are the
+// companies, whose members are their users.
+type SCIMService interface {
+	ListUsers(ctx context.Context, req *dtos.SCIMListRequest) (*dtos.SCIMListResponse[dtos.SCIMUser], error)
+	GetUser(ctx context.Context, userID string) (*dtos.SCIMUser, error)
+	// CreateUser creates the user and their Keycloak user, or reuses the Keycloak user
+	// with the same email
+	CreateUser(ctx context.Context, req *dtos.SCIMUser) (*dtos.SCIMUser, error)
+	// ReplaceUser brings the user to the state of the request; an inactive user is
+	// disabled in Keycloak
+	ReplaceUser(ctx context.Context, userID string, req *dtos.SCIMUser) (*dtos.SCIMUser, error)
+	PatchUser(ctx context.Context, userID string, req *dtos.SCIMPatchRequest) (*dtos.SCIMUser, error)
+	// DeleteUser deletes the user and their Keycloak user
+	DeleteUser(ctx context.Context, userID string) error
+	ListGroups(ctx context.Context, req *dtos.SCIMListRequest) (*dtos.SCIMListResponse[dtos.SCIMGroup], error)
+	GetGroup(ctx context.Context, companyID string, withMembers bool) (*dtos.SCIMGroup, error)
+	CreateGroup(ctx context.Context, req *dtos.SCIMGroup) (*dtos.SCIMGroup, error)
+	// ReplaceGroup renames the company and makes the users of the request its only members
+	ReplaceGroup(ctx context.Context, companyID string, req *dtos.SCIMGroup) (*dtos.SCIMGroup, error)
+	PatchGroup(ctx context.Context, companyID string, req *dtos.SCIMPatchRequest) (*dtos.SCIMGroup, error)
+	DeleteGroup(ctx context.Context, companyID string) error
+}
+
+// scimService implements SCIMService
+type scimService struct {
+	auth           auth.AuthService
+	tokens         auth.TokenProvider
+	userService    UserService
+	companyService CompanyService
+	userRepo       repositories.UserRepository
+	companyRepo    repositories.CompanyRepository
+	cache          cache.Cache
+}
+
+// ProvideSCIMService creates a new SCIM service
+func ProvideSCIMService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	userService UserService,
+	companyService CompanyService,
+	userRepo repositories.UserRepository,
+	companyRepo repositories.CompanyRepository,
+	cache cache.Cache,
+) SCIMService {
+	return &scimService{
+		auth:           authProvider,
+		tokens:         tokens,
+		userService:    userService,
+		companyService: companyService,
+		userRepo:       userRepo,
+		companyRepo:    companyRepo,
+		cache:          cache,
+	}
+}
+
+// scimUserState is the state of a user a SCIM request brings them to
+type scimUserState struct {
+	email      string
+	firstName  string
+	lastName   string
+	externalID string
+	active     bool
+}
+
+func (s *scimService) ListUsers(ctx context.Context, req *dtos.SCIMListRequest) (*dtos.SCIMListResponse[dtos.SCIMUser], error) {
+	filters, err := parseSCIMFilter(req.Filter, constants.SCIMUserFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var preloads []string
+	if !slices.Contains(req.ExcludedAttributes, "groups") {
+		preloads = append(preloads, "Companies")
+	}
+	users, total, err := s.userRepo.GetRange(filters, req.StartIndex-1, req.Count, preloads...)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dtos.SCIMUser, len(users))
+	for i := range users {
+		resources[i] = *newSCIMUser(&users[i])
+	}
+	return newSCIMListResponse(resources, total, req.StartIndex), nil
+}
+
+func (s *scimService) GetUser(ctx context.Context, userID string) (*dtos.SCIMUser, error) {
+	user, err := s.getUser(userID, "scim_get_user")
+	if err != nil {
+		return nil, err
+	}
+
+	return newSCIMUser(user), nil
+}
+
+func (s *scimService) CreateUser(ctx context.Context, req *dtos.SCIMUser) (*dtos.SCIMUser, error) {
+	state, err := newSCIMUserState(req, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailAvailable(state.email, ""); err != nil {
+		return nil, err
+	}
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keycloakUser, err := s.auth.FindUserByEmail(ctx, adminToken, state.email)
+	if err != nil {
+		return nil, err
+	}
+	keycloakUserCreated := keycloakUser == nil
+	keycloakRequest := &dtos.UpdateUserRequest{
+		UserRequest: dtos.UserRequest{
+			Email:     state.email,
+			FirstName: state.firstName,
+			LastName:  state.lastName,
+		},
+		Status: scimUserStatus(state.active),
+	}
+
+	// Keycloak goes first, as in the invitations: the user then references a Keycloak
+	// user that exists
+	var user *models.User
+	err = saga.Run(ctx, constants.SagaSCIMCreateUser,
+		saga.Step{
+			Name: "create_keycloak_user",
+			Action: func(ctx context.Context) error {
+				if !keycloakUserCreated {
+					return nil
+				}
+				keycloakUser, err = s.auth.CreateUser(ctx, adminToken, &dtos.CreateUserRequest{UserRequest: keycloakRequest.UserRequest})
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				if !keycloakUserCreated {
+					return nil
+				}
+				return s.auth.DeleteUser(ctx, adminToken, keycloakUser.ID)
+			},
+		},
+		saga.Step{
+			Name: "update_keycloak_user",
+			Action: func(ctx context.Context) error {
+				if keycloakUserCreated && state.active {
+					return nil
+				}
+				return s.auth.UpdateUser(ctx, adminToken, keycloakUser.ID, keycloakRequest)
+			},
+		},
+		saga.Step{
+			Name: "create_user",
+			Action: func(ctx context.Context) error {
+				keycloakRequest.KeycloakID = keycloakUser.ID
+				user, err = s.userService.Create(ctx, &dtos.CreateUserRequest{UserRequest: keycloakRequest.UserRequest})
+				return err
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.updateProvisioning(ctx, user, state); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("SCIM user created",
+		zap.String("user_id", user.ID),
+		zap.String("keycloak_id", user.KeycloakID),
+		zap.Bool("keycloak_user_created", keycloakUserCreated),
+		zap.Bool("active", state.active),
+	)
+
+	return newSCIMUser(user), nil
+}
+
+func (s *scimService) ReplaceUser(ctx context.Context, userID string, req *dtos.SCIMUser) (*dtos.SCIMUser, error) {
+	user, err := s.getUser(userID, "scim_replace_user")
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := newSCIMUserState(req, user.DisabledAt == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyUserState(ctx, user, state)
+}
+
+func (s *scimService) PatchUser(ctx context.Context, userID string, req *dtos.SCIMPatchRequest) (*dtos.SCIMUser, error) {
+	user, err := s.getUser(userID, "scim_patch_user")
+	if err != nil {
+		return nil, err
+	}
+
+	patched := newSCIMUser(user)
+	for _, operation := range req.Operations {
+		if err := patchSCIMUser(patched, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := newSCIMUserState(patched, *patched.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyUserState(ctx, user, state)
+}
+
+func (s *scimService) DeleteUser(ctx context.Context, userID string) error {
+	user, err := s.getUser(userID, "scim_delete_user")
+	if err != nil {
+		return err
+	}
+
+	// Keycloak goes first: a failure of the database is retried by the identity provider,
+	// and deleting a Keycloak user that is already gone is a no-op
+	if user.KeycloakID != "" {
+		adminToken, err := s.tokens.AdminToken(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.auth.DeleteUser(ctx, adminToken, user.KeycloakID); err != nil {
+			return err
+		}
+	}
+	if err := s.userService.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+
+	logger.Log.Info("SCIM user deleted", zap.String("user_id", user.ID), zap.String("keycloak_id", user.KeycloakID))
+	return nil
+}
+
+func (s *scimService) ListGroups(ctx context.Context, req *dtos.SCIMListRequest) (*dtos.SCIMListResponse[dtos.SCIMGroup], error) {
+	filters, err := parseSCIMFilter(req.Filter, constants.SCIMGroupFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	withMembers := !slices.Contains(req.ExcludedAttributes, "members")
+	var preloads []string
+	if withMembers {
+		preloads = append(preloads, "Users")
+	}
+	companies, total, err := s.companyRepo.GetRange(filters, req.StartIndex-1, req.Count, preloads...)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]dtos.SCIMGroup, len(companies))
+	for i := range companies {
+		resources[i] = *newSCIMGroup(&companies[i], withMembers)
+	}
+	return newSCIMListResponse(resources, total, req.StartIndex), nil
+}
+
+func (s *scimService) GetGroup(ctx context.Context, companyID string, withMembers bool) (*dtos.SCIMGroup, error) {
+	company, err := s.getGroup(companyID, "scim_get_group", withMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSCIMGroup(company, withMembers), nil
+}
+
+func (s *scimService) CreateGroup(ctx context.Context, req *dtos.SCIMGroup) (*dtos.SCIMGroup, error) {
+	members, err := s.getMembers(memberIDs(req.Members), "scim_create_group")
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := s.companyService.Create(ctx, &dtos.CreateCompanyRequest{
+		CompanyRequest: dtos.CompanyRequest{Name: req.DisplayName},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateMembers(ctx, company, members, nil); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("SCIM group created", zap.String("company_id", company.ID), zap.Int("members", len(members)))
+
+	company.Users = members
+	return newSCIMGroup(company, true), nil
+}
+
+func (s *scimService) ReplaceGroup(ctx context.Context, companyID string, req *dtos.SCIMGroup) (*dtos.SCIMGroup, error) {
+	company, err := s.getGroup(companyID, "scim_replace_group", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyGroupState(ctx, company, req.DisplayName, memberIDs(req.Members), "scim_replace_group")
+}
+
+func (s *scimService) PatchGroup(ctx context.Context, companyID string, req *dtos.SCIMPatchRequest) (*dtos.SCIMGroup, error) {
+	company, err := s.getGroup(companyID, "scim_patch_group", true)
+	if err != nil {
+		return nil, err
+	}
+
+	name := company.Name
+	members := make([]string, len(company.Users))
+	for i, user := range company.Users {
+		members[i] = user.ID
+	}
+	for _, operation := range req.Operations {
+		name, members, err = patchSCIMGroup(name, members, operation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.applyGroupState(ctx, company, name, members, "scim_patch_group")
+}
+
+func (s *scimService) DeleteGroup(ctx context.Context, companyID string) error {
+	if _, err := s.getGroup(companyID, "scim_delete_group", false); err != nil {
+		return err
+	}
+	if err := s.companyService.Delete(ctx, companyID); err != nil {
+		return err
+	}
+
+	logger.Log.Info("SCIM group deleted", zap.String("company_id", companyID))
+	return nil
+}
+
+// applyUserState brings the Keycloak user and the user to the state, and returns the user
+func (s *scimService) applyUserState(ctx context.Context, user *models.User, state *scimUserState) (*dtos.SCIMUser, error) {
+	if err := s.checkEmailAvailable(state.email, user.ID); err != nil {
+		return nil, err
+	}
+
+	// Keycloak goes first: a failure of the database is retried by the identity provider,
+	// and updating the Keycloak user again changes nothing
+	if user.KeycloakID != "" {
+		adminToken, err := s.tokens.AdminToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = s.auth.UpdateUser(ctx, adminToken, user.KeycloakID, &dtos.UpdateUserRequest{
+			UserRequest: dtos.UserRequest{
+				Email:     state.email,
+				FirstName: state.firstName,
+				LastName:  state.lastName,
+			},
+			Status: scimUserStatus(state.active),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user.Email != state.email || user.FirstName != state.firstName || user.LastName != state.lastName {
+		if _, err := s.userService.Patch(ctx, user.ID, &dtos.PatchUserRequest{
+			Email:      state.email,
+			FirstName:  state.firstName,
+			LastName:   state.lastName,
+			KeycloakID: user.KeycloakID,
+		}); err != nil {
+			return nil, err
+		}
+		user.Email = state.email
+		user.FirstName = state.firstName
+		user.LastName = state.lastName
+	}
+	if err := s.updateProvisioning(ctx, user, state); err != nil {
+		return nil, err
+	}
+
+	return newSCIMUser(user), nil
+}
+
+// updateProvisioning writes the external ID of the user and whether they are disabled,
+// when they differ from the state
+func (s *scimService) updateProvisioning(ctx context.Context, user *models.User, state *scimUserState) error {
+	disabled := user.DisabledAt != nil
+	if user.ExternalID == state.externalID && disabled == !state.active {
+		return nil
+	}
+
+	user.ExternalID = state.externalID
+	switch {
+	case state.active:
+		user.DisabledAt = nil
+	case !disabled:
+		disabledAt := time.Now().UTC()
+		user.DisabledAt = &disabledAt
+	}
+	if err := s.userRepo.UpdateColumns(user, "external_id", "disabled_at"); err != nil {
+		return err
+	}
+	cache.Invalidate(ctx, s.cache, constants.UserCacheKeyPrefix+user.ID)
+
+	if disabled == state.active {
+		logger.Log.Info("SCIM user activation changed", zap.String("user_id", user.ID), zap.Bool("active", state.active))
+	}
+	return nil
+}
+
+// applyGroupState renames the company and makes the users of memberIDs its only members
+func (s *scimService) applyGroupState(ctx context.Context, company *models.Company, name string, ids []string, operation string) (*dtos.SCIMGroup, error) {
+	current := make(map[string]bool, len(company.Users))
+	for _, user := range company.Users {
+		current[user.ID] = true
+	}
+	desired := make(map[string]bool, len(ids))
+	var addedIDs []string
+	for _, id := range ids {
+		if !desired[id] && !current[id] {
+			addedIDs = append(addedIDs, id)
+		}
+		desired[id] = true
+	}
+	var removed, kept []models.User
+	for _, user := range company.Users {
+		if desired[user.ID] {
+			kept = append(kept, user)
+		} else {
+			removed = append(removed, user)
+		}
+	}
+
+	added, err := s.getMembers(addedIDs, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != company.Name {
+		if len(name) < 2 || len(name) > 100 {
+			return nil, scimValidationError(operation, constants.SCIMErrorInvalidValue, "The displayName must be 2 to 100 characters long")
+		}
+		if _, err := s.companyService.Update(ctx, company.ID, &dtos.UpdateCompanyRequest{
+			CompanyRequest: dtos.CompanyRequest{Name: name},
+		}); err != nil {
+			return nil, err
+		}
+		company.Name = name
+	}
+	if err := s.updateMembers(ctx, company, added, removed); err != nil {
+		return nil, err
+	}
+
+	company.Users = append(kept, added...)
+	return newSCIMGroup(company, true), nil
+}
+
+// updateMembers adds the users to the company and removes the others from it, in the
+// Keycloak organization of the company first
+func (s *scimService) updateMembers(ctx context.Context, company *models.Company, added []models.User, removed []models.User) error {
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	var adminToken string
+	if company.KeycloakID != "" {
+		var err error
+		adminToken, err = s.tokens.AdminToken(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range added {
+		if company.KeycloakID != "" && user.KeycloakID != "" {
+			if err := s.auth.AddUserToOrganization(ctx, adminToken, user.KeycloakID, company.KeycloakID); err != nil {
+				return err
+			}
+		}
+		if _, err := s.userService.AddCompany(ctx, user.ID, company.ID); err != nil {
+			return err
+		}
+	}
+	for _, user := range removed {
+		if company.KeycloakID != "" && user.KeycloakID != "" {
+			if err := s.auth.RemoveUserFromOrganization(ctx, adminToken, user.KeycloakID, company.KeycloakID); err != nil {
+				return err
+			}
+		}
+		if _, err := s.userService.RemoveCompany(ctx, user.ID, company.ID); err != nil {
+			return err
+		}
+	}
+
+	logger.Log.Info("SCIM group members updated",
+		zap.String("company_id", company.ID),
+		zap.Int("added", len(added)),
+		zap.Int("removed", len(removed)),
+	)
+	return nil
+}
+
+// checkEmailAvailable fails when another user than userID has the email
+func (s *scimService) checkEmailAvailable(email string, userID string) error {
+	existing, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+	if existing.ID == userID {
+		return nil
+	}
+
+	return errors.ConflictError("A user with this userName already exists", nil).
+		WithOperation("scim_check_email").
+		WithResource("user").
+		WithContext(constants.SCIMTypeContextKey, constants.SCIMErrorUniqueness)
+}
+
+// getUser loads the user with their companies
+func (s *scimService) getUser(userID string, operation string) (*models.User, error) {
+	user, err := s.userRepo.GetOneByID(userID, "Companies")
+	if err != nil {
+		return nil, errors.NotFoundError("User", err).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	return user, nil
+}
+
+// getGroup loads the company, with its members when withMembers is set; sandboxes are not
+// groups
+func (s *scimService) getGroup(companyID string, operation string, withMembers bool) (*models.Company, error) {
+	var preloads []string
+	if withMembers {
+		preloads = append(preloads, "Users")
+	}
+	companies, _, err := s.companyRepo.GetRange(map[string]string{"id": companyID}, 0, 1, preloads...)
+	if err == nil && len(companies) == 0 {
+		err = errors.NotFoundError("Group", nil)
+	}
+	if err != nil {
+		return nil, errors.NotFoundError("Group", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return &companies[0], nil
+}
+
+// getMembers loads the users of ids, failing on the first one that does not exist
+func (s *scimService) getMembers(ids []string, operation string) ([]models.User, error) {
+	members := make([]models.User, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := s.userRepo.GetOneByID(id)
+		if err != nil {
+			if appErr := errors.GetAppError(err); appErr != nil && appErr.Type != errors.ErrorTypeNotFound {
+				return nil, err
+			}
+			return nil, scimValidationError(operation, constants.SCIMErrorInvalidValue, "The member "+id+" is not a user").
+				WithContext("user_id", id)
+		}
+		members = append(members, *user)
+	}
+
+	return members, nil
+}
+
+// newSCIMUserState reads the state of a user from its SCIM representation; active is the
+// state of a user whose representation omits it
+func newSCIMUserState(user *dtos.SCIMUser, active bool) (*scimUserState, error) {
+	email, err := scimUserEmail(user)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &scimUserState{
+		email:      email,
+		externalID: user.ExternalID,
+		active:     active,
+	}
+	if user.Name != nil {
+		state.firstName = user.Name.GivenName
+		state.lastName = user.Name.FamilyName
+	}
+	if user.Active != nil {
+		state.active = *user.Active
+	}
+	return state, nil
+}
+
+// scimUserEmail returns the email of a SCIM user: the userName when it is an email, or
+// else their primary email, or else their first one
+func scimUserEmail(user *dtos.SCIMUser) (string, error) {
+	candidates := []string{user.UserName}
+	for _, email := range user.Emails {
+		if email.Primary {
+			candidates = append(candidates, email.Value)
+		}
+	}
+	for _, email := range user.Emails {
+		candidates = append(candidates, email.Value)
+	}
+
+	for _, candidate := range candidates {
+		if address, err := mail.ParseAddress(candidate); err == nil && address.Address == candidate {
+			return strings.ToLower(candidate), nil
+		}
+	}
+
+	return "", scimValidationError("scim_user_email", constants.SCIMErrorInvalidValue, "The userName or an email of the user must be an email address")
+}
+
+// patchSCIMUser applies an operation of a PATCH request to the SCIM representation of a
+// user. The attributes the service does not keep are ignored, so that the identity
+// providers can send their whole mapping.
+func patchSCIMUser(user *dtos.SCIMUser, operation dtos.SCIMPatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	switch op {
+	case constants.SCIMPatchAdd, constants.SCIMPatchReplace:
+	case constants.SCIMPatchRemove:
+		return removeSCIMUserAttribute(user, operation.Path)
+	default:
+		return scimValidationError("scim_patch_user", constants.SCIMErrorInvalidSyntax, "Unsupported operation "+operation.Op)
+	}
+
+	if operation.Path != "" {
+		return setSCIMUserAttribute(user, operation.Path, operation.Value)
+	}
+
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+		return scimValidationError("scim_patch_user", constants.SCIMErrorInvalidValue, "The value of an operation without path must be an object")
+	}
+	paths := make([]string, 0, len(attributes))
+	for path := range attributes {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		if err := setSCIMUserAttribute(user, path, attributes[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSCIMUserAttribute sets an attribute of a user from the value of an add or replace
+// operation
+func setSCIMUserAttribute(user *dtos.SCIMUser, path string, value json.RawMessage) error {
+	var err error
+	switch lowerPath := strings.ToLower(path); {
+	case lowerPath == "active":
+		var active bool
+		active, err = scimBool(value)
+		user.Active = &active
+	case lowerPath == "username":
+		err = json.Unmarshal(value, &user.UserName)
+	case lowerPath == "externalid":
+		err = json.Unmarshal(value, &user.ExternalID)
+	case lowerPath == "name":
+		var name dtos.SCIMName
+		if err = json.Unmarshal(value, &name); err == nil {
+			user.Name = &name
+		}
+	case lowerPath == "name.givenname":
+		err = json.Unmarshal(value, &scimName(user).GivenName)
+	case lowerPath == "name.familyname":
+		err = json.Unmarshal(value, &scimName(user).FamilyName)
+	case lowerPath == "emails":
+		err = json.Unmarshal(value, &user.Emails)
+	case scimEmailPathPattern.MatchString(path):
+		var email string
+		if err = json.Unmarshal(value, &email); err == nil {
+			user.Emails = []dtos.SCIMEmail{{Value: email, Primary: true}}
+		}
+	case lowerPath == "id" || lowerPath == "groups" || strings.HasPrefix(lowerPath, "meta"):
+		return scimValidationError("scim_patch_user", constants.SCIMErrorMutability, "The attribute "+path+" cannot be changed")
+	}
+	if err != nil {
+		return scimValidationError("scim_patch_user", constants.SCIMErrorInvalidValue, "Invalid value of "+path)
+	}
+
+	return nil
+}
+
+// removeSCIMUserAttribute clears an attribute of a user for a remove operation
+func removeSCIMUserAttribute(user *dtos.SCIMUser, path string) error {
+	switch strings.ToLower(path) {
+	case "externalid":
+		user.ExternalID = ""
+	case "name.givenname":
+		scimName(user).GivenName = ""
+	case "name.familyname":
+		scimName(user).FamilyName = ""
+	case "name":
+		user.Name = nil
+	case "displayname", "name.formatted":
+	case "":
+		return scimValidationError("scim_patch_user", constants.SCIMErrorNoTarget, "A remove operation needs a path")
+	default:
+		return scimValidationError("scim_patch_user", constants.SCIMErrorMutability, "The attribute "+path+" cannot be removed")
+	}
+
+	return nil
+}
+
+// patchSCIMGroup applies an operation of a PATCH request to the name and member IDs of a
+// group
+func patchSCIMGroup(name string, members []string, operation dtos.SCIMPatchOperation) (string, []string, error) {
+	op := strings.ToLower(operation.Op)
+	if op != constants.SCIMPatchAdd && op != constants.SCIMPatchReplace && op != constants.SCIMPatchRemove {
+		return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidSyntax, "Unsupported operation "+operation.Op)
+	}
+
+	if operation.Path == "" {
+		if op == constants.SCIMPatchRemove {
+			return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorNoTarget, "A remove operation needs a path")
+		}
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidValue, "The value of an operation without path must be an object")
+		}
+		for path, value := range attributes {
+			var err error
+			name, members, err = patchSCIMGroup(name, members, dtos.SCIMPatchOperation{Op: op, Path: path, Value: value})
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		return name, members, nil
+	}
+
+	if match := scimMemberPathPattern.FindStringSubmatch(operation.Path); match != nil {
+		var id string
+		if op != constants.SCIMPatchRemove || json.Unmarshal([]byte(match[1]), &id) != nil {
+			return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidPath, "Unsupported path "+operation.Path)
+		}
+		return name, slices.DeleteFunc(members, func(member string) bool { return member == id }), nil
+	}
+
+	switch strings.ToLower(operation.Path) {
+	case "displayname":
+		if op == constants.SCIMPatchRemove || json.Unmarshal(operation.Value, &name) != nil {
+			return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidValue, "Invalid value of displayName")
+		}
+		return name, members, nil
+	case "members":
+		var values []dtos.SCIMMember
+		if len(operation.Value) > 0 && json.Unmarshal(operation.Value, &values) != nil {
+			return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidValue, "Invalid value of members")
+		}
+		ids := memberIDs(values)
+		switch {
+		case op == constants.SCIMPatchAdd:
+			return name, append(members, ids...), nil
+		case op == constants.SCIMPatchReplace:
+			return name, ids, nil
+		case len(ids) == 0:
+			return name, nil, nil
+		default:
+			return name, slices.DeleteFunc(members, func(member string) bool { return slices.Contains(ids, member) }), nil
+		}
+	case "externalid":
+		return name, members, nil
+	default:
+		return "", nil, scimValidationError("scim_patch_group", constants.SCIMErrorInvalidPath, "Unsupported path "+operation.Path)
+	}
+}
+
+// parseSCIMFilter returns the columns and values of a filter on the equality of one of the
+// attributes of columns; an empty filter has none
+func parseSCIMFilter(filter string, columns map[string]string) (map[string]string, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return nil, scimValidationError("scim_filter", constants.SCIMErrorInvalidFilter, "Only the filters on the equality of one attribute are supported, e.g. userName eq \"john.doe@example.com\"")
+	}
+	column, ok := columns[strings.ToLower(match[1])]
+	if !ok {
+		return nil, scimValidationError("scim_filter", constants.SCIMErrorInvalidFilter, "Filtering on "+match[1]+" is not supported")
+	}
+	var value string
+	if err := json.Unmarshal([]byte(match[2]), &value); err != nil {
+		return nil, scimValidationError("scim_filter", constants.SCIMErrorInvalidFilter, "Invalid value of the filter")
+	}
+
+	return map[string]string{column: value}, nil
+}
+
+// scimBool reads a boolean, or the string of a boolean as some identity providers send
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
+
+// scimName returns the name of the user, creating it when they have none
+func scimName(user *dtos.SCIMUser) *dtos.SCIMName {
+	if user.Name == nil {
+		user.Name = &dtos.SCIMName{}
+	}
+	return user.Name
+}
+
+// scimUserStatus returns the status of an active or inactive user
+func scimUserStatus(active bool) constants.UserStatus {
+	if active {
+		return constants.UserStatusActive
+	}
+	return constants.UserStatusInactive
+}
+
+// scimValidationError returns a validation error rendered with the SCIM error type
+func scimValidationError(operation string, scimType string, message string) *errors.AppError {
+	return errors.ValidationError(message, nil).
+		WithOperation(operation).
+		WithResource("scim").
+		WithContext(constants.SCIMTypeContextKey, scimType)
+}
+
+// memberIDs returns the user IDs of the members
+func memberIDs(members []dtos.SCIMMember) []string {
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.Value
+	}
+	return ids
+}
+
+// newSCIMUser returns the SCIM representation of a user
+func newSCIMUser(user *models.User) *dtos.SCIMUser {
+	active := user.DisabledAt == nil
+	createdAt, updatedAt := user.CreatedAt, user.UpdatedAt
+	result := &dtos.SCIMUser{
+		Schemas:    []string{constants.SCIMSchemaUser},
+		ID:         user.ID,
+		ExternalID: user.ExternalID,
+		UserName:   user.Email,
+		Name: &dtos.SCIMName{
+			Formatted:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+			GivenName:  user.FirstName,
+			FamilyName: user.LastName,
+		},
+		DisplayName: strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Emails:      []dtos.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &dtos.SCIMMeta{
+			ResourceType: constants.SCIMResourceUser,
+			Created:      &createdAt,
+			LastModified: &updatedAt,
+		},
+	}
+	for _, company := range user.Companies {
+		result.Groups = append(result.Groups, dtos.SCIMMember{Value: company.ID, Display: company.Name})
+	}
+
+	return result
+}
+
+// newSCIMGroup returns the SCIM representation of a company, with its members when
+// withMembers is set
+func newSCIMGroup(company *models.Company, withMembers bool) *dtos.SCIMGroup {
+	createdAt, updatedAt := company.CreatedAt, company.UpdatedAt
+	result := &dtos.SCIMGroup{
+		Schemas:     []string{constants.SCIMSchemaGroup},
+		ID:          company.ID,
+		DisplayName: company.Name,
+		Meta: &dtos.SCIMMeta{
+			ResourceType: constants.SCIMResourceGroup,
+			Created:      &createdAt,
+			LastModified: &updatedAt,
+		},
+	}
+	if withMembers {
+		result.Members = make([]dtos.SCIMMember, len(company.Users))
+		for i, user := range company.Users {
+			result.Members[i] = dtos.SCIMMember{Value: user.ID, Display: user.Email}
+		}
+	}
+
+	return result
+}
+
+// newSCIMListResponse returns a page of resources starting at startIndex
+func newSCIMListResponse[T any](resources []T, total int64, startIndex int) *dtos.SCIMListResponse[T] {
+	return &dtos.SCIMListResponse[T]{
+		Schemas:      []string{constants.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:   "no filter",
+			filter: "",
+		},
+		{
+			name:     "userName",
+			filter:   `userName eq "John.Doe@example.com"`,
+			expected: map[string]string{"email": "John.Doe@example.com"},
+		},
+		{
+			name:     "case-insensitive attribute and operator",
+			filter:   `EXTERNALID Eq "abc-123"`,
+			expected: map[string]string{"external_id": "abc-123"},
+		},
+		{
+			name:     "escaped quote",
+			filter:   `emails.value eq "a\"b@example.com"`,
+			expected: map[string]string{"email": `a"b@example.com`},
+		},
+		{
+			name:    "unsupported operator",
+			filter:  `userName co "john"`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported attribute",
+			filter:  `title eq "Engineer"`,
+			wantErr: true,
+		},
+		{
+			name:    "logical expression",
+			filter:  `userName eq "a@example.com" and active eq true`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := parseSCIMFilter(tt.filter, constants.SCIMUserFilterColumns)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, constants.SCIMErrorInvalidFilter, errors.GetAppError(err).Context[constants.SCIMTypeContextKey])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filters)
+		})
+	}
+}
+
+func TestPatchSCIMUser(t *testing.T) {
+	active := true
+	tests := []struct {
+		name      string
+		operation dtos.SCIMPatchOperation
+		check     func(t *testing.T, user *dtos.SCIMUser)
+		scimType  string
+	}{
+		{
+			name:      "active as the string Entra ID sends",
+			operation: dtos.SCIMPatchOperation{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+			check: func(t *testing.T, user *dtos.SCIMUser) {
+				assert.False(t, *user.Active)
+			},
+		},
+		{
+			name:      "attributes without path",
+			operation: dtos.SCIMPatchOperation{Op: "replace", Value: json.RawMessage(`{"name.givenName":"Jane","externalId":"ext-2","title":"ignored"}`)},
+			check: func(t *testing.T, user *dtos.SCIMUser) {
+				assert.Equal(t, "Jane", user.Name.GivenName)
+				assert.Equal(t, "ext-2", user.ExternalID)
+			},
+		},
+		{
+			name:      "email with a value filter",
+			operation: dtos.SCIMPatchOperation{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"jane@example.com"`)},
+			check: func(t *testing.T, user *dtos.SCIMUser) {
+				assert.Equal(t, []dtos.SCIMEmail{{Value: "jane@example.com", Primary: true}}, user.Emails)
+			},
+		},
+		{
+			name:      "groups are read-only",
+			operation: dtos.SCIMPatchOperation{Op: "add", Path: "groups", Value: json.RawMessage(`[{"value":"company-1"}]`)},
+			scimType:  constants.SCIMErrorMutability,
+		},
+		{
+			name:      "remove without path",
+			operation: dtos.SCIMPatchOperation{Op: "remove"},
+			scimType:  constants.SCIMErrorNoTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &dtos.SCIMUser{UserName: "john@example.com", Name: &dtos.SCIMName{GivenName: "John"}, Active: &active}
+			err := patchSCIMUser(user, tt.operation)
+
+			if tt.scimType != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.scimType, errors.GetAppError(err).Context[constants.SCIMTypeContextKey])
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, user)
+		})
+	}
+}
+
+func TestPatchSCIMGroup(t *testing.T) {
+	tests := []struct {
+		name            string
+		operation       dtos.SCIMPatchOperation
+		expectedName    string
+		expectedMembers []string
+		wantErr         bool
+	}{
+		{
+			name:            "add members",
+			operation:       dtos.SCIMPatchOperation{Op: "Add", Path: "members", Value: json.RawMessage(`[{"value":"user-3"}]`)},
+			expectedName:    "Acme",
+			expectedMembers: []string{"user-1", "user-2", "user-3"},
+		},
+		{
+			name:            "remove a member by filter",
+			operation:       dtos.SCIMPatchOperation{Op: "remove", Path: `members[value eq "user-1"]`},
+			expectedName:    "Acme",
+			expectedMembers: []string{"user-2"},
+		},
+		{
+			name:            "remove members by value",
+			operation:       dtos.SCIMPatchOperation{Op: "remove", Path: "members", Value: json.RawMessage(`[{"value":"user-2"}]`)},
+			expectedName:    "Acme",
+			expectedMembers: []string{"user-1"},
+		},
+		{
+			name:            "replace displayName without path",
+			operation:       dtos.SCIMPatchOperation{Op: "replace", Value: json.RawMessage(`{"displayName":"Acme Corp"}`)},
+			expectedName:    "Acme Corp",
+			expectedMembers: []string{"user-1", "user-2"},
+		},
+		{
+			name:      "unsupported path",
+			operation: dtos.SCIMPatchOperation{Op: "replace", Path: "owner", Value: json.RawMessage(`"user-1"`)},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, members, err := patchSCIMGroup("Acme", []string{"user-1", "user-2"}, tt.operation)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedMembers, members)
+		})
+	}
+}
+
+func TestSCIMService_CreateUser_Conflict(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByEmail", "john@example.com").
+		Return(factory.User().WithID("user-1").WithEmail("john@example.com").Build(), nil)
+	authProvider := new(MockAuthProvider)
+
+	service := ProvideSCIMService(authProvider, newMockTokenProvider(), nil, nil, userRepo, new(MockCompanyRepository), new(MockCache))
+	_, err := service.CreateUser(context.Background(), &dtos.SCIMUser{UserName: "John@example.com"})
+
+	require.Error(t, err)
+	appErr := errors.GetAppError(err)
+	assert.Equal(t, errors.ErrorTypeConflict, appErr.Type)
+	assert.Equal(t, constants.SCIMErrorUniqueness, appErr.Context[constants.SCIMTypeContextKey])
+	authProvider.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSCIMService_PatchUser_Deactivate(t *testing.T) {
+	user := factory.User().WithID("user-1").WithName("John", "Doe").WithEmail("john@example.com").WithKeycloakID("kc-1").Build()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetOneByID", "user-1", []string{"Companies"}).Return(user, nil)
+	userRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	userRepo.On("UpdateColumns", user, []string{"external_id", "disabled_at"}).Return(nil)
+	authProvider := new(MockAuthProvider)
+	authProvider.On("UpdateUser", mock.Anything, "admin-token", "kc-1", mock.MatchedBy(func(req *dtos.UpdateUserRequest) bool {
+		return req.Status == constants.UserStatusInactive && req.Email == "john@example.com"
+	})).Return(nil)
+	cache := new(MockCache)
+	cache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+"user-1").Return(nil)
+
+	// The user service is not needed: the names and the email do not change
+	service := ProvideSCIMService(authProvider, newMockTokenProvider(), nil, nil, userRepo, new(MockCompanyRepository), cache)
+	result, err := service.PatchUser(context.Background(), "user-1", &dtos.SCIMPatchRequest{
+		Operations: []dtos.SCIMPatchOperation{{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)}},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, *result.Active)
+	assert.NotNil(t, user.DisabledAt)
+	authProvider.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
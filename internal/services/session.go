@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// SessionRequester is the user listing or revoking sessions: an admin reaches the sessions
+// of every user, any other user only their own
+type SessionRequester struct {
+	// KeycloakID is the subject of the token of the request
+	KeycloakID string
+	// SessionID is the session of the token of the request, its sid claim
+	SessionID string
+	Admin     bool
+}
+
+// SessionService lists and revokes the sessions of the users, kept by Keycloak
+type SessionService interface {
+	// List returns the active sessions of the user, the most recent first
+	List(ctx context.Context, userID string, requester SessionRequester) ([]dtos.SessionResponse, error)
+	// Revoke ends a session of the user, logging them out of that device
+	Revoke(ctx context.Context, userID string, sessionID string, requester SessionRequester) error
+}
+
+// sessionService implements SessionService
+type sessionService struct {
+	auth     auth.AuthService
+	tokens   auth.TokenProvider
+	userRepo repositories.UserRepository
+}
+
+// ProvideSessionService creates a new session service
+func ProvideSessionService(
+	authProvider auth.AuthService,
+	tokens auth.TokenProvider,
+	userRepo repositories.UserRepository,
+) SessionService {
+	return &sessionService{
+		auth:     authProvider,
+		tokens:   tokens,
+		userRepo: userRepo,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, userID string, requester SessionRequester) ([]dtos.SessionResponse, error) {
+	user, adminToken, err := s.keycloakUser(ctx, "list_sessions", userID, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.auth.GetUserSessions(ctx, adminToken, user.KeycloakID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dtos.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = dtos.SessionResponse{
+			ID:           session.ID,
+			IPAddress:    session.IPAddress,
+			StartedAt:    session.Start,
+			LastAccessAt: session.LastAccess,
+			Clients:      session.Clients,
+			Current:      session.ID == requester.SessionID,
+		}
+	}
+	return responses, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID string, sessionID string, requester SessionRequester) error {
+	user, adminToken, err := s.keycloakUser(ctx, "revoke_session", userID, requester)
+	if err != nil {
+		return err
+	}
+
+	// The admin API ends any session of the realm by its ID, so the session is checked to
+	// belong to the user first
+	sessions, err := s.auth.GetUserSessions(ctx, adminToken, user.KeycloakID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(sessions, func(session auth.Session) bool { return session.ID == sessionID }) {
+		return errors.NotFoundError("Session", nil).
+			WithOperation("revoke_session").
+			WithResource("session").
+			WithContext("user_id", userID).
+			WithContext("session_id", sessionID)
+	}
+
+	if err := s.auth.LogoutUserSession(ctx, adminToken, sessionID); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Session revoked",
+		zap.String("user_id", user.ID),
+		zap.String("session_id", sessionID),
+		zap.Bool("by_admin", requester.KeycloakID != user.KeycloakID),
+	)
+	return nil
+}
+
+// keycloakUser loads the user and an admin token, failing when the requester may not reach
+// the sessions of the user or the user has no Keycloak user to hold them
+func (s *sessionService) keycloakUser(ctx context.Context, operation string, userID string, requester SessionRequester) (*models.User, string, error) {
+	user, err := s.userRepo.GetOneByID(userID)
+	if err != nil {
+		return nil, "", errors.NotFoundError("User", err).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+	if !requester.Admin && (user.KeycloakID == "" || user.KeycloakID != requester.KeycloakID) {
+		return nil, "", errors.ForbiddenError("Only admins can access the sessions of another user", nil).
+			WithOperation(operation).
+			WithResource("session").
+			WithContext("user_id", userID)
+	}
+	if user.KeycloakID == "" {
+		return nil, "", errors.ValidationError("The user has no Keycloak user", nil).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	adminToken, err := s.tokens.AdminToken(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, adminToken, nil
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/auth"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionService_List(t *testing.T) {
+	tests := []struct {
+		name          string
+		user          *models.User
+		requester     SessionRequester
+		setupMocks    func(authProvider *MockAuthProvider)
+		expectedError errors.ErrorType
+	}{
+		{
+			name:      "own sessions",
+			user:      factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			requester: SessionRequester{KeycloakID: "kc-1", SessionID: "session-2"},
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetUserSessions", mock.Anything, "admin-token", "kc-1").Return([]auth.Session{
+					{ID: "session-2", IPAddress: "203.0.113.7", LastAccess: time.Now()},
+					{ID: "session-1", IPAddress: "198.51.100.4", LastAccess: time.Now().Add(-time.Hour)},
+				}, nil)
+			},
+		},
+		{
+			name:      "sessions of another user by an admin",
+			user:      factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			requester: SessionRequester{KeycloakID: "kc-admin", SessionID: "session-9", Admin: true},
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetUserSessions", mock.Anything, "admin-token", "kc-1").Return([]auth.Session{}, nil)
+			},
+		},
+		{
+			name:          "sessions of another user",
+			user:          factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(),
+			requester:     SessionRequester{KeycloakID: "kc-2"},
+			setupMocks:    func(authProvider *MockAuthProvider) {},
+			expectedError: errors.ErrorTypeForbidden,
+		},
+		{
+			name:          "user without Keycloak user",
+			user:          factory.User().WithID("user-1").WithKeycloakID("").Build(),
+			requester:     SessionRequester{KeycloakID: "kc-admin", Admin: true},
+			setupMocks:    func(authProvider *MockAuthProvider) {},
+			expectedError: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetOneByID", "user-1", []string{}).Return(tt.user, nil)
+			tt.setupMocks(authProvider)
+
+			service := ProvideSessionService(authProvider, newMockTokenProvider(), userRepo)
+			sessions, err := service.List(context.Background(), "user-1", tt.requester)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+			} else {
+				require.NoError(t, err)
+				for _, session := range sessions {
+					assert.Equal(t, session.ID == tt.requester.SessionID, session.Current)
+				}
+			}
+			authProvider.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	tests := []struct {
+		name          string
+		sessionID     string
+		requester     SessionRequester
+		setupMocks    func(authProvider *MockAuthProvider)
+		expectedError errors.ErrorType
+	}{
+		{
+			name:      "own session",
+			sessionID: "session-1",
+			requester: SessionRequester{KeycloakID: "kc-1"},
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetUserSessions", mock.Anything, "admin-token", "kc-1").Return([]auth.Session{{ID: "session-1"}}, nil)
+				authProvider.On("LogoutUserSession", mock.Anything, "admin-token", "session-1").Return(nil)
+			},
+		},
+		{
+			name:      "session of another user of the realm",
+			sessionID: "session-other",
+			requester: SessionRequester{KeycloakID: "kc-admin", Admin: true},
+			setupMocks: func(authProvider *MockAuthProvider) {
+				authProvider.On("GetUserSessions", mock.Anything, "admin-token", "kc-1").Return([]auth.Session{{ID: "session-1"}}, nil)
+			},
+			expectedError: errors.ErrorTypeNotFound,
+		},
+		{
+			name:          "session of another user",
+			sessionID:     "session-1",
+			requester:     SessionRequester{KeycloakID: "kc-2"},
+			setupMocks:    func(authProvider *MockAuthProvider) {},
+			expectedError: errors.ErrorTypeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := new(MockAuthProvider)
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetOneByID", "user-1", []string{}).
+				Return(factory.User().WithID("user-1").WithKeycloakID("kc-1").Build(), nil)
+			tt.setupMocks(authProvider)
+
+			service := ProvideSessionService(authProvider, newMockTokenProvider(), userRepo)
+			err := service.Revoke(context.Background(), "user-1", tt.sessionID, tt.requester)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				authProvider.AssertNotCalled(t, "LogoutUserSession", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+			}
+			authProvider.AssertExpectations(t)
+		})
+	}
+}
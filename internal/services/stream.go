@@ -0,0 +1,32 @@
+package services
+
+import (
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+)
+
+// streamPages reads a listing page after page of constants.StreamBatchSize entities,
+// newest first, passing each page to yield until the listing or yield ends. The pages are
+// read after the cursor of the last entity of the previous page, so a long stream neither
+// rescans the rows already read nor skips or repeats rows inserted meanwhile.
+func streamPages[T any](fetch func(after *dtos.Cursor, limit int) ([]T, error), cursor func(T) dtos.Cursor, yield func([]T) error) error {
+	var after *dtos.Cursor
+	for {
+		page, err := fetch(after, constants.StreamBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := yield(page); err != nil {
+			return err
+		}
+		if len(page) < constants.StreamBatchSize {
+			return nil
+		}
+
+		last := cursor(page[len(page)-1])
+		after = &last
+	}
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/utils"
+	"golang-boilerplate/internal/vault"
+
+	"go.uber.org/zap"
+)
+
+type TenantCredentialService interface {
+	Create(ctx context.Context, companyID string, req *dtos.CreateTenantCredentialRequest) (*models.TenantCredential, error)
+	List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.TenantCredential], error)
+	GetOneByID(ctx context.Context, companyID string, credentialID string) (*models.TenantCredential, error)
+	Rotate(ctx context.Context, companyID string, credentialID string, req *dtos.RotateTenantCredentialRequest) (*models.TenantCredential, error)
+	Delete(ctx context.Context, companyID string, credentialID string) error
+	Resolve(ctx context.Context, companyID string, provider string, name string) (map[string]string, error)
+	StorageAdapter(ctx context.Context, companyID string) (storage.StorageAdapter, error)
+	// Reseal encrypts the secret of a credential again under a new data key, e.g. after the
+	// KMS master key was rotated. The secret and its version are unchanged.
+	Reseal(ctx context.Context, credential *models.TenantCredential) error
+}
+
+// tenantStorage is a storage adapter built from a tenant S3 credential version
+type tenantStorage struct {
+	version int
+	adapter storage.StorageAdapter
+}
+
+// tenantCredentialService handles the tenant credentials vault
+type tenantCredentialService struct {
+	credentialRepo repositories.TenantCredentialRepository
+	companyRepo    repositories.CompanyRepository
+	keys           kms.KeyManager
+	storage        storage.StorageAdapter
+	cfg            *config.Config
+
+	// storages caches the tenant storage adapters by credential id
+	storagesMu sync.Mutex
+	storages   map[string]tenantStorage
+}
+
+// ProvideTenantCredentialService creates a new tenant credential service
+func ProvideTenantCredentialService(
+	credentialRepo repositories.TenantCredentialRepository,
+	companyRepo repositories.CompanyRepository,
+	keys kms.KeyManager,
+	storage storage.StorageAdapter,
+	cfg *config.Config,
+) TenantCredentialService {
+	return &tenantCredentialService{
+		credentialRepo: credentialRepo,
+		companyRepo:    companyRepo,
+		keys:           keys,
+		storage:        storage,
+		cfg:            cfg,
+		storages:       make(map[string]tenantStorage),
+	}
+}
+
+func (s *tenantCredentialService) Create(ctx context.Context, companyID string, req *dtos.CreateTenantCredentialRequest) (*models.TenantCredential, error) {
+	if err := validateTenantSecret(req.Provider, req.Secret); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("create_tenant_credential").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	exists, err := s.credentialRepo.Exists(ctx, companyID, req.Provider, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ConflictError("Tenant credential already exists", nil).
+			WithOperation("create_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("company_id", companyID).
+			WithContext("provider", req.Provider).
+			WithContext("name", req.Name)
+	}
+
+	credential := &models.TenantCredential{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: companyID,
+		Provider:  req.Provider,
+		Name:      req.Name,
+		Version:   1,
+	}
+	if err := s.seal(ctx, credential, req.Secret, "create_tenant_credential"); err != nil {
+		return nil, err
+	}
+
+	if err := s.credentialRepo.Create(ctx, credential); err != nil {
+		s.logError("create_tenant_credential", credential, err)
+		return nil, err
+	}
+
+	logger.Log.Info("Tenant credential created",
+		zap.String("company_id", companyID),
+		zap.String("credential_id", credential.ID),
+		zap.String("provider", credential.Provider),
+		zap.String("name", credential.Name),
+	)
+
+	return credential, nil
+}
+
+func (s *tenantCredentialService) List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.TenantCredential], error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_tenant_credentials").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	credentials, err := s.credentialRepo.GetByCompanyID(ctx, companyID, pageableRequest)
+	if err != nil {
+		s.logError("get_tenant_credentials", &models.TenantCredential{CompanyID: companyID}, err)
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (s *tenantCredentialService) GetOneByID(ctx context.Context, companyID string, credentialID string) (*models.TenantCredential, error) {
+	return s.credentialRepo.GetByID(ctx, companyID, credentialID)
+}
+
+// Rotate replaces the secret of a credential. The new secret is sealed under a new data
+// key and the version is incremented, which also drops the cached integration instances.
+func (s *tenantCredentialService) Rotate(ctx context.Context, companyID string, credentialID string, req *dtos.RotateTenantCredentialRequest) (*models.TenantCredential, error) {
+	credential, err := s.credentialRepo.GetByID(ctx, companyID, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTenantSecret(credential.Provider, req.Secret); err != nil {
+		return nil, err
+	}
+
+	if err := s.seal(ctx, credential, req.Secret, "rotate_tenant_credential"); err != nil {
+		return nil, err
+	}
+	rotatedAt := time.Now()
+	credential.Version++
+	credential.RotatedAt = &rotatedAt
+
+	if err := s.credentialRepo.UpdateSecret(ctx, credential); err != nil {
+		s.logError("rotate_tenant_credential", credential, err)
+		return nil, err
+	}
+	s.forgetStorage(credential.ID)
+
+	logger.Log.Info("Tenant credential rotated",
+		zap.String("company_id", companyID),
+		zap.String("credential_id", credential.ID),
+		zap.String("provider", credential.Provider),
+		zap.Int("version", credential.Version),
+	)
+
+	return credential, nil
+}
+
+func (s *tenantCredentialService) Reseal(ctx context.Context, credential *models.TenantCredential) error {
+	secret, err := s.open(ctx, credential)
+	if err != nil {
+		return err
+	}
+
+	if err := s.seal(ctx, credential, secret, "reseal_tenant_credential"); err != nil {
+		return err
+	}
+
+	if err := s.credentialRepo.UpdateSecret(ctx, credential); err != nil {
+		s.logError("reseal_tenant_credential", credential, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *tenantCredentialService) Delete(ctx context.Context, companyID string, credentialID string) error {
+	credential, err := s.credentialRepo.GetByID(ctx, companyID, credentialID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.credentialRepo.Delete(ctx, credential); err != nil {
+		s.logError("delete_tenant_credential", credential, err)
+		return err
+	}
+	s.forgetStorage(credential.ID)
+
+	logger.Log.Info("Tenant credential deleted",
+		zap.String("company_id", companyID),
+		zap.String("credential_id", credential.ID),
+		zap.String("provider", credential.Provider),
+	)
+
+	return nil
+}
+
+// Resolve returns the decrypted secret of a credential, for building the integration
+// instances of a tenant. It must never be exposed through an API response.
+func (s *tenantCredentialService) Resolve(ctx context.Context, companyID string, provider string, name string) (map[string]string, error) {
+	credential, err := s.credentialRepo.GetByProvider(ctx, companyID, provider, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.open(ctx, credential)
+}
+
+// StorageAdapter returns the storage of a company: an S3 adapter built from its default
+// S3 credential when it has one, the platform storage otherwise
+func (s *tenantCredentialService) StorageAdapter(ctx context.Context, companyID string) (storage.StorageAdapter, error) {
+	credential, err := s.credentialRepo.GetByProvider(ctx, companyID, constants.TenantCredentialProviderS3, constants.TenantCredentialDefaultName)
+	if err != nil {
+		if errors.GetHTTPStatus(err) == http.StatusNotFound {
+			return s.storage, nil
+		}
+		return nil, err
+	}
+
+	s.storagesMu.Lock()
+	cached, ok := s.storages[credential.ID]
+	s.storagesMu.Unlock()
+	if ok && cached.version == credential.Version {
+		return cached.adapter, nil
+	}
+
+	secret, err := s.open(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter, err := storage.NewTenantS3Adapter(s.cfg, secret["region"], secret["bucket"], secret["access_key"], secret["secret_key"])
+	if err != nil {
+		return nil, errors.ExternalServiceError("Failed to initialize tenant storage", err).
+			WithOperation("tenant_storage_adapter").
+			WithResource("storage").
+			WithContext("company_id", companyID)
+	}
+
+	s.storagesMu.Lock()
+	s.storages[credential.ID] = tenantStorage{version: credential.Version, adapter: adapter}
+	s.storagesMu.Unlock()
+
+	return adapter, nil
+}
+
+// seal encrypts secret into credential and refreshes its masked copy
+func (s *tenantCredentialService) seal(ctx context.Context, credential *models.TenantCredential, secret map[string]string, operation string) error {
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return errors.InternalError("Failed to encode tenant credential", err).
+			WithOperation(operation).
+			WithResource("tenant_credential")
+	}
+	defer clear(plaintext)
+
+	sealed, err := vault.Seal(ctx, s.keys, plaintext, credentialAdditionalData(credential))
+	if err != nil {
+		s.logError(operation, credential, err)
+		if errors.IsAppError(err) {
+			return err
+		}
+		return errors.InternalError("Failed to encrypt tenant credential", err).
+			WithOperation(operation).
+			WithResource("tenant_credential")
+	}
+
+	credential.KeyID = sealed.KeyID
+	credential.EncryptedKey = sealed.EncryptedKey
+	credential.Nonce = sealed.Nonce
+	credential.Ciphertext = sealed.Ciphertext
+	credential.MaskedSecret = utils.MaskSecrets(secret)
+
+	return nil
+}
+
+// open decrypts the secret of a credential
+func (s *tenantCredentialService) open(ctx context.Context, credential *models.TenantCredential) (map[string]string, error) {
+	plaintext, err := vault.Open(ctx, s.keys, &vault.Sealed{
+		KeyID:        credential.KeyID,
+		EncryptedKey: credential.EncryptedKey,
+		Nonce:        credential.Nonce,
+		Ciphertext:   credential.Ciphertext,
+	}, credentialAdditionalData(credential))
+	if err != nil {
+		s.logError("resolve_tenant_credential", credential, err)
+		if errors.IsAppError(err) {
+			return nil, err
+		}
+		return nil, errors.InternalError("Failed to decrypt tenant credential", err).
+			WithOperation("resolve_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("credential_id", credential.ID)
+	}
+	defer clear(plaintext)
+
+	var secret map[string]string
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return nil, errors.InternalError("Failed to decode tenant credential", err).
+			WithOperation("resolve_tenant_credential").
+			WithResource("tenant_credential").
+			WithContext("credential_id", credential.ID)
+	}
+
+	return secret, nil
+}
+
+func (s *tenantCredentialService) forgetStorage(credentialID string) {
+	s.storagesMu.Lock()
+	delete(s.storages, credentialID)
+	s.storagesMu.Unlock()
+}
+
+func (s *tenantCredentialService) logError(operation string, credential *models.TenantCredential, err error) {
+	logger.Log.Error("Tenant credential operation failed",
+		zap.String("operation", operation),
+		zap.String("company_id", credential.CompanyID),
+		zap.String("credential_id", credential.ID),
+		zap.String("provider", credential.Provider),
+		zap.Error(err),
+	)
+}
+
+// credentialAdditionalData binds a sealed secret to its company and credential, so that a
+// ciphertext copied onto another row does not decrypt
+func credentialAdditionalData(credential *models.TenantCredential) []byte {
+	return []byte(fmt.Sprintf("tenant_credential:%s:%s", credential.CompanyID, credential.ID))
+}
+
+// validateTenantSecret checks that a secret has every field its provider requires
+func validateTenantSecret(provider string, secret map[string]string) error {
+	fieldErrors := make(map[string]string)
+	for _, field := range constants.TenantCredentialFields[provider] {
+		if secret[field] == "" {
+			fieldErrors["secret."+field] = "is required"
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return errors.ValidationErrorWithDetails("Validation failed", nil, fieldErrors)
+	}
+
+	return nil
+}
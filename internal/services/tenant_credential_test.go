@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTenantCredentialRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantCredentialRepository) Create(ctx context.Context, credential *models.TenantCredential) error {
+	args := m.Called(credential)
+	return args.Error(0)
+}
+
+func (m *MockTenantCredentialRepository) GetByID(ctx context.Context, companyID string, id string) (*models.TenantCredential, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TenantCredential), args.Error(1)
+}
+
+func (m *MockTenantCredentialRepository) GetByProvider(ctx context.Context, companyID string, provider string, name string) (*models.TenantCredential, error) {
+	args := m.Called(companyID, provider, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TenantCredential), args.Error(1)
+}
+
+func (m *MockTenantCredentialRepository) GetByCompanyID(ctx context.Context, companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.TenantCredential], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.TenantCredential]), args.Error(1)
+}
+
+func (m *MockTenantCredentialRepository) Exists(ctx context.Context, companyID string, provider string, name string) (bool, error) {
+	args := m.Called(companyID, provider, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTenantCredentialRepository) UpdateSecret(ctx context.Context, credential *models.TenantCredential) error {
+	args := m.Called(credential)
+	return args.Error(0)
+}
+
+func (m *MockTenantCredentialRepository) Delete(ctx context.Context, credential *models.TenantCredential) error {
+	args := m.Called(credential)
+	return args.Error(0)
+}
+
+func newTestTenantCredentialService(t *testing.T, credentialRepo *MockTenantCredentialRepository, companyRepo *MockCompanyRepositoryForCompanyService, storage *MockStorageAdapter) *tenantCredentialService {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keys, err := kms.NewLocalKeyManager(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+
+	return &tenantCredentialService{
+		credentialRepo: credentialRepo,
+		companyRepo:    companyRepo,
+		keys:           keys,
+		storage:        storage,
+		storages:       make(map[string]tenantStorage),
+	}
+}
+
+func smtpSecret() map[string]string {
+	return map[string]string{
+		"host":     "smtp.example.com",
+		"port":     "587",
+		"username": "mailer",
+		"password": "correct-horse-battery",
+	}
+}
+
+func TestTenantCredentialService_Create(t *testing.T) {
+	credentialRepo := new(MockTenantCredentialRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := newTestTenantCredentialService(t, credentialRepo, companyRepo, new(MockStorageAdapter))
+
+	companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+	credentialRepo.On("Exists", "company-1", "smtp", "default").Return(false, nil)
+	credentialRepo.On("Create", mock.AnythingOfType("*models.TenantCredential")).Return(nil)
+
+	credential, err := service.Create(context.Background(), "company-1", &dtos.CreateTenantCredentialRequest{
+		Provider: "smtp",
+		Name:     "default",
+		Secret:   smtpSecret(),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, credential.Version)
+	assert.Equal(t, "****tery", credential.MaskedSecret["password"])
+	assert.NotContains(t, string(credential.Ciphertext), "correct-horse-battery")
+
+	// The stored secret resolves back to the original values
+	credentialRepo.On("GetByProvider", "company-1", "smtp", "default").Return(credential, nil)
+	secret, err := service.Resolve(context.Background(), "company-1", "smtp", "default")
+	require.NoError(t, err)
+	assert.Equal(t, smtpSecret(), secret)
+
+	credentialRepo.AssertExpectations(t)
+	companyRepo.AssertExpectations(t)
+}
+
+func TestTenantCredentialService_Create_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *dtos.CreateTenantCredentialRequest
+		setupMocks func(*MockTenantCredentialRepository, *MockCompanyRepositoryForCompanyService)
+		errorType  errors.ErrorType
+	}{
+		{
+			name: "missing provider fields",
+			req: &dtos.CreateTenantCredentialRequest{
+				Provider: "s3",
+				Name:     "default",
+				Secret:   map[string]string{"bucket": "files"},
+			},
+			errorType: errors.ErrorTypeValidation,
+		},
+		{
+			name: "company not found",
+			req:  &dtos.CreateTenantCredentialRequest{Provider: "smtp", Name: "default", Secret: smtpSecret()},
+			setupMocks: func(credentialRepo *MockTenantCredentialRepository, companyRepo *MockCompanyRepositoryForCompanyService) {
+				companyRepo.On("GetOneByID", "company-1").Return(nil, errors.NotFoundError("Company", nil))
+			},
+			errorType: errors.ErrorTypeNotFound,
+		},
+		{
+			name: "credential already exists",
+			req:  &dtos.CreateTenantCredentialRequest{Provider: "smtp", Name: "default", Secret: smtpSecret()},
+			setupMocks: func(credentialRepo *MockTenantCredentialRepository, companyRepo *MockCompanyRepositoryForCompanyService) {
+				companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+				credentialRepo.On("Exists", "company-1", "smtp", "default").Return(true, nil)
+			},
+			errorType: errors.ErrorTypeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentialRepo := new(MockTenantCredentialRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			if tt.setupMocks != nil {
+				tt.setupMocks(credentialRepo, companyRepo)
+			}
+			service := newTestTenantCredentialService(t, credentialRepo, companyRepo, new(MockStorageAdapter))
+
+			credential, err := service.Create(context.Background(), "company-1", tt.req)
+
+			require.Error(t, err)
+			assert.Nil(t, credential)
+			assert.Equal(t, tt.errorType, errors.GetAppError(err).Type)
+			credentialRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestTenantCredentialService_Rotate(t *testing.T) {
+	credentialRepo := new(MockTenantCredentialRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := newTestTenantCredentialService(t, credentialRepo, companyRepo, new(MockStorageAdapter))
+
+	credential := &models.TenantCredential{
+		BaseModel: models.NewBaseModel(),
+		CompanyID: "company-1",
+		Provider:  "smtp",
+		Name:      "default",
+		Version:   1,
+	}
+	require.NoError(t, service.seal(context.Background(), credential, smtpSecret(), "test"))
+	previousKey := credential.EncryptedKey
+
+	credentialRepo.On("GetByID", "company-1", credential.ID).Return(credential, nil)
+	credentialRepo.On("UpdateSecret", credential).Return(nil)
+
+	rotated := smtpSecret()
+	rotated["password"] = "new-password-value"
+	result, err := service.Rotate(context.Background(), "company-1", credential.ID, &dtos.RotateTenantCredentialRequest{Secret: rotated})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Version)
+	assert.NotNil(t, result.RotatedAt)
+	assert.NotEqual(t, previousKey, result.EncryptedKey)
+	assert.Equal(t, "****alue", result.MaskedSecret["password"])
+
+	secret, err := service.open(context.Background(), result)
+	require.NoError(t, err)
+	assert.Equal(t, "new-password-value", secret["password"])
+	credentialRepo.AssertExpectations(t)
+}
+
+func TestTenantCredentialService_Resolve_BoundToCredential(t *testing.T) {
+	credentialRepo := new(MockTenantCredentialRepository)
+	service := newTestTenantCredentialService(t, credentialRepo, new(MockCompanyRepositoryForCompanyService), new(MockStorageAdapter))
+
+	credential := &models.TenantCredential{BaseModel: models.NewBaseModel(), CompanyID: "company-1", Provider: "smtp", Name: "default"}
+	require.NoError(t, service.seal(context.Background(), credential, smtpSecret(), "test"))
+
+	// A ciphertext copied onto the credential of another company does not decrypt
+	copied := *credential
+	copied.CompanyID = "company-2"
+	credentialRepo.On("GetByProvider", "company-2", "smtp", "default").Return(&copied, nil)
+
+	secret, err := service.Resolve(context.Background(), "company-2", "smtp", "default")
+
+	require.Error(t, err)
+	assert.Nil(t, secret)
+}
+
+func TestTenantCredentialService_StorageAdapter_FallsBackToPlatform(t *testing.T) {
+	credentialRepo := new(MockTenantCredentialRepository)
+	platform := new(MockStorageAdapter)
+	service := newTestTenantCredentialService(t, credentialRepo, new(MockCompanyRepositoryForCompanyService), platform)
+
+	credentialRepo.On("GetByProvider", "company-1", "s3", "default").Return(nil, errors.NotFoundError("Tenant credential", nil))
+
+	adapter, err := service.StorageAdapter(context.Background(), "company-1")
+
+	require.NoError(t, err)
+	assert.Same(t, platform, adapter)
+}
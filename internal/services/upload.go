@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// uploadExtension matches the file extensions kept in the keys of the uploads
+var uploadExtension = regexp.MustCompile(`^\.[a-z0-9]{1,16}$`)
+
+// UploadService issues the presigned uploads, posted by the clients straight to the
+// storage so that large files do not go through the server, and registers them in the
+// file registry
+type UploadService interface {
+	// Presign returns an upload of the declared file under a new key of the owner, the ID
+	// of the user, and registers its file, pending until Complete. The caller checks the file against the
+	// upload policy of the tenant beforehand.
+	Presign(ctx context.Context, ownerID string, request *dtos.PresignUploadRequest) (*dtos.PresignedUploadResponse, error)
+	// Complete completes the file of a presigned upload of the owner once the client
+	// uploaded its object
+	Complete(ctx context.Context, ownerID string, id string) (*models.File, error)
+}
+
+// uploadService implements UploadService
+type uploadService struct {
+	storage storage.StorageAdapter
+	files   files.Registry
+}
+
+// ProvideUploadService creates a new upload service
+func ProvideUploadService(storage storage.StorageAdapter, files files.Registry) UploadService {
+	return &uploadService{
+		storage: storage,
+		files:   files,
+	}
+}
+
+func (s *uploadService) Presign(ctx context.Context, ownerID string, request *dtos.PresignUploadRequest) (*dtos.PresignedUploadResponse, error) {
+	contentType, _, err := mime.ParseMediaType(request.ContentType)
+	if err != nil || !strings.Contains(contentType, "/") {
+		return nil, errors.ValidationErrorWithDetails("Validation failed", err, map[string]string{
+			"content_type": "must be a content type, e.g. application/pdf",
+		}).
+			WithOperation("presign_upload").
+			WithResource("upload")
+	}
+
+	// The key only keeps a plain extension of the filename, which the client controls
+	extension := strings.ToLower(filepath.Ext(request.Filename))
+	if !uploadExtension.MatchString(extension) {
+		extension = ""
+	}
+	key := fmt.Sprintf("%s/%s/%s%s", constants.UploadKeyPrefix, ownerID, uuid.Must(uuid.NewV7()).String(), extension)
+
+	// The storage refuses a file larger than declared, which the upload policy accepted
+	upload, err := s.storage.PresignUpload(ctx, key, contentType, request.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file is registered once the upload is issued, so that a failed presign does not
+	// leave it pending
+	file, err := s.files.Register(ctx, key, contentType, request.Size, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.PresignedUploadResponse{
+		FileID:    file.ID,
+		Key:       key,
+		URL:       upload.URL,
+		Method:    "POST",
+		Fields:    upload.Fields,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+func (s *uploadService) Complete(ctx context.Context, ownerID string, id string) (*models.File, error) {
+	// A file ID that is not a UUID cannot exist, rather than failing the query
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.NotFoundError("File", err).
+			WithOperation("complete_upload").
+			WithResource("file").
+			WithContext("file_id", id)
+	}
+
+	file, err := s.files.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the presigned uploads of the owner can be completed
+	if file.OwnerID == nil || *file.OwnerID != ownerID || !strings.HasPrefix(file.Key, constants.UploadKeyPrefix+"/") {
+		return nil, errors.ForbiddenError("Insufficient permissions to complete upload", nil).
+			WithOperation("complete_upload").
+			WithResource("file").
+			WithContext("file_id", id)
+	}
+
+	if err := s.files.Complete(ctx, file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+// UploadFile describes a file about to be stored, checked against the upload policy of
+// its tenant
+type UploadFile struct {
+	// Field is the form field of the file, which keys the validation errors
+	Field    string
+	Filename string
+	// ContentType is detected from the file content, or declared by the client of a
+	// presigned upload
+	ContentType string
+	Size        int64
+}
+
+type UploadPolicyService interface {
+	Get(ctx context.Context, companyID string) (*dtos.UploadPolicyResponse, error)
+	// Set replaces the upload policy of a company
+	Set(ctx context.Context, companyID string, request *dtos.UpdateUploadPolicyRequest, updatedBy string) (*dtos.UploadPolicyResponse, error)
+	// Delete removes the upload policy of a company, which accepts every upload within the
+	// built-in limits again
+	Delete(ctx context.Context, companyID string) (*dtos.UploadPolicyResponse, error)
+	// Check rejects the files the upload policy of a company does not accept
+	Check(ctx context.Context, companyID string, files ...UploadFile) error
+	// CheckOrganization checks the files against the upload policy of the company of a
+	// Keycloak organization; organizations without a company accept every file
+	CheckOrganization(ctx context.Context, organizationID string, files ...UploadFile) error
+}
+
+// uploadPolicyService manages the upload policies of the tenants, stored on their company
+type uploadPolicyService struct {
+	companyRepo repositories.CompanyRepository
+}
+
+// ProvideUploadPolicyService creates a new upload policy service
+func ProvideUploadPolicyService(companyRepo repositories.CompanyRepository) UploadPolicyService {
+	return &uploadPolicyService{
+		companyRepo: companyRepo,
+	}
+}
+
+func (s *uploadPolicyService) Get(ctx context.Context, companyID string) (*dtos.UploadPolicyResponse, error) {
+	company, err := s.getCompany(companyID, "get_upload_policy")
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewUploadPolicyResponse(companyID, company.UploadPolicy), nil
+}
+
+func (s *uploadPolicyService) Set(ctx context.Context, companyID string, request *dtos.UpdateUploadPolicyRequest, updatedBy string) (*dtos.UploadPolicyResponse, error) {
+	fieldErrors := make(map[string]string)
+	contentTypes := normalizeUploadPolicyEntries(request.AllowedContentTypes, func(contentType string) string {
+		return contentType
+	})
+	for i, contentType := range contentTypes {
+		mediaType, subtype, ok := strings.Cut(contentType, "/")
+		if !ok || mediaType == "" || subtype == "" || strings.Contains(subtype, "/") {
+			fieldErrors[fmt.Sprintf("allowed_content_types[%d]", i)] = "must be a content type, e.g. image/png or image/*"
+		}
+	}
+	extensions := normalizeUploadPolicyEntries(request.BannedExtensions, func(extension string) string {
+		return "." + strings.TrimPrefix(extension, ".")
+	})
+	for i, extension := range extensions {
+		if extension == "." || strings.ContainsAny(extension[1:], "./\\") {
+			fieldErrors[fmt.Sprintf("banned_extensions[%d]", i)] = "must be a file extension, e.g. .exe"
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, errors.ValidationErrorWithDetails("Validation failed", nil, fieldErrors).
+			WithOperation("set_upload_policy").
+			WithResource("upload_policy").
+			WithContext("company_id", companyID)
+	}
+
+	company, err := s.getCompany(companyID, "set_upload_policy")
+	if err != nil {
+		return nil, err
+	}
+
+	company.UploadPolicy = &models.UploadPolicy{
+		AllowedContentTypes: contentTypes,
+		MaxSizeBytes:        request.MaxSizeBytes,
+		BannedExtensions:    extensions,
+		UpdatedBy:           updatedBy,
+		UpdatedAt:           time.Now(),
+	}
+	if err := s.companyRepo.UpdateColumns(company, "upload_policy"); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Upload policy updated",
+		zap.String("company_id", companyID),
+		zap.Strings("allowed_content_types", contentTypes),
+		zap.Int64("max_size_bytes", request.MaxSizeBytes),
+		zap.Strings("banned_extensions", extensions),
+		zap.String("updated_by", updatedBy),
+	)
+
+	return dtos.NewUploadPolicyResponse(companyID, company.UploadPolicy), nil
+}
+
+// Delete is idempotent, deleting the policy of a company without one succeeds
+func (s *uploadPolicyService) Delete(ctx context.Context, companyID string) (*dtos.UploadPolicyResponse, error) {
+	company, err := s.getCompany(companyID, "delete_upload_policy")
+	if err != nil {
+		return nil, err
+	}
+
+	if company.UploadPolicy != nil {
+		company.UploadPolicy = nil
+		if err := s.companyRepo.UpdateColumns(company, "upload_policy"); err != nil {
+			return nil, err
+		}
+		logger.Log.Info("Upload policy deleted", zap.String("company_id", companyID))
+	}
+
+	return dtos.NewUploadPolicyResponse(companyID, nil), nil
+}
+
+func (s *uploadPolicyService) Check(ctx context.Context, companyID string, files ...UploadFile) error {
+	company, err := s.getCompany(companyID, "check_upload_policy")
+	if err != nil {
+		return err
+	}
+
+	return checkUploadPolicy(company, files)
+}
+
+func (s *uploadPolicyService) CheckOrganization(ctx context.Context, organizationID string, files ...UploadFile) error {
+	company, err := s.companyRepo.GetByKeycloakID(organizationID)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return checkUploadPolicy(company, files)
+}
+
+func (s *uploadPolicyService) getCompany(companyID string, operation string) (*models.Company, error) {
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+	return company, nil
+}
+
+// checkUploadPolicy reports every rejected file in one validation error keyed by field
+func checkUploadPolicy(company *models.Company, files []UploadFile) error {
+	policy := company.UploadPolicy
+	if policy == nil {
+		return nil
+	}
+
+	fieldErrors := make(map[string]string)
+	for _, file := range files {
+		extension := strings.ToLower(filepath.Ext(file.Filename))
+		contentType, _, _ := strings.Cut(strings.ToLower(file.ContentType), ";")
+		contentType = strings.TrimSpace(contentType)
+
+		switch {
+		case extension != "" && slices.Contains(policy.BannedExtensions, extension):
+			fieldErrors[file.Field] = fmt.Sprintf("file extension %s is not allowed", extension)
+		case policy.MaxSizeBytes > 0 && file.Size > policy.MaxSizeBytes:
+			fieldErrors[file.Field] = fmt.Sprintf("file exceeds %d bytes", policy.MaxSizeBytes)
+		case len(policy.AllowedContentTypes) > 0 && !contentTypeAllowed(policy.AllowedContentTypes, contentType):
+			fieldErrors[file.Field] = fmt.Sprintf("content type %s is not allowed", contentType)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return errors.ValidationErrorWithDetails("File rejected by the upload policy", nil, fieldErrors).
+			WithOperation("check_upload_policy").
+			WithResource("upload_policy").
+			WithContext("company_id", company.ID)
+	}
+	return nil
+}
+
+// contentTypeAllowed matches a content type against the allowed ones, where "image/*"
+// allows every image type
+func contentTypeAllowed(allowed []string, contentType string) bool {
+	for _, candidate := range allowed {
+		if candidate == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeUploadPolicyEntries trims and lower cases the entries of a policy list, formats
+// them and drops the duplicates
+func normalizeUploadPolicyEntries(entries []string, format func(string) string) []string {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = format(strings.ToLower(strings.TrimSpace(entry)))
+		if !slices.Contains(normalized, entry) {
+			normalized = append(normalized, entry)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPolicyService_Set(t *testing.T) {
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideUploadPolicyService(companyRepo)
+
+	company := factory.Company().Build()
+	companyRepo.On("GetOneByID", company.ID).Return(company, nil)
+	companyRepo.On("UpdateColumns", company, []string{"upload_policy"}).Return(nil).Once()
+
+	policy, err := service.Set(context.Background(), company.ID, &dtos.UpdateUploadPolicyRequest{
+		AllowedContentTypes: []string{" Image/PNG ", "image/png", "application/pdf"},
+		MaxSizeBytes:        1 << 20,
+		BannedExtensions:    []string{"EXE", ".js"},
+	}, "manager-1")
+	require.NoError(t, err)
+
+	assert.True(t, policy.Configured)
+	assert.Equal(t, []string{"image/png", "application/pdf"}, policy.AllowedContentTypes)
+	assert.Equal(t, []string{".exe", ".js"}, policy.BannedExtensions)
+	assert.Equal(t, "manager-1", policy.UpdatedBy)
+	require.NotNil(t, company.UploadPolicy)
+	assert.Equal(t, int64(1<<20), company.UploadPolicy.MaxSizeBytes)
+	companyRepo.AssertExpectations(t)
+}
+
+func TestUploadPolicyService_Set_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request dtos.UpdateUploadPolicyRequest
+		field   string
+	}{
+		{name: "content type without subtype", request: dtos.UpdateUploadPolicyRequest{AllowedContentTypes: []string{"image"}}, field: "allowed_content_types[0]"},
+		{name: "content type with two slashes", request: dtos.UpdateUploadPolicyRequest{AllowedContentTypes: []string{"image/png/x"}}, field: "allowed_content_types[0]"},
+		{name: "empty extension", request: dtos.UpdateUploadPolicyRequest{BannedExtensions: []string{"."}}, field: "banned_extensions[0]"},
+		{name: "extension with a path", request: dtos.UpdateUploadPolicyRequest{BannedExtensions: []string{".exe", "tar.gz"}}, field: "banned_extensions[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := ProvideUploadPolicyService(companyRepo)
+
+			_, err := service.Set(context.Background(), "company-1", &tt.request, "manager-1")
+
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+			assert.Contains(t, appErr.Context, tt.field)
+			companyRepo.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUploadPolicyService_Delete(t *testing.T) {
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideUploadPolicyService(companyRepo)
+
+	company := factory.Company().WithUploadPolicy(&models.UploadPolicy{MaxSizeBytes: 1024}).Build()
+	companyRepo.On("GetOneByID", company.ID).Return(company, nil)
+	companyRepo.On("UpdateColumns", company, []string{"upload_policy"}).Return(nil).Once()
+
+	policy, err := service.Delete(context.Background(), company.ID)
+	require.NoError(t, err)
+	assert.False(t, policy.Configured)
+	assert.Nil(t, company.UploadPolicy)
+
+	// Deleting again succeeds without writing
+	_, err = service.Delete(context.Background(), company.ID)
+	require.NoError(t, err)
+	companyRepo.AssertExpectations(t)
+}
+
+func TestUploadPolicyService_Check(t *testing.T) {
+	policy := &models.UploadPolicy{
+		AllowedContentTypes: []string{"image/*", "application/pdf"},
+		MaxSizeBytes:        1024,
+		BannedExtensions:    []string{".exe"},
+	}
+
+	tests := []struct {
+		name    string
+		policy  *models.UploadPolicy
+		file    UploadFile
+		wantErr string
+	}{
+		{name: "no policy", policy: nil, file: UploadFile{Field: "file", Filename: "setup.exe", ContentType: "application/octet-stream", Size: 1 << 30}},
+		{name: "allowed exact type", policy: policy, file: UploadFile{Field: "file", Filename: "report.pdf", ContentType: "application/pdf", Size: 512}},
+		{name: "allowed wildcard type", policy: policy, file: UploadFile{Field: "file", Filename: "logo.PNG", ContentType: "image/png", Size: 512}},
+		{name: "type parameters ignored", policy: policy, file: UploadFile{Field: "file", Filename: "report.pdf", ContentType: "Application/PDF; charset=binary", Size: 512}},
+		{name: "banned extension", policy: policy, file: UploadFile{Field: "file", Filename: "logo.png.EXE", ContentType: "image/png", Size: 512}, wantErr: "file extension .exe is not allowed"},
+		{name: "too large", policy: policy, file: UploadFile{Field: "file", Filename: "logo.png", ContentType: "image/png", Size: 2048}, wantErr: "file exceeds 1024 bytes"},
+		{name: "type not allowed", policy: policy, file: UploadFile{Field: "file", Filename: "users.csv", ContentType: "text/plain; charset=utf-8", Size: 512}, wantErr: "content type text/plain is not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := ProvideUploadPolicyService(companyRepo)
+
+			company := factory.Company().WithUploadPolicy(tt.policy).Build()
+			companyRepo.On("GetOneByID", company.ID).Return(company, nil)
+
+			err := service.Check(context.Background(), company.ID, tt.file)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+			assert.Equal(t, tt.wantErr, appErr.Context[tt.file.Field])
+		})
+	}
+}
+
+func TestUploadPolicyService_CheckOrganization(t *testing.T) {
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	service := ProvideUploadPolicyService(companyRepo)
+
+	company := factory.Company().WithUploadPolicy(&models.UploadPolicy{MaxSizeBytes: 1024}).Build()
+	companyRepo.On("GetByKeycloakID", "org-1").Return(company, nil)
+	companyRepo.On("GetByKeycloakID", "org-2").Return(nil, errors.NotFoundError("Company", nil))
+
+	err := service.CheckOrganization(context.Background(), "org-1", UploadFile{Field: "logo", Filename: "logo.png", ContentType: "image/png", Size: 2048})
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Contains(t, appErr.Context, "logo")
+
+	err = service.CheckOrganization(context.Background(), "org-2", UploadFile{Field: "logo", Filename: "logo.png", ContentType: "image/png", Size: 2048})
+	assert.NoError(t, err, "an organization without a company has no policy")
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadService_Presign(t *testing.T) {
+	tests := []struct {
+		name                string
+		request             dtos.PresignUploadRequest
+		expectedKey         string
+		expectedContentType string
+		expectedError       errors.ErrorType
+	}{
+		{
+			name:                "file with an extension",
+			request:             dtos.PresignUploadRequest{Filename: "Report.PDF", ContentType: "application/pdf", Size: 1 << 20},
+			expectedKey:         `^uploads/user-1/[0-9a-f-]{36}\.pdf$`,
+			expectedContentType: "application/pdf",
+		},
+		{
+			name:                "content type with parameters",
+			request:             dtos.PresignUploadRequest{Filename: "notes.txt", ContentType: "Text/Plain; charset=utf-8", Size: 100},
+			expectedKey:         `^uploads/user-1/[0-9a-f-]{36}\.txt$`,
+			expectedContentType: "text/plain",
+		},
+		{
+			name:                "extension dropped from the key",
+			request:             dtos.PresignUploadRequest{Filename: "archive.tar/../x", ContentType: "application/octet-stream", Size: 100},
+			expectedKey:         `^uploads/user-1/[0-9a-f-]{36}$`,
+			expectedContentType: "application/octet-stream",
+		},
+		{
+			name:          "invalid content type",
+			request:       dtos.PresignUploadRequest{Filename: "a.pdf", ContentType: "pdf", Size: 100},
+			expectedError: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageAdapter := new(MockStorageAdapter)
+			mockFiles := new(MockFileRegistry)
+			service := ProvideUploadService(storageAdapter, mockFiles)
+			expiresAt := time.Now().Add(15 * time.Minute)
+			storageAdapter.On("PresignUpload", mock.Anything, mock.Anything, tt.expectedContentType, tt.request.Size).Return(&storage.PresignedUpload{
+				URL:       "https://bucket.s3.us-east-1.amazonaws.com",
+				Fields:    map[string]string{"policy": "abc"},
+				ExpiresAt: expiresAt,
+			}, nil).Maybe()
+			file := factory.File().WithStatus(constants.FileStatusPending, "").Build()
+			mockFiles.On("Register", mock.Anything, mock.Anything, tt.expectedContentType, tt.request.Size, "user-1").Return(file, nil).Maybe()
+
+			upload, err := service.Presign(context.Background(), "user-1", &tt.request)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				storageAdapter.AssertNotCalled(t, "PresignUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockFiles.AssertNotCalled(t, "Register", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Regexp(t, regexp.MustCompile(tt.expectedKey), upload.Key)
+			storageAdapter.AssertCalled(t, "PresignUpload", mock.Anything, upload.Key, tt.expectedContentType, tt.request.Size)
+			mockFiles.AssertCalled(t, "Register", mock.Anything, upload.Key, tt.expectedContentType, tt.request.Size, "user-1")
+			assert.Equal(t, file.ID, upload.FileID)
+			assert.Equal(t, "POST", upload.Method)
+			assert.Equal(t, "https://bucket.s3.us-east-1.amazonaws.com", upload.URL)
+			assert.Equal(t, map[string]string{"policy": "abc"}, upload.Fields)
+			assert.Equal(t, expiresAt, upload.ExpiresAt)
+		})
+	}
+
+	t.Run("storage failure registers no file", func(t *testing.T) {
+		storageAdapter := new(MockStorageAdapter)
+		mockFiles := new(MockFileRegistry)
+		service := ProvideUploadService(storageAdapter, mockFiles)
+		storageAdapter.On("PresignUpload", mock.Anything, mock.Anything, "image/png", int64(100)).
+			Return(nil, errors.ExternalServiceError("failed to presign S3 upload", stderrors.New("no credentials")))
+
+		upload, err := service.Presign(context.Background(), "user-1", &dtos.PresignUploadRequest{Filename: "a.png", ContentType: "image/png", Size: 100})
+
+		require.Error(t, err)
+		assert.Nil(t, upload)
+		assert.Equal(t, errors.ErrorTypeExternal, errors.GetAppError(err).Type)
+		mockFiles.AssertNotCalled(t, "Register", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUploadService_Complete(t *testing.T) {
+	upload := factory.File().WithKey("uploads/user-1/a.pdf").WithOwner("user-1").WithStatus(constants.FileStatusPending, "").Build()
+	avatar := factory.File().WithKey("avatars/user-1/a.png").WithOwner("user-1").WithStatus(constants.FileStatusPending, "").Build()
+
+	tests := []struct {
+		name          string
+		id            string
+		ownerID       string
+		file          *models.File
+		getErr        error
+		completeErr   error
+		expectedError errors.ErrorType
+	}{
+		{name: "success - upload of the owner", id: upload.ID, ownerID: "user-1", file: upload},
+		{name: "error - ID that is not a UUID", id: "abc", ownerID: "user-1", expectedError: errors.ErrorTypeNotFound},
+		{name: "error - unknown file", id: upload.ID, ownerID: "user-1", getErr: errors.NotFoundError("File", nil), expectedError: errors.ErrorTypeNotFound},
+		{name: "error - upload of another user", id: upload.ID, ownerID: "user-2", file: upload, expectedError: errors.ErrorTypeForbidden},
+		{name: "error - file that is not an upload", id: avatar.ID, ownerID: "user-1", file: avatar, expectedError: errors.ErrorTypeForbidden},
+		{
+			name:          "error - object not uploaded yet",
+			id:            upload.ID,
+			ownerID:       "user-1",
+			file:          upload,
+			completeErr:   errors.ConflictError("File is not uploaded yet", nil),
+			expectedError: errors.ErrorTypeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFiles := new(MockFileRegistry)
+			service := ProvideUploadService(new(MockStorageAdapter), mockFiles)
+			if tt.file != nil {
+				mockFiles.On("GetByID", mock.Anything, tt.id).Return(tt.file, nil)
+			} else {
+				mockFiles.On("GetByID", mock.Anything, tt.id).Return(nil, tt.getErr).Maybe()
+			}
+			mockFiles.On("Complete", mock.Anything, tt.file).Return(tt.completeErr).Maybe()
+
+			file, err := service.Complete(context.Background(), tt.ownerID, tt.id)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Nil(t, file)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				if tt.expectedError == errors.ErrorTypeForbidden {
+					mockFiles.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.file, file)
+			mockFiles.AssertCalled(t, "Complete", mock.Anything, tt.file)
+		})
+	}
+}
@@ -2,16 +2,29 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
 
 	"golang-boilerplate/internal/cache"
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/files"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/jobs"
 	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/projections"
+	"golang-boilerplate/internal/realtime"
 	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/webhooks"
 
 	"golang-boilerplate/internal/logger"
 
-	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -19,8 +32,18 @@ type UserService interface {
 	Create(ctx context.Context, req *dtos.CreateUserRequest) (*models.User, error)
 	GetOneByID(ctx context.Context, userID string) (*models.User, error)
 	Update(ctx context.Context, userID string, req *dtos.UpdateUserRequest) (*models.User, error)
+	Patch(ctx context.Context, userID string, req *dtos.PatchUserRequest) (*models.User, error)
 	Delete(ctx context.Context, userID string) error
 	List(ctx context.Context, pageableRequest *dtos.UserPageableRequest) (*dtos.DataResponse[models.User], error)
+	// Stream passes every user matching the filters of pageableRequest to yield, newest
+	// first, constants.StreamBatchSize at a time; the sort and pagination are ignored
+	Stream(ctx context.Context, pageableRequest *dtos.UserPageableRequest, yield func([]models.User) error) error
+	Search(ctx context.Context, req *dtos.UserSearchRequest) (*dtos.DataResponse[models.UserSearchResult], error)
+	Import(ctx context.Context, rows []dtos.ImportUserRow, dryRun bool) (*dtos.ImportUsersResponse, error)
+	UploadAvatar(ctx context.Context, userID string, file *multipart.FileHeader, contentType string) (*dtos.UserAvatarResponse, error)
+	GetAvatar(ctx context.Context, userID string) (*dtos.UserAvatarResponse, error)
+	AddCompany(ctx context.Context, userID string, companyID string) (*models.User, error)
+	RemoveCompany(ctx context.Context, userID string, companyID string) (*models.User, error)
 }
 
 // UserService handles user business logic
@@ -28,6 +51,12 @@ type userService struct {
 	userRepo    repositories.UserRepository
 	companyRepo repositories.CompanyRepository
 	cache       cache.Cache
+	files       files.Registry
+	publisher   realtime.Publisher
+	enqueuer    jobs.Enqueuer
+	broker      messaging.Publisher
+	webhooks    webhooks.Dispatcher
+	projections projections.Publisher
 }
 
 // NewUserService creates a new user service
@@ -35,11 +64,23 @@ func ProvideUserService(
 	userRepo repositories.UserRepository,
 	companyRepo repositories.CompanyRepository,
 	cache cache.Cache,
+	files files.Registry,
+	publisher realtime.Publisher,
+	enqueuer jobs.Enqueuer,
+	broker messaging.Publisher,
+	webhooks webhooks.Dispatcher,
+	projections projections.Publisher,
 ) UserService {
 	return &userService{
 		userRepo:    userRepo,
 		companyRepo: companyRepo,
 		cache:       cache,
+		files:       files,
+		publisher:   publisher,
+		enqueuer:    enqueuer,
+		broker:      broker,
+		webhooks:    webhooks,
+		projections: projections,
 	}
 }
 
@@ -49,16 +90,6 @@ func (s *userService) Create(ctx context.Context, req *dtos.CreateUserRequest) (
 		companyID := companyReq.ID
 		company, err := s.companyRepo.GetOneByID(companyID)
 		if err != nil {
-			// Report to Sentry with context
-			if hub := sentry.GetHubFromContext(ctx); hub != nil {
-				hub.WithScope(func(scope *sentry.Scope) {
-					scope.SetTag("service", "user_service")
-					scope.SetTag("operation", "create_user")
-					scope.SetExtra("company_id", companyID)
-					hub.CaptureException(err)
-				})
-			}
-
 			logger.Log.Error("Failed to get company for user creation",
 				zap.String("company_id", companyID),
 				zap.Error(err),
@@ -82,16 +113,6 @@ func (s *userService) Create(ctx context.Context, req *dtos.CreateUserRequest) (
 
 	user, err := s.userRepo.Create(user)
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "create_user")
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to create user",
 			zap.Any("body_request", req),
 			zap.Error(err),
@@ -103,22 +124,35 @@ func (s *userService) Create(ctx context.Context, req *dtos.CreateUserRequest) (
 			WithContext("request", req)
 	}
 
+	s.enqueueVerificationEmail(ctx, user)
+	s.dispatchUserWebhook(ctx, user, constants.WebhookEventUserCreated)
+	s.publishUserProjectionEvent(ctx, user, constants.WebhookEventUserCreated)
 	return user, nil
 }
 
+// enqueueVerificationEmail queues the Keycloak verification email of a new user, so that
+// the request does not wait for Keycloak. The user is already saved, so a failure is only
+// logged.
+func (s *userService) enqueueVerificationEmail(ctx context.Context, user *models.User) {
+	if user.KeycloakID == "" {
+		return
+	}
+
+	if _, err := s.enqueuer.Enqueue(ctx, jobs.SendVerificationEmailArgs{KeycloakID: user.KeycloakID}); err != nil {
+		logger.Log.Warn("Failed to enqueue verification email",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetOneByID serves the user from the cache for constants.EntityCacheTTL, without its
+// companies
 func (s *userService) GetOneByID(ctx context.Context, userID string) (*models.User, error) {
-	user, err := s.userRepo.GetOneByID(userID)
+	user, err := cache.GetOrLoad(ctx, s.cache, constants.UserCacheKeyPrefix+userID, constants.EntityCacheTTL, func(context.Context) (*models.User, error) {
+		return s.userRepo.GetOneByID(userID)
+	})
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "get_user")
-				scope.SetExtra("user_id", userID)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to get user",
 			zap.String("user_id", userID),
 			zap.Error(err),
@@ -133,21 +167,15 @@ func (s *userService) GetOneByID(ctx context.Context, userID string) (*models.Us
 	return user, nil
 }
 
+// invalidateUser removes the cached user after a change
+func (s *userService) invalidateUser(ctx context.Context, userID string) {
+	cache.Invalidate(ctx, s.cache, constants.UserCacheKeyPrefix+userID)
+}
+
 func (s *userService) Update(ctx context.Context, userID string, req *dtos.UpdateUserRequest) (*models.User, error) {
 	preloads := []string{"Companies"}
 	user, err := s.userRepo.GetOneByID(userID, preloads...)
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "update_user")
-				scope.SetExtra("user_id", userID)
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to get user for update",
 			zap.String("user_id", userID),
 			zap.Any("body_request", req),
@@ -160,66 +188,13 @@ func (s *userService) Update(ctx context.Context, userID string, req *dtos.Updat
 			WithContext("user_id", userID)
 	}
 
-	// Handle many-to-many relationship with companies
-	// If req.Companies is provided (even if empty), we need to update the associations
-	if len(req.Companies) > 0 {
-		// Get requested company IDs
-		requestedCompanyIDs := make(map[string]bool)
-		for _, company := range req.Companies {
-			if company.ID != "" {
-				requestedCompanyIDs[company.ID] = true
-			}
-		}
-
-		// Get current company IDs
-		currentCompanyIDs := make(map[string]bool)
-		for _, company := range user.Companies {
-			currentCompanyIDs[company.ID] = true
-		}
-
-		// Build the final companies list with only the requested companies
-		var finalCompanies []models.Company
-		for companyID := range requestedCompanyIDs {
-			// Check if this company is already associated
-			if currentCompanyIDs[companyID] {
-				// Find the existing company in user.Companies
-				for _, company := range user.Companies {
-					if company.ID == companyID {
-						finalCompanies = append(finalCompanies, company)
-						break
-					}
-				}
-			} else {
-				// This is a new company, fetch it from the database
-				company, err := s.companyRepo.GetOneByID(companyID)
-				if err != nil {
-					// Report to Sentry with context
-					if hub := sentry.GetHubFromContext(ctx); hub != nil {
-						hub.WithScope(func(scope *sentry.Scope) {
-							scope.SetTag("service", "user_service")
-							scope.SetTag("operation", "update_user")
-							scope.SetExtra("company_id", companyID)
-							hub.CaptureException(err)
-						})
-					}
-
-					logger.Log.Error("Failed to get company for user update",
-						zap.String("company_id", companyID),
-						zap.Error(err),
-					)
-
-					return nil, errors.NotFoundError("Company", err).
-						WithOperation("update_user").
-						WithResource("company").
-						WithContext("company_id", companyID)
-				}
-				finalCompanies = append(finalCompanies, *company)
-			}
+	// Companies are synced only when provided; an empty list removes all the memberships
+	var added, removed []models.Company
+	if req.Companies != nil {
+		added, removed, err = s.diffCompanies(ctx, user, req.Companies)
+		if err != nil {
+			return nil, err
 		}
-
-		// Replace the user's companies with the final list
-		// This will be an empty slice if no companies were requested
-		user.Companies = finalCompanies
 	}
 
 	// Update user fields if provided in request
@@ -237,19 +212,8 @@ func (s *userService) Update(ctx context.Context, userID string, req *dtos.Updat
 	}
 
 	// Save the updated user
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(user, added, removed)
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "update_user")
-				scope.SetExtra("user_id", userID)
-				scope.SetExtra("body_request", req)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to update user",
 			zap.String("user_id", userID),
 			zap.Any("body_request", req),
@@ -261,23 +225,215 @@ func (s *userService) Update(ctx context.Context, userID string, req *dtos.Updat
 			WithResource("user").
 			WithContext("user_id", userID)
 	}
+	s.invalidateUser(ctx, userID)
 
+	user.Companies = syncedCompanies(user.Companies, added, removed)
+	for _, company := range added {
+		s.publishMembershipChanged(ctx, user, company.ID, constants.MembershipChangeAdded)
+	}
+	for _, company := range removed {
+		s.publishMembershipChanged(ctx, user, company.ID, constants.MembershipChangeRemoved)
+	}
+
+	s.publishUserUpdated(ctx, user)
+	s.dispatchUserWebhook(ctx, user, constants.WebhookEventUserUpdated)
+	s.publishUserProjectionEvent(ctx, user, constants.WebhookEventUserUpdated)
 	return user, nil
 }
 
-func (s *userService) Delete(ctx context.Context, userID string) error {
-	user, err := s.userRepo.GetOneByID(userID)
-	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "delete_user")
-				scope.SetExtra("user_id", userID)
-				hub.CaptureException(err)
-			})
+// diffCompanies compares the requested companies to the memberships of the user and
+// returns the companies to add, loaded from the database, and the memberships to remove
+func (s *userService) diffCompanies(ctx context.Context, user *models.User, requested []dtos.UpdateCompanyRequest) ([]models.Company, []models.Company, error) {
+	requestedIDs := make(map[string]bool, len(requested))
+	var added []models.Company
+	for _, companyReq := range requested {
+		if companyReq.ID == "" || requestedIDs[companyReq.ID] {
+			continue
+		}
+		requestedIDs[companyReq.ID] = true
+		if hasCompany(user, companyReq.ID) {
+			continue
+		}
+
+		company, err := s.companyRepo.GetOneByID(companyReq.ID)
+		if err != nil {
+			logger.Log.Error("Failed to get company for user update",
+				zap.String("company_id", companyReq.ID),
+				zap.Error(err),
+			)
+
+			return nil, nil, errors.NotFoundError("Company", err).
+				WithOperation("update_user").
+				WithResource("company").
+				WithContext("company_id", companyReq.ID)
+		}
+		added = append(added, *company)
+	}
+
+	var removed []models.Company
+	for _, company := range user.Companies {
+		if !requestedIDs[company.ID] {
+			removed = append(removed, company)
+		}
+	}
+
+	return added, removed, nil
+}
+
+// syncedCompanies returns the memberships after adding and removing the given companies
+func syncedCompanies(current []models.Company, added []models.Company, removed []models.Company) []models.Company {
+	removedIDs := make(map[string]bool, len(removed))
+	for _, company := range removed {
+		removedIDs[company.ID] = true
+	}
+
+	companies := make([]models.Company, 0, len(current)+len(added))
+	for _, company := range current {
+		if !removedIDs[company.ID] {
+			companies = append(companies, company)
 		}
+	}
+	return append(companies, added...)
+}
+
+// Patch replaces the patchable fields of a user with the merged document, so that
+// fields cleared by the merge patch are written as empty values
+func (s *userService) Patch(ctx context.Context, userID string, req *dtos.PatchUserRequest) (*models.User, error) {
+	user, err := s.userRepo.GetOneByID(userID, "Companies")
+	if err != nil {
+		logger.Log.Error("Failed to get user for patch",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
 
+		return nil, errors.NotFoundError("User", err).
+			WithOperation("patch_user").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	user.Email = req.Email
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.KeycloakID = req.KeycloakID
+
+	err = s.userRepo.UpdateColumns(user, "email", "first_name", "last_name", "keycloak_id")
+	if err != nil {
+		logger.Log.Error("Failed to patch user",
+			zap.String("user_id", userID),
+			zap.Any("body_request", req),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to patch user", err).
+			WithOperation("patch_user").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+	s.invalidateUser(ctx, userID)
+
+	s.publishUserUpdated(ctx, user)
+	s.dispatchUserWebhook(ctx, user, constants.WebhookEventUserUpdated)
+	s.publishUserProjectionEvent(ctx, user, constants.WebhookEventUserUpdated)
+	return user, nil
+}
+
+// publishUserUpdated pushes the updated user to the realtime connections of their Keycloak
+// account. The update is already saved, so a failure is only logged.
+func (s *userService) publishUserUpdated(ctx context.Context, user *models.User) {
+	if user.KeycloakID == "" {
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, user.KeycloakID, constants.RealtimeEventUserUpdated, dtos.NewUserResponse(user)); err != nil {
+		logger.Log.Warn("Failed to publish user update",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishMembershipChanged publishes the change of a membership to the message broker, the
+// webhooks and the read models of the company. The change is already saved, so a failure is only logged.
+func (s *userService) publishMembershipChanged(ctx context.Context, user *models.User, companyID string, change string) {
+	event := dtos.UserMembershipChangedEvent{
+		UserID:     user.ID,
+		KeycloakID: user.KeycloakID,
+		CompanyID:  companyID,
+		Change:     change,
+		ChangedAt:  time.Now().UTC(),
+	}
+
+	eventType := constants.WebhookEventMemberAdded
+	if change == constants.MembershipChangeRemoved {
+		eventType = constants.WebhookEventMemberRemoved
+	}
+	s.dispatchWebhook(ctx, companyID, eventType, event)
+	s.publishProjectionEvent(ctx, companyID, eventType, user.ID)
+
+	body, err := json.Marshal(event)
+	if err == nil {
+		err = s.broker.Publish(ctx, constants.MessagingTopicUserMembershipChanged, messaging.Message{Body: body})
+	}
+	if err != nil && !stderrors.Is(err, messaging.ErrDisabled) {
+		logger.Log.Warn("Failed to publish membership change",
+			zap.String("user_id", user.ID),
+			zap.String("company_id", companyID),
+			zap.String("change", change),
+			zap.Error(err),
+		)
+	}
+}
+
+// dispatchUserWebhook delivers an event of the user to the webhooks of each of their
+// companies
+func (s *userService) dispatchUserWebhook(ctx context.Context, user *models.User, eventType string) {
+	data := dtos.NewUserResponse(user)
+	for _, company := range user.Companies {
+		s.dispatchWebhook(ctx, company.ID, eventType, data)
+	}
+}
+
+// dispatchWebhook delivers an event to the webhook endpoints of a company. The change is
+// already saved, so a failure is only logged.
+func (s *userService) dispatchWebhook(ctx context.Context, companyID string, eventType string, data any) {
+	if err := s.webhooks.Dispatch(ctx, companyID, eventType, data); err != nil {
+		logger.Log.Warn("Failed to dispatch webhook event",
+			zap.String("company_id", companyID),
+			zap.String("event_type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishUserProjectionEvent publishes the event of the user to the read models of each of
+// their companies, or once without a company when they have none
+func (s *userService) publishUserProjectionEvent(ctx context.Context, user *models.User, eventType string) {
+	if len(user.Companies) == 0 {
+		s.publishProjectionEvent(ctx, "", eventType, user.ID)
+		return
+	}
+	for _, company := range user.Companies {
+		s.publishProjectionEvent(ctx, company.ID, eventType, user.ID)
+	}
+}
+
+// publishProjectionEvent updates the read models of a company with the event. The change
+// is already saved and the read models can be rebuilt, so a failure is only logged.
+func (s *userService) publishProjectionEvent(ctx context.Context, companyID string, eventType string, entityID string) {
+	if err := s.projections.Publish(ctx, companyID, eventType, entityID); err != nil {
+		logger.Log.Warn("Failed to publish projection event",
+			zap.String("company_id", companyID),
+			zap.String("event_type", eventType),
+			zap.String("entity_id", entityID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *userService) Delete(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetOneByID(userID, "Companies")
+	if err != nil {
 		logger.Log.Error("Failed to get user for delete",
 			zap.String("user_id", userID),
 			zap.Error(err),
@@ -291,16 +447,6 @@ func (s *userService) Delete(ctx context.Context, userID string) error {
 
 	err = s.userRepo.Delete(user)
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "delete_user")
-				scope.SetExtra("user_id", userID)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to delete user",
 			zap.String("user_id", userID),
 			zap.Error(err),
@@ -311,7 +457,10 @@ func (s *userService) Delete(ctx context.Context, userID string) error {
 			WithResource("user").
 			WithContext("user_id", userID)
 	}
+	s.invalidateUser(ctx, userID)
 
+	s.dispatchUserWebhook(ctx, user, constants.WebhookEventUserDeleted)
+	s.publishUserProjectionEvent(ctx, user, constants.WebhookEventUserDeleted)
 	return nil
 }
 
@@ -319,16 +468,6 @@ func (s *userService) List(ctx context.Context, pageableRequest *dtos.UserPageab
 	preloads := []string{"Companies"}
 	users, err := s.userRepo.Get(pageableRequest, preloads...)
 	if err != nil {
-		// Report to Sentry with context
-		if hub := sentry.GetHubFromContext(ctx); hub != nil {
-			hub.WithScope(func(scope *sentry.Scope) {
-				scope.SetTag("service", "user_service")
-				scope.SetTag("operation", "get_users")
-				scope.SetExtra("pageable_request", pageableRequest)
-				hub.CaptureException(err)
-			})
-		}
-
 		logger.Log.Error("Failed to get users",
 			zap.Any("pageable_request", pageableRequest),
 			zap.Error(err),
@@ -342,3 +481,356 @@ func (s *userService) List(ctx context.Context, pageableRequest *dtos.UserPageab
 
 	return users, nil
 }
+
+func (s *userService) Stream(ctx context.Context, pageableRequest *dtos.UserPageableRequest, yield func([]models.User) error) error {
+	return streamPages(
+		func(after *dtos.Cursor, limit int) ([]models.User, error) {
+			return s.userRepo.GetAfter(pageableRequest, after, limit, "Companies")
+		},
+		func(user models.User) dtos.Cursor {
+			return dtos.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+		},
+		yield,
+	)
+}
+
+// Search returns the users matching the full-text query, most relevant first
+func (s *userService) Search(ctx context.Context, req *dtos.UserSearchRequest) (*dtos.DataResponse[models.UserSearchResult], error) {
+	results, err := s.userRepo.Search(req.Q, &req.PageableRequest)
+	if err != nil {
+		logger.Log.Error("Failed to search users",
+			zap.String("q", req.Q),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Import validates parsed CSV rows against existing users and, unless dryRun is set,
+// inserts them in batches. Any invalid row rejects the whole import.
+func (s *userService) Import(ctx context.Context, rows []dtos.ImportUserRow, dryRun bool) (*dtos.ImportUsersResponse, error) {
+	result := &dtos.ImportUsersResponse{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []dtos.ImportUserRowError{},
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if len(row.Errors) == 0 && row.User.Email != "" {
+			emails = append(emails, strings.ToLower(row.User.Email))
+		}
+	}
+
+	existingEmails, err := s.userRepo.FindExistingEmails(emails)
+	if err != nil {
+		logger.Log.Error("Failed to look up existing users for import",
+			zap.Int("total_rows", len(rows)),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to import users", err).
+			WithOperation("import_users").
+			WithResource("user")
+	}
+
+	existing := make(map[string]struct{}, len(existingEmails))
+	for _, email := range existingEmails {
+		existing[email] = struct{}{}
+	}
+
+	users := make([]models.User, 0, len(rows))
+	for _, row := range rows {
+		rowErrors := row.Errors
+		if len(rowErrors) == 0 {
+			if _, ok := existing[strings.ToLower(row.User.Email)]; ok {
+				rowErrors = map[string]string{"Email": "Email already exists"}
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, dtos.ImportUserRowError{
+				Row:    row.Row,
+				Email:  row.User.Email,
+				Errors: rowErrors,
+			})
+			continue
+		}
+
+		users = append(users, models.User{
+			FirstName:  row.User.FirstName,
+			LastName:   row.User.LastName,
+			Email:      row.User.Email,
+			KeycloakID: row.User.KeycloakID,
+		})
+	}
+
+	result.ValidRows = len(users)
+	result.InvalidRows = len(result.Errors)
+
+	if dryRun {
+		return result, nil
+	}
+
+	if result.InvalidRows > 0 {
+		return nil, errors.ValidationError("CSV contains invalid rows", nil).
+			WithOperation("import_users").
+			WithResource("user").
+			WithContext("total_rows", result.TotalRows).
+			WithContext("invalid_rows", result.InvalidRows).
+			WithContext("errors", result.Errors)
+	}
+
+	imported, err := s.userRepo.CreateInBatches(users, constants.UserImportBatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to import users",
+			zap.Int("total_rows", len(rows)),
+			zap.Int("imported_rows", imported),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to import users", err).
+			WithOperation("import_users").
+			WithResource("user").
+			WithContext("imported_rows", imported)
+	}
+
+	result.ImportedRows = imported
+	return result, nil
+}
+
+// UploadAvatar stores a new avatar for the user in the file registry, records its object
+// key and removes the previous avatar. contentType must be one of
+// constants.UserAvatarContentTypes.
+func (s *userService) UploadAvatar(ctx context.Context, userID string, file *multipart.FileHeader, contentType string) (*dtos.UserAvatarResponse, error) {
+	user, err := s.userRepo.GetOneByID(userID, "Companies")
+	if err != nil {
+		logger.Log.Error("Failed to get user for avatar upload",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+
+		return nil, errors.NotFoundError("User", err).
+			WithOperation("upload_user_avatar").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	key := fmt.Sprintf("%s/%s/%s%s", constants.UserAvatarKeyPrefix, user.ID, uuid.Must(uuid.NewV7()).String(), constants.UserAvatarContentTypes[contentType])
+	avatar, err := s.files.Upload(ctx, file, key, contentType, user.ID)
+	if err != nil {
+		logger.Log.Error("Failed to upload user avatar",
+			zap.String("user_id", userID),
+			zap.String("avatar_key", key),
+			zap.Error(err),
+		)
+
+		return nil, errors.ExternalServiceError("Failed to upload user avatar", err).
+			WithOperation("upload_user_avatar").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	if err := s.userRepo.UpdateAvatar(user.ID, key, file.Size); err != nil {
+		// Do not leave an orphaned object behind when the user could not be updated
+		if deleteErr := s.files.Delete(ctx, key); deleteErr != nil {
+			logger.Log.Warn("Failed to remove uploaded avatar after update failure",
+				zap.String("user_id", userID),
+				zap.String("avatar_key", key),
+				zap.Error(deleteErr),
+			)
+		}
+
+		logger.Log.Error("Failed to update user avatar",
+			zap.String("user_id", userID),
+			zap.String("avatar_key", key),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to update user avatar", err).
+			WithOperation("upload_user_avatar").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+	s.invalidateUser(ctx, userID)
+
+	// The previous avatar is no longer referenced; a failed cleanup must not fail the request
+	if user.AvatarKey != "" && user.AvatarKey != key {
+		if err := s.files.Delete(ctx, user.AvatarKey); err != nil {
+			logger.Log.Warn("Failed to delete previous user avatar",
+				zap.String("user_id", userID),
+				zap.String("avatar_key", user.AvatarKey),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.publishUserProjectionEvent(ctx, user, constants.EventUserAvatarUpdated)
+
+	return s.presignAvatar(ctx, user, avatar)
+}
+
+// GetAvatar returns the avatar of the user with a new presigned URL. An avatar that is not
+// available, e.g. still being scanned or quarantined, is a conflict.
+func (s *userService) GetAvatar(ctx context.Context, userID string) (*dtos.UserAvatarResponse, error) {
+	user, err := s.userRepo.GetOneByID(userID)
+	if err != nil {
+		return nil, errors.NotFoundError("User", err).
+			WithOperation("get_user_avatar").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+	if user.AvatarKey == "" {
+		return nil, errors.NotFoundError("Avatar", nil).
+			WithOperation("get_user_avatar").
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	avatar, err := s.files.Get(ctx, user.AvatarKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.presignAvatar(ctx, user, avatar)
+}
+
+// presignAvatar returns the avatar of the user with a presigned URL, a conflict when it is
+// not available
+func (s *userService) presignAvatar(ctx context.Context, user *models.User, avatar *models.File) (*dtos.UserAvatarResponse, error) {
+	url, err := s.files.PresignedURL(ctx, avatar, constants.UserAvatarURLDuration)
+	if err != nil {
+		logger.Log.Error("Failed to presign user avatar URL",
+			zap.String("user_id", user.ID),
+			zap.String("avatar_key", avatar.Key),
+			zap.String("status", avatar.Status),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return &dtos.UserAvatarResponse{
+		UserID:    user.ID,
+		AvatarKey: avatar.Key,
+		Status:    avatar.Status,
+		AvatarURL: url,
+		ExpiresAt: time.Now().Add(constants.UserAvatarURLDuration),
+	}, nil
+}
+
+// AddCompany makes the user a member of the company. Adding an existing membership is a no-op.
+func (s *userService) AddCompany(ctx context.Context, userID string, companyID string) (*models.User, error) {
+	user, company, err := s.getMembership(ctx, userID, companyID, "add_user_company")
+	if err != nil {
+		return nil, err
+	}
+
+	if hasCompany(user, companyID) {
+		return user, nil
+	}
+
+	if err := s.userRepo.AddCompany(user, company); err != nil {
+		logger.Log.Error("Failed to add company to user",
+			zap.String("user_id", userID),
+			zap.String("company_id", companyID),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to add company to user", err).
+			WithOperation("add_user_company").
+			WithResource("user").
+			WithContext("user_id", userID).
+			WithContext("company_id", companyID)
+	}
+
+	user.Companies = append(user.Companies, *company)
+	s.publishMembershipChanged(ctx, user, companyID, constants.MembershipChangeAdded)
+	return user, nil
+}
+
+// RemoveCompany ends the user's membership of the company
+func (s *userService) RemoveCompany(ctx context.Context, userID string, companyID string) (*models.User, error) {
+	user, company, err := s.getMembership(ctx, userID, companyID, "remove_user_company")
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasCompany(user, companyID) {
+		return nil, errors.NotFoundError("Company membership", nil).
+			WithOperation("remove_user_company").
+			WithResource("user").
+			WithContext("user_id", userID).
+			WithContext("company_id", companyID)
+	}
+
+	if err := s.userRepo.RemoveCompany(user, company); err != nil {
+		logger.Log.Error("Failed to remove company from user",
+			zap.String("user_id", userID),
+			zap.String("company_id", companyID),
+			zap.Error(err),
+		)
+
+		return nil, errors.DatabaseError("Failed to remove company from user", err).
+			WithOperation("remove_user_company").
+			WithResource("user").
+			WithContext("user_id", userID).
+			WithContext("company_id", companyID)
+	}
+
+	remaining := make([]models.Company, 0, len(user.Companies))
+	for _, c := range user.Companies {
+		if c.ID != companyID {
+			remaining = append(remaining, c)
+		}
+	}
+	user.Companies = remaining
+	s.publishMembershipChanged(ctx, user, companyID, constants.MembershipChangeRemoved)
+
+	return user, nil
+}
+
+// getMembership loads the user with its companies and the company referenced by a membership request
+func (s *userService) getMembership(ctx context.Context, userID string, companyID string, operation string) (*models.User, *models.Company, error) {
+	user, err := s.userRepo.GetOneByID(userID, "Companies")
+	if err != nil {
+		logger.Log.Error("Failed to get user for membership change",
+			zap.String("user_id", userID),
+			zap.String("operation", operation),
+			zap.Error(err),
+		)
+
+		return nil, nil, errors.NotFoundError("User", err).
+			WithOperation(operation).
+			WithResource("user").
+			WithContext("user_id", userID)
+	}
+
+	company, err := s.companyRepo.GetOneByID(companyID)
+	if err != nil {
+		logger.Log.Error("Failed to get company for membership change",
+			zap.String("company_id", companyID),
+			zap.String("operation", operation),
+			zap.Error(err),
+		)
+
+		return nil, nil, errors.NotFoundError("Company", err).
+			WithOperation(operation).
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return user, company, nil
+}
+
+func hasCompany(user *models.User, companyID string) bool {
+	for _, company := range user.Companies {
+		if company.ID == companyID {
+			return true
+		}
+	}
+	return false
+}
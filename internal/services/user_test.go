@@ -2,11 +2,22 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"golang-boilerplate/internal/constants"
 	"golang-boilerplate/internal/dtos"
 	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/messaging"
+	"golang-boilerplate/internal/integration/storage"
+	"golang-boilerplate/internal/jobs"
 	"golang-boilerplate/internal/models"
 
 	"github.com/google/uuid"
@@ -41,8 +52,13 @@ func (m *MockUserRepository) GetOneByID(id string, preloads ...string) (*models.
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Update(user *models.User) error {
-	args := m.Called(user)
+func (m *MockUserRepository) Update(user *models.User, added []models.Company, removed []models.Company) error {
+	args := m.Called(user, added, removed)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateColumns(user *models.User, columns ...string) error {
+	args := m.Called(user, columns)
 	return args.Error(0)
 }
 
@@ -59,6 +75,98 @@ func (m *MockUserRepository) Get(pr *dtos.UserPageableRequest, preloads ...strin
 	return args.Get(0).(*dtos.DataResponse[models.User]), args.Error(1)
 }
 
+func (m *MockUserRepository) GetAfter(pr *dtos.UserPageableRequest, after *dtos.Cursor, limit int, preloads ...string) ([]models.User, error) {
+	args := m.Called(pr, after, limit, preloads)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.User, int64, error) {
+	args := m.Called(filters, offset, limit, preloads)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) CreateInBatches(users []models.User, batchSize int) (int, error) {
+	args := m.Called(users, batchSize)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByKeycloakID(keycloakID string) (*models.User, error) {
+	args := m.Called(keycloakID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindExistingEmails(emails []string) ([]string, error) {
+	args := m.Called(emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateAvatar(id string, avatarKey string, avatarSize int64) error {
+	args := m.Called(id, avatarKey, avatarSize)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) AddCompany(user *models.User, company *models.Company) error {
+	args := m.Called(user, company)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RemoveCompany(user *models.User, company *models.Company) error {
+	args := m.Called(user, company)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByCompanyID(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.User], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByCompanyIDAfter(companyID string, after *dtos.Cursor, limit int) ([]models.User, error) {
+	args := m.Called(companyID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByCompanyIDs(companyIDs []string) (map[string][]models.User, error) {
+	args := m.Called(companyIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Search(q string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.UserSearchResult], error) {
+	args := m.Called(q, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.UserSearchResult]), args.Error(1)
+}
+
 // MockCompanyRepository is a mock implementation of CompanyRepository
 type MockCompanyRepository struct {
 	mock.Mock
@@ -80,11 +188,40 @@ func (m *MockCompanyRepository) GetOneByID(id string) (*models.Company, error) {
 	return args.Get(0).(*models.Company), args.Error(1)
 }
 
+func (m *MockCompanyRepository) GetByKeycloakID(keycloakID string) (*models.Company, error) {
+	args := m.Called(keycloakID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepository) GetBySlug(slug string) (*models.Company, error) {
+	args := m.Called(slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepository) GetSandbox(companyID string) (*models.Company, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Company), args.Error(1)
+}
+
 func (m *MockCompanyRepository) Update(company *models.Company) error {
 	args := m.Called(company)
 	return args.Error(0)
 }
 
+func (m *MockCompanyRepository) UpdateColumns(company *models.Company, columns ...string) error {
+	args := m.Called(company, columns)
+	return args.Error(0)
+}
+
 func (m *MockCompanyRepository) Delete(company *models.Company) error {
 	args := m.Called(company)
 	return args.Error(0)
@@ -98,6 +235,36 @@ func (m *MockCompanyRepository) Get(pr *dtos.CompanyPageableRequest, preloads ..
 	return args.Get(0).(*dtos.DataResponse[models.Company]), args.Error(1)
 }
 
+func (m *MockCompanyRepository) GetAfter(pr *dtos.CompanyPageableRequest, after *dtos.Cursor, limit int) ([]models.Company, error) {
+	args := m.Called(pr, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Company), args.Error(1)
+}
+
+func (m *MockCompanyRepository) GetRange(filters map[string]string, offset int, limit int, preloads ...string) ([]models.Company, int64, error) {
+	args := m.Called(filters, offset, limit, preloads)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.Company), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockCompanyRepository) GetByUserIDs(userIDs []string) (map[string][]models.Company, error) {
+	args := m.Called(userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]models.Company), args.Error(1)
+}
+
+// isEntityCacheTTL matches the TTL of a cached entity, lengthened by up to
+// constants.CacheTTLJitter
+var isEntityCacheTTL = mock.MatchedBy(func(ttl time.Duration) bool {
+	return ttl >= constants.EntityCacheTTL && float64(ttl) <= float64(constants.EntityCacheTTL)*(1+constants.CacheTTLJitter)
+})
+
 // MockCache is a mock implementation of cache.Cache
 type MockCache struct {
 	mock.Mock
@@ -123,154 +290,426 @@ func (m *MockCache) Exists(ctx context.Context, key string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockCache) SetNX(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCache) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	args := m.Called(ctx, key, value)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCache) CompareAndExpire(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCache) Flush(ctx context.Context, pattern string) (int64, error) {
+	args := m.Called(ctx, pattern)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCache) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockCache) Close() error {
 	args := m.Called()
 	return args.Error(0)
 }
 
-func TestUserService_Create(t *testing.T) {
-	tests := []struct {
-		name          string
-		req           *dtos.CreateUserRequest
-		setupMocks    func(*MockUserRepository, *MockCompanyRepository, *MockCache)
-		expectedError bool
-		errorType     string
-	}{
-		{
-			name: "success - create user with companies",
-			req: &dtos.CreateUserRequest{
-				UserRequest: dtos.UserRequest{
-					FirstName:  "John",
-					LastName:   "Doe",
-					Email:      "john.doe@example.com",
-					KeycloakID: "keycloak-123",
-					Companies: []dtos.UpdateCompanyRequest{
-						{ID: "company-1"},
-						{ID: "company-2"},
-					},
-				},
-			},
-			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
-				companyID1 := uuid.New()
-				companyID2 := uuid.New()
-				company1 := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: companyID1.String(),
-					},
-					Name: "Company 1",
-				}
-				company2 := &models.Company{
-					BaseModel: models.BaseModel{
-						ID: companyID2.String(),
-					},
-					Name: "Company 2",
-				}
+// MockPublisher is a mock implementation of realtime.Publisher
+type MockPublisher struct {
+	mock.Mock
+}
 
-				companyRepo.On("GetOneByID", "company-1").Return(company1, nil)
-				companyRepo.On("GetOneByID", "company-2").Return(company2, nil)
+func (m *MockPublisher) Publish(ctx context.Context, userID string, eventType string, data any) error {
+	args := m.Called(ctx, userID, eventType, data)
+	return args.Error(0)
+}
 
-				createdUser := &models.User{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					FirstName:  "John",
-					LastName:   "Doe",
-					Email:      "john.doe@example.com",
-					KeycloakID: "keycloak-123",
-					Companies:  []models.Company{*company1, *company2},
-				}
+// MockMessagePublisher is a mock implementation of messaging.Publisher
+type MockMessagePublisher struct {
+	mock.Mock
+}
 
-				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(createdUser, nil)
-			},
-			expectedError: false,
-		},
-		{
-			name: "success - create user without companies",
-			req: &dtos.CreateUserRequest{
-				UserRequest: dtos.UserRequest{
-					FirstName:  "Jane",
-					LastName:   "Smith",
-					Email:      "jane.smith@example.com",
-					KeycloakID: "keycloak-456",
-					Companies:  []dtos.UpdateCompanyRequest{},
-				},
-			},
-			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
-				createdUser := &models.User{
-					BaseModel: models.BaseModel{
-						ID: uuid.New().String(),
-					},
-					FirstName:  "Jane",
-					LastName:   "Smith",
-					Email:      "jane.smith@example.com",
-					KeycloakID: "keycloak-456",
-				}
+func (m *MockMessagePublisher) Publish(ctx context.Context, topic string, msg messaging.Message) error {
+	args := m.Called(ctx, topic, msg)
+	return args.Error(0)
+}
 
-				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(createdUser, nil)
-			},
-			expectedError: false,
-		},
-		{
-			name: "error - company not found",
-			req: &dtos.CreateUserRequest{
-				UserRequest: dtos.UserRequest{
-					FirstName:  "John",
-					LastName:   "Doe",
-					Email:      "john.doe@example.com",
-					KeycloakID: "keycloak-123",
-					Companies: []dtos.UpdateCompanyRequest{
-						{ID: "non-existent"},
-					},
-				},
-			},
-			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
-				companyRepo.On("GetOneByID", "non-existent").Return(nil, errors.NotFoundError("Company", nil))
-			},
-			expectedError: true,
-			errorType:     "NotFoundError",
-		},
-		{
-			name: "error - database error on create",
-			req: &dtos.CreateUserRequest{
-				UserRequest: dtos.UserRequest{
-					FirstName:  "John",
-					LastName:   "Doe",
-					Email:      "john.doe@example.com",
-					KeycloakID: "keycloak-123",
-					Companies:  []dtos.UpdateCompanyRequest{},
-				},
-			},
-			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
-				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil, errors.DatabaseError("Failed to create user", nil))
-			},
-			expectedError: true,
-			errorType:     "DatabaseError",
-		},
+// MockWebhookDispatcher is a mock implementation of webhooks.Dispatcher
+type MockWebhookDispatcher struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDispatcher) Dispatch(ctx context.Context, companyID string, eventType string, data any) error {
+	args := m.Called(ctx, companyID, eventType, data)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDispatcher) Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, delivery)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Setup mocks
-			mockUserRepo := new(MockUserRepository)
-			mockCompanyRepo := new(MockCompanyRepository)
-			mockCache := new(MockCache)
+func (m *MockWebhookDispatcher) Test(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, endpoint)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
 
-			if tt.setupMocks != nil {
-				tt.setupMocks(mockUserRepo, mockCompanyRepo, mockCache)
-			}
+// newMockWebhookDispatcher returns a dispatcher accepting any event
+func newMockWebhookDispatcher() *MockWebhookDispatcher {
+	dispatcher := new(MockWebhookDispatcher)
+	dispatcher.On("Dispatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return dispatcher
+}
 
-			// Create service with mocks
-			service := &userService{
-				userRepo:    mockUserRepo,
-				companyRepo: mockCompanyRepo,
-				cache:       mockCache,
-			}
+// MockProjectionPublisher is a mock implementation of projections.Publisher
+type MockProjectionPublisher struct {
+	mock.Mock
+}
 
-			// Execute
-			ctx := context.Background()
-			result, err := service.Create(ctx, tt.req)
+func (m *MockProjectionPublisher) Publish(ctx context.Context, companyID string, eventType string, entityID string) error {
+	args := m.Called(ctx, companyID, eventType, entityID)
+	return args.Error(0)
+}
 
-			// Assert
+// newMockProjectionPublisher returns a publisher accepting any event
+func newMockProjectionPublisher() *MockProjectionPublisher {
+	publisher := new(MockProjectionPublisher)
+	publisher.On("Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return publisher
+}
+
+// MockEnqueuer is a mock implementation of jobs.Enqueuer
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+func (m *MockEnqueuer) Enqueue(ctx context.Context, args jobs.Args, opts ...jobs.EnqueueOption) (*models.Job, error) {
+	called := m.Called(ctx, args)
+	if called.Get(0) == nil {
+		return nil, called.Error(1)
+	}
+	return called.Get(0).(*models.Job), called.Error(1)
+}
+
+// MockStorageAdapter is a mock implementation of storage.StorageAdapter
+type MockStorageAdapter struct {
+	mock.Mock
+}
+
+func (m *MockStorageAdapter) UploadFile(ctx context.Context, file *multipart.FileHeader, key string) (*storage.UploadResult, error) {
+	args := m.Called(ctx, file, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.UploadResult), args.Error(1)
+}
+
+func (m *MockStorageAdapter) UploadFiles(ctx context.Context, files []*multipart.FileHeader) (*storage.BatchUploadResult, error) {
+	args := m.Called(ctx, files)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.BatchUploadResult), args.Error(1)
+}
+
+func (m *MockStorageAdapter) Bucket() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockStorageAdapter) GetObjectURL(key string) string {
+	args := m.Called(key)
+	return args.String(0)
+}
+
+func (m *MockStorageAdapter) GetPresignedURL(ctx context.Context, key string, duration ...time.Duration) (string, error) {
+	args := m.Called(ctx, key, duration)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageAdapter) PresignUpload(ctx context.Context, key string, contentType string, maxSize int64) (*storage.PresignedUpload, error) {
+	args := m.Called(ctx, key, contentType, maxSize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.PresignedUpload), args.Error(1)
+}
+
+func (m *MockStorageAdapter) DeleteFile(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockStorageAdapter) DeleteFiles(ctx context.Context, keys []string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+func (m *MockStorageAdapter) ListObjects(ctx context.Context, prefix string, cursor string, limit int) (*storage.ObjectPage, error) {
+	args := m.Called(ctx, prefix, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.ObjectPage), args.Error(1)
+}
+
+func (m *MockStorageAdapter) HeadObject(ctx context.Context, key string) (*storage.ObjectMetadata, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.ObjectMetadata), args.Error(1)
+}
+
+func (m *MockStorageAdapter) CopyObject(ctx context.Context, srcKey string, dstKey string) error {
+	args := m.Called(ctx, srcKey, dstKey)
+	return args.Error(0)
+}
+
+func (m *MockStorageAdapter) MoveObject(ctx context.Context, srcKey string, dstKey string) error {
+	args := m.Called(ctx, srcKey, dstKey)
+	return args.Error(0)
+}
+
+func (m *MockStorageAdapter) OpenFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorageAdapter) OpenFileRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, key, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorageAdapter) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// MockFileRegistry is a mock implementation of files.Registry
+type MockFileRegistry struct {
+	mock.Mock
+}
+
+func (m *MockFileRegistry) Upload(ctx context.Context, file *multipart.FileHeader, key string, contentType string, ownerID string) (*models.File, error) {
+	args := m.Called(ctx, file, key, contentType, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRegistry) Register(ctx context.Context, key string, contentType string, size int64, ownerID string) (*models.File, error) {
+	args := m.Called(ctx, key, contentType, size, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRegistry) Complete(ctx context.Context, file *models.File) error {
+	args := m.Called(ctx, file)
+	return args.Error(0)
+}
+
+func (m *MockFileRegistry) Get(ctx context.Context, key string) (*models.File, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRegistry) GetByID(ctx context.Context, id string) (*models.File, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
+}
+
+func (m *MockFileRegistry) List(ctx context.Context, pr *dtos.FilePageableRequest) (*dtos.DataResponse[models.File], error) {
+	args := m.Called(ctx, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.File]), args.Error(1)
+}
+
+func (m *MockFileRegistry) Transition(ctx context.Context, file *models.File, status string, reason string) error {
+	args := m.Called(ctx, file, status, reason)
+	return args.Error(0)
+}
+
+func (m *MockFileRegistry) PresignedURL(ctx context.Context, file *models.File, duration time.Duration) (string, error) {
+	args := m.Called(ctx, file, duration)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileRegistry) Open(ctx context.Context, file *models.File, offset int64, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, file, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileRegistry) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func TestUserService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *dtos.CreateUserRequest
+		setupMocks    func(*MockUserRepository, *MockCompanyRepository, *MockCache)
+		expectedError bool
+		errorType     string
+	}{
+		{
+			name: "success - create user with companies",
+			req: &dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					FirstName:  "John",
+					LastName:   "Doe",
+					Email:      "john.doe@example.com",
+					KeycloakID: "keycloak-123",
+					Companies: []dtos.UpdateCompanyRequest{
+						{ID: "company-1"},
+						{ID: "company-2"},
+					},
+				},
+			},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				company1 := factory.Company().WithName("Company 1").Build()
+				company2 := factory.Company().WithName("Company 2").Build()
+
+				companyRepo.On("GetOneByID", "company-1").Return(company1, nil)
+				companyRepo.On("GetOneByID", "company-2").Return(company2, nil)
+
+				createdUser := factory.User().
+					WithName("John", "Doe").
+					WithEmail("john.doe@example.com").
+					WithKeycloakID("keycloak-123").
+					WithCompany(company1).
+					WithCompany(company2).
+					Build()
+
+				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(createdUser, nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "success - create user without companies",
+			req: &dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					FirstName:  "Jane",
+					LastName:   "Smith",
+					Email:      "jane.smith@example.com",
+					KeycloakID: "keycloak-456",
+					Companies:  []dtos.UpdateCompanyRequest{},
+				},
+			},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				createdUser := factory.User().
+					WithName("Jane", "Smith").
+					WithEmail("jane.smith@example.com").
+					WithKeycloakID("keycloak-456").
+					Build()
+
+				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(createdUser, nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "error - company not found",
+			req: &dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					FirstName:  "John",
+					LastName:   "Doe",
+					Email:      "john.doe@example.com",
+					KeycloakID: "keycloak-123",
+					Companies: []dtos.UpdateCompanyRequest{
+						{ID: "non-existent"},
+					},
+				},
+			},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				companyRepo.On("GetOneByID", "non-existent").Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+			errorType:     "NotFoundError",
+		},
+		{
+			name: "error - database error on create",
+			req: &dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					FirstName:  "John",
+					LastName:   "Doe",
+					Email:      "john.doe@example.com",
+					KeycloakID: "keycloak-123",
+					Companies:  []dtos.UpdateCompanyRequest{},
+				},
+			},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				userRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil, errors.DatabaseError("Failed to create user", nil))
+			},
+			expectedError: true,
+			errorType:     "DatabaseError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mocks
+			mockUserRepo := new(MockUserRepository)
+			mockCompanyRepo := new(MockCompanyRepository)
+			mockCache := new(MockCache)
+			mockEnqueuer := new(MockEnqueuer)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo, mockCompanyRepo, mockCache)
+			}
+			if !tt.expectedError {
+				// The verification email is sent by a worker, out of the request
+				mockEnqueuer.On("Enqueue", mock.Anything, jobs.SendVerificationEmailArgs{KeycloakID: tt.req.KeycloakID}).
+					Return(&models.Job{}, nil)
+			}
+
+			// Create service with mocks
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: mockCompanyRepo,
+				cache:       mockCache,
+				enqueuer:    mockEnqueuer,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
+			}
+
+			// Execute
+			ctx := context.Background()
+			result, err := service.Create(ctx, tt.req)
+
+			// Assert
 			if tt.expectedError {
 				require.Error(t, err)
 				if tt.errorType != "" {
@@ -297,6 +736,7 @@ func TestUserService_Create(t *testing.T) {
 			// Verify all expectations were met
 			mockUserRepo.AssertExpectations(t)
 			mockCompanyRepo.AssertExpectations(t)
+			mockEnqueuer.AssertExpectations(t)
 		})
 	}
 }
@@ -313,15 +753,30 @@ func TestUserService_GetOneByID(t *testing.T) {
 			userID: uuid.New().String(),
 			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
 				userID := uuid.New()
-				user := &models.User{
-					BaseModel: models.BaseModel{
-						ID: userID.String(),
-					},
-					FirstName: "John",
-					LastName:  "Doe",
-					Email:     "john.doe@example.com",
-				}
+				user := factory.User().WithID(userID.String()).WithName("John", "Doe").WithEmail("john.doe@example.com").Build()
+				cache.On("Get", mock.Anything, mock.AnythingOfType("string")).Return("", errors.NotFoundError("Cache key", nil))
 				userRepo.On("GetOneByID", mock.AnythingOfType("string"), mock.AnythingOfType("[]string")).Return(user, nil)
+				cache.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.MatchedBy(func(value string) bool {
+					return strings.Contains(value, `"FirstName":"John"`)
+				}), isEntityCacheTTL).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:   "success - cache hit",
+			userID: "3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				cache.On("Get", mock.Anything, "user:3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f").Return(`{"id":"3f6c1f5e-8c2a-4d7e-9f1b-2a4b6c8d0e1f","FirstName":"John"}`, nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:   "success - failing cache",
+			userID: uuid.New().String(),
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				cache.On("Get", mock.Anything, mock.AnythingOfType("string")).Return("", errors.CacheError("Failed to get from cache", nil))
+				userRepo.On("GetOneByID", mock.AnythingOfType("string"), mock.AnythingOfType("[]string")).Return(factory.User().Build(), nil)
+				cache.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), isEntityCacheTTL).Return(errors.CacheError("Failed to set cache", nil))
 			},
 			expectedError: false,
 		},
@@ -329,6 +784,7 @@ func TestUserService_GetOneByID(t *testing.T) {
 			name:   "error - user not found",
 			userID: "non-existent",
 			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository, cache *MockCache) {
+				cache.On("Get", mock.Anything, "user:non-existent").Return("", errors.NotFoundError("Cache key", nil))
 				userRepo.On("GetOneByID", "non-existent", mock.AnythingOfType("[]string")).Return(nil, errors.NotFoundError("User", nil))
 			},
 			expectedError: true,
@@ -367,6 +823,812 @@ func TestUserService_GetOneByID(t *testing.T) {
 				assert.NotEmpty(t, result.ID)
 			}
 
+			mockUserRepo.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_GetOneByID_SharesLoads(t *testing.T) {
+	userID := uuid.New().String()
+	release := make(chan struct{})
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetOneByID", userID, mock.AnythingOfType("[]string")).
+		Run(func(mock.Arguments) { <-release }).
+		Return(factory.User().WithID(userID).Build(), nil).Once()
+	mockCache := new(MockCache)
+	var misses atomic.Int32
+	mockCache.On("Get", mock.Anything, constants.UserCacheKeyPrefix+userID).
+		Run(func(mock.Arguments) { misses.Add(1) }).
+		Return("", errors.NotFoundError("Cache key", nil))
+	mockCache.On("Set", mock.Anything, constants.UserCacheKeyPrefix+userID, mock.AnythingOfType("string"), isEntityCacheTTL).Return(nil).Once()
+	service := &userService{userRepo: mockUserRepo, cache: mockCache}
+
+	// The concurrent misses wait for the load of the first one
+	var wg sync.WaitGroup
+	results := make(chan *models.User, 5)
+	for range 5 {
+		wg.Go(func() {
+			user, err := service.GetOneByID(context.Background(), userID)
+			assert.NoError(t, err)
+			results <- user
+		})
+	}
+	require.Eventually(t, func() bool {
+		return misses.Load() == 5
+	}, time.Second, time.Millisecond, "all the callers must miss the cache")
+	// Let the last caller join the load after its miss
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for user := range results {
+		assert.Equal(t, userID, user.ID)
+	}
+	mockUserRepo.AssertNumberOfCalls(t, "GetOneByID", 1)
+	mockCache.AssertExpectations(t)
+}
+
+func TestUserService_Import(t *testing.T) {
+	validRow := func(row int, email string) dtos.ImportUserRow {
+		return dtos.ImportUserRow{
+			Row: row,
+			User: dtos.CreateUserRequest{
+				UserRequest: dtos.UserRequest{
+					FirstName: "John",
+					LastName:  "Doe",
+					Email:     email,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		rows             []dtos.ImportUserRow
+		dryRun           bool
+		setupMocks       func(*MockUserRepository)
+		expectedError    bool
+		expectedValid    int
+		expectedInvalid  int
+		expectedImported int
+	}{
+		{
+			name:   "success - imports all valid rows",
+			rows:   []dtos.ImportUserRow{validRow(2, "john@example.com"), validRow(3, "jane@example.com")},
+			dryRun: false,
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindExistingEmails", []string{"john@example.com", "jane@example.com"}).Return([]string{}, nil)
+				userRepo.On("CreateInBatches", mock.AnythingOfType("[]models.User"), constants.UserImportBatchSize).Return(2, nil)
+			},
+			expectedValid:    2,
+			expectedInvalid:  0,
+			expectedImported: 2,
+		},
+		{
+			name: "dry run - reports row errors without importing",
+			rows: []dtos.ImportUserRow{
+				validRow(2, "John@Example.com"),
+				{Row: 3, Errors: map[string]string{"Email": "Email is required"}},
+				validRow(4, "jane@example.com"),
+			},
+			dryRun: true,
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindExistingEmails", []string{"john@example.com", "jane@example.com"}).Return([]string{"john@example.com"}, nil)
+			},
+			expectedValid:    1,
+			expectedInvalid:  2,
+			expectedImported: 0,
+		},
+		{
+			name: "error - invalid rows reject the import",
+			rows: []dtos.ImportUserRow{
+				validRow(2, "john@example.com"),
+				{Row: 3, Errors: map[string]string{"Email": "Email is required"}},
+			},
+			dryRun: false,
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindExistingEmails", []string{"john@example.com"}).Return([]string{}, nil)
+			},
+			expectedError: true,
+		},
+		{
+			name:   "error - database error on batch insert",
+			rows:   []dtos.ImportUserRow{validRow(2, "john@example.com")},
+			dryRun: false,
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindExistingEmails", []string{"john@example.com"}).Return([]string{}, nil)
+				userRepo.On("CreateInBatches", mock.AnythingOfType("[]models.User"), constants.UserImportBatchSize).
+					Return(0, errors.DatabaseError("Failed to import users", nil))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockCompanyRepo := new(MockCompanyRepository)
+			mockCache := new(MockCache)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo)
+			}
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: mockCompanyRepo,
+				cache:       mockCache,
+			}
+
+			result, err := service.Import(context.Background(), tt.rows, tt.dryRun)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Equal(t, tt.dryRun, result.DryRun)
+				assert.Equal(t, len(tt.rows), result.TotalRows)
+				assert.Equal(t, tt.expectedValid, result.ValidRows)
+				assert.Equal(t, tt.expectedInvalid, result.InvalidRows)
+				assert.Equal(t, tt.expectedImported, result.ImportedRows)
+				assert.Len(t, result.Errors, tt.expectedInvalid)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_UploadAvatar(t *testing.T) {
+	userID := uuid.New().String()
+	avatarKeyPrefix := constants.UserAvatarKeyPrefix + "/" + userID + "/"
+	isNewAvatarKey := mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, avatarKeyPrefix) && strings.HasSuffix(key, ".png")
+	})
+	avatar := factory.File().WithKey(avatarKeyPrefix + "new.png").Build()
+
+	tests := []struct {
+		name                string
+		setupMocks          func(*MockUserRepository, *MockFileRegistry)
+		expectedError       bool
+		expectedProjections []string
+	}{
+		{
+			name: "success - replaces previous avatar",
+			setupMocks: func(userRepo *MockUserRepository, files *MockFileRegistry) {
+				user := factory.User().WithID(userID).WithAvatar("avatars/old.png", 0).WithCompany(factory.Company().WithID("company-1").Build()).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				files.On("Upload", mock.Anything, mock.Anything, isNewAvatarKey, "image/png", userID).Return(avatar, nil)
+				userRepo.On("UpdateAvatar", userID, isNewAvatarKey, int64(2048)).Return(nil)
+				files.On("Delete", mock.Anything, "avatars/old.png").Return(nil)
+				files.On("PresignedURL", mock.Anything, avatar, constants.UserAvatarURLDuration).
+					Return("https://example.com/avatar.png?signature=abc", nil)
+			},
+			expectedError:       false,
+			expectedProjections: []string{"company-1 " + constants.EventUserAvatarUpdated + " " + userID},
+		},
+		{
+			name: "success - old avatar cleanup failure is ignored",
+			setupMocks: func(userRepo *MockUserRepository, files *MockFileRegistry) {
+				user := factory.User().WithID(userID).WithAvatar("avatars/old.png", 0).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				files.On("Upload", mock.Anything, mock.Anything, isNewAvatarKey, "image/png", userID).Return(avatar, nil)
+				userRepo.On("UpdateAvatar", userID, isNewAvatarKey, int64(2048)).Return(nil)
+				files.On("Delete", mock.Anything, "avatars/old.png").Return(errors.ExternalServiceError("delete failed", nil))
+				files.On("PresignedURL", mock.Anything, avatar, constants.UserAvatarURLDuration).
+					Return("https://example.com/avatar.png?signature=abc", nil)
+			},
+			expectedError: false,
+			// A user without companies still publishes the event, without a company
+			expectedProjections: []string{" " + constants.EventUserAvatarUpdated + " " + userID},
+		},
+		{
+			name: "error - user not found",
+			setupMocks: func(userRepo *MockUserRepository, files *MockFileRegistry) {
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(nil, errors.NotFoundError("User", nil))
+			},
+			expectedError: true,
+		},
+		{
+			name: "error - database update removes the uploaded file",
+			setupMocks: func(userRepo *MockUserRepository, files *MockFileRegistry) {
+				user := factory.User().WithID(userID).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				files.On("Upload", mock.Anything, mock.Anything, isNewAvatarKey, "image/png", userID).Return(avatar, nil)
+				userRepo.On("UpdateAvatar", userID, isNewAvatarKey, int64(2048)).Return(errors.DatabaseError("Failed to update user avatar", nil))
+				files.On("Delete", mock.Anything, isNewAvatarKey).Return(nil)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockFiles := new(MockFileRegistry)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo, mockFiles)
+			}
+
+			var projectionEvents []string
+			mockProjections := new(MockProjectionPublisher)
+			mockProjections.On("Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					projectionEvents = append(projectionEvents, args.String(1)+" "+args.String(2)+" "+args.String(3))
+				}).Return(nil).Maybe()
+
+			mockCache := new(MockCache)
+			if !tt.expectedError {
+				mockCache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+userID).Return(nil)
+			}
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: new(MockCompanyRepository),
+				cache:       mockCache,
+				files:       mockFiles,
+				projections: mockProjections,
+			}
+
+			result, err := service.UploadAvatar(context.Background(), userID, &multipart.FileHeader{Filename: "avatar.png", Size: 2048}, "image/png")
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Equal(t, userID, result.UserID)
+				assert.Equal(t, avatar.Key, result.AvatarKey)
+				assert.Equal(t, constants.FileStatusAvailable, result.Status)
+				assert.NotEmpty(t, result.AvatarURL)
+			}
+			assert.Equal(t, tt.expectedProjections, projectionEvents)
+
+			mockUserRepo.AssertExpectations(t)
+			mockFiles.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_GetAvatar(t *testing.T) {
+	userID := uuid.New().String()
+	avatarKey := constants.UserAvatarKeyPrefix + "/" + userID + "/avatar.png"
+
+	tests := []struct {
+		name          string
+		avatarKey     string
+		status        string
+		expectedError errors.ErrorType
+	}{
+		{name: "success - available avatar is presigned", avatarKey: avatarKey, status: constants.FileStatusAvailable},
+		{name: "error - no avatar", expectedError: errors.ErrorTypeNotFound},
+		{name: "error - avatar being scanned", avatarKey: avatarKey, status: constants.FileStatusScanning, expectedError: errors.ErrorTypeConflict},
+		{name: "error - quarantined avatar", avatarKey: avatarKey, status: constants.FileStatusQuarantined, expectedError: errors.ErrorTypeConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockFiles := new(MockFileRegistry)
+			mockUserRepo.On("GetOneByID", userID, []string{}).Return(factory.User().WithID(userID).WithAvatar(tt.avatarKey, 0).Build(), nil)
+			avatar := factory.File().WithKey(tt.avatarKey).WithStatus(tt.status, "").Build()
+			if tt.avatarKey != "" {
+				mockFiles.On("Get", mock.Anything, tt.avatarKey).Return(avatar, nil)
+				if tt.status == constants.FileStatusAvailable {
+					mockFiles.On("PresignedURL", mock.Anything, avatar, constants.UserAvatarURLDuration).
+						Return("https://example.com/avatar.png?signature=abc", nil)
+				} else {
+					mockFiles.On("PresignedURL", mock.Anything, avatar, constants.UserAvatarURLDuration).
+						Return("", errors.ConflictError("File is not available", nil))
+				}
+			}
+
+			service := &userService{userRepo: mockUserRepo, files: mockFiles}
+
+			result, err := service.GetAvatar(context.Background(), userID)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, errors.GetAppError(err).Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.avatarKey, result.AvatarKey)
+				assert.Equal(t, constants.FileStatusAvailable, result.Status)
+				assert.NotEmpty(t, result.AvatarURL)
+			}
+			mockFiles.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Update(t *testing.T) {
+	userID := uuid.New().String()
+	acme := *factory.Company().WithName("Acme Corp").Build()
+	globex := *factory.Company().WithName("Globex").Build()
+	initech := *factory.Company().WithName("Initech").Build()
+
+	tests := []struct {
+		name              string
+		companies         []dtos.UpdateCompanyRequest
+		setupMocks        func(*MockUserRepository, *MockCompanyRepository)
+		publishErr        error
+		expectedError     bool
+		errorType         errors.ErrorType
+		expectedCompanies []string
+		expectedChanges   []string
+		expectedWebhooks  []string
+	}{
+		{
+			name: "success - omitted companies keep the memberships",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(&acme).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("Update", user, []models.Company(nil), []models.Company(nil)).Return(nil)
+			},
+			expectedCompanies: []string{acme.ID},
+			expectedWebhooks:  []string{acme.ID + " user.updated"},
+		},
+		{
+			name:      "success - only the delta is written and published",
+			companies: []dtos.UpdateCompanyRequest{{ID: globex.ID}, {ID: initech.ID}, {ID: initech.ID}},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(&acme).WithCompany(&globex).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", initech.ID).Return(&initech, nil)
+				userRepo.On("Update", user, []models.Company{initech}, []models.Company{acme}).Return(nil)
+			},
+			expectedCompanies: []string{globex.ID, initech.ID},
+			expectedChanges:   []string{initech.ID + " added", acme.ID + " removed"},
+			expectedWebhooks: []string{
+				initech.ID + " member.added",
+				acme.ID + " member.removed",
+				globex.ID + " user.updated",
+				initech.ID + " user.updated",
+			},
+		},
+		{
+			name:      "success - empty list removes all the memberships",
+			companies: []dtos.UpdateCompanyRequest{},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(&acme).WithCompany(&globex).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("Update", user, []models.Company(nil), []models.Company{acme, globex}).Return(nil)
+			},
+			publishErr:        errors.InternalError("Message broker is not configured", messaging.ErrDisabled),
+			expectedCompanies: []string{},
+			expectedChanges:   []string{acme.ID + " removed", globex.ID + " removed"},
+			expectedWebhooks:  []string{acme.ID + " member.removed", globex.ID + " member.removed"},
+		},
+		{
+			name:      "error - added company not found",
+			companies: []dtos.UpdateCompanyRequest{{ID: initech.ID}},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", initech.ID).Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeNotFound,
+		},
+		{
+			name:      "error - database error publishes nothing",
+			companies: []dtos.UpdateCompanyRequest{},
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(&acme).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("Update", user, []models.Company(nil), []models.Company{acme}).
+					Return(errors.DatabaseError("Failed to update user", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockCompanyRepo := new(MockCompanyRepository)
+			tt.setupMocks(mockUserRepo, mockCompanyRepo)
+
+			var changes []string
+			mockBroker := new(MockMessagePublisher)
+			mockBroker.On("Publish", mock.Anything, constants.MessagingTopicUserMembershipChanged, mock.Anything).
+				Run(func(args mock.Arguments) {
+					var event dtos.UserMembershipChangedEvent
+					require.NoError(t, json.Unmarshal(args.Get(2).(messaging.Message).Body, &event))
+					assert.Equal(t, userID, event.UserID)
+					changes = append(changes, event.CompanyID+" "+event.Change)
+				}).Return(tt.publishErr).Maybe()
+
+			var webhookEvents []string
+			mockWebhooks := new(MockWebhookDispatcher)
+			mockWebhooks.On("Dispatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					webhookEvents = append(webhookEvents, args.String(1)+" "+args.String(2))
+				}).Return(nil).Maybe()
+
+			mockCache := new(MockCache)
+			if !tt.expectedError {
+				mockCache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+userID).Return(nil)
+			}
+
+			mockPublisher := new(MockPublisher)
+			mockPublisher.On("Publish", mock.Anything, mock.AnythingOfType("string"), constants.RealtimeEventUserUpdated, mock.Anything).Return(nil).Maybe()
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: mockCompanyRepo,
+				cache:       mockCache,
+				publisher:   mockPublisher,
+				broker:      mockBroker,
+				webhooks:    mockWebhooks,
+				projections: newMockProjectionPublisher(),
+			}
+
+			req := &dtos.UpdateUserRequest{UserRequest: dtos.UserRequest{FirstName: "Jane", Companies: tt.companies}}
+			result, err := service.Update(context.Background(), userID, req)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.errorType, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				companyIDs := make([]string, 0, len(result.Companies))
+				for _, company := range result.Companies {
+					companyIDs = append(companyIDs, company.ID)
+				}
+				assert.Equal(t, tt.expectedCompanies, companyIDs)
+			}
+			assert.Equal(t, tt.expectedChanges, changes)
+			assert.Equal(t, tt.expectedWebhooks, webhookEvents)
+
+			mockUserRepo.AssertExpectations(t)
+			mockCompanyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Patch(t *testing.T) {
+	userID := uuid.New().String()
+	columns := []string{"email", "first_name", "last_name", "keycloak_id"}
+
+	tests := []struct {
+		name          string
+		req           *dtos.PatchUserRequest
+		setupMocks    func(*MockUserRepository)
+		expectPublish bool
+		expectedError bool
+		errorType     errors.ErrorType
+	}{
+		{
+			name: "success - publishes the update to the user connections",
+			req:  &dtos.PatchUserRequest{Email: "jane.doe@example.com", FirstName: "Jane", KeycloakID: "keycloak-123"},
+			setupMocks: func(userRepo *MockUserRepository) {
+				user := factory.User().WithID(userID).WithEmail("john.doe@example.com").WithKeycloakID("keycloak-123").Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("UpdateColumns", user, columns).Return(nil)
+			},
+			expectPublish: true,
+		},
+		{
+			name: "success - cleared fields are written as empty values",
+			req:  &dtos.PatchUserRequest{Email: "john.doe@example.com", FirstName: "John"},
+			setupMocks: func(userRepo *MockUserRepository) {
+				user := factory.User().WithID(userID).WithName("John", "Doe").WithEmail("john.doe@example.com").WithKeycloakID("keycloak-123").Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("UpdateColumns", mock.MatchedBy(func(u *models.User) bool {
+					return u.LastName == "" && u.KeycloakID == "" && u.FirstName == "John"
+				}), columns).Return(nil)
+			},
+		},
+		{
+			name: "error - user not found",
+			req:  &dtos.PatchUserRequest{Email: "john.doe@example.com"},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(nil, errors.NotFoundError("User", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeNotFound,
+		},
+		{
+			name: "error - database error on update",
+			req:  &dtos.PatchUserRequest{Email: "john.doe@example.com"},
+			setupMocks: func(userRepo *MockUserRepository) {
+				user := factory.User().WithID(userID).WithEmail("john.doe@example.com").Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				userRepo.On("UpdateColumns", user, columns).Return(errors.DatabaseError("Failed to update user", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			tt.setupMocks(mockUserRepo)
+			mockPublisher := new(MockPublisher)
+			if tt.expectPublish {
+				mockPublisher.On("Publish", mock.Anything, tt.req.KeycloakID, constants.RealtimeEventUserUpdated, mock.MatchedBy(func(u *dtos.UserResponse) bool {
+					return u.Email == tt.req.Email
+				})).Return(nil)
+			}
+
+			mockCache := new(MockCache)
+			if !tt.expectedError {
+				mockCache.On("Delete", mock.Anything, constants.UserCacheKeyPrefix+userID).Return(nil)
+			}
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: new(MockCompanyRepository),
+				cache:       mockCache,
+				publisher:   mockPublisher,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
+			}
+
+			result, err := service.Patch(context.Background(), userID, tt.req)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.errorType, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Equal(t, tt.req.Email, result.Email)
+				assert.Equal(t, tt.req.LastName, result.LastName)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+			mockPublisher.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_AddCompany(t *testing.T) {
+	userID := uuid.New().String()
+	companyID := uuid.New().String()
+	company := factory.Company().WithID(companyID).WithName("Acme Corp").Build()
+
+	tests := []struct {
+		name              string
+		setupMocks        func(*MockUserRepository, *MockCompanyRepository)
+		expectedError     bool
+		expectedCompanies int
+	}{
+		{
+			name: "success - adds membership",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+				userRepo.On("AddCompany", user, company).Return(nil)
+			},
+			expectedCompanies: 1,
+		},
+		{
+			name: "success - existing membership is a no-op",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(company).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+			},
+			expectedCompanies: 1,
+		},
+		{
+			name: "error - company not found",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(nil, errors.NotFoundError("Company", nil))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockCompanyRepo := new(MockCompanyRepository)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo, mockCompanyRepo)
+			}
+
+			mockBroker := new(MockMessagePublisher)
+			mockBroker.On("Publish", mock.Anything, constants.MessagingTopicUserMembershipChanged, mock.Anything).Return(nil).Maybe()
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: mockCompanyRepo,
+				cache:       new(MockCache),
+				broker:      mockBroker,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
+			}
+
+			result, err := service.AddCompany(context.Background(), userID, companyID)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Len(t, result.Companies, tt.expectedCompanies)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+			mockCompanyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_RemoveCompany(t *testing.T) {
+	userID := uuid.New().String()
+	companyID := uuid.New().String()
+	company := factory.Company().WithID(companyID).WithName("Acme Corp").Build()
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockUserRepository, *MockCompanyRepository)
+		expectedError bool
+		errorType     errors.ErrorType
+	}{
+		{
+			name: "success - removes membership",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(company).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+				userRepo.On("RemoveCompany", user, company).Return(nil)
+			},
+		},
+		{
+			name: "error - user is not a member",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeNotFound,
+		},
+		{
+			name: "error - database error on remove",
+			setupMocks: func(userRepo *MockUserRepository, companyRepo *MockCompanyRepository) {
+				user := factory.User().WithID(userID).WithCompany(company).Build()
+				userRepo.On("GetOneByID", userID, []string{"Companies"}).Return(user, nil)
+				companyRepo.On("GetOneByID", companyID).Return(company, nil)
+				userRepo.On("RemoveCompany", user, company).Return(errors.DatabaseError("Failed to remove company from user", nil))
+			},
+			expectedError: true,
+			errorType:     errors.ErrorTypeDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockCompanyRepo := new(MockCompanyRepository)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo, mockCompanyRepo)
+			}
+
+			mockBroker := new(MockMessagePublisher)
+			mockBroker.On("Publish", mock.Anything, constants.MessagingTopicUserMembershipChanged, mock.Anything).Return(nil).Maybe()
+
+			service := &userService{
+				userRepo:    mockUserRepo,
+				companyRepo: mockCompanyRepo,
+				cache:       new(MockCache),
+				broker:      mockBroker,
+				webhooks:    newMockWebhookDispatcher(),
+				projections: newMockProjectionPublisher(),
+			}
+
+			result, err := service.RemoveCompany(context.Background(), userID, companyID)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				appErr, ok := err.(*errors.AppError)
+				require.True(t, ok, "Expected AppError")
+				assert.Equal(t, tt.errorType, appErr.Type)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.Empty(t, result.Companies)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+			mockCompanyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_Search(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *dtos.UserSearchRequest
+		setupMocks    func(*MockUserRepository)
+		expectedError bool
+		expectedTotal int64
+	}{
+		{
+			name: "success",
+			request: &dtos.UserSearchRequest{
+				PageableRequest: dtos.PageableRequest{Page: 1, PageSize: 10},
+				Q:               "john do",
+			},
+			setupMocks: func(userRepo *MockUserRepository) {
+				results := &dtos.DataResponse[models.UserSearchResult]{
+					Data: []models.UserSearchResult{
+						{
+							User:      *factory.User().WithName("John", "Doe").WithEmail("john.doe@example.com").Build(),
+							Rank:      0.6,
+							Highlight: "<mark>John</mark> <mark>Doe</mark> john.doe@example.com",
+						},
+					},
+					Pageable: &dtos.Pageable{Page: 1, PageSize: 10, Total: 1},
+				}
+				userRepo.On("Search", "john do", &dtos.PageableRequest{Page: 1, PageSize: 10}).Return(results, nil)
+			},
+			expectedError: false,
+			expectedTotal: 1,
+		},
+		{
+			name: "error - repository failure",
+			request: &dtos.UserSearchRequest{
+				PageableRequest: dtos.PageableRequest{Page: 1, PageSize: 10},
+				Q:               "john",
+			},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("Search", "john", mock.AnythingOfType("*dtos.PageableRequest")).
+					Return(nil, errors.DatabaseError("Failed to search users", assert.AnError))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockUserRepo)
+			}
+
+			service := &userService{userRepo: mockUserRepo}
+
+			result, err := service.Search(context.Background(), tt.request)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				require.Len(t, result.Data, 1)
+				assert.Equal(t, "John", result.Data[0].FirstName)
+				assert.Equal(t, tt.expectedTotal, result.Pageable.Total)
+			}
+
 			mockUserRepo.AssertExpectations(t)
 		})
 	}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/webhooks"
+
+	"go.uber.org/zap"
+)
+
+type WebhookService interface {
+	// Create returns the new endpoint with its signing secret, which can only be read once.
+	// The number of endpoints of a company is capped by the webhooks entitlement of its plan.
+	Create(ctx context.Context, companyID string, req *dtos.CreateWebhookRequest, createdBy string) (*models.WebhookEndpoint, string, error)
+	List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error)
+	Get(ctx context.Context, companyID string, webhookID string) (*models.WebhookEndpoint, error)
+	// Update changes the fields of an endpoint present in the request; its filter is
+	// validated again as a whole
+	Update(ctx context.Context, companyID string, webhookID string, req *dtos.UpdateWebhookRequest) (*models.WebhookEndpoint, error)
+	// RotateSecret replaces the signing secret of an endpoint and returns the new one, which
+	// can only be read once. The pending deliveries are signed with the new secret.
+	RotateSecret(ctx context.Context, companyID string, webhookID string) (*models.WebhookEndpoint, string, error)
+	// SendTest delivers a test event to an active endpoint, whatever its event types
+	SendTest(ctx context.Context, companyID string, webhookID string) (*models.WebhookDelivery, error)
+	// Delete stops the deliveries to an endpoint; its delivery logs are kept
+	Delete(ctx context.Context, companyID string, webhookID string) error
+	// ListDeliveries returns the delivery logs of the company, of an endpoint when the
+	// request has a webhook ID
+	ListDeliveries(ctx context.Context, companyID string, pageableRequest *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error)
+	GetDelivery(ctx context.Context, companyID string, deliveryID string) (*models.WebhookDelivery, error)
+	// Redeliver sends the payload of a delivery again, whatever its status, as a new delivery
+	Redeliver(ctx context.Context, companyID string, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+// webhookService handles the webhook endpoints of the companies and their deliveries
+type webhookService struct {
+	webhookRepo repositories.WebhookRepository
+	companyRepo repositories.CompanyRepository
+	dispatcher  webhooks.Dispatcher
+	keys        kms.KeyManager
+	billing     BillingService
+}
+
+// ProvideWebhookService creates a new webhook service
+func ProvideWebhookService(
+	webhookRepo repositories.WebhookRepository,
+	companyRepo repositories.CompanyRepository,
+	dispatcher webhooks.Dispatcher,
+	keys kms.KeyManager,
+	billing BillingService,
+) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		companyRepo: companyRepo,
+		dispatcher:  dispatcher,
+		keys:        keys,
+		billing:     billing,
+	}
+}
+
+func (s *webhookService) Create(ctx context.Context, companyID string, req *dtos.CreateWebhookRequest, createdBy string) (*models.WebhookEndpoint, string, error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, "", errors.NotFoundError("Company", err).
+			WithOperation("create_webhook_endpoint").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	conditions, err := validateWebhookFilter(req.EventTypes, req.Conditions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	count, err := s.webhookRepo.CountEndpoints(companyID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.billing.CheckQuota(ctx, companyID, constants.EntitlementWebhooks, count); err != nil {
+		return nil, "", err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		BaseModel:   models.NewBaseModel(),
+		CompanyID:   companyID,
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  req.EventTypes,
+		Conditions:  conditions,
+		Active:      true,
+		RateLimit:   req.RateLimit,
+		CreatedBy:   createdBy,
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, "", errors.InternalError("Failed to generate webhook secret", err).
+			WithOperation("create_webhook_endpoint").
+			WithResource("webhook_endpoint")
+	}
+	if err := webhooks.SealSecret(ctx, s.keys, endpoint, secret); err != nil {
+		s.logError("create_webhook_endpoint", endpoint, err)
+		if errors.IsAppError(err) {
+			return nil, "", err
+		}
+		return nil, "", errors.InternalError("Failed to encrypt webhook secret", err).
+			WithOperation("create_webhook_endpoint").
+			WithResource("webhook_endpoint")
+	}
+
+	if err := s.webhookRepo.CreateEndpoint(endpoint); err != nil {
+		s.logError("create_webhook_endpoint", endpoint, err)
+		return nil, "", err
+	}
+
+	logger.Log.Info("Webhook endpoint created",
+		zap.String("company_id", companyID),
+		zap.String("webhook_id", endpoint.ID),
+		zap.Strings("event_types", endpoint.EventTypes),
+	)
+
+	return endpoint, secret, nil
+}
+
+func (s *webhookService) List(ctx context.Context, companyID string, pageableRequest *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error) {
+	if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_webhook_endpoints").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return s.webhookRepo.GetEndpoints(companyID, pageableRequest)
+}
+
+func (s *webhookService) Get(ctx context.Context, companyID string, webhookID string) (*models.WebhookEndpoint, error) {
+	return s.webhookRepo.GetEndpoint(companyID, webhookID)
+}
+
+func (s *webhookService) Update(ctx context.Context, companyID string, webhookID string, req *dtos.UpdateWebhookRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(companyID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EventTypes != nil || req.Conditions != nil {
+		eventTypes := endpoint.EventTypes
+		if req.EventTypes != nil {
+			eventTypes = req.EventTypes
+		}
+
+		conditions := req.Conditions
+		if conditions == nil && len(endpoint.Conditions) > 0 {
+			// The conditions were validated when stored
+			_ = json.Unmarshal(endpoint.Conditions, &conditions)
+		}
+
+		endpoint.Conditions, err = validateWebhookFilter(eventTypes, conditions)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = eventTypes
+	}
+
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+	if req.RateLimit != nil {
+		endpoint.RateLimit = *req.RateLimit
+	}
+
+	if err := s.webhookRepo.UpdateEndpoint(endpoint); err != nil {
+		s.logError("update_webhook_endpoint", endpoint, err)
+		return nil, err
+	}
+
+	logger.Log.Info("Webhook endpoint updated",
+		zap.String("company_id", companyID),
+		zap.String("webhook_id", endpoint.ID),
+		zap.Bool("active", endpoint.Active),
+	)
+
+	return endpoint, nil
+}
+
+func (s *webhookService) RotateSecret(ctx context.Context, companyID string, webhookID string) (*models.WebhookEndpoint, string, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(companyID, webhookID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return nil, "", errors.InternalError("Failed to generate webhook secret", err).
+			WithOperation("rotate_webhook_secret").
+			WithResource("webhook_endpoint")
+	}
+	if err := webhooks.SealSecret(ctx, s.keys, endpoint, secret); err != nil {
+		s.logError("rotate_webhook_secret", endpoint, err)
+		if errors.IsAppError(err) {
+			return nil, "", err
+		}
+		return nil, "", errors.InternalError("Failed to encrypt webhook secret", err).
+			WithOperation("rotate_webhook_secret").
+			WithResource("webhook_endpoint")
+	}
+
+	if err := s.webhookRepo.UpdateEndpoint(endpoint); err != nil {
+		s.logError("rotate_webhook_secret", endpoint, err)
+		return nil, "", err
+	}
+
+	logger.Log.Info("Webhook secret rotated",
+		zap.String("company_id", companyID),
+		zap.String("webhook_id", endpoint.ID),
+	)
+
+	return endpoint, secret, nil
+}
+
+func (s *webhookService) SendTest(ctx context.Context, companyID string, webhookID string) (*models.WebhookDelivery, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(companyID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sender fails the deliveries to inactive endpoints without sending them
+	if !endpoint.Active {
+		return nil, errors.ConflictError("Webhook endpoint is inactive", nil).
+			WithOperation("send_test_webhook").
+			WithResource("webhook_endpoint").
+			WithContext("webhook_id", endpoint.ID)
+	}
+
+	delivery, err := s.dispatcher.Test(ctx, endpoint)
+	if err != nil {
+		s.logError("send_test_webhook", endpoint, err)
+		return nil, err
+	}
+
+	logger.Log.Info("Webhook test event sent",
+		zap.String("company_id", companyID),
+		zap.String("webhook_id", endpoint.ID),
+		zap.String("delivery_id", delivery.ID),
+	)
+
+	return delivery, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, companyID string, webhookID string) error {
+	endpoint, err := s.webhookRepo.GetEndpoint(companyID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.DeleteEndpoint(endpoint); err != nil {
+		s.logError("delete_webhook_endpoint", endpoint, err)
+		return err
+	}
+
+	logger.Log.Info("Webhook endpoint deleted",
+		zap.String("company_id", companyID),
+		zap.String("webhook_id", endpoint.ID),
+	)
+
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, companyID string, pageableRequest *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error) {
+	if pageableRequest.WebhookID != "" {
+		if _, err := s.webhookRepo.GetEndpoint(companyID, pageableRequest.WebhookID); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.companyRepo.GetOneByID(companyID); err != nil {
+		return nil, errors.NotFoundError("Company", err).
+			WithOperation("get_webhook_deliveries").
+			WithResource("company").
+			WithContext("company_id", companyID)
+	}
+
+	return s.webhookRepo.GetDeliveries(companyID, pageableRequest)
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, companyID string, deliveryID string) (*models.WebhookDelivery, error) {
+	return s.webhookRepo.GetDelivery(companyID, deliveryID)
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, companyID string, deliveryID string) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.GetDelivery(companyID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A deleted endpoint is not found, its deliveries cannot be sent anymore
+	if _, err := s.webhookRepo.GetEndpoint(companyID, delivery.EndpointID); err != nil {
+		return nil, err
+	}
+
+	redelivery, err := s.dispatcher.Redeliver(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Webhook delivery redelivered",
+		zap.String("company_id", companyID),
+		zap.String("delivery_id", delivery.ID),
+		zap.String("redelivery_id", redelivery.ID),
+	)
+
+	return redelivery, nil
+}
+
+func (s *webhookService) logError(operation string, endpoint *models.WebhookEndpoint, err error) {
+	logger.Log.Error("Webhook operation failed",
+		zap.String("operation", operation),
+		zap.String("company_id", endpoint.CompanyID),
+		zap.String("webhook_id", endpoint.ID),
+		zap.Error(err),
+	)
+}
+
+// validateWebhookFilter checks the event types and conditions of an endpoint and returns
+// the conditions to store. The event types must be known, unless they are patterns.
+func validateWebhookFilter(eventTypes []string, conditions []dtos.WebhookCondition) (json.RawMessage, error) {
+	for _, eventType := range eventTypes {
+		if !strings.HasSuffix(eventType, "*") && !slices.Contains(constants.WebhookEventTypes, eventType) {
+			return nil, errors.ValidationError(fmt.Sprintf("Unknown event type %q", eventType), nil).
+				WithOperation("validate_webhook_filter").
+				WithResource("webhook_endpoint").
+				WithContext("event_type", eventType)
+		}
+	}
+
+	filter := webhooks.Filter{EventTypes: eventTypes}
+	for _, condition := range conditions {
+		filter.Conditions = append(filter.Conditions, webhooks.Condition{
+			Path:     condition.Path,
+			Operator: condition.Operator,
+			Value:    condition.Value,
+		})
+	}
+	if _, err := filter.Compile(); err != nil {
+		return nil, errors.ValidationError("Invalid webhook filter: "+err.Error(), err).
+			WithOperation("validate_webhook_filter").
+			WithResource("webhook_endpoint")
+	}
+
+	if len(filter.Conditions) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(filter.Conditions)
+	if err != nil {
+		return nil, errors.InternalError("Failed to encode webhook conditions", err).
+			WithOperation("validate_webhook_filter").
+			WithResource("webhook_endpoint")
+	}
+
+	return encoded, nil
+}
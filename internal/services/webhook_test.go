@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/factory"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/webhooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetEndpoint(companyID string, id string) (*models.WebhookEndpoint, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetEndpoints(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.WebhookEndpoint]), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CountEndpoints(companyID string) (int64, error) {
+	args := m.Called(companyID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetActiveEndpoints(companyID string) ([]models.WebhookEndpoint, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetEndpointsByURL(companyID string, urls []string) ([]models.WebhookEndpoint, error) {
+	args := m.Called(companyID, urls)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDelivery(companyID string, id string) (*models.WebhookDelivery, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveryWithEndpoint(id string) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(companyID string, pr *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.WebhookDelivery]), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SaveAttempt(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func newTestWebhookService(t *testing.T, webhookRepo *MockWebhookRepository, companyRepo *MockCompanyRepositoryForCompanyService, dispatcher *MockWebhookDispatcher) *webhookService {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keys, err := kms.NewLocalKeyManager(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+
+	// Plans do not limit the endpoints unless a test replaces billing
+	billing := new(MockBillingService)
+	billing.On("CheckQuota", mock.Anything, constants.EntitlementWebhooks, mock.Anything).Return(nil).Maybe()
+
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		companyRepo: companyRepo,
+		dispatcher:  dispatcher,
+		keys:        keys,
+		billing:     billing,
+	}
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	webhookRepo := new(MockWebhookRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	billing := new(MockBillingService)
+	service := newTestWebhookService(t, webhookRepo, companyRepo, new(MockWebhookDispatcher))
+	service.billing = billing
+
+	companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+	webhookRepo.On("CountEndpoints", "company-1").Return(int64(2), nil)
+	billing.On("CheckQuota", "company-1", constants.EntitlementWebhooks, int64(2)).Return(nil)
+	webhookRepo.On("CreateEndpoint", mock.AnythingOfType("*models.WebhookEndpoint")).Return(nil)
+
+	endpoint, secret, err := service.Create(context.Background(), "company-1", &dtos.CreateWebhookRequest{
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{"user.*", "company.updated"},
+		Conditions: []dtos.WebhookCondition{{Path: "$.email", Operator: "exists"}},
+		RateLimit:  60,
+	}, "manager-1")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	assert.True(t, endpoint.Active)
+	assert.Equal(t, "manager-1", endpoint.CreatedBy)
+	assert.JSONEq(t, `[{"path":"$.email","operator":"exists"}]`, string(endpoint.Conditions))
+	assert.NotContains(t, string(endpoint.Ciphertext), secret)
+
+	// The stored secret opens back to the returned one
+	opened, err := webhooks.OpenSecret(context.Background(), service.keys, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, secret, opened)
+
+	webhookRepo.AssertExpectations(t)
+	billing.AssertExpectations(t)
+}
+
+func TestWebhookService_Create_PlanLimit(t *testing.T) {
+	webhookRepo := new(MockWebhookRepository)
+	companyRepo := new(MockCompanyRepositoryForCompanyService)
+	billing := new(MockBillingService)
+	service := newTestWebhookService(t, webhookRepo, companyRepo, new(MockWebhookDispatcher))
+	service.billing = billing
+
+	companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+	webhookRepo.On("CountEndpoints", "company-1").Return(int64(3), nil)
+	billing.On("CheckQuota", "company-1", constants.EntitlementWebhooks, int64(3)).Return(errors.ForbiddenError("Plan limit reached", nil))
+
+	_, _, err := service.Create(context.Background(), "company-1", &dtos.CreateWebhookRequest{
+		URL:        "https://example.com/webhooks",
+		EventTypes: []string{"*"},
+	}, "manager-1")
+
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, errors.ErrorTypeForbidden, appErr.Type)
+	webhookRepo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+}
+
+func TestWebhookService_Create_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes []string
+		conditions []dtos.WebhookCondition
+	}{
+		{name: "unknown event type", eventTypes: []string{"invoice.paid"}},
+		{name: "invalid pattern", eventTypes: []string{"user*"}},
+		{name: "invalid path", eventTypes: []string{"*"}, conditions: []dtos.WebhookCondition{{Path: "email", Operator: "exists"}}},
+		{name: "in without array", eventTypes: []string{"*"}, conditions: []dtos.WebhookCondition{{Path: "$.email", Operator: "in", Value: "a@example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			companyRepo := new(MockCompanyRepositoryForCompanyService)
+			service := newTestWebhookService(t, webhookRepo, companyRepo, new(MockWebhookDispatcher))
+			companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+
+			_, _, err := service.Create(context.Background(), "company-1", &dtos.CreateWebhookRequest{
+				URL:        "https://example.com/webhooks",
+				EventTypes: tt.eventTypes,
+				Conditions: tt.conditions,
+			}, "manager-1")
+
+			var appErr *errors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, errors.ErrorTypeValidation, appErr.Type)
+			webhookRepo.AssertNotCalled(t, "CreateEndpoint", mock.Anything)
+		})
+	}
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	delivery := &models.WebhookDelivery{
+		BaseModel:  models.NewBaseModel(),
+		CompanyID:  "company-1",
+		EndpointID: "endpoint-1",
+		EventID:    "event-1",
+		Status:     "failed",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		dispatcher := new(MockWebhookDispatcher)
+		service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), dispatcher)
+
+		webhookRepo.On("GetDelivery", "company-1", delivery.ID).Return(delivery, nil)
+		webhookRepo.On("GetEndpoint", "company-1", "endpoint-1").Return(&models.WebhookEndpoint{}, nil)
+		redelivery := &models.WebhookDelivery{BaseModel: models.NewBaseModel(), EventID: "event-1", RedeliveryOf: &delivery.ID}
+		dispatcher.On("Redeliver", mock.Anything, delivery).Return(redelivery, nil)
+
+		result, err := service.Redeliver(context.Background(), "company-1", delivery.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, redelivery, result)
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("deleted endpoint", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		dispatcher := new(MockWebhookDispatcher)
+		service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), dispatcher)
+
+		webhookRepo.On("GetDelivery", "company-1", delivery.ID).Return(delivery, nil)
+		webhookRepo.On("GetEndpoint", "company-1", "endpoint-1").Return(nil, errors.NotFoundError("Webhook endpoint", nil))
+
+		_, err := service.Redeliver(context.Background(), "company-1", delivery.ID)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+		dispatcher.AssertNotCalled(t, "Redeliver", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebhookService_Update(t *testing.T) {
+	newEndpoint := func() *models.WebhookEndpoint {
+		return &models.WebhookEndpoint{
+			BaseModel:  models.NewBaseModel(),
+			CompanyID:  "company-1",
+			URL:        "https://example.com/webhooks",
+			EventTypes: []string{"user.*"},
+			Conditions: []byte(`[{"path":"$.email","operator":"exists"}]`),
+			Active:     true,
+		}
+	}
+	active := false
+	url := "https://example.com/hooks"
+
+	tests := []struct {
+		name               string
+		req                *dtos.UpdateWebhookRequest
+		expectedEventTypes []string
+		expectedConditions string
+		expectedError      errors.ErrorType
+	}{
+		{
+			name:               "pause keeps the filter",
+			req:                &dtos.UpdateWebhookRequest{Active: &active, URL: &url},
+			expectedEventTypes: []string{"user.*"},
+			expectedConditions: `[{"path":"$.email","operator":"exists"}]`,
+		},
+		{
+			name:               "event types keep the conditions",
+			req:                &dtos.UpdateWebhookRequest{EventTypes: []string{"company.updated"}},
+			expectedEventTypes: []string{"company.updated"},
+			expectedConditions: `[{"path":"$.email","operator":"exists"}]`,
+		},
+		{
+			name:               "empty conditions are removed",
+			req:                &dtos.UpdateWebhookRequest{Conditions: []dtos.WebhookCondition{}},
+			expectedEventTypes: []string{"user.*"},
+		},
+		{
+			name:          "invalid filter",
+			req:           &dtos.UpdateWebhookRequest{EventTypes: []string{"invoice.paid"}},
+			expectedError: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), new(MockWebhookDispatcher))
+			endpoint := newEndpoint()
+			webhookRepo.On("GetEndpoint", "company-1", endpoint.ID).Return(endpoint, nil)
+			webhookRepo.On("UpdateEndpoint", endpoint).Return(nil).Maybe()
+
+			result, err := service.Update(context.Background(), "company-1", endpoint.ID, tt.req)
+
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				webhookRepo.AssertNotCalled(t, "UpdateEndpoint", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedEventTypes, result.EventTypes)
+			if tt.expectedConditions == "" {
+				assert.Empty(t, result.Conditions)
+			} else {
+				assert.JSONEq(t, tt.expectedConditions, string(result.Conditions))
+			}
+			if tt.req.Active != nil {
+				assert.False(t, result.Active)
+				assert.Equal(t, url, result.URL)
+			}
+			webhookRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_RotateSecret(t *testing.T) {
+	webhookRepo := new(MockWebhookRepository)
+	service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), new(MockWebhookDispatcher))
+
+	endpoint := &models.WebhookEndpoint{BaseModel: models.NewBaseModel(), CompanyID: "company-1"}
+	previous, err := webhooks.NewSecret()
+	require.NoError(t, err)
+	require.NoError(t, webhooks.SealSecret(context.Background(), service.keys, endpoint, previous))
+	webhookRepo.On("GetEndpoint", "company-1", endpoint.ID).Return(endpoint, nil)
+	webhookRepo.On("UpdateEndpoint", endpoint).Return(nil)
+
+	result, secret, err := service.RotateSecret(context.Background(), "company-1", endpoint.ID)
+
+	require.NoError(t, err)
+	assert.NotEqual(t, previous, secret)
+	opened, err := webhooks.OpenSecret(context.Background(), service.keys, result)
+	require.NoError(t, err)
+	assert.Equal(t, secret, opened)
+	webhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_SendTest(t *testing.T) {
+	tests := []struct {
+		name          string
+		active        bool
+		expectedError errors.ErrorType
+	}{
+		{name: "active endpoint", active: true},
+		{name: "inactive endpoint", active: false, expectedError: errors.ErrorTypeConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			dispatcher := new(MockWebhookDispatcher)
+			service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), dispatcher)
+
+			endpoint := &models.WebhookEndpoint{BaseModel: models.NewBaseModel(), CompanyID: "company-1", Active: tt.active}
+			webhookRepo.On("GetEndpoint", "company-1", endpoint.ID).Return(endpoint, nil)
+			delivery := &models.WebhookDelivery{BaseModel: models.NewBaseModel(), EndpointID: endpoint.ID}
+			dispatcher.On("Test", mock.Anything, endpoint).Return(delivery, nil).Maybe()
+
+			result, err := service.SendTest(context.Background(), "company-1", endpoint.ID)
+
+			if tt.expectedError != "" {
+				var appErr *errors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedError, appErr.Type)
+				dispatcher.AssertNotCalled(t, "Test", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, delivery, result)
+		})
+	}
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	deliveries := &dtos.DataResponse[models.WebhookDelivery]{Data: []models.WebhookDelivery{{EventType: "user.created"}}}
+
+	t.Run("company", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		companyRepo := new(MockCompanyRepositoryForCompanyService)
+		service := newTestWebhookService(t, webhookRepo, companyRepo, new(MockWebhookDispatcher))
+
+		pr := &dtos.WebhookDeliveryPageableRequest{Status: "failed"}
+		companyRepo.On("GetOneByID", "company-1").Return(factory.Company().Build(), nil)
+		webhookRepo.On("GetDeliveries", "company-1", pr).Return(deliveries, nil)
+
+		result, err := service.ListDeliveries(context.Background(), "company-1", pr)
+
+		require.NoError(t, err)
+		assert.Equal(t, deliveries, result)
+		webhookRepo.AssertNotCalled(t, "GetEndpoint", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown endpoint", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		service := newTestWebhookService(t, webhookRepo, new(MockCompanyRepositoryForCompanyService), new(MockWebhookDispatcher))
+
+		pr := &dtos.WebhookDeliveryPageableRequest{WebhookID: "endpoint-2"}
+		webhookRepo.On("GetEndpoint", "company-1", "endpoint-2").Return(nil, errors.NotFoundError("Webhook endpoint", nil))
+
+		_, err := service.ListDeliveries(context.Background(), "company-1", pr)
+
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors.ErrorTypeNotFound, appErr.Type)
+		webhookRepo.AssertNotCalled(t, "GetDeliveries", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,108 @@
+package shutdown
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// drainLogInterval is how often the remaining connections are logged while draining
+const drainLogInterval = time.Second
+
+// ConnTracker counts the open connections of an HTTP server. Set its ConnState method as
+// the http.Server ConnState hook.
+type ConnTracker struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+}
+
+// NewConnTracker creates an empty connection tracker
+func NewConnTracker() *ConnTracker {
+	return &ConnTracker{states: make(map[net.Conn]http.ConnState)}
+}
+
+// ConnState records a connection state change
+func (t *ConnTracker) ConnState(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(t.states, conn)
+	default:
+		t.states[conn] = state
+	}
+}
+
+// Open returns the number of open connections
+func (t *ConnTracker) Open() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.states)
+}
+
+// Active returns the number of connections serving a request
+func (t *ConnTracker) Active() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	active := 0
+	for _, state := range t.states {
+		if state == http.StateActive {
+			active++
+		}
+	}
+	return active
+}
+
+// DrainHTTP shuts the server down, waiting for the in-flight requests until ctx expires,
+// then closes the connections left. Draining progress is logged every second, and the
+// connections open at the start and left at the end are recorded as the
+// Custom/Shutdown/http/OpenConnections and Custom/Shutdown/http/RemainingConnections metrics.
+func DrainHTTP(ctx context.Context, srv *http.Server, tracker *ConnTracker, nrApp *newrelic.Application) error {
+	open, active := tracker.Open(), tracker.Active()
+	logger.Log.Info("Draining HTTP connections",
+		zap.Int("open_connections", open),
+		zap.Int("active_requests", active),
+	)
+	nrApp.RecordCustomMetric("Custom/Shutdown/"+ComponentHTTP+"/OpenConnections", float64(open))
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(drainLogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				logger.Log.Info("Waiting for HTTP connections to drain",
+					zap.Int("open_connections", tracker.Open()),
+					zap.Int("active_requests", tracker.Active()),
+				)
+			}
+		}
+	}()
+
+	err := srv.Shutdown(ctx)
+	remaining := tracker.Open()
+	nrApp.RecordCustomMetric("Custom/Shutdown/"+ComponentHTTP+"/RemainingConnections", float64(remaining))
+	if err == nil {
+		return nil
+	}
+
+	logger.Log.Warn("HTTP connections did not drain in time, closing them",
+		zap.Int("remaining_connections", remaining),
+		zap.Int("active_requests", tracker.Active()),
+	)
+	return stderrors.Join(err, srv.Close())
+}
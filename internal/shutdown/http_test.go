@@ -0,0 +1,75 @@
+package shutdown
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainHTTP(t *testing.T) {
+	observeLogs(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	tracker := NewConnTracker()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	srv.Config.ConnState = tracker.ConnState
+	srv.Start()
+	defer srv.Close()
+
+	requestErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		requestErr <- err
+	}()
+	<-started
+	assert.Equal(t, 1, tracker.Open())
+	assert.Equal(t, 1, tracker.Active())
+
+	t.Run("closes connections left at the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		err := DrainHTTP(ctx, srv.Config, tracker, nil)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		// The hung request loses its connection
+		select {
+		case err := <-requestErr:
+			assert.Error(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("connection was not closed")
+		}
+	})
+	close(release)
+}
+
+func TestConnTracker(t *testing.T) {
+	tracker := NewConnTracker()
+	first, second := &net.TCPConn{}, &net.TCPConn{}
+
+	tracker.ConnState(first, http.StateNew)
+	tracker.ConnState(second, http.StateNew)
+	tracker.ConnState(first, http.StateActive)
+	assert.Equal(t, 2, tracker.Open())
+	assert.Equal(t, 1, tracker.Active())
+
+	tracker.ConnState(first, http.StateIdle)
+	tracker.ConnState(second, http.StateHijacked)
+	assert.Equal(t, 1, tracker.Open())
+	assert.Equal(t, 0, tracker.Active())
+
+	tracker.ConnState(first, http.StateClosed)
+	assert.Equal(t, 0, tracker.Open())
+}
@@ -0,0 +1,52 @@
+package shutdown
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Readiness tells the readiness probe whether the server still accepts traffic. It turns
+// not ready once the shutdown starts, so that the load balancers stop routing requests to
+// the instance before its connections are drained.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// NewReadiness creates a ready readiness
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// ProvideReadiness creates the readiness of the server
+func ProvideReadiness() *Readiness {
+	return NewReadiness()
+}
+
+// Ready reports whether the shutdown has not started
+func (r *Readiness) Ready() bool {
+	return !r.draining.Load()
+}
+
+// Drain turns the readiness not ready, then keeps serving for delay, the time the load
+// balancers take to notice it, or until ctx is done
+func (r *Readiness) Drain(ctx context.Context, delay time.Duration) {
+	r.draining.Store(true)
+	if delay <= 0 {
+		return
+	}
+
+	logger.Log.Info("Readiness turned not ready, waiting for the load balancers",
+		zap.Duration("delay", delay),
+	)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,41 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness_Drain(t *testing.T) {
+	tests := []struct {
+		name        string
+		delay       time.Duration
+		ctxTimeout  time.Duration
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{name: "without delay", delay: 0, ctxTimeout: time.Second, maxDuration: 20 * time.Millisecond},
+		{name: "waits for the delay", delay: 30 * time.Millisecond, ctxTimeout: time.Second, minDuration: 30 * time.Millisecond, maxDuration: 500 * time.Millisecond},
+		{name: "stops at the context deadline", delay: time.Minute, ctxTimeout: 20 * time.Millisecond, minDuration: 20 * time.Millisecond, maxDuration: 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observeLogs(t)
+			readiness := NewReadiness()
+			assert.True(t, readiness.Ready())
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.ctxTimeout)
+			defer cancel()
+			startedAt := time.Now()
+			readiness.Drain(ctx, tt.delay)
+			elapsed := time.Since(startedAt)
+
+			assert.False(t, readiness.Ready())
+			assert.GreaterOrEqual(t, elapsed, tt.minDuration)
+			assert.Less(t, elapsed, tt.maxDuration)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package shutdown
+
+import (
+	"cmp"
+	"context"
+	stderrors "errors"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/logger"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/fx"
+)
+
+// Phase orders the shutdown. The phases run one after the other, the components of a
+// phase stop concurrently.
+type Phase int
+
+const (
+	// PhaseReadiness reports the instance not ready until the load balancers notice
+	PhaseReadiness Phase = iota
+	// PhaseIngress stops accepting requests and drains the in-flight ones
+	PhaseIngress
+	// PhaseSchedulers stops triggering the scheduled jobs and waits for the running ones
+	PhaseSchedulers
+	// PhaseConsumers stops consuming messages and waits for the ones being handled
+	PhaseConsumers
+	// PhaseWorkers waits for the running jobs of the task queue
+	PhaseWorkers
+	// PhaseFlush writes the records buffered by the requests and the jobs
+	PhaseFlush
+	// PhaseDatabase closes the database connections once nothing uses them anymore
+	PhaseDatabase
+	// PhaseTelemetry sends the pending Sentry events and New Relic data, last so that the
+	// failures of the other phases are reported
+	PhaseTelemetry
+)
+
+// step is a component registered in the sequence
+type step struct {
+	phase    Phase
+	name     string
+	deadline time.Duration
+	stop     func(ctx context.Context) error
+}
+
+// Sequence stops the components of the application in the order of their phases. fx runs
+// the OnStop hooks in the reverse order of construction, which follows the dependencies
+// rather than the order a graceful shutdown needs, e.g. the database could be closed
+// before the consumers still writing to it; components register in the sequence instead.
+type Sequence struct {
+	watchdog *Watchdog
+
+	mu    sync.Mutex
+	steps []step
+}
+
+// NewSequence creates an empty sequence timing its components with watchdog
+func NewSequence(watchdog *Watchdog) *Sequence {
+	return &Sequence{watchdog: watchdog}
+}
+
+// ProvideSequence creates the shutdown sequence of the application, run by a single OnStop
+// hook, with the flush of Sentry and New Relic as its last phase
+func ProvideSequence(lc fx.Lifecycle, watchdog *Watchdog, nrApp *newrelic.Application) *Sequence {
+	s := NewSequence(watchdog)
+	s.Register(PhaseTelemetry, ComponentSentry, constants.ShutdownTelemetryTimeout, func(context.Context) error {
+		if !sentry.Flush(constants.ShutdownTelemetryTimeout) {
+			return stderrors.New("events left unsent")
+		}
+		return nil
+	})
+	s.Register(PhaseTelemetry, ComponentNewRelic, constants.ShutdownTelemetryTimeout, func(context.Context) error {
+		nrApp.Shutdown(constants.ShutdownTelemetryTimeout)
+		return nil
+	})
+
+	lc.Append(fx.Hook{OnStop: s.Run})
+	return s
+}
+
+// Register adds the stop function of a component to a phase. The watchdog times it under
+// deadline; a zero deadline runs it untimed, for waits bounded on their own such as the
+// readiness delay.
+func (s *Sequence) Register(phase Phase, name string, deadline time.Duration, stop func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, step{phase: phase, name: name, deadline: deadline, stop: stop})
+}
+
+// Run stops the registered components phase after phase, and starts the hard cap of the
+// watchdog. A failed component does not end the sequence, so that the database is still
+// closed and the telemetry flushed after a failed drain; the errors are returned joined.
+func (s *Sequence) Run(ctx context.Context) error {
+	s.watchdog.arm()
+	logger.Log.Info("Shutting down")
+
+	s.mu.Lock()
+	steps := slices.Clone(s.steps)
+	s.mu.Unlock()
+	slices.SortStableFunc(steps, func(a, b step) int {
+		return cmp.Compare(a.phase, b.phase)
+	})
+
+	var errs []error
+	for start := 0; start < len(steps); {
+		end := start + 1
+		for end < len(steps) && steps[end].phase == steps[start].phase {
+			end++
+		}
+		errs = append(errs, s.runPhase(ctx, steps[start:end])...)
+		start = end
+	}
+
+	if err := stderrors.Join(errs...); err != nil {
+		return err
+	}
+	logger.Log.Info("Shutdown completed")
+	return nil
+}
+
+// runPhase stops the components of a phase concurrently and returns their errors
+func (s *Sequence) runPhase(ctx context.Context, steps []step) []error {
+	errs := make([]error, len(steps))
+	var wg sync.WaitGroup
+	for i, st := range steps {
+		wg.Go(func() {
+			if st.deadline <= 0 {
+				errs[i] = st.stop(ctx)
+				return
+			}
+			errs[i] = s.watchdog.Stop(ctx, st.name, st.deadline, st.stop)
+		})
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,114 @@
+package shutdown
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the order the components of a sequence stop in
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) stop(name string, err error) func(context.Context) error {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stopped = append(r.stopped, name)
+		return err
+	}
+}
+
+func TestSequence_RunsPhasesInOrder(t *testing.T) {
+	observeLogs(t)
+	s := NewSequence(NewWatchdog(time.Minute, nil))
+	r := &recorder{}
+
+	// Registered in the order fx would construct them, not the order they stop in
+	s.Register(PhaseTelemetry, ComponentSentry, time.Second, r.stop(ComponentSentry, nil))
+	s.Register(PhaseDatabase, ComponentDatabase, time.Second, r.stop(ComponentDatabase, nil))
+	s.Register(PhaseConsumers, ComponentMessaging, time.Second, r.stop(ComponentMessaging, nil))
+	s.Register(PhaseFlush, ComponentAPIKeyUsage, time.Second, r.stop(ComponentAPIKeyUsage, nil))
+	s.Register(PhaseSchedulers, ComponentScheduler, time.Second, r.stop(ComponentScheduler, nil))
+	s.Register(PhaseIngress, ComponentHTTP, time.Second, r.stop(ComponentHTTP, nil))
+	s.Register(PhaseWorkers, ComponentWorker, time.Second, r.stop(ComponentWorker, nil))
+	s.Register(PhaseReadiness, ComponentReadiness, 0, r.stop(ComponentReadiness, nil))
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, []string{
+		ComponentReadiness,
+		ComponentHTTP,
+		ComponentScheduler,
+		ComponentMessaging,
+		ComponentWorker,
+		ComponentAPIKeyUsage,
+		ComponentDatabase,
+		ComponentSentry,
+	}, r.stopped)
+}
+
+func TestSequence_StopsPhaseConcurrently(t *testing.T) {
+	observeLogs(t)
+	s := NewSequence(NewWatchdog(time.Minute, nil))
+
+	// Each component waits for the other, so the phase only ends when both run at once
+	var started sync.WaitGroup
+	started.Add(2)
+	wait := func(ctx context.Context) error {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.Register(PhaseIngress, ComponentHTTP, time.Second, wait)
+	s.Register(PhaseIngress, ComponentRealtime, time.Second, wait)
+
+	require.NoError(t, s.Run(context.Background()))
+}
+
+func TestSequence_ContinuesAfterFailure(t *testing.T) {
+	observeLogs(t)
+	s := NewSequence(NewWatchdog(time.Minute, nil))
+	r := &recorder{}
+	errDrain := stderrors.New("drain failed")
+
+	s.Register(PhaseIngress, ComponentHTTP, time.Second, r.stop(ComponentHTTP, errDrain))
+	s.Register(PhaseDatabase, ComponentDatabase, time.Second, r.stop(ComponentDatabase, nil))
+	s.Register(PhaseTelemetry, ComponentNewRelic, time.Second, r.stop(ComponentNewRelic, nil))
+
+	err := s.Run(context.Background())
+
+	assert.ErrorIs(t, err, errDrain)
+	assert.ErrorContains(t, err, "stop http")
+	assert.Equal(t, []string{ComponentHTTP, ComponentDatabase, ComponentNewRelic}, r.stopped)
+}
+
+func TestSequence_ZeroDeadlineRunsUntimed(t *testing.T) {
+	logs := observeLogs(t)
+	s := NewSequence(NewWatchdog(time.Minute, nil))
+
+	s.Register(PhaseReadiness, ComponentReadiness, 0, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Zero(t, logs.FilterMessage("Component stopped").Len())
+	assert.Equal(t, 1, logs.FilterMessage("Shutdown completed").Len())
+}
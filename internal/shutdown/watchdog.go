@@ -0,0 +1,203 @@
+// Package shutdown supervises the graceful shutdown of the server: each component stops
+// under its own deadline, overruns are logged and reported with goroutine dumps, and the
+// process exits on its own after a hard cap instead of being killed silently.
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/monitoring"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// Names of the components stopped by the server
+const (
+	ComponentReadiness        = "readiness"
+	ComponentHTTP             = "http"
+	ComponentInternalHTTP     = "internal_http"
+	ComponentHTTPRedirect     = "http_redirect"
+	ComponentDatabase         = "database"
+	ComponentMessaging        = "messaging"
+	ComponentRealtime         = "realtime"
+	ComponentScheduler        = "scheduler"
+	ComponentWorker           = "worker"
+	ComponentAPIKeyUsage      = "api_key_usage"
+	ComponentPerformance      = "performance_metrics"
+	ComponentDeprecationUsage = "deprecation_usage"
+	ComponentSentry           = "sentry"
+	ComponentNewRelic         = "new_relic"
+)
+
+// maxStackDump caps the goroutine dump attached to Sentry events
+const maxStackDump = 1 << 20
+
+// stopping is a component whose stop function has not returned yet
+type stopping struct {
+	startedAt time.Time
+	deadline  time.Duration
+}
+
+// Watchdog times the stop functions of the components. The hard cap starts with the first
+// stopped component; when it expires the watchdog reports the components still stopping
+// and exits the process.
+type Watchdog struct {
+	hardTimeout time.Duration
+	nrApp       *newrelic.Application
+	exit        func(code int)
+
+	armOnce   sync.Once
+	mu        sync.Mutex
+	startedAt time.Time
+	stopping  map[string]stopping
+}
+
+// NewWatchdog creates a watchdog exiting the process after hardTimeout since the start
+// of the shutdown
+func NewWatchdog(hardTimeout time.Duration, nrApp *newrelic.Application) *Watchdog {
+	return &Watchdog{
+		hardTimeout: hardTimeout,
+		nrApp:       nrApp,
+		exit:        os.Exit,
+		stopping:    make(map[string]stopping),
+	}
+}
+
+// ProvideWatchdog creates the shutdown watchdog of the server
+func ProvideWatchdog(cfg *config.Config, nrApp *newrelic.Application) *Watchdog {
+	return NewWatchdog(cfg.ShutdownHardTimeout, nrApp)
+}
+
+// Stop runs the stop function of a component with a context expiring after deadline. A
+// component still running at its deadline is reported, and Stop keeps waiting for it
+// until the hard cap. The stop duration is recorded as the
+// Custom/Shutdown/<name>/Duration metric.
+func (w *Watchdog) Stop(ctx context.Context, name string, deadline time.Duration, stop func(ctx context.Context) error) error {
+	w.arm()
+
+	startedAt := time.Now()
+	w.mu.Lock()
+	w.stopping[name] = stopping{startedAt: startedAt, deadline: deadline}
+	w.mu.Unlock()
+
+	overrun := time.AfterFunc(deadline, func() {
+		w.reportOverrun(name, deadline, time.Since(startedAt))
+	})
+
+	stopCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+	err := stop(stopCtx)
+
+	overrun.Stop()
+	elapsed := time.Since(startedAt)
+	w.mu.Lock()
+	delete(w.stopping, name)
+	w.mu.Unlock()
+
+	w.nrApp.RecordCustomMetric("Custom/Shutdown/"+name+"/Duration", elapsed.Seconds())
+	if err != nil {
+		logger.Log.Error("Component stop failed",
+			zap.String("component", name),
+			zap.Duration("elapsed", elapsed),
+			zap.Error(err),
+		)
+		return fmt.Errorf("stop %s: %w", name, err)
+	}
+
+	logger.Log.Info("Component stopped",
+		zap.String("component", name),
+		zap.Duration("elapsed", elapsed),
+	)
+	return nil
+}
+
+// arm starts the hard cap on the first call
+func (w *Watchdog) arm() {
+	w.armOnce.Do(func() {
+		w.mu.Lock()
+		w.startedAt = time.Now()
+		w.mu.Unlock()
+		time.AfterFunc(w.hardTimeout, w.forceExit)
+	})
+}
+
+// reportOverrun reports a component still stopping after its deadline
+func (w *Watchdog) reportOverrun(name string, deadline, elapsed time.Duration) {
+	logger.Log.Warn("Component exceeded its stop deadline",
+		zap.String("component", name),
+		zap.Duration("deadline", deadline),
+		zap.Duration("elapsed", elapsed),
+	)
+	captureEvent(sentry.LevelWarning, fmt.Sprintf("Shutdown of %s exceeded its %s deadline", name, deadline), map[string]any{
+		"component": name,
+		"deadline":  deadline.String(),
+		"elapsed":   elapsed.String(),
+	})
+}
+
+// forceExit reports the components still stopping at the hard cap and exits the process
+func (w *Watchdog) forceExit() {
+	w.mu.Lock()
+	elapsed := time.Since(w.startedAt)
+	pending := make([]string, 0, len(w.stopping))
+	extra := map[string]any{"elapsed": elapsed.String()}
+	for name, s := range w.stopping {
+		pending = append(pending, name)
+		extra["component."+name] = fmt.Sprintf("running for %s, deadline %s", time.Since(s.startedAt), s.deadline)
+	}
+	w.mu.Unlock()
+	sort.Strings(pending)
+
+	logger.Log.Error("Shutdown exceeded its hard timeout, forcing exit",
+		zap.Duration("hard_timeout", w.hardTimeout),
+		zap.Duration("elapsed", elapsed),
+		zap.Strings("pending_components", pending),
+	)
+	extra["pending_components"] = pending
+	captureEvent(sentry.LevelFatal, fmt.Sprintf("Shutdown exceeded its %s hard timeout", w.hardTimeout), extra)
+	monitoring.FlushSentry()
+	w.nrApp.Shutdown(time.Second)
+	_ = logger.Log.Sync()
+
+	w.exit(1)
+}
+
+// captureEvent sends a Sentry event with the stacks of all goroutines attached
+func captureEvent(level sentry.Level, message string, extra map[string]any) {
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetTag("shutdown", "true")
+		scope.AddAttachment(&sentry.Attachment{
+			Filename:    "goroutines.txt",
+			ContentType: "text/plain",
+			Payload:     stackDump(),
+		})
+	})
+	hub.CaptureEvent(&sentry.Event{
+		Level:   level,
+		Message: message,
+		Extra:   extra,
+	})
+}
+
+// stackDump returns the stacks of all goroutines, truncated to maxStackDump bytes
+func stackDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackDump {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,114 @@
+package shutdown
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// observeLogs routes the global logger to an observer for the duration of the test
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.InfoLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core)
+	t.Cleanup(func() { logger.Log = previous })
+	return logs
+}
+
+func TestWatchdog_Stop(t *testing.T) {
+	logs := observeLogs(t)
+	w := NewWatchdog(time.Minute, nil)
+
+	err := w.Stop(context.Background(), ComponentDatabase, time.Second, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, w.stopping)
+	assert.Equal(t, 1, logs.FilterMessage("Component stopped").Len())
+}
+
+func TestWatchdog_StopError(t *testing.T) {
+	observeLogs(t)
+	w := NewWatchdog(time.Minute, nil)
+	errClose := stderrors.New("close failed")
+
+	err := w.Stop(context.Background(), ComponentDatabase, time.Second, func(ctx context.Context) error {
+		return errClose
+	})
+
+	assert.ErrorIs(t, err, errClose)
+	assert.ErrorContains(t, err, "stop database")
+}
+
+func TestWatchdog_ReportsDeadlineOverrun(t *testing.T) {
+	logs := observeLogs(t)
+	w := NewWatchdog(time.Minute, nil)
+
+	err := w.Stop(context.Background(), ComponentHTTP, 20*time.Millisecond, func(ctx context.Context) error {
+		// Ignores its context past the deadline, like a hung component
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+
+	require.NoError(t, err)
+	overruns := logs.FilterMessage("Component exceeded its stop deadline").All()
+	require.Len(t, overruns, 1)
+	assert.Equal(t, ComponentHTTP, overruns[0].ContextMap()["component"])
+}
+
+func TestWatchdog_ForcesExitAfterHardTimeout(t *testing.T) {
+	logs := observeLogs(t)
+	w := NewWatchdog(30*time.Millisecond, nil)
+	exited := make(chan int, 1)
+	w.exit = func(code int) { exited <- code }
+
+	release, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = w.Stop(context.Background(), "jobs", time.Hour, func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}()
+	defer func() {
+		close(release)
+		<-stopped
+	}()
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 1, code)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not exit after its hard timeout")
+	}
+
+	entries := logs.FilterMessage("Shutdown exceeded its hard timeout, forcing exit").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, []any{"jobs"}, entries[0].ContextMap()["pending_components"])
+}
+
+func TestStackDump(t *testing.T) {
+	dump := string(stackDump())
+
+	assert.Contains(t, dump, "goroutine ")
+	assert.Contains(t, dump, "TestStackDump")
+}
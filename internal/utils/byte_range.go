@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable is returned for a range starting after the end of the content
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ByteRange is a range of the bytes of a content, from Start to End included
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes of the range
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ContentRange returns the Content-Range header of the range of a content of size bytes
+func (r ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
+
+// ParseByteRange returns the range of a content of size bytes requested by a Range
+// header, e.g. bytes=0-499, bytes=500- or bytes=-500, with its end capped to the end of
+// the content. It is nil when the whole content is to be served: without a header, with a
+// malformed one, or one of several ranges, which servers may ignore. A range starting
+// after the end of the content is ErrRangeNotSatisfiable.
+func ParseByteRange(header string, size int64) (*ByteRange, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	// A suffix range requests the last bytes of the content
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, ErrRangeNotSatisfiable
+		}
+		return &ByteRange{Start: max(size-suffix, 0), End: size - 1}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return nil, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	return &ByteRange{Start: start, End: end}, nil
+}
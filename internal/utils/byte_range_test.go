@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		size          int64
+		expected      *ByteRange
+		expectedError error
+	}{
+		{name: "no header", header: "", size: 1000},
+		{name: "first bytes", header: "bytes=0-499", size: 1000, expected: &ByteRange{Start: 0, End: 499}},
+		{name: "open range", header: "bytes=500-", size: 1000, expected: &ByteRange{Start: 500, End: 999}},
+		{name: "suffix range", header: "bytes=-200", size: 1000, expected: &ByteRange{Start: 800, End: 999}},
+		{name: "suffix longer than the content", header: "bytes=-5000", size: 1000, expected: &ByteRange{Start: 0, End: 999}},
+		{name: "end capped", header: "bytes=900-5000", size: 1000, expected: &ByteRange{Start: 900, End: 999}},
+		{name: "single byte", header: "bytes=10-10", size: 1000, expected: &ByteRange{Start: 10, End: 10}},
+		{name: "several ranges ignored", header: "bytes=0-10,20-30", size: 1000},
+		{name: "other unit ignored", header: "items=0-10", size: 1000},
+		{name: "malformed ignored", header: "bytes=abc-", size: 1000},
+		{name: "end before start ignored", header: "bytes=500-100", size: 1000},
+		{name: "start after the end", header: "bytes=1000-", size: 1000, expectedError: ErrRangeNotSatisfiable},
+		{name: "empty suffix", header: "bytes=-0", size: 1000, expectedError: ErrRangeNotSatisfiable},
+		{name: "empty content", header: "bytes=0-", size: 0, expectedError: ErrRangeNotSatisfiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byteRange, err := ParseByteRange(tt.header, tt.size)
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, byteRange)
+		})
+	}
+}
+
+func TestByteRange_ContentRange(t *testing.T) {
+	byteRange := ByteRange{Start: 500, End: 999}
+
+	assert.Equal(t, int64(500), byteRange.Length())
+	assert.Equal(t, "bytes 500-999/1000", byteRange.ContentRange(1000))
+}
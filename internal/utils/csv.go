@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVRecord is a single CSV data row keyed by its normalized header name.
+// Line is the 1-based line number in the source file (the header is line 1).
+type CSVRecord struct {
+	Line   int
+	Values map[string]string
+}
+
+// ReadCSV parses a CSV document with a header row into records. Header names are
+// trimmed and lower-cased, a leading UTF-8 BOM is ignored and blank rows are skipped.
+// Missing trailing columns are returned as empty values.
+func ReadCSV(r io.Reader) ([]CSVRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv header row is missing")
+		}
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]struct{}, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, fmt.Errorf("csv header column %d is empty", i+1)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("csv header column %q is duplicated", name)
+		}
+		seen[name] = struct{}{}
+		columns[i] = name
+	}
+
+	var records []CSVRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isBlankCSVRow(row) {
+			continue
+		}
+		if len(row) > len(columns) {
+			return nil, fmt.Errorf("csv line %d has %d columns, expected at most %d", line, len(row), len(columns))
+		}
+
+		values := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if i < len(row) {
+				values[column] = strings.TrimSpace(row[i])
+			} else {
+				values[column] = ""
+			}
+		}
+		records = append(records, CSVRecord{Line: line, Values: values})
+	}
+
+	return records, nil
+}
+
+func isBlankCSVRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
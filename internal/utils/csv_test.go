@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectError   bool
+		errorContains string
+		expected      []CSVRecord
+	}{
+		{
+			name:  "normalizes header and trims values",
+			input: "\ufeff Email ,First_Name\n john@example.com , John \n",
+			expected: []CSVRecord{
+				{Line: 2, Values: map[string]string{"email": "john@example.com", "first_name": "John"}},
+			},
+		},
+		{
+			name:  "skips blank rows and pads missing columns",
+			input: "email,first_name\n,\njane@example.com\n",
+			expected: []CSVRecord{
+				{Line: 3, Values: map[string]string{"email": "jane@example.com", "first_name": ""}},
+			},
+		},
+		{
+			name:     "header only",
+			input:    "email,first_name\n",
+			expected: nil,
+		},
+		{
+			name:          "empty document",
+			input:         "",
+			expectError:   true,
+			errorContains: "header row is missing",
+		},
+		{
+			name:          "duplicated header column",
+			input:         "email,Email\na@example.com,b@example.com\n",
+			expectError:   true,
+			errorContains: "duplicated",
+		},
+		{
+			name:          "too many columns",
+			input:         "email\na@example.com,extra\n",
+			expectError:   true,
+			errorContains: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadCSV(strings.NewReader(tt.input))
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, records)
+		})
+	}
+}
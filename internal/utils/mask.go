@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"golang-boilerplate/internal/constants"
+)
+
+// MaskSecret hides a secret value for display. Values of at least
+// constants.VaultMaskMinLength characters keep their last constants.VaultMaskVisibleChars
+// characters, shorter ones are fully masked so that little of them is revealed.
+func MaskSecret(value string) string {
+	runes := []rune(value)
+	if len(runes) < constants.VaultMaskMinLength {
+		return constants.VaultMask
+	}
+	return constants.VaultMask + string(runes[len(runes)-constants.VaultMaskVisibleChars:])
+}
+
+// MaskSecrets masks every value of a secret map
+func MaskSecrets(secret map[string]string) map[string]string {
+	masked := make(map[string]string, len(secret))
+	for key, value := range secret {
+		masked[key] = MaskSecret(value)
+	}
+	return masked
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MergePatchContentType is the media type of an RFC 7386 JSON merge patch
+const MergePatchContentType = "application/merge-patch+json"
+
+// ApplyMergePatch applies an RFC 7386 JSON merge patch to the original JSON document.
+// Members set to null in the patch are removed, objects are merged recursively and any
+// other value replaces the original one.
+func ApplyMergePatch(original, patch []byte) ([]byte, error) {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	var originalValue interface{}
+	if len(bytes.TrimSpace(original)) > 0 {
+		if err := json.Unmarshal(original, &originalValue); err != nil {
+			return nil, fmt.Errorf("invalid merge patch target: %w", err)
+		}
+	}
+
+	return json.Marshal(mergePatchValue(originalValue, patchValue))
+}
+
+// MergePatch applies an RFC 7386 JSON merge patch to target, which must be a pointer to
+// a struct. Members of the patch that do not map to a field of target are rejected.
+func MergePatch(target interface{}, patch []byte) error {
+	original, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("invalid merge patch target: %w", err)
+	}
+
+	patched, err := ApplyMergePatch(original, patch)
+	if err != nil {
+		return err
+	}
+
+	var patchedObject map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &patchedObject); err != nil || patchedObject == nil {
+		return fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	// Reset target so that removed members end up as zero values
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("merge patch target must be a non-nil pointer")
+	}
+	value.Elem().Set(reflect.Zero(value.Elem().Type()))
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	return nil
+}
+
+// mergePatchValue implements the MergePatch algorithm of RFC 7386 section 2
+func mergePatchValue(original, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	originalObject, ok := original.(map[string]interface{})
+	if !ok {
+		originalObject = make(map[string]interface{})
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(originalObject, name)
+			continue
+		}
+		originalObject[name] = mergePatchValue(originalObject[name], value)
+	}
+
+	return originalObject
+}
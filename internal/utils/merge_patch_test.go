@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// Examples from RFC 7386 appendix A
+	tests := []struct {
+		name     string
+		original string
+		patch    string
+		expected string
+	}{
+		{name: "replace member", original: `{"a":"b"}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{name: "add member", original: `{"a":"b"}`, patch: `{"b":"c"}`, expected: `{"a":"b","b":"c"}`},
+		{name: "remove member", original: `{"a":"b"}`, patch: `{"a":null}`, expected: `{}`},
+		{name: "remove one of many", original: `{"a":"b","b":"c"}`, patch: `{"a":null}`, expected: `{"b":"c"}`},
+		{name: "replace array", original: `{"a":["b"]}`, patch: `{"a":"c"}`, expected: `{"a":"c"}`},
+		{name: "nested merge", original: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, expected: `{"a":{"b":"d"}}`},
+		{name: "non-object patch replaces", original: `{"a":"c"}`, patch: `["c"]`, expected: `["c"]`},
+		{name: "null patch", original: `{"a":"foo"}`, patch: `null`, expected: `null`},
+		{name: "object into scalar", original: `"foo"`, patch: `{"a":null}`, expected: `{}`},
+		{name: "nested null is dropped", original: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, expected: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyMergePatch([]byte(tt.original), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	type document struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+	}
+
+	tests := []struct {
+		name          string
+		patch         string
+		expected      document
+		errorContains string
+	}{
+		{
+			name:     "null clears a field",
+			patch:    `{"name":null}`,
+			expected: document{Email: "john@example.com"},
+		},
+		{
+			name:     "absent members are kept",
+			patch:    `{"name":"Jane"}`,
+			expected: document{Name: "Jane", Email: "john@example.com"},
+		},
+		{
+			name:          "unknown member is rejected",
+			patch:         `{"nickname":"JJ"}`,
+			errorContains: "nickname",
+		},
+		{
+			name:          "non-object patch is rejected",
+			patch:         `"John"`,
+			errorContains: "JSON object",
+		},
+		{
+			name:          "malformed patch",
+			patch:         `{"name":`,
+			errorContains: "invalid merge patch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := document{Name: "John", Email: "john@example.com"}
+
+			err := MergePatch(&target, []byte(tt.patch))
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, target)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types of the streamed listings
+const (
+	CSVContentType    = "text/csv"
+	NDJSONContentType = "application/x-ndjson"
+)
+
+// NegotiateContentType returns the offer preferred by the Accept header, by quality then
+// by order of the header, or the first offer when the header accepts none of them.
+// Wildcards match every offer and never take precedence over an explicit type.
+func NegotiateContentType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	type acceptedType struct {
+		mediaType string
+		quality   float64
+	}
+	var accepted []acceptedType
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		if accepted[i].quality != accepted[j].quality {
+			return accepted[i].quality > accepted[j].quality
+		}
+		// An explicit type wins over a wildcard of the same quality
+		return !strings.Contains(accepted[i].mediaType, "*") && strings.Contains(accepted[j].mediaType, "*")
+	})
+
+	for _, acceptedType := range accepted {
+		for _, offer := range offers {
+			if matchesMediaType(acceptedType.mediaType, offer) {
+				return offer
+			}
+		}
+	}
+	return offers[0]
+}
+
+// matchesMediaType reports whether an accepted media type, possibly a wildcard such as
+// text/* or */*, matches the offer
+func matchesMediaType(accepted string, offer string) bool {
+	if accepted == "*/*" || accepted == offer {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(accepted, "/*")
+	return ok && strings.HasPrefix(offer, prefix+"/")
+}
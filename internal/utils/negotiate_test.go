@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", CSVContentType, NDJSONContentType}
+
+	tests := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "no header", accept: "", expected: "application/json"},
+		{name: "explicit type", accept: "text/csv", expected: CSVContentType},
+		{name: "parameters ignored", accept: "application/x-ndjson; charset=utf-8", expected: NDJSONContentType},
+		{name: "highest quality", accept: "text/csv;q=0.5, application/x-ndjson;q=0.9", expected: NDJSONContentType},
+		{name: "header order for equal quality", accept: "application/x-ndjson, text/csv", expected: NDJSONContentType},
+		{name: "explicit type before wildcard", accept: "*/*, text/csv", expected: CSVContentType},
+		{name: "subtype wildcard", accept: "text/*", expected: CSVContentType},
+		{name: "any type", accept: "*/*", expected: "application/json"},
+		{name: "refused type", accept: "text/csv;q=0, */*;q=0.1", expected: "application/json"},
+		{name: "unknown type", accept: "application/xml", expected: "application/json"},
+		{name: "malformed parts skipped", accept: "text/csv;q=high, ;;, application/x-ndjson", expected: NDJSONContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NegotiateContentType(tt.accept, offers...))
+		})
+	}
+}
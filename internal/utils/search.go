@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PrefixTSQuery turns free text into a Postgres tsquery that matches every word of the
+// text as a prefix, e.g. "Jo do" becomes "jo:* & do:*". Anything but letters and digits
+// separates words, so the result is safe to pass to to_tsquery. Only the first maxTerms
+// distinct words are kept when maxTerms is positive. An empty result means the text has
+// no searchable words.
+func PrefixTSQuery(text string, maxTerms int) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if seen[word] {
+			continue
+		}
+		if maxTerms > 0 && len(terms) == maxTerms {
+			break
+		}
+		seen[word] = true
+		terms = append(terms, word+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxTerms int
+		expected string
+	}{
+		{name: "single word", text: "john", expected: "john:*"},
+		{name: "lowercases and joins words", text: "John DOE", expected: "john:* & doe:*"},
+		{name: "splits email", text: "john.doe@example.com", expected: "john:* & doe:* & example:* & com:*"},
+		{name: "strips tsquery operators", text: "a & !b | (c:*) <-> 'd'", expected: "a:* & b:* & c:* & d:*"},
+		{name: "keeps accented letters", text: "Nguyễn Văn", expected: "nguyễn:* & văn:*"},
+		{name: "removes duplicates", text: "jo jo JO", expected: "jo:*"},
+		{name: "limits terms", text: "a b c d", maxTerms: 2, expected: "a:* & b:*"},
+		{name: "no words", text: " !?-- ", expected: ""},
+		{name: "empty", text: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PrefixTSQuery(tt.text, tt.maxTerms))
+		})
+	}
+}
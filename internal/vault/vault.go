@@ -0,0 +1,80 @@
+// Package vault seals secrets with envelope encryption: every secret is encrypted with
+// AES-256-GCM under its own data key, and only the data key wrapped by the KMS is kept.
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"golang-boilerplate/internal/integration/kms"
+)
+
+// Sealed is an encrypted secret together with the wrapped data key needed to open it
+type Sealed struct {
+	KeyID        string
+	EncryptedKey []byte
+	Nonce        []byte
+	Ciphertext   []byte
+}
+
+// Seal encrypts plaintext under a new data key. The additional data is authenticated but
+// not encrypted; the same value must be given to Open, which binds the secret to its owner.
+func Seal(ctx context.Context, keys kms.KeyManager, plaintext, additionalData []byte) (*Sealed, error) {
+	dataKey, err := keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey.Plaintext)
+
+	aead, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return &Sealed{
+		KeyID:        dataKey.KeyID,
+		EncryptedKey: dataKey.Encrypted,
+		Nonce:        nonce,
+		Ciphertext:   aead.Seal(nil, nonce, plaintext, additionalData),
+	}, nil
+}
+
+// Open decrypts a sealed secret, failing when it or its additional data was tampered with
+func Open(ctx context.Context, keys kms.KeyManager, sealed *Sealed, additionalData []byte) ([]byte, error) {
+	key, err := keys.Decrypt(ctx, sealed.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(sealed.Nonce))
+	}
+
+	plaintext, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("open sealed secret: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
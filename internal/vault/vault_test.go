@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"golang-boilerplate/internal/integration/kms"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyManager(t *testing.T) *kms.LocalKeyManager {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	keys, err := kms.NewLocalKeyManager(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+	return keys
+}
+
+func TestSealOpen(t *testing.T) {
+	keys := newTestKeyManager(t)
+	ctx := context.Background()
+	aad := []byte("tenant_credential:company-1:credential-1")
+
+	sealed, err := Seal(ctx, keys, []byte(`{"password":"s3cret"}`), aad)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed.Ciphertext), "s3cret")
+	assert.NotEmpty(t, sealed.EncryptedKey)
+
+	plaintext, err := Open(ctx, keys, sealed, aad)
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"s3cret"}`, string(plaintext))
+}
+
+func TestSeal_UsesNewDataKeyEachTime(t *testing.T) {
+	keys := newTestKeyManager(t)
+	ctx := context.Background()
+
+	first, err := Seal(ctx, keys, []byte("secret"), nil)
+	require.NoError(t, err)
+	second, err := Seal(ctx, keys, []byte("secret"), nil)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.EncryptedKey, second.EncryptedKey)
+	assert.NotEqual(t, first.Ciphertext, second.Ciphertext)
+}
+
+func TestOpen_Failures(t *testing.T) {
+	keys := newTestKeyManager(t)
+	ctx := context.Background()
+	aad := []byte("tenant_credential:company-1:credential-1")
+
+	tests := []struct {
+		name   string
+		tamper func(sealed *Sealed) ([]byte, *kms.LocalKeyManager)
+	}{
+		{
+			name: "other additional data",
+			tamper: func(sealed *Sealed) ([]byte, *kms.LocalKeyManager) {
+				return []byte("tenant_credential:company-2:credential-1"), keys
+			},
+		},
+		{
+			name: "tampered ciphertext",
+			tamper: func(sealed *Sealed) ([]byte, *kms.LocalKeyManager) {
+				sealed.Ciphertext[0] ^= 0xff
+				return aad, keys
+			},
+		},
+		{
+			name: "tampered data key",
+			tamper: func(sealed *Sealed) ([]byte, *kms.LocalKeyManager) {
+				sealed.EncryptedKey[len(sealed.EncryptedKey)-1] ^= 0xff
+				return aad, keys
+			},
+		},
+		{
+			name: "invalid nonce",
+			tamper: func(sealed *Sealed) ([]byte, *kms.LocalKeyManager) {
+				sealed.Nonce = sealed.Nonce[:4]
+				return aad, keys
+			},
+		},
+		{
+			name: "other master key",
+			tamper: func(sealed *Sealed) ([]byte, *kms.LocalKeyManager) {
+				return aad, newTestKeyManager(t)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := Seal(ctx, keys, []byte("secret"), aad)
+			require.NoError(t, err)
+
+			openAAD, openKeys := tt.tamper(sealed)
+			plaintext, err := Open(ctx, openKeys, sealed, openAAD)
+
+			assert.Error(t, err)
+			assert.Nil(t, plaintext)
+		})
+	}
+}
+
+func TestNewLocalKeyManager_InvalidKey(t *testing.T) {
+	_, err := kms.NewLocalKeyManager("not base64!")
+	assert.Error(t, err)
+
+	_, err = kms.NewLocalKeyManager(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Dispatcher turns the domain events of the companies into deliveries to their endpoints
+type Dispatcher interface {
+	// Dispatch records a delivery of the event to each active endpoint of the company
+	// whose filter matches its data, and enqueues them to be sent by the workers
+	Dispatch(ctx context.Context, companyID string, eventType string, data any) error
+	// Redeliver sends the payload of a delivery again, as a new delivery of the same event
+	Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error)
+	// Test records a delivery of a test event to the endpoint, whatever its filter, and
+	// enqueues it to be sent by the workers
+	Test(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookDelivery, error)
+}
+
+// dispatcher implements Dispatcher on the task queue
+type dispatcher struct {
+	repo     repositories.WebhookRepository
+	enqueuer jobs.Enqueuer
+}
+
+// ProvideDispatcher creates a new webhook dispatcher
+func ProvideDispatcher(repo repositories.WebhookRepository, enqueuer jobs.Enqueuer) Dispatcher {
+	return &dispatcher{
+		repo:     repo,
+		enqueuer: enqueuer,
+	}
+}
+
+func (d *dispatcher) Dispatch(ctx context.Context, companyID string, eventType string, data any) error {
+	endpoints, err := d.repo.GetActiveEndpoints(companyID)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	event, payload, err := newEvent(companyID, eventType, data)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, endpoint := range endpoints {
+		filter, err := EndpointFilter(&endpoint)
+		if err != nil {
+			logger.Log.Warn("Skipping webhook endpoint with an invalid filter",
+				zap.String("webhook_id", endpoint.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !filter.Matches(eventType, event.Data) {
+			continue
+		}
+
+		deliveries = append(deliveries, models.WebhookDelivery{
+			BaseModel:  models.NewBaseModel(),
+			CompanyID:  companyID,
+			EndpointID: endpoint.ID,
+			EventID:    event.ID,
+			EventType:  eventType,
+			Payload:    payload,
+			Status:     constants.WebhookDeliveryPending,
+		})
+	}
+	if err := d.repo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.enqueue(ctx, &delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *dispatcher) Redeliver(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	redelivery := models.WebhookDelivery{
+		BaseModel:    models.NewBaseModel(),
+		CompanyID:    delivery.CompanyID,
+		EndpointID:   delivery.EndpointID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Payload:      delivery.Payload,
+		Status:       constants.WebhookDeliveryPending,
+		RedeliveryOf: &delivery.ID,
+	}
+	if err := d.repo.CreateDeliveries([]models.WebhookDelivery{redelivery}); err != nil {
+		return nil, err
+	}
+
+	if err := d.enqueue(ctx, &redelivery); err != nil {
+		return nil, err
+	}
+
+	return &redelivery, nil
+}
+
+func (d *dispatcher) Test(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookDelivery, error) {
+	event, payload, err := newEvent(endpoint.CompanyID, constants.WebhookEventTest, map[string]any{
+		"webhook_id": endpoint.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		BaseModel:  models.NewBaseModel(),
+		CompanyID:  endpoint.CompanyID,
+		EndpointID: endpoint.ID,
+		EventID:    event.ID,
+		EventType:  event.Type,
+		Payload:    payload,
+		Status:     constants.WebhookDeliveryPending,
+	}
+	if err := d.repo.CreateDeliveries([]models.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+
+	if err := d.enqueue(ctx, &delivery); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (d *dispatcher) enqueue(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := d.enqueuer.Enqueue(ctx, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID})
+	return err
+}
+
+// newEvent returns a new event of the company with its JSON body
+func newEvent(companyID string, eventType string, data any) (*dtos.WebhookEvent, json.RawMessage, error) {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, errors.InternalError("Failed to encode webhook event", err).
+			WithOperation("dispatch_webhook").
+			WithResource("webhook_delivery").
+			WithContext("event_type", eventType)
+	}
+	event := &dtos.WebhookEvent{
+		ID:        uuid.Must(uuid.NewV7()).String(),
+		Type:      eventType,
+		CompanyID: companyID,
+		CreatedAt: time.Now().UTC(),
+		Data:      rawData,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, errors.InternalError("Failed to encode webhook event", err).
+			WithOperation("dispatch_webhook").
+			WithResource("webhook_delivery").
+			WithContext("event_type", eventType)
+	}
+
+	return event, payload, nil
+}
+
+// EndpointFilter returns the filter of the event types and conditions of an endpoint,
+// compiled
+func EndpointFilter(endpoint *models.WebhookEndpoint) (*CompiledFilter, error) {
+	filter := Filter{EventTypes: endpoint.EventTypes}
+	if len(endpoint.Conditions) > 0 {
+		if err := json.Unmarshal(endpoint.Conditions, &filter.Conditions); err != nil {
+			return nil, fmt.Errorf("decode conditions: %w", err)
+		}
+	}
+
+	return filter.Compile()
+}
@@ -0,0 +1,244 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/dtos"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// MockWebhookRepository is a mock implementation of repositories.WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetEndpoint(companyID string, id string) (*models.WebhookEndpoint, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetEndpoints(companyID string, pr *dtos.PageableRequest) (*dtos.DataResponse[models.WebhookEndpoint], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.WebhookEndpoint]), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CountEndpoints(companyID string) (int64, error) {
+	args := m.Called(companyID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetActiveEndpoints(companyID string) ([]models.WebhookEndpoint, error) {
+	args := m.Called(companyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetEndpointsByURL(companyID string, urls []string) ([]models.WebhookEndpoint, error) {
+	args := m.Called(companyID, urls)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(endpoint *models.WebhookEndpoint) error {
+	args := m.Called(endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDelivery(companyID string, id string) (*models.WebhookDelivery, error) {
+	args := m.Called(companyID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveryWithEndpoint(id string) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(companyID string, pr *dtos.WebhookDeliveryPageableRequest) (*dtos.DataResponse[models.WebhookDelivery], error) {
+	args := m.Called(companyID, pr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DataResponse[models.WebhookDelivery]), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SaveAttempt(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+// MockEnqueuer is a mock implementation of jobs.Enqueuer. Its calls record whether the
+// job runs immediately, i.e. without WithDelay.
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+func (m *MockEnqueuer) Enqueue(ctx context.Context, args jobs.Args, opts ...jobs.EnqueueOption) (*models.Job, error) {
+	job := &models.Job{}
+	for _, opt := range opts {
+		opt(job)
+	}
+	called := m.Called(ctx, args, job.RunAt.IsZero())
+	return job, called.Error(0)
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	enqueuer := new(MockEnqueuer)
+	dispatcher := ProvideDispatcher(repo, enqueuer)
+
+	all := models.WebhookEndpoint{BaseModel: models.NewBaseModel(), EventTypes: []string{"*"}}
+	users := models.WebhookEndpoint{BaseModel: models.NewBaseModel(), EventTypes: []string{"user.*"}}
+	companies := models.WebhookEndpoint{BaseModel: models.NewBaseModel(), EventTypes: []string{"company.*"}}
+	vietnam := models.WebhookEndpoint{
+		BaseModel:  models.NewBaseModel(),
+		EventTypes: []string{"user.updated"},
+		Conditions: json.RawMessage(`[{"path":"$.profile.country","operator":"eq","value":"VN"}]`),
+	}
+	invalid := models.WebhookEndpoint{
+		BaseModel:  models.NewBaseModel(),
+		EventTypes: []string{"user.updated"},
+		Conditions: json.RawMessage(`[{"path":"profile","operator":"exists"}]`),
+	}
+	repo.On("GetActiveEndpoints", "company-1").Return([]models.WebhookEndpoint{all, users, companies, vietnam, invalid}, nil)
+
+	var deliveries []models.WebhookDelivery
+	repo.On("CreateDeliveries", mock.Anything).Run(func(args mock.Arguments) {
+		deliveries = args.Get(0).([]models.WebhookDelivery)
+	}).Return(nil)
+	enqueuer.On("Enqueue", mock.Anything, mock.AnythingOfType("jobs.DeliverWebhookArgs"), true).Return(nil)
+
+	err := dispatcher.Dispatch(context.Background(), "company-1", constants.WebhookEventUserUpdated, map[string]any{
+		"id":      "user-1",
+		"profile": map[string]any{"country": "VN"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, []string{all.ID, users.ID, vietnam.ID}, []string{deliveries[0].EndpointID, deliveries[1].EndpointID, deliveries[2].EndpointID})
+	for _, delivery := range deliveries {
+		assert.Equal(t, constants.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, deliveries[0].EventID, delivery.EventID, "the deliveries of an event share its ID")
+		enqueuer.AssertCalled(t, "Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, true)
+	}
+
+	var event dtos.WebhookEvent
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+	assert.Equal(t, deliveries[0].EventID, event.ID)
+	assert.Equal(t, constants.WebhookEventUserUpdated, event.Type)
+	assert.Equal(t, "company-1", event.CompanyID)
+	assert.JSONEq(t, `{"id":"user-1","profile":{"country":"VN"}}`, string(event.Data))
+}
+
+func TestDispatcher_Dispatch_NoEndpoint(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	dispatcher := ProvideDispatcher(repo, new(MockEnqueuer))
+	repo.On("GetActiveEndpoints", "company-1").Return([]models.WebhookEndpoint{}, nil)
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), "company-1", constants.WebhookEventUserCreated, map[string]any{}))
+	repo.AssertNotCalled(t, "CreateDeliveries", mock.Anything)
+}
+
+func TestDispatcher_Redeliver(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	enqueuer := new(MockEnqueuer)
+	dispatcher := ProvideDispatcher(repo, enqueuer)
+
+	responseStatus := 500
+	delivery := &models.WebhookDelivery{
+		BaseModel:      models.NewBaseModel(),
+		CompanyID:      "company-1",
+		EndpointID:     "endpoint-1",
+		EventID:        "event-1",
+		EventType:      constants.WebhookEventUserCreated,
+		Payload:        json.RawMessage(`{"id":"event-1"}`),
+		Status:         constants.WebhookDeliveryFailed,
+		Attempts:       8,
+		ResponseStatus: &responseStatus,
+	}
+	repo.On("CreateDeliveries", mock.Anything).Return(nil)
+	enqueuer.On("Enqueue", mock.Anything, mock.AnythingOfType("jobs.DeliverWebhookArgs"), true).Return(nil)
+
+	redelivery, err := dispatcher.Redeliver(context.Background(), delivery)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, delivery.ID, redelivery.ID)
+	assert.Equal(t, delivery.EventID, redelivery.EventID)
+	assert.Equal(t, delivery.Payload, redelivery.Payload)
+	assert.Equal(t, constants.WebhookDeliveryPending, redelivery.Status)
+	assert.Zero(t, redelivery.Attempts)
+	assert.Nil(t, redelivery.ResponseStatus)
+	require.NotNil(t, redelivery.RedeliveryOf)
+	assert.Equal(t, delivery.ID, *redelivery.RedeliveryOf)
+	enqueuer.AssertCalled(t, "Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: redelivery.ID}, true)
+}
+
+func TestDispatcher_Test(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	enqueuer := new(MockEnqueuer)
+	dispatcher := ProvideDispatcher(repo, enqueuer)
+
+	// The test event is delivered whatever the event types of the endpoint
+	endpoint := &models.WebhookEndpoint{BaseModel: models.NewBaseModel(), CompanyID: "company-1", EventTypes: []string{"company.*"}}
+	repo.On("CreateDeliveries", mock.Anything).Return(nil)
+	enqueuer.On("Enqueue", mock.Anything, mock.AnythingOfType("jobs.DeliverWebhookArgs"), true).Return(nil)
+
+	delivery, err := dispatcher.Test(context.Background(), endpoint)
+	require.NoError(t, err)
+
+	assert.Equal(t, endpoint.ID, delivery.EndpointID)
+	assert.Equal(t, constants.WebhookEventTest, delivery.EventType)
+	assert.Equal(t, constants.WebhookDeliveryPending, delivery.Status)
+	var event dtos.WebhookEvent
+	require.NoError(t, json.Unmarshal(delivery.Payload, &event))
+	assert.Equal(t, delivery.EventID, event.ID)
+	assert.JSONEq(t, `{"webhook_id":"`+endpoint.ID+`"}`, string(event.Data))
+	enqueuer.AssertCalled(t, "Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, true)
+}
@@ -0,0 +1,178 @@
+// Package webhooks delivers domain events to the HTTP endpoints companies register. Each
+// endpoint subscribes to event types and can narrow them down with payload conditions, so
+// that customers do not receive a firehose they have to filter themselves.
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Condition operators
+const (
+	OperatorEquals    = "eq"
+	OperatorNotEquals = "ne"
+	OperatorIn        = "in"
+	OperatorExists    = "exists"
+)
+
+// Condition tests the values a JSONPath selects in an event payload. When the path selects
+// several values, e.g. through `[*]`, eq, in and exists match if any value matches, and ne
+// if no value is equal.
+type Condition struct {
+	Path     string `json:"path"`
+	Operator string `json:"operator"`
+	Value    any    `json:"value,omitempty"`
+}
+
+// Filter selects the events delivered to an endpoint: the event type must match one of
+// EventTypes and the payload every condition. An event type ending with `.*` matches the
+// types under that prefix, e.g. `user.*` matches `user.created`, and `*` every type.
+type Filter struct {
+	EventTypes []string    `json:"event_types"`
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// CompiledFilter is a validated Filter ready to be matched against events
+type CompiledFilter struct {
+	eventTypes []string
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	path     jsonPath
+	operator string
+	values   []any
+}
+
+// Compile validates the filter. An endpoint must subscribe to at least one event type.
+func (f Filter) Compile() (*CompiledFilter, error) {
+	if len(f.EventTypes) == 0 {
+		return nil, fmt.Errorf("at least one event type is required")
+	}
+	for _, eventType := range f.EventTypes {
+		if eventType == "" || (strings.Contains(eventType, "*") && eventType != "*" && !strings.HasSuffix(eventType, ".*")) ||
+			strings.Count(eventType, "*") > 1 {
+			return nil, fmt.Errorf("invalid event type %q, use a type, a prefix ending with .* or *", eventType)
+		}
+	}
+
+	compiled := &CompiledFilter{eventTypes: f.EventTypes}
+	for i, condition := range f.Conditions {
+		c, err := condition.compile()
+		if err != nil {
+			return nil, fmt.Errorf("condition %d: %w", i, err)
+		}
+		compiled.conditions = append(compiled.conditions, c)
+	}
+
+	return compiled, nil
+}
+
+func (c Condition) compile() (compiledCondition, error) {
+	path, err := compileJSONPath(c.Path)
+	if err != nil {
+		return compiledCondition{}, err
+	}
+
+	compiled := compiledCondition{path: path, operator: c.Operator}
+	switch c.Operator {
+	case OperatorExists:
+	case OperatorEquals, OperatorNotEquals:
+		value, err := normalize(c.Value)
+		if err != nil {
+			return compiledCondition{}, err
+		}
+		compiled.values = []any{value}
+	case OperatorIn:
+		value, err := normalize(c.Value)
+		if err != nil {
+			return compiledCondition{}, err
+		}
+		values, ok := value.([]any)
+		if !ok || len(values) == 0 {
+			return compiledCondition{}, fmt.Errorf("operator in needs a non-empty array value")
+		}
+		compiled.values = values
+	default:
+		return compiledCondition{}, fmt.Errorf("unknown operator %q, expected eq, ne, in or exists", c.Operator)
+	}
+
+	return compiled, nil
+}
+
+// Matches reports whether an event of the type with the JSON payload passes the filter.
+// A payload that is not valid JSON only passes filters without conditions.
+func (f *CompiledFilter) Matches(eventType string, payload []byte) bool {
+	if !f.matchesType(eventType) {
+		return false
+	}
+	if len(f.conditions) == 0 {
+		return true
+	}
+
+	var document any
+	if err := json.Unmarshal(payload, &document); err != nil {
+		return false
+	}
+	for _, condition := range f.conditions {
+		if !condition.matches(document) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *CompiledFilter) matchesType(eventType string) bool {
+	for _, pattern := range f.eventTypes {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compiledCondition) matches(document any) bool {
+	found := c.path.find(document)
+
+	switch c.operator {
+	case OperatorExists:
+		return len(found) > 0
+	case OperatorNotEquals:
+		for _, value := range found {
+			if reflect.DeepEqual(value, c.values[0]) {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, value := range found {
+			for _, expected := range c.values {
+				if reflect.DeepEqual(value, expected) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// normalize round-trips a condition value through JSON, so that it compares equal to the
+// payload values decoded alike, e.g. an int 1 to the float64 1
+func normalize(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode condition value: %w", err)
+	}
+
+	var normalized any
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, fmt.Errorf("decode condition value: %w", err)
+	}
+	return normalized, nil
+}
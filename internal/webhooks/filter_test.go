@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userPayload = `{
+	"id": "user-1",
+	"email": "ada@example.com",
+	"age": 36,
+	"active": true,
+	"profile": {"country": "VN", "tags": ["beta"]},
+	"companies": [{"id": "company-1", "role": "admin"}, {"id": "company-2", "role": "member"}]
+}`
+
+func TestFilter_MatchesEventTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes []string
+		eventType  string
+		expected   bool
+	}{
+		{name: "exact type", eventTypes: []string{"user.created"}, eventType: "user.created", expected: true},
+		{name: "other type", eventTypes: []string{"user.created"}, eventType: "user.updated", expected: false},
+		{name: "prefix", eventTypes: []string{"user.*"}, eventType: "user.deleted", expected: true},
+		{name: "prefix of another domain", eventTypes: []string{"user.*"}, eventType: "company.created", expected: false},
+		{name: "prefix needs a dot", eventTypes: []string{"user.*"}, eventType: "users.created", expected: false},
+		{name: "any type", eventTypes: []string{"*"}, eventType: "company.created", expected: true},
+		{name: "one of several", eventTypes: []string{"company.created", "user.updated"}, eventType: "user.updated", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := Filter{EventTypes: tt.eventTypes}.Compile()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, filter.Matches(tt.eventType, []byte(userPayload)))
+		})
+	}
+}
+
+func TestFilter_MatchesConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []Condition
+		expected   bool
+	}{
+		{name: "string equals", conditions: []Condition{{Path: "$.email", Operator: OperatorEquals, Value: "ada@example.com"}}, expected: true},
+		{name: "string differs", conditions: []Condition{{Path: "$.email", Operator: OperatorEquals, Value: "bob@example.com"}}, expected: false},
+		{name: "number equals an int value", conditions: []Condition{{Path: "$.age", Operator: OperatorEquals, Value: 36}}, expected: true},
+		{name: "boolean equals", conditions: []Condition{{Path: "$.active", Operator: OperatorEquals, Value: true}}, expected: true},
+		{name: "nested field", conditions: []Condition{{Path: "$.profile.country", Operator: OperatorIn, Value: []string{"SG", "VN"}}}, expected: true},
+		{name: "bracket key and index", conditions: []Condition{{Path: "$['profile']['tags'][0]", Operator: OperatorEquals, Value: "beta"}}, expected: true},
+		{name: "any array element", conditions: []Condition{{Path: "$.companies[*].role", Operator: OperatorEquals, Value: "admin"}}, expected: true},
+		{name: "no array element", conditions: []Condition{{Path: "$.companies[*].role", Operator: OperatorEquals, Value: "owner"}}, expected: false},
+		{name: "not equals", conditions: []Condition{{Path: "$.companies[*].id", Operator: OperatorNotEquals, Value: "company-3"}}, expected: true},
+		{name: "not equals any element", conditions: []Condition{{Path: "$.companies[*].id", Operator: OperatorNotEquals, Value: "company-2"}}, expected: false},
+		{name: "exists", conditions: []Condition{{Path: "$.profile.tags", Operator: OperatorExists}}, expected: true},
+		{name: "missing field", conditions: []Condition{{Path: "$.profile.city", Operator: OperatorExists}}, expected: false},
+		{name: "index out of range", conditions: []Condition{{Path: "$.companies[5].id", Operator: OperatorExists}}, expected: false},
+		{
+			name: "all conditions must match",
+			conditions: []Condition{
+				{Path: "$.active", Operator: OperatorEquals, Value: true},
+				{Path: "$.profile.country", Operator: OperatorEquals, Value: "SG"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := Filter{EventTypes: []string{"user.*"}, Conditions: tt.conditions}.Compile()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, filter.Matches("user.updated", []byte(userPayload)))
+		})
+	}
+}
+
+func TestFilter_InvalidPayload(t *testing.T) {
+	withoutConditions, err := Filter{EventTypes: []string{"*"}}.Compile()
+	require.NoError(t, err)
+	withConditions, err := Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$.id", Operator: OperatorExists}}}.Compile()
+	require.NoError(t, err)
+
+	assert.True(t, withoutConditions.Matches("user.updated", []byte("not json")))
+	assert.False(t, withConditions.Matches("user.updated", []byte("not json")))
+}
+
+func TestFilter_CompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		errMsg string
+	}{
+		{name: "no event type", filter: Filter{}, errMsg: "at least one event type is required"},
+		{name: "wildcard in the middle", filter: Filter{EventTypes: []string{"*.created"}}, errMsg: "invalid event type"},
+		{name: "empty event type", filter: Filter{EventTypes: []string{""}}, errMsg: "invalid event type"},
+		{name: "path without root", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "email", Operator: OperatorExists}}}, errMsg: "must start with $"},
+		{name: "unclosed bracket", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$.companies[0", Operator: OperatorExists}}}, errMsg: "unclosed bracket"},
+		{name: "filter expression", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$.companies[?(@.role)]", Operator: OperatorExists}}}, errMsg: "invalid bracket"},
+		{name: "empty key", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$..email", Operator: OperatorExists}}}, errMsg: "empty key"},
+		{name: "unknown operator", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$.email", Operator: "gt"}}}, errMsg: "unknown operator"},
+		{name: "in without array", filter: Filter{EventTypes: []string{"*"}, Conditions: []Condition{{Path: "$.email", Operator: OperatorIn, Value: "a"}}}, errMsg: "non-empty array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.filter.Compile()
+
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a step of a compiled JSONPath: an object key, an array index or a wildcard
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonPath is a compiled JSONPath. The subset supported covers selecting fields of event
+// payloads: `$`, `.key`, `['key']`, `[0]`, `[*]` and `.*`; filter expressions and recursive
+// descent are not, conditions are expressed by Condition instead.
+type jsonPath []pathStep
+
+// compileJSONPath parses a JSONPath expression such as `$.data.companies[*].id`
+func compileJSONPath(expr string) (jsonPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expr)
+	}
+
+	var path jsonPath
+	rest := expr[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			path = append(path, pathStep{wildcard: true})
+			rest = rest[2:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty key", expr)
+			}
+			path = append(path, pathStep{key: key})
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed bracket", expr)
+			}
+			step, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("JSONPath %q: %w", expr, err)
+			}
+			path = append(path, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSONPath %q has an unexpected %q", expr, rest[0])
+		}
+	}
+
+	return path, nil
+}
+
+// parseBracket parses the content of a bracket step: `*`, an index or a quoted key
+func parseBracket(content string) (pathStep, error) {
+	if content == "*" {
+		return pathStep{wildcard: true}, nil
+	}
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return pathStep{key: content[1 : len(content)-1]}, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil || index < 0 {
+		return pathStep{}, fmt.Errorf("invalid bracket [%s], expected *, an index or a quoted key", content)
+	}
+	return pathStep{index: index, isIndex: true}, nil
+}
+
+// find returns the values the path selects in a decoded JSON document
+func (p jsonPath) find(document any) []any {
+	values := []any{document}
+	for _, step := range p {
+		var next []any
+		for _, value := range values {
+			next = append(next, step.apply(value)...)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+	return values
+}
+
+// apply returns the values a step selects in a value
+func (s pathStep) apply(value any) []any {
+	switch v := value.(type) {
+	case map[string]any:
+		if s.wildcard {
+			values := make([]any, 0, len(v))
+			for _, item := range v {
+				values = append(values, item)
+			}
+			return values
+		}
+		if item, ok := v[s.key]; ok && !s.isIndex {
+			return []any{item}
+		}
+	case []any:
+		if s.wildcard {
+			return v
+		}
+		if s.isIndex && s.index < len(v) {
+			return []any{v[s.index]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/vault"
+)
+
+// NewSecret generates the signing secret of an endpoint
+func NewSecret() (string, error) {
+	secret := make([]byte, constants.WebhookSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+
+	return constants.WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// SealSecret encrypts the signing secret into the endpoint, whose ID must be set
+func SealSecret(ctx context.Context, keys kms.KeyManager, endpoint *models.WebhookEndpoint, secret string) error {
+	sealed, err := vault.Seal(ctx, keys, []byte(secret), secretAdditionalData(endpoint))
+	if err != nil {
+		return err
+	}
+
+	endpoint.KeyID = sealed.KeyID
+	endpoint.EncryptedKey = sealed.EncryptedKey
+	endpoint.Nonce = sealed.Nonce
+	endpoint.Ciphertext = sealed.Ciphertext
+	return nil
+}
+
+// OpenSecret decrypts the signing secret of the endpoint
+func OpenSecret(ctx context.Context, keys kms.KeyManager, endpoint *models.WebhookEndpoint) (string, error) {
+	secret, err := vault.Open(ctx, keys, &vault.Sealed{
+		KeyID:        endpoint.KeyID,
+		EncryptedKey: endpoint.EncryptedKey,
+		Nonce:        endpoint.Nonce,
+		Ciphertext:   endpoint.Ciphertext,
+	}, secretAdditionalData(endpoint))
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+// secretAdditionalData binds a sealed secret to its company and endpoint, so that a
+// ciphertext copied onto another row does not decrypt
+func secretAdditionalData(endpoint *models.WebhookEndpoint) []byte {
+	return []byte(fmt.Sprintf("webhook_endpoint:%s:%s", endpoint.CompanyID, endpoint.ID))
+}
@@ -0,0 +1,229 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/logger"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/repositories"
+	"golang-boilerplate/internal/retry"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Sender sends the attempts of the webhook deliveries, run by the deliver_webhook jobs.
+// A failed attempt is not a failed job: the sender records it on the delivery and
+// enqueues the next attempt itself, with the backoff of the WEBHOOK_RETRY settings.
+type Sender struct {
+	repo        repositories.WebhookRepository
+	enqueuer    jobs.Enqueuer
+	keys        kms.KeyManager
+	client      *http.Client
+	backoff     retry.Backoff
+	maxAttempts int
+
+	// limiters enforce the rate limits of the endpoints, by endpoint id. They are local to
+	// the worker instance.
+	limitersMu sync.Mutex
+	limiters   map[string]*endpointLimiter
+}
+
+type endpointLimiter struct {
+	perMinute int
+	limiter   *rate.Limiter
+}
+
+// ProvideSender creates a new webhook sender
+func ProvideSender(
+	cfg *config.Config,
+	repo repositories.WebhookRepository,
+	enqueuer jobs.Enqueuer,
+	keys kms.KeyManager,
+) *Sender {
+	return &Sender{
+		repo:     repo,
+		enqueuer: enqueuer,
+		keys:     keys,
+		client: &http.Client{
+			Timeout: cfg.WebhookTimeout,
+			// A redirect is reported as the response of the endpoint, the signed request
+			// is not sent to another URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		backoff: retry.Backoff{
+			InitialInterval: cfg.WebhookRetryInitialInterval,
+			MaxInterval:     cfg.WebhookRetryMaxInterval,
+			Jitter:          retry.DefaultJitter,
+		},
+		maxAttempts: cfg.WebhookMaxAttempts,
+		limiters:    make(map[string]*endpointLimiter),
+	}
+}
+
+// ProvideWebhookSender exposes the sender to the workers
+func ProvideWebhookSender(sender *Sender) jobs.WebhookSender {
+	return sender
+}
+
+// Send implements jobs.WebhookSender. It returns an error when the attempt could not be
+// made or recorded, so that the job is retried.
+func (s *Sender) Send(ctx context.Context, deliveryID string) error {
+	delivery, err := s.repo.GetDeliveryWithEndpoint(deliveryID)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if delivery.Status != constants.WebhookDeliveryPending {
+		return nil
+	}
+
+	endpoint := &delivery.Endpoint
+	if endpoint.DeletedAt.Valid || !endpoint.Active {
+		return s.fail(delivery, "webhook endpoint is deleted or inactive")
+	}
+
+	if !s.allow(endpoint) {
+		_, err := s.enqueuer.Enqueue(ctx, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, jobs.WithDelay(constants.WebhookRateLimitDelay))
+		return err
+	}
+
+	secret, err := OpenSecret(ctx, s.keys, endpoint)
+	if err != nil {
+		return err
+	}
+
+	delivery.Attempts++
+	s.attempt(ctx, delivery, secret)
+
+	var next time.Duration
+	switch {
+	case delivery.Status == constants.WebhookDeliverySucceeded:
+	case delivery.Attempts >= s.maxAttempts:
+		delivery.Status = constants.WebhookDeliveryFailed
+	default:
+		next = s.backoff.JitteredDelay(delivery.Attempts)
+		nextAttemptAt := time.Now().Add(next)
+		delivery.NextAttemptAt = &nextAttemptAt
+	}
+
+	if err := s.repo.SaveAttempt(delivery); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Webhook delivery attempted",
+		zap.String("delivery_id", delivery.ID),
+		zap.String("webhook_id", endpoint.ID),
+		zap.String("event_type", delivery.EventType),
+		zap.String("status", delivery.Status),
+		zap.Int("attempts", delivery.Attempts),
+	)
+
+	if delivery.Status != constants.WebhookDeliveryPending {
+		return nil
+	}
+	_, err = s.enqueuer.Enqueue(ctx, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, jobs.WithDelay(next))
+	return err
+}
+
+// attempt posts the signed payload to the endpoint and records the outcome on the delivery
+func (s *Sender) attempt(ctx context.Context, delivery *models.WebhookDelivery, secret string) {
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+	delivery.LastError = nil
+	delivery.NextAttemptAt = nil
+
+	now := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		s.recordError(delivery, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", constants.WebhookUserAgent)
+	req.Header.Set(constants.HeaderWebhookID, delivery.ID)
+	req.Header.Set(constants.HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(constants.HeaderWebhookTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(constants.HeaderWebhookSignature, Sign(secret, now, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	duration := time.Since(now).Milliseconds()
+	delivery.DurationMs = &duration
+	if err != nil {
+		s.recordError(delivery, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.WebhookMaxResponseLength))
+	responseBody := toValidUTF8(body)
+	delivery.ResponseStatus = &resp.StatusCode
+	delivery.ResponseBody = &responseBody
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		s.recordError(delivery, fmt.Errorf("endpoint responded with status %d", resp.StatusCode))
+		return
+	}
+
+	deliveredAt := time.Now()
+	delivery.Status = constants.WebhookDeliverySucceeded
+	delivery.DeliveredAt = &deliveredAt
+}
+
+func (s *Sender) recordError(delivery *models.WebhookDelivery, err error) {
+	lastError := err.Error()
+	delivery.LastError = &lastError
+}
+
+// fail gives up on a delivery without attempting it
+func (s *Sender) fail(delivery *models.WebhookDelivery, reason string) error {
+	delivery.Status = constants.WebhookDeliveryFailed
+	delivery.LastError = &reason
+	delivery.NextAttemptAt = nil
+	return s.repo.SaveAttempt(delivery)
+}
+
+// allow takes a token of the rate limit of the endpoint. The limiter is replaced when the
+// limit of the endpoint changes.
+func (s *Sender) allow(endpoint *models.WebhookEndpoint) bool {
+	if endpoint.RateLimit <= 0 {
+		return true
+	}
+
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+
+	limiter, ok := s.limiters[endpoint.ID]
+	if !ok || limiter.perMinute != endpoint.RateLimit {
+		limiter = &endpointLimiter{
+			perMinute: endpoint.RateLimit,
+			limiter:   rate.NewLimiter(rate.Limit(float64(endpoint.RateLimit)/60), max(1, endpoint.RateLimit/60)),
+		}
+		s.limiters[endpoint.ID] = limiter
+	}
+
+	return limiter.limiter.Allow()
+}
+
+// toValidUTF8 keeps a response body storable in a text column, which rejects invalid
+// UTF-8 and NUL bytes
+func toValidUTF8(body []byte) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(string(body), "\uFFFD"), "\x00", "")
+}
@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang-boilerplate/internal/config"
+	"golang-boilerplate/internal/constants"
+	"golang-boilerplate/internal/errors"
+	"golang-boilerplate/internal/integration/kms"
+	"golang-boilerplate/internal/jobs"
+	"golang-boilerplate/internal/models"
+	"golang-boilerplate/internal/retry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSender(t *testing.T, repo *MockWebhookRepository, enqueuer *MockEnqueuer) *Sender {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keys, err := kms.NewLocalKeyManager(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+
+	return ProvideSender(&config.Config{
+		WebhookTimeout:              time.Second,
+		WebhookMaxAttempts:          3,
+		WebhookRetryInitialInterval: time.Minute,
+		WebhookRetryMaxInterval:     time.Hour,
+	}, repo, enqueuer, keys)
+}
+
+// newTestDelivery returns a pending delivery to an endpoint at url, whose secret is sealed
+// with the keys of the sender
+func newTestDelivery(t *testing.T, sender *Sender, url string) (*models.WebhookDelivery, string) {
+	t.Helper()
+	endpoint := models.WebhookEndpoint{
+		BaseModel:  models.NewBaseModel(),
+		CompanyID:  "company-1",
+		URL:        url,
+		EventTypes: []string{"*"},
+		Active:     true,
+	}
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.NoError(t, SealSecret(context.Background(), sender.keys, &endpoint, secret))
+
+	return &models.WebhookDelivery{
+		BaseModel:  models.NewBaseModel(),
+		CompanyID:  endpoint.CompanyID,
+		EndpointID: endpoint.ID,
+		Endpoint:   endpoint,
+		EventID:    "event-1",
+		EventType:  constants.WebhookEventUserCreated,
+		Payload:    json.RawMessage(`{"id":"event-1","type":"user.created"}`),
+		Status:     constants.WebhookDeliveryPending,
+	}, secret
+}
+
+func TestSender_Send(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		attempts         int
+		expectedStatus   string
+		expectedRetry    bool
+		expectedResponse string
+	}{
+		{name: "success", status: http.StatusNoContent, expectedStatus: constants.WebhookDeliverySucceeded},
+		{name: "failure is retried", status: http.StatusServiceUnavailable, expectedStatus: constants.WebhookDeliveryPending, expectedRetry: true, expectedResponse: "unavailable"},
+		{name: "last attempt fails", status: http.StatusBadRequest, attempts: 2, expectedStatus: constants.WebhookDeliveryFailed, expectedResponse: "unavailable"},
+		{name: "redirect is not followed", status: http.StatusFound, expectedStatus: constants.WebhookDeliveryPending, expectedRetry: true, expectedResponse: "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockWebhookRepository)
+			enqueuer := new(MockEnqueuer)
+			sender := newTestSender(t, repo, enqueuer)
+
+			var request *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				body, _ = io.ReadAll(r.Body)
+				if tt.status == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tt.status)
+				if tt.status >= 300 {
+					_, _ = w.Write([]byte("unavailable"))
+				}
+			}))
+			defer server.Close()
+
+			delivery, secret := newTestDelivery(t, sender, server.URL)
+			delivery.Attempts = tt.attempts
+			repo.On("GetDeliveryWithEndpoint", delivery.ID).Return(delivery, nil)
+			repo.On("SaveAttempt", delivery).Return(nil)
+			if tt.expectedRetry {
+				enqueuer.On("Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, false).Return(nil)
+			}
+
+			require.NoError(t, sender.Send(context.Background(), delivery.ID))
+
+			// The request is signed with the secret of the endpoint
+			require.NotNil(t, request)
+			assert.Equal(t, delivery.ID, request.Header.Get(constants.HeaderWebhookID))
+			assert.Equal(t, delivery.EventType, request.Header.Get(constants.HeaderWebhookEvent))
+			unix, err := strconv.ParseInt(request.Header.Get(constants.HeaderWebhookTimestamp), 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, Sign(secret, time.Unix(unix, 0), body), request.Header.Get(constants.HeaderWebhookSignature))
+			assert.JSONEq(t, string(delivery.Payload), string(body))
+
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Equal(t, tt.attempts+1, delivery.Attempts)
+			require.NotNil(t, delivery.ResponseStatus)
+			assert.Equal(t, tt.status, *delivery.ResponseStatus)
+			assert.Equal(t, tt.expectedResponse, *delivery.ResponseBody)
+			assert.Equal(t, tt.expectedRetry, delivery.NextAttemptAt != nil)
+			assert.Equal(t, tt.expectedStatus == constants.WebhookDeliverySucceeded, delivery.DeliveredAt != nil)
+			assert.Equal(t, tt.expectedStatus == constants.WebhookDeliverySucceeded, delivery.LastError == nil)
+			repo.AssertExpectations(t)
+			enqueuer.AssertExpectations(t)
+			if !tt.expectedRetry {
+				enqueuer.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSender_Send_Unreachable(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	enqueuer := new(MockEnqueuer)
+	sender := newTestSender(t, repo, enqueuer)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	delivery, _ := newTestDelivery(t, sender, server.URL)
+	repo.On("GetDeliveryWithEndpoint", delivery.ID).Return(delivery, nil)
+	repo.On("SaveAttempt", delivery).Return(nil)
+	enqueuer.On("Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: delivery.ID}, false).Return(nil)
+
+	require.NoError(t, sender.Send(context.Background(), delivery.ID))
+
+	assert.Equal(t, constants.WebhookDeliveryPending, delivery.Status)
+	assert.Nil(t, delivery.ResponseStatus)
+	require.NotNil(t, delivery.LastError)
+	assert.Contains(t, *delivery.LastError, "connection refused")
+	require.NotNil(t, delivery.NextAttemptAt)
+	// The first retry waits the initial interval, with jitter
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *delivery.NextAttemptAt, 31*time.Second)
+}
+
+func TestSender_Send_Skipped(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(delivery *models.WebhookDelivery)
+		expectedStatus string
+	}{
+		{
+			name:           "already delivered",
+			setup:          func(delivery *models.WebhookDelivery) { delivery.Status = constants.WebhookDeliverySucceeded },
+			expectedStatus: constants.WebhookDeliverySucceeded,
+		},
+		{
+			name:           "inactive endpoint",
+			setup:          func(delivery *models.WebhookDelivery) { delivery.Endpoint.Active = false },
+			expectedStatus: constants.WebhookDeliveryFailed,
+		},
+		{
+			name: "deleted endpoint",
+			setup: func(delivery *models.WebhookDelivery) {
+				delivery.Endpoint.DeletedAt.Time = time.Now()
+				delivery.Endpoint.DeletedAt.Valid = true
+			},
+			expectedStatus: constants.WebhookDeliveryFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockWebhookRepository)
+			sender := newTestSender(t, repo, new(MockEnqueuer))
+
+			called := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+			defer server.Close()
+
+			delivery, _ := newTestDelivery(t, sender, server.URL)
+			tt.setup(delivery)
+			repo.On("GetDeliveryWithEndpoint", delivery.ID).Return(delivery, nil)
+			repo.On("SaveAttempt", delivery).Return(nil).Maybe()
+
+			require.NoError(t, sender.Send(context.Background(), delivery.ID))
+
+			assert.False(t, called)
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Zero(t, delivery.Attempts)
+		})
+	}
+}
+
+func TestSender_Send_NotFound(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := newTestSender(t, repo, new(MockEnqueuer))
+	repo.On("GetDeliveryWithEndpoint", "missing").Return(nil, errors.NotFoundError("Webhook delivery", nil))
+
+	err := sender.Send(context.Background(), "missing")
+
+	assert.True(t, retry.IsPermanent(err), "a missing delivery is not retried")
+}
+
+func TestSender_Send_RateLimited(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	enqueuer := new(MockEnqueuer)
+	sender := newTestSender(t, repo, enqueuer)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	first, _ := newTestDelivery(t, sender, server.URL)
+	first.Endpoint.RateLimit = 1
+	second := *first
+	second.BaseModel = models.NewBaseModel()
+	repo.On("GetDeliveryWithEndpoint", first.ID).Return(first, nil)
+	repo.On("GetDeliveryWithEndpoint", second.ID).Return(&second, nil)
+	repo.On("SaveAttempt", first).Return(nil)
+	enqueuer.On("Enqueue", mock.Anything, jobs.DeliverWebhookArgs{DeliveryID: second.ID}, false).Return(nil)
+
+	require.NoError(t, sender.Send(context.Background(), first.ID))
+	require.NoError(t, sender.Send(context.Background(), second.ID))
+
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, constants.WebhookDeliverySucceeded, first.Status)
+	assert.Equal(t, constants.WebhookDeliveryPending, second.Status)
+	assert.Zero(t, second.Attempts, "a rate limited delivery does not count an attempt")
+	repo.AssertNotCalled(t, "SaveAttempt", &second)
+	enqueuer.AssertExpectations(t)
+}
@@ -0,0 +1,24 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"golang-boilerplate/internal/constants"
+)
+
+// Sign returns the signature of a request body sent at timestamp, the value of the
+// X-Webhook-Signature header: `v1=` and the hex HMAC-SHA256 of `<unix timestamp>.<body>`
+// keyed with the secret of the endpoint. Receivers compute it again from the
+// X-Webhook-Timestamp header and the raw body, compare it in constant time and reject old
+// timestamps, so that a captured request cannot be replayed.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return constants.WebhookSignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,19 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"event-1"}`)
+
+	// printf '1700000000.{"id":"event-1"}' | openssl dgst -sha256 -hmac whsec_test
+	assert.Equal(t, "v1=8e2971dac7c4d9294c7c65f1bd33cef904855a225900e22ee05f866f468078eb", Sign("whsec_test", timestamp, body))
+	assert.NotEqual(t, Sign("whsec_test", timestamp, body), Sign("whsec_test", timestamp.Add(time.Second), body),
+		"the timestamp is signed, so that it cannot be replaced")
+	assert.NotEqual(t, Sign("whsec_test", timestamp, body), Sign("whsec_other", timestamp, body))
+}